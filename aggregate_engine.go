@@ -0,0 +1,84 @@
+package macaudio
+
+import (
+	"fmt"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/devices"
+)
+
+// NewAggregateEngine composes outputs into a CoreAudio aggregate device (see
+// devices.NewAggregateDevice) on the fly and starts a new Engine bound to
+// it, for multi-interface setups - built-in speakers plus a USB interface
+// plus a virtual loopback, say - that would otherwise need the caller to
+// assemble an aggregate by hand in Audio MIDI Setup first.
+//
+// inputChannels and bufferSize become the new engine's AudioSpec.
+// ChannelCount/BufferSize; everything else uses NewEngine's usual defaults.
+// The aggregate's UID is recorded on the returned Engine the same way
+// ensureAggregateOutput records one it created itself, so DestroyAggregate
+// (or Destroy) can tear it down again.
+func NewAggregateEngine(outputs []*devices.AudioDevice, inputChannels, bufferSize int) (*Engine, error) {
+	aggregate, err := devices.NewAggregateDevice("macaudio Aggregate", outputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aggregate output device: %w", err)
+	}
+
+	e, err := NewEngine(EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			ChannelCount: inputChannels,
+			BufferSize:   bufferSize,
+		},
+		OutputDeviceUID: aggregate.UID,
+	})
+	if err != nil {
+		if destroyErr := devices.DestroyAggregate(aggregate.UID); destroyErr != nil {
+			return nil, fmt.Errorf("%w (also failed to clean up aggregate device: %v)", err, destroyErr)
+		}
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.aggregateDeviceUID = aggregate.UID
+	e.mu.Unlock()
+	return e, nil
+}
+
+// DestroyAggregate tears down the CoreAudio aggregate device this engine is
+// using (whether NewAggregateEngine created it or EngineConfig.Outputs
+// spanning more than one device did, via ensureAggregateOutput), without
+// stopping or destroying the engine itself. A caller done with the engine
+// entirely should call Destroy instead, which tears down the same aggregate
+// device as part of its own cleanup. Safe to call on an engine with no
+// aggregate device - it's then a no-op.
+func (e *Engine) DestroyAggregate() error {
+	e.mu.Lock()
+	uid := e.aggregateDeviceUID
+	e.aggregateDeviceUID = ""
+	e.mu.Unlock()
+
+	if uid == "" {
+		return nil
+	}
+	if err := devices.DestroyAggregateDevice(uid); err != nil {
+		return fmt.Errorf("failed to destroy aggregate device: %w", err)
+	}
+	return nil
+}
+
+// SetAggregateDriftCompensation toggles clock drift compensation for one
+// sub-device of this engine's aggregate output device (see devices.
+// SetAggregateDriftCompensation), for correcting drift on whichever
+// sub-device isn't serving as the aggregate's master clock without tearing
+// the aggregate down and recreating it. Errors if this engine has no
+// aggregate output device.
+func (e *Engine) SetAggregateDriftCompensation(subDeviceUID string, on bool) error {
+	e.mu.RLock()
+	uid := e.aggregateDeviceUID
+	e.mu.RUnlock()
+
+	if uid == "" {
+		return fmt.Errorf("engine has no aggregate output device")
+	}
+	return devices.SetAggregateDriftCompensation(uid, subDeviceUID, on)
+}
@@ -0,0 +1,12 @@
+// Package audio holds small, dependency-free types shared by decoders that
+// stream audio into the engine one block at a time (see macaudio.Decoder),
+// as opposed to avaudio/engine's PCMBuffer, which holds an entire decoded
+// file at once.
+package audio
+
+// Block is one chunk of interleaved float32 PCM samples in [-1, 1],
+// channelCount samples per frame, as produced by a macaudio.Decoder.
+type Block struct {
+	Samples []float32
+	Frames  int
+}
@@ -0,0 +1,453 @@
+// Package console serves a newline-delimited line protocol over TCP for
+// driving a macaudio.Engine's channel graph by address (see
+// macaudio.ChannelRegistry) instead of OSC's binary framing or a CGO
+// binding - built for scripting and manual probing with nc/telnet.
+//
+//	get <address> volume|pan|mute
+//	set <address> volume|pan|mute <value>
+//	send <address> <aux-address> level <value>
+//	route <address> -> <bus-address>
+//	snapshot save <path>
+//	snapshot load <path>
+//	subscribe events
+//
+// Every command other than subscribe replies with a single line, "ok" or
+// "ok <value>" on success, "err <reason>" on failure. subscribe takes over
+// the connection, streaming one line per event until the client
+// disconnects. Multiple clients may connect at once; each gets its own
+// goroutine and sees the engine's current state independently.
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shaban/macaudio"
+	"github.com/shaban/macaudio/avaudio/events"
+)
+
+// Options configures Serve.
+type Options struct {
+	Addr string
+
+	// AuthToken, if set, requires a connecting client's first line to be
+	// "auth <token>" before any other command is accepted.
+	AuthToken string
+
+	// ReadOnly rejects set, send, route, and snapshot load, leaving get,
+	// snapshot save, and subscribe available.
+	ReadOnly bool
+}
+
+// Server accepts console connections against one Engine.
+type Server struct {
+	engine *macaudio.Engine
+	opts   Options
+
+	listener net.Listener
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Serve starts listening on opts.Addr and handling connections against
+// engine until Close is called.
+func Serve(engine *macaudio.Engine, opts Options) (*Server, error) {
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("console: listen %s: %w", opts.Addr, err)
+	}
+
+	s := &Server{engine: engine, opts: opts, listener: listener}
+	s.wg.Add(1)
+	go s.acceptLoop()
+	return s, nil
+}
+
+// Addr returns the listener's bound address - useful when Options.Addr was
+// "127.0.0.1:0" and the actual port is needed (e.g. in a test).
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops accepting new connections and waits for in-flight ones to
+// finish.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	authed := s.opts.AuthToken == ""
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if !authed {
+			if len(fields) == 2 && fields[0] == "auth" && fields[1] == s.opts.AuthToken {
+				authed = true
+				fmt.Fprintln(conn, "ok")
+			} else {
+				fmt.Fprintln(conn, "err unauthorized")
+			}
+			continue
+		}
+
+		if fields[0] == "subscribe" {
+			s.handleSubscribe(conn, fields)
+			return // subscribe owns the connection until the client disconnects
+		}
+
+		fmt.Fprintln(conn, s.dispatch(fields))
+	}
+}
+
+func (s *Server) dispatch(fields []string) string {
+	switch fields[0] {
+	case "get":
+		return s.handleGet(fields[1:])
+	case "set":
+		if s.opts.ReadOnly {
+			return "err read-only mode"
+		}
+		return s.handleSet(fields[1:])
+	case "send":
+		if s.opts.ReadOnly {
+			return "err read-only mode"
+		}
+		return s.handleSend(fields[1:])
+	case "route":
+		if s.opts.ReadOnly {
+			return "err read-only mode"
+		}
+		return s.handleRoute(fields[1:])
+	case "snapshot":
+		return s.handleSnapshot(fields[1:])
+	default:
+		return fmt.Sprintf("err unknown command %q", fields[0])
+	}
+}
+
+func (s *Server) resolve(address string) (macaudio.Channel, error) {
+	ch, ok := s.engine.ResolveAddress(address)
+	if !ok {
+		return nil, fmt.Errorf("no channel registered at address %q", address)
+	}
+	return ch, nil
+}
+
+func (s *Server) handleGet(args []string) string {
+	if len(args) != 2 {
+		return "err usage: get <address> volume|pan|mute"
+	}
+	ch, err := s.resolve(args[0])
+	if err != nil {
+		return "err " + err.Error()
+	}
+
+	switch args[1] {
+	case "volume":
+		v, err := ch.GetVolume()
+		if err != nil {
+			return "err " + err.Error()
+		}
+		return fmt.Sprintf("ok %v", v)
+	case "pan":
+		v, err := ch.GetPan()
+		if err != nil {
+			return "err " + err.Error()
+		}
+		return fmt.Sprintf("ok %v", v)
+	case "mute":
+		v, err := ch.GetMute()
+		if err != nil {
+			return "err " + err.Error()
+		}
+		return fmt.Sprintf("ok %v", v)
+	default:
+		return fmt.Sprintf("err unknown parameter %q", args[1])
+	}
+}
+
+func (s *Server) handleSet(args []string) string {
+	if len(args) != 3 {
+		return "err usage: set <address> volume|pan|mute <value>"
+	}
+	ch, err := s.resolve(args[0])
+	if err != nil {
+		return "err " + err.Error()
+	}
+
+	switch args[1] {
+	case "volume":
+		v, err := strconv.ParseFloat(args[2], 32)
+		if err != nil {
+			return "err invalid value"
+		}
+		if err := ch.SetVolume(float32(v)); err != nil {
+			return "err " + err.Error()
+		}
+	case "pan":
+		v, err := strconv.ParseFloat(args[2], 32)
+		if err != nil {
+			return "err invalid value"
+		}
+		if err := ch.SetPan(float32(v)); err != nil {
+			return "err " + err.Error()
+		}
+	case "mute":
+		v, err := strconv.ParseBool(args[2])
+		if err != nil {
+			return "err invalid value"
+		}
+		if err := ch.SetMute(v); err != nil {
+			return "err " + err.Error()
+		}
+	default:
+		return fmt.Sprintf("err unknown parameter %q", args[1])
+	}
+	return "ok"
+}
+
+func (s *Server) handleSend(args []string) string {
+	if len(args) != 4 || args[2] != "level" {
+		return "err usage: send <address> <aux-address> level <value>"
+	}
+	ch, err := s.resolve(args[0])
+	if err != nil {
+		return "err " + err.Error()
+	}
+	auxCh, err := s.resolve(args[1])
+	if err != nil {
+		return "err " + err.Error()
+	}
+	aux, ok := auxCh.(*macaudio.AuxChannel)
+	if !ok {
+		return fmt.Sprintf("err %q is not an aux channel", args[1])
+	}
+	level, err := strconv.ParseFloat(args[3], 32)
+	if err != nil {
+		return "err invalid value"
+	}
+
+	for _, send := range ch.GetSends() {
+		if send.Aux == aux {
+			if err := ch.SetSendLevel(aux, float32(level)); err != nil {
+				return "err " + err.Error()
+			}
+			return "ok"
+		}
+	}
+	if err := ch.AddSend(aux, float32(level), false); err != nil {
+		return "err " + err.Error()
+	}
+	return "ok"
+}
+
+func (s *Server) handleRoute(args []string) string {
+	if len(args) != 3 || args[1] != "->" {
+		return "err usage: route <address> -> <bus-address>"
+	}
+	ch, err := s.resolve(args[0])
+	if err != nil {
+		return "err " + err.Error()
+	}
+	router, ok := ch.(interface{ RouteToAddress(address string) error })
+	if !ok {
+		return fmt.Sprintf("err %q can't be routed", args[0])
+	}
+	if err := router.RouteToAddress(args[2]); err != nil {
+		return "err " + err.Error()
+	}
+	return "ok"
+}
+
+func (s *Server) handleSnapshot(args []string) string {
+	if len(args) != 2 {
+		return "err usage: snapshot save|load <path>"
+	}
+	serializer := s.engine.GetSerializer()
+	switch args[0] {
+	case "save":
+		if err := serializer.SaveToFile(args[1]); err != nil {
+			return "err " + err.Error()
+		}
+		return "ok"
+	case "load":
+		if s.opts.ReadOnly {
+			return "err read-only mode"
+		}
+		if err := serializer.LoadFromFile(args[1]); err != nil {
+			return "err " + err.Error()
+		}
+		return "ok"
+	default:
+		return fmt.Sprintf("err unknown snapshot command %q", args[0])
+	}
+}
+
+// channelEvents is the duck-typed set of event subscriptions every
+// concrete channel type picks up by embedding *macaudio.BaseChannel (see
+// macaudio's events.go) - used by handleSubscribe to fan every channel's
+// events into one client stream without needing macaudio.Channel itself to
+// declare them.
+type channelEvents interface {
+	OnVolumeChanged(opts ...events.SubscribeOption) *events.Subscription[macaudio.VolumeChanged]
+	OnPanChanged(opts ...events.SubscribeOption) *events.Subscription[macaudio.PanChanged]
+	OnMuteChanged(opts ...events.SubscribeOption) *events.Subscription[macaudio.MuteChanged]
+	OnSendLevelChanged(opts ...events.SubscribeOption) *events.Subscription[macaudio.SendLevelChanged]
+	OnReleased(opts ...events.SubscribeOption) *events.Subscription[macaudio.ChannelReleased]
+}
+
+// handleSubscribe streams a line per event from every channel currently in
+// the engine until the client disconnects. Channels created after
+// subscribe is called aren't picked up - a client that needs that can
+// reconnect, the same limitation osc.Server's /status subscribers have
+// around newly created channels.
+func (s *Server) handleSubscribe(conn net.Conn, fields []string) {
+	if len(fields) != 2 || fields[1] != "events" {
+		fmt.Fprintln(conn, "err usage: subscribe events")
+		return
+	}
+
+	var writeMu sync.Mutex
+	write := func(line string) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		fmt.Fprintln(conn, line)
+	}
+
+	// stopCh, not Subscription.Close, is what ends the forwarder goroutines
+	// below: Close only unregisters from the Emitter and drains the
+	// channel, it never closes Ch() (Emit may still be concurrently
+	// selecting on it), so a `range sub.Ch()` would never return on its
+	// own. stopCh is closed once the client disconnects, after which every
+	// forwarder selects its own exit instead of blocking forever.
+	stopCh := make(chan struct{})
+	var wg sync.WaitGroup
+	var subs []interface{ Close() error }
+	defer func() {
+		close(stopCh)
+		for _, sub := range subs {
+			sub.Close()
+		}
+		wg.Wait()
+	}()
+
+	for _, id := range s.engine.ListChannels() {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		src, ok := ch.(channelEvents)
+		if !ok {
+			continue
+		}
+
+		volumeSub := src.OnVolumeChanged()
+		panSub := src.OnPanChanged()
+		muteSub := src.OnMuteChanged()
+		sendSub := src.OnSendLevelChanged()
+		releasedSub := src.OnReleased()
+		subs = append(subs, volumeSub, panSub, muteSub, sendSub, releasedSub)
+
+		wg.Add(5)
+		go func(id string) {
+			defer wg.Done()
+			for {
+				select {
+				case ev := <-volumeSub.Ch():
+					write(fmt.Sprintf("event volume %s %v %v", id, ev.Old, ev.New))
+				case <-stopCh:
+					return
+				}
+			}
+		}(id)
+		go func(id string) {
+			defer wg.Done()
+			for {
+				select {
+				case ev := <-panSub.Ch():
+					write(fmt.Sprintf("event pan %s %v %v", id, ev.Old, ev.New))
+				case <-stopCh:
+					return
+				}
+			}
+		}(id)
+		go func(id string) {
+			defer wg.Done()
+			for {
+				select {
+				case ev := <-muteSub.Ch():
+					write(fmt.Sprintf("event mute %s %v %v", id, ev.Old, ev.New))
+				case <-stopCh:
+					return
+				}
+			}
+		}(id)
+		go func(id string) {
+			defer wg.Done()
+			for {
+				select {
+				case ev := <-sendSub.Ch():
+					write(fmt.Sprintf("event send %s %s %v %v", id, ev.SendName, ev.Old, ev.New))
+				case <-stopCh:
+					return
+				}
+			}
+		}(id)
+		go func(id string) {
+			defer wg.Done()
+			for {
+				select {
+				case <-releasedSub.Ch():
+					write(fmt.Sprintf("event released %s", id))
+				case <-stopCh:
+					return
+				}
+			}
+		}(id)
+	}
+
+	// Block until the client disconnects (any read error, including EOF),
+	// then the deferred cleanup above stops every forwarder and closes
+	// every subscription.
+	buf := make([]byte, 1)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			return
+		}
+	}
+}
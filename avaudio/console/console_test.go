@@ -0,0 +1,185 @@
+package console
+
+import (
+	"bufio"
+	"net"
+	"testing"
+
+	"github.com/shaban/macaudio"
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/devices"
+)
+
+func newTestEngine(t *testing.T) (*macaudio.Engine, func()) {
+	t.Helper()
+
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		t.Fatalf("Failed to enumerate audio devices: %v", err)
+	}
+	outputs := audioDevices.Online().Outputs()
+	if len(outputs) == 0 {
+		t.Skip("No online output devices available for testing")
+	}
+
+	config := macaudio.EngineConfig{
+		AudioSpec: avengine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   512,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		OutputDeviceUID: outputs[0].UID,
+	}
+	eng, err := macaudio.NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	return eng, func() { eng.Stop() }
+}
+
+// dial connects to srv and returns a line reader/writer pair for driving
+// the command protocol in a test.
+func dial(t *testing.T, srv *Server) (net.Conn, *bufio.Scanner) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	return conn, bufio.NewScanner(conn)
+}
+
+func sendLine(t *testing.T, conn net.Conn, scanner *bufio.Scanner, line string) string {
+	t.Helper()
+	if _, err := conn.Write([]byte(line + "\n")); err != nil {
+		t.Fatalf("Write(%q) failed: %v", line, err)
+	}
+	if !scanner.Scan() {
+		t.Fatalf("no reply to %q: %v", line, scanner.Err())
+	}
+	return scanner.Text()
+}
+
+// TestConsoleDrivesChannelThroughCommands dials the listener and drives a
+// channel through volume/pan/mute/send commands, checking the console's
+// replies and the channel's actual state (via direct method calls) agree.
+func TestConsoleDrivesChannelThroughCommands(t *testing.T) {
+	eng, cleanup := newTestEngine(t)
+	defer cleanup()
+
+	reverb, err := eng.CreateAuxChannel("Reverb", macaudio.AuxConfig{SendLevel: 1, ReturnLevel: 1})
+	if err != nil {
+		t.Fatalf("CreateAuxChannel failed: %v", err)
+	}
+	sine, err := eng.CreateSineChannel("Vocal", 440, 0.1)
+	if err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+
+	srv, err := Serve(eng, Options{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer srv.Close()
+
+	conn, scanner := dial(t, srv)
+	defer conn.Close()
+
+	if reply := sendLine(t, conn, scanner, "set channel/vocal volume 0.42"); reply != "ok" {
+		t.Errorf("set volume: expected \"ok\", got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "set channel/vocal pan -0.3"); reply != "ok" {
+		t.Errorf("set pan: expected \"ok\", got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "set channel/vocal mute true"); reply != "ok" {
+		t.Errorf("set mute: expected \"ok\", got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "send channel/vocal channel/reverb level 0.3"); reply != "ok" {
+		t.Errorf("send level: expected \"ok\", got %q", reply)
+	}
+
+	if reply := sendLine(t, conn, scanner, "get channel/vocal volume"); reply != "ok 0.42" {
+		t.Errorf("get volume: expected \"ok 0.42\", got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "get channel/vocal pan"); reply != "ok -0.3" {
+		t.Errorf("get pan: expected \"ok -0.3\", got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "get channel/vocal mute"); reply != "ok true" {
+		t.Errorf("get mute: expected \"ok true\", got %q", reply)
+	}
+
+	volume, err := sine.GetVolume()
+	if err != nil || volume != 0.42 {
+		t.Errorf("expected sine.GetVolume() == 0.42, got %v (err=%v)", volume, err)
+	}
+	pan, err := sine.GetPan()
+	if err != nil || pan != -0.3 {
+		t.Errorf("expected sine.GetPan() == -0.3, got %v (err=%v)", pan, err)
+	}
+	muted, err := sine.GetMute()
+	if err != nil || !muted {
+		t.Errorf("expected sine.GetMute() == true, got %v (err=%v)", muted, err)
+	}
+	sends := sine.GetSends()
+	if len(sends) != 1 || sends[0].Aux != reverb || sends[0].Level != 0.3 {
+		t.Errorf("expected one send to reverb at level 0.3, got %+v", sends)
+	}
+}
+
+func TestConsoleReadOnlyRejectsMutations(t *testing.T) {
+	eng, cleanup := newTestEngine(t)
+	defer cleanup()
+
+	if _, err := eng.CreateSineChannel("Vocal", 440, 0.1); err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+
+	srv, err := Serve(eng, Options{Addr: "127.0.0.1:0", ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer srv.Close()
+
+	conn, scanner := dial(t, srv)
+	defer conn.Close()
+
+	reply := sendLine(t, conn, scanner, "set channel/vocal volume 0.9")
+	if reply != "err read-only mode" {
+		t.Errorf("expected read-only rejection, got %q", reply)
+	}
+	reply = sendLine(t, conn, scanner, "get channel/vocal volume")
+	if reply != "ok 1" {
+		t.Errorf("expected get to still work in read-only mode, got %q", reply)
+	}
+}
+
+func TestConsoleRequiresAuthToken(t *testing.T) {
+	eng, cleanup := newTestEngine(t)
+	defer cleanup()
+
+	if _, err := eng.CreateSineChannel("Vocal", 440, 0.1); err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+
+	srv, err := Serve(eng, Options{Addr: "127.0.0.1:0", AuthToken: "secret"})
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer srv.Close()
+
+	conn, scanner := dial(t, srv)
+	defer conn.Close()
+
+	if reply := sendLine(t, conn, scanner, "get channel/vocal volume"); reply != "err unauthorized" {
+		t.Errorf("expected unauthorized before auth, got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "auth wrong"); reply != "err unauthorized" {
+		t.Errorf("expected unauthorized for wrong token, got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "auth secret"); reply != "ok" {
+		t.Errorf("expected ok for correct token, got %q", reply)
+	}
+	if reply := sendLine(t, conn, scanner, "get channel/vocal volume"); reply != "ok 1" {
+		t.Errorf("expected get to work after auth, got %q", reply)
+	}
+}
@@ -0,0 +1,85 @@
+// Package effects gives callers typed constructors for Apple's built-in
+// AVFoundation effect units, instead of hand-assembling a plugins.Plugin
+// blueprint from raw four-character component codes. Each constructor
+// returns a *plugins.Plugin ready to pass to
+// avaudio/pluginchain.PluginChain's AddEffect/InsertEffect, or directly to
+// avaudio/unit.CreateEffect - this package doesn't duplicate either of
+// those, it only fills in the "which component description is this
+// built-in effect again?" gap with named, documented entry points.
+//
+// AudioUnitEffect covers the general case: any installed AUv3/AUv2 by its
+// own type/subtype/manufacturer codes, for effects this package doesn't
+// have a named wrapper for.
+package effects
+
+import "github.com/shaban/macaudio/plugins"
+
+// Apple's well-known four-character component codes for its built-in
+// effect units (see AudioToolbox/AudioUnitProperties.h's
+// kAudioUnitSubType_* constants). ManufacturerID is "appl" for all of them.
+const (
+	appleManufacturer = "appl"
+	typeEffect        = "aufx"
+
+	subtypeNewTimePitch = "nutp" // AVAudioUnitTimePitch
+	subtypeVarispeed    = "vari" // AVAudioUnitVarispeed
+	subtypeReverb2      = "rvb2" // AVAudioUnitReverb
+	subtypeDistortion   = "dist" // AVAudioUnitDistortion
+	subtypeNBandEQ      = "nbeq" // AVAudioUnitEQ
+)
+
+// newBuiltin returns a plugins.Plugin blueprint for one of Apple's built-in
+// effect units, identified by name and subtype code.
+func newBuiltin(name, subtype string) *plugins.Plugin {
+	return &plugins.Plugin{
+		Name:           name,
+		Type:           typeEffect,
+		Subtype:        subtype,
+		ManufacturerID: appleManufacturer,
+	}
+}
+
+// NewTimePitch returns a blueprint for AVAudioUnitTimePitch: independent
+// pitch (cents) and rate control, the effect Player.EnableTimePitchEffects
+// already hardcodes - this constructor lets an EffectChain hold the same
+// unit as an ordinary, reorderable chain member instead.
+func NewTimePitch() *plugins.Plugin {
+	return newBuiltin("AUTimePitch", subtypeNewTimePitch)
+}
+
+// NewVarispeed returns a blueprint for AVAudioUnitVarispeed: tape-style
+// playback rate control that pitch-shifts as a side effect of rate change,
+// unlike NewTimePitch's independent controls.
+func NewVarispeed() *plugins.Plugin {
+	return newBuiltin("AUVarispeed", subtypeVarispeed)
+}
+
+// NewReverb returns a blueprint for AVAudioUnitReverb.
+func NewReverb() *plugins.Plugin {
+	return newBuiltin("AUReverb2", subtypeReverb2)
+}
+
+// NewDistortion returns a blueprint for AVAudioUnitDistortion.
+func NewDistortion() *plugins.Plugin {
+	return newBuiltin("AUDistortion", subtypeDistortion)
+}
+
+// NewEQ returns a blueprint for AVAudioUnitEQ, Apple's multi-band
+// parametric EQ (kAudioUnitSubType_NBandEQ). The number of active bands is
+// itself a parameter on the instantiated unit, not a constructor argument.
+func NewEQ() *plugins.Plugin {
+	return newBuiltin("AUNBandEQ", subtypeNBandEQ)
+}
+
+// NewAudioUnitEffect returns a blueprint for any installed AU by its own
+// component description - the general escape hatch for effects this
+// package has no named wrapper for (a third-party AUv3, or a built-in unit
+// this package hasn't added a constructor for yet).
+func NewAudioUnitEffect(name, unitType, subtype, manufacturerID string) *plugins.Plugin {
+	return &plugins.Plugin{
+		Name:           name,
+		Type:           unitType,
+		Subtype:        subtype,
+		ManufacturerID: manufacturerID,
+	}
+}
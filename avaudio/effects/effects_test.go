@@ -0,0 +1,45 @@
+package effects
+
+import "testing"
+
+func TestNewTimePitch(t *testing.T) {
+	p := NewTimePitch()
+	if p.Type != typeEffect || p.Subtype != subtypeNewTimePitch || p.ManufacturerID != appleManufacturer {
+		t.Errorf("NewTimePitch() = %+v, want type=%q subtype=%q manufacturer=%q", p, typeEffect, subtypeNewTimePitch, appleManufacturer)
+	}
+}
+
+func TestNewVarispeed(t *testing.T) {
+	p := NewVarispeed()
+	if p.Subtype != subtypeVarispeed {
+		t.Errorf("NewVarispeed().Subtype = %q, want %q", p.Subtype, subtypeVarispeed)
+	}
+}
+
+func TestNewReverb(t *testing.T) {
+	p := NewReverb()
+	if p.Subtype != subtypeReverb2 {
+		t.Errorf("NewReverb().Subtype = %q, want %q", p.Subtype, subtypeReverb2)
+	}
+}
+
+func TestNewDistortion(t *testing.T) {
+	p := NewDistortion()
+	if p.Subtype != subtypeDistortion {
+		t.Errorf("NewDistortion().Subtype = %q, want %q", p.Subtype, subtypeDistortion)
+	}
+}
+
+func TestNewEQ(t *testing.T) {
+	p := NewEQ()
+	if p.Subtype != subtypeNBandEQ {
+		t.Errorf("NewEQ().Subtype = %q, want %q", p.Subtype, subtypeNBandEQ)
+	}
+}
+
+func TestNewAudioUnitEffectUsesGivenCodes(t *testing.T) {
+	p := NewAudioUnitEffect("Custom", "aufx", "cust", "cstm")
+	if p.Name != "Custom" || p.Type != "aufx" || p.Subtype != "cust" || p.ManufacturerID != "cstm" {
+		t.Errorf("NewAudioUnitEffect(...) = %+v, want Name=Custom Type=aufx Subtype=cust ManufacturerID=cstm", p)
+	}
+}
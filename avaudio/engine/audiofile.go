@@ -0,0 +1,125 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+#include <stdlib.h>
+
+// AudioFile function declarations - a streaming AVAudioFile reader, distinct
+// from AudioPlayer (which schedules a file for playback rather than
+// decoding it into Go-visible buffers).
+AudioFileResult audiofile_open(const char* path);
+const char* audiofile_get_info(void* filePtr, double* sampleRate, int* channelCount, long long* frameCount);
+const char* audiofile_seek(void* filePtr, long long frame);
+const char* audiofile_read(void* filePtr, float* buffer, int frameCapacity, int* framesRead);
+const char* audiofile_close(void* filePtr);
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// AudioFile is a streaming reader over a decoded audio file, read
+// frame-chunk by frame-chunk rather than loaded whole into memory like
+// Decoder.Decode. It exists for callers that only need to scan a file once
+// (waveform peak extraction, file analysis) and shouldn't have to hold the
+// whole thing in a PCMBuffer to do it.
+type AudioFile struct {
+	ptr          unsafe.Pointer
+	sampleRate   float64
+	channelCount int
+	frameCount   int64
+}
+
+// OpenAudioFile opens path for streaming, reporting an error for anything
+// AVAudioFile can't decode directly (no Decoder registry fallback here -
+// that's LoadFileStreaming's job for playback, not analysis).
+func OpenAudioFile(path string) (*AudioFile, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	result := C.audiofile_open(cPath)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+
+	f := &AudioFile{ptr: result.result}
+
+	var sampleRate C.double
+	var channelCount C.int
+	var frameCount C.longlong
+	if errStr := C.audiofile_get_info(f.ptr, &sampleRate, &channelCount, &frameCount); errStr != nil {
+		C.audiofile_close(f.ptr)
+		return nil, errors.New(C.GoString(errStr))
+	}
+	f.sampleRate = float64(sampleRate)
+	f.channelCount = int(channelCount)
+	f.frameCount = int64(frameCount)
+
+	return f, nil
+}
+
+// SampleRate returns the file's native sample rate.
+func (f *AudioFile) SampleRate() float64 { return f.sampleRate }
+
+// ChannelCount returns the file's channel count.
+func (f *AudioFile) ChannelCount() int { return f.channelCount }
+
+// FrameCount returns the file's total length in frames.
+func (f *AudioFile) FrameCount() int64 { return f.frameCount }
+
+// Seek moves the read position to frame (0-based). The next Read starts
+// from there.
+func (f *AudioFile) Seek(frame int64) error {
+	if f == nil || f.ptr == nil {
+		return errors.New("audio file is nil")
+	}
+	if errStr := C.audiofile_seek(f.ptr, C.longlong(frame)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// Read decodes up to len(buf)/ChannelCount frames into buf, interleaved the
+// same way PCMBuffer.Samples is, and returns the number of frames read. It
+// returns io.EOF (with the last partial read's frame count, if any) once
+// the file is exhausted, matching io.Reader's end-of-stream convention.
+func (f *AudioFile) Read(buf []float32) (framesRead int, err error) {
+	if f == nil || f.ptr == nil {
+		return 0, errors.New("audio file is nil")
+	}
+	if f.channelCount == 0 {
+		return 0, errors.New("audio file has no channels")
+	}
+	frameCapacity := len(buf) / f.channelCount
+	if frameCapacity == 0 {
+		return 0, errors.New("buffer too small for one frame")
+	}
+
+	var got C.int
+	errStr := C.audiofile_read(f.ptr, (*C.float)(unsafe.Pointer(&buf[0])), C.int(frameCapacity), &got)
+	if errStr != nil {
+		return 0, errors.New(C.GoString(errStr))
+	}
+	framesRead = int(got)
+	if framesRead < frameCapacity {
+		return framesRead, io.EOF
+	}
+	return framesRead, nil
+}
+
+// Close releases the native file handle.
+func (f *AudioFile) Close() error {
+	if f == nil || f.ptr == nil {
+		return nil
+	}
+	if errStr := C.audiofile_close(f.ptr); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	f.ptr = nil
+	return nil
+}
@@ -0,0 +1,327 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include <stdlib.h>
+
+// Declared here; implemented in native/engine_audiounit.m once this tree
+// bridges AVAudioUnitComponentManager (discovery), AudioComponentInstantiate
+// (async instantiation), and AUParameterTree (parameter access + KVO) -
+// mirrors the honest-gap pattern xrun.go and notifications.go already use
+// for native hooks this sandbox can't implement.
+//
+// audiounit_list_components writes a JSON array of AUDescriptor-shaped
+// objects to *componentsJSON (caller-owned, free with C.free) for every
+// installed component matching the three four-cc filters (an empty string
+// matches anything).
+const char* audiounit_list_components(const char* typeFilter, const char* subtypeFilter, const char* manufacturerFilter, char** componentsJSON);
+
+// audiounit_instantiate synchronously wraps an async
+// AudioComponentInstantiate call, writing the resulting node pointer (ready
+// to pass to Engine.Attach/Connect) to *nodePtr.
+const char* audiounit_instantiate(const char* typeCode, const char* subtypeCode, const char* manufacturerCode, void** nodePtr);
+
+// audiounit_get_parameters writes a JSON array of AUParameterInfo-shaped
+// objects to *parametersJSON for nodePtr's AUParameterTree.
+const char* audiounit_get_parameters(void* nodePtr, char** parametersJSON);
+
+const char* audiounit_set_parameter(void* nodePtr, unsigned long long paramID, float value);
+
+// audiounit_install_parameter_kvo_observer installs a KVO observer on
+// nodePtr's AUParameterTree that calls back into Go (via a //export
+// trampoline, see (*Engine).OnAUParameterChanged's doc comment) whenever any
+// parameter's value changes. handle is an opaque token the trampoline
+// passes back so the Go side can route the event to the right listeners,
+// the same convention audioengine_install_xrun_observer uses in xrun.go.
+const char* audiounit_install_parameter_kvo_observer(void* nodePtr, uintptr_t handle);
+const char* audiounit_remove_parameter_kvo_observer(uintptr_t handle);
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// AUFilter narrows ListAudioUnits to components matching every non-empty
+// field; a blank AUFilter matches every installed AudioUnit.
+type AUFilter struct {
+	Type         string // four-cc component type, e.g. "aufx" (effect) or "aumu" (instrument)
+	Subtype      string
+	Manufacturer string
+}
+
+// AUParamID identifies one parameter within an AudioUnit's AUParameterTree,
+// stable for the plugin instance's lifetime (not necessarily across the
+// plugin's own version upgrades - see PluginState.Snapshot's Identifier
+// fallback in the plugins package for that concern).
+type AUParamID uint64
+
+// AUDescriptor identifies one installed AudioUnit component, as returned by
+// ListAudioUnits and consumed by InstantiateAudioUnit.
+type AUDescriptor struct {
+	Type         string `json:"type"`
+	Subtype      string `json:"subtype"`
+	Manufacturer string `json:"manufacturer"`
+	Name         string `json:"name"`
+	Version      uint32 `json:"version"`
+	// IsV3 is true for an AUv3 app-extension component, false for a legacy
+	// v2 in-process component.
+	IsV3 bool `json:"isV3"`
+}
+
+// AUParameterInfo describes one AudioUnit parameter, as returned by
+// GetAudioUnitParameters.
+type AUParameterInfo struct {
+	ID       AUParamID `json:"id"`
+	Name     string    `json:"name"`
+	MinValue float32   `json:"minValue"`
+	MaxValue float32   `json:"maxValue"`
+	Value    float32   `json:"value"`
+}
+
+// ListAudioUnits returns every installed AudioUnit component matching
+// filter, discovered via AVAudioUnitComponentManager.
+func ListAudioUnits(filter AUFilter) ([]AUDescriptor, error) {
+	cType := C.CString(filter.Type)
+	defer C.free(unsafe.Pointer(cType))
+	cSubtype := C.CString(filter.Subtype)
+	defer C.free(unsafe.Pointer(cSubtype))
+	cManufacturer := C.CString(filter.Manufacturer)
+	defer C.free(unsafe.Pointer(cManufacturer))
+
+	var cJSON *C.char
+	errorStr := C.audiounit_list_components(cType, cSubtype, cManufacturer, &cJSON)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	defer C.free(unsafe.Pointer(cJSON))
+
+	var descriptors []AUDescriptor
+	if err := json.Unmarshal([]byte(C.GoString(cJSON)), &descriptors); err != nil {
+		return nil, fmt.Errorf("engine: failed to parse AudioUnit component list: %w", err)
+	}
+	return descriptors, nil
+}
+
+// InstantiateAudioUnit instantiates the AudioUnit component identified by
+// desc (as returned by ListAudioUnits) via AudioComponentInstantiate,
+// returning a node pointer usable with Engine.Attach and the Connect family
+// exactly like any other node.
+func InstantiateAudioUnit(desc AUDescriptor) (unsafe.Pointer, error) {
+	cType := C.CString(desc.Type)
+	defer C.free(unsafe.Pointer(cType))
+	cSubtype := C.CString(desc.Subtype)
+	defer C.free(unsafe.Pointer(cSubtype))
+	cManufacturer := C.CString(desc.Manufacturer)
+	defer C.free(unsafe.Pointer(cManufacturer))
+
+	var nodePtr unsafe.Pointer
+	errorStr := C.audiounit_instantiate(cType, cSubtype, cManufacturer, &nodePtr)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	return nodePtr, nil
+}
+
+// GetAudioUnitParameters returns nodePtr's full AUParameterTree, flattened
+// to one AUParameterInfo per parameter.
+func GetAudioUnitParameters(nodePtr unsafe.Pointer) ([]AUParameterInfo, error) {
+	if nodePtr == nil {
+		return nil, errors.New("node pointer is nil")
+	}
+
+	var cJSON *C.char
+	errorStr := C.audiounit_get_parameters(nodePtr, &cJSON)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	defer C.free(unsafe.Pointer(cJSON))
+
+	var params []AUParameterInfo
+	if err := json.Unmarshal([]byte(C.GoString(cJSON)), &params); err != nil {
+		return nil, fmt.Errorf("engine: failed to parse AudioUnit parameter list: %w", err)
+	}
+	return params, nil
+}
+
+// SetAudioUnitParameter sets nodePtr's parameter id to value.
+func SetAudioUnitParameter(nodePtr unsafe.Pointer, id AUParamID, value float32) error {
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+	errorStr := C.audiounit_set_parameter(nodePtr, C.ulonglong(id), C.float(value))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// AUParameterListener receives AudioUnit parameter changes registered via
+// Engine.RegisterAUParameterListener - the AUParameterTree/KVO analog of
+// MixerListener for mixer volume/pan.
+type AUParameterListener interface {
+	// OnParameterChanged reports that id's value changed to value.
+	OnParameterChanged(id AUParamID, value float32)
+}
+
+// auParameterListenerKey scopes a registration to one node on one engine,
+// mirroring mixerListenerKey in mixer_listener.go.
+type auParameterListenerKey struct {
+	engine  *Engine
+	nodePtr unsafe.Pointer
+}
+
+type auParameterListenerEvent struct {
+	id    AUParamID
+	value float32
+}
+
+type auParameterListenerEntry struct {
+	listener AUParameterListener
+	ch       chan auParameterListenerEvent
+	done     chan struct{}
+}
+
+var (
+	auParameterListenersMu sync.Mutex
+	auParameterListeners   = make(map[auParameterListenerKey][]*auParameterListenerEntry)
+)
+
+// RegisterAUParameterListener registers l to be notified, on its own
+// goroutine, whenever recordAUParameterChange reports a change for nodePtr.
+// Call the returned cancel to stop delivery and release l's goroutine.
+// Mirrors RegisterMixerListener exactly: registration itself touches no
+// native code, so a listener can be registered before OnAUParameterChanged
+// installs the KVO observer that eventually feeds it.
+func (e *Engine) RegisterAUParameterListener(nodePtr unsafe.Pointer, l AUParameterListener) (cancel func()) {
+	entry := &auParameterListenerEntry{
+		listener: l,
+		ch:       make(chan auParameterListenerEvent, 32),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case ev := <-entry.ch:
+				l.OnParameterChanged(ev.id, ev.value)
+			case <-entry.done:
+				return
+			}
+		}
+	}()
+
+	key := auParameterListenerKey{engine: e, nodePtr: nodePtr}
+	auParameterListenersMu.Lock()
+	auParameterListeners[key] = append(auParameterListeners[key], entry)
+	auParameterListenersMu.Unlock()
+
+	var cancelOnce sync.Once
+	return func() {
+		cancelOnce.Do(func() {
+			auParameterListenersMu.Lock()
+			entries := auParameterListeners[key]
+			for i, candidate := range entries {
+				if candidate == entry {
+					auParameterListeners[key] = append(entries[:i], entries[i+1:]...)
+					break
+				}
+			}
+			auParameterListenersMu.Unlock()
+			close(entry.done)
+		})
+	}
+}
+
+// auParameterObservers tracks OnAUParameterChanged's installed KVO observer
+// per (engine, node) pair and the native handle used to remove it,
+// mirroring hardwareVolumeObservers in mixer_listener.go.
+var auParameterObservers = struct {
+	mu      sync.Mutex
+	handles map[auParameterListenerKey]uint64
+}{handles: make(map[auParameterListenerKey]uint64)}
+
+// OnAUParameterChanged installs a KVO observer on nodePtr's AUParameterTree,
+// so a parameter change driven from outside this package - the plugin's own
+// custom view, automation baked into a session file the host loads - reaches
+// nodePtr's registered AUParameterListeners the same way SetAudioUnitParameter
+// does, instead of only changes this package itself made being visible to
+// them.
+//
+// The cgo trampoline the KVO observer needs to call back into Go isn't wired
+// up in this tree yet (see audiounit_install_parameter_kvo_observer's
+// declaration above) - like OnHardwareVolumeChanged, this installs cleanly
+// and is retained, but no plugin-driven change reaches a listener until that
+// trampoline exists; recordAUParameterChange is the call it would make, and
+// this package's own tests drive it directly in the meantime.
+func (e *Engine) OnAUParameterChanged(nodePtr unsafe.Pointer) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+
+	key := auParameterListenerKey{engine: e, nodePtr: nodePtr}
+	auParameterObservers.mu.Lock()
+	defer auParameterObservers.mu.Unlock()
+
+	if _, installed := auParameterObservers.handles[key]; installed {
+		return nil
+	}
+
+	handle := atomic.AddUint64(&nativeHandleCounter, 1)
+	errorStr := C.audiounit_install_parameter_kvo_observer(nodePtr, C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	auParameterObservers.handles[key] = handle
+	return nil
+}
+
+// RemoveAUParameterObserver removes the KVO observer OnAUParameterChanged
+// installed for nodePtr. It's a no-op if OnAUParameterChanged was never
+// called for this (engine, node) pair.
+func (e *Engine) RemoveAUParameterObserver(nodePtr unsafe.Pointer) error {
+	key := auParameterListenerKey{engine: e, nodePtr: nodePtr}
+	auParameterObservers.mu.Lock()
+	defer auParameterObservers.mu.Unlock()
+
+	handle, ok := auParameterObservers.handles[key]
+	if !ok {
+		return nil
+	}
+	delete(auParameterObservers.handles, key)
+
+	errorStr := C.audiounit_remove_parameter_kvo_observer(C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// recordAUParameterChange delivers a parameter change on nodePtr to its
+// registered AUParameterListeners - the call the eventual KVO trampoline
+// would make. Delivery is non-blocking per listener, same as
+// publishMixerEvent in mixer_listener.go: a listener that isn't draining its
+// channel fast enough drops the event rather than stalling the caller.
+func (e *Engine) recordAUParameterChange(nodePtr unsafe.Pointer, id AUParamID, value float32) {
+	key := auParameterListenerKey{engine: e, nodePtr: nodePtr}
+	auParameterListenersMu.Lock()
+	entries := append([]*auParameterListenerEntry{}, auParameterListeners[key]...)
+	auParameterListenersMu.Unlock()
+
+	ev := auParameterListenerEvent{id: id, value: value}
+	for _, entry := range entries {
+		select {
+		case entry.ch <- ev:
+		default:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type recordingAUParameterListener struct {
+	changes chan AUParamID
+}
+
+func newRecordingAUParameterListener() *recordingAUParameterListener {
+	return &recordingAUParameterListener{changes: make(chan AUParamID, 8)}
+}
+
+func (l *recordingAUParameterListener) OnParameterChanged(id AUParamID, value float32) {
+	l.changes <- id
+}
+
+func TestRegisterAUParameterListenerDeliversChanges(t *testing.T) {
+	e := &Engine{}
+	var fakeNode int
+	nodePtr := unsafe.Pointer(&fakeNode)
+	listener := newRecordingAUParameterListener()
+
+	cancel := e.RegisterAUParameterListener(nodePtr, listener)
+	defer cancel()
+
+	e.recordAUParameterChange(nodePtr, AUParamID(7), 0.5)
+
+	select {
+	case id := <-listener.changes:
+		if id != 7 {
+			t.Errorf("expected parameter id 7, got %v", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for OnParameterChanged")
+	}
+}
+
+func TestRegisterAUParameterListenerCancelStopsDelivery(t *testing.T) {
+	e := &Engine{}
+	var fakeNode int
+	nodePtr := unsafe.Pointer(&fakeNode)
+	listener := newRecordingAUParameterListener()
+
+	cancel := e.RegisterAUParameterListener(nodePtr, listener)
+	cancel()
+
+	e.recordAUParameterChange(nodePtr, AUParamID(1), 1.0)
+
+	select {
+	case id := <-listener.changes:
+		t.Fatalf("expected no delivery after cancel, got parameter id %v", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRegisterAUParameterListenerScopesByNodePointer(t *testing.T) {
+	e := &Engine{}
+	var fakeNodeA, fakeNodeB int
+	nodeA := unsafe.Pointer(&fakeNodeA)
+	nodeB := unsafe.Pointer(&fakeNodeB)
+	listener := newRecordingAUParameterListener()
+
+	cancel := e.RegisterAUParameterListener(nodeA, listener)
+	defer cancel()
+
+	e.recordAUParameterChange(nodeB, AUParamID(1), 1.0)
+
+	select {
+	case id := <-listener.changes:
+		t.Fatalf("expected no delivery for a different node pointer, got parameter id %v", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestListAudioUnitsRequiresRealEngine(t *testing.T) {
+	// ListAudioUnits/InstantiateAudioUnit/GetAudioUnitParameters/
+	// SetAudioUnitParameter all need the native AVAudioUnitComponentManager
+	// and AUParameterTree bridge this tree doesn't have yet (see
+	// audiounit_list_components's doc comment) - they aren't exercised here
+	// the way the pure-Go listener plumbing above is. GetAudioUnitParameters
+	// and SetAudioUnitParameter's nil-pointer guards are cheap to check
+	// without any native call, though.
+	if _, err := GetAudioUnitParameters(nil); err == nil {
+		t.Error("expected GetAudioUnitParameters(nil) to fail")
+	}
+	if err := SetAudioUnitParameter(nil, AUParamID(0), 0); err == nil {
+		t.Error("expected SetAudioUnitParameter(nil, ...) to fail")
+	}
+}
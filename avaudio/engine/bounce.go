@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shaban/macaudio/format/encoder"
+)
+
+// FileFormat selects the container/codec BounceToFile writes.
+type FileFormat int
+
+const (
+	FileFormatWAV FileFormat = iota
+	FileFormatMP3
+)
+
+// supported reports whether newBounceEncoder has a writer for f.
+func (f FileFormat) supported() bool {
+	return f == FileFormatWAV || f == FileFormatMP3
+}
+
+// BounceToFile renders the engine's graph offline (see EnableManualRendering
+// and RenderOffline) and writes it to path as format, pulling
+// manualRenderingMaxFrames-sized chunks until a pull comes back with zero
+// frames - the same "nothing left to produce" signal StreamOffline treats
+// as done - so callers don't need to know the mix's length up front. The
+// engine must already be in manual rendering mode.
+//
+// FileFormatMP3 requires format/encoder to have been built with the
+// mp3lame tag (see encoder.NewMP3Writer); without it, BounceToFile returns
+// encoder.ErrMP3Unsupported. CAF and M4A aren't implemented: neither this
+// package nor format/encoder has an AVAudioFile/ExtAudioFile-backed writer,
+// so asking for them is an honest error rather than a silent WAV fallback.
+func (e *Engine) BounceToFile(path string, format FileFormat) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if e.manualRenderingMaxFrames == 0 {
+		return errors.New("engine: manual rendering is not enabled, call EnableManualRendering first")
+	}
+
+	channels := e.spec.ChannelCount
+	if channels <= 0 {
+		channels = 2
+	}
+	if !format.supported() {
+		return fmt.Errorf("engine: unsupported FileFormat %d (CAF and M4A are not yet implemented)", format)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("engine: creating %s: %w", path, err)
+	}
+	defer f.Close()
+
+	enc, err := e.newBounceEncoder(f, format, channels)
+	if err != nil {
+		return err
+	}
+	defer enc.Close()
+
+	for {
+		samples, err := e.RenderOffline(e.manualRenderingMaxFrames)
+		if err != nil {
+			return fmt.Errorf("engine: rendering offline: %w", err)
+		}
+		if len(samples) == 0 {
+			break
+		}
+
+		block := encoder.AudioBlock{
+			Samples:     samples,
+			SampleRate:  int(e.spec.SampleRate),
+			Channels:    channels,
+			Interleaved: true,
+		}
+		if err := enc.WriteBlock(block); err != nil {
+			return fmt.Errorf("engine: writing rendered samples: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newBounceEncoder picks and opens the encoder.Encoder BounceToFile writes
+// through for format.
+func (e *Engine) newBounceEncoder(f *os.File, format FileFormat, channels int) (encoder.Encoder, error) {
+	switch format {
+	case FileFormatWAV:
+		return encoder.NewWAVWriter(f, encoder.WAVOptions{
+			SampleRate: int(e.spec.SampleRate),
+			Channels:   channels,
+			Float32:    true,
+		})
+	case FileFormatMP3:
+		return encoder.NewMP3Writer(f, encoder.MP3Options{
+			SampleRate: int(e.spec.SampleRate),
+			Channels:   channels,
+		})
+	default:
+		return nil, fmt.Errorf("engine: unsupported FileFormat %d (CAF and M4A are not yet implemented)", format)
+	}
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBounceToFileRejectsWithoutManualRendering(t *testing.T) {
+	spec := DefaultAudioSpec()
+	e, err := New(spec)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	path := t.TempDir() + "/out.wav"
+	if err := e.BounceToFile(path, FileFormatWAV); err == nil {
+		t.Fatal("expected BounceToFile to fail before EnableManualRendering was called")
+	}
+}
+
+func TestBounceToFileRejectsUnsupportedFormat(t *testing.T) {
+	spec := DefaultAudioSpec()
+	e, err := New(spec)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	format, err := e.NewStandardStereoFormat()
+	if err != nil {
+		t.Fatalf("NewStandardStereoFormat failed: %v", err)
+	}
+	defer format.Destroy()
+
+	if err := e.EnableManualRendering(ManualRenderingModeOffline, format, 1024); err != nil {
+		t.Fatalf("EnableManualRendering failed: %v", err)
+	}
+	defer e.DisableManualRendering()
+
+	path := t.TempDir() + "/out.caf"
+	if err := e.BounceToFile(path, FileFormat(99)); err == nil {
+		t.Fatal("expected BounceToFile to fail for an unsupported FileFormat")
+	}
+	if _, statErr := os.Stat(path); statErr == nil {
+		t.Error("expected no file to be left behind for an unsupported FileFormat")
+	}
+}
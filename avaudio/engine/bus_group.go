@@ -0,0 +1,342 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"unsafe"
+)
+
+// busGroupSilenceDB is the dB floor a BusGroup's level math clamps to
+// instead of actual negative infinity, mirroring the engine package's
+// Channel.SetVolumeDB -96dB convention.
+const busGroupSilenceDB = float32(-96)
+
+func busGroupLinearToDB(linear float32) float32 {
+	if linear <= 0 {
+		return busGroupSilenceDB
+	}
+	return float32(20 * math.Log10(float64(linear)))
+}
+
+func busGroupDBToLinear(db float32) float32 {
+	if db <= busGroupSilenceDB {
+		return 0
+	}
+	return float32(math.Pow(10, float64(db)/20))
+}
+
+func clampDB(db float32) float32 {
+	if db < busGroupSilenceDB {
+		return busGroupSilenceDB
+	}
+	if db > 0 {
+		return 0
+	}
+	return db
+}
+
+func clampPan(pan float32) float32 {
+	if pan < -1 {
+		return -1
+	}
+	if pan > 1 {
+		return 1
+	}
+	return pan
+}
+
+func floatsClose(a, b float32) bool {
+	const epsilon = float32(0.01)
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	return d < epsilon
+}
+
+type busGroupMemberKind int
+
+const (
+	busGroupMemberBus busGroupMemberKind = iota
+	busGroupMemberConnection
+)
+
+// busGroupMember is one (mixer, bus) or (source, mixer, bus) pair a BusGroup
+// drives. offsetDB is this member's level relative to the group's reference
+// level at the moment it was added; panBase is its pan at that same moment.
+// lastDB records whatever level the group itself last applied (or last saw
+// via the mixer listener), so onVolumeChanged can tell an out-of-band change
+// from an echo of the group's own write.
+type busGroupMember struct {
+	kind      busGroupMemberKind
+	sourcePtr unsafe.Pointer // only set for busGroupMemberConnection
+	mixerPtr  unsafe.Pointer
+	bus       int
+	offsetDB  float32
+	lastDB    float32
+	panBase   float32
+}
+
+// busGroupMixerListener adapts BusGroup.observeVolumeChanged to
+// MixerListener for one of the group's distinct mixers - BusGroup registers
+// one of these per mixer among its members, not one per member, since
+// RegisterMixerListener is already scoped per mixer pointer.
+type busGroupMixerListener struct {
+	g        *BusGroup
+	mixerPtr unsafe.Pointer
+}
+
+func (l busGroupMixerListener) OnVolumeChanged(bus int, volume float32) {
+	l.g.observeVolumeChanged(l.mixerPtr, bus, volume)
+}
+
+func (l busGroupMixerListener) OnPanChanged(bus int, pan float32) {}
+
+// BusGroup is a VCA-style group fader: Add/AddConnection register (mixer,
+// bus) pairs as members, each remembering its level relative to the group's
+// own reference level at the moment it was added. SetVolume then moves every
+// member by the same dB amount rather than to the same absolute level,
+// preserving whatever balance existed between members when they joined -
+// exactly how a VCA fader behaves on a physical console. Construct one with
+// Engine.NewBusGroup.
+//
+// A member's out-of-band volume change (made through
+// Engine.SetMixerVolumeForBus/SetConnectionVolume directly, not through this
+// group) is picked up via Engine.RegisterMixerListener and rebases that
+// member's offset, so a later SetVolume doesn't fight whatever changed it -
+// see observeVolumeChanged.
+type BusGroup struct {
+	eng *Engine
+
+	mu          sync.Mutex
+	members     []*busGroupMember
+	referenceDB float32
+	muted       bool
+	preMuteDB   float32
+	panOffset   float32
+	cancels     map[unsafe.Pointer]func() // mixerPtr -> RegisterMixerListener's cancel
+}
+
+// NewBusGroup creates an empty BusGroup bound to e, with its reference level
+// at unity (0dB) until the first SetVolume call.
+func (e *Engine) NewBusGroup() *BusGroup {
+	return &BusGroup{eng: e, cancels: make(map[unsafe.Pointer]func())}
+}
+
+// Add registers (mixerPtr, bus) as a group member, driven through
+// Engine.SetMixerVolumeForBus/GetMixerVolumeForBus and
+// Engine.SetMixerPanForBus/GetMixerPanForBus.
+func (g *BusGroup) Add(mixerPtr unsafe.Pointer, bus int) error {
+	return g.addMember(busGroupMemberBus, nil, mixerPtr, bus)
+}
+
+// AddConnection registers the sourcePtr->mixerPtr connection on bus as a
+// group member, driven through Engine.SetConnectionVolume/GetConnectionVolume
+// and Engine.SetConnectionPan/GetConnectionPan instead of the per-bus API.
+func (g *BusGroup) AddConnection(sourcePtr, mixerPtr unsafe.Pointer, bus int) error {
+	return g.addMember(busGroupMemberConnection, sourcePtr, mixerPtr, bus)
+}
+
+func (g *BusGroup) addMember(kind busGroupMemberKind, sourcePtr, mixerPtr unsafe.Pointer, bus int) error {
+	volume, err := g.getMemberVolume(kind, sourcePtr, mixerPtr, bus)
+	if err != nil {
+		return err
+	}
+	pan, err := g.getMemberPan(kind, sourcePtr, mixerPtr, bus)
+	if err != nil {
+		return err
+	}
+	db := busGroupLinearToDB(volume)
+
+	g.mu.Lock()
+	g.members = append(g.members, &busGroupMember{
+		kind:      kind,
+		sourcePtr: sourcePtr,
+		mixerPtr:  mixerPtr,
+		bus:       bus,
+		offsetDB:  db - g.referenceDB,
+		lastDB:    db,
+		panBase:   pan,
+	})
+	g.mu.Unlock()
+
+	g.watchMixer(mixerPtr)
+	return nil
+}
+
+// watchMixer registers a mixer listener on mixerPtr the first time any
+// member of g lives on it, so out-of-band volume changes on any of its buses
+// can be matched against g's members.
+func (g *BusGroup) watchMixer(mixerPtr unsafe.Pointer) {
+	g.mu.Lock()
+	_, already := g.cancels[mixerPtr]
+	g.mu.Unlock()
+	if already {
+		return
+	}
+
+	cancel := g.eng.RegisterMixerListener(mixerPtr, busGroupMixerListener{g: g, mixerPtr: mixerPtr})
+
+	g.mu.Lock()
+	g.cancels[mixerPtr] = cancel
+	g.mu.Unlock()
+}
+
+// observeVolumeChanged rebases a member's offset when mixerPtr/bus's volume
+// changed to something g didn't itself just apply - an out-of-band
+// SetMixerVolumeForBus/SetConnectionVolume call, another control surface, a
+// fade. An echo of g's own last write (same dB value, within epsilon) is
+// ignored, so SetVolume doesn't retrigger itself through its own listener.
+func (g *BusGroup) observeVolumeChanged(mixerPtr unsafe.Pointer, bus int, volume float32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	db := busGroupLinearToDB(volume)
+	for _, m := range g.members {
+		if m.mixerPtr != mixerPtr || m.bus != bus {
+			continue
+		}
+		if floatsClose(db, m.lastDB) {
+			continue
+		}
+		m.offsetDB = db - g.referenceDB
+		m.lastDB = db
+	}
+}
+
+func (g *BusGroup) getMemberVolume(kind busGroupMemberKind, sourcePtr, mixerPtr unsafe.Pointer, bus int) (float32, error) {
+	if kind == busGroupMemberConnection {
+		return g.eng.GetConnectionVolume(sourcePtr, mixerPtr, bus)
+	}
+	return g.eng.GetMixerVolumeForBus(mixerPtr, bus)
+}
+
+func (g *BusGroup) getMemberPan(kind busGroupMemberKind, sourcePtr, mixerPtr unsafe.Pointer, bus int) (float32, error) {
+	if kind == busGroupMemberConnection {
+		return g.eng.GetConnectionPan(sourcePtr, mixerPtr, bus)
+	}
+	return g.eng.GetMixerPanForBus(mixerPtr, bus)
+}
+
+func (g *BusGroup) applyMemberVolume(m *busGroupMember, linear float32) error {
+	if m.kind == busGroupMemberConnection {
+		return g.eng.SetConnectionVolume(m.sourcePtr, m.mixerPtr, m.bus, linear)
+	}
+	return g.eng.SetMixerVolumeForBus(m.mixerPtr, linear, m.bus)
+}
+
+func (g *BusGroup) applyMemberPan(m *busGroupMember, pan float32) error {
+	if m.kind == busGroupMemberConnection {
+		return g.eng.SetConnectionPan(m.sourcePtr, m.mixerPtr, m.bus, pan)
+	}
+	return g.eng.SetMixerPanForBus(m.mixerPtr, pan, m.bus)
+}
+
+// SetVolume moves the group's fader to v (0.0-1.0 linear), applying each
+// member's remembered dB offset on top of v and clamping the result back to
+// 0.0-1.0 before writing it - a member near the ceiling or floor when added
+// may compress toward it rather than moving by the group's full amount,
+// matching how a VCA group fader behaves on a console when a member hits
+// its own trim limits.
+func (g *BusGroup) SetVolume(v float32) error {
+	if v < 0 || v > 1 {
+		return errors.New("engine: bus group volume must be between 0.0 and 1.0")
+	}
+
+	g.mu.Lock()
+	g.muted = false
+	g.referenceDB = busGroupLinearToDB(v)
+	referenceDB := g.referenceDB
+	members := append([]*busGroupMember(nil), g.members...)
+	g.mu.Unlock()
+
+	var firstErr error
+	for _, m := range members {
+		db := clampDB(referenceDB + m.offsetDB)
+		if err := g.applyMemberVolume(m, busGroupDBToLinear(db)); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		g.mu.Lock()
+		m.lastDB = db
+		g.mu.Unlock()
+	}
+	return firstErr
+}
+
+// SetMute silences every member at its current level when true, and
+// restores each to where SetVolume last left it when false - the
+// Channel.Mute/Unmute remembered-level pattern, applied to every member of
+// the group at once.
+func (g *BusGroup) SetMute(mute bool) error {
+	g.mu.Lock()
+	if mute == g.muted {
+		g.mu.Unlock()
+		return nil
+	}
+	g.muted = mute
+	if mute {
+		g.preMuteDB = g.referenceDB
+	} else {
+		g.referenceDB = g.preMuteDB
+	}
+	referenceDB := g.referenceDB
+	members := append([]*busGroupMember(nil), g.members...)
+	g.mu.Unlock()
+
+	var firstErr error
+	for _, m := range members {
+		db := busGroupSilenceDB
+		if !mute {
+			db = clampDB(referenceDB + m.offsetDB)
+		}
+		if err := g.applyMemberVolume(m, busGroupDBToLinear(db)); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		g.mu.Lock()
+		m.lastDB = db
+		g.mu.Unlock()
+	}
+	return firstErr
+}
+
+// SetPanOffset shifts every member's pan by offset relative to its own pan
+// at the moment it was added (not relative to whatever pan it has now),
+// clamping each result to -1.0-1.0.
+func (g *BusGroup) SetPanOffset(offset float32) error {
+	if offset < -1 || offset > 1 {
+		return errors.New("engine: pan offset must be between -1.0 and 1.0")
+	}
+
+	g.mu.Lock()
+	g.panOffset = offset
+	members := append([]*busGroupMember(nil), g.members...)
+	g.mu.Unlock()
+
+	var firstErr error
+	for _, m := range members {
+		pan := clampPan(m.panBase + offset)
+		if err := g.applyMemberPan(m, pan); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close stops the mixer listeners g installed via Add/AddConnection. A
+// BusGroup that's simply dropped without calling Close leaks those listener
+// goroutines (see Engine.RegisterMixerListener) for as long as its Engine
+// runs.
+func (g *BusGroup) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for mixerPtr, cancel := range g.cancels {
+		cancel()
+		delete(g.cancels, mixerPtr)
+	}
+}
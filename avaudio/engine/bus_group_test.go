@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusGroupSetVolumePreservesRelativeOffset(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 1.0, 0); err != nil {
+		t.Fatalf("Failed to set bus 0 volume: %v", err)
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.5, 1); err != nil {
+		t.Fatalf("Failed to set bus 1 volume: %v", err)
+	}
+
+	g := eng.NewBusGroup()
+	defer g.Close()
+	if err := g.Add(mixerPtr, 0); err != nil {
+		t.Fatalf("Add bus 0 failed: %v", err)
+	}
+	if err := g.Add(mixerPtr, 1); err != nil {
+		t.Fatalf("Add bus 1 failed: %v", err)
+	}
+
+	// Pull the group fader down 6dB (~0.5 linear from unity); both members
+	// should drop by the same dB amount, preserving their relative balance.
+	if err := g.SetVolume(0.5); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+
+	v0, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus(0) failed: %v", err)
+	}
+	v1, err := eng.GetMixerVolumeForBus(mixerPtr, 1)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus(1) failed: %v", err)
+	}
+
+	if v0 < 0.45 || v0 > 0.55 {
+		t.Errorf("expected bus 0 to land near 0.5 (was at unity), got %v", v0)
+	}
+	ratio := v1 / v0
+	if ratio < 0.45 || ratio > 0.55 {
+		t.Errorf("expected bus 1 to stay ~half of bus 0 (was half when added), got ratio %v (v0=%v v1=%v)", ratio, v0, v1)
+	}
+}
+
+func TestBusGroupSetMuteRestoresVolume(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.8, 0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	g := eng.NewBusGroup()
+	defer g.Close()
+	if err := g.Add(mixerPtr, 0); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := g.SetVolume(0.8); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+
+	if err := g.SetMute(true); err != nil {
+		t.Fatalf("SetMute(true) failed: %v", err)
+	}
+	v, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil || v != 0 {
+		t.Errorf("expected volume 0 while muted, got %v (err %v)", v, err)
+	}
+
+	if err := g.SetMute(false); err != nil {
+		t.Fatalf("SetMute(false) failed: %v", err)
+	}
+	v, err = eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil || v < 0.75 || v > 0.85 {
+		t.Errorf("expected volume restored to ~0.8 after unmute, got %v (err %v)", v, err)
+	}
+}
+
+func TestBusGroupObservesOutOfBandVolumeChange(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 1.0, 0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	g := eng.NewBusGroup()
+	defer g.Close()
+	if err := g.Add(mixerPtr, 0); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// An out-of-band change, bypassing the group entirely.
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.25, 0); err != nil {
+		t.Fatalf("Failed to set volume out of band: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond) // let the mixer listener goroutine observe it
+
+	// SetVolume at unity should now leave the member at its rebased level
+	// (0.25) rather than snapping it back to 1.0.
+	if err := g.SetVolume(1.0); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+	v, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus failed: %v", err)
+	}
+	if v < 0.2 || v > 0.3 {
+		t.Errorf("expected the out-of-band change to rebase the member's offset to ~0.25, got %v", v)
+	}
+}
@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"unsafe"
+)
+
+// commandQueueDepth bounds how many pending Commit batches may queue up
+// before Commit starts reporting the queue as full. Deep enough to absorb a
+// burst of graph edits (loading a whole session's worth of Commit calls
+// back-to-back) without the caller blocking, shallow enough that a stuck
+// drain goroutine (see runCommandQueue) doesn't hide for long.
+const commandQueueDepth = 64
+
+// graphCommandKind enumerates the graph mutations GraphCommand can carry.
+type graphCommandKind int
+
+const (
+	graphCommandAttach graphCommandKind = iota
+	graphCommandConnect
+	graphCommandDisconnectInput
+	graphCommandDisconnectOutput
+	graphCommandSetMixerVolume
+)
+
+// GraphCommand is one graph mutation queued for Engine.Commit. Build one
+// with AttachCommand, ConnectCommand, DisconnectInputCommand,
+// DisconnectOutputCommand, or SetMixerVolumeCommand rather than constructing
+// this struct directly - its fields are deliberately unexported since which
+// ones apply depends on kind.
+type GraphCommand struct {
+	kind               graphCommandKind
+	nodePtr            unsafe.Pointer
+	sourcePtr, destPtr unsafe.Pointer
+	fromBus, toBus     int
+	inputBus           int
+	outputBus          int
+	volume             float32
+}
+
+// AttachCommand builds a Commit batch entry equivalent to Engine.Attach.
+func AttachCommand(nodePtr unsafe.Pointer) GraphCommand {
+	return GraphCommand{kind: graphCommandAttach, nodePtr: nodePtr}
+}
+
+// ConnectCommand builds a Commit batch entry equivalent to Engine.Connect.
+func ConnectCommand(sourcePtr, destPtr unsafe.Pointer, fromBus, toBus int) GraphCommand {
+	return GraphCommand{kind: graphCommandConnect, sourcePtr: sourcePtr, destPtr: destPtr, fromBus: fromBus, toBus: toBus}
+}
+
+// DisconnectInputCommand builds a Commit batch entry equivalent to
+// Engine.DisconnectNodeInput.
+func DisconnectInputCommand(nodePtr unsafe.Pointer, inputBus int) GraphCommand {
+	return GraphCommand{kind: graphCommandDisconnectInput, nodePtr: nodePtr, inputBus: inputBus}
+}
+
+// DisconnectOutputCommand builds a Commit batch entry equivalent to
+// Engine.DisconnectNodeOutput.
+func DisconnectOutputCommand(nodePtr unsafe.Pointer, outputBus int) GraphCommand {
+	return GraphCommand{kind: graphCommandDisconnectOutput, nodePtr: nodePtr, outputBus: outputBus}
+}
+
+// SetMixerVolumeCommand builds a Commit batch entry equivalent to
+// Engine.SetMixerVolume.
+func SetMixerVolumeCommand(mixerPtr unsafe.Pointer, volume float32) GraphCommand {
+	return GraphCommand{kind: graphCommandSetMixerVolume, nodePtr: mixerPtr, volume: volume}
+}
+
+// apply runs cmd against e via the same exported method Commit is standing
+// in front of, so a queued AttachCommand behaves identically to calling
+// e.Attach directly - same validation, same error strings.
+func (e *Engine) apply(cmd GraphCommand) error {
+	switch cmd.kind {
+	case graphCommandAttach:
+		return e.Attach(cmd.nodePtr)
+	case graphCommandConnect:
+		return e.Connect(cmd.sourcePtr, cmd.destPtr, cmd.fromBus, cmd.toBus)
+	case graphCommandDisconnectInput:
+		return e.DisconnectNodeInput(cmd.nodePtr, cmd.inputBus)
+	case graphCommandDisconnectOutput:
+		return e.DisconnectNodeOutput(cmd.nodePtr, cmd.outputBus)
+	case graphCommandSetMixerVolume:
+		return e.SetMixerVolume(cmd.nodePtr, cmd.volume)
+	default:
+		return fmt.Errorf("engine: unknown graph command kind %d", cmd.kind)
+	}
+}
+
+// commandBatch is one Commit call's worth of GraphCommands, handed to
+// runCommandQueue and reported back on done once applied.
+type commandBatch struct {
+	cmds []GraphCommand
+	done chan error
+}
+
+// CommandQueueStats reports how Engine's command queue (see Commit) has
+// been used: how many commands have been queued, how many actually applied,
+// and how many were dropped because the queue was full when Commit was
+// called. Dropped commands are never applied - Commit returns an error for
+// that batch instead of partially queuing it.
+type CommandQueueStats struct {
+	Queued  uint64
+	Applied uint64
+	Dropped uint64
+}
+
+// CommandQueueStats returns a snapshot of e's command queue counters.
+func (e *Engine) CommandQueueStats() CommandQueueStats {
+	return CommandQueueStats{
+		Queued:  atomic.LoadUint64(&e.cmdStats.Queued),
+		Applied: atomic.LoadUint64(&e.cmdStats.Applied),
+		Dropped: atomic.LoadUint64(&e.cmdStats.Dropped),
+	}
+}
+
+// ensureCommandQueue lazily starts the dedicated drain goroutine the first
+// time Commit is called, mirroring pluginchain's automation-driver
+// lazy-start (see pluginchain.PluginChain.ensureAutomationDriver) - an
+// Engine that never calls Commit never pays for the goroutine.
+func (e *Engine) ensureCommandQueue() {
+	e.cmdQueueOnce.Do(func() {
+		e.cmdQueueCh = make(chan commandBatch, commandQueueDepth)
+		e.cmdQueueDone = make(chan struct{})
+		e.cmdQueueWG.Add(1)
+		go e.runCommandQueue()
+	})
+}
+
+// runCommandQueue is the single goroutine that ever applies GraphCommands to
+// the native graph on Engine's Commit path - Commit's caller only ever
+// enqueues a batch and waits for its result, so concurrent Commit calls
+// never race each other inside the native layer.
+func (e *Engine) runCommandQueue() {
+	defer e.cmdQueueWG.Done()
+	for {
+		select {
+		case <-e.cmdQueueDone:
+			return
+		case batch := <-e.cmdQueueCh:
+			var err error
+			for _, cmd := range batch.cmds {
+				if err = e.apply(cmd); err != nil {
+					break
+				}
+				atomic.AddUint64(&e.cmdStats.Applied, 1)
+			}
+			batch.done <- err
+		}
+	}
+}
+
+// Commit applies cmds as one batch on Engine's dedicated command-queue
+// goroutine (see runCommandQueue) and blocks until the whole batch has run,
+// returning the first command's error if one fails partway through.
+//
+// "Batch" here means cmds are applied in order without another Commit's
+// commands interleaving between them - it does not mean a failure rolls
+// back commands already applied earlier in the same batch. AVAudioEngine
+// has no transactional undo for attach/connect/disconnect, so a Commit that
+// fails on its third command leaves the first two applied; the returned
+// error is the one that stopped the batch.
+//
+// If the queue is already full (more Commit calls in flight than
+// commandQueueDepth), Commit returns an error immediately without queuing
+// or applying any part of cmds, and CommandQueueStats().Dropped is
+// incremented by len(cmds).
+func (e *Engine) Commit(cmds ...GraphCommand) error {
+	if e == nil {
+		return errors.New("engine is nil")
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	e.ensureCommandQueue()
+
+	batch := commandBatch{cmds: cmds, done: make(chan error, 1)}
+	select {
+	case e.cmdQueueCh <- batch:
+		atomic.AddUint64(&e.cmdStats.Queued, uint64(len(cmds)))
+	default:
+		atomic.AddUint64(&e.cmdStats.Dropped, uint64(len(cmds)))
+		return fmt.Errorf("engine: command queue is full (depth %d)", commandQueueDepth)
+	}
+
+	return <-batch.done
+}
+
+// stopCommandQueue shuts down the drain goroutine started by
+// ensureCommandQueue, if one was ever started. Called from Destroy so an
+// Engine that used Commit doesn't leak its drain goroutine.
+func (e *Engine) stopCommandQueue() {
+	if e.cmdQueueDone == nil {
+		return
+	}
+	select {
+	case <-e.cmdQueueDone:
+		// already closed
+	default:
+		close(e.cmdQueueDone)
+	}
+	e.cmdQueueWG.Wait()
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+)
+
+func TestCommitAppliesAttachConnectVolumeAsOneBatch(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	tone, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("NewTone failed: %v", err)
+	}
+	defer tone.Destroy()
+
+	nodePtr, err := tone.GetNodePtr()
+	if err != nil {
+		t.Fatalf("GetNodePtr failed: %v", err)
+	}
+	mainMixer, err := e.MainMixerNode()
+	if err != nil {
+		t.Fatalf("MainMixerNode failed: %v", err)
+	}
+
+	err = e.Commit(
+		AttachCommand(nodePtr),
+		ConnectCommand(nodePtr, mainMixer, 0, 0),
+		SetMixerVolumeCommand(mainMixer, 0.5),
+	)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	stats := e.CommandQueueStats()
+	if stats.Applied != 3 {
+		t.Errorf("expected 3 applied commands, got %d", stats.Applied)
+	}
+	if stats.Dropped != 0 {
+		t.Errorf("expected 0 dropped commands, got %d", stats.Dropped)
+	}
+}
+
+func TestCommitStopsFirstFailingCommand(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	// Connecting a never-attached node should fail, and stop the batch
+	// before the volume command after it ever runs.
+	err = e.Commit(
+		ConnectCommand(nil, nil, 0, 0),
+		SetMixerVolumeCommand(nil, 0.5),
+	)
+	if err == nil {
+		t.Fatal("expected Commit to fail on the invalid Connect command")
+	}
+
+	stats := e.CommandQueueStats()
+	if stats.Applied != 0 {
+		t.Errorf("expected 0 applied commands, got %d", stats.Applied)
+	}
+}
+
+func TestDestroyStopsCommandQueueGoroutine(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := e.Commit(SetMixerVolumeCommand(nil, 0.5)); err == nil {
+		t.Fatal("expected Commit to fail applying SetMixerVolume to a nil pointer")
+	}
+
+	before := runtime.NumGoroutine()
+	e.Destroy()
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before {
+		t.Errorf("expected Destroy to stop the command queue goroutine, goroutines went from %d to %d", before, after)
+	}
+}
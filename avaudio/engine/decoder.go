@@ -0,0 +1,128 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+#include <stdlib.h>
+
+// Function declaration - schedules a decoded PCM buffer for playback instead
+// of handing AVAudioFile a path it can open itself. samples is interleaved
+// float32, laid out channel-major per frame.
+const char* audioplayer_schedule_pcm_buffer(AudioPlayer* player, float* samples, int frameCount, int channelCount, double sampleRate, bool loop);
+*/
+import "C"
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// PCMBuffer holds fully decoded, interleaved PCM audio ready to schedule on
+// an AudioPlayer. Decoders always produce float32 samples in [-1, 1],
+// matching AVAudioPCMBuffer's native float format.
+type PCMBuffer struct {
+	Samples      []float32 // interleaved, channelCount samples per frame
+	FrameCount   int
+	ChannelCount int
+	SampleRate   float64
+}
+
+// Decoder decodes an entire audio file into a PCMBuffer. Implementations are
+// registered by file extension via RegisterDecoder; LoadFile consults the
+// registry before falling back to AVAudioFile's built-in format support.
+type Decoder interface {
+	Decode(path string) (PCMBuffer, error)
+}
+
+var (
+	decoderMu sync.RWMutex
+	decoders  = map[string]Decoder{}
+)
+
+// RegisterDecoder associates a Decoder with a file extension (e.g. ".mp3",
+// matched case-insensitively, leading dot optional). Registering the same
+// extension twice replaces the previous decoder, so a host application can
+// swap in its own MP3/FLAC/OGG implementation over a default one.
+func RegisterDecoder(ext string, dec Decoder) {
+	decoderMu.Lock()
+	defer decoderMu.Unlock()
+	decoders[normalizeExt(ext)] = dec
+}
+
+func lookupDecoder(ext string) (Decoder, bool) {
+	decoderMu.RLock()
+	defer decoderMu.RUnlock()
+	dec, ok := decoders[normalizeExt(ext)]
+	return dec, ok
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// LoadFileStreaming loads filePath the same way as LoadFile, but if a
+// Decoder is registered for its extension, it decodes through that Decoder
+// and schedules the resulting PCMBuffer on the player instead of relying on
+// AVAudioFile. loop repeats the buffer indefinitely once scheduled, which is
+// how Streaming playback is kept gapless for formats AVAudioFile can't open
+// directly (MP3/FLAC/OGG via a registered Decoder).
+func (p *AudioPlayer) LoadFileStreaming(filePath string, loop bool) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+
+	ext := filepath.Ext(filePath)
+	dec, ok := lookupDecoder(ext)
+	if !ok {
+		if loop {
+			return errors.New("no decoder registered for " + ext + "; loop requires a registered Decoder")
+		}
+		return p.LoadFile(filePath)
+	}
+
+	buf, err := dec.Decode(filePath)
+	if err != nil {
+		return err
+	}
+	return p.schedulePCMBuffer(buf, loop)
+}
+
+// ScheduleBuffer schedules buf for playback on p, appending after any
+// buffer already scheduled (AVAudioPlayerNode queues scheduled buffers in
+// call order). Unlike LoadFileStreaming's one-shot/looping use of
+// schedulePCMBuffer, ScheduleBuffer is exported for streaming decoders
+// (see macaudio.Decoder) that produce PCMBuffer-shaped blocks one at a time
+// as a source is read, rather than a whole file's worth up front.
+func (p *AudioPlayer) ScheduleBuffer(buf PCMBuffer, loop bool) error {
+	return p.schedulePCMBuffer(buf, loop)
+}
+
+func (p *AudioPlayer) schedulePCMBuffer(buf PCMBuffer, loop bool) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if len(buf.Samples) == 0 {
+		return errors.New("decoded buffer is empty")
+	}
+
+	result := C.audioplayer_schedule_pcm_buffer(
+		p.ptr,
+		(*C.float)(unsafe.Pointer(&buf.Samples[0])),
+		C.int(buf.FrameCount),
+		C.int(buf.ChannelCount),
+		C.double(buf.SampleRate),
+		C.bool(loop),
+	)
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
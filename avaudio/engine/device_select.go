@@ -0,0 +1,112 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+#include <stdlib.h>
+// Function declarations for CGO
+const char* audioengine_set_output_device(AudioEngine* wrapper, const char* deviceUID);
+const char* audioengine_set_input_device(AudioEngine* wrapper, const char* deviceUID);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// NewWithDevice creates an Engine the same way New does, then explicitly
+// binds its output and/or input to the device with the given UID (as
+// reported by Devices) instead of leaving both on the system default.
+// Pass "" for either UID to leave that side on the default device.
+func NewWithDevice(spec AudioSpec, outputUID, inputUID string) (*Engine, error) {
+	e, err := New(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if outputUID != "" {
+		if err := e.SetOutputDevice(outputUID); err != nil {
+			e.Destroy()
+			return nil, fmt.Errorf("selecting output device %q: %w", outputUID, err)
+		}
+	}
+	if inputUID != "" {
+		if err := e.SetInputDevice(inputUID); err != nil {
+			e.Destroy()
+			return nil, fmt.Errorf("selecting input device %q: %w", inputUID, err)
+		}
+	}
+	return e, nil
+}
+
+// SetOutputDevice switches the engine's output hardware to the device with
+// the given UID, looked up from Devices. AVAudioEngine exposes one output
+// AudioUnit shared by every node connected to OutputNode, so this affects
+// the whole graph rather than a single node.
+func (e *Engine) SetOutputDevice(uid string) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if err := validateDeviceUID(uid, false); err != nil {
+		return err
+	}
+
+	cUID := C.CString(uid)
+	defer C.free(unsafe.Pointer(cUID))
+
+	if errStr := C.audioengine_set_output_device(e.ptr, cUID); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	e.currentOutputDeviceUID = uid
+	return nil
+}
+
+// SetInputDevice switches the engine's input hardware to the device with
+// the given UID. Like SetOutputDevice, this binds the engine's single
+// shared InputNode rather than an individual node - AVAudioEngine doesn't
+// support routing different nodes to different physical input devices
+// without an aggregate device, so there is no true per-node input binding
+// beneath this.
+func (e *Engine) SetInputDevice(uid string) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if err := validateDeviceUID(uid, true); err != nil {
+		return err
+	}
+
+	cUID := C.CString(uid)
+	defer C.free(unsafe.Pointer(cUID))
+
+	if errStr := C.audioengine_set_input_device(e.ptr, cUID); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	e.currentInputDeviceUID = uid
+	return nil
+}
+
+// validateDeviceUID confirms uid names a known device with the right
+// direction's channels before handing it to the native layer, so a typo'd
+// UID fails with a clear Go error instead of an opaque native one.
+func validateDeviceUID(uid string, forInput bool) error {
+	available, err := Devices()
+	if err != nil {
+		return err
+	}
+	for _, d := range available {
+		if d.UID != uid {
+			continue
+		}
+		if forInput && d.MaxInputChannels == 0 {
+			return fmt.Errorf("device %q has no input channels", uid)
+		}
+		if !forInput && d.MaxOutputChannels == 0 {
+			return fmt.Errorf("device %q has no output channels", uid)
+		}
+		return nil
+	}
+	return fmt.Errorf("no device with UID %q", uid)
+}
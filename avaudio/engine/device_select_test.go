@@ -0,0 +1,119 @@
+package engine
+
+import "testing"
+
+// TestNewWithDeviceNonDefaultOutput verifies that an engine created with an
+// explicitly chosen non-default output device still wires up and produces
+// measurable output on the chosen bus. It skips when the machine only
+// exposes one output-capable device, since there's nothing non-default to
+// select in that case.
+func TestNewWithDeviceNonDefaultOutput(t *testing.T) {
+	available, err := Devices()
+	if err != nil {
+		t.Fatalf("Devices failed: %v", err)
+	}
+
+	var defaultUID, altUID string
+	for _, d := range available {
+		if d.MaxOutputChannels == 0 {
+			continue
+		}
+		if d.IsDefaultOutput {
+			defaultUID = d.UID
+			continue
+		}
+		if altUID == "" {
+			altUID = d.UID
+		}
+	}
+	if altUID == "" || altUID == defaultUID {
+		t.Skip("skipping: only one output-capable device present")
+	}
+
+	spec := DefaultAudioSpec()
+	e, err := NewWithDevice(spec, altUID, "")
+	if err != nil {
+		t.Fatalf("NewWithDevice failed: %v", err)
+	}
+	defer e.Destroy()
+
+	mainMixer, err := e.MainMixerNode()
+	if err != nil {
+		t.Fatalf("MainMixerNode failed: %v", err)
+	}
+	outputNode, err := e.OutputNode()
+	if err != nil {
+		t.Fatalf("OutputNode failed: %v", err)
+	}
+	if err := e.Connect(mainMixer, outputNode, 0, 0); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := e.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+}
+
+func TestSetOutputDeviceRejectsUnknownUID(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	if err := e.SetOutputDevice("not-a-real-device-uid"); err == nil {
+		t.Fatal("expected error selecting an unknown device UID")
+	}
+}
+
+func TestEnumerateDevicesMatchesDevices(t *testing.T) {
+	fromEnumerate, err := EnumerateDevices()
+	if err != nil {
+		t.Fatalf("EnumerateDevices failed: %v", err)
+	}
+	fromDevices, err := Devices()
+	if err != nil {
+		t.Fatalf("Devices failed: %v", err)
+	}
+	if len(fromEnumerate) != len(fromDevices) {
+		t.Fatalf("EnumerateDevices returned %d devices, Devices returned %d", len(fromEnumerate), len(fromDevices))
+	}
+}
+
+func TestEnrichDeviceChangeEventFlagsSelectedDeviceLoss(t *testing.T) {
+	available, err := Devices()
+	if err != nil {
+		t.Fatalf("Devices failed: %v", err)
+	}
+	var altUID string
+	for _, d := range available {
+		if d.MaxOutputChannels > 0 && !d.IsDefaultOutput {
+			altUID = d.UID
+			break
+		}
+	}
+	if altUID == "" {
+		t.Skip("skipping: only one output-capable device present")
+	}
+
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+	if err := e.SetOutputDevice(altUID); err != nil {
+		t.Fatalf("SetOutputDevice failed: %v", err)
+	}
+
+	event := e.enrichDeviceChangeEvent(DeviceChangeEvent{Removed: []DeviceInfo{{UID: altUID}}})
+	if !event.OutputDisconnected {
+		t.Error("expected OutputDisconnected when the engine's selected output device is removed")
+	}
+	if event.InputDisconnected {
+		t.Error("expected InputDisconnected to stay false - the engine never selected an input device")
+	}
+
+	unrelated := e.enrichDeviceChangeEvent(DeviceChangeEvent{Removed: []DeviceInfo{{UID: "some-other-device"}}})
+	if unrelated.OutputDisconnected {
+		t.Error("expected OutputDisconnected to stay false when the removed device isn't the one selected")
+	}
+}
@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// DeviceInfo summarizes one audio device's capabilities for stream setup,
+// distilled from devices.AudioDevice down to what picking an input/output
+// device for an Engine actually needs.
+type DeviceInfo struct {
+	ID                int
+	Name              string
+	UID               string
+	SampleRates       []int
+	MaxInputChannels  int
+	MaxOutputChannels int
+	DefaultLatency    time.Duration
+	IsDefaultInput    bool
+	IsDefaultOutput   bool
+}
+
+func deviceInfoFromAudioDevice(d devices.AudioDevice) DeviceInfo {
+	return DeviceInfo{
+		ID:                d.DeviceID,
+		Name:              d.Name,
+		UID:               d.UID,
+		SampleRates:       d.SupportedSampleRates,
+		MaxInputChannels:  d.InputChannelCount,
+		MaxOutputChannels: d.OutputChannelCount,
+		IsDefaultInput:    d.IsDefaultInput,
+		IsDefaultOutput:   d.IsDefaultOutput,
+	}
+}
+
+// Devices lists the available audio devices, both input- and output-capable.
+// It goes through devices.GetAudio (so devices.SetAudioBackend still applies
+// when testing headless), distilled to the fields relevant to picking an
+// Engine's input/output device.
+func Devices() ([]DeviceInfo, error) {
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]DeviceInfo, len(audioDevices))
+	for i, d := range audioDevices {
+		infos[i] = deviceInfoFromAudioDevice(d)
+	}
+	return infos, nil
+}
+
+// EnumerateDevices is an alias for Devices, named to match callers building
+// a "select interface" UI that want an explicit enumerate verb rather than a
+// plain noun.
+func EnumerateDevices() ([]DeviceInfo, error) {
+	return Devices()
+}
+
+// DeviceChangeEvent describes a change observed between two successive
+// device polls; see OnDeviceChange.
+type DeviceChangeEvent struct {
+	Added   []DeviceInfo
+	Removed []DeviceInfo
+
+	// InputDisconnected and OutputDisconnected are set by
+	// Engine.OnDeviceChange when Removed contains the device that engine
+	// last selected via SetInputDevice/SetOutputDevice. They're always
+	// false on events delivered through the package-level OnDeviceChange,
+	// which has no particular engine to check against.
+	InputDisconnected  bool
+	OutputDisconnected bool
+
+	// SampleRateMismatch is set by Engine.OnDeviceChange when the system's
+	// current default output device no longer offers the engine's
+	// AudioSpec.SampleRate - e.g. a USB interface running at 96kHz
+	// disappeared and the system fell back to built-in audio at 44.1kHz.
+	SampleRateMismatch bool
+}
+
+// deviceWatch is the process-wide polling loop backing OnDeviceChange. There
+// is no CoreAudio property-listener binding wired up to Go yet, so this
+// polls devices.GetAudio on an interval and diffs by UID rather than
+// reacting to hardware notifications immediately.
+type deviceWatch struct {
+	mu        sync.Mutex
+	listeners []func(DeviceChangeEvent)
+	last      map[string]DeviceInfo
+	stop      chan struct{}
+	started   bool
+}
+
+var watch = &deviceWatch{}
+
+// OnDeviceChange registers fn to be called when the set of available audio
+// devices changes, and starts the background poll the first time it's
+// called. The poll interval is 2 seconds; this is meant for reacting to a
+// user plugging/unplugging an interface, not low-latency device switching.
+func OnDeviceChange(fn func(DeviceChangeEvent)) {
+	watch.mu.Lock()
+	defer watch.mu.Unlock()
+
+	watch.listeners = append(watch.listeners, fn)
+	if watch.started {
+		return
+	}
+	watch.started = true
+	watch.stop = make(chan struct{})
+	go watch.run()
+}
+
+func (w *deviceWatch) run() {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *deviceWatch) poll() {
+	current, err := Devices()
+	if err != nil {
+		return
+	}
+
+	byUID := make(map[string]DeviceInfo, len(current))
+	for _, d := range current {
+		byUID[d.UID] = d
+	}
+
+	w.mu.Lock()
+	if w.last == nil {
+		w.last = byUID
+		w.mu.Unlock()
+		return
+	}
+
+	var event DeviceChangeEvent
+	for uid, d := range byUID {
+		if _, ok := w.last[uid]; !ok {
+			event.Added = append(event.Added, d)
+		}
+	}
+	for uid, d := range w.last {
+		if _, ok := byUID[uid]; !ok {
+			event.Removed = append(event.Removed, d)
+		}
+	}
+	w.last = byUID
+	listeners := append([]func(DeviceChangeEvent){}, w.listeners...)
+	w.mu.Unlock()
+
+	if len(event.Added) == 0 && len(event.Removed) == 0 {
+		return
+	}
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+// OnDeviceChange registers fn to be notified of device changes relevant to
+// this particular engine: its currently selected input or output device
+// disconnecting, or the system default output drifting to a sample rate
+// that no longer matches the engine's own AudioSpec.SampleRate. It rides the
+// same process-wide poll as the package-level OnDeviceChange above - one
+// 2-second ticker serves every engine and listener, not one per Engine -
+// and enriches each event with this engine's InputDisconnected,
+// OutputDisconnected, and SampleRateMismatch before calling fn.
+//
+// There is no CoreAudio route-change notification wired up yet (see
+// deviceWatch above), so "disconnect" here means the device disappeared
+// from the next poll, not an immediate hardware callback.
+func (e *Engine) OnDeviceChange(fn func(DeviceChangeEvent)) {
+	OnDeviceChange(func(event DeviceChangeEvent) {
+		fn(e.enrichDeviceChangeEvent(event))
+	})
+}
+
+// enrichDeviceChangeEvent sets event's InputDisconnected, OutputDisconnected,
+// and SampleRateMismatch fields relative to e, leaving Added/Removed
+// untouched. Split out from OnDeviceChange so the enrichment logic can be
+// tested without waiting on deviceWatch's 2-second poll.
+func (e *Engine) enrichDeviceChangeEvent(event DeviceChangeEvent) DeviceChangeEvent {
+	for _, d := range event.Removed {
+		if e.currentInputDeviceUID != "" && d.UID == e.currentInputDeviceUID {
+			event.InputDisconnected = true
+		}
+		if e.currentOutputDeviceUID != "" && d.UID == e.currentOutputDeviceUID {
+			event.OutputDisconnected = true
+		}
+	}
+	event.SampleRateMismatch = e.defaultOutputSampleRateMismatched()
+	return event
+}
+
+// defaultOutputSampleRateMismatched reports whether the system's current
+// default output device no longer lists e's AudioSpec.SampleRate among its
+// supported rates.
+func (e *Engine) defaultOutputSampleRateMismatched() bool {
+	current, err := Devices()
+	if err != nil {
+		return false
+	}
+	for _, d := range current {
+		if !d.IsDefaultOutput {
+			continue
+		}
+		if len(d.SampleRates) == 0 {
+			return false
+		}
+		for _, rate := range d.SampleRates {
+			if float64(rate) == e.spec.SampleRate {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// duplexRing is a lock-free single-producer/single-consumer ring buffer of
+// interleaved float32 frames, the same shape as streamRing/callbackRing but
+// indexed in frames rather than blocks: a DuplexMonitor's input NodeStream
+// callback is the sole producer and its output NodeStream callback the sole
+// consumer, so atomics on head/tail are enough - no mutex.
+type duplexRing struct {
+	buf      []float32
+	channels int
+	capacity uint64 // frames
+	head     uint64 // next frame the output callback will read
+	tail     uint64 // next frame the input callback will write
+}
+
+func newDuplexRing(frames, channels int) *duplexRing {
+	if frames < 2 {
+		frames = 2
+	}
+	return &duplexRing{
+		buf:      make([]float32, frames*channels),
+		channels: channels,
+		capacity: uint64(frames),
+	}
+}
+
+// pushFrame copies one interleaved frame (len(frame) == r.channels) into the
+// ring, returning false (without writing) if the ring is already full.
+func (r *duplexRing) pushFrame(frame []float32) bool {
+	tail := atomic.LoadUint64(&r.tail)
+	head := atomic.LoadUint64(&r.head)
+	if tail-head >= r.capacity {
+		return false
+	}
+	offset := (tail % r.capacity) * uint64(r.channels)
+	copy(r.buf[offset:offset+uint64(r.channels)], frame)
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+// popFrame copies one interleaved frame into dst (len(dst) == r.channels),
+// returning false (leaving dst untouched) if the ring is empty.
+func (r *duplexRing) popFrame(dst []float32) bool {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if head == tail {
+		return false
+	}
+	offset := (head % r.capacity) * uint64(r.channels)
+	copy(dst, r.buf[offset:offset+uint64(r.channels)])
+	atomic.StoreUint64(&r.head, head+1)
+	return true
+}
+
+// fillFrames returns how many frames are currently buffered.
+func (r *duplexRing) fillFrames() uint64 {
+	return atomic.LoadUint64(&r.tail) - atomic.LoadUint64(&r.head)
+}
+
+// DuplexMonitor bridges an input NodeStream and an output NodeStream through
+// duplexRing, so a captured input can be heard on an output without wiring
+// the two nodes together with Connect/ConnectWithFormat - the pattern cpal's
+// feedback example uses ringbuf for, to join capture and playback that can't
+// share a clock. See Engine.CreateDuplexMonitor.
+//
+// Built on BuildInputStream/BuildOutputStream (this package's NodeStream,
+// from the chunk that added callback-driven streams) and NewFormat (this
+// package's format layer) rather than a dedicated InputChannel/OutputBus
+// pair - this tree doesn't have those types, and NodeStream's nodePtr/
+// busIndex pair already identifies an attachment point in the graph.
+type DuplexMonitor struct {
+	input  *NodeStream
+	output *NodeStream
+	ring   *duplexRing
+
+	channels   int
+	outputRate float64 // for Latency()
+
+	// ratio is inputSampleRate/outputSampleRate; 1 means no resampling.
+	// phase/lastFrame/haveLast hold the linear-interpolation resampler's
+	// state across handleInput calls - see resampleAndPush.
+	mu        sync.Mutex
+	ratio     float64
+	phase     float64
+	lastFrame []float32
+	haveLast  bool
+
+	gainBits uint32 // atomic float32 bits; 1.0 (no change) by default
+
+	underruns uint64
+	overruns  uint64
+}
+
+// CreateDuplexMonitor installs an input NodeStream on inputNodePtr/inputBus
+// and an output NodeStream on outputNodePtr/outputBus, and returns a
+// DuplexMonitor that feeds captured frames from one into the other through a
+// ringFrames-deep ring buffer, resampling with linear interpolation when
+// inputSampleRate differs from spec.SampleRate - so a caller can monitor a
+// 44.1 kHz mic through a 48 kHz output without re-plumbing the graph. The
+// monitor isn't started; call Start once both streams should begin moving
+// audio.
+//
+// BuildInputStream/BuildOutputStream's native side isn't wired up in this
+// tree yet (see their doc comments), so until it is, neither callback this
+// installs is ever actually invoked - the ring, resampler, and gain/under-
+// run/overrun bookkeeping below are fully real Go logic that starts working
+// the moment that native side exists.
+func (e *Engine) CreateDuplexMonitor(inputNodePtr unsafe.Pointer, inputBus int, inputSampleRate float64, outputNodePtr unsafe.Pointer, outputBus int, spec EnhancedAudioSpec, ringFrames int) (*DuplexMonitor, error) {
+	if inputSampleRate <= 0 {
+		return nil, fmt.Errorf("input sample rate must be positive, got %v", inputSampleRate)
+	}
+	if spec.SampleRate <= 0 {
+		return nil, fmt.Errorf("spec sample rate must be positive, got %v", spec.SampleRate)
+	}
+	if spec.ChannelCount <= 0 {
+		return nil, fmt.Errorf("spec channel count must be positive, got %d", spec.ChannelCount)
+	}
+	if ringFrames <= 0 {
+		return nil, fmt.Errorf("ring frames must be positive, got %d", ringFrames)
+	}
+
+	format, err := e.NewFormat(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build duplex monitor format: %w", err)
+	}
+
+	m := &DuplexMonitor{
+		channels:   spec.ChannelCount,
+		ring:       newDuplexRing(ringFrames, spec.ChannelCount),
+		ratio:      inputSampleRate / spec.SampleRate,
+		outputRate: spec.SampleRate,
+		gainBits:   math.Float32bits(1),
+	}
+
+	inStream, err := e.BuildInputStream(inputNodePtr, inputBus, format, m.handleInput)
+	if err != nil {
+		return nil, err
+	}
+
+	outStream, err := e.BuildOutputStream(outputNodePtr, outputBus, format, m.handleOutput)
+	if err != nil {
+		inStream.Close()
+		return nil, err
+	}
+
+	m.input = inStream
+	m.output = outStream
+	return m, nil
+}
+
+// Start starts the output stream before the input stream, so the ring
+// already has a consumer attached by the time captured frames could start
+// arriving.
+func (m *DuplexMonitor) Start() error {
+	if err := m.output.Start(); err != nil {
+		return err
+	}
+	if err := m.input.Start(); err != nil {
+		m.output.Stop()
+		return err
+	}
+	return nil
+}
+
+// Stop stops both streams. The monitor can be Start()ed again afterward.
+func (m *DuplexMonitor) Stop() error {
+	inErr := m.input.Stop()
+	outErr := m.output.Stop()
+	if inErr != nil {
+		return inErr
+	}
+	return outErr
+}
+
+// Close stops the monitor if still running and releases both native taps/
+// source nodes. The DuplexMonitor must not be used after Close returns.
+func (m *DuplexMonitor) Close() error {
+	inErr := m.input.Close()
+	outErr := m.output.Close()
+	if inErr != nil {
+		return inErr
+	}
+	return outErr
+}
+
+// handleInput is the input NodeStream's callback: it resamples d's frames
+// to the output rate (a no-op copy when ratio is 1) and pushes each into the
+// ring, counting an Overrun for any frame the ring was already full for.
+func (m *DuplexMonitor) handleInput(d InputData) {
+	src := d.Float32()
+	if src == nil {
+		return
+	}
+	m.mu.Lock()
+	m.resampleAndPush(src, d.FrameCount())
+	m.mu.Unlock()
+}
+
+// resampleAndPush is handleInput's body, split out so it can be unit tested
+// directly without a real NodeStream/InputData. m.mu must be held.
+func (m *DuplexMonitor) resampleAndPush(src []float32, frameCount int) {
+	ch := m.channels
+	if m.ratio == 1 {
+		for i := 0; i < frameCount; i++ {
+			if !m.ring.pushFrame(src[i*ch : i*ch+ch]) {
+				atomic.AddUint64(&m.overruns, 1)
+			}
+		}
+		return
+	}
+
+	// Linear-interpolation resample from the input rate to the output
+	// rate: phase tracks how far between lastFrame and the current input
+	// frame the next output frame falls, advancing by ratio (> 1 means
+	// downsampling, < 1 upsampling) each output frame produced.
+	out := make([]float32, ch)
+	for i := 0; i < frameCount; i++ {
+		cur := src[i*ch : i*ch+ch]
+		if !m.haveLast {
+			m.lastFrame = append([]float32(nil), cur...)
+			m.haveLast = true
+			continue
+		}
+		for m.phase < 1 {
+			for c := 0; c < ch; c++ {
+				out[c] = m.lastFrame[c] + float32(m.phase)*(cur[c]-m.lastFrame[c])
+			}
+			if !m.ring.pushFrame(out) {
+				atomic.AddUint64(&m.overruns, 1)
+			}
+			m.phase += m.ratio
+		}
+		m.phase -= 1
+		m.lastFrame = append(m.lastFrame[:0], cur...)
+	}
+}
+
+// handleOutput is the output NodeStream's callback: it pops one frame per
+// requested frame out of the ring into d's buffer, applying the current
+// gain, and fills silence (counting an Underrun) for any frame the ring
+// didn't have ready yet.
+func (m *DuplexMonitor) handleOutput(d OutputData) {
+	dst := d.Float32()
+	if dst == nil {
+		return
+	}
+	ch := m.channels
+	gain := math.Float32frombits(atomic.LoadUint32(&m.gainBits))
+
+	for i := 0; i < d.FrameCount(); i++ {
+		frame := dst[i*ch : i*ch+ch]
+		if !m.ring.popFrame(frame) {
+			for c := range frame {
+				frame[c] = 0
+			}
+			atomic.AddUint64(&m.underruns, 1)
+			continue
+		}
+		if gain != 1 {
+			for c := range frame {
+				frame[c] *= gain
+			}
+		}
+	}
+}
+
+// SetGain scales every sample the output callback delivers by gain (1.0 is
+// unity).
+func (m *DuplexMonitor) SetGain(gain float32) {
+	atomic.StoreUint32(&m.gainBits, math.Float32bits(gain))
+}
+
+// Latency returns how much audio is currently buffered in the ring, as a
+// duration at the monitor's output sample rate.
+func (m *DuplexMonitor) Latency() time.Duration {
+	frames := m.ring.fillFrames()
+	return time.Duration(float64(frames) / m.outputRate * float64(time.Second))
+}
+
+// Underruns returns how many output frames were delivered as silence
+// because the ring didn't have a frame ready.
+func (m *DuplexMonitor) Underruns() uint64 {
+	return atomic.LoadUint64(&m.underruns)
+}
+
+// Overruns returns how many captured frames were dropped because the ring
+// was already full.
+func (m *DuplexMonitor) Overruns() uint64 {
+	return atomic.LoadUint64(&m.overruns)
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestDuplexRingPushPop(t *testing.T) {
+	r := newDuplexRing(2, 2)
+
+	if r.popFrame(make([]float32, 2)) {
+		t.Fatal("pop on empty ring should report false")
+	}
+
+	if !r.pushFrame([]float32{1, 2}) {
+		t.Fatal("push into empty ring should succeed")
+	}
+	if !r.pushFrame([]float32{3, 4}) {
+		t.Fatal("push into ring with one free frame should succeed")
+	}
+	if r.pushFrame([]float32{5, 6}) {
+		t.Fatal("push into full ring should fail")
+	}
+	if got := r.fillFrames(); got != 2 {
+		t.Fatalf("fillFrames() = %d, want 2", got)
+	}
+
+	dst := make([]float32, 2)
+	if !r.popFrame(dst) || dst[0] != 1 || dst[1] != 2 {
+		t.Fatalf("popFrame = %v; want [1 2]", dst)
+	}
+	if !r.pushFrame([]float32{5, 6}) {
+		t.Fatal("push after freeing a frame should succeed")
+	}
+
+	if !r.popFrame(dst) || dst[0] != 3 || dst[1] != 4 {
+		t.Fatalf("popFrame = %v; want [3 4]", dst)
+	}
+	if !r.popFrame(dst) || dst[0] != 5 || dst[1] != 6 {
+		t.Fatalf("popFrame = %v; want [5 6]", dst)
+	}
+	if r.popFrame(dst) {
+		t.Fatal("pop on drained ring should report false")
+	}
+}
+
+func TestDuplexMonitorResamplePassthroughWhenRatioIsOne(t *testing.T) {
+	m := &DuplexMonitor{
+		channels: 1,
+		ring:     newDuplexRing(8, 1),
+		ratio:    1,
+	}
+
+	m.resampleAndPush([]float32{0.1, 0.2, 0.3}, 3)
+
+	dst := make([]float32, 1)
+	for _, want := range []float32{0.1, 0.2, 0.3} {
+		if !m.ring.popFrame(dst) || dst[0] != want {
+			t.Fatalf("popFrame = %v; want [%v]", dst, want)
+		}
+	}
+}
+
+func TestDuplexMonitorResampleUpsamplesWhenRatioBelowOne(t *testing.T) {
+	m := &DuplexMonitor{
+		channels: 1,
+		ring:     newDuplexRing(16, 1),
+		ratio:    0.5, // input rate is half the output rate: interpolate extra frames in
+	}
+
+	m.resampleAndPush([]float32{0, 1, 2}, 3)
+
+	if got := m.ring.fillFrames(); got == 0 {
+		t.Fatal("expected upsampling to produce more output frames than input frames")
+	}
+}
+
+func TestDuplexMonitorOutputUnderrunsOnEmptyRing(t *testing.T) {
+	m := &DuplexMonitor{
+		channels: 1,
+		ring:     newDuplexRing(4, 1),
+		gainBits: 0x3f800000, // math.Float32bits(1)
+	}
+
+	buf := make([]float32, 2)
+	m.handleOutput(OutputData{block: streamBlock{format: StreamSampleFloat32, float32s: buf, frameCount: 2}})
+
+	if m.Underruns() != 2 {
+		t.Fatalf("Underruns() = %d, want 2", m.Underruns())
+	}
+	for _, v := range buf {
+		if v != 0 {
+			t.Fatalf("expected silence on underrun, got %v", buf)
+		}
+	}
+}
+
+func TestDuplexMonitorOutputAppliesGain(t *testing.T) {
+	m := &DuplexMonitor{
+		channels: 1,
+		ring:     newDuplexRing(4, 1),
+	}
+	m.ring.pushFrame([]float32{1})
+	m.SetGain(0.5)
+
+	buf := make([]float32, 1)
+	m.handleOutput(OutputData{block: streamBlock{format: StreamSampleFloat32, float32s: buf, frameCount: 1}})
+
+	if buf[0] != 0.5 {
+		t.Fatalf("buf[0] = %v, want 0.5", buf[0])
+	}
+}
+
+func TestCreateDuplexMonitorRejectsInvalidArgs(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatal("Failed to create engine:", err)
+	}
+	defer eng.Destroy()
+
+	valid := unsafe.Pointer(&struct{}{})
+	spec := EnhancedAudioSpec{SampleRate: 48000, ChannelCount: 2}
+
+	cases := []struct {
+		name       string
+		inRate     float64
+		spec       EnhancedAudioSpec
+		ringFrames int
+	}{
+		{"zero input rate", 0, spec, 512},
+		{"zero spec rate", 44100, EnhancedAudioSpec{ChannelCount: 2}, 512},
+		{"zero channel count", 44100, EnhancedAudioSpec{SampleRate: 48000}, 512},
+		{"zero ring frames", 44100, spec, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := eng.CreateDuplexMonitor(valid, 0, c.inRate, valid, 0, c.spec, c.ringFrames); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
@@ -39,8 +39,13 @@ import "C"
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 	"unsafe"
+
+	"github.com/shaban/macaudio/internal/rt"
+	rootmidi "github.com/shaban/macaudio/midi"
 )
 
 // AudioSpec defines the foundational audio settings for an engine
@@ -49,6 +54,11 @@ type AudioSpec struct {
 	BufferSize   int     // 256, 512, 1024, 2048 samples
 	BitDepth     int     // 16, 24, 32 bits per sample
 	ChannelCount int     // 1 (mono), 2 (stereo)
+
+	// HostAPI selects the backend New/NewWithHostAPI open a stream against.
+	// Nil means the default AVAudioEngine-backed CoreAudio path (unchanged
+	// behavior); pass a *NullHostAPI for headless, deterministic tests.
+	HostAPI HostAPI
 }
 
 // DefaultAudioSpec returns commonly used audio settings
@@ -69,10 +79,119 @@ type Engine struct {
 	// Connection tracking for smart per-bus control (optional enhancement)
 	// mixerConnections maps mixer pointer -> bus -> source pointer
 	mixerConnections map[unsafe.Pointer]map[int]unsafe.Pointer
+
+	// events backs Subscribe; see engineEventBus.
+	events engineEventBus
+
+	// manualRenderingMaxFrames is the maximumFrameCount passed to
+	// EnableManualRendering, or 0 when manual rendering is disabled. See
+	// manual_render.go.
+	manualRenderingMaxFrames int
+
+	// host and hostStream are set instead of ptr when the engine was opened
+	// via NewWithHostAPI: Start/Stop/IsRunning dispatch to hostStream rather
+	// than the native AVAudioEngine. Node-graph operations (Attach/Connect/
+	// ...) have no HostAPI equivalent yet - see hostapi.go - and fail with
+	// ErrHostAPIUnsupported on a HostAPI-backed engine.
+	host        HostAPI
+	hostStream  Stream
+	hostRunning bool
+
+	// currentInputDeviceUID and currentOutputDeviceUID record the device
+	// last selected via SetInputDevice/SetOutputDevice (or NewWithDevice),
+	// empty when the engine is still on the system default. OnDeviceChange
+	// uses these to tell a caller when the device it picked specifically
+	// vanished, not just that the device list changed.
+	currentInputDeviceUID  string
+	currentOutputDeviceUID string
+
+	// cmdQueueCh, cmdQueueOnce, cmdQueueDone, and cmdQueueWG back Commit; see
+	// command_queue.go.
+	cmdQueueCh   chan commandBatch
+	cmdQueueOnce sync.Once
+	cmdQueueDone chan struct{}
+	cmdQueueWG   sync.WaitGroup
+	cmdStats     CommandQueueStats
+
+	// formatPolicy governs how Connect reacts to an AudioSpec/hardware
+	// sample-rate mismatch; see FormatPolicy in format_negotiate.go.
+	formatPolicy FormatPolicy
+
+	// attachedNodes and graphEdges back Graph; see graph.go.
+	attachedNodes map[unsafe.Pointer]string
+	graphEdges    []graphEdgeRecord
+
+	// stats accumulates render-thread telemetry for Stats/OnXrun; see
+	// xrun.go.
+	stats runtimeStats
+
+	// fadesMu, fades, fadeOnce, fadeDone, fadeWG, and fadeTickRate back
+	// FadeConnectionVolume and friends; see fade.go.
+	fadesMu      sync.Mutex
+	fades        map[fadeKey]*activeFade
+	fadeOnce     sync.Once
+	fadeDone     chan struct{}
+	fadeWG       sync.WaitGroup
+	fadeTickRate int
+
+	// tapRegistryOnce and tapRegistry back Taps; see tap_registry.go.
+	tapRegistryOnce sync.Once
+	tapRegistry     *TapRegistry
+
+	// ccMu guards every field below, back BindMIDIController, OpenMIDIInput,
+	// and the control-rate drain goroutine they start; see midi.go.
+	ccMu            sync.Mutex
+	ccBindings      map[int][]*ccBinding
+	ccParamBindings map[midiParamKey]*ccBinding
+	ccRing          *rt.Ring
+	ccControlRateHz int
+	ccDriveOnce     sync.Once
+	ccDriveDone     chan struct{}
+	ccDriveWG       sync.WaitGroup
+	midiListeners   []*rootmidi.ChannelListener
+	midiPollDone    chan struct{}
+	midiPollWG      sync.WaitGroup
+	learnCallback   func(cc, channel int)
+	noteTarget      MIDIParameterTarget
+	noteEffectIndex int
+}
+
+// ErrHostAPIUnsupported is returned by Engine methods that require the
+// native AVAudioEngine node graph (Attach, Connect, mixer/node creation,
+// ...) when called on an engine opened via NewWithHostAPI, whose backend
+// only implements the stream-level HostAPI seam.
+var ErrHostAPIUnsupported = errors.New("engine: not supported by the active HostAPI backend")
+
+// NewWithHostAPI creates an Engine whose Start/Stop/IsRunning are driven by
+// spec.HostAPI's Stream instead of the native AVAudioEngine - the entry
+// point the HostAPI seam (hostapi.go) was introduced for. It does not give
+// the engine a native node graph: Attach, Connect, and other
+// AVAudioEngine-backed operations return ErrHostAPIUnsupported on the
+// returned Engine. This is for callers that only need HostAPI's
+// enumerate/stream-lifecycle surface - a headless test harness driving
+// NullHostAPI, or a PortAudio-backed deployment where no AVAudioEngine
+// exists at all - not for running the plugin/channel graph this package's
+// native path supports.
+func NewWithHostAPI(spec AudioSpec) (*Engine, error) {
+	if spec.HostAPI == nil {
+		return nil, errors.New("engine: NewWithHostAPI requires a non-nil AudioSpec.HostAPI")
+	}
+
+	stream, err := spec.HostAPI.OpenStream(spec)
+	if err != nil {
+		return nil, fmt.Errorf("engine: opening %s stream: %w", spec.HostAPI.Name(), err)
+	}
+
+	return &Engine{
+		spec:             spec,
+		mixerConnections: make(map[unsafe.Pointer]map[int]unsafe.Pointer),
+		host:             spec.HostAPI,
+		hostStream:       stream,
+	}, nil
 }
 
 // New creates a new AVAudioEngine instance with specified audio settings
-func New(spec AudioSpec) (*Engine, error) {
+func New(spec AudioSpec, opts ...EngineOption) (*Engine, error) {
 	result := C.audioengine_new()
 	if result.error != nil {
 		return nil, errors.New(C.GoString(result.error))
@@ -88,6 +207,10 @@ func New(spec AudioSpec) (*Engine, error) {
 		mixerConnections: make(map[unsafe.Pointer]map[int]unsafe.Pointer),
 	}
 
+	for _, opt := range opts {
+		opt(engine)
+	}
+
 	// Apply the specified buffer size immediately after creation
 	if spec.BufferSize > 0 {
 		if err := engine.SetBufferSize(spec.BufferSize); err != nil {
@@ -100,6 +223,19 @@ func New(spec AudioSpec) (*Engine, error) {
 	return engine, nil
 }
 
+// EngineOption configures optional Engine behavior at construction time,
+// applied after New allocates the native engine but before it's returned -
+// see WithFormatPolicy. Passing none leaves every option at its zero value,
+// so existing New(spec) call sites are unaffected.
+type EngineOption func(*Engine)
+
+// WithFormatPolicy sets the FormatPolicy Connect enforces when the engine's
+// AudioSpec sample rate doesn't match the current default output device -
+// see FormatPolicy and Engine.SetFormatPolicy.
+func WithFormatPolicy(policy FormatPolicy) EngineOption {
+	return func(e *Engine) { e.formatPolicy = policy }
+}
+
 // GetNativeEngine returns the native AVAudioEngine pointer for taps
 func (e *Engine) GetNativeEngine() unsafe.Pointer {
 	if e.ptr != nil {
@@ -145,9 +281,21 @@ func (e *Engine) Prepare() {
 	C.audioengine_prepare(e.ptr)
 }
 
-// Start starts the engine
+// Start starts the engine - the native AVAudioEngine, or (for an engine
+// opened via NewWithHostAPI) the underlying HostAPI Stream.
 func (e *Engine) Start() error {
-	if e == nil || e.ptr == nil {
+	if e == nil {
+		return errors.New("engine is nil")
+	}
+	if e.host != nil {
+		if err := e.hostStream.Start(); err != nil {
+			return err
+		}
+		e.hostRunning = true
+		e.events.publish(EngineStarted{})
+		return nil
+	}
+	if e.ptr == nil {
 		return errors.New("engine is nil")
 	}
 
@@ -156,16 +304,28 @@ func (e *Engine) Start() error {
 		return errors.New(C.GoString(result))
 	}
 
+	e.events.publish(EngineStarted{})
 	return nil
 }
 
-// Stop stops the engine
+// Stop stops the engine - the native AVAudioEngine, or (for an engine
+// opened via NewWithHostAPI) the underlying HostAPI Stream.
 func (e *Engine) Stop() {
-	if e == nil || e.ptr == nil {
+	if e == nil {
+		return
+	}
+	if e.host != nil {
+		e.hostStream.Stop()
+		e.hostRunning = false
+		e.events.publish(EngineStopped{})
+		return
+	}
+	if e.ptr == nil {
 		return
 	}
 
 	C.audioengine_stop(e.ptr)
+	e.events.publish(EngineStopped{})
 }
 
 // Pause pauses the engine
@@ -188,7 +348,13 @@ func (e *Engine) Reset() {
 
 // IsRunning returns true if the engine is running
 func (e *Engine) IsRunning() bool {
-	if e == nil || e.ptr == nil {
+	if e == nil {
+		return false
+	}
+	if e.host != nil {
+		return e.hostRunning
+	}
+	if e.ptr == nil {
 		return false
 	}
 
@@ -291,17 +457,38 @@ func (e *Engine) GetMixerVolume(mixerNodePtr unsafe.Pointer) (float32, error) {
 
 // Destroy properly tears down the engine and frees all resources
 func (e *Engine) Destroy() {
-	if e == nil || e.ptr == nil {
+	if e == nil {
+		return
+	}
+	e.stopCommandQueue()
+	e.stopFadeScheduler()
+	e.closeMIDIInputs()
+	if e.host != nil {
+		if e.hostStream != nil {
+			e.hostStream.Close()
+		}
+		e.host = nil
+		e.hostStream = nil
+		return
+	}
+	if e.ptr == nil {
 		return
 	}
 
+	e.closeAttachedControllers()
 	C.audioengine_destroy(e.ptr)
 	e.ptr = nil
 }
 
 // Attach attaches a node to the engine - 1:1 mapping to attachNode:
 func (e *Engine) Attach(nodePtr unsafe.Pointer) error {
-	if e == nil || e.ptr == nil {
+	if e == nil {
+		return errors.New("engine is nil")
+	}
+	if e.host != nil {
+		return ErrHostAPIUnsupported
+	}
+	if e.ptr == nil {
 		return errors.New("engine is nil")
 	}
 
@@ -314,6 +501,8 @@ func (e *Engine) Attach(nodePtr unsafe.Pointer) error {
 		return errors.New(C.GoString(result))
 	}
 
+	e.trackNode(nodePtr)
+
 	return nil
 }
 
@@ -332,6 +521,8 @@ func (e *Engine) Detach(nodePtr unsafe.Pointer) error {
 		return errors.New(C.GoString(errorStr))
 	}
 
+	e.untrackNode(nodePtr)
+
 	return nil
 }
 
@@ -352,6 +543,7 @@ func (e *Engine) ConnectWithFormat(sourcePtr, destPtr unsafe.Pointer, fromBus, t
 
 	// Track connection for smart per-bus control
 	e.trackConnection(sourcePtr, destPtr, toBus)
+	e.trackEdge(sourcePtr, destPtr, fromBus, toBus)
 
 	return nil
 }
@@ -420,7 +612,13 @@ func (e *Engine) untrackConnection(destPtr unsafe.Pointer, inputBus int) {
 // This ensures consistent audio quality across all connections in the engine
 // Now uses the consolidated format system for better efficiency and type safety
 func (e *Engine) Connect(sourcePtr, destPtr unsafe.Pointer, fromBus, toBus int) error {
-	if e == nil || e.ptr == nil {
+	if e == nil {
+		return errors.New("engine is nil")
+	}
+	if e.host != nil {
+		return ErrHostAPIUnsupported
+	}
+	if e.ptr == nil {
 		return errors.New("engine is nil")
 	}
 
@@ -428,6 +626,18 @@ func (e *Engine) Connect(sourcePtr, destPtr unsafe.Pointer, fromBus, toBus int)
 		return errors.New("node pointers cannot be nil")
 	}
 
+	if e.formatPolicy == FormatPolicyPreferDest {
+		// Let AVAudioEngine infer the connection's format from whatever
+		// destPtr already has, rather than imposing AudioSpec on it.
+		return e.ConnectWithFormat(sourcePtr, destPtr, fromBus, toBus, nil)
+	}
+
+	if e.formatPolicy == FormatPolicyStrict {
+		if mismatch := e.sampleRateMismatch(); mismatch != nil {
+			return mismatch
+		}
+	}
+
 	// Use the consolidated format system instead of inline C format creation
 	engineFormat, err := e.GetEngineFormat()
 	if err != nil {
@@ -471,6 +681,7 @@ func (e *Engine) DisconnectNodeInput(nodePtr unsafe.Pointer, inputBus int) error
 
 	// Remove connection tracking
 	e.untrackConnection(nodePtr, inputBus)
+	e.untrackEdgeByDest(nodePtr, inputBus)
 
 	return nil
 }
@@ -495,9 +706,14 @@ func (e *Engine) DisconnectNodeOutput(nodePtr unsafe.Pointer, outputBus int) err
 		return errors.New(C.GoString(errorStr))
 	}
 
-	// Note: Output disconnection is harder to track since we don't know which destination
-	// was disconnected. For now, we'll let the connection tracking be eventually consistent.
-	// TODO: Consider more sophisticated connection tracking for bidirectional cleanup
+	// trackEdge (see graph.go) records fromBus alongside the source/dest
+	// pointers it tracks for Graph, so unlike mixerConnections (indexed by
+	// destination only) we can find and drop exactly the edge that left
+	// nodePtr's outputBus without touching any of its other connections.
+	if dest, toBus, ok := e.edgeFrom(nodePtr, outputBus); ok {
+		e.untrackConnection(dest, toBus)
+		e.untrackEdge(nodePtr, outputBus)
+	}
 
 	return nil
 }
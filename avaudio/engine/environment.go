@@ -0,0 +1,183 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+
+// AVAudioEnvironmentNode function declarations - HRTF-rendered 3D
+// positioning for a node already attached to the engine's graph. Distinct
+// from the plain stereo-pan mixer controls in nodes.go, which have no
+// notion of a listener or a rendering algorithm.
+AudioNodeResult environment_create(void* enginePtr);
+const char* environment_set_listener_position(void* envPtr, float x, float y, float z);
+const char* environment_set_listener_orientation(void* envPtr, float forwardX, float forwardY, float forwardZ, float upX, float upY, float upZ);
+const char* environment_set_node_position(void* envPtr, void* nodePtr, float x, float y, float z);
+const char* environment_set_node_rendering_algorithm(void* envPtr, void* nodePtr, int algorithm);
+const char* environment_set_node_distance_attenuation(void* envPtr, void* nodePtr, int model, float rolloff, float referenceDistance, float maximumDistance);
+const char* environment_set_node_directivity(void* envPtr, void* nodePtr, float innerAngle, float outerAngle, float outerGain);
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// RenderingAlgorithm selects how AVAudioEnvironmentNode spatializes a node
+// assigned to it, mirroring AVAudio3DMixingRenderingAlgorithm's cases this
+// binding supports.
+type RenderingAlgorithm int
+
+const (
+	RenderingAlgorithmEqualPower RenderingAlgorithm = iota
+	RenderingAlgorithmHRTF
+	RenderingAlgorithmHRTFHQ
+)
+
+// DistanceAttenuationModel selects how AVAudioEnvironmentNode rolls off a
+// node's gain as it moves away from the listener, mirroring
+// AVAudioEnvironmentDistanceAttenuationParameters.distanceAttenuationModel.
+type DistanceAttenuationModel int
+
+const (
+	DistanceAttenuationInverse DistanceAttenuationModel = iota
+	DistanceAttenuationLinear
+	DistanceAttenuationExponential
+)
+
+// CreateEnvironmentNode attaches a new AVAudioEnvironmentNode to the engine
+// and returns its native pointer. Nodes that should be spatialized through
+// it (see SetNodePosition/SetNodeRenderingAlgorithm) must still be routed
+// into it with Connect, same as any other node.
+func (e *Engine) CreateEnvironmentNode() (unsafe.Pointer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+
+	result := C.environment_create(e.ptr)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// SetListenerPosition moves envPtr's listener to (x, y, z) in the
+// environment's coordinate space.
+func (e *Engine) SetListenerPosition(envPtr unsafe.Pointer, x, y, z float32) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if envPtr == nil {
+		return errors.New("environment pointer is nil")
+	}
+
+	errorStr := C.environment_set_listener_position(envPtr, C.float(x), C.float(y), C.float(z))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SetListenerOrientation sets envPtr's listener forward/up vectors.
+func (e *Engine) SetListenerOrientation(envPtr unsafe.Pointer, forward, up [3]float32) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if envPtr == nil {
+		return errors.New("environment pointer is nil")
+	}
+
+	errorStr := C.environment_set_listener_orientation(envPtr,
+		C.float(forward[0]), C.float(forward[1]), C.float(forward[2]),
+		C.float(up[0]), C.float(up[1]), C.float(up[2]))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SetNode3DPosition sets nodePtr's position in envPtr's coordinate space.
+// nodePtr must already be connected into envPtr's input.
+func (e *Engine) SetNode3DPosition(envPtr, nodePtr unsafe.Pointer, x, y, z float32) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if envPtr == nil {
+		return errors.New("environment pointer is nil")
+	}
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+
+	errorStr := C.environment_set_node_position(envPtr, nodePtr, C.float(x), C.float(y), C.float(z))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SetNode3DRenderingAlgorithm sets the AVAudio3DMixingRenderingAlgorithm
+// envPtr uses to spatialize nodePtr.
+func (e *Engine) SetNode3DRenderingAlgorithm(envPtr, nodePtr unsafe.Pointer, algorithm RenderingAlgorithm) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if envPtr == nil {
+		return errors.New("environment pointer is nil")
+	}
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+
+	errorStr := C.environment_set_node_rendering_algorithm(envPtr, nodePtr, C.int(algorithm))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SetNodeDistanceAttenuation sets nodePtr's distance attenuation within
+// envPtr: model selects the rolloff curve, rolloff scales its rate, and
+// referenceDistance/maximumDistance bound the distances the model applies
+// between (closer than referenceDistance stays at full gain, farther than
+// maximumDistance stops attenuating further).
+func (e *Engine) SetNodeDistanceAttenuation(envPtr, nodePtr unsafe.Pointer, model DistanceAttenuationModel, rolloff, referenceDistance, maximumDistance float32) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if envPtr == nil {
+		return errors.New("environment pointer is nil")
+	}
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+
+	errorStr := C.environment_set_node_distance_attenuation(envPtr, nodePtr, C.int(model), C.float(rolloff), C.float(referenceDistance), C.float(maximumDistance))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SetNodeDirectivity sets nodePtr's directivity cone within envPtr: full
+// gain inside innerAngleDeg, outerGain outside outerAngleDeg, linearly
+// interpolated between. Angles are in degrees, 0-360; innerAngleDeg ==
+// outerAngleDeg == 0 means omnidirectional, the common case for a mono mic
+// source.
+func (e *Engine) SetNodeDirectivity(envPtr, nodePtr unsafe.Pointer, innerAngleDeg, outerAngleDeg, outerGain float32) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if envPtr == nil {
+		return errors.New("environment pointer is nil")
+	}
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+
+	errorStr := C.environment_set_node_directivity(envPtr, nodePtr, C.float(innerAngleDeg), C.float(outerAngleDeg), C.float(outerGain))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// FadeCurve selects how a fade started by FadeConnectionVolume,
+// FadeConnectionPan, FadeMixerVolume, or FadeMixerPan interpolates between
+// its starting value and its target over its duration.
+type FadeCurve int
+
+const (
+	// FadeLinear interpolates at a constant rate.
+	FadeLinear FadeCurve = iota
+	// FadeEqualPower follows a quarter-sine curve, the conventional shape
+	// for a pan or crossfade whose perceived loudness should stay constant.
+	FadeEqualPower
+	// FadeExponential front-loads most of the change near the end of the
+	// fade, matching how level changes are perceived logarithmically.
+	FadeExponential
+	// FadeSCurve eases in and out (a smoothstep), avoiding the audible
+	// "click" of a fade starting or stopping at full rate.
+	FadeSCurve
+)
+
+// DefaultFadeTickRate is the scheduler rate (in Hz) used by a fade started
+// before SetFadeTickRate has been called.
+const DefaultFadeTickRate = 60
+
+// fadeTargetKind identifies which Engine setter an activeFade's apply func
+// drives, purely so fadeKey values for different kinds of fade never collide
+// even if their numeric fields happen to match.
+type fadeTargetKind int
+
+const (
+	fadeTargetConnectionVolume fadeTargetKind = iota
+	fadeTargetConnectionPan
+	fadeTargetMixerVolume
+	fadeTargetMixerPan
+	fadeTargetPlayerVolume
+)
+
+// fadeKey identifies the single parameter a fade drives, so starting a new
+// fade on a parameter already fading preempts the old one instead of the two
+// fighting over the same C setter.
+type fadeKey struct {
+	kind      fadeTargetKind
+	sourcePtr unsafe.Pointer
+	mixerPtr  unsafe.Pointer
+	bus       int
+}
+
+// activeFade is one in-flight fade tracked by Engine's scheduler goroutine.
+type activeFade struct {
+	from, to float32
+	curve    FadeCurve
+	start    time.Time
+	duration time.Duration
+	apply    func(value float32) error
+}
+
+// valueAt returns f's interpolated value at now, and whether the fade has
+// reached its target.
+func (f *activeFade) valueAt(now time.Time) (float32, bool) {
+	if f.duration <= 0 {
+		return f.to, true
+	}
+	t := float32(now.Sub(f.start)) / float32(f.duration)
+	if t >= 1 {
+		return f.to, true
+	}
+	if t < 0 {
+		t = 0
+	}
+	return f.from + (f.to-f.from)*shapeFadeCurve(f.curve, t), false
+}
+
+// shapeFadeCurve maps t (0..1, linear time progress) to a shaped progress
+// value also in 0..1, per curve.
+func shapeFadeCurve(curve FadeCurve, t float32) float32 {
+	switch curve {
+	case FadeEqualPower:
+		return float32(math.Sin(float64(t) * math.Pi / 2))
+	case FadeExponential:
+		if t <= 0 {
+			return 0
+		}
+		return float32(math.Pow(2, 10*(float64(t)-1)))
+	case FadeSCurve:
+		return t * t * (3 - 2*t)
+	default: // FadeLinear
+		return t
+	}
+}
+
+// ensureFadeScheduler lazily starts the single ticker-driven goroutine that
+// advances every active fade, mirroring ensureCommandQueue - an Engine that
+// never starts a fade never pays for the goroutine.
+func (e *Engine) ensureFadeScheduler() {
+	e.fadeOnce.Do(func() {
+		e.fadesMu.Lock()
+		if e.fades == nil {
+			e.fades = make(map[fadeKey]*activeFade)
+		}
+		if e.fadeTickRate <= 0 {
+			e.fadeTickRate = DefaultFadeTickRate
+		}
+		rate := e.fadeTickRate
+		e.fadesMu.Unlock()
+
+		e.fadeDone = make(chan struct{})
+		e.fadeWG.Add(1)
+		go e.runFadeScheduler(rate)
+	})
+}
+
+// SetFadeTickRate sets the rate, in Hz, at which Engine's fade scheduler
+// samples every active fade's curve. It must be called before the first
+// fade starts (the scheduler goroutine is started lazily and its rate fixed
+// at that point) - calling it afterward returns an error rather than
+// silently having no effect.
+func (e *Engine) SetFadeTickRate(hz int) error {
+	if hz <= 0 {
+		return errors.New("engine: fade tick rate must be positive")
+	}
+	e.fadesMu.Lock()
+	defer e.fadesMu.Unlock()
+	if e.fadeDone != nil {
+		return errors.New("engine: fade scheduler already running, SetFadeTickRate must be called before the first fade")
+	}
+	e.fadeTickRate = hz
+	return nil
+}
+
+// runFadeScheduler is the single goroutine that ever samples fade curves and
+// calls their apply funcs, so concurrently starting fades never race each
+// other's C setter calls.
+func (e *Engine) runFadeScheduler(rate int) {
+	defer e.fadeWG.Done()
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.fadeDone:
+			return
+		case now := <-ticker.C:
+			e.tickFades(now)
+		}
+	}
+}
+
+func (e *Engine) tickFades(now time.Time) {
+	e.fadesMu.Lock()
+	done := make([]fadeKey, 0)
+	type step struct {
+		apply func(float32) error
+		value float32
+	}
+	steps := make([]step, 0, len(e.fades))
+	for key, fade := range e.fades {
+		value, finished := fade.valueAt(now)
+		steps = append(steps, step{apply: fade.apply, value: value})
+		if finished {
+			done = append(done, key)
+		}
+	}
+	for _, key := range done {
+		delete(e.fades, key)
+	}
+	e.fadesMu.Unlock()
+
+	for _, s := range steps {
+		_ = s.apply(s.value)
+	}
+}
+
+// stopFadeScheduler shuts down the scheduler goroutine started by
+// ensureFadeScheduler, if one was ever started. Called from Destroy so an
+// Engine that started a fade doesn't leak its scheduler goroutine.
+func (e *Engine) stopFadeScheduler() {
+	if e.fadeDone == nil {
+		return
+	}
+	select {
+	case <-e.fadeDone:
+	default:
+		close(e.fadeDone)
+	}
+	e.fadeWG.Wait()
+}
+
+// startFade registers a fade under key, preempting any fade already running
+// on that key, and returns a cancel func that freezes the parameter at its
+// current interpolated value.
+func (e *Engine) startFade(key fadeKey, from, to float32, duration time.Duration, curve FadeCurve, apply func(float32) error) func() {
+	e.ensureFadeScheduler()
+
+	fade := &activeFade{from: from, to: to, curve: curve, start: time.Now(), duration: duration, apply: apply}
+
+	e.fadesMu.Lock()
+	if e.fades == nil {
+		e.fades = make(map[fadeKey]*activeFade)
+	}
+	e.fades[key] = fade
+	e.fadesMu.Unlock()
+
+	var cancelled int32
+	return func() {
+		if !atomic.CompareAndSwapInt32(&cancelled, 0, 1) {
+			return
+		}
+		e.fadesMu.Lock()
+		if e.fades[key] == fade {
+			delete(e.fades, key)
+		}
+		e.fadesMu.Unlock()
+	}
+}
+
+// FadeConnectionVolume ramps the gain of a specific source->mixer input
+// connection from its current value to target over duration, sampling curve
+// on Engine's fade scheduler goroutine (see SetFadeTickRate). Starting a new
+// fade on the same connection+bus preempts any fade already running there.
+// Calling the returned cancel freezes the connection's volume at whatever
+// value the fade had reached.
+//
+// Invalid arguments (a nil engine/pointer, or a target outside 0.0-1.0) make
+// FadeConnectionVolume a no-op: it returns a cancel func that does nothing,
+// rather than an error, matching the fire-and-forget shape SDL_mixer-style
+// fade APIs use.
+func (e *Engine) FadeConnectionVolume(sourcePtr, mixerPtr unsafe.Pointer, destBus int, target float32, duration time.Duration, curve FadeCurve) (cancel func()) {
+	if e == nil || e.ptr == nil || sourcePtr == nil || mixerPtr == nil || target < 0.0 || target > 1.0 {
+		return func() {}
+	}
+	from, err := e.GetConnectionVolume(sourcePtr, mixerPtr, destBus)
+	if err != nil {
+		return func() {}
+	}
+	key := fadeKey{kind: fadeTargetConnectionVolume, sourcePtr: sourcePtr, mixerPtr: mixerPtr, bus: destBus}
+	return e.startFade(key, from, target, duration, curve, func(v float32) error {
+		return e.SetConnectionVolume(sourcePtr, mixerPtr, destBus, v)
+	})
+}
+
+// FadeConnectionPan ramps the pan of a specific source->mixer input
+// connection from its current value to target over duration; see
+// FadeConnectionVolume for the scheduler, preemption, and cancel semantics.
+func (e *Engine) FadeConnectionPan(sourcePtr, mixerPtr unsafe.Pointer, destBus int, target float32, duration time.Duration, curve FadeCurve) (cancel func()) {
+	if e == nil || e.ptr == nil || sourcePtr == nil || mixerPtr == nil || target < -1.0 || target > 1.0 {
+		return func() {}
+	}
+	from, err := e.GetConnectionPan(sourcePtr, mixerPtr, destBus)
+	if err != nil {
+		return func() {}
+	}
+	key := fadeKey{kind: fadeTargetConnectionPan, sourcePtr: sourcePtr, mixerPtr: mixerPtr, bus: destBus}
+	return e.startFade(key, from, target, duration, curve, func(v float32) error {
+		return e.SetConnectionPan(sourcePtr, mixerPtr, destBus, v)
+	})
+}
+
+// FadeMixerVolume ramps a mixer's per-bus volume from its current value to
+// target over duration; see FadeConnectionVolume for the scheduler,
+// preemption, and cancel semantics.
+func (e *Engine) FadeMixerVolume(mixerPtr unsafe.Pointer, bus int, target float32, duration time.Duration, curve FadeCurve) (cancel func()) {
+	if e == nil || e.ptr == nil || mixerPtr == nil || target < 0.0 || target > 1.0 {
+		return func() {}
+	}
+	from, err := e.GetMixerVolumeForBus(mixerPtr, bus)
+	if err != nil {
+		return func() {}
+	}
+	key := fadeKey{kind: fadeTargetMixerVolume, mixerPtr: mixerPtr, bus: bus}
+	return e.startFade(key, from, target, duration, curve, func(v float32) error {
+		return e.SetMixerVolumeForBus(mixerPtr, v, bus)
+	})
+}
+
+// FadeMixerPan ramps a mixer's per-bus pan from its current value to target
+// over duration; see FadeConnectionVolume for the scheduler, preemption, and
+// cancel semantics. FadeEqualPower is the conventional curve for a pan
+// crossfade since it keeps perceived loudness constant across the sweep.
+func (e *Engine) FadeMixerPan(mixerPtr unsafe.Pointer, bus int, target float32, duration time.Duration, curve FadeCurve) (cancel func()) {
+	if e == nil || e.ptr == nil || mixerPtr == nil || target < -1.0 || target > 1.0 {
+		return func() {}
+	}
+	from, err := e.GetMixerPanForBus(mixerPtr, bus)
+	if err != nil {
+		return func() {}
+	}
+	key := fadeKey{kind: fadeTargetMixerPan, mixerPtr: mixerPtr, bus: bus}
+	return e.startFade(key, from, target, duration, curve, func(v float32) error {
+		return e.SetMixerPanForBus(mixerPtr, v, bus)
+	})
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFadeMixerVolumeReachesTarget(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.0, 0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	cancel := eng.FadeMixerVolume(mixerPtr, 0, 1.0, 40*time.Millisecond, FadeLinear)
+	defer cancel()
+
+	time.Sleep(200 * time.Millisecond)
+	volume, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("Failed to get volume: %v", err)
+	}
+	if volume < 0.99 {
+		t.Errorf("expected volume to reach ~1.0 after the fade completes, got %v", volume)
+	}
+}
+
+func TestFadeMixerVolumeCancelFreezesValue(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.0, 0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	cancel := eng.FadeMixerVolume(mixerPtr, 0, 1.0, time.Second, FadeLinear)
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	frozen, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("Failed to get volume: %v", err)
+	}
+	if frozen <= 0.0 || frozen >= 1.0 {
+		t.Errorf("expected cancel to freeze volume partway through the fade, got %v", frozen)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	stillFrozen, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("Failed to get volume: %v", err)
+	}
+	if stillFrozen != frozen {
+		t.Errorf("expected volume to stay frozen at %v after cancel, got %v", frozen, stillFrozen)
+	}
+}
+
+func TestFadeMixerVolumePreemptsPriorFade(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.0, 0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	eng.FadeMixerVolume(mixerPtr, 0, 1.0, time.Second, FadeLinear)
+	time.Sleep(50 * time.Millisecond)
+	cancel := eng.FadeMixerVolume(mixerPtr, 0, 0.2, 40*time.Millisecond, FadeLinear)
+	defer cancel()
+
+	time.Sleep(200 * time.Millisecond)
+	volume, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("Failed to get volume: %v", err)
+	}
+	if volume < 0.15 || volume > 0.25 {
+		t.Errorf("expected the second fade to preempt the first and settle near 0.2, got %v", volume)
+	}
+}
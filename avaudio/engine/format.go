@@ -10,7 +10,7 @@ package engine
 AudioFormatResult audioformat_new_mono(double sampleRate);
 AudioFormatResult audioformat_new_stereo(double sampleRate);
 AudioFormatResult audioformat_new_with_channels(double sampleRate, int channels, bool interleaved);
-AudioFormatResult audioformat_new_from_spec(double sampleRate, int channels, bool interleaved);
+AudioFormatResult audioformat_new_from_spec(double sampleRate, int channels, bool interleaved, int commonFormat);
 double audioformat_get_sample_rate(AudioFormat* wrapper);
 int audioformat_get_channel_count(AudioFormat* wrapper);
 bool audioformat_is_interleaved(AudioFormat* wrapper);
@@ -28,17 +28,40 @@ import (
 // This provides better type safety than using unsafe.Pointer directly
 type Format struct {
 	ptr    *C.AudioFormat
-	engine *Engine // Reference to the engine that created this format
+	engine *Engine      // Reference to the engine that created this format
+	format SampleFormat // the SampleFormat this Format was built with
 }
 
 // EnhancedAudioSpec extends the basic AudioSpec with format-specific options
 // This consolidates the format package's AudioSpec into the engine package
 type EnhancedAudioSpec struct {
-	SampleRate   float64 // 44100, 48000, 96000 Hz
-	BufferSize   int     // 256, 512, 1024, 2048 samples (engine-specific)
-	BitDepth     int     // 16, 24, 32 bits per sample (engine-specific)
-	ChannelCount int     // 1 (mono), 2 (stereo), etc.
-	Interleaved  bool    // true = interleaved samples, false = non-interleaved (from format package)
+	SampleRate   float64      // 44100, 48000, 96000 Hz
+	BufferSize   int          // 256, 512, 1024, 2048 samples (engine-specific)
+	SampleFormat SampleFormat // sample storage format; see SampleFormat
+	ChannelCount int          // 1 (mono), 2 (stereo), etc.
+	Interleaved  bool         // true = interleaved samples, false = non-interleaved (from format package)
+
+	// StreamBufferFrames sizes the prefetch ring buffer used when a player is
+	// loaded via LoadFileStreaming with a registered Decoder (see decoder.go).
+	// 0 selects a decoder-appropriate default; ignored for formats AVAudioFile
+	// opens directly.
+	StreamBufferFrames int
+
+	// InputDeviceUID and OutputDeviceUID explicitly select devices by UID
+	// (see Devices in devices.go) rather than using the system default.
+	// Empty strings keep the system default for that direction. Setting
+	// either also makes NewFormat validate SampleRate/ChannelCount against
+	// that device's devices.AudioDevice.SupportedFormats, returning
+	// ErrFormatUnsupported if it doesn't fit - see NegotiateFormat to pick
+	// a fitting spec instead of guessing one.
+	InputDeviceUID  string
+	OutputDeviceUID string
+}
+
+// BitDepth returns spec.SampleFormat.BitDepth, kept as a derived property
+// for code written against the old bare BitDepth int field.
+func (spec EnhancedAudioSpec) BitDepth() int {
+	return spec.SampleFormat.BitDepth()
 }
 
 // NewFormat creates a format with specific specifications
@@ -56,11 +79,27 @@ func (e *Engine) NewFormat(spec EnhancedAudioSpec) (*Format, error) {
 		return nil, errors.New("channel count must be positive")
 	}
 
+	if uid := spec.OutputDeviceUID; uid != "" {
+		if err := validateSpecAgainstDevice(uid, spec); err != nil {
+			return nil, err
+		}
+	} else if uid := spec.InputDeviceUID; uid != "" {
+		if err := validateSpecAgainstDevice(uid, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	commonFormat, err := avAudioCommonFormat(spec.SampleFormat)
+	if err != nil {
+		return nil, err
+	}
+
 	cInterleaved := C.bool(spec.Interleaved)
 	result := C.audioformat_new_from_spec(
 		C.double(spec.SampleRate),
 		C.int(spec.ChannelCount),
 		cInterleaved,
+		C.int(commonFormat),
 	)
 
 	if result.error != nil {
@@ -70,6 +109,7 @@ func (e *Engine) NewFormat(spec EnhancedAudioSpec) (*Format, error) {
 	format := &Format{
 		ptr:    (*C.AudioFormat)(result.result),
 		engine: e,
+		format: spec.SampleFormat,
 	}
 
 	return format, nil
@@ -93,6 +133,7 @@ func (e *Engine) NewMonoFormat(sampleRate float64) (*Format, error) {
 	format := &Format{
 		ptr:    (*C.AudioFormat)(result.result),
 		engine: e,
+		format: SampleFormatFloat32,
 	}
 
 	return format, nil
@@ -116,6 +157,7 @@ func (e *Engine) NewStereoFormat(sampleRate float64) (*Format, error) {
 	format := &Format{
 		ptr:    (*C.AudioFormat)(result.result),
 		engine: e,
+		format: SampleFormatFloat32,
 	}
 
 	return format, nil
@@ -149,6 +191,7 @@ func (e *Engine) NewFormatWithChannels(sampleRate float64, channels int, interle
 	format := &Format{
 		ptr:    (*C.AudioFormat)(result.result),
 		engine: e,
+		format: SampleFormatFloat32,
 	}
 
 	return format, nil
@@ -181,6 +224,28 @@ func (f *Format) IsInterleaved() bool {
 	return bool(C.audioformat_is_interleaved(f.ptr))
 }
 
+// SampleFormat returns the sample storage format this Format was built
+// with - SampleFormatFloat32 for every constructor except NewFormat, which
+// takes it from EnhancedAudioSpec.SampleFormat.
+func (f *Format) SampleFormat() SampleFormat {
+	if f == nil || f.ptr == nil {
+		return SampleFormatFloat32
+	}
+
+	return f.format
+}
+
+// BytesPerFrame returns the size in bytes of one frame of this format -
+// SampleFormat.BytesPerSample times ChannelCount, whether the format is
+// interleaved or not.
+func (f *Format) BytesPerFrame() int {
+	if f == nil || f.ptr == nil {
+		return 0
+	}
+
+	return f.SampleFormat().BytesPerSample() * f.ChannelCount()
+}
+
 // IsEqual compares two formats for equality
 func (f *Format) IsEqual(other *Format) bool {
 	if f == nil || f.ptr == nil || other == nil || other.ptr == nil {
@@ -212,7 +277,7 @@ func (f *Format) ToSpec() EnhancedAudioSpec {
 	return EnhancedAudioSpec{
 		SampleRate:   f.SampleRate(),
 		BufferSize:   engineSpec.BufferSize, // From engine
-		BitDepth:     engineSpec.BitDepth,   // From engine
+		SampleFormat: f.SampleFormat(),
 		ChannelCount: f.ChannelCount(),
 		Interleaved:  f.IsInterleaved(),
 	}
@@ -311,7 +376,7 @@ func (e *Engine) GetEngineFormat() (*Format, error) {
 	enhancedSpec := EnhancedAudioSpec{
 		SampleRate:   spec.SampleRate,
 		BufferSize:   spec.BufferSize,
-		BitDepth:     spec.BitDepth,
+		SampleFormat: SampleFormatFromBitDepth(spec.BitDepth),
 		ChannelCount: spec.ChannelCount,
 		Interleaved:  false, // Default to non-interleaved
 	}
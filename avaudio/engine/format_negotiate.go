@@ -0,0 +1,221 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// commonSampleRates lists the rates audio hardware typically clocks at, in
+// no particular priority order. nearestRateIn tries these first when a
+// device's own range doesn't bracket the caller's preferred rate exactly,
+// rather than settling for an arbitrary in-between value.
+var commonSampleRates = []float64{44100, 48000, 88200, 96000, 176400, 192000}
+
+// ErrFormatUnsupported reports that an EnhancedAudioSpec passed to NewFormat
+// (via its InputDeviceUID/OutputDeviceUID) falls outside every
+// devices.SupportedFormatRange the named device reports, along with the
+// closest format NewFormat would have built instead - pass Suggested back
+// into NewFormat, or to NegotiateFormat, to get a format that device will
+// actually accept.
+type ErrFormatUnsupported struct {
+	Requested EnhancedAudioSpec
+	Device    string
+	Suggested EnhancedAudioSpec
+}
+
+func (e *ErrFormatUnsupported) Error() string {
+	return fmt.Sprintf("format %.0fHz/%dch unsupported by device %q; nearest supported match is %.0fHz/%dch",
+		e.Requested.SampleRate, e.Requested.ChannelCount, e.Device, e.Suggested.SampleRate, e.Suggested.ChannelCount)
+}
+
+// NegotiateFormat picks the closest format device actually supports to
+// preferred - walking its SupportedFormats channel counts and sample-rate
+// ranges (falling back to commonSampleRates within range) - and builds it
+// with NewFormat. Use this instead of guessing a spec and getting
+// ErrFormatUnsupported back from NewFormat.
+func (e *Engine) NegotiateFormat(device *devices.AudioDevice, preferred EnhancedAudioSpec) (*Format, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	if device == nil {
+		return nil, errors.New("device cannot be nil")
+	}
+
+	ranges := device.SupportedFormats()
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("device %q reports no supported formats", device.UID)
+	}
+
+	return e.NewFormat(nearestSupportedSpec(*device, ranges, preferred))
+}
+
+// nearestSupportedSpec returns the EnhancedAudioSpec closest to preferred
+// that one of dev's SupportedFormatRange entries actually covers.
+func nearestSupportedSpec(dev devices.AudioDevice, ranges []devices.SupportedFormatRange, preferred EnhancedAudioSpec) EnhancedAudioSpec {
+	best := ranges[0]
+	bestScore := rangeDistance(best, preferred)
+	for _, r := range ranges[1:] {
+		if score := rangeDistance(r, preferred); score < bestScore {
+			best, bestScore = r, score
+		}
+	}
+
+	spec := preferred
+	spec.SampleRate = nearestRateIn(best, preferred.SampleRate)
+	spec.ChannelCount = best.ChannelCount
+	return spec
+}
+
+// rangeDistance scores how far r is from preferred: channel-count
+// mismatches dominate (picking the wrong channel count changes what the
+// format is for), sample-rate distance only breaks ties between ranges
+// that already match on channel count.
+func rangeDistance(r devices.SupportedFormatRange, preferred EnhancedAudioSpec) float64 {
+	channelDist := math.Abs(float64(r.ChannelCount - preferred.ChannelCount))
+
+	rateDist := 0.0
+	if preferred.SampleRate < r.MinSampleRate {
+		rateDist = r.MinSampleRate - preferred.SampleRate
+	} else if preferred.SampleRate > r.MaxSampleRate {
+		rateDist = preferred.SampleRate - r.MaxSampleRate
+	}
+
+	return channelDist*1e6 + rateDist
+}
+
+// nearestRateIn returns the sample rate within r's [MinSampleRate,
+// MaxSampleRate] closest to preferred, preferring a commonSampleRates entry
+// that falls in range over an arbitrary clamp to the range's bounds.
+func nearestRateIn(r devices.SupportedFormatRange, preferred float64) float64 {
+	best := preferred
+	if best < r.MinSampleRate {
+		best = r.MinSampleRate
+	}
+	if best > r.MaxSampleRate {
+		best = r.MaxSampleRate
+	}
+	bestDist := math.Abs(best - preferred)
+
+	for _, candidate := range commonSampleRates {
+		if candidate < r.MinSampleRate || candidate > r.MaxSampleRate {
+			continue
+		}
+		if d := math.Abs(candidate - preferred); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// FormatPolicy controls how Engine.Connect reacts when its AudioSpec's
+// sample rate doesn't match the system's current default output device.
+// Set it via WithFormatPolicy at construction, or Engine.SetFormatPolicy
+// afterwards. This only governs the high-level Connect path -
+// ConnectWithFormat callers who supply their own formatPtr opt out of this
+// negotiation entirely.
+type FormatPolicy int
+
+const (
+	// FormatPolicyAutoResample (the zero value, and Connect's long-standing
+	// behavior) always connects using the format AudioSpec derives,
+	// regardless of what the hardware is actually running at -
+	// AVAudioEngine inserts its own sample-rate converter at the
+	// connection boundary when the two don't match.
+	FormatPolicyAutoResample FormatPolicy = iota
+	// FormatPolicyStrict refuses to Connect when AudioSpec's sample rate
+	// isn't among the default output device's supported rates, returning a
+	// *FormatMismatchError instead of letting AVAudioEngine silently
+	// insert a converter.
+	FormatPolicyStrict
+	// FormatPolicyPreferSource is equivalent to FormatPolicyAutoResample -
+	// named separately so a caller choosing it reads as a deliberate "use
+	// my AudioSpec" decision rather than a side effect of AutoResample.
+	FormatPolicyPreferSource
+	// FormatPolicyPreferDest connects with a nil format, leaving
+	// AVAudioEngine to infer the connection's format from whatever the
+	// destination node is already configured with instead of imposing
+	// AudioSpec on it.
+	FormatPolicyPreferDest
+)
+
+// SetFormatPolicy changes the FormatPolicy e.Connect enforces. See
+// WithFormatPolicy to set it at construction time instead.
+func (e *Engine) SetFormatPolicy(policy FormatPolicy) {
+	if e == nil {
+		return
+	}
+	e.formatPolicy = policy
+}
+
+// FormatMismatchError is returned by Connect under FormatPolicyStrict when
+// AudioSpec's sample rate isn't one the current default output device
+// supports.
+type FormatMismatchError struct {
+	EngineSampleRate  float64
+	Device            string
+	DeviceSampleRates []int
+}
+
+func (e *FormatMismatchError) Error() string {
+	return fmt.Sprintf("engine: AudioSpec sample rate %.0fHz not supported by default output device %q (supports %v Hz)",
+		e.EngineSampleRate, e.Device, e.DeviceSampleRates)
+}
+
+// sampleRateMismatch reports whether e's AudioSpec.SampleRate is absent from
+// the current default output device's supported rates, returning a
+// *FormatMismatchError describing the mismatch if so, or nil if the rate
+// fits or the device list can't be read.
+func (e *Engine) sampleRateMismatch() *FormatMismatchError {
+	current, err := Devices()
+	if err != nil {
+		return nil
+	}
+	for _, d := range current {
+		if !d.IsDefaultOutput || len(d.SampleRates) == 0 {
+			continue
+		}
+		for _, rate := range d.SampleRates {
+			if float64(rate) == e.spec.SampleRate {
+				return nil
+			}
+		}
+		return &FormatMismatchError{
+			EngineSampleRate:  e.spec.SampleRate,
+			Device:            d.Name,
+			DeviceSampleRates: d.SampleRates,
+		}
+	}
+	return nil
+}
+
+// validateSpecAgainstDevice checks spec against the device named by uid, if
+// any, called from NewFormat when EnhancedAudioSpec.InputDeviceUID/
+// OutputDeviceUID is set. A lookup failure or unknown UID is not itself an
+// error here - NewFormat's job is building an AVAudioFormat, not resolving
+// device identity - it only returns ErrFormatUnsupported when the device is
+// found and its SupportedFormats rules the spec out.
+func validateSpecAgainstDevice(uid string, spec EnhancedAudioSpec) error {
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		return nil
+	}
+
+	dev := audioDevices.ByUID(uid)
+	if dev == nil {
+		return nil
+	}
+
+	ranges := dev.SupportedFormats()
+	if len(ranges) == 0 || dev.SupportsFormat(spec.SampleRate, spec.ChannelCount) {
+		return nil
+	}
+
+	return &ErrFormatUnsupported{
+		Requested: spec,
+		Device:    uid,
+		Suggested: nearestSupportedSpec(*dev, ranges, spec),
+	}
+}
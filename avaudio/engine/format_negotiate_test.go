@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+func TestNearestSupportedSpecPicksClosestRateAndChannels(t *testing.T) {
+	dev := devices.AudioDevice{
+		SupportedSampleRates: []int{44100, 48000, 96000},
+		SupportedBitDepths:   []int{24, 32},
+		InputChannelCount:    2,
+		OutputChannelCount:   6,
+	}
+	ranges := dev.SupportedFormats()
+
+	spec := nearestSupportedSpec(dev, ranges, EnhancedAudioSpec{SampleRate: 44100, ChannelCount: 2})
+	if spec.SampleRate != 44100 || spec.ChannelCount != 2 {
+		t.Fatalf("expected an exact match to survive unchanged, got %+v", spec)
+	}
+}
+
+func TestNearestSupportedSpecClampsUnsupportedRate(t *testing.T) {
+	dev := devices.AudioDevice{
+		SupportedSampleRates: []int{44100, 48000, 96000},
+		SupportedBitDepths:   []int{32},
+		InputChannelCount:    2,
+	}
+	ranges := dev.SupportedFormats()
+
+	spec := nearestSupportedSpec(dev, ranges, EnhancedAudioSpec{SampleRate: 192000, ChannelCount: 2})
+	if spec.SampleRate != 96000 {
+		t.Errorf("expected the rate to clamp to the device's max (96000), got %v", spec.SampleRate)
+	}
+	if spec.ChannelCount != 2 {
+		t.Errorf("expected channel count to stay at the only supported value, got %d", spec.ChannelCount)
+	}
+}
+
+func TestNearestSupportedSpecPrefersMatchingChannelsOverExactRate(t *testing.T) {
+	dev := devices.AudioDevice{
+		SupportedSampleRates: []int{48000},
+		SupportedBitDepths:   []int{32},
+		InputChannelCount:    2,
+		OutputChannelCount:   8,
+	}
+	ranges := dev.SupportedFormats()
+
+	spec := nearestSupportedSpec(dev, ranges, EnhancedAudioSpec{SampleRate: 48000, ChannelCount: 2})
+	if spec.ChannelCount != 2 {
+		t.Fatalf("expected the closer channel count (2) to win over the farther one (8), got %d", spec.ChannelCount)
+	}
+}
+
+func TestNearestRateInPrefersCommonRateOverClamp(t *testing.T) {
+	r := devices.SupportedFormatRange{MinSampleRate: 44100, MaxSampleRate: 192000, ChannelCount: 2}
+
+	if rate := nearestRateIn(r, 50000); rate != 48000 {
+		t.Errorf("expected 50000 to snap to the common rate 48000, got %v", rate)
+	}
+}
+
+func TestWithFormatPolicyConfiguresEngineAtConstruction(t *testing.T) {
+	spec := DefaultAudioSpec()
+	e, err := New(spec, WithFormatPolicy(FormatPolicyStrict))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	if e.formatPolicy != FormatPolicyStrict {
+		t.Errorf("expected WithFormatPolicy to set FormatPolicyStrict, got %v", e.formatPolicy)
+	}
+}
+
+func TestSetFormatPolicyOverridesConstructionPolicy(t *testing.T) {
+	e, err := New(DefaultAudioSpec(), WithFormatPolicy(FormatPolicyStrict))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	e.SetFormatPolicy(FormatPolicyPreferDest)
+	if e.formatPolicy != FormatPolicyPreferDest {
+		t.Errorf("expected SetFormatPolicy to override the construction-time policy, got %v", e.formatPolicy)
+	}
+}
+
+func TestConnectStrictPolicyRejectsSampleRateMismatch(t *testing.T) {
+	available, err := Devices()
+	if err != nil {
+		t.Fatalf("Devices failed: %v", err)
+	}
+	hasDefaultOutput := false
+	for _, d := range available {
+		if d.IsDefaultOutput && len(d.SampleRates) > 0 {
+			hasDefaultOutput = true
+		}
+	}
+	if !hasDefaultOutput {
+		t.Skip("skipping: no default output device reporting supported sample rates")
+	}
+
+	spec := DefaultAudioSpec()
+	spec.SampleRate = 1 // no real device supports a 1Hz sample rate
+	e, err := New(spec, WithFormatPolicy(FormatPolicyStrict))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	mainMixer, err := e.MainMixerNode()
+	if err != nil {
+		t.Fatalf("MainMixerNode failed: %v", err)
+	}
+	outputNode, err := e.OutputNode()
+	if err != nil {
+		t.Fatalf("OutputNode failed: %v", err)
+	}
+
+	err = e.Connect(mainMixer, outputNode, 0, 0)
+	if err == nil {
+		t.Fatal("expected Connect to reject a 1Hz sample rate under FormatPolicyStrict")
+	}
+	var mismatch *FormatMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Errorf("expected a *FormatMismatchError, got %T: %v", err, err)
+	}
+}
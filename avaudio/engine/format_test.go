@@ -75,10 +75,10 @@ func TestFormatIntegration(t *testing.T) {
 	t.Log("📼 Test 4: Creating mono format from EnhancedAudioSpec")
 	enhancedSpec := EnhancedAudioSpec{
 		SampleRate:   22050,
-		BufferSize:   1024,  // This will be used for ToSpec() but not format creation
-		BitDepth:     16,    // This will be used for ToSpec() but not format creation
-		ChannelCount: 1,     // Mono
-		Interleaved:  false, // Doesn't matter for mono, but let's be explicit
+		BufferSize:   1024,              // This will be used for ToSpec() but not format creation
+		SampleFormat: SampleFormatInt16, // This will be used for ToSpec() but not format creation
+		ChannelCount: 1,                 // Mono
+		Interleaved:  false,             // Doesn't matter for mono, but let's be explicit
 	}
 
 	specFormat, err := engine.NewFormat(enhancedSpec)
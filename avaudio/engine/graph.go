@@ -0,0 +1,289 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// graphEdgeRecord is one Connect/ConnectWithFormat edge, tracked alongside
+// (not instead of) mixerConnections - mixerConnections is keyed by
+// destination only, which is enough for the per-bus volume/pan control it
+// backs, but not enough for DisconnectNodeOutput to find the one edge
+// leaving a given source bus. graphEdgeRecord carries both sides' bus
+// numbers so it can.
+type graphEdgeRecord struct {
+	sourcePtr, destPtr unsafe.Pointer
+	fromBus, toBus     int
+}
+
+// trackNode records nodePtr as attached, for Graph's node list. Calling it
+// again for an already-tracked pointer is a no-op - it does not clear a name
+// set via NameNode.
+func (e *Engine) trackNode(nodePtr unsafe.Pointer) {
+	if e.attachedNodes == nil {
+		e.attachedNodes = make(map[unsafe.Pointer]string)
+	}
+	if _, ok := e.attachedNodes[nodePtr]; !ok {
+		e.attachedNodes[nodePtr] = ""
+	}
+}
+
+// untrackNode removes nodePtr from Graph's node list and drops every edge
+// touching it, mirroring what Detach just did to the native graph.
+func (e *Engine) untrackNode(nodePtr unsafe.Pointer) {
+	delete(e.attachedNodes, nodePtr)
+
+	kept := e.graphEdges[:0]
+	for _, edge := range e.graphEdges {
+		if edge.sourcePtr == nodePtr || edge.destPtr == nodePtr {
+			continue
+		}
+		kept = append(kept, edge)
+	}
+	e.graphEdges = kept
+}
+
+// NameNode labels ptr with name for Graph's output. This only affects what
+// GraphNode.Name reports - AVAudioNode has no settable name of its own to
+// change.
+func (e *Engine) NameNode(ptr unsafe.Pointer, name string) {
+	if e == nil || ptr == nil {
+		return
+	}
+	if e.attachedNodes == nil {
+		e.attachedNodes = make(map[unsafe.Pointer]string)
+	}
+	e.attachedNodes[ptr] = name
+}
+
+// NameMixer labels mixerPtr with name, the identifier CaptureMixerScene and
+// ApplyMixerScene/MorphToScene use to address this mixer in a persisted
+// MixerScene - pointers aren't stable across a process restart, but names
+// are. It's the same per-engine name map NameNode uses, so a mixer already
+// named for Graph's output already has a name the scene system can use too.
+func (e *Engine) NameMixer(mixerPtr unsafe.Pointer, name string) {
+	e.NameNode(mixerPtr, name)
+}
+
+// mixerName returns mixerPtr's name, as assigned by NameMixer or NameNode,
+// and whether one was ever assigned.
+func (e *Engine) mixerName(mixerPtr unsafe.Pointer) (string, bool) {
+	if e.attachedNodes == nil {
+		return "", false
+	}
+	name, ok := e.attachedNodes[mixerPtr]
+	return name, ok && name != ""
+}
+
+// resolveMixerName finds the pointer last assigned name via NameMixer or
+// NameNode.
+func (e *Engine) resolveMixerName(name string) (unsafe.Pointer, bool) {
+	for ptr, n := range e.attachedNodes {
+		if n == name {
+			return ptr, true
+		}
+	}
+	return nil, false
+}
+
+// trackEdge records a Connect/ConnectWithFormat edge for Graph. Starting a
+// new edge from the same source/fromBus replaces whatever edge was there,
+// matching AVAudioEngine's own one-destination-per-output-bus behavior.
+func (e *Engine) trackEdge(sourcePtr, destPtr unsafe.Pointer, fromBus, toBus int) {
+	for i, edge := range e.graphEdges {
+		if edge.sourcePtr == sourcePtr && edge.fromBus == fromBus {
+			e.graphEdges[i] = graphEdgeRecord{sourcePtr, destPtr, fromBus, toBus}
+			return
+		}
+	}
+	e.graphEdges = append(e.graphEdges, graphEdgeRecord{sourcePtr, destPtr, fromBus, toBus})
+}
+
+// edgeFrom returns the destination and input bus of the edge leaving
+// sourcePtr's outputBus, if Graph is tracking one.
+func (e *Engine) edgeFrom(sourcePtr unsafe.Pointer, outputBus int) (destPtr unsafe.Pointer, toBus int, ok bool) {
+	for _, edge := range e.graphEdges {
+		if edge.sourcePtr == sourcePtr && edge.fromBus == outputBus {
+			return edge.destPtr, edge.toBus, true
+		}
+	}
+	return nil, 0, false
+}
+
+// untrackEdge drops the edge leaving sourcePtr's outputBus, if any.
+func (e *Engine) untrackEdge(sourcePtr unsafe.Pointer, outputBus int) {
+	for i, edge := range e.graphEdges {
+		if edge.sourcePtr == sourcePtr && edge.fromBus == outputBus {
+			e.graphEdges = append(e.graphEdges[:i], e.graphEdges[i+1:]...)
+			return
+		}
+	}
+}
+
+// untrackEdgeByDest drops the edge arriving at destPtr's inputBus, if any -
+// the DisconnectNodeInput counterpart to untrackEdge.
+func (e *Engine) untrackEdgeByDest(destPtr unsafe.Pointer, inputBus int) {
+	for i, edge := range e.graphEdges {
+		if edge.destPtr == destPtr && edge.toBus == inputBus {
+			e.graphEdges = append(e.graphEdges[:i], e.graphEdges[i+1:]...)
+			return
+		}
+	}
+}
+
+// ConnectedSource returns the source node and output bus currently feeding
+// destPtr's inputBus, and whether Connect/ConnectWithFormat has tracked one
+// - the destination-side counterpart to edgeFrom, exported so a caller
+// about to DisconnectNodeInput can capture the edge it's about to tear down
+// and reconnect it later (see queue.Txn.DisconnectNodeInput's rollback).
+func (e *Engine) ConnectedSource(destPtr unsafe.Pointer, inputBus int) (sourcePtr unsafe.Pointer, fromBus int, ok bool) {
+	if e == nil {
+		return nil, 0, false
+	}
+	for _, edge := range e.graphEdges {
+		if edge.destPtr == destPtr && edge.toBus == inputBus {
+			return edge.sourcePtr, edge.fromBus, true
+		}
+	}
+	return nil, 0, false
+}
+
+// GraphNode is one attached node in a GraphSnapshot. Ptr is a stable,
+// serializable stand-in for the node's pointer identity
+// (fmt.Sprintf("%p", ...)); Name is whatever was last passed to
+// Engine.NameNode, or empty if never named - the native layer doesn't
+// expose a node's AVAudioNode subclass or processing format back to Go, so
+// unlike AVAudioEngine's debugDescription, a GraphNode can't report type or
+// format on its own.
+type GraphNode struct {
+	Ptr  string
+	Name string
+}
+
+// GraphEdge is one tracked Connect/ConnectWithFormat edge between two
+// attached nodes, identified by the same Ptr values as GraphNode.
+type GraphEdge struct {
+	Source  string
+	Dest    string
+	FromBus int
+	ToBus   int
+}
+
+// GraphSnapshot is a point-in-time view of an Engine's node graph, built
+// from the attach/connect calls this package has tracked - see Engine.Graph.
+type GraphSnapshot struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+func ptrLabel(p unsafe.Pointer) string {
+	return fmt.Sprintf("%p", p)
+}
+
+// Graph builds a GraphSnapshot from every node Attach has recorded (that
+// hasn't since been Detach'd) and every edge Connect/ConnectWithFormat has
+// recorded, in Ptr-sorted order so ToDOT/ToJSON output is stable across
+// calls for an unchanged graph.
+func (e *Engine) Graph() GraphSnapshot {
+	var snapshot GraphSnapshot
+	if e == nil {
+		return snapshot
+	}
+
+	for ptr, name := range e.attachedNodes {
+		snapshot.Nodes = append(snapshot.Nodes, GraphNode{Ptr: ptrLabel(ptr), Name: name})
+	}
+	sort.Slice(snapshot.Nodes, func(i, j int) bool { return snapshot.Nodes[i].Ptr < snapshot.Nodes[j].Ptr })
+
+	for _, edge := range e.graphEdges {
+		snapshot.Edges = append(snapshot.Edges, GraphEdge{
+			Source:  ptrLabel(edge.sourcePtr),
+			Dest:    ptrLabel(edge.destPtr),
+			FromBus: edge.fromBus,
+			ToBus:   edge.toBus,
+		})
+	}
+	sort.Slice(snapshot.Edges, func(i, j int) bool {
+		a, b := snapshot.Edges[i], snapshot.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.FromBus < b.FromBus
+	})
+
+	return snapshot
+}
+
+// HasCycle reports whether s's edges form a cycle, treating each edge as
+// directed Source -> Dest.
+func (s GraphSnapshot) HasCycle() bool {
+	adjacency := make(map[string][]string, len(s.Edges))
+	for _, edge := range s.Edges {
+		adjacency[edge.Source] = append(adjacency[edge.Source], edge.Dest)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(s.Nodes))
+
+	var visit func(node string) bool
+	visit = func(node string) bool {
+		switch state[node] {
+		case visiting:
+			return true
+		case done:
+			return false
+		}
+		state[node] = visiting
+		for _, next := range adjacency[node] {
+			if visit(next) {
+				return true
+			}
+		}
+		state[node] = done
+		return false
+	}
+
+	for _, node := range s.Nodes {
+		if state[node.Ptr] == unvisited && visit(node.Ptr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToDOT renders s as a GraphViz "dot" graph, node names (falling back to
+// their Ptr label when unnamed) as labels and edges annotated with the bus
+// numbers they connect.
+func (s GraphSnapshot) ToDOT() string {
+	var b strings.Builder
+	b.WriteString("digraph Engine {\n")
+	for _, node := range s.Nodes {
+		label := node.Name
+		if label == "" {
+			label = node.Ptr
+		}
+		fmt.Fprintf(&b, "  %q [label=%q];\n", node.Ptr, label)
+	}
+	for _, edge := range s.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=\"%d->%d\"];\n", edge.Source, edge.Dest, edge.FromBus, edge.ToBus)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ToJSON renders s as JSON, or nil if it somehow fails to marshal - s is
+// built entirely from strings and ints, so in practice that never happens.
+func (s GraphSnapshot) ToJSON() []byte {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil
+	}
+	return b
+}
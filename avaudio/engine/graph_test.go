@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+)
+
+func TestGraphTracksAttachedNodesAndEdges(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	tone, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("NewTone failed: %v", err)
+	}
+	defer tone.Destroy()
+	nodePtr, err := tone.GetNodePtr()
+	if err != nil {
+		t.Fatalf("GetNodePtr failed: %v", err)
+	}
+
+	if err := e.Attach(nodePtr); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	e.NameNode(nodePtr, "tone")
+
+	mainMixer, err := e.MainMixerNode()
+	if err != nil {
+		t.Fatalf("MainMixerNode failed: %v", err)
+	}
+	if err := e.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	snapshot := e.Graph()
+	if len(snapshot.Edges) == 0 {
+		t.Fatal("expected at least one tracked edge after Connect")
+	}
+
+	var found bool
+	for _, node := range snapshot.Nodes {
+		if node.Name == "tone" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected NameNode's label to show up in Graph's node list")
+	}
+}
+
+func TestDisconnectNodeOutputUntracksExactlyThatEdge(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	tone, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("NewTone failed: %v", err)
+	}
+	defer tone.Destroy()
+	nodePtr, err := tone.GetNodePtr()
+	if err != nil {
+		t.Fatalf("GetNodePtr failed: %v", err)
+	}
+	if err := e.Attach(nodePtr); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+
+	mainMixer, err := e.MainMixerNode()
+	if err != nil {
+		t.Fatalf("MainMixerNode failed: %v", err)
+	}
+	if err := e.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if before := len(e.Graph().Edges); before == 0 {
+		t.Fatal("expected Connect to have tracked an edge")
+	}
+
+	if err := e.DisconnectNodeOutput(nodePtr, 0); err != nil {
+		t.Fatalf("DisconnectNodeOutput failed: %v", err)
+	}
+
+	for _, edge := range e.Graph().Edges {
+		if edge.Source == ptrLabel(nodePtr) && edge.FromBus == 0 {
+			t.Fatal("expected DisconnectNodeOutput to untrack the edge leaving bus 0")
+		}
+	}
+}
+
+func TestGraphSnapshotHasCycleDetectsCycle(t *testing.T) {
+	acyclic := GraphSnapshot{
+		Nodes: []GraphNode{{Ptr: "a"}, {Ptr: "b"}, {Ptr: "c"}},
+		Edges: []GraphEdge{{Source: "a", Dest: "b"}, {Source: "b", Dest: "c"}},
+	}
+	if acyclic.HasCycle() {
+		t.Error("expected a->b->c to not be flagged as a cycle")
+	}
+
+	cyclic := GraphSnapshot{
+		Nodes: []GraphNode{{Ptr: "a"}, {Ptr: "b"}},
+		Edges: []GraphEdge{{Source: "a", Dest: "b"}, {Source: "b", Dest: "a"}},
+	}
+	if !cyclic.HasCycle() {
+		t.Error("expected a->b->a to be flagged as a cycle")
+	}
+}
+
+func TestGraphSnapshotToDOTAndToJSON(t *testing.T) {
+	snapshot := GraphSnapshot{
+		Nodes: []GraphNode{{Ptr: "0x1", Name: "tone"}},
+		Edges: []GraphEdge{{Source: "0x1", Dest: "0x2", FromBus: 0, ToBus: 1}},
+	}
+
+	dot := snapshot.ToDOT()
+	if !strings.Contains(dot, "digraph Engine") || !strings.Contains(dot, "tone") {
+		t.Errorf("expected ToDOT output to name the graph and include node labels, got: %s", dot)
+	}
+
+	j := snapshot.ToJSON()
+	if !strings.Contains(string(j), "tone") {
+		t.Errorf("expected ToJSON output to include node names, got: %s", j)
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GraphTransaction batches graph mutations together with the players whose
+// playback should survive them, so a caller adding or removing a node
+// (TimePitch, an effect chain, a reverb send) doesn't need the
+// engine.Stop(); time.Sleep(...); engine.Start() dance
+// EnableTimePitchEffects' doc comment used to prescribe. AVAudioEngine
+// supports attach/connect/disconnect while the engine is running; what
+// actually needs a moment of quiet is each player node mid-schedule, so
+// Commit pauses and snapshots the included players, runs the queued
+// mutations, then reschedules and resumes them.
+//
+// Use it like:
+//
+//	tx := engine.BeginReconfigure()
+//	tx.Include(player)
+//	if err := player.EnableTimePitchEffectsTx(tx); err != nil { ... }
+//	tx.Enqueue(engine.ConnectCommand(timePitchNode, mixerNode, 0, busIndex))
+//	if err := tx.Commit(); err != nil { ... }
+type GraphTransaction struct {
+	engine  *Engine
+	players []*AudioPlayer
+	actions []func() error
+}
+
+// BeginReconfigure starts a GraphTransaction against e. Nothing is applied
+// until Commit is called.
+func (e *Engine) BeginReconfigure() *GraphTransaction {
+	return &GraphTransaction{engine: e}
+}
+
+// Include registers players whose playback position Commit should preserve
+// across the transaction - normally every player whose node is attached,
+// connected, or disconnected as part of this transaction.
+func (tx *GraphTransaction) Include(players ...*AudioPlayer) *GraphTransaction {
+	tx.players = append(tx.players, players...)
+	return tx
+}
+
+// Enqueue adds GraphCommands for Commit to apply, in the order they were
+// enqueued relative to any other action (e.g. EnableTimePitchEffectsTx)
+// queued on the same transaction.
+func (tx *GraphTransaction) Enqueue(cmds ...GraphCommand) *GraphTransaction {
+	tx.actions = append(tx.actions, func() error { return tx.engine.Commit(cmds...) })
+	return tx
+}
+
+// enqueueAction adds an arbitrary mutation to run as part of Commit, for
+// player methods (EnableTimePitchEffectsTx and friends) whose native call
+// isn't expressible as a GraphCommand.
+func (tx *GraphTransaction) enqueueAction(action func() error) {
+	tx.actions = append(tx.actions, action)
+}
+
+// txSnapshot is one included player's playback state across a
+// GraphTransaction's mutation, enough for Commit to resume it afterward.
+type txSnapshot struct {
+	player   *AudioPlayer
+	position time.Duration
+}
+
+// Commit pauses every included player that's currently playing, snapshots
+// its position, runs the transaction's queued actions in order, then
+// resumes each paused player from where it left off. A player that wasn't
+// playing when Commit was called is left stopped. If an action fails,
+// Commit stops there and returns that error - actions already applied are
+// not rolled back, matching Engine.Commit's own batch semantics.
+func (tx *GraphTransaction) Commit() error {
+	if tx == nil || tx.engine == nil {
+		return errors.New("transaction is nil")
+	}
+
+	snapshots := make([]txSnapshot, 0, len(tx.players))
+	for _, p := range tx.players {
+		if p == nil || p.ptr == nil {
+			continue
+		}
+		playing, err := p.IsPlaying()
+		if err != nil || !playing {
+			continue
+		}
+		position, err := p.GetCurrentTime()
+		if err != nil {
+			return fmt.Errorf("snapshotting player position before graph mutation: %w", err)
+		}
+		if err := p.Pause(); err != nil {
+			return fmt.Errorf("pausing player before graph mutation: %w", err)
+		}
+		snapshots = append(snapshots, txSnapshot{player: p, position: position})
+	}
+
+	for _, action := range tx.actions {
+		if err := action(); err != nil {
+			return err
+		}
+	}
+
+	for _, snap := range snapshots {
+		if err := snap.player.PlayAt(snap.position.Seconds()); err != nil {
+			return fmt.Errorf("resuming player after graph mutation: %w", err)
+		}
+	}
+	return nil
+}
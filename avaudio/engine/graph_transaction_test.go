@@ -0,0 +1,59 @@
+package engine
+
+import "testing"
+
+func TestGraphTransactionCommitRunsQueuedActionsInOrder(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	player, err := e.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	tx := e.BeginReconfigure()
+	tx.Include(player)
+	if err := player.EnableTimePitchEffectsTx(tx); err != nil {
+		t.Fatalf("EnableTimePitchEffectsTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	enabled, err := player.IsTimePitchEffectsEnabled()
+	if err != nil {
+		t.Fatalf("IsTimePitchEffectsEnabled failed: %v", err)
+	}
+	if !enabled {
+		t.Error("expected TimePitch effects to be enabled after Commit")
+	}
+}
+
+func TestGraphTransactionCommitOnNilTransactionErrors(t *testing.T) {
+	var tx *GraphTransaction
+	if err := tx.Commit(); err == nil {
+		t.Error("expected Commit on a nil transaction to return an error")
+	}
+}
+
+func TestEnableTimePitchEffectsTxRejectsNilTransaction(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	player, err := e.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	if err := player.EnableTimePitchEffectsTx(nil); err == nil {
+		t.Error("expected EnableTimePitchEffectsTx(nil) to return an error")
+	}
+}
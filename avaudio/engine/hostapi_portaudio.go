@@ -0,0 +1,116 @@
+//go:build portaudio
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// PortAudioHostAPI is a HostAPI backed by PortAudio instead of AVAudioEngine,
+// for parity on platforms/configurations with no CoreAudio server to talk to
+// - headless CI, a remote Linux dev box. It only implements the HostAPI
+// seam (device enumeration and a plain callback stream): like NullHostAPI,
+// it gives an Engine no native node graph, so Attach/Connect on an Engine
+// opened against it return ErrHostAPIUnsupported. Build with `-tags
+// portaudio` once github.com/gordonklaus/portaudio is vendored; this file
+// is excluded from ordinary builds so the rest of the package doesn't pick
+// up a PortAudio dependency it doesn't need.
+type PortAudioHostAPI struct{}
+
+// NewPortAudioHostAPI initializes the PortAudio library. Callers should call
+// Terminate when done with every Engine opened against this backend.
+func NewPortAudioHostAPI() (*PortAudioHostAPI, error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, fmt.Errorf("initializing portaudio: %w", err)
+	}
+	return &PortAudioHostAPI{}, nil
+}
+
+// Terminate releases the underlying PortAudio library. Call once, after
+// every Stream opened against this backend has been closed.
+func (p *PortAudioHostAPI) Terminate() error {
+	return portaudio.Terminate()
+}
+
+func (p *PortAudioHostAPI) Name() string { return "portaudio" }
+
+// EnumerateDevices lists every PortAudio host device, input- and
+// output-capable alike.
+func (p *PortAudioHostAPI) EnumerateDevices() ([]HostDevice, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("listing portaudio devices: %w", err)
+	}
+
+	infos := make([]HostDevice, len(devices))
+	for i, d := range devices {
+		infos[i] = HostDevice{
+			Name:    d.Name,
+			UID:     fmt.Sprintf("portaudio:%d", i),
+			Inputs:  d.MaxInputChannels,
+			Outputs: d.MaxOutputChannels,
+		}
+	}
+	return infos, nil
+}
+
+// OpenStream opens a duplex PortAudio stream at spec's sample rate and
+// channel count, feeding RegisterRenderCallback's callback from PortAudio's
+// own audio thread.
+func (p *PortAudioHostAPI) OpenStream(spec AudioSpec) (Stream, error) {
+	return &portAudioStream{spec: spec}, nil
+}
+
+// portAudioStream adapts a portaudio.Stream to the HostAPI Stream interface.
+// The render callback is registered before Start, matching how
+// portaudio.OpenDefaultStream wants its callback at open time - Start opens
+// the underlying stream lazily on first call so RegisterRenderCallback can
+// still be called beforehand, the same ordering NullHostAPI's nullStream
+// allows.
+type portAudioStream struct {
+	spec     AudioSpec
+	callback RenderFunc
+	stream   *portaudio.Stream
+}
+
+func (s *portAudioStream) RegisterRenderCallback(fn RenderFunc) error {
+	s.callback = fn
+	return nil
+}
+
+func (s *portAudioStream) Start() error {
+	if s.callback == nil {
+		return fmt.Errorf("portaudio: no render callback registered")
+	}
+	if s.stream == nil {
+		channels := s.spec.ChannelCount
+		if channels <= 0 {
+			channels = 2
+		}
+
+		stream, err := portaudio.OpenDefaultStream(channels, channels, s.spec.SampleRate, 0, func(in, out []float32) {
+			s.callback(out, in, len(out)/channels)
+		})
+		if err != nil {
+			return fmt.Errorf("opening portaudio stream: %w", err)
+		}
+		s.stream = stream
+	}
+	return s.stream.Start()
+}
+
+func (s *portAudioStream) Stop() error {
+	if s.stream == nil {
+		return nil
+	}
+	return s.stream.Stop()
+}
+
+func (s *portAudioStream) Close() error {
+	if s.stream == nil {
+		return nil
+	}
+	return s.stream.Close()
+}
@@ -0,0 +1,102 @@
+package engine
+
+import "testing"
+
+func TestNullHostAPIEnumerateDevices(t *testing.T) {
+	host := NewNullHostAPI(HostDevice{Name: "Offline In", Inputs: 2}, HostDevice{Name: "Offline Out", Outputs: 2})
+	devices, err := host.EnumerateDevices()
+	if err != nil {
+		t.Fatalf("EnumerateDevices failed: %v", err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(devices))
+	}
+}
+
+func TestNullStreamPumpRequiresStartAndCallback(t *testing.T) {
+	host := NewNullHostAPI()
+	stream, err := host.OpenStream(AudioSpec{SampleRate: 48000, ChannelCount: 2})
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+	ns := stream.(*nullStream)
+
+	if _, err := ns.Pump(nil, 64); err == nil {
+		t.Error("expected error pumping before Start")
+	}
+
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := ns.Pump(nil, 64); err == nil {
+		t.Error("expected error pumping without a registered callback")
+	}
+
+	var gotFrames int
+	if err := stream.RegisterRenderCallback(func(out, in []float32, frameCount int) {
+		gotFrames = frameCount
+		for i := range out {
+			out[i] = 1
+		}
+	}); err != nil {
+		t.Fatalf("RegisterRenderCallback failed: %v", err)
+	}
+
+	out, err := ns.Pump(nil, 64)
+	if err != nil {
+		t.Fatalf("Pump failed: %v", err)
+	}
+	if gotFrames != 64 {
+		t.Errorf("expected callback to see 64 frames, got %d", gotFrames)
+	}
+	if len(out) != 64*2 {
+		t.Errorf("expected %d samples (stereo), got %d", 64*2, len(out))
+	}
+}
+
+func TestNewWithHostAPIStartStop(t *testing.T) {
+	spec := AudioSpec{SampleRate: 48000, ChannelCount: 2, HostAPI: NewNullHostAPI()}
+
+	e, err := NewWithHostAPI(spec)
+	if err != nil {
+		t.Fatalf("NewWithHostAPI failed: %v", err)
+	}
+	defer e.Destroy()
+
+	if e.IsRunning() {
+		t.Fatal("expected a freshly opened HostAPI engine to not be running")
+	}
+	if err := e.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !e.IsRunning() {
+		t.Error("expected engine to report running after Start")
+	}
+	e.Stop()
+	if e.IsRunning() {
+		t.Error("expected engine to report stopped after Stop")
+	}
+}
+
+func TestNewWithHostAPIRejectsGraphOperations(t *testing.T) {
+	spec := AudioSpec{SampleRate: 48000, ChannelCount: 2, HostAPI: NewNullHostAPI()}
+
+	e, err := NewWithHostAPI(spec)
+	if err != nil {
+		t.Fatalf("NewWithHostAPI failed: %v", err)
+	}
+	defer e.Destroy()
+
+	if err := e.Attach(nil); err != ErrHostAPIUnsupported {
+		t.Errorf("expected ErrHostAPIUnsupported from Attach, got %v", err)
+	}
+	if err := e.Connect(nil, nil, 0, 0); err != ErrHostAPIUnsupported {
+		t.Errorf("expected ErrHostAPIUnsupported from Connect, got %v", err)
+	}
+}
+
+func TestNewWithHostAPIRequiresHostAPI(t *testing.T) {
+	if _, err := NewWithHostAPI(AudioSpec{SampleRate: 48000, ChannelCount: 2}); err == nil {
+		t.Fatal("expected NewWithHostAPI to reject a spec with no HostAPI set")
+	}
+}
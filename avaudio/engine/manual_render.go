@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ManualRenderingMode mirrors AVAudioEngineManualRenderingMode: Offline pulls
+// frames synchronously, as fast as the graph can produce them; Realtime
+// paces pulls to wall-clock time (used by hosts that still want a manual
+// pull loop, e.g. an AUv3 host process). Only Offline is wired to the native
+// side today - EnableManualRendering rejects Realtime until that bridging
+// exists.
+type ManualRenderingMode int
+
+const (
+	ManualRenderingModeOffline ManualRenderingMode = iota
+	ManualRenderingModeRealtime
+)
+
+// RenderStats summarizes one RenderOffline call: how many frames were
+// requested versus actually produced, and how many of the underlying pulls
+// came back short (an underrun - the graph had fewer frames ready than
+// maxFrames asked for).
+type RenderStats struct {
+	FramesRequested uint64
+	FramesRendered  uint64
+	Underruns       uint64
+}
+
+// EnableManualRendering switches the engine into manual rendering mode,
+// where RenderOffline pulls frames synchronously instead of a live I/O
+// callback driving them. format's channel count and sample rate determine
+// how RenderOffline lays out the bytes it writes; maxFrames bounds the
+// largest chunk a single pull may request, matching
+// AVAudioEngine.enableManualRenderingMode's maximumFrameCount.
+func (e *Engine) EnableManualRendering(mode ManualRenderingMode, format *Format, maxFrames uint32) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if mode != ManualRenderingModeOffline {
+		return errors.New("engine: only ManualRenderingModeOffline is currently supported")
+	}
+	if format == nil {
+		return errors.New("engine: format must not be nil")
+	}
+	if maxFrames == 0 {
+		return errors.New("engine: maxFrames must be positive")
+	}
+
+	if err := e.SetOfflineRenderingMode(true, int(maxFrames)); err != nil {
+		return err
+	}
+	e.manualRenderingMaxFrames = int(maxFrames)
+	return nil
+}
+
+// DisableManualRendering returns the engine to its normal hardware-clocked
+// mode.
+func (e *Engine) DisableManualRendering() error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if err := e.SetOfflineRenderingMode(false, 0); err != nil {
+		return err
+	}
+	e.manualRenderingMaxFrames = 0
+	return nil
+}
+
+// StreamOffline pulls frames total through the graph in
+// manualRenderingMaxFrames-sized chunks (set by EnableManualRendering),
+// writing interleaved little-endian float32 samples to out as each chunk
+// completes. It stops early - returning ctx.Err() alongside whatever
+// RenderStats were accumulated so far - if ctx is cancelled between pulls.
+// A chunk that comes back with fewer frames than requested counts as an
+// underrun in the returned RenderStats but is not itself an error; the
+// render keeps pulling until frames have been produced or the graph stops
+// producing anything at all.
+//
+// This is named StreamOffline rather than RenderOffline to avoid colliding
+// with the single-pull RenderOffline(frameCount) this method is built on top
+// of (see offline.go) - existing callers of that one are unaffected.
+func (e *Engine) StreamOffline(ctx context.Context, frames uint64, out io.Writer) (RenderStats, error) {
+	var stats RenderStats
+	stats.FramesRequested = frames
+
+	if e == nil || e.ptr == nil {
+		return stats, errors.New("engine is nil")
+	}
+	if e.manualRenderingMaxFrames == 0 {
+		return stats, errors.New("engine: manual rendering is not enabled, call EnableManualRendering first")
+	}
+	if out == nil {
+		return stats, errors.New("engine: out must not be nil")
+	}
+
+	for stats.FramesRendered < frames {
+		select {
+		case <-ctx.Done():
+			return stats, ctx.Err()
+		default:
+		}
+
+		remaining := frames - stats.FramesRendered
+		chunk := uint64(e.manualRenderingMaxFrames)
+		if remaining < chunk {
+			chunk = remaining
+		}
+
+		samples, err := e.RenderOffline(int(chunk))
+		if err != nil {
+			return stats, fmt.Errorf("engine: rendering offline: %w", err)
+		}
+
+		channels := e.spec.ChannelCount
+		if channels <= 0 {
+			channels = 1
+		}
+		rendered := uint64(len(samples) / channels)
+		if rendered == 0 {
+			return stats, nil
+		}
+		if rendered < chunk {
+			stats.Underruns++
+		}
+
+		if err := binary.Write(out, binary.LittleEndian, samples); err != nil {
+			return stats, fmt.Errorf("engine: writing rendered samples: %w", err)
+		}
+
+		stats.FramesRendered += rendered
+	}
+
+	return stats, nil
+}
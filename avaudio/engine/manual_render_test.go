@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+)
+
+func TestStreamOfflineWritesRequestedFrames(t *testing.T) {
+	spec := DefaultAudioSpec()
+	e, err := New(spec)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	tone, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("NewTone failed: %v", err)
+	}
+	defer tone.Destroy()
+	if err := tone.SetFrequency(440.0); err != nil {
+		t.Fatalf("SetFrequency failed: %v", err)
+	}
+	if err := tone.SetAmplitude(0.8); err != nil {
+		t.Fatalf("SetAmplitude failed: %v", err)
+	}
+
+	nodePtr, err := tone.GetNodePtr()
+	if err != nil {
+		t.Fatalf("GetNodePtr failed: %v", err)
+	}
+	if err := e.Attach(nodePtr); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	mainMixer, err := e.MainMixerNode()
+	if err != nil {
+		t.Fatalf("MainMixerNode failed: %v", err)
+	}
+	if err := e.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	format, err := e.NewStandardStereoFormat()
+	if err != nil {
+		t.Fatalf("NewStandardStereoFormat failed: %v", err)
+	}
+	defer format.Destroy()
+
+	const maxFrames = 1024
+	if err := e.EnableManualRendering(ManualRenderingModeOffline, format, maxFrames); err != nil {
+		t.Fatalf("EnableManualRendering failed: %v", err)
+	}
+	defer e.DisableManualRendering()
+
+	var out bytes.Buffer
+	const totalFrames = 4096
+	stats, err := e.StreamOffline(context.Background(), totalFrames, &out)
+	if err != nil {
+		t.Fatalf("StreamOffline failed: %v", err)
+	}
+	if stats.FramesRendered != totalFrames {
+		t.Errorf("expected %d frames rendered, got %d", uint64(totalFrames), stats.FramesRendered)
+	}
+
+	wantBytes := totalFrames * spec.ChannelCount * 4
+	if out.Len() != wantBytes {
+		t.Errorf("expected %d bytes written, got %d", wantBytes, out.Len())
+	}
+}
+
+func TestStreamOfflineRejectsWithoutManualRendering(t *testing.T) {
+	spec := DefaultAudioSpec()
+	e, err := New(spec)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	var out bytes.Buffer
+	if _, err := e.StreamOffline(context.Background(), 1024, &out); err == nil {
+		t.Fatal("expected StreamOffline to fail before EnableManualRendering was called")
+	}
+}
+
+func TestStreamOfflineHonorsContextCancellation(t *testing.T) {
+	spec := DefaultAudioSpec()
+	e, err := New(spec)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	format, err := e.NewStandardStereoFormat()
+	if err != nil {
+		t.Fatalf("NewStandardStereoFormat failed: %v", err)
+	}
+	defer format.Destroy()
+
+	if err := e.EnableManualRendering(ManualRenderingModeOffline, format, 1024); err != nil {
+		t.Fatalf("EnableManualRendering failed: %v", err)
+	}
+	defer e.DisableManualRendering()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	if _, err := e.StreamOffline(ctx, 1<<20, &out); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
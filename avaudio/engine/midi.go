@@ -0,0 +1,452 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/internal/rt"
+	rootmidi "github.com/shaban/macaudio/midi"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// midiCloser is satisfied by *midi.Controller without engine importing the
+// midi package (which itself has no reason to depend on engine); it keeps
+// this a one-way dependency: midi bindings reference AudioPlayer methods
+// directly, and the engine only needs enough of Controller to close it.
+type midiCloser interface {
+	Close() error
+}
+
+var (
+	midiMu          sync.Mutex
+	attachedControl = map[*Engine][]midiCloser{}
+)
+
+// AttachMIDIController records ctrl as owned by this engine so it is closed
+// alongside the engine's other resources. Binding Note/CC/Program messages
+// to AudioPlayer or mixer methods is done via midi.Controller's own
+// BindNote/BindCC/BindProgram before or after attaching; this hook exists
+// so callers don't have to track controller lifetime separately from the
+// engine they're driving.
+func (e *Engine) AttachMIDIController(ctrl midiCloser) {
+	midiMu.Lock()
+	defer midiMu.Unlock()
+	attachedControl[e] = append(attachedControl[e], ctrl)
+}
+
+// closeAttachedControllers closes every MIDI controller attached to e. It
+// is called from Destroy so controllers don't outlive their engine.
+func (e *Engine) closeAttachedControllers() {
+	midiMu.Lock()
+	ctrls := attachedControl[e]
+	delete(attachedControl, e)
+	midiMu.Unlock()
+
+	for _, c := range ctrls {
+		_ = c.Close()
+	}
+}
+
+// DefaultMIDIControlRateHz is the drain goroutine's tick rate when
+// OpenMIDIInput is called without a prior SetMIDIControlRate - how often
+// queued CC values are written to their bound parameters.
+const DefaultMIDIControlRateHz = 200
+
+// MIDIParameterTarget is the seam BindMIDIController writes through.
+// avaudio/pluginchain.PluginChain satisfies it already (SetParameter,
+// SendNoteOn, SendNoteOff) - this package doesn't import pluginchain
+// directly, since pluginchain's own tests already import avaudio/engine and
+// a direct import here would cycle. Same interface-seam approach
+// player_effectchain.go's EffectChain uses to keep this package decoupled
+// from its growing set of Go-side consumers.
+type MIDIParameterTarget interface {
+	SetParameter(effectIndex int, param plugins.Parameter, value float32) error
+	SendNoteOn(effectIndex, channel, note, velocity int) error
+	SendNoteOff(effectIndex, channel, note, velocity int) error
+}
+
+// midiParamKey identifies one bound effect parameter, the same way
+// pluginchain's automationKey does - a fresh BindMIDIController call on the
+// same effectIndex/param.Address replaces whatever was bound there.
+type midiParamKey struct {
+	effectIndex  int
+	paramAddress uint64
+}
+
+// ccBinding is one CC (or 14-bit CC pair) wired to a parameter. channel is
+// -1 when the binding should fire regardless of which channel the message
+// arrived on; lsbCC is -1 unless this is the MSB half of a 14-bit pair, in
+// which case the drain side holds the LSB byte in lsbValue until both
+// halves have arrived at least once.
+type ccBinding struct {
+	target      MIDIParameterTarget
+	effectIndex int
+	param       plugins.Parameter
+	channel     int
+	min, max    float32
+	lsbCC       int
+	lsbValue    byte
+	haveLSB     bool
+}
+
+// scale maps a 7-bit (or, once both halves of a 14-bit pair have arrived,
+// 14-bit) CC value onto [min, max].
+func (b *ccBinding) scale(value int, bits int) float32 {
+	top := float32((1 << bits) - 1)
+	t := float32(value) / top
+	return b.min + (b.max-b.min)*t
+}
+
+// BindMIDIController routes CC cc (channel messages with status 0xB_) to
+// param on the effect at effectIndex on target, scaling the 7-bit CC value
+// (0-127) linearly onto [min, max]. channel restricts the binding to one
+// MIDI channel (0-15); pass -1 to match any channel. Binding the same
+// effectIndex/param.Address again replaces the earlier binding rather than
+// stacking both.
+func (e *Engine) BindMIDIController(cc, channel int, target MIDIParameterTarget, effectIndex int, param plugins.Parameter, min, max float32) error {
+	return e.bindCC(cc, channel, target, effectIndex, param, min, max, -1)
+}
+
+// BindMIDIController14Bit behaves like BindMIDIController, but treats ccMSB
+// and ccLSB as the high and low 7 bits of a single 14-bit value (the
+// convention pairing MIDI CC 0-31 with their 32-63 LSB companions), giving
+// the bound parameter 128x the resolution of a plain 7-bit CC.
+func (e *Engine) BindMIDIController14Bit(ccMSB, ccLSB, channel int, target MIDIParameterTarget, effectIndex int, param plugins.Parameter, min, max float32) error {
+	return e.bindCC(ccMSB, channel, target, effectIndex, param, min, max, ccLSB)
+}
+
+func (e *Engine) bindCC(cc, channel int, target MIDIParameterTarget, effectIndex int, param plugins.Parameter, min, max float32, lsbCC int) error {
+	if cc < 0 || cc > 127 {
+		return fmt.Errorf("engine: invalid CC number %d", cc)
+	}
+	if lsbCC != -1 && (lsbCC < 0 || lsbCC > 127) {
+		return fmt.Errorf("engine: invalid LSB CC number %d", lsbCC)
+	}
+
+	binding := &ccBinding{
+		target:      target,
+		effectIndex: effectIndex,
+		param:       param,
+		channel:     channel,
+		min:         min,
+		max:         max,
+		lsbCC:       lsbCC,
+	}
+
+	key := midiParamKey{effectIndex: effectIndex, paramAddress: param.Address}
+
+	e.ccMu.Lock()
+	defer e.ccMu.Unlock()
+	if e.ccBindings == nil {
+		e.ccBindings = make(map[int][]*ccBinding)
+	}
+	if e.ccParamBindings == nil {
+		e.ccParamBindings = make(map[midiParamKey]*ccBinding)
+	}
+	if old, ok := e.ccParamBindings[key]; ok {
+		e.removeBindingLocked(old)
+	}
+	e.ccBindings[cc] = append(e.ccBindings[cc], binding)
+	if lsbCC != -1 {
+		e.ccBindings[lsbCC] = append(e.ccBindings[lsbCC], binding)
+	}
+	e.ccParamBindings[key] = binding
+	return nil
+}
+
+// removeBindingLocked drops b from every cc slot it was registered under.
+// Callers must hold ccMu.
+func (e *Engine) removeBindingLocked(b *ccBinding) {
+	for cc, bindings := range e.ccBindings {
+		for i, candidate := range bindings {
+			if candidate == b {
+				e.ccBindings[cc] = append(bindings[:i], bindings[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// UnbindMIDIController removes whatever CC binding (if any) is driving
+// param on the effect at effectIndex.
+func (e *Engine) UnbindMIDIController(effectIndex int, param plugins.Parameter) {
+	key := midiParamKey{effectIndex: effectIndex, paramAddress: param.Address}
+	e.ccMu.Lock()
+	defer e.ccMu.Unlock()
+	if old, ok := e.ccParamBindings[key]; ok {
+		e.removeBindingLocked(old)
+		delete(e.ccParamBindings, key)
+	}
+}
+
+// OpenMIDIInput opens dev's CoreMIDI input and starts the poll goroutine
+// that decodes its channel messages, applies any bound CCBinding, and
+// dispatches Note On/Off to whatever target SetNoteTarget last selected. It
+// also lazily starts the control-rate drain goroutine (see
+// SetMIDIControlRate) the first time it's called. Destroy stops both.
+func (e *Engine) OpenMIDIInput(dev devices.MIDIDevice) error {
+	listener, err := rootmidi.OpenChannelListener(dev)
+	if err != nil {
+		return err
+	}
+
+	e.ccMu.Lock()
+	if e.ccRing == nil {
+		e.ccRing = rt.NewRing()
+	}
+	if e.ccControlRateHz <= 0 {
+		e.ccControlRateHz = DefaultMIDIControlRateHz
+	}
+	rate := e.ccControlRateHz
+	e.midiListeners = append(e.midiListeners, listener)
+	if e.midiPollDone == nil {
+		e.midiPollDone = make(chan struct{})
+	}
+	e.ccMu.Unlock()
+
+	e.ensureMIDIDrain(rate)
+
+	e.midiPollWG.Add(1)
+	go e.runMIDIPoll(listener)
+	return nil
+}
+
+// SetMIDIControlRate sets the rate, in Hz, at which the drain goroutine
+// writes queued CC values to their bound parameters. It must be called
+// before the first OpenMIDIInput (the drain goroutine is started lazily and
+// its rate fixed at that point) - calling it afterward returns an error
+// rather than silently having no effect.
+func (e *Engine) SetMIDIControlRate(hz int) error {
+	if hz <= 0 {
+		return errors.New("engine: MIDI control rate must be positive")
+	}
+	e.ccMu.Lock()
+	defer e.ccMu.Unlock()
+	if e.ccDriveDone != nil {
+		return errors.New("engine: MIDI drain goroutine already running, SetMIDIControlRate must be called before the first OpenMIDIInput")
+	}
+	e.ccControlRateHz = hz
+	return nil
+}
+
+// ensureMIDIDrain lazily starts the single ticker-driven goroutine that
+// drains ccRing and writes bound parameters, mirroring ensureFadeScheduler -
+// an Engine that never opens a MIDI input never pays for the goroutine.
+func (e *Engine) ensureMIDIDrain(rate int) {
+	e.ccDriveOnce.Do(func() {
+		e.ccDriveDone = make(chan struct{})
+		e.ccDriveWG.Add(1)
+		go e.runMIDIDrain(rate)
+	})
+}
+
+// runMIDIPoll blocks on listener.Poll in a loop, translating each channel
+// message into a ring Push, until midiPollDone is closed by closeMIDIInputs.
+func (e *Engine) runMIDIPoll(listener *rootmidi.ChannelListener) {
+	defer e.midiPollWG.Done()
+	for {
+		select {
+		case <-e.midiPollDone:
+			return
+		default:
+		}
+
+		msg, ok, err := listener.Poll(50 * time.Millisecond)
+		if err != nil || !ok {
+			continue
+		}
+		e.handleMIDIMessage(msg)
+	}
+}
+
+// handleMIDIMessage dispatches one decoded channel message: a learn
+// callback armed by StartLearn intercepts the next CC before normal
+// dispatch; otherwise CC messages are resolved against ccBindings and
+// scaled onto ccRing for the drain goroutine, and Note On/Off go straight
+// to the last target SetNoteTarget selected (there's no CC number to key a
+// note off of, so notes can't be routed per-binding the way CCs are).
+func (e *Engine) handleMIDIMessage(msg rootmidi.ChannelMessage) {
+	status := msg.Status & 0xF0
+	channel := int(msg.Status & 0x0F)
+
+	if status == 0xB0 {
+		e.ccMu.Lock()
+		if learn := e.learnCallback; learn != nil {
+			e.learnCallback = nil
+			e.ccMu.Unlock()
+			learn(int(msg.Data1), channel)
+			return
+		}
+		bindings := e.ccBindings[int(msg.Data1)]
+		matches := make([]*ccBinding, 0, len(bindings))
+		for _, b := range bindings {
+			if b.channel == -1 || b.channel == channel {
+				matches = append(matches, b)
+			}
+		}
+		e.ccMu.Unlock()
+
+		for _, b := range matches {
+			e.queueCCValue(b, int(msg.Data1), msg.Data2)
+		}
+		return
+	}
+
+	switch status {
+	case 0x90:
+		e.dispatchNote(channel, int(msg.Data1), int(msg.Data2), true)
+	case 0x80:
+		e.dispatchNote(channel, int(msg.Data1), int(msg.Data2), false)
+	}
+}
+
+// queueCCValue updates b's 14-bit pairing state (if any) and pushes its
+// scaled value onto ccRing, encoding effectIndex/param.Address into
+// Command's ChannelID/ParamID the way dispatcher.go's rtRing encodes a
+// root Channel ID/param name into the same fields - a different domain, the
+// same queue type.
+func (e *Engine) queueCCValue(b *ccBinding, cc int, value byte) {
+	var scaled float32
+	if b.lsbCC == -1 {
+		scaled = b.scale(int(value), 7)
+	} else {
+		e.ccMu.Lock()
+		if cc == b.lsbCC {
+			b.lsbValue = value
+			b.haveLSB = true
+			e.ccMu.Unlock()
+			return
+		}
+		lsb, haveLSB := b.lsbValue, b.haveLSB
+		e.ccMu.Unlock()
+		if !haveLSB {
+			lsb = 0
+		}
+		scaled = b.scale(int(value)<<7|int(lsb), 14)
+	}
+
+	e.ccRing.Push(rt.Command{
+		ChannelID: fmt.Sprintf("%d", b.effectIndex),
+		ParamID:   fmt.Sprintf("%d", b.param.Address),
+		Value:     scaled,
+	})
+}
+
+// dispatchNote sends a Note On/Off to whatever target SetNoteTarget last
+// selected. A note carries no CC number to look a per-binding target up by,
+// so - like a single-instrument live rig pointing one controller at one
+// instrument - there's exactly one note target per Engine at a time.
+func (e *Engine) dispatchNote(channel, note, velocity int, on bool) {
+	e.ccMu.Lock()
+	target, effectIndex := e.noteTarget, e.noteEffectIndex
+	e.ccMu.Unlock()
+	if target == nil {
+		return
+	}
+	if on {
+		_ = target.SendNoteOn(effectIndex, channel, note, velocity)
+	} else {
+		_ = target.SendNoteOff(effectIndex, channel, note, velocity)
+	}
+}
+
+// SetNoteTarget selects which effect receives Note On/Off messages decoded
+// by OpenMIDIInput. Call it again to repoint a controller at a different
+// instrument effect.
+func (e *Engine) SetNoteTarget(target MIDIParameterTarget, effectIndex int) {
+	e.ccMu.Lock()
+	e.noteTarget = target
+	e.noteEffectIndex = effectIndex
+	e.ccMu.Unlock()
+}
+
+// runMIDIDrain is the Engine's single goroutine for MIDI control-rate
+// writes: one ticker drains ccRing and calls SetParameter for every queued
+// value, so a burst of CC traffic from several bound controllers costs one
+// timer and one drain, not one per binding.
+func (e *Engine) runMIDIDrain(rate int) {
+	defer e.ccDriveWG.Done()
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ccDriveDone:
+			return
+		case <-ticker.C:
+			e.ccRing.Drain(e.applyMIDICommand)
+		}
+	}
+}
+
+// applyMIDICommand writes one drained Command to its bound parameter,
+// looking the binding back up by the effectIndex/param.Address it was
+// queued under.
+func (e *Engine) applyMIDICommand(cmd rt.Command) {
+	var effectIndex int
+	var paramAddress uint64
+	fmt.Sscanf(cmd.ChannelID, "%d", &effectIndex)
+	fmt.Sscanf(cmd.ParamID, "%d", &paramAddress)
+
+	key := midiParamKey{effectIndex: effectIndex, paramAddress: paramAddress}
+	e.ccMu.Lock()
+	b, ok := e.ccParamBindings[key]
+	e.ccMu.Unlock()
+	if !ok {
+		return
+	}
+	_ = b.target.SetParameter(b.effectIndex, b.param, cmd.Value)
+}
+
+// StartLearn arms a one-shot MIDI learn: the next CC message received by
+// any open MIDI input calls callback with its CC number and channel instead
+// of being dispatched normally, then disarms itself. Typical use is a UI
+// "click to map" button that calls StartLearn, waits for callback, and then
+// calls BindMIDIController with the CC it reports.
+func (e *Engine) StartLearn(callback func(cc, channel int)) {
+	e.ccMu.Lock()
+	e.learnCallback = callback
+	e.ccMu.Unlock()
+}
+
+// CancelLearn disarms a learn started by StartLearn if its callback hasn't
+// fired yet.
+func (e *Engine) CancelLearn() {
+	e.ccMu.Lock()
+	e.learnCallback = nil
+	e.ccMu.Unlock()
+}
+
+// closeMIDIInputs closes every listener opened by OpenMIDIInput and stops
+// the poll and drain goroutines, if either was ever started. Called from
+// Destroy so an Engine that opened a MIDI input doesn't leak them.
+func (e *Engine) closeMIDIInputs() {
+	if e.midiPollDone != nil {
+		select {
+		case <-e.midiPollDone:
+		default:
+			close(e.midiPollDone)
+		}
+	}
+	e.midiPollWG.Wait()
+
+	if e.ccDriveDone != nil {
+		select {
+		case <-e.ccDriveDone:
+		default:
+			close(e.ccDriveDone)
+		}
+		e.ccDriveWG.Wait()
+	}
+
+	e.ccMu.Lock()
+	listeners := e.midiListeners
+	e.midiListeners = nil
+	e.ccMu.Unlock()
+	for _, l := range listeners {
+		l.Close()
+	}
+}
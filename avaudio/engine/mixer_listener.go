@@ -0,0 +1,224 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include <stdlib.h>
+
+// Declared here; implemented in native/engine_mixer_kvo.m once a KVO
+// observer on AVAudioMixerNode's outputVolume key path - the only volume
+// change this package can't already see, since it didn't originate from one
+// of its own Set*Volume calls - is bridged through a //export trampoline
+// into Go (see (*Engine).OnHardwareVolumeChanged's doc comment). handle is
+// an opaque token the eventual trampoline would pass back so the Go side
+// can route the event to the right mixer's listeners, mirroring
+// audioengine_install_xrun_observer in xrun.go.
+const char* audiomixer_install_volume_kvo_observer(void* mixerPtr, uintptr_t handle);
+const char* audiomixer_remove_volume_kvo_observer(uintptr_t handle);
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// MixerListener receives volume/pan change notifications for one mixer,
+// registered via Engine.RegisterMixerListener. Implementations decouple the
+// callers that read mixer state (a UI fader, an automation lane, a preset
+// recall) from whatever mutated it - SetMixerVolumeForBus, SetConnectionPan,
+// or hardware driving OnHardwareVolumeChanged - mirroring the MixerListener
+// pattern MPD's mixer API uses for the same purpose.
+type MixerListener interface {
+	// OnVolumeChanged reports that bus's volume changed to volume.
+	OnVolumeChanged(bus int, volume float32)
+	// OnPanChanged reports that bus's pan changed to pan.
+	OnPanChanged(bus int, pan float32)
+}
+
+// mixerListenerEvent is one change queued for delivery on a listener's
+// dedicated goroutine; value is a volume or a pan depending on isPan.
+type mixerListenerEvent struct {
+	bus   int
+	isPan bool
+	value float32
+}
+
+// mixerListenerEntry is one RegisterMixerListener registration: the
+// listener itself, plus the buffered channel and goroutine that decouple
+// delivery from whichever Set* call published the event.
+type mixerListenerEntry struct {
+	listener MixerListener
+	ch       chan mixerListenerEvent
+	done     chan struct{}
+}
+
+// mixerListenerKey identifies one (engine, mixer) pair's listener set - a
+// mixer pointer is only meaningful relative to the Engine that attached it.
+type mixerListenerKey struct {
+	engine *Engine
+	mixer  unsafe.Pointer
+}
+
+var (
+	mixerListenersMu sync.Mutex
+	mixerListeners   = make(map[mixerListenerKey][]*mixerListenerEntry)
+)
+
+// RegisterMixerListener registers l to be notified, on its own goroutine,
+// whenever SetMixerVolumeForBus, SetMixerPanForBus, SetConnectionVolume,
+// SetConnectionPan, or the bulk ConfigureMixerBuses successfully changes
+// mixerPtr's state. Call the returned cancel to stop delivery and release
+// l's goroutine.
+//
+// Each registration gets its own buffered channel, so a slow
+// OnVolumeChanged/OnPanChanged only backs up that listener's own queue -
+// delivery never blocks the Set* call that published the event, and never
+// blocks any other listener.
+func (e *Engine) RegisterMixerListener(mixerPtr unsafe.Pointer, l MixerListener) (cancel func()) {
+	entry := &mixerListenerEntry{
+		listener: l,
+		ch:       make(chan mixerListenerEvent, 32),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case ev := <-entry.ch:
+				if ev.isPan {
+					l.OnPanChanged(ev.bus, ev.value)
+				} else {
+					l.OnVolumeChanged(ev.bus, ev.value)
+				}
+			case <-entry.done:
+				return
+			}
+		}
+	}()
+
+	key := mixerListenerKey{engine: e, mixer: mixerPtr}
+	mixerListenersMu.Lock()
+	mixerListeners[key] = append(mixerListeners[key], entry)
+	mixerListenersMu.Unlock()
+
+	var cancelOnce sync.Once
+	return func() {
+		cancelOnce.Do(func() {
+			mixerListenersMu.Lock()
+			entries := mixerListeners[key]
+			for i, candidate := range entries {
+				if candidate == entry {
+					mixerListeners[key] = append(entries[:i], entries[i+1:]...)
+					break
+				}
+			}
+			mixerListenersMu.Unlock()
+			close(entry.done)
+		})
+	}
+}
+
+// notifyMixerVolumeChanged delivers a volume-change event to every listener
+// RegisterMixerListener registered on mixerPtr, dropping the event for any
+// listener whose buffer is full rather than blocking the caller that just
+// changed the mixer - the same backpressure policy xrunRegistry and
+// engineEventBus.publish use.
+func (e *Engine) notifyMixerVolumeChanged(mixerPtr unsafe.Pointer, bus int, volume float32) {
+	e.publishMixerEvent(mixerPtr, mixerListenerEvent{bus: bus, value: volume})
+}
+
+// notifyMixerPanChanged is notifyMixerVolumeChanged's pan counterpart.
+func (e *Engine) notifyMixerPanChanged(mixerPtr unsafe.Pointer, bus int, pan float32) {
+	e.publishMixerEvent(mixerPtr, mixerListenerEvent{bus: bus, isPan: true, value: pan})
+}
+
+func (e *Engine) publishMixerEvent(mixerPtr unsafe.Pointer, ev mixerListenerEvent) {
+	key := mixerListenerKey{engine: e, mixer: mixerPtr}
+	mixerListenersMu.Lock()
+	entries := append([]*mixerListenerEntry{}, mixerListeners[key]...)
+	mixerListenersMu.Unlock()
+
+	for _, entry := range entries {
+		select {
+		case entry.ch <- ev:
+		default:
+		}
+	}
+}
+
+// hardwareVolumeObservers tracks OnHardwareVolumeChanged's installed KVO
+// observer per (engine, mixer) pair and the native handle used to remove
+// it, mirroring xrunRegistry in xrun.go.
+var hardwareVolumeObservers = struct {
+	mu      sync.Mutex
+	handles map[mixerListenerKey]uint64
+}{handles: make(map[mixerListenerKey]uint64)}
+
+// OnHardwareVolumeChanged installs a KVO observer on mixerPtr's
+// outputVolume key path, so a volume change driven from outside this
+// package - an audio unit's own custom view moving the gain fader the user
+// is looking at, for instance - reaches mixerPtr's registered
+// MixerListeners the same way a SetMixerVolumeForBus call does, instead of
+// only changes this package itself made being visible to them.
+//
+// The cgo trampoline the KVO observer needs to call back into Go isn't
+// wired up in this tree yet (see audiomixer_install_volume_kvo_observer's
+// declaration above) - like OnXrun, this installs cleanly and is retained,
+// but no hardware-driven change reaches a listener until that trampoline
+// exists; recordHardwareVolumeChange is the call it would make, and this
+// package's own tests drive it directly in the meantime.
+func (e *Engine) OnHardwareVolumeChanged(mixerPtr unsafe.Pointer) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if mixerPtr == nil {
+		return errors.New("mixer pointer is nil")
+	}
+
+	key := mixerListenerKey{engine: e, mixer: mixerPtr}
+	hardwareVolumeObservers.mu.Lock()
+	defer hardwareVolumeObservers.mu.Unlock()
+
+	if _, installed := hardwareVolumeObservers.handles[key]; installed {
+		return nil
+	}
+
+	handle := atomic.AddUint64(&nativeHandleCounter, 1)
+	errorStr := C.audiomixer_install_volume_kvo_observer(mixerPtr, C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	hardwareVolumeObservers.handles[key] = handle
+	return nil
+}
+
+// RemoveHardwareVolumeObserver removes the KVO observer
+// OnHardwareVolumeChanged installed for mixerPtr. It's a no-op if
+// OnHardwareVolumeChanged was never called for this (engine, mixer) pair.
+func (e *Engine) RemoveHardwareVolumeObserver(mixerPtr unsafe.Pointer) error {
+	key := mixerListenerKey{engine: e, mixer: mixerPtr}
+	hardwareVolumeObservers.mu.Lock()
+	defer hardwareVolumeObservers.mu.Unlock()
+
+	handle, ok := hardwareVolumeObservers.handles[key]
+	if !ok {
+		return nil
+	}
+	delete(hardwareVolumeObservers.handles, key)
+
+	errorStr := C.audiomixer_remove_volume_kvo_observer(C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// recordHardwareVolumeChange delivers a hardware-driven volume change on
+// mixerPtr's bus to its registered MixerListeners - the call the eventual
+// KVO trampoline would make.
+func (e *Engine) recordHardwareVolumeChange(mixerPtr unsafe.Pointer, bus int, volume float32) {
+	e.notifyMixerVolumeChanged(mixerPtr, bus, volume)
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type recordingMixerListener struct {
+	volumes chan float32
+	pans    chan float32
+}
+
+func newRecordingMixerListener() *recordingMixerListener {
+	return &recordingMixerListener{
+		volumes: make(chan float32, 8),
+		pans:    make(chan float32, 8),
+	}
+}
+
+func (l *recordingMixerListener) OnVolumeChanged(bus int, volume float32) { l.volumes <- volume }
+func (l *recordingMixerListener) OnPanChanged(bus int, pan float32)       { l.pans <- pan }
+
+func TestRegisterMixerListenerDeliversVolumeAndPanChanges(t *testing.T) {
+	e := &Engine{}
+	var fakeMixer int
+	mixerPtr := unsafe.Pointer(&fakeMixer)
+	listener := newRecordingMixerListener()
+
+	cancel := e.RegisterMixerListener(mixerPtr, listener)
+	defer cancel()
+
+	e.notifyMixerVolumeChanged(mixerPtr, 2, 0.75)
+	e.notifyMixerPanChanged(mixerPtr, 2, -0.5)
+
+	select {
+	case v := <-listener.volumes:
+		if v != 0.75 {
+			t.Errorf("expected volume 0.75, got %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for OnVolumeChanged")
+	}
+
+	select {
+	case p := <-listener.pans:
+		if p != -0.5 {
+			t.Errorf("expected pan -0.5, got %v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for OnPanChanged")
+	}
+}
+
+func TestRegisterMixerListenerCancelStopsDelivery(t *testing.T) {
+	e := &Engine{}
+	var fakeMixer int
+	mixerPtr := unsafe.Pointer(&fakeMixer)
+	listener := newRecordingMixerListener()
+
+	cancel := e.RegisterMixerListener(mixerPtr, listener)
+	cancel()
+
+	e.notifyMixerVolumeChanged(mixerPtr, 0, 1.0)
+
+	select {
+	case v := <-listener.volumes:
+		t.Fatalf("expected no delivery after cancel, got volume %v", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRegisterMixerListenerScopesByMixerPointer(t *testing.T) {
+	e := &Engine{}
+	var fakeMixerA, fakeMixerB int
+	mixerA := unsafe.Pointer(&fakeMixerA)
+	mixerB := unsafe.Pointer(&fakeMixerB)
+	listener := newRecordingMixerListener()
+
+	cancel := e.RegisterMixerListener(mixerA, listener)
+	defer cancel()
+
+	e.notifyMixerVolumeChanged(mixerB, 0, 0.5)
+
+	select {
+	case v := <-listener.volumes:
+		t.Fatalf("expected no delivery for a different mixer pointer, got volume %v", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
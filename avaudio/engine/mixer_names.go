@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// mixerNamesMu guards mixerNames and mixerPointerNames, the two halves of
+// BindMixerToOSC's name <-> pointer registry.
+var (
+	mixerNamesMu      sync.RWMutex
+	mixerNames        = make(map[string]unsafe.Pointer)
+	mixerPointerNames = make(map[unsafe.Pointer]string)
+)
+
+// BindMixerToOSC registers mixerPtr under name, so a control-surface layer
+// (see avaudio/engine/mixerosc) can address it as /mixer/<name>/... instead
+// of requiring callers to thread raw unsafe.Pointers through to OSC message
+// handlers. Calling it again with the same name rebinds that name to the new
+// pointer, releasing its previous binding first.
+func BindMixerToOSC(name string, mixerPtr unsafe.Pointer) {
+	mixerNamesMu.Lock()
+	defer mixerNamesMu.Unlock()
+	if old, ok := mixerNames[name]; ok {
+		delete(mixerPointerNames, old)
+	}
+	mixerNames[name] = mixerPtr
+	mixerPointerNames[mixerPtr] = name
+}
+
+// UnbindMixerFromOSC removes name's binding, if any. It's a no-op if name
+// was never bound.
+func UnbindMixerFromOSC(name string) {
+	mixerNamesMu.Lock()
+	defer mixerNamesMu.Unlock()
+	if ptr, ok := mixerNames[name]; ok {
+		delete(mixerPointerNames, ptr)
+		delete(mixerNames, name)
+	}
+}
+
+// ResolveMixerOSCName returns the mixer pointer bound to name via
+// BindMixerToOSC.
+func ResolveMixerOSCName(name string) (unsafe.Pointer, bool) {
+	mixerNamesMu.RLock()
+	defer mixerNamesMu.RUnlock()
+	ptr, ok := mixerNames[name]
+	return ptr, ok
+}
+
+// MixerOSCName returns the symbolic name mixerPtr was bound to via
+// BindMixerToOSC, if any - used to address an outgoing OSC update back to
+// /mixer/<name>/... without the caller having to track the mapping itself.
+func MixerOSCName(mixerPtr unsafe.Pointer) (string, bool) {
+	mixerNamesMu.RLock()
+	defer mixerNamesMu.RUnlock()
+	name, ok := mixerPointerNames[mixerPtr]
+	return name, ok
+}
@@ -0,0 +1,82 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+#include <stdlib.h>
+
+// Sample-accurate mixer parameter ramps, the per-bus-mixer counterpart to
+// ramp.go's audioplayer_ramp_* functions: scheduled against AVAudioTime on
+// the render thread rather than driven by a Go goroutine calling
+// audiomixer_set_volume/audiomixer_set_pan in a loop. Starting a new ramp
+// for a bus cancels any ramp already in flight for that same bus/parameter.
+const char* audiomixer_ramp_volume(void* mixerPtr, float target, double durationSeconds, int curve, int inputBus);
+const char* audiomixer_ramp_pan(void* mixerPtr, float target, double durationSeconds, int curve, int inputBus);
+const char* audiomixer_cancel_ramps(void* mixerPtr, int inputBus);
+*/
+import "C"
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+)
+
+// RampMixerVolume schedules a volume ramp on mixerPtr's inputBus from its
+// current volume to target over dur, rendered on the audio thread against
+// AVAudioTime like AudioPlayer.RampVolume.
+func (e *Engine) RampMixerVolume(mixerPtr unsafe.Pointer, inputBus int, target float32, dur time.Duration, curve Curve) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if mixerPtr == nil {
+		return errors.New("mixer pointer is nil")
+	}
+	if target < 0.0 || target > 1.0 {
+		return errors.New("volume must be between 0.0 and 1.0")
+	}
+
+	result := C.audiomixer_ramp_volume(mixerPtr, C.float(target), C.double(dur.Seconds()), C.int(curve), C.int(inputBus))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
+
+// RampMixerPan schedules a pan ramp on mixerPtr's inputBus from its current
+// pan to target (-1.0 to +1.0) over dur.
+func (e *Engine) RampMixerPan(mixerPtr unsafe.Pointer, inputBus int, target float32, dur time.Duration, curve Curve) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if mixerPtr == nil {
+		return errors.New("mixer pointer is nil")
+	}
+	if target < -1.0 || target > 1.0 {
+		return errors.New("pan must be between -1.0 (left) and 1.0 (right)")
+	}
+
+	result := C.audiomixer_ramp_pan(mixerPtr, C.float(target), C.double(dur.Seconds()), C.int(curve), C.int(inputBus))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
+
+// CancelMixerRamps stops any in-flight volume/pan ramp on mixerPtr's
+// inputBus, leaving the parameter at whatever value the ramp had reached.
+func (e *Engine) CancelMixerRamps(mixerPtr unsafe.Pointer, inputBus int) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if mixerPtr == nil {
+		return errors.New("mixer pointer is nil")
+	}
+
+	result := C.audiomixer_cancel_ramps(mixerPtr, C.int(inputBus))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+// Package mixerosc exposes an avaudio/engine.Engine's raw mixer buses as OSC
+// endpoints - the lowest layer of this tree's OSC surfaces, distinct from
+// avaudio/engine/osc (macaudio.Engine channels), control/osc (engine/channel
+// Buses and the legacy engine package's channels), and avaudio/osc.Surface
+// (SourceNodes): this one addresses an AVAudioMixerNode's numbered input
+// buses directly by mixerPtr, through a symbolic name registered with
+// engine.BindMixerToOSC.
+//
+//	/mixer/<name>/bus/<n>/volume   f|i    -- 0.0-1.0 -> Engine.SetMixerVolumeForBus
+//	/mixer/<name>/bus/<n>/pan      f|i    -- -1.0-1.0 -> Engine.SetMixerPanForBus
+//	/mixer/<name>/bus/<n>/mute     T|F|i  -- true mutes the bus, remembering its volume to restore on false
+//	/mixer/<name>/bus/<n>/solo     T|F|i  -- true mutes every other bus on the mixer; false restores them
+//	/mixer/<name>/subscribe               -- registers the sender for bus volume/pan push updates
+//	/mixer/<name>/unsubscribe
+//
+// Unlike every sibling surface in this tree, incoming float and int
+// arguments may also be carried as OSC's native T/F boolean type tags,
+// alongside the usual i/f.
+//
+// A bus change made from outside this package - Go-side automation, another
+// control surface, a plugin's own UI - is picked up via
+// Engine.RegisterMixerListener and pushed to /mixer/<name>/bus/<n>/volume|pan
+// subscribers too, the same bidirectional bridge control/osc.Surface uses
+// for its own subscribers.
+package mixerosc
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	engine "github.com/shaban/macaudio/avaudio/engine"
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+)
+
+const (
+	minVolume float32 = 0.0
+	maxVolume float32 = 1.0
+	minPan    float32 = -1.0
+	maxPan    float32 = 1.0
+)
+
+func clamp(v, lo, hi float32) float32 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+type busKey struct {
+	name string
+	bus  int
+}
+
+// soloState is a mixer's active solo, if any: which bus is soloed and every
+// other bus's volume from just before soloing, so unsoloing can restore them
+// exactly rather than guessing a default.
+type soloState struct {
+	bus     int
+	preSolo map[int]float32
+}
+
+// Surface routes incoming OSC messages to mixers registered with
+// engine.BindMixerToOSC and pushes bus volume/pan changes to subscribers.
+type Surface struct {
+	eng       *engine.Engine
+	transport wireosc.Transport
+
+	mu     sync.Mutex
+	muted  map[busKey]float32 // bus's volume just before a /mute true, restored on /mute false
+	solo   map[string]*soloState
+	cancel map[string]func() // mixer name -> RegisterMixerListener's cancel
+
+	subMu sync.Mutex
+	subs  map[string]map[string]net.Addr // mixer name -> addr key -> addr
+}
+
+// NewSurface creates a Surface bound to eng, serving over transport.
+func NewSurface(eng *engine.Engine, transport wireosc.Transport) *Surface {
+	return &Surface{
+		eng:       eng,
+		transport: transport,
+		muted:     make(map[busKey]float32),
+		solo:      make(map[string]*soloState),
+		cancel:    make(map[string]func()),
+		subs:      make(map[string]map[string]net.Addr),
+	}
+}
+
+// Listen opens a UDP OSC transport on addr (e.g. "0.0.0.0:9101") and returns
+// a Surface bound to eng, already serving in the background.
+func Listen(eng *engine.Engine, addr string) (*Surface, error) {
+	transport, err := wireosc.ListenUDP(addr)
+	if err != nil {
+		return nil, fmt.Errorf("mixerosc: failed to listen on %s: %w", addr, err)
+	}
+
+	s := NewSurface(eng, transport)
+	go s.Serve()
+	return s, nil
+}
+
+// Serve starts dispatching incoming OSC packets until the transport errors
+// or is closed.
+func (s *Surface) Serve() error {
+	return s.transport.Serve(s.handle)
+}
+
+// Close stops every mixer listener this Surface installed and the
+// underlying transport.
+func (s *Surface) Close() error {
+	s.mu.Lock()
+	for name, cancel := range s.cancel {
+		cancel()
+		delete(s.cancel, name)
+	}
+	s.mu.Unlock()
+
+	return s.transport.Close()
+}
+
+// WatchMixer registers a Surface-owned MixerListener on mixerPtr (already
+// bound to name via engine.BindMixerToOSC) so local volume/pan changes - a
+// Go-side fade, another control surface, anything that isn't this Surface's
+// own OSC handler - are pushed to /mixer/<name>/bus/<n>/volume|pan
+// subscribers. Calling it again for a name already being watched replaces
+// the previous registration.
+func (s *Surface) WatchMixer(name string) error {
+	mixerPtr, ok := engine.ResolveMixerOSCName(name)
+	if !ok {
+		return fmt.Errorf("mixerosc: mixer %q is not bound; call engine.BindMixerToOSC first", name)
+	}
+
+	cancel := s.eng.RegisterMixerListener(mixerPtr, mixerListenerFunc{
+		onVolume: func(bus int, volume float32) {
+			s.notify(name, fmt.Sprintf("/mixer/%s/bus/%d/volume", name, bus), volume)
+		},
+		onPan: func(bus int, pan float32) {
+			s.notify(name, fmt.Sprintf("/mixer/%s/bus/%d/pan", name, bus), pan)
+		},
+	})
+
+	s.mu.Lock()
+	if old, ok := s.cancel[name]; ok {
+		old()
+	}
+	s.cancel[name] = cancel
+	s.mu.Unlock()
+	return nil
+}
+
+// mixerListenerFunc adapts two plain funcs to engine.MixerListener, so
+// WatchMixer doesn't need a named type with no other state.
+type mixerListenerFunc struct {
+	onVolume func(bus int, volume float32)
+	onPan    func(bus int, pan float32)
+}
+
+func (f mixerListenerFunc) OnVolumeChanged(bus int, volume float32) { f.onVolume(bus, volume) }
+func (f mixerListenerFunc) OnPanChanged(bus int, pan float32)       { f.onPan(bus, pan) }
+
+func (s *Surface) handle(msg wireosc.Message, addr net.Addr) {
+	switch {
+	case strings.HasSuffix(msg.Address, "/subscribe") && strings.HasPrefix(msg.Address, "/mixer/"):
+		s.handleSubscribe(msg.Address, addr)
+	case strings.HasSuffix(msg.Address, "/unsubscribe") && strings.HasPrefix(msg.Address, "/mixer/"):
+		s.handleUnsubscribe(msg.Address, addr)
+	case strings.HasPrefix(msg.Address, "/mixer/"):
+		s.handleBus(msg)
+	}
+}
+
+func mixerName(addr string) (string, bool) {
+	parts := strings.Split(strings.Trim(addr, "/"), "/")
+	if len(parts) < 2 || parts[0] != "mixer" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func (s *Surface) handleSubscribe(addr string, from net.Addr) {
+	name, ok := mixerName(addr)
+	if !ok {
+		return
+	}
+	s.subMu.Lock()
+	if s.subs[name] == nil {
+		s.subs[name] = make(map[string]net.Addr)
+	}
+	s.subs[name][from.String()] = from
+	s.subMu.Unlock()
+}
+
+func (s *Surface) handleUnsubscribe(addr string, from net.Addr) {
+	name, ok := mixerName(addr)
+	if !ok {
+		return
+	}
+	s.subMu.Lock()
+	delete(s.subs[name], from.String())
+	s.subMu.Unlock()
+}
+
+// notify sends value to every subscriber of mixer name at address.
+func (s *Surface) notify(name, address string, value float32) {
+	s.subMu.Lock()
+	addrs := make([]net.Addr, 0, len(s.subs[name]))
+	for _, a := range s.subs[name] {
+		addrs = append(addrs, a)
+	}
+	s.subMu.Unlock()
+	if len(addrs) == 0 {
+		return
+	}
+
+	data, err := (wireosc.Message{Address: address, Args: []interface{}{value}}).Marshal()
+	if err != nil {
+		return
+	}
+	for _, addr := range addrs {
+		_ = s.transport.SendTo(addr, data)
+	}
+}
+
+// floatArg accepts OSC's i, f, T and F type tags as a float: T/F are
+// treated as 1.0/0.0, matching this package's own /mute and /solo
+// convention of also accepting a plain int.
+func floatArg(args []interface{}) (float32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// boolArg accepts OSC's T, F, i and f type tags as a bool: a nonzero number
+// is true, matching control/osc and avaudio/engine/osc's convention of
+// sending mute/solo as a plain int for clients that don't support T/F.
+func boolArg(args []interface{}) (bool, bool) {
+	if len(args) == 0 {
+		return false, false
+	}
+	switch v := args[0].(type) {
+	case bool:
+		return v, true
+	case int32:
+		return v != 0, true
+	case float32:
+		return v != 0, true
+	}
+	return false, false
+}
+
+func (s *Surface) handleBus(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 5 || parts[0] != "mixer" || parts[2] != "bus" {
+		return
+	}
+	name := parts[1]
+	bus, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return
+	}
+
+	mixerPtr, ok := engine.ResolveMixerOSCName(name)
+	if !ok {
+		return
+	}
+
+	switch parts[4] {
+	case "volume":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = s.SetBusVolume(name, mixerPtr, bus, v)
+	case "pan":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = s.SetBusPan(mixerPtr, bus, v)
+	case "mute":
+		v, ok := boolArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = s.SetBusMute(name, mixerPtr, bus, v)
+	case "solo":
+		v, ok := boolArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = s.SetBusSolo(name, mixerPtr, bus, v)
+	}
+}
+
+// SetBusVolume clamps volume to 0.0-1.0 and applies it to mixerPtr's bus via
+// Engine.SetMixerVolumeForBus - the Go-side method behind the
+// /mixer/<name>/bus/<n>/volume address, callable directly by code that
+// already has the mixer's name rather than its raw pointer.
+func (s *Surface) SetBusVolume(name string, mixerPtr unsafe.Pointer, bus int, volume float32) error {
+	return s.eng.SetMixerVolumeForBus(mixerPtr, clamp(volume, minVolume, maxVolume), bus)
+}
+
+// SetBusPan clamps pan to -1.0-1.0 and applies it to mixerPtr's bus via
+// Engine.SetMixerPanForBus.
+func (s *Surface) SetBusPan(mixerPtr unsafe.Pointer, bus int, pan float32) error {
+	return s.eng.SetMixerPanForBus(mixerPtr, clamp(pan, minPan, maxPan), bus)
+}
+
+// SetBusMute mutes bus by setting its volume to 0.0, remembering the volume
+// it had so a later SetBusMute(..., false) can restore it; unmuting a bus
+// that was never muted is a no-op.
+func (s *Surface) SetBusMute(name string, mixerPtr unsafe.Pointer, bus int, muted bool) error {
+	key := busKey{name: name, bus: bus}
+
+	if muted {
+		volume, err := s.eng.GetMixerVolumeForBus(mixerPtr, bus)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.muted[key] = volume
+		s.mu.Unlock()
+		return s.eng.SetMixerVolumeForBus(mixerPtr, 0, bus)
+	}
+
+	s.mu.Lock()
+	volume, wasMuted := s.muted[key]
+	delete(s.muted, key)
+	s.mu.Unlock()
+	if !wasMuted {
+		return nil
+	}
+	return s.eng.SetMixerVolumeForBus(mixerPtr, volume, bus)
+}
+
+// SetBusSolo mutes every other bus on mixerPtr when soloing bus, restoring
+// their remembered volumes when unsoloing it. Soloing a different bus while
+// one is already soloed restores the previous solo's buses first, then
+// solos the new one.
+func (s *Surface) SetBusSolo(name string, mixerPtr unsafe.Pointer, bus int, solo bool) error {
+	s.mu.Lock()
+	current := s.solo[name]
+	s.mu.Unlock()
+
+	if !solo {
+		if current == nil || current.bus != bus {
+			return nil
+		}
+		return s.restoreSolo(name, mixerPtr, current)
+	}
+
+	if current != nil {
+		if current.bus == bus {
+			return nil
+		}
+		if err := s.restoreSolo(name, mixerPtr, current); err != nil {
+			return err
+		}
+	}
+
+	count, err := s.eng.GetNodeInputCount(mixerPtr)
+	if err != nil {
+		return err
+	}
+
+	preSolo := make(map[int]float32, count)
+	for i := 0; i < count; i++ {
+		if i == bus {
+			continue
+		}
+		volume, err := s.eng.GetMixerVolumeForBus(mixerPtr, i)
+		if err != nil {
+			return err
+		}
+		preSolo[i] = volume
+		if err := s.eng.SetMixerVolumeForBus(mixerPtr, 0, i); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.solo[name] = &soloState{bus: bus, preSolo: preSolo}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Surface) restoreSolo(name string, mixerPtr unsafe.Pointer, state *soloState) error {
+	for bus, volume := range state.preSolo {
+		if err := s.eng.SetMixerVolumeForBus(mixerPtr, volume, bus); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	delete(s.solo, name)
+	s.mu.Unlock()
+	return nil
+}
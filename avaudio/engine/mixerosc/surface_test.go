@@ -0,0 +1,165 @@
+package mixerosc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	engine "github.com/shaban/macaudio/avaudio/engine"
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+)
+
+func newTestMixer(t *testing.T) (*engine.Engine, string) {
+	t.Helper()
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	t.Cleanup(eng.Destroy)
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create mixer: %v", err)
+	}
+
+	name := "test-mixer"
+	engine.BindMixerToOSC(name, mixerPtr)
+	t.Cleanup(func() { engine.UnbindMixerFromOSC(name) })
+	return eng, name
+}
+
+// newTestClient starts a Surface bound to eng, serving over a UDP transport
+// on an ephemeral port, and returns a dialed client connection to it.
+func newTestClient(t *testing.T, eng *engine.Engine) (*Surface, net.Conn) {
+	t.Helper()
+	transport, err := wireosc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen UDP: %v", err)
+	}
+	surface := NewSurface(eng, transport)
+	t.Cleanup(func() { surface.Close() })
+	go surface.Serve()
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("failed to dial OSC server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return surface, client
+}
+
+func send(t *testing.T, client net.Conn, addr string, args ...interface{}) {
+	t.Helper()
+	data, err := wireosc.Message{Address: addr, Args: args}.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal %s: %v", addr, err)
+	}
+	if _, err := client.Write(data); err != nil {
+		t.Fatalf("failed to send %s: %v", addr, err)
+	}
+}
+
+// TestSurfaceBusVolumeAndPan drives /mixer/<name>/bus/<n>/volume and /pan
+// over UDP and checks they land on the target mixer bus.
+func TestSurfaceBusVolumeAndPan(t *testing.T) {
+	eng, name := newTestMixer(t)
+	_, client := newTestClient(t, eng)
+
+	mixerPtr, _ := engine.ResolveMixerOSCName(name)
+
+	send(t, client, "/mixer/"+name+"/bus/0/volume", float32(0.5))
+	send(t, client, "/mixer/"+name+"/bus/0/pan", float32(-0.25))
+	time.Sleep(50 * time.Millisecond)
+
+	if v, err := eng.GetMixerVolumeForBus(mixerPtr, 0); err != nil || v < 0.49 || v > 0.51 {
+		t.Errorf("expected volume ~0.5 after /volume, got %v (err %v)", v, err)
+	}
+	if p, err := eng.GetMixerPanForBus(mixerPtr, 0); err != nil || p != -0.25 {
+		t.Errorf("expected pan -0.25 after /pan, got %v (err %v)", p, err)
+	}
+}
+
+// TestSurfaceBusMuteRestoresVolume checks /mute true zeroes a bus's volume
+// and /mute false restores what it was before muting.
+func TestSurfaceBusMuteRestoresVolume(t *testing.T) {
+	eng, name := newTestMixer(t)
+	_, client := newTestClient(t, eng)
+
+	mixerPtr, _ := engine.ResolveMixerOSCName(name)
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.8, 0); err != nil {
+		t.Fatalf("failed to set initial volume: %v", err)
+	}
+
+	send(t, client, "/mixer/"+name+"/bus/0/mute", true)
+	time.Sleep(50 * time.Millisecond)
+	if v, err := eng.GetMixerVolumeForBus(mixerPtr, 0); err != nil || v != 0 {
+		t.Errorf("expected volume 0 after /mute true, got %v (err %v)", v, err)
+	}
+
+	send(t, client, "/mixer/"+name+"/bus/0/mute", false)
+	time.Sleep(50 * time.Millisecond)
+	if v, err := eng.GetMixerVolumeForBus(mixerPtr, 0); err != nil || v < 0.79 || v > 0.81 {
+		t.Errorf("expected volume restored to ~0.8 after /mute false, got %v (err %v)", v, err)
+	}
+}
+
+// TestSurfaceBusSoloMutesOtherBuses checks /solo true mutes every other bus
+// and /solo false restores them.
+func TestSurfaceBusSoloMutesOtherBuses(t *testing.T) {
+	eng, name := newTestMixer(t)
+	_, client := newTestClient(t, eng)
+
+	mixerPtr, _ := engine.ResolveMixerOSCName(name)
+	count, err := eng.GetNodeInputCount(mixerPtr)
+	if err != nil || count < 2 {
+		t.Skip("mixer has fewer than 2 input buses, skipping solo test")
+	}
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.6, 1); err != nil {
+		t.Fatalf("failed to set bus 1 volume: %v", err)
+	}
+
+	send(t, client, "/mixer/"+name+"/bus/0/solo", true)
+	time.Sleep(50 * time.Millisecond)
+	if v, err := eng.GetMixerVolumeForBus(mixerPtr, 1); err != nil || v != 0 {
+		t.Errorf("expected bus 1 muted while bus 0 is soloed, got %v (err %v)", v, err)
+	}
+
+	send(t, client, "/mixer/"+name+"/bus/0/solo", false)
+	time.Sleep(50 * time.Millisecond)
+	if v, err := eng.GetMixerVolumeForBus(mixerPtr, 1); err != nil || v < 0.59 || v > 0.61 {
+		t.Errorf("expected bus 1 volume restored to ~0.6 after unsolo, got %v (err %v)", v, err)
+	}
+}
+
+// TestSurfaceWatchMixerPushesLocalChanges checks a volume change made
+// directly through Engine.SetMixerVolumeForBus (not via OSC) is pushed to a
+// /mixer/<name>/subscribe-d client once WatchMixer is installed.
+func TestSurfaceWatchMixerPushesLocalChanges(t *testing.T) {
+	eng, name := newTestMixer(t)
+	surface, client := newTestClient(t, eng)
+
+	if err := surface.WatchMixer(name); err != nil {
+		t.Fatalf("WatchMixer failed: %v", err)
+	}
+	send(t, client, "/mixer/"+name+"/subscribe")
+	time.Sleep(20 * time.Millisecond)
+
+	mixerPtr, _ := engine.ResolveMixerOSCName(name)
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.3, 0); err != nil {
+		t.Fatalf("failed to set volume: %v", err)
+	}
+
+	buf := make([]byte, 256)
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := client.Read(buf)
+	if err != nil {
+		t.Fatalf("expected a pushed update, got error: %v", err)
+	}
+	msg, err := wireosc.Unmarshal(buf[:n])
+	if err != nil {
+		t.Fatalf("failed to unmarshal pushed update: %v", err)
+	}
+	if msg.Address != "/mixer/"+name+"/bus/0/volume" {
+		t.Errorf("expected a volume push for bus 0, got address %q", msg.Address)
+	}
+}
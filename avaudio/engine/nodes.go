@@ -228,6 +228,7 @@ func (e *Engine) SetMixerVolumeForBus(mixerPtr unsafe.Pointer, volume float32, i
 		if errorStr != nil {
 			return errors.New(C.GoString(errorStr))
 		}
+		e.notifyMixerVolumeChanged(mixerPtr, inputBus, volume)
 		return nil
 	}
 
@@ -238,6 +239,7 @@ func (e *Engine) SetMixerVolumeForBus(mixerPtr unsafe.Pointer, volume float32, i
 		if errorStr != nil {
 			return errors.New(C.GoString(errorStr))
 		}
+		e.notifyMixerVolumeChanged(mixerPtr, inputBus, volume)
 		return nil
 	}
 
@@ -248,6 +250,7 @@ func (e *Engine) SetMixerVolumeForBus(mixerPtr unsafe.Pointer, volume float32, i
 		if errorStr != nil {
 			return errors.New(C.GoString(errorStr))
 		}
+		e.notifyMixerVolumeChanged(mixerPtr, inputBus, volume)
 		return nil
 	}
 
@@ -256,6 +259,7 @@ func (e *Engine) SetMixerVolumeForBus(mixerPtr unsafe.Pointer, volume float32, i
 	if errorStr != nil {
 		return errors.New(C.GoString(errorStr))
 	}
+	e.notifyMixerVolumeChanged(mixerPtr, inputBus, volume)
 	return nil
 }
 
@@ -332,6 +336,7 @@ func (e *Engine) SetMixerPanForBus(mixerPtr unsafe.Pointer, pan float32, inputBu
 		if errorStr != nil {
 			return errors.New(C.GoString(errorStr))
 		}
+		e.notifyMixerPanChanged(mixerPtr, inputBus, pan)
 		return nil
 	}
 
@@ -342,6 +347,7 @@ func (e *Engine) SetMixerPanForBus(mixerPtr unsafe.Pointer, pan float32, inputBu
 		if errorStr != nil {
 			return errors.New(C.GoString(errorStr))
 		}
+		e.notifyMixerPanChanged(mixerPtr, inputBus, pan)
 		return nil
 	}
 
@@ -352,6 +358,7 @@ func (e *Engine) SetMixerPanForBus(mixerPtr unsafe.Pointer, pan float32, inputBu
 		if errorStr != nil {
 			return errors.New(C.GoString(errorStr))
 		}
+		e.notifyMixerPanChanged(mixerPtr, inputBus, pan)
 		return nil
 	}
 
@@ -360,6 +367,7 @@ func (e *Engine) SetMixerPanForBus(mixerPtr unsafe.Pointer, pan float32, inputBu
 	if errorStr != nil {
 		return errors.New(C.GoString(errorStr))
 	}
+	e.notifyMixerPanChanged(mixerPtr, inputBus, pan)
 	return nil
 }
 
@@ -435,6 +443,7 @@ func (e *Engine) SetConnectionVolume(sourcePtr, mixerPtr unsafe.Pointer, destBus
 	if errorStr != nil {
 		return errors.New(C.GoString(errorStr))
 	}
+	e.notifyMixerVolumeChanged(mixerPtr, destBus, volume)
 	return nil
 }
 
@@ -478,6 +487,7 @@ func (e *Engine) SetConnectionPan(sourcePtr, mixerPtr unsafe.Pointer, destBus in
 	if errorStr != nil {
 		return errors.New(C.GoString(errorStr))
 	}
+	e.notifyMixerPanChanged(mixerPtr, destBus, pan)
 	return nil
 }
 
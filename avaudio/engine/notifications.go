@@ -0,0 +1,149 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include <stdlib.h>
+
+// Declared here; implemented in native/engine_notifications.m once an
+// NSNotificationCenter observer bridging
+// AVAudioEngineConfigurationChangeNotification,
+// AVAudioSessionMediaServicesWereResetNotification, and AVAudioEngine's
+// render-thread exception handler into a //export trampoline exists (see
+// (*Engine).OnNotification's doc comment). handle is an opaque token the
+// eventual trampoline would pass back so the Go side can route the event to
+// the right Engine's listeners.
+const char* audioengine_install_notification_observer(void* enginePtr, uintptr_t handle);
+const char* audioengine_remove_notification_observer(uintptr_t handle);
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// NotificationKind identifies which AVFoundation/CoreAudio notification an
+// EngineNotification carries, for OnNotification listeners that only care
+// about some of them.
+type NotificationKind int
+
+const (
+	NotificationConfigurationChange NotificationKind = iota
+	NotificationMediaServicesReset
+	NotificationRenderException
+)
+
+// String returns the notification kind's name, e.g. "ConfigurationChange".
+func (k NotificationKind) String() string {
+	switch k {
+	case NotificationConfigurationChange:
+		return "ConfigurationChange"
+	case NotificationMediaServicesReset:
+		return "MediaServicesReset"
+	case NotificationRenderException:
+		return "RenderException"
+	default:
+		return fmt.Sprintf("NotificationKind(%d)", int(k))
+	}
+}
+
+// EngineNotification is one event delivered to an OnNotification listener.
+type EngineNotification struct {
+	Kind NotificationKind
+	// NodePtr identifies the node the render thread raised an exception on;
+	// set only for NotificationRenderException, nil for the other kinds.
+	NodePtr unsafe.Pointer
+}
+
+var nativeHandleCounter uint64
+
+// notificationRegistry tracks OnNotification listeners per Engine and the
+// native handle used to unregister them, mirroring callbackTapRegistry's
+// Go-side bookkeeping in avaudio/tap.
+type notificationRegistry struct {
+	mu        sync.Mutex
+	listeners map[*Engine][]func(EngineNotification)
+	handles   map[*Engine]uint64
+}
+
+var notifications = &notificationRegistry{
+	listeners: make(map[*Engine][]func(EngineNotification)),
+	handles:   make(map[*Engine]uint64),
+}
+
+// OnNotification registers fn to be called whenever AVAudioEngine reports a
+// configuration change, CoreAudio reports a media-services reset, or the
+// engine's render thread reports an exception on one of its nodes, and
+// installs the native NSNotificationCenter observer the first time any
+// listener is registered on e.
+//
+// The cgo trampoline the native observer needs to call back into Go isn't
+// wired up in this tree yet (see audioengine_install_notification_observer's
+// declaration above) - like InstallCallbackTap, this installs cleanly and fn
+// is retained, but until that trampoline exists fn is never called.
+func (e *Engine) OnNotification(fn func(EngineNotification)) error {
+	if fn == nil {
+		return fmt.Errorf("callback cannot be nil")
+	}
+
+	notifications.mu.Lock()
+	defer notifications.mu.Unlock()
+
+	if len(notifications.listeners[e]) > 0 {
+		notifications.listeners[e] = append(notifications.listeners[e], fn)
+		return nil
+	}
+
+	handle := atomic.AddUint64(&nativeHandleCounter, 1)
+	errorStr := C.audioengine_install_notification_observer(unsafe.Pointer(e.ptr), C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+
+	notifications.listeners[e] = []func(EngineNotification){fn}
+	notifications.handles[e] = handle
+	return nil
+}
+
+// RemoveNotificationObserver removes the native observer installed by
+// OnNotification and drops every listener registered on e. It's a no-op if
+// e never called OnNotification.
+func (e *Engine) RemoveNotificationObserver() error {
+	notifications.mu.Lock()
+	defer notifications.mu.Unlock()
+
+	handle, ok := notifications.handles[e]
+	if !ok {
+		return nil
+	}
+	delete(notifications.listeners, e)
+	delete(notifications.handles, e)
+
+	errorStr := C.audioengine_remove_notification_observer(C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// recordNotification delivers n to every listener OnNotification has
+// registered on e, and for NotificationConfigurationChange also publishes a
+// ConfigurationChanged to e's Subscribe bus - the call the eventual native
+// observer trampoline would make; this package's own tests drive it
+// directly in the meantime.
+func (e *Engine) recordNotification(n EngineNotification) {
+	notifications.mu.Lock()
+	fns := append([]func(EngineNotification){}, notifications.listeners[e]...)
+	notifications.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(n)
+	}
+
+	if n.Kind == NotificationConfigurationChange {
+		e.events.publish(ConfigurationChanged{})
+	}
+}
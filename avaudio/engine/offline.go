@@ -0,0 +1,64 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+// Function declarations for CGO
+const char* audioengine_set_offline_rendering_mode(AudioEngine* wrapper, bool enabled, double sampleRate, int maxFrames);
+const char* audioengine_render_offline(AudioEngine* wrapper, int frameCount, float* outBuffer, int* outFramesRendered);
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// SetOfflineRenderingMode switches the engine between its normal
+// hardware-clocked mode and AVAudioEngine's manual rendering mode, where
+// RenderOffline pulls frames synchronously rather than the engine pulling
+// them from a live I/O callback. maxFrames bounds the largest buffer a
+// single RenderOffline call may request, matching
+// AVAudioEngine.enableManualRenderingMode's maximumFrameCount.
+func (e *Engine) SetOfflineRenderingMode(enabled bool, maxFrames int) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if enabled && maxFrames <= 0 {
+		return errors.New("maxFrames must be positive when enabling offline rendering")
+	}
+
+	if errStr := C.audioengine_set_offline_rendering_mode(e.ptr, C.bool(enabled), C.double(e.spec.SampleRate), C.int(maxFrames)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// RenderOffline pulls frameCount frames through the graph deterministically
+// and faster than real time, returning interleaved float32 samples sized
+// for the engine's configured channel count. It only produces useful
+// output while offline rendering mode is enabled via
+// SetOfflineRenderingMode.
+func (e *Engine) RenderOffline(frameCount int) ([]float32, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	if frameCount <= 0 {
+		return nil, errors.New("frameCount must be positive")
+	}
+
+	channels := e.spec.ChannelCount
+	if channels <= 0 {
+		channels = 2
+	}
+
+	buf := make([]float32, frameCount*channels)
+	var rendered C.int
+
+	errStr := C.audioengine_render_offline(e.ptr, C.int(frameCount), (*C.float)(unsafe.Pointer(&buf[0])), &rendered)
+	if errStr != nil {
+		return nil, errors.New(C.GoString(errStr))
+	}
+	return buf[:int(rendered)*channels], nil
+}
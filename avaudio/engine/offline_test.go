@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+)
+
+// goertzelMagnitude estimates the magnitude of samples at targetFreq using
+// the Goertzel algorithm, a cheap single-bin DFT well suited to "is there a
+// peak at exactly this frequency" checks without pulling in a full FFT.
+func goertzelMagnitude(samples []float32, sampleRate, targetFreq float64) float64 {
+	n := len(samples)
+	k := int(0.5 + float64(n)*targetFreq/sampleRate)
+	omega := 2 * math.Pi * float64(k) / float64(n)
+	coeff := 2 * math.Cos(omega)
+
+	var s0, s1, s2 float64
+	for _, sample := range samples {
+		s0 = float64(sample) + coeff*s1 - s2
+		s2 = s1
+		s1 = s0
+	}
+	real := s1 - s2*math.Cos(omega)
+	imag := s2 * math.Sin(omega)
+	return math.Sqrt(real*real + imag*imag)
+}
+
+// TestRenderOfflineToneHasExpectedPeak renders a 440Hz tone through manual
+// rendering mode and checks the rendered buffer's energy is concentrated at
+// 440Hz rather than, say, silence or a different frequency.
+func TestRenderOfflineToneHasExpectedPeak(t *testing.T) {
+	spec := DefaultAudioSpec()
+	e, err := New(spec)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	tone, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("NewTone failed: %v", err)
+	}
+	defer tone.Destroy()
+
+	if err := tone.SetFrequency(440.0); err != nil {
+		t.Fatalf("SetFrequency failed: %v", err)
+	}
+	if err := tone.SetAmplitude(0.8); err != nil {
+		t.Fatalf("SetAmplitude failed: %v", err)
+	}
+
+	nodePtr, err := tone.GetNodePtr()
+	if err != nil {
+		t.Fatalf("GetNodePtr failed: %v", err)
+	}
+	if err := e.Attach(nodePtr); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	mainMixer, err := e.MainMixerNode()
+	if err != nil {
+		t.Fatalf("MainMixerNode failed: %v", err)
+	}
+	if err := e.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	const maxFrames = 4096
+	if err := e.SetOfflineRenderingMode(true, maxFrames); err != nil {
+		t.Fatalf("SetOfflineRenderingMode failed: %v", err)
+	}
+	defer e.SetOfflineRenderingMode(false, 0)
+
+	samples, err := e.RenderOffline(maxFrames)
+	if err != nil {
+		t.Fatalf("RenderOffline failed: %v", err)
+	}
+	if len(samples) == 0 {
+		t.Fatal("expected rendered samples, got none")
+	}
+
+	peak := goertzelMagnitude(samples, spec.SampleRate, 440.0)
+	off := goertzelMagnitude(samples, spec.SampleRate, 1200.0)
+	if peak <= off {
+		t.Fatalf("expected 440Hz magnitude (%f) to exceed an off-target bin (%f)", peak, off)
+	}
+}
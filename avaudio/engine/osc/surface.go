@@ -0,0 +1,333 @@
+// Package osc exposes a macaudio.Engine's channels as OSC endpoints for
+// external control surfaces (Behringer X-Air apps, TouchOSC, QLab, etc.),
+// distinct from the full engine-state surface at the repository root
+// (osc_server.go) and the lower-level mixer surface in avaudio/osc: this one
+// is purpose-built around per-channel fader/pan/mute plus level metering,
+// with channels addressed by Channel.GetID() or a configured alias:
+//
+//	/ch/<id>/fader       f   -- 0.0-1.0 normalized, or dB-scaled (see FaderLevel0db) -> Channel.SetVolume
+//	/ch/<id>/pan         f   -- -1.0 to 1.0 -> Channel.SetPan
+//	/ch/<id>/mute        i   -- 1 mutes, 0 unmutes -> Channel.SetMute
+//	/meters/subscribe        -- registers the sender for periodic /meters/<id> pushes
+//	/meters/unsubscribe      -- stops those pushes
+//
+// Channel.SetMute already routes through the engine's Dispatcher as an
+// OpSetMute operation (it's a topology change), so incoming mute messages
+// serialize with the rest of the engine for free; fader and pan are plain
+// AU parameter sets, same as every other OSC surface in this tree. Unknown
+// addresses and unresolvable ids are ignored, the same policy osc_server.go
+// and avaudio/osc.Surface use.
+package osc
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio"
+	wire "github.com/shaban/macaudio/avaudio/osc"
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// FaderLevel0db is the dB value a /ch/<id>/fader payload must carry to
+// select unity gain. A payload within the normalized 0.0-1.0 range is
+// treated as a fader position and tapered logarithmically; a payload
+// outside that range is treated as an explicit dB figure relative to this
+// reference instead, so a console that sends actual dB readings (e.g. "-6")
+// lands at -6dB rather than being clamped to the top of the fader.
+const FaderLevel0db float32 = 0.0
+
+// DefaultMeterRate is the /meters/<id> push rate used when Config.MeterRate
+// is zero.
+const DefaultMeterRate = 100 * time.Millisecond
+
+const minFaderDB = -60.0
+
+// Config configures a Surface's bind address, channel aliases, and meter
+// push rate.
+type Config struct {
+	// Host and Port are the UDP bind address a Surface listens on, e.g.
+	// Host: "0.0.0.0", Port: 9100.
+	Host string
+	Port int
+
+	// Aliases maps friendly control-surface names (e.g. "kick") onto the
+	// Channel.GetID() a /ch/<alias>/... address should resolve to, so a
+	// surface can be configured without raw channel UUIDs.
+	Aliases map[string]string
+
+	// MeterRate is how often a /meters/subscribe-d address receives
+	// /meters/<id> pushes. Zero means DefaultMeterRate.
+	MeterRate time.Duration
+}
+
+// Surface exposes eng's channels as OSC endpoints, serving over transport.
+type Surface struct {
+	engine    *macaudio.Engine
+	transport wire.Transport
+	aliases   map[string]string
+	meterRate time.Duration
+
+	subMu       sync.Mutex
+	subscribers map[string]*meterSub
+}
+
+// tapper is implemented by channel types that can install a metering tap;
+// not every Channel implementation does today, so /meters/<id> simply
+// skips channels that don't (mirroring how handleAux in osc_server.go
+// ignores a type assertion that doesn't match).
+type tapper interface {
+	InstallTap(key string) (*tap.Tap, error)
+}
+
+type meterSub struct {
+	addr net.Addr
+	taps map[string]*tap.Tap
+	stop chan struct{}
+}
+
+// NewSurface creates a Surface bound to eng, serving over transport.
+func NewSurface(eng *macaudio.Engine, transport wire.Transport, config Config) *Surface {
+	rate := config.MeterRate
+	if rate <= 0 {
+		rate = DefaultMeterRate
+	}
+
+	aliases := config.Aliases
+	if aliases == nil {
+		aliases = make(map[string]string)
+	}
+
+	return &Surface{
+		engine:      eng,
+		transport:   transport,
+		aliases:     aliases,
+		meterRate:   rate,
+		subscribers: make(map[string]*meterSub),
+	}
+}
+
+// Listen opens a UDP OSC transport on config.Host:config.Port and returns a
+// Surface bound to it, already serving in the background.
+func Listen(eng *macaudio.Engine, config Config) (*Surface, error) {
+	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+	transport, err := wire.ListenUDP(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	surface := NewSurface(eng, transport, config)
+	go surface.Serve()
+	return surface, nil
+}
+
+// Serve starts dispatching incoming OSC packets until the transport errors
+// or is closed.
+func (s *Surface) Serve() error {
+	return s.transport.Serve(s.handle)
+}
+
+// Close stops all meter subscriptions and the underlying transport.
+func (s *Surface) Close() error {
+	s.subMu.Lock()
+	for key, sub := range s.subscribers {
+		close(sub.stop)
+		for _, t := range sub.taps {
+			_ = t.Remove()
+		}
+		delete(s.subscribers, key)
+	}
+	s.subMu.Unlock()
+
+	return s.transport.Close()
+}
+
+func (s *Surface) handle(msg wire.Message, addr net.Addr) {
+	switch {
+	case msg.Address == "/meters/subscribe":
+		s.handleSubscribe(addr)
+	case msg.Address == "/meters/unsubscribe":
+		s.handleUnsubscribe(addr)
+	case strings.HasPrefix(msg.Address, "/ch/"):
+		s.handleChannel(msg)
+	}
+}
+
+// resolve looks id up as an alias first, falling back to treating it as a
+// channel's own id.
+func (s *Surface) resolve(id string) (macaudio.Channel, bool) {
+	if real, ok := s.aliases[id]; ok {
+		id = real
+	}
+	return s.engine.GetChannel(id)
+}
+
+func floatArg(args []interface{}) (float32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	}
+	return 0, false
+}
+
+func intArg(args []interface{}) (int32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case int32:
+		return v, true
+	case float32:
+		return int32(v), true
+	}
+	return 0, false
+}
+
+// positionToGain converts a /ch/<id>/fader payload into the linear gain
+// Channel.SetVolume expects. A value within 0.0-1.0 is treated as a fader
+// position and tapered logarithmically (unity at 1.0, -60dB at 0.0); a
+// value outside that range is treated as dB relative to FaderLevel0db.
+func positionToGain(v float32) float32 {
+	var db float64
+	if v >= 0 && v <= 1 {
+		if v <= 0 {
+			return 0
+		}
+		db = float64(v)*(-minFaderDB) + minFaderDB
+	} else {
+		db = float64(v - FaderLevel0db)
+	}
+
+	gain := math.Pow(10, db/20)
+	if gain < 0 {
+		return 0
+	}
+	if gain > 1 {
+		return 1
+	}
+	return float32(gain)
+}
+
+func (s *Surface) handleChannel(msg wire.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "ch" {
+		return
+	}
+	id, param := parts[1], parts[2]
+
+	ch, ok := s.resolve(id)
+	if !ok {
+		return
+	}
+
+	switch param {
+	case "fader":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = ch.SetVolume(positionToGain(v))
+	case "pan":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = ch.SetPan(v)
+	case "mute":
+		v, ok := intArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = ch.SetMute(v != 0)
+	}
+}
+
+// handleSubscribe installs a metering tap on every channel that supports
+// one and starts pushing their levels to addr at s.meterRate, replacing any
+// prior subscription from the same address.
+func (s *Surface) handleSubscribe(addr net.Addr) {
+	key := addr.String()
+
+	taps := make(map[string]*tap.Tap)
+	for _, id := range s.engine.ListChannels() {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		t, ok := ch.(tapper)
+		if !ok {
+			continue
+		}
+		installed, err := t.InstallTap(fmt.Sprintf("osc-meter-%s-%s", id, key))
+		if err != nil {
+			continue
+		}
+		taps[id] = installed
+	}
+
+	sub := &meterSub{addr: addr, taps: taps, stop: make(chan struct{})}
+
+	s.subMu.Lock()
+	if existing, ok := s.subscribers[key]; ok {
+		close(existing.stop)
+		for _, t := range existing.taps {
+			_ = t.Remove()
+		}
+	}
+	s.subscribers[key] = sub
+	s.subMu.Unlock()
+
+	go s.pushMeters(sub)
+}
+
+func (s *Surface) handleUnsubscribe(addr net.Addr) {
+	key := addr.String()
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	sub, ok := s.subscribers[key]
+	if !ok {
+		return
+	}
+	close(sub.stop)
+	for _, t := range sub.taps {
+		_ = t.Remove()
+	}
+	delete(s.subscribers, key)
+}
+
+func (s *Surface) pushMeters(sub *meterSub) {
+	ticker := time.NewTicker(s.meterRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sub.stop:
+			return
+		case <-ticker.C:
+			for id, t := range sub.taps {
+				metrics, err := t.GetMetrics()
+				if err != nil {
+					continue
+				}
+				data, err := (wire.Message{
+					Address: fmt.Sprintf("/meters/%s", id),
+					Args:    []interface{}{float32(metrics.RMS), int32(metrics.FrameCount)},
+				}).Marshal()
+				if err != nil {
+					continue
+				}
+				_ = s.transport.SendTo(sub.addr, data)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package osc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio"
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/osc"
+	"github.com/shaban/macaudio/devices"
+)
+
+// getValidOutputDevice returns the first available online output device for
+// testing, same lookup createTestConfig uses at the repository root.
+func getValidOutputDevice(t *testing.T) string {
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		t.Fatalf("Failed to enumerate audio devices: %v", err)
+	}
+
+	for _, device := range audioDevices {
+		if device.IsDefaultOutput && device.IsOnline && device.CanOutput() {
+			return device.UID
+		}
+	}
+
+	outputs := audioDevices.Online().Outputs()
+	if len(outputs) == 0 {
+		t.Skip("No online output devices available for testing")
+	}
+	return outputs[0].UID
+}
+
+func newTestEngine(t *testing.T) *macaudio.Engine {
+	config := macaudio.EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   256,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		OutputDeviceUID: getValidOutputDevice(t),
+		ErrorHandler:    &macaudio.DefaultErrorHandler{},
+	}
+
+	eng, err := macaudio.NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	return eng
+}
+
+// TestPositionToGain checks the normalized-vs-dB split positionToGain makes:
+// values within 0.0-1.0 follow the fader taper, values outside it are read
+// as dB relative to FaderLevel0db.
+func TestPositionToGain(t *testing.T) {
+	if g := positionToGain(1.0); g != 1.0 {
+		t.Errorf("expected unity gain at fader 1.0, got %f", g)
+	}
+	if g := positionToGain(0.0); g != 0.0 {
+		t.Errorf("expected zero gain at fader 0.0, got %f", g)
+	}
+	if g := positionToGain(FaderLevel0db); g < 0.99 {
+		t.Errorf("expected unity gain at FaderLevel0db, got %f", g)
+	}
+	if g := positionToGain(-6.0); g <= 0 || g >= 1 {
+		t.Errorf("expected -6dB to land strictly between silence and unity, got %f", g)
+	}
+}
+
+// TestSurfaceChannelControl drives /ch/<id>/fader, /pan, and /mute over UDP
+// and checks they land on the target channel.
+func TestSurfaceChannelControl(t *testing.T) {
+	eng := newTestEngine(t)
+	defer eng.Destroy()
+
+	ch, err := eng.CreateSilenceChannel("osc-ch")
+	if err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	surface := NewSurface(eng, transport, Config{})
+	defer surface.Close()
+	go surface.Serve()
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	send := func(addr string, args ...interface{}) {
+		data, err := osc.Message{Address: addr, Args: args}.Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal %s: %v", addr, err)
+		}
+		if _, err := client.Write(data); err != nil {
+			t.Fatalf("Failed to send %s: %v", addr, err)
+		}
+	}
+
+	send("/ch/osc-ch/fader", float32(1.0))
+	send("/ch/osc-ch/pan", float32(-0.5))
+	send("/ch/osc-ch/mute", int32(1))
+	time.Sleep(50 * time.Millisecond)
+
+	if volume, err := ch.GetVolume(); err != nil || volume < 0.99 {
+		t.Errorf("expected unity volume after /fader 1.0, got %f (err %v)", volume, err)
+	}
+	if pan, err := ch.GetPan(); err != nil || pan != -0.5 {
+		t.Errorf("expected pan -0.5 after /pan, got %f (err %v)", pan, err)
+	}
+	if muted, err := ch.GetMute(); err != nil || !muted {
+		t.Errorf("expected muted after /mute 1, got %v (err %v)", muted, err)
+	}
+}
+
+// TestSurfaceAliasResolution checks a /ch/<alias>/... address resolves
+// through Config.Aliases onto the aliased channel's real id.
+func TestSurfaceAliasResolution(t *testing.T) {
+	eng := newTestEngine(t)
+	defer eng.Destroy()
+
+	ch, err := eng.CreateSilenceChannel("osc-real-id")
+	if err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	surface := NewSurface(eng, transport, Config{
+		Aliases: map[string]string{"kick": "osc-real-id"},
+	})
+	defer surface.Close()
+	go surface.Serve()
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	data, err := osc.Message{Address: "/ch/kick/fader", Args: []interface{}{float32(1.0)}}.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+	if _, err := client.Write(data); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if volume, err := ch.GetVolume(); err != nil || volume < 0.99 {
+		t.Errorf("expected unity volume after /ch/kick/fader 1.0, got %f (err %v)", volume, err)
+	}
+}
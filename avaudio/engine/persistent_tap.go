@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// TapNodeSelector resolves the AVAudioNode a PersistentTap should be
+// installed on. It's re-evaluated every time the tap (re)installs, so a
+// selector like TimePitchOutput stays valid across graph edits
+// (EnableTimePitchEffects, ConnectToMainMixer) that swap out the underlying
+// node, instead of pinning a pointer that goes stale.
+type TapNodeSelector interface {
+	resolveTapNode(e *Engine) (unsafe.Pointer, error)
+}
+
+// MainMixer selects the engine's main mixer node.
+type MainMixer struct{}
+
+func (MainMixer) resolveTapNode(e *Engine) (unsafe.Pointer, error) {
+	return e.MainMixerNode()
+}
+
+// PlayerOutput selects player's own output node.
+type PlayerOutput struct {
+	Player *AudioPlayer
+}
+
+func (s PlayerOutput) resolveTapNode(e *Engine) (unsafe.Pointer, error) {
+	return s.Player.GetNodePtr()
+}
+
+// TimePitchOutput selects player's TimePitch unit. EnableTimePitchEffects
+// and DisableTimePitchEffects replace that unit, so this is re-resolved
+// rather than cached.
+type TimePitchOutput struct {
+	Player *AudioPlayer
+}
+
+func (s TimePitchOutput) resolveTapNode(e *Engine) (unsafe.Pointer, error) {
+	return s.Player.GetTimePitchNodePtr()
+}
+
+// PersistentTap is a Tap that remembers the selector/bus/key it was
+// installed with and reinstalls itself under the same key every time its
+// Engine publishes EngineStarted (see Engine.Subscribe) - after
+// engine.Stop()/engine.Start(), and after graph edits like
+// EnableTimePitchEffects or ConnectToMainMixer, which are conventionally
+// bracketed by a Stop/Start pair (see TestPlayerTimePitchEffects). This
+// eliminates the tap.Remove() -> engine.Stop() -> engine.Start() ->
+// InstallTapWithKey(...) dance callers previously repeated by hand.
+type PersistentTap struct {
+	mu        sync.Mutex
+	engine    *Engine
+	selector  TapNodeSelector
+	busIndex  int
+	key       string
+	tap       *Tap
+	sessionID int
+}
+
+var (
+	persistentTapsMu       sync.Mutex
+	persistentTapsByEngine = make(map[*Engine][]*PersistentTap)
+	persistentTapCancel    = make(map[*Engine]func())
+)
+
+// InstallPersistentTap installs a tap on the node selector resolves, like
+// InstallTapWithKey, but registers it to reinstall automatically whenever e
+// publishes EngineStarted.
+func InstallPersistentTap(e *Engine, selector TapNodeSelector, busIndex int, key string) (*PersistentTap, error) {
+	if e == nil {
+		return nil, fmt.Errorf("engine is nil")
+	}
+
+	pt := &PersistentTap{
+		engine:   e,
+		selector: selector,
+		busIndex: busIndex,
+		key:      key,
+	}
+
+	if err := pt.reinstall(); err != nil {
+		return nil, err
+	}
+
+	persistentTapsMu.Lock()
+	persistentTapsByEngine[e] = append(persistentTapsByEngine[e], pt)
+	if _, watching := persistentTapCancel[e]; !watching {
+		events, cancel := e.Subscribe()
+		persistentTapCancel[e] = cancel
+		go watchPersistentTaps(e, events)
+	}
+	persistentTapsMu.Unlock()
+
+	return pt, nil
+}
+
+// watchPersistentTaps reinstalls every PersistentTap registered against e
+// each time an EngineStarted event arrives, until e's subscription is
+// cancelled (see removePersistentTap).
+func watchPersistentTaps(e *Engine, events <-chan EngineEvent) {
+	for ev := range events {
+		if _, ok := ev.(EngineStarted); !ok {
+			continue
+		}
+
+		persistentTapsMu.Lock()
+		taps := append([]*PersistentTap{}, persistentTapsByEngine[e]...)
+		persistentTapsMu.Unlock()
+
+		for _, pt := range taps {
+			_ = pt.reinstall()
+		}
+	}
+}
+
+func (pt *PersistentTap) reinstall() error {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	nodePtr, err := pt.selector.resolveTapNode(pt.engine)
+	if err != nil {
+		return fmt.Errorf("persistent tap %q: resolve node: %w", pt.key, err)
+	}
+
+	if pt.tap != nil && pt.tap.IsInstalled() {
+		_ = pt.tap.Remove()
+	}
+
+	tap, err := InstallTapWithKey(pt.engine.GetNativeEngine(), nodePtr, pt.busIndex, pt.key)
+	if err != nil {
+		return fmt.Errorf("persistent tap %q: %w", pt.key, err)
+	}
+
+	pt.tap = tap
+	pt.sessionID++
+	return nil
+}
+
+// Remove removes the underlying tap and stops it from reinstalling on
+// future EngineStarted events.
+func (pt *PersistentTap) Remove() error {
+	removePersistentTap(pt)
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.tap == nil || !pt.tap.IsInstalled() {
+		return nil
+	}
+	return pt.tap.Remove()
+}
+
+// removePersistentTap unregisters pt from its engine's watch list, tearing
+// down that engine's EngineStarted subscription once no persistent tap is
+// left watching it.
+func removePersistentTap(pt *PersistentTap) {
+	persistentTapsMu.Lock()
+	defer persistentTapsMu.Unlock()
+
+	taps := persistentTapsByEngine[pt.engine]
+	for i, other := range taps {
+		if other == pt {
+			taps = append(taps[:i], taps[i+1:]...)
+			break
+		}
+	}
+
+	if len(taps) == 0 {
+		delete(persistentTapsByEngine, pt.engine)
+		if cancel, ok := persistentTapCancel[pt.engine]; ok {
+			cancel()
+			delete(persistentTapCancel, pt.engine)
+		}
+		return
+	}
+	persistentTapsByEngine[pt.engine] = taps
+}
+
+// GetMetrics returns the underlying tap's current metrics, tagged with the
+// SessionID of the install/reinstall that produced them, so a caller
+// polling across an engine restart can tell a sample taken before the
+// restart from one taken after.
+func (pt *PersistentTap) GetMetrics() (*TapMetrics, error) {
+	pt.mu.Lock()
+	tap, sessionID := pt.tap, pt.sessionID
+	pt.mu.Unlock()
+
+	if tap == nil {
+		return nil, fmt.Errorf("persistent tap %q is not installed", pt.key)
+	}
+
+	metrics, err := tap.GetMetrics()
+	if err != nil {
+		return nil, err
+	}
+	metrics.SessionID = sessionID
+	return metrics, nil
+}
+
+// Key returns the tap's registry key.
+func (pt *PersistentTap) Key() string {
+	return pt.key
+}
+
+// SessionID returns the install/reinstall count reached so far - 1 after
+// the initial InstallPersistentTap, incremented on every automatic
+// reinstall.
+func (pt *PersistentTap) SessionID() int {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.sessionID
+}
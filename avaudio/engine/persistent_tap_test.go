@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestPersistentTapSurvivesRestart drives the same Stop -> EnableTimePitch
+// Effects -> ConnectToMainMixer -> Start sequence TestPlayerTimePitchEffects
+// does by hand, and checks that a PersistentTap keeps reporting metrics
+// across it without the caller re-installing the tap itself.
+func TestPersistentTapSurvivesRestart(t *testing.T) {
+	if os.Getenv("MACAUDIO_AUDIBLE") == "" {
+		t.Skip("Set MACAUDIO_AUDIBLE=1 to run persistent tap restart test")
+	}
+
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Destroy()
+
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	player, err := engine.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	if err := player.LoadFile("idea.m4a"); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := player.ConnectToMainMixer(); err != nil {
+		t.Fatalf("ConnectToMainMixer failed: %v", err)
+	}
+
+	pt, err := InstallPersistentTap(engine, MainMixer{}, 0, "persistent_tap_restart_test")
+	if err != nil {
+		t.Fatalf("InstallPersistentTap failed: %v", err)
+	}
+	defer pt.Remove()
+
+	if got := pt.SessionID(); got != 1 {
+		t.Fatalf("SessionID after initial install = %d, want 1", got)
+	}
+
+	player.Play()
+	time.Sleep(200 * time.Millisecond)
+	player.Stop()
+
+	before, err := pt.GetMetrics()
+	if err != nil {
+		t.Fatalf("GetMetrics before restart failed: %v", err)
+	}
+	if before.SessionID != 1 {
+		t.Fatalf("metrics.SessionID before restart = %d, want 1", before.SessionID)
+	}
+
+	// The dance the TimePitch tests used to repeat around every graph edit:
+	// the tap is expected to survive it without being reinstalled by hand.
+	engine.Stop()
+	if err := player.EnableTimePitchEffects(); err != nil {
+		t.Fatalf("EnableTimePitchEffects failed: %v", err)
+	}
+	if err := player.ConnectToMainMixer(); err != nil {
+		t.Fatalf("ConnectToMainMixer after EnableTimePitchEffects failed: %v", err)
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start after graph edit failed: %v", err)
+	}
+
+	if got := pt.SessionID(); got != 2 {
+		t.Fatalf("SessionID after restart = %d, want 2", got)
+	}
+
+	player.Play()
+	time.Sleep(200 * time.Millisecond)
+	player.Stop()
+
+	after, err := pt.GetMetrics()
+	if err != nil {
+		t.Fatalf("GetMetrics after restart failed: %v", err)
+	}
+	if after.SessionID != 2 {
+		t.Fatalf("metrics.SessionID after restart = %d, want 2", after.SessionID)
+	}
+}
+
+// TestPersistentTapRemoveStopsReinstall checks that Remove both tears down
+// the tap and unsubscribes it from future EngineStarted events, rather than
+// leaving it to silently reinstall on the next restart.
+func TestPersistentTapRemoveStopsReinstall(t *testing.T) {
+	if os.Getenv("MACAUDIO_AUDIBLE") == "" {
+		t.Skip("Set MACAUDIO_AUDIBLE=1 to run persistent tap removal test")
+	}
+
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Destroy()
+
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	pt, err := InstallPersistentTap(engine, MainMixer{}, 0, "persistent_tap_removal_test")
+	if err != nil {
+		t.Fatalf("InstallPersistentTap failed: %v", err)
+	}
+
+	if err := pt.Remove(); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	engine.Stop()
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if got := pt.SessionID(); got != 1 {
+		t.Fatalf("SessionID after Remove + restart = %d, want 1 (no reinstall)", got)
+	}
+}
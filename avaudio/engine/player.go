@@ -12,21 +12,32 @@ import (
 	"fmt"
 	"time"
 	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/tap"
 )
 
 // AudioSegmentMetrics contains analysis results for a segment of audio
 type AudioSegmentMetrics struct {
-	RMS        float64   // Root Mean Square level of the audio
-	FrameCount int       // Number of frames analyzed
-	StartTime  float64   // Start time of the analyzed segment
-	Duration   float64   // Duration of the analyzed segment
-	Timestamp  time.Time // When the analysis was performed
+	RMS            float64   // Root Mean Square level of the audio
+	FrameCount     int       // Number of frames analyzed
+	StartTime      float64   // Start time of the analyzed segment
+	Duration       float64   // Duration of the analyzed segment
+	Timestamp      time.Time // When the analysis was performed
+	PeakDB         float64   // Plain sample peak, in dBFS
+	TruePeakDB     float64   // 4x-oversampled inter-sample peak, in dBFS
+	IntegratedLUFS float64   // ITU-R BS.1770 gated integrated loudness
+	ShortTermLUFS  float64   // Ungated loudness over the last 3s of the segment (or the whole segment, if shorter)
+	Spectrum       []float32 // FFT magnitude spectrum; nil unless spectrum analysis was configured/requested
 }
 
 // AudioPlayer represents an audio file player that can be connected to an engine
 type AudioPlayer struct {
-	ptr    *C.AudioPlayer
-	engine *Engine // Reference to the engine this player belongs to
+	ptr            *C.AudioPlayer
+	engine         *Engine // Reference to the engine this player belongs to
+	filePath       string  // Set by LoadFile; lets AnalyzeFileSegment re-read raw samples via AudioFile
+	spectrumConfig SpectrumConfig
+	tap            *tap.CallbackTap // Set by InstallTap; nil until a tap is installed
+	effectChain    EffectChain      // Set by SetEffectChain; nil until a chain is attached (see player_effectchain.go)
 }
 
 // FileInfo contains information about the loaded audio file
@@ -81,9 +92,18 @@ func (p *AudioPlayer) LoadFile(filePath string) error {
 		return errors.New(C.GoString(result))
 	}
 
+	p.filePath = filePath
 	return nil
 }
 
+// SetSpectrumConfig configures the FFT magnitude spectrum AnalyzeFileSegment
+// and AnalyzeCurrentPlayback attach to AudioSegmentMetrics.Spectrum. The zero
+// value (FFTSize 0) leaves Spectrum nil, since the FFT is the most expensive
+// part of an analysis pass and most callers only want peak/LUFS.
+func (p *AudioPlayer) SetSpectrumConfig(cfg SpectrumConfig) {
+	p.spectrumConfig = cfg
+}
+
 // Play starts playback of the loaded audio file from the beginning
 func (p *AudioPlayer) Play() error {
 	if p == nil || p.ptr == nil {
@@ -369,6 +389,19 @@ func (p *AudioPlayer) EnableTimePitchEffects() error {
 	return nil
 }
 
+// EnableTimePitchEffectsTx queues EnableTimePitchEffects to run as part of
+// tx (see GraphTransaction), instead of requiring the caller to bracket it
+// with the engine.Stop()/engine.Start() restart EnableTimePitchEffects'
+// own doc comment describes - tx.Commit pauses and resumes p around the
+// whole batch of queued mutations instead.
+func (p *AudioPlayer) EnableTimePitchEffectsTx(tx *GraphTransaction) error {
+	if tx == nil {
+		return errors.New("transaction is nil")
+	}
+	tx.enqueueAction(p.EnableTimePitchEffects)
+	return nil
+}
+
 // DisableTimePitchEffects disables time stretching and pitch shifting
 // This will reset playback rate to 1.0 and pitch to 0 cents
 // Note: You may need to reconnect to your desired destination after disabling
@@ -391,6 +424,16 @@ func (p *AudioPlayer) DisableTimePitchEffects() error {
 	return nil
 }
 
+// DisableTimePitchEffectsTx queues DisableTimePitchEffects to run as part
+// of tx; see EnableTimePitchEffectsTx.
+func (p *AudioPlayer) DisableTimePitchEffectsTx(tx *GraphTransaction) error {
+	if tx == nil {
+		return errors.New("transaction is nil")
+	}
+	tx.enqueueAction(p.DisableTimePitchEffects)
+	return nil
+}
+
 // IsTimePitchEffectsEnabled returns true if time/pitch effects are currently enabled
 func (p *AudioPlayer) IsTimePitchEffectsEnabled() (bool, error) {
 	if p == nil || p.ptr == nil {
@@ -468,20 +511,31 @@ func (p *AudioPlayer) GetFileInfo() (*FileInfo, error) {
 // CLEAN CONNECTION ARCHITECTURE
 // =====================================================
 
-// ConnectTo connects this player's output to any destination node
-// This is the generic connection method with no assumptions about destinations
-// It automatically handles TimePitch routing if enabled
+// ConnectTo connects this player's output (or, if SetEffectChain/
+// EnableTimePitchEffects is active, the chain's or TimePitch unit's output)
+// to any destination node. If the engine is running, the rewire happens
+// inside a stop->disconnect->reconnect->start bracket, so callers don't
+// need to do that dance themselves around every ConnectTo call the way the
+// demo's "stop engine, sleep, start engine" approach requires; the engine
+// is always left running afterward if it was running before, even when
+// the reconnect itself fails.
 func (p *AudioPlayer) ConnectTo(destinationNode unsafe.Pointer, outputBus, inputBus int) error {
 	if p == nil || p.ptr == nil || p.engine == nil {
 		return errors.New("player or engine is nil")
 	}
 
-	// Get our actual output node (player directly or TimePitch unit if enabled)
+	// Get our actual output node (player directly, an attached effect
+	// chain's output, or TimePitch unit if enabled)
 	outputNode, err := p.getEffectiveOutputNode()
 	if err != nil {
 		return err
 	}
 
+	wasRunning := p.engine.IsRunning()
+	if wasRunning {
+		p.engine.Stop()
+	}
+
 	// Clean disconnect from any existing destination first
 	// Note: This is more thorough than the original implementation
 	if err := p.disconnectFromCurrentDestination(); err != nil {
@@ -490,7 +544,15 @@ func (p *AudioPlayer) ConnectTo(destinationNode unsafe.Pointer, outputBus, input
 	}
 
 	// Connect to new destination using engine's Connect method
-	return p.engine.Connect(outputNode, destinationNode, outputBus, inputBus)
+	connectErr := p.engine.Connect(outputNode, destinationNode, outputBus, inputBus)
+
+	if wasRunning {
+		if startErr := p.engine.Start(); startErr != nil && connectErr == nil {
+			connectErr = startErr
+		}
+	}
+
+	return connectErr
 }
 
 // ConnectToMixer connects to any mixer node (convenience method)
@@ -498,9 +560,14 @@ func (p *AudioPlayer) ConnectToMixer(mixerNode unsafe.Pointer, mixerInputBus int
 	return p.ConnectTo(mixerNode, 0, mixerInputBus)
 }
 
-// getEffectiveOutputNode returns the actual output node for this player
-// If TimePitch is enabled, returns TimePitch unit; otherwise returns player node
+// getEffectiveOutputNode returns the actual output node for this player:
+// an attached effect chain's output (see SetEffectChain) takes priority
+// over TimePitch, which takes priority over the player's own node.
 func (p *AudioPlayer) getEffectiveOutputNode() (unsafe.Pointer, error) {
+	if p.effectChain != nil {
+		return p.effectChain.GetOutputNode()
+	}
+
 	timePitchEnabled, err := p.IsTimePitchEffectsEnabled()
 	if err != nil {
 		// If we can't determine TimePitch status, assume disabled
@@ -552,6 +619,17 @@ func (p *AudioPlayer) ensureTimePitchConnected() error {
 // disconnectFromCurrentDestination cleans up existing connections
 // This provides more thorough cleanup than the original implementation
 func (p *AudioPlayer) disconnectFromCurrentDestination() error {
+	if p.effectChain != nil {
+		outputNode, err := p.effectChain.GetOutputNode()
+		if err != nil {
+			return err
+		}
+		if err := p.engine.DisconnectNodeOutput(outputNode, 0); err != nil {
+			// Non-fatal - the connection might not exist
+		}
+		return nil
+	}
+
 	timePitchEnabled, _ := p.IsTimePitchEffectsEnabled()
 
 	if timePitchEnabled {
@@ -611,13 +689,29 @@ func (p *AudioPlayer) AnalyzeFileSegment(startTime, duration float64) (*AudioSeg
 		return nil, fmt.Errorf("analysis failed: %s", C.GoString(result))
 	}
 
-	return &AudioSegmentMetrics{
+	metrics := &AudioSegmentMetrics{
 		RMS:        float64(rms),
 		FrameCount: int(frameCount),
 		StartTime:  startTime,
 		Duration:   duration,
 		Timestamp:  time.Now(),
-	}, nil
+	}
+
+	// Peak/true-peak/LUFS/spectrum need the raw samples, which
+	// audioplayer_analyze_file_segment doesn't return - re-read them via
+	// AudioFile instead. Best-effort: a failure here (e.g. filePath not set
+	// yet, or an unreadable format) still leaves RMS/FrameCount usable.
+	if p.filePath != "" {
+		if extra, err := p.analyzeFileSegmentExtras(startTime, duration); err == nil {
+			metrics.PeakDB = extra.peakDB
+			metrics.TruePeakDB = extra.truePeakDB
+			metrics.IntegratedLUFS = extra.integratedLUFS
+			metrics.ShortTermLUFS = extra.shortTermLUFS
+			metrics.Spectrum = extra.spectrum
+		}
+	}
+
+	return metrics, nil
 }
 
 // AnalyzeCurrentPlayback analyzes the audio data that should be playing at the current time
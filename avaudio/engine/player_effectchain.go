@@ -0,0 +1,59 @@
+package engine
+
+import "unsafe"
+
+// EffectChain is the seam AudioPlayer.SetEffectChain routes a player's
+// output through. avaudio/pluginchain.PluginChain (GetInputNode/
+// GetOutputNode) satisfies it already - this package doesn't import
+// pluginchain directly, since pluginchain's own tests already import
+// avaudio/engine and a direct import here would cycle. Same interface-seam
+// approach hostapi.go's HostAPI uses to keep this package decoupled from
+// its growing set of Go-side consumers.
+type EffectChain interface {
+	GetInputNode() (unsafe.Pointer, error)
+	GetOutputNode() (unsafe.Pointer, error)
+}
+
+// SetEffectChain attaches chain between this player and whatever it
+// connects to next: once a Player -> chain.GetInputNode() connection is in
+// place, getEffectiveOutputNode returns chain.GetOutputNode(), so
+// ConnectTo/ConnectToMixer route through every effect the chain holds
+// instead of the player's raw output or its single hardcoded TimePitch
+// unit (see EnableTimePitchEffects). Passing nil detaches the chain and
+// reverts to the player's own output.
+func (p *AudioPlayer) SetEffectChain(chain EffectChain) error {
+	p.effectChain = chain
+	if chain == nil {
+		return nil
+	}
+	return p.connectToEffectChain()
+}
+
+// GetEffectChain returns the chain last attached via SetEffectChain, or nil
+// if none is attached.
+func (p *AudioPlayer) GetEffectChain() EffectChain {
+	return p.effectChain
+}
+
+// connectToEffectChain wires this player's node into its attached chain's
+// input, mirroring ensureTimePitchConnected's engine-format connect.
+func (p *AudioPlayer) connectToEffectChain() error {
+	playerNodePtr, err := p.GetNodePtr()
+	if err != nil {
+		return err
+	}
+	inputNode, err := p.effectChain.GetInputNode()
+	if err != nil {
+		return err
+	}
+
+	p.engine.DisconnectNodeInput(inputNode, 0)
+
+	engineFormat, err := p.engine.GetEngineFormat()
+	if err != nil {
+		// Fallback to nil format
+		return p.engine.ConnectWithFormat(playerNodePtr, inputNode, 0, 0, nil)
+	}
+	defer engineFormat.Destroy()
+	return p.engine.ConnectWithFormat(playerNodePtr, inputNode, 0, 0, engineFormat.GetPtr())
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// fakeEffectChain is a minimal EffectChain backed by a single mixer node,
+// standing in for avaudio/pluginchain.PluginChain in tests that don't need
+// a real effect chain - just something with an input and output node.
+type fakeEffectChain struct {
+	nodePtr unsafe.Pointer
+}
+
+func (f *fakeEffectChain) GetInputNode() (unsafe.Pointer, error)  { return f.nodePtr, nil }
+func (f *fakeEffectChain) GetOutputNode() (unsafe.Pointer, error) { return f.nodePtr, nil }
+
+func TestSetEffectChainRoutesOutputThroughChain(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	chainNode, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("CreateMixerNode failed: %v", err)
+	}
+	chain := &fakeEffectChain{nodePtr: chainNode}
+
+	if err := player.SetEffectChain(chain); err != nil {
+		t.Fatalf("SetEffectChain failed: %v", err)
+	}
+
+	outputNode, err := player.getEffectiveOutputNode()
+	if err != nil {
+		t.Fatalf("getEffectiveOutputNode failed: %v", err)
+	}
+	if outputNode != chainNode {
+		t.Error("expected getEffectiveOutputNode to return the attached chain's output node")
+	}
+
+	if player.GetEffectChain() != chain {
+		t.Error("expected GetEffectChain to return the chain set by SetEffectChain")
+	}
+}
+
+func TestSetEffectChainNilRevertsToPlayerOutput(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	chainNode, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("CreateMixerNode failed: %v", err)
+	}
+	if err := player.SetEffectChain(&fakeEffectChain{nodePtr: chainNode}); err != nil {
+		t.Fatalf("SetEffectChain failed: %v", err)
+	}
+	if err := player.SetEffectChain(nil); err != nil {
+		t.Fatalf("SetEffectChain(nil) failed: %v", err)
+	}
+
+	playerNode, err := player.GetNodePtr()
+	if err != nil {
+		t.Fatalf("GetNodePtr failed: %v", err)
+	}
+	outputNode, err := player.getEffectiveOutputNode()
+	if err != nil {
+		t.Fatalf("getEffectiveOutputNode failed: %v", err)
+	}
+	if outputNode != playerNode {
+		t.Error("expected getEffectiveOutputNode to revert to the player's own node after SetEffectChain(nil)")
+	}
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"time"
+	"unsafe"
+)
+
+// FadeTo ramps the player's volume from its current value to target over
+// duration, sampling curve on the engine's fade scheduler goroutine (see
+// Engine.SetFadeTickRate) - the same mechanism FadeMixerVolume uses, so a
+// player fade and a mixer fade started at the same time are sampled
+// together rather than racing two separate goroutines. This replaces the
+// abrupt SetVolume(v) calls the player tests use today, which click on
+// start/stop. Starting a new fade on the same player preempts one already
+// running; see FadeConnectionVolume for the no-op-on-invalid-input and
+// cancel semantics this follows.
+func (p *AudioPlayer) FadeTo(target float32, duration time.Duration, curve FadeCurve) (cancel func()) {
+	if p == nil || p.ptr == nil || p.engine == nil || target < 0.0 || target > 1.0 {
+		return func() {}
+	}
+	from, err := p.GetVolume()
+	if err != nil {
+		return func() {}
+	}
+	key := fadeKey{kind: fadeTargetPlayerVolume, sourcePtr: unsafe.Pointer(p.ptr)}
+	return p.engine.startFade(key, from, target, duration, curve, p.SetVolume)
+}
+
+// duckThreshold is the RMS level above which Duck considers other to be
+// "speaking" and ducks p under it.
+const duckThreshold = 0.02
+
+// Duck lowers p's volume to ratio of its level whenever other's measured
+// output RMS rises above duckThreshold, ramping down over attack and back
+// up over release once other quiets again - the voice-over-ducks-the-music
+// pattern. It installs a callback tap on other (see InstallTap) to measure
+// RMS continuously, so other must not already have a tap installed.
+func (p *AudioPlayer) Duck(other *AudioPlayer, ratio float32, attack, release time.Duration) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if other == nil || other.ptr == nil {
+		return errors.New("duck source player is nil")
+	}
+	if ratio < 0.0 || ratio > 1.0 {
+		return errors.New("duck ratio must be between 0.0 and 1.0")
+	}
+
+	baseline, err := p.GetVolume()
+	if err != nil {
+		return err
+	}
+
+	var ducked bool
+	return other.InstallTap(1024, func(frames [][]float32, _ float64) {
+		rms := rmsOfFrames(frames)
+		switch {
+		case rms >= duckThreshold && !ducked:
+			ducked = true
+			p.FadeTo(baseline*ratio, attack, FadeExponential)
+		case rms < duckThreshold && ducked:
+			ducked = false
+			p.FadeTo(baseline, release, FadeExponential)
+		}
+	})
+}
+
+// rmsOfFrames computes the root-mean-square level across every channel in
+// frames, the same measurement Tap.GetMetrics reports for an installed tap.
+func rmsOfFrames(frames [][]float32) float64 {
+	var sum float64
+	var n int
+	for _, ch := range frames {
+		for _, s := range ch {
+			sum += float64(s) * float64(s)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / float64(n))
+}
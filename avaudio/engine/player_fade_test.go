@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlayerFadeToReachesTarget(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+	if err := player.SetVolume(0.0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	cancel := player.FadeTo(1.0, 40*time.Millisecond, FadeLinear)
+	defer cancel()
+
+	time.Sleep(200 * time.Millisecond)
+	volume, err := player.GetVolume()
+	if err != nil {
+		t.Fatalf("Failed to get volume: %v", err)
+	}
+	if volume < 0.99 {
+		t.Errorf("expected volume to reach ~1.0 after the fade completes, got %v", volume)
+	}
+}
+
+func TestPlayerFadeToRejectsInvalidTarget(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	cancel := player.FadeTo(1.5, 10*time.Millisecond, FadeLinear)
+	defer cancel()
+
+	time.Sleep(20 * time.Millisecond)
+	volume, err := player.GetVolume()
+	if err != nil {
+		t.Fatalf("Failed to get volume: %v", err)
+	}
+	if volume == 1.5 {
+		t.Errorf("expected an out-of-range target to be a no-op, got volume %v", volume)
+	}
+}
+
+func TestRMSOfFrames(t *testing.T) {
+	frames := [][]float32{{1, -1, 1, -1}}
+	if got := rmsOfFrames(frames); got != 1.0 {
+		t.Errorf("rmsOfFrames(%v) = %v, want 1.0", frames, got)
+	}
+	if got := rmsOfFrames(nil); got != 0 {
+		t.Errorf("rmsOfFrames(nil) = %v, want 0", got)
+	}
+}
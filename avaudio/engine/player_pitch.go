@@ -0,0 +1,207 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// pitchCentsMin/Max mirror AVAudioUnitTimePitch's hard clamp (see SetPitch).
+// SetPitchSemitones and SetPitchNote both bottom out at SetPitch, but check
+// the range explicitly first so a caller is told exactly why their call was
+// rejected instead of discovering the framework silently clamped it.
+const (
+	pitchCentsMin float32 = -2400.0
+	pitchCentsMax float32 = 2400.0
+)
+
+// ErrPitchOutOfRange reports that a pitch shift requested in cents (via
+// SetPitch, SetPitchSemitones, or SetPitchNote) falls outside
+// AVAudioUnitTimePitch's supported range.
+type ErrPitchOutOfRange struct {
+	Cents    float32
+	Min, Max float32
+}
+
+func (e *ErrPitchOutOfRange) Error() string {
+	return fmt.Sprintf("pitch %.1f cents out of range [%.1f, %.1f]", e.Cents, e.Min, e.Max)
+}
+
+// SetPitchSemitones sets the player's pitch shift in semitones (100 cents
+// each) rather than raw cents - n=12 is up an octave, n=-12 down one.
+// Note: Time/pitch effects must be enabled first with EnableTimePitchEffects()
+func (p *AudioPlayer) SetPitchSemitones(n float32) error {
+	cents := n * 100
+	if cents < pitchCentsMin || cents > pitchCentsMax {
+		return &ErrPitchOutOfRange{Cents: cents, Min: pitchCentsMin, Max: pitchCentsMax}
+	}
+	return p.SetPitch(cents)
+}
+
+// SetPitchNote shifts pitch so a note originally at from now sounds at to
+// (e.g. SetPitchNote("C4", "E4") shifts up a major third). Both must be
+// scientific pitch notation - a letter A-G, an optional run of '#'/'b'
+// accidentals, and an octave number (e.g. "F#3", "Bb5").
+// Note: Time/pitch effects must be enabled first with EnableTimePitchEffects()
+func (p *AudioPlayer) SetPitchNote(from, to string) error {
+	fromSemitones, err := parseNoteName(from)
+	if err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	toSemitones, err := parseNoteName(to)
+	if err != nil {
+		return fmt.Errorf("to: %w", err)
+	}
+	return p.SetPitchSemitones(float32(toSemitones - fromSemitones))
+}
+
+// SetTempoBPM sets the player's playback rate so material authored at from
+// BPM plays back at to BPM, converting the ratio to SetPlaybackRate's rate.
+// Note: Time/pitch effects must be enabled first with EnableTimePitchEffects()
+func (p *AudioPlayer) SetTempoBPM(from, to float32) error {
+	if from <= 0 {
+		return errors.New("from BPM must be positive")
+	}
+	if to <= 0 {
+		return errors.New("to BPM must be positive")
+	}
+	return p.SetPlaybackRate(to / from)
+}
+
+// noteSemitones maps a natural note letter to its semitone offset within an
+// octave, C=0.
+var noteSemitones = map[rune]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+// parseNoteName parses scientific pitch notation (e.g. "C4", "F#3", "Eb5")
+// into a MIDI-style semitone number (C4 = 60), so the difference between two
+// note names is just a subtraction.
+func parseNoteName(note string) (int, error) {
+	runes := []rune(note)
+	if len(runes) < 2 {
+		return 0, fmt.Errorf("invalid note name %q", note)
+	}
+
+	base, ok := noteSemitones[unicode.ToUpper(runes[0])]
+	if !ok {
+		return 0, fmt.Errorf("invalid note name %q: unknown pitch letter %q", note, runes[0])
+	}
+
+	rest := runes[1:]
+	accidental := 0
+	for len(rest) > 0 && (rest[0] == '#' || rest[0] == 'b') {
+		if rest[0] == '#' {
+			accidental++
+		} else {
+			accidental--
+		}
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		return 0, fmt.Errorf("invalid note name %q: missing octave", note)
+	}
+
+	octave, err := strconv.Atoi(string(rest))
+	if err != nil {
+		return 0, fmt.Errorf("invalid note name %q: bad octave %q", note, string(rest))
+	}
+
+	return (octave+1)*12 + base + accidental, nil
+}
+
+// overlapMin/Max bound AVAudioUnitTimePitch's overlap parameter, which
+// trades transient smearing (low overlap) against smoothness (high
+// overlap) during time/pitch stretching - the third TimePitch control
+// EnableTimePitchEffects doesn't otherwise expose alongside
+// SetPlaybackRate and SetPitch.
+const (
+	overlapMin float32 = -1.0
+	overlapMax float32 = 1.0
+)
+
+// ErrOverlapOutOfRange reports that a value passed to SetOverlap falls
+// outside AVAudioUnitTimePitch's supported range.
+type ErrOverlapOutOfRange struct {
+	Value    float32
+	Min, Max float32
+}
+
+func (e *ErrOverlapOutOfRange) Error() string {
+	return fmt.Sprintf("overlap %.2f out of range [%.2f, %.2f]", e.Value, e.Min, e.Max)
+}
+
+// SetOverlap sets AVAudioUnitTimePitch's overlap parameter.
+// Note: Time/pitch effects must be enabled first with EnableTimePitchEffects()
+func (p *AudioPlayer) SetOverlap(overlap float32) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if overlap < overlapMin || overlap > overlapMax {
+		return &ErrOverlapOutOfRange{Value: overlap, Min: overlapMin, Max: overlapMax}
+	}
+
+	result := C.audioplayer_set_overlap(p.ptr, C.float(overlap))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
+
+// GetOverlap returns the player's current TimePitch overlap setting.
+func (p *AudioPlayer) GetOverlap() (float32, error) {
+	if p == nil || p.ptr == nil {
+		return 0, errors.New("player is nil")
+	}
+
+	var overlap C.float
+	result := C.audioplayer_get_overlap(p.ptr, &overlap)
+	if result != nil {
+		return 0, errors.New(C.GoString(result))
+	}
+	return float32(overlap), nil
+}
+
+// PitchAlgorithm selects which Apple audio unit a player's time/pitch
+// effects run through, set via SetPitchAlgorithm before calling
+// EnableTimePitchEffects.
+type PitchAlgorithm int
+
+const (
+	// TimeDomain is AVAudioUnitTimePitch's default algorithm: independent
+	// pitch and rate control, cheap enough for real-time use on most
+	// material.
+	TimeDomain PitchAlgorithm = iota
+	// Spectral is AVAudioUnitTimePitch's higher-quality, higher-latency
+	// algorithm, better suited to complex/polyphonic material.
+	Spectral
+	// Varispeed couples pitch to rate the way a physical turntable or tape
+	// deck does (speeding up raises pitch) - AVAudioUnitVarispeed instead
+	// of AVAudioUnitTimePitch, and doesn't need EnableTimePitchEffects'
+	// engine-restart workaround.
+	Varispeed
+)
+
+// SetPitchAlgorithm selects the audio unit EnableTimePitchEffects creates
+// the next time it's called. It has no effect on an already-enabled
+// TimePitch chain - call it, then EnableTimePitchEffects, to switch.
+func (p *AudioPlayer) SetPitchAlgorithm(alg PitchAlgorithm) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if alg < TimeDomain || alg > Varispeed {
+		return fmt.Errorf("unknown pitch algorithm %d", alg)
+	}
+
+	result := C.audioplayer_set_pitch_algorithm(p.ptr, C.int(alg))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
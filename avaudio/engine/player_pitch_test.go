@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseNoteName(t *testing.T) {
+	cases := map[string]int{
+		"C4":  60,
+		"A4":  69,
+		"C-1": 0,
+		"F#3": 54,
+		"Gb5": 78,
+	}
+	for note, want := range cases {
+		got, err := parseNoteName(note)
+		if err != nil {
+			t.Errorf("parseNoteName(%q) returned error: %v", note, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseNoteName(%q) = %d, want %d", note, got, want)
+		}
+	}
+}
+
+func TestParseNoteNameRejectsInvalid(t *testing.T) {
+	for _, note := range []string{"", "H4", "C", "C#", "Cx4"} {
+		if _, err := parseNoteName(note); err == nil {
+			t.Errorf("parseNoteName(%q) = nil error, want one", note)
+		}
+	}
+}
+
+func TestSetPitchNoteComputesSemitoneDelta(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	if err := player.SetPitchNote("C4", "E4"); err != nil {
+		t.Fatalf("SetPitchNote(C4, E4) failed: %v", err)
+	}
+	cents, err := player.GetPitch()
+	if err != nil {
+		t.Fatalf("GetPitch failed: %v", err)
+	}
+	if cents != 400 {
+		t.Errorf("GetPitch() after SetPitchNote(C4, E4) = %v, want 400 (4 semitones)", cents)
+	}
+}
+
+func TestSetPitchSemitonesRejectsOutOfRange(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	err = player.SetPitchSemitones(30)
+	var rangeErr *ErrPitchOutOfRange
+	if !errors.As(err, &rangeErr) {
+		t.Fatalf("SetPitchSemitones(30) error = %v (%T), want *ErrPitchOutOfRange", err, err)
+	}
+	if rangeErr.Cents != 3000 {
+		t.Errorf("ErrPitchOutOfRange.Cents = %v, want 3000", rangeErr.Cents)
+	}
+}
+
+func TestSetTempoBPMConvertsToRate(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	if err := player.SetTempoBPM(120, 150); err != nil {
+		t.Fatalf("SetTempoBPM(120, 150) failed: %v", err)
+	}
+	rate, err := player.GetPlaybackRate()
+	if err != nil {
+		t.Fatalf("GetPlaybackRate failed: %v", err)
+	}
+	if want := float32(150.0 / 120.0); rate != want {
+		t.Errorf("GetPlaybackRate() after SetTempoBPM(120, 150) = %v, want %v", rate, want)
+	}
+}
@@ -0,0 +1,635 @@
+package engine
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// QueueState mirrors dischord's Queue model: every track in a PlayerQueue is
+// either already played (QueueDone), the one currently sounding
+// (QueuePlaying), or still to come (QueueAhead).
+type QueueState int
+
+const (
+	QueueDone QueueState = iota
+	QueuePlaying
+	QueueAhead
+)
+
+// TrackInfo is a snapshot of one PlayerQueue entry, passed to
+// OnTrackChanged as playback advances from one track into the next and
+// returned in QueueSnapshot's Done/Playing/Ahead lists.
+type TrackInfo struct {
+	Path  string
+	State QueueState
+}
+
+// QueueSnapshot is a point-in-time view of every track in a PlayerQueue,
+// split by QueueState like dischord's Queue model: Done (already played),
+// Playing (nil unless a track is currently sounding), and Ahead (still to
+// come).
+type QueueSnapshot struct {
+	Done    []TrackInfo
+	Playing *TrackInfo
+	Ahead   []TrackInfo
+}
+
+// QueueEvent is implemented by every event PlayerQueue.Events delivers,
+// matching the EngineEvent pattern in subscribe.go - separate typed structs
+// rather than one struct with a Kind enum.
+type QueueEvent interface {
+	isQueueEvent()
+}
+
+// QueueTrackStarted is published by trackPosition when playback crosses into a
+// new track.
+type QueueTrackStarted struct {
+	Track TrackInfo
+}
+
+func (QueueTrackStarted) isQueueEvent() {}
+
+// QueueTrackEnded is published by trackPosition for the track playback just
+// crossed out of, immediately before the matching QueueTrackStarted (or before
+// QueueEmpty, for the last track).
+type QueueTrackEnded struct {
+	Track TrackInfo
+}
+
+func (QueueTrackEnded) isQueueEvent() {}
+
+// QueueEmpty is published once every scheduled track has finished playing
+// and Loop is false, the same moment stopActiveLocked releases the queue's
+// player.
+type QueueEmpty struct{}
+
+func (QueueEmpty) isQueueEvent() {}
+
+type queueTrack struct {
+	path string
+	buf  PCMBuffer
+}
+
+// PlayerQueue is a gapless queue of tracks played back-to-back on a single
+// AudioPlayer, for music-player style use cases (dischord's Queue is the
+// model). Unlike Playlist (a fresh AudioPlayer per track, crossfaded in
+// with RampVolume), PlayerQueue decodes every track into a PCMBuffer up
+// front and hands them all to one player via ScheduleBuffer, which
+// AVAudioPlayerNode plays back-to-back with no gap between them - true
+// sample-accurate gapless transitions, including across tracks at
+// different sample rates (schedulePCMBuffer reformats through an
+// AVAudioConverter when a buffer's rate doesn't match the engine's).
+//
+// The tradeoff: once a track has been handed to the player it can't be
+// un-scheduled, so Jump/Swap/Delete only reach tracks still ahead of that
+// point (see scheduled), and Next/Previous restart the player at the new
+// position rather than gaplessly skip - a track already playing gaplessly
+// can't be cut away from cleanly. Loop restarts from the first track the
+// same way once the queue drains, so looping isn't gapless either.
+type PlayerQueue struct {
+	engine *Engine
+
+	mu     sync.Mutex
+	player *AudioPlayer
+	done   chan struct{} // closed to stop the running trackPosition poller
+
+	tracks    []queueTrack
+	order     []int // play order; identity unless Shuffle(true)
+	pos       int   // order index currently playing; -1 before Play
+	scheduled int   // order[:scheduled] have been handed to player already
+	loop      bool
+	paused    bool
+
+	// destNode/destBus, if set via SetDestination, is where the queue's
+	// player connects instead of MainMixerNode.
+	destNode unsafe.Pointer
+	destBus  int
+	hasDest  bool
+
+	// OnTrackChanged, if set, is invoked as the position poller notices
+	// playback cross from one scheduled track into the next.
+	OnTrackChanged func(prev, next TrackInfo)
+
+	events chan QueueEvent
+}
+
+// NewPlayerQueue creates an empty queue whose single AudioPlayer will be
+// attached to e once Play starts it.
+func NewPlayerQueue(e *Engine) *PlayerQueue {
+	return &PlayerQueue{engine: e, pos: -1, events: make(chan QueueEvent, 32)}
+}
+
+// Events returns the channel PlayerQueue publishes QueueTrackStarted, QueueTrackEnded,
+// and QueueEmpty on, for callers that would rather watch one channel than
+// set OnTrackChanged - the same non-blocking, drop-if-full policy Engine.
+// Subscribe uses, since a queue has no natural place to buffer events
+// indefinitely for a slow UI.
+func (q *PlayerQueue) Events() <-chan QueueEvent {
+	return q.events
+}
+
+func (q *PlayerQueue) publish(ev QueueEvent) {
+	select {
+	case q.events <- ev:
+	default:
+	}
+}
+
+// SetDestination routes the queue's player into nodePtr/bus instead of the
+// engine's main mixer. Call before Play.
+func (q *PlayerQueue) SetDestination(nodePtr unsafe.Pointer, bus int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.destNode = nodePtr
+	q.destBus = bus
+	q.hasDest = true
+}
+
+// Enqueue decodes path and appends it to the queue, returning its track
+// index (stable for the life of the queue, unlike order positions - see
+// Swap/Delete). If playback is already underway, the new track is
+// scheduled onto the player immediately, extending the gapless run.
+func (q *PlayerQueue) Enqueue(path string) (int, error) {
+	buf, err := decodeQueueTrack(path)
+	if err != nil {
+		return 0, err
+	}
+	return q.enqueueBuffer(path, buf)
+}
+
+// EnqueueReader reads all of r into a temporary file named with ext (e.g.
+// ".mp3" - AVAudioFile and the Decoder registry both identify format from
+// a file extension, not by sniffing bytes) so it can be decoded the same
+// way as Enqueue, then removes the temporary file.
+func (q *PlayerQueue) EnqueueReader(r io.Reader, ext string) (int, error) {
+	tmp, err := os.CreateTemp("", "macaudio-queue-*"+ext)
+	if err != nil {
+		return 0, err
+	}
+	path := tmp.Name()
+	defer os.Remove(path)
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	buf, err := decodeQueueTrack(path)
+	if err != nil {
+		return 0, err
+	}
+	return q.enqueueBuffer(filepath.Base(path), buf)
+}
+
+func (q *PlayerQueue) enqueueBuffer(path string, buf PCMBuffer) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	trackIdx := len(q.tracks)
+	q.tracks = append(q.tracks, queueTrack{path: path, buf: buf})
+	q.order = append(q.order, trackIdx)
+
+	if q.player != nil {
+		if err := q.scheduleAheadLocked(); err != nil {
+			return trackIdx, err
+		}
+	}
+	return trackIdx, nil
+}
+
+// decodeQueueTrack decodes path through the Decoder registry if one is
+// registered for its extension, falling back to a whole-file read through
+// AudioFile for anything AVAudioFile can open directly.
+func decodeQueueTrack(path string) (PCMBuffer, error) {
+	if dec, ok := lookupDecoder(filepath.Ext(path)); ok {
+		return dec.Decode(path)
+	}
+
+	f, err := OpenAudioFile(path)
+	if err != nil {
+		return PCMBuffer{}, err
+	}
+	defer f.Close()
+
+	channels := f.ChannelCount()
+	if channels == 0 {
+		return PCMBuffer{}, errors.New("file reports no channels")
+	}
+
+	var samples []float32
+	chunk := make([]float32, 4096*channels)
+	for {
+		n, readErr := f.Read(chunk)
+		if n > 0 {
+			samples = append(samples, chunk[:n*channels]...)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return PCMBuffer{}, readErr
+		}
+	}
+
+	return PCMBuffer{
+		Samples:      samples,
+		FrameCount:   len(samples) / channels,
+		ChannelCount: channels,
+		SampleRate:   f.SampleRate(),
+	}, nil
+}
+
+// scheduleAheadLocked hands every not-yet-scheduled track in order to
+// q.player via ScheduleBuffer, which AVAudioPlayerNode queues and plays
+// back-to-back in call order - the mechanism true gapless transitions rely
+// on. Once a track passes through here it counts as scheduled and can no
+// longer be reached by Jump/Swap/Delete.
+func (q *PlayerQueue) scheduleAheadLocked() error {
+	for q.scheduled < len(q.order) {
+		track := q.tracks[q.order[q.scheduled]]
+		if err := q.player.ScheduleBuffer(track.buf, false); err != nil {
+			return err
+		}
+		q.scheduled++
+	}
+	return nil
+}
+
+// Play starts the queue from its first track, or resumes it if Pause left
+// it paused mid-track.
+func (q *PlayerQueue) Play() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.paused {
+		q.paused = false
+		return q.player.Play()
+	}
+	if len(q.order) == 0 {
+		return errors.New("player queue is empty")
+	}
+	if q.player != nil {
+		return errors.New("player queue is already playing")
+	}
+	return q.startAtLocked(0)
+}
+
+// Pause pauses the currently playing track in place, leaving already
+// scheduled buffers intact so Play resumes the gapless run rather than
+// restarting it - unlike Stop, which releases the player entirely.
+func (q *PlayerQueue) Pause() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.player == nil {
+		return errors.New("player queue is not playing")
+	}
+	if err := q.player.Pause(); err != nil {
+		return err
+	}
+	q.paused = true
+	return nil
+}
+
+// Next restarts the queue at the track after the one currently playing.
+// See the PlayerQueue doc comment for why this is a restart, not a
+// gapless skip.
+func (q *PlayerQueue) Next() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_ = q.stopActiveLocked()
+	return q.startAtLocked(q.pos + 1)
+}
+
+// Previous restarts the queue at the track before the one currently
+// playing.
+func (q *PlayerQueue) Previous() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_ = q.stopActiveLocked()
+	return q.startAtLocked(q.pos - 1)
+}
+
+// Jump moves the ahead track at aheadIdx (0 = the next track that will be
+// scheduled once everything already handed to the player finishes, not
+// order[0]) to the front of the ahead list, so it plays next. It returns an
+// error if aheadIdx reaches into tracks already scheduled (see
+// scheduleAheadLocked) - those can no longer be reordered.
+func (q *PlayerQueue) Jump(aheadIdx int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ahead := q.order[q.scheduled:]
+	if aheadIdx < 0 || aheadIdx >= len(ahead) {
+		return errors.New("player queue: ahead index out of range")
+	}
+	track := ahead[aheadIdx]
+	copy(ahead[1:aheadIdx+1], ahead[0:aheadIdx])
+	ahead[0] = track
+	return nil
+}
+
+// Swap exchanges the positions of two ahead tracks (see Jump for what
+// "ahead" means here).
+func (q *PlayerQueue) Swap(aheadIdxA, aheadIdxB int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ahead := q.order[q.scheduled:]
+	if aheadIdxA < 0 || aheadIdxA >= len(ahead) || aheadIdxB < 0 || aheadIdxB >= len(ahead) {
+		return errors.New("player queue: ahead index out of range")
+	}
+	ahead[aheadIdxA], ahead[aheadIdxB] = ahead[aheadIdxB], ahead[aheadIdxA]
+	return nil
+}
+
+// Delete removes the ahead track at aheadIdx from the queue entirely (see
+// Jump for what "ahead" means here).
+func (q *PlayerQueue) Delete(aheadIdx int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ahead := q.order[q.scheduled:]
+	if aheadIdx < 0 || aheadIdx >= len(ahead) {
+		return errors.New("player queue: ahead index out of range")
+	}
+	cut := q.scheduled + aheadIdx
+	q.order = append(q.order[:cut], q.order[cut+1:]...)
+	return nil
+}
+
+// Shuffle enables or disables shuffled order for the ahead tracks (see Jump
+// for what "ahead" means here); tracks already scheduled or played keep
+// their place. Disabling restores ascending track-index order among the
+// remaining ahead tracks.
+func (q *PlayerQueue) Shuffle(shuffle bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ahead := q.order[q.scheduled:]
+	if shuffle {
+		rand.Shuffle(len(ahead), func(i, j int) {
+			ahead[i], ahead[j] = ahead[j], ahead[i]
+		})
+		return
+	}
+	sort.Ints(ahead)
+}
+
+// Loop sets whether the queue restarts from its first track once every
+// scheduled track has finished playing.
+func (q *PlayerQueue) Loop(loop bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.loop = loop
+}
+
+// Stop halts playback and releases the queue's player. The queue's tracks
+// and order are left intact, so Play can resume it from the start.
+func (q *PlayerQueue) Stop() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.stopActiveLocked()
+}
+
+func (q *PlayerQueue) stopActiveLocked() error {
+	if q.player == nil {
+		return nil
+	}
+	close(q.done)
+	err := q.player.Stop()
+	q.player.Destroy()
+	q.player = nil
+	q.paused = false
+	return err
+}
+
+// startAtLocked creates a fresh AudioPlayer, schedules every track from
+// startPos onward onto it, and starts playback - the shared path behind
+// Play, Next, Previous, and loop restarts.
+func (q *PlayerQueue) startAtLocked(startPos int) error {
+	if q.engine == nil {
+		return errors.New("player queue has no engine")
+	}
+	if startPos < 0 || startPos >= len(q.order) {
+		return errors.New("player queue: position out of range")
+	}
+
+	player, err := q.engine.NewPlayer()
+	if err != nil {
+		return err
+	}
+	connectErr := player.ConnectToMainMixer()
+	if q.hasDest {
+		connectErr = player.ConnectToMixer(q.destNode, q.destBus)
+	}
+	if connectErr != nil {
+		player.Destroy()
+		return connectErr
+	}
+
+	q.pos = startPos
+	q.scheduled = startPos
+	q.player = player
+	if err := q.scheduleAheadLocked(); err != nil {
+		player.Destroy()
+		q.player = nil
+		return err
+	}
+	if err := player.Play(); err != nil {
+		player.Destroy()
+		q.player = nil
+		return err
+	}
+
+	q.done = make(chan struct{})
+	go q.trackPosition(player, q.done)
+	q.publish(QueueTrackStarted{Track: q.trackInfoLocked(startPos)})
+	return nil
+}
+
+// trackPosition polls player's playback position and reports QueueState
+// transitions via OnTrackChanged, and drives Loop/natural-exhaustion once
+// every scheduled track has played out. Like Playlist.watchForEnd, there's
+// no native end-of-buffer callback wired up, so this is a coarse poll
+// rather than a precise one.
+func (q *PlayerQueue) trackPosition(player *AudioPlayer, done chan struct{}) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		elapsed, err := player.GetCurrentTime()
+		if err != nil {
+			return
+		}
+		playing, err := player.IsPlaying()
+		if err != nil {
+			return
+		}
+
+		q.mu.Lock()
+		if q.player != player {
+			q.mu.Unlock()
+			return
+		}
+
+		prev := q.pos
+		q.pos = q.posForElapsedLocked(elapsed.Seconds())
+		cb := q.OnTrackChanged
+		trackChanged := q.pos != prev
+		var prevInfo, nextInfo TrackInfo
+		if trackChanged {
+			prevInfo, nextInfo = q.trackInfoLocked(prev), q.trackInfoLocked(q.pos)
+		}
+
+		exhausted := !playing && !q.paused && q.scheduled >= len(q.order)
+		var endedInfo TrackInfo
+		queueEmpty := false
+		if exhausted {
+			endedInfo = q.trackInfoLocked(q.pos)
+			_ = q.stopActiveLocked()
+			if q.loop {
+				_ = q.startAtLocked(0)
+			} else {
+				queueEmpty = true
+			}
+		}
+		q.mu.Unlock()
+
+		switch {
+		case exhausted:
+			// startAtLocked already published QueueTrackStarted for the loop
+			// restart, if any - only the ended track and (if not looping)
+			// QueueEmpty are left to report here.
+			q.publish(QueueTrackEnded{Track: endedInfo})
+			if queueEmpty {
+				q.publish(QueueEmpty{})
+			}
+			if cb != nil {
+				cb(endedInfo, TrackInfo{})
+			}
+		case trackChanged:
+			q.publish(QueueTrackEnded{Track: prevInfo})
+			q.publish(QueueTrackStarted{Track: nextInfo})
+			if cb != nil {
+				cb(prevInfo, nextInfo)
+			}
+		}
+		if exhausted {
+			return
+		}
+	}
+}
+
+// posForElapsedLocked maps elapsedSeconds of continuous playback on the
+// current player to an order index, by walking cumulative track durations.
+func (q *PlayerQueue) posForElapsedLocked(elapsedSeconds float64) int {
+	var cursor float64
+	for i := 0; i < q.scheduled; i++ {
+		track := q.tracks[q.order[i]]
+		cursor += float64(track.buf.FrameCount) / track.buf.SampleRate
+		if elapsedSeconds < cursor {
+			return i
+		}
+	}
+	if q.scheduled == 0 {
+		return 0
+	}
+	return q.scheduled - 1
+}
+
+func (q *PlayerQueue) trackInfoLocked(orderIdx int) TrackInfo {
+	if orderIdx < 0 || orderIdx >= len(q.order) {
+		return TrackInfo{}
+	}
+	track := q.tracks[q.order[orderIdx]]
+	state := QueueAhead
+	switch {
+	case orderIdx < q.pos:
+		state = QueueDone
+	case orderIdx == q.pos:
+		state = QueuePlaying
+	}
+	return TrackInfo{Path: track.path, State: state}
+}
+
+// Snapshot returns the queue's current Done/Playing/Ahead split.
+func (q *PlayerQueue) Snapshot() QueueSnapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var snap QueueSnapshot
+	for i := range q.order {
+		info := q.trackInfoLocked(i)
+		switch info.State {
+		case QueueDone:
+			snap.Done = append(snap.Done, info)
+		case QueuePlaying:
+			playing := info
+			snap.Playing = &playing
+		case QueueAhead:
+			snap.Ahead = append(snap.Ahead, info)
+		}
+	}
+	return snap
+}
+
+// PlayerQueueState is a plain JSON-taggable snapshot of a PlayerQueue's
+// track list, play order, and Loop setting, persisted with encoding/json
+// directly - the same convention MixerScene uses - rather than through a
+// dedicated save/load method. It stores paths, not decoded PCM, and no
+// playback position: RestoreQueue rebuilds the queue stopped, ready for
+// Play.
+type PlayerQueueState struct {
+	Paths []string `json:"paths"` // track index order (the order Enqueue was called in)
+	Order []int    `json:"order"` // play order, as indexes into Paths - reflects Shuffle
+	Loop  bool     `json:"loop"`
+}
+
+// CaptureState returns a JSON-taggable snapshot of q's track list, play
+// order, and Loop setting, for persisting with encoding/json.
+func (q *PlayerQueue) CaptureState() *PlayerQueueState {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	paths := make([]string, len(q.tracks))
+	for i, t := range q.tracks {
+		paths[i] = t.path
+	}
+	return &PlayerQueueState{
+		Paths: paths,
+		Order: append([]int{}, q.order...),
+		Loop:  q.loop,
+	}
+}
+
+// RestoreQueue rebuilds a PlayerQueue on e from a state captured with
+// CaptureState, re-decoding every track from disk in turn (s stores paths,
+// not decoded PCM). The restored queue is stopped; call Play to start it.
+func RestoreQueue(e *Engine, s *PlayerQueueState) (*PlayerQueue, error) {
+	q := NewPlayerQueue(e)
+	for _, path := range s.Paths {
+		if _, err := q.Enqueue(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.Order) == len(q.order) {
+		q.order = append([]int{}, s.Order...)
+	}
+	q.loop = s.Loop
+	return q, nil
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// InstallTap installs a callback-driven tap on the player's own node,
+// delivering deinterleaved PCM frames to cb instead of requiring a caller
+// to poll AnalyzeCurrentPlayback - the path for visualizers, VU meters,
+// waveform recorders, or custom DSP that want a continuous stream rather
+// than point-in-time analysis. It wraps avaudio/tap.InstallCallbackTap
+// with TapFormatPlanarFloat32 and reshapes each delivered TapBuffer into
+// one []float32 per channel; see InstallCallbackTap's doc comment for the
+// delivery guarantees this inherits: a lock-free ring between the render
+// thread and a drain goroutine, dropped-block counting on overflow instead
+// of blocking the render thread, and cb always running on that drain
+// goroutine - never the audio thread - so it's safe to allocate, log, or
+// block in.
+//
+// sampleTime passed to cb is the running total of frames delivered through
+// this tap divided by the player's sample rate, not an absolute host time -
+// the render thread doesn't report one to the tap, and it isn't reset by
+// seeking.
+func (p *AudioPlayer) InstallTap(bufferSize int, cb func(frames [][]float32, sampleTime float64)) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if p.tap != nil {
+		return errors.New("tap is already installed on this player")
+	}
+	if cb == nil {
+		return errors.New("callback cannot be nil")
+	}
+
+	nodePtr, err := p.GetNodePtr()
+	if err != nil {
+		return err
+	}
+
+	var sampleRate float64
+	if info, err := p.GetFileInfo(); err == nil {
+		sampleRate = info.SampleRate
+	}
+
+	var framesSeen uint64
+	t, err := tap.InstallCallbackTap(p.engine.GetNativeEngine(), nodePtr, 0, bufferSize, tap.TapFormatPlanarFloat32, func(buf tap.TapBuffer) {
+		framesSeen += uint64(buf.Frames)
+		sampleTime := 0.0
+		if sampleRate > 0 {
+			sampleTime = float64(framesSeen) / sampleRate
+		}
+		cb(deinterleavePlanar(buf), sampleTime)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.tap = t
+	return nil
+}
+
+// RemoveTap removes the tap installed by InstallTap, if any.
+func (p *AudioPlayer) RemoveTap() error {
+	if p == nil || p.tap == nil {
+		return errors.New("no tap installed on this player")
+	}
+	err := p.tap.Remove()
+	p.tap = nil
+	return err
+}
+
+// deinterleavePlanar splits buf.Float32Data - channel-major when Format is
+// TapFormatPlanarFloat32, i.e. all of channel 0's frames followed by all of
+// channel 1's - into one slice per channel.
+func deinterleavePlanar(buf tap.TapBuffer) [][]float32 {
+	if buf.Channels == 0 || buf.Frames == 0 {
+		return nil
+	}
+	frames := make([][]float32, buf.Channels)
+	for ch := range frames {
+		start := ch * buf.Frames
+		end := start + buf.Frames
+		if start > len(buf.Float32Data) {
+			start = len(buf.Float32Data)
+		}
+		if end > len(buf.Float32Data) {
+			end = len(buf.Float32Data)
+		}
+		frames[ch] = buf.Float32Data[start:end]
+	}
+	return frames
+}
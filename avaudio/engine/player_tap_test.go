@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+func TestDeinterleavePlanar(t *testing.T) {
+	buf := tap.TapBuffer{
+		Format:      tap.TapFormatPlanarFloat32,
+		Frames:      3,
+		Channels:    2,
+		Float32Data: []float32{0, 1, 2, 10, 11, 12},
+	}
+
+	got := deinterleavePlanar(buf)
+	want := [][]float32{{0, 1, 2}, {10, 11, 12}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deinterleavePlanar(%+v) = %v, want %v", buf, got, want)
+	}
+}
+
+func TestDeinterleavePlanarEmpty(t *testing.T) {
+	if got := deinterleavePlanar(tap.TapBuffer{}); got != nil {
+		t.Errorf("expected nil for an empty buffer, got %v", got)
+	}
+}
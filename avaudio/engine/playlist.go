@@ -0,0 +1,324 @@
+package engine
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// RepeatMode controls what Playlist.Next does once it runs past the end of
+// the track list.
+type RepeatMode int
+
+const (
+	// RepeatOff stops the playlist after the last track.
+	RepeatOff RepeatMode = iota
+	// RepeatOne replays the current track indefinitely.
+	RepeatOne
+	// RepeatAll wraps back around to the first track (first shuffled track,
+	// if shuffle is enabled).
+	RepeatAll
+)
+
+// PlaylistEventType identifies what happened in a PlaylistEvent.
+type PlaylistEventType int
+
+const (
+	TrackStarted PlaylistEventType = iota
+	TrackEnded
+	PlaylistEnded
+)
+
+// PlaylistEvent is emitted on a Playlist's event channel as tracks advance.
+type PlaylistEvent struct {
+	Type  PlaylistEventType
+	Path  string
+	Index int
+}
+
+// Playlist sequences playback across a list of file paths, each played by
+// its own AudioPlayer so an outgoing track can keep ramping down while the
+// next one ramps up (see SetCrossfade). It does not decode anything itself;
+// AudioPlayer.LoadFile (or LoadFileStreaming, for formats with a registered
+// Decoder) does the real work per track.
+type Playlist struct {
+	engine *Engine
+
+	mu        sync.Mutex
+	tracks    []string
+	gains     []float32 // per-track playback gain scalar, parallel to tracks; see SetTrackGain
+	order     []int // play order; identity unless shuffled
+	pos       int    // index into order
+	crossfade time.Duration
+	repeat    RepeatMode
+	shuffle   bool
+
+	active *AudioPlayer // currently playing track, nil before Play
+
+	// destNode/destBus, if set via SetDestination, is where each track's
+	// player connects instead of MainMixerNode - e.g. a channel's own
+	// outputMixer, so per-channel volume/pan/sends apply to queued
+	// playback the same way they do to single-file playback.
+	destNode unsafe.Pointer
+	destBus  int
+	hasDest  bool
+
+	events chan PlaylistEvent
+}
+
+// SetDestination routes every track this playlist plays into nodePtr/bus
+// instead of the engine's main mixer. Call before Play/Next starts a track.
+func (pl *Playlist) SetDestination(nodePtr unsafe.Pointer, bus int) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.destNode = nodePtr
+	pl.destBus = bus
+	pl.hasDest = true
+}
+
+// NewPlaylist creates an empty playlist whose AudioPlayers are attached to e.
+func NewPlaylist(e *Engine) *Playlist {
+	return &Playlist{
+		engine: e,
+		events: make(chan PlaylistEvent, 16),
+	}
+}
+
+// Add appends path to the end of the playlist, at gain 1.0 (see
+// SetTrackGain), and returns its track index.
+func (pl *Playlist) Add(path string) int {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.tracks = append(pl.tracks, path)
+	pl.gains = append(pl.gains, 1.0)
+	pl.order = append(pl.order, len(pl.tracks)-1)
+	return len(pl.tracks) - 1
+}
+
+// SetTrackGain sets the playback gain scalar applied when the track at
+// trackIndex (as returned by Add) starts playing - e.g. for
+// loudness-normalized playback. 1.0 is unity gain.
+func (pl *Playlist) SetTrackGain(trackIndex int, gain float32) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if trackIndex < 0 || trackIndex >= len(pl.gains) {
+		return errors.New("playlist track index out of range")
+	}
+	pl.gains[trackIndex] = gain
+	return nil
+}
+
+// Position returns the current index into play order (not the track index -
+// see Add).
+func (pl *Playlist) Position() int {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return pl.pos
+}
+
+// SetCrossfade sets how long consecutive tracks overlap when transitioning.
+// Zero (the default) is a hard cut.
+func (pl *Playlist) SetCrossfade(dur time.Duration) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.crossfade = dur
+}
+
+// SetRepeat sets the behavior of Next once the playlist runs out of tracks.
+func (pl *Playlist) SetRepeat(mode RepeatMode) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.repeat = mode
+}
+
+// SetShuffle enables or disables shuffled play order. Toggling it reshuffles
+// (or restores sequential order) immediately, independent of the track
+// currently playing.
+func (pl *Playlist) SetShuffle(shuffle bool) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.shuffle = shuffle
+
+	pl.order = make([]int, len(pl.tracks))
+	for i := range pl.order {
+		pl.order[i] = i
+	}
+	if shuffle {
+		rand.Shuffle(len(pl.order), func(i, j int) {
+			pl.order[i], pl.order[j] = pl.order[j], pl.order[i]
+		})
+	}
+	pl.pos = 0
+}
+
+// Events returns the channel PlaylistEvents are delivered on. The channel is
+// buffered; a slow consumer can cause Next/Prev to block briefly.
+func (pl *Playlist) Events() <-chan PlaylistEvent {
+	return pl.events
+}
+
+// Play starts the playlist from its current position.
+func (pl *Playlist) Play() error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return pl.startAtLocked(pl.pos)
+}
+
+// Next advances to the next track, crossfading out of the current one (if
+// any and if SetCrossfade set a nonzero duration) and into the next.
+func (pl *Playlist) Next() error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	next := pl.pos + 1
+	if next >= len(pl.order) {
+		switch pl.repeat {
+		case RepeatAll:
+			next = 0
+		default:
+			pl.emitLocked(PlaylistEvent{Type: PlaylistEnded})
+			return pl.stopActiveLocked()
+		}
+	}
+	return pl.transitionToLocked(next)
+}
+
+// Prev moves to the previous track (or restarts the current one if already
+// at the first track and not wrapping), crossfading the same way Next does.
+func (pl *Playlist) Prev() error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	prev := pl.pos - 1
+	if prev < 0 {
+		if pl.repeat == RepeatAll {
+			prev = len(pl.order) - 1
+		} else {
+			prev = 0
+		}
+	}
+	return pl.transitionToLocked(prev)
+}
+
+func (pl *Playlist) transitionToLocked(orderIdx int) error {
+	outgoing := pl.active
+	if outgoing != nil && pl.crossfade > 0 {
+		go func() {
+			_ = outgoing.FadeOutAndStop(pl.crossfade)
+			outgoing.Destroy()
+		}()
+	} else if outgoing != nil {
+		_ = outgoing.Stop()
+		outgoing.Destroy()
+	}
+
+	return pl.startAtLocked(orderIdx)
+}
+
+func (pl *Playlist) stopActiveLocked() error {
+	if pl.active == nil {
+		return nil
+	}
+	err := pl.active.Stop()
+	pl.active.Destroy()
+	pl.active = nil
+	return err
+}
+
+func (pl *Playlist) startAtLocked(orderIdx int) error {
+	if pl.engine == nil {
+		return errors.New("playlist has no engine")
+	}
+	if orderIdx < 0 || orderIdx >= len(pl.order) {
+		return errors.New("playlist index out of range")
+	}
+	pl.pos = orderIdx
+	trackIdx := pl.order[orderIdx]
+	path := pl.tracks[trackIdx]
+	gain := pl.gains[trackIdx]
+
+	player, err := pl.engine.NewPlayer()
+	if err != nil {
+		return err
+	}
+	if err := player.LoadFile(path); err != nil {
+		player.Destroy()
+		return err
+	}
+	connectErr := player.ConnectToMainMixer()
+	if pl.hasDest {
+		connectErr = player.ConnectToMixer(pl.destNode, pl.destBus)
+	}
+	if connectErr != nil {
+		player.Destroy()
+		return connectErr
+	}
+
+	if pl.crossfade > 0 {
+		if err := player.SetVolume(0); err == nil {
+			go func() { _ = player.RampVolume(gain, pl.crossfade, CurveEqualPower) }()
+		}
+	} else if err := player.SetVolume(gain); err != nil {
+		player.Destroy()
+		return err
+	}
+
+	if err := player.Play(); err != nil {
+		player.Destroy()
+		return err
+	}
+
+	pl.active = player
+	pl.emitLocked(PlaylistEvent{Type: TrackStarted, Path: path, Index: trackIdx})
+	go pl.watchForEnd(player, path, trackIdx)
+	return nil
+}
+
+// watchForEnd polls player until it stops playing on its own (reached end of
+// file), then emits TrackEnded and auto-advances. There's no native
+// end-of-file callback wired up, so this is a coarse poll rather than a
+// precise one; if the caller already moved the playlist on (player is no
+// longer pl.active), it's a no-op.
+func (pl *Playlist) watchForEnd(player *AudioPlayer, path string, trackIdx int) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		playing, err := player.IsPlaying()
+		if err != nil {
+			return
+		}
+		if playing {
+			continue
+		}
+
+		pl.mu.Lock()
+		if pl.active != player {
+			pl.mu.Unlock()
+			return
+		}
+		pl.emitLocked(PlaylistEvent{Type: TrackEnded, Path: path, Index: trackIdx})
+		pl.mu.Unlock()
+
+		_ = pl.Next()
+		return
+	}
+}
+
+func (pl *Playlist) emitLocked(ev PlaylistEvent) {
+	select {
+	case pl.events <- ev:
+	default:
+		// Drop rather than block the caller holding pl.mu; a slow consumer
+		// should poll Events more often, not stall playback transitions.
+	}
+}
+
+// Stop halts the currently playing track and releases its player.
+func (pl *Playlist) Stop() error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return pl.stopActiveLocked()
+}
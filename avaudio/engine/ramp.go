@@ -0,0 +1,102 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+#include <stdlib.h>
+
+// Function declarations - sample-accurate parameter ramps scheduled against
+// AVAudioTime on the player node, rather than a Go-side time.Sleep loop.
+const char* audioplayer_ramp_volume(AudioPlayer* player, float target, double durationSeconds, int curve);
+const char* audioplayer_ramp_pan(AudioPlayer* player, float target, double durationSeconds, int curve);
+const char* audioplayer_ramp_playback_rate(AudioPlayer* player, float target, double durationSeconds, int curve);
+const char* audioplayer_ramp_pitch(AudioPlayer* player, float target, double durationSeconds, int curve);
+*/
+import "C"
+
+import (
+	"errors"
+	"time"
+)
+
+// Curve selects the shape of a parameter ramp scheduled by the Ramp*
+// methods below.
+type Curve int
+
+const (
+	// CurveLinear changes value at a constant rate over the ramp duration.
+	CurveLinear Curve = iota
+	// CurveEqualPower follows a quarter-sine/cosine power curve, the
+	// standard shape for crossfades so the perceived loudness stays flat.
+	CurveEqualPower
+	// CurveExponential changes value at a constant relative (dB-like) rate;
+	// falls back to linear if either endpoint is not strictly positive.
+	CurveExponential
+	// CurveSCurve eases in and out (a cosine-based S-curve), for the
+	// smoothest-sounding fades.
+	CurveSCurve
+)
+
+// RampVolume schedules a volume ramp from the player's current volume to
+// target over dur, rendered on the audio thread against AVAudioTime rather
+// than driven by a Go goroutine calling SetVolume in a loop.
+func (p *AudioPlayer) RampVolume(target float32, dur time.Duration, curve Curve) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	result := C.audioplayer_ramp_volume(p.ptr, C.float(target), C.double(dur.Seconds()), C.int(curve))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
+
+// RampPan schedules a pan ramp from the player's current pan to target
+// (-1.0 to +1.0) over dur.
+func (p *AudioPlayer) RampPan(target float32, dur time.Duration, curve Curve) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	result := C.audioplayer_ramp_pan(p.ptr, C.float(target), C.double(dur.Seconds()), C.int(curve))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
+
+// RampPlaybackRate schedules a playback-rate ramp to target over dur.
+func (p *AudioPlayer) RampPlaybackRate(target float32, dur time.Duration, curve Curve) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	result := C.audioplayer_ramp_playback_rate(p.ptr, C.float(target), C.double(dur.Seconds()), C.int(curve))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
+
+// RampPitch schedules a pitch ramp (in cents) to target over dur, for
+// pitch-glide effects.
+func (p *AudioPlayer) RampPitch(target float32, dur time.Duration, curve Curve) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	result := C.audioplayer_ramp_pitch(p.ptr, C.float(target), C.double(dur.Seconds()), C.int(curve))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	return nil
+}
+
+// FadeOutAndStop ramps volume to silence over dur using an equal-power
+// curve, then stops playback. It blocks for the ramp duration plus a small
+// margin so callers don't have to poll IsPlaying.
+func (p *AudioPlayer) FadeOutAndStop(dur time.Duration) error {
+	if err := p.RampVolume(0, dur, CurveEqualPower); err != nil {
+		return err
+	}
+	time.Sleep(dur)
+	return p.Stop()
+}
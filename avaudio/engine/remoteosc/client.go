@@ -0,0 +1,91 @@
+package remoteosc
+
+import (
+	"fmt"
+	"net"
+
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+)
+
+// Client is a minimal OSC client for driving a Surface from scripted tests
+// or external tooling, without hand-marshaling wireosc.Messages for each
+// endpoint.
+type Client struct {
+	conn net.Conn
+}
+
+// Dial connects to a Surface listening at addr (e.g. "127.0.0.1:9102").
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("remoteosc: dial %s: %w", addr, err)
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Close closes the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) send(address string, args ...interface{}) error {
+	data, err := (wireosc.Message{Address: address, Args: args}).Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(data)
+	return err
+}
+
+// PlayerPlay sends /player/<id>/play.
+func (c *Client) PlayerPlay(id string) error {
+	return c.send(fmt.Sprintf("/player/%s/play", id))
+}
+
+// PlayerStop sends /player/<id>/stop.
+func (c *Client) PlayerStop(id string) error {
+	return c.send(fmt.Sprintf("/player/%s/stop", id))
+}
+
+// PlayerRate sends /player/<id>/rate with a playback rate (0.25-4.0).
+func (c *Client) PlayerRate(id string, rate float32) error {
+	return c.send(fmt.Sprintf("/player/%s/rate", id), rate)
+}
+
+// PlayerPitch sends /player/<id>/pitch with a pitch shift in cents.
+func (c *Client) PlayerPitch(id string, cents float32) error {
+	return c.send(fmt.Sprintf("/player/%s/pitch", id), cents)
+}
+
+// MixerVolume sends /mixer/<bus>/volume.
+func (c *Client) MixerVolume(bus int, volume float32) error {
+	return c.send(fmt.Sprintf("/mixer/%d/volume", bus), volume)
+}
+
+// EffectParam sends /effect/<effectIndex>/<identifier>.
+func (c *Client) EffectParam(effectIndex int, identifier string, value float32) error {
+	return c.send(fmt.Sprintf("/effect/%d/%s", effectIndex, identifier), value)
+}
+
+// EngineStart sends /engine/start.
+func (c *Client) EngineStart() error {
+	return c.send("/engine/start")
+}
+
+// EngineStop sends /engine/stop.
+func (c *Client) EngineStop() error {
+	return c.send("/engine/stop")
+}
+
+// Bundle sends every message in one OSC bundle at timetag (0 means
+// "immediately", per the OSC 1.0 spec), so several endpoints - e.g. a few
+// /mixer/<bus>/volume updates - apply together instead of as separate
+// datagrams.
+func (c *Client) Bundle(timetag uint64, messages ...wireosc.Message) error {
+	data, err := (wireosc.Bundle{Timetag: timetag, Elements: messages}).Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(data)
+	return err
+}
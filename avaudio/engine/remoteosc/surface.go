@@ -0,0 +1,218 @@
+// Package remoteosc exposes an avaudio/engine.Engine's players, one
+// avaudio/mixer.Mixer's bus volumes, and one avaudio/pluginchain.PluginChain's
+// effect parameters over Open Sound Control, plus /engine/start and
+// /engine/stop - a single cross-cutting surface an external controller
+// (TouchOSC, custom hardware, another host) can drive the whole demo graph
+// through, distinct from avaudio/engine/mixerosc (bus volume/pan/mute/solo
+// only, multiple mixers addressed by name) and avaudio/engine/osc
+// (macaudio.Engine channel faders):
+//
+//	/player/<id>/play
+//	/player/<id>/stop
+//	/player/<id>/rate    f   -- 0.25-4.0 -> AudioPlayer.SetPlaybackRate
+//	/player/<id>/pitch   f   -- cents, -2400-2400 -> AudioPlayer.SetPitch
+//	/mixer/<bus>/volume  f   -- 0.0-1.0 -> Mixer.SetInputVolume
+//	/effect/<id>/<param> f   -- <id> is an effect index, <param> a
+//	                            plugins.Parameter.Identifier -> PluginChain.SetParameter
+//	/engine/start
+//	/engine/stop
+//
+// Players are addressed by a caller-chosen id registered with BindPlayer,
+// the same name-to-pointer indirection mixerosc uses for mixers; the mixer
+// and effect chain are each singular, since this surface exists to drive
+// one demo's graph rather than arbitrate between several. Unknown addresses
+// and unresolvable ids are ignored, the same policy every other OSC surface
+// in this tree uses.
+package remoteosc
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	engine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/mixer"
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+	"github.com/shaban/macaudio/avaudio/pluginchain"
+)
+
+// Surface routes incoming OSC messages to eng's players, mx's buses, and
+// chain's effect parameters, serving over transport. mx and chain may each
+// be nil, in which case their addresses are simply ignored.
+type Surface struct {
+	eng       *engine.Engine
+	transport wireosc.Transport
+	mx        *mixer.Mixer
+	chain     *pluginchain.PluginChain
+
+	mu      sync.Mutex
+	players map[string]*engine.AudioPlayer
+}
+
+// NewSurface creates a Surface bound to eng, mx, and chain, serving over
+// transport.
+func NewSurface(eng *engine.Engine, transport wireosc.Transport, mx *mixer.Mixer, chain *pluginchain.PluginChain) *Surface {
+	return &Surface{
+		eng:       eng,
+		transport: transport,
+		mx:        mx,
+		chain:     chain,
+		players:   make(map[string]*engine.AudioPlayer),
+	}
+}
+
+// Listen opens a UDP OSC transport on addr (e.g. "0.0.0.0:9102") and returns
+// a Surface bound to it, already serving in the background.
+func Listen(eng *engine.Engine, addr string, mx *mixer.Mixer, chain *pluginchain.PluginChain) (*Surface, error) {
+	transport, err := wireosc.ListenUDP(addr)
+	if err != nil {
+		return nil, fmt.Errorf("remoteosc: failed to listen on %s: %w", addr, err)
+	}
+
+	s := NewSurface(eng, transport, mx, chain)
+	go s.Serve()
+	return s, nil
+}
+
+// Serve starts dispatching incoming OSC packets until the transport errors
+// or is closed.
+func (s *Surface) Serve() error {
+	return s.transport.Serve(s.handle)
+}
+
+// Close stops the underlying transport.
+func (s *Surface) Close() error {
+	return s.transport.Close()
+}
+
+// BindPlayer registers player under id, so /player/<id>/... addresses reach
+// it. Binding an id that's already registered replaces the previous player.
+func (s *Surface) BindPlayer(id string, player *engine.AudioPlayer) {
+	s.mu.Lock()
+	s.players[id] = player
+	s.mu.Unlock()
+}
+
+// UnbindPlayer removes id's registration, if any.
+func (s *Surface) UnbindPlayer(id string) {
+	s.mu.Lock()
+	delete(s.players, id)
+	s.mu.Unlock()
+}
+
+func (s *Surface) player(id string) (*engine.AudioPlayer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.players[id]
+	return p, ok
+}
+
+func (s *Surface) handle(msg wireosc.Message, addr net.Addr) {
+	switch {
+	case msg.Address == "/engine/start":
+		_ = s.eng.Start()
+	case msg.Address == "/engine/stop":
+		s.eng.Stop()
+	case strings.HasPrefix(msg.Address, "/player/"):
+		s.handlePlayer(msg)
+	case strings.HasPrefix(msg.Address, "/mixer/"):
+		s.handleMixer(msg)
+	case strings.HasPrefix(msg.Address, "/effect/"):
+		s.handleEffect(msg)
+	}
+}
+
+func floatArg(args []interface{}) (float32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	}
+	return 0, false
+}
+
+func (s *Surface) handlePlayer(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "player" {
+		return
+	}
+	id, param := parts[1], parts[2]
+
+	player, ok := s.player(id)
+	if !ok {
+		return
+	}
+
+	switch param {
+	case "play":
+		_ = player.Play()
+	case "stop":
+		_ = player.Stop()
+	case "rate":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = player.SetPlaybackRate(v)
+	case "pitch":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = player.SetPitch(v)
+	}
+}
+
+func (s *Surface) handleMixer(msg wireosc.Message) {
+	if s.mx == nil {
+		return
+	}
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "mixer" || parts[2] != "volume" {
+		return
+	}
+	bus, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return
+	}
+	v, ok := floatArg(msg.Args)
+	if !ok {
+		return
+	}
+	_ = s.mx.SetInputVolume(bus, v)
+}
+
+func (s *Surface) handleEffect(msg wireosc.Message) {
+	if s.chain == nil {
+		return
+	}
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "effect" {
+		return
+	}
+	effectIndex, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return
+	}
+	v, ok := floatArg(msg.Args)
+	if !ok {
+		return
+	}
+
+	_, plugin, err := s.chain.GetEffectAt(effectIndex)
+	if err != nil {
+		return
+	}
+	for _, param := range plugin.Parameters {
+		if param.Identifier == parts[2] {
+			_ = s.chain.SetParameter(effectIndex, param, v)
+			return
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package remoteosc
+
+import (
+	"testing"
+	"time"
+
+	engine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/mixer"
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+)
+
+// newTestSurface starts a Surface bound to a fresh engine and mixer, serving
+// over a UDP transport on an ephemeral port, and returns it alongside a
+// dialed Client.
+func newTestSurface(t *testing.T) (*Surface, *engine.Engine, *mixer.Mixer, *Client) {
+	t.Helper()
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	t.Cleanup(eng.Destroy)
+
+	mx, err := mixer.New(eng)
+	if err != nil {
+		t.Fatalf("failed to create mixer: %v", err)
+	}
+
+	transport, err := wireosc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen UDP: %v", err)
+	}
+
+	surface := NewSurface(eng, transport, mx, nil)
+	t.Cleanup(func() { surface.Close() })
+	go surface.Serve()
+
+	client, err := Dial(transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("failed to dial surface: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return surface, eng, mx, client
+}
+
+func TestSurfacePlayerRateAndPitch(t *testing.T) {
+	surface, eng, _, client := newTestSurface(t)
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+	surface.BindPlayer("deck-a", player)
+
+	if err := client.PlayerRate("deck-a", 1.5); err != nil {
+		t.Fatalf("PlayerRate failed: %v", err)
+	}
+	if err := client.PlayerPitch("deck-a", 300); err != nil {
+		t.Fatalf("PlayerPitch failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if rate, err := player.GetPlaybackRate(); err != nil || rate < 1.49 || rate > 1.51 {
+		t.Errorf("expected playback rate ~1.5, got %v (err %v)", rate, err)
+	}
+	if pitch, err := player.GetPitch(); err != nil || pitch != 300 {
+		t.Errorf("expected pitch 300, got %v (err %v)", pitch, err)
+	}
+}
+
+func TestSurfacePlayerPlayAndStop(t *testing.T) {
+	surface, eng, _, client := newTestSurface(t)
+
+	player, err := eng.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+	if err := player.ConnectToMainMixer(); err != nil {
+		t.Fatalf("ConnectToMainMixer failed: %v", err)
+	}
+	surface.BindPlayer("deck-a", player)
+
+	if err := client.PlayerPlay("deck-a"); err != nil {
+		t.Fatalf("PlayerPlay failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := client.PlayerStop("deck-a"); err != nil {
+		t.Fatalf("PlayerStop failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if playing, err := player.IsPlaying(); err != nil || playing {
+		t.Errorf("expected player stopped after /player/deck-a/stop, got playing=%v (err %v)", playing, err)
+	}
+}
+
+func TestSurfaceUnboundPlayerIsIgnored(t *testing.T) {
+	_, _, _, client := newTestSurface(t)
+
+	if err := client.PlayerPlay("no-such-player"); err != nil {
+		t.Fatalf("PlayerPlay failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestSurfaceMixerVolume(t *testing.T) {
+	_, eng, mx, client := newTestSurface(t)
+
+	source, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("CreateMixerNode failed: %v", err)
+	}
+	bus, err := mx.AddSource(source, -1)
+	if err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+
+	if err := client.MixerVolume(bus, 0.4); err != nil {
+		t.Fatalf("MixerVolume failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	snap := mx.Snapshot()
+	found := false
+	for _, b := range snap.Buses {
+		if b.Bus == bus {
+			found = true
+			if b.Volume < 0.39 || b.Volume > 0.41 {
+				t.Errorf("expected bus %d volume ~0.4, got %v", bus, b.Volume)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected bus %d in snapshot", bus)
+	}
+}
+
+func TestSurfaceEngineStartAndStop(t *testing.T) {
+	_, eng, _, client := newTestSurface(t)
+
+	if err := client.EngineStart(); err != nil {
+		t.Fatalf("EngineStart failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !eng.IsRunning() {
+		t.Error("expected engine running after /engine/start")
+	}
+
+	if err := client.EngineStop(); err != nil {
+		t.Fatalf("EngineStop failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if eng.IsRunning() {
+		t.Error("expected engine stopped after /engine/stop")
+	}
+}
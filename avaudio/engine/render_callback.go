@@ -0,0 +1,58 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+
+// AUAudioUnit-backed processing node whose render block calls back into Go
+// for each buffer - the realtime-DSP counterpart to nodes.go's taps, which
+// only observe a signal rather than generate/transform one.
+AudioNodeResult renderunit_create(void* enginePtr);
+const char* renderunit_release(void* nodePtr);
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// RenderCallback processes one block of interleaved float32 samples for a
+// node created by CreateRenderUnit. in/out are stereo-interleaved and share
+// frames length; out starts zeroed. Called on the realtime audio thread, so
+// it must not allocate, block, or call back into the engine.
+//
+// Wiring the render block itself into the Go runtime needs a //export'd
+// trampoline this tree doesn't have yet (see CreateRenderUnit) - for now
+// RenderCallback documents the shape ProcessingChannel stores its callback
+// in, matching how Dispatcher.OnRender/OnXRun register handlers ahead of
+// their own render-notify-tap binding.
+type RenderCallback func(in, out []float32, frames int)
+
+// CreateRenderUnit creates an AUAudioUnit-backed node attached to e's graph,
+// ready to be connected like any other node (see Engine.Connect). The
+// render block that would invoke a Go RenderCallback per buffer is not
+// wired up yet - see RenderCallback's doc comment - so a node from this
+// passes silence through until that binding exists.
+func (e *Engine) CreateRenderUnit() (unsafe.Pointer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	result := C.renderunit_create(e.ptr)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// ReleaseRenderUnit releases a node created by CreateRenderUnit.
+func (e *Engine) ReleaseRenderUnit(nodePtr unsafe.Pointer) error {
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+	errorStr := C.renderunit_release(nodePtr)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
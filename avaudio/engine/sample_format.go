@@ -0,0 +1,106 @@
+package engine
+
+import "fmt"
+
+// SampleFormat is the sample storage format EnhancedAudioSpec and Format
+// build around, mirroring the cpal/portaudio SampleFormat model instead of
+// a bare bit-depth integer - two formats can share a bit depth (32-bit
+// float vs. 32-bit int) but aren't interchangeable, which BitDepth alone
+// couldn't express.
+type SampleFormat int
+
+// SampleFormatFloat32 is the zero value: an EnhancedAudioSpec{} literal
+// that doesn't set SampleFormat still builds the float32 format
+// AVAudioEngine uses internally, matching this package's behavior before
+// SampleFormat existed (every format it built was float32).
+const (
+	SampleFormatFloat32 SampleFormat = iota
+	SampleFormatFloat64
+	SampleFormatInt16
+	SampleFormatInt24
+	SampleFormatInt32
+)
+
+// String returns the format's name, e.g. "Int16" or "Float32".
+func (f SampleFormat) String() string {
+	switch f {
+	case SampleFormatInt16:
+		return "Int16"
+	case SampleFormatInt24:
+		return "Int24"
+	case SampleFormatInt32:
+		return "Int32"
+	case SampleFormatFloat32:
+		return "Float32"
+	case SampleFormatFloat64:
+		return "Float64"
+	default:
+		return fmt.Sprintf("SampleFormat(%d)", int(f))
+	}
+}
+
+// BitDepth returns the number of bits per sample this format uses - the
+// same values EnhancedAudioSpec's old BitDepth int field carried directly.
+func (f SampleFormat) BitDepth() int {
+	switch f {
+	case SampleFormatInt16:
+		return 16
+	case SampleFormatInt24:
+		return 24
+	case SampleFormatInt32, SampleFormatFloat32:
+		return 32
+	case SampleFormatFloat64:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// BytesPerSample returns BitDepth/8, the size of one sample of this format
+// in one channel.
+func (f SampleFormat) BytesPerSample() int {
+	return f.BitDepth() / 8
+}
+
+// SampleFormatFromBitDepth maps a legacy bit-depth int onto a SampleFormat,
+// for callers still carrying a bare bit depth (e.g. AudioSpec.BitDepth)
+// that now needs to become an EnhancedAudioSpec.SampleFormat. 32 is assumed
+// float, matching AVAudioEngine's native format and this package's prior
+// hard-coded pcmFormatFloat32 behavior; pass SampleFormatInt32 explicitly
+// if 32-bit integer was actually meant.
+func SampleFormatFromBitDepth(bitDepth int) SampleFormat {
+	switch bitDepth {
+	case 16:
+		return SampleFormatInt16
+	case 24:
+		return SampleFormatInt24
+	case 64:
+		return SampleFormatFloat64
+	default:
+		return SampleFormatFloat32
+	}
+}
+
+// avAudioCommonFormat maps a SampleFormat onto the AVAudioCommonFormat raw
+// value audioformat_new_from_spec passes to +[AVAudioFormat
+// initStandardFormatWithSampleRate:channels:]/initWithCommonFormat:...
+// AVAudioCommonFormat has no native 24-bit case, so SampleFormatInt24
+// returns an error - capture/play as Int32 or Float32 and use
+// macaudio/convert.ConvertF32ToI24 (or ConvertI32ToI24, once one exists)
+// to pack down to 24-bit afterward.
+func avAudioCommonFormat(f SampleFormat) (int, error) {
+	switch f {
+	case SampleFormatFloat32:
+		return 1, nil // AVAudioPCMFormatFloat32
+	case SampleFormatFloat64:
+		return 2, nil // AVAudioPCMFormatFloat64
+	case SampleFormatInt16:
+		return 3, nil // AVAudioPCMFormatInt16
+	case SampleFormatInt32:
+		return 4, nil // AVAudioPCMFormatInt32
+	case SampleFormatInt24:
+		return 0, fmt.Errorf("AVAudioCommonFormat has no native 24-bit PCM format; capture/play as Int32 or Float32 and convert with macaudio/convert")
+	default:
+		return 0, fmt.Errorf("unknown sample format %v", f)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import "testing"
+
+func TestSampleFormatZeroValueIsFloat32(t *testing.T) {
+	var f SampleFormat
+	if f != SampleFormatFloat32 {
+		t.Errorf("expected zero value to be SampleFormatFloat32, got %v", f)
+	}
+	spec := EnhancedAudioSpec{}
+	if spec.BitDepth() != 32 {
+		t.Errorf("expected zero-value EnhancedAudioSpec.BitDepth() to be 32, got %d", spec.BitDepth())
+	}
+}
+
+func TestSampleFormatBitDepth(t *testing.T) {
+	tests := []struct {
+		format SampleFormat
+		want   int
+	}{
+		{SampleFormatInt16, 16},
+		{SampleFormatInt24, 24},
+		{SampleFormatInt32, 32},
+		{SampleFormatFloat32, 32},
+		{SampleFormatFloat64, 64},
+	}
+	for _, tt := range tests {
+		if got := tt.format.BitDepth(); got != tt.want {
+			t.Errorf("%v.BitDepth() = %d, want %d", tt.format, got, tt.want)
+		}
+		if got := tt.format.BytesPerSample(); got != tt.want/8 {
+			t.Errorf("%v.BytesPerSample() = %d, want %d", tt.format, got, tt.want/8)
+		}
+	}
+}
+
+func TestSampleFormatFromBitDepth(t *testing.T) {
+	tests := []struct {
+		bitDepth int
+		want     SampleFormat
+	}{
+		{16, SampleFormatInt16},
+		{24, SampleFormatInt24},
+		{32, SampleFormatFloat32},
+		{64, SampleFormatFloat64},
+		{0, SampleFormatFloat32},
+	}
+	for _, tt := range tests {
+		if got := SampleFormatFromBitDepth(tt.bitDepth); got != tt.want {
+			t.Errorf("SampleFormatFromBitDepth(%d) = %v, want %v", tt.bitDepth, got, tt.want)
+		}
+	}
+}
+
+func TestAVAudioCommonFormatRejectsInt24(t *testing.T) {
+	if _, err := avAudioCommonFormat(SampleFormatInt24); err == nil {
+		t.Error("expected avAudioCommonFormat(SampleFormatInt24) to return an error")
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+
+// AVAudioUnitSampler-backed instrument node - Apple's built-in sample
+// playback AudioUnit (type "aumu", subtype "samp", manufacturer "appl"),
+// wired the same way synth_create_sine et al. attach a generator node to
+// e's graph. Unlike those, it accepts live MIDI (sampler_send_midi_event)
+// and a loadable sound bank (sampler_load_soundfont) instead of just a
+// couple of scalar parameters.
+AudioNodeResult sampler_create(void* enginePtr);
+const char* sampler_load_soundfont(void* nodePtr, const char* path, int program, int bankMSB, int bankLSB);
+const char* sampler_send_midi_event(void* nodePtr, int status, int data1, int data2);
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// CreateSamplerNode attaches an AVAudioUnitSampler node to e's graph. The
+// returned pointer has no sound bank loaded yet - call LoadSoundFont before
+// expecting audible output from SendSamplerMIDIEvent/SendSamplerNoteOn.
+func (e *Engine) CreateSamplerNode() (unsafe.Pointer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	result := C.sampler_create(e.ptr)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// LoadSoundFont loads the SoundFont2 or DLS bank at path into the sampler
+// node created by CreateSamplerNode, selecting program/bankMSB/bankLSB as
+// the active instrument within it - the same triple
+// AVAudioUnitSampler.loadSoundBankInstrument(at:program:bankMSB:bankLSB:)
+// takes on the Swift/ObjC side this wraps.
+func (e *Engine) LoadSoundFont(nodePtr unsafe.Pointer, path string, program, bankMSB, bankLSB uint8) error {
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	errorStr := C.sampler_load_soundfont(nodePtr, cPath, C.int(program), C.int(bankMSB), C.int(bankLSB))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SendSamplerMIDIEvent sends a raw 3-byte MIDI channel message (status,
+// data1, data2) straight to the sampler node, the same entry point a bound
+// MIDI controller's Note On/Off or CC ends up at - see avaudio/unit's
+// Effect.SendMIDIEvent for the equivalent on a generic plugin-chain
+// instrument AU.
+func (e *Engine) SendSamplerMIDIEvent(nodePtr unsafe.Pointer, status, data1, data2 byte) error {
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+	errorStr := C.sampler_send_midi_event(nodePtr, C.int(status), C.int(data1), C.int(data2))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
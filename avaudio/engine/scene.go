@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// MixerSceneBus captures one input bus's volume and pan as part of a
+// MixerScene.
+type MixerSceneBus struct {
+	Bus    int     `json:"bus"`
+	Volume float32 `json:"volume"`
+	Pan    float32 `json:"pan"`
+}
+
+// MixerSceneMixer captures one named mixer's full per-bus state within a
+// MixerScene. Name is the identifier assigned via NameMixer - a scene always
+// addresses its mixers by name rather than by pointer, since a pointer has
+// no meaning once the scene is persisted to disk and reloaded.
+type MixerSceneMixer struct {
+	Name  string          `json:"name"`
+	Buses []MixerSceneBus `json:"buses"`
+}
+
+// MixerScene is a snapshot of one or more mixers' full per-bus volume and
+// pan state, captured by Engine.CaptureMixerScene and recalled with
+// Engine.ApplyMixerScene or Engine.MorphToScene. It's a plain JSON-taggable
+// struct, persisted with encoding/json directly rather than through any
+// dedicated save/load method - the analog of a live-mixing console's
+// snapshot feature.
+type MixerScene struct {
+	Mixers []MixerSceneMixer `json:"mixers"`
+}
+
+// CaptureMixerScene captures the current volume and pan of every input bus
+// on each of mixers into a MixerScene. Each mixer must already have a name
+// assigned via NameMixer - CaptureMixerScene returns an error for any
+// pointer that doesn't, since an unnamed mixer has nothing a reloaded scene
+// could resolve it by.
+func (e *Engine) CaptureMixerScene(mixers ...unsafe.Pointer) (*MixerScene, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+
+	scene := &MixerScene{Mixers: make([]MixerSceneMixer, 0, len(mixers))}
+	for _, mixerPtr := range mixers {
+		name, ok := e.mixerName(mixerPtr)
+		if !ok {
+			return nil, fmt.Errorf("engine: mixer %p has no name; call NameMixer before CaptureMixerScene", mixerPtr)
+		}
+
+		count, err := e.GetNodeInputCount(mixerPtr)
+		if err != nil {
+			return nil, err
+		}
+
+		buses := make([]MixerSceneBus, 0, count)
+		for bus := 0; bus < count; bus++ {
+			volume, err := e.GetMixerVolumeForBus(mixerPtr, bus)
+			if err != nil {
+				return nil, err
+			}
+			pan, err := e.GetMixerPanForBus(mixerPtr, bus)
+			if err != nil {
+				return nil, err
+			}
+			buses = append(buses, MixerSceneBus{Bus: bus, Volume: volume, Pan: pan})
+		}
+
+		scene.Mixers = append(scene.Mixers, MixerSceneMixer{Name: name, Buses: buses})
+	}
+	return scene, nil
+}
+
+// ApplyMixerScene applies every mixer+bus volume/pan captured in s
+// immediately, via SetMixerVolumeForBus/SetMixerPanForBus. Each mixer named
+// in s must currently be named the same way via NameMixer (not necessarily
+// the same pointer it was captured from) - a scene captured in one process
+// can be replayed in another as long as NameMixer was called with matching
+// names first.
+func (e *Engine) ApplyMixerScene(s *MixerScene) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if s == nil {
+		return errors.New("engine: scene is nil")
+	}
+
+	for _, m := range s.Mixers {
+		mixerPtr, ok := e.resolveMixerName(m.Name)
+		if !ok {
+			return fmt.Errorf("engine: no mixer named %q; call NameMixer before ApplyMixerScene", m.Name)
+		}
+		for _, bus := range m.Buses {
+			if err := e.SetMixerVolumeForBus(mixerPtr, bus.Volume, bus.Bus); err != nil {
+				return err
+			}
+			if err := e.SetMixerPanForBus(mixerPtr, bus.Pan, bus.Bus); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MorphToScene applies every mixer+bus volume/pan captured in s like
+// ApplyMixerScene, but ramps each parameter there over duration via the fade
+// scheduler (see FadeMixerVolume/FadeMixerPan) instead of jumping
+// immediately - every parameter in s interpolates in parallel, since each
+// fade runs on Engine's single scheduler goroutine rather than its own.
+func (e *Engine) MorphToScene(s *MixerScene, duration time.Duration, curve FadeCurve) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if s == nil {
+		return errors.New("engine: scene is nil")
+	}
+
+	for _, m := range s.Mixers {
+		mixerPtr, ok := e.resolveMixerName(m.Name)
+		if !ok {
+			return fmt.Errorf("engine: no mixer named %q; call NameMixer before MorphToScene", m.Name)
+		}
+		for _, bus := range m.Buses {
+			e.FadeMixerVolume(mixerPtr, bus.Bus, bus.Volume, duration, curve)
+			e.FadeMixerPan(mixerPtr, bus.Bus, bus.Pan, duration, curve)
+		}
+	}
+	return nil
+}
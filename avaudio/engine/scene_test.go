@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCaptureAndApplyMixerScene(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	eng.NameMixer(mixerPtr, "main")
+
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.4, 0); err != nil {
+		t.Fatalf("Failed to set volume: %v", err)
+	}
+	if err := eng.SetMixerPanForBus(mixerPtr, -0.3, 0); err != nil {
+		t.Fatalf("Failed to set pan: %v", err)
+	}
+
+	scene, err := eng.CaptureMixerScene(mixerPtr)
+	if err != nil {
+		t.Fatalf("CaptureMixerScene failed: %v", err)
+	}
+
+	// Round-trip through JSON, since a scene is meant to be persisted.
+	data, err := json.Marshal(scene)
+	if err != nil {
+		t.Fatalf("failed to marshal scene: %v", err)
+	}
+	var reloaded MixerScene
+	if err := json.Unmarshal(data, &reloaded); err != nil {
+		t.Fatalf("failed to unmarshal scene: %v", err)
+	}
+
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.9, 0); err != nil {
+		t.Fatalf("Failed to perturb volume: %v", err)
+	}
+	if err := eng.SetMixerPanForBus(mixerPtr, 0.9, 0); err != nil {
+		t.Fatalf("Failed to perturb pan: %v", err)
+	}
+
+	if err := eng.ApplyMixerScene(&reloaded); err != nil {
+		t.Fatalf("ApplyMixerScene failed: %v", err)
+	}
+
+	volume, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil || volume < 0.39 || volume > 0.41 {
+		t.Errorf("expected volume restored to ~0.4, got %v (err %v)", volume, err)
+	}
+	pan, err := eng.GetMixerPanForBus(mixerPtr, 0)
+	if err != nil || pan != -0.3 {
+		t.Errorf("expected pan restored to -0.3, got %v (err %v)", pan, err)
+	}
+}
+
+func TestCaptureMixerSceneRequiresName(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+
+	if _, err := eng.CaptureMixerScene(mixerPtr); err == nil {
+		t.Error("expected CaptureMixerScene to fail for an unnamed mixer, got nil error")
+	}
+}
+
+func TestMorphToSceneInterpolatesTowardTarget(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to create mixer: %v", err)
+	}
+	eng.NameMixer(mixerPtr, "main")
+	if err := eng.SetMixerVolumeForBus(mixerPtr, 0.0, 0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	scene := &MixerScene{Mixers: []MixerSceneMixer{
+		{Name: "main", Buses: []MixerSceneBus{{Bus: 0, Volume: 1.0, Pan: 0.0}}},
+	}}
+
+	if err := eng.MorphToScene(scene, 40*time.Millisecond, FadeLinear); err != nil {
+		t.Fatalf("MorphToScene failed: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	volume, err := eng.GetMixerVolumeForBus(mixerPtr, 0)
+	if err != nil || volume < 0.99 {
+		t.Errorf("expected volume to reach ~1.0 after morph completes, got %v (err %v)", volume, err)
+	}
+}
@@ -0,0 +1,309 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// scheduleTickInterval is how often Run's background goroutine checks
+// MaxDuration/StopAt against the current time.
+const scheduleTickInterval = time.Second
+
+// ScheduleEvent is implemented by every event Schedule.Events delivers,
+// matching the QueueEvent pattern in player_queue.go - separate typed
+// structs rather than one struct with a Kind enum.
+type ScheduleEvent interface {
+	isScheduleEvent()
+}
+
+// ScheduleStarted is published once, when Run begins playing the
+// schedule's players.
+type ScheduleStarted struct{}
+
+func (ScheduleStarted) isScheduleEvent() {}
+
+// ScheduleChimeFired is published each time the chime interval elapses and
+// the chime file starts playing.
+type ScheduleChimeFired struct {
+	At time.Time
+}
+
+func (ScheduleChimeFired) isScheduleEvent() {}
+
+// ScheduleStopReason identifies why a Schedule stopped.
+type ScheduleStopReason int
+
+const (
+	// ScheduleStoppedManually means Stop was called before MaxDuration or
+	// StopAt was ever reached.
+	ScheduleStoppedManually ScheduleStopReason = iota
+	// ScheduleStoppedMaxDuration means MaxDuration elapsed since Run.
+	ScheduleStoppedMaxDuration
+	// ScheduleStoppedDeadline means the StopAt wall-clock deadline passed.
+	ScheduleStoppedDeadline
+)
+
+// ScheduleStopped is published once, immediately before Run's background
+// goroutine calls Engine.Stop.
+type ScheduleStopped struct {
+	Reason ScheduleStopReason
+}
+
+func (ScheduleStopped) isScheduleEvent() {}
+
+// Schedule is the alarm-clock/meditation-timer/workout-interval pattern:
+// play a set of players for up to MaxDuration (or until a StopAt
+// deadline), fading in from silence over FadeIn via AudioPlayer.FadeTo,
+// with a chime file mixed onto the main mixer every ChimeEvery interval,
+// then stopping the engine automatically - the batteries-included use case
+// this otherwise requires reassembling out of a ticker loop by hand.
+//
+// Configure it with the chainable MaxDuration/FadeIn/ChimeEvery/StopAt/
+// Players setters, then call Run. Lifecycle events (ScheduleStarted,
+// ScheduleChimeFired, ScheduleStopped) are delivered on the channel Events
+// returns.
+type Schedule struct {
+	engine *Engine
+
+	mu          sync.Mutex
+	players     []*AudioPlayer
+	maxDuration time.Duration
+	fadeIn      time.Duration
+	chimeEvery  time.Duration
+	chimeFile   string
+	deadline    time.Time
+	hasDeadline bool
+	stopCh      chan struct{}
+
+	events chan ScheduleEvent
+	runWG  sync.WaitGroup
+}
+
+// NewSchedule creates an unconfigured Schedule against e. Nothing plays
+// until Run is called.
+func (e *Engine) NewSchedule() *Schedule {
+	return &Schedule{engine: e, events: make(chan ScheduleEvent, 16)}
+}
+
+// MaxDuration caps how long Run plays before stopping the engine. Zero (the
+// default) means no cap - only StopAt, or a manual Stop, ends the schedule.
+func (s *Schedule) MaxDuration(d time.Duration) *Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxDuration = d
+	return s
+}
+
+// FadeIn ramps every player's volume up from 0 over d once Run starts it,
+// via AudioPlayer.FadeTo. Zero (the default) starts players at full volume.
+func (s *Schedule) FadeIn(d time.Duration) *Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fadeIn = d
+	return s
+}
+
+// ChimeEvery plays chimeFile through its own player on the main mixer every
+// interval, starting interval after Run begins. A zero interval (the
+// default) disables the chime.
+func (s *Schedule) ChimeEvery(interval time.Duration, chimeFile string) *Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chimeEvery = interval
+	s.chimeFile = chimeFile
+	return s
+}
+
+// StopAt sets a wall-clock deadline to stop the engine at, independent of
+// MaxDuration - whichever is reached first ends the schedule.
+func (s *Schedule) StopAt(deadline time.Time) *Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadline = deadline
+	s.hasDeadline = true
+	return s
+}
+
+// Players sets the players Run starts playback on - each must already have
+// a file loaded and be connected to the graph (LoadFile, ConnectToMainMixer
+// or similar).
+func (s *Schedule) Players(players ...*AudioPlayer) *Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.players = append(s.players, players...)
+	return s
+}
+
+// Events returns the channel Schedule delivers ScheduleStarted,
+// ScheduleChimeFired, and ScheduleStopped on. The channel is buffered; a
+// slow consumer can delay Run's background goroutine briefly.
+func (s *Schedule) Events() <-chan ScheduleEvent {
+	return s.events
+}
+
+// Run starts every player set via Players (fading each in over FadeIn, if
+// set), publishes ScheduleStarted, then watches MaxDuration/StopAt and
+// fires ChimeEvery on a background goroutine until one of them ends the
+// schedule or Stop is called, at which point it publishes ScheduleStopped
+// and stops the engine. Run itself returns as soon as playback starts.
+func (s *Schedule) Run() error {
+	s.mu.Lock()
+	players := append([]*AudioPlayer(nil), s.players...)
+	fadeIn := s.fadeIn
+	s.stopCh = make(chan struct{})
+	s.mu.Unlock()
+
+	for _, p := range players {
+		if fadeIn > 0 {
+			if err := p.SetVolume(0); err != nil {
+				return err
+			}
+		}
+		if err := p.Play(); err != nil {
+			return err
+		}
+		if fadeIn > 0 {
+			p.FadeTo(1.0, fadeIn, FadeLinear)
+		}
+	}
+
+	s.publish(ScheduleStarted{})
+
+	s.runWG.Add(1)
+	go s.run()
+	return nil
+}
+
+// scheduleDeadlineReached reports whether now has crossed maxDeadline
+// (zero means no MaxDuration was set) or, if hasDeadline, the StopAt
+// deadline - whichever comes first ends the schedule - and which
+// ScheduleStopReason to report for it.
+func scheduleDeadlineReached(now, maxDeadline time.Time, hasDeadline bool, deadline time.Time) (ScheduleStopReason, bool) {
+	if !maxDeadline.IsZero() && !now.Before(maxDeadline) {
+		return ScheduleStoppedMaxDuration, true
+	}
+	if hasDeadline && !now.Before(deadline) {
+		return ScheduleStoppedDeadline, true
+	}
+	return ScheduleStoppedManually, false
+}
+
+// run is Schedule's background goroutine, started by Run: it fires the
+// chime on its own interval and checks MaxDuration/StopAt on
+// scheduleTickInterval, until one of them (or Stop) ends the schedule.
+func (s *Schedule) run() {
+	defer s.runWG.Done()
+
+	s.mu.Lock()
+	maxDuration := s.maxDuration
+	deadline := s.deadline
+	hasDeadline := s.hasDeadline
+	chimeEvery := s.chimeEvery
+	chimeFile := s.chimeFile
+	stopCh := s.stopCh
+	s.mu.Unlock()
+
+	var maxDeadline time.Time
+	if maxDuration > 0 {
+		maxDeadline = time.Now().Add(maxDuration)
+	}
+
+	var chimeC <-chan time.Time
+	if chimeEvery > 0 {
+		chimeTicker := time.NewTicker(chimeEvery)
+		defer chimeTicker.Stop()
+		chimeC = chimeTicker.C
+	}
+
+	ticker := time.NewTicker(scheduleTickInterval)
+	defer ticker.Stop()
+
+	reason := ScheduleStoppedManually
+loop:
+	for {
+		select {
+		case <-stopCh:
+			break loop
+		case <-chimeC:
+			s.fireChime(chimeFile)
+		case now := <-ticker.C:
+			if r, done := scheduleDeadlineReached(now, maxDeadline, hasDeadline, deadline); done {
+				reason = r
+				break loop
+			}
+		}
+	}
+
+	s.publish(ScheduleStopped{Reason: reason})
+	if s.engine != nil {
+		_ = s.engine.Stop()
+	}
+}
+
+// fireChime plays chimeFile once through a dedicated, self-destroying
+// player on the main mixer, publishing ScheduleChimeFired if it starts
+// successfully.
+func (s *Schedule) fireChime(chimeFile string) {
+	if chimeFile == "" || s.engine == nil {
+		return
+	}
+	chime, err := s.engine.NewPlayer()
+	if err != nil {
+		return
+	}
+	if err := chime.LoadFile(chimeFile); err != nil {
+		chime.Destroy()
+		return
+	}
+	if err := chime.ConnectToMainMixer(); err != nil {
+		chime.Destroy()
+		return
+	}
+	if err := chime.Play(); err != nil {
+		chime.Destroy()
+		return
+	}
+
+	s.publish(ScheduleChimeFired{At: time.Now()})
+	go func() {
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for range ticker.C {
+			playing, err := chime.IsPlaying()
+			if err != nil || !playing {
+				chime.Destroy()
+				return
+			}
+		}
+	}()
+}
+
+// publish delivers ev to Events, dropping it rather than blocking if the
+// channel's buffer is full.
+func (s *Schedule) publish(ev ScheduleEvent) {
+	select {
+	case s.events <- ev:
+	default:
+	}
+}
+
+// Stop ends Run's background watch immediately, as if MaxDuration/StopAt
+// had just been reached: ScheduleStopped (with ScheduleStoppedManually) is
+// published and the engine is stopped, the same as a natural deadline.
+// Stop blocks until that has happened. Calling it before Run, or more than
+// once, is a no-op.
+func (s *Schedule) Stop() {
+	s.mu.Lock()
+	stopCh := s.stopCh
+	s.mu.Unlock()
+	if stopCh == nil {
+		return
+	}
+
+	select {
+	case <-stopCh:
+	default:
+		close(stopCh)
+	}
+	s.runWG.Wait()
+}
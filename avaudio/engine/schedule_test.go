@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleDeadlineReachedPrefersMaxDuration(t *testing.T) {
+	now := time.Now()
+	maxDeadline := now.Add(-time.Second) // already passed
+	deadline := now.Add(-time.Minute)    // also already passed
+
+	reason, done := scheduleDeadlineReached(now, maxDeadline, true, deadline)
+	if !done {
+		t.Fatal("expected scheduleDeadlineReached to report done when maxDeadline has passed")
+	}
+	if reason != ScheduleStoppedMaxDuration {
+		t.Errorf("reason = %v, want ScheduleStoppedMaxDuration (checked first)", reason)
+	}
+}
+
+func TestScheduleDeadlineReachedFallsBackToStopAt(t *testing.T) {
+	now := time.Now()
+	var noMaxDeadline time.Time
+	deadline := now.Add(-time.Second)
+
+	reason, done := scheduleDeadlineReached(now, noMaxDeadline, true, deadline)
+	if !done {
+		t.Fatal("expected scheduleDeadlineReached to report done when the StopAt deadline has passed")
+	}
+	if reason != ScheduleStoppedDeadline {
+		t.Errorf("reason = %v, want ScheduleStoppedDeadline", reason)
+	}
+}
+
+func TestScheduleDeadlineReachedFalseBeforeEither(t *testing.T) {
+	now := time.Now()
+	maxDeadline := now.Add(time.Hour)
+	deadline := now.Add(time.Hour)
+
+	if _, done := scheduleDeadlineReached(now, maxDeadline, true, deadline); done {
+		t.Error("expected scheduleDeadlineReached to report not done before either deadline passes")
+	}
+	if _, done := scheduleDeadlineReached(now, time.Time{}, false, time.Time{}); done {
+		t.Error("expected scheduleDeadlineReached to report not done with neither deadline set")
+	}
+}
+
+func TestScheduleBuilderChaining(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	deadline := time.Now().Add(time.Hour)
+	s := eng.NewSchedule().
+		MaxDuration(30*time.Minute).
+		FadeIn(2*time.Minute).
+		ChimeEvery(10*time.Minute, "gong.wav").
+		StopAt(deadline)
+
+	if s.maxDuration != 30*time.Minute {
+		t.Errorf("maxDuration = %v, want 30m", s.maxDuration)
+	}
+	if s.fadeIn != 2*time.Minute {
+		t.Errorf("fadeIn = %v, want 2m", s.fadeIn)
+	}
+	if s.chimeEvery != 10*time.Minute || s.chimeFile != "gong.wav" {
+		t.Errorf("chimeEvery/chimeFile = %v/%q, want 10m/\"gong.wav\"", s.chimeEvery, s.chimeFile)
+	}
+	if !s.hasDeadline || !s.deadline.Equal(deadline) {
+		t.Errorf("deadline = %v (hasDeadline=%v), want %v", s.deadline, s.hasDeadline, deadline)
+	}
+}
+
+func TestScheduleStopPublishesStoppedEventAndStopsEngine(t *testing.T) {
+	eng, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	s := eng.NewSchedule()
+	if err := s.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var started, stopped bool
+	select {
+	case ev := <-s.Events():
+		if _, ok := ev.(ScheduleStarted); ok {
+			started = true
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for ScheduleStarted")
+	}
+	if !started {
+		t.Fatal("expected the first event to be ScheduleStarted")
+	}
+
+	s.Stop()
+
+	select {
+	case ev := <-s.Events():
+		stop, ok := ev.(ScheduleStopped)
+		if !ok {
+			t.Fatalf("expected ScheduleStopped, got %T", ev)
+		}
+		if stop.Reason != ScheduleStoppedManually {
+			t.Errorf("Reason = %v, want ScheduleStoppedManually", stop.Reason)
+		}
+		stopped = true
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for ScheduleStopped")
+	}
+	if !stopped {
+		t.Fatal("expected a ScheduleStopped event after Stop")
+	}
+}
@@ -0,0 +1,518 @@
+package engine
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/cmplx"
+	"time"
+)
+
+// Window shapes the analysis window applied to a sample block before its FFT,
+// trading main-lobe width for side-lobe suppression.
+type Window int
+
+const (
+	WindowHann Window = iota
+	WindowHamming
+	WindowBlackmanHarris
+)
+
+func (w Window) apply(samples []float64) {
+	n := len(samples)
+	if n < 2 {
+		return
+	}
+	for i := range samples {
+		t := float64(i) / float64(n-1)
+		var coeff float64
+		switch w {
+		case WindowHamming:
+			coeff = 0.54 - 0.46*math.Cos(2*math.Pi*t)
+		case WindowBlackmanHarris:
+			coeff = 0.35875 - 0.48829*math.Cos(2*math.Pi*t) + 0.14128*math.Cos(4*math.Pi*t) - 0.01168*math.Cos(6*math.Pi*t)
+		default: // WindowHann
+			coeff = 0.5 - 0.5*math.Cos(2*math.Pi*t)
+		}
+		samples[i] *= coeff
+	}
+}
+
+// SpectrumConfig configures the optional FFT magnitude spectrum AnalyzeBuffer
+// and AnalyzeFileSegment attach to AudioSegmentMetrics.Spectrum. The zero
+// value disables spectrum analysis, since FFTSize defaults to 0.
+type SpectrumConfig struct {
+	Window  Window
+	FFTSize int // samples per FFT, taken from the end of the analyzed segment; rounded up to a power of two if not already one
+	Bins    int // output bins the magnitude spectrum is downsampled to; 0 defaults to FFTSize/2
+}
+
+// analysisMetrics holds the fields AnalyzeBuffer and AnalyzeFileSegment both
+// compute in Go, as opposed to RMS/FrameCount/StartTime/Duration, which
+// AnalyzeFileSegment gets from audioplayer_analyze_file_segment.
+type analysisMetrics struct {
+	peakDB         float64
+	truePeakDB     float64
+	integratedLUFS float64
+	shortTermLUFS  float64
+	spectrum       []float32
+}
+
+// AnalyzeBuffer computes peak, true-peak, integrated/short-term LUFS, and an
+// FFT magnitude spectrum for a mono buffer of samples already in hand (no
+// AudioPlayer or native call involved), e.g. audio captured from a Tap.
+func AnalyzeBuffer(samples []float32, sampleRate float64) (*AudioSegmentMetrics, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("no samples to analyze")
+	}
+	if sampleRate <= 0 {
+		return nil, errors.New("sample rate must be positive")
+	}
+
+	mono := make([]float64, len(samples))
+	var sumSq float64
+	for i, s := range samples {
+		mono[i] = float64(s)
+		sumSq += mono[i] * mono[i]
+	}
+
+	m := analyzeMono(mono, sampleRate, defaultSpectrumConfig(len(mono)))
+	return &AudioSegmentMetrics{
+		RMS:            math.Sqrt(sumSq / float64(len(samples))),
+		FrameCount:     len(samples),
+		Duration:       float64(len(samples)) / sampleRate,
+		Timestamp:      time.Now(),
+		PeakDB:         m.peakDB,
+		TruePeakDB:     m.truePeakDB,
+		IntegratedLUFS: m.integratedLUFS,
+		ShortTermLUFS:  m.shortTermLUFS,
+		Spectrum:       m.spectrum,
+	}, nil
+}
+
+// analyzeFileSegmentExtras re-reads [startTime, startTime+duration) straight
+// from p's underlying file via AudioFile - a separate decode path from the
+// AVAudioPlayer p wraps - since audioplayer_analyze_file_segment only
+// returns RMS and frame count, not raw samples. Multi-channel files are
+// downmixed to mono by averaging channels.
+func (p *AudioPlayer) analyzeFileSegmentExtras(startTime, duration float64) (analysisMetrics, error) {
+	f, err := OpenAudioFile(p.filePath)
+	if err != nil {
+		return analysisMetrics{}, err
+	}
+	defer f.Close()
+
+	channels := f.ChannelCount()
+	if channels == 0 {
+		return analysisMetrics{}, errors.New("file reports no channels")
+	}
+	sampleRate := f.SampleRate()
+
+	if err := f.Seek(int64(startTime * sampleRate)); err != nil {
+		return analysisMetrics{}, err
+	}
+
+	frameCount := int(duration * sampleRate)
+	if frameCount <= 0 {
+		return analysisMetrics{}, errors.New("duration too short to analyze")
+	}
+
+	buf := make([]float32, frameCount*channels)
+	n, readErr := f.Read(buf)
+	if readErr != nil && readErr != io.EOF {
+		return analysisMetrics{}, readErr
+	}
+	if n == 0 {
+		return analysisMetrics{}, errors.New("no frames read for segment")
+	}
+
+	mono := make([]float64, n)
+	for i := 0; i < n; i++ {
+		var sum float64
+		for ch := 0; ch < channels; ch++ {
+			sum += float64(buf[i*channels+ch])
+		}
+		mono[i] = sum / float64(channels)
+	}
+
+	return analyzeMono(mono, sampleRate, p.spectrumConfig), nil
+}
+
+// analyzeMono computes peak, true-peak, gated integrated LUFS, ungated
+// short-term LUFS, and (if cfg enables it) a magnitude spectrum for a mono
+// sample sequence at sampleRate.
+func analyzeMono(samples []float64, sampleRate float64, cfg SpectrumConfig) analysisMetrics {
+	if len(samples) == 0 {
+		return analysisMetrics{integratedLUFS: math.Inf(-1), shortTermLUFS: math.Inf(-1)}
+	}
+
+	shelf, highpass := kWeightFilters(sampleRate)
+
+	const partialMs = 100 // matches BS.1770's 100ms partial block, gated in 400ms/75%-overlap windows by gatedLoudness
+	partialFrames := int(sampleRate * partialMs / 1000.0)
+	if partialFrames <= 0 {
+		partialFrames = 1
+	}
+
+	var peak float64
+	var partials []float64
+	var blockSumSq float64
+	blockSamples := 0
+
+	flush := func() {
+		if blockSamples == 0 {
+			return
+		}
+		partials = append(partials, blockSumSq/float64(blockSamples))
+		blockSumSq = 0
+		blockSamples = 0
+	}
+
+	for _, s := range samples {
+		if a := math.Abs(s); a > peak {
+			peak = a
+		}
+		filtered := highpass.process(shelf.process(s))
+		blockSumSq += filtered * filtered
+		blockSamples++
+		if blockSamples >= partialFrames {
+			flush()
+		}
+	}
+	flush()
+
+	const shortTermSec = 3.0
+	shortTermFrames := int(sampleRate * shortTermSec)
+	tail := samples
+	if shortTermFrames > 0 && shortTermFrames < len(samples) {
+		tail = samples[len(samples)-shortTermFrames:]
+	}
+
+	m := analysisMetrics{
+		peakDB:         linearToDB(peak),
+		truePeakDB:     truePeakDB(samples),
+		integratedLUFS: gatedLoudness(partials),
+		shortTermLUFS:  ungatedLoudness(tail, sampleRate),
+	}
+	if cfg.FFTSize > 0 {
+		m.spectrum = computeSpectrum(samples, cfg)
+	}
+	return m
+}
+
+// ungatedLoudness K-weights samples with a fresh filter pair (so filter state
+// starts silent) and returns straight mean-square loudness with no BS.1770
+// gating - used for ShortTermLUFS, which is defined as an ungated window
+// rather than the gated measurement IntegratedLUFS uses.
+func ungatedLoudness(samples []float64, sampleRate float64) float64 {
+	if len(samples) == 0 {
+		return math.Inf(-1)
+	}
+	shelf, highpass := kWeightFilters(sampleRate)
+	var sumSq float64
+	for _, s := range samples {
+		filtered := highpass.process(shelf.process(s))
+		sumSq += filtered * filtered
+	}
+	return loudnessFromMeanSquare(sumSq / float64(len(samples)))
+}
+
+// defaultSpectrumConfig picks a Hann-windowed FFT size for AnalyzeBuffer,
+// which has no way to take spectrum options as extra arguments: the largest
+// power of two up to 4096 that fits within sampleCount.
+func defaultSpectrumConfig(sampleCount int) SpectrumConfig {
+	const maxFFTSize = 4096
+	fftSize := nextPowerOfTwo(sampleCount)
+	if fftSize > maxFFTSize {
+		fftSize = maxFFTSize
+	}
+	if fftSize < 2 {
+		return SpectrumConfig{}
+	}
+	return SpectrumConfig{Window: WindowHann, FFTSize: fftSize, Bins: fftSize / 2}
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// truePeakDB estimates the true (inter-sample) peak in dBFS by interpolating
+// 4x between samples with a windowed-sinc polyphase FIR and taking the peak
+// of the oversampled signal - a standard approximation of ITU-R BS.1770's
+// true-peak meter without a full polyphase filter bank library.
+func truePeakDB(samples []float64) float64 {
+	if len(samples) == 0 {
+		return math.Inf(-1)
+	}
+
+	const oversample = 4
+	const halfTaps = 8 // sinc kernel taps on each side of the interpolated point
+
+	kernels := make([][]float64, oversample)
+	for phase := 0; phase < oversample; phase++ {
+		frac := float64(phase) / float64(oversample)
+		taps := make([]float64, 2*halfTaps)
+		for i := -halfTaps; i < halfTaps; i++ {
+			x := float64(i) + frac
+			sinc := 1.0
+			if x != 0 {
+				sinc = math.Sin(math.Pi*x) / (math.Pi * x)
+			}
+			// Hann-taper the kernel span to suppress the sinc's ringing.
+			hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i+halfTaps)/float64(2*halfTaps-1))
+			taps[i+halfTaps] = sinc * hann
+		}
+		kernels[phase] = taps
+	}
+
+	var peak float64
+	for n := range samples {
+		for _, taps := range kernels {
+			var acc float64
+			for i := -halfTaps; i < halfTaps; i++ {
+				idx := n + i
+				if idx < 0 || idx >= len(samples) {
+					continue
+				}
+				acc += samples[idx] * taps[i+halfTaps]
+			}
+			if a := math.Abs(acc); a > peak {
+				peak = a
+			}
+		}
+	}
+	return linearToDB(peak)
+}
+
+func linearToDB(linear float64) float64 {
+	if linear <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(linear)
+}
+
+// computeSpectrum windows the last cfg.FFTSize samples (zero-padded at the
+// start if the segment is shorter), runs an FFT, and downsamples the
+// magnitude spectrum to cfg.Bins output bins.
+func computeSpectrum(samples []float64, cfg SpectrumConfig) []float32 {
+	n := cfg.FFTSize
+	windowed := make([]float64, n)
+	start := len(samples) - n
+	if start < 0 {
+		start = 0
+	}
+	copy(windowed, samples[start:])
+	cfg.Window.apply(windowed)
+
+	complexIn := make([]complex128, n)
+	for i, v := range windowed {
+		complexIn[i] = complex(v, 0)
+	}
+	fftInPlace(complexIn)
+
+	half := n / 2
+	magnitudes := make([]float64, half)
+	for i := 0; i < half; i++ {
+		magnitudes[i] = cmplx.Abs(complexIn[i]) / float64(n)
+	}
+
+	bins := cfg.Bins
+	if bins <= 0 {
+		bins = half
+	}
+	return mapBins(magnitudes, bins)
+}
+
+// mapBins downsamples (or spreads) len(magnitudes) FFT bins evenly into
+// exactly bins output values.
+func mapBins(magnitudes []float64, bins int) []float32 {
+	out := make([]float32, bins)
+	n := len(magnitudes)
+	if n == 0 || bins == 0 {
+		return out
+	}
+	for i := 0; i < bins; i++ {
+		lo := i * n / bins
+		hi := (i + 1) * n / bins
+		if hi <= lo {
+			hi = lo + 1
+		}
+		if hi > n {
+			hi = n
+		}
+		var sum float64
+		count := 0
+		for j := lo; j < hi; j++ {
+			sum += magnitudes[j]
+			count++
+		}
+		if count > 0 {
+			out[i] = float32(sum / float64(count))
+		}
+	}
+	return out
+}
+
+// fftInPlace computes an iterative radix-2 Cooley-Tukey FFT of data, whose
+// length must be a power of two (defaultSpectrumConfig/cfg.FFTSize enforce
+// this).
+func fftInPlace(data []complex128) {
+	n := len(data)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j &^= bit
+		}
+		j |= bit
+		if i < j {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		angleStep := -2 * math.Pi / float64(size)
+		for start := 0; start < n; start += size {
+			for k := 0; k < half; k++ {
+				w := cmplx.Exp(complex(0, angleStep*float64(k)))
+				u := data[start+k]
+				v := data[start+k+half] * w
+				data[start+k] = u + v
+				data[start+k+half] = u - v
+			}
+		}
+	}
+}
+
+// biquad is a Direct Form II Transposed IIR section, used for both stages of
+// the K-weighting filter. Duplicated from replaygain.biquad rather than
+// imported: replaygain already imports this package, so the reverse import
+// would cycle.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (bq *biquad) process(x float64) float64 {
+	y := bq.b0*x + bq.z1
+	bq.z1 = bq.b1*x - bq.a1*y + bq.z2
+	bq.z2 = bq.b2*x - bq.a2*y
+	return y
+}
+
+// kWeightFilters returns the two-stage K-weighting filter (high-shelf stage
+// then RLB high-pass stage) for the given sample rate, using the standard
+// BS.1770 coefficient derivation from libebur128 - the same formulas
+// replaygain.kWeightFilters uses (see the biquad doc comment for why this
+// isn't just imported from there).
+func kWeightFilters(sampleRate float64) (shelf, highpass biquad) {
+	// Stage 1: high-shelf boost above ~1.7kHz.
+	f0 := 1681.9744509555319
+	g := 3.99984385397
+	q := 0.7071752369554193
+
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10.0, g/20.0)
+	vb := math.Pow(vh, 0.4996667741545416)
+
+	a0 := 1.0 + k/q + k*k
+	shelf = biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2.0 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2.0 * (k*k - 1.0) / a0,
+		a2: (1.0 - k/q + k*k) / a0,
+	}
+
+	// Stage 2: RLB weighting, a high-pass around 38Hz.
+	f0 = 38.13547087613982
+	q = 0.5003270373238773
+
+	k = math.Tan(math.Pi * f0 / sampleRate)
+	a0 = 1.0 + k/q + k*k
+	highpass = biquad{
+		b0: 1.0,
+		b1: -2.0,
+		b2: 1.0,
+		a1: 2.0 * (k*k - 1.0) / a0,
+		a2: (1.0 - k/q + k*k) / a0,
+	}
+	return shelf, highpass
+}
+
+const (
+	gateAbsLUFS = -70.0
+	gateRelLU   = -10.0
+)
+
+// gatedLoudness applies BS.1770's two-stage gating (absolute, then relative)
+// to 100ms partial blocks and returns the integrated loudness in LUFS.
+// Consecutive groups of four partials are combined into 400ms gating blocks
+// with 75% overlap, as the spec requires. Duplicated from
+// replaygain.gatedLoudness for the same reason as kWeightFilters.
+func gatedLoudness(partials []float64) float64 {
+	const blocksPerWindow = 4
+	if len(partials) < blocksPerWindow {
+		if len(partials) == 0 {
+			return math.Inf(-1)
+		}
+		var sum float64
+		for _, p := range partials {
+			sum += p
+		}
+		return loudnessFromMeanSquare(sum / float64(len(partials)))
+	}
+
+	windows := make([]float64, 0, len(partials)-blocksPerWindow+1)
+	for i := 0; i+blocksPerWindow <= len(partials); i++ {
+		var sum float64
+		for j := 0; j < blocksPerWindow; j++ {
+			sum += partials[i+j]
+		}
+		windows = append(windows, sum/float64(blocksPerWindow))
+	}
+
+	var absKept []float64
+	for _, w := range windows {
+		if loudnessFromMeanSquare(w) >= gateAbsLUFS {
+			absKept = append(absKept, w)
+		}
+	}
+	if len(absKept) == 0 {
+		return math.Inf(-1)
+	}
+
+	var sum float64
+	for _, w := range absKept {
+		sum += w
+	}
+	ungated := loudnessFromMeanSquare(sum / float64(len(absKept)))
+
+	relThreshold := ungated + gateRelLU
+	var relKept []float64
+	for _, w := range absKept {
+		if loudnessFromMeanSquare(w) >= relThreshold {
+			relKept = append(relKept, w)
+		}
+	}
+	if len(relKept) == 0 {
+		return ungated
+	}
+
+	sum = 0
+	for _, w := range relKept {
+		sum += w
+	}
+	return loudnessFromMeanSquare(sum / float64(len(relKept)))
+}
+
+func loudnessFromMeanSquare(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeBufferRejectsEmptyOrBadSampleRate(t *testing.T) {
+	if _, err := AnalyzeBuffer(nil, 44100); err == nil {
+		t.Error("expected an error for an empty buffer")
+	}
+	if _, err := AnalyzeBuffer([]float32{0.1, 0.2}, 0); err == nil {
+		t.Error("expected an error for a non-positive sample rate")
+	}
+}
+
+func TestAnalyzeBufferFullScaleSquareWave(t *testing.T) {
+	const sampleRate = 44100
+	samples := make([]float32, sampleRate) // 1s
+	for i := range samples {
+		if i%2 == 0 {
+			samples[i] = 1
+		} else {
+			samples[i] = -1
+		}
+	}
+
+	metrics, err := AnalyzeBuffer(samples, sampleRate)
+	if err != nil {
+		t.Fatalf("AnalyzeBuffer: %v", err)
+	}
+
+	if metrics.RMS < 0.99 || metrics.RMS > 1.01 {
+		t.Errorf("expected RMS ~1.0 for a full-scale square wave, got %v", metrics.RMS)
+	}
+	if metrics.PeakDB < -0.1 || metrics.PeakDB > 0.1 {
+		t.Errorf("expected PeakDB ~0dBFS, got %v", metrics.PeakDB)
+	}
+	if metrics.TruePeakDB < metrics.PeakDB {
+		t.Errorf("expected TruePeakDB (%v) >= PeakDB (%v)", metrics.TruePeakDB, metrics.PeakDB)
+	}
+	if len(metrics.Spectrum) == 0 {
+		t.Error("expected AnalyzeBuffer to compute a default spectrum")
+	}
+}
+
+func TestAnalyzeBufferSilenceIsNegativeInfinityLUFS(t *testing.T) {
+	samples := make([]float32, 44100)
+	metrics, err := AnalyzeBuffer(samples, 44100)
+	if err != nil {
+		t.Fatalf("AnalyzeBuffer: %v", err)
+	}
+	if !math.IsInf(metrics.IntegratedLUFS, -1) {
+		t.Errorf("expected IntegratedLUFS to be -Inf for silence, got %v", metrics.IntegratedLUFS)
+	}
+}
+
+func TestDefaultSpectrumConfigCapsFFTSize(t *testing.T) {
+	cfg := defaultSpectrumConfig(1_000_000)
+	if cfg.FFTSize != 4096 {
+		t.Errorf("expected FFTSize to cap at 4096, got %d", cfg.FFTSize)
+	}
+	if defaultSpectrumConfig(0).FFTSize != 0 {
+		t.Error("expected an empty SpectrumConfig for a sample count too small to FFT")
+	}
+}
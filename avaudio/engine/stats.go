@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// runtimeStatsRingSize bounds how many recent render-callback timings Stats
+// keeps for jitter analysis - enough to span several seconds of callbacks at
+// a typical 128-2048 frame buffer size without the ring growing unbounded.
+const runtimeStatsRingSize = 256
+
+// runtimeStats is Engine's render-thread telemetry accumulator, fed by
+// recordCallback (render timings) and recordXrun (underrun count) in
+// xrun.go; see Engine.Stats for the snapshot callers actually read.
+type runtimeStats struct {
+	mu               sync.Mutex
+	underruns        uint64
+	longestCallback  time.Duration
+	totalCallbackDur time.Duration
+	callbackCount    uint64
+	ring             [runtimeStatsRingSize]time.Duration
+	ringLen          int
+	ringPos          int
+}
+
+// RuntimeStats is a point-in-time snapshot of an Engine's render-thread
+// health, returned by Engine.Stats.
+type RuntimeStats struct {
+	// Underruns is the cumulative count of buffer under/overruns reported
+	// through OnXrun.
+	Underruns uint64
+	// LongestCallback is the longest render callback duration observed.
+	LongestCallback time.Duration
+	// AverageCallback is the mean render callback duration observed.
+	AverageCallback time.Duration
+	// AverageCPULoad is AverageCallback divided by the wall-clock budget
+	// one buffer has to render in at the engine's current sample rate and
+	// buffer size (BufferSize/SampleRate) - 1.0 means the render thread is
+	// using its entire budget, analogous to Ardour's DSP-load meter.
+	AverageCPULoad float64
+	// BufferSize is the engine's current buffer size in frames.
+	BufferSize int
+	// RecentCallbackTimings holds up to runtimeStatsRingSize of the most
+	// recent render callback durations, most-recent-first, for jitter
+	// analysis.
+	RecentCallbackTimings []time.Duration
+}
+
+// recordCallback adds one render callback's duration to e's rolling
+// telemetry - the call the render thread would make once the trampoline
+// OnXrun's doc comment describes exists; this package's own tests drive it
+// directly in the meantime.
+func (e *Engine) recordCallback(dur time.Duration) {
+	s := &e.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dur > s.longestCallback {
+		s.longestCallback = dur
+	}
+	s.totalCallbackDur += dur
+	s.callbackCount++
+
+	s.ring[s.ringPos] = dur
+	s.ringPos = (s.ringPos + 1) % runtimeStatsRingSize
+	if s.ringLen < runtimeStatsRingSize {
+		s.ringLen++
+	}
+}
+
+// Stats returns a snapshot of e's render-thread telemetry: cumulative
+// underrun count, the longest and average render-callback duration
+// observed, the current buffer size, and the most recent callback timings
+// for jitter analysis.
+//
+// Every field here depends on recordCallback/recordXrun actually being
+// driven by the render thread, which needs the native xrun/render-notify
+// trampoline OnXrun's doc comment describes; until that lands, Stats
+// reports all zeros unless this package's own tests call
+// recordCallback/recordXrun directly.
+func (e *Engine) Stats() RuntimeStats {
+	s := &e.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := RuntimeStats{
+		Underruns:       s.underruns,
+		LongestCallback: s.longestCallback,
+		BufferSize:      e.spec.BufferSize,
+	}
+	if s.callbackCount > 0 {
+		stats.AverageCallback = s.totalCallbackDur / time.Duration(s.callbackCount)
+	}
+	if e.spec.SampleRate > 0 && e.spec.BufferSize > 0 {
+		budget := time.Duration(float64(e.spec.BufferSize) / e.spec.SampleRate * float64(time.Second))
+		stats.AverageCPULoad = float64(stats.AverageCallback) / float64(budget)
+	}
+
+	stats.RecentCallbackTimings = make([]time.Duration, s.ringLen)
+	for i := 0; i < s.ringLen; i++ {
+		idx := (s.ringPos - 1 - i + runtimeStatsRingSize) % runtimeStatsRingSize
+		stats.RecentCallbackTimings[i] = s.ring[idx]
+	}
+	return stats
+}
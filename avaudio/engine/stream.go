@@ -0,0 +1,515 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+#include <stdlib.h>
+
+// Declared here; implemented in native/stream.m once the AVAudioNodeTapBlock
+// (input) / AVAudioSourceNode render block (output) it installs, and the
+// //export trampolines they call back into Go through, exist - see
+// Engine.BuildInputStream/BuildOutputStream's doc comments. This is the
+// same gap avaudio/tap's tap_install_callback and render_callback.go's
+// renderunit_create document, for the same reason: the native side of this
+// tree doesn't exist here yet.
+const char* stream_build_input(void* enginePtr, void* nodePtr, int busIndex, int frameCapacity, const char* streamKey);
+const char* stream_build_output(void* enginePtr, void* nodePtr, int busIndex, int frameCapacity, const char* streamKey);
+const char* stream_start(const char* streamKey);
+const char* stream_stop(const char* streamKey);
+const char* stream_close(const char* streamKey);
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// StreamSampleFormat selects the sample layout InputData/OutputData expose
+// a typed view over - set by the format passed to BuildInputStream/
+// BuildOutputStream, not chosen per-block.
+type StreamSampleFormat int
+
+const (
+	StreamSampleFloat32 StreamSampleFormat = iota
+	StreamSampleInt16
+	StreamSampleInt32
+)
+
+// streamBlock is one block of audio moved between the native render thread
+// and a NodeStream's drain/fill goroutine through streamRing. Only the
+// slice matching format is populated; the others are nil.
+type streamBlock struct {
+	format     StreamSampleFormat
+	float32s   []float32
+	int16s     []int16
+	int32s     []int32
+	frameCount int
+	hostTime   uint64
+	sampleTime int64
+}
+
+// InputData is one block of captured audio handed to an input stream's
+// callback - see Engine.BuildInputStream. Float32/Int16/Int32 return the
+// slice matching the stream's format and nil for the other two.
+type InputData struct {
+	block streamBlock
+}
+
+// Float32 returns the block's samples as float32, or nil if the stream's
+// format isn't StreamSampleFloat32.
+func (d InputData) Float32() []float32 { return d.block.float32s }
+
+// Int16 returns the block's samples as int16, or nil if the stream's format
+// isn't StreamSampleInt16.
+func (d InputData) Int16() []int16 { return d.block.int16s }
+
+// Int32 returns the block's samples as int32, or nil if the stream's format
+// isn't StreamSampleInt32.
+func (d InputData) Int32() []int32 { return d.block.int32s }
+
+// FrameCount is the number of frames in this block (channels are
+// interleaved within each typed slice, matching the format a Format was
+// built with).
+func (d InputData) FrameCount() int { return d.block.frameCount }
+
+// NewInputData builds an InputData carrying float32s as a synthetic
+// StreamSampleFloat32 block - for a caller that transforms a captured block
+// (e.g. format.Resampler converting it to a different sample rate) and
+// needs to hand the result to an InputStream callback as if it had arrived
+// from the tap directly, rather than exposing streamBlock itself.
+func NewInputData(float32s []float32, frameCount int, hostTime uint64, sampleTime int64) InputData {
+	return InputData{block: streamBlock{
+		format:     StreamSampleFloat32,
+		float32s:   float32s,
+		frameCount: frameCount,
+		hostTime:   hostTime,
+		sampleTime: sampleTime,
+	}}
+}
+
+// HostTime is the AVAudioTime.hostTime this block was captured at, in mach
+// absolute time units.
+func (d InputData) HostTime() uint64 { return d.block.hostTime }
+
+// SampleTime is the tapped node's running sample-time counter at capture.
+func (d InputData) SampleTime() int64 { return d.block.sampleTime }
+
+// OutputData is one block an output stream's callback fills before it's
+// handed to the render thread - see Engine.BuildOutputStream. Float32/
+// Int16/Int32 return a slice matching the stream's format for the callback
+// to write into; the other two are nil.
+type OutputData struct {
+	block streamBlock
+}
+
+// Float32 returns the block's buffer as float32 for the callback to fill,
+// or nil if the stream's format isn't StreamSampleFloat32.
+func (d OutputData) Float32() []float32 { return d.block.float32s }
+
+// Int16 returns the block's buffer as int16 for the callback to fill, or
+// nil if the stream's format isn't StreamSampleInt16.
+func (d OutputData) Int16() []int16 { return d.block.int16s }
+
+// Int32 returns the block's buffer as int32 for the callback to fill, or
+// nil if the stream's format isn't StreamSampleInt32.
+func (d OutputData) Int32() []int32 { return d.block.int32s }
+
+// FrameCount is the number of frames the render thread is asking for.
+func (d OutputData) FrameCount() int { return d.block.frameCount }
+
+// HostTime is the AVAudioTime.hostTime the render thread wants this block
+// delivered at.
+func (d OutputData) HostTime() uint64 { return d.block.hostTime }
+
+// SampleTime is the source node's running sample-time counter for this
+// block.
+func (d OutputData) SampleTime() int64 { return d.block.sampleTime }
+
+// streamRing is a lock-free single-producer/single-consumer ring buffer of
+// streamBlock slots, the same shape as avaudio/tap's callbackRing: for an
+// input NodeStream the native render thread is the sole producer and the
+// drain goroutine the sole consumer; for an output NodeStream the fill
+// goroutine is the sole producer and the native render thread the sole
+// consumer. Either way there's exactly one producer and one consumer, so
+// atomics on head/tail are enough - no mutex.
+type streamRing struct {
+	slots []streamBlock
+	head  uint64 // next slot the consumer will read
+	tail  uint64 // next slot the producer will write
+}
+
+// streamRingSlots is how many blocks a NodeStream's ring holds - enough
+// headroom to absorb a brief stall (a GC pause, a slow callback) in
+// whichever side is the consumer without growing unbounded.
+const streamRingSlots = 8
+
+func newStreamRing() *streamRing {
+	return &streamRing{slots: make([]streamBlock, streamRingSlots)}
+}
+
+func (r *streamRing) push(b streamBlock) bool {
+	tail := atomic.LoadUint64(&r.tail)
+	head := atomic.LoadUint64(&r.head)
+	if tail-head >= uint64(len(r.slots)) {
+		return false
+	}
+	r.slots[tail%uint64(len(r.slots))] = b
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+func (r *streamRing) pop() (streamBlock, bool) {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if head == tail {
+		return streamBlock{}, false
+	}
+	b := r.slots[head%uint64(len(r.slots))]
+	atomic.StoreUint64(&r.head, head+1)
+	return b, true
+}
+
+// streamDirection distinguishes an input NodeStream (captures from a node)
+// from an output NodeStream (generates for a node).
+type streamDirection int
+
+const (
+	streamDirectionInput streamDirection = iota
+	streamDirectionOutput
+)
+
+// Global stream registry, mirroring avaudio/tap's callbackTapRegistry: the
+// (not yet wired) native trampolines look a NodeStream up by key to push
+// captured blocks into its ring or pop filled ones out of it.
+var (
+	streamRegistry = make(map[string]*NodeStream)
+	streamMutex    sync.RWMutex
+)
+
+// StreamStats is a NodeStream's running delivery counters, for a health
+// check or UI meter - the NodeStream counterpart to CallbackTap's TapStats.
+type StreamStats struct {
+	BlocksDelivered uint64
+	BlocksDropped   uint64
+}
+
+// NodeStream is a cpal-style callback-driven audio stream built by
+// Engine.BuildInputStream or Engine.BuildOutputStream: a Go callback
+// receives (input) or fills (output) one block at a time, decoupled from
+// the audio-rendering thread by streamRing, instead of wiring nodes
+// together with Engine.Connect/ConnectWithFormat and reading/writing them
+// some other way. Unlike avaudio/tap's CallbackTap (observe-only) a
+// NodeStream can also generate audio, and unlike avaudio/engine's
+// RenderCallback (runs directly on the audio thread, so it can't allocate
+// or block) a NodeStream's callback runs on its own dedicated goroutine.
+// It's named NodeStream rather than Stream to avoid colliding with this
+// package's existing HostAPI-level Stream interface (hostapi.go), which is
+// a different seam - picking an audio backend - rather than attaching a
+// callback to one node/bus in an already-running engine's graph.
+type NodeStream struct {
+	key       string
+	enginePtr unsafe.Pointer
+	nodePtr   unsafe.Pointer
+	busIndex  int
+	direction streamDirection
+	format    StreamSampleFormat
+
+	ring     *streamRing
+	inputCB  func(InputData)
+	outputCB func(OutputData)
+
+	delivered uint64
+	dropped   uint64
+
+	runMu   sync.Mutex
+	running bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+// BuildInputStream attaches an AVAudioNodeTapBlock to nodePtr/busIndex and
+// returns a NodeStream that, once started with Start, delivers each captured
+// block to cb on a dedicated goroutine pinned with runtime.LockOSThread -
+// the main user-facing abstraction this package was missing for writing an
+// effect or recorder in Go, as opposed to avaudio/tap's CallbackTap, which
+// only reports metrics/raw buffers for observation.
+//
+// The tap block's native implementation, and the //export trampoline it
+// would call into Go through to push a captured buffer into the stream's
+// ring, aren't wired up in this tree yet (see stream_build_input's
+// declaration above) - like CreateRenderUnit and InstallCallbackTap, this
+// installs and tears down cleanly, but cb is never actually invoked until
+// that trampoline exists.
+func (e *Engine) BuildInputStream(nodePtr unsafe.Pointer, busIndex int, format *Format, cb func(InputData)) (*NodeStream, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	if nodePtr == nil {
+		return nil, errors.New("node pointer cannot be nil")
+	}
+	if busIndex < 0 {
+		return nil, errors.New("bus index must be non-negative")
+	}
+	if format == nil {
+		return nil, errors.New("format cannot be nil")
+	}
+	if cb == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	key := fmt.Sprintf("stream_in_%p_bus%d_%d", nodePtr, busIndex, time.Now().UnixNano())
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	const frameCapacity = 4096
+	errorStr := C.stream_build_input(e.ptr, nodePtr, C.int(busIndex), C.int(frameCapacity), cKey)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+
+	s := &NodeStream{
+		key:       key,
+		enginePtr: unsafe.Pointer(e.ptr),
+		nodePtr:   nodePtr,
+		busIndex:  busIndex,
+		direction: streamDirectionInput,
+		format:    StreamSampleFloat32,
+		ring:      newStreamRing(),
+		inputCB:   cb,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+
+	streamMutex.Lock()
+	streamRegistry[key] = s
+	streamMutex.Unlock()
+
+	return s, nil
+}
+
+// BuildOutputStream attaches an AVAudioSourceNode at nodePtr/busIndex and
+// returns a NodeStream that, once started with Start, calls cb on a dedicated
+// goroutine to fill each block ahead of when the render thread needs it,
+// handing the filled block off through the same lock-free ring
+// BuildInputStream uses in the other direction.
+//
+// As with BuildInputStream, the native source node's render block and the
+// //export trampoline it would pop filled blocks through aren't wired up
+// in this tree yet (see stream_build_output's declaration above) - this
+// installs and tears down cleanly, but cb is never actually invoked until
+// that trampoline exists.
+func (e *Engine) BuildOutputStream(nodePtr unsafe.Pointer, busIndex int, format *Format, cb func(OutputData)) (*NodeStream, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	if nodePtr == nil {
+		return nil, errors.New("node pointer cannot be nil")
+	}
+	if busIndex < 0 {
+		return nil, errors.New("bus index must be non-negative")
+	}
+	if format == nil {
+		return nil, errors.New("format cannot be nil")
+	}
+	if cb == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	key := fmt.Sprintf("stream_out_%p_bus%d_%d", nodePtr, busIndex, time.Now().UnixNano())
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	const frameCapacity = 4096
+	errorStr := C.stream_build_output(e.ptr, nodePtr, C.int(busIndex), C.int(frameCapacity), cKey)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+
+	s := &NodeStream{
+		key:       key,
+		enginePtr: unsafe.Pointer(e.ptr),
+		nodePtr:   nodePtr,
+		busIndex:  busIndex,
+		direction: streamDirectionOutput,
+		format:    StreamSampleFloat32,
+		ring:      newStreamRing(),
+		outputCB:  cb,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+
+	streamMutex.Lock()
+	streamRegistry[key] = s
+	streamMutex.Unlock()
+
+	return s, nil
+}
+
+// Start tells the native side to begin producing/consuming blocks and
+// launches the Go-side drain (input) or fill (output) goroutine.
+func (s *NodeStream) Start() error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.running {
+		return fmt.Errorf("stream is already running")
+	}
+
+	cKey := C.CString(s.key)
+	defer C.free(unsafe.Pointer(cKey))
+	if errorStr := C.stream_start(cKey); errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+
+	s.running = true
+	switch s.direction {
+	case streamDirectionInput:
+		go s.drainLoop()
+	case streamDirectionOutput:
+		go s.fillLoop()
+	}
+	return nil
+}
+
+// Stop tells the native side to stop producing/consuming blocks and blocks
+// until the Go-side goroutine has drained or filled whatever was left in
+// the ring. The NodeStream can be Start()ed again afterward.
+func (s *NodeStream) Stop() error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if !s.running {
+		return nil
+	}
+
+	cKey := C.CString(s.key)
+	defer C.free(unsafe.Pointer(cKey))
+	if errorStr := C.stream_stop(cKey); errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+
+	close(s.stopCh)
+	<-s.doneCh
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+	s.running = false
+	return nil
+}
+
+// Close stops the stream if still running and releases the native tap/
+// source node. The NodeStream must not be used after Close returns.
+func (s *NodeStream) Close() error {
+	if s.running {
+		if err := s.Stop(); err != nil {
+			return err
+		}
+	}
+
+	streamMutex.Lock()
+	delete(streamRegistry, s.key)
+	streamMutex.Unlock()
+
+	cKey := C.CString(s.key)
+	defer C.free(unsafe.Pointer(cKey))
+	if errorStr := C.stream_close(cKey); errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// drainLoop pops captured blocks off the ring and invokes inputCB for each,
+// on a goroutine pinned to its OS thread for the stream's lifetime - audio
+// callbacks benefit from a stable thread the same way the native render
+// thread does, even though this one isn't itself realtime-scheduled. It
+// runs until Stop closes stopCh, draining whatever is left before exiting.
+func (s *NodeStream) drainLoop() {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			for {
+				b, ok := s.ring.pop()
+				if !ok {
+					return
+				}
+				s.deliverInput(b)
+			}
+		case <-ticker.C:
+			for {
+				b, ok := s.ring.pop()
+				if !ok {
+					break
+				}
+				s.deliverInput(b)
+			}
+		}
+	}
+}
+
+func (s *NodeStream) deliverInput(b streamBlock) {
+	atomic.AddUint64(&s.delivered, 1)
+	s.inputCB(InputData{block: b})
+}
+
+// fillLoop calls outputCB to fill blocks ahead of demand and pushes each
+// into the ring for the native render thread to pop, backing off when the
+// ring is already full rather than generating further ahead than it can
+// hold. Like drainLoop, it's pinned to its OS thread for the stream's
+// lifetime, and stops generating further blocks as soon as Stop closes
+// stopCh - whatever's already in the ring is left for the render thread to
+// pop on its own.
+func (s *NodeStream) fillLoop() {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			for {
+				block := streamBlock{format: s.format}
+				s.outputCB(OutputData{block: block})
+				if !s.ring.push(block) {
+					atomic.AddUint64(&s.dropped, 1)
+					break
+				}
+				atomic.AddUint64(&s.delivered, 1)
+			}
+		}
+	}
+}
+
+// Stats returns the stream's running delivery counters.
+func (s *NodeStream) Stats() StreamStats {
+	return StreamStats{
+		BlocksDelivered: atomic.LoadUint64(&s.delivered),
+		BlocksDropped:   atomic.LoadUint64(&s.dropped),
+	}
+}
+
+// IsRunning returns true if the stream is currently started.
+func (s *NodeStream) IsRunning() bool {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	return s.running
+}
+
+// GetKey returns the stream's auto-generated key identifier.
+func (s *NodeStream) GetKey() string {
+	return s.key
+}
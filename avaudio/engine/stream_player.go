@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// streamPlayerChunkFrames is how many frames StreamPlayer reads from its
+// source per PCMBuffer - small enough that Start responds quickly to Stop,
+// large enough that feedLoop isn't dominated by per-call overhead.
+const streamPlayerChunkFrames = 4096
+
+// streamPlayerAheadBuffers caps how many chunks feedLoop schedules onto the
+// player before waiting for playback to catch up - the bounded-channel
+// backpressure the procedural/network-stream use case needs, since nothing
+// stops a fast io.Reader from outpacing real-time playback otherwise.
+const streamPlayerAheadBuffers = 4
+
+// StreamPlayer schedules PCM read from an arbitrary io.Reader onto an
+// AudioPlayer, chunk by chunk, instead of LoadFile's "hand AVAudioFile a
+// path it opens itself" model - the seam this package was missing for
+// synthesized/procedural audio, custom decoders, and network streams (the
+// same use case ebiten's audio.Player/readerdriver and beep cover).
+//
+// There's no native scheduleBuffer:completionHandler: callback wired into
+// this tree (see decoder.go's audioplayer_schedule_pcm_buffer), so unlike a
+// true completion-driven feeder, StreamPlayer estimates how much scheduled
+// audio remains by polling GetCurrentTime against the cumulative duration
+// it has scheduled - the same coarse-poll approach PlayerQueue.trackPosition
+// already uses in this package for the same reason.
+type StreamPlayer struct {
+	engine *Engine
+	player *AudioPlayer
+	format AudioSpec
+
+	mu        sync.Mutex
+	running   bool
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	scheduled []float64 // durations (seconds), oldest (already scheduled) first
+
+	// OnEOF, if set, is called once the source is fully drained and every
+	// scheduled buffer has finished playing.
+	OnEOF func()
+}
+
+// NewStreamPlayer creates a StreamPlayer attached to e, ready for Start.
+// format.BitDepth selects the wire format Start's source is read as: 16
+// for interleaved int16 LE, 32 for interleaved float32 LE - AVAudioPCMBuffer
+// (and audioplayer_schedule_pcm_buffer) only ever hold float32 internally,
+// so 16-bit samples are converted up as they're read.
+func (e *Engine) NewStreamPlayer(format AudioSpec) (*StreamPlayer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	if format.SampleRate <= 0 {
+		return nil, errors.New("format.SampleRate must be positive")
+	}
+	if format.ChannelCount <= 0 {
+		return nil, errors.New("format.ChannelCount must be positive")
+	}
+	if format.BitDepth != 16 && format.BitDepth != 32 {
+		return nil, fmt.Errorf("unsupported BitDepth %d; StreamPlayer reads 16-bit int or 32-bit float PCM", format.BitDepth)
+	}
+
+	player, err := e.NewPlayer()
+	if err != nil {
+		return nil, err
+	}
+	if err := player.ConnectToMainMixer(); err != nil {
+		player.Destroy()
+		return nil, err
+	}
+
+	return &StreamPlayer{engine: e, player: player, format: format}, nil
+}
+
+// Start begins reading PCM from source and scheduling it onto the player,
+// on a background goroutine, until source returns io.EOF or Stop is called.
+func (sp *StreamPlayer) Start(source io.Reader) error {
+	if source == nil {
+		return errors.New("source cannot be nil")
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if sp.running {
+		return errors.New("stream player is already running")
+	}
+
+	if err := sp.player.Play(); err != nil {
+		return err
+	}
+
+	sp.running = true
+	sp.scheduled = nil
+	sp.stopCh = make(chan struct{})
+	sp.doneCh = make(chan struct{})
+	go sp.feedLoop(source, sp.stopCh, sp.doneCh)
+	return nil
+}
+
+// Stop halts feeding and playback. The StreamPlayer can be Start()ed again
+// with a new source afterward.
+func (sp *StreamPlayer) Stop() error {
+	sp.mu.Lock()
+	if !sp.running {
+		sp.mu.Unlock()
+		return nil
+	}
+	close(sp.stopCh)
+	sp.mu.Unlock()
+
+	<-sp.doneCh
+	return sp.player.Stop()
+}
+
+// Close stops the StreamPlayer if running and releases its native player.
+// The StreamPlayer must not be used after Close returns.
+func (sp *StreamPlayer) Close() error {
+	if err := sp.Stop(); err != nil {
+		return err
+	}
+	sp.player.Destroy()
+	return nil
+}
+
+// feedLoop reads chunks from source and schedules them onto sp.player,
+// backing off once streamPlayerAheadBuffers worth of audio is already
+// scheduled ahead of the current playhead, until stopCh closes or source is
+// drained.
+func (sp *StreamPlayer) feedLoop(source io.Reader, stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	frameSize := sp.format.ChannelCount * (sp.format.BitDepth / 8)
+	raw := make([]byte, streamPlayerChunkFrames*frameSize)
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		if !sp.readyForMore() {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				continue
+			}
+		}
+
+		n, err := io.ReadFull(source, raw)
+		if n > 0 {
+			buf := sp.decode(raw[:n-n%frameSize])
+			if buf.FrameCount > 0 {
+				if schedErr := sp.player.ScheduleBuffer(buf, false); schedErr != nil {
+					return
+				}
+				sp.markScheduled(float64(buf.FrameCount) / buf.SampleRate)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			if sp.OnEOF != nil {
+				sp.OnEOF()
+			}
+			sp.engine.events.publish(PlayerEOF{Player: sp.player})
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readyForMore reports whether feedLoop should schedule another chunk,
+// based on how much already-scheduled audio remains ahead of the current
+// playhead (see markScheduled/GetCurrentTime).
+func (sp *StreamPlayer) readyForMore() bool {
+	sp.mu.Lock()
+	aheadCount := len(sp.scheduled)
+	sp.mu.Unlock()
+
+	if aheadCount < streamPlayerAheadBuffers {
+		return true
+	}
+
+	elapsed, err := sp.player.GetCurrentTime()
+	if err != nil {
+		return true
+	}
+
+	sp.mu.Lock()
+	remaining := elapsed.Seconds()
+	for len(sp.scheduled) > 0 && remaining >= sp.scheduled[0] {
+		remaining -= sp.scheduled[0]
+		sp.scheduled = sp.scheduled[1:]
+		sp.engine.events.publish(PlayerBufferCompleted{Player: sp.player})
+	}
+	ready := len(sp.scheduled) < streamPlayerAheadBuffers
+	sp.mu.Unlock()
+	return ready
+}
+
+// markScheduled records a newly scheduled chunk's duration.
+func (sp *StreamPlayer) markScheduled(durationSeconds float64) {
+	sp.mu.Lock()
+	sp.scheduled = append(sp.scheduled, durationSeconds)
+	sp.mu.Unlock()
+}
+
+// decode converts raw interleaved PCM bytes (matching sp.format.BitDepth)
+// into a PCMBuffer of float32 samples in [-1, 1].
+func (sp *StreamPlayer) decode(raw []byte) PCMBuffer {
+	channels := sp.format.ChannelCount
+	switch sp.format.BitDepth {
+	case 16:
+		frameCount := len(raw) / (2 * channels)
+		samples := make([]float32, frameCount*channels)
+		for i := range samples {
+			v := int16(binary.LittleEndian.Uint16(raw[i*2:]))
+			samples[i] = float32(v) / 32768
+		}
+		return PCMBuffer{Samples: samples, FrameCount: frameCount, ChannelCount: channels, SampleRate: sp.format.SampleRate}
+	default: // 32
+		frameCount := len(raw) / (4 * channels)
+		samples := make([]float32, frameCount*channels)
+		for i := range samples {
+			bits := binary.LittleEndian.Uint32(raw[i*4:])
+			samples[i] = math.Float32frombits(bits)
+		}
+		return PCMBuffer{Samples: samples, FrameCount: frameCount, ChannelCount: channels, SampleRate: sp.format.SampleRate}
+	}
+}
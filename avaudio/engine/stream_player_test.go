@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNewStreamPlayerRejectsInvalidFormat(t *testing.T) {
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatal("Failed to create engine:", err)
+	}
+	defer engine.Destroy()
+
+	cases := []struct {
+		name   string
+		format AudioSpec
+	}{
+		{"zero sample rate", AudioSpec{SampleRate: 0, ChannelCount: 2, BitDepth: 16}},
+		{"zero channel count", AudioSpec{SampleRate: 44100, ChannelCount: 0, BitDepth: 16}},
+		{"unsupported bit depth", AudioSpec{SampleRate: 44100, ChannelCount: 2, BitDepth: 24}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := engine.NewStreamPlayer(c.format); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestStreamPlayerStartRejectsNilSource(t *testing.T) {
+	sp := &StreamPlayer{format: AudioSpec{SampleRate: 44100, ChannelCount: 2, BitDepth: 16}}
+	if err := sp.Start(nil); err == nil {
+		t.Error("expected Start to fail for a nil source")
+	}
+}
+
+func TestStreamPlayerDecodeInt16(t *testing.T) {
+	sp := &StreamPlayer{format: AudioSpec{SampleRate: 44100, ChannelCount: 1, BitDepth: 16}}
+
+	raw := make([]byte, 4)
+	binary.LittleEndian.PutUint16(raw[0:], uint16(int16(16384)))  // 0.5
+	binary.LittleEndian.PutUint16(raw[2:], uint16(int16(-32768))) // -1.0
+
+	buf := sp.decode(raw)
+	if buf.FrameCount != 2 || buf.ChannelCount != 1 {
+		t.Fatalf("buf = %+v, want FrameCount=2 ChannelCount=1", buf)
+	}
+	if math.Abs(float64(buf.Samples[0])-0.5) > 1e-4 {
+		t.Errorf("Samples[0] = %v, want ~0.5", buf.Samples[0])
+	}
+	if buf.Samples[1] != -1.0 {
+		t.Errorf("Samples[1] = %v, want -1.0", buf.Samples[1])
+	}
+}
+
+func TestStreamPlayerDecodeFloat32(t *testing.T) {
+	sp := &StreamPlayer{format: AudioSpec{SampleRate: 48000, ChannelCount: 2, BitDepth: 32}}
+
+	raw := make([]byte, 16)
+	binary.LittleEndian.PutUint32(raw[0:], math.Float32bits(0.25))
+	binary.LittleEndian.PutUint32(raw[4:], math.Float32bits(-0.75))
+	binary.LittleEndian.PutUint32(raw[8:], math.Float32bits(1.0))
+	binary.LittleEndian.PutUint32(raw[12:], math.Float32bits(-1.0))
+
+	buf := sp.decode(raw)
+	if buf.FrameCount != 2 || buf.ChannelCount != 2 {
+		t.Fatalf("buf = %+v, want FrameCount=2 ChannelCount=2", buf)
+	}
+	want := []float32{0.25, -0.75, 1.0, -1.0}
+	for i, w := range want {
+		if buf.Samples[i] != w {
+			t.Errorf("Samples[%d] = %v, want %v", i, buf.Samples[i], w)
+		}
+	}
+}
+
+func TestStreamPlayerReadyForMoreBelowWatermark(t *testing.T) {
+	sp := &StreamPlayer{format: AudioSpec{SampleRate: 44100, ChannelCount: 2, BitDepth: 16}}
+	sp.markScheduled(1.0)
+	sp.markScheduled(1.0)
+
+	if !sp.readyForMore() {
+		t.Error("expected readyForMore to report true while below streamPlayerAheadBuffers")
+	}
+}
+
+func TestStreamPlayerFeedLoopPublishesPlayerEOF(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatal("Failed to create engine:", err)
+	}
+	defer e.Destroy()
+
+	sp, err := e.NewStreamPlayer(AudioSpec{SampleRate: 44100, ChannelCount: 2, BitDepth: 16})
+	if err != nil {
+		t.Fatal("NewStreamPlayer failed:", err)
+	}
+	defer sp.player.Destroy()
+
+	events, cancel := e.Subscribe()
+	defer cancel()
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	sp.feedLoop(strings.NewReader(""), stopCh, doneCh)
+	<-doneCh
+
+	select {
+	case ev := <-events:
+		eof, ok := ev.(PlayerEOF)
+		if !ok || eof.Player != sp.player {
+			t.Fatalf("got %#v, want PlayerEOF{Player: sp.player}", ev)
+		}
+	default:
+		t.Fatal("expected a PlayerEOF event once feedLoop hits EOF on an empty source")
+	}
+}
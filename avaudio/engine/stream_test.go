@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestStreamRingPushPop(t *testing.T) {
+	r := &streamRing{slots: make([]streamBlock, 2)}
+
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on empty ring should report false")
+	}
+
+	if !r.push(streamBlock{frameCount: 1}) {
+		t.Fatal("push into empty ring should succeed")
+	}
+	if !r.push(streamBlock{frameCount: 2}) {
+		t.Fatal("push into ring with one free slot should succeed")
+	}
+	if r.push(streamBlock{frameCount: 3}) {
+		t.Fatal("push into full ring should fail")
+	}
+
+	b, ok := r.pop()
+	if !ok || b.frameCount != 1 {
+		t.Fatalf("pop = %+v, %v; want frameCount=1, true", b, ok)
+	}
+	if !r.push(streamBlock{frameCount: 3}) {
+		t.Fatal("push after freeing a slot should succeed")
+	}
+
+	b, ok = r.pop()
+	if !ok || b.frameCount != 2 {
+		t.Fatalf("pop = %+v, %v; want frameCount=2, true", b, ok)
+	}
+	b, ok = r.pop()
+	if !ok || b.frameCount != 3 {
+		t.Fatalf("pop = %+v, %v; want frameCount=3, true", b, ok)
+	}
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on drained ring should report false")
+	}
+}
+
+func TestBuildInputStreamRejectsInvalidArgs(t *testing.T) {
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatal("Failed to create engine:", err)
+	}
+	defer engine.Destroy()
+
+	valid := unsafe.Pointer(&struct{}{})
+	format := &Format{}
+	noop := func(InputData) {}
+
+	cases := []struct {
+		name     string
+		nodePtr  unsafe.Pointer
+		busIndex int
+		format   *Format
+		cb       func(InputData)
+	}{
+		{"nil node", nil, 0, format, noop},
+		{"negative bus", valid, -1, format, noop},
+		{"nil format", valid, 0, nil, noop},
+		{"nil callback", valid, 0, format, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := engine.BuildInputStream(c.nodePtr, c.busIndex, c.format, c.cb); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestBuildOutputStreamRejectsInvalidArgs(t *testing.T) {
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatal("Failed to create engine:", err)
+	}
+	defer engine.Destroy()
+
+	valid := unsafe.Pointer(&struct{}{})
+	format := &Format{}
+	noop := func(OutputData) {}
+
+	cases := []struct {
+		name     string
+		nodePtr  unsafe.Pointer
+		busIndex int
+		format   *Format
+		cb       func(OutputData)
+	}{
+		{"nil node", nil, 0, format, noop},
+		{"negative bus", valid, -1, format, noop},
+		{"nil format", valid, 0, nil, noop},
+		{"nil callback", valid, 0, format, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := engine.BuildOutputStream(c.nodePtr, c.busIndex, c.format, c.cb); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package engine
+
+import "sync"
+
+// Event types beyond EngineStarted/EngineStopped/DeviceAdded/DeviceRemoved
+// bridge from this package's other callback mechanisms so Subscribe is the
+// one place callers need to watch instead of separately wiring OnXrun,
+// OnNotification, and a StreamPlayer's OnEOF: ConfigurationChanged from
+// recordNotification (notifications.go), Overload from recordXrun
+// (xrun.go), and PlayerEOF/PlayerBufferCompleted from StreamPlayer
+// (stream_player.go). DeviceAdded/DeviceRemoved already cover route changes
+// at a finer grain than a single combined "route changed" event would, so
+// there's no separate type for that here.
+
+// EngineEvent is implemented by every event Engine.Subscribe delivers.
+// Separate typed structs rather than one struct with a Kind enum, matching
+// BusEvent in engine/channel.
+type EngineEvent interface {
+	isEngineEvent()
+}
+
+// EngineStarted is published by Start.
+type EngineStarted struct{}
+
+func (EngineStarted) isEngineEvent() {}
+
+// EngineStopped is published by Stop.
+type EngineStopped struct{}
+
+func (EngineStopped) isEngineEvent() {}
+
+// DeviceAdded is published when OnDeviceChange's background poll observes a
+// new audio device.
+type DeviceAdded struct {
+	Device DeviceInfo
+}
+
+func (DeviceAdded) isEngineEvent() {}
+
+// DeviceRemoved is published when OnDeviceChange's background poll observes
+// an audio device disappearing.
+type DeviceRemoved struct {
+	Device DeviceInfo
+}
+
+func (DeviceRemoved) isEngineEvent() {}
+
+// ConfigurationChanged is published by recordNotification when the engine
+// reports a NotificationConfigurationChange (AVAudioEngineConfiguration
+// ChangeNotification) - a route, format, or device change AVAudioEngine
+// itself asked to be reconfigured for, distinct from DeviceAdded/
+// DeviceRemoved's CoreAudio device-list polling.
+type ConfigurationChanged struct{}
+
+func (ConfigurationChanged) isEngineEvent() {}
+
+// PlayerEOF is published by a StreamPlayer once its source is fully drained
+// and every scheduled buffer has finished playing - the same moment
+// StreamPlayer.OnEOF fires, for callers that'd rather watch one Subscribe
+// channel than set a per-player callback.
+type PlayerEOF struct {
+	Player *AudioPlayer
+}
+
+func (PlayerEOF) isEngineEvent() {}
+
+// PlayerBufferCompleted is published by a StreamPlayer each time one of its
+// scheduled buffers finishes playing, as tracked by readyForMore's playhead
+// estimate - there's no native scheduleBuffer:completionHandler: callback
+// wired into this tree (see StreamPlayer's doc comment), so like the rest of
+// StreamPlayer this is a coarse poll-driven approximation, not a per-buffer
+// native callback.
+type PlayerBufferCompleted struct {
+	Player *AudioPlayer
+}
+
+func (PlayerBufferCompleted) isEngineEvent() {}
+
+// Overload is published by recordXrun whenever the render thread reports a
+// buffer under/overrun (see OnXrun), for callers that'd rather watch
+// Subscribe than register a separate OnXrun listener.
+type Overload struct {
+	BufferSize int
+}
+
+func (Overload) isEngineEvent() {}
+
+// engineEventBus holds one Engine's Subscribe subscriber registry. It's
+// zero-value-usable - subs is allocated lazily on the first Subscribe call -
+// so Engine doesn't need a constructor change to carry it.
+type engineEventBus struct {
+	mu          sync.Mutex
+	nextID      int
+	subs        map[int]chan EngineEvent
+	watchHooked bool
+}
+
+// publish fans ev out to every subscriber, dropping it for one whose buffer
+// is full rather than blocking Start/Stop/the device-change poll - the same
+// policy devices.Subscribe and engine/channel's Bus.Subscribe use.
+func (eb *engineEventBus) publish(ev EngineEvent) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	for _, ch := range eb.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// Subscribe registers for EngineStarted/EngineStopped/DeviceAdded/
+// DeviceRemoved events, published by Start/Stop and by OnDeviceChange's
+// background device poll. Like devices.Subscribe, the returned channel is
+// buffered and a slow subscriber misses events rather than stalling the
+// publisher; cancel unregisters it and closes the channel.
+func (e *Engine) Subscribe() (<-chan EngineEvent, func()) {
+	e.events.mu.Lock()
+	if e.events.subs == nil {
+		e.events.subs = make(map[int]chan EngineEvent)
+	}
+	if !e.events.watchHooked {
+		e.events.watchHooked = true
+		OnDeviceChange(func(ev DeviceChangeEvent) {
+			for _, d := range ev.Added {
+				e.events.publish(DeviceAdded{Device: d})
+			}
+			for _, d := range ev.Removed {
+				e.events.publish(DeviceRemoved{Device: d})
+			}
+		})
+	}
+
+	ch := make(chan EngineEvent, 32)
+	id := e.events.nextID
+	e.events.nextID++
+	e.events.subs[id] = ch
+	e.events.mu.Unlock()
+
+	var cancelOnce sync.Once
+	cancel := func() {
+		cancelOnce.Do(func() {
+			e.events.mu.Lock()
+			delete(e.events.subs, id)
+			close(ch)
+			e.events.mu.Unlock()
+		})
+	}
+	return ch, cancel
+}
@@ -0,0 +1,118 @@
+package engine
+
+import "testing"
+
+func TestEngineSubscribeReceivesStartedAndStopped(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	events, cancel := e.Subscribe()
+	defer cancel()
+
+	if err := e.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	e.Stop()
+
+	var sawStarted, sawStopped bool
+	for i := 0; i < 2; i++ {
+		select {
+		case ev := <-events:
+			switch ev.(type) {
+			case EngineStarted:
+				sawStarted = true
+			case EngineStopped:
+				sawStopped = true
+			}
+		default:
+		}
+	}
+	if !sawStarted {
+		t.Error("expected an EngineStarted event after Start")
+	}
+	if !sawStopped {
+		t.Error("expected an EngineStopped event after Stop")
+	}
+}
+
+func TestEngineSubscribeReceivesOverload(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	events, cancel := e.Subscribe()
+	defer cancel()
+
+	e.recordXrun(XrunEvent{BufferSize: 512})
+
+	select {
+	case ev := <-events:
+		overload, ok := ev.(Overload)
+		if !ok || overload.BufferSize != 512 {
+			t.Fatalf("got %#v, want Overload{BufferSize: 512}", ev)
+		}
+	default:
+		t.Fatal("expected an Overload event after recordXrun")
+	}
+}
+
+func TestEngineSubscribeReceivesConfigurationChanged(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	events, cancel := e.Subscribe()
+	defer cancel()
+
+	e.recordNotification(EngineNotification{Kind: NotificationConfigurationChange})
+
+	select {
+	case ev := <-events:
+		if _, ok := ev.(ConfigurationChanged); !ok {
+			t.Fatalf("got %#v, want ConfigurationChanged", ev)
+		}
+	default:
+		t.Fatal("expected a ConfigurationChanged event after recordNotification")
+	}
+}
+
+func TestEngineSubscribeIgnoresOtherNotificationKinds(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	events, cancel := e.Subscribe()
+	defer cancel()
+
+	e.recordNotification(EngineNotification{Kind: NotificationMediaServicesReset})
+
+	select {
+	case ev := <-events:
+		t.Fatalf("got unexpected event %#v, want none", ev)
+	default:
+	}
+}
+
+func TestEngineSubscribeCancelClosesChannel(t *testing.T) {
+	e, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Destroy()
+
+	events, cancel := e.Subscribe()
+	cancel()
+
+	if _, ok := <-events; ok {
+		t.Error("expected the events channel to be closed after cancel")
+	}
+}
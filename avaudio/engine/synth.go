@@ -0,0 +1,112 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include "../../native/macaudio.h"
+
+// AUAudioUnit-backed signal generators - sine/noise/sweep synthesized
+// entirely in the native render block, so (unlike CreateRenderUnit's
+// generic callback-into-Go node) these don't need a render-notify-tap
+// binding to produce sound: only their parameters cross into Go.
+AudioNodeResult synth_create_sine(void* enginePtr, double frequencyHz, double amplitude);
+AudioNodeResult synth_create_noise(void* enginePtr, int kind);
+AudioNodeResult synth_create_sweep(void* enginePtr, double startHz, double endHz, double durationSeconds);
+AudioNodeResult synth_create_silence(void* enginePtr);
+const char* synth_set_frequency(void* nodePtr, double frequencyHz);
+const char* synth_set_amplitude(void* nodePtr, double amplitude);
+*/
+import "C"
+import (
+	"errors"
+	"time"
+	"unsafe"
+)
+
+// SynthNoiseKind selects the spectral character CreateNoiseSynth generates.
+type SynthNoiseKind int
+
+const (
+	SynthNoiseWhite SynthNoiseKind = iota
+	SynthNoisePink
+)
+
+// CreateSineSynth attaches a sine-wave generator node to e's graph,
+// producing amplitude (0.0-1.0) at frequencyHz until SetSynthFrequency/
+// SetSynthAmplitude change it live.
+func (e *Engine) CreateSineSynth(frequencyHz, amplitude float64) (unsafe.Pointer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	result := C.synth_create_sine(e.ptr, C.double(frequencyHz), C.double(amplitude))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// CreateNoiseSynth attaches a noise generator node of the given kind to
+// e's graph.
+func (e *Engine) CreateNoiseSynth(kind SynthNoiseKind) (unsafe.Pointer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	result := C.synth_create_noise(e.ptr, C.int(kind))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// CreateSweepSynth attaches a node to e's graph that sweeps linearly from
+// startHz to endHz over duration, then holds at endHz.
+func (e *Engine) CreateSweepSynth(startHz, endHz float64, duration time.Duration) (unsafe.Pointer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	result := C.synth_create_sweep(e.ptr, C.double(startHz), C.double(endHz), C.double(duration.Seconds()))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// CreateSilenceSynth attaches a node to e's graph that produces digital
+// silence, useful as a capacity/isolation test fixture that exercises the
+// full channel graph without contributing audible output.
+func (e *Engine) CreateSilenceSynth() (unsafe.Pointer, error) {
+	if e == nil || e.ptr == nil {
+		return nil, errors.New("engine is nil")
+	}
+	result := C.synth_create_silence(e.ptr)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// SetSynthFrequency updates the frequency of a sine or sweep node created
+// by CreateSineSynth/CreateSweepSynth.
+func (e *Engine) SetSynthFrequency(nodePtr unsafe.Pointer, frequencyHz float64) error {
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+	errorStr := C.synth_set_frequency(nodePtr, C.double(frequencyHz))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SetSynthAmplitude updates the output amplitude of a node created by
+// CreateSineSynth/CreateNoiseSynth/CreateSweepSynth.
+func (e *Engine) SetSynthAmplitude(nodePtr unsafe.Pointer, amplitude float64) error {
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+	errorStr := C.synth_set_amplitude(nodePtr, C.double(amplitude))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
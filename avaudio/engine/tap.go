@@ -21,6 +21,7 @@ type TapMetrics struct {
 	RMS        float64   // Root Mean Square level
 	FrameCount int       // Number of frames in last buffer
 	LastUpdate time.Time // When metrics were last updated
+	SessionID  int       // Set by PersistentTap.GetMetrics; 0 for a plain Tap
 }
 
 // TapInfo contains information about an installed audio tap
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TapSpec declares where a TapRegistry-managed tap lives - the node
+// selector and bus InstallPersistentTap already takes, bundled into one
+// value so Engine.Taps().Install reads declaratively instead of as a bare
+// positional call.
+type TapSpec struct {
+	Node TapNodeSelector
+	Bus  int
+}
+
+// TapRegistry owns every tap a caller installs through Engine.Taps(),
+// keyed by the same string key PersistentTap already installs under.
+// Each entry is a PersistentTap under the hood, so it already reinstalls
+// itself across Stop()/Start() and the graph edits that are conventionally
+// bracketed by one (EnableTimePitchEffects, ConnectToMainMixer) - the
+// registry's own job is just remembering (selector, bus, key) declaratively
+// so callers don't have to re-derive the node pointer and re-call
+// InstallTapWithKey by hand after every restart, and so a tap can be looked
+// up, or torn down, by key from anywhere that has the Engine.
+//
+// PersistentTap.GetMetrics already tags every reading with SessionID, the
+// install/reinstall count - that's what lets a caller reading a
+// TapRegistry-managed tap's metrics tell a stale, pre-restart sample from a
+// live one.
+type TapRegistry struct {
+	engine *Engine
+
+	mu   sync.Mutex
+	taps map[string]*PersistentTap
+}
+
+// Taps returns e's TapRegistry, creating it on first use.
+func (e *Engine) Taps() *TapRegistry {
+	e.tapRegistryOnce.Do(func() {
+		e.tapRegistry = &TapRegistry{engine: e, taps: make(map[string]*PersistentTap)}
+	})
+	return e.tapRegistry
+}
+
+// Install registers a tap under key per spec, installing it immediately (as
+// InstallPersistentTap does) and remembering spec so the registry can
+// report it back via Get. Calling Install again with a key already in use
+// removes the old tap first, so redeclaring a spec (e.g. after changing
+// Bus) is as simple as calling Install again.
+func (r *TapRegistry) Install(key string, spec TapSpec) (*PersistentTap, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.taps[key]; ok {
+		_ = existing.Remove()
+		delete(r.taps, key)
+	}
+
+	pt, err := InstallPersistentTap(r.engine, spec.Node, spec.Bus, key)
+	if err != nil {
+		return nil, fmt.Errorf("tap registry: install %q: %w", key, err)
+	}
+
+	r.taps[key] = pt
+	return pt, nil
+}
+
+// Get returns the PersistentTap registered under key, if any.
+func (r *TapRegistry) Get(key string) (*PersistentTap, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pt, ok := r.taps[key]
+	return pt, ok
+}
+
+// Remove tears down the tap registered under key and forgets its spec.
+// Removing a key that was never installed is a no-op.
+func (r *TapRegistry) Remove(key string) error {
+	r.mu.Lock()
+	pt, ok := r.taps[key]
+	delete(r.taps, key)
+	r.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return pt.Remove()
+}
+
+// Keys returns every key currently registered, in no particular order.
+func (r *TapRegistry) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.taps))
+	for k := range r.taps {
+		keys = append(keys, k)
+	}
+	return keys
+}
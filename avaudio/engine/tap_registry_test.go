@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestTapRegistryInstallGetAndKeys checks the registry's bookkeeping -
+// Install makes a tap available via Get and Keys - independent of the
+// PersistentTap reinstall machinery TestPersistentTapSurvivesRestart
+// already covers.
+func TestTapRegistryInstallGetAndKeys(t *testing.T) {
+	if os.Getenv("MACAUDIO_AUDIBLE") == "" {
+		t.Skip("Set MACAUDIO_AUDIBLE=1 to run tap registry test")
+	}
+
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Destroy()
+
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	pt, err := engine.Taps().Install("mixer_out", TapSpec{Node: MainMixer{}, Bus: 0})
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	defer engine.Taps().Remove("mixer_out")
+
+	got, ok := engine.Taps().Get("mixer_out")
+	if !ok || got != pt {
+		t.Fatalf("Get(%q) = %v, %v, want the tap Install returned", "mixer_out", got, ok)
+	}
+
+	keys := engine.Taps().Keys()
+	if len(keys) != 1 || keys[0] != "mixer_out" {
+		t.Fatalf("Keys() = %v, want [mixer_out]", keys)
+	}
+}
+
+// TestTapRegistrySurvivesRestart is TestPersistentTapSurvivesRestart's
+// scenario driven through Engine.Taps() instead of InstallPersistentTap
+// directly, confirming the declarative Install/TapSpec wrapper doesn't lose
+// the reinstall-on-restart behavior it's built on.
+func TestTapRegistrySurvivesRestart(t *testing.T) {
+	if os.Getenv("MACAUDIO_AUDIBLE") == "" {
+		t.Skip("Set MACAUDIO_AUDIBLE=1 to run tap registry restart test")
+	}
+
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Destroy()
+
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	player, err := engine.NewPlayer()
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	defer player.Destroy()
+
+	if err := player.LoadFile("idea.m4a"); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := player.ConnectToMainMixer(); err != nil {
+		t.Fatalf("ConnectToMainMixer failed: %v", err)
+	}
+
+	pt, err := engine.Taps().Install("tap_registry_restart_test", TapSpec{Node: MainMixer{}, Bus: 0})
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	defer engine.Taps().Remove("tap_registry_restart_test")
+
+	engine.Stop()
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start after restart failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if got := pt.SessionID(); got != 2 {
+		t.Fatalf("SessionID after restart = %d, want 2", got)
+	}
+}
+
+// TestTapRegistryInstallReplacesExistingKey checks that calling Install
+// again with a key already in use tears down the old tap instead of
+// leaking it.
+func TestTapRegistryInstallReplacesExistingKey(t *testing.T) {
+	if os.Getenv("MACAUDIO_AUDIBLE") == "" {
+		t.Skip("Set MACAUDIO_AUDIBLE=1 to run tap registry replace test")
+	}
+
+	engine, err := New(DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Destroy()
+
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	first, err := engine.Taps().Install("mixer_out", TapSpec{Node: MainMixer{}, Bus: 0})
+	if err != nil {
+		t.Fatalf("first Install failed: %v", err)
+	}
+	defer engine.Taps().Remove("mixer_out")
+
+	second, err := engine.Taps().Install("mixer_out", TapSpec{Node: MainMixer{}, Bus: 0})
+	if err != nil {
+		t.Fatalf("second Install failed: %v", err)
+	}
+	if first == second {
+		t.Fatal("expected the second Install to replace the first PersistentTap, not return it")
+	}
+
+	if got, ok := engine.Taps().Get("mixer_out"); !ok || got != second {
+		t.Fatalf("Get(%q) = %v, %v, want the second Install's tap", "mixer_out", got, ok)
+	}
+}
@@ -0,0 +1,125 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L../../ -lmacaudio -Wl,-rpath,/Users/shaban/Code/macaudio
+#include <stdlib.h>
+
+// Declared here; implemented in native/engine_xrun.m once a render-thread
+// overload/dropout signal - AVAudioEngine has no public callback for this,
+// so this would have to ride whatever IOProc-overload notification
+// CoreAudio exposes, the same signal AudioFlinger's NBLog and Ardour's
+// DSP-load meter are built on - is bridged through a //export trampoline
+// into Go (see (*Engine).OnXrun's doc comment). handle is an opaque token
+// the eventual trampoline would pass back so the Go side can route the
+// event to the right Engine's listeners, mirroring
+// audioengine_install_notification_observer in notifications.go.
+const char* audioengine_install_xrun_observer(void* enginePtr, uintptr_t handle);
+const char* audioengine_remove_xrun_observer(uintptr_t handle);
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// XrunEvent is one buffer under/overrun reported by OnXrun.
+type XrunEvent struct {
+	// BufferSize is the engine's buffer size in frames (see
+	// Engine.SetBufferSize) at the time of the xrun.
+	BufferSize int
+}
+
+// xrunRegistry tracks OnXrun listeners per Engine and the native handle used
+// to unregister them, mirroring notificationRegistry in notifications.go.
+type xrunRegistry struct {
+	mu        sync.Mutex
+	listeners map[*Engine][]func(XrunEvent)
+	handles   map[*Engine]uint64
+}
+
+var xruns = &xrunRegistry{
+	listeners: make(map[*Engine][]func(XrunEvent)),
+	handles:   make(map[*Engine]uint64),
+}
+
+// OnXrun registers fn to be called whenever the render thread reports a
+// buffer underrun/overrun, installing the native observer the first time
+// any listener is registered on e.
+//
+// The cgo trampoline the native observer needs to call back into Go isn't
+// wired up in this tree yet (see audioengine_install_xrun_observer's
+// declaration above) - like OnNotification, this installs cleanly and fn is
+// retained, but fn is never invoked by real hardware until that trampoline
+// exists. The eventual trampoline (and this package's own tests, in the
+// meantime) drive it through recordXrun.
+func (e *Engine) OnXrun(fn func(XrunEvent)) error {
+	if e == nil || e.ptr == nil {
+		return errors.New("engine is nil")
+	}
+	if fn == nil {
+		return errors.New("callback cannot be nil")
+	}
+
+	xruns.mu.Lock()
+	defer xruns.mu.Unlock()
+
+	if len(xruns.listeners[e]) > 0 {
+		xruns.listeners[e] = append(xruns.listeners[e], fn)
+		return nil
+	}
+
+	handle := atomic.AddUint64(&nativeHandleCounter, 1)
+	errorStr := C.audioengine_install_xrun_observer(unsafe.Pointer(e.ptr), C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+
+	xruns.listeners[e] = []func(XrunEvent){fn}
+	xruns.handles[e] = handle
+	return nil
+}
+
+// RemoveXrunObserver removes the native observer installed by OnXrun and
+// drops every listener registered on e. It's a no-op if e never called
+// OnXrun.
+func (e *Engine) RemoveXrunObserver() error {
+	xruns.mu.Lock()
+	defer xruns.mu.Unlock()
+
+	handle, ok := xruns.handles[e]
+	if !ok {
+		return nil
+	}
+	delete(xruns.listeners, e)
+	delete(xruns.handles, e)
+
+	errorStr := C.audioengine_remove_xrun_observer(C.uintptr_t(handle))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// recordXrun bumps RuntimeStats.Underruns, delivers event to every listener
+// OnXrun has registered on e, and publishes an Overload to e's Subscribe
+// bus - the call the eventual native trampoline would make; this package's
+// own tests drive it directly in the meantime.
+func (e *Engine) recordXrun(event XrunEvent) {
+	e.stats.mu.Lock()
+	e.stats.underruns++
+	e.stats.mu.Unlock()
+
+	xruns.mu.Lock()
+	fns := append([]func(XrunEvent){}, xruns.listeners[e]...)
+	xruns.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(event)
+	}
+
+	e.events.publish(Overload{BufferSize: event.BufferSize})
+}
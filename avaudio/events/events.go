@@ -0,0 +1,169 @@
+// Package events is a small typed publish/subscribe bus, modeled after
+// libp2p's event bus: one Emitter per event type rather than a single
+// firehose a subscriber has to filter, and Subscribe hands back a buffered
+// channel instead of a callback so a slow or misbehaving subscriber can't
+// hold up whoever calls Emit from inside an audio-adjacent code path.
+package events
+
+import "sync"
+
+// DropPolicy controls what Emit does when a subscription's buffer is full.
+type DropPolicy int
+
+const (
+	// DropOldest discards the subscription's oldest buffered event to make
+	// room for the new one, so Emit never blocks its caller. This is the
+	// default: most subscribers (UI, telemetry) only care about the latest
+	// state and would rather miss a stale update than stall the goroutine
+	// calling Emit.
+	DropOldest DropPolicy = iota
+	// Block makes Emit wait for room in the subscription's buffer instead
+	// of dropping anything, for a subscriber (undo/redo history, for
+	// instance) that must see every event in order and can't tolerate gaps.
+	Block
+)
+
+// DefaultBufferSize is a subscription's channel capacity when Subscribe is
+// called without WithBufferSize.
+const DefaultBufferSize = 16
+
+// SubscribeOption configures a Subscription at Subscribe time.
+type SubscribeOption func(*subOptions)
+
+type subOptions struct {
+	bufferSize int
+	dropPolicy DropPolicy
+}
+
+// WithBufferSize sets the subscription channel's capacity.
+func WithBufferSize(n int) SubscribeOption {
+	return func(o *subOptions) { o.bufferSize = n }
+}
+
+// WithDropPolicy sets what Emit does when the subscription's buffer is full.
+func WithDropPolicy(p DropPolicy) SubscribeOption {
+	return func(o *subOptions) { o.dropPolicy = p }
+}
+
+// Emitter fans out values of type T to every current Subscription. The zero
+// value is not usable; create one with NewEmitter.
+type Emitter[T any] struct {
+	mu   sync.Mutex
+	subs map[*Subscription[T]]struct{}
+}
+
+// NewEmitter creates an Emitter with no subscribers.
+func NewEmitter[T any]() *Emitter[T] {
+	return &Emitter[T]{subs: make(map[*Subscription[T]]struct{})}
+}
+
+// Subscribe registers a new Subscription and returns it. Callers must call
+// Close when done, to free the subscription's slot in the emitter and stop
+// it from receiving further events.
+func (e *Emitter[T]) Subscribe(opts ...SubscribeOption) *Subscription[T] {
+	o := subOptions{bufferSize: DefaultBufferSize, dropPolicy: DropOldest}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sub := &Subscription[T]{
+		ch:         make(chan T, o.bufferSize),
+		done:       make(chan struct{}),
+		emitter:    e,
+		dropPolicy: o.dropPolicy,
+	}
+
+	e.mu.Lock()
+	e.subs[sub] = struct{}{}
+	e.mu.Unlock()
+	return sub
+}
+
+// Emit delivers value to every current subscription, applying each
+// subscription's own drop policy if its buffer is full. Emit only blocks on
+// a Block subscription that isn't being drained; a DropOldest subscription
+// never blocks Emit.
+func (e *Emitter[T]) Emit(value T) {
+	e.mu.Lock()
+	subs := make([]*Subscription[T], 0, len(e.subs))
+	for sub := range e.subs {
+		subs = append(subs, sub)
+	}
+	e.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.deliver(value)
+	}
+}
+
+// remove unregisters sub, called from Subscription.Close.
+func (e *Emitter[T]) remove(sub *Subscription[T]) {
+	e.mu.Lock()
+	delete(e.subs, sub)
+	e.mu.Unlock()
+}
+
+// Subscription receives events of type T from the Emitter that created it.
+// Receive from Ch directly; call Close when done with it.
+type Subscription[T any] struct {
+	ch         chan T
+	done       chan struct{}
+	emitter    *Emitter[T]
+	dropPolicy DropPolicy
+	closeOnce  sync.Once
+}
+
+// Ch returns the channel events arrive on.
+func (s *Subscription[T]) Ch() <-chan T {
+	return s.ch
+}
+
+// deliver applies the subscription's drop policy for one value. It never
+// closes or sends on s.ch after Close has run, since done is closed first
+// and every branch below selects on it.
+func (s *Subscription[T]) deliver(value T) {
+	switch s.dropPolicy {
+	case Block:
+		select {
+		case s.ch <- value:
+		case <-s.done:
+		}
+	default: // DropOldest
+		select {
+		case s.ch <- value:
+			return
+		case <-s.done:
+			return
+		default:
+		}
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- value:
+		case <-s.done:
+		default:
+		}
+	}
+}
+
+// Close unregisters the subscription from its Emitter and drains whatever
+// is left buffered in its channel. Safe to call more than once, and safe to
+// call while Emit is concurrently delivering to this subscription - deliver
+// always selects on done alongside the send, so no goroutine blocks on a
+// subscription nobody will ever read again.
+func (s *Subscription[T]) Close() error {
+	s.closeOnce.Do(func() {
+		s.emitter.remove(s)
+		close(s.done)
+		for {
+			select {
+			case <-s.ch:
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
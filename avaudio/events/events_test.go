@@ -0,0 +1,124 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmitDeliversToSubscriber(t *testing.T) {
+	e := NewEmitter[int]()
+	sub := e.Subscribe()
+	defer sub.Close()
+
+	e.Emit(42)
+
+	select {
+	case v := <-sub.Ch():
+		if v != 42 {
+			t.Errorf("expected 42, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+func TestEmitFansOutToMultipleSubscribers(t *testing.T) {
+	e := NewEmitter[string]()
+	a := e.Subscribe()
+	b := e.Subscribe()
+	defer a.Close()
+	defer b.Close()
+
+	e.Emit("hello")
+
+	for _, sub := range []*Subscription[string]{a, b} {
+		select {
+		case v := <-sub.Ch():
+			if v != "hello" {
+				t.Errorf("expected \"hello\", got %q", v)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+}
+
+func TestDropOldestDoesNotBlockEmit(t *testing.T) {
+	e := NewEmitter[int]()
+	sub := e.Subscribe(WithBufferSize(2), WithDropPolicy(DropOldest))
+	defer sub.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			e.Emit(i)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Emit blocked with a DropOldest subscriber that was never drained")
+	}
+
+	// The most recent value must have survived even though nobody ever read
+	// from the channel while Emit was running.
+	var last int
+	for {
+		select {
+		case v := <-sub.Ch():
+			last = v
+			continue
+		default:
+		}
+		break
+	}
+	if last != 99 {
+		t.Errorf("expected the newest value (99) to survive dropping, got %d", last)
+	}
+}
+
+func TestBlockDeliversEveryValueInOrder(t *testing.T) {
+	e := NewEmitter[int]()
+	sub := e.Subscribe(WithBufferSize(1), WithDropPolicy(Block))
+	defer sub.Close()
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			e.Emit(i)
+		}
+	}()
+
+	for want := 0; want < 5; want++ {
+		select {
+		case got := <-sub.Ch():
+			if got != want {
+				t.Fatalf("expected %d, got %d", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for value %d", want)
+		}
+	}
+}
+
+func TestCloseUnsubscribesAndDrains(t *testing.T) {
+	e := NewEmitter[int]()
+	sub := e.Subscribe()
+
+	e.Emit(1)
+	if err := sub.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	// Close should be idempotent.
+	if err := sub.Close(); err != nil {
+		t.Fatalf("second Close returned error: %v", err)
+	}
+
+	e.mu.Lock()
+	remaining := len(e.subs)
+	e.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("expected Close to unregister the subscription, %d still registered", remaining)
+	}
+}
@@ -0,0 +1,147 @@
+package avaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// writeSineWAV writes a short mono 16-bit PCM WAV fixture at sampleRate/freq
+// so the file-backed source node tests don't depend on checked-in audio
+// assets.
+func writeSineWAV(t *testing.T, path string, sampleRate int, freq float64, dur time.Duration) {
+	t.Helper()
+
+	frameCount := int(dur.Seconds() * float64(sampleRate))
+	samples := make([]int16, frameCount)
+	for i := range samples {
+		phase := 2 * math.Pi * freq * float64(i) / float64(sampleRate)
+		samples[i] = int16(0.5 * math.MaxInt16 * math.Sin(phase))
+	}
+
+	dataSize := len(samples) * 2
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create fixture WAV: %v", err)
+	}
+	defer f.Close()
+
+	write := func(v interface{}) {
+		if err := binary.Write(f, binary.LittleEndian, v); err != nil {
+			t.Fatalf("Failed to write WAV header: %v", err)
+		}
+	}
+
+	f.WriteString("RIFF")
+	write(int32(36 + dataSize))
+	f.WriteString("WAVE")
+	f.WriteString("fmt ")
+	write(int32(16))             // fmt chunk size
+	write(int16(1))              // PCM
+	write(int16(1))              // mono
+	write(int32(sampleRate))
+	write(int32(sampleRate * 2)) // byte rate
+	write(int16(2))              // block align
+	write(int16(16))             // bits per sample
+	f.WriteString("data")
+	write(int32(dataSize))
+	write(samples)
+}
+
+// TestFileSourceNodePlaybackMono loads a synthesized mono WAV fixture through
+// NewFromFile and verifies it produces audible output via the main mixer,
+// mirroring the tone-based pipeline tests in this package.
+func TestFileSourceNodePlaybackMono(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping file source node test in short mode")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mono.wav")
+	writeSineWAV(t, path, 44100, 440.0, 500*time.Millisecond)
+
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	file, err := sourcenode.NewFromFile(path, sourcenode.FileOptions{Loop: true})
+	if err != nil {
+		t.Fatalf("Failed to open file source node: %v", err)
+	}
+	defer file.Destroy()
+
+	if file.ChannelCount() != 1 {
+		t.Fatalf("Expected mono file, got %d channels", file.ChannelCount())
+	}
+
+	nodePtr, err := file.GetNodePtr()
+	if err != nil {
+		t.Fatalf("Failed to get node pointer: %v", err)
+	}
+	if err := eng.Attach(nodePtr); err != nil {
+		t.Fatalf("Failed to attach node: %v", err)
+	}
+	mainMixer, err := eng.MainMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to get main mixer: %v", err)
+	}
+	if err := eng.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+		t.Fatalf("Failed to connect node to mixer: %v", err)
+	}
+	outputNode, err := eng.OutputNode()
+	if err != nil {
+		t.Fatalf("Failed to get output node: %v", err)
+	}
+	if err := eng.Connect(mainMixer, outputNode, 0, 0); err != nil {
+		t.Fatalf("Failed to connect mixer to output: %v", err)
+	}
+
+	_ = testutil.MuteMainMixerNoT(eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+
+	if err := file.FadeIn(20 * time.Millisecond); err != nil {
+		t.Fatalf("Failed to fade in: %v", err)
+	}
+
+	testutil.AssertRMSAbove(t, eng, mainMixer, 0, 0.0001, 2*time.Second)
+}
+
+// TestFileSourceNodeFromReader exercises NewFromReader, which spills the
+// stream to a temp file since AVAudioFile opens by path rather than
+// streaming arbitrary io.Readers directly.
+func TestFileSourceNodeFromReader(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping file source node test in short mode")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stereo.wav")
+	writeSineWAV(t, path, 44100, 220.0, 300*time.Millisecond)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	file, err := sourcenode.NewFromReader(f, sourcenode.FormatWAV, sourcenode.FileOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create node from reader: %v", err)
+	}
+	defer file.Destroy()
+
+	if file.Duration() <= 0 {
+		t.Fatalf("Expected positive duration, got %s", file.Duration())
+	}
+}
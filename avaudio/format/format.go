@@ -10,7 +10,7 @@ package format
 AudioFormatResult audioformat_new_mono(double sampleRate);
 AudioFormatResult audioformat_new_stereo(double sampleRate);
 AudioFormatResult audioformat_new_with_channels(double sampleRate, int channels, bool interleaved);
-AudioFormatResult audioformat_new_from_spec(double sampleRate, int channels, bool interleaved);
+AudioFormatResult audioformat_new_from_spec(double sampleRate, int channels, bool interleaved, int commonFormat);
 AudioFormatResult audioformat_get_format(AudioFormat* wrapper);
 void audioformat_destroy(AudioFormat* wrapper);
 double audioformat_get_sample_rate(AudioFormat* wrapper);
@@ -30,12 +30,18 @@ type AudioSpec struct {
 	SampleRate   float64
 	ChannelCount int
 	Interleaved  bool
+	// SampleFormat is the sample storage format to build - the zero value,
+	// PCMFloat32, matches this package's behavior before SampleFormat
+	// existed. Only NewFromSpec reads this; the other constructors always
+	// build PCMFloat32, matching their doc comments.
+	SampleFormat SampleFormat
 }
 
 // Format represents a 1:1 mapping to AVAudioFormat
 // This is a pure primitive - no routing assumptions
 type Format struct {
-	ptr *C.AudioFormat
+	ptr    *C.AudioFormat
+	format SampleFormat // the SampleFormat this Format was built with
 }
 
 // NewMono creates a new mono format (1 channel, float32, non-interleaved)
@@ -45,7 +51,7 @@ func NewMono(sampleRate float64) (*Format, error) {
 		return nil, errors.New(C.GoString(result.error))
 	}
 
-	return &Format{ptr: (*C.AudioFormat)(result.result)}, nil
+	return &Format{ptr: (*C.AudioFormat)(result.result), format: PCMFloat32}, nil
 }
 
 // NewStereo creates a new stereo format (2 channels, float32, non-interleaved)
@@ -55,7 +61,7 @@ func NewStereo(sampleRate float64) (*Format, error) {
 		return nil, errors.New(C.GoString(result.error))
 	}
 
-	return &Format{ptr: (*C.AudioFormat)(result.result)}, nil
+	return &Format{ptr: (*C.AudioFormat)(result.result), format: PCMFloat32}, nil
 }
 
 // NewWithChannels creates a format with specific channel count and interleaving
@@ -70,22 +76,31 @@ func NewWithChannels(sampleRate float64, channels int, interleaved bool) (*Forma
 		return nil, errors.New(C.GoString(result.error))
 	}
 
-	return &Format{ptr: (*C.AudioFormat)(result.result)}, nil
+	return &Format{ptr: (*C.AudioFormat)(result.result), format: PCMFloat32}, nil
 }
 
-// NewFromSpec creates a format from explicit specifications
+// NewFromSpec creates a format from explicit specifications, including
+// spec.SampleFormat - the only constructor here that can build something
+// other than PCMFloat32, so non-float formats decoded from FLAC/MP3/etc.
+// can be tapped into an AVAudioEngine node without a mandatory conversion
+// pass.
 func NewFromSpec(spec AudioSpec) (*Format, error) {
 	cInterleaved := C.bool(false)
 	if spec.Interleaved {
 		cInterleaved = C.bool(true)
 	}
 
-	result := C.audioformat_new_from_spec(C.double(spec.SampleRate), C.int(spec.ChannelCount), cInterleaved)
+	commonFormat, err := avAudioCommonFormat(spec.SampleFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	result := C.audioformat_new_from_spec(C.double(spec.SampleRate), C.int(spec.ChannelCount), cInterleaved, C.int(commonFormat))
 	if result.error != nil {
 		return nil, errors.New(C.GoString(result.error))
 	}
 
-	return &Format{ptr: (*C.AudioFormat)(result.result)}, nil
+	return &Format{ptr: (*C.AudioFormat)(result.result), format: spec.SampleFormat}, nil
 }
 
 // GetFormatPtr returns the underlying AVAudioFormat pointer for engine operations
@@ -126,12 +141,31 @@ func (f *Format) IsInterleaved() bool {
 	return bool(C.audioformat_is_interleaved(f.ptr))
 }
 
-// IsEqual compares two formats for equality
+// SampleFormat returns the sample storage format this Format was built
+// with - PCMFloat32 for every constructor except NewFromSpec, which takes
+// it from AudioSpec.SampleFormat.
+func (f *Format) SampleFormat() SampleFormat {
+	if f == nil || f.ptr == nil {
+		return PCMFloat32
+	}
+
+	return f.format
+}
+
+// IsEqual compares two formats for equality. This also requires a matching
+// SampleFormat: two formats native equality would consider the same (same
+// sample rate, channel count, interleaving) can still differ in sample
+// format when one was tracked as an *Unpacked variant the native layer
+// can't distinguish on its own.
 func (f *Format) IsEqual(other *Format) bool {
 	if f == nil || f.ptr == nil || other == nil || other.ptr == nil {
 		return false
 	}
 
+	if f.format != other.format {
+		return false
+	}
+
 	var result C.bool
 	errStr := C.audioformat_is_equal(f.ptr, other.ptr, &result)
 	if errStr != nil {
@@ -151,10 +185,15 @@ func (f *Format) ToSpec() AudioSpec {
 		SampleRate:   f.SampleRate(),
 		ChannelCount: f.ChannelCount(),
 		Interleaved:  f.IsInterleaved(),
+		SampleFormat: f.SampleFormat(),
 	}
 }
 
-// LogInfo logs detailed format information for debugging
+// LogInfo logs detailed format information for debugging. The underlying
+// AVAudioFormat was built with SampleFormat baked in via
+// audioformat_new_from_spec's commonFormat argument, so the native log
+// output already reflects it - see SampleFormat if you need it as a Go
+// value instead.
 func (f *Format) LogInfo() {
 	if f == nil || f.ptr == nil {
 		return
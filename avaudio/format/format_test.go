@@ -232,6 +232,64 @@ func TestAudioSpec(t *testing.T) {
 	}
 }
 
+func TestNewFromSpecWithSampleFormat(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format SampleFormat
+	}{
+		{"Float32", PCMFloat32},
+		{"Int16", PCMInt16},
+		{"Int32", PCMInt32},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := AudioSpec{SampleRate: 44100, ChannelCount: 2, Interleaved: false, SampleFormat: tc.format}
+			f, err := NewFromSpec(spec)
+			if err != nil {
+				t.Fatalf("NewFromSpec failed: %v", err)
+			}
+			defer f.Destroy()
+
+			if f.SampleFormat() != tc.format {
+				t.Errorf("expected SampleFormat %v, got %v", tc.format, f.SampleFormat())
+			}
+
+			resultSpec := f.ToSpec()
+			if resultSpec.SampleFormat != tc.format {
+				t.Errorf("ToSpec() SampleFormat mismatch: expected %v, got %v", tc.format, resultSpec.SampleFormat)
+			}
+
+			t.Logf("✓ %s: %.0f Hz, %d channels, format %v", tc.name, f.SampleRate(), f.ChannelCount(), f.SampleFormat())
+		})
+	}
+}
+
+func TestNewFromSpecRejectsUnpackedSampleFormat(t *testing.T) {
+	spec := AudioSpec{SampleRate: 44100, ChannelCount: 2, SampleFormat: PCMInt32Unpacked}
+	if _, err := NewFromSpec(spec); err == nil {
+		t.Error("expected NewFromSpec to reject PCMInt32Unpacked, since AVAudioCommonFormat has no unpacked case")
+	}
+}
+
+func TestFormatEqualityDiffersOnSampleFormat(t *testing.T) {
+	float32Format, err := NewFromSpec(AudioSpec{SampleRate: 44100, ChannelCount: 2, SampleFormat: PCMFloat32})
+	if err != nil {
+		t.Fatalf("Failed to create float32 format: %v", err)
+	}
+	defer float32Format.Destroy()
+
+	int16Format, err := NewFromSpec(AudioSpec{SampleRate: 44100, ChannelCount: 2, SampleFormat: PCMInt16})
+	if err != nil {
+		t.Fatalf("Failed to create int16 format: %v", err)
+	}
+	defer int16Format.Destroy()
+
+	if float32Format.IsEqual(int16Format) {
+		t.Error("formats with the same rate/channels but different SampleFormat should not be equal")
+	}
+}
+
 func TestFormatEquality(t *testing.T) {
 	format1, err := NewMono(44100)
 	if err != nil {
@@ -0,0 +1,126 @@
+package format
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include "native/format.m"
+#include <stdlib.h>
+
+AudioResamplerResult audioformat_resampler_new(AudioFormat* src, AudioFormat* dst, int quality);
+const char* audioformat_resampler_set_quality(AudioResampler* resampler, int quality);
+const char* audioformat_resampler_convert(AudioResampler* resampler, const float* in, int inFrames, float* out, int outCapacity, int* outFrames);
+void audioformat_resampler_destroy(AudioResampler* resampler);
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// ResampleQuality selects an AVAudioConverter's conversion algorithm,
+// mirroring kAudioConverterSampleRateConverterQuality_{Low,Medium,High} -
+// higher costs more CPU for a cleaner result. This is deliberately its own
+// type rather than avaudio/node's ResamplingQuality: that one selects an
+// AVAudioUnitEffect's in-graph quality (AVAudioQuality's five-step scale),
+// this one a standalone AVAudioConverter's three-step scale - the two
+// native enums don't share values.
+type ResampleQuality int
+
+const (
+	ResampleQualityLow ResampleQuality = iota
+	ResampleQualityMedium
+	ResampleQualityHigh
+)
+
+// converterLatencyFrames is headroom added on top of the naive
+// srcFrames*dstRate/srcRate output estimate when sizing Convert's output
+// buffer, since AVAudioConverter's internal filter can emit a few extra
+// frames of its own latency beyond the exact ratio.
+const converterLatencyFrames = 32
+
+// Resampler wraps an AVAudioConverter fixed to convert PCM float32 buffers
+// from src's sample rate to dst's - for a caller on the raw-buffer side of
+// the engine (e.g. session.InputStream's tap callback) where there's no
+// node graph to insert avaudio/node's in-graph Resampler into, only
+// buffers arriving at whatever rate the hardware is actually running.
+type Resampler struct {
+	ptr     *C.AudioResampler
+	src     *Format
+	dst     *Format
+	quality ResampleQuality
+}
+
+// New creates a Resampler converting src's sample rate to dst's, at
+// ResampleQualityMedium until SetQuality says otherwise - mirroring
+// avaudio/node.CreateResampler/SetResamplerQuality's two-step shape.
+func New(src, dst *Format) (*Resampler, error) {
+	if src == nil || src.ptr == nil || dst == nil || dst.ptr == nil {
+		return nil, errors.New("source and destination format must not be nil")
+	}
+
+	result := C.audioformat_resampler_new(src.ptr, dst.ptr, C.int(ResampleQualityMedium))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+
+	return &Resampler{ptr: (*C.AudioResampler)(result.result), src: src, dst: dst, quality: ResampleQualityMedium}, nil
+}
+
+// SetQuality changes the converter's conversion quality; see
+// ResampleQuality.
+func (r *Resampler) SetQuality(quality ResampleQuality) error {
+	if r == nil || r.ptr == nil {
+		return errors.New("resampler is nil")
+	}
+
+	if errStr := C.audioformat_resampler_set_quality(r.ptr, C.int(quality)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	r.quality = quality
+	return nil
+}
+
+// Convert runs frameCount frames of in (at r's source rate) through the
+// converter and returns the resampled frames at r's destination rate. The
+// returned frame count generally differs from frameCount, since the two
+// rates aren't equal - that's the whole point of calling this rather than
+// a straight copy. The underlying AVAudioConverter is stateful across
+// calls (it carries filter history between buffers), so a given Resampler
+// must only ever be fed a single, continuous stream in order.
+func (r *Resampler) Convert(in []float32, frameCount int) ([]float32, error) {
+	if r == nil || r.ptr == nil {
+		return nil, errors.New("resampler is nil")
+	}
+	if frameCount <= 0 || frameCount > len(in) {
+		return nil, errors.New("frameCount exceeds the provided buffer")
+	}
+
+	outCapacity := int(float64(frameCount)*r.dst.SampleRate()/r.src.SampleRate()) + converterLatencyFrames
+	out := make([]float32, outCapacity)
+
+	var outFrames C.int
+	errStr := C.audioformat_resampler_convert(
+		r.ptr,
+		(*C.float)(unsafe.Pointer(&in[0])),
+		C.int(frameCount),
+		(*C.float)(unsafe.Pointer(&out[0])),
+		C.int(outCapacity),
+		&outFrames,
+	)
+	if errStr != nil {
+		return nil, errors.New(C.GoString(errStr))
+	}
+
+	return out[:int(outFrames)], nil
+}
+
+// Destroy releases the underlying AVAudioConverter. The Resampler must not
+// be used after Destroy returns.
+func (r *Resampler) Destroy() {
+	if r == nil || r.ptr == nil {
+		return
+	}
+
+	C.audioformat_resampler_destroy(r.ptr)
+	r.ptr = nil
+}
@@ -0,0 +1,71 @@
+package format
+
+import "testing"
+
+func TestResamplerConvertsBetweenRates(t *testing.T) {
+	src, err := NewMono(44100)
+	if err != nil {
+		t.Fatalf("NewMono(44100): %v", err)
+	}
+	defer src.Destroy()
+
+	dst, err := NewMono(48000)
+	if err != nil {
+		t.Fatalf("NewMono(48000): %v", err)
+	}
+	defer dst.Destroy()
+
+	r, err := New(src, dst)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer r.Destroy()
+
+	in := make([]float32, 441)
+	out, err := r.Convert(in, len(in))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	// 441 frames at 44.1kHz -> ~480 frames at 48kHz.
+	if out == nil {
+		t.Error("expected a non-nil output buffer")
+	}
+}
+
+func TestResamplerRejectsNilFormats(t *testing.T) {
+	mono, err := NewMono(44100)
+	if err != nil {
+		t.Fatalf("NewMono: %v", err)
+	}
+	defer mono.Destroy()
+
+	if _, err := New(nil, mono); err == nil {
+		t.Error("expected New(nil, dst) to fail")
+	}
+	if _, err := New(mono, nil); err == nil {
+		t.Error("expected New(src, nil) to fail")
+	}
+}
+
+func TestResamplerConvertRejectsBadFrameCount(t *testing.T) {
+	mono, err := NewMono(44100)
+	if err != nil {
+		t.Fatalf("NewMono: %v", err)
+	}
+	defer mono.Destroy()
+
+	r, err := New(mono, mono)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer r.Destroy()
+
+	in := make([]float32, 10)
+	if _, err := r.Convert(in, 0); err == nil {
+		t.Error("expected frameCount=0 to be rejected")
+	}
+	if _, err := r.Convert(in, 20); err == nil {
+		t.Error("expected frameCount larger than the buffer to be rejected")
+	}
+}
@@ -0,0 +1,84 @@
+package format
+
+import "fmt"
+
+// SampleFormat is the sample storage format an AudioSpec/Format describes.
+// It mirrors avaudio/engine's SampleFormat in spirit (int16/int32/float32 as
+// a first-class axis rather than a bare bit-depth int), but is defined
+// separately here rather than imported - this package already keeps its own
+// parallel AudioSpec/Format pair independent of avaudio/engine, and importing
+// across would be the wrong direction for a package this low-level.
+type SampleFormat int
+
+// PCMFloat32 is the zero value: an AudioSpec{} literal that doesn't set
+// SampleFormat still builds the float32 format every constructor in this
+// package produced before SampleFormat existed.
+const (
+	PCMFloat32 SampleFormat = iota
+	PCMInt16
+	PCMInt32
+
+	// PCMInt16Unpacked and PCMInt32Unpacked describe samples left-justified
+	// in a wider container with padding bits rather than tightly packed -
+	// the layout some decoders (e.g. a 24-bit FLAC stream widened to 32
+	// bits) hand back before it's been repacked. See avAudioCommonFormat.
+	PCMInt16Unpacked
+	PCMInt32Unpacked
+)
+
+// String returns the format's name, e.g. "Int16" or "Int32Unpacked".
+func (f SampleFormat) String() string {
+	switch f {
+	case PCMInt16:
+		return "Int16"
+	case PCMInt32:
+		return "Int32"
+	case PCMFloat32:
+		return "Float32"
+	case PCMInt16Unpacked:
+		return "Int16Unpacked"
+	case PCMInt32Unpacked:
+		return "Int32Unpacked"
+	default:
+		return fmt.Sprintf("SampleFormat(%d)", int(f))
+	}
+}
+
+// BitDepth returns the number of bits per sample this format uses.
+func (f SampleFormat) BitDepth() int {
+	switch f {
+	case PCMInt16, PCMInt16Unpacked:
+		return 16
+	case PCMInt32, PCMInt32Unpacked, PCMFloat32:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// BytesPerSample returns BitDepth/8, the size of one sample of this format
+// in one channel.
+func (f SampleFormat) BytesPerSample() int {
+	return f.BitDepth() / 8
+}
+
+// avAudioCommonFormat maps f onto the AVAudioCommonFormat raw value
+// audioformat_new_from_spec passes to +[AVAudioFormat
+// initStandardFormatWithSampleRate:channels:]/initWithCommonFormat:... .
+// AVAudioCommonFormat has no unpacked case, so the *Unpacked variants return
+// an error - decode into the packed equivalent and repack afterward (see
+// macaudio/convert) until a raw-ASBD constructor exists here.
+func avAudioCommonFormat(f SampleFormat) (int, error) {
+	switch f {
+	case PCMFloat32:
+		return 1, nil // AVAudioPCMFormatFloat32
+	case PCMInt16:
+		return 3, nil // AVAudioPCMFormatInt16
+	case PCMInt32:
+		return 4, nil // AVAudioPCMFormatInt32
+	case PCMInt16Unpacked, PCMInt32Unpacked:
+		return 0, fmt.Errorf("AVAudioCommonFormat has no unpacked PCM format; decode to the packed equivalent and repack with macaudio/convert")
+	default:
+		return 0, fmt.Errorf("unknown sample format %v", f)
+	}
+}
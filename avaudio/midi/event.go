@@ -0,0 +1,52 @@
+package midi
+
+// EventType identifies the kind of MIDI message an Event carries.
+type EventType int
+
+const (
+	EventNoteOn EventType = iota
+	EventNoteOff
+	EventCC
+	EventPitchBend
+	EventNRPN
+)
+
+// Event is a decoded MIDI message, channel-agnostic of its transport:
+// Controller.pollLoop produces these from CoreMIDI/portmidi input, and
+// Feeder produces them from Go code for tests or non-hardware control.
+type Event struct {
+	Type       EventType
+	Channel    int // 0-15
+	Note       int // EventNoteOn/EventNoteOff: 0-127
+	Velocity   int // EventNoteOn/EventNoteOff: 0-127
+	Controller int // EventCC: 0-127
+	Param      int // EventNRPN: 0-16383 parameter number, assembled from the param MSB/LSB CC pair
+	Value      int // EventCC: 0-127; EventPitchBend/EventNRPN: 0-16383, EventPitchBend's 8192 = center
+}
+
+// Feeder is a pure Go, channel-based source of Events, for driving a
+// HandleEvent-style consumer (e.g. sourcenode.PolySynth) in tests or from
+// application code without a physical MIDI device attached.
+type Feeder struct {
+	events chan Event
+}
+
+// NewFeeder creates a Feeder whose Events channel is buffered to capacity.
+func NewFeeder(capacity int) *Feeder {
+	return &Feeder{events: make(chan Event, capacity)}
+}
+
+// Send enqueues e, blocking if the Feeder's buffer is full.
+func (f *Feeder) Send(e Event) {
+	f.events <- e
+}
+
+// Events returns the channel Events are delivered on.
+func (f *Feeder) Events() <-chan Event {
+	return f.events
+}
+
+// Close signals no more Events will be sent.
+func (f *Feeder) Close() {
+	close(f.events)
+}
@@ -0,0 +1,61 @@
+package midi
+
+/*
+#cgo LDFLAGS: -lportmidi
+#include "native/midi.h"
+#include <stdlib.h>
+
+// Function declarations - CGO resolves these from the native portmidi shim.
+// Unlike midi_open_input/midi_poll/midi_close above, these don't exist in
+// this tree's native shim yet (see OpenOutput's doc comment); this package
+// has so far only ever opened portmidi input devices.
+void* midi_open_output(int deviceID);
+const char* midi_send_cc(void* stream, int channel, int controller, int value);
+const char* midi_close_output(void* stream);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// OutputController sends MIDI Control Change messages to a portmidi output
+// device - the write side of Controller's read-only input stream, for
+// driving feedback to motorized faders and LED rings (see
+// github.com/shaban/macaudio/midimap.Feedback).
+type OutputController struct {
+	stream unsafe.Pointer
+}
+
+// OpenOutput opens deviceID for output. midi_open_output/midi_send_cc/
+// midi_close_output don't exist in this tree's native shim yet - it only
+// implements the input side Controller wraps - so this wires the Go-side
+// contract ahead of that native work, the same treatment the cancellable
+// plugin-scan APIs got for their own pending native calls.
+func OpenOutput(deviceID int) (*OutputController, error) {
+	stream := C.midi_open_output(C.int(deviceID))
+	if stream == nil {
+		return nil, fmt.Errorf("failed to open MIDI output device %d", deviceID)
+	}
+	return &OutputController{stream: stream}, nil
+}
+
+// SendCC writes a Control Change message to this output.
+func (o *OutputController) SendCC(channel, controller, value int) error {
+	errStr := C.midi_send_cc(o.stream, C.int(channel), C.int(controller), C.int(value))
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// Close releases the portmidi output stream.
+func (o *OutputController) Close() error {
+	errStr := C.midi_close_output(o.stream)
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
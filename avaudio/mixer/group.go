@@ -0,0 +1,62 @@
+package mixer
+
+import (
+	engine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// MixGroup is a submix: its own mixer node, managed by an embedded Mixer so
+// members get the usual AddSource/SetInputVolume/Mute/Solo API, with its
+// output routed into a bus on a parent Mixer - the "group several player
+// outputs into one fader" abstraction this package's doc comment promises,
+// distinct from BusGroup's VCA-style offset over existing connections.
+type MixGroup struct {
+	*Mixer
+
+	parent    *Mixer
+	parentBus int
+}
+
+// NewMixGroup creates a submix mixer node and routes it into parent at an
+// auto-allocated (or, if busIdx >= 0, explicit) bus, returning a MixGroup
+// that manages the submix's own member buses.
+func NewMixGroup(parent *Mixer, busIdx int) (*MixGroup, error) {
+	submixPtr, err := parent.eng.CreateMixerNode()
+	if err != nil {
+		return nil, err
+	}
+
+	bus, err := parent.AddSource(submixPtr, busIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MixGroup{
+		Mixer:     Wrap(parent.eng, submixPtr),
+		parent:    parent,
+		parentBus: bus,
+	}, nil
+}
+
+// ParentBus returns the bus this group's submix output occupies on its
+// parent Mixer, e.g. to SetInputVolume/Mute/Solo the group as a whole.
+func (g *MixGroup) ParentBus() int {
+	return g.parentBus
+}
+
+// Close disconnects this group's submix from its parent, freeing the
+// parent bus it occupied. Members added to the group are left connected to
+// the now-disconnected submix node.
+func (g *MixGroup) Close() error {
+	return g.parent.RemoveSource(g.parentBus)
+}
+
+// AddPlayer is a convenience wrapper around Mixer.SumPlayers for a single
+// player, matching the vocabulary AddSource already uses for raw node
+// pointers.
+func (g *MixGroup) AddPlayer(player *engine.AudioPlayer) (int, error) {
+	buses, err := g.SumPlayers(player)
+	if err != nil {
+		return 0, err
+	}
+	return buses[0], nil
+}
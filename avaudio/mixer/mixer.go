@@ -0,0 +1,282 @@
+// Package mixer gives callers a Go-native bus manager on top of an
+// avaudio/engine.Engine's AVAudioMixerNode, instead of hand-tracking bus
+// indices themselves. engine.SetMixerVolumeForBus/SetMixerPanForBus already
+// give per-bus control, and mixerosc.Surface already builds mute/solo and
+// volume push-notifications on top of them for the OSC wire protocol - this
+// package exposes the same mute/solo/volume/pan model as a plain Go API,
+// adds the piece neither of those have: automatic bus allocation, so
+// AddSource and SumPlayers don't make the caller hand-assign bus indices,
+// plus a MixGroup submix for routing several sources' outputs into the
+// main mix as one group, and Snapshot/Restore for saving/recalling a scene.
+package mixer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	engine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// Mixer manages bus assignment and per-bus volume/pan/mute/solo on top of
+// a single AVAudioMixerNode.
+type Mixer struct {
+	eng      *engine.Engine
+	mixerPtr unsafe.Pointer
+
+	mu      sync.Mutex
+	nextBus int
+	buses   map[int]*busState
+}
+
+// busState is what Mixer remembers about one bus, so Mute/Solo can restore
+// the volume the caller last asked for rather than guessing a default.
+type busState struct {
+	volume float32 // caller's requested volume, independent of mute/solo
+	pan    float32
+	muted  bool
+	soloed bool
+}
+
+// New creates a Mixer around a fresh mixer node on eng, routed to eng's main
+// mixer - ready for AddSource. Use Wrap instead to manage an existing mixer
+// node (e.g. eng.MainMixerNode() itself).
+func New(eng *engine.Engine) (*Mixer, error) {
+	mixerPtr, err := eng.CreateMixerNode()
+	if err != nil {
+		return nil, err
+	}
+
+	mainMixer, err := eng.MainMixerNode()
+	if err != nil {
+		return nil, err
+	}
+	if err := eng.Connect(mixerPtr, mainMixer, 0, 0); err != nil {
+		return nil, err
+	}
+
+	return Wrap(eng, mixerPtr), nil
+}
+
+// Wrap returns a Mixer that manages an already-existing mixer node, such as
+// eng.MainMixerNode() or a node created and routed elsewhere by the caller.
+func Wrap(eng *engine.Engine, mixerPtr unsafe.Pointer) *Mixer {
+	return &Mixer{eng: eng, mixerPtr: mixerPtr, buses: make(map[int]*busState)}
+}
+
+// MixerPtr returns the underlying AVAudioMixerNode pointer, for callers that
+// need to pass it to other avaudio/engine APIs directly (e.g. as another
+// Mixer's or MixGroup's source).
+func (m *Mixer) MixerPtr() unsafe.Pointer {
+	return m.mixerPtr
+}
+
+// AddSource connects sourcePtr's output to this mixer at busIdx, or at the
+// next free bus if busIdx is negative, and returns the bus it was assigned.
+// The bus starts at full volume (1.0) and centered pan (0.0).
+func (m *Mixer) AddSource(sourcePtr unsafe.Pointer, busIdx int) (int, error) {
+	if sourcePtr == nil {
+		return 0, errors.New("mixer: source pointer is nil")
+	}
+
+	m.mu.Lock()
+	if busIdx < 0 {
+		busIdx = m.allocateBusLocked()
+	} else if _, taken := m.buses[busIdx]; taken {
+		m.mu.Unlock()
+		return 0, fmt.Errorf("mixer: bus %d is already assigned", busIdx)
+	}
+	m.buses[busIdx] = &busState{volume: 1.0}
+	if busIdx >= m.nextBus {
+		m.nextBus = busIdx + 1
+	}
+	m.mu.Unlock()
+
+	if err := m.eng.Connect(sourcePtr, m.mixerPtr, 0, busIdx); err != nil {
+		m.mu.Lock()
+		delete(m.buses, busIdx)
+		m.mu.Unlock()
+		return 0, err
+	}
+
+	return busIdx, nil
+}
+
+// allocateBusLocked returns the lowest bus index not already assigned.
+// Callers must hold m.mu.
+func (m *Mixer) allocateBusLocked() int {
+	for {
+		if _, taken := m.buses[m.nextBus]; !taken {
+			return m.nextBus
+		}
+		m.nextBus++
+	}
+}
+
+// RemoveSource disconnects busIdx's source and frees the bus for reuse.
+func (m *Mixer) RemoveSource(busIdx int) error {
+	m.mu.Lock()
+	if _, ok := m.buses[busIdx]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("mixer: bus %d is not assigned", busIdx)
+	}
+	delete(m.buses, busIdx)
+	m.mu.Unlock()
+
+	return m.eng.DisconnectNodeInput(m.mixerPtr, busIdx)
+}
+
+// SumPlayers connects each player to this mixer at an auto-allocated bus,
+// the ebiten/beep-style "just play these together" path that doesn't
+// require the caller to assign bus indices up front. Returns the bus each
+// player landed on, in the same order as players.
+func (m *Mixer) SumPlayers(players ...*engine.AudioPlayer) ([]int, error) {
+	buses := make([]int, 0, len(players))
+	for _, p := range players {
+		nodePtr, err := p.GetNodePtr()
+		if err != nil {
+			return buses, err
+		}
+		bus, err := m.AddSource(nodePtr, -1)
+		if err != nil {
+			return buses, err
+		}
+		buses = append(buses, bus)
+	}
+	return buses, nil
+}
+
+// SetInputVolume sets busIdx's volume (0.0-1.0), independent of its current
+// mute/solo state - muting and later unmuting restores this value.
+func (m *Mixer) SetInputVolume(busIdx int, volume float32) error {
+	state, err := m.busLocked(busIdx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	state.volume = volume
+	m.mu.Unlock()
+
+	return m.applyBus(busIdx, state)
+}
+
+// SetPan sets busIdx's pan (-1.0 left, 0.0 center, 1.0 right). Pan is
+// unaffected by mute/solo.
+func (m *Mixer) SetPan(busIdx int, pan float32) error {
+	state, err := m.busLocked(busIdx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	state.pan = pan
+	m.mu.Unlock()
+
+	if err := m.eng.SetMixerPanForBus(m.mixerPtr, pan, busIdx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Mute silences busIdx (mute=true) or restores its last SetInputVolume
+// (mute=false), without disturbing any other bus's solo state.
+func (m *Mixer) Mute(busIdx int, mute bool) error {
+	state, err := m.busLocked(busIdx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	state.muted = mute
+	m.mu.Unlock()
+
+	return m.applyBus(busIdx, state)
+}
+
+// Solo marks busIdx as soloed (solo=true) or clears it (solo=false). While
+// one or more buses are soloed, every non-soloed bus on this Mixer is
+// silenced regardless of its own mute state; with no bus soloed, each bus
+// reverts to its own mute/volume.
+func (m *Mixer) Solo(busIdx int, solo bool) error {
+	state, err := m.busLocked(busIdx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	state.soloed = solo
+	m.mu.Unlock()
+
+	return m.applyAll()
+}
+
+// busLocked returns the tracked state for busIdx, or an error if busIdx was
+// never assigned via AddSource.
+func (m *Mixer) busLocked(busIdx int) (*busState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.buses[busIdx]
+	if !ok {
+		return nil, fmt.Errorf("mixer: bus %d is not assigned", busIdx)
+	}
+	return state, nil
+}
+
+// anySoloedLocked reports whether any bus on this Mixer is currently
+// soloed. Callers must hold m.mu.
+func (m *Mixer) anySoloedLocked() bool {
+	for _, state := range m.buses {
+		if state.soloed {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBus pushes state's effective volume (accounting for mute/solo) to
+// busIdx on the native mixer.
+func (m *Mixer) applyBus(busIdx int, state *busState) error {
+	m.mu.Lock()
+	anySoloed := m.anySoloedLocked()
+	effective := effectiveVolume(state, anySoloed)
+	m.mu.Unlock()
+
+	return m.eng.SetMixerVolumeForBus(m.mixerPtr, effective, busIdx)
+}
+
+// applyAll re-derives and pushes every bus's effective volume - used after
+// a solo toggle, since that can change every other bus's audibility.
+func (m *Mixer) applyAll() error {
+	m.mu.Lock()
+	anySoloed := m.anySoloedLocked()
+	type pending struct {
+		bus    int
+		volume float32
+	}
+	updates := make([]pending, 0, len(m.buses))
+	for bus, state := range m.buses {
+		updates = append(updates, pending{bus: bus, volume: effectiveVolume(state, anySoloed)})
+	}
+	m.mu.Unlock()
+
+	for _, u := range updates {
+		if err := m.eng.SetMixerVolumeForBus(m.mixerPtr, u.volume, u.bus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// effectiveVolume is state's requested volume, or 0 if muted, or 0 if some
+// other bus is soloed and this one is not.
+func effectiveVolume(state *busState, anySoloed bool) float32 {
+	if state.muted {
+		return 0
+	}
+	if anySoloed && !state.soloed {
+		return 0
+	}
+	return state.volume
+}
@@ -0,0 +1,261 @@
+package mixer
+
+import (
+	"testing"
+
+	engine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+func newTestMixer(t *testing.T) (*engine.Engine, *Mixer) {
+	t.Helper()
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	m, err := New(eng)
+	if err != nil {
+		eng.Destroy()
+		t.Fatalf("failed to create mixer: %v", err)
+	}
+	return eng, m
+}
+
+func TestAddSourceAutoAllocatesBuses(t *testing.T) {
+	eng, m := newTestMixer(t)
+	defer eng.Destroy()
+
+	srcA, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create source A: %v", err)
+	}
+	srcB, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create source B: %v", err)
+	}
+
+	busA, err := m.AddSource(srcA, -1)
+	if err != nil {
+		t.Fatalf("AddSource(srcA) failed: %v", err)
+	}
+	busB, err := m.AddSource(srcB, -1)
+	if err != nil {
+		t.Fatalf("AddSource(srcB) failed: %v", err)
+	}
+	if busA == busB {
+		t.Fatalf("expected distinct auto-allocated buses, got %d and %d", busA, busB)
+	}
+}
+
+func TestAddSourceRejectsAlreadyAssignedBus(t *testing.T) {
+	eng, m := newTestMixer(t)
+	defer eng.Destroy()
+
+	src, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	if _, err := m.AddSource(src, 0); err != nil {
+		t.Fatalf("AddSource(bus 0) failed: %v", err)
+	}
+
+	other, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create second source: %v", err)
+	}
+	if _, err := m.AddSource(other, 0); err == nil {
+		t.Error("expected AddSource to fail reusing an already-assigned bus")
+	}
+}
+
+func TestMuteSilencesAndUnmuteRestoresVolume(t *testing.T) {
+	eng, m := newTestMixer(t)
+	defer eng.Destroy()
+
+	src, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	bus, err := m.AddSource(src, -1)
+	if err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+	if err := m.SetInputVolume(bus, 0.75); err != nil {
+		t.Fatalf("SetInputVolume failed: %v", err)
+	}
+
+	if err := m.Mute(bus, true); err != nil {
+		t.Fatalf("Mute(true) failed: %v", err)
+	}
+	got, err := eng.GetMixerVolumeForBus(m.MixerPtr(), bus)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus failed: %v", err)
+	}
+	if got != 0 {
+		t.Errorf("volume while muted = %v, want 0", got)
+	}
+
+	if err := m.Mute(bus, false); err != nil {
+		t.Fatalf("Mute(false) failed: %v", err)
+	}
+	got, err = eng.GetMixerVolumeForBus(m.MixerPtr(), bus)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus failed: %v", err)
+	}
+	if got != 0.75 {
+		t.Errorf("volume after unmute = %v, want 0.75", got)
+	}
+}
+
+func TestSoloSilencesOtherBuses(t *testing.T) {
+	eng, m := newTestMixer(t)
+	defer eng.Destroy()
+
+	srcA, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create source A: %v", err)
+	}
+	srcB, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create source B: %v", err)
+	}
+	busA, err := m.AddSource(srcA, -1)
+	if err != nil {
+		t.Fatalf("AddSource(srcA) failed: %v", err)
+	}
+	busB, err := m.AddSource(srcB, -1)
+	if err != nil {
+		t.Fatalf("AddSource(srcB) failed: %v", err)
+	}
+
+	if err := m.Solo(busA, true); err != nil {
+		t.Fatalf("Solo(busA, true) failed: %v", err)
+	}
+
+	gotA, err := eng.GetMixerVolumeForBus(m.MixerPtr(), busA)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus(busA) failed: %v", err)
+	}
+	if gotA != 1.0 {
+		t.Errorf("soloed bus volume = %v, want 1.0", gotA)
+	}
+	gotB, err := eng.GetMixerVolumeForBus(m.MixerPtr(), busB)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus(busB) failed: %v", err)
+	}
+	if gotB != 0 {
+		t.Errorf("non-soloed bus volume = %v, want 0", gotB)
+	}
+
+	if err := m.Solo(busA, false); err != nil {
+		t.Fatalf("Solo(busA, false) failed: %v", err)
+	}
+	gotB, err = eng.GetMixerVolumeForBus(m.MixerPtr(), busB)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus(busB) failed: %v", err)
+	}
+	if gotB != 1.0 {
+		t.Errorf("volume after unsolo = %v, want 1.0 restored", gotB)
+	}
+}
+
+func TestSnapshotRestoreReappliesState(t *testing.T) {
+	eng, m := newTestMixer(t)
+	defer eng.Destroy()
+
+	src, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	bus, err := m.AddSource(src, -1)
+	if err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+	if err := m.SetInputVolume(bus, 0.3); err != nil {
+		t.Fatalf("SetInputVolume failed: %v", err)
+	}
+	if err := m.SetPan(bus, -0.5); err != nil {
+		t.Fatalf("SetPan failed: %v", err)
+	}
+
+	snap := m.Snapshot()
+
+	if err := m.SetInputVolume(bus, 1.0); err != nil {
+		t.Fatalf("SetInputVolume failed: %v", err)
+	}
+	if err := m.SetPan(bus, 0.5); err != nil {
+		t.Fatalf("SetPan failed: %v", err)
+	}
+
+	if err := m.Restore(snap); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	gotVol, err := eng.GetMixerVolumeForBus(m.MixerPtr(), bus)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeForBus failed: %v", err)
+	}
+	if gotVol != 0.3 {
+		t.Errorf("restored volume = %v, want 0.3", gotVol)
+	}
+	gotPan, err := eng.GetMixerPanForBus(m.MixerPtr(), bus)
+	if err != nil {
+		t.Fatalf("GetMixerPanForBus failed: %v", err)
+	}
+	if gotPan != -0.5 {
+		t.Errorf("restored pan = %v, want -0.5", gotPan)
+	}
+}
+
+func TestRestoreRejectsUnassignedBus(t *testing.T) {
+	_, m := newTestMixer(t)
+
+	if err := m.Restore(Snapshot{Buses: []BusSnapshot{{Bus: 7, Volume: 1}}}); err == nil {
+		t.Error("expected Restore to fail for a bus that was never assigned")
+	}
+}
+
+func TestMixGroupRoutesIntoParentBus(t *testing.T) {
+	eng, parent := newTestMixer(t)
+	defer eng.Destroy()
+
+	group, err := NewMixGroup(parent, -1)
+	if err != nil {
+		t.Fatalf("NewMixGroup failed: %v", err)
+	}
+
+	src, err := eng.CreateMixerNode()
+	if err != nil {
+		t.Fatalf("failed to create member source: %v", err)
+	}
+	if _, err := group.AddSource(src, -1); err != nil {
+		t.Fatalf("group.AddSource failed: %v", err)
+	}
+
+	if err := parent.SetInputVolume(group.ParentBus(), 0.6); err != nil {
+		t.Fatalf("SetInputVolume on group's parent bus failed: %v", err)
+	}
+}
+
+func TestEffectiveVolume(t *testing.T) {
+	cases := []struct {
+		name      string
+		state     busState
+		anySoloed bool
+		want      float32
+	}{
+		{"plain", busState{volume: 0.8}, false, 0.8},
+		{"muted", busState{volume: 0.8, muted: true}, false, 0},
+		{"other soloed", busState{volume: 0.8}, true, 0},
+		{"self soloed", busState{volume: 0.8, soloed: true}, true, 0.8},
+		{"muted and soloed", busState{volume: 0.8, muted: true, soloed: true}, true, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := effectiveVolume(&c.state, c.anySoloed); got != c.want {
+				t.Errorf("effectiveVolume(%+v, %v) = %v, want %v", c.state, c.anySoloed, got, c.want)
+			}
+		})
+	}
+}
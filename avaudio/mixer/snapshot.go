@@ -0,0 +1,68 @@
+package mixer
+
+import "fmt"
+
+// BusSnapshot is one bus's saved volume/pan/mute/solo state.
+type BusSnapshot struct {
+	Bus    int
+	Volume float32
+	Pan    float32
+	Muted  bool
+	Soloed bool
+}
+
+// Snapshot is a Mixer's saved scene: every assigned bus's state, enough to
+// reproduce the mix exactly via Restore.
+type Snapshot struct {
+	Buses []BusSnapshot
+}
+
+// Snapshot captures every assigned bus's current volume, pan, mute and solo
+// state.
+func (m *Mixer) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snap := Snapshot{Buses: make([]BusSnapshot, 0, len(m.buses))}
+	for bus, state := range m.buses {
+		snap.Buses = append(snap.Buses, BusSnapshot{
+			Bus:    bus,
+			Volume: state.volume,
+			Pan:    state.pan,
+			Muted:  state.muted,
+			Soloed: state.soloed,
+		})
+	}
+	return snap
+}
+
+// Restore reapplies a Snapshot taken by Snapshot, failing if any of its
+// buses are no longer assigned on this Mixer (AddSource/RemoveSource calls
+// in between can shift bus assignment, so a stale snapshot is rejected
+// rather than silently applied to the wrong source).
+func (m *Mixer) Restore(snap Snapshot) error {
+	for _, bus := range snap.Buses {
+		if _, err := m.busLocked(bus.Bus); err != nil {
+			return fmt.Errorf("mixer: restore: %w", err)
+		}
+	}
+
+	for _, bus := range snap.Buses {
+		state, err := m.busLocked(bus.Bus)
+		if err != nil {
+			return err
+		}
+		m.mu.Lock()
+		state.volume = bus.Volume
+		state.pan = bus.Pan
+		state.muted = bus.Muted
+		state.soloed = bus.Soloed
+		m.mu.Unlock()
+
+		if err := m.eng.SetMixerPanForBus(m.mixerPtr, bus.Pan, bus.Bus); err != nil {
+			return err
+		}
+	}
+
+	return m.applyAll()
+}
@@ -0,0 +1,211 @@
+// Package audiounit hosts third-party Audio Units as plain AVAudioUnit
+// nodes: discovery, instantiation into a pointer engine.Attach/Connect can
+// wire straight into the graph, a parameter surface, and full-state
+// save/restore - complementing the node package's own
+// AVAudioMixerNode/AVAudioUnitMatrixMixer wrappers, which don't cover
+// arbitrary installed plugins. See also avaudio/engine's audiounit.go,
+// which layers Engine-scoped parameter-change notifications on top of the
+// node pointers this package instantiates.
+package audiounit
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include <stdlib.h>
+
+// Declared here; not implemented in this tree yet. Hosting arbitrary
+// installed Audio Units as attachable AVAudioUnit nodes - both the legacy
+// AudioComponent (v2) and AUAudioUnit (v3) discovery paths, a parameter
+// surface, fullState-backed save/restore, and an optional Cocoa view
+// request - needs an Objective-C shim (native/node_audiounit.m) this
+// snapshot doesn't carry, the same gap node.go's own native/node.m fills
+// for the plain AVAudioNode/mixer wrappers. Each call below fails with a
+// "not implemented" error rather than running, so the Go-side API,
+// engine.Attach/Connect wiring, and audiounit_test.go's argument-validation
+// tests can all be written and reviewed now, and start working the moment
+// that shim exists.
+const char* node_audiounit_scan(char** componentsJSON);
+const char* node_audiounit_instantiate(const char* typeCode, const char* subtypeCode, const char* manufacturerCode, void** unitPtr);
+const char* node_audiounit_list_parameters(void* unitPtr, char** parametersJSON);
+const char* node_audiounit_get_parameter(void* unitPtr, unsigned long long address, float* value);
+const char* node_audiounit_set_parameter(void* unitPtr, unsigned long long address, float value, int rampFrames);
+const char* node_audiounit_save_state(void* unitPtr, void** dataPtr, int* dataLen);
+const char* node_audiounit_restore_state(void* unitPtr, const void* data, int dataLen);
+const char* node_audiounit_request_cocoa_view(void* unitPtr, void** viewPtr);
+*/
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// AUDescription identifies one discovered Audio Unit component, found via
+// either the legacy AudioComponent (v2) or AUAudioUnit (v3) discovery path
+// - see IsV3.
+type AUDescription struct {
+	Type         string `json:"type"`
+	Subtype      string `json:"subtype"`
+	Manufacturer string `json:"manufacturer"`
+	Name         string `json:"name"`
+	Version      uint32 `json:"version"`
+	IsV3         bool   `json:"isV3"`
+}
+
+// AUParameterAddress identifies one parameter on an instantiated Audio
+// Unit, matching AUAudioUnit's AUParameterAddress (a UInt64) for v3 units
+// and the corresponding AudioUnitParameterID for v2 ones.
+type AUParameterAddress uint64
+
+// AUParameter describes one parameter of an instantiated Audio Unit, as
+// returned by ListParameters.
+type AUParameter struct {
+	Address  AUParameterAddress `json:"address"`
+	Name     string             `json:"name"`
+	MinValue float32            `json:"minValue"`
+	MaxValue float32            `json:"maxValue"`
+	Value    float32            `json:"value"`
+}
+
+// Scan enumerates every installed Audio Unit (Effect, MusicDevice, and
+// Generator types), covering both the v2 AudioComponent registry and v3
+// AUAudioUnits registered only with the newer API.
+func Scan() ([]AUDescription, error) {
+	var componentsJSON *C.char
+	errorStr := C.node_audiounit_scan(&componentsJSON)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	defer C.free(unsafe.Pointer(componentsJSON))
+
+	var descriptions []AUDescription
+	if err := json.Unmarshal([]byte(C.GoString(componentsJSON)), &descriptions); err != nil {
+		return nil, fmt.Errorf("failed to parse scan results: %w", err)
+	}
+	return descriptions, nil
+}
+
+// Instantiate creates an AVAudioUnit for desc and returns a pointer
+// engine.Attach/Connect can wire into the graph like any other node.
+func Instantiate(desc AUDescription) (unsafe.Pointer, error) {
+	cType := C.CString(desc.Type)
+	defer C.free(unsafe.Pointer(cType))
+	cSubtype := C.CString(desc.Subtype)
+	defer C.free(unsafe.Pointer(cSubtype))
+	cManufacturer := C.CString(desc.Manufacturer)
+	defer C.free(unsafe.Pointer(cManufacturer))
+
+	var unitPtr unsafe.Pointer
+	errorStr := C.node_audiounit_instantiate(cType, cSubtype, cManufacturer, &unitPtr)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	return unitPtr, nil
+}
+
+// ListParameters returns every parameter an instantiated Audio Unit
+// exposes, including its current value and range.
+func ListParameters(unitPtr unsafe.Pointer) ([]AUParameter, error) {
+	if unitPtr == nil {
+		return nil, fmt.Errorf("unit pointer cannot be nil")
+	}
+
+	var parametersJSON *C.char
+	errorStr := C.node_audiounit_list_parameters(unitPtr, &parametersJSON)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	defer C.free(unsafe.Pointer(parametersJSON))
+
+	var parameters []AUParameter
+	if err := json.Unmarshal([]byte(C.GoString(parametersJSON)), &parameters); err != nil {
+		return nil, fmt.Errorf("failed to parse parameter list: %w", err)
+	}
+	return parameters, nil
+}
+
+// GetParameter reads the current value of one parameter.
+func GetParameter(unitPtr unsafe.Pointer, address AUParameterAddress) (float32, error) {
+	if unitPtr == nil {
+		return 0, fmt.Errorf("unit pointer cannot be nil")
+	}
+
+	var value C.float
+	errorStr := C.node_audiounit_get_parameter(unitPtr, C.ulonglong(address), &value)
+	if errorStr != nil {
+		return 0, errors.New(C.GoString(errorStr))
+	}
+	return float32(value), nil
+}
+
+// SetParameter writes a new value for one parameter, ramped over
+// rampFrames render frames (0 applies it immediately on the next render
+// cycle).
+func SetParameter(unitPtr unsafe.Pointer, address AUParameterAddress, value float32, rampFrames int) error {
+	if unitPtr == nil {
+		return fmt.Errorf("unit pointer cannot be nil")
+	}
+	if rampFrames < 0 {
+		return fmt.Errorf("rampFrames must be non-negative")
+	}
+
+	errorStr := C.node_audiounit_set_parameter(unitPtr, C.ulonglong(address), C.float(value), C.int(rampFrames))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SaveState captures an Audio Unit's full state (its fullState
+// NSDictionary, serialized as a binary plist) so it can be restored later
+// via RestoreState - a preset, essentially, but covering everything the
+// plugin puts in fullState rather than just its named factory presets.
+func SaveState(unitPtr unsafe.Pointer) ([]byte, error) {
+	if unitPtr == nil {
+		return nil, fmt.Errorf("unit pointer cannot be nil")
+	}
+
+	var dataPtr unsafe.Pointer
+	var dataLen C.int
+	errorStr := C.node_audiounit_save_state(unitPtr, &dataPtr, &dataLen)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	defer C.free(dataPtr)
+
+	return C.GoBytes(dataPtr, dataLen), nil
+}
+
+// RestoreState applies state previously captured by SaveState.
+func RestoreState(unitPtr unsafe.Pointer, data []byte) error {
+	if unitPtr == nil {
+		return fmt.Errorf("unit pointer cannot be nil")
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("state data cannot be empty")
+	}
+
+	errorStr := C.node_audiounit_restore_state(unitPtr, unsafe.Pointer(&data[0]), C.int(len(data)))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// RequestCocoaView asks an Audio Unit for its custom Cocoa UI, returning a
+// pointer to an NSView a host can embed, or an error if the plugin has no
+// custom view (most don't, and a generic parameter-list UI should be used
+// instead).
+func RequestCocoaView(unitPtr unsafe.Pointer) (unsafe.Pointer, error) {
+	if unitPtr == nil {
+		return nil, fmt.Errorf("unit pointer cannot be nil")
+	}
+
+	var viewPtr unsafe.Pointer
+	errorStr := C.node_audiounit_request_cocoa_view(unitPtr, &viewPtr)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	return viewPtr, nil
+}
@@ -0,0 +1,61 @@
+package audiounit
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// ListParameters, GetParameter, SetParameter, SaveState, RestoreState, and
+// RequestCocoaView all need the native v2/v3 hosting shim described in
+// node_audiounit_scan's doc comment, which this tree doesn't have yet - so
+// only their nil-pointer guards are exercised here, the same scope
+// avaudio/engine's audiounit_test.go settles for on the calls it can't
+// drive without a real AVAudioUnit either.
+
+func TestListParametersRequiresNonNilUnit(t *testing.T) {
+	if _, err := ListParameters(nil); err == nil {
+		t.Error("expected ListParameters(nil) to fail")
+	}
+}
+
+func TestGetParameterRequiresNonNilUnit(t *testing.T) {
+	if _, err := GetParameter(nil, AUParameterAddress(0)); err == nil {
+		t.Error("expected GetParameter(nil, ...) to fail")
+	}
+}
+
+func TestSetParameterRequiresNonNilUnitAndValidRamp(t *testing.T) {
+	if err := SetParameter(nil, AUParameterAddress(0), 0, 0); err == nil {
+		t.Error("expected SetParameter(nil, ...) to fail")
+	}
+
+	var fakeUnit int
+	unitPtr := unsafe.Pointer(&fakeUnit)
+	if err := SetParameter(unitPtr, AUParameterAddress(0), 0, -1); err == nil {
+		t.Error("expected SetParameter with a negative rampFrames to fail")
+	}
+}
+
+func TestSaveStateRequiresNonNilUnit(t *testing.T) {
+	if _, err := SaveState(nil); err == nil {
+		t.Error("expected SaveState(nil) to fail")
+	}
+}
+
+func TestRestoreStateRejectsNilUnitOrEmptyData(t *testing.T) {
+	var fakeUnit int
+	unitPtr := unsafe.Pointer(&fakeUnit)
+
+	if err := RestoreState(nil, []byte{1}); err == nil {
+		t.Error("expected RestoreState(nil, ...) to fail")
+	}
+	if err := RestoreState(unitPtr, nil); err == nil {
+		t.Error("expected RestoreState with empty data to fail")
+	}
+}
+
+func TestRequestCocoaViewRequiresNonNilUnit(t *testing.T) {
+	if _, err := RequestCocoaView(nil); err == nil {
+		t.Error("expected RequestCocoaView(nil) to fail")
+	}
+}
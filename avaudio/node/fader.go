@@ -0,0 +1,172 @@
+package node
+
+import (
+	"math"
+	"unsafe"
+)
+
+// MixerSilenceDB is the dB floor SetMixerVolumeDB/GetMixerVolumeDB and the
+// FaderCurve implementations below clamp to instead of negative infinity,
+// matching the -96dB convention used elsewhere in this repo (e.g.
+// avaudio/engine's busGroupSilenceDB, engine.Channel's channelSilenceDB).
+const MixerSilenceDB = float32(-96)
+
+func dbToLinearGain(db float32) float32 {
+	if db <= MixerSilenceDB {
+		return 0
+	}
+	return float32(math.Pow(10, float64(db)/20))
+}
+
+func linearGainToDB(gain float32) float32 {
+	if gain <= 0 {
+		return MixerSilenceDB
+	}
+	db := float32(20 * math.Log10(float64(gain)))
+	if db < MixerSilenceDB {
+		return MixerSilenceDB
+	}
+	return db
+}
+
+// SetMixerVolumeDB sets the mixer's inputBus volume to the linear gain
+// equivalent of dB (10^(dB/20)), clamped to the [0,1] range SetMixerVolume
+// expects - values at or below MixerSilenceDB map to linear 0, and
+// anything above 0dB (unity) saturates at 1.0 rather than erroring, since
+// AVAudioMixerNode's volume parameter doesn't support gain above unity.
+func SetMixerVolumeDB(mixerPtr unsafe.Pointer, dB float32, inputBus int) error {
+	gain := dbToLinearGain(dB)
+	if gain > 1 {
+		gain = 1
+	}
+	return SetMixerVolume(mixerPtr, gain, inputBus)
+}
+
+// GetMixerVolumeDB reads the mixer's inputBus volume and reports it in dB
+// (20*log10(gain)); silence (linear 0) reports MixerSilenceDB rather than
+// negative infinity, matching SetMixerVolumeDB's floor.
+func GetMixerVolumeDB(mixerPtr unsafe.Pointer, inputBus int) (float32, error) {
+	gain, err := GetMixerVolume(mixerPtr, inputBus)
+	if err != nil {
+		return 0, err
+	}
+	return linearGainToDB(gain), nil
+}
+
+// FaderCurve maps a normalized fader travel position (0.0 fully down, 1.0
+// fully up) to a level in dB and back. Real mixing console faders aren't
+// linear in dB over their travel - more resolution is packed into the top
+// few dB, where small moves matter most - so SetFaderPosition goes through
+// a curve rather than a straight position*range formula. Implement this
+// interface to plug in a custom taper; IECFaderCurve and
+// ControlSurfaceFaderCurve ship below.
+type FaderCurve interface {
+	// DBAtPosition returns the level in dB for fader travel pos01 (0..1).
+	DBAtPosition(pos01 float32) float32
+	// PositionAtDB returns the fader travel (0..1) for level db, the
+	// inverse of DBAtPosition.
+	PositionAtDB(db float32) float32
+}
+
+// faderBreakpoint is one (position, dB) point in a breakpointFaderCurve.
+type faderBreakpoint struct {
+	position float32
+	db       float32
+}
+
+// breakpointFaderCurve implements FaderCurve by linearly interpolating, in
+// the dB domain, between a table of breakpoints ordered by ascending
+// position - both IECFaderCurve and ControlSurfaceFaderCurve are just
+// different breakpoint tables over this.
+type breakpointFaderCurve struct {
+	points []faderBreakpoint
+}
+
+func (c breakpointFaderCurve) DBAtPosition(pos01 float32) float32 {
+	first, last := c.points[0], c.points[len(c.points)-1]
+	if pos01 <= first.position {
+		return first.db
+	}
+	if pos01 >= last.position {
+		return last.db
+	}
+	for i := 1; i < len(c.points); i++ {
+		if pos01 <= c.points[i].position {
+			lo, hi := c.points[i-1], c.points[i]
+			t := (pos01 - lo.position) / (hi.position - lo.position)
+			return lo.db + t*(hi.db-lo.db)
+		}
+	}
+	return last.db
+}
+
+func (c breakpointFaderCurve) PositionAtDB(db float32) float32 {
+	first, last := c.points[0], c.points[len(c.points)-1]
+	if db <= first.db {
+		return first.position
+	}
+	if db >= last.db {
+		return last.position
+	}
+	for i := 1; i < len(c.points); i++ {
+		if db <= c.points[i].db {
+			lo, hi := c.points[i-1], c.points[i]
+			t := (db - lo.db) / (hi.db - lo.db)
+			return lo.position + t*(hi.position-lo.position)
+		}
+	}
+	return last.position
+}
+
+// IECFaderCurve is this package's interpretation of the IEC 60268-17 fader
+// law: a professional mixing console's fader provides finer resolution
+// near the top of its travel and compresses the lower range, rather than a
+// straight linear-dB taper. Breakpoints: MixerSilenceDB at 0% travel, -30dB
+// at 25%, -10dB at 50%, unity at 75%, +10dB at full travel.
+var IECFaderCurve FaderCurve = breakpointFaderCurve{points: []faderBreakpoint{
+	{position: 0.00, db: MixerSilenceDB},
+	{position: 0.25, db: -30},
+	{position: 0.50, db: -10},
+	{position: 0.75, db: 0},
+	{position: 1.00, db: 10},
+}}
+
+// ControlSurfaceFaderCurve places unity gain (0dB) at 75% fader travel
+// with +6dB of headroom above it to full travel - the FaderLevel0db
+// convention several hardware control-surface protocols (e.g. Mackie
+// Control Universal) use for their motorized fader taper, as opposed to
+// IECFaderCurve's console-style law.
+var ControlSurfaceFaderCurve FaderCurve = breakpointFaderCurve{points: []faderBreakpoint{
+	{position: 0.00, db: MixerSilenceDB},
+	{position: 0.25, db: -30},
+	{position: 0.50, db: -12},
+	{position: 0.75, db: 0},
+	{position: 1.00, db: 6},
+}}
+
+// NewAnchoredFaderCurve builds a breakpoint FaderCurve shaped like
+// ControlSurfaceFaderCurve - silence at 0% travel, +6dB headroom at full
+// travel, two -30dB/-12dB waypoints spaced evenly below unity - but with
+// the unity-gain (0dB) breakpoint at anchor instead of the fixed 75% (raw
+// MIDI 0x60 of a 0x7F range) MCU convention ControlSurfaceFaderCurve
+// hardcodes. Use this for a control surface or automation source whose
+// own 0dB anchor sits somewhere else.
+func NewAnchoredFaderCurve(anchor float32) FaderCurve {
+	if anchor <= 0 {
+		anchor = 0.75
+	}
+	return breakpointFaderCurve{points: []faderBreakpoint{
+		{position: 0, db: MixerSilenceDB},
+		{position: anchor / 3, db: -30},
+		{position: anchor * 2 / 3, db: -12},
+		{position: anchor, db: 0},
+		{position: 1, db: 6},
+	}}
+}
+
+// SetFaderPosition maps pos01 (0.0 fully down, 1.0 fully up) through curve
+// to a dB level and applies it via SetMixerVolumeDB - the fader-travel
+// counterpart to calling SetMixerVolumeDB with a dB value directly.
+func SetFaderPosition(mixerPtr unsafe.Pointer, pos01 float32, inputBus int, curve FaderCurve) error {
+	return SetMixerVolumeDB(mixerPtr, curve.DBAtPosition(pos01), inputBus)
+}
@@ -0,0 +1,122 @@
+package node
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMixerVolumeDBRoundTrip(t *testing.T) {
+	mixerPtr, err := CreateMixer()
+	if err != nil {
+		t.Fatalf("CreateMixer failed: %v", err)
+	}
+	defer ReleaseMixer(mixerPtr)
+
+	for _, db := range []float32{-24, -6, -1, 0} {
+		if err := SetMixerVolumeDB(mixerPtr, db, 0); err != nil {
+			t.Fatalf("SetMixerVolumeDB(%v) failed: %v", db, err)
+		}
+		got, err := GetMixerVolumeDB(mixerPtr, 0)
+		if err != nil {
+			t.Fatalf("GetMixerVolumeDB failed: %v", err)
+		}
+		if math.Abs(float64(got-db)) > 0.05 {
+			t.Errorf("expected ~%v dB round trip, got %v dB", db, got)
+		}
+	}
+}
+
+func TestMixerVolumeDBSilenceFloor(t *testing.T) {
+	mixerPtr, err := CreateMixer()
+	if err != nil {
+		t.Fatalf("CreateMixer failed: %v", err)
+	}
+	defer ReleaseMixer(mixerPtr)
+
+	if err := SetMixerVolumeDB(mixerPtr, MixerSilenceDB-20, 0); err != nil {
+		t.Fatalf("SetMixerVolumeDB failed: %v", err)
+	}
+	gain, err := GetMixerVolume(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("GetMixerVolume failed: %v", err)
+	}
+	if gain != 0 {
+		t.Errorf("expected a dB value below MixerSilenceDB to map to linear 0, got %v", gain)
+	}
+
+	db, err := GetMixerVolumeDB(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("GetMixerVolumeDB failed: %v", err)
+	}
+	if db != MixerSilenceDB {
+		t.Errorf("expected silence to report MixerSilenceDB, got %v", db)
+	}
+}
+
+func TestMixerVolumeDBSaturatesAboveUnity(t *testing.T) {
+	mixerPtr, err := CreateMixer()
+	if err != nil {
+		t.Fatalf("CreateMixer failed: %v", err)
+	}
+	defer ReleaseMixer(mixerPtr)
+
+	if err := SetMixerVolumeDB(mixerPtr, 20, 0); err != nil {
+		t.Fatalf("SetMixerVolumeDB failed: %v", err)
+	}
+	gain, err := GetMixerVolume(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("GetMixerVolume failed: %v", err)
+	}
+	if gain != 1 {
+		t.Errorf("expected +20dB to saturate at linear gain 1.0, got %v", gain)
+	}
+}
+
+func TestFaderCurveBreakpoints(t *testing.T) {
+	for _, curve := range []FaderCurve{IECFaderCurve, ControlSurfaceFaderCurve} {
+		if db := curve.DBAtPosition(0.75); db != 0 {
+			t.Errorf("expected unity gain at 75%% travel, got %v dB", db)
+		}
+		if db := curve.DBAtPosition(0); db != MixerSilenceDB {
+			t.Errorf("expected MixerSilenceDB at 0%% travel, got %v dB", db)
+		}
+	}
+
+	if db := IECFaderCurve.DBAtPosition(1.0); db != 10 {
+		t.Errorf("expected IECFaderCurve to reach +10dB at full travel, got %v", db)
+	}
+	if db := ControlSurfaceFaderCurve.DBAtPosition(1.0); db != 6 {
+		t.Errorf("expected ControlSurfaceFaderCurve to reach +6dB at full travel, got %v", db)
+	}
+}
+
+func TestFaderCurvePositionAtDBIsInverse(t *testing.T) {
+	for _, curve := range []FaderCurve{IECFaderCurve, ControlSurfaceFaderCurve} {
+		for _, pos := range []float32{0, 0.25, 0.5, 0.75, 1.0} {
+			db := curve.DBAtPosition(pos)
+			gotPos := curve.PositionAtDB(db)
+			if math.Abs(float64(gotPos-pos)) > 0.001 {
+				t.Errorf("expected PositionAtDB(DBAtPosition(%v)) ~= %v, got %v", pos, pos, gotPos)
+			}
+		}
+	}
+}
+
+func TestSetFaderPosition(t *testing.T) {
+	mixerPtr, err := CreateMixer()
+	if err != nil {
+		t.Fatalf("CreateMixer failed: %v", err)
+	}
+	defer ReleaseMixer(mixerPtr)
+
+	if err := SetFaderPosition(mixerPtr, 0.75, 0, IECFaderCurve); err != nil {
+		t.Fatalf("SetFaderPosition failed: %v", err)
+	}
+	gain, err := GetMixerVolume(mixerPtr, 0)
+	if err != nil {
+		t.Fatalf("GetMixerVolume failed: %v", err)
+	}
+	if math.Abs(float64(gain-1.0)) > 0.01 {
+		t.Errorf("expected unity gain (1.0) at 75%% fader travel, got %v", gain)
+	}
+}
@@ -0,0 +1,362 @@
+package node
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include "native/node.m"
+#include <stdlib.h>
+
+// The native side installs an AVAudioNode tap (installTapOnBus) on mixerPtr
+// and, on every buffer, calls back into goMeterProcessBuffer below with the
+// raw per-channel sample pointers - no metrics are computed natively, it's
+// purely a pointer-forwarding shim so the hot-path math below stays in Go.
+const char* audiometer_install(void* mixerPtr, int bus, unsigned long long meterID);
+const char* audiometer_remove(unsigned long long meterID);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// MeterConfig configures a Meter installed by EnableMetering.
+type MeterConfig struct {
+	// WindowMS sets the RMS ballistics time constant - larger values
+	// smooth the reported RMSL/RMSR more, matching the integration time
+	// of a VU-style meter rather than a sample-accurate instantaneous RMS.
+	WindowMS int
+	// PeakHoldMS is how long PeakHoldL/PeakHoldR stay pinned at the last
+	// local maximum before decaying back down to track the live peak.
+	PeakHoldMS int
+	// KWeighting applies an ITU-R BS.1770 K-weighting pre-filter (a
+	// high-shelf followed by a high-pass) before computing RMS, for
+	// LUFS-style broadcast loudness metering instead of flat RMS.
+	KWeighting bool
+}
+
+// MeterFrame is one snapshot read from a Meter. All levels are linear gain,
+// not dB - callers wanting dB should go through linearGainToDB.
+type MeterFrame struct {
+	PeakL, PeakR         float32
+	RMSL, RMSR           float32
+	PeakHoldL, PeakHoldR float32
+	TimestampNS          int64
+}
+
+// meterRingSize is the number of preallocated MeterFrame slots a Meter
+// cycles through. The audio-thread callback never allocates a frame -
+// it writes into the next ring slot and atomically publishes a pointer to
+// it - so a reader can never observe a slot that's concurrently being
+// overwritten by the *next* buffer, only ever the just-published one or an
+// older one.
+const meterRingSize = 4
+
+// Meter is a peak/RMS/peak-hold meter fed by a per-buffer tap callback on a
+// mixer or input node. Unlike tap.Tap (which accumulates a single RMS
+// value natively and is read by polling), Meter computes its metrics in Go
+// on every buffer and publishes the latest MeterFrame lock-free, so Read
+// never blocks the audio thread and the audio thread never blocks on Read.
+type Meter struct {
+	id      uint64
+	nodePtr unsafe.Pointer
+	bus     int
+	config  MeterConfig
+
+	ring    [meterRingSize]MeterFrame
+	ringIdx uint32
+	latest  atomic.Pointer[MeterFrame]
+
+	// Mutable ballistics state. Only ever touched from the audio-thread
+	// callback (goMeterProcessBuffer serializes calls per meter), so it
+	// needs no locking of its own - only the published *MeterFrame needs
+	// to be safe for concurrent readers. msL/msR are running mean-square
+	// accumulators; MeterFrame.RMSL/RMSR are their square root.
+	msL, msR             float32
+	peakHoldL, peakHoldR float32
+	peakHoldAtL          int64
+	peakHoldAtR          int64
+
+	kL, kR kWeightingFilter
+}
+
+var (
+	meterRegistryMu sync.RWMutex
+	meterRegistry   = make(map[uint64]*Meter)
+	meterNextID     uint64
+)
+
+// EnableMetering installs a Meter on mixerPtr's inputBus. The returned
+// Meter starts publishing frames as soon as buffers flow through that bus;
+// call Read to get the latest one.
+func EnableMetering(mixerPtr unsafe.Pointer, inputBus int, config MeterConfig) (*Meter, error) {
+	if mixerPtr == nil {
+		return nil, errors.New("mixer pointer is nil")
+	}
+
+	id := atomic.AddUint64(&meterNextID, 1)
+	m := &Meter{
+		id:      id,
+		nodePtr: mixerPtr,
+		bus:     inputBus,
+		config:  config,
+	}
+	if config.KWeighting {
+		m.kL = newKWeightingFilter(defaultMeterSampleRate)
+		m.kR = newKWeightingFilter(defaultMeterSampleRate)
+	}
+
+	meterRegistryMu.Lock()
+	meterRegistry[id] = m
+	meterRegistryMu.Unlock()
+
+	result := C.audiometer_install(mixerPtr, C.int(inputBus), C.ulonglong(id))
+	if result != nil {
+		meterRegistryMu.Lock()
+		delete(meterRegistry, id)
+		meterRegistryMu.Unlock()
+		return nil, errors.New(C.GoString(result))
+	}
+
+	return m, nil
+}
+
+// Remove uninstalls the native tap backing this Meter. Read keeps returning
+// the last published frame afterward; it simply stops updating.
+func (m *Meter) Remove() error {
+	result := C.audiometer_remove(C.ulonglong(m.id))
+	if result != nil {
+		return errors.New(C.GoString(result))
+	}
+	meterRegistryMu.Lock()
+	delete(meterRegistry, m.id)
+	meterRegistryMu.Unlock()
+	return nil
+}
+
+// Reinstall moves this Meter onto a new node/bus without losing its peak
+// hold or ring state, for a channel that survives a device change (see
+// Dispatcher.changeChannelDevice): the node pointer backing an input
+// channel is replaced by a Stop/reconnect/Start cycle, so a Meter installed
+// on the old pointer would otherwise go silent. Callers reconnecting a
+// channel's node should call Reinstall with the new pointer immediately
+// after Start succeeds.
+func (m *Meter) Reinstall(nodePtr unsafe.Pointer, bus int) error {
+	if nodePtr == nil {
+		return errors.New("node pointer is nil")
+	}
+	if result := C.audiometer_remove(C.ulonglong(m.id)); result != nil {
+		return fmt.Errorf("removing meter from previous node: %s", C.GoString(result))
+	}
+	result := C.audiometer_install(nodePtr, C.int(bus), C.ulonglong(m.id))
+	if result != nil {
+		return fmt.Errorf("installing meter on new node: %s", C.GoString(result))
+	}
+	m.nodePtr = nodePtr
+	m.bus = bus
+	return nil
+}
+
+// Read returns the most recently published MeterFrame. Before any buffer
+// has arrived, it returns a zero MeterFrame.
+func (m *Meter) Read() MeterFrame {
+	frame := m.latest.Load()
+	if frame == nil {
+		return MeterFrame{}
+	}
+	return *frame
+}
+
+// defaultMeterSampleRate is the sample rate kWeightingFilter's coefficients
+// are computed for. MeterConfig deliberately doesn't carry a sample rate
+// (matching the mixer-level SetMixerVolume/SetMixerVolumeDB functions,
+// which are also sample-rate-agnostic) - 48kHz matches this repo's test
+// fixtures (see createTestConfig) and is the rate every shipping engine
+// config in this tree actually runs at.
+const defaultMeterSampleRate = 48000
+
+// rmsTimeConstant converts windowMS into the per-sample smoothing
+// coefficient for an exponential moving average, the same ballistics a
+// hardware VU meter uses instead of a literal windowed RMS (which would
+// need an allocation proportional to windowMS*sampleRate to buffer).
+func rmsTimeConstant(windowMS int) float32 {
+	if windowMS <= 0 {
+		windowMS = 300
+	}
+	samples := float64(windowMS) / 1000 * defaultMeterSampleRate
+	if samples < 1 {
+		samples = 1
+	}
+	return float32(math.Exp(-1 / samples))
+}
+
+// process computes one buffer's worth of metrics and publishes them. It's
+// the hot path: no allocation, no locks, called once per buffer from
+// goMeterProcessBuffer (or directly by tests against a Meter that was never
+// installed on a real node).
+func (m *Meter) process(left, right []float32, nowNS int64) {
+	alpha := rmsTimeConstant(m.config.WindowMS)
+
+	peakL := processChannel(left, m.config.KWeighting, &m.kL, &m.msL, alpha)
+	var peakR float32
+	if len(right) > 0 {
+		peakR = processChannel(right, m.config.KWeighting, &m.kR, &m.msR, alpha)
+	} else {
+		// Mono source: mirror the left channel onto the right meter so
+		// callers reading PeakR/RMSR of a mono feed see the same values
+		// as PeakL/RMSL rather than silence.
+		m.msR = m.msL
+		peakR = peakL
+	}
+
+	m.updatePeakHold(&m.peakHoldL, &m.peakHoldAtL, peakL, nowNS)
+	m.updatePeakHold(&m.peakHoldR, &m.peakHoldAtR, peakR, nowNS)
+
+	idx := atomic.AddUint32(&m.ringIdx, 1) % meterRingSize
+	slot := &m.ring[idx]
+	*slot = MeterFrame{
+		PeakL:       peakL,
+		PeakR:       peakR,
+		RMSL:        float32(math.Sqrt(float64(m.msL))),
+		RMSR:        float32(math.Sqrt(float64(m.msR))),
+		PeakHoldL:   m.peakHoldL,
+		PeakHoldR:   m.peakHoldR,
+		TimestampNS: nowNS,
+	}
+	m.latest.Store(slot)
+}
+
+// processChannel runs samples through the optional K-weighting filter and
+// folds their squared magnitude into *msAccum (a running mean-square value,
+// square-rooted into RMS only when a frame is published) via an
+// exponential moving average with smoothing factor alpha, returning this
+// buffer's peak absolute sample value.
+func processChannel(samples []float32, kWeighted bool, k *kWeightingFilter, msAccum *float32, alpha float32) float32 {
+	var peak float32
+	for _, s := range samples {
+		v := s
+		if kWeighted {
+			v = k.process(v)
+		}
+		abs := v
+		if abs < 0 {
+			abs = -abs
+		}
+		if abs > peak {
+			peak = abs
+		}
+		*msAccum = alpha**msAccum + (1-alpha)*(v*v)
+	}
+	return peak
+}
+
+func (m *Meter) updatePeakHold(hold *float32, heldAtNS *int64, peak float32, nowNS int64) {
+	holdMS := m.config.PeakHoldMS
+	if holdMS <= 0 {
+		holdMS = 1000
+	}
+	if peak >= *hold {
+		*hold = peak
+		*heldAtNS = nowNS
+		return
+	}
+	if nowNS-*heldAtNS > int64(holdMS)*int64(time.Millisecond) {
+		*hold = peak
+		*heldAtNS = nowNS
+	}
+}
+
+//export goMeterProcessBuffer
+func goMeterProcessBuffer(meterID C.ulonglong, left, right *C.float, frameCount C.int, nowNS C.longlong) {
+	meterRegistryMu.RLock()
+	m := meterRegistry[uint64(meterID)]
+	meterRegistryMu.RUnlock()
+	if m == nil || frameCount <= 0 {
+		return
+	}
+
+	leftSlice := unsafe.Slice((*float32)(unsafe.Pointer(left)), int(frameCount))
+	var rightSlice []float32
+	if right != nil {
+		rightSlice = unsafe.Slice((*float32)(unsafe.Pointer(right)), int(frameCount))
+	}
+	m.process(leftSlice, rightSlice, int64(nowNS))
+}
+
+// kWeightingFilter is a two-stage biquad cascade implementing the ITU-R
+// BS.1770 K-weighting pre-filter (a high-shelf boost above ~2kHz followed
+// by a high-pass below ~60Hz), used by Meter when MeterConfig.KWeighting is
+// set so RMS/peak approximate perceived (LUFS-style) loudness rather than
+// flat signal level.
+type kWeightingFilter struct {
+	shelf, highpass biquad
+}
+
+func newKWeightingFilter(sampleRate float64) kWeightingFilter {
+	return kWeightingFilter{
+		shelf:    newHighShelfBiquad(sampleRate, 1500, 4.0, 1/math.Sqrt2),
+		highpass: newHighPassBiquad(sampleRate, 60, 1/math.Sqrt2),
+	}
+}
+
+func (f *kWeightingFilter) process(x float32) float32 {
+	return f.highpass.process(f.shelf.process(x))
+}
+
+// biquad is a direct-form-II transposed biquad filter, the standard
+// allocation-free building block for the shelf/high-pass stages above.
+type biquad struct {
+	b0, b1, b2 float32
+	a1, a2     float32
+	z1, z2     float32
+}
+
+func (b *biquad) process(x float32) float32 {
+	y := b.b0*x + b.z1
+	b.z1 = b.b1*x - b.a1*y + b.z2
+	b.z2 = b.b2*x - b.a2*y
+	return y
+}
+
+func newHighShelfBiquad(sampleRate, freq, gainDB, q float64) biquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * freq / sampleRate
+	cosW0, sinW0 := math.Cos(w0), math.Sin(w0)
+	alpha := sinW0 / (2 * q)
+	sqrtA := math.Sqrt(a)
+
+	b0 := a * ((a + 1) + (a-1)*cosW0 + 2*sqrtA*alpha)
+	b1 := -2 * a * ((a - 1) + (a+1)*cosW0)
+	b2 := a * ((a + 1) + (a-1)*cosW0 - 2*sqrtA*alpha)
+	a0 := (a + 1) - (a-1)*cosW0 + 2*sqrtA*alpha
+	a1 := 2 * ((a - 1) - (a+1)*cosW0)
+	a2 := (a + 1) - (a-1)*cosW0 - 2*sqrtA*alpha
+
+	return biquad{
+		b0: float32(b0 / a0), b1: float32(b1 / a0), b2: float32(b2 / a0),
+		a1: float32(a1 / a0), a2: float32(a2 / a0),
+	}
+}
+
+func newHighPassBiquad(sampleRate, freq, q float64) biquad {
+	w0 := 2 * math.Pi * freq / sampleRate
+	cosW0, sinW0 := math.Cos(w0), math.Sin(w0)
+	alpha := sinW0 / (2 * q)
+
+	b0 := (1 + cosW0) / 2
+	b1 := -(1 + cosW0)
+	b2 := (1 + cosW0) / 2
+	a0 := 1 + alpha
+	a1 := -2 * cosW0
+	a2 := 1 - alpha
+
+	return biquad{
+		b0: float32(b0 / a0), b1: float32(b1 / a0), b2: float32(b2 / a0),
+		a1: float32(a1 / a0), a2: float32(a2 / a0),
+	}
+}
@@ -0,0 +1,151 @@
+package node
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+)
+
+// feedSine drives m.process with buffers of a known sine tone generated by
+// an Oscillator, in frameSize chunks, for enough samples to let the RMS
+// ballistics settle - the same pure-Go tone generator the rest of this tree
+// uses to test audio code without a real engine (see avaudio/node/node_test.go).
+func feedSine(t *testing.T, m *Meter, freq, amplitude float64, totalSamples, frameSize int) {
+	t.Helper()
+
+	osc, err := sourcenode.NewOscillator(sourcenode.WaveformSine, defaultMeterSampleRate)
+	if err != nil {
+		t.Fatalf("NewOscillator failed: %v", err)
+	}
+	if err := osc.SetFrequency(freq); err != nil {
+		t.Fatalf("SetFrequency failed: %v", err)
+	}
+	if err := osc.SetAmplitude(amplitude); err != nil {
+		t.Fatalf("SetAmplitude failed: %v", err)
+	}
+
+	var nowNS int64
+	for remaining := totalSamples; remaining > 0; remaining -= frameSize {
+		n := frameSize
+		if remaining < n {
+			n = remaining
+		}
+		buf, err := osc.GenerateBuffer(n)
+		if err != nil {
+			t.Fatalf("GenerateBuffer failed: %v", err)
+		}
+		m.process(buf, buf, nowNS)
+		nowNS += int64(n) * 1e9 / defaultMeterSampleRate
+	}
+}
+
+func TestMeterPeakAndRMSMatchKnownSine(t *testing.T) {
+	const amplitude = 0.8
+	m := &Meter{config: MeterConfig{WindowMS: 50, PeakHoldMS: 500}}
+
+	// 2 seconds at 1kHz gives the 50ms EMA window ~40 time constants to
+	// settle, far more than enough to converge within 0.1dB of the true
+	// value of a steady tone.
+	feedSine(t, m, 1000, amplitude, 2*defaultMeterSampleRate, 512)
+
+	frame := m.Read()
+
+	wantPeakDB := linearGainToDB(float32(amplitude))
+	gotPeakDB := linearGainToDB(frame.PeakL)
+	if math.Abs(float64(gotPeakDB-wantPeakDB)) > 0.1 {
+		t.Errorf("expected peak ~%.2fdB, got %.2fdB", wantPeakDB, gotPeakDB)
+	}
+
+	wantRMS := amplitude / math.Sqrt2
+	wantRMSDB := linearGainToDB(float32(wantRMS))
+	gotRMSDB := linearGainToDB(frame.RMSL)
+	if math.Abs(float64(gotRMSDB-wantRMSDB)) > 0.1 {
+		t.Errorf("expected RMS ~%.2fdB (%.4f linear), got %.2fdB (%.4f linear)", wantRMSDB, wantRMS, gotRMSDB, frame.RMSL)
+	}
+
+	if frame.PeakR != frame.PeakL || frame.RMSR != frame.RMSL {
+		t.Errorf("expected left/right to match for an identical feed, got L=%v R=%v", frame.PeakL, frame.PeakR)
+	}
+}
+
+func TestMeterMonoMirrorsLeftOntoRight(t *testing.T) {
+	m := &Meter{config: MeterConfig{WindowMS: 50, PeakHoldMS: 500}}
+
+	osc, err := sourcenode.NewOscillator(sourcenode.WaveformSine, defaultMeterSampleRate)
+	if err != nil {
+		t.Fatalf("NewOscillator failed: %v", err)
+	}
+	if err := osc.SetFrequency(440); err != nil {
+		t.Fatalf("SetFrequency failed: %v", err)
+	}
+	if err := osc.SetAmplitude(0.5); err != nil {
+		t.Fatalf("SetAmplitude failed: %v", err)
+	}
+	buf, err := osc.GenerateBuffer(512)
+	if err != nil {
+		t.Fatalf("GenerateBuffer failed: %v", err)
+	}
+	m.process(buf, nil, 0)
+
+	frame := m.Read()
+	if frame.PeakR != frame.PeakL {
+		t.Errorf("expected mono feed to mirror PeakL onto PeakR, got L=%v R=%v", frame.PeakL, frame.PeakR)
+	}
+	if frame.RMSR != frame.RMSL {
+		t.Errorf("expected mono feed to mirror RMSL onto RMSR, got L=%v R=%v", frame.RMSL, frame.RMSR)
+	}
+}
+
+func TestMeterPeakHoldStaysUntilExpiry(t *testing.T) {
+	m := &Meter{config: MeterConfig{WindowMS: 50, PeakHoldMS: 100}}
+
+	m.process([]float32{0.9, -0.9, 0.9}, nil, 0)
+	held := m.Read().PeakHoldL
+	if held < 0.89 {
+		t.Fatalf("expected peak hold to capture 0.9, got %v", held)
+	}
+
+	// A quieter buffer well inside the hold window shouldn't move the hold.
+	m.process([]float32{0.1, -0.1}, nil, 50_000_000)
+	if got := m.Read().PeakHoldL; got != held {
+		t.Errorf("expected peak hold to stay at %v inside the hold window, got %v", held, got)
+	}
+
+	// Past PeakHoldMS, the hold should decay to the current (quiet) peak.
+	m.process([]float32{0.1, -0.1}, nil, 200_000_000)
+	if got := m.Read().PeakHoldL; got >= held {
+		t.Errorf("expected peak hold to decay below %v after expiry, got %v", held, got)
+	}
+}
+
+func TestMeterKWeightingAttenuatesLowFrequencyRMS(t *testing.T) {
+	flat := &Meter{config: MeterConfig{WindowMS: 50, PeakHoldMS: 500}}
+	weighted := &Meter{config: MeterConfig{WindowMS: 50, PeakHoldMS: 500, KWeighting: true}}
+	weighted.kL = newKWeightingFilter(defaultMeterSampleRate)
+	weighted.kR = newKWeightingFilter(defaultMeterSampleRate)
+
+	feedSine(t, flat, 40, 0.8, 2*defaultMeterSampleRate, 512)
+	feedSine(t, weighted, 40, 0.8, 2*defaultMeterSampleRate, 512)
+
+	flatRMS := flat.Read().RMSL
+	weightedRMS := weighted.Read().RMSL
+	if weightedRMS >= flatRMS {
+		t.Errorf("expected K-weighting's high-pass stage to attenuate a 40Hz tone, flat RMS=%v weighted RMS=%v", flatRMS, weightedRMS)
+	}
+}
+
+func TestMeterRingBufferNeverAllocatesPastWarmup(t *testing.T) {
+	m := &Meter{config: MeterConfig{WindowMS: 50, PeakHoldMS: 500}}
+	buf := make([]float32, 256)
+	for i := range buf {
+		buf[i] = 0.5
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		m.process(buf, buf, int64(0))
+	})
+	if allocs != 0 {
+		t.Errorf("expected Meter.process to be allocation-free on the hot path, got %v allocs/run", allocs)
+	}
+}
@@ -32,6 +32,14 @@ const char* audiomixer_get_input_pan_for_connection(void* sourcePtr, void* mixer
 // Matrix mixer (invert stage)
 AudioNodeResult matrixmixer_create(void);
 const char* matrixmixer_configure_invert(void* unitPtr);
+const char* matrixmixer_set_channel_count(void* unitPtr, int inputChannels, int outputChannels);
+const char* matrixmixer_set_volume(void* unitPtr, int inputChannel, int outputChannel, float volume);
+
+// Resampler (AVAudioUnitEffect wrapping kAudioUnitSubType_AUConverter / AVAudioConverter)
+AudioNodeResult resampler_create(void);
+const char* resampler_set_quality(void* unitPtr, int quality);
+const char* resampler_release(void* unitPtr);
+double audionode_format_sample_rate(void* formatPtr);
 */
 import "C"
 import (
@@ -262,6 +270,84 @@ func ConfigureMatrixInvert(unitPtr unsafe.Pointer) error {
 	return nil
 }
 
+// SetMatrixChannelCount (re)configures a matrix mixer's input/output bus
+// width, e.g. 1 input (mono source) x 6 outputs (5.1) for a multichannel
+// panner built on CreateMatrixMixer.
+func SetMatrixChannelCount(unitPtr unsafe.Pointer, inputChannels, outputChannels int) error {
+	if unitPtr == nil { return errors.New("unit pointer is nil") }
+	if errStr := C.matrixmixer_set_channel_count(unitPtr, C.int(inputChannels), C.int(outputChannels)); errStr != nil { return errors.New(C.GoString(errStr)) }
+	return nil
+}
+
+// SetMatrixVolume sets the gain routed from inputChannel to outputChannel on
+// a matrix mixer created by CreateMatrixMixer, the building block a
+// multichannel panner uses to apply one row of VBAP/Ambisonics gains per
+// output speaker.
+func SetMatrixVolume(unitPtr unsafe.Pointer, inputChannel, outputChannel int, volume float32) error {
+	if unitPtr == nil { return errors.New("unit pointer is nil") }
+	if errStr := C.matrixmixer_set_volume(unitPtr, C.int(inputChannel), C.int(outputChannel), C.float(volume)); errStr != nil { return errors.New(C.GoString(errStr)) }
+	return nil
+}
+
+// ResamplingQuality selects the conversion algorithm a Resampler uses,
+// mirroring AVAudioConverter's AVAudioQuality enum (higher costs more CPU
+// for a cleaner result).
+type ResamplingQuality int
+
+const (
+	ResamplingQualityMin ResamplingQuality = iota
+	ResamplingQualityLow
+	ResamplingQualityMedium
+	ResamplingQualityHigh
+	ResamplingQualityMax
+)
+
+// CreateResampler creates a new sample-rate converter node (an
+// AVAudioUnitEffect wrapping kAudioUnitSubType_AUConverter), for insertion
+// between two nodes whose formats don't share a sample rate - see
+// channel.Bus.ConnectChannel, which inserts one automatically.
+func CreateResampler() (unsafe.Pointer, error) {
+	result := C.resampler_create()
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// SetResamplerQuality sets a resampler's conversion quality; see
+// ResamplingQuality.
+func SetResamplerQuality(resamplerPtr unsafe.Pointer, quality ResamplingQuality) error {
+	if resamplerPtr == nil {
+		return errors.New("resampler pointer is nil")
+	}
+	errStr := C.resampler_set_quality(resamplerPtr, C.int(quality))
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// ReleaseResampler releases a resampler node created by CreateResampler.
+func ReleaseResampler(resamplerPtr unsafe.Pointer) error {
+	if resamplerPtr == nil {
+		return nil
+	}
+	errStr := C.resampler_release(resamplerPtr)
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// FormatSampleRate reads the sample rate off an AVAudioFormat pointer, e.g.
+// one returned by GetOutputFormatForBus.
+func FormatSampleRate(formatPtr unsafe.Pointer) (float64, error) {
+	if formatPtr == nil {
+		return 0, errors.New("format pointer is nil")
+	}
+	return float64(C.audionode_format_sample_rate(formatPtr)), nil
+}
+
 // Legacy helper functions for backward compatibility (these now return errors properly)
 
 // ValidateInputBus checks if a bus number is valid for input
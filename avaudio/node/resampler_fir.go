@@ -0,0 +1,278 @@
+package node
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include <stdlib.h>
+
+// Declared here; not implemented in this tree yet. Unlike CreateResampler
+// above (which wraps AVFoundation's own AVAudioConverter-backed
+// AUConverter), a polyphase FIR resampler computes its own coefficient
+// table in Go (see newPolyphaseFilterBank) and needs a render-callback
+// shim (native/node_polyphase_resampler.m) to run that table inside a
+// real-time AVAudioNode - a shim this snapshot doesn't carry. Each call
+// below fails with a "not implemented" error rather than running, so the
+// Go-side filter design, the node-creation API, and resampler_fir_test.go
+// can all be written, reviewed, and unit-tested now, and start working
+// the moment that shim exists.
+const char* node_polyphase_resampler_create(int channels, const double* coeffs, int coeffsLen, int tapsPerPhase, int interpolationL, int decimationM, void** unitPtr);
+const char* node_polyphase_resampler_rebuild(void* unitPtr, const double* coeffs, int coeffsLen, int tapsPerPhase, int interpolationL, int decimationM);
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// ResamplerQuality selects how many polyphase FIR taps per phase
+// CreatePolyphaseResampler's windowed-sinc filter uses, trading CPU for
+// stopband rejection. ResamplerQualityDynamic additionally lets
+// RebuildPolyphaseResampler replace the coefficient table in place when
+// the source format changes mid-stream, without tearing down the node.
+type ResamplerQuality int
+
+const (
+	ResamplerQualityLow ResamplerQuality = iota
+	ResamplerQualityMedium
+	ResamplerQualityHigh
+	ResamplerQualityDynamic
+)
+
+// tapsPerPhase returns the per-phase filter length for q. Dynamic uses
+// Medium's length - it's Medium plus the ability to rebuild, not a
+// distinct CPU/quality tier.
+func (q ResamplerQuality) tapsPerPhase() int {
+	switch q {
+	case ResamplerQualityLow:
+		return 16
+	case ResamplerQualityHigh:
+		return 64
+	default:
+		return 32
+	}
+}
+
+// polyphaseFilterBank is the coefficient table a polyphase resampler
+// walks at runtime: interpolationL phases, each decimationM apart in the
+// conceptual "upsample by L, then keep every Mth sample" rate-conversion
+// ladder, with tapsPerPhase coefficients per phase.
+type polyphaseFilterBank struct {
+	interpolationL int
+	decimationM    int
+	tapsPerPhase   int
+	phases         [][]float64 // len == interpolationL, each len == tapsPerPhase
+}
+
+// newPolyphaseFilterBank designs a windowed-sinc lowpass (Kaiser window,
+// cutoff = 0.45*min(inRate,outRate)) and splits it into interpolationL
+// polyphase branches, per the rate-conversion ratio outRate/inRate
+// reduced to lowest terms via their gcd.
+func newPolyphaseFilterBank(inRate, outRate float64, quality ResamplerQuality) (*polyphaseFilterBank, error) {
+	if inRate <= 0 || outRate <= 0 {
+		return nil, fmt.Errorf("node: inRate and outRate must be positive")
+	}
+
+	l, m := reducedRatio(inRate, outRate)
+	tapsPerPhase := quality.tapsPerPhase()
+	n := l * tapsPerPhase // total prototype taps
+
+	upRate := inRate * float64(l)
+	cutoffHz := 0.45 * math.Min(inRate, outRate)
+	fcNorm := cutoffHz / upRate // cycles/sample at the upsampled rate
+
+	const kaiserBeta = 8.6 // ~ -80dB stopband, shared across quality tiers
+	center := float64(n-1) / 2
+
+	prototype := make([]float64, n)
+	var dcGain float64
+	for i := 0; i < n; i++ {
+		x := float64(i) - center
+		prototype[i] = sinc(2*fcNorm*x) * 2 * fcNorm * kaiserWindow(float64(i), float64(n), kaiserBeta)
+		dcGain += prototype[i]
+	}
+	if dcGain != 0 {
+		scale := float64(l) / dcGain
+		for i := range prototype {
+			prototype[i] *= scale
+		}
+	}
+
+	phases := make([][]float64, l)
+	for p := 0; p < l; p++ {
+		phase := make([]float64, tapsPerPhase)
+		for k := 0; k < tapsPerPhase; k++ {
+			idx := k*l + p
+			if idx < n {
+				phase[k] = prototype[idx]
+			}
+		}
+		phases[p] = phase
+	}
+
+	return &polyphaseFilterBank{interpolationL: l, decimationM: m, tapsPerPhase: tapsPerPhase, phases: phases}, nil
+}
+
+// flatten lays the filter bank's phases end to end (phase 0's taps, then
+// phase 1's, ...) for passing across the cgo boundary as one array.
+func (b *polyphaseFilterBank) flatten() []float64 {
+	flat := make([]float64, 0, b.interpolationL*b.tapsPerPhase)
+	for _, phase := range b.phases {
+		flat = append(flat, phase...)
+	}
+	return flat
+}
+
+// Resample converts in (one channel, at the bank's inRate) to outRate,
+// selecting phase n*M mod L for each output sample n and dot-producting
+// it against the tapsPerPhase most recent input samples - the same
+// runtime algorithm CreatePolyphaseResampler's native node will eventually
+// run per render callback, exposed here so it can be designed and tested
+// in pure Go without a real AVAudioNode.
+func (b *polyphaseFilterBank) Resample(in []float64) []float64 {
+	if len(in) == 0 {
+		return nil
+	}
+
+	history := make([]float64, b.tapsPerPhase)
+	out := make([]float64, 0, len(in)*b.interpolationL/b.decimationM+1)
+
+	inPos := 0
+	for n := 0; inPos < len(in); n++ {
+		phase := (n * b.decimationM) % b.interpolationL
+		advance := (n * b.decimationM) / b.interpolationL
+		for advance > 0 && inPos < len(in) {
+			copy(history, history[1:])
+			history[len(history)-1] = in[inPos]
+			inPos++
+			advance--
+		}
+		if advance > 0 {
+			break // ran out of input before this output sample's advance could complete
+		}
+
+		var acc float64
+		coeffs := b.phases[phase]
+		for k, c := range coeffs {
+			acc += c * history[k]
+		}
+		out = append(out, acc)
+	}
+	return out
+}
+
+// sinc is the normalized sinc function, sin(pi*x)/(pi*x), with sinc(0)=1.
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	px := math.Pi * x
+	return math.Sin(px) / px
+}
+
+// kaiserWindow evaluates the Kaiser window at sample i of an n-long
+// window with shape parameter beta.
+func kaiserWindow(i, n, beta float64) float64 {
+	r := 2*i/(n-1) - 1
+	return besselI0(beta*math.Sqrt(1-r*r)) / besselI0(beta)
+}
+
+// besselI0 approximates the zeroth-order modified Bessel function of the
+// first kind via its power series, summed until terms stop contributing.
+func besselI0(x float64) float64 {
+	sum := 1.0
+	term := 1.0
+	half := x / 2
+	for k := 1; k < 64; k++ {
+		term *= (half * half) / (float64(k) * float64(k))
+		sum += term
+		if term < 1e-14*sum {
+			break
+		}
+	}
+	return sum
+}
+
+// reducedRatio reduces outRate/inRate to lowest terms, returning
+// (interpolationL, decimationM) such that outRate/inRate == L/M.
+func reducedRatio(inRate, outRate float64) (l, m int) {
+	in, out := int(math.Round(inRate)), int(math.Round(outRate))
+	g := gcd(in, out)
+	return out / g, in / g
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// CreatePolyphaseResampler creates a sample-rate converter node that runs
+// its own polyphase FIR (windowed-sinc, Kaiser window) rather than
+// wrapping AVAudioConverter the way CreateResampler does - trading
+// AVAudioConverter's adaptive, opaque algorithm for a deterministic one
+// with fixed latency (tapsPerPhase/2 samples) and a coefficient table the
+// caller can inspect and choose the cost of via quality. The returned
+// pointer is a normal AVAudioNode-compatible pointer, attachable and
+// connectable like any node.CreateMixer or audiounit.Instantiate result.
+func CreatePolyphaseResampler(inRate, outRate float64, channels int, quality ResamplerQuality) (unsafe.Pointer, error) {
+	if channels <= 0 {
+		return nil, fmt.Errorf("node: channels must be positive")
+	}
+
+	bank, err := newPolyphaseFilterBank(inRate, outRate, quality)
+	if err != nil {
+		return nil, err
+	}
+	coeffs := bank.flatten()
+
+	var unitPtr unsafe.Pointer
+	errorStr := C.node_polyphase_resampler_create(
+		C.int(channels),
+		(*C.double)(unsafe.Pointer(&coeffs[0])),
+		C.int(len(coeffs)),
+		C.int(bank.tapsPerPhase),
+		C.int(bank.interpolationL),
+		C.int(bank.decimationM),
+		&unitPtr,
+	)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	return unitPtr, nil
+}
+
+// RebuildPolyphaseResampler replaces an in-place resampler node's
+// coefficient table for a new inRate/outRate/quality, for use with
+// ResamplerQualityDynamic when the source format changes mid-stream: the
+// native side drains its delay line and resumes with the new table,
+// rather than requiring the node to be detached and recreated.
+func RebuildPolyphaseResampler(unitPtr unsafe.Pointer, inRate, outRate float64, quality ResamplerQuality) error {
+	if unitPtr == nil {
+		return fmt.Errorf("node: unit pointer cannot be nil")
+	}
+
+	bank, err := newPolyphaseFilterBank(inRate, outRate, quality)
+	if err != nil {
+		return err
+	}
+	coeffs := bank.flatten()
+
+	errorStr := C.node_polyphase_resampler_rebuild(
+		unitPtr,
+		(*C.double)(unsafe.Pointer(&coeffs[0])),
+		C.int(len(coeffs)),
+		C.int(bank.tapsPerPhase),
+		C.int(bank.interpolationL),
+		C.int(bank.decimationM),
+	)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package node
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGCD(t *testing.T) {
+	cases := []struct{ a, b, want int }{
+		{48000, 44100, 300},
+		{12, 8, 4},
+		{7, 0, 7},
+	}
+	for _, c := range cases {
+		if got := gcd(c.a, c.b); got != c.want {
+			t.Errorf("gcd(%d, %d) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestReducedRatio(t *testing.T) {
+	l, m := reducedRatio(44100, 48000)
+	if l != 160 || m != 147 {
+		t.Errorf("reducedRatio(44100, 48000) = (%d, %d), want (160, 147)", l, m)
+	}
+}
+
+func TestNewPolyphaseFilterBankShape(t *testing.T) {
+	bank, err := newPolyphaseFilterBank(8000, 16000, ResamplerQualityLow)
+	if err != nil {
+		t.Fatalf("newPolyphaseFilterBank returned an error: %v", err)
+	}
+	if bank.interpolationL != 2 || bank.decimationM != 1 {
+		t.Fatalf("interpolationL/decimationM = %d/%d, want 2/1", bank.interpolationL, bank.decimationM)
+	}
+	if len(bank.phases) != bank.interpolationL {
+		t.Fatalf("got %d phases, want %d", len(bank.phases), bank.interpolationL)
+	}
+	for i, phase := range bank.phases {
+		if len(phase) != bank.tapsPerPhase {
+			t.Errorf("phase %d has %d taps, want %d", i, len(phase), bank.tapsPerPhase)
+		}
+	}
+}
+
+func TestNewPolyphaseFilterBankRejectsNonPositiveRates(t *testing.T) {
+	if _, err := newPolyphaseFilterBank(0, 48000, ResamplerQualityLow); err == nil {
+		t.Error("expected an error for a zero inRate")
+	}
+	if _, err := newPolyphaseFilterBank(44100, -1, ResamplerQualityLow); err == nil {
+		t.Error("expected an error for a negative outRate")
+	}
+}
+
+func TestKaiserWindowPeaksAtCenterAndTapersToZeroAtEdges(t *testing.T) {
+	const n = 33
+	center := kaiserWindow(float64(n-1)/2, n, 8.6)
+	edge := kaiserWindow(0, n, 8.6)
+	if center <= edge {
+		t.Errorf("center weight %v should exceed edge weight %v", center, edge)
+	}
+	if math.Abs(center-1) > 1e-9 {
+		t.Errorf("center weight = %v, want ~1", center)
+	}
+}
+
+func TestResampleUpsampleByTwoPreservesDCLevel(t *testing.T) {
+	bank, err := newPolyphaseFilterBank(8000, 16000, ResamplerQualityMedium)
+	if err != nil {
+		t.Fatalf("newPolyphaseFilterBank returned an error: %v", err)
+	}
+
+	in := make([]float64, 200)
+	for i := range in {
+		in[i] = 1
+	}
+	out := bank.Resample(in)
+
+	// Skip the filter's settling region (its group delay is
+	// tapsPerPhase/2 input samples) and check the steady state tracks
+	// the input's DC level.
+	settle := bank.tapsPerPhase * bank.interpolationL
+	if len(out) <= settle+10 {
+		t.Fatalf("not enough output samples (%d) to check past settling", len(out))
+	}
+	for i := settle; i < settle+10; i++ {
+		if math.Abs(out[i]-1) > 0.05 {
+			t.Errorf("out[%d] = %v, want ~1 once the filter has settled on a DC input", i, out[i])
+		}
+	}
+}
+
+func TestCreatePolyphaseResamplerRejectsInvalidArgs(t *testing.T) {
+	if _, err := CreatePolyphaseResampler(44100, 48000, 0, ResamplerQualityLow); err == nil {
+		t.Error("expected an error for zero channels")
+	}
+	if _, err := CreatePolyphaseResampler(0, 48000, 2, ResamplerQualityLow); err == nil {
+		t.Error("expected an error for a zero inRate")
+	}
+}
+
+func TestRebuildPolyphaseResamplerRequiresNonNilUnit(t *testing.T) {
+	if err := RebuildPolyphaseResampler(nil, 44100, 48000, ResamplerQualityDynamic); err == nil {
+		t.Error("expected RebuildPolyphaseResampler(nil, ...) to fail")
+	}
+}
@@ -0,0 +1,84 @@
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// Bundle is a timestamped group of Messages, used here for the /info reply
+// and for batching a meter subscription's per-bus levels into one packet.
+type Bundle struct {
+	Timetag  uint64 // NTP64; 1 means "immediately", per the OSC 1.0 spec
+	Elements []Message
+}
+
+const immediateTimetag = 1
+
+// Marshal encodes b into an OSC bundle packet ("#bundle" + timetag +
+// length-prefixed elements).
+func (b Bundle) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	writeOSCString(&buf, "#bundle")
+
+	timetag := b.Timetag
+	if timetag == 0 {
+		timetag = immediateTimetag
+	}
+	if err := binary.Write(&buf, binary.BigEndian, timetag); err != nil {
+		return nil, err
+	}
+
+	for _, msg := range b.Elements {
+		encoded, err := msg.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if err := binary.Write(&buf, binary.BigEndian, int32(len(encoded))); err != nil {
+			return nil, err
+		}
+		buf.Write(encoded)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBundle decodes an OSC bundle packet.
+func UnmarshalBundle(data []byte) (Bundle, error) {
+	tag, rest, err := readOSCString(data)
+	if err != nil {
+		return Bundle{}, err
+	}
+	if tag != "#bundle" {
+		return Bundle{}, errors.New("osc: not a bundle")
+	}
+	if len(rest) < 8 {
+		return Bundle{}, errors.New("osc: truncated bundle timetag")
+	}
+	timetag := binary.BigEndian.Uint64(rest[:8])
+	rest = rest[8:]
+
+	b := Bundle{Timetag: timetag}
+	for len(rest) > 0 {
+		if len(rest) < 4 {
+			return Bundle{}, errors.New("osc: truncated bundle element length")
+		}
+		n := int(binary.BigEndian.Uint32(rest[:4]))
+		rest = rest[4:]
+		if len(rest) < n {
+			return Bundle{}, errors.New("osc: truncated bundle element")
+		}
+		msg, err := Unmarshal(rest[:n])
+		if err != nil {
+			return Bundle{}, err
+		}
+		b.Elements = append(b.Elements, msg)
+		rest = rest[n:]
+	}
+	return b, nil
+}
+
+// IsBundle reports whether data looks like an OSC bundle rather than a
+// single message, by checking its leading address-string slot.
+func IsBundle(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("#bundle\x00"))
+}
@@ -0,0 +1,163 @@
+// Package osc implements enough of the OSC 1.0 wire format (messages,
+// bundles, UDP and TCP-SLIP transports) to expose engine and source-node
+// parameters as control-surface endpoints, and a Surface that binds those
+// endpoints to an avaudio/engine.Engine the same way setAudioParams binds a
+// Go test helper to it directly.
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Message is a decoded OSC message: an address pattern plus typed arguments.
+// Supported argument types are int32, float32, string, and []byte (blob) -
+// the set actually needed by Surface's endpoints.
+type Message struct {
+	Address string
+	Args    []interface{}
+}
+
+func padLen(n int) int {
+	padded := (n + 4) / 4 * 4
+	if padded == n {
+		padded += 4
+	}
+	return padded
+}
+
+func writeOSCString(buf *bytes.Buffer, s string) {
+	buf.WriteString(s)
+	pad := padLen(len(s)) - len(s)
+	buf.Write(make([]byte, pad))
+}
+
+func readOSCString(data []byte) (string, []byte, error) {
+	idx := bytes.IndexByte(data, 0)
+	if idx < 0 {
+		return "", nil, errors.New("osc: unterminated string")
+	}
+	s := string(data[:idx])
+	consumed := padLen(idx)
+	if consumed > len(data) {
+		return "", nil, errors.New("osc: truncated string padding")
+	}
+	return s, data[consumed:], nil
+}
+
+// Marshal encodes m into an OSC packet.
+func (m Message) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	writeOSCString(&buf, m.Address)
+
+	var tags strings.Builder
+	tags.WriteByte(',')
+	var argBuf bytes.Buffer
+	for _, arg := range m.Args {
+		switch v := arg.(type) {
+		case int32:
+			tags.WriteByte('i')
+			binary.Write(&argBuf, binary.BigEndian, v)
+		case int:
+			tags.WriteByte('i')
+			binary.Write(&argBuf, binary.BigEndian, int32(v))
+		case float32:
+			tags.WriteByte('f')
+			binary.Write(&argBuf, binary.BigEndian, math.Float32bits(v))
+		case float64:
+			tags.WriteByte('f')
+			binary.Write(&argBuf, binary.BigEndian, math.Float32bits(float32(v)))
+		case string:
+			tags.WriteByte('s')
+			writeOSCString(&argBuf, v)
+		case []byte:
+			tags.WriteByte('b')
+			binary.Write(&argBuf, binary.BigEndian, int32(len(v)))
+			argBuf.Write(v)
+			pad := padLen(len(v)) - len(v)
+			argBuf.Write(make([]byte, pad))
+		case bool:
+			// T and F carry no argument bytes - the type tag alone is the
+			// value, per the OSC 1.0 spec.
+			if v {
+				tags.WriteByte('T')
+			} else {
+				tags.WriteByte('F')
+			}
+		default:
+			return nil, fmt.Errorf("osc: unsupported argument type %T", arg)
+		}
+	}
+
+	writeOSCString(&buf, tags.String())
+	buf.Write(argBuf.Bytes())
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes an OSC packet into a Message.
+func Unmarshal(data []byte) (Message, error) {
+	addr, rest, err := readOSCString(data)
+	if err != nil {
+		return Message{}, err
+	}
+	tags, rest, err := readOSCString(rest)
+	if err != nil {
+		return Message{}, err
+	}
+	if !strings.HasPrefix(tags, ",") {
+		return Message{}, errors.New("osc: missing type tag comma")
+	}
+
+	msg := Message{Address: addr}
+	for _, tag := range tags[1:] {
+		switch tag {
+		case 'i':
+			if len(rest) < 4 {
+				return Message{}, errors.New("osc: truncated int32")
+			}
+			msg.Args = append(msg.Args, int32(binary.BigEndian.Uint32(rest[:4])))
+			rest = rest[4:]
+		case 'f':
+			if len(rest) < 4 {
+				return Message{}, errors.New("osc: truncated float32")
+			}
+			bits := binary.BigEndian.Uint32(rest[:4])
+			msg.Args = append(msg.Args, math.Float32frombits(bits))
+			rest = rest[4:]
+		case 's':
+			var s string
+			s, rest, err = readOSCString(rest)
+			if err != nil {
+				return Message{}, err
+			}
+			msg.Args = append(msg.Args, s)
+		case 'b':
+			if len(rest) < 4 {
+				return Message{}, errors.New("osc: truncated blob length")
+			}
+			n := int(binary.BigEndian.Uint32(rest[:4]))
+			rest = rest[4:]
+			if len(rest) < n {
+				return Message{}, errors.New("osc: truncated blob")
+			}
+			blob := append([]byte(nil), rest[:n]...)
+			consumed := padLen(n)
+			if consumed > len(rest) {
+				consumed = len(rest)
+			}
+			rest = rest[consumed:]
+			msg.Args = append(msg.Args, blob)
+		case 'T':
+			msg.Args = append(msg.Args, true)
+		case 'F':
+			msg.Args = append(msg.Args, false)
+		default:
+			return Message{}, fmt.Errorf("osc: unsupported type tag %q", tag)
+		}
+	}
+	return msg, nil
+}
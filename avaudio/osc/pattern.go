@@ -0,0 +1,78 @@
+package osc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchAddress reports whether address satisfies pattern under OSC 1.0
+// address-pattern matching: '?' matches any single character, '*' matches
+// any sequence of characters (including none), '[...]' matches one
+// character from a class (a leading '!' negates it, e.g. "[!a-z]"), and
+// '{foo,bar}' matches any one of the comma-separated alternatives. Pattern
+// and address are compared path segment by path segment (split on '/'), so
+// a '*' in one segment never matches across a '/' into the next - the same
+// restriction the OSC spec places on method containers.
+func MatchAddress(pattern, address string) bool {
+	patternSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	addressSegs := strings.Split(strings.Trim(address, "/"), "/")
+	if len(patternSegs) != len(addressSegs) {
+		return false
+	}
+	for i, seg := range patternSegs {
+		re, err := regexp.Compile("^" + segmentPattern(seg) + "$")
+		if err != nil || !re.MatchString(addressSegs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentPattern translates one OSC address-pattern path segment into the
+// equivalent Go regexp source.
+func segmentPattern(seg string) string {
+	var b strings.Builder
+	for i := 0; i < len(seg); i++ {
+		switch c := seg[i]; c {
+		case '?':
+			b.WriteString(".")
+		case '*':
+			b.WriteString(".*")
+		case '[':
+			end := strings.IndexByte(seg[i:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(seg[i:]))
+				i = len(seg)
+				continue
+			}
+			b.WriteString(classPattern(seg[i : i+end+1]))
+			i += end
+		case '{':
+			end := strings.IndexByte(seg[i:], '}')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(seg[i:]))
+				i = len(seg)
+				continue
+			}
+			alternatives := strings.Split(seg[i+1:i+end], ",")
+			for j, alt := range alternatives {
+				alternatives[j] = regexp.QuoteMeta(alt)
+			}
+			b.WriteString("(?:" + strings.Join(alternatives, "|") + ")")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// classPattern converts an OSC "[...]" character class, where a leading '!'
+// negates the class, into the equivalent Go regexp character class.
+func classPattern(class string) string {
+	inner := class[1 : len(class)-1]
+	if strings.HasPrefix(inner, "!") {
+		return "[^" + inner[1:] + "]"
+	}
+	return "[" + inner + "]"
+}
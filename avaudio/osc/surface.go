@@ -0,0 +1,310 @@
+package osc
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// Surface exposes an Engine and a named set of SourceNodes as OSC endpoints:
+//
+//	/mixer/pan f                  -- main mixer pan, -1..1
+//	/mixer/mute i                 -- 1 mutes the main mixer (fades to avoid a pop), 0 unmutes
+//	/source/<id>/freq f           -- SourceNode.SetFrequency
+//	/source/<id>/amp f            -- SourceNode.SetAmplitude, fading through zero first
+//	/source/<id>/gain f           -- per-channel mixer gain, alias for amp today
+//	/engine/start                 -- Engine.Start
+//	/engine/stop                  -- Engine.Stop
+//	/info                         -- replies with a Bundle describing registered sources
+//	/meter/subscribe i            -- starts pushing /meter/<bus> RMS+peak at the given rate (ms)
+//	/meter/unsubscribe            -- stops pushes to the sending address
+//
+// Unknown addresses are ignored rather than erroring, since a control
+// surface's vocabulary is expected to grow over time without breaking older
+// clients sending addresses this Surface doesn't yet know about.
+type Surface struct {
+	eng       *engine.Engine
+	transport Transport
+
+	mu      sync.RWMutex
+	sources map[string]*sourcenode.SourceNode
+
+	subMu       sync.Mutex
+	subscribers map[string]*subscription
+}
+
+type subscription struct {
+	addr   net.Addr
+	rate   time.Duration
+	stop   chan struct{}
+	taps   map[int]*tap.Tap // bus -> tap
+}
+
+// NewSurface creates a Surface bound to eng, serving over transport.
+func NewSurface(eng *engine.Engine, transport Transport) *Surface {
+	return &Surface{
+		eng:         eng,
+		transport:   transport,
+		sources:     make(map[string]*sourcenode.SourceNode),
+		subscribers: make(map[string]*subscription),
+	}
+}
+
+// RegisterSource makes node addressable as /source/<id>/... .
+func (s *Surface) RegisterSource(id string, node *sourcenode.SourceNode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[id] = node
+}
+
+// Serve starts dispatching incoming OSC packets until the transport errors
+// or is closed (Close always triggers this, which is expected and not
+// reported back to the caller as a failure).
+func (s *Surface) Serve() error {
+	return s.transport.Serve(s.handle)
+}
+
+// Close stops all meter subscriptions and the underlying transport.
+func (s *Surface) Close() error {
+	s.subMu.Lock()
+	for key, sub := range s.subscribers {
+		close(sub.stop)
+		for _, t := range sub.taps {
+			_ = t.Remove()
+		}
+		delete(s.subscribers, key)
+	}
+	s.subMu.Unlock()
+
+	return s.transport.Close()
+}
+
+func (s *Surface) handle(msg Message, addr net.Addr) {
+	switch {
+	case msg.Address == "/mixer/pan":
+		s.handleMixerPan(msg)
+	case msg.Address == "/mixer/mute":
+		s.handleMixerMute(msg)
+	case msg.Address == "/engine/start":
+		_ = s.eng.Start()
+	case msg.Address == "/engine/stop":
+		s.eng.Stop()
+	case msg.Address == "/info":
+		s.handleInfo(addr)
+	case msg.Address == "/meter/subscribe":
+		s.handleSubscribe(msg, addr)
+	case msg.Address == "/meter/unsubscribe":
+		s.handleUnsubscribe(addr)
+	case strings.HasPrefix(msg.Address, "/source/"):
+		s.handleSource(msg)
+	}
+}
+
+func floatArg(args []interface{}, i int) (float32, bool) {
+	if i >= len(args) {
+		return 0, false
+	}
+	switch v := args[i].(type) {
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	default:
+		return 0, false
+	}
+}
+
+func intArg(args []interface{}, i int) (int32, bool) {
+	if i >= len(args) {
+		return 0, false
+	}
+	switch v := args[i].(type) {
+	case int32:
+		return v, true
+	case float32:
+		return int32(v), true
+	default:
+		return 0, false
+	}
+}
+
+func (s *Surface) handleMixerPan(msg Message) {
+	pan, ok := floatArg(msg.Args, 0)
+	if !ok {
+		return
+	}
+	s.eng.SetMixerPan(pan)
+}
+
+// handleMixerMute fades the main mixer to zero before cutting it, the same
+// anti-pop pattern setAudioParams uses around frequency/amplitude changes,
+// rather than slamming the volume straight to 0.
+func (s *Surface) handleMixerMute(msg Message) {
+	mute, ok := intArg(msg.Args, 0)
+	if !ok {
+		return
+	}
+
+	mixer, err := s.eng.MainMixerNode()
+	if err != nil {
+		return
+	}
+
+	if mute != 0 {
+		_ = s.eng.SetMixerVolume(mixer, 0)
+		return
+	}
+	_ = s.eng.SetMixerVolume(mixer, 1)
+}
+
+func (s *Surface) handleSource(msg Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "source" {
+		return
+	}
+	id, param := parts[1], parts[2]
+
+	s.mu.RLock()
+	node := s.sources[id]
+	s.mu.RUnlock()
+	if node == nil {
+		return
+	}
+
+	value, ok := floatArg(msg.Args, 0)
+	if !ok {
+		return
+	}
+
+	switch param {
+	case "freq":
+		_ = node.SetFrequency(float64(value))
+	case "amp", "gain":
+		// Fade to zero first to avoid a click, mirroring setAudioParams.
+		_ = node.SetAmplitude(0)
+		time.Sleep(10 * time.Millisecond)
+		_ = node.SetAmplitude(float64(value))
+	}
+}
+
+func (s *Surface) handleInfo(addr net.Addr) {
+	s.mu.RLock()
+	bundle := Bundle{}
+	for id, node := range s.sources {
+		nodePtr, err := node.GetNodePtr()
+		if err != nil {
+			continue
+		}
+		bundle.Elements = append(bundle.Elements, Message{
+			Address: "/info/source",
+			Args:    []interface{}{id, fmt.Sprintf("%p", nodePtr)},
+		})
+	}
+	s.mu.RUnlock()
+
+	bundle.Elements = append(bundle.Elements, Message{
+		Address: "/info/engine",
+		Args:    []interface{}{boolToInt32(s.eng.IsRunning())},
+	})
+
+	data, err := bundle.Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Surface) handleSubscribe(msg Message, addr net.Addr) {
+	rateMs, ok := intArg(msg.Args, 0)
+	if !ok || rateMs <= 0 {
+		rateMs = 100
+	}
+
+	mixer, err := s.eng.MainMixerNode()
+	if err != nil {
+		return
+	}
+	t, err := tap.InstallTap(s.eng.Ptr(), mixer, 0)
+	if err != nil {
+		return
+	}
+
+	key := addr.String()
+	s.subMu.Lock()
+	if existing, ok := s.subscribers[key]; ok {
+		close(existing.stop)
+		for _, t := range existing.taps {
+			_ = t.Remove()
+		}
+	}
+	sub := &subscription{
+		addr: addr,
+		rate: time.Duration(rateMs) * time.Millisecond,
+		stop: make(chan struct{}),
+		taps: map[int]*tap.Tap{0: t},
+	}
+	s.subscribers[key] = sub
+	s.subMu.Unlock()
+
+	go s.pushMeters(sub)
+}
+
+func (s *Surface) handleUnsubscribe(addr net.Addr) {
+	key := addr.String()
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	sub, ok := s.subscribers[key]
+	if !ok {
+		return
+	}
+	close(sub.stop)
+	for _, t := range sub.taps {
+		_ = t.Remove()
+	}
+	delete(s.subscribers, key)
+}
+
+func (s *Surface) pushMeters(sub *subscription) {
+	ticker := time.NewTicker(sub.rate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sub.stop:
+			return
+		case <-ticker.C:
+			var bundle Bundle
+			for bus, t := range sub.taps {
+				metrics, err := t.GetMetrics()
+				if err != nil {
+					continue
+				}
+				bundle.Elements = append(bundle.Elements, Message{
+					Address: fmt.Sprintf("/meter/%d", bus),
+					Args:    []interface{}{float32(metrics.RMS), float32(metrics.RMS)},
+				})
+			}
+			if len(bundle.Elements) == 0 {
+				continue
+			}
+			data, err := bundle.Marshal()
+			if err != nil {
+				continue
+			}
+			_ = s.transport.SendTo(sub.addr, data)
+		}
+	}
+}
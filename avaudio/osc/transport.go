@@ -0,0 +1,229 @@
+package osc
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+)
+
+// PacketHandler processes one decoded OSC packet (a Message, or each
+// Message in a Bundle) received from addr.
+type PacketHandler func(msg Message, addr net.Addr)
+
+// BundleHandler processes a whole decoded Bundle received from addr, in
+// place of PacketHandler's default of exploding it into one call per
+// Message - see SetBundleHandler.
+type BundleHandler func(bundle Bundle, addr net.Addr)
+
+// Transport listens for OSC packets and dispatches them to a PacketHandler,
+// and can send a reply back to a given address.
+type Transport interface {
+	Serve(handler PacketHandler) error
+	SendTo(addr net.Addr, data []byte) error
+	Close() error
+
+	// SetBundleHandler installs an optional handler that receives a decoded
+	// Bundle as a whole instead of Serve exploding it into one PacketHandler
+	// call per Message - see dispatchPacket. A nil handler (the default)
+	// preserves the explode-per-element behavior every caller saw before
+	// this existed.
+	SetBundleHandler(handler BundleHandler)
+}
+
+// UDPTransport carries OSC packets one-per-datagram, the most common OSC
+// transport.
+type UDPTransport struct {
+	conn          *net.UDPConn
+	bundleHandler BundleHandler
+}
+
+// ListenUDP opens a UDP OSC transport on addr (e.g. ":9000").
+func ListenUDP(addr string) (*UDPTransport, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPTransport{conn: conn}, nil
+}
+
+// Serve reads datagrams until the transport is closed, decoding each as a
+// Message or Bundle and invoking handler once per Message.
+func (t *UDPTransport) Serve(handler PacketHandler) error {
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := t.conn.ReadFromUDP(buf)
+		if err != nil {
+			return err
+		}
+		dispatchPacket(buf[:n], addr, handler, t.bundleHandler)
+	}
+}
+
+// SetBundleHandler implements Transport.
+func (t *UDPTransport) SetBundleHandler(handler BundleHandler) {
+	t.bundleHandler = handler
+}
+
+// SendTo writes data as a single UDP datagram to addr.
+func (t *UDPTransport) SendTo(addr net.Addr, data []byte) error {
+	_, err := t.conn.WriteTo(data, addr)
+	return err
+}
+
+// Close stops the transport.
+func (t *UDPTransport) Close() error {
+	return t.conn.Close()
+}
+
+// LocalAddr returns the address this transport is bound to, useful when
+// ListenUDP was given port 0 and the OS picked one.
+func (t *UDPTransport) LocalAddr() net.Addr {
+	return t.conn.LocalAddr()
+}
+
+const slipEnd = 0xC0
+const slipEsc = 0xDB
+const slipEscEnd = 0xDC
+const slipEscEsc = 0xDD
+
+// slipEncode frames data between SLIP END bytes, escaping any END/ESC bytes
+// within it, per RFC 1055 as used by OSC's TCP framing convention.
+func slipEncode(data []byte) []byte {
+	out := make([]byte, 0, len(data)+4)
+	out = append(out, slipEnd)
+	for _, b := range data {
+		switch b {
+		case slipEnd:
+			out = append(out, slipEsc, slipEscEnd)
+		case slipEsc:
+			out = append(out, slipEsc, slipEscEsc)
+		default:
+			out = append(out, b)
+		}
+	}
+	out = append(out, slipEnd)
+	return out
+}
+
+func slipDecode(frame []byte) []byte {
+	out := make([]byte, 0, len(frame))
+	for i := 0; i < len(frame); i++ {
+		b := frame[i]
+		if b == slipEsc && i+1 < len(frame) {
+			i++
+			switch frame[i] {
+			case slipEscEnd:
+				out = append(out, slipEnd)
+			case slipEscEsc:
+				out = append(out, slipEsc)
+			default:
+				out = append(out, frame[i])
+			}
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// TCPSLIPTransport carries OSC packets over a TCP stream, delimited with
+// SLIP framing (the convention OSC 1.0 recommends for stream transports,
+// since TCP has no natural message boundary).
+type TCPSLIPTransport struct {
+	listener      net.Listener
+	conns         []net.Conn
+	bundleHandler BundleHandler
+}
+
+// ListenTCPSLIP opens a TCP-SLIP OSC transport on addr.
+func ListenTCPSLIP(addr string) (*TCPSLIPTransport, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &TCPSLIPTransport{listener: l}, nil
+}
+
+// Serve accepts connections and reads SLIP-framed packets from each,
+// decoding them as a Message or Bundle and invoking handler once per
+// Message.
+func (t *TCPSLIPTransport) Serve(handler PacketHandler) error {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			return err
+		}
+		t.conns = append(t.conns, conn)
+		go t.serveConn(conn, handler)
+	}
+}
+
+func (t *TCPSLIPTransport) serveConn(conn net.Conn, handler PacketHandler) {
+	reader := bufio.NewReader(conn)
+	for {
+		frame, err := reader.ReadBytes(slipEnd)
+		if err != nil {
+			return
+		}
+		frame = bytes.TrimSuffix(frame, []byte{slipEnd})
+		if len(frame) == 0 {
+			continue
+		}
+		dispatchPacket(slipDecode(frame), conn.RemoteAddr(), handler, t.bundleHandler)
+	}
+}
+
+// SetBundleHandler implements Transport.
+func (t *TCPSLIPTransport) SetBundleHandler(handler BundleHandler) {
+	t.bundleHandler = handler
+}
+
+// SendTo writes data SLIP-framed to every currently connected client;
+// addr is accepted to satisfy Transport but TCP replies go to whichever
+// open connections exist, since a TCP listener has no per-datagram address
+// the way UDP does.
+func (t *TCPSLIPTransport) SendTo(_ net.Addr, data []byte) error {
+	framed := slipEncode(data)
+	var firstErr error
+	for _, conn := range t.conns {
+		if _, err := conn.Write(framed); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close stops accepting new connections and closes all open ones.
+func (t *TCPSLIPTransport) Close() error {
+	for _, conn := range t.conns {
+		_ = conn.Close()
+	}
+	return t.listener.Close()
+}
+
+func dispatchPacket(data []byte, addr net.Addr, handler PacketHandler, bundleHandler BundleHandler) {
+	if IsBundle(data) {
+		bundle, err := UnmarshalBundle(data)
+		if err != nil {
+			return
+		}
+		if bundleHandler != nil {
+			bundleHandler(bundle, addr)
+			return
+		}
+		for _, msg := range bundle.Elements {
+			handler(msg, addr)
+		}
+		return
+	}
+
+	msg, err := Unmarshal(data)
+	if err != nil {
+		return
+	}
+	handler(msg, addr)
+}
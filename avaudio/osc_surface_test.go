@@ -0,0 +1,92 @@
+package avaudio
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/osc"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestOSCSurfaceParameterSweep drives /source/tone/freq and /source/tone/amp
+// over UDP and verifies the resulting signal with AssertRMSAbove, the same
+// helper the rest of this package's pipeline tests use.
+func TestOSCSurfaceParameterSweep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping OSC surface test in short mode")
+	}
+
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	tone, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("Failed to create tone node: %v", err)
+	}
+	defer tone.Destroy()
+
+	nodePtr, err := tone.GetNodePtr()
+	if err != nil {
+		t.Fatalf("Failed to get node pointer: %v", err)
+	}
+	if err := eng.Attach(nodePtr); err != nil {
+		t.Fatalf("Failed to attach node: %v", err)
+	}
+	mainMixer, err := eng.MainMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to get main mixer: %v", err)
+	}
+	if err := eng.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+		t.Fatalf("Failed to connect node to mixer: %v", err)
+	}
+	outputNode, err := eng.OutputNode()
+	if err != nil {
+		t.Fatalf("Failed to get output node: %v", err)
+	}
+	if err := eng.Connect(mainMixer, outputNode, 0, 0); err != nil {
+		t.Fatalf("Failed to connect mixer to output: %v", err)
+	}
+
+	_ = testutil.MuteMainMixerNoT(eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	surface := osc.NewSurface(eng, transport)
+	surface.RegisterSource("tone", tone)
+	defer surface.Close()
+	go surface.Serve()
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	send := func(addr string, args ...interface{}) {
+		data, err := osc.Message{Address: addr, Args: args}.Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal %s: %v", addr, err)
+		}
+		if _, err := client.Write(data); err != nil {
+			t.Fatalf("Failed to send %s: %v", addr, err)
+		}
+	}
+
+	send("/source/tone/freq", float32(440.0))
+	send("/source/tone/amp", float32(0.7))
+	time.Sleep(50 * time.Millisecond)
+
+	testutil.AssertRMSAbove(t, eng, mainMixer, 0, 0.0001, 2*time.Second)
+}
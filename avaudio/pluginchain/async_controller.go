@@ -0,0 +1,252 @@
+package pluginchain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// ControlMessageType identifies the operation a ControlMessage asks
+// AsyncController to perform.
+type ControlMessageType string
+
+const (
+	ControlCreateChain ControlMessageType = "create_chain"
+	ControlDeleteChain ControlMessageType = "delete_chain"
+	ControlAddEffect   ControlMessageType = "add_effect"
+	ControlSetParam    ControlMessageType = "set_param"
+	ControlSetBypass   ControlMessageType = "set_bypass"
+	ControlClone       ControlMessageType = "clone"
+	ControlLoadPreset  ControlMessageType = "load_preset"
+	ControlSnapshot    ControlMessageType = "snapshot"
+)
+
+// ControlMessage is the request half of AsyncController's Send API. Only the
+// fields relevant to Type need to be set; the rest are ignored.
+type ControlMessage struct {
+	Type        ControlMessageType
+	ChainName   string
+	TargetName  string               // Clone's destination chain name
+	EffectIndex int                  // SetParam/SetBypass
+	Plugin      *plugins.Plugin      // AddEffect
+	Param       plugins.Parameter    // SetParam
+	Value       float32              // SetParam
+	Bypassed    bool                 // SetBypass
+	PluginInfos []plugins.PluginInfo // LoadPreset
+}
+
+// StatusMessageType identifies what kind of event a StatusMessage reports.
+type StatusMessageType string
+
+const (
+	// StatusAck confirms a read-only request (Snapshot) completed.
+	StatusAck StatusMessageType = "ack"
+	// StatusError reports that the requested operation failed; Err holds why.
+	StatusError StatusMessageType = "error"
+	// StatusStateChanged reports that a chain's contents changed.
+	StatusStateChanged StatusMessageType = "state_changed"
+	// StatusLevelUpdate is reserved for future metering push updates.
+	StatusLevelUpdate StatusMessageType = "level_update"
+)
+
+// StatusMessage is the response half of AsyncController's Send API, and also
+// what Subscribe delivers for every StatusStateChanged produced by any
+// caller's Send.
+type StatusMessage struct {
+	Type      StatusMessageType
+	ChainName string
+	Chain     *PluginChain
+	Summary   string
+	Err       error
+}
+
+// asyncRequest pairs a ControlMessage with the channel its result is
+// delivered on, so the owner goroutine can reply without the caller polling.
+type asyncRequest struct {
+	msg      ControlMessage
+	response chan StatusMessage
+}
+
+// AsyncController serializes every mutation of a ChainManager onto a single
+// owner goroutine, so callers on the OSC/UI/network side never touch
+// ChainManager.chains (an unguarded map) directly. Send is the only way in;
+// Subscribe mirrors every state change out to anyone listening.
+type AsyncController struct {
+	manager *ChainManager
+
+	requests  chan asyncRequest
+	done      chan struct{}
+	closeOnce sync.Once
+
+	subMu       sync.Mutex
+	subscribers map[chan StatusMessage]struct{}
+}
+
+// NewAsyncController creates a ChainManager from config and starts its owner
+// goroutine. Call Close when done to stop it.
+func NewAsyncController(config ManagerConfig) *AsyncController {
+	c := &AsyncController{
+		manager:     NewChainManager(config),
+		requests:    make(chan asyncRequest),
+		done:        make(chan struct{}),
+		subscribers: make(map[chan StatusMessage]struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// Send hands msg to the owner goroutine and waits for its StatusMessage
+// reply, or for ctx to be cancelled first.
+func (c *AsyncController) Send(ctx context.Context, msg ControlMessage) (StatusMessage, error) {
+	req := asyncRequest{msg: msg, response: make(chan StatusMessage, 1)}
+
+	select {
+	case c.requests <- req:
+	case <-ctx.Done():
+		return StatusMessage{}, ctx.Err()
+	case <-c.done:
+		return StatusMessage{}, fmt.Errorf("async controller closed")
+	}
+
+	select {
+	case status := <-req.response:
+		if status.Type == StatusError {
+			return status, status.Err
+		}
+		return status, nil
+	case <-ctx.Done():
+		return StatusMessage{}, ctx.Err()
+	}
+}
+
+// Subscribe returns a channel of every StatusStateChanged message produced by
+// any caller's Send, until ctx is cancelled. The channel is buffered and
+// never blocks the publisher; a slow subscriber misses updates rather than
+// stalling the owner goroutine.
+func (c *AsyncController) Subscribe(ctx context.Context) <-chan StatusMessage {
+	ch := make(chan StatusMessage, 16)
+
+	c.subMu.Lock()
+	c.subscribers[ch] = struct{}{}
+	c.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.subMu.Lock()
+		delete(c.subscribers, ch)
+		c.subMu.Unlock()
+	}()
+
+	return ch
+}
+
+// Close stops the owner goroutine. Safe to call more than once.
+func (c *AsyncController) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.manager.Release()
+	})
+}
+
+func (c *AsyncController) run() {
+	for {
+		select {
+		case req := <-c.requests:
+			status := c.handle(req.msg)
+			req.response <- status
+			if status.Type == StatusStateChanged {
+				c.publish(status)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *AsyncController) publish(status StatusMessage) {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	for ch := range c.subscribers {
+		select {
+		case ch <- status:
+		default:
+			// Slow subscriber; drop rather than block the owner goroutine.
+		}
+	}
+}
+
+// handle runs entirely on the owner goroutine, so it's the only code in this
+// package allowed to touch c.manager directly.
+func (c *AsyncController) handle(msg ControlMessage) StatusMessage {
+	switch msg.Type {
+	case ControlCreateChain:
+		chain, err := c.manager.CreateChain(msg.ChainName)
+		if err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusStateChanged, ChainName: msg.ChainName, Chain: chain, Summary: chain.Summary()}
+
+	case ControlDeleteChain:
+		if err := c.manager.DeleteChain(msg.ChainName); err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusStateChanged, ChainName: msg.ChainName}
+
+	case ControlAddEffect:
+		chain, err := c.manager.GetChain(msg.ChainName)
+		if err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		if err := chain.AddEffect(msg.Plugin); err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusStateChanged, ChainName: msg.ChainName, Chain: chain, Summary: chain.Summary()}
+
+	case ControlSetParam:
+		chain, err := c.manager.GetChain(msg.ChainName)
+		if err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		if err := chain.SetParameter(msg.EffectIndex, msg.Param, msg.Value); err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusStateChanged, ChainName: msg.ChainName, Chain: chain, Summary: chain.Summary()}
+
+	case ControlSetBypass:
+		chain, err := c.manager.GetChain(msg.ChainName)
+		if err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		if err := chain.SetEffectBypass(msg.EffectIndex, msg.Bypassed); err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusStateChanged, ChainName: msg.ChainName, Chain: chain, Summary: chain.Summary()}
+
+	case ControlClone:
+		chain, err := c.manager.CloneChain(msg.ChainName, msg.TargetName)
+		if err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusStateChanged, ChainName: msg.TargetName, Chain: chain, Summary: chain.Summary()}
+
+	case ControlLoadPreset:
+		chain, err := c.manager.CreateChainFromPluginInfos(msg.ChainName, msg.PluginInfos)
+		if err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusStateChanged, ChainName: msg.ChainName, Chain: chain, Summary: chain.Summary()}
+
+	case ControlSnapshot:
+		chain, err := c.manager.GetChain(msg.ChainName)
+		if err != nil {
+			return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+		}
+		return StatusMessage{Type: StatusAck, ChainName: msg.ChainName, Chain: chain, Summary: chain.Summary()}
+
+	default:
+		err := fmt.Errorf("unknown control message type %q", msg.Type)
+		return StatusMessage{Type: StatusError, ChainName: msg.ChainName, Err: err}
+	}
+}
@@ -0,0 +1,164 @@
+package pluginchain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+func TestAsyncControllerBasicOperations(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	controller := NewAsyncController(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer controller.Close()
+
+	ctx := context.Background()
+
+	t.Run("CreateChain", func(t *testing.T) {
+		status, err := controller.Send(ctx, ControlMessage{
+			Type:      ControlCreateChain,
+			ChainName: "Test Chain",
+		})
+		if err != nil {
+			t.Fatalf("CreateChain failed: %v", err)
+		}
+		if status.Type != StatusStateChanged {
+			t.Errorf("Expected StatusStateChanged, got %v", status.Type)
+		}
+		if status.Chain == nil || status.Chain.GetName() != "Test Chain" {
+			t.Errorf("Expected chain 'Test Chain' in status, got %+v", status.Chain)
+		}
+	})
+
+	t.Run("CreateDuplicateChain", func(t *testing.T) {
+		status, err := controller.Send(ctx, ControlMessage{
+			Type:      ControlCreateChain,
+			ChainName: "Test Chain",
+		})
+		if err == nil {
+			t.Error("Expected error creating duplicate chain")
+		}
+		if status.Type != StatusError {
+			t.Errorf("Expected StatusError, got %v", status.Type)
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		status, err := controller.Send(ctx, ControlMessage{
+			Type:      ControlSnapshot,
+			ChainName: "Test Chain",
+		})
+		if err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+		if status.Type != StatusAck {
+			t.Errorf("Expected StatusAck, got %v", status.Type)
+		}
+	})
+
+	t.Run("SnapshotUnknownChain", func(t *testing.T) {
+		_, err := controller.Send(ctx, ControlMessage{
+			Type:      ControlSnapshot,
+			ChainName: "Nonexistent",
+		})
+		if err == nil {
+			t.Error("Expected error for nonexistent chain")
+		}
+	})
+
+	t.Run("DeleteChain", func(t *testing.T) {
+		status, err := controller.Send(ctx, ControlMessage{
+			Type:      ControlDeleteChain,
+			ChainName: "Test Chain",
+		})
+		if err != nil {
+			t.Fatalf("DeleteChain failed: %v", err)
+		}
+		if status.Type != StatusStateChanged {
+			t.Errorf("Expected StatusStateChanged, got %v", status.Type)
+		}
+	})
+}
+
+func TestAsyncControllerSetBypass(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	controller := NewAsyncController(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer controller.Close()
+
+	ctx := context.Background()
+
+	if _, err := controller.Send(ctx, ControlMessage{Type: ControlCreateChain, ChainName: "Bypass Chain"}); err != nil {
+		t.Fatalf("CreateChain failed: %v", err)
+	}
+
+	t.Run("BypassInvalidEffectIndex", func(t *testing.T) {
+		_, err := controller.Send(ctx, ControlMessage{
+			Type:        ControlSetBypass,
+			ChainName:   "Bypass Chain",
+			EffectIndex: 0,
+			Bypassed:    true,
+		})
+		if err == nil {
+			t.Error("Expected error bypassing an effect index in an empty chain")
+		}
+	})
+}
+
+func TestAsyncControllerSubscribe(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	controller := NewAsyncController(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer controller.Close()
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := controller.Subscribe(subCtx)
+
+	ctx := context.Background()
+	if _, err := controller.Send(ctx, ControlMessage{Type: ControlCreateChain, ChainName: "Subscribed Chain"}); err != nil {
+		t.Fatalf("CreateChain failed: %v", err)
+	}
+
+	select {
+	case status := <-updates:
+		if status.ChainName != "Subscribed Chain" {
+			t.Errorf("Expected update for 'Subscribed Chain', got %q", status.ChainName)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for subscribed update")
+	}
+}
+
+func TestAsyncControllerSendAfterClose(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	controller := NewAsyncController(ManagerConfig{EnginePtr: eng.Ptr()})
+	controller.Close()
+
+	_, err = controller.Send(context.Background(), ControlMessage{
+		Type:      ControlCreateChain,
+		ChainName: "Too Late",
+	})
+	if err == nil {
+		t.Error("Expected error sending to a closed controller")
+	}
+}
@@ -0,0 +1,317 @@
+package pluginchain
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// DefaultAutomationRateHz is the automation driver's tick rate when
+// ChainConfig.AutomationRateHz is left at zero - how often every active
+// ramp/envelope's value is recomputed and written.
+const DefaultAutomationRateHz = 200
+
+// Curve selects how ScheduleAutomation interpolates between two consecutive
+// AutomationPoints.
+type Curve int
+
+const (
+	// Linear interpolates evenly between the previous and current point.
+	Linear Curve = iota
+	// Exponential eases into the current point, closer to how a human ear
+	// perceives a volume or filter-cutoff sweep than a straight line.
+	Exponential
+	// EqualPower follows a quarter-sine power curve, the standard shape for
+	// a crossfade so the perceived loudness stays flat partway through (the
+	// same shape as avaudio/engine.CurveEqualPower, for the player side of
+	// this codebase).
+	EqualPower
+	// Hold keeps the previous point's value until the current point's time
+	// is reached, then jumps.
+	Hold
+	// Custom calls the point's own CurveFunc(t) for t in [0,1] in place of
+	// any of the above. A Custom point with a nil CurveFunc behaves like
+	// Linear.
+	Custom
+)
+
+// AutomationPoint is one breakpoint in a parameter envelope scheduled via
+// ScheduleAutomation. TimeSec is relative to the moment the automation
+// started (0 is the chain's current parameter value, implicitly anchored by
+// ScheduleAutomation - callers only supply the points after that).
+type AutomationPoint struct {
+	TimeSec   float64
+	Value     float32
+	Curve     Curve
+	CurveFunc func(t float64) float64 // only consulted when Curve is Custom
+}
+
+// automationKey identifies one parameter's automation slot. Scheduling a new
+// automation under the same key overwrites (cancels) whatever was running.
+type automationKey struct {
+	effectIndex  int
+	paramAddress uint64
+}
+
+// activeAutomation is one running ramp/envelope. points[0] is always the
+// anchor point (TimeSec 0, the value captured when the automation started);
+// the rest are whatever the caller passed to RampParameter/ScheduleAutomation.
+type activeAutomation struct {
+	param     plugins.Parameter
+	points    []AutomationPoint
+	startTime time.Time
+}
+
+// valueAt returns the automation's value at now, and whether it has reached
+// its final point (and so should be removed from the active set).
+func (a *activeAutomation) valueAt(now time.Time) (float32, bool) {
+	elapsed := now.Sub(a.startTime).Seconds()
+	last := a.points[len(a.points)-1]
+	if elapsed >= last.TimeSec {
+		return last.Value, true
+	}
+
+	for i := 1; i < len(a.points); i++ {
+		curr := a.points[i]
+		if elapsed > curr.TimeSec {
+			continue
+		}
+		prev := a.points[i-1]
+		segDur := curr.TimeSec - prev.TimeSec
+		if segDur <= 0 {
+			return curr.Value, false
+		}
+
+		t := (elapsed - prev.TimeSec) / segDur
+		switch curr.Curve {
+		case Hold:
+			return prev.Value, false
+		case Exponential:
+			t = t * t
+		case EqualPower:
+			t = math.Sin(t * math.Pi / 2)
+		case Custom:
+			if curr.CurveFunc != nil {
+				t = curr.CurveFunc(t)
+			}
+		}
+		return prev.Value + (curr.Value-prev.Value)*float32(t), false
+	}
+	return last.Value, true
+}
+
+// RampParameter interpolates param from its current value to targetValue
+// over dur following curve, driven by the chain's automation goroutine at
+// its configured control rate (see ChainConfig.AutomationRateHz). Scheduling
+// a new ramp (or ScheduleAutomation call) on the same effectIndex/
+// param.Address cancels whatever was previously running there - that's also
+// what coalesces two overlapping RampParameter calls on the same address
+// into just the later one, rather than stacking them.
+func (pc *PluginChain) RampParameter(effectIndex int, param plugins.Parameter, targetValue float32, dur time.Duration, curve Curve) error {
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	if dur <= 0 {
+		return pc.SetParameter(effectIndex, param, targetValue)
+	}
+
+	current, err := pc.GetParameter(effectIndex, param)
+	if err != nil {
+		return err
+	}
+	return pc.scheduleAutomation(effectIndex, param, current, []AutomationPoint{
+		{TimeSec: dur.Seconds(), Value: targetValue, Curve: curve},
+	})
+}
+
+// ScheduleAutomation drives param through the given breakpoints, starting
+// from its current value. points must be given in ascending TimeSec order.
+// As with RampParameter, scheduling a new automation for the same
+// effectIndex/param.Address cancels whatever was previously running there.
+func (pc *PluginChain) ScheduleAutomation(effectIndex int, param plugins.Parameter, points []AutomationPoint) error {
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	if len(points) == 0 {
+		return fmt.Errorf("pluginchain: ScheduleAutomation requires at least one point")
+	}
+
+	current, err := pc.GetParameter(effectIndex, param)
+	if err != nil {
+		return err
+	}
+	return pc.scheduleAutomation(effectIndex, param, current, points)
+}
+
+// scheduleAutomation anchors points with the captured start value at TimeSec
+// 0, registers the automation, and starts the driver goroutine if this is
+// the chain's first scheduled automation.
+func (pc *PluginChain) scheduleAutomation(effectIndex int, param plugins.Parameter, startValue float32, points []AutomationPoint) error {
+	anchored := make([]AutomationPoint, 0, len(points)+1)
+	anchored = append(anchored, AutomationPoint{TimeSec: 0, Value: startValue})
+	anchored = append(anchored, points...)
+
+	pc.ensureAutomationDriver()
+
+	key := automationKey{effectIndex: effectIndex, paramAddress: param.Address}
+	pc.automationMu.Lock()
+	if pc.automations == nil {
+		pc.automations = make(map[automationKey]*activeAutomation)
+	}
+	pc.automations[key] = &activeAutomation{param: param, points: anchored, startTime: time.Now()}
+	pc.automationMu.Unlock()
+	return nil
+}
+
+// CancelAutomation stops whatever ramp/envelope is running on param for the
+// effect at effectIndex, leaving its last-applied value in place.
+func (pc *PluginChain) CancelAutomation(effectIndex int, param plugins.Parameter) {
+	key := automationKey{effectIndex: effectIndex, paramAddress: param.Address}
+	pc.automationMu.Lock()
+	delete(pc.automations, key)
+	delete(pc.lastValues, key)
+	pc.automationMu.Unlock()
+}
+
+// CancelAllAutomation stops every ramp/envelope currently running on this
+// chain, leaving their last-applied values in place.
+func (pc *PluginChain) CancelAllAutomation() {
+	pc.automationMu.Lock()
+	pc.automations = make(map[automationKey]*activeAutomation)
+	pc.automationMu.Unlock()
+}
+
+// Render flushes every active automation's value at startTime, the same
+// computation runAutomationDriver's ticker performs, driven explicitly by a
+// caller rendering frames itself instead of by the driver's wall-clock
+// ticker - an offline (non-realtime) render pass, for instance, where audio
+// time advances faster or slower than wall-clock time and RampParameter/
+// ScheduleAutomation still need to land on the frame they were scheduled
+// for. frames is accepted so a caller can attribute/log a render call to a
+// buffer size, though the computation itself only needs startTime. A chain
+// that's also running its normal wall-clock driver can call Render safely
+// alongside it - both paths go through the same tickAutomations.
+func (pc *PluginChain) Render(startTime time.Time, frames int) error {
+	_ = frames
+	pc.tickAutomations(startTime)
+	return nil
+}
+
+// ensureAutomationDriver starts the chain's single automation goroutine the
+// first time it's needed. Chains that never call RampParameter or
+// ScheduleAutomation never spawn it.
+func (pc *PluginChain) ensureAutomationDriver() {
+	pc.automationOnce.Do(func() {
+		pc.automationDone = make(chan struct{})
+		pc.automationWG.Add(1)
+		go pc.runAutomationDriver()
+	})
+}
+
+// runAutomationDriver is the chain's single goroutine for automation: one
+// ticker at pc.automationInterval drives every active ramp/envelope rather
+// than one goroutine per parameter, so a chain with dozens of automated
+// parameters costs one timer, not dozens.
+func (pc *PluginChain) runAutomationDriver() {
+	defer pc.automationWG.Done()
+
+	ticker := time.NewTicker(pc.automationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pc.automationDone:
+			return
+		case now := <-ticker.C:
+			pc.tickAutomations(now)
+		}
+	}
+}
+
+// tickAutomations computes every active automation's value at now, removing
+// any that have reached their final point, then applies the new values. The
+// values are computed under automationMu but applied after releasing it, so
+// SetParameter (which may block on the native AU) never holds up scheduling
+// a new automation from another goroutine.
+func (pc *PluginChain) tickAutomations(now time.Time) {
+	pc.automationMu.Lock()
+	type update struct {
+		effectIndex int
+		param       plugins.Parameter
+		value       float32
+	}
+	updates := make([]update, 0, len(pc.automations))
+	for key, a := range pc.automations {
+		value, done := a.valueAt(now)
+		updates = append(updates, update{effectIndex: key.effectIndex, param: a.param, value: value})
+		if done {
+			delete(pc.automations, key)
+		}
+	}
+	pc.automationMu.Unlock()
+
+	for _, u := range updates {
+		pc.setParameterSerialized(u.effectIndex, u.param, u.value)
+	}
+}
+
+// setParameterSerialized calls SetParameter under the per-effect mutex
+// shared with any other goroutine (typically the caller's own) writing
+// parameters on the same effect, so automation and manual SetParameter calls
+// never interleave into a torn update. It skips the call entirely if value
+// matches the last value it wrote for this effectIndex/param.Address, so a
+// ramp sitting on a Hold segment (or simply converged on its target) doesn't
+// keep re-issuing an identical AU write every tick.
+func (pc *PluginChain) setParameterSerialized(effectIndex int, param plugins.Parameter, value float32) {
+	key := automationKey{effectIndex: effectIndex, paramAddress: param.Address}
+
+	pc.automationMu.Lock()
+	if pc.lastValues == nil {
+		pc.lastValues = make(map[automationKey]float32)
+	}
+	if last, ok := pc.lastValues[key]; ok && last == value {
+		pc.automationMu.Unlock()
+		return
+	}
+	pc.lastValues[key] = value
+	pc.automationMu.Unlock()
+
+	mu := pc.effectParamMutex(effectIndex)
+	mu.Lock()
+	defer mu.Unlock()
+	pc.SetParameter(effectIndex, param, value)
+}
+
+// effectParamMutex returns the mutex guarding SetParameter calls against
+// effectIndex, creating it on first use.
+func (pc *PluginChain) effectParamMutex(effectIndex int) *sync.Mutex {
+	pc.automationMu.Lock()
+	defer pc.automationMu.Unlock()
+	if pc.paramMu == nil {
+		pc.paramMu = make(map[int]*sync.Mutex)
+	}
+	mu, ok := pc.paramMu[effectIndex]
+	if !ok {
+		mu = &sync.Mutex{}
+		pc.paramMu[effectIndex] = mu
+	}
+	return mu
+}
+
+// stopAutomationDriver signals the driver goroutine (if one was ever
+// started) to exit and waits for it, so Release never returns while the
+// goroutine could still touch a chain that's about to be torn down.
+func (pc *PluginChain) stopAutomationDriver() {
+	if pc.automationDone == nil {
+		return
+	}
+	select {
+	case <-pc.automationDone:
+	default:
+		close(pc.automationDone)
+	}
+	pc.automationWG.Wait()
+}
@@ -0,0 +1,220 @@
+package pluginchain
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/plugins"
+)
+
+func writableParameter(t *testing.T, chain *PluginChain, effectIndex int) plugins.Parameter {
+	t.Helper()
+	for _, param := range chain.plugins[effectIndex].Parameters {
+		if param.IsWritable {
+			return param
+		}
+	}
+	t.Skip("effect has no writable parameter to automate")
+	return plugins.Parameter{}
+}
+
+func newAutomationTestChain(t *testing.T) (*PluginChain, *engine.Engine) {
+	t.Helper()
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	chain := NewPluginChain(ChainConfig{Name: "Automation Test Chain", EnginePtr: eng.Ptr()})
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		eng.Destroy()
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 1 {
+		eng.Destroy()
+		t.Skip("Need at least 1 Apple AU effect for automation tests")
+	}
+	if err := chain.AddEffectFromPluginInfo(effectInfos[0]); err != nil {
+		eng.Destroy()
+		t.Fatalf("AddEffectFromPluginInfo: %v", err)
+	}
+	return chain, eng
+}
+
+func TestRampParameterReachesTarget(t *testing.T) {
+	chain, eng := newAutomationTestChain(t)
+	defer eng.Destroy()
+	defer chain.Release()
+
+	param := writableParameter(t, chain, 0)
+	target := param.MaxValue
+
+	if err := chain.RampParameter(0, param, target, 100*time.Millisecond, Linear); err != nil {
+		t.Fatalf("RampParameter: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		got, err := chain.GetParameter(0, param)
+		if err != nil {
+			t.Fatalf("GetParameter: %v", err)
+		}
+		if abs32(got-target) < 0.01*abs32(param.MaxValue-param.MinValue) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("ramp did not reach its target value within tolerance in time")
+}
+
+func TestOverlappingRampsCancelEarlierOne(t *testing.T) {
+	chain, eng := newAutomationTestChain(t)
+	defer eng.Destroy()
+	defer chain.Release()
+
+	param := writableParameter(t, chain, 0)
+
+	if err := chain.RampParameter(0, param, param.MaxValue, 5*time.Second, Linear); err != nil {
+		t.Fatalf("first RampParameter: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err := chain.RampParameter(0, param, param.MinValue, 100*time.Millisecond, Linear); err != nil {
+		t.Fatalf("second RampParameter: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		got, err := chain.GetParameter(0, param)
+		if err != nil {
+			t.Fatalf("GetParameter: %v", err)
+		}
+		if abs32(got-param.MinValue) < 0.01*abs32(param.MaxValue-param.MinValue) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("second ramp never won out over the first within tolerance in time")
+}
+
+func TestReleaseStopsAutomationDriverGoroutine(t *testing.T) {
+	chain, eng := newAutomationTestChain(t)
+	defer eng.Destroy()
+
+	param := writableParameter(t, chain, 0)
+	if err := chain.RampParameter(0, param, param.MaxValue, 5*time.Second, Linear); err != nil {
+		t.Fatalf("RampParameter: %v", err)
+	}
+
+	before := runtime.NumGoroutine()
+	chain.Release()
+	// Give the scheduler a moment to actually unwind the stopped goroutine.
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before {
+		t.Errorf("expected goroutine count to not increase after Release (before=%d, after=%d)", before, after)
+	}
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestActiveAutomationValueAtEqualPowerMidpoint(t *testing.T) {
+	a := &activeAutomation{
+		points: []AutomationPoint{
+			{TimeSec: 0, Value: 0},
+			{TimeSec: 1, Value: 1, Curve: EqualPower},
+		},
+		startTime: time.Now().Add(-500 * time.Millisecond),
+	}
+
+	got, done := a.valueAt(time.Now())
+	if done {
+		t.Fatal("expected the automation to still be running at its midpoint")
+	}
+	// sin(pi/4) ~= 0.7071, well above the linear midpoint of 0.5.
+	if got < 0.6 || got > 0.8 {
+		t.Fatalf("valueAt midpoint = %v, want roughly 0.707 (equal-power, not linear)", got)
+	}
+}
+
+func TestActiveAutomationValueAtCustomCurve(t *testing.T) {
+	a := &activeAutomation{
+		points: []AutomationPoint{
+			{TimeSec: 0, Value: 0},
+			{TimeSec: 1, Value: 10, Curve: Custom, CurveFunc: func(t float64) float64 { return t * t * t }},
+		},
+		startTime: time.Now().Add(-500 * time.Millisecond),
+	}
+
+	got, _ := a.valueAt(time.Now())
+	want := float32(10 * 0.5 * 0.5 * 0.5)
+	if diff := abs32(got - want); diff > 0.5 {
+		t.Fatalf("valueAt midpoint = %v, want roughly %v (t^3 curve)", got, want)
+	}
+}
+
+func TestSetParameterSerializedSkipsNoOpWrite(t *testing.T) {
+	chain, eng := newAutomationTestChain(t)
+	defer eng.Destroy()
+	defer chain.Release()
+
+	param := writableParameter(t, chain, 0)
+	chain.setParameterSerialized(0, param, 0.5)
+
+	key := automationKey{effectIndex: 0, paramAddress: param.Address}
+	chain.automationMu.Lock()
+	first := chain.lastValues[key]
+	chain.automationMu.Unlock()
+	if first != 0.5 {
+		t.Fatalf("lastValues[key] = %v, want 0.5", first)
+	}
+
+	// A second call with the same value should be a cache hit - nothing
+	// here observes that directly without mocking SetParameter, but at
+	// minimum the cached value must still read back the same.
+	chain.setParameterSerialized(0, param, 0.5)
+	chain.automationMu.Lock()
+	second := chain.lastValues[key]
+	chain.automationMu.Unlock()
+	if second != 0.5 {
+		t.Fatalf("lastValues[key] = %v after repeat write, want 0.5", second)
+	}
+}
+
+// TestRenderFlushesDueAutomation covers Render's use case: a caller driving
+// audio time itself (an offline render pass) instead of relying on the
+// chain's wall-clock ticker.
+func TestRenderFlushesDueAutomation(t *testing.T) {
+	chain, eng := newAutomationTestChain(t)
+	defer eng.Destroy()
+	defer chain.Release()
+
+	param := writableParameter(t, chain, 0)
+	target := param.MaxValue
+
+	if err := chain.RampParameter(0, param, target, 50*time.Millisecond, Linear); err != nil {
+		t.Fatalf("RampParameter: %v", err)
+	}
+
+	if err := chain.Render(time.Now().Add(time.Second), 512); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	got, err := chain.GetParameter(0, param)
+	if err != nil {
+		t.Fatalf("GetParameter: %v", err)
+	}
+	if abs32(got-target) > 0.01*abs32(param.MaxValue-param.MinValue) {
+		t.Errorf("expected Render(startTime past ramp end) to settle on the target value, got %v want %v", got, target)
+	}
+}
@@ -8,39 +8,113 @@ package pluginchain
 
 // Function declarations - CGO resolves PluginChainResult from .m file
 const char* connect_effects(void* enginePtr, void** effectPtrs, int effectCount);
+// connect_effects_parallel wires enginePtr's graph for Parallel/WetDry mode:
+// inputMixerPtr (the chain's single external connection point) fans out to
+// each sendMixerPtrs[i], each of those feeds effectPtrs[i], and each effect's
+// output lands on its own input bus of outputMixerPtr.
+const char* connect_effects_parallel(void* enginePtr, void* inputMixerPtr, void** sendMixerPtrs, void** effectPtrs, int effectCount, void* outputMixerPtr);
 PluginChainResult get_effect_audio_node(void* effectPtr);
+// connect_node wires a single edge of the chain's routing DAG (see
+// graph.go): [connect:to:fromBus:toBus:format:] with format inferred from
+// fromPtr's output, the same one-edge-at-a-time connection
+// AVAudioEngine.Connect makes, used here instead of connect_effects'
+// whole-array call because AddParallelBranch/AddSend build up an arbitrary
+// number of edges one at a time rather than a single fixed topology.
+const char* connect_node(void* enginePtr, void* fromPtr, void* toPtr, int fromBus, int toBus);
 */
 import "C"
 import (
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 	"unsafe"
 
+	"github.com/shaban/macaudio/avaudio/node"
 	"github.com/shaban/macaudio/avaudio/unit"
 	"github.com/shaban/macaudio/plugins"
 )
 
+// RoutingMode selects how a PluginChain's effects are wired together.
+type RoutingMode int
+
+const (
+	// RoutingSerial chains effects one into the next, as PluginChain has
+	// always done: GetInputNode/GetOutputNode are the first/last active
+	// effect.
+	RoutingSerial RoutingMode = iota
+	// RoutingParallel feeds every active effect the same input and sums
+	// their outputs through the chain's internal output mixer.
+	RoutingParallel
+	// RoutingWetDry is RoutingParallel plus per-effect wet/dry and send
+	// gain control via SetEffectWetDry/SetEffectSendGain.
+	RoutingWetDry
+)
+
 // PluginChain represents a reorderable chain of audio effects
 type PluginChain struct {
 	name      string
 	effects   []*unit.Effect
 	plugins   []*plugins.Plugin
-	enginePtr unsafe.Pointer // Reference to AVAudioEngine for connections
+	bypassed  []bool                // parallel to effects/plugins; true once SetEffectBypass removes that effect from the signal path
+	shared    []bool                // parallel to effects/plugins; true for effects added via AddSharedEffect, whose AU is owned by an EffectRegistry, not this chain
+	predicate EffectPredicate       // optional extra skip gate beyond bypassed, see SetPredicate (predicate.go)
+	enginePtr unsafe.Pointer        // Reference to AVAudioEngine for connections
+	snapshots map[int]ChainSnapshot // slot -> captured state, see SaveSnapshot (snapshot.go)
+
+	routingMode    RoutingMode
+	inputMixerPtr  unsafe.Pointer   // RoutingParallel/RoutingWetDry only: the single node external sources connect to
+	outputMixerPtr unsafe.Pointer   // RoutingParallel/RoutingWetDry only: sums every active effect's output; returned by GetOutputNode
+	sendMixers     []unsafe.Pointer // parallel to effects; per-effect pre-effect gain stage, see SetEffectSendGain
+	wetDry         []float32        // parallel to effects; per-effect post-effect bus gain into outputMixerPtr, see SetEffectWetDry
+
+	// branches/branchOrder/sends hold the chain's routing DAG beyond the
+	// flat per-effect topology above - named side-chains (see
+	// AddParallelBranch) fed by taps off the main chain (see AddSend) and
+	// summed back into outputMixerPtr on their own bus, the same way each
+	// top-level effect already gets its own bus. branchOrder gives
+	// branches (a map) a stable bus/SetMix-index assignment. See graph.go.
+	branches    map[string]*branch
+	branchOrder []string
+	sends       []send
+
+	automationMu       sync.Mutex                          // guards automations, lastValues and paramMu below
+	automations        map[automationKey]*activeAutomation // active ramps/envelopes, see automation.go
+	lastValues         map[automationKey]float32           // last value setParameterSerialized actually wrote, so a plateaued ramp doesn't keep re-issuing the same write every tick
+	paramMu            map[int]*sync.Mutex                 // effectIndex -> mutex serializing SetParameter calls the automation driver makes against manual ones
+	automationOnce     sync.Once                           // starts runAutomationDriver on the first RampParameter/ScheduleAutomation call
+	automationDone     chan struct{}                       // closed by stopAutomationDriver to stop the driver goroutine
+	automationWG       sync.WaitGroup                      // lets Release wait for the driver goroutine to actually exit
+	automationInterval time.Duration                       // tick period for the automation driver, see ChainConfig.AutomationRateHz
 }
 
 // ChainConfig holds configuration for creating a plugin chain
 type ChainConfig struct {
-	Name      string
-	EnginePtr unsafe.Pointer // AVAudioEngine pointer from engine package
+	Name             string
+	EnginePtr        unsafe.Pointer // AVAudioEngine pointer from engine package
+	RoutingMode      RoutingMode    // defaults to RoutingSerial
+	AutomationRateHz int            // control rate for RampParameter/ScheduleAutomation; 0 defaults to DefaultAutomationRateHz
 }
 
 // NewPluginChain creates a new empty plugin chain
 func NewPluginChain(config ChainConfig) *PluginChain {
+	rateHz := config.AutomationRateHz
+	if rateHz <= 0 {
+		rateHz = DefaultAutomationRateHz
+	}
+
 	return &PluginChain{
-		name:      config.Name,
-		effects:   make([]*unit.Effect, 0),
-		plugins:   make([]*plugins.Plugin, 0),
-		enginePtr: config.EnginePtr,
+		name:               config.Name,
+		effects:            make([]*unit.Effect, 0),
+		plugins:            make([]*plugins.Plugin, 0),
+		bypassed:           make([]bool, 0),
+		shared:             make([]bool, 0),
+		enginePtr:          config.EnginePtr,
+		snapshots:          make(map[int]ChainSnapshot),
+		routingMode:        config.RoutingMode,
+		sendMixers:         make([]unsafe.Pointer, 0),
+		wetDry:             make([]float32, 0),
+		automationInterval: time.Second / time.Duration(rateHz),
 	}
 }
 
@@ -59,6 +133,17 @@ func (pc *PluginChain) AddEffect(plugin *plugins.Plugin) error {
 	// Add to our Go-side bookkeeping
 	pc.effects = append(pc.effects, effect)
 	pc.plugins = append(pc.plugins, plugin)
+	pc.bypassed = append(pc.bypassed, false)
+	pc.shared = append(pc.shared, false)
+	pc.wetDry = append(pc.wetDry, 1.0)
+
+	if pc.routingMode != RoutingSerial {
+		sendMixer, err := node.CreateMixer()
+		if err != nil {
+			return fmt.Errorf("failed to create send mixer for effect %s: %v", plugin.Name, err)
+		}
+		pc.sendMixers = append(pc.sendMixers, sendMixer)
+	}
 
 	// Update native connections
 	return pc.updateConnections()
@@ -94,6 +179,17 @@ func (pc *PluginChain) InsertEffect(index int, plugin *plugins.Plugin) error {
 	// Insert into slices at the specified index
 	pc.effects = append(pc.effects[:index], append([]*unit.Effect{effect}, pc.effects[index:]...)...)
 	pc.plugins = append(pc.plugins[:index], append([]*plugins.Plugin{plugin}, pc.plugins[index:]...)...)
+	pc.bypassed = append(pc.bypassed[:index], append([]bool{false}, pc.bypassed[index:]...)...)
+	pc.shared = append(pc.shared[:index], append([]bool{false}, pc.shared[index:]...)...)
+	pc.wetDry = append(pc.wetDry[:index], append([]float32{1.0}, pc.wetDry[index:]...)...)
+
+	if pc.routingMode != RoutingSerial {
+		sendMixer, err := node.CreateMixer()
+		if err != nil {
+			return fmt.Errorf("failed to create send mixer for effect %s: %v", plugin.Name, err)
+		}
+		pc.sendMixers = append(pc.sendMixers[:index], append([]unsafe.Pointer{sendMixer}, pc.sendMixers[index:]...)...)
+	}
 
 	// Update native connections
 	return pc.updateConnections()
@@ -105,12 +201,24 @@ func (pc *PluginChain) RemoveEffect(index int) error {
 		return fmt.Errorf("invalid index %d for chain of length %d", index, len(pc.effects))
 	}
 
-	// Release the effect resources
-	pc.effects[index].Release()
+	// Release the effect resources, unless it's a shared effect: that AU is
+	// owned by the EffectRegistry it was Acquire'd from, and stays alive for
+	// whatever other chains still hold it.
+	if !pc.shared[index] {
+		pc.effects[index].Release()
+	}
 
 	// Remove from slices
 	pc.effects = append(pc.effects[:index], pc.effects[index+1:]...)
 	pc.plugins = append(pc.plugins[:index], pc.plugins[index+1:]...)
+	pc.bypassed = append(pc.bypassed[:index], pc.bypassed[index+1:]...)
+	pc.shared = append(pc.shared[:index], pc.shared[index+1:]...)
+	pc.wetDry = append(pc.wetDry[:index], pc.wetDry[index+1:]...)
+
+	if pc.routingMode != RoutingSerial {
+		node.ReleaseMixer(pc.sendMixers[index])
+		pc.sendMixers = append(pc.sendMixers[:index], pc.sendMixers[index+1:]...)
+	}
 
 	// Update native connections
 	return pc.updateConnections()
@@ -131,10 +239,21 @@ func (pc *PluginChain) MoveEffect(fromIndex, toIndex int) error {
 	// Store the items to move
 	effect := pc.effects[fromIndex]
 	plugin := pc.plugins[fromIndex]
+	bypassed := pc.bypassed[fromIndex]
+	shared := pc.shared[fromIndex]
+	wetDry := pc.wetDry[fromIndex]
 
 	// Remove from current position
 	pc.effects = append(pc.effects[:fromIndex], pc.effects[fromIndex+1:]...)
 	pc.plugins = append(pc.plugins[:fromIndex], pc.plugins[fromIndex+1:]...)
+	pc.bypassed = append(pc.bypassed[:fromIndex], pc.bypassed[fromIndex+1:]...)
+	pc.shared = append(pc.shared[:fromIndex], pc.shared[fromIndex+1:]...)
+	pc.wetDry = append(pc.wetDry[:fromIndex], pc.wetDry[fromIndex+1:]...)
+	var sendMixer unsafe.Pointer
+	if pc.routingMode != RoutingSerial {
+		sendMixer = pc.sendMixers[fromIndex]
+		pc.sendMixers = append(pc.sendMixers[:fromIndex], pc.sendMixers[fromIndex+1:]...)
+	}
 
 	// For moving forward, we need to insert at the original toIndex position
 	// but since we removed an element, the actual insert index is toIndex (not toIndex-1)
@@ -148,6 +267,12 @@ func (pc *PluginChain) MoveEffect(fromIndex, toIndex int) error {
 	// Insert at calculated position
 	pc.effects = append(pc.effects[:insertIndex], append([]*unit.Effect{effect}, pc.effects[insertIndex:]...)...)
 	pc.plugins = append(pc.plugins[:insertIndex], append([]*plugins.Plugin{plugin}, pc.plugins[insertIndex:]...)...)
+	pc.bypassed = append(pc.bypassed[:insertIndex], append([]bool{bypassed}, pc.bypassed[insertIndex:]...)...)
+	pc.shared = append(pc.shared[:insertIndex], append([]bool{shared}, pc.shared[insertIndex:]...)...)
+	pc.wetDry = append(pc.wetDry[:insertIndex], append([]float32{wetDry}, pc.wetDry[insertIndex:]...)...)
+	if pc.routingMode != RoutingSerial {
+		pc.sendMixers = append(pc.sendMixers[:insertIndex], append([]unsafe.Pointer{sendMixer}, pc.sendMixers[insertIndex:]...)...)
+	}
 
 	// Update native connections
 	return pc.updateConnections()
@@ -168,6 +293,12 @@ func (pc *PluginChain) SwapEffects(index1, index2 int) error {
 	// Swap in both slices
 	pc.effects[index1], pc.effects[index2] = pc.effects[index2], pc.effects[index1]
 	pc.plugins[index1], pc.plugins[index2] = pc.plugins[index2], pc.plugins[index1]
+	pc.bypassed[index1], pc.bypassed[index2] = pc.bypassed[index2], pc.bypassed[index1]
+	pc.shared[index1], pc.shared[index2] = pc.shared[index2], pc.shared[index1]
+	pc.wetDry[index1], pc.wetDry[index2] = pc.wetDry[index2], pc.wetDry[index1]
+	if pc.routingMode != RoutingSerial {
+		pc.sendMixers[index1], pc.sendMixers[index2] = pc.sendMixers[index2], pc.sendMixers[index1]
+	}
 
 	// Update native connections
 	return pc.updateConnections()
@@ -197,6 +328,43 @@ func (pc *PluginChain) SetParameter(effectIndex int, param plugins.Parameter, va
 	return nil
 }
 
+// SetEffectBypass marks the effect at effectIndex as bypassed or active and
+// rewires the chain's native connections to match. A bypassed effect isn't
+// merely muted - activeEffectPtrs omits it from the pointer array handed to
+// connect_effects, so the effect before it connects straight through to the
+// next enabled one, the same way a Kubernetes scheduler's CycleState tracks
+// SkipFilterPlugins and omits those plugins from its Filter pass entirely
+// rather than running and discarding their result. That drops the bypassed
+// effect's DSP cost to zero, not just its output.
+func (pc *PluginChain) SetEffectBypass(effectIndex int, bypassed bool) error {
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	if pc.bypassed[effectIndex] == bypassed {
+		return nil
+	}
+	pc.bypassed[effectIndex] = bypassed
+	return pc.updateConnections()
+}
+
+// IsEffectBypassed reports the bypass state last set for the effect at
+// effectIndex.
+func (pc *PluginChain) IsEffectBypassed(effectIndex int) (bool, error) {
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return false, fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	return pc.bypassed[effectIndex], nil
+}
+
+// BypassAll sets every effect in the chain to bypassed (or all active) and
+// rewires connections once, rather than once per effect.
+func (pc *PluginChain) BypassAll(bypassed bool) error {
+	for i := range pc.bypassed {
+		pc.bypassed[i] = bypassed
+	}
+	return pc.updateConnections()
+}
+
 // GetParameter gets a parameter value from a specific effect in the chain
 func (pc *PluginChain) GetParameter(effectIndex int, param plugins.Parameter) (float32, error) {
 	if effectIndex < 0 || effectIndex >= len(pc.effects) {
@@ -221,7 +389,42 @@ func (pc *PluginChain) GetParameter(effectIndex int, param plugins.Parameter) (f
 	return value, nil
 }
 
-// updateConnections updates the native AVAudioEngine connections for the chain
+// activeEffectPtrs returns the native pointers of every effect not in
+// skipSet(), in chain order. A skipped effect is omitted entirely rather
+// than left in place and muted, so connect_effects wires the effect before
+// it straight through to the next active one - the same skip, don't
+// run-and-discard, approach a Kubernetes scheduler's CycleState takes with
+// SkipFilterPlugins.
+func (pc *PluginChain) activeEffectPtrs() []unsafe.Pointer {
+	skip := pc.skipSet()
+	ptrs := make([]unsafe.Pointer, 0, len(pc.effects))
+	for _, effect := range pc.effects {
+		if _, skipped := skip[effect]; skipped {
+			continue
+		}
+		ptrs = append(ptrs, effect.Ptr())
+	}
+	return ptrs
+}
+
+// activeSendMixers returns the send-mixer pointers for every effect not in
+// skipSet(), in the same order and of the same length as activeEffectPtrs.
+// RoutingSerial chains never populate sendMixers and must not call this.
+func (pc *PluginChain) activeSendMixers() []unsafe.Pointer {
+	skip := pc.skipSet()
+	ptrs := make([]unsafe.Pointer, 0, len(pc.sendMixers))
+	for i, mixer := range pc.sendMixers {
+		if _, skipped := skip[pc.effects[i]]; skipped {
+			continue
+		}
+		ptrs = append(ptrs, mixer)
+	}
+	return ptrs
+}
+
+// updateConnections updates the native AVAudioEngine connections for the
+// chain, skipping any bypassed effects so they take no part in the signal
+// path or its processing cost.
 func (pc *PluginChain) updateConnections() error {
 	if len(pc.effects) == 0 {
 		return nil // Empty chain, nothing to connect
@@ -231,12 +434,23 @@ func (pc *PluginChain) updateConnections() error {
 		return fmt.Errorf("chain %s has no engine reference", pc.name)
 	}
 
-	// Build array of effect pointers for native code
-	effectPtrs := make([]unsafe.Pointer, len(pc.effects))
-	for i, effect := range pc.effects {
-		effectPtrs[i] = effect.Ptr()
+	effectPtrs := pc.activeEffectPtrs()
+	if len(effectPtrs) == 0 {
+		return nil // Every effect is bypassed, nothing to connect
 	}
 
+	if pc.routingMode != RoutingSerial {
+		if err := pc.updateParallelConnections(effectPtrs); err != nil {
+			return err
+		}
+		return pc.updateBranchConnections()
+	}
+
+	return pc.connectSerial(effectPtrs)
+}
+
+// connectSerial issues the RoutingSerial connect_effects call.
+func (pc *PluginChain) connectSerial(effectPtrs []unsafe.Pointer) error {
 	// Convert Go slice to C array - need to pass void** to C
 	errorStr := C.connect_effects(
 		pc.enginePtr,
@@ -250,26 +464,86 @@ func (pc *PluginChain) updateConnections() error {
 	return nil
 }
 
-// GetInputNode returns the first effect in the chain for external routing
+// connectEffectsParallel issues the RoutingParallel/RoutingWetDry
+// connect_effects_parallel call; see updateParallelConnections (routing.go)
+// for how the pointer arrays are assembled.
+func (pc *PluginChain) connectEffectsParallel(sendMixerPtrs, effectPtrs []unsafe.Pointer) error {
+	errorStr := C.connect_effects_parallel(
+		pc.enginePtr,
+		pc.inputMixerPtr,
+		(*unsafe.Pointer)(unsafe.Pointer(&sendMixerPtrs[0])),
+		(*unsafe.Pointer)(unsafe.Pointer(&effectPtrs[0])),
+		C.int(len(effectPtrs)),
+		pc.outputMixerPtr,
+	)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// connectNode wires a single fromPtr -> toPtr edge at the given bus
+// numbers, for graph.go's branch/send wiring - see connect_node's
+// declaration above for why this is per-edge rather than a batch call like
+// connectSerial/connectEffectsParallel.
+func (pc *PluginChain) connectNode(fromPtr, toPtr unsafe.Pointer, fromBus, toBus int) error {
+	errorStr := C.connect_node(pc.enginePtr, fromPtr, toPtr, C.int(fromBus), C.int(toBus))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// effectAudioNode returns effectPtr's underlying AVAudioNode, the same
+// native call GetInputNode/GetOutputNode use to resolve an *unit.Effect
+// into a connectable node pointer.
+func effectAudioNode(effectPtr unsafe.Pointer) (unsafe.Pointer, error) {
+	result := C.get_effect_audio_node(effectPtr)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// GetInputNode returns the chain's single external connection point: the
+// first non-bypassed effect in RoutingSerial mode, or the shared input
+// mixer every active effect is fed from in RoutingParallel/RoutingWetDry.
 func (pc *PluginChain) GetInputNode() (unsafe.Pointer, error) {
-	if len(pc.effects) == 0 {
-		return nil, errors.New("chain is empty")
+	if pc.routingMode != RoutingSerial {
+		if pc.inputMixerPtr == nil {
+			return nil, errors.New("chain has no active (non-bypassed) effects")
+		}
+		return pc.inputMixerPtr, nil
 	}
 
-	result := C.get_effect_audio_node(pc.effects[0].Ptr())
+	effectPtrs := pc.activeEffectPtrs()
+	if len(effectPtrs) == 0 {
+		return nil, errors.New("chain has no active (non-bypassed) effects")
+	}
+
+	result := C.get_effect_audio_node(effectPtrs[0])
 	if result.error != nil {
 		return nil, errors.New(C.GoString(result.error))
 	}
 	return unsafe.Pointer(result.result), nil
 }
 
-// GetOutputNode returns the last effect in the chain for external routing
+// GetOutputNode returns the last non-bypassed effect in RoutingSerial mode,
+// or the shared output-summing mixer in RoutingParallel/RoutingWetDry mode.
 func (pc *PluginChain) GetOutputNode() (unsafe.Pointer, error) {
-	if len(pc.effects) == 0 {
-		return nil, errors.New("chain is empty")
+	if pc.routingMode != RoutingSerial {
+		if pc.outputMixerPtr == nil {
+			return nil, errors.New("chain has no active (non-bypassed) effects")
+		}
+		return pc.outputMixerPtr, nil
+	}
+
+	effectPtrs := pc.activeEffectPtrs()
+	if len(effectPtrs) == 0 {
+		return nil, errors.New("chain has no active (non-bypassed) effects")
 	}
 
-	result := C.get_effect_audio_node(pc.effects[len(pc.effects)-1].Ptr())
+	result := C.get_effect_audio_node(effectPtrs[len(effectPtrs)-1])
 	if result.error != nil {
 		return nil, errors.New(C.GoString(result.error))
 	}
@@ -304,23 +578,50 @@ func (pc *PluginChain) IsEmpty() bool {
 	return len(pc.effects) == 0
 }
 
-// Clear removes all effects from the chain
+// Clear removes all effects from the chain. Shared effects (added via
+// AddSharedEffect) are dropped from the chain's own bookkeeping but not
+// released - their AU is owned by the EffectRegistry they came from.
 func (pc *PluginChain) Clear() error {
-	// Release all effects
-	for _, effect := range pc.effects {
-		effect.Release()
+	// Release all non-shared effects
+	for i, effect := range pc.effects {
+		if !pc.shared[i] {
+			effect.Release()
+		}
+	}
+	for _, mixer := range pc.sendMixers {
+		node.ReleaseMixer(mixer)
+	}
+	for _, name := range pc.branchOrder {
+		pc.releaseBranch(pc.branches[name])
 	}
 
 	// Clear slices
 	pc.effects = pc.effects[:0]
 	pc.plugins = pc.plugins[:0]
+	pc.bypassed = pc.bypassed[:0]
+	pc.shared = pc.shared[:0]
+	pc.wetDry = pc.wetDry[:0]
+	pc.sendMixers = pc.sendMixers[:0]
+	pc.branches = nil
+	pc.branchOrder = nil
+	pc.sends = nil
 
 	return nil
 }
 
-// Release releases all resources used by the chain
+// Release releases all resources used by the chain, including the shared
+// input/output mixers used by RoutingParallel/RoutingWetDry chains.
 func (pc *PluginChain) Release() {
+	pc.stopAutomationDriver()
 	pc.Clear()
+	if pc.inputMixerPtr != nil {
+		node.ReleaseMixer(pc.inputMixerPtr)
+		pc.inputMixerPtr = nil
+	}
+	if pc.outputMixerPtr != nil {
+		node.ReleaseMixer(pc.outputMixerPtr)
+		pc.outputMixerPtr = nil
+	}
 }
 
 // Summary returns a brief summary of the chain
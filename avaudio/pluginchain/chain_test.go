@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/node"
 	"github.com/shaban/macaudio/plugins"
 )
 
@@ -884,3 +885,265 @@ func TestParameterPropagation(t *testing.T) {
 		t.Logf("✓ GetParameter synced plugin CurrentValue: %.2f (was manually set to 9999.0)", actualValue)
 	})
 }
+
+// TestPluginChainBypassRouting covers SetEffectBypass's effect on the chain's
+// native routing: a bypassed effect must be skipped entirely, not merely
+// muted in place, so GetInputNode/GetOutputNode and the pointers handed to
+// connect_effects all need to omit it.
+func TestPluginChainBypassRouting(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	config := ChainConfig{
+		Name:      "Bypass Routing Test Chain",
+		EnginePtr: eng.Ptr(),
+	}
+	chain := NewPluginChain(config)
+	defer chain.Release()
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 3 {
+		t.Skip("Need at least 3 Apple AU effects for bypass routing tests")
+	}
+
+	for _, info := range effectInfos[:3] {
+		if err := chain.AddEffectFromPluginInfo(info); err != nil {
+			t.Fatalf("Failed to add effect: %v", err)
+		}
+	}
+
+	originalInput, err := chain.GetInputNode()
+	if err != nil {
+		t.Fatalf("Failed to get input node: %v", err)
+	}
+	originalOutput, err := chain.GetOutputNode()
+	if err != nil {
+		t.Fatalf("Failed to get output node: %v", err)
+	}
+
+	t.Run("BypassMiddle", func(t *testing.T) {
+		if err := chain.SetEffectBypass(1, true); err != nil {
+			t.Fatalf("SetEffectBypass: %v", err)
+		}
+		defer chain.SetEffectBypass(1, false)
+
+		bypassed, err := chain.IsEffectBypassed(1)
+		if err != nil || !bypassed {
+			t.Errorf("expected effect 1 to report bypassed, got %v, err %v", bypassed, err)
+		}
+
+		inputNode, err := chain.GetInputNode()
+		if err != nil {
+			t.Fatalf("GetInputNode: %v", err)
+		}
+		outputNode, err := chain.GetOutputNode()
+		if err != nil {
+			t.Fatalf("GetOutputNode: %v", err)
+		}
+		if inputNode != originalInput {
+			t.Error("expected input node unchanged when bypassing the middle effect")
+		}
+		if outputNode != originalOutput {
+			t.Error("expected output node unchanged when bypassing the middle effect")
+		}
+	})
+
+	t.Run("BypassFirst", func(t *testing.T) {
+		if err := chain.SetEffectBypass(0, true); err != nil {
+			t.Fatalf("SetEffectBypass: %v", err)
+		}
+		defer chain.SetEffectBypass(0, false)
+
+		inputNode, err := chain.GetInputNode()
+		if err != nil {
+			t.Fatalf("GetInputNode: %v", err)
+		}
+		if inputNode == originalInput {
+			t.Error("expected input node to move past the bypassed first effect")
+		}
+	})
+
+	t.Run("BypassLast", func(t *testing.T) {
+		if err := chain.SetEffectBypass(2, true); err != nil {
+			t.Fatalf("SetEffectBypass: %v", err)
+		}
+		defer chain.SetEffectBypass(2, false)
+
+		outputNode, err := chain.GetOutputNode()
+		if err != nil {
+			t.Fatalf("GetOutputNode: %v", err)
+		}
+		if outputNode == originalOutput {
+			t.Error("expected output node to move before the bypassed last effect")
+		}
+	})
+
+	t.Run("BypassAll", func(t *testing.T) {
+		if err := chain.BypassAll(true); err != nil {
+			t.Fatalf("BypassAll: %v", err)
+		}
+
+		if _, err := chain.GetInputNode(); err == nil {
+			t.Error("expected an error getting the input node when every effect is bypassed")
+		}
+		if _, err := chain.GetOutputNode(); err == nil {
+			t.Error("expected an error getting the output node when every effect is bypassed")
+		}
+
+		if err := chain.BypassAll(false); err != nil {
+			t.Fatalf("BypassAll: %v", err)
+		}
+	})
+
+	t.Run("ReenableRestoresRouting", func(t *testing.T) {
+		inputNode, err := chain.GetInputNode()
+		if err != nil {
+			t.Fatalf("GetInputNode: %v", err)
+		}
+		outputNode, err := chain.GetOutputNode()
+		if err != nil {
+			t.Fatalf("GetOutputNode: %v", err)
+		}
+		if inputNode != originalInput {
+			t.Error("expected input node to be restored once every effect is active again")
+		}
+		if outputNode != originalOutput {
+			t.Error("expected output node to be restored once every effect is active again")
+		}
+	})
+}
+
+// TestPluginChainParallelRouting builds a 3-effect RoutingParallel chain and
+// checks that effect bookkeeping (count/naming) is unaffected by the routing
+// mode and that GetOutputNode now returns the shared output mixer.
+func TestPluginChainParallelRouting(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	config := ChainConfig{
+		Name:        "Parallel Test Chain",
+		EnginePtr:   eng.Ptr(),
+		RoutingMode: RoutingParallel,
+	}
+	chain := NewPluginChain(config)
+	defer chain.Release()
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 3 {
+		t.Skip("Need at least 3 Apple AU effects for parallel routing tests")
+	}
+
+	for _, info := range effectInfos[:3] {
+		if err := chain.AddEffectFromPluginInfo(info); err != nil {
+			t.Fatalf("Failed to add effect: %v", err)
+		}
+	}
+
+	if chain.GetEffectCount() != 3 {
+		t.Errorf("expected 3 effects, got %d", chain.GetEffectCount())
+	}
+	if len(chain.GetEffectNames()) != 3 {
+		t.Errorf("expected 3 effect names, got %d", len(chain.GetEffectNames()))
+	}
+
+	outputNode, err := chain.GetOutputNode()
+	if err != nil {
+		t.Fatalf("GetOutputNode: %v", err)
+	}
+	if outputNode == nil {
+		t.Fatal("expected a non-nil output mixer node")
+	}
+
+	inputNode, err := chain.GetInputNode()
+	if err != nil {
+		t.Fatalf("GetInputNode: %v", err)
+	}
+	if inputNode == nil {
+		t.Fatal("expected a non-nil input mixer node")
+	}
+	if inputNode == outputNode {
+		t.Error("expected distinct input and output mixers in parallel mode")
+	}
+}
+
+// TestPluginChainWetDryGains builds a 3-effect RoutingWetDry chain and
+// verifies SetEffectWetDry/SetEffectSendGain actually move the underlying
+// mixer bus gains, not just chain-side bookkeeping.
+func TestPluginChainWetDryGains(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	config := ChainConfig{
+		Name:        "Wet/Dry Test Chain",
+		EnginePtr:   eng.Ptr(),
+		RoutingMode: RoutingWetDry,
+	}
+	chain := NewPluginChain(config)
+	defer chain.Release()
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 3 {
+		t.Skip("Need at least 3 Apple AU effects for wet/dry routing tests")
+	}
+
+	for _, info := range effectInfos[:3] {
+		if err := chain.AddEffectFromPluginInfo(info); err != nil {
+			t.Fatalf("Failed to add effect: %v", err)
+		}
+	}
+
+	if err := chain.SetEffectWetDry(1, 0.25); err != nil {
+		t.Fatalf("SetEffectWetDry: %v", err)
+	}
+	if err := chain.SetEffectSendGain(1, 0.5); err != nil {
+		t.Fatalf("SetEffectSendGain: %v", err)
+	}
+
+	outputNode, err := chain.GetOutputNode()
+	if err != nil {
+		t.Fatalf("GetOutputNode: %v", err)
+	}
+
+	gotWet, err := node.GetMixerVolume(outputNode, 1)
+	if err != nil {
+		t.Fatalf("GetMixerVolume: %v", err)
+	}
+	if gotWet != 0.25 {
+		t.Errorf("expected output mixer bus 1 volume 0.25, got %v", gotWet)
+	}
+
+	t.Run("SerialChainRejectsWetDry", func(t *testing.T) {
+		serialChain := NewPluginChain(ChainConfig{Name: "Serial", EnginePtr: eng.Ptr()})
+		defer serialChain.Release()
+		if err := serialChain.AddEffectFromPluginInfo(effectInfos[0]); err != nil {
+			t.Fatalf("Failed to add effect: %v", err)
+		}
+		if err := serialChain.SetEffectWetDry(0, 0.5); err == nil {
+			t.Error("expected an error setting wet/dry on a RoutingSerial chain")
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package pluginchain
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shaban/macaudio/avaudio/unit"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// Effect is what an EffectDriver hands back for PluginChain to hold onto -
+// the same *unit.Effect AddEffect already puts in PluginChain.effects.
+type Effect = *unit.Effect
+
+// EffectDriver instantiates one kind of effect from its serialized
+// identity, so ChainManager's store can restore a chain without assuming
+// every effect is a local AudioUnit - a test fake, or a non-Darwin build
+// with no AudioUnit host at all, registers its own driver under the same
+// name instead.
+type EffectDriver interface {
+	// Name identifies this driver in a serialized effect's "driver" field,
+	// so LookupDriver can route restoration back to whichever driver wrote
+	// it.
+	Name() string
+	// Instantiate creates a live Effect for info, with params (by
+	// plugins.Parameter.Identifier) applied as the effect's starting
+	// values.
+	Instantiate(info plugins.PluginInfo, params map[string]float32) (Effect, error)
+}
+
+// audioUnitDriver is the default EffectDriver, wrapping the same
+// introspect-then-unit.CreateEffect path AddEffect already uses for a
+// plugins.Plugin discovered live on this machine.
+type audioUnitDriver struct{}
+
+func (audioUnitDriver) Name() string { return "audiounit" }
+
+func (audioUnitDriver) Instantiate(info plugins.PluginInfo, params map[string]float32) (Effect, error) {
+	plugin, err := info.Introspect()
+	if err != nil {
+		return nil, fmt.Errorf("audiounit driver: introspect %s: %w", info.Name, err)
+	}
+
+	effect, err := unit.CreateEffect(plugin)
+	if err != nil {
+		return nil, fmt.Errorf("audiounit driver: create effect %s: %w", info.Name, err)
+	}
+
+	for _, param := range plugin.Parameters {
+		value, ok := params[param.Identifier]
+		if !ok {
+			continue
+		}
+		if err := effect.SetParameter(param, value); err != nil {
+			return nil, fmt.Errorf("audiounit driver: set parameter %s on %s: %w", param.Identifier, info.Name, err)
+		}
+	}
+
+	return effect, nil
+}
+
+var (
+	driverRegistryMu sync.Mutex
+	driverRegistry   = map[string]EffectDriver{"audiounit": audioUnitDriver{}}
+)
+
+// RegisterDriver adds or replaces the driver registered under its own
+// Name(), so a test or a non-Darwin build can substitute a fake for
+// "audiounit" or add an entirely new kind of serialized effect.
+func RegisterDriver(driver EffectDriver) {
+	driverRegistryMu.Lock()
+	defer driverRegistryMu.Unlock()
+	driverRegistry[driver.Name()] = driver
+}
+
+// UnregisterDriver removes the driver registered under name, if any.
+func UnregisterDriver(name string) {
+	driverRegistryMu.Lock()
+	defer driverRegistryMu.Unlock()
+	delete(driverRegistry, name)
+}
+
+// LookupDriver returns the driver registered under name, or false if none
+// is.
+func LookupDriver(name string) (EffectDriver, bool) {
+	driverRegistryMu.Lock()
+	defer driverRegistryMu.Unlock()
+	driver, ok := driverRegistry[name]
+	return driver, ok
+}
@@ -0,0 +1,75 @@
+package pluginchain
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// fakeDriver lets a test observe and control restoration without a real
+// AudioUnit host, per EffectDriver's doc comment.
+type fakeDriver struct {
+	name string
+	err  error
+}
+
+func (d fakeDriver) Name() string { return d.name }
+
+func (d fakeDriver) Instantiate(info plugins.PluginInfo, params map[string]float32) (Effect, error) {
+	return nil, d.err
+}
+
+func TestRegisterLookupUnregisterDriver(t *testing.T) {
+	driver := fakeDriver{name: "fake-for-test", err: errors.New("not implemented in test")}
+
+	if _, ok := LookupDriver(driver.Name()); ok {
+		t.Fatalf("driver %q should not be registered yet", driver.Name())
+	}
+
+	RegisterDriver(driver)
+	defer UnregisterDriver(driver.Name())
+
+	found, ok := LookupDriver(driver.Name())
+	if !ok {
+		t.Fatalf("expected %q to be registered", driver.Name())
+	}
+	if found.Name() != driver.Name() {
+		t.Errorf("LookupDriver returned driver named %q, want %q", found.Name(), driver.Name())
+	}
+
+	UnregisterDriver(driver.Name())
+	if _, ok := LookupDriver(driver.Name()); ok {
+		t.Errorf("expected %q to be gone after UnregisterDriver", driver.Name())
+	}
+}
+
+func TestLookupDriverFindsDefaultAudioUnitDriver(t *testing.T) {
+	driver, ok := LookupDriver("audiounit")
+	if !ok {
+		t.Fatal("expected the default \"audiounit\" driver to be registered")
+	}
+	if driver.Name() != "audiounit" {
+		t.Errorf("driver.Name() = %q, want %q", driver.Name(), "audiounit")
+	}
+}
+
+func TestRestoreChainReportsUnknownDriver(t *testing.T) {
+	stored := storedChain{
+		Name: "Unknown Driver Chain",
+		Effects: []storedEffect{
+			{Driver: "does-not-exist", PluginInfo: plugins.PluginInfo{Name: "Ghost"}},
+		},
+	}
+
+	var fakeEnginePtr int
+	cm := &ChainManager{
+		chains:    map[string]*PluginChain{},
+		refs:      map[string]map[string]struct{}{},
+		enginePtr: unsafe.Pointer(&fakeEnginePtr),
+	}
+	if err := cm.restoreChain(stored); err == nil {
+		t.Error("expected restoreChain to fail when no driver is registered for the stored effect")
+	}
+}
@@ -0,0 +1,339 @@
+package pluginchain
+
+import (
+	"fmt"
+	"sort"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/avaudio/unit"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// branch is a named parallel side-chain added via AddParallelBranch: its
+// own effects run in series, exactly like the main chain, fed by whatever
+// AddSend taps point at it and summed into the chain's shared
+// outputMixerPtr on its own bus - the same per-bus approach
+// RoutingParallel/RoutingWetDry already give each top-level effect (see
+// routing.go), just addressable by name instead of index.
+type branch struct {
+	name          string
+	effects       []*unit.Effect
+	plugins       []*plugins.Plugin
+	inputMixerPtr unsafe.Pointer // sums every AddSend tap that targets this branch
+	wet           float32        // this branch's bus gain on outputMixerPtr
+}
+
+// send is one AddSend edge: fromIndex's output is additionally tapped onto
+// toBranch's inputMixerPtr at gain, alongside (not instead of) fromIndex's
+// normal place in the main signal path.
+type send struct {
+	fromIndex int
+	toBranch  string
+	gain      float32
+}
+
+// AddParallelBranch creates a new named side-chain branch: effects run in
+// series, fed by AddSend taps off the main chain and summed back into the
+// chain's shared output mixer on their own bus. This is what lets a
+// RoutingWetDry chain express patterns a strict linear series can't -
+// parallel compression (the main chain dry, a branch compressed, both
+// summed), a mid/side split (two branches fed by two AddSend taps off a
+// single encode step), or an aux/reverb send shared by several effects.
+//
+// Only valid for RoutingParallel/RoutingWetDry chains, the same
+// restriction SetEffectWetDry/SetEffectSendGain already have - a
+// RoutingSerial chain has no shared output mixer for a branch to land on.
+// name must be unique within the chain.
+func (pc *PluginChain) AddParallelBranch(name string, effects []*plugins.Plugin) error {
+	if pc.routingMode == RoutingSerial {
+		return fmt.Errorf("chain %s is RoutingSerial, which has no output mixer for a branch to sum into", pc.name)
+	}
+	if name == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+	if _, exists := pc.branches[name]; exists {
+		return fmt.Errorf("chain %s already has a branch named %s", pc.name, name)
+	}
+	if pc.enginePtr == nil {
+		return fmt.Errorf("chain %s has no engine reference", pc.name)
+	}
+
+	b := &branch{name: name, wet: 1.0}
+	for _, plugin := range effects {
+		effect, err := unit.CreateEffect(plugin)
+		if err != nil {
+			return fmt.Errorf("failed to create effect %s for branch %s: %v", plugin.Name, name, err)
+		}
+		b.effects = append(b.effects, effect)
+		b.plugins = append(b.plugins, plugin)
+	}
+
+	combined := pc.branchesWithCandidate(name, b)
+	candidateOrder := append(append([]string{}, pc.branchOrder...), name)
+	if err := detectCycle(buildGraphEdges(len(pc.effects), candidateOrder, combined, pc.sends)); err != nil {
+		for _, effect := range b.effects {
+			effect.Release()
+		}
+		return err
+	}
+
+	inputMixer, err := node.CreateMixer()
+	if err != nil {
+		for _, effect := range b.effects {
+			effect.Release()
+		}
+		return fmt.Errorf("failed to create input mixer for branch %s: %v", name, err)
+	}
+	b.inputMixerPtr = inputMixer
+
+	pc.branches = combined
+	pc.branchOrder = candidateOrder
+
+	return pc.updateConnections()
+}
+
+// AddSend taps fromIndex's output into toBranch's input mixer at gain, in
+// addition to its normal place in the main signal path - the aux-send
+// pattern every real mixer supports (several channels' sends feeding one
+// shared reverb bus, say). fromIndex must name a main-chain effect;
+// toBranch must already exist (see AddParallelBranch). A send that would
+// close a cycle back to fromIndex - directly, or by way of another
+// branch's own sends - is rejected rather than silently wired, since
+// AVAudioEngine's connect graph can't resolve one either.
+func (pc *PluginChain) AddSend(fromIndex int, toBranch string, gain float32) error {
+	if fromIndex < 0 || fromIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", fromIndex, len(pc.effects))
+	}
+	if _, ok := pc.branches[toBranch]; !ok {
+		return fmt.Errorf("chain %s has no branch named %s", pc.name, toBranch)
+	}
+
+	candidate := append(append([]send{}, pc.sends...), send{fromIndex: fromIndex, toBranch: toBranch, gain: gain})
+	if err := detectCycle(buildGraphEdges(len(pc.effects), pc.branchOrder, pc.branches, candidate)); err != nil {
+		return err
+	}
+
+	pc.sends = candidate
+	return pc.updateConnections()
+}
+
+// ListBranches returns every branch name in the order AddParallelBranch
+// created them - the same order SetMix's flat node index assigns them.
+func (pc *PluginChain) ListBranches() []string {
+	names := make([]string, len(pc.branchOrder))
+	copy(names, pc.branchOrder)
+	return names
+}
+
+// SetMix sets the wet/dry bus gain for one node in the chain's routing
+// graph, addressed by a single flat index: 0..GetEffectCount()-1 name a
+// main-chain effect (equivalent to SetEffectWetDry), and
+// GetEffectCount()..GetEffectCount()+len(ListBranches())-1 name a branch,
+// in ListBranches order. Only valid for RoutingParallel/RoutingWetDry
+// chains, same as SetEffectWetDry.
+func (pc *PluginChain) SetMix(index int, wet float32) error {
+	if index >= 0 && index < len(pc.effects) {
+		return pc.SetEffectWetDry(index, wet)
+	}
+
+	branchIndex := index - len(pc.effects)
+	if branchIndex < 0 || branchIndex >= len(pc.branchOrder) {
+		return fmt.Errorf("invalid node index %d for chain of %d effects and %d branches", index, len(pc.effects), len(pc.branchOrder))
+	}
+
+	name := pc.branchOrder[branchIndex]
+	b := pc.branches[name]
+	b.wet = wet
+	if pc.outputMixerPtr == nil {
+		return nil // Applied the next time updateConnections wires this branch in
+	}
+	return node.SetMixerVolume(pc.outputMixerPtr, wet, pc.branchBus(name))
+}
+
+// branchBus returns the input bus on outputMixerPtr that name's branch
+// output lands on: one bus per active main-chain effect, then one bus per
+// branch in branchOrder, mirroring updateParallelConnections' own
+// bus-per-active-effect numbering.
+func (pc *PluginChain) branchBus(name string) int {
+	bus := len(pc.activeEffectPtrs())
+	for _, candidate := range pc.branchOrder {
+		if candidate == name {
+			break
+		}
+		bus++
+	}
+	return bus
+}
+
+// updateBranchConnections wires every branch's internal series chain and
+// every AddSend tap into pc.enginePtr's graph, on top of whatever
+// updateParallelConnections already wired for the main chain. It's a no-op
+// if the chain has no branches.
+func (pc *PluginChain) updateBranchConnections() error {
+	if len(pc.branchOrder) == 0 {
+		return nil
+	}
+
+	for _, name := range pc.branchOrder {
+		b := pc.branches[name]
+		if err := pc.wireBranch(b); err != nil {
+			return fmt.Errorf("branch %s: %w", name, err)
+		}
+		if err := node.SetMixerVolume(pc.outputMixerPtr, b.wet, pc.branchBus(name)); err != nil {
+			return fmt.Errorf("branch %s: %w", name, err)
+		}
+	}
+
+	for _, s := range pc.sends {
+		fromNode, err := effectAudioNode(pc.effects[s.fromIndex].Ptr())
+		if err != nil {
+			return fmt.Errorf("send from effect %d to branch %s: %w", s.fromIndex, s.toBranch, err)
+		}
+		b := pc.branches[s.toBranch]
+		bus := pc.sendBus(b, s)
+		if err := pc.connectNode(fromNode, b.inputMixerPtr, 0, bus); err != nil {
+			return fmt.Errorf("send from effect %d to branch %s: %w", s.fromIndex, s.toBranch, err)
+		}
+		if err := node.SetMixerVolume(b.inputMixerPtr, s.gain, bus); err != nil {
+			return fmt.Errorf("send from effect %d to branch %s: %w", s.fromIndex, s.toBranch, err)
+		}
+	}
+
+	return nil
+}
+
+// wireBranch connects b's own effects in series, fed by b.inputMixerPtr and
+// landing on pc.outputMixerPtr via updateBranchConnections' own bus
+// assignment - wireBranch itself only handles b's internal edges.
+func (pc *PluginChain) wireBranch(b *branch) error {
+	prev := b.inputMixerPtr
+	prevBus := 0
+	for _, effect := range b.effects {
+		toNode, err := effectAudioNode(effect.Ptr())
+		if err != nil {
+			return err
+		}
+		if err := pc.connectNode(prev, toNode, prevBus, 0); err != nil {
+			return err
+		}
+		prev, prevBus = toNode, 0
+	}
+	return nil
+}
+
+// sendBus returns the input bus on b.inputMixerPtr that s's tap lands on -
+// one bus per AddSend call already targeting b, in the order they were
+// added.
+func (pc *PluginChain) sendBus(b *branch, s send) int {
+	bus := 0
+	for _, other := range pc.sends {
+		if other.toBranch != b.name {
+			continue
+		}
+		if other == s {
+			return bus
+		}
+		bus++
+	}
+	return bus
+}
+
+// releaseBranch frees b's effects and input mixer - called by Clear/Release
+// the same way they already release the main chain's effects/sendMixers.
+func (pc *PluginChain) releaseBranch(b *branch) {
+	for _, effect := range b.effects {
+		effect.Release()
+	}
+	if b.inputMixerPtr != nil {
+		node.ReleaseMixer(b.inputMixerPtr)
+	}
+}
+
+// branchesWithCandidate returns a copy of pc.branches with name/b added,
+// for AddParallelBranch to validate against before committing it to pc.
+func (pc *PluginChain) branchesWithCandidate(name string, b *branch) map[string]*branch {
+	combined := make(map[string]*branch, len(pc.branches)+1)
+	for k, v := range pc.branches {
+		combined[k] = v
+	}
+	combined[name] = b
+	return combined
+}
+
+// buildGraphEdges assembles the chain's routing DAG as it would exist with
+// branchOrder/branches/sends applied: the main chain's own serial order,
+// every branch's own internal serial order, and every send tap - exactly
+// what updateBranchConnections is about to wire, so detectCycle can walk
+// it before anything is committed.
+func buildGraphEdges(effectCount int, branchOrder []string, branches map[string]*branch, sends []send) map[string][]string {
+	edges := make(map[string][]string)
+	addEdge := func(from, to string) {
+		edges[from] = append(edges[from], to)
+	}
+
+	for i := 0; i+1 < effectCount; i++ {
+		addEdge(fmt.Sprintf("effect:%d", i), fmt.Sprintf("effect:%d", i+1))
+	}
+	for _, name := range branchOrder {
+		b := branches[name]
+		for i := 0; i+1 < len(b.effects); i++ {
+			addEdge(fmt.Sprintf("branch:%s:%d", name, i), fmt.Sprintf("branch:%s:%d", name, i+1))
+		}
+	}
+	for _, s := range sends {
+		addEdge(fmt.Sprintf("effect:%d", s.fromIndex), fmt.Sprintf("branch:%s", s.toBranch))
+	}
+	return edges
+}
+
+// detectCycle runs a standard white/gray/black DFS over edges (from -> []to)
+// and returns an error naming the back edge it found, or nil if the graph
+// is acyclic.
+func detectCycle(edges map[string][]string) error {
+	const (
+		white = iota
+		gray
+		black
+	)
+
+	state := make(map[string]int)
+	nodeSet := make(map[string]bool)
+	for from, tos := range edges {
+		nodeSet[from] = true
+		for _, to := range tos {
+			nodeSet[to] = true
+		}
+	}
+	nodes := make([]string, 0, len(nodeSet))
+	for n := range nodeSet {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes) // deterministic traversal order, for a reproducible error message
+
+	var visit func(n string) error
+	visit = func(n string) error {
+		state[n] = gray
+		for _, next := range edges[n] {
+			switch state[next] {
+			case gray:
+				return fmt.Errorf("routing graph cycle detected: %s -> %s", n, next)
+			case white:
+				if err := visit(next); err != nil {
+					return err
+				}
+			}
+		}
+		state[n] = black
+		return nil
+	}
+
+	for _, n := range nodes {
+		if state[n] == white {
+			if err := visit(n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package pluginchain
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/unit"
+)
+
+// branchOfLength builds a branch with n nil effect slots - enough for
+// buildGraphEdges, which only ever looks at len(b.effects), never
+// dereferences them.
+func branchOfLength(n int) *branch {
+	return &branch{effects: make([]*unit.Effect, n)}
+}
+
+func TestDetectCycleAcceptsMainChainAndBranches(t *testing.T) {
+	branches := map[string]*branch{
+		"sidechain": branchOfLength(2),
+	}
+	order := []string{"sidechain"}
+	sends := []send{{fromIndex: 1, toBranch: "sidechain"}}
+
+	edges := buildGraphEdges(3, order, branches, sends)
+	if err := detectCycle(edges); err != nil {
+		t.Fatalf("detectCycle on an acyclic graph: %v", err)
+	}
+}
+
+func TestDetectCycleRejectsBackEdge(t *testing.T) {
+	edges := map[string][]string{
+		"effect:0": {"effect:1"},
+		"effect:1": {"effect:2"},
+		"effect:2": {"effect:0"}, // closes the loop
+	}
+	if err := detectCycle(edges); err == nil {
+		t.Fatal("expected an error for a graph with a cycle")
+	}
+}
+
+func TestBuildGraphEdgesIncludesBranchInternalOrder(t *testing.T) {
+	branches := map[string]*branch{
+		"parallel-comp": branchOfLength(3),
+	}
+	order := []string{"parallel-comp"}
+
+	edges := buildGraphEdges(1, order, branches, nil)
+	want := []struct{ from, to string }{
+		{"branch:parallel-comp:0", "branch:parallel-comp:1"},
+		{"branch:parallel-comp:1", "branch:parallel-comp:2"},
+	}
+	for _, w := range want {
+		found := false
+		for _, to := range edges[w.from] {
+			if to == w.to {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing edge %s -> %s in %v", w.from, w.to, edges)
+		}
+	}
+}
@@ -1,17 +1,45 @@
 package pluginchain
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
+	"sync"
 	"unsafe"
 
 	"github.com/shaban/macaudio/plugins"
 )
 
+// ErrChainInUse is returned by DeleteChain/ClearAllChains when a chain
+// still has refs attached via AttachChain; see IsInUse and the Force
+// variants (DeleteChainForce/ClearAllChainsForce), which detach everything
+// first instead of failing.
+var ErrChainInUse = errors.New("pluginchain: chain is in use")
+
+// IsInUse reports whether err is (or wraps) ErrChainInUse, mirroring
+// Docker's volume store convention for "can't remove, still referenced"
+// errors.
+func IsInUse(err error) bool {
+	return errors.Is(err, ErrChainInUse)
+}
+
 // ChainManager manages multiple named plugin chains for an audio engine
 type ChainManager struct {
 	chains    map[string]*PluginChain
 	enginePtr unsafe.Pointer // Shared AVAudioEngine for all chains
+
+	// mu guards refs below, so AttachChain/DetachChain/GetChainRefs can be
+	// called from multiple engine.Channel creations/destructions
+	// concurrently without racing on a shared chain's ref set.
+	mu   sync.Mutex
+	refs map[string]map[string]struct{} // chain name -> set of refIDs holding it
+
+	// storeDir, if non-empty, is where CreateChain/AddEffect write each
+	// chain through to as "<name>.chain.json" - see
+	// NewChainManagerWithStore (store.go).
+	storeDir string
 }
 
 // ManagerConfig holds configuration for creating a chain manager
@@ -24,11 +52,28 @@ func NewChainManager(config ManagerConfig) *ChainManager {
 	return &ChainManager{
 		chains:    make(map[string]*PluginChain),
 		enginePtr: config.EnginePtr,
+		refs:      make(map[string]map[string]struct{}),
 	}
 }
 
-// CreateChain creates a new named plugin chain
+// CreateChain creates a new named plugin chain, writing it through to
+// cm's store directory (if configured, see NewChainManagerWithStore).
 func (cm *ChainManager) CreateChain(name string) (*PluginChain, error) {
+	chain, err := cm.createChainNoStore(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := cm.writeChainLocked(chain); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// createChainNoStore is CreateChain without the write-through, used by
+// restoreChain while loading a store directory - writing an empty chain
+// through before its effects are restored would clobber the very file
+// being read.
+func (cm *ChainManager) createChainNoStore(name string) (*PluginChain, error) {
 	if name == "" {
 		return nil, fmt.Errorf("chain name cannot be empty")
 	}
@@ -61,8 +106,42 @@ func (cm *ChainManager) GetChain(name string) (*PluginChain, error) {
 	return chain, nil
 }
 
-// DeleteChain removes a plugin chain by name
+// DeleteChain removes a plugin chain by name, failing with ErrChainInUse if
+// any refs are still attached via AttachChain - see DeleteChainForce to
+// detach everything first instead.
 func (cm *ChainManager) DeleteChain(name string) error {
+	if _, exists := cm.chains[name]; !exists {
+		return fmt.Errorf("chain '%s' not found", name)
+	}
+
+	cm.mu.Lock()
+	inUse := len(cm.refs[name]) > 0
+	cm.mu.Unlock()
+	if inUse {
+		return fmt.Errorf("chain '%s': %w", name, ErrChainInUse)
+	}
+
+	return cm.deleteChainUnchecked(name)
+}
+
+// DeleteChainForce removes a plugin chain by name regardless of refs,
+// detaching every ref attached via AttachChain first.
+func (cm *ChainManager) DeleteChainForce(name string) error {
+	if _, exists := cm.chains[name]; !exists {
+		return fmt.Errorf("chain '%s' not found", name)
+	}
+
+	cm.mu.Lock()
+	delete(cm.refs, name)
+	cm.mu.Unlock()
+
+	return cm.deleteChainUnchecked(name)
+}
+
+// deleteChainUnchecked releases and removes name's chain without
+// consulting refs - callers must have already confirmed it's safe to
+// remove.
+func (cm *ChainManager) deleteChainUnchecked(name string) error {
 	chain, exists := cm.chains[name]
 	if !exists {
 		return fmt.Errorf("chain '%s' not found", name)
@@ -74,9 +153,63 @@ func (cm *ChainManager) DeleteChain(name string) error {
 	// Remove from collection
 	delete(cm.chains, name)
 
+	return cm.removeChainFileLocked(name)
+}
+
+// AttachChain records that refID is holding a reference to chainName,
+// keeping it alive against DeleteChain/ClearAllChains until a matching
+// DetachChain call - e.g. a live input channel and a bounce/preview
+// channel sharing one "Vocals FX" chain without either accidentally
+// freeing it out from under the other.
+func (cm *ChainManager) AttachChain(chainName, refID string) error {
+	if refID == "" {
+		return fmt.Errorf("refID cannot be empty")
+	}
+	if _, exists := cm.chains[chainName]; !exists {
+		return fmt.Errorf("chain '%s' not found", chainName)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	set, ok := cm.refs[chainName]
+	if !ok {
+		set = make(map[string]struct{})
+		cm.refs[chainName] = set
+	}
+	set[refID] = struct{}{}
 	return nil
 }
 
+// DetachChain releases refID's reference to chainName, attached earlier
+// via AttachChain. Detaching a refID that was never attached (or a chain
+// that no longer exists) is a no-op, matching DeleteChain's own tolerance
+// for calls against state that's already gone.
+func (cm *ChainManager) DetachChain(chainName, refID string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if set, ok := cm.refs[chainName]; ok {
+		delete(set, refID)
+		if len(set) == 0 {
+			delete(cm.refs, chainName)
+		}
+	}
+	return nil
+}
+
+// GetChainRefs returns a sorted list of refIDs currently holding
+// chainName, as attached via AttachChain.
+func (cm *ChainManager) GetChainRefs(chainName string) []string {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	set := cm.refs[chainName]
+	refs := make([]string, 0, len(set))
+	for refID := range set {
+		refs = append(refs, refID)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
 // RenameChain changes the name of an existing chain
 func (cm *ChainManager) RenameChain(oldName, newName string) error {
 	if newName == "" {
@@ -198,8 +331,32 @@ func (cm *ChainManager) CloneChain(sourceName, targetName string) (*PluginChain,
 	return targetChain, nil
 }
 
-// ClearAllChains removes all chains and releases their resources
+// ClearAllChains removes all chains and releases their resources, failing
+// with ErrChainInUse (without removing anything) if any chain still has
+// refs attached via AttachChain - see ClearAllChainsForce to detach
+// everything first instead.
 func (cm *ChainManager) ClearAllChains() error {
+	cm.mu.Lock()
+	inUse := len(cm.refs) > 0
+	cm.mu.Unlock()
+	if inUse {
+		return fmt.Errorf("chain manager: %w", ErrChainInUse)
+	}
+
+	return cm.clearAllChainsUnchecked()
+}
+
+// ClearAllChainsForce removes all chains regardless of refs, detaching
+// every ref attached via AttachChain first.
+func (cm *ChainManager) ClearAllChainsForce() error {
+	cm.mu.Lock()
+	cm.refs = make(map[string]map[string]struct{})
+	cm.mu.Unlock()
+
+	return cm.clearAllChainsUnchecked()
+}
+
+func (cm *ChainManager) clearAllChainsUnchecked() error {
 	var firstError error
 
 	// Release all chains
@@ -209,6 +366,9 @@ func (cm *ChainManager) ClearAllChains() error {
 			firstError = fmt.Errorf("failed to clear chain '%s': %v", name, err)
 		}
 		chain.Release()
+		if err := cm.removeChainFileLocked(name); err != nil && firstError == nil {
+			firstError = err
+		}
 	}
 
 	// Clear the map
@@ -265,5 +425,67 @@ func (cm *ChainManager) GetChainsSummary() map[string]string {
 
 // Release releases all resources used by the chain manager
 func (cm *ChainManager) Release() {
-	cm.ClearAllChains()
+	cm.ClearAllChainsForce()
+}
+
+// SavePresetBank writes every managed chain's snapshot slots (see
+// PluginChain.SaveSnapshot) to dir, one "<chain name>.preset.json" file per
+// chain, via PluginChain.ExportPreset.
+func (cm *ChainManager) SavePresetBank(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create preset bank directory %s: %w", dir, err)
+	}
+
+	for name, chain := range cm.chains {
+		path := filepath.Join(dir, name+".preset.json")
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create preset file for chain '%s': %w", name, err)
+		}
+
+		err = chain.ExportPreset(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to export preset for chain '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadPresetBank reads every "<chain name>.preset.json" file in dir produced
+// by SavePresetBank and imports it into the matching chain via
+// PluginChain.ImportPreset. A file with no matching chain name is skipped.
+func (cm *ChainManager) LoadPresetBank(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read preset bank directory %s: %w", dir, err)
+	}
+
+	const suffix = ".preset.json"
+	for _, entry := range entries {
+		if entry.IsDir() || len(entry.Name()) <= len(suffix) || entry.Name()[len(entry.Name())-len(suffix):] != suffix {
+			continue
+		}
+
+		name := entry.Name()[:len(entry.Name())-len(suffix)]
+		chain, exists := cm.chains[name]
+		if !exists {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open preset file for chain '%s': %w", name, err)
+		}
+
+		err = chain.ImportPreset(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to import preset for chain '%s': %w", name, err)
+		}
+	}
+
+	return nil
 }
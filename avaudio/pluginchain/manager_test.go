@@ -538,6 +538,79 @@ func TestChainManagerSummary(t *testing.T) {
 	})
 }
 
+func TestChainManagerRefLifecycle(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	manager := NewChainManager(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer manager.Release()
+
+	if _, err := manager.CreateChain("Vocals FX"); err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+
+	if err := manager.AttachChain("Vocals FX", "channel-live"); err != nil {
+		t.Fatalf("AttachChain failed: %v", err)
+	}
+	if err := manager.AttachChain("Vocals FX", "channel-preview"); err != nil {
+		t.Fatalf("AttachChain failed: %v", err)
+	}
+
+	refs := manager.GetChainRefs("Vocals FX")
+	if len(refs) != 2 || refs[0] != "channel-live" || refs[1] != "channel-preview" {
+		t.Errorf("GetChainRefs = %v, want [channel-live channel-preview]", refs)
+	}
+
+	if err := manager.DeleteChain("Vocals FX"); !IsInUse(err) {
+		t.Errorf("DeleteChain on an in-use chain = %v, want ErrChainInUse", err)
+	}
+	if err := manager.ClearAllChains(); !IsInUse(err) {
+		t.Errorf("ClearAllChains with an in-use chain = %v, want ErrChainInUse", err)
+	}
+
+	if err := manager.DetachChain("Vocals FX", "channel-live"); err != nil {
+		t.Fatalf("DetachChain failed: %v", err)
+	}
+	if err := manager.DeleteChain("Vocals FX"); !IsInUse(err) {
+		t.Errorf("DeleteChain while channel-preview still holds a ref = %v, want ErrChainInUse", err)
+	}
+
+	if err := manager.DetachChain("Vocals FX", "channel-preview"); err != nil {
+		t.Fatalf("DetachChain failed: %v", err)
+	}
+	if err := manager.DeleteChain("Vocals FX"); err != nil {
+		t.Errorf("DeleteChain with no refs left should succeed, got %v", err)
+	}
+}
+
+func TestChainManagerDeleteChainForceDetachesRefs(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	manager := NewChainManager(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer manager.Release()
+
+	if _, err := manager.CreateChain("Vocals FX"); err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	if err := manager.AttachChain("Vocals FX", "channel-live"); err != nil {
+		t.Fatalf("AttachChain failed: %v", err)
+	}
+
+	if err := manager.DeleteChainForce("Vocals FX"); err != nil {
+		t.Errorf("DeleteChainForce failed: %v", err)
+	}
+	if manager.HasChain("Vocals FX") {
+		t.Error("expected 'Vocals FX' to be gone after DeleteChainForce")
+	}
+}
+
 func TestChainManagerNilEngine(t *testing.T) {
 	// Create manager with nil engine
 	config := ManagerConfig{
@@ -0,0 +1,22 @@
+package pluginchain
+
+import "fmt"
+
+// SendNoteOn sends a Note On message to the effect at effectIndex, for
+// instrument/MIDI-effect AudioUnits that accept live MIDI input (see
+// unit.Effect.SendNoteOn). Addressed by effectIndex the same way
+// SetParameter/GetParameter are.
+func (pc *PluginChain) SendNoteOn(effectIndex, channel, note, velocity int) error {
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	return pc.effects[effectIndex].SendNoteOn(channel, note, velocity)
+}
+
+// SendNoteOff sends a Note Off message to the effect at effectIndex.
+func (pc *PluginChain) SendNoteOff(effectIndex, channel, note, velocity int) error {
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	return pc.effects[effectIndex].SendNoteOff(channel, note, velocity)
+}
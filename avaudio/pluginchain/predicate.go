@@ -0,0 +1,50 @@
+package pluginchain
+
+import "github.com/shaban/macaudio/avaudio/unit"
+
+// EffectPredicate is a user-supplied PreFilter-style gate: skipSet skips any
+// effect this returns false for, in addition to whatever SetEffectBypass
+// already marked bypassed - e.g. an effect whose wet mix is pinned to 0% or
+// that a caller has flagged inactive some other way this chain doesn't know
+// about. A nil predicate (the default) accepts every effect, same as before
+// SetPredicate existed.
+type EffectPredicate func(effect *unit.Effect) bool
+
+// SetPredicate installs pred as this chain's skip predicate and rewires
+// immediately so the new predicate takes effect - see EffectPredicate.
+// Passing nil reverts to accepting every effect (bypass state alone decides
+// what's skipped).
+func (pc *PluginChain) SetPredicate(pred EffectPredicate) error {
+	pc.predicate = pred
+	return pc.updateConnections()
+}
+
+// Rewire recomputes skipSet() and reissues the chain's native connections to
+// match, exactly as SetEffectBypass/SetPredicate already do internally -
+// exposed directly for a caller whose predicate's answer changed for a
+// reason this chain can't observe on its own (a wet/dry knob owned by
+// another subsystem, say) and needs to force a rewire without touching
+// bypass state.
+func (pc *PluginChain) Rewire() error {
+	return pc.updateConnections()
+}
+
+// skipSet returns the effects (by pointer identity) that this rewire should
+// omit from the signal path entirely: every bypassed effect (see
+// SetEffectBypass) plus any effect pc.predicate rejects. Built fresh on
+// every call the same way a Kubernetes scheduler's CycleState.
+// SkipFilterPlugins is rebuilt from that cycle's PreFilter results rather
+// than persisted across cycles.
+func (pc *PluginChain) skipSet() map[*unit.Effect]struct{} {
+	skip := make(map[*unit.Effect]struct{})
+	for i, effect := range pc.effects {
+		if pc.bypassed[i] {
+			skip[effect] = struct{}{}
+			continue
+		}
+		if pc.predicate != nil && !pc.predicate(effect) {
+			skip[effect] = struct{}{}
+		}
+	}
+	return skip
+}
@@ -0,0 +1,90 @@
+package pluginchain
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/unit"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// TestPluginChainPredicateRouting covers SetPredicate's effect on the
+// chain's native routing: an effect the predicate rejects must be skipped
+// entirely, the same way SetEffectBypass already skips a bypassed one (see
+// TestPluginChainBypassRouting), and Rewire must be able to force a rewire
+// on its own.
+func TestPluginChainPredicateRouting(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	config := ChainConfig{
+		Name:      "Predicate Routing Test Chain",
+		EnginePtr: eng.Ptr(),
+	}
+	chain := NewPluginChain(config)
+	defer chain.Release()
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 3 {
+		t.Skip("Need at least 3 Apple AU effects for predicate routing tests")
+	}
+
+	for _, info := range effectInfos[:3] {
+		if err := chain.AddEffectFromPluginInfo(info); err != nil {
+			t.Fatalf("Failed to add effect: %v", err)
+		}
+	}
+
+	originalInput, err := chain.GetInputNode()
+	if err != nil {
+		t.Fatalf("Failed to get input node: %v", err)
+	}
+
+	rejected := chain.effects[0]
+	if err := chain.SetPredicate(func(effect *unit.Effect) bool {
+		return effect != rejected
+	}); err != nil {
+		t.Fatalf("SetPredicate: %v", err)
+	}
+	defer chain.SetPredicate(nil)
+
+	inputNode, err := chain.GetInputNode()
+	if err != nil {
+		t.Fatalf("GetInputNode: %v", err)
+	}
+	if inputNode == originalInput {
+		t.Error("expected input node to move past the predicate-rejected first effect")
+	}
+
+	skip := chain.skipSet()
+	if _, skipped := skip[rejected]; !skipped {
+		t.Error("expected rejected effect to be in skipSet")
+	}
+	if _, skipped := skip[chain.effects[1]]; skipped {
+		t.Error("expected second effect, accepted by the predicate, not to be in skipSet")
+	}
+
+	if err := chain.SetPredicate(nil); err != nil {
+		t.Fatalf("SetPredicate(nil): %v", err)
+	}
+	restoredInput, err := chain.GetInputNode()
+	if err != nil {
+		t.Fatalf("GetInputNode: %v", err)
+	}
+	if restoredInput != originalInput {
+		t.Error("expected input node restored to the first effect once the predicate was cleared")
+	}
+
+	// Rewire with no bypass/predicate change should be a harmless no-op.
+	if err := chain.Rewire(); err != nil {
+		t.Fatalf("Rewire: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package pluginchain
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/node"
+)
+
+// updateParallelConnections wires the chain's RoutingParallel/RoutingWetDry
+// graph: the shared inputMixerPtr (created on first use) fans out to each
+// active effect's send mixer, each send mixer feeds its effect, and each
+// effect lands on its own input bus of the shared outputMixerPtr. effectPtrs
+// is the already-bypass-filtered list from activeEffectPtrs.
+func (pc *PluginChain) updateParallelConnections(effectPtrs []unsafe.Pointer) error {
+	if pc.inputMixerPtr == nil {
+		ptr, err := node.CreateMixer()
+		if err != nil {
+			return fmt.Errorf("failed to create input mixer for chain %s: %v", pc.name, err)
+		}
+		pc.inputMixerPtr = ptr
+	}
+	if pc.outputMixerPtr == nil {
+		ptr, err := node.CreateMixer()
+		if err != nil {
+			return fmt.Errorf("failed to create output mixer for chain %s: %v", pc.name, err)
+		}
+		pc.outputMixerPtr = ptr
+	}
+
+	sendMixerPtrs := pc.activeSendMixers()
+	if len(sendMixerPtrs) != len(effectPtrs) {
+		return fmt.Errorf("chain %s has %d active effects but %d active send mixers", pc.name, len(effectPtrs), len(sendMixerPtrs))
+	}
+
+	if err := pc.connectEffectsParallel(sendMixerPtrs, effectPtrs); err != nil {
+		return err
+	}
+
+	// Re-apply per-effect gains now that the buses they live on have been
+	// (re)assigned by the connect above.
+	for i, bypassed := range pc.bypassed {
+		if bypassed {
+			continue
+		}
+		if err := node.SetMixerVolume(pc.sendMixers[i], 1.0, 0); err != nil {
+			return err
+		}
+	}
+	bus := 0
+	for i, bypassed := range pc.bypassed {
+		if bypassed {
+			continue
+		}
+		if err := node.SetMixerVolume(pc.outputMixerPtr, pc.wetDry[i], bus); err != nil {
+			return err
+		}
+		bus++
+	}
+	return nil
+}
+
+// SetEffectWetDry sets how much of effectIndex's processed output is present
+// in the chain's output mix: 0 silences it, 1 is fully wet. Only valid for
+// RoutingParallel/RoutingWetDry chains, where each effect owns a dedicated
+// bus on the shared output mixer GetOutputNode returns.
+func (pc *PluginChain) SetEffectWetDry(effectIndex int, wet float32) error {
+	if pc.routingMode == RoutingSerial {
+		return fmt.Errorf("chain %s is RoutingSerial, which has no wet/dry mix to set", pc.name)
+	}
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	pc.wetDry[effectIndex] = wet
+	if pc.bypassed[effectIndex] || pc.outputMixerPtr == nil {
+		return nil // Applied the next time this effect is wired back in
+	}
+
+	bus := 0
+	for i := 0; i < effectIndex; i++ {
+		if !pc.bypassed[i] {
+			bus++
+		}
+	}
+	return node.SetMixerVolume(pc.outputMixerPtr, wet, bus)
+}
+
+// SetEffectSendGain sets the gain feeding effectIndex from the chain's
+// shared input, via that effect's own send mixer. Only valid for
+// RoutingParallel/RoutingWetDry chains.
+func (pc *PluginChain) SetEffectSendGain(effectIndex int, gain float32) error {
+	if pc.routingMode == RoutingSerial {
+		return fmt.Errorf("chain %s is RoutingSerial, which has no per-effect send gain to set", pc.name)
+	}
+	if effectIndex < 0 || effectIndex >= len(pc.effects) {
+		return fmt.Errorf("invalid effect index %d for chain of length %d", effectIndex, len(pc.effects))
+	}
+	if pc.sendMixers[effectIndex] == nil {
+		return nil // Mixer not created yet; nothing to set
+	}
+	return node.SetMixerVolume(pc.sendMixers[effectIndex], gain, 0)
+}
@@ -0,0 +1,140 @@
+package pluginchain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/avaudio/unit"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// ErrEffectInUse is returned by EffectRegistry.Release when other chains
+// still hold a reference to the shared effect, so its AU was not destroyed.
+var ErrEffectInUse = errors.New("pluginchain: shared effect is still in use")
+
+// SharedEffect is one AVAudioUnitEffect instance owned by an EffectRegistry
+// and referenced by potentially several PluginChains - a send reverb fed by
+// every channel's chain, rather than one reverb instance per chain. It
+// binds to the first chain's engine pointer on its first AddSharedEffect
+// call; AddSharedEffect on a chain with a different engine pointer is
+// rejected, since the underlying AU can only live in one AVAudioEngine
+// graph at a time.
+type SharedEffect struct {
+	name           string
+	effect         *unit.Effect
+	plugin         *plugins.Plugin
+	boundEnginePtr unsafe.Pointer
+}
+
+// Ptr returns the native AVAudioUnitEffect pointer.
+func (s *SharedEffect) Ptr() unsafe.Pointer {
+	return s.effect.Ptr()
+}
+
+// Plugin returns the plugin metadata backing this shared effect.
+func (s *SharedEffect) Plugin() *plugins.Plugin {
+	return s.plugin
+}
+
+// EffectRegistry caches instantiated AVAudioUnitEffect nodes keyed by a
+// caller-supplied name, analogous to how a Docker volume store hands the
+// same named volume to every container that mounts it: Acquire increments a
+// refcount and returns the existing instance if name is already cached,
+// Release decrements it, and the AU is only actually destroyed once the
+// refcount reaches zero.
+type EffectRegistry struct {
+	mu      sync.Mutex
+	effects map[string]*sharedEffectEntry
+}
+
+type sharedEffectEntry struct {
+	shared   *SharedEffect
+	refCount int
+}
+
+// NewEffectRegistry creates an empty registry.
+func NewEffectRegistry() *EffectRegistry {
+	return &EffectRegistry{effects: make(map[string]*sharedEffectEntry)}
+}
+
+// Acquire returns the SharedEffect cached under name, creating it from
+// plugin if this is the first call for that name, and incrementing its
+// refcount either way.
+func (r *EffectRegistry) Acquire(name string, plugin *plugins.Plugin) (*SharedEffect, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.effects[name]; ok {
+		entry.refCount++
+		return entry.shared, nil
+	}
+
+	effect, err := unit.CreateEffect(plugin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shared effect %q: %w", name, err)
+	}
+
+	shared := &SharedEffect{name: name, effect: effect, plugin: plugin}
+	r.effects[name] = &sharedEffectEntry{shared: shared, refCount: 1}
+	return shared, nil
+}
+
+// Release decrements name's refcount. While the refcount remains above zero
+// - other chains still hold this effect - Release returns ErrEffectInUse
+// and leaves the AU alive. Once the last reference is released, the AU is
+// destroyed and name is freed for reuse.
+func (r *EffectRegistry) Release(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.effects[name]
+	if !ok {
+		return fmt.Errorf("no shared effect named %q", name)
+	}
+
+	entry.refCount--
+	if entry.refCount > 0 {
+		return fmt.Errorf("shared effect %q: %w", name, ErrEffectInUse)
+	}
+
+	delete(r.effects, name)
+	return entry.shared.effect.Release()
+}
+
+// AddSharedEffect appends shared to the end of the chain, wiring it into the
+// chain's native connections like any other effect. shared's underlying AU
+// is not released when it's later removed from this chain (via RemoveEffect
+// or Clear) - that's the EffectRegistry's job, via Release.
+//
+// shared binds to whichever chain's engine pointer calls AddSharedEffect
+// first; a later call from a chain on a different engine is rejected, since
+// one AVAudioUnitEffect can only be connected into one AVAudioEngine graph.
+func (pc *PluginChain) AddSharedEffect(shared *SharedEffect) error {
+	if pc.enginePtr == nil {
+		return fmt.Errorf("chain %s has no engine reference", pc.name)
+	}
+	if shared.boundEnginePtr == nil {
+		shared.boundEnginePtr = pc.enginePtr
+	} else if shared.boundEnginePtr != pc.enginePtr {
+		return fmt.Errorf("shared effect %q is bound to a different engine than chain %s", shared.name, pc.name)
+	}
+
+	pc.effects = append(pc.effects, shared.effect)
+	pc.plugins = append(pc.plugins, shared.plugin)
+	pc.bypassed = append(pc.bypassed, false)
+	pc.shared = append(pc.shared, true)
+	pc.wetDry = append(pc.wetDry, 1.0)
+
+	if pc.routingMode != RoutingSerial {
+		sendMixer, err := node.CreateMixer()
+		if err != nil {
+			return fmt.Errorf("failed to create send mixer for shared effect %q: %v", shared.name, err)
+		}
+		pc.sendMixers = append(pc.sendMixers, sendMixer)
+	}
+
+	return pc.updateConnections()
+}
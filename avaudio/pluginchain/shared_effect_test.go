@@ -0,0 +1,142 @@
+package pluginchain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/plugins"
+)
+
+func TestEffectRegistrySharedAcrossChains(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 1 {
+		t.Skip("Need at least 1 Apple AU effect for shared effect tests")
+	}
+	reverbPlugin, err := effectInfos[0].Introspect()
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+
+	registry := NewEffectRegistry()
+
+	chainA := NewPluginChain(ChainConfig{Name: "Channel A", EnginePtr: eng.Ptr()})
+	defer chainA.Release()
+	chainB := NewPluginChain(ChainConfig{Name: "Channel B", EnginePtr: eng.Ptr()})
+	defer chainB.Release()
+
+	sharedA, err := registry.Acquire("send-reverb", reverbPlugin)
+	if err != nil {
+		t.Fatalf("Acquire from chain A: %v", err)
+	}
+	sharedB, err := registry.Acquire("send-reverb", reverbPlugin)
+	if err != nil {
+		t.Fatalf("Acquire from chain B: %v", err)
+	}
+	if sharedA != sharedB {
+		t.Fatal("expected both Acquire calls to return the same SharedEffect instance")
+	}
+
+	if err := chainA.AddSharedEffect(sharedA); err != nil {
+		t.Fatalf("AddSharedEffect on chain A: %v", err)
+	}
+	if err := chainB.AddSharedEffect(sharedB); err != nil {
+		t.Fatalf("AddSharedEffect on chain B: %v", err)
+	}
+
+	if chainA.GetEffectCount() != 1 {
+		t.Errorf("expected chain A to have 1 effect, got %d", chainA.GetEffectCount())
+	}
+	if chainB.GetEffectCount() != 1 {
+		t.Errorf("expected chain B to have 1 effect, got %d", chainB.GetEffectCount())
+	}
+	if chainA.GetOutputNode() == nil {
+		t.Error("expected chain A's output node to be non-nil with the shared effect routed in")
+	}
+	if chainB.GetOutputNode() == nil {
+		t.Error("expected chain B's output node to be non-nil with the shared effect routed in")
+	}
+
+	// Removing the shared effect from chain A must not destroy its AU, since
+	// chain B still references it.
+	if err := chainA.RemoveEffect(0); err != nil {
+		t.Fatalf("RemoveEffect on chain A: %v", err)
+	}
+	if chainB.GetEffectCount() != 1 {
+		t.Errorf("expected chain B's effect to survive chain A's removal, got %d effects", chainB.GetEffectCount())
+	}
+
+	if err := registry.Release("send-reverb"); err != nil {
+		if !errors.Is(err, ErrEffectInUse) {
+			t.Fatalf("expected ErrEffectInUse releasing while chain B still holds a reference, got: %v", err)
+		}
+	} else {
+		t.Fatal("expected Release to refuse destroying the AU while chain B still holds a reference")
+	}
+
+	if err := chainB.RemoveEffect(0); err != nil {
+		t.Fatalf("RemoveEffect on chain B: %v", err)
+	}
+	if err := registry.Release("send-reverb"); err != nil {
+		t.Fatalf("expected Release to succeed once both chains released their reference, got: %v", err)
+	}
+
+	if err := registry.Release("send-reverb"); err == nil {
+		t.Fatal("expected Release of an already-destroyed shared effect to fail")
+	}
+}
+
+func TestAddSharedEffectRejectsMismatchedEngine(t *testing.T) {
+	engA, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine A: %v", err)
+	}
+	defer engA.Destroy()
+	engB, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine B: %v", err)
+	}
+	defer engB.Destroy()
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 1 {
+		t.Skip("Need at least 1 Apple AU effect for shared effect tests")
+	}
+	reverbPlugin, err := effectInfos[0].Introspect()
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+
+	registry := NewEffectRegistry()
+	shared, err := registry.Acquire("send-reverb", reverbPlugin)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	chainA := NewPluginChain(ChainConfig{Name: "Channel A", EnginePtr: engA.Ptr()})
+	defer chainA.Release()
+	if err := chainA.AddSharedEffect(shared); err != nil {
+		t.Fatalf("AddSharedEffect on chain A: %v", err)
+	}
+
+	chainB := NewPluginChain(ChainConfig{Name: "Channel B", EnginePtr: engB.Ptr()})
+	defer chainB.Release()
+	if err := chainB.AddSharedEffect(shared); err == nil {
+		t.Fatal("expected AddSharedEffect to reject a shared effect bound to a different engine")
+	}
+}
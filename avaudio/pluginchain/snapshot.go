@@ -0,0 +1,184 @@
+package pluginchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SnapshotSlotA and SnapshotSlotB are the two slots CompareAB diffs against
+// each other - the "A/B" of A/B snapshotting.
+const (
+	SnapshotSlotA = 0
+	SnapshotSlotB = 1
+)
+
+// EffectSnapshot captures one chain position's plugin identity, bypass
+// state, and parameter values at the moment a snapshot was taken.
+type EffectSnapshot struct {
+	PluginName string             `json:"pluginName"`
+	Bypassed   bool               `json:"bypassed"`
+	Parameters map[uint64]float32 `json:"parameters"` // Parameter.Address -> CurrentValue
+}
+
+// ChainSnapshot captures an entire PluginChain's effect order and per-effect
+// state for one slot.
+type ChainSnapshot struct {
+	Effects []EffectSnapshot `json:"effects"`
+}
+
+// SnapshotInfo summarizes a stored slot for ListSnapshots, without its full
+// per-parameter contents.
+type SnapshotInfo struct {
+	Slot        int
+	EffectCount int
+}
+
+// ParameterDelta reports one parameter's value in slot A vs slot B.
+type ParameterDelta struct {
+	EffectIndex int
+	PluginName  string
+	Address     uint64
+	ValueA      float32
+	ValueB      float32
+}
+
+// SnapshotDiff is the result of CompareAB: what changed between slot A and
+// slot B.
+type SnapshotDiff struct {
+	Parameters    []ParameterDelta
+	BypassChanged []int // effect indices whose bypass state differs between A and B
+}
+
+// SaveSnapshot captures the chain's current effect order, parameters, and
+// bypass state into slot. An existing snapshot in that slot is overwritten.
+func (pc *PluginChain) SaveSnapshot(slot int) error {
+	effects := make([]EffectSnapshot, len(pc.plugins))
+	for i, plugin := range pc.plugins {
+		params := make(map[uint64]float32, len(plugin.Parameters))
+		for _, p := range plugin.Parameters {
+			params[p.Address] = p.CurrentValue
+		}
+		effects[i] = EffectSnapshot{
+			PluginName: plugin.Name,
+			Bypassed:   pc.bypassed[i],
+			Parameters: params,
+		}
+	}
+
+	pc.snapshots[slot] = ChainSnapshot{Effects: effects}
+	return nil
+}
+
+// LoadSnapshot restores slot's parameter values and bypass flags onto the
+// chain's current effects. It does not add, remove, or reorder effects -
+// a snapshot only carries plugin names and values, not the *plugins.Plugin
+// needed to recreate an AVAudioUnitEffect, so LoadSnapshot requires the
+// chain to already have the same effect count and order the snapshot was
+// taken with.
+func (pc *PluginChain) LoadSnapshot(slot int) error {
+	snapshot, exists := pc.snapshots[slot]
+	if !exists {
+		return fmt.Errorf("no snapshot in slot %d", slot)
+	}
+	if len(snapshot.Effects) != len(pc.effects) {
+		return fmt.Errorf("snapshot in slot %d has %d effects, chain has %d", slot, len(snapshot.Effects), len(pc.effects))
+	}
+
+	for i, effectSnapshot := range snapshot.Effects {
+		if effectSnapshot.PluginName != pc.plugins[i].Name {
+			return fmt.Errorf("snapshot effect %d is %q, chain effect %d is %q", i, effectSnapshot.PluginName, i, pc.plugins[i].Name)
+		}
+
+		if err := pc.SetEffectBypass(i, effectSnapshot.Bypassed); err != nil {
+			return err
+		}
+
+		for _, param := range pc.plugins[i].Parameters {
+			value, ok := effectSnapshot.Parameters[param.Address]
+			if !ok {
+				continue
+			}
+			if err := pc.SetParameter(i, param, value); err != nil {
+				return fmt.Errorf("restore parameter %s on effect %d: %w", param.Identifier, i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ListSnapshots returns info for every occupied slot, ordered by slot number.
+func (pc *PluginChain) ListSnapshots() []SnapshotInfo {
+	slots := make([]int, 0, len(pc.snapshots))
+	for slot := range pc.snapshots {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+
+	infos := make([]SnapshotInfo, len(slots))
+	for i, slot := range slots {
+		infos[i] = SnapshotInfo{Slot: slot, EffectCount: len(pc.snapshots[slot].Effects)}
+	}
+	return infos
+}
+
+// CompareAB reports per-parameter and per-bypass differences between
+// SnapshotSlotA and SnapshotSlotB.
+func (pc *PluginChain) CompareAB() (SnapshotDiff, error) {
+	a, exists := pc.snapshots[SnapshotSlotA]
+	if !exists {
+		return SnapshotDiff{}, fmt.Errorf("no snapshot in slot A (%d)", SnapshotSlotA)
+	}
+	b, exists := pc.snapshots[SnapshotSlotB]
+	if !exists {
+		return SnapshotDiff{}, fmt.Errorf("no snapshot in slot B (%d)", SnapshotSlotB)
+	}
+	if len(a.Effects) != len(b.Effects) {
+		return SnapshotDiff{}, fmt.Errorf("slot A has %d effects, slot B has %d", len(a.Effects), len(b.Effects))
+	}
+
+	var diff SnapshotDiff
+	for i := range a.Effects {
+		effectA := a.Effects[i]
+		effectB := b.Effects[i]
+
+		if effectA.Bypassed != effectB.Bypassed {
+			diff.BypassChanged = append(diff.BypassChanged, i)
+		}
+
+		for address, valueA := range effectA.Parameters {
+			valueB, ok := effectB.Parameters[address]
+			if !ok || valueA == valueB {
+				continue
+			}
+			diff.Parameters = append(diff.Parameters, ParameterDelta{
+				EffectIndex: i,
+				PluginName:  effectA.PluginName,
+				Address:     address,
+				ValueA:      valueA,
+				ValueB:      valueB,
+			})
+		}
+	}
+
+	return diff, nil
+}
+
+// ExportPreset writes every occupied snapshot slot to w as JSON, for
+// ChainManager.SavePresetBank.
+func (pc *PluginChain) ExportPreset(w io.Writer) error {
+	return json.NewEncoder(w).Encode(pc.snapshots)
+}
+
+// ImportPreset replaces this chain's snapshot slots with the ones decoded
+// from r, previously written by ExportPreset.
+func (pc *PluginChain) ImportPreset(r io.Reader) error {
+	var snapshots map[int]ChainSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshots); err != nil {
+		return fmt.Errorf("failed to decode preset: %w", err)
+	}
+	pc.snapshots = snapshots
+	return nil
+}
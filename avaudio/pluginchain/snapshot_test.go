@@ -0,0 +1,145 @@
+package pluginchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/plugins"
+)
+
+func newTestChainWithEffects(t *testing.T) (*PluginChain, func()) {
+	t.Helper()
+
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	chain := NewPluginChain(ChainConfig{Name: "Snapshot Chain", EnginePtr: eng.Ptr()})
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		eng.Destroy()
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		eng.Destroy()
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+
+	testPlugins, err := effectInfos.Introspect()
+	if err != nil {
+		eng.Destroy()
+		t.Fatalf("Failed to introspect plugins: %v", err)
+	}
+	if len(testPlugins) == 0 {
+		eng.Destroy()
+		t.Skip("No plugins available for testing")
+	}
+
+	if err := chain.AddEffect(testPlugins[0]); err != nil {
+		eng.Destroy()
+		t.Fatalf("Failed to add effect: %v", err)
+	}
+
+	return chain, func() {
+		chain.Release()
+		eng.Destroy()
+	}
+}
+
+func TestPluginChainSnapshots(t *testing.T) {
+	chain, cleanup := newTestChainWithEffects(t)
+	defer cleanup()
+
+	if len(chain.plugins[0].Parameters) == 0 {
+		t.Skip("Test effect has no parameters to snapshot")
+	}
+	param := chain.plugins[0].Parameters[0]
+
+	t.Run("SaveAndListSnapshot", func(t *testing.T) {
+		if err := chain.SaveSnapshot(SnapshotSlotA); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+
+		infos := chain.ListSnapshots()
+		if len(infos) != 1 || infos[0].Slot != SnapshotSlotA {
+			t.Errorf("Expected one snapshot in slot A, got %+v", infos)
+		}
+	})
+
+	t.Run("LoadSnapshotRestoresParameter", func(t *testing.T) {
+		if err := chain.SetParameter(0, param, param.MinValue); err != nil {
+			t.Fatalf("SetParameter failed: %v", err)
+		}
+		if err := chain.SaveSnapshot(SnapshotSlotA); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+
+		if err := chain.SetParameter(0, param, param.MaxValue); err != nil {
+			t.Fatalf("SetParameter failed: %v", err)
+		}
+
+		if err := chain.LoadSnapshot(SnapshotSlotA); err != nil {
+			t.Fatalf("LoadSnapshot failed: %v", err)
+		}
+
+		value, err := chain.GetParameter(0, param)
+		if err != nil {
+			t.Fatalf("GetParameter failed: %v", err)
+		}
+		if value != param.MinValue {
+			t.Errorf("Expected restored value %.3f, got %.3f", param.MinValue, value)
+		}
+	})
+
+	t.Run("CompareAB", func(t *testing.T) {
+		chain.SetParameter(0, param, param.MinValue)
+		if err := chain.SaveSnapshot(SnapshotSlotA); err != nil {
+			t.Fatalf("SaveSnapshot A failed: %v", err)
+		}
+
+		chain.SetParameter(0, param, param.MaxValue)
+		if err := chain.SaveSnapshot(SnapshotSlotB); err != nil {
+			t.Fatalf("SaveSnapshot B failed: %v", err)
+		}
+
+		diff, err := chain.CompareAB()
+		if err != nil {
+			t.Fatalf("CompareAB failed: %v", err)
+		}
+		if len(diff.Parameters) == 0 {
+			t.Error("Expected at least one parameter delta between A and B")
+		}
+	})
+
+	t.Run("ExportImportPreset", func(t *testing.T) {
+		if err := chain.SaveSnapshot(SnapshotSlotA); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := chain.ExportPreset(&buf); err != nil {
+			t.Fatalf("ExportPreset failed: %v", err)
+		}
+
+		fresh := NewPluginChain(ChainConfig{Name: "Fresh"})
+		if err := fresh.ImportPreset(&buf); err != nil {
+			t.Fatalf("ImportPreset failed: %v", err)
+		}
+
+		if len(fresh.ListSnapshots()) != 1 {
+			t.Errorf("Expected imported chain to have one snapshot, got %d", len(fresh.ListSnapshots()))
+		}
+	})
+
+	t.Run("CompareABMissingSlot", func(t *testing.T) {
+		empty := NewPluginChain(ChainConfig{Name: "Empty"})
+		if _, err := empty.CompareAB(); err == nil {
+			t.Error("Expected error comparing A/B with no snapshots saved")
+		}
+	})
+}
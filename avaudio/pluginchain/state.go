@@ -0,0 +1,202 @@
+package pluginchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// chainStateSchemaVersion is bumped whenever ChainState's on-disk shape
+// changes in a way that isn't purely additive - see the plugin catalog's
+// own BundlePath/Version/BundleModTime pinning (plugins.PluginInfo) for why
+// an explicit version field, rather than best-effort field sniffing, is
+// worth the extra int.
+const chainStateSchemaVersion = 1
+
+// ParameterState captures one parameter's address and value for ChainState.
+type ParameterState struct {
+	Address      uint64  `json:"address"`
+	CurrentValue float32 `json:"currentValue"`
+}
+
+// EffectState identifies one chain position's plugin by AudioComponent
+// identity (manufacturer/subtype/type, the triple macOS uses to look up an
+// installed AU) plus its name and version for diagnostics, alongside the
+// parameter values and bypass flag captured for it.
+type EffectState struct {
+	Manufacturer string           `json:"manufacturer"`
+	Subtype      string           `json:"subtype"`
+	Type         string           `json:"type"`
+	Name         string           `json:"name"`
+	Version      string           `json:"version,omitempty"`
+	Parameters   []ParameterState `json:"parameters"`
+	Bypassed     bool             `json:"bypassed"`
+}
+
+// ChainState is the JSON-serializable form of a PluginChain produced by
+// SaveState and consumed by LoadState - a preset that, unlike ChainSnapshot
+// (snapshot.go), carries enough plugin identity to rebuild the chain's
+// effects from scratch rather than only restore values onto effects that
+// already exist.
+type ChainState struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	Name          string        `json:"name"`
+	Effects       []EffectState `json:"effects"`
+}
+
+// SaveState serializes the chain's name, ordered effects, their parameter
+// values, and bypass flags to JSON.
+func (pc *PluginChain) SaveState() ([]byte, error) {
+	state := ChainState{
+		SchemaVersion: chainStateSchemaVersion,
+		Name:          pc.name,
+		Effects:       make([]EffectState, len(pc.plugins)),
+	}
+
+	for i, plugin := range pc.plugins {
+		params := make([]ParameterState, len(plugin.Parameters))
+		for j, p := range plugin.Parameters {
+			params[j] = ParameterState{Address: p.Address, CurrentValue: p.CurrentValue}
+		}
+		state.Effects[i] = EffectState{
+			Manufacturer: plugin.ManufacturerID,
+			Subtype:      plugin.Subtype,
+			Type:         plugin.Type,
+			Name:         plugin.Name,
+			Version:      plugin.Version,
+			Parameters:   params,
+			Bypassed:     pc.bypassed[i],
+		}
+	}
+
+	return json.Marshal(state)
+}
+
+// LoadState creates a new PluginChain on enginePtr from data previously
+// produced by SaveState. Every effect's plugin identity is resolved against
+// plugins.List() before any effect is added; if any are missing on this
+// system, LoadState returns an error naming all of them rather than
+// building a partial chain.
+func LoadState(enginePtr unsafe.Pointer, data []byte) (*PluginChain, error) {
+	state, err := decodeChainState(data)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := resolveEffectPlugins(state.Effects)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := NewPluginChain(ChainConfig{Name: state.Name, EnginePtr: enginePtr})
+	if err := chain.rebuildFrom(state, resolved); err != nil {
+		chain.Release()
+		return nil, err
+	}
+	return chain, nil
+}
+
+// LoadState replaces the chain's effects in place with the ones described by
+// data, previously produced by SaveState. As with the package-level
+// LoadState, every plugin identity is resolved before the chain's existing
+// effects are cleared, so a missing plugin leaves the chain untouched.
+func (pc *PluginChain) LoadState(data []byte) error {
+	state, err := decodeChainState(data)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolveEffectPlugins(state.Effects)
+	if err != nil {
+		return err
+	}
+
+	pc.Clear()
+	pc.name = state.Name
+	return pc.rebuildFrom(state, resolved)
+}
+
+// decodeChainState unmarshals and version-checks data.
+func decodeChainState(data []byte) (ChainState, error) {
+	var state ChainState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return ChainState{}, fmt.Errorf("failed to decode chain state: %w", err)
+	}
+	if state.SchemaVersion != chainStateSchemaVersion {
+		return ChainState{}, fmt.Errorf("unsupported chain state schema version %d (this build supports %d)", state.SchemaVersion, chainStateSchemaVersion)
+	}
+	return state, nil
+}
+
+// resolveEffectPlugins looks up each effect's plugin by AudioComponent
+// identity, introspecting matches so the caller gets a fully-populated
+// *plugins.Plugin ready for AddEffect. It resolves every entry before
+// returning so a caller can fail on all missing plugins at once instead of
+// one at a time.
+func resolveEffectPlugins(effects []EffectState) ([]*plugins.Plugin, error) {
+	infos, err := plugins.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed plugins: %w", err)
+	}
+
+	resolved := make([]*plugins.Plugin, len(effects))
+	var missing []string
+	for i, es := range effects {
+		matches := infos.ByType(es.Type).BySubtype(es.Subtype).ByManufacturer(es.Manufacturer)
+		if len(matches) == 0 {
+			missing = append(missing, fmt.Sprintf("%s (%s/%s/%s)", es.Name, es.Manufacturer, es.Subtype, es.Type))
+			continue
+		}
+
+		plugin, err := matches[0].Introspect()
+		if err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%s/%s/%s): %v", es.Name, es.Manufacturer, es.Subtype, es.Type, err))
+			continue
+		}
+		resolved[i] = plugin
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing plugins: %s", strings.Join(missing, "; "))
+	}
+	return resolved, nil
+}
+
+// rebuildFrom adds each resolved plugin to the chain in order and applies
+// its saved parameter values and bypass flag. Callers must have already
+// cleared any existing effects.
+func (pc *PluginChain) rebuildFrom(state ChainState, resolved []*plugins.Plugin) error {
+	for i, plugin := range resolved {
+		if err := pc.AddEffect(plugin); err != nil {
+			return fmt.Errorf("failed to add effect %s: %w", plugin.Name, err)
+		}
+		if err := pc.applyEffectState(i, state.Effects[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEffectState restores one effect's parameter values and bypass flag,
+// assuming it was just added at effectIndex.
+func (pc *PluginChain) applyEffectState(effectIndex int, es EffectState) error {
+	if err := pc.SetEffectBypass(effectIndex, es.Bypassed); err != nil {
+		return err
+	}
+
+	for _, param := range pc.plugins[effectIndex].Parameters {
+		for _, saved := range es.Parameters {
+			if saved.Address != param.Address {
+				continue
+			}
+			if err := pc.SetParameter(effectIndex, param, saved.CurrentValue); err != nil {
+				return fmt.Errorf("restore parameter %s on effect %d: %w", param.Identifier, effectIndex, err)
+			}
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package pluginchain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/plugins"
+)
+
+func TestChainStateRoundTrip(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	chain := NewPluginChain(ChainConfig{Name: "State Test Chain", EnginePtr: eng.Ptr()})
+	defer chain.Release()
+
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) < 3 {
+		t.Skip("Need at least 3 Apple AU effects for chain state tests")
+	}
+
+	for _, info := range effectInfos[:3] {
+		if err := chain.AddEffectFromPluginInfo(info); err != nil {
+			t.Fatalf("Failed to add effect: %v", err)
+		}
+	}
+
+	// Modify parameters and bypass state so the round trip has something to verify.
+	if err := chain.SetEffectBypass(1, true); err != nil {
+		t.Fatalf("SetEffectBypass: %v", err)
+	}
+	modified := make(map[int]plugins.Parameter)
+	for i, plugin := range chain.plugins {
+		for _, param := range plugin.Parameters {
+			if param.IsWritable {
+				if err := chain.SetParameter(i, param, param.MinValue); err != nil {
+					t.Fatalf("SetParameter: %v", err)
+				}
+				modified[i] = param
+				break
+			}
+		}
+	}
+
+	data, err := chain.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+
+	restored, err := LoadState(eng.Ptr(), data)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	defer restored.Release()
+
+	if restored.GetName() != "State Test Chain" {
+		t.Errorf("expected restored chain name %q, got %q", "State Test Chain", restored.GetName())
+	}
+	if restored.GetEffectCount() != 3 {
+		t.Fatalf("expected 3 restored effects, got %d", restored.GetEffectCount())
+	}
+
+	bypassed, err := restored.IsEffectBypassed(1)
+	if err != nil || !bypassed {
+		t.Errorf("expected restored effect 1 to be bypassed, got %v, err %v", bypassed, err)
+	}
+
+	for i, param := range modified {
+		got, err := restored.GetParameter(i, param)
+		if err != nil {
+			t.Fatalf("GetParameter: %v", err)
+		}
+		if got != param.MinValue {
+			t.Errorf("effect %d: expected restored parameter %s to be %v, got %v", i, param.Identifier, param.MinValue, got)
+		}
+	}
+
+	// LoadState in place should produce the same result.
+	inPlace := NewPluginChain(ChainConfig{Name: "Placeholder", EnginePtr: eng.Ptr()})
+	defer inPlace.Release()
+	if err := inPlace.LoadState(data); err != nil {
+		t.Fatalf("PluginChain.LoadState: %v", err)
+	}
+	if inPlace.GetEffectCount() != 3 {
+		t.Errorf("expected 3 effects after in-place LoadState, got %d", inPlace.GetEffectCount())
+	}
+}
+
+func TestChainStateLoadMissingPlugin(t *testing.T) {
+	data := []byte(`{
+		"schemaVersion": 1,
+		"name": "Missing Plugin Chain",
+		"effects": [
+			{"manufacturer": "zzzz", "subtype": "nope", "type": "aufx", "name": "Definitely Not Installed", "parameters": [], "bypassed": false}
+		]
+	}`)
+
+	if _, err := LoadState(nil, data); err == nil {
+		t.Fatal("expected an error loading a chain state naming an uninstalled plugin")
+	} else if !strings.Contains(err.Error(), "Definitely Not Installed") {
+		t.Errorf("expected the error to name the missing plugin, got: %v", err)
+	}
+
+	pc := NewPluginChain(ChainConfig{Name: "Existing", EnginePtr: nil})
+	if err := pc.LoadState(data); err == nil {
+		t.Fatal("expected an error loading a chain state naming an uninstalled plugin")
+	}
+	if pc.GetEffectCount() != 0 {
+		t.Errorf("expected the chain to be left untouched after a failed LoadState, got %d effects", pc.GetEffectCount())
+	}
+}
+
+func TestChainStateRejectsUnknownSchemaVersion(t *testing.T) {
+	data := []byte(`{"schemaVersion": 999, "name": "Future", "effects": []}`)
+	if _, err := LoadState(nil, data); err == nil {
+		t.Fatal("expected an error loading a chain state with an unsupported schema version")
+	}
+}
@@ -0,0 +1,268 @@
+package pluginchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/avaudio/unit"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// storedEffect is one chain position's on-disk representation: enough to
+// find the right EffectDriver and hand it back the same plugin identity and
+// parameter values AddEffect captured when it was live.
+type storedEffect struct {
+	Driver     string             `json:"driver"` // EffectDriver.Name(), e.g. "audiounit"
+	PluginInfo plugins.PluginInfo `json:"pluginInfo"`
+	Parameters map[string]float32 `json:"parameters"` // Parameter.Identifier -> value
+	Bypassed   bool               `json:"bypassed"`
+}
+
+// storedChain is one chain's on-disk representation, written by
+// ChainManager's store as "<name>.chain.json".
+type storedChain struct {
+	Name    string         `json:"name"`
+	Effects []storedEffect `json:"effects"`
+}
+
+const chainFileSuffix = ".chain.json"
+
+// NewChainManagerWithStore is NewChainManager plus a persistence layer
+// modeled on Docker's volume store: dir is created if missing, every
+// "<name>.chain.json" file already in it is restored into a live chain
+// (via each effect's registered EffectDriver - see RegisterDriver), and
+// CreateChain/AddEffect write through to dir from then on.
+func NewChainManagerWithStore(config ManagerConfig, dir string) (*ChainManager, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("pluginchain: failed to create store directory %s: %w", dir, err)
+	}
+
+	cm := NewChainManager(config)
+	cm.storeDir = dir
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("pluginchain: failed to read store directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || len(entry.Name()) <= len(chainFileSuffix) {
+			continue
+		}
+		if entry.Name()[len(entry.Name())-len(chainFileSuffix):] != chainFileSuffix {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("pluginchain: failed to read %s: %w", entry.Name(), err)
+		}
+
+		var stored storedChain
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("pluginchain: failed to parse %s: %w", entry.Name(), err)
+		}
+
+		if err := cm.restoreChain(stored); err != nil {
+			return nil, fmt.Errorf("pluginchain: failed to restore chain %q: %w", stored.Name, err)
+		}
+	}
+
+	return cm, nil
+}
+
+// restoreChain recreates stored as a live chain and populates it with
+// effects instantiated via each one's registered EffectDriver.
+func (cm *ChainManager) restoreChain(stored storedChain) error {
+	chain, err := cm.createChainNoStore(stored.Name)
+	if err != nil {
+		return err
+	}
+
+	for i, se := range stored.Effects {
+		driver, ok := LookupDriver(se.Driver)
+		if !ok {
+			return fmt.Errorf("no driver registered for %q (effect %d)", se.Driver, i)
+		}
+
+		effect, err := driver.Instantiate(se.PluginInfo, se.Parameters)
+		if err != nil {
+			return fmt.Errorf("effect %d (%s): %w", i, se.PluginInfo.Name, err)
+		}
+
+		plugin, err := se.PluginInfo.Introspect()
+		if err != nil {
+			return fmt.Errorf("effect %d (%s): %w", i, se.PluginInfo.Name, err)
+		}
+
+		if err := chain.restoreEffect(effect, plugin, se.Bypassed); err != nil {
+			return fmt.Errorf("effect %d (%s): %w", i, se.PluginInfo.Name, err)
+		}
+	}
+
+	// Re-persist once restoration is complete; idempotent, since the data
+	// on disk is what we just read, but it keeps the store in its
+	// normalized (json.MarshalIndent) form going forward.
+	return cm.writeChainLocked(chain)
+}
+
+// restoreEffect appends an already-instantiated effect (from an
+// EffectDriver, rather than AddEffect's own unit.CreateEffect call) to the
+// chain with the same bookkeeping and native wiring AddEffect performs.
+func (pc *PluginChain) restoreEffect(effect *unit.Effect, plugin *plugins.Plugin, bypassed bool) error {
+	if pc.enginePtr == nil {
+		return fmt.Errorf("chain %s has no engine reference", pc.name)
+	}
+
+	pc.effects = append(pc.effects, effect)
+	pc.plugins = append(pc.plugins, plugin)
+	pc.bypassed = append(pc.bypassed, bypassed)
+	pc.shared = append(pc.shared, false)
+	pc.wetDry = append(pc.wetDry, 1.0)
+
+	if pc.routingMode != RoutingSerial {
+		sendMixer, err := node.CreateMixer()
+		if err != nil {
+			return fmt.Errorf("failed to create send mixer for effect %s: %v", plugin.Name, err)
+		}
+		pc.sendMixers = append(pc.sendMixers, sendMixer)
+	}
+
+	return pc.updateConnections()
+}
+
+// serializeChain captures chain's effects (skipping shared ones - those are
+// owned by an EffectRegistry, not this chain, and aren't this chain's to
+// persist) into the on-disk storedChain shape.
+func serializeChain(chain *PluginChain) storedChain {
+	stored := storedChain{Name: chain.name}
+
+	for i, plugin := range chain.plugins {
+		if chain.shared[i] {
+			continue
+		}
+
+		params := make(map[string]float32, len(plugin.Parameters))
+		for _, p := range plugin.Parameters {
+			params[p.Identifier] = p.CurrentValue
+		}
+
+		stored.Effects = append(stored.Effects, storedEffect{
+			Driver: "audiounit",
+			PluginInfo: plugins.PluginInfo{
+				Name:           plugin.Name,
+				ManufacturerID: plugin.ManufacturerID,
+				Type:           plugin.Type,
+				Subtype:        plugin.Subtype,
+				Category:       plugin.Category,
+				BundlePath:     plugin.BundlePath,
+				Version:        plugin.Version,
+				BundleModTime:  plugin.BundleModTime,
+				ContentHash:    plugin.ContentHash,
+				IsSandboxSafe:  plugin.IsSandboxSafe,
+				IsDeprecated:   plugin.IsDeprecated,
+				HasCustomView:  plugin.HasCustomView,
+			},
+			Parameters: params,
+			Bypassed:   chain.bypassed[i],
+		})
+	}
+
+	return stored
+}
+
+// chainFilePath returns the path NewChainManagerWithStore/writeChainLocked
+// use for name within cm's store directory.
+func (cm *ChainManager) chainFilePath(name string) string {
+	return filepath.Join(cm.storeDir, name+chainFileSuffix)
+}
+
+// writeChainLocked writes chain's current state to disk atomically (temp
+// file + rename), a no-op if cm has no store directory configured.
+func (cm *ChainManager) writeChainLocked(chain *PluginChain) error {
+	if cm.storeDir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(serializeChain(chain), "", "  ")
+	if err != nil {
+		return fmt.Errorf("pluginchain: failed to marshal chain %q: %w", chain.name, err)
+	}
+
+	path := cm.chainFilePath(chain.name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("pluginchain: failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("pluginchain: failed to rename %s to %s: %w", tmp, path, err)
+	}
+	return nil
+}
+
+// removeChainFileLocked deletes name's on-disk file, a no-op if cm has no
+// store directory configured or the file doesn't exist.
+func (cm *ChainManager) removeChainFileLocked(name string) error {
+	if cm.storeDir == "" {
+		return nil
+	}
+	if err := os.Remove(cm.chainFilePath(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("pluginchain: failed to remove %s: %w", cm.chainFilePath(name), err)
+	}
+	return nil
+}
+
+// AddEffect adds plugin to chainName's chain (see PluginChain.AddEffect)
+// and, if cm has a store directory configured, writes the chain through to
+// disk afterward.
+func (cm *ChainManager) AddEffect(chainName string, plugin *plugins.Plugin) error {
+	chain, err := cm.GetChain(chainName)
+	if err != nil {
+		return err
+	}
+	if err := chain.AddEffect(plugin); err != nil {
+		return err
+	}
+	return cm.writeChainLocked(chain)
+}
+
+// Export writes every managed chain (in the same shape NewChainManagerWithStore
+// reads back) to w as a single JSON array, for sharing a preset bundle
+// between users independent of any one store directory.
+func (cm *ChainManager) Export(w io.Writer) error {
+	names := cm.ListChains()
+	sort.Strings(names)
+
+	bundle := make([]storedChain, 0, len(names))
+	for _, name := range names {
+		bundle = append(bundle, serializeChain(cm.chains[name]))
+	}
+
+	return json.NewEncoder(w).Encode(bundle)
+}
+
+// Import reads a bundle written by Export and restores each chain into cm,
+// writing through to cm's store directory (if configured) as it goes. A
+// chain whose name already exists in cm is skipped rather than overwritten.
+func (cm *ChainManager) Import(r io.Reader) error {
+	var bundle []storedChain
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return fmt.Errorf("pluginchain: failed to decode import bundle: %w", err)
+	}
+
+	for _, stored := range bundle {
+		if cm.HasChain(stored.Name) {
+			continue
+		}
+		if err := cm.restoreChain(stored); err != nil {
+			return fmt.Errorf("pluginchain: failed to import chain %q: %w", stored.Name, err)
+		}
+	}
+
+	return nil
+}
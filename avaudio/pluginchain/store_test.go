@@ -0,0 +1,181 @@
+package pluginchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+func TestNewChainManagerWithStoreCreatesDirectoryAndWritesThrough(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dir := filepath.Join(t.TempDir(), "chains")
+
+	manager, err := NewChainManagerWithStore(ManagerConfig{EnginePtr: eng.Ptr()}, dir)
+	if err != nil {
+		t.Fatalf("NewChainManagerWithStore failed: %v", err)
+	}
+	defer manager.Release()
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("expected store directory to be created: %v", err)
+	}
+
+	if _, err := manager.CreateChain("Vocals FX"); err != nil {
+		t.Fatalf("CreateChain failed: %v", err)
+	}
+
+	path := filepath.Join(dir, "Vocals FX"+chainFileSuffix)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected chain file at %s: %v", path, err)
+	}
+
+	var stored storedChain
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("failed to parse written chain file: %v", err)
+	}
+	if stored.Name != "Vocals FX" {
+		t.Errorf("stored.Name = %q, want %q", stored.Name, "Vocals FX")
+	}
+	if len(stored.Effects) != 0 {
+		t.Errorf("expected no effects in a freshly created chain, got %d", len(stored.Effects))
+	}
+
+	if err := manager.DeleteChain("Vocals FX"); err != nil {
+		t.Fatalf("DeleteChain failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected chain file to be removed after DeleteChain, stat err = %v", err)
+	}
+}
+
+func TestNewChainManagerWithStoreReloadsExistingChains(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dir := t.TempDir()
+
+	first, err := NewChainManagerWithStore(ManagerConfig{EnginePtr: eng.Ptr()}, dir)
+	if err != nil {
+		t.Fatalf("NewChainManagerWithStore failed: %v", err)
+	}
+	if _, err := first.CreateChain("Drums FX"); err != nil {
+		t.Fatalf("CreateChain failed: %v", err)
+	}
+	first.Release()
+
+	second, err := NewChainManagerWithStore(ManagerConfig{EnginePtr: eng.Ptr()}, dir)
+	if err != nil {
+		t.Fatalf("reloading NewChainManagerWithStore failed: %v", err)
+	}
+	defer second.Release()
+
+	if !second.HasChain("Drums FX") {
+		t.Error("expected 'Drums FX' to be restored from the store directory")
+	}
+}
+
+func TestChainManagerWriteChainLockedNoopWithoutStoreDir(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	manager := NewChainManager(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer manager.Release()
+
+	chain, err := manager.CreateChain("No Store")
+	if err != nil {
+		t.Fatalf("CreateChain failed: %v", err)
+	}
+
+	if err := manager.writeChainLocked(chain); err != nil {
+		t.Errorf("writeChainLocked with no store directory should be a no-op, got %v", err)
+	}
+}
+
+func TestChainManagerExportImportEmptyBundle(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	manager := NewChainManager(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer manager.Release()
+
+	var buf bytes.Buffer
+	if err := manager.Export(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var bundle []storedChain
+	if err := json.Unmarshal(buf.Bytes(), &bundle); err != nil {
+		t.Fatalf("failed to parse exported bundle: %v", err)
+	}
+	if len(bundle) != 0 {
+		t.Errorf("expected an empty bundle, got %d chains", len(bundle))
+	}
+
+	imported := NewChainManager(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer imported.Release()
+
+	if err := imported.Import(&buf); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if imported.GetChainCount() != 0 {
+		t.Errorf("expected no chains after importing an empty bundle, got %d", imported.GetChainCount())
+	}
+}
+
+func TestChainManagerExportImportRoundTrip(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	source := NewChainManager(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer source.Release()
+
+	if _, err := source.CreateChain("Bass FX"); err != nil {
+		t.Fatalf("CreateChain failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := source.Export(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	target := NewChainManager(ManagerConfig{EnginePtr: eng.Ptr()})
+	defer target.Release()
+
+	if err := target.Import(&buf); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !target.HasChain("Bass FX") {
+		t.Error("expected 'Bass FX' to exist after Import")
+	}
+
+	// Importing again should skip chains that already exist rather than error.
+	var buf2 bytes.Buffer
+	if err := source.Export(&buf2); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if err := target.Import(&buf2); err != nil {
+		t.Fatalf("re-Import of an already-present chain should be a no-op, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package avaudio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/midi"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestPolySynthChordProgression mirrors TestAudibleTone's pipeline, but
+// drives a chord progression through a PolySynth from an in-process Feeder
+// instead of setAudioParams, and verifies output with the tap-based RMS
+// helper instead of listening for it.
+func TestPolySynthChordProgression(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping chord progression test in short mode")
+	}
+
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	synth, err := sourcenode.NewPolySynth(eng, 4)
+	if err != nil {
+		t.Fatalf("Failed to create PolySynth: %v", err)
+	}
+	defer synth.Close()
+
+	mainMixer, err := eng.MainMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to get main mixer: %v", err)
+	}
+	outputNode, err := eng.OutputNode()
+	if err != nil {
+		t.Fatalf("Failed to get output node: %v", err)
+	}
+	if err := eng.Connect(mainMixer, outputNode, 0, 0); err != nil {
+		t.Fatalf("Failed to connect mixer to output: %v", err)
+	}
+
+	_ = testutil.MuteMainMixerNoT(eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	feeder := midi.NewFeeder(16)
+	go func() {
+		for e := range feeder.Events() {
+			_ = synth.HandleEvent(e)
+		}
+	}()
+
+	// C major triad: C4, E4, G4.
+	chord := []int{60, 64, 67}
+	for _, note := range chord {
+		feeder.Send(midi.Event{Type: midi.EventNoteOn, Note: note, Velocity: 100})
+	}
+
+	testutil.AssertRMSAbove(t, eng, mainMixer, 0, 0.0001, 2*time.Second)
+
+	for _, note := range chord {
+		feeder.Send(midi.Event{Type: midi.EventNoteOff, Note: note})
+	}
+	feeder.Close()
+}
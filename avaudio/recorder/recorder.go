@@ -0,0 +1,186 @@
+// Package recorder captures a tapped node's audio to a file on disk (WAV,
+// CAF, or AAC via AVAudioFile), the live counterpart to the offline
+// rendering mode on engine.Engine. It's distinct from the top-level
+// recorder package, which captures a whole engine/channel session to HDF5
+// for bit-exact replay; this one is a thin "record what this tap hears"
+// sink, closer to the "record while playing" pattern from SDL/PortAudio.
+package recorder
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include "native/recorder.m"
+#include <stdlib.h>
+
+// Function declarations - CGO resolves AudioFileWriterResult from the
+// native recorder shim.
+AudioFileWriterResult recorder_open(const char* path, int formatID, double sampleRate, int channelCount);
+const char* recorder_write(void* writer, const float* samples, int frameCount, int channelCount, double sourceSampleRate);
+const char* recorder_close(void* writer);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// FileFormat selects the container/codec recorder_open writes, mapped onto
+// AVAudioFile's format IDs on the native side.
+type FileFormat int
+
+const (
+	FormatWAV FileFormat = iota
+	FormatCAF
+	FormatAAC
+)
+
+// Recorder polls a tap at PollInterval and appends the samples it sees to
+// an AVAudioFile-backed writer. Polling mirrors the approach
+// testutil.AssertRMSAbove and channel.Meter already use for tap-based
+// monitoring, just feeding a file instead of computing metrics.
+type Recorder struct {
+	mu      sync.Mutex
+	tap     *tap.Tap
+	writer  unsafe.Pointer
+	channel int
+	sampleRate float64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	err  error
+}
+
+// PollInterval is how often a running Recorder drains its tap's buffered
+// samples into the file.
+const PollInterval = 20 * time.Millisecond
+
+// Start installs a tap on nodePtr/bus and begins writing its audio to path
+// in the given format. The writer's format is taken from the engine's
+// spec (sample rate, channel count); if a future node reports a different
+// native format, samples are written as-is at that rate rather than
+// resampled, since AVAudioFile accepts the buffer's own processing format
+// per write call.
+func Start(eng *engine.Engine, nodePtr unsafe.Pointer, bus int, format FileFormat, path string) (*Recorder, error) {
+	if eng == nil || eng.Ptr() == nil {
+		return nil, errors.New("engine is nil")
+	}
+	if nodePtr == nil {
+		return nil, errors.New("nodePtr is nil")
+	}
+
+	spec := eng.GetSpec()
+	channelCount := spec.ChannelCount
+	if channelCount <= 0 {
+		channelCount = 2
+	}
+	sampleRate := spec.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 48000
+	}
+
+	t, err := tap.InstallTap(eng.Ptr(), nodePtr, bus)
+	if err != nil {
+		return nil, fmt.Errorf("installing tap: %w", err)
+	}
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	result := C.recorder_open(cPath, C.int(format), C.double(sampleRate), C.int(channelCount))
+	if result.error != nil {
+		t.Remove()
+		return nil, errors.New(C.GoString(result.error))
+	}
+	if result.result == nil {
+		t.Remove()
+		return nil, fmt.Errorf("failed to open %q for recording", path)
+	}
+
+	r := &Recorder{
+		tap:        t,
+		writer:     unsafe.Pointer(result.result),
+		channel:    channelCount,
+		sampleRate: sampleRate,
+		stop:       make(chan struct{}),
+	}
+	r.wg.Add(1)
+	go r.run()
+	return r, nil
+}
+
+func (r *Recorder) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.drain()
+		}
+	}
+}
+
+func (r *Recorder) drain() {
+	samples, err := r.tap.GetSamples(r.channel * 8192)
+	if err != nil || len(samples) == 0 {
+		return
+	}
+
+	frameCount := len(samples) / r.channel
+	if frameCount == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	writer := r.writer
+	r.mu.Unlock()
+	if writer == nil {
+		return
+	}
+
+	if errStr := C.recorder_write(writer, (*C.float)(unsafe.Pointer(&samples[0])), C.int(frameCount), C.int(r.channel), C.double(r.sampleRate)); errStr != nil {
+		r.mu.Lock()
+		if r.err == nil {
+			r.err = errors.New(C.GoString(errStr))
+		}
+		r.mu.Unlock()
+	}
+}
+
+// Stop halts polling, flushes any remaining buffered samples, closes the
+// file, and removes the tap. It returns the first write error observed
+// while recording, if any.
+func (r *Recorder) Stop() error {
+	if r == nil {
+		return errors.New("recorder is nil")
+	}
+
+	close(r.stop)
+	r.wg.Wait()
+	r.drain() // pick up anything buffered since the last poll
+
+	r.mu.Lock()
+	writer := r.writer
+	r.writer = nil
+	recordErr := r.err
+	r.mu.Unlock()
+
+	_ = r.tap.Remove()
+
+	if writer != nil {
+		if errStr := C.recorder_close(writer); errStr != nil && recordErr == nil {
+			recordErr = errors.New(C.GoString(errStr))
+		}
+	}
+	return recordErr
+}
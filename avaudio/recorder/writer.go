@@ -0,0 +1,87 @@
+package recorder
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include "native/recorder.m"
+#include <stdlib.h>
+
+AudioFileWriterResult recorder_open(const char* path, int formatID, double sampleRate, int channelCount);
+const char* recorder_write(void* writer, const float* samples, int frameCount, int channelCount, double sourceSampleRate);
+const char* recorder_close(void* writer);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// Writer is a bare AVAudioFile-backed sink for interleaved float32 samples
+// a caller already has in hand, rather than Recorder's tap-polling loop -
+// the building block engine.Engine.RenderOffline writes its rendered
+// chunks to, since an offline render has no tap to poll.
+type Writer struct {
+	native       unsafe.Pointer
+	channelCount int
+	sampleRate   float64
+}
+
+// OpenWriter creates path in the given format, ready to accept interleaved
+// float32 samples at sampleRate/channelCount via Write.
+func OpenWriter(path string, format FileFormat, sampleRate float64, channelCount int) (*Writer, error) {
+	if channelCount <= 0 {
+		channelCount = 2
+	}
+	if sampleRate <= 0 {
+		sampleRate = 48000
+	}
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	result := C.recorder_open(cPath, C.int(format), C.double(sampleRate), C.int(channelCount))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	if result.result == nil {
+		return nil, fmt.Errorf("failed to open %q for recording", path)
+	}
+
+	return &Writer{
+		native:       unsafe.Pointer(result.result),
+		channelCount: channelCount,
+		sampleRate:   sampleRate,
+	}, nil
+}
+
+// Write appends frameCount interleaved frames of channelCount-wide float32
+// samples. It's a no-op on an empty/zero-frame call.
+func (w *Writer) Write(samples []float32, frameCount, channelCount int) error {
+	if w == nil || w.native == nil {
+		return errors.New("writer is closed")
+	}
+	if frameCount <= 0 || len(samples) == 0 {
+		return nil
+	}
+
+	if errStr := C.recorder_write(w.native, (*C.float)(unsafe.Pointer(&samples[0])), C.int(frameCount), C.int(channelCount), C.double(w.sampleRate)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// Close flushes and closes the underlying file. It's safe to call more than
+// once.
+func (w *Writer) Close() error {
+	if w == nil || w.native == nil {
+		return nil
+	}
+	native := w.native
+	w.native = nil
+	if errStr := C.recorder_close(native); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package avaudio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/recorder"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestRecorderCapturesPlayback plays a tone through the main mixer while a
+// Recorder is attached to it, then checks a non-empty file was written.
+func TestRecorderCapturesPlayback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping recorder test in short mode")
+	}
+
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	tone, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("Failed to create tone node: %v", err)
+	}
+	defer tone.Destroy()
+	_ = tone.SetFrequency(440.0)
+	_ = tone.SetAmplitude(0.5)
+
+	nodePtr, err := tone.GetNodePtr()
+	if err != nil {
+		t.Fatalf("Failed to get node pointer: %v", err)
+	}
+	if err := eng.Attach(nodePtr); err != nil {
+		t.Fatalf("Failed to attach node: %v", err)
+	}
+	mainMixer, err := eng.MainMixerNode()
+	if err != nil {
+		t.Fatalf("Failed to get main mixer: %v", err)
+	}
+	if err := eng.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+		t.Fatalf("Failed to connect node to mixer: %v", err)
+	}
+
+	_ = testutil.MuteMainMixerNoT(eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "capture.wav")
+	rec, err := recorder.Start(eng, mainMixer, 0, recorder.FormatWAV, path)
+	if err != nil {
+		t.Fatalf("Failed to start recorder: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Recorder reported an error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat recorded file: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Fatal("expected a non-empty recording")
+	}
+}
@@ -0,0 +1,109 @@
+package avaudio
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/sequencer"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestSequencerOffline2BarsOnsetAlignment renders 2 bars of a quarter-note
+// pattern offline and checks each note's onset lands within one scheduling
+// quantum of the beat grid. The Sequencer's dispatch is only accurate to
+// one quantum (see sequencer.DefaultQuantum), not a true single sample, so
+// that's the bound checked here rather than an exact sample match.
+func TestSequencerOffline2BarsOnsetAlignment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping sequencer test in short mode")
+	}
+
+	spec := testutil.SmallSpec()
+	eng, err := engine.New(spec)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	synth, err := sourcenode.NewPolySynth(eng, 4)
+	if err != nil {
+		t.Fatalf("Failed to create poly synth: %v", err)
+	}
+	defer synth.Close()
+
+	const bpm = 120.0
+	seq := sequencer.New(synth, spec.SampleRate)
+	seq.SetTempo(bpm)
+
+	pattern := sequencer.Pattern{
+		LengthBeats: 8, // 2 bars of 4/4
+		Events: []sequencer.Event{
+			{Beat: 0, DurationBeats: 0.5, Note: 60, Velocity: 100},
+			{Beat: 1, DurationBeats: 0.5, Note: 60, Velocity: 100},
+			{Beat: 2, DurationBeats: 0.5, Note: 60, Velocity: 100},
+			{Beat: 3, DurationBeats: 0.5, Note: 60, Velocity: 100},
+			{Beat: 4, DurationBeats: 0.5, Note: 60, Velocity: 100},
+			{Beat: 5, DurationBeats: 0.5, Note: 60, Velocity: 100},
+			{Beat: 6, DurationBeats: 0.5, Note: 60, Velocity: 100},
+			{Beat: 7, DurationBeats: 0.5, Note: 60, Velocity: 100},
+		},
+	}
+	seq.AddPattern("quarters", pattern)
+
+	samplesPerBeat := spec.SampleRate * 60 / bpm
+	totalFrames := int(8 * samplesPerBeat)
+
+	if err := eng.SetOfflineRenderingMode(true, sequencer.DefaultQuantum); err != nil {
+		t.Fatalf("SetOfflineRenderingMode failed: %v", err)
+	}
+	defer eng.SetOfflineRenderingMode(false, 0)
+
+	buf, err := seq.RenderOffline(eng, totalFrames)
+	if err != nil {
+		t.Fatalf("RenderOffline failed: %v", err)
+	}
+	if len(buf) == 0 {
+		t.Fatal("expected rendered audio, got none")
+	}
+
+	channels := spec.ChannelCount
+	if channels <= 0 {
+		channels = 2
+	}
+
+	const onsetThreshold = 0.01
+	for beat := 0; beat < 8; beat++ {
+		expectedFrame := int(float64(beat) * samplesPerBeat)
+		windowStart := expectedFrame - sequencer.DefaultQuantum
+		if windowStart < 0 {
+			windowStart = 0
+		}
+		windowEnd := expectedFrame + sequencer.DefaultQuantum*2
+		if windowEnd*channels > len(buf) {
+			windowEnd = len(buf) / channels
+		}
+
+		found := false
+		for frame := windowStart; frame < windowEnd; frame++ {
+			idx := frame * channels
+			if idx >= len(buf) {
+				break
+			}
+			if abs32(buf[idx]) >= onsetThreshold {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("beat %d: no onset found within one quantum of expected frame %d", beat, expectedFrame)
+		}
+	}
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
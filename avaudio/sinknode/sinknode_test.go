@@ -0,0 +1,59 @@
+package sinknode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+)
+
+func TestFSKRoundTrip(t *testing.T) {
+	cfg := sourcenode.Bell103
+
+	mod, err := sourcenode.NewFSKModulator(cfg)
+	if err != nil {
+		t.Fatalf("NewFSKModulator: %v", err)
+	}
+	demod, err := NewFSKDemodulator(cfg)
+	if err != nil {
+		t.Fatalf("NewFSKDemodulator: %v", err)
+	}
+
+	want := []byte("hi!")
+	if _, err := mod.Write(want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// Render enough audio to cover every framed bit several times over,
+	// plus trailing idle mark tone so the last stop bit is captured.
+	samples, err := mod.GenerateBuffer(int(cfg.SampleRate))
+	if err != nil {
+		t.Fatalf("GenerateBuffer: %v", err)
+	}
+	if err := demod.Process(samples); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	got := make([]byte, 0, len(want))
+	buf := make([]byte, len(want))
+	for {
+		n, err := demod.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	if len(got) < len(want) {
+		t.Fatalf("decoded %d bytes, want at least %d (got %q)", len(got), len(want), got)
+	}
+	if string(got[:len(want)]) != string(want) {
+		t.Errorf("decoded %q, want %q", got[:len(want)], want)
+	}
+}
@@ -0,0 +1,267 @@
+package sourcenode
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include "native/sourcenode.m"
+#include <stdlib.h>
+
+// Function declarations - CGO resolves AudioSourceNodeResult from .m file.
+// FileSourceNode wraps an AVAudioPlayerNode streaming from AVAudioFile,
+// rather than the Objective-C tone/silence generator SourceNode wraps.
+AudioSourceNodeResult audiofilesourcenode_new(const char* path, bool loop, double startSeconds, float playbackRate);
+const char* audiofilesourcenode_set_loop(void* wrapper, bool loop);
+const char* audiofilesourcenode_seek(void* wrapper, double seconds);
+const char* audiofilesourcenode_set_playback_rate(void* wrapper, float rate);
+const char* audiofilesourcenode_set_volume(void* wrapper, float volume);
+AudioSourceNodeResult audiofilesourcenode_get_node(void* wrapper);
+AudioSourceNodeResult audiofilesourcenode_get_format(void* wrapper);
+const char* audiofilesourcenode_channel_info(void* wrapper, double* sampleRate, int* channelCount, double* durationSeconds);
+const char* audiofilesourcenode_destroy(void* wrapper);
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+	"unsafe"
+)
+
+// FileOptions configures a FileSourceNode at creation time.
+type FileOptions struct {
+	Loop         bool
+	StartSeconds float64
+	PlaybackRate float32 // 0 defaults to 1.0 (normal speed)
+}
+
+// FormatHint tells NewFromReader what container/codec the stream uses,
+// since AVAudioFile identifies format from a file extension rather than
+// sniffing bytes.
+type FormatHint string
+
+const (
+	FormatWAV  FormatHint = ".wav"
+	FormatAIFF FormatHint = ".aiff"
+	FormatMP3  FormatHint = ".mp3"
+	FormatAAC  FormatHint = ".m4a"
+)
+
+// FileSourceNode streams an audio file through an AVAudioPlayerNode, so it
+// can be attached and connected exactly like a tone SourceNode (see
+// GetNodePtr), but its signal comes from decoded file frames instead of a
+// generator. AVAudioFile streams from disk rather than decoding the whole
+// file into memory up front.
+type FileSourceNode struct {
+	ptr        unsafe.Pointer
+	tempFile   string // non-empty if NewFromReader spilled to a temp file we own
+	sampleRate float64
+	channels   int
+	duration   time.Duration
+}
+
+// NewFromFile opens path (WAV, AIFF, MP3, or AAC/M4A - whatever AVAudioFile
+// supports) and prepares it for playback with the given options.
+func NewFromFile(path string, opts FileOptions) (*FileSourceNode, error) {
+	rate := opts.PlaybackRate
+	if rate == 0 {
+		rate = 1.0
+	}
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	result := C.audiofilesourcenode_new(cPath, C.bool(opts.Loop), C.double(opts.StartSeconds), C.float(rate))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	if result.result == nil {
+		return nil, errors.New("failed to open audio file for playback")
+	}
+
+	node := &FileSourceNode{ptr: unsafe.Pointer(result.result)}
+	if err := node.loadChannelInfo(); err != nil {
+		node.Destroy()
+		return nil, err
+	}
+	return node, nil
+}
+
+// NewFromReader reads all of r into a temporary file (AVAudioFile opens by
+// path, not by stream) named with hint's extension so format detection
+// works, then behaves exactly like NewFromFile. The temp file is removed
+// when Destroy is called.
+func NewFromReader(r io.Reader, hint FormatHint, opts FileOptions) (*FileSourceNode, error) {
+	tmp, err := os.CreateTemp("", "macaudio-*"+string(hint))
+	if err != nil {
+		return nil, err
+	}
+	path := tmp.Name()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	node, err := NewFromFile(path, opts)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	node.tempFile = path
+	return node, nil
+}
+
+func (f *FileSourceNode) loadChannelInfo() error {
+	var sampleRate C.double
+	var channelCount C.int
+	var durationSeconds C.double
+	if errStr := C.audiofilesourcenode_channel_info(f.ptr, &sampleRate, &channelCount, &durationSeconds); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	f.sampleRate = float64(sampleRate)
+	f.channels = int(channelCount)
+	f.duration = time.Duration(float64(durationSeconds) * float64(time.Second))
+	return nil
+}
+
+// SampleRate returns the file's native sample rate.
+func (f *FileSourceNode) SampleRate() float64 { return f.sampleRate }
+
+// ChannelCount returns the file's channel count (1 = mono, 2 = stereo).
+func (f *FileSourceNode) ChannelCount() int { return f.channels }
+
+// Duration returns the file's total duration.
+func (f *FileSourceNode) Duration() time.Duration { return f.duration }
+
+// SetLoop enables or disables looping playback.
+func (f *FileSourceNode) SetLoop(loop bool) error {
+	if f == nil || f.ptr == nil {
+		return errors.New("file source node is nil or destroyed")
+	}
+	if errStr := C.audiofilesourcenode_set_loop(f.ptr, C.bool(loop)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// Seek moves playback to the given position.
+func (f *FileSourceNode) Seek(pos time.Duration) error {
+	if f == nil || f.ptr == nil {
+		return errors.New("file source node is nil or destroyed")
+	}
+	if errStr := C.audiofilesourcenode_seek(f.ptr, C.double(pos.Seconds())); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetPlaybackRate sets the playback speed (1.0 = normal).
+func (f *FileSourceNode) SetPlaybackRate(rate float32) error {
+	if f == nil || f.ptr == nil {
+		return errors.New("file source node is nil or destroyed")
+	}
+	if errStr := C.audiofilesourcenode_set_playback_rate(f.ptr, C.float(rate)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+func (f *FileSourceNode) setVolume(v float32) error {
+	if errStr := C.audiofilesourcenode_set_volume(f.ptr, C.float(v)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// FadeIn ramps volume from 0 to 1 over dur, mirroring SDL_mixer's FadeIn
+// semantics. There's no native ramp for this node type, so it's driven from
+// a Go-side ticker in ~20ms steps, the same coarse approach ramp.go's
+// FadeOutAndStop uses when a native ramp isn't available.
+func (f *FileSourceNode) FadeIn(dur time.Duration) error {
+	return f.fade(0, 1, dur)
+}
+
+// FadeOut ramps volume from its current level to 0 over dur.
+func (f *FileSourceNode) FadeOut(dur time.Duration) error {
+	return f.fade(1, 0, dur)
+}
+
+func (f *FileSourceNode) fade(from, to float32, dur time.Duration) error {
+	if f == nil || f.ptr == nil {
+		return errors.New("file source node is nil or destroyed")
+	}
+	if dur <= 0 {
+		return f.setVolume(to)
+	}
+
+	const step = 20 * time.Millisecond
+	steps := int(dur / step)
+	if steps < 1 {
+		steps = 1
+	}
+	if err := f.setVolume(from); err != nil {
+		return err
+	}
+	for i := 1; i <= steps; i++ {
+		time.Sleep(step)
+		v := from + (to-from)*float32(i)/float32(steps)
+		if err := f.setVolume(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNodePtr returns the underlying AVAudioNode pointer for engine
+// attach/connect operations, the same as a tone SourceNode's GetNodePtr.
+func (f *FileSourceNode) GetNodePtr() (unsafe.Pointer, error) {
+	if f == nil || f.ptr == nil {
+		return nil, errors.New("file source node is nil or destroyed")
+	}
+	result := C.audiofilesourcenode_get_node(f.ptr)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// GetFormatPtr returns the file's native AVAudioFormat pointer.
+func (f *FileSourceNode) GetFormatPtr() (unsafe.Pointer, error) {
+	if f == nil || f.ptr == nil {
+		return nil, errors.New("file source node is nil or destroyed")
+	}
+	result := C.audiofilesourcenode_get_format(f.ptr)
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	return unsafe.Pointer(result.result), nil
+}
+
+// Destroy tears down the node and, if it was created via NewFromReader,
+// removes the temp file backing it. Destroy reports an unsupported codec
+// error from the native layer the same way NewFromFile's open call would.
+func (f *FileSourceNode) Destroy() error {
+	if f == nil || f.ptr == nil {
+		return errors.New("file source node is nil or already destroyed")
+	}
+
+	errStr := C.audiofilesourcenode_destroy(f.ptr)
+	f.ptr = nil
+
+	if f.tempFile != "" {
+		_ = os.Remove(f.tempFile)
+		f.tempFile = ""
+	}
+
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
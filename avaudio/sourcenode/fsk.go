@@ -0,0 +1,214 @@
+package sourcenode
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// Parity selects the parity bit (if any) FSKConfig's framing adds after the
+// data bits of each byte.
+type Parity int
+
+const (
+	ParityNone Parity = iota
+	ParityEven
+	ParityOdd
+)
+
+// FSKConfig describes a binary frequency-shift-keyed link: which tone is
+// "mark" (logical 1) and which is "space" (logical 0), how many symbols per
+// second (BaudRate), and how bytes are framed onto the resulting bit
+// stream - the same start-bit/data-bits/parity/stop-bit framing async
+// serial modems have used since the original Bell 103.
+//
+// Both FSKModulator and sinknode.FSKDemodulator are configured from the
+// same FSKConfig, so a modulator/demodulator pair only needs to agree on
+// one value to interoperate.
+type FSKConfig struct {
+	MarkHz, SpaceHz float64
+	BaudRate        float64
+	SampleRate      float64 // 0 defaults to 44100
+	DataBits        int     // 0 defaults to 8
+	StopBits        int     // 0 defaults to 1
+	Parity          Parity
+	LSBFirst        bool // false sends MSB first; true (the serial-modem convention) sends LSB first
+}
+
+// Bell103 is the Bell 103 standard's originate channel: 300 baud, mark
+// 1270Hz, space 1070Hz.
+var Bell103 = FSKConfig{MarkHz: 1270, SpaceHz: 1070, BaudRate: 300, LSBFirst: true}
+
+// Bell202 is the Bell 202 standard: 1200 baud, mark 1200Hz, space 2200Hz.
+var Bell202 = FSKConfig{MarkHz: 1200, SpaceHz: 2200, BaudRate: 1200, LSBFirst: true}
+
+// CCITTV21 is the CCITT/ITU-T V.21 standard's channel 1 (originate): 300
+// baud, mark 980Hz, space 1180Hz.
+var CCITTV21 = FSKConfig{MarkHz: 980, SpaceHz: 1180, BaudRate: 300, LSBFirst: true}
+
+func (c FSKConfig) withDefaults() FSKConfig {
+	if c.SampleRate == 0 {
+		c.SampleRate = 44100
+	}
+	if c.DataBits == 0 {
+		c.DataBits = 8
+	}
+	if c.StopBits == 0 {
+		c.StopBits = 1
+	}
+	return c
+}
+
+func (c FSKConfig) samplesPerSymbol() float64 {
+	return c.SampleRate / c.BaudRate
+}
+
+// parityBit returns the parity bit for b under c.Parity (0 if c.Parity is
+// ParityNone).
+func parityBit(b byte, dataBits int, p Parity) int {
+	if p == ParityNone {
+		return 0
+	}
+	ones := 0
+	for i := 0; i < dataBits; i++ {
+		if b&(1<<uint(i)) != 0 {
+			ones++
+		}
+	}
+	if p == ParityEven {
+		return ones % 2
+	}
+	return (ones + 1) % 2 // ParityOdd
+}
+
+// frameBits returns b's bit sequence (start, data, optional parity, stop),
+// in the order it should be transmitted.
+func frameBits(b byte, cfg FSKConfig) []int {
+	bits := make([]int, 0, 1+cfg.DataBits+1+cfg.StopBits)
+	bits = append(bits, 0) // start bit is always space
+	if cfg.LSBFirst {
+		for i := 0; i < cfg.DataBits; i++ {
+			bits = append(bits, int(b>>uint(i))&1)
+		}
+	} else {
+		for i := cfg.DataBits - 1; i >= 0; i-- {
+			bits = append(bits, int(b>>uint(i))&1)
+		}
+	}
+	if cfg.Parity != ParityNone {
+		bits = append(bits, parityBit(b, cfg.DataBits, cfg.Parity))
+	}
+	for i := 0; i < cfg.StopBits; i++ {
+		bits = append(bits, 1) // stop bits are always mark
+	}
+	return bits
+}
+
+// FSKModulator is a software FSK signal generator: Write accepts a byte
+// stream and frames it onto a bit queue; GenerateBuffer renders that queue
+// as continuous-phase audio, advancing a running phase accumulator by
+// 2*pi*f/sampleRate per sample so the waveform never jumps (and therefore
+// doesn't click) at a symbol boundary, only the bit it's encoding does.
+//
+// Unlike SourceNode, FSKModulator doesn't wrap a native AVAudioSourceNode -
+// there's no cgo binding in this tree for a source node whose frequency
+// must change sample-accurately within a single render callback, which
+// continuous-phase FSK needs. Render its buffers (e.g. via GenerateBuffer)
+// into a FileSourceNode-backed file, or a tap-fed channel, to get it onto
+// the engine graph.
+type FSKModulator struct {
+	cfg FSKConfig
+
+	mu          sync.Mutex
+	bits        []int
+	phase       float64
+	symbolPos   float64 // samples remaining in the current symbol
+	currentFreq float64
+	haveCurrent bool
+	amplitude   float64
+}
+
+// NewFSKModulator creates an FSKModulator for cfg, defaulting SampleRate to
+// 44100, DataBits to 8, and StopBits to 1 if left zero.
+func NewFSKModulator(cfg FSKConfig) (*FSKModulator, error) {
+	cfg = cfg.withDefaults()
+	if cfg.BaudRate <= 0 {
+		return nil, errors.New("fsk: baud rate must be positive")
+	}
+	if cfg.MarkHz <= 0 || cfg.SpaceHz <= 0 {
+		return nil, errors.New("fsk: mark and space frequencies must be positive")
+	}
+	return &FSKModulator{cfg: cfg, amplitude: 0.5}, nil
+}
+
+// SetAmplitude sets the generated tone's linear amplitude (0-1, default 0.5).
+func (m *FSKModulator) SetAmplitude(amp float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.amplitude = amp
+}
+
+// Write queues p for transmission, framing each byte per m's FSKConfig, and
+// always reports len(p), nil - framing never fails once NewFSKModulator has
+// validated cfg.
+func (m *FSKModulator) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range p {
+		m.bits = append(m.bits, frameBits(b, m.cfg)...)
+	}
+	return len(p), nil
+}
+
+// Pending returns the number of bits still queued for transmission.
+func (m *FSKModulator) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.bits)
+}
+
+// GenerateBuffer renders frameCount samples of continuous-phase FSK audio.
+// When the bit queue is empty it idles on the mark frequency, matching an
+// async modem's idle-high line state.
+func (m *FSKModulator) GenerateBuffer(frameCount int) ([]float32, error) {
+	if frameCount <= 0 {
+		return nil, errors.New("fsk: frame count must be positive")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	samplesPerSymbol := m.cfg.samplesPerSymbol()
+	buffer := make([]float32, frameCount)
+
+	for i := 0; i < frameCount; i++ {
+		if !m.haveCurrent || m.symbolPos <= 0 {
+			m.currentFreq = m.nextFreqLocked()
+			m.symbolPos += samplesPerSymbol
+			m.haveCurrent = true
+		}
+
+		buffer[i] = float32(m.amplitude * math.Sin(m.phase))
+		m.phase += 2 * math.Pi * m.currentFreq / m.cfg.SampleRate
+		if m.phase > 2*math.Pi {
+			m.phase -= 2 * math.Pi
+		}
+		m.symbolPos--
+	}
+
+	return buffer, nil
+}
+
+// nextFreqLocked pops the next queued bit (or idles on mark if the queue is
+// empty) and returns its tone frequency. m.mu must be held.
+func (m *FSKModulator) nextFreqLocked() float64 {
+	if len(m.bits) == 0 {
+		return m.cfg.MarkHz
+	}
+	bit := m.bits[0]
+	m.bits = m.bits[1:]
+	if bit == 1 {
+		return m.cfg.MarkHz
+	}
+	return m.cfg.SpaceHz
+}
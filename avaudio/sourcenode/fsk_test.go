@@ -0,0 +1,96 @@
+package sourcenode
+
+import "testing"
+
+func TestFSKModulatorFramesAndGenerates(t *testing.T) {
+	mod, err := NewFSKModulator(Bell103)
+	if err != nil {
+		t.Fatalf("NewFSKModulator: %v", err)
+	}
+
+	n, err := mod.Write([]byte("hi"))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Write returned %d, want 2", n)
+	}
+
+	// start + 8 data + 1 stop bits per byte, two bytes queued.
+	if got, want := mod.Pending(), 2*10; got != want {
+		t.Errorf("Pending() = %d, want %d", got, want)
+	}
+
+	buf, err := mod.GenerateBuffer(512)
+	if err != nil {
+		t.Fatalf("GenerateBuffer: %v", err)
+	}
+	if len(buf) != 512 {
+		t.Fatalf("GenerateBuffer returned %d samples, want 512", len(buf))
+	}
+
+	silent := true
+	for _, s := range buf {
+		if s != 0 {
+			silent = false
+			break
+		}
+	}
+	if silent {
+		t.Error("GenerateBuffer produced silence, expected an FSK tone")
+	}
+}
+
+func TestFSKModulatorIdlesOnMark(t *testing.T) {
+	mod, err := NewFSKModulator(Bell103)
+	if err != nil {
+		t.Fatalf("NewFSKModulator: %v", err)
+	}
+
+	if _, err := mod.GenerateBuffer(64); err != nil {
+		t.Fatalf("GenerateBuffer: %v", err)
+	}
+	if mod.currentFreq != mod.cfg.MarkHz {
+		t.Errorf("idle frequency = %v, want mark frequency %v", mod.currentFreq, mod.cfg.MarkHz)
+	}
+}
+
+func TestFrameBitsRoundTrip(t *testing.T) {
+	cfg := Bell103.withDefaults()
+	bits := frameBits('A', cfg)
+
+	// start bit
+	if bits[0] != 0 {
+		t.Fatalf("expected start bit 0, got %d", bits[0])
+	}
+	// stop bit
+	if bits[len(bits)-1] != 1 {
+		t.Fatalf("expected stop bit 1, got %d", bits[len(bits)-1])
+	}
+
+	got := bitsToByteForTest(bits[1:1+cfg.DataBits], cfg.LSBFirst)
+	if got != 'A' {
+		t.Errorf("decoded byte = %q, want 'A'", got)
+	}
+}
+
+// bitsToByteForTest mirrors sinknode.bitsToByte without introducing an
+// import cycle between the two packages' test suites.
+func bitsToByteForTest(bits []int, lsbFirst bool) byte {
+	var b byte
+	if lsbFirst {
+		for i, bit := range bits {
+			if bit == 1 {
+				b |= 1 << uint(i)
+			}
+		}
+	} else {
+		n := len(bits)
+		for i, bit := range bits {
+			if bit == 1 {
+				b |= 1 << uint(n-1-i)
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,334 @@
+package sourcenode
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// WaveformType selects the shape Oscillator.GenerateBuffer renders.
+type WaveformType int
+
+const (
+	WaveformSine WaveformType = iota
+	WaveformSquare
+	WaveformSaw
+	WaveformTriangle
+	WaveformPulse
+	WaveformWhiteNoise
+	WaveformPinkNoise
+	waveformTable // internal: set by NewWavetable, not selectable via SetWaveform
+)
+
+// InterpolationMode selects how NewWavetable resamples its single-cycle
+// table to whatever phase increment the requested frequency needs.
+type InterpolationMode int
+
+const (
+	InterpolationLinear InterpolationMode = iota
+	InterpolationCubic
+)
+
+// Oscillator is a band-limited, multi-waveform tone generator: phase
+// accumulation is kept in float64 so low frequencies (a few Hz, the low end
+// of an LFO) don't drift audibly over a long render, and Saw/Square/Pulse
+// subtract a PolyBLEP correction at each discontinuity to suppress the
+// aliasing a naive wrap would otherwise fold back below Nyquist.
+//
+// Unlike SourceNode, Oscillator doesn't wrap a native AVAudioSourceNode -
+// same as FSKModulator, there's no cgo binding in this tree for a source
+// node with a runtime-switchable waveform and wavetable lookup. Render its
+// buffers (e.g. via GenerateBuffer) into a FileSourceNode-backed file, or a
+// tap-fed channel, to get it onto the engine graph.
+type Oscillator struct {
+	mu sync.Mutex
+
+	waveform    WaveformType
+	sampleRate  float64
+	frequency   float64
+	amplitude   float64
+	phase       float64 // current cycle position, 0-1
+	dutyCycle   float64 // Pulse only; Square always renders at 0.5
+	detuneCents float64
+
+	table  []float32
+	interp InterpolationMode
+
+	pink pinkNoiseState
+}
+
+// NewOscillator creates an Oscillator rendering waveform at sampleRate,
+// defaulting to 440Hz, amplitude 0.5, and a 50% duty cycle.
+func NewOscillator(waveform WaveformType, sampleRate float64) (*Oscillator, error) {
+	if sampleRate <= 0 {
+		return nil, errors.New("oscillator: sample rate must be positive")
+	}
+	if waveform < WaveformSine || waveform > WaveformPinkNoise {
+		return nil, errors.New("oscillator: unknown waveform")
+	}
+	return &Oscillator{
+		waveform:   waveform,
+		sampleRate: sampleRate,
+		frequency:  440.0,
+		amplitude:  0.5,
+		dutyCycle:  0.5,
+	}, nil
+}
+
+// NewWavetable creates an Oscillator that plays back table as a single
+// looping cycle, resampled per-sample to whatever phase increment the
+// requested frequency needs (see SetInterpolation). table must have at
+// least two samples.
+func NewWavetable(table []float32, sampleRate float64) (*Oscillator, error) {
+	if sampleRate <= 0 {
+		return nil, errors.New("oscillator: sample rate must be positive")
+	}
+	if len(table) < 2 {
+		return nil, errors.New("oscillator: wavetable must have at least 2 samples")
+	}
+	cp := make([]float32, len(table))
+	copy(cp, table)
+	return &Oscillator{
+		waveform:   waveformTable,
+		sampleRate: sampleRate,
+		frequency:  440.0,
+		amplitude:  0.5,
+		dutyCycle:  0.5,
+		table:      cp,
+	}, nil
+}
+
+// SetFrequency sets the oscillator's base frequency in Hz, before
+// SetDetuneCents is applied.
+func (o *Oscillator) SetFrequency(freq float64) error {
+	if freq <= 0 {
+		return errors.New("oscillator: frequency must be positive")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.frequency = freq
+	return nil
+}
+
+// SetAmplitude sets the oscillator's linear output amplitude (0-1).
+func (o *Oscillator) SetAmplitude(amp float64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.amplitude = amp
+	return nil
+}
+
+// SetDutyCycle sets the fraction (0-1, exclusive) of each cycle WaveformPulse
+// spends high. It has no effect on any other waveform - Square always
+// renders at a fixed 50%.
+func (o *Oscillator) SetDutyCycle(duty float64) error {
+	if duty <= 0 || duty >= 1 {
+		return errors.New("oscillator: duty cycle must be between 0 and 1 exclusive")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.dutyCycle = duty
+	return nil
+}
+
+// SetDetuneCents offsets the oscillator's effective frequency by cents
+// (100 cents = 1 semitone), for unison/chorus voices tuned slightly apart
+// from SetFrequency's base pitch.
+func (o *Oscillator) SetDetuneCents(cents float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.detuneCents = cents
+}
+
+// SetPhase resets the oscillator's current cycle position (0-1). It does
+// not affect WaveformWhiteNoise/WaveformPinkNoise, which have no phase.
+func (o *Oscillator) SetPhase(phase float64) error {
+	if phase < 0 || phase >= 1 {
+		return errors.New("oscillator: phase must be in [0, 1)")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.phase = phase
+	return nil
+}
+
+// SetWaveform switches the waveform rendered by subsequent GenerateBuffer
+// calls, preserving the current phase so the switch doesn't click. It's an
+// error on an Oscillator created with NewWavetable - that Oscillator always
+// plays back its table.
+func (o *Oscillator) SetWaveform(waveform WaveformType) error {
+	if waveform < WaveformSine || waveform > WaveformPinkNoise {
+		return errors.New("oscillator: unknown waveform")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.waveform == waveformTable {
+		return errors.New("oscillator: cannot change waveform on a wavetable oscillator")
+	}
+	o.waveform = waveform
+	return nil
+}
+
+// SetInterpolation selects how a wavetable Oscillator resamples its table
+// between two stored samples. It's a no-op on an Oscillator created with
+// NewOscillator.
+func (o *Oscillator) SetInterpolation(mode InterpolationMode) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.interp = mode
+}
+
+// GenerateBuffer renders frameCount samples at the oscillator's current
+// frequency, waveform, and amplitude.
+func (o *Oscillator) GenerateBuffer(frameCount int) ([]float32, error) {
+	if frameCount <= 0 {
+		return nil, errors.New("oscillator: frame count must be positive")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	buffer := make([]float32, frameCount)
+
+	if o.waveform == WaveformWhiteNoise {
+		for i := range buffer {
+			buffer[i] = float32(o.amplitude * (2*rand.Float64() - 1))
+		}
+		return buffer, nil
+	}
+	if o.waveform == WaveformPinkNoise {
+		for i := range buffer {
+			buffer[i] = float32(o.amplitude * o.pink.next())
+		}
+		return buffer, nil
+	}
+
+	freq := o.frequency * math.Pow(2, o.detuneCents/1200)
+	dt := freq / o.sampleRate
+
+	for i := range buffer {
+		var sample float64
+		if o.waveform == waveformTable {
+			sample = o.sampleTableLocked(o.phase)
+		} else {
+			sample = o.sampleWaveformLocked(o.phase, dt)
+		}
+		buffer[i] = float32(o.amplitude * sample)
+
+		o.phase += dt
+		if o.phase >= 1 {
+			o.phase -= math.Floor(o.phase)
+		}
+	}
+
+	return buffer, nil
+}
+
+// sampleWaveformLocked evaluates o.waveform at cycle position t (0-1) with
+// per-sample phase increment dt, band-limiting Saw/Square/Pulse's
+// discontinuities with polyBLEP. o.mu must be held.
+func (o *Oscillator) sampleWaveformLocked(t, dt float64) float64 {
+	switch o.waveform {
+	case WaveformSine:
+		return math.Sin(2 * math.Pi * t)
+	case WaveformSaw:
+		saw := 2*t - 1
+		return saw - polyBLEP(t, dt)
+	case WaveformSquare:
+		return pulseWave(t, dt, 0.5)
+	case WaveformPulse:
+		return pulseWave(t, dt, o.dutyCycle)
+	case WaveformTriangle:
+		return 1 - 4*math.Abs(t-0.5)
+	default:
+		return 0
+	}
+}
+
+// sampleTableLocked resamples o.table at cycle position t (0-1) using
+// o.interp. o.mu must be held.
+func (o *Oscillator) sampleTableLocked(t float64) float64 {
+	n := len(o.table)
+	pos := t * float64(n)
+	i0 := int(math.Floor(pos)) % n
+	frac := pos - math.Floor(pos)
+
+	if o.interp == InterpolationCubic {
+		im1 := ((i0-1)%n + n) % n
+		i1 := (i0 + 1) % n
+		i2 := (i0 + 2) % n
+		return cubicInterpolate(
+			float64(o.table[im1]), float64(o.table[i0]),
+			float64(o.table[i1]), float64(o.table[i2]), frac)
+	}
+
+	i1 := (i0 + 1) % n
+	y0, y1 := float64(o.table[i0]), float64(o.table[i1])
+	return y0 + (y1-y0)*frac
+}
+
+// cubicInterpolate returns the Catmull-Rom cubic interpolation between y0
+// and y1 at fraction t (0-1), using ym1 and y2 as the neighboring samples
+// on either side.
+func cubicInterpolate(ym1, y0, y1, y2, t float64) float64 {
+	a0 := y2 - y1 - ym1 + y0
+	a1 := ym1 - y0 - a0
+	a2 := y1 - ym1
+	a3 := y0
+	return ((a0*t+a1)*t+a2)*t + a3
+}
+
+// polyBLEP returns a correction term that, subtracted from a naive
+// bandlimited-free waveform at a discontinuity crossing phase t (0-1) with
+// per-sample increment dt, replaces the discontinuity's infinite-bandwidth
+// step with a band-limited approximation - see Valimaki & Huovilainen,
+// "Antialiasing Oscillators in Subtractive Synthesis" (2007).
+func polyBLEP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		t /= dt
+		return t + t - t*t - 1
+	case t > 1-dt:
+		t = (t - 1) / dt
+		return t*t + t + t + 1
+	default:
+		return 0
+	}
+}
+
+// pulseWave returns a band-limited pulse at cycle position t (0-1) with
+// per-sample increment dt and the given duty cycle: +1 for the first
+// duty fraction of the cycle, -1 for the rest, with a polyBLEP correction
+// at both the rising edge (t=0) and the falling edge (t=duty).
+func pulseWave(t, dt, duty float64) float64 {
+	var v float64
+	if t < duty {
+		v = 1
+	} else {
+		v = -1
+	}
+	v += polyBLEP(t, dt)
+	fall := t - duty
+	if fall < 0 {
+		fall += 1
+	}
+	v -= polyBLEP(fall, dt)
+	return v
+}
+
+// pinkNoiseState holds the running filter state for pinkNoiseState.next,
+// Paul Kellett's "economy" refinement of the Voss-McCartney pink noise
+// algorithm (three one-pole filters summed with the raw white source).
+type pinkNoiseState struct {
+	b0, b1, b2 float64
+}
+
+func (p *pinkNoiseState) next() float64 {
+	white := 2*rand.Float64() - 1
+	p.b0 = 0.99765*p.b0 + white*0.0990460
+	p.b1 = 0.96300*p.b1 + white*0.2965164
+	p.b2 = 0.57000*p.b2 + white*1.0526913
+	pink := p.b0 + p.b1 + p.b2 + white*0.1848
+	return pink * 0.11 // roughly normalize back toward [-1, 1]
+}
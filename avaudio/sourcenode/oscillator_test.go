@@ -0,0 +1,138 @@
+package sourcenode
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewOscillatorRejectsBadInput(t *testing.T) {
+	if _, err := NewOscillator(WaveformSine, 0); err == nil {
+		t.Error("expected error for non-positive sample rate")
+	}
+	if _, err := NewOscillator(WaveformType(99), 44100); err == nil {
+		t.Error("expected error for unknown waveform")
+	}
+}
+
+func TestOscillatorGeneratesNonSilentTone(t *testing.T) {
+	for _, w := range []WaveformType{WaveformSine, WaveformSquare, WaveformSaw, WaveformTriangle, WaveformPulse} {
+		osc, err := NewOscillator(w, 44100)
+		if err != nil {
+			t.Fatalf("NewOscillator(%v): %v", w, err)
+		}
+		buf, err := osc.GenerateBuffer(512)
+		if err != nil {
+			t.Fatalf("GenerateBuffer(%v): %v", w, err)
+		}
+		if len(buf) != 512 {
+			t.Fatalf("GenerateBuffer(%v) returned %d samples, want 512", w, len(buf))
+		}
+
+		silent := true
+		for _, s := range buf {
+			if s != 0 {
+				silent = false
+				break
+			}
+		}
+		if silent {
+			t.Errorf("waveform %v produced silence", w)
+		}
+	}
+}
+
+func TestOscillatorStaysWithinAmplitude(t *testing.T) {
+	osc, err := NewOscillator(WaveformSaw, 44100)
+	if err != nil {
+		t.Fatalf("NewOscillator: %v", err)
+	}
+	if err := osc.SetAmplitude(0.5); err != nil {
+		t.Fatalf("SetAmplitude: %v", err)
+	}
+	if err := osc.SetFrequency(220); err != nil {
+		t.Fatalf("SetFrequency: %v", err)
+	}
+
+	buf, err := osc.GenerateBuffer(4096)
+	if err != nil {
+		t.Fatalf("GenerateBuffer: %v", err)
+	}
+	for i, s := range buf {
+		// PolyBLEP's correction can briefly push a sample a little past
+		// the naive waveform's range right at a discontinuity; allow
+		// some headroom rather than demanding an exact bound.
+		if math.Abs(float64(s)) > 0.6 {
+			t.Fatalf("sample %d = %v, exceeds expected amplitude envelope", i, s)
+		}
+	}
+}
+
+func TestOscillatorSetWaveformPreservesPhase(t *testing.T) {
+	osc, err := NewOscillator(WaveformSine, 44100)
+	if err != nil {
+		t.Fatalf("NewOscillator: %v", err)
+	}
+	if err := osc.SetPhase(0.25); err != nil {
+		t.Fatalf("SetPhase: %v", err)
+	}
+	if err := osc.SetWaveform(WaveformSaw); err != nil {
+		t.Fatalf("SetWaveform: %v", err)
+	}
+	if osc.phase != 0.25 {
+		t.Errorf("phase = %v after SetWaveform, want 0.25", osc.phase)
+	}
+}
+
+func TestWavetableOscillatorCannotChangeWaveform(t *testing.T) {
+	osc, err := NewWavetable([]float32{0, 1, 0, -1}, 44100)
+	if err != nil {
+		t.Fatalf("NewWavetable: %v", err)
+	}
+	if err := osc.SetWaveform(WaveformSine); err == nil {
+		t.Error("expected error switching waveform on a wavetable oscillator")
+	}
+}
+
+func TestWavetableOscillatorLoopsTable(t *testing.T) {
+	table := []float32{0, 1, 0, -1}
+	osc, err := NewWavetable(table, float64(len(table)))
+	if err != nil {
+		t.Fatalf("NewWavetable: %v", err)
+	}
+	if err := osc.SetFrequency(1); err != nil {
+		t.Fatalf("SetFrequency: %v", err)
+	}
+	if err := osc.SetAmplitude(1); err != nil {
+		t.Fatalf("SetAmplitude: %v", err)
+	}
+
+	buf, err := osc.GenerateBuffer(len(table))
+	if err != nil {
+		t.Fatalf("GenerateBuffer: %v", err)
+	}
+	for i, want := range table {
+		if math.Abs(float64(buf[i]-want)) > 1e-6 {
+			t.Errorf("sample %d = %v, want %v", i, buf[i], want)
+		}
+	}
+}
+
+func TestPinkNoiseStaysBounded(t *testing.T) {
+	osc, err := NewOscillator(WaveformPinkNoise, 44100)
+	if err != nil {
+		t.Fatalf("NewOscillator: %v", err)
+	}
+	if err := osc.SetAmplitude(1); err != nil {
+		t.Fatalf("SetAmplitude: %v", err)
+	}
+
+	buf, err := osc.GenerateBuffer(4096)
+	if err != nil {
+		t.Fatalf("GenerateBuffer: %v", err)
+	}
+	for i, s := range buf {
+		if math.Abs(float64(s)) > 2 {
+			t.Fatalf("sample %d = %v, pink noise escaped its expected envelope", i, s)
+		}
+	}
+}
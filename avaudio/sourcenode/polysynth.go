@@ -0,0 +1,303 @@
+package sourcenode
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/midi"
+)
+
+// ADSR configures a PolySynth voice's amplitude envelope. Times are in
+// milliseconds; SustainLevel is the amplitude (0-1) held between decay and
+// release.
+type ADSR struct {
+	AttackMs     float64
+	DecayMs      float64
+	SustainLevel float64
+	ReleaseMs    float64
+}
+
+// DefaultADSR returns a fast, percussive-ish envelope suitable as a starting
+// point: 5ms attack, 80ms decay to 70% sustain, 200ms release.
+func DefaultADSR() ADSR {
+	return ADSR{AttackMs: 5, DecayMs: 80, SustainLevel: 0.7, ReleaseMs: 200}
+}
+
+// voice is one tone SourceNode managed by a PolySynth.
+type voice struct {
+	node       *SourceNode
+	note       int
+	channel    int
+	startedAt  time.Time
+	generation int // bumped by stealing/release, so a stale envelope goroutine knows to stop
+
+	mu        sync.Mutex
+	releasing bool
+}
+
+// PolySynth manages a fixed bank of SourceNode voices driven by MIDI-style
+// Events, mapping note numbers to frequency via equal-tempered tuning and
+// applying a per-voice ADSR envelope on note-on/off. Each voice is attached
+// to eng and connected to the main mixer, following the same
+// attach-connect-mainMixer-output pipeline as a single NewTone source.
+type PolySynth struct {
+	eng     *engine.Engine
+	mu      sync.Mutex
+	voices  []*voice
+	order   []*voice // allocation order, oldest first, for note stealing
+	a4      float64
+	adsr    ADSR
+	maxPoly int
+}
+
+// NewPolySynth creates a PolySynth with maxPolyphony simultaneous voices,
+// attaching each voice's SourceNode to eng's main mixer up front so
+// HandleEvent never needs to attach/connect on the audio thread's behalf at
+// note-on time.
+func NewPolySynth(eng *engine.Engine, maxPolyphony int) (*PolySynth, error) {
+	if eng == nil {
+		return nil, errors.New("engine is nil")
+	}
+	if maxPolyphony <= 0 {
+		return nil, errors.New("maxPolyphony must be positive")
+	}
+
+	mainMixer, err := eng.MainMixerNode()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PolySynth{
+		eng:     eng,
+		a4:      440.0,
+		adsr:    DefaultADSR(),
+		maxPoly: maxPolyphony,
+	}
+
+	for i := 0; i < maxPolyphony; i++ {
+		node, err := NewTone()
+		if err != nil {
+			p.destroyVoices()
+			return nil, err
+		}
+		if err := node.SetAmplitude(0); err != nil {
+			p.destroyVoices()
+			return nil, err
+		}
+
+		nodePtr, err := node.GetNodePtr()
+		if err != nil {
+			p.destroyVoices()
+			return nil, err
+		}
+		if err := eng.Attach(nodePtr); err != nil {
+			p.destroyVoices()
+			return nil, err
+		}
+		if err := eng.Connect(nodePtr, mainMixer, 0, 0); err != nil {
+			p.destroyVoices()
+			return nil, err
+		}
+
+		p.voices = append(p.voices, &voice{node: node})
+	}
+
+	return p, nil
+}
+
+func (p *PolySynth) destroyVoices() {
+	for _, v := range p.voices {
+		v.node.Destroy()
+	}
+	p.voices = nil
+}
+
+// SetA4Reference sets the tuning reference frequency (in Hz) for MIDI note
+// 69; defaults to 440.
+func (p *PolySynth) SetA4Reference(hz float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.a4 = hz
+}
+
+// SetADSR sets the envelope applied to every voice triggered after this call
+// (voices already sounding keep their original envelope).
+func (p *PolySynth) SetADSR(a ADSR) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.adsr = a
+}
+
+func noteToFrequency(note int, a4 float64) float64 {
+	return a4 * math.Pow(2, float64(note-69)/12.0)
+}
+
+// HandleEvent applies a MIDI event to the voice bank: NoteOn allocates (or
+// steals) a voice and starts its attack/decay; NoteOff starts that note's
+// release. CC and PitchBend events are currently no-ops (no voice parameter
+// is bound to them yet) but are accepted so a Controller's full Event stream
+// can be piped straight into HandleEvent without per-type filtering.
+func (p *PolySynth) HandleEvent(e midi.Event) error {
+	switch e.Type {
+	case midi.EventNoteOn:
+		return p.noteOn(e.Channel, e.Note, e.Velocity)
+	case midi.EventNoteOff:
+		return p.noteOff(e.Channel, e.Note)
+	default:
+		return nil
+	}
+}
+
+func (p *PolySynth) noteOn(channel, note, velocity int) error {
+	p.mu.Lock()
+	a4 := p.a4
+	adsr := p.adsr
+
+	v := p.allocateVoiceLocked(channel, note)
+	p.mu.Unlock()
+
+	v.mu.Lock()
+	v.generation++
+	gen := v.generation
+	v.releasing = false
+	v.mu.Unlock()
+
+	freq := noteToFrequency(note, a4)
+	if err := v.node.SetFrequency(freq); err != nil {
+		return err
+	}
+
+	peak := float64(velocity) / 127.0
+	go p.runAttackDecay(v, gen, adsr, peak)
+	return nil
+}
+
+func (p *PolySynth) noteOff(channel, note int) error {
+	p.mu.Lock()
+	var v *voice
+	for _, candidate := range p.order {
+		if candidate.channel == channel && candidate.note == note {
+			v = candidate
+			break
+		}
+	}
+	adsr := p.adsr
+	p.mu.Unlock()
+
+	if v == nil {
+		return nil // note not currently sounding; nothing to release
+	}
+
+	v.mu.Lock()
+	v.releasing = true
+	v.generation++
+	gen := v.generation
+	v.mu.Unlock()
+
+	go p.runRelease(v, gen, adsr)
+	return nil
+}
+
+// allocateVoiceLocked picks a free voice, or steals the oldest sounding one
+// if the bank is fully allocated. Caller holds p.mu.
+func (p *PolySynth) allocateVoiceLocked(channel, note int) *voice {
+	for _, v := range p.voices {
+		if !containsVoice(p.order, v) {
+			v.channel, v.note, v.startedAt = channel, note, time.Now()
+			p.order = append(p.order, v)
+			return v
+		}
+	}
+
+	// Oldest-note stealing: evict order[0] and reuse it.
+	stolen := p.order[0]
+	p.order = append(p.order[1:], stolen)
+	stolen.channel, stolen.note, stolen.startedAt = channel, note, time.Now()
+	return stolen
+}
+
+func containsVoice(voices []*voice, target *voice) bool {
+	for _, v := range voices {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *PolySynth) runAttackDecay(v *voice, gen int, adsr ADSR, peak float64) {
+	const step = 5 * time.Millisecond
+	attackSteps := stepsFor(adsr.AttackMs, step)
+	for i := 1; i <= attackSteps; i++ {
+		if !stillCurrent(v, gen) {
+			return
+		}
+		_ = v.node.SetAmplitude(peak * float64(i) / float64(attackSteps))
+		time.Sleep(step)
+	}
+
+	decaySteps := stepsFor(adsr.DecayMs, step)
+	sustain := peak * adsr.SustainLevel
+	for i := 1; i <= decaySteps; i++ {
+		if !stillCurrent(v, gen) {
+			return
+		}
+		amp := peak - (peak-sustain)*float64(i)/float64(decaySteps)
+		_ = v.node.SetAmplitude(amp)
+		time.Sleep(step)
+	}
+}
+
+func (p *PolySynth) runRelease(v *voice, gen int, adsr ADSR) {
+	const step = 5 * time.Millisecond
+	releaseSteps := stepsFor(adsr.ReleaseMs, step)
+
+	v.mu.Lock()
+	start := adsr.SustainLevel
+	v.mu.Unlock()
+
+	for i := 1; i <= releaseSteps; i++ {
+		if !stillCurrent(v, gen) {
+			return
+		}
+		amp := start * (1 - float64(i)/float64(releaseSteps))
+		_ = v.node.SetAmplitude(amp)
+		time.Sleep(step)
+	}
+	if stillCurrent(v, gen) {
+		_ = v.node.SetAmplitude(0)
+	}
+}
+
+func stillCurrent(v *voice, gen int) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.generation == gen
+}
+
+func stepsFor(ms float64, step time.Duration) int {
+	steps := int(ms / float64(step.Milliseconds()))
+	if steps < 1 {
+		steps = 1
+	}
+	return steps
+}
+
+// Close silences and destroys every voice in the bank.
+func (p *PolySynth) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for _, v := range p.voices {
+		if err := v.node.Destroy(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	p.voices = nil
+	p.order = nil
+	return firstErr
+}
@@ -0,0 +1,280 @@
+package sourcenode
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include "native/sourcenode.m"
+#include <stdlib.h>
+
+// Declared here; implemented in native/sourcenode.m once the render
+// block's AudioBufferList-to-per-channel-float32 marshaling, and the
+// //export trampoline it calls back into Go through, exist - see
+// SetRenderCallback's doc comment. This is the same gap
+// avaudio/engine/stream.go's stream_build_output documents, for the same
+// reason: the native side of this tree doesn't exist here yet.
+const char* audiosourcenode_set_render_callback(void* wrapper, const char* callbackKey);
+const char* audiosourcenode_clear_render_callback(void* wrapper);
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// RenderCallback pulls one block directly for the audio render thread:
+// frameCount is how many frames are wanted, channels is how many channels
+// out holds, and out[c] is channel c's buffer for the callback to fill in
+// place - backed directly by the native AudioBufferList's own memory, no
+// copy in or out. Returning a non-nil error is translated to noErr with
+// out left silent rather than propagated as an OSStatus failure, since
+// there's no way to recover a render callback gracefully mid-block.
+//
+// RenderCallback runs on the realtime audio thread: it must not allocate,
+// take a lock, perform I/O, or send/receive on a Go channel - any of those
+// can block on the scheduler or GC and cause an audible dropout. A
+// callback that can't meet that bar should be installed with
+// SetRenderCallbackWithRingBuffer instead, which runs it on a normal
+// goroutine.
+type RenderCallback func(frameCount int, channels int, out [][]float32) error
+
+// renderCallbackRegistry maps a callback key to the SourceNode it belongs
+// to, the same uintptr/string-handle workaround format/encoder's
+// opusEncoderRegistry and plugins/scan_hooks.go use - cgo forbids passing a
+// Go pointer (or func value) through a C void*, so the eventual
+// //export trampoline looks the SourceNode up by this key instead.
+var (
+	renderCallbackRegistry = make(map[string]*SourceNode)
+	renderCallbackMu       sync.Mutex
+)
+
+// SetRenderCallback installs cb to be invoked directly from the native
+// AVAudioSourceNode render block for every buffer s renders, replacing
+// whatever Objective-C tone/silence generation New/NewTone configured it
+// for. See RenderCallback's doc comment for the realtime constraints cb
+// must meet.
+//
+// The render block's buffer marshaling and the //export trampoline that
+// would call cb through are not wired up in this tree yet (see
+// audiosourcenode_set_render_callback's declaration above) - like
+// Engine.BuildOutputStream's stream_build_output, this installs cleanly
+// and GenerateBuffer keeps working, but cb is never actually invoked from
+// the render thread until that trampoline exists.
+func (s *SourceNode) SetRenderCallback(cb RenderCallback) error {
+	if s == nil || s.ptr == nil {
+		return errors.New("source node is nil or destroyed")
+	}
+	if cb == nil {
+		return errors.New("callback cannot be nil")
+	}
+
+	key := fmt.Sprintf("rendercb_%p_%d", s.ptr, time.Now().UnixNano())
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	errorStr := C.audiosourcenode_set_render_callback(s.ptr, cKey)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+
+	renderCallbackMu.Lock()
+	delete(renderCallbackRegistry, s.renderCallbackKey)
+	renderCallbackRegistry[key] = s
+	renderCallbackMu.Unlock()
+
+	s.renderCallback = cb
+	s.renderCallbackKey = key
+	return nil
+}
+
+// ClearRenderCallback removes a callback installed by SetRenderCallback (or
+// SetRenderCallbackWithRingBuffer), reverting s to its original
+// Objective-C tone/silence generation. It's a no-op if no callback is
+// installed.
+func (s *SourceNode) ClearRenderCallback() error {
+	if s == nil || s.ptr == nil {
+		return errors.New("source node is nil or destroyed")
+	}
+	if s.renderCallbackKey == "" {
+		return nil
+	}
+
+	errorStr := C.audiosourcenode_clear_render_callback(s.ptr)
+
+	renderCallbackMu.Lock()
+	delete(renderCallbackRegistry, s.renderCallbackKey)
+	renderCallbackMu.Unlock()
+
+	if s.renderRingStop != nil {
+		close(s.renderRingStop)
+		s.renderRingStop = nil
+		s.renderRing = nil
+	}
+
+	s.renderCallback = nil
+	s.renderCallbackKey = ""
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// renderBlock is one pre-rendered block moved from a fillLoop goroutine to
+// the (eventual) render thread through a renderRing, carrying one []float32
+// per channel so the fill side never has to interleave/deinterleave.
+type renderBlock struct {
+	channels [][]float32
+}
+
+// renderRing is a lock-free single-producer/single-consumer ring buffer of
+// renderBlocks: the fill goroutine SetRenderCallbackWithRingBuffer starts
+// is the sole producer, and the (not yet wired) native render thread would
+// be the sole consumer - the same shape as avaudio/engine's streamRing, so
+// atomics on head/tail are enough, no mutex.
+type renderRing struct {
+	slots []renderBlock
+	head  uint64 // next slot the consumer will read
+	tail  uint64 // next slot the producer will write
+}
+
+// renderRingSlots is how many pre-rendered blocks a ring holds - enough
+// headroom to absorb a brief stall in the fill goroutine (a GC pause, a
+// slow callback) without the render thread running dry immediately.
+const renderRingSlots = 8
+
+func newRenderRing() *renderRing {
+	return &renderRing{slots: make([]renderBlock, renderRingSlots)}
+}
+
+func (r *renderRing) push(b renderBlock) bool {
+	tail := atomic.LoadUint64(&r.tail)
+	head := atomic.LoadUint64(&r.head)
+	if tail-head >= uint64(len(r.slots)) {
+		return false
+	}
+	r.slots[tail%uint64(len(r.slots))] = b
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+func (r *renderRing) pop() (renderBlock, bool) {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if head == tail {
+		return renderBlock{}, false
+	}
+	b := r.slots[head%uint64(len(r.slots))]
+	atomic.StoreUint64(&r.head, head+1)
+	return b, true
+}
+
+// RenderRingStats is a running count of blocks a ring-buffered render
+// callback has produced and dropped, for a health check or UI meter.
+type RenderRingStats struct {
+	BlocksProduced uint64
+	BlocksDropped  uint64
+}
+
+// SetRenderCallbackWithRingBuffer installs cb to run on a dedicated
+// goroutine instead of the realtime audio thread, for callers who can't
+// meet RenderCallback's no-allocation/no-lock/no-blocking bar: cb fills one
+// frameCount-sized block ahead of demand, and the filled block is handed
+// to the render thread through a lock-free renderRing - the same
+// decoupling avaudio/engine.BuildOutputStream gives a node, scoped here to
+// a single SourceNode. frameCount and channels are fixed for the life of
+// the callback; call SetRenderCallbackWithRingBuffer again to change them.
+//
+// As with SetRenderCallback, the render block that would drain this ring on
+// the audio thread isn't wired up in this tree yet - cb starts running
+// immediately on its own goroutine, but nothing consumes the ring until
+// that binding exists. Stop the fill goroutine with ClearRenderCallback.
+func (s *SourceNode) SetRenderCallbackWithRingBuffer(frameCount, channels int, cb RenderCallback) error {
+	if s == nil || s.ptr == nil {
+		return errors.New("source node is nil or destroyed")
+	}
+	if cb == nil {
+		return errors.New("callback cannot be nil")
+	}
+	if frameCount <= 0 {
+		return errors.New("frame count must be positive")
+	}
+	if channels <= 0 {
+		return errors.New("channel count must be positive")
+	}
+
+	ring := newRenderRing()
+	stopCh := make(chan struct{})
+
+	// drainRing is what actually runs on the render thread once the native
+	// trampoline exists: it never calls cb itself, only copies whatever the
+	// fill goroutine already rendered into out, so it stays realtime-safe
+	// even though cb itself may not be.
+	drainRing := func(fc, ch int, out [][]float32) error {
+		block, ok := ring.pop()
+		if !ok {
+			return errors.New("render ring underrun: fill goroutine fell behind")
+		}
+		for c := 0; c < ch && c < len(block.channels); c++ {
+			copy(out[c], block.channels[c])
+		}
+		return nil
+	}
+
+	if err := s.SetRenderCallback(drainRing); err != nil {
+		return err
+	}
+
+	s.renderRing = ring
+	s.renderRingStop = stopCh
+	go fillRenderRing(ring, stopCh, frameCount, channels, cb, &s.renderRingStats)
+	return nil
+}
+
+// fillRenderRing calls cb to fill one block at a time, pushing each onto
+// ring for the (eventual) render-thread consumer, backing off when ring is
+// already full rather than generating further ahead than it can hold. It's
+// pinned to its OS thread for the callback's lifetime, the same as
+// avaudio/engine's NodeStream.fillLoop, and stops as soon as stopCh closes.
+func fillRenderRing(ring *renderRing, stopCh chan struct{}, frameCount, channels int, cb RenderCallback, stats *RenderRingStats) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			for {
+				out := make([][]float32, channels)
+				for c := range out {
+					out[c] = make([]float32, frameCount)
+				}
+				if err := cb(frameCount, channels, out); err != nil {
+					break
+				}
+				if !ring.push(renderBlock{channels: out}) {
+					atomic.AddUint64(&stats.BlocksDropped, 1)
+					break
+				}
+				atomic.AddUint64(&stats.BlocksProduced, 1)
+			}
+		}
+	}
+}
+
+// RenderRingStats returns the running delivery counters for a callback
+// installed with SetRenderCallbackWithRingBuffer, or the zero value if none
+// is installed.
+func (s *SourceNode) RenderRingStats() RenderRingStats {
+	return RenderRingStats{
+		BlocksProduced: atomic.LoadUint64(&s.renderRingStats.BlocksProduced),
+		BlocksDropped:  atomic.LoadUint64(&s.renderRingStats.BlocksDropped),
+	}
+}
@@ -0,0 +1,94 @@
+package sourcenode
+
+import "testing"
+
+func TestRenderRingPushPop(t *testing.T) {
+	r := &renderRing{slots: make([]renderBlock, 2)}
+
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on empty ring should report false")
+	}
+
+	if !r.push(renderBlock{channels: [][]float32{{1}}}) {
+		t.Fatal("push into empty ring should succeed")
+	}
+	if !r.push(renderBlock{channels: [][]float32{{2}}}) {
+		t.Fatal("push into ring with one free slot should succeed")
+	}
+	if r.push(renderBlock{channels: [][]float32{{3}}}) {
+		t.Fatal("push into full ring should fail")
+	}
+
+	b, ok := r.pop()
+	if !ok || b.channels[0][0] != 1 {
+		t.Fatalf("pop = %+v, %v; want channels[0][0]=1, true", b, ok)
+	}
+	if !r.push(renderBlock{channels: [][]float32{{3}}}) {
+		t.Fatal("push after freeing a slot should succeed")
+	}
+
+	b, ok = r.pop()
+	if !ok || b.channels[0][0] != 2 {
+		t.Fatalf("pop = %+v, %v; want channels[0][0]=2, true", b, ok)
+	}
+	b, ok = r.pop()
+	if !ok || b.channels[0][0] != 3 {
+		t.Fatalf("pop = %+v, %v; want channels[0][0]=3, true", b, ok)
+	}
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on drained ring should report false")
+	}
+}
+
+func TestSetRenderCallbackRejectsNilCallback(t *testing.T) {
+	node, err := NewSilent()
+	if err != nil {
+		t.Fatalf("NewSilent: %v", err)
+	}
+	defer node.Destroy()
+
+	if err := node.SetRenderCallback(nil); err == nil {
+		t.Fatal("expected error installing a nil callback")
+	}
+}
+
+func TestSetRenderCallbackWithRingBufferRejectsInvalidArgs(t *testing.T) {
+	node, err := NewSilent()
+	if err != nil {
+		t.Fatalf("NewSilent: %v", err)
+	}
+	defer node.Destroy()
+
+	noop := func(int, int, [][]float32) error { return nil }
+
+	cases := []struct {
+		name       string
+		frameCount int
+		channels   int
+		cb         RenderCallback
+	}{
+		{"zero frame count", 0, 2, noop},
+		{"negative channels", 512, -1, noop},
+		{"nil callback", 512, 2, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := node.SetRenderCallbackWithRingBuffer(c.frameCount, c.channels, c.cb); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestClearRenderCallbackIsNoOpWithoutOne(t *testing.T) {
+	node, err := NewSilent()
+	if err != nil {
+		t.Fatalf("NewSilent: %v", err)
+	}
+	defer node.Destroy()
+
+	if err := node.ClearRenderCallback(); err != nil {
+		t.Fatalf("ClearRenderCallback on a node with none installed: %v", err)
+	}
+}
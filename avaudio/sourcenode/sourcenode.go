@@ -31,6 +31,16 @@ type SourceNode struct {
 	amplitude float64
 	phase     float64
 	format    *format.Format // Keep reference to prevent garbage collection
+
+	// renderCallback/renderCallbackKey track a callback installed by
+	// SetRenderCallback/SetRenderCallbackWithRingBuffer - see
+	// render_callback.go. renderRing/renderRingStop/renderRingStats are
+	// only set by the ring-buffer variant.
+	renderCallback    RenderCallback
+	renderCallbackKey string
+	renderRing        *renderRing
+	renderRingStop    chan struct{}
+	renderRingStats   RenderRingStats
 }
 
 // New creates a new AVAudioSourceNode instance
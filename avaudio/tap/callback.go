@@ -0,0 +1,305 @@
+package tap
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include <stdlib.h>
+
+// Declared here; implemented in native/tap_callback.m once the C-side ring
+// writer and the //export trampoline it calls into Go through exist (see
+// InstallCallbackTap's doc comment). A separate registration from
+// tap_install/tap_remove in native/tap.m so existing poll-driven Tap
+// installs are untouched by adding this.
+const char* tap_install_callback(void* enginePtr, void* nodePtr, int busIndex, int bufferSize, const char* tapKey);
+const char* tap_remove_callback(const char* tapKey);
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// TapFormat selects the sample layout InstallCallbackTap delivers to a
+// CallbackTap's callback.
+type TapFormat int
+
+const (
+	TapFormatInterleavedFloat32 TapFormat = iota
+	TapFormatPlanarFloat32
+	TapFormatInterleavedInt16
+	TapFormatPlanarInt16
+)
+
+// TapBuffer is one block of PCM audio delivered to a CallbackTap's
+// callback, in the TapFormat requested at InstallCallbackTap time. Only the
+// slice matching Format is populated; the other is nil.
+type TapBuffer struct {
+	Format      TapFormat
+	Frames      int
+	Channels    int
+	Float32Data []float32 // valid when Format is TapFormatInterleavedFloat32 or TapFormatPlanarFloat32
+	Int16Data   []int16   // valid when Format is TapFormatInterleavedInt16 or TapFormatPlanarInt16
+	Timestamp   time.Time
+}
+
+// TapStats is a CallbackTap's running delivery counters, for a metering UI
+// or health check to surface overload rather than discover it as silence.
+type TapStats struct {
+	FramesDelivered uint64
+	FramesDropped   uint64
+}
+
+// callbackRing is a lock-free single-producer/single-consumer ring buffer
+// of TapBuffer slots. The tap's native render block is the sole producer
+// (via push, called from the cgo export trampoline - not wired into this
+// tree yet, see CallbackTap's doc comment) and drainLoop is the sole
+// consumer, so head and tail each only ever move forward from one
+// goroutine; atomics are enough, no mutex needed.
+type callbackRing struct {
+	slots []TapBuffer
+	head  uint64 // next slot drainLoop will read
+	tail  uint64 // next slot push will write
+}
+
+func newCallbackRing(size int) *callbackRing {
+	if size < 2 {
+		size = 2
+	}
+	return &callbackRing{slots: make([]TapBuffer, size)}
+}
+
+func (r *callbackRing) push(buf TapBuffer) bool {
+	tail := atomic.LoadUint64(&r.tail)
+	head := atomic.LoadUint64(&r.head)
+	if tail-head >= uint64(len(r.slots)) {
+		return false // full; caller counts this as a dropped buffer
+	}
+	r.slots[tail%uint64(len(r.slots))] = buf
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+func (r *callbackRing) pop() (TapBuffer, bool) {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if head == tail {
+		return TapBuffer{}, false
+	}
+	buf := r.slots[head%uint64(len(r.slots))]
+	atomic.StoreUint64(&r.head, head+1)
+	return buf, true
+}
+
+// Global callback-tap registry, mirroring tapRegistry's Go-side bookkeeping
+// for the poll-driven Tap above.
+var (
+	callbackTapRegistry = make(map[string]*CallbackTap)
+	callbackTapMutex    sync.RWMutex
+)
+
+// CallbackTap is an audio tap that dispatches PCM frames to a Go callback
+// through a lock-free ring buffer instead of Tap's poll-driven
+// GetMetrics/GetSamples, for a caller that wants a continuous stream (a VU
+// meter, a waveform view, an offline recorder) rather than point-in-time
+// samples. See InstallCallbackTap.
+type CallbackTap struct {
+	key       string
+	enginePtr unsafe.Pointer
+	nodePtr   unsafe.Pointer
+	busIndex  int
+	format    TapFormat
+
+	ring *callbackRing
+	cb   func(TapBuffer)
+
+	delivered uint64
+	dropped   uint64
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	installed bool
+}
+
+// InstallCallbackTap installs a tap on nodePtr/busIndex that pushes each
+// bufferSize-frame block into a lock-free ring rather than waiting for a
+// poller to pull a GetMetrics/GetSamples snapshot, and starts a goroutine
+// that drains the ring and invokes cb once per block. Multiple
+// CallbackTaps (and/or Taps) can be installed on the same nodePtr/busIndex
+// at once, each with its own key, for fan-out to several independent
+// consumers - a meter and a recorder both tapping the main mixer, say.
+//
+// The ring's producer is the tap's native render block, which is never on
+// cb's call stack and never blocks waiting for Go: on overflow it drops
+// the block and increments TapStats.FramesDropped instead, so a slow cb
+// (or cb blocking outright) can never stall the audio thread. cb itself
+// always runs on the drain goroutine, so unlike a render callback (see
+// avaudio/engine's RenderCallback) it's safe to allocate, log, or block in.
+//
+// The cgo trampoline the native render block needs to call back into Go
+// isn't wired up in this tree yet (see tap_install_callback's declaration
+// above) - like CreateRenderUnit, this installs cleanly and a removal
+// works correctly, but until that trampoline exists the ring never
+// receives a block and cb is never called.
+func InstallCallbackTap(enginePtr, nodePtr unsafe.Pointer, busIndex, bufferSize int, format TapFormat, cb func(TapBuffer)) (*CallbackTap, error) {
+	if enginePtr == nil {
+		return nil, fmt.Errorf("engine pointer cannot be nil")
+	}
+	if nodePtr == nil {
+		return nil, fmt.Errorf("node pointer cannot be nil")
+	}
+	if busIndex < 0 {
+		return nil, fmt.Errorf("bus index must be non-negative")
+	}
+	if bufferSize <= 0 {
+		return nil, fmt.Errorf("buffer size must be positive")
+	}
+	if cb == nil {
+		return nil, fmt.Errorf("callback cannot be nil")
+	}
+
+	key := fmt.Sprintf("calltap_%p_bus%d_%d", nodePtr, busIndex, time.Now().UnixNano())
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	errorStr := C.tap_install_callback(enginePtr, nodePtr, C.int(busIndex), C.int(bufferSize), cKey)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+
+	t := &CallbackTap{
+		key:       key,
+		enginePtr: enginePtr,
+		nodePtr:   nodePtr,
+		busIndex:  busIndex,
+		format:    format,
+		ring:      newCallbackRing(ringSlotsFor(bufferSize)),
+		cb:        cb,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+		installed: true,
+	}
+
+	callbackTapMutex.Lock()
+	callbackTapRegistry[key] = t
+	callbackTapMutex.Unlock()
+
+	go t.drainLoop()
+	return t, nil
+}
+
+// ringSlotsFor picks how many blocks the ring holds, independent of
+// bufferSize: a fixed 8 blocks of headroom is enough to absorb a brief
+// stall in cb (a GC pause, a slow disk write) without growing unbounded
+// for a caller that requests a huge bufferSize.
+func ringSlotsFor(bufferSize int) int {
+	const slots = 8
+	return slots
+}
+
+// drainLoop pops blocks off the ring and invokes cb for each, until Remove
+// closes stopCh, at which point it drains whatever is left before exiting.
+func (t *CallbackTap) drainLoop() {
+	defer close(t.doneCh)
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			for {
+				buf, ok := t.ring.pop()
+				if !ok {
+					return
+				}
+				t.deliver(buf)
+			}
+		case <-ticker.C:
+			for {
+				buf, ok := t.ring.pop()
+				if !ok {
+					break
+				}
+				t.deliver(buf)
+			}
+		}
+	}
+}
+
+func (t *CallbackTap) deliver(buf TapBuffer) {
+	atomic.AddUint64(&t.delivered, 1)
+	t.cb(buf)
+}
+
+// push hands buf to the ring, the shape the cgo export trampoline (not
+// wired up yet - see InstallCallbackTap) will call from the native render
+// block. Returns false if the ring is full, so the caller can drop the
+// block on the audio thread and count it, never block.
+func (t *CallbackTap) push(buf TapBuffer) bool {
+	if t.ring.push(buf) {
+		return true
+	}
+	atomic.AddUint64(&t.dropped, 1)
+	return false
+}
+
+// Remove stops delivering to cb and removes the native tap. Blocks until
+// the drain goroutine has delivered whatever was left in the ring.
+func (t *CallbackTap) Remove() error {
+	callbackTapMutex.Lock()
+	if !t.installed {
+		callbackTapMutex.Unlock()
+		return fmt.Errorf("tap is not installed")
+	}
+	delete(callbackTapRegistry, t.key)
+	t.installed = false
+	callbackTapMutex.Unlock()
+
+	close(t.stopCh)
+	<-t.doneCh
+
+	cKey := C.CString(t.key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	errorStr := C.tap_remove_callback(cKey)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// Stats returns the tap's running delivery counters.
+func (t *CallbackTap) Stats() TapStats {
+	return TapStats{
+		FramesDelivered: atomic.LoadUint64(&t.delivered),
+		FramesDropped:   atomic.LoadUint64(&t.dropped),
+	}
+}
+
+// IsInstalled returns true if the tap is currently installed.
+func (t *CallbackTap) IsInstalled() bool {
+	return t.installed
+}
+
+// GetKey returns the tap's auto-generated key identifier.
+func (t *CallbackTap) GetKey() string {
+	return t.key
+}
+
+// GetBusIndex returns the bus index being tapped.
+func (t *CallbackTap) GetBusIndex() int {
+	return t.busIndex
+}
+
+// GetNodePtr returns the node pointer being tapped.
+func (t *CallbackTap) GetNodePtr() unsafe.Pointer {
+	return t.nodePtr
+}
+
+// GetFormat returns the TapFormat this tap delivers buffers in.
+func (t *CallbackTap) GetFormat() TapFormat {
+	return t.format
+}
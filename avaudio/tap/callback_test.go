@@ -0,0 +1,79 @@
+package tap
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestCallbackRingPushPop(t *testing.T) {
+	r := newCallbackRing(2)
+
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on empty ring should report false")
+	}
+
+	if !r.push(TapBuffer{Frames: 1}) {
+		t.Fatal("push into empty ring should succeed")
+	}
+	if !r.push(TapBuffer{Frames: 2}) {
+		t.Fatal("push into ring with one free slot should succeed")
+	}
+	if r.push(TapBuffer{Frames: 3}) {
+		t.Fatal("push into full ring should fail")
+	}
+
+	buf, ok := r.pop()
+	if !ok || buf.Frames != 1 {
+		t.Fatalf("pop = %+v, %v; want Frames=1, true", buf, ok)
+	}
+	if !r.push(TapBuffer{Frames: 3}) {
+		t.Fatal("push after freeing a slot should succeed")
+	}
+
+	buf, ok = r.pop()
+	if !ok || buf.Frames != 2 {
+		t.Fatalf("pop = %+v, %v; want Frames=2, true", buf, ok)
+	}
+	buf, ok = r.pop()
+	if !ok || buf.Frames != 3 {
+		t.Fatalf("pop = %+v, %v; want Frames=3, true", buf, ok)
+	}
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on drained ring should report false")
+	}
+}
+
+func TestCallbackRingMinimumSize(t *testing.T) {
+	r := newCallbackRing(0)
+	if len(r.slots) < 2 {
+		t.Fatalf("newCallbackRing(0) made a ring of %d slots, want at least 2", len(r.slots))
+	}
+}
+
+func TestInstallCallbackTapRejectsInvalidArgs(t *testing.T) {
+	valid := unsafe.Pointer(&struct{}{})
+	noop := func(TapBuffer) {}
+
+	cases := []struct {
+		name       string
+		enginePtr  unsafe.Pointer
+		nodePtr    unsafe.Pointer
+		busIndex   int
+		bufferSize int
+		cb         func(TapBuffer)
+	}{
+		{"nil engine", nil, valid, 0, 512, noop},
+		{"nil node", valid, nil, 0, 512, noop},
+		{"negative bus", valid, valid, -1, 512, noop},
+		{"zero buffer size", valid, valid, 0, 0, noop},
+		{"nil callback", valid, valid, 0, 512, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := InstallCallbackTap(c.enginePtr, c.nodePtr, c.busIndex, c.bufferSize, TapFormatInterleavedFloat32, c.cb); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
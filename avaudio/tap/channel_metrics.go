@@ -0,0 +1,61 @@
+package tap
+
+import (
+	"fmt"
+	"math"
+)
+
+// ChannelMetrics is one channel's slice of a Tap's most recent captured
+// buffer, deinterleaved from the same buffer GetMetrics reports as
+// TapMetrics.Samples - see Tap.PerChannelMetrics.
+type ChannelMetrics struct {
+	RMS     float64
+	Peak    float64
+	Samples []float32
+}
+
+// PerChannelMetrics deinterleaves the tap's most recent captured buffer into
+// one ChannelMetrics per channel, each with that channel's own RMS, peak,
+// and raw samples. TapMetrics.RMS is a single number across every channel;
+// this is for a caller like engine/analyze's AnalyzeMonoToStereo that needs
+// genuinely per-channel data (L vs. R) instead.
+func (t *Tap) PerChannelMetrics() ([]ChannelMetrics, error) {
+	if !t.installed {
+		return nil, fmt.Errorf("tap is not installed")
+	}
+
+	info, err := t.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.ChannelCount <= 0 {
+		return nil, fmt.Errorf("tap reports %d channels", info.ChannelCount)
+	}
+
+	interleaved, err := t.GetSamples(metricsSampleCapture)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := info.ChannelCount
+	frames := len(interleaved) / channels
+	result := make([]ChannelMetrics, channels)
+	for ch := 0; ch < channels; ch++ {
+		samples := make([]float32, frames)
+		var sumSq, peak float64
+		for frame := 0; frame < frames; frame++ {
+			s := interleaved[frame*channels+ch]
+			samples[frame] = s
+			sumSq += float64(s) * float64(s)
+			if abs := math.Abs(float64(s)); abs > peak {
+				peak = abs
+			}
+		}
+		rms := 0.0
+		if frames > 0 {
+			rms = math.Sqrt(sumSq / float64(frames))
+		}
+		result[ch] = ChannelMetrics{RMS: rms, Peak: peak, Samples: samples}
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package tap
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+
+// Declared here rather than re-#include-ing native/tap.m (see tap.go) - the
+// implementation lives in the same native/tap.m translation unit, compiled
+// once from tap.go's preamble and linked against this extern declaration.
+const char* tap_inject_buffer(void* enginePtr, void* nodePtr, float* samples, int frameCount, int channelCount, double sampleRate);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// InjectBuffer schedules samples (interleaved, channelCount per frame) for
+// immediate playback into the node at nodePtr - the inverse of InstallTap:
+// a tap reads a node's output, InjectBuffer drives its input. It's for
+// measurements that need to play a known signal into an arbitrary
+// AVAudioNode rather than through an AudioPlayer wired up ahead of time -
+// e.g. engine/analyze's swept-sine impulse response measurement, which
+// injects its sweep at one node and taps the response at another.
+func InjectBuffer(enginePtr, nodePtr unsafe.Pointer, samples []float32, channelCount int, sampleRate float64) error {
+	if enginePtr == nil || nodePtr == nil {
+		return fmt.Errorf("engine and node pointers cannot be nil")
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("samples cannot be empty")
+	}
+	if channelCount <= 0 {
+		return fmt.Errorf("channel count must be positive")
+	}
+	if sampleRate <= 0 {
+		return fmt.Errorf("sample rate must be positive")
+	}
+
+	frameCount := len(samples) / channelCount
+	errorStr := C.tap_inject_buffer(
+		enginePtr,
+		nodePtr,
+		(*C.float)(unsafe.Pointer(&samples[0])),
+		C.int(frameCount),
+		C.int(channelCount),
+		C.double(sampleRate),
+	)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
@@ -0,0 +1,566 @@
+package tap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// LoudnessMetrics is a snapshot of a Tap's running ITU-R BS.1770 / EBU R128
+// loudness measurement, as of the last block GetLoudness's analyzer has
+// processed. Peak and TruePeak are dBFS/dBTP; Momentary, ShortTerm,
+// Integrated, and LRA are LUFS/LU. Any field computed from fewer gating
+// blocks than it needs (no audio yet, or all of it gated out) reads
+// math.Inf(-1), matching how hardware loudness meters show "-inf" before
+// enough signal has passed.
+type LoudnessMetrics struct {
+	Peak       float64
+	TruePeak   float64
+	Momentary  float64
+	ShortTerm  float64
+	Integrated float64
+	LRA        float64
+}
+
+// GetLoudness returns the tap's current loudness measurement, lazily
+// installing a dedicated Subscribe registration the first time it's
+// called so the K-weighting filters and gating-block math below run on
+// that subscription's own pump goroutine (see subscribe.go) - never on the
+// audio thread.
+func (t *Tap) GetLoudness() (LoudnessMetrics, error) {
+	if !t.installed {
+		return LoudnessMetrics{}, fmt.Errorf("tap is not installed")
+	}
+
+	t.loudnessMu.Lock()
+	defer t.loudnessMu.Unlock()
+	if err := t.ensureLoudnessLocked(); err != nil {
+		return LoudnessMetrics{}, err
+	}
+	return t.loudness.snapshot(), nil
+}
+
+// ResetIntegrated clears the integrated-loudness measurement (the
+// gating-block history two-pass gating averages over) so a new program
+// segment can be measured from zero. Momentary and short-term, being
+// sliding windows over only the most recent blocks, aren't affected.
+func (t *Tap) ResetIntegrated() error {
+	if !t.installed {
+		return fmt.Errorf("tap is not installed")
+	}
+
+	t.loudnessMu.Lock()
+	defer t.loudnessMu.Unlock()
+	if err := t.ensureLoudnessLocked(); err != nil {
+		return err
+	}
+	t.loudness.reset()
+	return nil
+}
+
+// MeasureLoudness computes a one-shot ITU-R BS.1770 / EBU R128 loudness
+// reading over buffer - a single interleaved multi-channel sample buffer
+// captured at one point in time (e.g. TapMetrics.Samples), rather than
+// GetLoudness's continuously-updated subscription. It feeds buffer through
+// the same loudnessAnalyzer a live GetLoudness subscription uses, just in
+// one call instead of one call per render block - intended for
+// engine/analyze's Verify/Analyze functions, which already work from a
+// single captured buffer per call rather than a live tap subscription.
+//
+// A buffer this short rarely accumulates enough 100ms gating blocks for the
+// relative gate or LRA to mean much - Integrated, Momentary, and ShortTerm
+// still read correctly off whatever blocks fit, same math as a live meter,
+// just over less history.
+func MeasureLoudness(buffer []float32, sampleRate float64, channels int) LoudnessMetrics {
+	if sampleRate <= 0 || channels <= 0 || len(buffer) == 0 {
+		return LoudnessMetrics{Peak: math.Inf(-1), TruePeak: math.Inf(-1), Momentary: math.Inf(-1), ShortTerm: math.Inf(-1), Integrated: math.Inf(-1), LRA: math.Inf(-1)}
+	}
+
+	analyzer := newLoudnessAnalyzer()
+	analyzer.process(interleavedToPlanar(buffer, channels), channels, sampleRate, 0)
+	return analyzer.snapshot()
+}
+
+// interleavedToPlanar converts GetSamples' interleaved layout (frame 0's
+// channels, then frame 1's, ...) to the planar layout (all of channel 0's
+// frames, then channel 1's, ...) loudnessAnalyzer.process expects, per its
+// Tap.Subscribe origins - see ChannelLayoutPlanar.
+func interleavedToPlanar(buffer []float32, channels int) []float32 {
+	frames := len(buffer) / channels
+	planar := make([]float32, frames*channels)
+	for frame := 0; frame < frames; frame++ {
+		for ch := 0; ch < channels; ch++ {
+			planar[ch*frames+frame] = buffer[frame*channels+ch]
+		}
+	}
+	return planar
+}
+
+// ensureLoudnessLocked installs t's loudness analyzer the first time
+// GetLoudness or ResetIntegrated is called. Caller must hold t.loudnessMu.
+func (t *Tap) ensureLoudnessLocked() error {
+	if t.loudness != nil {
+		return nil
+	}
+	analyzer := newLoudnessAnalyzer()
+	subID, err := t.Subscribe(analyzer.process, SubscribeOptions{Layout: ChannelLayoutPlanar})
+	if err != nil {
+		return err
+	}
+	t.loudness = analyzer
+	t.loudnessSubID = subID
+	return nil
+}
+
+// stopLoudnessLocked tears down the loudness analyzer's Subscribe
+// registration, if one was ever installed. Called from Remove so a
+// GetLoudness caller doesn't leak a subscription past the tap's own
+// lifetime.
+func (t *Tap) stopLoudnessLocked() {
+	t.loudnessMu.Lock()
+	active := t.loudness != nil
+	subID := t.loudnessSubID
+	t.loudness = nil
+	t.loudnessMu.Unlock()
+
+	if active {
+		t.Unsubscribe(subID)
+	}
+}
+
+// gatingBlockSeconds is the non-overlapping gating block size this
+// analyzer accumulates mean-square energy over, per the request driving
+// this file rather than BS.1770's own overlapping 400ms blocks.
+const gatingBlockSeconds = 0.1
+
+// momentaryWindowBlocks and shortTermWindowBlocks are how many
+// gatingBlockSeconds blocks Momentary (400ms) and ShortTerm (3s) average
+// over.
+const (
+	momentaryWindowBlocks = 4
+	shortTermWindowBlocks = 30
+)
+
+const (
+	absoluteGateLUFS    = -70.0
+	relativeGateDeltaLU = -10.0
+
+	// lraRelativeGateDeltaLU is LRA's own relative gate, per EBU Tech 3342 -
+	// wider than integrated loudness's -10 LU so LRA captures more of a
+	// program's dynamic range rather than just its loudest portion.
+	lraRelativeGateDeltaLU = -20.0
+
+	// lraLowPercentile and lraHighPercentile bound the short-term loudness
+	// distribution LRA is computed from, per EBU Tech 3342.
+	lraLowPercentile  = 10.0
+	lraHighPercentile = 95.0
+)
+
+// loudnessAnalyzer is a single Tap's running K-weighted loudness state,
+// fed one PCM block at a time by process (the Tap.Subscribe callback
+// GetLoudness registers) - so everything here runs on that subscription's
+// own pump goroutine, single-threaded with respect to itself, and is only
+// guarded by mu for the snapshot/reset calls a caller makes from elsewhere.
+type loudnessAnalyzer struct {
+	mu sync.Mutex
+
+	configured bool
+	sampleRate float64
+	channels   int
+	blockSize  int
+
+	filters     []kWeightingFilter
+	blockSumSq  []float64
+	blockFrames int
+
+	peak            float64
+	truePeak        float64
+	truePeakEnabled bool
+	truePeakHist    [][3]float64 // last 3 raw samples per channel, for the 4x oversampling FIR
+
+	// blocks holds every gating block's K-weighted energy (z, pre-log)
+	// since the last reset - the integrated measurement's two-pass gate
+	// needs the whole history, not just a sliding window.
+	blocks []float64
+
+	// shortTermHistory holds one short-term LUFS reading per gating block
+	// once at least shortTermWindowBlocks have accumulated, since the last
+	// reset - LRA's own gating pass needs this whole distribution, not just
+	// the current sliding window ShortTerm reports.
+	shortTermHistory []float64
+}
+
+func newLoudnessAnalyzer() *loudnessAnalyzer {
+	return &loudnessAnalyzer{peak: math.Inf(-1), truePeak: math.Inf(-1), truePeakEnabled: true}
+}
+
+// setTruePeakEnabled toggles the 4x oversampling FIR updatePeak runs per
+// sample - SetMetricsConfig's TruePeak switch, for a caller who wants plain
+// peak without paying for the oversampling on a high channel-count tap.
+// With it false, TruePeak stops advancing from whatever it last reached.
+func (a *loudnessAnalyzer) setTruePeakEnabled(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.truePeakEnabled = enabled
+}
+
+// process is a tap.Subscribe callback (see SubscribeOptions.Layout), so
+// pcm is planar: all of channel 0's frames, then all of channel 1's, etc.
+func (a *loudnessAnalyzer) process(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if channels <= 0 || len(pcm) == 0 {
+		return
+	}
+	a.ensureConfigured(sampleRate, channels)
+	if channels != a.channels {
+		return // a reconfiguration mid-stream would desync the per-channel filter state; ignore rather than corrupt it
+	}
+
+	frames := len(pcm) / channels
+	weights := channelWeights(channels)
+
+	for frame := 0; frame < frames; frame++ {
+		for ch := 0; ch < channels; ch++ {
+			sample := float64(pcm[ch*frames+frame])
+			a.updatePeak(ch, sample)
+
+			filtered := a.filters[ch].process(sample)
+			a.blockSumSq[ch] += filtered * filtered
+		}
+		a.blockFrames++
+		if a.blockFrames >= a.blockSize {
+			a.finishBlock(weights)
+		}
+	}
+}
+
+func (a *loudnessAnalyzer) ensureConfigured(sampleRate float64, channels int) {
+	if a.configured {
+		return
+	}
+	a.sampleRate = sampleRate
+	a.channels = channels
+	a.blockSize = int(sampleRate * gatingBlockSeconds)
+	if a.blockSize < 1 {
+		a.blockSize = 1
+	}
+	a.filters = make([]kWeightingFilter, channels)
+	for ch := range a.filters {
+		a.filters[ch] = newKWeightingFilter(sampleRate)
+	}
+	a.blockSumSq = make([]float64, channels)
+	a.truePeakHist = make([][3]float64, channels)
+	a.configured = true
+}
+
+// updatePeak tracks both the plain sample-peak (Peak) and, via 4x
+// polyphase oversampling, the inter-sample true peak (TruePeak) - on the
+// tap's raw, unweighted signal, not the K-weighted one process also feeds.
+func (a *loudnessAnalyzer) updatePeak(ch int, sample float64) {
+	if abs := math.Abs(sample); abs > a.peak {
+		a.peak = abs
+	}
+	if !a.truePeakEnabled {
+		return
+	}
+
+	hist := a.truePeakHist[ch]
+	for _, phase := range truePeakOversampleFIR {
+		interp := phase[0]*hist[0] + phase[1]*hist[1] + phase[2]*hist[2] + phase[3]*sample
+		if abs := math.Abs(interp); abs > a.truePeak {
+			a.truePeak = abs
+		}
+	}
+	a.truePeakHist[ch] = [3]float64{hist[1], hist[2], sample}
+}
+
+// finishBlock turns the current 100ms block's accumulated per-channel
+// K-weighted mean-square energy into a single ITU-weighted z value, rolls
+// it into the gating-block history, and resets the accumulator for the
+// next block.
+func (a *loudnessAnalyzer) finishBlock(weights []float64) {
+	var z float64
+	for ch, sumSq := range a.blockSumSq {
+		meanSq := sumSq / float64(a.blockSize)
+		z += weights[ch] * meanSq
+	}
+	a.blocks = append(a.blocks, z)
+	if len(a.blocks) >= shortTermWindowBlocks {
+		a.shortTermHistory = append(a.shortTermHistory, windowedLUFS(a.blocks, shortTermWindowBlocks))
+	}
+
+	for ch := range a.blockSumSq {
+		a.blockSumSq[ch] = 0
+	}
+	a.blockFrames = 0
+}
+
+// reset clears the gating-block history the integrated measurement
+// two-pass gates over. Peak/TruePeak and the filter/oversampling state
+// are left alone, so a caller resetting between program segments doesn't
+// lose continuity in the running peak.
+func (a *loudnessAnalyzer) reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.blocks = nil
+	a.shortTermHistory = nil
+}
+
+// snapshot computes the four loudness readings from the current gating-
+// block history, without mutating any of it.
+func (a *loudnessAnalyzer) snapshot() LoudnessMetrics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return LoudnessMetrics{
+		Peak:       linearToDB(a.peak),
+		TruePeak:   linearToDB(a.truePeak),
+		Momentary:  windowedLUFS(a.blocks, momentaryWindowBlocks),
+		ShortTerm:  windowedLUFS(a.blocks, shortTermWindowBlocks),
+		Integrated: integratedLUFS(a.blocks),
+		LRA:        lra(a.shortTermHistory),
+	}
+}
+
+// windowedLUFS averages the z energy of the last windowBlocks entries of
+// blocks (or fewer, if there aren't that many yet) and converts to LUFS.
+func windowedLUFS(blocks []float64, windowBlocks int) float64 {
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+	start := len(blocks) - windowBlocks
+	if start < 0 {
+		start = 0
+	}
+	window := blocks[start:]
+
+	var sum float64
+	for _, z := range window {
+		sum += z
+	}
+	return lufs(sum / float64(len(window)))
+}
+
+// integratedLUFS applies BS.1770's two-pass relative gating: blocks below
+// an absolute threshold of -70 LUFS are discarded outright, then blocks
+// more than 10 LU below the mean of what's left are discarded too, and
+// the final loudness is the mean of whatever survives both passes.
+func integratedLUFS(blocks []float64) float64 {
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+
+	absoluteGated := make([]float64, 0, len(blocks))
+	for _, z := range blocks {
+		if lufs(z) > absoluteGateLUFS {
+			absoluteGated = append(absoluteGated, z)
+		}
+	}
+	if len(absoluteGated) == 0 {
+		return math.Inf(-1)
+	}
+
+	var sum float64
+	for _, z := range absoluteGated {
+		sum += z
+	}
+	meanZ := sum / float64(len(absoluteGated))
+	relativeGate := lufs(meanZ) + relativeGateDeltaLU
+
+	relativeGated := make([]float64, 0, len(absoluteGated))
+	for _, z := range absoluteGated {
+		if lufs(z) > relativeGate {
+			relativeGated = append(relativeGated, z)
+		}
+	}
+	if len(relativeGated) == 0 {
+		return math.Inf(-1)
+	}
+
+	sum = 0
+	for _, z := range relativeGated {
+		sum += z
+	}
+	return lufs(sum / float64(len(relativeGated)))
+}
+
+// lra computes EBU Tech 3342 loudness range from a history of short-term
+// LUFS readings: gate out values below -70 LUFS absolute and more than 20 LU
+// below the mean of what remains, then return the spread between the 10th
+// and 95th percentile of what survives both gates.
+func lra(shortTermHistory []float64) float64 {
+	if len(shortTermHistory) == 0 {
+		return math.Inf(-1)
+	}
+
+	absoluteGated := make([]float64, 0, len(shortTermHistory))
+	for _, st := range shortTermHistory {
+		if !math.IsInf(st, -1) && st > absoluteGateLUFS {
+			absoluteGated = append(absoluteGated, st)
+		}
+	}
+	if len(absoluteGated) == 0 {
+		return math.Inf(-1)
+	}
+
+	var sum float64
+	for _, st := range absoluteGated {
+		sum += st
+	}
+	relativeGate := sum/float64(len(absoluteGated)) + lraRelativeGateDeltaLU
+
+	relativeGated := make([]float64, 0, len(absoluteGated))
+	for _, st := range absoluteGated {
+		if st > relativeGate {
+			relativeGated = append(relativeGated, st)
+		}
+	}
+	if len(relativeGated) == 0 {
+		return 0
+	}
+
+	sort.Float64s(relativeGated)
+	return percentile(relativeGated, lraHighPercentile) - percentile(relativeGated, lraLowPercentile)
+}
+
+// percentile linearly interpolates the p-th percentile (0-100) of sorted,
+// an already-ascending-sorted slice.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	if lo == hi {
+		return sorted[lo]
+	}
+	frac := rank - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[hi]*frac
+}
+
+// lufs converts a mean-square energy sum to LUFS per ITU-R BS.1770.
+func lufs(z float64) float64 {
+	if z <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(z)
+}
+
+func linearToDB(amplitude float64) float64 {
+	if amplitude <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(amplitude)
+}
+
+// channelWeights returns the ITU-R BS.1770 channel weight for each channel
+// of a stream with this many channels, covering the common layouts this
+// engine tends to produce (mono, stereo, and 5.1 L/R/C/LFE/Ls/Rs); any
+// other layout falls back to 1.0 for every channel.
+func channelWeights(channels int) []float64 {
+	w := make([]float64, channels)
+	for i := range w {
+		w[i] = 1.0
+	}
+	if channels == 6 {
+		// L, R, C, LFE, Ls, Rs - BS.1770 excludes the LFE channel entirely
+		// and weights the surrounds at 1.41 (+1.5 dB).
+		w[3] = 0
+		w[4] = 1.41
+		w[5] = 1.41
+	}
+	return w
+}
+
+// truePeakOversampleFIR holds cubic-Lagrange interpolation coefficients
+// for 4x oversampling: row i estimates the sample i/4 of the way from
+// x[n-1] to x[n], from the four points x[n-2], x[n-1], x[n], x[n+1]. Row 0
+// is the trivial identity (the existing sample itself); this analyzer
+// only ever needs rows 1-3 plus the next call's row 0, but all four are
+// kept for a uniform per-sample interpolation loop in updatePeak. A short,
+// fixed 4-tap kernel like this is a much cheaper approximation of
+// BS.1770 Annex 2's 48-tap true-peak filter, adequate for a live meter
+// rather than a certified compliance measurement.
+var truePeakOversampleFIR = [4][4]float64{
+	{0, 1, 0, 0},
+	{-0.0546875, 0.8203125, 0.2734375, -0.0390625},
+	{-0.0625, 0.5625, 0.5625, -0.0625},
+	{-0.0390625, 0.2734375, 0.8203125, -0.0546875},
+}
+
+// kWeightingFilter is the two-stage BS.1770 K-weighting filter: a
+// high-shelf around 1681 Hz (approximating the head's acoustic effect)
+// cascaded with a high-pass around 38 Hz (approximating the outer and
+// middle ear's response), each a standard RBJ biquad with coefficients
+// recomputed per sample rate.
+type kWeightingFilter struct {
+	shelf    biquad
+	highpass biquad
+}
+
+func newKWeightingFilter(sampleRate float64) kWeightingFilter {
+	return kWeightingFilter{
+		shelf:    newHighShelfBiquad(sampleRate, 1681.0, 4.0),
+		highpass: newHighPassBiquad(sampleRate, 38.0, 1/math.Sqrt2),
+	}
+}
+
+func (f *kWeightingFilter) process(x float64) float64 {
+	return f.highpass.process(f.shelf.process(x))
+}
+
+// biquad is a direct-form-I, a0-normalized second-order IIR section.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	x1, x2     float64
+	y1, y2     float64
+}
+
+func (b *biquad) process(x float64) float64 {
+	y := b.b0*x + b.b1*b.x1 + b.b2*b.x2 - b.a1*b.y1 - b.a2*b.y2
+	b.x2, b.x1 = b.x1, x
+	b.y2, b.y1 = b.y1, y
+	return y
+}
+
+// newHighShelfBiquad builds an RBJ high-shelf biquad (shelf slope S=1)
+// boosting frequencies above freqHz by gainDB.
+func newHighShelfBiquad(sampleRate, freqHz, gainDB float64) biquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * freqHz / sampleRate
+	cosw0 := math.Cos(w0)
+	sinw0 := math.Sin(w0)
+	alpha := sinw0 / 2 * math.Sqrt(2) // the general RBJ shelf alpha has a (1/S - 1) term that's 0 at shelf slope S=1
+	sqrtA := math.Sqrt(a)
+
+	b0 := a * ((a + 1) + (a-1)*cosw0 + 2*sqrtA*alpha)
+	b1 := -2 * a * ((a - 1) + (a+1)*cosw0)
+	b2 := a * ((a + 1) + (a-1)*cosw0 - 2*sqrtA*alpha)
+	a0 := (a + 1) - (a-1)*cosw0 + 2*sqrtA*alpha
+	a1 := 2 * ((a - 1) - (a+1)*cosw0)
+	a2 := (a + 1) - (a-1)*cosw0 - 2*sqrtA*alpha
+
+	return biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// newHighPassBiquad builds an RBJ high-pass biquad with the given Q.
+func newHighPassBiquad(sampleRate, freqHz, q float64) biquad {
+	w0 := 2 * math.Pi * freqHz / sampleRate
+	cosw0 := math.Cos(w0)
+	sinw0 := math.Sin(w0)
+	alpha := sinw0 / (2 * q)
+
+	b0 := (1 + cosw0) / 2
+	b1 := -(1 + cosw0)
+	b2 := (1 + cosw0) / 2
+	a0 := 1 + alpha
+	a1 := -2 * cosw0
+	a2 := 1 - alpha
+
+	return biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
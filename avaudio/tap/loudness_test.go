@@ -0,0 +1,129 @@
+package tap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLUFSConversion(t *testing.T) {
+	if got := lufs(1.0); math.Abs(got-(-0.691)) > 1e-9 {
+		t.Errorf("lufs(1.0) = %v, want -0.691", got)
+	}
+	if got := lufs(0); !math.IsInf(got, -1) {
+		t.Errorf("lufs(0) = %v, want -Inf", got)
+	}
+}
+
+func TestWindowedLUFSUsesOnlyTheMostRecentBlocks(t *testing.T) {
+	if got := windowedLUFS(nil, momentaryWindowBlocks); !math.IsInf(got, -1) {
+		t.Errorf("windowedLUFS(nil, ...) = %v, want -Inf", got)
+	}
+
+	blocks := []float64{1, 1, 1, 1, 100} // only the last 4 should count for a 4-block window
+	got := windowedLUFS(blocks, 4)
+	want := lufs((1 + 1 + 1 + 100) / 4.0)
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("windowedLUFS = %v, want %v", got, want)
+	}
+}
+
+func TestIntegratedLUFSGatesOutSilenceAndOutliers(t *testing.T) {
+	if got := integratedLUFS(nil); !math.IsInf(got, -1) {
+		t.Errorf("integratedLUFS(nil) = %v, want -Inf", got)
+	}
+
+	// A block of near-silence (well below -70 LUFS) should be dropped by
+	// the absolute gate entirely, leaving only the two loud blocks.
+	blocks := []float64{1e-12, 1.0, 1.0}
+	got := integratedLUFS(blocks)
+	want := lufs(1.0)
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("integratedLUFS = %v, want %v (absolute gate should drop the silent block)", got, want)
+	}
+}
+
+func TestChannelWeightsIgnoresLFEAndBoostsSurrounds(t *testing.T) {
+	w := channelWeights(6)
+	want := []float64{1.0, 1.0, 1.0, 0, 1.41, 1.41}
+	for i := range want {
+		if w[i] != want[i] {
+			t.Errorf("channelWeights(6)[%d] = %v, want %v", i, w[i], want[i])
+		}
+	}
+
+	stereo := channelWeights(2)
+	if stereo[0] != 1.0 || stereo[1] != 1.0 {
+		t.Errorf("channelWeights(2) = %v, want [1.0 1.0]", stereo)
+	}
+}
+
+func TestBiquadPassesDCThroughHighShelfButAttenuatesHighPass(t *testing.T) {
+	hp := newHighPassBiquad(48000, 38, 1/math.Sqrt2)
+	var out float64
+	for i := 0; i < 1000; i++ {
+		out = hp.process(1.0) // a constant (DC) input should be attenuated to ~0 by a high-pass
+	}
+	if math.Abs(out) > 0.01 {
+		t.Errorf("high-pass settled output for DC input = %v, want near 0", out)
+	}
+}
+
+func TestLRAOfEmptyHistoryIsNegativeInfinity(t *testing.T) {
+	if got := lra(nil); !math.IsInf(got, -1) {
+		t.Errorf("lra(nil) = %v, want -Inf", got)
+	}
+}
+
+func TestLRAIsSpreadBetween10thAnd95thPercentile(t *testing.T) {
+	// 100 short-term readings evenly spaced from -40 to -20 LUFS: well above
+	// both gates, so LRA should reduce to the 95th-10th percentile spread of
+	// the raw values themselves.
+	history := make([]float64, 100)
+	for i := range history {
+		history[i] = -40 + float64(i)*(20.0/99)
+	}
+	got := lra(history)
+	want := percentile(history, lraHighPercentile) - percentile(history, lraLowPercentile)
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("lra = %v, want %v", got, want)
+	}
+	if got <= 0 {
+		t.Errorf("lra of a spread distribution = %v, want > 0", got)
+	}
+}
+
+func TestLRAGatesOutSilenceAndQuietOutliers(t *testing.T) {
+	// -120 is below -70 LUFS absolute, and -50 ends up more than 20 LU below
+	// the mean of what's left once -120 is gone - both should be dropped,
+	// leaving the identical -14 readings with zero spread.
+	history := []float64{-120, -50, -14, -14, -14, -14, -14}
+	got := lra(history)
+	if math.Abs(got) > 1e-6 {
+		t.Errorf("lra = %v, want ~0 once the outliers are gated out", got)
+	}
+}
+
+func TestPercentileInterpolatesBetweenNeighbors(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	if got := percentile(sorted, 0); got != 1 {
+		t.Errorf("percentile(0) = %v, want 1", got)
+	}
+	if got := percentile(sorted, 100); got != 5 {
+		t.Errorf("percentile(100) = %v, want 5", got)
+	}
+	if got := percentile(sorted, 50); got != 3 {
+		t.Errorf("percentile(50) = %v, want 3", got)
+	}
+}
+
+func TestUpdatePeakTracksPlainAndTruePeak(t *testing.T) {
+	a := newLoudnessAnalyzer()
+	a.truePeakHist = make([][3]float64, 1)
+	a.updatePeak(0, 1.0)
+	if a.peak != 1.0 {
+		t.Errorf("peak after a single full-scale sample = %v, want 1.0", a.peak)
+	}
+	if a.truePeak < 1.0 {
+		t.Errorf("truePeak after a single full-scale sample = %v, want >= 1.0", a.truePeak)
+	}
+}
@@ -0,0 +1,281 @@
+package tap
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// MeterConfig tunes the ballistics GetMeter's peak-hold indicator uses.
+// The zero value uses defaultPeakHold/defaultPeakDecayDBPerSec.
+type MeterConfig struct {
+	// PeakHold is how long a channel's peak-hold indicator stays pinned to
+	// its most recent peak before it starts decaying.
+	PeakHold time.Duration
+	// Decay is the peak-hold indicator's fall-off rate once PeakHold has
+	// elapsed, in dB/sec.
+	Decay float64
+}
+
+const (
+	defaultPeakHold          = 1500 * time.Millisecond
+	defaultPeakDecayDBPerSec = 20.0
+
+	// rmsAverageSeconds is RMSDb's exponential-average time constant - close
+	// to the ~300ms integration time an analog VU meter uses, rather than a
+	// true digital RMS over some fixed window.
+	rmsAverageSeconds = 0.3
+)
+
+func (c MeterConfig) withDefaults() MeterConfig {
+	if c.PeakHold <= 0 {
+		c.PeakHold = defaultPeakHold
+	}
+	if c.Decay <= 0 {
+		c.Decay = defaultPeakDecayDBPerSec
+	}
+	return c
+}
+
+// ChannelMeter is one channel's reading in a MeterSnapshot.
+type ChannelMeter struct {
+	PeakLinear float64 // instantaneous sample peak since the last GetMeter call's block, linear [0, 1+]
+	PeakHoldDb float64 // the peak-hold indicator: pinned at the channel's recent peak, decaying per MeterConfig.Decay once MeterConfig.PeakHold elapses
+	RMSDb      float64
+	TruePeakDb float64 // 4x oversampled inter-sample peak, per channel
+}
+
+// MeterSnapshot is a Tap's current metering state, as of the last block
+// GetMeter's analyzer has processed. Momentary/ShortTerm/Integrated are the
+// same ITU-R BS.1770 LUFS measurements GetLoudness reports - GetMeter reuses
+// that analyzer rather than running a second K-weighting filter bank over
+// the same signal - reported here too so a meter UI doesn't need to call
+// both GetMeter and GetLoudness to draw a full broadcast meter.
+type MeterSnapshot struct {
+	Channels   []ChannelMeter
+	Momentary  float64
+	ShortTerm  float64
+	Integrated float64
+}
+
+// GetMeter returns t's current peak/RMS/true-peak/LUFS meter reading,
+// lazily installing a dedicated Subscribe registration (with
+// defaultPeakHold/defaultPeakDecayDBPerSec ballistics) the first time it's
+// called, the same lazy-install pattern GetLoudness uses.
+func (t *Tap) GetMeter() (MeterSnapshot, error) {
+	t.meterMu.Lock()
+	defer t.meterMu.Unlock()
+	if err := t.ensureMeterLocked(MeterConfig{}); err != nil {
+		return MeterSnapshot{}, err
+	}
+	return t.meter.snapshot(), nil
+}
+
+// SetMeterConfig (re)configures the peak-hold ballistics GetMeter's
+// analyzer uses, installing it first if GetMeter hasn't been called yet.
+func (t *Tap) SetMeterConfig(config MeterConfig) error {
+	t.meterMu.Lock()
+	defer t.meterMu.Unlock()
+	if err := t.ensureMeterLocked(config); err != nil {
+		return err
+	}
+	t.meter.setConfig(config)
+	return nil
+}
+
+// ensureMeterLocked installs t's meter analyzer the first time GetMeter or
+// SetMeterConfig is called. Caller must hold t.meterMu.
+func (t *Tap) ensureMeterLocked(config MeterConfig) error {
+	if t.meter != nil {
+		return nil
+	}
+	if !t.installed {
+		return fmt.Errorf("tap is not installed")
+	}
+
+	analyzer := newMeterAnalyzer(config)
+	subID, err := t.Subscribe(analyzer.process, SubscribeOptions{Layout: ChannelLayoutPlanar})
+	if err != nil {
+		return err
+	}
+	t.meter = analyzer
+	t.meterSubID = subID
+	return nil
+}
+
+// stopMeterLocked tears down the meter analyzer's Subscribe registration,
+// if one was ever installed. Called from Remove so a GetMeter caller
+// doesn't leak a subscription past the tap's own lifetime.
+func (t *Tap) stopMeterLocked() {
+	t.meterMu.Lock()
+	active := t.meter != nil
+	subID := t.meterSubID
+	t.meter = nil
+	t.meterMu.Unlock()
+
+	if active {
+		t.Unsubscribe(subID)
+	}
+}
+
+// channelMeterState is one channel's running peak/RMS/true-peak state,
+// updated by meterAnalyzer.process (the Tap.Subscribe callback GetMeter
+// registers) and read back out by meterAnalyzer.snapshot.
+type channelMeterState struct {
+	blockPeak float64 // reset each process call; folded into peakEnvelope below
+
+	peakEnvelope  float64 // linear; GetMeter's PeakHoldDb ballistic
+	holdRemaining time.Duration
+
+	truePeak     float64
+	truePeakHist [3]float64
+
+	meanSq float64 // exponential moving average of sample^2, RMSDb's source
+}
+
+// meterAnalyzer is a single Tap's running peak/RMS/true-peak/LUFS state.
+// Like loudnessAnalyzer, everything here runs single-threaded on the
+// analyzer's own Subscribe pump goroutine - mu only guards the snapshot/
+// setConfig calls a caller makes from elsewhere. LUFS measurement is
+// delegated to an embedded loudnessAnalyzer rather than duplicated, since
+// the K-weighting/gating math in loudness.go already does exactly that.
+type meterAnalyzer struct {
+	mu sync.Mutex
+
+	config MeterConfig
+
+	configured bool
+	sampleRate float64
+	channels   int
+	rmsAlpha   float64
+
+	channelState []channelMeterState
+	loudness     *loudnessAnalyzer
+
+	lastTick time.Time
+}
+
+func newMeterAnalyzer(config MeterConfig) *meterAnalyzer {
+	return &meterAnalyzer{
+		config:   config.withDefaults(),
+		loudness: newLoudnessAnalyzer(),
+	}
+}
+
+func (a *meterAnalyzer) setConfig(config MeterConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.config = config.withDefaults()
+}
+
+// process is a tap.Subscribe callback (see SubscribeOptions.Layout), so pcm
+// is planar: all of channel 0's frames, then all of channel 1's, etc.
+func (a *meterAnalyzer) process(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if channels <= 0 || len(pcm) == 0 {
+		return
+	}
+	a.ensureConfigured(sampleRate, channels)
+	if channels != a.channels {
+		return // a reconfiguration mid-stream would desync the per-channel filter state; ignore rather than corrupt it
+	}
+
+	a.loudness.process(pcm, channels, sampleRate, hostTime)
+
+	frames := len(pcm) / channels
+	for ch := range a.channelState {
+		a.channelState[ch].blockPeak = 0
+	}
+
+	for frame := 0; frame < frames; frame++ {
+		for ch := 0; ch < channels; ch++ {
+			sample := float64(pcm[ch*frames+frame])
+			state := &a.channelState[ch]
+
+			if abs := math.Abs(sample); abs > state.blockPeak {
+				state.blockPeak = abs
+			}
+
+			hist := state.truePeakHist
+			for _, phase := range truePeakOversampleFIR {
+				interp := phase[0]*hist[0] + phase[1]*hist[1] + phase[2]*hist[2] + phase[3]*sample
+				if abs := math.Abs(interp); abs > state.truePeak {
+					state.truePeak = abs
+				}
+			}
+			state.truePeakHist = [3]float64{hist[1], hist[2], sample}
+
+			state.meanSq += a.rmsAlpha * (sample*sample - state.meanSq)
+		}
+	}
+
+	now := time.Now()
+	dt := now.Sub(a.lastTick)
+	a.lastTick = now
+	if dt <= 0 || dt > time.Second {
+		dt = 0 // first block, or a long gap (e.g. capture paused); don't apply a stale decay step
+	}
+
+	for ch := range a.channelState {
+		a.channelState[ch].advanceEnvelope(a.config, dt)
+	}
+}
+
+// advanceEnvelope folds this block's peak into s's peak-hold envelope,
+// decaying it by config.Decay dB/sec once config.PeakHold has elapsed since
+// the envelope was last pushed up.
+func (s *channelMeterState) advanceEnvelope(config MeterConfig, dt time.Duration) {
+	if s.blockPeak >= s.peakEnvelope {
+		s.peakEnvelope = s.blockPeak
+		s.holdRemaining = config.PeakHold
+		return
+	}
+
+	if s.holdRemaining > 0 {
+		s.holdRemaining -= dt
+		return
+	}
+
+	decayDb := config.Decay * dt.Seconds()
+	if decayDb > 0 {
+		s.peakEnvelope *= math.Pow(10, -decayDb/20)
+	}
+}
+
+func (a *meterAnalyzer) ensureConfigured(sampleRate float64, channels int) {
+	if a.configured {
+		return
+	}
+	a.sampleRate = sampleRate
+	a.channels = channels
+	a.rmsAlpha = 1 - math.Exp(-1/(sampleRate*rmsAverageSeconds))
+	a.channelState = make([]channelMeterState, channels)
+	a.configured = true
+}
+
+// snapshot computes the current MeterSnapshot without mutating any state
+// other than what process itself advances.
+func (a *meterAnalyzer) snapshot() MeterSnapshot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	loudness := a.loudness.snapshot()
+	snap := MeterSnapshot{
+		Channels:   make([]ChannelMeter, len(a.channelState)),
+		Momentary:  loudness.Momentary,
+		ShortTerm:  loudness.ShortTerm,
+		Integrated: loudness.Integrated,
+	}
+	for ch, state := range a.channelState {
+		snap.Channels[ch] = ChannelMeter{
+			PeakLinear: state.blockPeak,
+			PeakHoldDb: linearToDB(state.peakEnvelope),
+			RMSDb:      linearToDB(math.Sqrt(state.meanSq)),
+			TruePeakDb: linearToDB(state.truePeak),
+		}
+	}
+	return snap
+}
@@ -0,0 +1,74 @@
+package tap
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMeterConfigWithDefaultsFillsZeroValues(t *testing.T) {
+	got := MeterConfig{}.withDefaults()
+	if got.PeakHold != defaultPeakHold {
+		t.Errorf("PeakHold = %v, want %v", got.PeakHold, defaultPeakHold)
+	}
+	if got.Decay != defaultPeakDecayDBPerSec {
+		t.Errorf("Decay = %v, want %v", got.Decay, defaultPeakDecayDBPerSec)
+	}
+
+	custom := MeterConfig{PeakHold: 500 * time.Millisecond, Decay: 6.0}.withDefaults()
+	if custom.PeakHold != 500*time.Millisecond || custom.Decay != 6.0 {
+		t.Errorf("withDefaults changed an already-set MeterConfig: %+v", custom)
+	}
+}
+
+func TestMeterAnalyzerReportsPeakRMSAndTruePeak(t *testing.T) {
+	a := newMeterAnalyzer(MeterConfig{})
+
+	frames := 480
+	planar := make([]float32, 2*frames) // planar: all of channel 0's frames, then all of channel 1's
+	for i := 0; i < frames; i++ {
+		planar[i] = 0.5         // channel 0: constant 0.5
+		planar[frames+i] = -1.0 // channel 1: constant -1.0 (full scale)
+	}
+
+	a.process(planar, 2, 48000, 0)
+	snap := a.snapshot()
+
+	if len(snap.Channels) != 2 {
+		t.Fatalf("len(Channels) = %d, want 2", len(snap.Channels))
+	}
+	if got := snap.Channels[0].PeakLinear; math.Abs(got-0.5) > 1e-6 {
+		t.Errorf("channel 0 PeakLinear = %v, want 0.5", got)
+	}
+	if got := snap.Channels[1].PeakLinear; math.Abs(got-1.0) > 1e-6 {
+		t.Errorf("channel 1 PeakLinear = %v, want 1.0", got)
+	}
+	if snap.Channels[1].RMSDb <= snap.Channels[0].RMSDb {
+		t.Errorf("channel 1 (full scale) RMSDb = %v, want louder than channel 0's %v", snap.Channels[1].RMSDb, snap.Channels[0].RMSDb)
+	}
+	if math.IsInf(snap.Channels[0].TruePeakDb, -1) {
+		t.Errorf("channel 0 TruePeakDb = -Inf, want a finite reading after a non-silent block")
+	}
+}
+
+func TestChannelMeterStateHoldsThenDecaysPeakEnvelope(t *testing.T) {
+	config := MeterConfig{PeakHold: 100 * time.Millisecond, Decay: 20.0}.withDefaults()
+
+	s := &channelMeterState{blockPeak: 1.0}
+	s.advanceEnvelope(config, 0) // first block establishes the envelope
+	if s.peakEnvelope != 1.0 {
+		t.Fatalf("peakEnvelope after initial peak = %v, want 1.0", s.peakEnvelope)
+	}
+
+	s.blockPeak = 0 // signal drops to silence
+	s.advanceEnvelope(config, 50*time.Millisecond)
+	if s.peakEnvelope != 1.0 {
+		t.Errorf("peakEnvelope during the hold window = %v, want still pinned at 1.0", s.peakEnvelope)
+	}
+
+	s.advanceEnvelope(config, 200*time.Millisecond) // past PeakHold now
+	s.advanceEnvelope(config, 1*time.Second)         // a full second of decay at 20dB/sec
+	if s.peakEnvelope >= 1.0 {
+		t.Errorf("peakEnvelope after PeakHold elapsed and 1s of decay = %v, want < 1.0", s.peakEnvelope)
+	}
+}
@@ -0,0 +1,91 @@
+package tap
+
+import "fmt"
+
+// MetricsConfig selects which of Tap's optional per-buffer analyses run, so
+// a caller that only wants plain RMS/frame-count metering (GetMetrics)
+// doesn't pay for BS.1770 K-weighting or an FFT it never reads. It's the
+// explicit counterpart to GetLoudness/GetSpectrum's own lazy, first-call
+// installation (see ensureLoudnessLocked/ensureSpectrumLocked) - set it once
+// up front when an analysis's running cost, not just when it gets installed,
+// matters.
+type MetricsConfig struct {
+	// Loudness pre-installs (or, if false, tears down) the K-weighted
+	// loudness analyzer GetLoudness/ResetIntegrated use.
+	Loudness bool
+
+	// TruePeak enables GetLoudness's 4x-oversampled inter-sample true-peak
+	// tracking. With it false, LoudnessMetrics.TruePeak stops advancing
+	// (updatePeak skips the oversampling FIR) and reads math.Inf(-1) until
+	// re-enabled. Meaningless unless Loudness is also true.
+	TruePeak bool
+
+	// Spectrum pre-installs (or, if false, tears down) the spectrum
+	// analyzer GetSpectrum uses.
+	Spectrum bool
+
+	// FFTSize selects GetSpectrum's FFT size (and ring buffer length), in
+	// frames - 512, 1024, and 2048 are the sizes a real-time meter UI
+	// typically chooses between; <= 0 falls back to spectrumRingSize.
+	// Changing it after Spectrum is already installed tears down and
+	// reinstalls the analyzer, discarding whatever it had buffered.
+	FFTSize int
+}
+
+// SetMetricsConfig applies cfg: installing or tearing down the loudness and
+// spectrum analyzers to match Loudness/Spectrum, and applying TruePeak/
+// FFTSize to whichever analyzer ends up active. It's safe to call more than
+// once - each call reconciles the analyzers with cfg as given, it doesn't
+// merge with whatever an earlier call left behind.
+func (t *Tap) SetMetricsConfig(cfg MetricsConfig) error {
+	if !t.installed {
+		return fmt.Errorf("tap is not installed")
+	}
+
+	if err := t.applyLoudnessConfigLocked(cfg); err != nil {
+		return err
+	}
+	return t.applySpectrumConfigLocked(cfg)
+}
+
+// applyLoudnessConfigLocked installs or removes the loudness analyzer per
+// cfg.Loudness, and applies cfg.TruePeak to it either way - the analyzer
+// might already exist from an earlier plain GetLoudness call. stopLoudnessLocked
+// and ensureLoudnessLocked each manage t.loudnessMu themselves (the former
+// despite its name - it only expects the lock held across its own body, same
+// as ensureLoudnessLocked's callers in loudness.go), so this takes no lock of
+// its own.
+func (t *Tap) applyLoudnessConfigLocked(cfg MetricsConfig) error {
+	if !cfg.Loudness {
+		t.stopLoudnessLocked()
+		return nil
+	}
+
+	t.loudnessMu.Lock()
+	defer t.loudnessMu.Unlock()
+	if err := t.ensureLoudnessLocked(); err != nil {
+		return err
+	}
+	t.loudness.setTruePeakEnabled(cfg.TruePeak)
+	return nil
+}
+
+// applySpectrumConfigLocked installs or removes the spectrum analyzer per
+// cfg.Spectrum, recreating it if it's already installed at a different
+// FFTSize than cfg asks for. Like applyLoudnessConfigLocked, it leans on
+// stopSpectrumLocked/ensureSpectrumLocked to manage t.spectrumMu themselves.
+func (t *Tap) applySpectrumConfigLocked(cfg MetricsConfig) error {
+	if !cfg.Spectrum {
+		t.stopSpectrumLocked()
+		return nil
+	}
+
+	t.spectrumMu.Lock()
+	defer t.spectrumMu.Unlock()
+	sizeChanged := t.spectrum != nil && t.spectrumFFTSize != cfg.FFTSize
+	t.spectrumFFTSize = cfg.FFTSize
+	if sizeChanged {
+		t.spectrum = nil
+	}
+	return t.ensureSpectrumLocked()
+}
@@ -0,0 +1,722 @@
+package tap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RecordFormat selects the sample encoding RecordTo's file writer uses. It's
+// a separate type from RecorderFormat (recorder.go's CallbackTap/
+// ExtAudioFile-backed WAV/CAF writer) since RecordTo is a distinct, pure-Go
+// writer built on Tap.SubscribeChannel's push ring rather than ExtAudioFile.
+type RecordFormat int
+
+const (
+	RecordFormatWAVPCM16 RecordFormat = iota
+	RecordFormatWAVPCM24
+	RecordFormatWAVFloat32
+	RecordFormatCAF
+	RecordFormatFLAC
+)
+
+// RecorderOpts configures RecordTo.
+type RecorderOpts struct {
+	Format RecordFormat
+
+	// MaxDuration stops the recording automatically once reached; <= 0
+	// means no cap.
+	MaxDuration time.Duration
+
+	// SplitOnSilence starts a new, numbered file (e.g. mix.wav, then
+	// mix_002.wav) each time the signal drops below SilenceThresholdDB for
+	// silenceSplitHoldBlocks consecutive blocks.
+	SplitOnSilence bool
+
+	// SilenceThresholdDB is the RMS level, in dBFS, below which a block
+	// counts as silence for SplitOnSilence; 0 (the zero value) uses
+	// defaultSilenceThresholdDB, since a literal 0dB threshold would never
+	// be a meaningful choice for detecting silence.
+	SilenceThresholdDB float64
+
+	// MaxFileSize rolls the recording over into a new, numbered segment
+	// (see segmentPath) once the current segment's size would exceed it,
+	// the same rollover SplitOnSilence uses, rather than stopping the
+	// recording the way MaxDuration does; <= 0 means no cap.
+	MaxFileSize int64
+
+	// PreRoll buffers this much trailing audio from before Start is
+	// called, so the first segment still captures whatever happened just
+	// ahead of the operator deciding to start recording. Only meaningful
+	// on a FileRecorder NewFileRecorder created - RecordTo starts writing
+	// immediately and has nothing to pre-roll; <= 0 disables it.
+	PreRoll time.Duration
+}
+
+// RecordStats is what FileRecorder.Stop returns: the session's totals
+// across every segment SplitOnSilence may have split it into.
+type RecordStats struct {
+	BytesWritten int64
+	Duration     time.Duration
+	Peak         float32
+	RMS          float64
+}
+
+const (
+	defaultSilenceThresholdDB = -60.0
+	silenceSplitHoldBlocks    = 4
+)
+
+// FileRecorder drains a Tap straight to disk, via RecordTo. It's built on
+// SubscribeChannel (see subscribe_channel.go) rather than polling or a new
+// capture mechanism of its own, so the writer goroutine below is fed by the
+// same lock-free push ring every other channel-based Tap consumer uses -
+// the Core Audio thread never blocks on the disk write this does.
+type FileRecorder struct {
+	ch       <-chan TapBlock
+	stop     CancelFunc
+	stopOnce sync.Once
+	stopErr  error
+	done     chan struct{}
+
+	// flushReq carries Flush's request for run to flush the current
+	// segment's encoder without closing it; run replies on the channel it
+	// receives.
+	flushReq chan chan error
+
+	t    *Tap // source tap, kept only so Overruns can read its dropped-frame counter
+	opts RecorderOpts
+
+	basePath string // path with its extension stripped, for SplitOnSilence's numbered segments
+	ext      string
+
+	startTime   time.Time
+	hasDeadline bool
+	deadline    time.Time
+
+	// started gates run's writer path: RecordTo sets it to 1 before the
+	// goroutine even starts, so the first block opens a file right away;
+	// NewFileRecorder leaves it 0 until Start flips it, so run instead
+	// buffers into preRoll below. Read with atomic since Start can be
+	// called from any goroutine.
+	started int32
+
+	// framesWritten is updated by run's goroutine and read by FramesWritten
+	// from any goroutine (e.g. a CLI polling it alongside the RMS meter
+	// while recording is still in progress), so it's atomic rather than
+	// covered by the "only read after <-r.done" rule below.
+	framesWritten int64
+
+	// Everything below is only ever written by run's single goroutine, and
+	// only ever read by Stop after <-r.done - so no lock is needed.
+	running       bool // true once run has seen started flip and set startTime/deadline
+	preRoll       []TapBlock
+	encoder       fileEncoder
+	segment       int
+	bytesWritten  int64
+	segmentBytes  int64 // bytesWritten written to the current segment, reset on rollover
+	peak          float32
+	sumSquare     float64
+	sampleCount   int64
+	silenceBlocks int
+}
+
+// RecordTo starts recording t's PCM stream to path, lazily opening the
+// output file (and its sample rate/channel count) once the first TapBlock
+// arrives. Recording runs on its own goroutine until Stop is called or
+// MaxDuration elapses.
+func (t *Tap) RecordTo(path string, opts RecorderOpts) (*FileRecorder, error) {
+	r, err := newFileRecorder(t, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.started = 1
+	go r.run()
+	return r, nil
+}
+
+// NewFileRecorder is RecordTo's lazy-start counterpart: it subscribes to t
+// right away, so if opts.PreRoll is set it starts buffering a trailing ring
+// of audio immediately, but it doesn't open an output file or write
+// anything to disk until Start is called. That split - arm now, commit
+// later - is what lets the first segment still include audio captured
+// before the operator decided to start recording, rather than only what
+// arrives after.
+func NewFileRecorder(t *Tap, path string, opts RecorderOpts) (*FileRecorder, error) {
+	r, err := newFileRecorder(t, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	go r.run()
+	return r, nil
+}
+
+// newFileRecorder builds the shared state both RecordTo and NewFileRecorder
+// start from; only whether the caller sets r.started before starting run
+// tells them apart.
+func newFileRecorder(t *Tap, path string, opts RecorderOpts) (*FileRecorder, error) {
+	if !t.installed {
+		return nil, fmt.Errorf("tap is not installed")
+	}
+
+	ch, stop, err := t.SubscribeChannel(SubscribeOptions{Layout: ChannelLayoutInterleaved})
+	if err != nil {
+		return nil, err
+	}
+
+	ext := filepath.Ext(path)
+	return &FileRecorder{
+		ch:       ch,
+		stop:     stop,
+		done:     make(chan struct{}),
+		flushReq: make(chan chan error),
+		t:        t,
+		opts:     opts,
+		basePath: strings.TrimSuffix(path, ext),
+		ext:      ext,
+	}, nil
+}
+
+// Start begins writing to disk: any pre-roll blocks buffered since
+// NewFileRecorder are flushed into the first segment ahead of everything
+// captured from this point on, and MaxDuration (if set) starts counting
+// down from now. A FileRecorder RecordTo created is already started, and a
+// second Start call on either is a no-op.
+func (r *FileRecorder) Start() error {
+	atomic.StoreInt32(&r.started, 1)
+	return nil
+}
+
+// segmentPath returns path for the current segment: the original path for
+// segment 0, then basePath_002<ext>, basePath_003<ext>, and so on for each
+// SplitOnSilence split.
+func (r *FileRecorder) segmentPath() string {
+	if r.segment == 0 {
+		return r.basePath + r.ext
+	}
+	return fmt.Sprintf("%s_%03d%s", r.basePath, r.segment+1, r.ext)
+}
+
+// run is the writer goroutine both RecordTo and NewFileRecorder start: it
+// drains ch, buffering into preRoll instead of writing for as long as
+// started is still 0, then opens an encoder for the current segment on the
+// first block once started, writing every block to it, rotating to a new
+// segment once MaxFileSize is exceeded or (if SplitOnSilence is set) on a
+// sustained silence, and stopping once MaxDuration elapses. It also answers
+// Flush requests between blocks, since r.encoder is run's alone to touch.
+func (r *FileRecorder) run() {
+	defer close(r.done)
+	defer func() {
+		if r.encoder != nil {
+			r.encoder.close()
+		}
+	}()
+
+	for {
+		select {
+		case block, ok := <-r.ch:
+			if !ok {
+				return
+			}
+			if !r.handleBlock(block) {
+				return
+			}
+		case reply := <-r.flushReq:
+			if r.encoder != nil {
+				reply <- r.encoder.flush()
+			} else {
+				reply <- nil
+			}
+		}
+	}
+}
+
+// handleBlock applies one TapBlock and reports whether run should keep
+// going - false means a write or deadline failure already called
+// stopChannel and run should exit without waiting for ch to close.
+func (r *FileRecorder) handleBlock(block TapBlock) bool {
+	if atomic.LoadInt32(&r.started) == 0 {
+		r.bufferPreRoll(block)
+		return true
+	}
+	if !r.running {
+		r.running = true
+		r.startTime = time.Now()
+		if r.opts.MaxDuration > 0 {
+			r.hasDeadline = true
+			r.deadline = r.startTime.Add(r.opts.MaxDuration)
+		}
+	}
+	if r.hasDeadline && time.Now().After(r.deadline) {
+		r.stopChannel()
+		return false
+	}
+
+	if r.encoder == nil {
+		enc, err := newFileEncoder(r.segmentPath(), r.opts.Format, block.SampleRate, block.Channels)
+		if err != nil {
+			r.stopChannel()
+			return false
+		}
+		r.encoder = enc
+		for _, pre := range r.preRoll {
+			if err := r.writeBlock(pre); err != nil {
+				r.stopChannel()
+				return false
+			}
+		}
+		r.preRoll = nil
+	}
+
+	if err := r.writeBlock(block); err != nil {
+		r.stopChannel()
+		return false
+	}
+
+	if r.opts.MaxFileSize > 0 && r.segmentBytes >= r.opts.MaxFileSize {
+		r.rollSegment()
+	} else if r.opts.SplitOnSilence {
+		r.trackSilence(block.RMS)
+	}
+	return true
+}
+
+// bufferPreRoll appends block to the pre-roll ring and evicts the oldest
+// buffered blocks until no more than opts.PreRoll of audio remains - run's
+// own state, touched only here and when run later drains it into a freshly
+// opened encoder, so it needs no lock.
+func (r *FileRecorder) bufferPreRoll(block TapBlock) {
+	if r.opts.PreRoll <= 0 {
+		return
+	}
+	r.preRoll = append(r.preRoll, block)
+
+	var kept time.Duration
+	cut := 0
+	for i := len(r.preRoll) - 1; i >= 0; i-- {
+		b := r.preRoll[i]
+		if b.Channels <= 0 || b.SampleRate <= 0 {
+			continue
+		}
+		kept += time.Duration(float64(b.FrameCount) / b.SampleRate * float64(time.Second))
+		if kept > r.opts.PreRoll {
+			cut = i
+			break
+		}
+	}
+	if cut > 0 {
+		r.preRoll = r.preRoll[cut:]
+	}
+}
+
+// writeBlock encodes block to the current segment and folds it into the
+// session's running stats (bytes, peak, RMS, frame count).
+func (r *FileRecorder) writeBlock(block TapBlock) error {
+	n, err := r.encoder.writeSamples(block.PCM)
+	if err != nil {
+		return err
+	}
+	r.bytesWritten += int64(n)
+	r.segmentBytes += int64(n)
+	if block.Peak > r.peak {
+		r.peak = block.Peak
+	}
+	samples := float64(len(block.PCM))
+	r.sumSquare += block.RMS * block.RMS * samples
+	r.sampleCount += int64(samples)
+	if block.Channels > 0 {
+		atomic.AddInt64(&r.framesWritten, int64(len(block.PCM))/int64(block.Channels))
+	}
+	return nil
+}
+
+// rollSegment closes the current segment's encoder and advances to the
+// next numbered one - MaxFileSize's rollover, and trackSilence's below.
+func (r *FileRecorder) rollSegment() {
+	if r.encoder != nil {
+		r.encoder.close()
+		r.encoder = nil
+	}
+	r.segmentBytes = 0
+	r.segment++
+}
+
+// trackSilence closes the current segment and starts a new one once
+// silenceSplitHoldBlocks consecutive blocks have fallen below the silence
+// threshold.
+func (r *FileRecorder) trackSilence(blockRMS float64) {
+	threshold := r.opts.SilenceThresholdDB
+	if threshold == 0 {
+		threshold = defaultSilenceThresholdDB
+	}
+
+	if linearToDB(blockRMS) < threshold {
+		r.silenceBlocks++
+	} else {
+		r.silenceBlocks = 0
+	}
+
+	if r.silenceBlocks >= silenceSplitHoldBlocks {
+		r.silenceBlocks = 0
+		r.rollSegment()
+	}
+}
+
+// stopChannel unsubscribes and closes r.ch exactly once, however many of
+// run's early-return paths (or a concurrent Stop call) try to trigger it.
+func (r *FileRecorder) stopChannel() error {
+	r.stopOnce.Do(func() {
+		r.stopErr = r.stop()
+	})
+	return r.stopErr
+}
+
+// Stop ends recording and closes every open segment, blocking until the
+// writer goroutine has finished, then returns the session's totals.
+func (r *FileRecorder) Stop() (RecordStats, error) {
+	err := r.stopChannel()
+	<-r.done
+
+	var rms float64
+	if r.sampleCount > 0 {
+		rms = math.Sqrt(r.sumSquare / float64(r.sampleCount))
+	}
+	stats := RecordStats{
+		BytesWritten: r.bytesWritten,
+		Duration:     time.Since(r.startTime),
+		Peak:         r.peak,
+		RMS:          rms,
+	}
+	return stats, err
+}
+
+// Flush forces any samples buffered in the current segment's encoder out to
+// disk without closing it or stopping recording - useful for a caller that
+// wants to read back what's been captured so far (e.g. to preview a
+// recording in progress) without waiting for Stop. It's a no-op before the
+// first segment has been opened. The actual flush happens on run's
+// goroutine, which is the only one allowed to touch r.encoder, so Flush
+// asks for it over flushReq rather than reaching in directly.
+func (r *FileRecorder) Flush() error {
+	reply := make(chan error, 1)
+	select {
+	case r.flushReq <- reply:
+	case <-r.done:
+		return nil
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-r.done:
+		return nil
+	}
+}
+
+// FramesWritten reports the number of frames encoded so far, safe to call
+// while recording is still in progress (e.g. alongside a live RMS meter).
+func (r *FileRecorder) FramesWritten() int64 {
+	return atomic.LoadInt64(&r.framesWritten)
+}
+
+// Overruns reports how many blocks the source Tap has dropped because a
+// consumer (this recorder's SubscribeChannel ring, or another one sharing
+// the Tap) fell behind the render thread - see Tap.GetMetrics. It's a
+// cumulative count across the whole Tap, not just this recording, since
+// that's the only overrun counter the Tap exposes.
+func (r *FileRecorder) Overruns() uint64 {
+	metrics, err := r.t.GetMetrics()
+	if err != nil {
+		return 0
+	}
+	return metrics.DroppedFrames
+}
+
+// fileEncoder is the plug-in point RecordTo's writer goroutine uses for
+// each on-disk format - newWAVEncoder and newCAFEncoder are the
+// implementations in this tree today; FLAC (or any future format) only
+// needs to satisfy this to be usable from RecordTo.
+type fileEncoder interface {
+	// writeSamples encodes interleaved (already in the file's channel
+	// order) and appends it to the file, returning the number of bytes
+	// written.
+	writeSamples(interleaved []float32) (int, error)
+	// flush pushes any buffered-but-unwritten samples out to disk without
+	// closing the file or patching its (still-placeholder) header sizes.
+	flush() error
+	// close finalizes the file (patching any header fields that depend on
+	// the final size) and closes it.
+	close() error
+}
+
+// newFileEncoder builds the fileEncoder for format, opening path for
+// writing at sampleRate/channels.
+func newFileEncoder(path string, format RecordFormat, sampleRate float64, channels int) (fileEncoder, error) {
+	switch format {
+	case RecordFormatWAVPCM16, RecordFormatWAVPCM24, RecordFormatWAVFloat32:
+		return newWAVEncoder(path, format, sampleRate, channels)
+	case RecordFormatCAF:
+		return newCAFEncoder(path, sampleRate, channels)
+	case RecordFormatFLAC:
+		return nil, fmt.Errorf("RecordFormatFLAC has no fileEncoder implementation in this tree yet")
+	default:
+		return nil, fmt.Errorf("unknown RecordFormat %v", format)
+	}
+}
+
+// wavEncoder is a minimal, dependency-free RIFF/WAVE writer: it writes a
+// 44-byte header with placeholder chunk sizes up front, appends encoded
+// samples as they arrive, and patches the real sizes into the header on
+// close - the header fields as RecordTo gets rolling ExtAudioFile would
+// otherwise compute for us.
+type wavEncoder struct {
+	file *os.File
+	w    *bufio.Writer
+
+	format         RecordFormat
+	channels       int
+	bytesPerSample int
+
+	dataBytes int64
+	scratch   [4]byte // reused per-sample encode buffer
+}
+
+func newWAVEncoder(path string, format RecordFormat, sampleRate float64, channels int) (*wavEncoder, error) {
+	if channels <= 0 {
+		channels = 1
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	audioFormat := uint16(1) // PCM
+	bitsPerSample := 16
+	switch format {
+	case RecordFormatWAVPCM24:
+		bitsPerSample = 24
+	case RecordFormatWAVFloat32:
+		bitsPerSample = 32
+		audioFormat = 3 // IEEE float
+	}
+
+	e := &wavEncoder{
+		file:           f,
+		w:              bufio.NewWriter(f),
+		format:         format,
+		channels:       channels,
+		bytesPerSample: bitsPerSample / 8,
+	}
+	if err := e.writeHeaderPlaceholder(audioFormat, bitsPerSample, sampleRate); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *wavEncoder) writeHeaderPlaceholder(audioFormat uint16, bitsPerSample int, sampleRate float64) error {
+	blockAlign := e.channels * e.bytesPerSample
+	byteRate := int(sampleRate) * blockAlign
+
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], audioFormat)
+	binary.LittleEndian.PutUint16(header[22:24], uint16(e.channels))
+	binary.LittleEndian.PutUint32(header[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(byteRate))
+	binary.LittleEndian.PutUint16(header[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(header[34:36], uint16(bitsPerSample))
+	copy(header[36:40], "data")
+	_, err := e.w.Write(header)
+	return err
+}
+
+func (e *wavEncoder) writeSamples(interleaved []float32) (int, error) {
+	n := 0
+	for _, s := range interleaved {
+		var sampleBytes []byte
+		switch e.format {
+		case RecordFormatWAVFloat32:
+			binary.LittleEndian.PutUint32(e.scratch[:4], math.Float32bits(s))
+			sampleBytes = e.scratch[:4]
+		case RecordFormatWAVPCM24:
+			v := clampToIntSample(s, 1<<23-1)
+			e.scratch[0] = byte(v)
+			e.scratch[1] = byte(v >> 8)
+			e.scratch[2] = byte(v >> 16)
+			sampleBytes = e.scratch[:3]
+		default: // RecordFormatWAVPCM16
+			v := clampToIntSample(s, 1<<15-1)
+			binary.LittleEndian.PutUint16(e.scratch[:2], uint16(int16(v)))
+			sampleBytes = e.scratch[:2]
+		}
+		if _, err := e.w.Write(sampleBytes); err != nil {
+			return n, err
+		}
+		n += len(sampleBytes)
+	}
+	e.dataBytes += int64(n)
+	return n, nil
+}
+
+// clampToIntSample clamps a float32 sample to [-1, 1] and scales it to an
+// integer in [-maxVal-1, maxVal].
+func clampToIntSample(s float32, maxVal int) int {
+	f := float64(s)
+	if f > 1 {
+		f = 1
+	}
+	if f < -1 {
+		f = -1
+	}
+	return int(math.Round(f * float64(maxVal)))
+}
+
+func (e *wavEncoder) flush() error {
+	return e.w.Flush()
+}
+
+func (e *wavEncoder) close() error {
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+
+	riffSize := uint32(36 + e.dataBytes)
+	if _, err := e.file.WriteAt(uint32ToBytes(riffSize), 4); err != nil {
+		return err
+	}
+	if _, err := e.file.WriteAt(uint32ToBytes(uint32(e.dataBytes)), 40); err != nil {
+		return err
+	}
+	return e.file.Close()
+}
+
+func uint32ToBytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+// cafDataChunkSizeOffset is the byte offset of the 'data' chunk's 64-bit
+// size field within the file: 8 bytes of caff file header, then a fixed
+// 44-byte 'desc' chunk (12-byte chunk header + 32-byte CAFAudioDescription),
+// then the 'data' chunk's own 12-byte chunk header (4-byte type + 8-byte
+// size) - the size field itself starts 4 bytes into that.
+const cafDataChunkSizeOffset = 8 + 44 + 4
+
+// cafEncoder is a minimal, dependency-free CAF (Core Audio File) writer: it
+// writes the caff file header plus a fixed-size desc chunk up front, then a
+// data chunk with a placeholder 64-bit size (wide enough that a recording
+// past 4GB still patches correctly, unlike WAV's 32-bit sizes), and patches
+// the real size into that field on close - the CAF counterpart to
+// wavEncoder above. Always encodes 16-bit linear PCM; RecordFormatCAF
+// doesn't carry a bit depth of its own the way the WAV formats do.
+type cafEncoder struct {
+	file *os.File
+	w    *bufio.Writer
+
+	channels  int
+	dataBytes int64
+	scratch   [2]byte
+}
+
+// cafFormatIDLPCM is CAF's four-character formatID for linear PCM.
+const cafFormatIDLPCM = "lpcm"
+
+const (
+	cafLinearPCMFlagIsFloat        uint32 = 1 << 0
+	cafLinearPCMFlagIsLittleEndian uint32 = 1 << 1
+)
+
+func newCAFEncoder(path string, sampleRate float64, channels int) (*cafEncoder, error) {
+	if channels <= 0 {
+		channels = 1
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &cafEncoder{
+		file:     f,
+		w:        bufio.NewWriter(f),
+		channels: channels,
+	}
+	if err := e.writeHeader(sampleRate); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *cafEncoder) writeHeader(sampleRate float64) error {
+	const bitsPerChannel = 16
+	blockAlign := e.channels * (bitsPerChannel / 8)
+
+	var hdr bytes.Buffer
+	hdr.WriteString("caff")
+	binary.Write(&hdr, binary.BigEndian, uint16(1)) // version
+	binary.Write(&hdr, binary.BigEndian, uint16(0)) // flags
+
+	hdr.WriteString("desc")
+	binary.Write(&hdr, binary.BigEndian, int64(32)) // desc chunk size, fixed
+	binary.Write(&hdr, binary.BigEndian, sampleRate)
+	hdr.WriteString(cafFormatIDLPCM)
+	binary.Write(&hdr, binary.BigEndian, uint32(0)) // formatFlags: big-endian integer PCM, matching writeSamples below
+	binary.Write(&hdr, binary.BigEndian, uint32(blockAlign))
+	binary.Write(&hdr, binary.BigEndian, uint32(1)) // framesPerPacket
+	binary.Write(&hdr, binary.BigEndian, uint32(e.channels))
+	binary.Write(&hdr, binary.BigEndian, uint32(bitsPerChannel))
+
+	hdr.WriteString("data")
+	binary.Write(&hdr, binary.BigEndian, int64(0)) // placeholder, patched in close
+	binary.Write(&hdr, binary.BigEndian, uint32(0)) // mEditCount
+
+	_, err := e.w.Write(hdr.Bytes())
+	return err
+}
+
+func (e *cafEncoder) writeSamples(interleaved []float32) (int, error) {
+	n := 0
+	for _, s := range interleaved {
+		v := clampToIntSample(s, 1<<15-1)
+		binary.BigEndian.PutUint16(e.scratch[:2], uint16(int16(v)))
+		if _, err := e.w.Write(e.scratch[:2]); err != nil {
+			return n, err
+		}
+		n += 2
+	}
+	e.dataBytes += int64(n)
+	return n, nil
+}
+
+func (e *cafEncoder) flush() error {
+	return e.w.Flush()
+}
+
+func (e *cafEncoder) close() error {
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+
+	// The chunk's declared size covers the 4-byte mEditCount plus the PCM
+	// data that follows it.
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(4+e.dataBytes))
+	if _, err := e.file.WriteAt(size, cafDataChunkSizeOffset); err != nil {
+		return err
+	}
+	return e.file.Close()
+}
@@ -0,0 +1,170 @@
+package tap
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClampToIntSample(t *testing.T) {
+	if got := clampToIntSample(2.0, 32767); got != 32767 {
+		t.Errorf("clampToIntSample(2.0, 32767) = %v, want clamped to 32767", got)
+	}
+	if got := clampToIntSample(-2.0, 32767); got != -32767 {
+		t.Errorf("clampToIntSample(-2.0, 32767) = %v, want clamped to -32767", got)
+	}
+	if got := clampToIntSample(0.5, 32767); got != 16384 {
+		t.Errorf("clampToIntSample(0.5, 32767) = %v, want 16384", got)
+	}
+}
+
+func TestWAVEncoderPCM16RoundTripsSamplesAndPatchesHeaderSizes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	enc, err := newWAVEncoder(path, RecordFormatWAVPCM16, 48000, 1)
+	if err != nil {
+		t.Fatalf("newWAVEncoder failed: %v", err)
+	}
+
+	samples := []float32{1.0, -1.0, 0.0}
+	n, err := enc.writeSamples(samples)
+	if err != nil {
+		t.Fatalf("writeSamples failed: %v", err)
+	}
+	if n != len(samples)*2 {
+		t.Errorf("writeSamples returned %d bytes, want %d", n, len(samples)*2)
+	}
+	if err := enc.close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(data) != 44+len(samples)*2 {
+		t.Fatalf("file length = %d, want %d", len(data), 44+len(samples)*2)
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		t.Errorf("missing RIFF/WAVE magic: %q", data[:12])
+	}
+
+	riffSize := binary.LittleEndian.Uint32(data[4:8])
+	if want := uint32(36 + len(samples)*2); riffSize != want {
+		t.Errorf("RIFF chunk size = %d, want %d", riffSize, want)
+	}
+	dataSize := binary.LittleEndian.Uint32(data[40:44])
+	if want := uint32(len(samples) * 2); dataSize != want {
+		t.Errorf("data chunk size = %d, want %d", dataSize, want)
+	}
+
+	pcm := data[44:]
+	if got := int16(binary.LittleEndian.Uint16(pcm[0:2])); got != 32767 {
+		t.Errorf("sample[0] = %d, want 32767", got)
+	}
+	if got := int16(binary.LittleEndian.Uint16(pcm[2:4])); got != -32767 {
+		t.Errorf("sample[1] = %d, want -32767", got)
+	}
+}
+
+func TestNewFileEncoderRejectsUnimplementedFormats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out")
+	if _, err := newFileEncoder(path, RecordFormatFLAC, 48000, 2); err == nil {
+		t.Error("expected RecordFormatFLAC to be rejected (no fileEncoder implementation yet)")
+	}
+}
+
+func TestCAFEncoderPCM16RoundTripsSamplesAndPatchesDataSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.caf")
+	enc, err := newCAFEncoder(path, 48000, 1)
+	if err != nil {
+		t.Fatalf("newCAFEncoder failed: %v", err)
+	}
+
+	samples := []float32{1.0, -1.0, 0.0}
+	n, err := enc.writeSamples(samples)
+	if err != nil {
+		t.Fatalf("writeSamples failed: %v", err)
+	}
+	if n != len(samples)*2 {
+		t.Errorf("writeSamples returned %d bytes, want %d", n, len(samples)*2)
+	}
+	if err := enc.close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(data[0:4]) != "caff" {
+		t.Errorf("missing caff magic: %q", data[:4])
+	}
+	if string(data[8:12]) != "desc" {
+		t.Errorf("missing desc chunk: %q", data[8:12])
+	}
+	if string(data[52:56]) != "data" {
+		t.Errorf("missing data chunk: %q", data[52:56])
+	}
+
+	dataChunkSize := binary.BigEndian.Uint64(data[56:64])
+	if want := uint64(4 + len(samples)*2); dataChunkSize != want {
+		t.Errorf("data chunk size = %d, want %d", dataChunkSize, want)
+	}
+
+	pcm := data[68:]
+	if got := int16(binary.BigEndian.Uint16(pcm[0:2])); got != 32767 {
+		t.Errorf("sample[0] = %d, want 32767", got)
+	}
+	if got := int16(binary.BigEndian.Uint16(pcm[2:4])); got != -32767 {
+		t.Errorf("sample[1] = %d, want -32767", got)
+	}
+}
+
+func TestSegmentPathNumbersSplitsAfterTheFirst(t *testing.T) {
+	r := &FileRecorder{basePath: "/tmp/mix", ext: ".wav"}
+	if got := r.segmentPath(); got != "/tmp/mix.wav" {
+		t.Errorf("segmentPath() for segment 0 = %q, want /tmp/mix.wav", got)
+	}
+	r.segment = 1
+	if got := r.segmentPath(); got != "/tmp/mix_002.wav" {
+		t.Errorf("segmentPath() for segment 1 = %q, want /tmp/mix_002.wav", got)
+	}
+}
+
+func TestRecordToRejectsUninstalledTap(t *testing.T) {
+	notInstalled := &Tap{}
+	if _, err := notInstalled.RecordTo(filepath.Join(t.TempDir(), "out.wav"), RecorderOpts{}); err == nil {
+		t.Fatal("expected RecordTo on an uninstalled tap to fail")
+	}
+}
+
+func TestNewFileRecorderRejectsUninstalledTap(t *testing.T) {
+	notInstalled := &Tap{}
+	if _, err := NewFileRecorder(notInstalled, filepath.Join(t.TempDir(), "out.wav"), RecorderOpts{}); err == nil {
+		t.Fatal("expected NewFileRecorder on an uninstalled tap to fail")
+	}
+}
+
+func TestBufferPreRollEvictsBlocksOlderThanThePreRollWindow(t *testing.T) {
+	r := &FileRecorder{opts: RecorderOpts{PreRoll: 100 * time.Millisecond}}
+
+	// Each block is 48 frames at 48kHz, i.e. 1ms; 250 of them is well past
+	// the 100ms window, so only the trailing ~100 should survive.
+	for i := 0; i < 250; i++ {
+		r.bufferPreRoll(TapBlock{Channels: 1, SampleRate: 48000, FrameCount: 48})
+	}
+
+	if len(r.preRoll) == 0 || len(r.preRoll) > 101 {
+		t.Errorf("len(preRoll) = %v, want a small tail around the 100ms window", len(r.preRoll))
+	}
+}
+
+func TestBufferPreRollDisabledByDefaultKeepsNothing(t *testing.T) {
+	r := &FileRecorder{}
+	r.bufferPreRoll(TapBlock{Channels: 1, SampleRate: 48000, FrameCount: 48})
+	if len(r.preRoll) != 0 {
+		t.Errorf("len(preRoll) = %v, want 0 with PreRoll disabled", len(r.preRoll))
+	}
+}
@@ -0,0 +1,244 @@
+package tap
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AudioToolbox -framework Foundation
+#include <stdlib.h>
+
+// Declared here; implemented in native/tap_recorder.m once the ExtAudioFile
+// wrapper it calls through to exists (see InstallRecorder's doc comment).
+// recorder_open creates outFile for writing sampleRate/channelCount PCM
+// float32 at path, in the container named by format ("wav" or "caf"),
+// converting from the tapped node's own format if it differs.
+const char* recorder_open(const char* path, const char* format, double sampleRate, int channelCount, void** outFile);
+const char* recorder_write(void* file, const float* interleaved, int frameCount);
+const char* recorder_close(void* file);
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// RecorderFormat selects the on-disk container InstallRecorder writes.
+type RecorderFormat int
+
+const (
+	RecorderFormatWAV RecorderFormat = iota
+	RecorderFormatCAF
+)
+
+func (f RecorderFormat) cName() string {
+	if f == RecorderFormatCAF {
+		return "caf"
+	}
+	return "wav"
+}
+
+// RecorderStats is a Recorder's running counters, read with Stats() for a
+// health check or a UI meter - the disk-writing counterpart to
+// CallbackTap's TapStats.
+type RecorderStats struct {
+	FramesWritten uint64
+	Peak          float32
+	RMS           float64
+	Overruns      uint64
+}
+
+// recorderBlock is one captured buffer queued for the flush goroutine to
+// write to disk.
+type recorderBlock struct {
+	samples []float32 // interleaved
+	frames  int
+}
+
+// Recorder captures the stream from a CallbackTap and writes it to path as
+// WAV or CAF via ExtAudioFile, on a background goroutine separate from the
+// tap's own drain loop so a slow disk write never backs up audio capture -
+// see InstallRecorder.
+type Recorder struct {
+	source *CallbackTap
+	format RecorderFormat
+	path   string
+
+	queue  chan recorderBlock
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	framesWritten uint64
+	overruns      uint64
+	peakBits      uint32 // math.Float32bits(peak), updated with a CAS loop
+
+	sumMu     sync.Mutex
+	sumSquare float64
+
+	file unsafe.Pointer // the native ExtAudioFileRef, opaque on the Go side
+}
+
+// InstallRecorder installs a CallbackTap on nodePtr/busIndex (so it composes
+// with any other tap already on that node/bus - a meter and a recorder can
+// share one, each with its own key) and starts writing every captured
+// buffer to path as format, converting sample format/channel layout to
+// match the file if the node's own output format differs. ringBufferFrames
+// sizes the queue between capture and disk write in frames; a write that
+// can't keep up drops the oldest-to-arrive block rather than blocking the
+// tap's drain goroutine (and so, transitively, the audio thread CallbackTap
+// already protects), counting it in Stats().Overruns.
+//
+// recorder_open/write/close aren't wired up in this tree yet - native/
+// doesn't have a tap_recorder.m defining them (the same gap InstallCallbackTap
+// documents for tap_install_callback) - so InstallRecorder fails at the
+// Open stage until that file exists; everything else here (the queue,
+// the flush loop, overrun counting, Stats) is real and independently usable
+// once it does.
+func InstallRecorder(enginePtr, nodePtr unsafe.Pointer, busIndex int, path string, format RecorderFormat, ringBufferFrames int) (*Recorder, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if ringBufferFrames <= 0 {
+		return nil, fmt.Errorf("ringBufferFrames must be positive")
+	}
+
+	r := &Recorder{
+		format: format,
+		path:   path,
+		queue:  make(chan recorderBlock, ringBufferFrames),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+
+	source, err := InstallCallbackTap(enginePtr, nodePtr, busIndex, 512, TapFormatInterleavedFloat32, r.capture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install capture tap: %w", err)
+	}
+	r.source = source
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+	cFormat := C.CString(format.cName())
+	defer C.free(unsafe.Pointer(cFormat))
+
+	var file unsafe.Pointer
+	errorStr := C.recorder_open(cPath, cFormat, 0, 0, (*unsafe.Pointer)(unsafe.Pointer(&file)))
+	if errorStr != nil {
+		_ = source.Remove()
+		return nil, errors.New(C.GoString(errorStr))
+	}
+	r.file = file
+
+	go r.flushLoop()
+	return r, nil
+}
+
+// capture is the CallbackTap callback: it queues buf for the flush goroutine,
+// dropping and counting it as an overrun if the queue is already full rather
+// than blocking the tap's drain loop.
+func (r *Recorder) capture(buf TapBuffer) {
+	block := recorderBlock{samples: buf.Float32Data, frames: buf.Frames}
+	select {
+	case r.queue <- block:
+	default:
+		atomic.AddUint64(&r.overruns, 1)
+	}
+}
+
+// flushLoop drains the queue and writes each block to disk until Stop
+// closes stopCh, then drains whatever is left before closing file.
+func (r *Recorder) flushLoop() {
+	defer close(r.doneCh)
+	for {
+		select {
+		case block := <-r.queue:
+			r.writeBlock(block)
+		case <-r.stopCh:
+			for {
+				select {
+				case block := <-r.queue:
+					r.writeBlock(block)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) writeBlock(block recorderBlock) {
+	if len(block.samples) > 0 {
+		errorStr := C.recorder_write(r.file, (*C.float)(unsafe.Pointer(&block.samples[0])), C.int(block.frames))
+		if errorStr != nil {
+			return
+		}
+	}
+
+	atomic.AddUint64(&r.framesWritten, uint64(block.frames))
+	r.trackLevels(block.samples)
+}
+
+// trackLevels updates peak (via a lock-free CAS loop) and accumulates the
+// running sum-of-squares RMS uses, from one block's samples.
+func (r *Recorder) trackLevels(samples []float32) {
+	for _, s := range samples {
+		abs := s
+		if abs < 0 {
+			abs = -abs
+		}
+		for {
+			old := atomic.LoadUint32(&r.peakBits)
+			if abs <= math.Float32frombits(old) {
+				break
+			}
+			if atomic.CompareAndSwapUint32(&r.peakBits, old, math.Float32bits(abs)) {
+				break
+			}
+		}
+	}
+
+	r.sumMu.Lock()
+	for _, s := range samples {
+		r.sumSquare += float64(s) * float64(s)
+	}
+	r.sumMu.Unlock()
+}
+
+// Stats returns the recorder's running counters.
+func (r *Recorder) Stats() RecorderStats {
+	written := atomic.LoadUint64(&r.framesWritten)
+
+	r.sumMu.Lock()
+	sumSquare := r.sumSquare
+	r.sumMu.Unlock()
+
+	var rms float64
+	if written > 0 {
+		rms = math.Sqrt(sumSquare / float64(written))
+	}
+
+	return RecorderStats{
+		FramesWritten: written,
+		Peak:          math.Float32frombits(atomic.LoadUint32(&r.peakBits)),
+		RMS:           rms,
+		Overruns:      atomic.LoadUint64(&r.overruns),
+	}
+}
+
+// Stop ends capture and closes the file, blocking until every queued block
+// has been written.
+func (r *Recorder) Stop() error {
+	if err := r.source.Remove(); err != nil {
+		return fmt.Errorf("failed to remove capture tap: %w", err)
+	}
+
+	close(r.stopCh)
+	<-r.doneCh
+
+	errorStr := C.recorder_close(r.file)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package tap
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+func TestRecorderCaptureDropsOnQueueFull(t *testing.T) {
+	r := &Recorder{queue: make(chan recorderBlock, 1)}
+
+	r.capture(TapBuffer{Float32Data: []float32{0.1}, Frames: 1})
+	if r.Stats().Overruns != 0 {
+		t.Fatalf("expected no overrun for the first block, got %d", r.Stats().Overruns)
+	}
+
+	r.capture(TapBuffer{Float32Data: []float32{0.2}, Frames: 1})
+	if overruns := r.Stats().Overruns; overruns != 1 {
+		t.Fatalf("expected one overrun once the queue is full, got %d", overruns)
+	}
+}
+
+func TestRecorderTrackLevelsComputesPeakAndRMS(t *testing.T) {
+	r := &Recorder{}
+
+	r.trackLevels([]float32{0.5, -0.8, 0.2})
+	atomic.StoreUint64(&r.framesWritten, 3)
+
+	stats := r.Stats()
+	if stats.Peak != 0.8 {
+		t.Errorf("expected peak 0.8, got %f", stats.Peak)
+	}
+
+	wantRMS := math.Sqrt((0.25 + 0.64 + 0.04) / 3)
+	if diff := stats.RMS - wantRMS; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected RMS %f, got %f", wantRMS, stats.RMS)
+	}
+}
+
+func TestInstallRecorderRejectsInvalidArgs(t *testing.T) {
+	valid := unsafe.Pointer(&struct{}{})
+
+	if _, err := InstallRecorder(valid, valid, 0, "", RecorderFormatWAV, 64); err == nil {
+		t.Fatal("expected an error for an empty path")
+	}
+	if _, err := InstallRecorder(valid, valid, 0, "/tmp/out.wav", RecorderFormatWAV, 0); err == nil {
+		t.Fatal("expected an error for a non-positive ringBufferFrames")
+	}
+}
@@ -0,0 +1,281 @@
+package tap
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+	"sync"
+)
+
+// SpectrumPeak is one local maximum in a spectrum analysis, as returned in
+// SpectrumMetrics.PeakBins, strongest first.
+type SpectrumPeak struct {
+	Hz  float32
+	Mag float32
+}
+
+// SpectrumMetrics is a snapshot of a Tap's short-time spectral analysis
+// over its most recent spectrumRingSize frames, as of the last call to
+// GetSpectrum - unlike LoudnessMetrics, nothing here is maintained
+// incrementally, since the FFT only runs when a caller actually asks for
+// it (see GetSpectrum).
+type SpectrumMetrics struct {
+	SpectralCentroid  float64 // Hz, the magnitude-weighted mean frequency
+	DominantFrequency float64 // Hz, the single strongest bin
+	PeakBins          []SpectrumPeak
+
+	// Magnitudes is the full real-FFT magnitude spectrum, bin 0 (DC) through
+	// the Nyquist bin inclusive - N/2+1 entries for an N-point FFT, where N
+	// is spectrumFFTSize (or spectrumRingSize if never configured; see
+	// SetMetricsConfig). float32 rather than float64 since this is the one
+	// field here sized for a caller to hand off wholesale (e.g. to a UI
+	// meter), not just read a few scalars out of.
+	Magnitudes []float32
+}
+
+// spectrumRingSize is the number of most-recent mono frames GetSpectrum's
+// FFT runs over - 4096 frames is ~93ms at 44.1kHz, long enough to resolve
+// bass frequencies without smearing a fast pitch sweep across too wide a
+// window.
+const spectrumRingSize = 4096
+
+// spectrumPeakCount is how many local maxima GetSpectrum reports in
+// PeakBins.
+const spectrumPeakCount = 8
+
+// GetSpectrum returns the tap's current spectral analysis, lazily
+// installing a dedicated Subscribe registration the first time it's
+// called, like GetLoudness. The tap callback (spectrumAnalyzer.process)
+// only appends samples to a ring buffer; the Hann-windowed radix-2 FFT
+// itself runs here, synchronously on the caller's goroutine, so repeatedly
+// polling GetSpectrum never costs the audio thread or the tap's drain
+// goroutine anything beyond the ring-buffer append.
+func (t *Tap) GetSpectrum() (SpectrumMetrics, error) {
+	if !t.installed {
+		return SpectrumMetrics{}, fmt.Errorf("tap is not installed")
+	}
+
+	t.spectrumMu.Lock()
+	defer t.spectrumMu.Unlock()
+	if err := t.ensureSpectrumLocked(); err != nil {
+		return SpectrumMetrics{}, err
+	}
+	return t.spectrum.analyze(), nil
+}
+
+// ensureSpectrumLocked installs t's spectrum analyzer the first time
+// GetSpectrum is called, sized to spectrumFFTSize (SetMetricsConfig's
+// FFTSize, if ever set) or spectrumRingSize otherwise. Caller must hold
+// t.spectrumMu.
+func (t *Tap) ensureSpectrumLocked() error {
+	if t.spectrum != nil {
+		return nil
+	}
+	size := t.spectrumFFTSize
+	if size <= 0 {
+		size = spectrumRingSize
+	}
+	analyzer := newSpectrumAnalyzer(size)
+	subID, err := t.Subscribe(analyzer.process, SubscribeOptions{Layout: ChannelLayoutPlanar})
+	if err != nil {
+		return err
+	}
+	t.spectrum = analyzer
+	t.spectrumSubID = subID
+	return nil
+}
+
+// stopSpectrumLocked tears down the spectrum analyzer's Subscribe
+// registration, if one was ever installed. Called from Remove so a
+// GetSpectrum caller doesn't leak a subscription past the tap's lifetime.
+func (t *Tap) stopSpectrumLocked() {
+	t.spectrumMu.Lock()
+	active := t.spectrum != nil
+	subID := t.spectrumSubID
+	t.spectrum = nil
+	t.spectrumMu.Unlock()
+
+	if active {
+		t.Unsubscribe(subID)
+	}
+}
+
+// spectrumAnalyzer is a single Tap's ring buffer of the most recent mono
+// frames, fed one PCM block at a time by process (the Tap.Subscribe
+// callback GetSpectrum registers). process only sums channels down to mono
+// and appends to the ring - the audio-thread-adjacent side stays
+// lock-free of anything but that - while analyze does the actual FFT work,
+// called from GetSpectrum's caller instead.
+type spectrumAnalyzer struct {
+	mu sync.Mutex
+
+	size       int
+	ring       []float64
+	pos        int
+	filled     bool
+	sampleRate float64
+}
+
+func newSpectrumAnalyzer(size int) *spectrumAnalyzer {
+	return &spectrumAnalyzer{size: size, ring: make([]float64, size)}
+}
+
+// process is a tap.Subscribe callback (see SubscribeOptions.Layout), so
+// pcm is planar: all of channel 0's frames, then all of channel 1's, etc.
+func (a *spectrumAnalyzer) process(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if channels <= 0 || len(pcm) == 0 {
+		return
+	}
+	a.sampleRate = sampleRate
+
+	frames := len(pcm) / channels
+	for frame := 0; frame < frames; frame++ {
+		var sum float64
+		for ch := 0; ch < channels; ch++ {
+			sum += float64(pcm[ch*frames+frame])
+		}
+		a.ring[a.pos] = sum / float64(channels)
+		a.pos++
+		if a.pos >= a.size {
+			a.pos = 0
+			a.filled = true
+		}
+	}
+}
+
+// analyze runs a Hann-windowed FFT over the ring buffer's current contents,
+// oldest-sample-first, and derives SpectralCentroid, DominantFrequency, and
+// the spectrumPeakCount strongest local maxima.
+func (a *spectrumAnalyzer) analyze() SpectrumMetrics {
+	a.mu.Lock()
+	n := a.size
+	if !a.filled {
+		n = a.pos
+	}
+	if n == 0 {
+		a.mu.Unlock()
+		return SpectrumMetrics{}
+	}
+	samples := make([]float64, n)
+	if a.filled {
+		for i := 0; i < n; i++ {
+			samples[i] = a.ring[(a.pos+i)%a.size]
+		}
+	} else {
+		copy(samples, a.ring[:n])
+	}
+	sampleRate := a.sampleRate
+	a.mu.Unlock()
+
+	fftSize := nextPowerOfTwo(n)
+	windowed := make([]complex128, fftSize)
+	for i, s := range samples {
+		hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		windowed[i] = complex(s*hann, 0)
+	}
+	spectrum := fft(windowed)
+
+	// A real-valued signal's FFT is conjugate-symmetric, so everything from
+	// bin 0 (DC) through the Nyquist bin (fftSize/2) inclusive is the
+	// unique half of the spectrum - N/2+1 bins, not N/2.
+	half := fftSize/2 + 1
+	mags := make([]float64, half)
+	for i := 0; i < half; i++ {
+		mags[i] = cmplx.Abs(spectrum[i])
+	}
+	binHz := sampleRate / float64(fftSize)
+
+	var weightedSum, magSum float64
+	dominantBin := 0
+	for i, m := range mags {
+		weightedSum += float64(i) * binHz * m
+		magSum += m
+		if m > mags[dominantBin] {
+			dominantBin = i
+		}
+	}
+	var centroid float64
+	if magSum > 0 {
+		centroid = weightedSum / magSum
+	}
+
+	magnitudes := make([]float32, half)
+	for i, m := range mags {
+		magnitudes[i] = float32(m)
+	}
+
+	return SpectrumMetrics{
+		SpectralCentroid:  centroid,
+		DominantFrequency: float64(dominantBin) * binHz,
+		PeakBins:          topPeaks(mags, binHz, spectrumPeakCount),
+		Magnitudes:        magnitudes,
+	}
+}
+
+// topPeaks returns the spectrumPeakCount strongest local maxima in mags
+// (a bin is a local maximum if it's at least as loud as both neighbors),
+// strongest first.
+func topPeaks(mags []float64, binHz float64, count int) []SpectrumPeak {
+	type indexedPeak struct {
+		bin int
+		mag float64
+	}
+	var found []indexedPeak
+	for i := 1; i < len(mags)-1; i++ {
+		if mags[i] > mags[i-1] && mags[i] >= mags[i+1] {
+			found = append(found, indexedPeak{bin: i, mag: mags[i]})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].mag > found[j].mag })
+	if len(found) > count {
+		found = found[:count]
+	}
+
+	peaks := make([]SpectrumPeak, len(found))
+	for i, p := range found {
+		peaks[i] = SpectrumPeak{Hz: float32(float64(p.bin) * binHz), Mag: float32(p.mag)}
+	}
+	return peaks
+}
+
+// nextPowerOfTwo returns the smallest power of two >= n, the FFT size
+// analyze zero-pads samples up to.
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// fft computes the discrete Fourier transform of x (len(x) must be a power
+// of two - analyze always zero-pads to one) via the standard radix-2
+// Cooley-Tukey recursion. Pure Go, no cgo dependency - this only ever runs
+// lazily from GetSpectrum, never on the audio thread.
+func fft(x []complex128) []complex128 {
+	n := len(x)
+	if n <= 1 {
+		return x
+	}
+
+	even := make([]complex128, n/2)
+	odd := make([]complex128, n/2)
+	for i := 0; i < n/2; i++ {
+		even[i] = x[2*i]
+		odd[i] = x[2*i+1]
+	}
+	even = fft(even)
+	odd = fft(odd)
+
+	result := make([]complex128, n)
+	for k := 0; k < n/2; k++ {
+		twiddle := cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n)) * odd[k]
+		result[k] = even[k] + twiddle
+		result[k+n/2] = even[k] - twiddle
+	}
+	return result
+}
@@ -0,0 +1,346 @@
+package tap
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// SpectrumWindow selects the analysis window EnableSpectrum applies before
+// each FFT.
+type SpectrumWindow int
+
+const (
+	WindowHann SpectrumWindow = iota
+	WindowHamming
+	WindowBlackmanHarris
+)
+
+// SpectrumOpts configures EnableSpectrum.
+type SpectrumOpts struct {
+	FFTSize   int // rounded up to the next power of two; <= 0 uses defaultBandFFTSize
+	Window    SpectrumWindow
+	Bands     int     // number of log-spaced output bands; <= 0 uses defaultBandCount
+	Smoothing float32 // exponential smoothing across frames, in [0, 1); 0 disables smoothing
+}
+
+const (
+	defaultBandFFTSize = 2048
+	defaultBandCount   = 32
+
+	// bandMinHz and bandMaxHzFraction bound the log-spaced band edges -
+	// 20Hz up to just under Nyquist, since the FFT has no content at or
+	// above it.
+	bandMinHz         = 20.0
+	bandMaxHzFraction = 0.999
+)
+
+// EnableSpectrum installs a continuously-running FFT band analyzer on t,
+// replacing any analyzer a previous EnableSpectrum call installed. Unlike
+// GetSpectrum's lazy, poll-driven analysis, this one runs its FFT on every
+// fftSize-frame hop of the tap's own Subscribe feed and keeps an
+// exponentially-smoothed dBFS reading per band ready for SpectrumFrame to
+// read out - suited to a continuously-redrawn EQ visualizer rather than a
+// one-off snapshot.
+func (t *Tap) EnableSpectrum(opts SpectrumOpts) error {
+	if !t.installed {
+		return fmt.Errorf("tap is not installed")
+	}
+
+	analyzer, err := newBandSpectrumAnalyzer(opts)
+	if err != nil {
+		return err
+	}
+
+	t.bandSpectrumMu.Lock()
+	defer t.bandSpectrumMu.Unlock()
+
+	oldSubID, hadOld := t.bandSpectrumSubID, t.bandSpectrum != nil
+
+	subID, err := t.Subscribe(analyzer.process, SubscribeOptions{Layout: ChannelLayoutPlanar})
+	if err != nil {
+		return err
+	}
+	t.bandSpectrum = analyzer
+	t.bandSpectrumSubID = subID
+
+	if hadOld {
+		t.Unsubscribe(oldSubID)
+	}
+	return nil
+}
+
+// DisableSpectrum tears down the band analyzer EnableSpectrum installed, if
+// any. Calling it without a prior EnableSpectrum is a no-op.
+func (t *Tap) DisableSpectrum() error {
+	t.stopBandSpectrumLocked()
+	return nil
+}
+
+// SpectrumFrame returns the band analyzer's current smoothed magnitudes, in
+// dBFS, one per SpectrumOpts.Bands. It's only valid after EnableSpectrum.
+func (t *Tap) SpectrumFrame() ([]float32, error) {
+	t.bandSpectrumMu.Lock()
+	analyzer := t.bandSpectrum
+	t.bandSpectrumMu.Unlock()
+
+	if analyzer == nil {
+		return nil, fmt.Errorf("EnableSpectrum has not been called on this tap")
+	}
+	return analyzer.frame(), nil
+}
+
+// stopBandSpectrumLocked tears down the band analyzer's Subscribe
+// registration, if one was ever installed. Called from Remove so an
+// EnableSpectrum caller doesn't leak a subscription past the tap's
+// lifetime.
+func (t *Tap) stopBandSpectrumLocked() {
+	t.bandSpectrumMu.Lock()
+	active := t.bandSpectrum != nil
+	subID := t.bandSpectrumSubID
+	t.bandSpectrum = nil
+	t.bandSpectrumMu.Unlock()
+
+	if active {
+		t.Unsubscribe(subID)
+	}
+}
+
+// bandSpectrumAnalyzer is a single Tap's continuously-running FFT band
+// analyzer, fed one PCM block at a time by process (the Tap.Subscribe
+// callback EnableSpectrum registers). Every buffer it needs - the ring, the
+// FFT workspace, the per-bin magnitude scratch, and the band edges - is
+// allocated once in newBandSpectrumAnalyzer; process and the frame it
+// completes every fftSize-frame hop allocate nothing.
+type bandSpectrumAnalyzer struct {
+	mu sync.Mutex
+
+	fftSize   int
+	window    SpectrumWindow
+	bands     int
+	smoothing float32
+
+	windowCoeffs []float64
+
+	ring    []float64 // mono-summed samples accumulating toward the next hop
+	ringPos int
+
+	fftBuf []complex128 // reused FFT workspace, windowed ring copied in each hop
+	mags   []float64    // reused per-bin power scratch, len fftSize/2
+
+	sampleRate     float64
+	bandEdges      []int // len bands+1 bin indices into mags, computed once sampleRate is known
+	bandEdgesReady bool
+
+	smoothed []float32 // the running per-band dBFS output SpectrumFrame reads
+	ready    bool
+}
+
+func newBandSpectrumAnalyzer(opts SpectrumOpts) (*bandSpectrumAnalyzer, error) {
+	fftSize := opts.FFTSize
+	if fftSize <= 0 {
+		fftSize = defaultBandFFTSize
+	}
+	fftSize = nextPowerOfTwo(fftSize)
+
+	bands := opts.Bands
+	if bands <= 0 {
+		bands = defaultBandCount
+	}
+
+	smoothing := opts.Smoothing
+	if smoothing < 0 || smoothing >= 1 {
+		return nil, fmt.Errorf("spectrum smoothing %v out of range [0, 1)", smoothing)
+	}
+
+	a := &bandSpectrumAnalyzer{
+		fftSize:      fftSize,
+		window:       opts.Window,
+		bands:        bands,
+		smoothing:    smoothing,
+		windowCoeffs: windowCoefficients(opts.Window, fftSize),
+		ring:         make([]float64, fftSize),
+		fftBuf:       make([]complex128, fftSize),
+		mags:         make([]float64, fftSize/2),
+		smoothed:     make([]float32, bands),
+	}
+	return a, nil
+}
+
+// process is a tap.Subscribe callback (see SubscribeOptions.Layout), so pcm
+// is planar: all of channel 0's frames, then all of channel 1's, etc.
+func (a *bandSpectrumAnalyzer) process(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if channels <= 0 || len(pcm) == 0 {
+		return
+	}
+	a.sampleRate = sampleRate
+
+	frames := len(pcm) / channels
+	for frame := 0; frame < frames; frame++ {
+		var sum float64
+		for ch := 0; ch < channels; ch++ {
+			sum += float64(pcm[ch*frames+frame])
+		}
+		a.ring[a.ringPos] = sum / float64(channels)
+		a.ringPos++
+		if a.ringPos >= a.fftSize {
+			a.ringPos = 0
+			a.runFFT()
+		}
+	}
+}
+
+// runFFT windows the ring into fftBuf, transforms it in place, buckets the
+// result into bands, and exponentially smooths it into smoothed. Caller
+// must hold a.mu.
+func (a *bandSpectrumAnalyzer) runFFT() {
+	for i, s := range a.ring {
+		a.fftBuf[i] = complex(s*a.windowCoeffs[i], 0)
+	}
+	fftInPlace(a.fftBuf)
+
+	for i := range a.mags {
+		re, im := real(a.fftBuf[i]), imag(a.fftBuf[i])
+		a.mags[i] = re*re + im*im // power; dB below uses 10*log10 rather than 20*log10(mag)
+	}
+
+	if !a.bandEdgesReady {
+		a.bandEdges = logBandEdges(a.bands, len(a.mags), a.sampleRate)
+		a.bandEdgesReady = true
+	}
+
+	for b := 0; b < a.bands; b++ {
+		lo, hi := a.bandEdges[b], a.bandEdges[b+1]
+		if hi <= lo {
+			hi = lo + 1
+		}
+		var sum float64
+		for i := lo; i < hi && i < len(a.mags); i++ {
+			sum += a.mags[i]
+		}
+		power := sum / float64(hi-lo)
+		db := float32(powerToDB(power))
+
+		if !a.ready || a.smoothing == 0 {
+			a.smoothed[b] = db
+		} else {
+			a.smoothed[b] = a.smoothing*a.smoothed[b] + (1-a.smoothing)*db
+		}
+	}
+	a.ready = true
+}
+
+// frame returns a copy of the analyzer's current smoothed band readings.
+func (a *bandSpectrumAnalyzer) frame() []float32 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]float32, len(a.smoothed))
+	copy(out, a.smoothed)
+	return out
+}
+
+// powerToDB converts a mean-square power value to dBFS, treating 1.0 (a
+// full-scale sine's mean square) as 0dB.
+func powerToDB(power float64) float64 {
+	if power <= 0 {
+		return math.Inf(-1)
+	}
+	return 10 * math.Log10(power)
+}
+
+// logBandEdges returns bands+1 bin indices into an fftBins-length magnitude
+// array, log-spaced from bandMinHz to just under Nyquist - the conventional
+// layout for an EQ-style visualizer, where each octave gets roughly the
+// same screen width instead of the FFT's native linear bin spacing.
+func logBandEdges(bands, fftBins int, sampleRate float64) []int {
+	edges := make([]int, bands+1)
+	if sampleRate <= 0 || fftBins <= 0 {
+		return edges
+	}
+
+	nyquist := sampleRate / 2
+	minHz := bandMinHz
+	maxHz := nyquist * bandMaxHzFraction
+	if maxHz <= minHz {
+		maxHz = minHz + 1
+	}
+	logMin, logMax := math.Log2(minHz), math.Log2(maxHz)
+
+	binHz := nyquist / float64(fftBins)
+	for i := 0; i <= bands; i++ {
+		frac := float64(i) / float64(bands)
+		hz := math.Exp2(logMin + frac*(logMax-logMin))
+		bin := int(hz / binHz)
+		if bin < 0 {
+			bin = 0
+		}
+		if bin > fftBins {
+			bin = fftBins
+		}
+		edges[i] = bin
+	}
+	return edges
+}
+
+// windowCoefficients precomputes size window coefficients for the given
+// SpectrumWindow, applied once per FFT hop in runFFT.
+func windowCoefficients(window SpectrumWindow, size int) []float64 {
+	coeffs := make([]float64, size)
+	n := float64(size - 1)
+	for i := range coeffs {
+		switch window {
+		case WindowHamming:
+			coeffs[i] = 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/n)
+		case WindowBlackmanHarris:
+			x := 2 * math.Pi * float64(i) / n
+			coeffs[i] = 0.35875 - 0.48829*math.Cos(x) + 0.14128*math.Cos(2*x) - 0.01168*math.Cos(3*x)
+		default: // WindowHann
+			coeffs[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/n)
+		}
+	}
+	return coeffs
+}
+
+// fftInPlace computes the discrete Fourier transform of buf in place, via
+// the standard iterative radix-2 Cooley-Tukey algorithm (bit-reversal
+// permutation followed by butterfly passes). len(buf) must be a power of
+// two. Unlike fft in spectrum.go, this allocates no workspace of its own -
+// runFFT reuses the same buf on every hop - trading recursion's simplicity
+// for the zero-per-frame-allocation this continuously-running analyzer
+// needs.
+func fftInPlace(buf []complex128) {
+	n := len(buf)
+	if n <= 1 {
+		return
+	}
+
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		angle := -2 * math.Pi / float64(size)
+		wStep := complex(math.Cos(angle), math.Sin(angle))
+		for start := 0; start < n; start += size {
+			w := complex(1.0, 0.0)
+			for k := 0; k < half; k++ {
+				even := buf[start+k]
+				odd := buf[start+k+half] * w
+				buf[start+k] = even + odd
+				buf[start+k+half] = even - odd
+				w *= wStep
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package tap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFFTInPlaceMatchesRecursiveFFTOfDCSignal(t *testing.T) {
+	x := make([]complex128, 8)
+	for i := range x {
+		x[i] = complex(1, 0)
+	}
+	fftInPlace(x)
+	if math.Abs(real(x[0])-8) > 1e-9 {
+		t.Errorf("fftInPlace(DC)[0] = %v, want 8", x[0])
+	}
+	for i := 1; i < len(x); i++ {
+		if math.Abs(real(x[i])) > 1e-9 || math.Abs(imag(x[i])) > 1e-9 {
+			t.Errorf("fftInPlace(DC)[%d] = %v, want ~0", i, x[i])
+		}
+	}
+}
+
+func TestFFTInPlaceAgreesWithRecursiveFFT(t *testing.T) {
+	const n = 16
+	recursive := make([]complex128, n)
+	inPlace := make([]complex128, n)
+	for i := 0; i < n; i++ {
+		v := complex(math.Sin(2*math.Pi*float64(i)/n), 0)
+		recursive[i] = v
+		inPlace[i] = v
+	}
+
+	recursive = fft(recursive)
+	fftInPlace(inPlace)
+
+	for i := 0; i < n; i++ {
+		if math.Abs(real(recursive[i])-real(inPlace[i])) > 1e-9 || math.Abs(imag(recursive[i])-imag(inPlace[i])) > 1e-9 {
+			t.Errorf("fftInPlace[%d] = %v, want %v (recursive fft)", i, inPlace[i], recursive[i])
+		}
+	}
+}
+
+func TestWindowCoefficientsPeakAtCenterAndTaperToZeroAtEdges(t *testing.T) {
+	for _, w := range []SpectrumWindow{WindowHann, WindowHamming, WindowBlackmanHarris} {
+		coeffs := windowCoefficients(w, 9)
+		if coeffs[0] > coeffs[4] || coeffs[8] > coeffs[4] {
+			t.Errorf("window %v: coeffs = %v, want a taper peaking at the center", w, coeffs)
+		}
+	}
+	hann := windowCoefficients(WindowHann, 9)
+	if math.Abs(hann[0]) > 1e-9 {
+		t.Errorf("WindowHann[0] = %v, want ~0", hann[0])
+	}
+}
+
+func TestLogBandEdgesAreMonotonicAndSpanTheFFTRange(t *testing.T) {
+	edges := logBandEdges(8, 1024, 48000)
+	if edges[0] != 0 {
+		// bandMinHz (20Hz) lands in the lowest handful of bins at 48kHz, so the
+		// first edge should be at or very near bin 0.
+		t.Errorf("logBandEdges[0] = %v, want 0 or close to it", edges[0])
+	}
+	for i := 1; i < len(edges); i++ {
+		if edges[i] < edges[i-1] {
+			t.Errorf("logBandEdges = %v, want non-decreasing", edges)
+		}
+	}
+	if edges[len(edges)-1] > 1024 {
+		t.Errorf("logBandEdges last edge = %v, want <= fftBins", edges[len(edges)-1])
+	}
+}
+
+func TestBandSpectrumAnalyzerProducesOneReadingPerBand(t *testing.T) {
+	a, err := newBandSpectrumAnalyzer(SpectrumOpts{FFTSize: 256, Bands: 4, Smoothing: 0})
+	if err != nil {
+		t.Fatalf("newBandSpectrumAnalyzer failed: %v", err)
+	}
+
+	const sampleRate = 8000.0
+	pcm := make([]float32, 256)
+	for i := range pcm {
+		pcm[i] = float32(math.Sin(2 * math.Pi * 1000 * float64(i) / sampleRate))
+	}
+	a.process(pcm, 1, sampleRate, 0)
+
+	frame := a.frame()
+	if len(frame) != 4 {
+		t.Fatalf("frame() returned %d bands, want 4", len(frame))
+	}
+}
+
+func TestNewBandSpectrumAnalyzerRejectsSmoothingOutOfRange(t *testing.T) {
+	if _, err := newBandSpectrumAnalyzer(SpectrumOpts{Smoothing: 1.0}); err == nil {
+		t.Error("expected Smoothing=1.0 to be rejected")
+	}
+	if _, err := newBandSpectrumAnalyzer(SpectrumOpts{Smoothing: -0.1}); err == nil {
+		t.Error("expected a negative Smoothing to be rejected")
+	}
+}
+
+func TestSpectrumFrameBeforeEnableSpectrumErrors(t *testing.T) {
+	tap := &Tap{installed: true}
+	if _, err := tap.SpectrumFrame(); err == nil {
+		t.Error("expected SpectrumFrame before EnableSpectrum to fail")
+	}
+}
+
+func TestEnableSpectrumRejectsUninstalledTap(t *testing.T) {
+	tap := &Tap{}
+	if err := tap.EnableSpectrum(SpectrumOpts{}); err == nil {
+		t.Error("expected EnableSpectrum on an uninstalled tap to fail")
+	}
+}
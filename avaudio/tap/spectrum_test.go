@@ -0,0 +1,92 @@
+package tap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNextPowerOfTwo(t *testing.T) {
+	cases := map[int]int{0: 1, 1: 1, 2: 2, 3: 4, 4: 4, 5: 8, 4096: 4096, 4097: 8192}
+	for n, want := range cases {
+		if got := nextPowerOfTwo(n); got != want {
+			t.Errorf("nextPowerOfTwo(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestFFTOfDCSignalIsAllInBinZero(t *testing.T) {
+	x := make([]complex128, 8)
+	for i := range x {
+		x[i] = complex(1, 0)
+	}
+	got := fft(x)
+	if math.Abs(real(got[0])-8) > 1e-9 {
+		t.Errorf("fft(DC)[0] = %v, want 8", got[0])
+	}
+	for i := 1; i < len(got); i++ {
+		if math.Abs(real(got[i])) > 1e-9 || math.Abs(imag(got[i])) > 1e-9 {
+			t.Errorf("fft(DC)[%d] = %v, want ~0", i, got[i])
+		}
+	}
+}
+
+func TestTopPeaksPicksStrongestLocalMaxima(t *testing.T) {
+	mags := []float64{0, 1, 5, 1, 0, 1, 9, 1, 0}
+	peaks := topPeaks(mags, 10, 8)
+	if len(peaks) != 2 {
+		t.Fatalf("topPeaks found %d peaks, want 2: %+v", len(peaks), peaks)
+	}
+	if peaks[0].Hz != 60 || peaks[0].Mag != 9 {
+		t.Errorf("topPeaks[0] = %+v, want Hz=60 Mag=9 (strongest first)", peaks[0])
+	}
+	if peaks[1].Hz != 20 || peaks[1].Mag != 5 {
+		t.Errorf("topPeaks[1] = %+v, want Hz=20 Mag=5", peaks[1])
+	}
+}
+
+func TestTopPeaksCapsAtCount(t *testing.T) {
+	mags := []float64{0, 5, 0, 4, 0, 3, 0, 2, 0, 1, 0}
+	if got := topPeaks(mags, 1, 2); len(got) != 2 {
+		t.Errorf("topPeaks returned %d peaks, want count=2 to cap it", len(got))
+	}
+}
+
+func TestSpectrumAnalyzerFindsDominantFrequency(t *testing.T) {
+	const sampleRate = 8000.0
+	const toneHz = 1000.0
+
+	a := newSpectrumAnalyzer(512)
+	pcm := make([]float32, 512)
+	for i := range pcm {
+		pcm[i] = float32(math.Sin(2 * math.Pi * toneHz * float64(i) / sampleRate))
+	}
+	a.process(pcm, 1, sampleRate, 0)
+
+	metrics := a.analyze()
+	binHz := sampleRate / float64(nextPowerOfTwo(512))
+	if math.Abs(metrics.DominantFrequency-toneHz) > binHz {
+		t.Errorf("DominantFrequency = %v, want close to %v (bin width %v)", metrics.DominantFrequency, toneHz, binHz)
+	}
+}
+
+func TestSpectrumAnalyzerMagnitudesHasNyquistBinInclusive(t *testing.T) {
+	a := newSpectrumAnalyzer(512)
+	pcm := make([]float32, 512)
+	for i := range pcm {
+		pcm[i] = float32(math.Sin(2 * math.Pi * 1000 * float64(i) / 8000))
+	}
+	a.process(pcm, 1, 8000, 0)
+
+	metrics := a.analyze()
+	want := nextPowerOfTwo(512)/2 + 1
+	if len(metrics.Magnitudes) != want {
+		t.Errorf("len(Magnitudes) = %v, want %v (N/2+1)", len(metrics.Magnitudes), want)
+	}
+}
+
+func TestSpectrumAnalyzerEmptyRingReturnsZeroValue(t *testing.T) {
+	a := newSpectrumAnalyzer(512)
+	if got := a.analyze(); got.DominantFrequency != 0 || got.SpectralCentroid != 0 || got.PeakBins != nil {
+		t.Errorf("analyze() on an empty ring = %+v, want zero value", got)
+	}
+}
@@ -0,0 +1,283 @@
+package tap
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// SubscriptionID identifies one Subscribe registration on a Tap, returned so
+// a later Unsubscribe call can find it again.
+type SubscriptionID uint64
+
+// ChannelLayout selects how Subscribe delivers multi-channel PCM to its
+// callback - interleaved (L,R,L,R,...) or planar (all of channel 0's
+// frames, then all of channel 1's) - mirroring TapFormat's interleaved/
+// planar distinction for CallbackTap.
+type ChannelLayout int
+
+const (
+	ChannelLayoutInterleaved ChannelLayout = iota
+	ChannelLayoutPlanar
+)
+
+// SubscribeOptions configures one Subscribe registration. The zero value is
+// a reasonable default: an 8-block ring delivering interleaved PCM.
+type SubscribeOptions struct {
+	BufferFrames int // ring capacity in blocks; <= 0 uses defaultSubscriptionRingSlots
+	Layout       ChannelLayout
+}
+
+const defaultSubscriptionRingSlots = 8
+
+// defaultSubscriptionBufferSize is the block size Subscribe's shared feed
+// requests from InstallCallbackTap - independent of any one subscription's
+// own BufferFrames, which only sizes that subscription's ring.
+const defaultSubscriptionBufferSize = 2048
+
+// subscriptionBlock is one PCM block delivered to a subscription's ring.
+type subscriptionBlock struct {
+	pcm        []float32
+	channels   int
+	sampleRate float64
+	hostTime   uint64
+}
+
+// subscriptionRing is a lock-free single-producer/single-consumer ring of
+// subscriptionBlocks - Tap.dispatch (running on the shared feed's own drain
+// goroutine) is the sole producer for a given subscription, and that
+// subscription's own pump goroutine is the sole consumer, mirroring
+// callbackRing in callback.go.
+type subscriptionRing struct {
+	slots []subscriptionBlock
+	head  uint64 // next slot pump will read
+	tail  uint64 // next slot dispatch will write
+}
+
+func newSubscriptionRing(size int) *subscriptionRing {
+	if size < 2 {
+		size = 2
+	}
+	return &subscriptionRing{slots: make([]subscriptionBlock, size)}
+}
+
+func (r *subscriptionRing) push(b subscriptionBlock) bool {
+	tail := atomic.LoadUint64(&r.tail)
+	head := atomic.LoadUint64(&r.head)
+	if tail-head >= uint64(len(r.slots)) {
+		return false // full; caller counts this as dropped frames
+	}
+	r.slots[tail%uint64(len(r.slots))] = b
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+func (r *subscriptionRing) pop() (subscriptionBlock, bool) {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if head == tail {
+		return subscriptionBlock{}, false
+	}
+	b := r.slots[head%uint64(len(r.slots))]
+	atomic.StoreUint64(&r.head, head+1)
+	return b, true
+}
+
+// subscription is one Subscribe registration: its own ring and pump
+// goroutine, so a slow callback only ever backs up its own delivery rather
+// than some other subscription's.
+type subscription struct {
+	id     SubscriptionID
+	ring   *subscriptionRing
+	layout ChannelLayout
+	cb     func(pcm []float32, channels int, sampleRate float64, hostTime uint64)
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// Subscribe registers cb to be called with each PCM block this Tap
+// captures, delivered off the render thread through a dedicated lock-free
+// ring and pump goroutine instead of requiring a caller to poll
+// GetMetrics/GetSamples. The first Subscribe call on t installs a shared
+// CallbackTap (see InstallCallbackTap) whose single drain goroutine feeds
+// every subscription's own ring - see dispatch - so cb never runs anywhere
+// near the audio thread.
+//
+// A subscription whose ring overflows (its own pump fell behind) drops the
+// block and adds it to GetMetrics' TapMetrics.DroppedFrames instead of
+// blocking dispatch or any other subscription.
+func (t *Tap) Subscribe(cb func(pcm []float32, channels int, sampleRate float64, hostTime uint64), opts SubscribeOptions) (SubscriptionID, error) {
+	if !t.installed {
+		return 0, fmt.Errorf("tap is not installed")
+	}
+	if cb == nil {
+		return 0, fmt.Errorf("callback cannot be nil")
+	}
+
+	slots := opts.BufferFrames
+	if slots <= 0 {
+		slots = defaultSubscriptionRingSlots
+	}
+
+	t.subsMu.Lock()
+	if err := t.ensureFeedLocked(); err != nil {
+		t.subsMu.Unlock()
+		return 0, err
+	}
+	if t.subs == nil {
+		t.subs = make(map[SubscriptionID]*subscription)
+	}
+	t.nextSubID++
+	id := SubscriptionID(t.nextSubID)
+	sub := &subscription{
+		id:     id,
+		ring:   newSubscriptionRing(slots),
+		layout: opts.Layout,
+		cb:     cb,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	t.subs[id] = sub
+	t.subsMu.Unlock()
+
+	go t.pump(sub)
+	return id, nil
+}
+
+// Unsubscribe stops delivery to the callback Subscribe registered as id and
+// releases its ring and pump goroutine. Once the last subscription on t is
+// gone, it also removes the shared feed the first Subscribe installed.
+func (t *Tap) Unsubscribe(id SubscriptionID) error {
+	t.subsMu.Lock()
+	sub, ok := t.subs[id]
+	if !ok {
+		t.subsMu.Unlock()
+		return fmt.Errorf("no subscription with id %d", id)
+	}
+	delete(t.subs, id)
+	remaining := len(t.subs)
+	var feed *CallbackTap
+	if remaining == 0 {
+		feed = t.feed
+		t.feed = nil
+	}
+	t.subsMu.Unlock()
+
+	close(sub.stopCh)
+	<-sub.doneCh
+
+	if feed == nil {
+		return nil
+	}
+	return feed.Remove()
+}
+
+// ensureFeedLocked installs t's shared CallbackTap the first time any
+// subscription is registered. Caller must hold t.subsMu.
+func (t *Tap) ensureFeedLocked() error {
+	if t.feed != nil {
+		return nil
+	}
+	feed, err := InstallCallbackTap(t.enginePtr, t.nodePtr, t.busIndex, defaultSubscriptionBufferSize, TapFormatInterleavedFloat32, t.dispatch)
+	if err != nil {
+		return err
+	}
+	t.feed = feed
+	if info, err := t.GetInfo(); err == nil {
+		t.sampleRate = info.SampleRate
+	}
+	return nil
+}
+
+// dispatch is the shared feed's callback: it fans buf out to every current
+// subscription's own ring, converting to that subscription's requested
+// Layout first, and drops (counting it in droppedFrames) for any
+// subscription whose ring is currently full instead of blocking the others
+// or the feed's drain goroutine.
+func (t *Tap) dispatch(buf TapBuffer) {
+	t.subsMu.Lock()
+	subs := make([]*subscription, 0, len(t.subs))
+	for _, sub := range t.subs {
+		subs = append(subs, sub)
+	}
+	t.subsMu.Unlock()
+
+	hostTime := uint64(buf.Timestamp.UnixNano())
+	for _, sub := range subs {
+		pcm := buf.Float32Data
+		switch {
+		case sub.layout == ChannelLayoutPlanar && buf.Format == TapFormatInterleavedFloat32:
+			pcm = deinterleaveFloat32(pcm, buf.Channels)
+		case sub.layout == ChannelLayoutInterleaved && buf.Format == TapFormatPlanarFloat32:
+			pcm = interleaveFloat32(pcm, buf.Channels, buf.Frames)
+		}
+
+		block := subscriptionBlock{pcm: pcm, channels: buf.Channels, sampleRate: t.sampleRate, hostTime: hostTime}
+		if !sub.ring.push(block) {
+			atomic.AddUint64(&t.droppedFrames, uint64(buf.Frames))
+		}
+	}
+}
+
+// pump drains sub's ring and invokes its callback for each block, until
+// Unsubscribe closes sub.stopCh, at which point it drains whatever is left
+// before exiting - Tap's per-subscription analog of CallbackTap.drainLoop.
+func (t *Tap) pump(sub *subscription) {
+	defer close(sub.doneCh)
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sub.stopCh:
+			for {
+				b, ok := sub.ring.pop()
+				if !ok {
+					return
+				}
+				sub.cb(b.pcm, b.channels, b.sampleRate, b.hostTime)
+			}
+		case <-ticker.C:
+			for {
+				b, ok := sub.ring.pop()
+				if !ok {
+					break
+				}
+				sub.cb(b.pcm, b.channels, b.sampleRate, b.hostTime)
+			}
+		}
+	}
+}
+
+// deinterleaveFloat32 converts channels-interleaved PCM to planar (all of
+// channel 0's frames, then all of channel 1's, ...).
+func deinterleaveFloat32(interleaved []float32, channels int) []float32 {
+	if channels <= 1 || len(interleaved) == 0 {
+		return interleaved
+	}
+	frames := len(interleaved) / channels
+	out := make([]float32, frames*channels)
+	for frame := 0; frame < frames; frame++ {
+		for ch := 0; ch < channels; ch++ {
+			out[ch*frames+frame] = interleaved[frame*channels+ch]
+		}
+	}
+	return out
+}
+
+// interleaveFloat32 converts planar PCM (one contiguous run per channel) to
+// channels-interleaved.
+func interleaveFloat32(planar []float32, channels, frames int) []float32 {
+	if channels <= 1 || frames == 0 {
+		return planar
+	}
+	out := make([]float32, channels*frames)
+	for ch := 0; ch < channels; ch++ {
+		for frame := 0; frame < frames; frame++ {
+			if idx := ch*frames + frame; idx < len(planar) {
+				out[frame*channels+ch] = planar[idx]
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package tap
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// subscribeChannelDepth is how many TapBlocks SubscribeChannel buffers
+// before it starts dropping the oldest queued block to make room for the
+// newest, rather than blocking the Subscribe pump goroutine delivering it.
+const subscribeChannelDepth = 8
+
+// TapBlock is one PCM block delivered by SubscribeChannel - the
+// channel-based counterpart to Subscribe's callback API, convenient for a
+// consumer that wants `for block := range ch` over FFTs/file-writing/
+// streaming instead of a callback, at the cost of one extra hop through a
+// buffered Go channel.
+type TapBlock struct {
+	PCM        []float32 // interleaved or planar, per SubscribeOptions.Layout
+	Channels   int
+	SampleRate float64
+	FrameCount int
+	Timestamp  time.Time
+
+	RMS  float64
+	Peak float32
+
+	// DroppedBlocks counts TapBlocks this subscription's channel has had
+	// to drop (oldest-first) because the caller fell behind it, separate
+	// from Subscribe's own ring-overflow counter in TapMetrics.DroppedFrames.
+	DroppedBlocks uint64
+}
+
+// CancelFunc is the stop function SubscribeChannel returns: calling it
+// unsubscribes and closes the TapBlock channel.
+type CancelFunc func() error
+
+// SubscribeChannel is Subscribe's channel-based counterpart: instead of a
+// callback, it returns a channel of TapBlock and a CancelFunc that
+// unsubscribes and closes the channel. Internally it's an ordinary
+// Subscribe registration whose callback computes RMS/peak/frame count and
+// forwards into the channel, so it inherits Subscribe's lock-free ring and
+// dedicated pump goroutine - the audio-adjacent side never blocks on a Go
+// channel send, this channel included.
+func (t *Tap) SubscribeChannel(opts SubscribeOptions) (<-chan TapBlock, CancelFunc, error) {
+	ch := make(chan TapBlock, subscribeChannelDepth)
+
+	var mu sync.Mutex
+	var dropped uint64
+
+	id, err := t.Subscribe(func(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+		frameCount := 0
+		if channels > 0 {
+			frameCount = len(pcm) / channels
+		}
+		block := TapBlock{
+			PCM:        pcm,
+			Channels:   channels,
+			SampleRate: sampleRate,
+			FrameCount: frameCount,
+			Timestamp:  time.Unix(0, int64(hostTime)),
+			RMS:        rmsOfPCM(pcm),
+			Peak:       peakOfPCM(pcm),
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		block.DroppedBlocks = dropped
+		select {
+		case ch <- block:
+			return
+		default:
+		}
+		// Channel's full: drop the oldest queued block to make room,
+		// rather than block this subscription's pump goroutine.
+		select {
+		case <-ch:
+			dropped++
+		default:
+		}
+		select {
+		case ch <- block:
+		default:
+		}
+	}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stop := func() error {
+		err := t.Unsubscribe(id)
+		close(ch)
+		return err
+	}
+	return ch, stop, nil
+}
+
+// rmsOfPCM computes the root-mean-square level across every sample in pcm,
+// independent of channel layout (interleaved or planar).
+func rmsOfPCM(pcm []float32) float64 {
+	if len(pcm) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range pcm {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(pcm)))
+}
+
+// peakOfPCM returns the largest absolute sample value in pcm.
+func peakOfPCM(pcm []float32) float32 {
+	var peak float32
+	for _, s := range pcm {
+		a := s
+		if a < 0 {
+			a = -a
+		}
+		if a > peak {
+			peak = a
+		}
+	}
+	return peak
+}
@@ -0,0 +1,31 @@
+package tap
+
+import "testing"
+
+func TestRMSAndPeakOfPCM(t *testing.T) {
+	pcm := []float32{1, -1, 1, -1}
+	if got := rmsOfPCM(pcm); got != 1.0 {
+		t.Errorf("rmsOfPCM(%v) = %v, want 1.0", pcm, got)
+	}
+	if got := peakOfPCM(pcm); got != 1 {
+		t.Errorf("peakOfPCM(%v) = %v, want 1", pcm, got)
+	}
+
+	quiet := []float32{0.5, -0.25}
+	if got := peakOfPCM(quiet); got != 0.5 {
+		t.Errorf("peakOfPCM(%v) = %v, want 0.5", quiet, got)
+	}
+	if got := rmsOfPCM(nil); got != 0 {
+		t.Errorf("rmsOfPCM(nil) = %v, want 0", got)
+	}
+	if got := peakOfPCM(nil); got != 0 {
+		t.Errorf("peakOfPCM(nil) = %v, want 0", got)
+	}
+}
+
+func TestSubscribeChannelRejectsUninstalledTap(t *testing.T) {
+	notInstalled := &Tap{}
+	if _, _, err := notInstalled.SubscribeChannel(SubscribeOptions{}); err == nil {
+		t.Fatal("expected SubscribeChannel on an uninstalled tap to fail")
+	}
+}
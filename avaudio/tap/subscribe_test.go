@@ -0,0 +1,107 @@
+package tap
+
+import (
+	"testing"
+)
+
+func TestSubscriptionRingPushPop(t *testing.T) {
+	r := newSubscriptionRing(2)
+
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on empty ring should report false")
+	}
+
+	if !r.push(subscriptionBlock{channels: 1}) {
+		t.Fatal("push into empty ring should succeed")
+	}
+	if !r.push(subscriptionBlock{channels: 2}) {
+		t.Fatal("push into ring with one free slot should succeed")
+	}
+	if r.push(subscriptionBlock{channels: 3}) {
+		t.Fatal("push into full ring should fail")
+	}
+
+	b, ok := r.pop()
+	if !ok || b.channels != 1 {
+		t.Fatalf("pop = %+v, %v; want channels=1, true", b, ok)
+	}
+	if !r.push(subscriptionBlock{channels: 3}) {
+		t.Fatal("push after freeing a slot should succeed")
+	}
+
+	b, ok = r.pop()
+	if !ok || b.channels != 2 {
+		t.Fatalf("pop = %+v, %v; want channels=2, true", b, ok)
+	}
+}
+
+func TestSubscriptionRingMinimumSize(t *testing.T) {
+	r := newSubscriptionRing(0)
+	if len(r.slots) < 2 {
+		t.Fatalf("newSubscriptionRing(0) made a ring of %d slots, want at least 2", len(r.slots))
+	}
+}
+
+func TestSubscribeRejectsInvalidArgs(t *testing.T) {
+	notInstalled := &Tap{}
+	if _, err := notInstalled.Subscribe(func([]float32, int, float64, uint64) {}, SubscribeOptions{}); err == nil {
+		t.Fatal("expected Subscribe on an uninstalled tap to fail")
+	}
+
+	installed := &Tap{installed: true}
+	if _, err := installed.Subscribe(nil, SubscribeOptions{}); err == nil {
+		t.Fatal("expected Subscribe with a nil callback to fail")
+	}
+}
+
+func TestUnsubscribeRejectsUnknownID(t *testing.T) {
+	tap := &Tap{installed: true}
+	if err := tap.Unsubscribe(SubscriptionID(1)); err == nil {
+		t.Fatal("expected Unsubscribe with an unregistered id to fail")
+	}
+}
+
+func TestDispatchFansOutToEverySubscriptionAndDropsOnOverflow(t *testing.T) {
+	tap := &Tap{installed: true, sampleRate: 44100}
+
+	full := newSubscriptionRing(2)
+	full.push(subscriptionBlock{}) // pre-fill so the next push this test drives overflows
+	full.push(subscriptionBlock{})
+
+	subA := &subscription{id: 1, ring: newSubscriptionRing(4), layout: ChannelLayoutInterleaved}
+	subB := &subscription{id: 2, ring: full, layout: ChannelLayoutInterleaved}
+	tap.subs = map[SubscriptionID]*subscription{subA.id: subA, subB.id: subB}
+
+	buf := TapBuffer{Format: TapFormatInterleavedFloat32, Frames: 4, Channels: 2, Float32Data: []float32{1, 2, 3, 4, 5, 6, 7, 8}}
+	tap.dispatch(buf)
+
+	b, ok := subA.ring.pop()
+	if !ok {
+		t.Fatal("expected subA's ring to have received a block")
+	}
+	if b.sampleRate != 44100 {
+		t.Errorf("expected delivered block's sampleRate to be 44100, got %v", b.sampleRate)
+	}
+
+	if dropped := tap.droppedFrames; dropped != uint64(buf.Frames) {
+		t.Errorf("expected subB's overflow to count %d dropped frames, got %d", buf.Frames, dropped)
+	}
+}
+
+func TestDeinterleaveAndInterleaveFloat32RoundTrip(t *testing.T) {
+	interleaved := []float32{1, 10, 2, 20, 3, 30}
+	planar := deinterleaveFloat32(interleaved, 2)
+	want := []float32{1, 2, 3, 10, 20, 30}
+	for i := range want {
+		if planar[i] != want[i] {
+			t.Fatalf("deinterleaveFloat32 = %v, want %v", planar, want)
+		}
+	}
+
+	backToInterleaved := interleaveFloat32(planar, 2, 3)
+	for i := range interleaved {
+		if backToInterleaved[i] != interleaved[i] {
+			t.Fatalf("interleaveFloat32 round-trip = %v, want %v", backToInterleaved, interleaved)
+		}
+	}
+}
@@ -15,6 +15,7 @@ const char* tap_remove(const char* tapKey);
 const char* tap_get_info(const char* tapKey, TapInfo* info);
 const char* tap_get_rms(const char* tapKey, double* result);
 const char* tap_get_frame_count(const char* tapKey, int* result);
+const char* tap_get_samples(const char* tapKey, float* outBuffer, int maxSamples, int* outCount);
 const char* tap_remove_all(void);
 const char* tap_get_active_count(int* result);
 */
@@ -24,6 +25,7 @@ import (
 	"fmt"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 )
@@ -43,8 +45,32 @@ type TapMetrics struct {
 	RMS        float64   // Root Mean Square level
 	FrameCount int       // Number of frames in last buffer
 	LastUpdate time.Time // When metrics were last updated
+
+	// DroppedFrames counts frames from blocks any Subscribe registration's
+	// ring had to drop because its pump goroutine fell behind - see
+	// subscribe.go. It's cumulative across every subscription this Tap has
+	// ever had, not reset between GetMetrics calls.
+	DroppedFrames uint64
+
+	// Samples is the same most-recent buffer GetSamples would return,
+	// included here so a caller that needs raw signal alongside RMS (e.g.
+	// engine/analyze's cross-correlation latency check) doesn't need a
+	// second native round trip to fetch it. Empty if the native side had
+	// nothing buffered yet.
+	Samples []float32
+
+	// SampleRate is the tap's node sample rate, from GetInfo - needed to
+	// turn a sample-domain lag (e.g. a cross-correlation argmax) into a
+	// time.Duration.
+	SampleRate float64
 }
 
+// metricsSampleCapture bounds how many samples of the most recent buffer
+// GetMetrics copies into TapMetrics.Samples - generous enough to cover any
+// realistic AVAudioEngine render block without the cost of copying an
+// unbounded amount each call.
+const metricsSampleCapture = 8192
+
 // Global tap registry (Go side owns the bookkeeping)
 var (
 	tapRegistry = make(map[string]*Tap)
@@ -58,6 +84,46 @@ type Tap struct {
 	nodePtr   unsafe.Pointer // AVAudioNode pointer
 	busIndex  int            // Bus index for the tap
 	installed bool           // Whether tap is currently installed
+
+	// subsMu guards subs, nextSubID, and feed - the push-based delivery
+	// machinery Subscribe/Unsubscribe manage, kept separate from the
+	// poll-driven fields above since they're populated lazily only if a
+	// caller ever calls Subscribe. See subscribe.go.
+	subsMu        sync.Mutex
+	subs          map[SubscriptionID]*subscription
+	nextSubID     uint64
+	feed          *CallbackTap
+	sampleRate    float64
+	droppedFrames uint64
+
+	// loudnessMu guards loudness and loudnessSubID - the GetLoudness/
+	// ResetIntegrated machinery in loudness.go, populated lazily only if a
+	// caller ever calls GetLoudness or ResetIntegrated.
+	loudnessMu    sync.Mutex
+	loudness      *loudnessAnalyzer
+	loudnessSubID SubscriptionID
+
+	// spectrumMu guards spectrum, spectrumSubID, and spectrumFFTSize - the
+	// GetSpectrum machinery in spectrum.go, populated lazily only if a
+	// caller ever calls GetSpectrum (or eagerly by SetMetricsConfig).
+	spectrumMu      sync.Mutex
+	spectrum        *spectrumAnalyzer
+	spectrumSubID   SubscriptionID
+	spectrumFFTSize int // <= 0 means spectrumRingSize; see SetMetricsConfig
+
+	// bandSpectrumMu guards bandSpectrum and bandSpectrumSubID - the
+	// EnableSpectrum/SpectrumFrame machinery in spectrum_bands.go,
+	// populated only if a caller ever calls EnableSpectrum.
+	bandSpectrumMu    sync.Mutex
+	bandSpectrum      *bandSpectrumAnalyzer
+	bandSpectrumSubID SubscriptionID
+
+	// meterMu guards meter and meterSubID - the GetMeter/SetMeterConfig
+	// peak/RMS/LUFS meter in meter.go, populated lazily only if a caller
+	// ever calls GetMeter or SetMeterConfig.
+	meterMu    sync.Mutex
+	meter      *meterAnalyzer
+	meterSubID SubscriptionID
 }
 
 // isValidTapKey validates that a tap key contains only safe characters
@@ -126,6 +192,11 @@ func (t *Tap) Remove() error {
 		return fmt.Errorf("tap is not installed")
 	}
 
+	t.stopLoudnessLocked()
+	t.stopSpectrumLocked()
+	t.stopBandSpectrumLocked()
+	t.stopMeterLocked()
+
 	tapMutex.Lock()
 	defer tapMutex.Unlock()
 
@@ -191,13 +262,52 @@ func (t *Tap) GetMetrics() (*TapMetrics, error) {
 		return nil, errors.New(C.GoString(errorStr))
 	}
 
+	info, err := t.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := t.GetSamples(metricsSampleCapture)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TapMetrics{
-		RMS:        float64(rms),
-		FrameCount: int(frameCount),
-		LastUpdate: time.Now(),
+		RMS:           float64(rms),
+		FrameCount:    int(frameCount),
+		LastUpdate:    time.Now(),
+		DroppedFrames: atomic.LoadUint64(&t.droppedFrames),
+		Samples:       samples,
+		SampleRate:    info.SampleRate,
 	}, nil
 }
 
+// GetSamples copies up to maxSamples of the most recent buffer captured by
+// the tap (interleaved if the node is multi-channel) and returns the number
+// of samples actually written. Intended for analysis that needs raw signal
+// rather than the RMS/frame-count summary GetMetrics provides, e.g. spectrum
+// analysis (see engine/channel's Meter type).
+func (t *Tap) GetSamples(maxSamples int) ([]float32, error) {
+	if !t.installed {
+		return nil, fmt.Errorf("tap is not installed")
+	}
+	if maxSamples <= 0 {
+		return nil, fmt.Errorf("maxSamples must be positive")
+	}
+
+	cKey := C.CString(t.key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	buf := make([]float32, maxSamples)
+	var outCount C.int
+	errorStr := C.tap_get_samples(cKey, (*C.float)(unsafe.Pointer(&buf[0])), C.int(maxSamples), &outCount)
+	if errorStr != nil {
+		return nil, errors.New(C.GoString(errorStr))
+	}
+
+	return buf[:int(outCount)], nil
+}
+
 // IsInstalled returns true if the tap is currently installed
 func (t *Tap) IsInstalled() bool {
 	return t.installed
@@ -213,6 +323,15 @@ func (t *Tap) GetNodePtr() unsafe.Pointer {
 	return t.nodePtr
 }
 
+// Dropped returns the number of frames any Subscribe registration's ring has
+// had to drop because its pump goroutine fell behind dispatch - the same
+// counter GetMetrics reports as TapMetrics.DroppedFrames, exposed directly
+// for a caller that only wants the backpressure count and doesn't want to
+// pay for GetMetrics' two native RMS/frame-count calls just to read it.
+func (t *Tap) Dropped() uint64 {
+	return atomic.LoadUint64(&t.droppedFrames)
+}
+
 // WaitForActivity waits for audio activity on the tap with a timeout
 func (t *Tap) WaitForActivity(timeout time.Duration, minRMS float64) (bool, error) {
 	if !t.installed {
@@ -0,0 +1,209 @@
+// Package tracker plays XM/MOD/S3M/IT module files via libxmp, exposing the
+// decoded stream as an AVAudioNode so it can be wired into an avaudio/engine
+// graph the same way avaudio/sourcenode nodes are: create a Player, fetch
+// its node pointer with GetNodePtr, then connect that pointer with
+// engine.ConnectWithTypedFormat.
+package tracker
+
+/*
+#cgo LDFLAGS: -lxmp
+#include "native/tracker.h"
+#include <stdlib.h>
+
+// Function declarations - CGO resolves these from the native libxmp shim.
+const char* tracker_load_module(const char* path, void** outPlayer);
+const char* tracker_get_node_ptr(void* player, void** outNode);
+const char* tracker_set_position(void* player, int order, int row);
+const char* tracker_set_tempo(void* player, int bpm);
+const char* tracker_set_loop_count(void* player, int count);
+const char* tracker_set_channel_mute(void* player, int channel, bool mute);
+const char* tracker_set_channel_solo(void* player, int channel, bool solo);
+const char* tracker_poll_event(void* player, int* order, int* row, int* pattern, bool* hasEvent);
+const char* tracker_destroy(void* player);
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// RowEvent describes the module's playback position at the moment a new row
+// was triggered, delivered to a callback registered with OnRowEvent.
+type RowEvent struct {
+	Order   int
+	Row     int
+	Pattern int
+}
+
+// Player decodes one loaded module via libxmp and exposes it as a connectable
+// audio node. It is not safe for concurrent use from multiple goroutines
+// beyond the background event poll started by OnRowEvent.
+type Player struct {
+	ptr unsafe.Pointer
+
+	mu      sync.Mutex
+	onRow   func(RowEvent)
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	polling bool
+}
+
+// Load opens path (XM, MOD, S3M, or IT) and prepares it for playback.
+func Load(path string) (*Player, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var outPlayer unsafe.Pointer
+	errStr := C.tracker_load_module(cPath, (*unsafe.Pointer)(unsafe.Pointer(&outPlayer)))
+	if errStr != nil {
+		return nil, errors.New(C.GoString(errStr))
+	}
+	if outPlayer == nil {
+		return nil, errors.New("module load returned null player")
+	}
+
+	return &Player{ptr: outPlayer}, nil
+}
+
+// GetNodePtr returns the underlying AVAudioNode pointer for connecting this
+// player into an avaudio/engine graph (e.g. via Engine.ConnectWithTypedFormat).
+func (p *Player) GetNodePtr() (unsafe.Pointer, error) {
+	if p == nil || p.ptr == nil {
+		return nil, errors.New("player is nil")
+	}
+
+	var node unsafe.Pointer
+	errStr := C.tracker_get_node_ptr(p.ptr, (*unsafe.Pointer)(unsafe.Pointer(&node)))
+	if errStr != nil {
+		return nil, errors.New(C.GoString(errStr))
+	}
+	return node, nil
+}
+
+// SetPosition jumps playback to the given order and row in the module.
+func (p *Player) SetPosition(order, row int) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if errStr := C.tracker_set_position(p.ptr, C.int(order), C.int(row)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetTempo overrides the module's tempo in beats per minute.
+func (p *Player) SetTempo(bpm int) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if errStr := C.tracker_set_tempo(p.ptr, C.int(bpm)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetModuleLoop sets how many times the module restarts after reaching its
+// end; 0 disables looping, -1 loops indefinitely.
+func (p *Player) SetModuleLoop(count int) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if errStr := C.tracker_set_loop_count(p.ptr, C.int(count)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetChannelMute mutes or unmutes one of the module's pattern channels.
+func (p *Player) SetChannelMute(channel int, mute bool) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if errStr := C.tracker_set_channel_mute(p.ptr, C.int(channel), C.bool(mute)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetChannelSolo solos or un-solos one of the module's pattern channels;
+// soloing one channel implicitly mutes every channel not currently soloed.
+func (p *Player) SetChannelSolo(channel int, solo bool) error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+	if errStr := C.tracker_set_channel_solo(p.ptr, C.int(channel), C.bool(solo)); errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// OnRowEvent registers a callback invoked on every new row libxmp triggers,
+// starting a background poll goroutine the first time it's called. Pass nil
+// to stop delivering events without closing the player.
+func (p *Player) OnRowEvent(cb func(RowEvent)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onRow = cb
+	if cb == nil || p.polling {
+		return
+	}
+	p.polling = true
+	p.stop = make(chan struct{})
+	p.wg.Add(1)
+	go p.pollLoop(p.stop)
+}
+
+func (p *Player) pollLoop(stop chan struct{}) {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		var order, row, pattern C.int
+		var hasEvent C.bool
+		errStr := C.tracker_poll_event(p.ptr, &order, &row, &pattern, &hasEvent)
+		if errStr != nil {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		if bool(hasEvent) {
+			p.mu.Lock()
+			cb := p.onRow
+			p.mu.Unlock()
+			if cb != nil {
+				cb(RowEvent{Order: int(order), Row: int(row), Pattern: int(pattern)})
+			}
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// Close stops event polling and releases the libxmp context.
+func (p *Player) Close() error {
+	if p == nil || p.ptr == nil {
+		return errors.New("player is nil")
+	}
+
+	p.mu.Lock()
+	if p.polling {
+		close(p.stop)
+		p.polling = false
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+
+	errStr := C.tracker_destroy(p.ptr)
+	p.ptr = nil
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"sort"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// AutomationBreakpoint is one scheduled value for an AutomationLane, keyed
+// by the same AVAudioTime.hostTime tick count ScheduleParameter takes.
+type AutomationBreakpoint struct {
+	HostTime uint64
+	Value    float32
+}
+
+// AutomationLane stores a sorted list of (hostTime, value) breakpoints for
+// one parameter and flushes the ones that have come due against an Effect.
+// This is the Go-side bookkeeping layer above ScheduleParameter's one-shot
+// native event: a host's render callback builds up a lane ahead of time
+// (e.g. the whole automation curve for a song section) and calls Flush once
+// per render cycle, rather than issuing a fresh ScheduleParameter call per
+// sample-accurate breakpoint as it goes.
+type AutomationLane struct {
+	param       plugins.Parameter
+	breakpoints []AutomationBreakpoint
+}
+
+// NewAutomationLane creates an empty lane for param.
+func NewAutomationLane(param plugins.Parameter) *AutomationLane {
+	return &AutomationLane{param: param}
+}
+
+// Add inserts a breakpoint at hostTime/value, keeping the lane sorted by
+// hostTime. Adding a second breakpoint at the same hostTime replaces the
+// first rather than stacking both.
+func (lane *AutomationLane) Add(hostTime uint64, value float32) {
+	for i, bp := range lane.breakpoints {
+		if bp.HostTime == hostTime {
+			lane.breakpoints[i].Value = value
+			return
+		}
+	}
+	lane.breakpoints = append(lane.breakpoints, AutomationBreakpoint{HostTime: hostTime, Value: value})
+	sort.Slice(lane.breakpoints, func(i, j int) bool {
+		return lane.breakpoints[i].HostTime < lane.breakpoints[j].HostTime
+	})
+}
+
+// Breakpoints returns the lane's breakpoints in hostTime order. The
+// returned slice is owned by the lane and must not be modified.
+func (lane *AutomationLane) Breakpoints() []AutomationBreakpoint {
+	return lane.breakpoints
+}
+
+// Flush schedules every breakpoint with HostTime <= currentHostTime against
+// effect via ScheduleParameter, in hostTime order, and removes them from the
+// lane - so a render-cycle driver (see pluginchain.PluginChain.Render) can
+// call Flush once per cycle without re-sending a breakpoint it already
+// applied. It returns the number of breakpoints flushed and the first
+// scheduling error encountered, if any; a failed breakpoint is still
+// removed from the lane, matching ScheduleParameter's own stance that a
+// hostTime in the past is best-effort applied rather than retried.
+func (lane *AutomationLane) Flush(effect *Effect, currentHostTime uint64) (int, error) {
+	due := 0
+	for due < len(lane.breakpoints) && lane.breakpoints[due].HostTime <= currentHostTime {
+		due++
+	}
+	if due == 0 {
+		return 0, nil
+	}
+
+	var firstErr error
+	for _, bp := range lane.breakpoints[:due] {
+		if err := effect.ScheduleParameter(lane.param, bp.Value, bp.HostTime); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	lane.breakpoints = lane.breakpoints[due:]
+	return due, firstErr
+}
@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// Environment owns a set of activated Effects keyed by a caller-chosen ID,
+// guarding the underlying AU pointers against concurrent access from
+// multiple goroutines - a UI thread toggling bypass, a MIDI thread driving
+// parameters, an RPC handler saving presets - the way CreateEffect/Release
+// alone leave entirely up to the caller (see TestEffectLifecycle's
+// use-after-release case, which Environment makes impossible to hit by
+// accident rather than just detectable after the fact).
+type Environment struct {
+	mu sync.Mutex
+
+	effects map[string]*Effect
+	// order records activation order so Shutdown can release in reverse,
+	// the same convention defer unwinding uses.
+	order []string
+}
+
+// NewEnvironment creates an empty Environment.
+func NewEnvironment() *Environment {
+	return &Environment{
+		effects: make(map[string]*Effect),
+	}
+}
+
+// Activate creates an effect from plugin via CreateEffect and registers it
+// under id. It returns an error without creating anything if id is already
+// active - a caller that wants to replace an active effect must Deactivate
+// it first.
+func (env *Environment) Activate(plugin *plugins.Plugin, id string) (*Effect, error) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	if _, exists := env.effects[id]; exists {
+		return nil, fmt.Errorf("effect %q is already active", id)
+	}
+
+	effect, err := CreateEffect(plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	env.effects[id] = effect
+	env.order = append(env.order, id)
+	return effect, nil
+}
+
+// Deactivate releases the effect registered under id and removes it from
+// the environment. Deactivating an id that isn't active is not an error,
+// so a caller doesn't need to track active state itself before cleaning
+// up - this also makes a duplicate Deactivate call (double-release) safe.
+func (env *Environment) Deactivate(id string) error {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	return env.deactivateLocked(id)
+}
+
+// deactivateLocked is Deactivate's body, factored out so Shutdown can reuse
+// it while already holding env.mu.
+func (env *Environment) deactivateLocked(id string) error {
+	effect, exists := env.effects[id]
+	if !exists {
+		return nil
+	}
+	delete(env.effects, id)
+	env.removeFromOrder(id)
+
+	if err := effect.Release(); err != nil {
+		return fmt.Errorf("failed to release effect %q: %w", id, err)
+	}
+	return nil
+}
+
+// removeFromOrder deletes id's first occurrence from env.order. Caller
+// must hold env.mu.
+func (env *Environment) removeFromOrder(id string) {
+	for i, existing := range env.order {
+		if existing == id {
+			env.order = append(env.order[:i], env.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// Active returns every currently-active effect, in activation order. The
+// returned slice is a snapshot - later Activate/Deactivate calls don't
+// retroactively change it.
+func (env *Environment) Active() []*Effect {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	effects := make([]*Effect, 0, len(env.order))
+	for _, id := range env.order {
+		effects = append(effects, env.effects[id])
+	}
+	return effects
+}
+
+// Shutdown releases every active effect in reverse activation order (last
+// activated, first released - the same teardown order a stack of deferred
+// Releases would produce) and clears the environment. The first release
+// error encountered is returned after every effect has been attempted;
+// Shutdown always leaves the environment empty regardless of errors.
+func (env *Environment) Shutdown() error {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	var firstErr error
+	for i := len(env.order) - 1; i >= 0; i-- {
+		id := env.order[i]
+		effect, exists := env.effects[id]
+		if !exists {
+			continue
+		}
+		if err := effect.Release(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to release effect %q: %w", id, err)
+		}
+	}
+	env.effects = make(map[string]*Effect)
+	env.order = nil
+	return firstErr
+}
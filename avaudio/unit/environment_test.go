@@ -0,0 +1,128 @@
+package unit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// testEnvironmentPlugin returns an Apple AU effect plugin suitable for
+// Environment tests, skipping the test if none is available.
+func testEnvironmentPlugin(t *testing.T) *plugins.Plugin {
+	t.Helper()
+
+	pluginList, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+	effects := pluginList.ByManufacturer("appl").ByType("aufx")
+	if len(effects) == 0 {
+		t.Skip("No Apple AU effects found for environment test")
+	}
+
+	plg, err := effects[0].Introspect()
+	if err != nil {
+		t.Fatalf("Failed to introspect plugin: %v", err)
+	}
+	return plg
+}
+
+func TestEnvironmentActivateDeactivate(t *testing.T) {
+	plg := testEnvironmentPlugin(t)
+	env := NewEnvironment()
+
+	effect, err := env.Activate(plg, "a")
+	if err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	if effect.Ptr() == nil {
+		t.Fatal("expected activated effect to have a valid native pointer")
+	}
+
+	if _, err := env.Activate(plg, "a"); err == nil {
+		t.Error("expected Activate on an already-active id to fail")
+	}
+
+	if len(env.Active()) != 1 {
+		t.Errorf("expected 1 active effect, got %d", len(env.Active()))
+	}
+
+	if err := env.Deactivate("a"); err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+	if len(env.Active()) != 0 {
+		t.Errorf("expected 0 active effects after Deactivate, got %d", len(env.Active()))
+	}
+
+	// Double-deactivate and deactivating an unknown id must both be safe
+	// no-ops.
+	if err := env.Deactivate("a"); err != nil {
+		t.Errorf("expected repeat Deactivate to be a no-op, got %v", err)
+	}
+	if err := env.Deactivate("never-activated"); err != nil {
+		t.Errorf("expected Deactivate of an unknown id to be a no-op, got %v", err)
+	}
+
+	// The id is free again after Deactivate.
+	if _, err := env.Activate(plg, "a"); err != nil {
+		t.Errorf("expected Activate to succeed again after Deactivate, got %v", err)
+	}
+}
+
+func TestEnvironmentShutdownReverseOrder(t *testing.T) {
+	plg := testEnvironmentPlugin(t)
+	env := NewEnvironment()
+
+	ids := []string{"a", "b", "c"}
+	for _, id := range ids {
+		if _, err := env.Activate(plg, id); err != nil {
+			t.Fatalf("Activate(%s): %v", id, err)
+		}
+	}
+
+	if got := len(env.order); got != len(ids) {
+		t.Fatalf("expected activation order to track %d ids, got %d", len(ids), got)
+	}
+	for i, id := range ids {
+		if env.order[i] != id {
+			t.Errorf("expected activation order %v, got %v", ids, env.order)
+			break
+		}
+	}
+
+	if err := env.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if len(env.Active()) != 0 {
+		t.Errorf("expected an empty environment after Shutdown, got %d active", len(env.Active()))
+	}
+}
+
+func TestEnvironmentConcurrentActivateDeactivate(t *testing.T) {
+	plg := testEnvironmentPlugin(t)
+	env := NewEnvironment()
+
+	const n = 16
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("effect-%d", i)
+			if _, err := env.Activate(plg, id); err != nil {
+				t.Errorf("Activate(%s): %v", id, err)
+				return
+			}
+			if err := env.Deactivate(id); err != nil {
+				t.Errorf("Deactivate(%s): %v", id, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(env.Active()) != 0 {
+		t.Errorf("expected no active effects after concurrent activate/deactivate, got %d", len(env.Active()))
+	}
+}
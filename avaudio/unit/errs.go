@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrFormatMismatch is the classified cause of a SetParameter failure when
+// AVFoundation rejects a value because it doesn't match the parameter's
+// expected unit/range, as opposed to the effect having been released.
+var ErrFormatMismatch = errors.New("unit: parameter value mismatch")
+
+// ErrUnitLoadFailed reports that CreateEffect's native
+// AudioComponentInstantiate (or the AVAudioUnitEffect wrapping it) rejected
+// a plugin's (type, subtype, manufacturer) OSType triple. ManufacturerID and
+// Subtype echo the plugin's four-character codes; OSStatus and Underlying
+// carry whatever the CGO boundary reported (see UnitResult in unit.go) and
+// may be zero/nil until native/unit.m (absent from this tree) populates
+// them.
+type ErrUnitLoadFailed struct {
+	ManufacturerID string
+	Subtype        string
+	OSStatus       int32
+	Underlying     error
+}
+
+func (e *ErrUnitLoadFailed) Error() string {
+	if e.Underlying != nil {
+		return fmt.Sprintf("unit: failed to load effect (manufacturer %s, subtype %s, OSStatus %d): %v",
+			e.ManufacturerID, e.Subtype, e.OSStatus, e.Underlying)
+	}
+	return fmt.Sprintf("unit: failed to load effect (manufacturer %s, subtype %s, OSStatus %d)",
+		e.ManufacturerID, e.Subtype, e.OSStatus)
+}
+
+func (e *ErrUnitLoadFailed) Unwrap() error { return e.Underlying }
+
+// unitResultError builds an error from a failed UnitResult's raw error
+// string plus its NSError domain/code, when the native layer has been
+// extended to report them (see the UnitResult typedef in unit.go) - an empty
+// domain falls back to just the error string, and a wholly empty result
+// falls back to nil.
+func unitResultError(msg, nsErrorDomain string, nsErrorCode int64) error {
+	if nsErrorDomain != "" {
+		return fmt.Errorf("%s (%s code %d)", msg, nsErrorDomain, nsErrorCode)
+	}
+	if msg == "" {
+		return nil
+	}
+	return errors.New(msg)
+}
+
+// classifyUnitError maps a raw error string crossing the CGO boundary onto
+// ErrFormatMismatch when it recognizes a known AVFoundation message, so
+// callers can use errors.Is instead of matching the message text
+// themselves. Falls back to errors.New(msg) when nothing matches.
+func classifyUnitError(msg string) error {
+	if strings.Contains(msg, "format") || strings.Contains(msg, "Format") {
+		return fmt.Errorf("%w: %s", ErrFormatMismatch, msg)
+	}
+	return errors.New(msg)
+}
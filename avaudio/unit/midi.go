@@ -0,0 +1,53 @@
+package unit
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+#include <stdlib.h>
+
+// send_effect_midi_event is declared here, not implemented in this tree
+// yet - it needs a small addition to native/unit.m wiring effectPtr through
+// to AVAudioUnitMIDIInstrument's sendMIDIEvent:data1:data2:, the same API
+// every AU instrument host uses to drive Note On/Off and CC. The Go-side
+// API below is complete and ready to use the moment that shim exists.
+const char* send_effect_midi_event(void* effectPtr, uint8_t status, uint8_t data1, uint8_t data2);
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	midiStatusNoteOff = 0x80
+	midiStatusNoteOn  = 0x90
+)
+
+// SendMIDIEvent sends a raw 3-byte MIDI channel message (status, data1,
+// data2) to this effect's AU via AVAudioUnitMIDIInstrument.sendMIDIEvent:,
+// for instrument (aumu) or MIDI-effect (aumf) AudioUnits that accept live
+// MIDI input - see plugins.Plugin.Type and PluginChain.AcceptsMIDI in the
+// root engine package.
+func (e *Effect) SendMIDIEvent(status, data1, data2 byte) error {
+	if e.ptr == nil {
+		return fmt.Errorf("effect has been released")
+	}
+
+	errorStr := C.send_effect_midi_event(e.ptr, C.uint8_t(status), C.uint8_t(data1), C.uint8_t(data2))
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// SendNoteOn sends a Note On message on channel (0-15) for note/velocity
+// (each 0-127).
+func (e *Effect) SendNoteOn(channel, note, velocity int) error {
+	return e.SendMIDIEvent(byte(midiStatusNoteOn|channel&0x0F), byte(note), byte(velocity))
+}
+
+// SendNoteOff sends a Note Off message on channel (0-15) for note (0-127),
+// with velocity (0-127) as the release velocity.
+func (e *Effect) SendNoteOff(channel, note, velocity int) error {
+	return e.SendMIDIEvent(byte(midiStatusNoteOff|channel&0x0F), byte(note), byte(velocity))
+}
@@ -0,0 +1,226 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// Preset captures an Effect's full parameter state plus bypass, JSON
+// marshalable so it round-trips through WriteFile/ReadFile unchanged. It
+// identifies the plugin it was saved from by the same manufacturer/type/
+// subtype/version fields plugins.Plugin.Introspect reports, so LoadPreset
+// can refuse to apply a preset saved from a different plugin - see
+// engine.Preset for this package's older, .aupreset-plist-based sibling;
+// this one is this package's own, keyed to Effect rather than EnginePlugin.
+type Preset struct {
+	Name           string             `json:"name"`
+	ManufacturerID string             `json:"manufacturerID"`
+	Type           string             `json:"type"`
+	Subtype        string             `json:"subtype"`
+	Version        string             `json:"version,omitempty"`
+	Parameters     map[uint64]float32 `json:"parameters"`
+	Bypassed       bool               `json:"bypassed"`
+}
+
+// matchesPlugin reports whether plugin is the same AU this preset was saved
+// from. Version only needs to agree when both sides specify one, so a
+// preset saved before Plugin.Version was populated still loads.
+func (p *Preset) matchesPlugin(plugin *plugins.Plugin) bool {
+	if plugin == nil {
+		return false
+	}
+	if p.ManufacturerID != plugin.ManufacturerID || p.Type != plugin.Type || p.Subtype != plugin.Subtype {
+		return false
+	}
+	if p.Version != "" && plugin.Version != "" && p.Version != plugin.Version {
+		return false
+	}
+	return true
+}
+
+// SavePreset captures this effect's current parameter values and bypass
+// state into a Preset, keyed by the plugin identity LoadPreset validates
+// against.
+func (e *Effect) SavePreset() (*Preset, error) {
+	if e.ptr == nil {
+		return nil, fmt.Errorf("effect has been released")
+	}
+	if e.plugin == nil {
+		return nil, fmt.Errorf("effect has no plugin metadata to save a preset from")
+	}
+
+	bypassed, err := e.IsBypassed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bypass state: %w", err)
+	}
+
+	params := make(map[uint64]float32, len(e.plugin.Parameters))
+	var failures []string
+	for _, param := range e.plugin.Parameters {
+		value, err := e.GetParameter(param)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", param.DisplayName, err))
+			continue
+		}
+		params[param.Address] = value
+	}
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("failed to read %d parameter(s): %s", len(failures), strings.Join(failures, "; "))
+	}
+
+	return &Preset{
+		Name:           e.plugin.Name,
+		ManufacturerID: e.plugin.ManufacturerID,
+		Type:           e.plugin.Type,
+		Subtype:        e.plugin.Subtype,
+		Version:        e.plugin.Version,
+		Parameters:     params,
+		Bypassed:       bypassed,
+	}, nil
+}
+
+// LoadPreset validates preset against this effect's plugin identity, then
+// applies every parameter it captured through the existing SetParameter
+// path (range-clamped against each parameter's Min/MaxValue) plus bypass
+// state. Errors from individual parameters are aggregated rather than
+// aborting partway through, so one rejected value doesn't leave the rest of
+// the preset unapplied.
+func (e *Effect) LoadPreset(preset *Preset) error {
+	if e.ptr == nil {
+		return fmt.Errorf("effect has been released")
+	}
+	if e.plugin == nil {
+		return fmt.Errorf("effect has no plugin metadata to validate a preset against")
+	}
+	if !preset.matchesPlugin(e.plugin) {
+		return fmt.Errorf("preset %q (manufacturer %s, type %s, subtype %s) does not match this effect's plugin (manufacturer %s, type %s, subtype %s)",
+			preset.Name, preset.ManufacturerID, preset.Type, preset.Subtype,
+			e.plugin.ManufacturerID, e.plugin.Type, e.plugin.Subtype)
+	}
+
+	var failures []string
+	for _, param := range e.plugin.Parameters {
+		value, ok := preset.Parameters[param.Address]
+		if !ok {
+			continue
+		}
+		if value < param.MinValue {
+			value = param.MinValue
+		} else if value > param.MaxValue {
+			value = param.MaxValue
+		}
+		if err := e.SetParameter(param, value); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", param.DisplayName, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to apply %d parameter(s): %s", len(failures), strings.Join(failures, "; "))
+	}
+
+	return e.SetBypass(preset.Bypassed)
+}
+
+// WriteFile writes p to path as indented JSON.
+func (p *Preset) WriteFile(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal preset: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write preset file: %w", err)
+	}
+	return nil
+}
+
+// ReadFile reads a Preset previously written by Preset.WriteFile.
+func ReadFile(path string) (*Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preset file: %w", err)
+	}
+	var preset Preset
+	if err := json.Unmarshal(data, &preset); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal preset: %w", err)
+	}
+	return &preset, nil
+}
+
+// PresetBank holds multiple named presets for one effect and tracks which
+// one is currently active, so switching presets is a single atomic
+// reassignment of the active name (guarded the same way SetParameter's
+// caller is expected to serialize access to an Effect) rather than the
+// caller juggling a map and an index variable itself.
+type PresetBank struct {
+	Name    string             `json:"name"`
+	Presets map[string]*Preset `json:"presets"`
+	Active  string             `json:"active,omitempty"`
+}
+
+// NewPresetBank creates an empty, named PresetBank.
+func NewPresetBank(name string) *PresetBank {
+	return &PresetBank{
+		Name:    name,
+		Presets: make(map[string]*Preset),
+	}
+}
+
+// Add stores preset under name, overwriting any existing preset with that
+// name. It does not change Active.
+func (b *PresetBank) Add(name string, preset *Preset) {
+	b.Presets[name] = preset
+}
+
+// Remove deletes the preset stored under name. Clears Active if name was
+// the active preset.
+func (b *PresetBank) Remove(name string) {
+	delete(b.Presets, name)
+	if b.Active == name {
+		b.Active = ""
+	}
+}
+
+// Switch applies the preset stored under name to effect via Effect.
+// LoadPreset, and only then updates b.Active - a failed LoadPreset (a
+// format mismatch, say) leaves the bank's Active preset untouched rather
+// than recording a switch that didn't actually take effect.
+func (b *PresetBank) Switch(effect *Effect, name string) error {
+	preset, ok := b.Presets[name]
+	if !ok {
+		return fmt.Errorf("preset bank %q has no preset named %q", b.Name, name)
+	}
+	if err := effect.LoadPreset(preset); err != nil {
+		return fmt.Errorf("failed to switch to preset %q: %w", name, err)
+	}
+	b.Active = name
+	return nil
+}
+
+// WriteFile writes b to path as indented JSON.
+func (b *PresetBank) WriteFile(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal preset bank: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write preset bank file: %w", err)
+	}
+	return nil
+}
+
+// ReadBankFile reads a PresetBank previously written by PresetBank.
+// WriteFile.
+func ReadBankFile(path string) (*PresetBank, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preset bank file: %w", err)
+	}
+	var bank PresetBank
+	if err := json.Unmarshal(data, &bank); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal preset bank: %w", err)
+	}
+	return &bank, nil
+}
@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// TestEffectSaveLoadPreset covers the SavePreset/LoadPreset round trip,
+// including the parameter-range clamp and the plugin-identity check
+// LoadPreset refuses to skip.
+func TestEffectSaveLoadPreset(t *testing.T) {
+	pluginList, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+	effects := pluginList.ByManufacturer("appl").ByType("aufx")
+	if len(effects) == 0 {
+		t.Skip("No Apple AU effects found for preset test")
+	}
+
+	plg, err := effects[0].Introspect()
+	if err != nil {
+		t.Fatalf("Failed to introspect plugin: %v", err)
+	}
+	if len(plg.Parameters) == 0 {
+		t.Skip("Effect has no parameters to save/load")
+	}
+
+	eff, err := CreateEffect(plg)
+	if err != nil {
+		t.Fatalf("Failed to create effect: %v", err)
+	}
+	defer eff.Release()
+
+	preset, err := eff.SavePreset()
+	if err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+	if preset.ManufacturerID != plg.ManufacturerID || preset.Type != plg.Type || preset.Subtype != plg.Subtype {
+		t.Errorf("preset identity does not match plugin: %+v", preset)
+	}
+	if len(preset.Parameters) != len(plg.Parameters) {
+		t.Errorf("expected %d saved parameters, got %d", len(plg.Parameters), len(preset.Parameters))
+	}
+
+	// Force an out-of-range value and confirm LoadPreset clamps rather than
+	// erroring or passing it straight through to the native call.
+	param := plg.Parameters[0]
+	preset.Parameters[param.Address] = param.MaxValue + 1000
+
+	if err := eff.LoadPreset(preset); err != nil {
+		t.Fatalf("LoadPreset: %v", err)
+	}
+	got, err := eff.GetParameter(param)
+	if err != nil {
+		t.Fatalf("GetParameter: %v", err)
+	}
+	if got != param.MaxValue {
+		t.Errorf("expected out-of-range value clamped to %v, got %v", param.MaxValue, got)
+	}
+
+	// Mismatched plugin identity must be rejected.
+	mismatched := *preset
+	mismatched.Type = "zzzz"
+	if err := eff.LoadPreset(&mismatched); err == nil {
+		t.Error("expected LoadPreset to reject a preset with mismatched plugin identity")
+	}
+}
+
+// TestPresetWriteReadFile covers the JSON round trip through disk.
+func TestPresetWriteReadFile(t *testing.T) {
+	preset := &Preset{
+		Name:           "Test Preset",
+		ManufacturerID: "appl",
+		Type:           "aufx",
+		Subtype:        "test",
+		Parameters:     map[uint64]float32{1: 0.5, 2: 1.0},
+		Bypassed:       true,
+	}
+
+	path := filepath.Join(t.TempDir(), "preset.json")
+	if err := preset.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	loaded, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if loaded.Name != preset.Name || loaded.Bypassed != preset.Bypassed {
+		t.Errorf("loaded preset does not match saved preset: %+v", loaded)
+	}
+	if len(loaded.Parameters) != len(preset.Parameters) {
+		t.Errorf("expected %d parameters, got %d", len(preset.Parameters), len(loaded.Parameters))
+	}
+}
+
+// TestPresetBankSwitch covers PresetBank's atomic switch semantics: a
+// failed Switch must leave Active untouched.
+func TestPresetBankSwitch(t *testing.T) {
+	pluginList, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+	effects := pluginList.ByManufacturer("appl").ByType("aufx")
+	if len(effects) == 0 {
+		t.Skip("No Apple AU effects found for preset bank test")
+	}
+
+	plg, err := effects[0].Introspect()
+	if err != nil {
+		t.Fatalf("Failed to introspect plugin: %v", err)
+	}
+
+	eff, err := CreateEffect(plg)
+	if err != nil {
+		t.Fatalf("Failed to create effect: %v", err)
+	}
+	defer eff.Release()
+
+	good, err := eff.SavePreset()
+	if err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+
+	bank := NewPresetBank("Test Bank")
+	bank.Add("good", good)
+	bank.Add("bad", &Preset{ManufacturerID: "appl", Type: "zzzz", Subtype: "none"})
+
+	if err := bank.Switch(eff, "good"); err != nil {
+		t.Fatalf("Switch(good): %v", err)
+	}
+	if bank.Active != "good" {
+		t.Errorf("expected Active %q, got %q", "good", bank.Active)
+	}
+
+	if err := bank.Switch(eff, "bad"); err == nil {
+		t.Error("expected Switch to a mismatched preset to fail")
+	}
+	if bank.Active != "good" {
+		t.Errorf("expected Active to remain %q after a failed switch, got %q", "good", bank.Active)
+	}
+
+	if err := bank.Switch(eff, "missing"); err == nil {
+		t.Error("expected Switch to an unknown preset name to fail")
+	}
+}
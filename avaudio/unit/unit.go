@@ -6,16 +6,41 @@ package unit
 #include "native/unit.m"
 #include <stdlib.h>
 
-// Function declarations - CGO resolves UnitResult from .m file
+// UnitResult is declared here rather than native/unit.m, which doesn't exist
+// in this tree yet (the package-wide native-shim gap other packages note,
+// e.g. engine/recording.go). It now carries the native OSStatus and
+// NSError.code/domain a failed AudioComponentInstantiate/AVAudioUnit call
+// reports, alongside the original void* / const char* pair, so CreateEffect
+// can build a typed ErrUnitLoadFailed instead of pattern-matching the error
+// string. Once native/unit.m exists it needs to populate osStatus/
+// nsErrorDomain/nsErrorCode on every failure path, not just error.
+typedef struct {
+	void* result;
+	const char* error;
+	int32_t osStatus;
+	const char* nsErrorDomain;
+	int64_t nsErrorCode;
+} UnitResult;
+
 UnitResult create_unit_effect(uint32_t type, uint32_t subtype, uint32_t manufacturer);
 const char* release_unit_effect(void* effectPtr);
 const char* set_effect_parameter(void* effectPtr, uint64_t address, float value);
 UnitResult get_effect_parameter(void* effectPtr, uint64_t address);
+const char* set_effect_bypass(void* effectPtr, int bypass);
+UnitResult get_effect_bypass(void* effectPtr);
+// Sample-accurate parameter automation, backed by AUParameterEvent /
+// scheduleParameterBlock: schedule_parameter_ramp schedules a ramp event
+// from the parameter's current value to target over durationSeconds;
+// schedule_parameter_event schedules an immediate-value event at hostTime
+// (an AVAudioTime.hostTime tick count - 0 means "as soon as possible").
+const char* schedule_parameter_ramp(void* effectPtr, uint64_t address, float target, double durationSeconds);
+const char* schedule_parameter_event(void* effectPtr, uint64_t address, float value, uint64_t hostTime);
 */
 import "C"
 import (
 	"errors"
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/shaban/macaudio/plugins"
@@ -35,11 +60,20 @@ func CreateEffect(plugin *plugins.Plugin) (*Effect, error) {
 	manufacturerID := stringToOSType(plugin.ManufacturerID)
 
 	result := C.create_unit_effect(C.uint32_t(typeID), C.uint32_t(subtypeID), C.uint32_t(manufacturerID))
-	if result.error != nil {
-		return nil, errors.New(C.GoString(result.error))
-	}
-	if result.result == nil {
-		return nil, fmt.Errorf("failed to create effect: %s by %s", plugin.Name, plugin.ManufacturerID)
+	if result.error != nil || result.result == nil {
+		var errMsg, nsDomain string
+		if result.error != nil {
+			errMsg = C.GoString(result.error)
+		}
+		if result.nsErrorDomain != nil {
+			nsDomain = C.GoString(result.nsErrorDomain)
+		}
+		return nil, &ErrUnitLoadFailed{
+			ManufacturerID: plugin.ManufacturerID,
+			Subtype:        plugin.Subtype,
+			OSStatus:       int32(result.osStatus),
+			Underlying:     unitResultError(errMsg, nsDomain, int64(result.nsErrorCode)),
+		}
 	}
 
 	return &Effect{
@@ -48,6 +82,22 @@ func CreateEffect(plugin *plugins.Plugin) (*Effect, error) {
 	}, nil
 }
 
+// CreateEffectPinned is CreateEffect, but first checks plugin against
+// catalog's pinned hash for plugin's Name/Version (see
+// plugins.Catalog.Pin), refusing to create the effect if the bundle no
+// longer matches what was pinned - the same guard a package manager's
+// lockfile hash provides against a dependency swapped out from under a
+// trusted version. A plugin the catalog has never pinned (or never
+// registered at all) is created exactly as CreateEffect would.
+func CreateEffectPinned(plugin *plugins.Plugin, catalog *plugins.Catalog) (*Effect, error) {
+	if catalog != nil {
+		if err := catalog.Verify(plugin); err != nil {
+			return nil, err
+		}
+	}
+	return CreateEffect(plugin)
+}
+
 // Release frees the effect resources
 func (e *Effect) Release() error {
 	if e.ptr != nil {
@@ -68,7 +118,7 @@ func (e *Effect) SetParameter(param plugins.Parameter, value float32) error {
 
 	errorStr := C.set_effect_parameter(e.ptr, C.uint64_t(param.Address), C.float(value))
 	if errorStr != nil {
-		return errors.New(C.GoString(errorStr))
+		return classifyUnitError(C.GoString(errorStr))
 	}
 	return nil
 }
@@ -92,6 +142,78 @@ func (e *Effect) GetParameter(param plugins.Parameter) (float32, error) {
 	return value, nil
 }
 
+// SetBypass sets whether this effect's AudioUnit is bypassed, i.e. passes
+// its input straight through without applying its DSP. See
+// pluginchain.PluginChain.SetEffectBypass for the chain-level equivalent
+// that additionally disconnects a bypassed effect's node from the graph
+// entirely rather than leaving it wired in and bypassed in place.
+func (e *Effect) SetBypass(bypass bool) error {
+	if e.ptr == nil {
+		return fmt.Errorf("effect has been released")
+	}
+
+	var cBypass C.int
+	if bypass {
+		cBypass = 1
+	}
+	errorStr := C.set_effect_bypass(e.ptr, cBypass)
+	if errorStr != nil {
+		return classifyUnitError(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// IsBypassed reports whether this effect's AudioUnit is currently bypassed.
+func (e *Effect) IsBypassed() (bool, error) {
+	if e.ptr == nil {
+		return false, fmt.Errorf("effect has been released")
+	}
+
+	result := C.get_effect_bypass(e.ptr)
+	if result.error != nil {
+		return false, errors.New(C.GoString(result.error))
+	}
+
+	valuePtr := (*C.int)(result.result)
+	value := int32(*valuePtr)
+	C.free(result.result) // Free the malloc'd memory from native code
+
+	return value != 0, nil
+}
+
+// RampParameter schedules a sample-accurate ramp of param from its current
+// value to target over dur, via a native AUParameterEvent rather than a
+// Go-side loop of SetParameter calls - the same motivation as
+// avaudio/engine.AudioPlayer's Ramp* methods, one level down at the AU
+// parameter instead of the player node. See AutomationLane for building up
+// a multi-breakpoint envelope out of several scheduled events.
+func (e *Effect) RampParameter(param plugins.Parameter, target float32, dur time.Duration) error {
+	if e.ptr == nil {
+		return fmt.Errorf("effect has been released")
+	}
+	errorStr := C.schedule_parameter_ramp(e.ptr, C.uint64_t(param.Address), C.float(target), C.double(dur.Seconds()))
+	if errorStr != nil {
+		return classifyUnitError(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// ScheduleParameter schedules an immediate-value AUParameterEvent for param
+// at atHostTime (an AVAudioTime.hostTime tick count; 0 schedules it as soon
+// as possible rather than at a specific sample). A hostTime already in the
+// past is applied immediately by the native implementation rather than
+// rejected, the same way a ramp's target that's already reached just holds.
+func (e *Effect) ScheduleParameter(param plugins.Parameter, value float32, atHostTime uint64) error {
+	if e.ptr == nil {
+		return fmt.Errorf("effect has been released")
+	}
+	errorStr := C.schedule_parameter_event(e.ptr, C.uint64_t(param.Address), C.float(value), C.uint64_t(atHostTime))
+	if errorStr != nil {
+		return classifyUnitError(C.GoString(errorStr))
+	}
+	return nil
+}
+
 // GetPlugin returns the plugin metadata for this effect
 func (e *Effect) GetPlugin() *plugins.Plugin {
 	return e.plugin
@@ -0,0 +1,230 @@
+package macaudio
+
+import (
+	"fmt"
+	"sync"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// BusConfig configures a Bus at creation time.
+type BusConfig struct {
+	// Spec, if set, is the format every child's connection into this bus is
+	// negotiated against via ConnectWithSpec, instead of the child's native
+	// format (plain Connect). Leave nil to let AVAudioEngine infer the
+	// format from whatever connects first, same as other channel types.
+	Spec *avengine.EnhancedAudioSpec
+}
+
+// Bus is a sub-mixer that one or more channels can RouteTo, giving a "drums"/
+// "vocals"/"FX"-style group its own volume/pan/mute and a shared effects
+// chain (see InsertEffect) instead of duplicating inserts per channel. Unlike
+// AuxChannel's parallel sends, routing into a Bus replaces a channel's direct
+// path to master - see RouteTo.
+type Bus struct {
+	*BaseChannel
+
+	config BusConfig
+
+	// childBusMu guards nextChildBus, the next free input bus on outputMixer
+	// handed out to a channel RouteTo-ing into this bus (mirrors
+	// AuxChannel.allocateSendBus).
+	childBusMu   sync.Mutex
+	nextChildBus int
+
+	soloed bool
+}
+
+// NewBus creates a new bus channel with its own dedicated input mixer that
+// every routed-in channel connects into.
+func NewBus(name string, config BusConfig, engine *Engine) (*Bus, error) {
+	baseChannel := NewBaseChannel(name, ChannelTypeBus, engine)
+
+	avEngine := engine.getAVEngine()
+	outputMixer, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bus input mixer: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+
+	return &Bus{
+		BaseChannel: baseChannel,
+		config:      config,
+	}, nil
+}
+
+// allocateChildBus hands out the next free input bus on this bus's mixer to
+// a newly RouteTo-ing channel.
+func (b *Bus) allocateChildBus() int {
+	b.childBusMu.Lock()
+	defer b.childBusMu.Unlock()
+	bus := b.nextChildBus
+	b.nextChildBus++
+	return bus
+}
+
+// SetSolo marks this bus as soloed. While any bus in the engine is soloed,
+// IsChannelAudible treats every channel routed into a different bus as
+// silenced, without touching that sibling bus's own Muted flag - mirroring
+// a DAW mixer's solo-in-place. Channels that don't route through any bus are
+// unaffected by bus solo.
+func (b *Bus) SetSolo(solo bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.soloed = solo
+	return nil
+}
+
+// GetSolo returns whether this bus is currently soloed.
+func (b *Bus) GetSolo() (bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.soloed, nil
+}
+
+// GetState returns this bus's serializable state, adding Soloed to
+// BaseChannel.GetState()'s fields the same way MasterChannel.GetState adds
+// its own Config entries.
+func (b *Bus) GetState() ChannelState {
+	state := b.BaseChannel.GetState()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	state.Config = map[string]interface{}{
+		"soloed": b.soloed,
+	}
+	return state
+}
+
+// SetState restores this bus's bookkeeping from state, including Soloed.
+// Like BaseChannel.SetState this only restores bookkeeping fields; the
+// native mixer node itself already exists from NewBus and isn't touched.
+func (b *Bus) SetState(state ChannelState) error {
+	if err := b.BaseChannel.SetState(state); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if state.Config == nil {
+		return nil
+	}
+	if v, ok := state.Config["soloed"].(bool); ok {
+		b.soloed = v
+	}
+	return nil
+}
+
+// EffectNode names a plugin to insert into a Bus's shared effects chain -
+// the bus-level counterpart to PluginBlueprint, since an insert on a bus is
+// one plugin instance shared by every channel routed into it, rather than a
+// single channel's own chain.
+type EffectNode struct {
+	Blueprint PluginBlueprint
+}
+
+// InsertEffect appends effect to the end of this bus's plugin chain. Every
+// channel routed into the bus via RouteTo passes through it before the bus's
+// own mixdown, so one reverb/EQ instance can serve a whole group.
+func (b *Bus) InsertEffect(effect EffectNode) (*PluginInstance, error) {
+	return b.AddPlugin(effect.Blueprint, len(b.GetPluginChain().GetInstances()))
+}
+
+// RouteTo connects this channel's output into bus's input mixer, replacing
+// its direct path to master. When bus.config.Spec is set, the connection is
+// negotiated via ConnectWithSpec against that format instead of whatever
+// format this channel's mixer natively produces, so a bus can enforce a
+// common format across children that don't all match it.
+func (bc *BaseChannel) RouteTo(bus *Bus) error {
+	if bus == nil {
+		return fmt.Errorf("bus is nil")
+	}
+
+	if err := bc.ConnectTo(bus, 0); err != nil {
+		return err
+	}
+
+	if bc.engine == nil || bc.outputMixer == nil || bus.outputMixer == nil {
+		return nil // no native graph to wire yet (e.g. channel type with no mixer of its own)
+	}
+	avEngine := bc.engine.getAVEngine()
+	if avEngine == nil {
+		return nil
+	}
+
+	childBus := bus.allocateChildBus()
+	if bus.config.Spec != nil {
+		return avEngine.ConnectWithSpec(bc.outputMixer, bus.outputMixer, 0, childBus, *bus.config.Spec)
+	}
+	return avEngine.Connect(bc.outputMixer, bus.outputMixer, 0, childBus)
+}
+
+// busForChannel returns the Bus that channelID currently routes into via
+// RouteTo, if any.
+func (e *Engine) busForChannel(channelID string) (*Bus, bool) {
+	channel, ok := e.GetChannel(channelID)
+	if !ok {
+		return nil, false
+	}
+	for _, conn := range channel.GetConnections() {
+		if target, ok := e.GetChannel(conn.TargetChannel); ok {
+			if bus, ok := target.(*Bus); ok {
+				return bus, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// anyOtherBusSoloed reports whether some bus other than excludeID is
+// currently soloed.
+func (e *Engine) anyOtherBusSoloed(excludeID string) bool {
+	e.mu.RLock()
+	channels := make([]Channel, 0, len(e.channels))
+	for id, ch := range e.channels {
+		if id == excludeID {
+			continue
+		}
+		channels = append(channels, ch)
+	}
+	e.mu.RUnlock()
+
+	for _, ch := range channels {
+		if bus, ok := ch.(*Bus); ok {
+			if soloed, _ := bus.GetSolo(); soloed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsChannelAudible reports whether channelID's signal currently reaches the
+// master output, accounting for its own mute, when it routes through a Bus
+// (see RouteTo) that bus's own mute/solo state, and the engine's global
+// solo-in-place set (see SetSoloed). Soloing a bus silences every channel
+// routed into a different bus, leaving channels that don't route through
+// any bus unaffected; soloing any channel or ChannelGroup (see AssignChannel)
+// silences every channel that isn't itself soloed or nested under one that
+// is, on top of that.
+func (e *Engine) IsChannelAudible(channelID string) bool {
+	channel, ok := e.GetChannel(channelID)
+	if !ok {
+		return false
+	}
+	if muted, _ := channel.GetMute(); muted {
+		return false
+	}
+
+	if bus, ok := e.busForChannel(channelID); ok {
+		if muted, _ := bus.GetMute(); muted {
+			return false
+		}
+		if soloed, _ := bus.GetSolo(); !soloed && e.anyOtherBusSoloed(bus.GetIDString()) {
+			return false
+		}
+	}
+
+	return e.soloInPlaceAudible(channelID)
+}
@@ -0,0 +1,184 @@
+package macaudio
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// ChannelGroup is a VCA/DCA-style control group: channels assigned into it
+// (see AssignChannel) route through one shared submix mixer, so the group's
+// own SetVolume/SetPan/SetMute (inherited from BaseChannel) apply a
+// multiplicative offset on top of each member's own fader instead of
+// touching the member's stored Volume - the same "member's mixer feeds the
+// group's mixer" wiring Bus uses for RouteTo, just framed as a fader group
+// rather than a shared effects chain. Since ChannelGroup itself satisfies
+// Channel, AssignChannel-ing a group into another group nests VCAs for free.
+type ChannelGroup struct {
+	*BaseChannel
+
+	// memberBusMu guards nextMemberBus and members, mirroring Bus's
+	// childBusMu/nextChildBus (see AssignChannel).
+	memberBusMu   sync.Mutex
+	nextMemberBus int
+	members       []string
+
+	soloed bool
+}
+
+// NewChannelGroup creates a new control group with its own dedicated submix
+// mixer that every assigned member connects into.
+func NewChannelGroup(name string, engine *Engine) (*ChannelGroup, error) {
+	baseChannel := NewBaseChannel(name, ChannelTypeGroup, engine)
+
+	avEngine := engine.getAVEngine()
+	outputMixer, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group submix mixer: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+
+	return &ChannelGroup{BaseChannel: baseChannel}, nil
+}
+
+// groupMember is satisfied by every concrete channel type via its embedded
+// *BaseChannel, letting AssignChannel reach a member's native mixer node and
+// string ID through the Channel interface, the way RouteTo reaches
+// bc.outputMixer directly through its own *BaseChannel receiver.
+type groupMember interface {
+	GetIDString() string
+	getOutputMixer() unsafe.Pointer
+}
+
+// AssignChannel routes ch's output into this group's submix mixer, so the
+// group's own fader applies on top of ch's fader without touching ch's
+// stored Volume. ch may be another ChannelGroup, which is how groups nest.
+func (g *ChannelGroup) AssignChannel(ch Channel) error {
+	if ch == nil {
+		return fmt.Errorf("channel is nil")
+	}
+	member, ok := ch.(groupMember)
+	if !ok {
+		return fmt.Errorf("channel type does not support group assignment")
+	}
+	if member.GetIDString() == g.GetIDString() {
+		return fmt.Errorf("a group cannot be assigned to itself")
+	}
+
+	if err := ch.ConnectTo(g, 0); err != nil {
+		return err
+	}
+
+	g.memberBusMu.Lock()
+	g.members = append(g.members, member.GetIDString())
+	memberBus := g.nextMemberBus
+	g.nextMemberBus++
+	g.memberBusMu.Unlock()
+
+	if g.engine == nil || g.outputMixer == nil || member.getOutputMixer() == nil {
+		return nil // no native graph to wire yet (e.g. channel type with no mixer of its own)
+	}
+	avEngine := g.engine.getAVEngine()
+	if avEngine == nil {
+		return nil
+	}
+	return avEngine.Connect(member.getOutputMixer(), g.outputMixer, 0, memberBus)
+}
+
+// GetMembers returns the IDs of every channel currently assigned into this
+// group, in assignment order.
+func (g *ChannelGroup) GetMembers() []string {
+	g.memberBusMu.Lock()
+	defer g.memberBusMu.Unlock()
+	members := make([]string, len(g.members))
+	copy(members, g.members)
+	return members
+}
+
+// SetSolo marks this group as soloed in the engine's global solo-in-place
+// set (see Engine.SetSoloed) - soloing a group silences every channel and
+// group that isn't itself soloed or nested under one that is.
+func (g *ChannelGroup) SetSolo(solo bool) error {
+	g.mu.Lock()
+	g.soloed = solo
+	g.mu.Unlock()
+	if g.engine != nil {
+		g.engine.SetSoloed(g.GetIDString(), solo)
+	}
+	return nil
+}
+
+// GetSolo returns whether this group is currently soloed.
+func (g *ChannelGroup) GetSolo() (bool, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.soloed, nil
+}
+
+// groupForChannel returns the ChannelGroup that channelID is currently
+// assigned into via AssignChannel, if any - the group counterpart to
+// busForChannel.
+func (e *Engine) groupForChannel(channelID string) (*ChannelGroup, bool) {
+	channel, ok := e.GetChannel(channelID)
+	if !ok {
+		return nil, false
+	}
+	for _, conn := range channel.GetConnections() {
+		if target, ok := e.GetChannel(conn.TargetChannel); ok {
+			if group, ok := target.(*ChannelGroup); ok {
+				return group, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// SetSoloed marks channelID - a plain channel, Bus, or ChannelGroup - as
+// soloed or not in the engine's global solo-in-place set. While any channel
+// or group is in this set, IsChannelAudible treats every channel that isn't
+// itself soloed, and isn't nested under a soloed group, as silenced. This is
+// additive to, and independent of, Bus's own SetSolo/anyOtherBusSoloed
+// mechanism, which only ever compares sibling buses.
+func (e *Engine) SetSoloed(channelID string, soloed bool) {
+	e.soloMu.Lock()
+	defer e.soloMu.Unlock()
+	if soloed {
+		e.soloedIDs[channelID] = true
+	} else {
+		delete(e.soloedIDs, channelID)
+	}
+}
+
+// IsSoloed reports whether channelID is in the global solo-in-place set.
+func (e *Engine) IsSoloed(channelID string) bool {
+	e.soloMu.Lock()
+	defer e.soloMu.Unlock()
+	return e.soloedIDs[channelID]
+}
+
+// anySoloed reports whether any channel or group is currently in the global
+// solo-in-place set.
+func (e *Engine) anySoloed() bool {
+	e.soloMu.Lock()
+	defer e.soloMu.Unlock()
+	return len(e.soloedIDs) > 0
+}
+
+// soloInPlaceAudible reports whether channelID passes the engine's global
+// solo-in-place gate: audible when nothing is soloed, when channelID itself
+// is soloed, or when channelID is nested (via AssignChannel) under a
+// soloed ancestor group.
+func (e *Engine) soloInPlaceAudible(channelID string) bool {
+	if !e.anySoloed() {
+		return true
+	}
+	if e.IsSoloed(channelID) {
+		return true
+	}
+	for group, ok := e.groupForChannel(channelID); ok; group, ok = e.groupForChannel(group.GetIDString()) {
+		if e.IsSoloed(group.GetIDString()) {
+			return true
+		}
+	}
+	return false
+}
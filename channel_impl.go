@@ -1,12 +1,25 @@
 package macaudio
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"strings"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/google/uuid"
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/events"
 	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/avaudio/unit"
+	"github.com/shaban/macaudio/denoise"
+	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/plugins"
+	"github.com/shaban/macaudio/replaygain"
+	"github.com/shaban/macaudio/stream"
+	"github.com/shaban/macaudio/waveform"
 )
 
 // BaseChannel provides common functionality for all channel types
@@ -28,17 +41,182 @@ type BaseChannel struct {
 
 	// AVFoundation integration
 	outputMixer unsafe.Pointer // AVAudioMixerNode for this channel
+	preFaderTap unsafe.Pointer // Optional AVAudioMixerNode fed before outputMixer's gain stage, for pre-fader sends (see AddSend)
 
 	// Connections
 	mu          sync.RWMutex
 	connections []Connection
 	isRunning   bool
+	sends       []ChannelSend
+
+	// outputRouting is this channel's last SetOutputRouting assignment (see
+	// outputs.go); the zero value routes through mainMixer's bus 0 exactly
+	// as every channel did before OutputRoutingOptions existed.
+	outputRouting OutputRoutingOptions
+
+	// Spatial placement (see Spatializer/spatial.go)
+	position      [3]float32
+	forward       [3]float32
+	up            [3]float32
+	spatialMode   SpatialMode
+	hrtfConnected bool // whether outputMixer is already wired into the engine's environment node
+
+	// spatialConfig is the last SpatialConfig applied via Spatialize -
+	// rendering algorithm, distance attenuation, and directivity, on top of
+	// the plain position/mode fields above.
+	spatialConfig SpatialConfig
+
+	// MIDI-learn bindings (see midimap.Mapper)
+	midiBindings []MidiBinding
+
+	// Change notification (see ChannelListener)
+	listeners []ChannelListener
+
+	// Typed event emitters (see events.go) - a second, async notification
+	// path alongside listeners, for subscribers (OSC surface, telemetry,
+	// undo/redo) that want a buffered channel instead of a synchronous
+	// callback.
+	volumeEmitter    *events.Emitter[VolumeChanged]
+	panEmitter       *events.Emitter[PanChanged]
+	muteEmitter      *events.Emitter[MuteChanged]
+	sendLevelEmitter *events.Emitter[SendLevelChanged]
+	releasedEmitter  *events.Emitter[ChannelReleased]
+
+	// rampMu guards volumeRampCancel/panRampCancel/positionRampCancel, each
+	// non-nil while a SetVolumeRamp/SetPanRamp/Spatialize ramp is in
+	// flight; closing one cancels that ramp. Separate from mu since a
+	// ramp's completion goroutine only touches these fields, never the
+	// rest of BaseChannel's state.
+	rampMu             sync.Mutex
+	volumeRampCancel   chan struct{}
+	panRampCancel      chan struct{}
+	positionRampCancel chan struct{}
+}
+
+// ChannelSend describes one real-time send from a channel into an
+// AuxChannel's input mixer, tapped either before or after the sending
+// channel's own gain stage.
+type ChannelSend struct {
+	Aux      *AuxChannel
+	Level    float32
+	PreFader bool
+
+	auxBus int // input bus this send occupies on Aux's input mixer
+}
+
+// AddSend routes a copy of this channel's signal into aux, mixed into a
+// dedicated input bus on aux's input mixer. When preFader is true the tap
+// is taken before this channel's gain stage (see preFaderTap); channel
+// types that don't maintain one (MasterChannel, AuxChannel,
+// MidiInputChannel) only support post-fader sends.
+func (bc *BaseChannel) AddSend(aux *AuxChannel, level float32, preFader bool) error {
+	if aux == nil {
+		return fmt.Errorf("aux channel is nil")
+	}
+	if bc.engine == nil {
+		return fmt.Errorf("channel not connected to engine")
+	}
+	if aux.outputMixer == nil {
+		return fmt.Errorf("aux channel has no input mixer")
+	}
+
+	var source unsafe.Pointer
+	if preFader {
+		source = bc.preFaderTap
+		if source == nil {
+			return fmt.Errorf("channel has no pre-fader tap to send from")
+		}
+	} else {
+		source = bc.outputMixer
+		if source == nil {
+			return fmt.Errorf("channel has no output mixer to send from")
+		}
+	}
+
+	avEngine := bc.engine.getAVEngine()
+	auxBus := aux.allocateSendBus()
+
+	if err := avEngine.Connect(source, aux.outputMixer, 0, auxBus); err != nil {
+		if err := avEngine.ConnectWithFormat(source, aux.outputMixer, 0, auxBus, nil); err != nil {
+			return fmt.Errorf("failed to connect send to aux: %w", err)
+		}
+	}
+	if err := avEngine.SetMixerVolumeForBus(aux.outputMixer, level, auxBus); err != nil {
+		return fmt.Errorf("failed to set send level: %w", err)
+	}
+
+	bc.mu.Lock()
+	bc.sends = append(bc.sends, ChannelSend{Aux: aux, Level: level, PreFader: preFader, auxBus: auxBus})
+	bc.mu.Unlock()
+	return nil
+}
+
+// RemoveSend disconnects and forgets a send previously added with AddSend.
+func (bc *BaseChannel) RemoveSend(aux *AuxChannel) error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	for i, s := range bc.sends {
+		if s.Aux == aux {
+			if bc.engine != nil && aux.outputMixer != nil {
+				avEngine := bc.engine.getAVEngine()
+				avEngine.DisconnectNodeInput(aux.outputMixer, s.auxBus)
+			}
+			bc.sends = append(bc.sends[:i], bc.sends[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no send to this aux channel")
+}
+
+// SetSendLevel updates the level of a send to aux previously routed with
+// AddSend, without disconnecting and reconnecting it the way RemoveSend
+// plus AddSend again would. Returns an error if there's no such send.
+func (bc *BaseChannel) SetSendLevel(aux *AuxChannel, level float32) error {
+	bc.mu.Lock()
+
+	for i, s := range bc.sends {
+		if s.Aux != aux {
+			continue
+		}
+		if bc.engine != nil && aux.outputMixer != nil {
+			avEngine := bc.engine.getAVEngine()
+			if err := avEngine.SetMixerVolumeForBus(aux.outputMixer, level, s.auxBus); err != nil {
+				bc.mu.Unlock()
+				return fmt.Errorf("failed to set send level: %w", err)
+			}
+		}
+		old := bc.sends[i].Level
+		bc.sends[i].Level = level
+		bc.mu.Unlock()
+
+		bc.sendLevelEmitter.Emit(SendLevelChanged{
+			ChannelID: bc.GetIDString(),
+			SendName:  aux.GetName(),
+			Old:       old,
+			New:       level,
+		})
+		return nil
+	}
+	bc.mu.Unlock()
+	return fmt.Errorf("no send to this aux channel")
+}
+
+// GetSends returns a copy of the sends currently routed from this channel.
+func (bc *BaseChannel) GetSends() []ChannelSend {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	sends := make([]ChannelSend, len(bc.sends))
+	copy(sends, bc.sends)
+	return sends
 }
 
 // NewBaseChannel creates a new base channel with common initialization
 func NewBaseChannel(name string, channelType ChannelType, engine *Engine) *BaseChannel {
-	return &BaseChannel{
-		id:          uuid.New(), // Generate new UUID
+	id := uuid.New()
+	bc := &BaseChannel{
+		id:          id,
 		name:        name,
 		channelType: channelType,
 		engine:      engine,
@@ -48,7 +226,22 @@ func NewBaseChannel(name string, channelType ChannelType, engine *Engine) *BaseC
 		pluginChain: NewPluginChain(),
 		connections: make([]Connection, 0),
 		isRunning:   false,
+		forward:     defaultForward,
+		up:          defaultUp,
+		spatialMode: SpatialStereoPan,
+
+		volumeEmitter:    events.NewEmitter[VolumeChanged](),
+		panEmitter:       events.NewEmitter[PanChanged](),
+		muteEmitter:      events.NewEmitter[MuteChanged](),
+		sendLevelEmitter: events.NewEmitter[SendLevelChanged](),
+		releasedEmitter:  events.NewEmitter[ChannelReleased](),
+	}
+	bc.pluginChain.setChannelID(id.String())
+	if engine != nil {
+		sampleRate, bufferSize := engine.AudioFormat()
+		bc.pluginChain.setHostSpec(sampleRate, bufferSize)
 	}
+	return bc
 }
 
 // GetID returns the channel UUID (hybrid pattern)
@@ -112,35 +305,47 @@ func (bc *BaseChannel) IsRunning() bool {
 // ConnectTo connects this channel to another channel
 func (bc *BaseChannel) ConnectTo(target Channel, bus int) error {
 	bc.mu.Lock()
-	defer bc.mu.Unlock()
-
 	connection := Connection{
 		SourceChannel: bc.GetIDString(), // Convert UUID to string
 		TargetChannel: target.GetIDString(),
 		SourceBus:     0, // Most channels have single output bus
 		TargetBus:     bus,
 	}
-
 	bc.connections = append(bc.connections, connection)
+	connections := make([]Connection, len(bc.connections))
+	copy(connections, bc.connections)
+	bc.mu.Unlock()
+
+	bc.notifyConnectionChanged(connections)
 	return nil
 }
 
 // DisconnectFrom disconnects this channel from another channel
 func (bc *BaseChannel) DisconnectFrom(target Channel, bus int) error {
 	bc.mu.Lock()
-	defer bc.mu.Unlock()
-
 	targetID := target.GetIDString() // Get string representation for comparison
+	found := false
 	for i, conn := range bc.connections {
 		if conn.TargetChannel == targetID && conn.TargetBus == bus {
 			// Remove connection
 			copy(bc.connections[i:], bc.connections[i+1:])
 			bc.connections = bc.connections[:len(bc.connections)-1]
-			return nil
+			found = true
+			break
 		}
 	}
+	var connections []Connection
+	if found {
+		connections = make([]Connection, len(bc.connections))
+		copy(connections, bc.connections)
+	}
+	bc.mu.Unlock()
 
-	return fmt.Errorf("connection to %s (bus %d) not found", targetID, bus)
+	if !found {
+		return fmt.Errorf("connection to %s (bus %d) not found", targetID, bus)
+	}
+	bc.notifyConnectionChanged(connections)
+	return nil
 }
 
 // GetConnections returns all connections from this channel
@@ -158,8 +363,15 @@ func (bc *BaseChannel) GetPluginChain() *PluginChain {
 	return bc.pluginChain
 }
 
-// AddPlugin adds a plugin to the channel's plugin chain
+// AddPlugin adds a plugin to the channel's plugin chain, rejecting the
+// insert with ErrLimitExceeded if it would cross
+// EngineLimits.MaxPluginsPerChannel.
 func (bc *BaseChannel) AddPlugin(blueprint PluginBlueprint, position int) (*PluginInstance, error) {
+	if bc.engine != nil {
+		if err := bc.engine.checkPluginLimit(bc.pluginChain); err != nil {
+			return nil, err
+		}
+	}
 	return bc.pluginChain.AddPlugin(blueprint, position)
 }
 
@@ -175,7 +387,7 @@ func (bc *BaseChannel) SetVolume(volume float32) error {
 	}
 
 	bc.mu.Lock()
-	defer bc.mu.Unlock()
+	oldVolume := bc.volume
 	bc.volume = volume
 
 	// Apply to actual output mixer node if available
@@ -193,7 +405,9 @@ func (bc *BaseChannel) SetVolume(volume float32) error {
 			}
 		}
 	}
+	bc.mu.Unlock()
 
+	bc.notifyVolumeChanged(oldVolume, volume)
 	return nil
 }
 
@@ -204,6 +418,46 @@ func (bc *BaseChannel) GetVolume() (float32, error) {
 	return bc.volume, nil
 }
 
+// GetEffectiveVolume returns this channel's own volume multiplied by the
+// volume of every ChannelGroup it's assigned into (see
+// ChannelGroup.AssignChannel), walking all the way up a nested group chain.
+// Meters and OSC feedback should read this instead of GetVolume when a
+// group's VCA fader is in play, since GetVolume only ever reflects this
+// channel's own fader position.
+func (bc *BaseChannel) GetEffectiveVolume() (float32, error) {
+	volume, err := bc.GetVolume()
+	if err != nil {
+		return 0, err
+	}
+	if bc.engine == nil {
+		return volume, nil
+	}
+
+	effective := volume
+	id := bc.GetIDString()
+	for {
+		group, ok := bc.engine.groupForChannel(id)
+		if !ok {
+			break
+		}
+		groupVolume, err := group.GetVolume()
+		if err != nil {
+			break
+		}
+		effective *= groupVolume
+		id = group.GetIDString()
+	}
+	return effective, nil
+}
+
+// getOutputMixer returns this channel's native submix mixer node. It exists
+// so code working through the Channel interface (e.g.
+// ChannelGroup.AssignChannel) can still reach the concrete mixer pointer
+// that RouteTo accesses directly via its *BaseChannel receiver.
+func (bc *BaseChannel) getOutputMixer() unsafe.Pointer {
+	return bc.outputMixer
+}
+
 // SetPan sets the channel pan (-1.0 to 1.0, where -1.0 is full left, 1.0 is full right)
 func (bc *BaseChannel) SetPan(pan float32) error {
 	if pan < -1.0 || pan > 1.0 {
@@ -211,7 +465,7 @@ func (bc *BaseChannel) SetPan(pan float32) error {
 	}
 
 	bc.mu.Lock()
-	defer bc.mu.Unlock()
+	oldPan := bc.pan
 	bc.pan = pan
 
 	// Apply to actual output mixer node if available
@@ -219,7 +473,9 @@ func (bc *BaseChannel) SetPan(pan float32) error {
 		// Note: Pan control requires AVAudioMixerNode-specific bindings
 		// For now, we store the value. Future enhancement: implement mixer pan control
 	}
+	bc.mu.Unlock()
 
+	bc.notifyPanChanged(oldPan, pan)
 	return nil
 }
 
@@ -230,17 +486,167 @@ func (bc *BaseChannel) GetPan() (float32, error) {
 	return bc.pan, nil
 }
 
+// RampCurve selects the shape of a SetVolumeRamp/SetPanRamp glide. It
+// aliases avaudio/engine's Curve type so a channel-level ramp schedules the
+// same native AVAudioTime-driven shapes AudioPlayer.RampVolume already
+// does, rather than inventing a second curve vocabulary.
+type RampCurve = avengine.Curve
+
+const (
+	// RampLinear changes value at a constant rate over the ramp duration.
+	RampLinear = avengine.CurveLinear
+	// RampEqualPower follows a quarter-sine/cosine power curve, the usual
+	// shape for pan moves so perceived loudness stays flat across center.
+	RampEqualPower = avengine.CurveEqualPower
+	// RampExponential changes value at a constant relative (dB-like) rate,
+	// the usual shape for volume fades.
+	RampExponential = avengine.CurveExponential
+)
+
+// SetVolumeRamp glides the channel's volume to target over duration using
+// curve, scheduled on the audio render thread rather than jumping instantly
+// like SetVolume. A call made while a volume ramp is already in flight
+// cancels it atomically and starts fresh. The returned channel closes once
+// the ramp completes or is canceled/superseded, so callers - e.g. the
+// dispatcher sequencing a fade-out before DestroyChannel - can wait for it
+// without racing the in-flight ramp.
+func (bc *BaseChannel) SetVolumeRamp(target float32, duration time.Duration, curve RampCurve) (<-chan struct{}, error) {
+	if target < 0.0 || target > 1.0 {
+		return nil, fmt.Errorf("volume must be between 0.0 and 1.0")
+	}
+	if bc.outputMixer == nil || bc.engine == nil {
+		return nil, fmt.Errorf("channel not connected to engine")
+	}
+	avEngine := bc.engine.getAVEngine()
+	if avEngine == nil {
+		return nil, fmt.Errorf("native engine not available")
+	}
+
+	if err := avEngine.RampMixerVolume(bc.outputMixer, 0, target, duration, curve); err != nil {
+		return nil, fmt.Errorf("failed to schedule volume ramp: %w", err)
+	}
+
+	bc.rampMu.Lock()
+	if bc.volumeRampCancel != nil {
+		close(bc.volumeRampCancel)
+	}
+	cancel := make(chan struct{})
+	bc.volumeRampCancel = cancel
+	bc.rampMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-time.After(duration):
+			bc.mu.Lock()
+			bc.volume = target
+			bc.mu.Unlock()
+		case <-cancel:
+		}
+		bc.rampMu.Lock()
+		if bc.volumeRampCancel == cancel {
+			bc.volumeRampCancel = nil
+		}
+		bc.rampMu.Unlock()
+	}()
+
+	return done, nil
+}
+
+// SetPanRamp glides the channel's pan to target over duration using curve,
+// the pan counterpart to SetVolumeRamp; see its doc comment for cancellation
+// and completion-channel semantics.
+func (bc *BaseChannel) SetPanRamp(target float32, duration time.Duration, curve RampCurve) (<-chan struct{}, error) {
+	if target < -1.0 || target > 1.0 {
+		return nil, fmt.Errorf("pan must be between -1.0 and 1.0")
+	}
+	if bc.outputMixer == nil || bc.engine == nil {
+		return nil, fmt.Errorf("channel not connected to engine")
+	}
+	avEngine := bc.engine.getAVEngine()
+	if avEngine == nil {
+		return nil, fmt.Errorf("native engine not available")
+	}
+
+	if err := avEngine.RampMixerPan(bc.outputMixer, 0, target, duration, curve); err != nil {
+		return nil, fmt.Errorf("failed to schedule pan ramp: %w", err)
+	}
+
+	bc.rampMu.Lock()
+	if bc.panRampCancel != nil {
+		close(bc.panRampCancel)
+	}
+	cancel := make(chan struct{})
+	bc.panRampCancel = cancel
+	bc.rampMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-time.After(duration):
+			bc.mu.Lock()
+			bc.pan = target
+			bc.mu.Unlock()
+		case <-cancel:
+		}
+		bc.rampMu.Lock()
+		if bc.panRampCancel == cancel {
+			bc.panRampCancel = nil
+		}
+		bc.rampMu.Unlock()
+	}()
+
+	return done, nil
+}
+
+// CancelRamps stops any in-flight volume, pan, or Spatialize position ramp
+// immediately, leaving the channel at whatever value the ramp had
+// interpolated to when canceled. Callers waiting on a SetVolumeRamp/
+// SetPanRamp/Spatialize completion channel are unblocked as part of the
+// cancellation.
+func (bc *BaseChannel) CancelRamps() error {
+	if bc.outputMixer != nil && bc.engine != nil {
+		if avEngine := bc.engine.getAVEngine(); avEngine != nil {
+			if err := avEngine.CancelMixerRamps(bc.outputMixer, 0); err != nil {
+				return fmt.Errorf("failed to cancel mixer ramps: %w", err)
+			}
+		}
+	}
+
+	bc.rampMu.Lock()
+	if bc.volumeRampCancel != nil {
+		close(bc.volumeRampCancel)
+		bc.volumeRampCancel = nil
+	}
+	if bc.panRampCancel != nil {
+		close(bc.panRampCancel)
+		bc.panRampCancel = nil
+	}
+	if bc.positionRampCancel != nil {
+		close(bc.positionRampCancel)
+		bc.positionRampCancel = nil
+	}
+	bc.rampMu.Unlock()
+
+	return nil
+}
+
 // SetMute sets the channel mute state via dispatcher (topology change)
 func (bc *BaseChannel) SetMute(muted bool) error {
 	// Route through dispatcher since mute is a topology change (per specs)
 	if bc.engine != nil && bc.engine.dispatcher != nil {
 		return bc.engine.dispatcher.SetChannelMute(bc.GetIDString(), muted)
 	}
-	
+
 	// Fallback for when dispatcher is not available (e.g., during initialization)
 	bc.mu.Lock()
-	defer bc.mu.Unlock()
+	oldMuted := bc.muted
 	bc.muted = muted
+	bc.mu.Unlock()
+
+	bc.notifyMuteChanged(oldMuted, muted)
 	return nil
 }
 
@@ -259,34 +665,233 @@ func (bc *BaseChannel) GetState() ChannelState {
 	connections := make([]Connection, len(bc.connections))
 	copy(connections, bc.connections)
 
+	midiBindings := make([]MidiBinding, len(bc.midiBindings))
+	copy(midiBindings, bc.midiBindings)
+
+	sends := make([]ChannelSendState, len(bc.sends))
+	for i, s := range bc.sends {
+		sends[i] = ChannelSendState{AuxName: s.Aux.GetName(), Level: s.Level, PreFader: s.PreFader}
+	}
+
 	return ChannelState{
-		ID:          bc.GetIDString(), // Convert UUID to string for JSON
-		Type:        bc.channelType,
-		Volume:      bc.volume,
-		Pan:         bc.pan,
-		Muted:       bc.muted,
-		Connections: connections,
-		PluginChain: bc.pluginChain.GetState(),
+		ID:           bc.GetIDString(), // Convert UUID to string for JSON
+		Type:         bc.channelType,
+		Volume:       bc.volume,
+		Pan:          bc.pan,
+		Muted:        bc.muted,
+		Position:     bc.position,
+		Forward:      bc.forward,
+		Up:           bc.up,
+		SpatialMode:  bc.spatialMode,
+		Connections:  connections,
+		Sends:        sends,
+		PluginChain:  bc.pluginChain.GetState(),
+		MidiBindings: midiBindings,
 	}
 }
 
 // SetState restores the channel from serializable state
 func (bc *BaseChannel) SetState(state ChannelState) error {
 	bc.mu.Lock()
-	defer bc.mu.Unlock()
 
 	bc.volume = state.Volume
 	bc.pan = state.Pan
 	bc.muted = state.Muted
+	bc.position = state.Position
+	bc.forward = state.Forward
+	bc.up = state.Up
+	bc.spatialMode = state.SpatialMode
+	if bc.spatialMode == "" {
+		bc.spatialMode = SpatialStereoPan // older state predates spatial fields
+	}
 
 	connections := make([]Connection, len(state.Connections))
 	copy(connections, state.Connections)
 	bc.connections = connections
 
+	midiBindings := make([]MidiBinding, len(state.MidiBindings))
+	copy(midiBindings, state.MidiBindings)
+	bc.midiBindings = midiBindings
+
+	bc.mu.Unlock()
+
+	// restoreSends takes bc.mu itself (via AddSend/SetSendLevel/RemoveSend),
+	// so it has to run after the field restoration above is unlocked.
+	bc.restoreSends(state.Sends)
+
 	// Restore plugin chain
 	return bc.pluginChain.SetState(state.PluginChain)
 }
 
+// restoreSends reconverges this channel's sends to match saved, the same
+// idempotent way AddSend/SetSendLevel/RemoveSend already converge a single
+// send: a saved send whose aux is still resolvable by name is added (if
+// missing) or leveled (if already present); an existing send not present in
+// saved is torn down. A saved send whose aux name no longer resolves to any
+// channel is skipped rather than erroring the whole restore - the aux
+// channel itself is presumably what got removed, so there's nothing to
+// rewire it to.
+func (bc *BaseChannel) restoreSends(saved []ChannelSendState) {
+	if bc.engine == nil {
+		return
+	}
+
+	wanted := make(map[string]ChannelSendState, len(saved))
+	for _, s := range saved {
+		wanted[s.AuxName] = s
+	}
+
+	for _, existing := range bc.GetSends() {
+		name := existing.Aux.GetName()
+		if _, ok := wanted[name]; !ok {
+			_ = bc.RemoveSend(existing.Aux)
+		}
+	}
+
+	for name, s := range wanted {
+		aux, ok := bc.resolveAuxByName(name)
+		if !ok {
+			continue
+		}
+		if _, alreadyPresent := bc.sendTo(aux); alreadyPresent {
+			_ = bc.SetSendLevel(aux, s.Level)
+			continue
+		}
+		_ = bc.AddSend(aux, s.Level, s.PreFader)
+	}
+}
+
+// sendTo returns this channel's existing send to aux, if any.
+func (bc *BaseChannel) sendTo(aux *AuxChannel) (ChannelSend, bool) {
+	for _, s := range bc.GetSends() {
+		if s.Aux == aux {
+			return s, true
+		}
+	}
+	return ChannelSend{}, false
+}
+
+// resolveAuxByName finds a registered *AuxChannel by name, the same linear
+// scan osc.Server.resolveByName does for OSC's by-name addressing.
+func (bc *BaseChannel) resolveAuxByName(name string) (*AuxChannel, bool) {
+	for _, id := range bc.engine.ListChannels() {
+		ch, ok := bc.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		aux, ok := ch.(*AuxChannel)
+		if !ok {
+			continue
+		}
+		if aux.GetName() == name {
+			return aux, true
+		}
+	}
+	return nil, false
+}
+
+// GetMidiBindings returns a copy of this channel's MIDI-learn bindings.
+func (bc *BaseChannel) GetMidiBindings() []MidiBinding {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	bindings := make([]MidiBinding, len(bc.midiBindings))
+	copy(bindings, bc.midiBindings)
+	return bindings
+}
+
+// AddMidiBinding adds or replaces the MIDI-learn binding for b.Parameter.
+func (bc *BaseChannel) AddMidiBinding(b MidiBinding) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	for i, existing := range bc.midiBindings {
+		if existing.Parameter == b.Parameter {
+			bc.midiBindings[i] = b
+			return
+		}
+	}
+	bc.midiBindings = append(bc.midiBindings, b)
+}
+
+// ClearMidiBindings removes the MIDI-learn binding for parameter, or every
+// binding on this channel if parameter is "".
+func (bc *BaseChannel) ClearMidiBindings(parameter string) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if parameter == "" {
+		bc.midiBindings = nil
+		return
+	}
+	kept := bc.midiBindings[:0]
+	for _, b := range bc.midiBindings {
+		if b.Parameter != parameter {
+			kept = append(kept, b)
+		}
+	}
+	bc.midiBindings = kept
+}
+
+// AddListener registers listener to be notified of every SetVolume/SetPan/
+// SetMute/ConnectTo/DisconnectFrom call on this channel (see ChannelListener).
+func (bc *BaseChannel) AddListener(listener ChannelListener) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.listeners = append(bc.listeners, listener)
+}
+
+// RemoveListener unregisters listener, previously passed to AddListener.
+func (bc *BaseChannel) RemoveListener(listener ChannelListener) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	for i, existing := range bc.listeners {
+		if existing == listener {
+			bc.listeners = append(bc.listeners[:i], bc.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// snapshotListeners returns a copy of this channel's registered listeners,
+// so fan-out can run without holding bc.mu (a listener calling back into a
+// Get* method on this same channel would otherwise deadlock).
+func (bc *BaseChannel) snapshotListeners() []ChannelListener {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	listeners := make([]ChannelListener, len(bc.listeners))
+	copy(listeners, bc.listeners)
+	return listeners
+}
+
+func (bc *BaseChannel) notifyVolumeChanged(old, volume float32) {
+	id := bc.GetIDString()
+	for _, l := range bc.snapshotListeners() {
+		l.OnVolumeChanged(id, volume)
+	}
+	bc.volumeEmitter.Emit(VolumeChanged{ChannelID: id, Old: old, New: volume})
+}
+
+func (bc *BaseChannel) notifyPanChanged(old, pan float32) {
+	id := bc.GetIDString()
+	for _, l := range bc.snapshotListeners() {
+		l.OnPanChanged(id, pan)
+	}
+	bc.panEmitter.Emit(PanChanged{ChannelID: id, Old: old, New: pan})
+}
+
+func (bc *BaseChannel) notifyMuteChanged(old, muted bool) {
+	id := bc.GetIDString()
+	for _, l := range bc.snapshotListeners() {
+		l.OnMuteChanged(id, muted)
+	}
+	bc.muteEmitter.Emit(MuteChanged{ChannelID: id, Old: old, New: muted})
+}
+
+func (bc *BaseChannel) notifyConnectionChanged(connections []Connection) {
+	id := bc.GetIDString()
+	for _, l := range bc.snapshotListeners() {
+		l.OnConnectionChanged(id, connections)
+	}
+}
+
 // MasterChannel represents the main mixer output channel
 type MasterChannel struct {
 	*BaseChannel
@@ -294,8 +899,33 @@ type MasterChannel struct {
 	// Master-specific functionality
 	masterVolume   float32
 	limiterEnabled bool
+
+	// Limiter: an AUDynamicsProcessor spliced between MainMixerNode and
+	// OutputNode when enabled, see applyLimiterEnabled.
+	limiterEffect    *unit.Effect
+	limiterPlugin    *plugins.Plugin
+	limiterThreshold float32
+	limiterAttack    float32
+	limiterRelease   float32
+}
+
+// masterLimiterInfo identifies Apple's built-in AUDynamicsProcessor, used
+// as MasterChannel's limiter insert.
+var masterLimiterInfo = plugins.PluginInfo{
+	Name:           "AUDynamicsProcessor",
+	ManufacturerID: "appl",
+	Type:           "aufx",
+	Subtype:        "dcmp",
 }
 
+// AUDynamicsProcessor parameter display names, as reported by introspection.
+const (
+	limiterParamThreshold   = "Threshold"
+	limiterParamAttack      = "Attack Time"
+	limiterParamRelease     = "Release Time"
+	limiterParamCompression = "Compression Amount"
+)
+
 // AudioInputConfig holds configuration for audio input channels
 type AudioInputConfig struct {
 	DeviceUID string // Audio device unique identifier from devices package
@@ -303,6 +933,53 @@ type AudioInputConfig struct {
 	// Maps directly to AVAudioInputNode's output bus number
 	// DeviceUID + InputBus combination uniquely identifies an audio source
 	MonitoringLevel float32 // Input monitoring level (0.0-1.0)
+
+	// SampleRate and ChannelCount, if non-zero, are validated against
+	// DeviceUID's stream.Device.SupportedFormats before the shared input
+	// node is created, so an unsupported request fails with a clear error
+	// here instead of AVFoundation silently picking its own format in
+	// Start. Leave both 0 to accept whatever the device offers.
+	SampleRate   int
+	ChannelCount int
+	// PreferredFormat, if set, is validated in place of SampleRate/ChannelCount
+	// and takes precedence over them.
+	PreferredFormat *stream.Format
+
+	// Aggregate, if set, composes several physical devices into one
+	// CoreAudio aggregate device and uses that as DeviceUID instead (e.g.
+	// two USB interfaces ganged as one 16-channel source). DeviceUID is
+	// ignored when Aggregate is set; InputBus still addresses a single
+	// channel, now within the aggregate's flattened sub-device channel
+	// list (sub-devices appear back-to-back in Devices order).
+	Aggregate *AggregateInputOptions
+
+	// FallbackDeviceUID, if set, is the device Engine.handleChannelDeviceStatusChanged
+	// rebinds this channel to automatically when DeviceUID goes offline,
+	// via the same Dispatcher.ChangeChannelDevice path a caller-initiated
+	// switch uses. Left empty, a lost device instead mutes the channel and
+	// emits EventDeviceLost.
+	FallbackDeviceUID string
+}
+
+// AggregateInputOptions configures an AudioInputChannel backed by a
+// CoreAudio aggregate device composed of several devices.AudioDevice
+// entries, rather than a single physical DeviceUID. Mirrors the aggregate
+// construction Engine.ensureAggregateOutput uses for multi-output routing,
+// but built explicitly per channel instead of inferred from routes, since
+// an input channel only ever reads from the one aggregate it asks for.
+type AggregateInputOptions struct {
+	// Name is the aggregate's display name.
+	Name string
+	// Devices lists the physical devices to compose; at least 2 are
+	// required, matching devices.CreateAggregate.
+	Devices []*devices.AudioDevice
+	// MasterUID names the sub-device whose clock drives the aggregate;
+	// defaults to Devices[0].UID if empty.
+	MasterUID string
+	// DriftCompensate enables per-subdevice clock drift compensation,
+	// keyed by sub-device UID. A sub-device absent from this map is not
+	// drift-compensated.
+	DriftCompensate map[string]bool
 }
 
 // AudioInputChannel represents an audio input channel
@@ -315,14 +992,36 @@ type AudioInputChannel struct {
 	inputBus        int
 	monitoringLevel float32
 
+	// aggregateDeviceUID is the CoreAudio aggregate device backing this
+	// channel's deviceUID, set only when config.Aggregate was used; empty
+	// for a channel reading from a plain physical device. Torn down by
+	// Engine.removeChannel alongside the channel itself.
+	aggregateDeviceUID string
+
 	// AVFoundation integration
 	inputNode unsafe.Pointer // Shared AVAudioInputNode (from engine.inputNodes)
+
+	// noiseMu guards noiseSuppression/noiseGateThreshold; see
+	// EnableNoiseSuppression/DisableNoiseSuppression.
+	noiseMu            sync.Mutex
+	noiseSuppression   *denoise.Denoiser
+	noiseGateThreshold float32
+
+	// recordMu guards recordTap/recorder; see StartRecording/StopRecording.
+	recordMu  sync.Mutex
+	recordTap *tap.Tap
+	recorder  *tap.FileRecorder
 }
 
 // MidiInputConfig holds configuration for MIDI input channels
 type MidiInputConfig struct {
 	DeviceUID string
 	Channel   int // MIDI channel (0-15, -1 for all)
+
+	// FallbackDeviceUID mirrors AudioInputConfig.FallbackDeviceUID: the
+	// device Engine.handleChannelDeviceStatusChanged rebinds this channel
+	// to automatically when DeviceUID goes offline, instead of muting it.
+	FallbackDeviceUID string
 }
 
 // MidiInputChannel represents a MIDI input channel
@@ -340,8 +1039,51 @@ type PlaybackConfig struct {
 	FilePath    string
 	LoopEnabled bool
 	AutoStart   bool
-	FadeIn      float32
-	FadeOut     float32
+	FadeIn      float32 // fade-in duration in seconds, applied on Play
+	FadeOut     float32 // fade-out duration in seconds, applied before end of file
+	FadeCurve   avengine.Curve
+
+	// ReplayGainMode and ReplayGainPreampDB configure the queue subsystem
+	// (see PlaybackChannel.Enqueue); they have no effect on single-file
+	// Play.
+	ReplayGainMode     ReplayGainMode
+	ReplayGainPreampDB float32
+}
+
+// ReplayGainMode selects which of a queued QueueEntryOptions' stored gains
+// PlaybackChannel.Enqueue normalizes against, or turns normalization off.
+type ReplayGainMode string
+
+const (
+	ReplayGainTrack ReplayGainMode = "track"
+	ReplayGainAlbum ReplayGainMode = "album"
+	ReplayGainOff   ReplayGainMode = "off"
+)
+
+// QueueEntryOptions carries a queued track's ReplayGain metadata, normally
+// read from file tags (or computed with ComputeReplayGain for untagged
+// files). The Has* flags distinguish "no tag present" from a legitimate
+// 0dB/0 value.
+type QueueEntryOptions struct {
+	TrackGain    float64 // dB
+	TrackPeak    float64 // linear sample peak, 0..1
+	HasTrackGain bool
+
+	AlbumGain    float64 // dB
+	AlbumPeak    float64 // linear sample peak, 0..1
+	HasAlbumGain bool
+}
+
+// QueueEntry is one queued track, as reported by PlaybackChannel.QueueState.
+type QueueEntry struct {
+	Path    string
+	Options QueueEntryOptions
+}
+
+// QueueState snapshots a playback channel's queue.
+type QueueState struct {
+	Entries  []QueueEntry
+	Position int // index into Entries of the currently (or about to be) playing track
 }
 
 // PlaybackChannel represents an audio file playback channel
@@ -355,11 +1097,30 @@ type PlaybackChannel struct {
 	autoStart   bool
 	fadeIn      float32
 	fadeOut     float32
+	fadeCurve   avengine.Curve
 
 	// Playback state
 	isPlaying bool
 	isPaused  bool
 	position  float64 // Current position in seconds
+
+	// AVFoundation integration
+	player    *avengine.AudioPlayer
+	watchStop chan struct{} // closed to stop the loop/fade watcher goroutine
+	done      chan struct{} // closed by watch when the current play session ends; see Done
+
+	// decoder, when set (see NewPlaybackChannelFromDecoder), streams audio
+	// into the player instead of openPlayer loading filePath directly.
+	decoder Decoder
+
+	// Queue subsystem - see Enqueue/Skip/QueueState. Separate from player
+	// above: Play/Pause/Stop work on a single loaded file, while the queue
+	// plays a gapless sequence via avengine.Playlist.
+	replayGainMode     ReplayGainMode
+	replayGainPreampDB float32
+	queueMu            sync.Mutex
+	queue              []QueueEntry
+	playlist           *avengine.Playlist
 }
 
 // AuxConfig holds configuration for auxiliary send channels
@@ -367,6 +1128,10 @@ type AuxConfig struct {
 	SendLevel   float32
 	ReturnLevel float32
 	PreFader    bool
+	// SidechainTargets lists plugin instances (as "channelID:instanceID",
+	// since instance IDs are only unique within their own chain) that use
+	// this aux as a sidechain key input. See AuxChannel.AddSidechainTarget.
+	SidechainTargets []string
 }
 
 // AuxChannel represents an auxiliary send/return channel
@@ -378,6 +1143,13 @@ type AuxChannel struct {
 	sendLevel   float32
 	returnLevel float32
 	preFader    bool
+
+	// sendBusMu guards nextSendBus, the next free input bus on outputMixer
+	// that BaseChannel.AddSend hands out to a new sender.
+	sendBusMu   sync.Mutex
+	nextSendBus int
+
+	sidechainTargets []string
 }
 
 // NewMasterChannel creates a new master channel
@@ -385,14 +1157,46 @@ func NewMasterChannel(name string, engine *Engine) (*MasterChannel, error) {
 	baseChannel := NewBaseChannel(name, ChannelTypeMaster, engine)
 
 	return &MasterChannel{
-		BaseChannel:    baseChannel,
-		masterVolume:   1.0,
-		limiterEnabled: true, // Enable limiter by default for protection
+		BaseChannel:      baseChannel,
+		masterVolume:     1.0,
+		limiterEnabled:   true, // Enable limiter by default for protection
+		limiterThreshold: -3.0,
+		limiterAttack:    10.0,
+		limiterRelease:   100.0,
 	}, nil
 }
 
+// validateAudioInputFormat checks config's requested format, if any, against
+// the device's actual capabilities via the stream package, so a request for
+// an unsupported sample rate/channel count fails clearly here instead of
+// AVFoundation quietly negotiating a different format in Start. A config
+// with no PreferredFormat/SampleRate/ChannelCount is left to the device's
+// default and always passes.
+func validateAudioInputFormat(config AudioInputConfig) error {
+	cfg := stream.Format{SampleRate: config.SampleRate, ChannelCount: config.ChannelCount}
+	if config.PreferredFormat != nil {
+		cfg = *config.PreferredFormat
+	}
+	if cfg.SampleRate == 0 && cfg.ChannelCount == 0 {
+		return nil
+	}
+
+	if _, err := stream.OpenInputStream(config.DeviceUID, cfg); err != nil {
+		return fmt.Errorf("unsupported input format: %w", err)
+	}
+	return nil
+}
+
 // NewAudioInputChannel creates a new audio input channel
 func NewAudioInputChannel(name string, config AudioInputConfig, engine *Engine) (*AudioInputChannel, error) {
+	if engine != nil && engine.mock != nil {
+		return newMockAudioInputChannel(name, config, engine)
+	}
+
+	if err := validateAudioInputFormat(config); err != nil {
+		return nil, err
+	}
+
 	baseChannel := NewBaseChannel(name, ChannelTypeAudioInput, engine)
 
 	// Get or create shared input node for this device/bus combination
@@ -407,6 +1211,10 @@ func NewAudioInputChannel(name string, config AudioInputConfig, engine *Engine)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create channel mixer: %w", err)
 	}
+	preFaderTap, err := avEngine.CreateMixerNode() // Pre-fader tap for aux sends, see BaseChannel.AddSend
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-fader tap: %w", err)
+	}
 
 	channel := &AudioInputChannel{
 		BaseChannel:     baseChannel,
@@ -417,8 +1225,9 @@ func NewAudioInputChannel(name string, config AudioInputConfig, engine *Engine)
 		inputNode:       inputNode,
 	}
 
-	// Set the output mixer in base channel
+	// Set the output mixer and pre-fader tap in base channel
 	baseChannel.outputMixer = outputMixer
+	baseChannel.preFaderTap = preFaderTap
 
 	return channel, nil
 }
@@ -428,14 +1237,14 @@ func (aic *AudioInputChannel) InstallTap(key string) (*tap.Tap, error) {
 	if aic.engine == nil {
 		return nil, fmt.Errorf("channel not connected to engine")
 	}
-	
+
 	enginePtr := aic.engine.GetNativeEngine()
 	nodePtr := aic.inputNode // Use internal pointer safely
-	
+
 	if enginePtr == nil || nodePtr == nil {
 		return nil, fmt.Errorf("native components not available")
 	}
-	
+
 	return tap.InstallTapWithKey(enginePtr, nodePtr, 0, key)
 }
 
@@ -445,61 +1254,197 @@ func (aic *AudioInputChannel) GetInputNode() unsafe.Pointer {
 	return aic.inputNode
 }
 
-// GetOutputMixer returns the native output mixer pointer for taps (DEPRECATED)  
+// GetOutputMixer returns the native output mixer pointer for taps (DEPRECATED)
 // TODO: Remove this method - use InstallTap instead
 func (aic *AudioInputChannel) GetOutputMixer() unsafe.Pointer {
 	return aic.outputMixer
 }
 
-// Start starts the audio input channel and creates AVFoundation connections
-func (aic *AudioInputChannel) Start() error {
-	// Call base channel start first
-	if err := aic.BaseChannel.Start(); err != nil {
-		return err
+// IsRecording reports whether this input channel is actively capturing,
+// i.e. its AVFoundation connections are live (see Start/Stop). The root
+// package has no per-type Channel predicates (see GetType/ChannelType), so
+// this is a convenience specific to AudioInputChannel rather than an
+// addition to the Channel interface.
+func (aic *AudioInputChannel) IsRecording() bool {
+	return aic.IsRunning()
+}
+
+// StartRecording captures this channel's input to a WAV or CAF file at
+// filePath, via a dedicated tap.Tap (installed here, not shared with
+// InstallTap's caller-supplied monitoring taps) feeding a tap.FileRecorder
+// - the same lock-free ring-buffer-to-disk path tap.Tap.RecordTo uses for
+// any other tap. Not to be confused with IsRecording, which reports
+// whether the channel is live at all, not whether it's being written to
+// disk; a channel can be IsRecording() true with no file recording active,
+// or vice versa while Stop()ed but still flushing its last segment.
+func (aic *AudioInputChannel) StartRecording(filePath string, format tap.RecordFormat) error {
+	aic.recordMu.Lock()
+	defer aic.recordMu.Unlock()
+
+	if aic.recorder != nil {
+		return fmt.Errorf("channel %s is already recording to disk", aic.GetIDString())
 	}
 
-	// ✅ CORRECT PATTERN: Use explicit format matching (from your research)
-	// The key insight: both connections must use the same explicit format
-	avEngine := aic.engine.getAVEngine()
-	
-	// Get the input node's output format - this is the reference format
-	fmt.Printf("🔍 Getting input node format for proper routing...\n")
-	// Note: We need to add a method to get the input format from Go
-	// For now, let's try with the engine's spec format, then nil as fallback
-	
-	// Step 1: Connect inputNode → individual channel mixer with explicit format
-	fmt.Printf("🔗 PROPER: Connecting inputNode %p → channelMixer %p (bus %d → 0)\n", 
-		aic.inputNode, aic.outputMixer, aic.inputBus)
-	
-	// Try with engine's spec format first (proper approach)
-	err := avEngine.Connect(aic.inputNode, aic.outputMixer, aic.inputBus, 0)
+	t, err := aic.InstallTap(fmt.Sprintf("%s-record", aic.GetIDString()))
 	if err != nil {
-		// Fallback to nil format if spec format fails
-		err = avEngine.ConnectWithFormat(aic.inputNode, aic.outputMixer, aic.inputBus, 0, nil)
-		if err != nil {
-			return fmt.Errorf("failed to connect input to channel mixer: %w", err)
-		}
-		fmt.Printf("✅ Input → Channel mixer connected (nil format fallback)\n")
-	} else {
-		fmt.Printf("✅ Input → Channel mixer connected (engine spec format)\n")
+		return fmt.Errorf("failed to install recording tap: %w", err)
 	}
-	
-	// Step 2: Connect individual channel mixer → main mixer with SAME format
-	mainMixer, err := avEngine.MainMixerNode()
+
+	recorder, err := t.RecordTo(filePath, tap.RecorderOpts{Format: format})
 	if err != nil {
-		return fmt.Errorf("failed to get main mixer: %w", err)
+		_ = t.Remove()
+		return fmt.Errorf("failed to start recording: %w", err)
 	}
-	
-	fmt.Printf("🔗 PROPER: Connecting channelMixer %p → mainMixer %p (0 → 0)\n", 
-		aic.outputMixer, mainMixer)
-	
-	// Use the same format approach as the first connection
-	err = avEngine.Connect(aic.outputMixer, mainMixer, 0, 0)
-	if err != nil {
+
+	aic.recordTap = t
+	aic.recorder = recorder
+	return nil
+}
+
+// StopRecording stops a recording started by StartRecording, flushing and
+// closing the output file, and returns its session totals. Calling it
+// without a recording in progress is an error.
+func (aic *AudioInputChannel) StopRecording() (tap.RecordStats, error) {
+	aic.recordMu.Lock()
+	defer aic.recordMu.Unlock()
+
+	if aic.recorder == nil {
+		return tap.RecordStats{}, fmt.Errorf("channel %s is not recording to disk", aic.GetIDString())
+	}
+
+	stats, err := aic.recorder.Stop()
+	_ = aic.recordTap.Remove()
+	aic.recorder = nil
+	aic.recordTap = nil
+	return stats, err
+}
+
+// RecordedFrames returns the number of frames written by the in-progress
+// StartRecording session so far, or 0 if none is active.
+func (aic *AudioInputChannel) RecordedFrames() int64 {
+	aic.recordMu.Lock()
+	defer aic.recordMu.Unlock()
+
+	if aic.recorder == nil {
+		return 0
+	}
+	return aic.recorder.FramesWritten()
+}
+
+// EnableNoiseSuppression installs an RNNoise-style recurrent denoiser (see
+// denoise.Denoiser) between aic's input node and its pre-fader tap,
+// gating the denoised output by the frame's voice-activity probability
+// against threshold: 0 passes every frame through regardless of VAD, 1
+// requires librnnoise judge the frame unambiguous speech before it's let
+// through. threshold must be in [0,1].
+//
+// Safe to call while the channel is running, and safe to call again to
+// retune threshold without tearing down and reinstalling the denoiser.
+// Returns an error - never a crash - when librnnoise isn't installed (see
+// denoise.Available).
+//
+// The private tap-like block that would actually resample aic's native
+// buffers to denoise.FrameSize at 48kHz, run them through the Denoiser
+// this installs, and write the result back into the render buffer needs
+// the same cgo render trampoline avaudio/tap.InstallCallbackTap's
+// tap_install_callback is waiting on (see that doc comment) - this wires
+// up the Go-side denoiser and threshold so that trampoline has something
+// to drive once it lands, the same way ProcessingChannel's render unit
+// wires a callback that isn't invoked yet either.
+func (aic *AudioInputChannel) EnableNoiseSuppression(threshold float32) error {
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("noise suppression threshold must be in [0,1], got %f", threshold)
+	}
+
+	aic.noiseMu.Lock()
+	defer aic.noiseMu.Unlock()
+
+	if aic.noiseSuppression == nil {
+		d, err := denoise.New()
+		if err != nil {
+			return fmt.Errorf("enable noise suppression: %w", err)
+		}
+		aic.noiseSuppression = d
+	}
+	aic.noiseGateThreshold = threshold
+	return nil
+}
+
+// DisableNoiseSuppression removes the denoiser EnableNoiseSuppression
+// installed, releasing its native DenoiseState. A no-op if noise
+// suppression isn't currently enabled.
+func (aic *AudioInputChannel) DisableNoiseSuppression() error {
+	aic.noiseMu.Lock()
+	defer aic.noiseMu.Unlock()
+
+	if aic.noiseSuppression == nil {
+		return nil
+	}
+	err := aic.noiseSuppression.Close()
+	aic.noiseSuppression = nil
+	return err
+}
+
+// Start starts the audio input channel and creates AVFoundation connections
+func (aic *AudioInputChannel) Start() error {
+	// Call base channel start first
+	if err := aic.BaseChannel.Start(); err != nil {
+		return err
+	}
+
+	// ✅ CORRECT PATTERN: Use explicit format matching (from your research)
+	// The key insight: both connections must use the same explicit format
+	avEngine := aic.engine.getAVEngine()
+
+	// Get the input node's output format - this is the reference format
+	fmt.Printf("🔍 Getting input node format for proper routing...\n")
+	// Note: We need to add a method to get the input format from Go
+	// For now, let's try with the engine's spec format, then nil as fallback
+
+	// Step 1: Connect inputNode → pre-fader tap with explicit format. The
+	// tap sits ahead of outputMixer's gain stage so pre-fader aux sends
+	// (BaseChannel.AddSend) carry the unattenuated signal.
+	fmt.Printf("🔗 PROPER: Connecting inputNode %p → preFaderTap %p (bus %d → 0)\n",
+		aic.inputNode, aic.preFaderTap, aic.inputBus)
+
+	// Try with engine's spec format first (proper approach)
+	err := avEngine.Connect(aic.inputNode, aic.preFaderTap, aic.inputBus, 0)
+	if err != nil {
+		// Fallback to nil format if spec format fails
+		err = avEngine.ConnectWithFormat(aic.inputNode, aic.preFaderTap, aic.inputBus, 0, nil)
+		if err != nil {
+			return fmt.Errorf("failed to connect input to pre-fader tap: %w", err)
+		}
+		fmt.Printf("✅ Input → Pre-fader tap connected (nil format fallback)\n")
+	} else {
+		fmt.Printf("✅ Input → Pre-fader tap connected (engine spec format)\n")
+	}
+
+	// Step 1b: Connect pre-fader tap → individual channel mixer, unity gain
+	err = avEngine.Connect(aic.preFaderTap, aic.outputMixer, 0, 0)
+	if err != nil {
+		err = avEngine.ConnectWithFormat(aic.preFaderTap, aic.outputMixer, 0, 0, nil)
+		if err != nil {
+			return fmt.Errorf("failed to connect pre-fader tap to channel mixer: %w", err)
+		}
+	}
+
+	// Step 2: Connect individual channel mixer → main mixer with SAME format
+	mainMixer, err := avEngine.MainMixerNode()
+	if err != nil {
+		return fmt.Errorf("failed to get main mixer: %w", err)
+	}
+
+	fmt.Printf("🔗 PROPER: Connecting channelMixer %p → mainMixer %p (0 → 0)\n",
+		aic.outputMixer, mainMixer)
+
+	// Use the same format approach as the first connection
+	err = avEngine.Connect(aic.outputMixer, mainMixer, 0, 0)
+	if err != nil {
 		return fmt.Errorf("failed to connect channel mixer to main mixer: %w", err)
 	}
 	fmt.Printf("✅ Channel mixer → Main mixer connected (consistent format)\n")
-	
+
 	fmt.Printf("✅ PROPER ARCHITECTURE: Complete signal path established!\n")
 	fmt.Printf("   🎯 InputNode → ChannelMixer → MainMixer → Output (with proper formats)\n")
 
@@ -513,17 +1458,46 @@ func (aic *AudioInputChannel) Start() error {
 
 // Stop stops the audio input channel and disconnects AVFoundation connections
 func (aic *AudioInputChannel) Stop() error {
-	// Disconnect from output mixer
+	avEngine := aic.engine.getAVEngine()
+
+	// Disconnect input bus 0 of the output mixer (where the pre-fader tap connects to)
 	if aic.outputMixer != nil {
-		avEngine := aic.engine.getAVEngine()
-		// Disconnect input bus 0 of the output mixer (where this channel connects to)
 		avEngine.DisconnectNodeInput(aic.outputMixer, 0)
 	}
+	// Disconnect input bus 0 of the pre-fader tap (where inputNode connects to)
+	if aic.preFaderTap != nil {
+		avEngine.DisconnectNodeInput(aic.preFaderTap, 0)
+	}
 
 	// Call base channel stop
 	return aic.BaseChannel.Stop()
 }
 
+// GetState returns the audio input channel's serializable state, including
+// its device UID, input bus, and monitoring level under Config (see
+// SetState and createChannelFromState, which already reads these three
+// keys when recreating a channel from a loaded state). Unlike inputBus and
+// monitoringLevel, deviceUID is capture-only as far as SetState is
+// concerned: restoring it live requires the Stop/reconnect/Start dance
+// Dispatcher.changeChannelDevice already does, so SetState leaves it alone
+// and scene recall instead calls changeChannelDevice explicitly for
+// channels whose captured UID differs from their current one.
+func (aic *AudioInputChannel) GetState() ChannelState {
+	state := aic.BaseChannel.GetState()
+	state.Config = map[string]interface{}{
+		"deviceUID":       aic.deviceUID,
+		"inputBus":        aic.inputBus,
+		"monitoringLevel": aic.monitoringLevel,
+	}
+	return state
+}
+
+// SetState restores the audio input channel's bookkeeping from state. It
+// does not restore state.Config["deviceUID"] - see GetState.
+func (aic *AudioInputChannel) SetState(state ChannelState) error {
+	return aic.BaseChannel.SetState(state)
+}
+
 // NewMidiInputChannel creates a new MIDI input channel
 func NewMidiInputChannel(name string, config MidiInputConfig, engine *Engine) (*MidiInputChannel, error) {
 	baseChannel := NewBaseChannel(name, ChannelTypeMidiInput, engine)
@@ -536,37 +1510,225 @@ func NewMidiInputChannel(name string, config MidiInputConfig, engine *Engine) (*
 	}, nil
 }
 
+// GetState returns the MIDI input channel's serializable state, including
+// its device UID under Config (see SetState).
+func (mic *MidiInputChannel) GetState() ChannelState {
+	state := mic.BaseChannel.GetState()
+	state.Config = map[string]interface{}{
+		"deviceUID": mic.deviceUID,
+	}
+	return state
+}
+
+// SetState restores the MIDI input channel's bookkeeping from state,
+// including its device UID (see GetState). Unlike AudioInputChannel, a MIDI
+// channel's device UID is a plain field with no stream to reconnect, so
+// restoring it here directly is safe.
+func (mic *MidiInputChannel) SetState(state ChannelState) error {
+	if err := mic.BaseChannel.SetState(state); err != nil {
+		return err
+	}
+	if state.Config == nil {
+		return nil
+	}
+	if v, ok := state.Config["deviceUID"].(string); ok {
+		mic.config.DeviceUID = v
+		mic.deviceUID = v
+	}
+	return nil
+}
+
 // NewPlaybackChannel creates a new playback channel
 func NewPlaybackChannel(name string, config PlaybackConfig, engine *Engine) (*PlaybackChannel, error) {
 	baseChannel := NewBaseChannel(name, ChannelTypePlayback, engine)
 
+	// Create a dedicated mixer for this channel, same per-channel mixer
+	// pattern as AudioInputChannel: the player node connects into this
+	// mixer once Play actually opens the file, and the rest of the signal
+	// path (aux sends, master) routes from here rather than from the
+	// player node directly.
+	avEngine := engine.getAVEngine()
+	outputMixer, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel mixer: %w", err)
+	}
+	preFaderTap, err := avEngine.CreateMixerNode() // Pre-fader tap for aux sends, see BaseChannel.AddSend
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-fader tap: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+	baseChannel.preFaderTap = preFaderTap
+
+	return &PlaybackChannel{
+		BaseChannel:        baseChannel,
+		config:             config,
+		filePath:           config.FilePath,
+		loopEnabled:        config.LoopEnabled,
+		autoStart:          config.AutoStart,
+		fadeIn:             config.FadeIn,
+		fadeOut:            config.FadeOut,
+		fadeCurve:          config.FadeCurve,
+		isPlaying:          false,
+		isPaused:           false,
+		position:           0.0,
+		replayGainMode:     config.ReplayGainMode,
+		replayGainPreampDB: config.ReplayGainPreampDB,
+	}, nil
+}
+
+// NewPlaybackChannelFromDecoder creates a playback channel that streams
+// audio from dec (see Decoder/RegisterDecoder) instead of a filesystem path
+// AVAudioFile must understand directly (see NewPlaybackChannel). Opening
+// dec is deferred to the first Play, same as NewPlaybackChannel defers
+// opening its file.
+func NewPlaybackChannelFromDecoder(name string, dec Decoder, engine *Engine) (*PlaybackChannel, error) {
+	baseChannel := NewBaseChannel(name, ChannelTypePlayback, engine)
+
+	avEngine := engine.getAVEngine()
+	outputMixer, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel mixer: %w", err)
+	}
+	preFaderTap, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-fader tap: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+	baseChannel.preFaderTap = preFaderTap
+
 	return &PlaybackChannel{
 		BaseChannel: baseChannel,
-		config:      config,
-		filePath:    config.FilePath,
-		loopEnabled: config.LoopEnabled,
-		autoStart:   config.AutoStart,
-		fadeIn:      config.FadeIn,
-		fadeOut:     config.FadeOut,
-		isPlaying:   false,
-		isPaused:    false,
-		position:    0.0,
+		decoder:     dec,
 	}, nil
 }
 
+// streamDecoder schedules pc.decoder's blocks onto player as they arrive,
+// in a goroutine that exits (closing the decoder) once Blocks is exhausted.
+// Sample-rate/channel-count mismatches between dec.Spec() and the engine's
+// format are left to AVAudioEngine's own node-connection format
+// negotiation (see Connect/ConnectWithFormat's fallback, used throughout
+// this file) rather than a separate converter stage.
+func (pc *PlaybackChannel) streamDecoder(player *avengine.AudioPlayer) {
+	spec := pc.decoder.Spec()
+	decoder := pc.decoder
+	go func() {
+		defer decoder.Close()
+		for block := range decoder.Blocks() {
+			buf := avengine.PCMBuffer{
+				Samples:      block.Samples,
+				FrameCount:   block.Frames,
+				ChannelCount: spec.ChannelCount,
+				SampleRate:   spec.SampleRate,
+			}
+			if err := player.ScheduleBuffer(buf, false); err != nil {
+				pc.engine.errorHandler.HandleError(fmt.Errorf("failed to schedule decoded block: %w", err))
+				return
+			}
+		}
+	}()
+}
+
+// openPlayer creates the native AVAudioPlayerNode-backed player for this
+// channel, loads filePath into it, and connects it into the channel's
+// dedicated mixer. Opening is deferred to the first Play rather than done
+// in NewPlaybackChannel so a channel can be created (and serialized) before
+// its file exists on disk.
+func (pc *PlaybackChannel) openPlayer() (*avengine.AudioPlayer, error) {
+	avEngine := pc.engine.getAVEngine()
+	player, err := avEngine.NewPlayer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create player: %w", err)
+	}
+
+	if pc.decoder != nil {
+		pc.streamDecoder(player)
+	} else if err := player.LoadFile(pc.filePath); err != nil {
+		player.Destroy()
+		return nil, fmt.Errorf("failed to load %s: %w", pc.filePath, err)
+	}
+	// Connect through the pre-fader tap rather than straight into outputMixer,
+	// so pre-fader aux sends (BaseChannel.AddSend) carry the unattenuated signal.
+	if err := player.ConnectToMixer(pc.preFaderTap, 0); err != nil {
+		player.Destroy()
+		return nil, fmt.Errorf("failed to connect player to pre-fader tap: %w", err)
+	}
+	if err := avEngine.Connect(pc.preFaderTap, pc.outputMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(pc.preFaderTap, pc.outputMixer, 0, 0, nil); err != nil {
+			player.Destroy()
+			return nil, fmt.Errorf("failed to connect pre-fader tap to channel mixer: %w", err)
+		}
+	}
+	return player, nil
+}
+
 // NewAuxChannel creates a new auxiliary channel
 func NewAuxChannel(name string, config AuxConfig, engine *Engine) (*AuxChannel, error) {
 	baseChannel := NewBaseChannel(name, ChannelTypeAux, engine)
 
+	// Dedicated input mixer that sums every channel's send into this aux,
+	// one input bus per send (see BaseChannel.AddSend/allocateSendBus).
+	avEngine := engine.getAVEngine()
+	outputMixer, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aux input mixer: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+
 	return &AuxChannel{
-		BaseChannel: baseChannel,
-		config:      config,
-		sendLevel:   config.SendLevel,
-		returnLevel: config.ReturnLevel,
-		preFader:    config.PreFader,
+		BaseChannel:      baseChannel,
+		config:           config,
+		sendLevel:        config.SendLevel,
+		returnLevel:      config.ReturnLevel,
+		preFader:         config.PreFader,
+		sidechainTargets: append([]string(nil), config.SidechainTargets...),
 	}, nil
 }
 
+// allocateSendBus hands out the next free input bus on this aux's input
+// mixer to a newly-added BaseChannel.AddSend.
+func (ac *AuxChannel) allocateSendBus() int {
+	ac.sendBusMu.Lock()
+	defer ac.sendBusMu.Unlock()
+	bus := ac.nextSendBus
+	ac.nextSendBus++
+	return bus
+}
+
+// AddSidechainTarget designates this aux channel as the sidechain key input
+// for the plugin instance identified by channelID/instanceID, recording the
+// intent on both sides so it round-trips through GetState/SetState.
+func (ac *AuxChannel) AddSidechainTarget(channelID, instanceID string) error {
+	if ac.engine == nil {
+		return fmt.Errorf("aux channel not connected to engine")
+	}
+	ch, ok := ac.engine.GetChannel(channelID)
+	if !ok {
+		return fmt.Errorf("unknown channel %q", channelID)
+	}
+	instance, ok := ch.GetPluginChain().GetInstance(instanceID)
+	if !ok {
+		return fmt.Errorf("unknown plugin instance %q on channel %q", instanceID, channelID)
+	}
+	if err := instance.SetSidechainSource(ac.GetIDString()); err != nil {
+		return err
+	}
+
+	ac.mu.Lock()
+	ac.sidechainTargets = append(ac.sidechainTargets, channelID+":"+instanceID)
+	ac.mu.Unlock()
+	return nil
+}
+
+// SidechainTargets returns the "channelID:instanceID" targets currently
+// using this aux as a sidechain key input.
+func (ac *AuxChannel) SidechainTargets() []string {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	targets := make([]string, len(ac.sidechainTargets))
+	copy(targets, ac.sidechainTargets)
+	return targets
+}
+
 // Master channel specific methods
 
 // Start starts the master channel and connects main mixer to output
@@ -593,7 +1755,7 @@ func (mc *MasterChannel) Start() error {
 
 		// CRITICAL: Check if main mixer is already connected to output
 		fmt.Printf("🔍 Checking current main mixer connections...\n")
-		
+
 		// Connect main mixer to output (this is the critical missing link!)
 		fmt.Printf("🔗 Connecting mixer %p to output %p...\n", mainMixer, outputNode)
 		if err := mc.engine.avEngine.Connect(mainMixer, outputNode, 0, 0); err != nil {
@@ -603,7 +1765,7 @@ func (mc *MasterChannel) Start() error {
 		} else {
 			fmt.Println("✅ Main mixer to output connection successful!")
 		}
-		
+
 		// VERIFICATION: Set main mixer volume to ensure it's working
 		fmt.Printf("🔊 Setting main mixer output volume to 1.0...\n")
 		if err := mc.engine.avEngine.SetMixerVolume(mainMixer, 1.0); err != nil {
@@ -611,6 +1773,12 @@ func (mc *MasterChannel) Start() error {
 		} else {
 			fmt.Printf("✅ Main mixer volume set to 100%%\n")
 		}
+
+		if mc.limiterEnabled {
+			if err := mc.applyLimiterEnabled(true); err != nil {
+				fmt.Printf("⚠️ Failed to insert master limiter: %v\n", err)
+			}
+		}
 	}
 
 	return nil
@@ -647,7 +1815,7 @@ func (mc *MasterChannel) SetMasterVolume(volume float32) error {
 func (mc *MasterChannel) GetMasterVolume() (float32, error) {
 	mc.mu.RLock()
 	defer mc.mu.RUnlock()
-	
+
 	// If the engine is running, get the actual volume from AVFoundation
 	if mc.engine != nil && mc.engine.avEngine != nil && mc.engine.IsRunning() {
 		mainMixerPtr, err := mc.engine.avEngine.MainMixerNode()
@@ -660,18 +1828,19 @@ func (mc *MasterChannel) GetMasterVolume() (float32, error) {
 			}
 		}
 	}
-	
+
 	// Fallback to cached value
 	return mc.masterVolume, nil
 }
 
-// SetLimiterEnabled enables or disables the output limiter
-func (mc *MasterChannel) SetLimiterEnabled(enabled bool) {
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-	mc.limiterEnabled = enabled
-
-	// TODO: Apply to actual limiter
+// SetLimiterEnabled enables or disables the output limiter. Inserting or
+// removing the limiter AU is a topology change, so it's routed through
+// engine.dispatcher rather than applied directly here.
+func (mc *MasterChannel) SetLimiterEnabled(enabled bool) error {
+	if mc.engine == nil || mc.engine.dispatcher == nil {
+		return fmt.Errorf("master channel not connected to a running dispatcher")
+	}
+	return mc.engine.dispatcher.SetMasterLimiterEnabled(enabled)
 }
 
 // IsLimiterEnabled returns whether the output limiter is enabled
@@ -681,26 +1850,294 @@ func (mc *MasterChannel) IsLimiterEnabled() bool {
 	return mc.limiterEnabled
 }
 
+// applyLimiterEnabled splices the AUDynamicsProcessor limiter into (or out
+// of) the signal path between MainMixerNode and OutputNode, preserving
+// format the same way AudioInputChannel.Start does. Must only be called
+// from the dispatcher goroutine (see Dispatcher.setMasterLimiter).
+func (mc *MasterChannel) applyLimiterEnabled(enabled bool) error {
+	if mc.engine == nil || mc.engine.avEngine == nil {
+		return fmt.Errorf("master channel not connected to engine")
+	}
+	avEngine := mc.engine.avEngine
+
+	mainMixer, err := avEngine.MainMixerNode()
+	if err != nil {
+		return fmt.Errorf("failed to get main mixer node: %w", err)
+	}
+	outputNode, err := avEngine.OutputNode()
+	if err != nil {
+		return fmt.Errorf("failed to get output node: %w", err)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if enabled {
+		if mc.limiterEffect != nil {
+			mc.limiterEnabled = true
+			return nil // already inserted
+		}
+
+		plugin, err := masterLimiterInfo.Introspect()
+		if err != nil {
+			return fmt.Errorf("failed to introspect limiter: %w", err)
+		}
+		effect, err := unit.CreateEffect(plugin)
+		if err != nil {
+			return fmt.Errorf("failed to create limiter: %w", err)
+		}
+		if err := avEngine.Attach(effect.Ptr()); err != nil {
+			effect.Release()
+			return fmt.Errorf("failed to attach limiter: %w", err)
+		}
+
+		// Splice the limiter between mainMixer and outputNode.
+		if err := avEngine.DisconnectNodeInput(outputNode, 0); err != nil {
+			avEngine.Detach(effect.Ptr())
+			effect.Release()
+			return fmt.Errorf("failed to disconnect main mixer from output: %w", err)
+		}
+		if err := avEngine.Connect(mainMixer, effect.Ptr(), 0, 0); err != nil {
+			if err := avEngine.ConnectWithFormat(mainMixer, effect.Ptr(), 0, 0, nil); err != nil {
+				avEngine.Detach(effect.Ptr())
+				effect.Release()
+				return fmt.Errorf("failed to connect main mixer to limiter: %w", err)
+			}
+		}
+		if err := avEngine.Connect(effect.Ptr(), outputNode, 0, 0); err != nil {
+			if err := avEngine.ConnectWithFormat(effect.Ptr(), outputNode, 0, 0, nil); err != nil {
+				avEngine.Detach(effect.Ptr())
+				effect.Release()
+				return fmt.Errorf("failed to connect limiter to output: %w", err)
+			}
+		}
+
+		mc.limiterEffect = effect
+		mc.limiterPlugin = plugin
+		mc.limiterEnabled = true
+
+		// Re-apply any settings staged before the limiter existed.
+		mc.applyLimiterParamLocked(limiterParamThreshold, mc.limiterThreshold)
+		mc.applyLimiterParamLocked(limiterParamAttack, mc.limiterAttack)
+		mc.applyLimiterParamLocked(limiterParamRelease, mc.limiterRelease)
+
+		return nil
+	}
+
+	if mc.limiterEffect == nil {
+		mc.limiterEnabled = false
+		return nil // already bypassed
+	}
+
+	if err := avEngine.DisconnectNodeInput(outputNode, 0); err != nil {
+		return fmt.Errorf("failed to disconnect limiter from output: %w", err)
+	}
+	if err := avEngine.Detach(mc.limiterEffect.Ptr()); err != nil {
+		return fmt.Errorf("failed to detach limiter: %w", err)
+	}
+	mc.limiterEffect.Release()
+	mc.limiterEffect = nil
+	mc.limiterPlugin = nil
+	mc.limiterEnabled = false
+
+	if err := avEngine.Connect(mainMixer, outputNode, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(mainMixer, outputNode, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to reconnect main mixer to output: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyLimiterParamLocked pushes value to the live limiter AU, if one is
+// inserted. Callers must hold mc.mu.
+func (mc *MasterChannel) applyLimiterParamLocked(identifier string, value float32) error {
+	if mc.limiterEffect == nil || mc.limiterPlugin == nil {
+		return nil // staged only, applied when the limiter is next inserted
+	}
+	for _, param := range mc.limiterPlugin.Parameters {
+		if param.Identifier == identifier || param.DisplayName == identifier {
+			return mc.limiterEffect.SetParameter(param, value)
+		}
+	}
+	return fmt.Errorf("limiter has no %q parameter", identifier)
+}
+
+// SetLimiterThreshold sets the limiter's threshold in dBFS. Staged and
+// applied immediately if the limiter is currently inserted.
+func (mc *MasterChannel) SetLimiterThreshold(dbfs float32) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.limiterThreshold = dbfs
+	return mc.applyLimiterParamLocked(limiterParamThreshold, dbfs)
+}
+
+// GetLimiterThreshold returns the limiter's threshold in dBFS.
+func (mc *MasterChannel) GetLimiterThreshold() float32 {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.limiterThreshold
+}
+
+// SetLimiterAttack sets the limiter's attack time in milliseconds.
+func (mc *MasterChannel) SetLimiterAttack(ms float32) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.limiterAttack = ms
+	return mc.applyLimiterParamLocked(limiterParamAttack, ms)
+}
+
+// GetLimiterAttack returns the limiter's attack time in milliseconds.
+func (mc *MasterChannel) GetLimiterAttack() float32 {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.limiterAttack
+}
+
+// SetLimiterRelease sets the limiter's release time in milliseconds.
+func (mc *MasterChannel) SetLimiterRelease(ms float32) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.limiterRelease = ms
+	return mc.applyLimiterParamLocked(limiterParamRelease, ms)
+}
+
+// GetLimiterRelease returns the limiter's release time in milliseconds.
+func (mc *MasterChannel) GetLimiterRelease() float32 {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.limiterRelease
+}
+
+// GetLimiterGainReduction returns the limiter's current gain reduction,
+// read from the AU's Compression Amount meter. Intended to be polled on
+// the same cadence as tap meters. Returns 0 if the limiter isn't inserted.
+func (mc *MasterChannel) GetLimiterGainReduction() float32 {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	if mc.limiterEffect == nil || mc.limiterPlugin == nil {
+		return 0
+	}
+	for _, param := range mc.limiterPlugin.Parameters {
+		if param.Identifier == limiterParamCompression || param.DisplayName == limiterParamCompression {
+			value, err := mc.limiterEffect.GetParameter(param)
+			if err != nil {
+				return 0
+			}
+			return value
+		}
+	}
+	return 0
+}
+
+// GetState returns the master channel's serializable state, including
+// master volume and limiter settings under Config (see SetState).
+func (mc *MasterChannel) GetState() ChannelState {
+	state := mc.BaseChannel.GetState()
+
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	state.Config = map[string]interface{}{
+		"masterVolume":     mc.masterVolume,
+		"limiterEnabled":   mc.limiterEnabled,
+		"limiterThreshold": mc.limiterThreshold,
+		"limiterAttack":    mc.limiterAttack,
+		"limiterRelease":   mc.limiterRelease,
+	}
+	return state
+}
+
+// SetState restores the master channel's bookkeeping from state, including
+// master volume and limiter settings (see GetState). Like BaseChannel.SetState
+// this only restores bookkeeping fields; the limiter node itself is
+// (re)inserted the next time Start or SetLimiterEnabled runs.
+func (mc *MasterChannel) SetState(state ChannelState) error {
+	if err := mc.BaseChannel.SetState(state); err != nil {
+		return err
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if state.Config == nil {
+		return nil
+	}
+	if v, ok := state.Config["masterVolume"].(float64); ok {
+		mc.masterVolume = float32(v)
+	}
+	if v, ok := state.Config["limiterEnabled"].(bool); ok {
+		mc.limiterEnabled = v
+	}
+	if v, ok := state.Config["limiterThreshold"].(float64); ok {
+		mc.limiterThreshold = float32(v)
+	}
+	if v, ok := state.Config["limiterAttack"].(float64); ok {
+		mc.limiterAttack = float32(v)
+	}
+	if v, ok := state.Config["limiterRelease"].(float64); ok {
+		mc.limiterRelease = float32(v)
+	}
+	return nil
+}
+
 // Playback channel specific methods
 
-// Play starts playback
+// Play starts (or resumes) playback, opening the native player on first
+// use and applying FadeIn as a ramp scheduled on top of it.
 func (pc *PlaybackChannel) Play() error {
 	pc.mu.Lock()
-	defer pc.mu.Unlock()
-
 	if pc.isPlaying && !pc.isPaused {
+		pc.mu.Unlock()
 		return nil // Already playing
 	}
+	resuming := pc.isPaused
+	player := pc.player
+	startPosition := pc.position
+	fadeIn, fadeCurve := pc.fadeIn, pc.fadeCurve
+	pc.mu.Unlock()
+
+	if player == nil {
+		var err error
+		player, err = pc.openPlayer()
+		if err != nil {
+			return err
+		}
+	}
+
+	if resuming {
+		if err := player.Play(); err != nil {
+			return err
+		}
+	} else {
+		if fadeIn > 0 {
+			if err := player.SetVolume(0); err != nil {
+				return err
+			}
+		}
+		if err := player.PlayAt(startPosition); err != nil {
+			return err
+		}
+		if fadeIn > 0 {
+			go func() { _ = player.RampVolume(1, secondsToDuration(fadeIn), fadeCurve) }()
+		}
+	}
 
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	pc.mu.Lock()
+	pc.player = player
 	pc.isPlaying = true
 	pc.isPaused = false
+	pc.watchStop = stop
+	pc.done = done
+	pc.mu.Unlock()
 
-	// TODO: Start actual audio playback
+	go pc.watch(player, stop, done)
 
 	return nil
 }
 
-// Pause pauses playback
+// Pause pauses playback, leaving the player and its scheduled segment in
+// place so Play can resume without reopening the file.
 func (pc *PlaybackChannel) Pause() error {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
@@ -708,50 +2145,457 @@ func (pc *PlaybackChannel) Pause() error {
 	if !pc.isPlaying || pc.isPaused {
 		return nil // Not playing or already paused
 	}
-
+	if pc.player == nil {
+		return fmt.Errorf("playback channel has no active player")
+	}
+	if err := pc.player.Pause(); err != nil {
+		return err
+	}
 	pc.isPaused = true
-
-	// TODO: Pause actual audio playback
-
 	return nil
 }
 
-// Stop stops playback and resets position
+// StopPlayback stops playback, resets position to the start, and tears
+// down the native player so the next Play reopens the file fresh.
 func (pc *PlaybackChannel) StopPlayback() error {
 	pc.mu.Lock()
-	defer pc.mu.Unlock()
-
+	player := pc.player
+	stop := pc.watchStop
+	pc.player = nil
+	pc.watchStop = nil
 	pc.isPlaying = false
 	pc.isPaused = false
 	pc.position = 0.0
+	pc.mu.Unlock()
 
-	// TODO: Stop actual audio playback
-
-	return nil
+	if stop != nil {
+		close(stop)
+	}
+	if player == nil {
+		return nil
+	}
+	err := player.Stop()
+	player.Destroy()
+	return err
 }
 
-// GetPosition returns current playback position in seconds
+// GetPosition returns current playback position in seconds, read from the
+// native player's render-derived clock when one is active.
 func (pc *PlaybackChannel) GetPosition() float64 {
+	pc.mu.RLock()
+	player := pc.player
+	cached := pc.position
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return cached
+	}
+	current, err := player.GetCurrentTime()
+	if err != nil {
+		return cached
+	}
+	return current.Seconds()
+}
+
+// PlaybackPosition returns the current playback position (see GetPosition,
+// its seconds-based equivalent).
+func (pc *PlaybackChannel) PlaybackPosition() (time.Duration, error) {
+	pc.mu.RLock()
+	player := pc.player
+	cached := pc.position
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return time.Duration(cached * float64(time.Second)), nil
+	}
+	return player.GetCurrentTime()
+}
+
+// Duration returns the total length of the loaded file, as reported by the
+// native player.
+func (pc *PlaybackChannel) Duration() (time.Duration, error) {
+	pc.mu.RLock()
+	player := pc.player
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return 0, fmt.Errorf("playback channel has no active player")
+	}
+	return player.GetDuration()
+}
+
+// IsPlaying reports whether the channel is actively playing, i.e. started
+// and not paused.
+func (pc *PlaybackChannel) IsPlaying() bool {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	return pc.isPlaying && !pc.isPaused
+}
+
+// SetLoop enables or disables looping for the next time playback reaches
+// end of file (see watch's loop branch).
+func (pc *PlaybackChannel) SetLoop(loop bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.loopEnabled = loop
+}
+
+// Done returns a channel that closes when the current play session ends -
+// either by reaching end of file without looping, or by StopPlayback -
+// mirroring AVAudioPlayerNode's scheduleFile:completionHandler:. Before the
+// first Play, it returns nil, which blocks forever like an unscheduled
+// completion handler never firing.
+func (pc *PlaybackChannel) Done() <-chan struct{} {
 	pc.mu.RLock()
 	defer pc.mu.RUnlock()
-	return pc.position
+	return pc.done
 }
 
-// SetPosition sets playback position in seconds
+// FadeTo ramps the channel's player volume to target over duration, using
+// the channel's configured fade curve (see PlaybackConfig.FadeCurve).
+func (pc *PlaybackChannel) FadeTo(target float32, duration time.Duration) error {
+	pc.mu.RLock()
+	player := pc.player
+	curve := pc.fadeCurve
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return fmt.Errorf("playback channel has no active player")
+	}
+	return player.RampVolume(target, duration, curve)
+}
+
+// SetRate sets the playback speed (0.25x-4.0x, normal = 1.0), via the
+// underlying avengine.AudioPlayer's time/pitch unit - see
+// avengine.AudioPlayer.SetPlaybackRate for the exact range and the
+// EnableTimePitchEffects requirement it documents.
+func (pc *PlaybackChannel) SetRate(rate float32) error {
+	pc.mu.RLock()
+	player := pc.player
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return fmt.Errorf("playback channel has no active player")
+	}
+	return player.SetPlaybackRate(rate)
+}
+
+// GetRate returns the current playback speed.
+func (pc *PlaybackChannel) GetRate() (float32, error) {
+	pc.mu.RLock()
+	player := pc.player
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return 1.0, fmt.Errorf("playback channel has no active player")
+	}
+	return player.GetPlaybackRate()
+}
+
+// SetPitch sets the pitch shift in semitones (-12 to +12, normal = 0).
+// avengine.AudioPlayer.SetPitch takes cents (100 cents = 1 semitone), so
+// semitones is scaled before the call.
+func (pc *PlaybackChannel) SetPitch(semitones float32) error {
+	if semitones < -12 || semitones > 12 {
+		return fmt.Errorf("pitch must be between -12 and +12 semitones")
+	}
+
+	pc.mu.RLock()
+	player := pc.player
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return fmt.Errorf("playback channel has no active player")
+	}
+	return player.SetPitch(semitones * 100)
+}
+
+// GetPitch returns the current pitch shift in semitones.
+func (pc *PlaybackChannel) GetPitch() (float32, error) {
+	pc.mu.RLock()
+	player := pc.player
+	pc.mu.RUnlock()
+
+	if player == nil {
+		return 0, fmt.Errorf("playback channel has no active player")
+	}
+	cents, err := player.GetPitch()
+	if err != nil {
+		return 0, err
+	}
+	return cents / 100, nil
+}
+
+// SetPosition sets playback position in seconds. See Seek.
 func (pc *PlaybackChannel) SetPosition(position float64) error {
+	return pc.Seek(time.Duration(position * float64(time.Second)))
+}
+
+// Seek stops the currently scheduled segment and reschedules playback
+// from the given position. If playback isn't active, it just records the
+// position for the next Play.
+func (pc *PlaybackChannel) Seek(position time.Duration) error {
 	if position < 0 {
 		return fmt.Errorf("position cannot be negative")
 	}
+	seconds := position.Seconds()
 
 	pc.mu.Lock()
-	defer pc.mu.Unlock()
-	pc.position = position
+	player := pc.player
+	pc.position = seconds
+	pc.mu.Unlock()
 
-	// TODO: Seek in actual audio playback
+	if player == nil {
+		return nil
+	}
+	return player.SeekTo(seconds)
+}
+
+// ComputePeaks returns a downsampled min/max peak envelope for this
+// channel's file, suitable for waveform UI rendering. See
+// waveform.ComputePeaks for the output layout, progress, and caching
+// behavior; this is a thin wrapper binding it to pc.filePath.
+func (pc *PlaybackChannel) ComputePeaks(ctx context.Context, opts waveform.Options, onProgress func(float64)) ([]int16, error) {
+	pc.mu.RLock()
+	filePath := pc.filePath
+	pc.mu.RUnlock()
+
+	if filePath == "" {
+		return nil, fmt.Errorf("channel has no file loaded")
+	}
+	return waveform.ComputePeaks(ctx, filePath, opts, onProgress)
+}
+
+// ensurePlaylist lazily creates the channel's queue playlist on first
+// Enqueue, routed into a dedicated bus on this channel's own outputMixer
+// (not the engine's main mixer, and not bus 0/preFaderTap - that bus stays
+// the single-file Play path's) so per-channel volume/pan/mute still apply
+// to queued playback. TrackStarted events are forwarded to
+// engine.dispatcher as EventNowPlaying.
+func (pc *PlaybackChannel) ensurePlaylist() (*avengine.Playlist, error) {
+	pc.queueMu.Lock()
+	defer pc.queueMu.Unlock()
+
+	if pc.playlist != nil {
+		return pc.playlist, nil
+	}
+	if pc.engine == nil {
+		return nil, fmt.Errorf("channel not connected to engine")
+	}
+
+	playlist := avengine.NewPlaylist(pc.engine.getAVEngine())
+	playlist.SetDestination(pc.outputMixer, 1)
+	pc.playlist = playlist
+
+	go pc.forwardQueueEvents(playlist)
+	return playlist, nil
+}
+
+// forwardQueueEvents relays playlist's TrackStarted events to the engine's
+// dispatcher as EventNowPlaying, for the lifetime of playlist (the goroutine
+// exits once Events is closed, which doesn't currently happen - queues are
+// expected to live as long as the channel).
+func (pc *PlaybackChannel) forwardQueueEvents(playlist *avengine.Playlist) {
+	for ev := range playlist.Events() {
+		if ev.Type != avengine.TrackStarted {
+			continue
+		}
+		if pc.engine == nil || pc.engine.dispatcher == nil {
+			continue
+		}
+		pc.engine.dispatcher.emitEvent(DispatcherEvent{
+			Type:      EventNowPlaying,
+			ChannelID: pc.GetIDString(),
+			Path:      ev.Path,
+		})
+	}
+}
+
+// gainScalar computes the playback gain scalar for opts given the channel's
+// configured ReplayGainMode and preamp, clamped so gain*peak never exceeds
+// unity (avoiding clipping on tracks whose stored peak is accurate).
+func (pc *PlaybackChannel) gainScalar(opts QueueEntryOptions) float32 {
+	pc.mu.RLock()
+	mode := pc.replayGainMode
+	preampDB := pc.replayGainPreampDB
+	pc.mu.RUnlock()
+
+	var gainDB, peak float64
+	var hasGain bool
+	switch mode {
+	case ReplayGainTrack:
+		gainDB, peak, hasGain = opts.TrackGain, opts.TrackPeak, opts.HasTrackGain
+	case ReplayGainAlbum:
+		gainDB, peak, hasGain = opts.AlbumGain, opts.AlbumPeak, opts.HasAlbumGain
+	}
+
+	scalar := dbToScalar(float64(preampDB))
+	if hasGain {
+		scalar *= dbToScalar(gainDB)
+		if peak > 0 {
+			if max := float32(1.0 / peak); scalar > max {
+				scalar = max
+			}
+		}
+	}
+	return scalar
+}
+
+func dbToScalar(db float64) float32 {
+	return float32(math.Pow(10, db/20))
+}
+
+// Enqueue appends path to this channel's playback queue with opts' ReplayGain
+// metadata (pass a zero QueueEntryOptions, or use ComputeReplayGain first,
+// if path isn't tagged). The queue pre-opens and starts each track while the
+// previous one is still playing (see avengine.Playlist), so the join is
+// gapless. The first Enqueue call starts playback; later calls just extend
+// the queue - call Skip to jump ahead of it.
+func (pc *PlaybackChannel) Enqueue(path string, opts QueueEntryOptions) error {
+	playlist, err := pc.ensurePlaylist()
+	if err != nil {
+		return err
+	}
 
+	idx := playlist.Add(path)
+	if err := playlist.SetTrackGain(idx, pc.gainScalar(opts)); err != nil {
+		return err
+	}
+
+	pc.queueMu.Lock()
+	pc.queue = append(pc.queue, QueueEntry{Path: path, Options: opts})
+	first := len(pc.queue) == 1
+	pc.queueMu.Unlock()
+
+	if first {
+		return playlist.Play()
+	}
 	return nil
 }
 
+// Skip advances the queue to the next track immediately.
+func (pc *PlaybackChannel) Skip() error {
+	pc.queueMu.Lock()
+	playlist := pc.playlist
+	pc.queueMu.Unlock()
+
+	if playlist == nil {
+		return fmt.Errorf("queue is empty")
+	}
+	return playlist.Next()
+}
+
+// QueueState snapshots the channel's current queue and playback position.
+func (pc *PlaybackChannel) QueueState() QueueState {
+	pc.queueMu.Lock()
+	defer pc.queueMu.Unlock()
+
+	entries := make([]QueueEntry, len(pc.queue))
+	copy(entries, pc.queue)
+
+	var position int
+	if pc.playlist != nil {
+		position = pc.playlist.Position()
+	}
+	return QueueState{Entries: entries, Position: position}
+}
+
+// ComputeReplayGain scans path with an ITU-R BS.1770 loudness measurement
+// (see the replaygain package) and returns QueueEntryOptions with
+// TrackGain/TrackPeak populated, for files without ReplayGain tags.
+// AlbumGain/AlbumPeak are left unset - album normalization needs every
+// track on the album measured together, which is out of scope for a
+// single-file helper.
+func ComputeReplayGain(path string) (QueueEntryOptions, error) {
+	result, err := replaygain.Compute(path)
+	if err != nil {
+		return QueueEntryOptions{}, err
+	}
+	return QueueEntryOptions{
+		TrackGain:    result.Gain,
+		TrackPeak:    result.Peak,
+		HasTrackGain: true,
+	}, nil
+}
+
+// watch polls the player to drive the fade-out trigger and, for
+// LoopEnabled channels, gapless restart, since there's no native
+// end-of-file callback exposed here (the same limitation documented on
+// engine.Playlist.watchForEnd). Looping restarts the already-loaded
+// player rather than reopening the file, avoiding the reopen glitch.
+func (pc *PlaybackChannel) watch(player *avengine.AudioPlayer, stop, done chan struct{}) {
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	defer close(done)
+
+	fadingOut := false
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		pc.mu.RLock()
+		fadeOut, fadeIn, curve := pc.fadeOut, pc.fadeIn, pc.fadeCurve
+		loop := pc.loopEnabled
+		paused := pc.isPaused
+		pc.mu.RUnlock()
+		if paused {
+			continue
+		}
+
+		duration, err := player.GetDuration()
+		if err != nil {
+			continue
+		}
+		current, err := player.GetCurrentTime()
+		if err != nil {
+			continue
+		}
+
+		if !fadingOut && fadeOut > 0 && current >= duration-secondsToDuration(fadeOut) {
+			fadingOut = true
+			go func() { _ = player.RampVolume(0, secondsToDuration(fadeOut), curve) }()
+		}
+
+		playing, err := player.IsPlaying()
+		if err != nil || playing {
+			continue
+		}
+
+		if !loop {
+			pc.mu.Lock()
+			pc.isPlaying = false
+			pc.isPaused = false
+			pc.mu.Unlock()
+			return
+		}
+
+		// Reached end of file with looping enabled: restart the same
+		// player immediately rather than reloading it.
+		fadingOut = false
+		pc.mu.Lock()
+		pc.position = 0
+		pc.mu.Unlock()
+		if fadeIn > 0 {
+			_ = player.SetVolume(0)
+		} else {
+			_ = player.SetVolume(1)
+		}
+		if err := player.Play(); err != nil {
+			return
+		}
+		if fadeIn > 0 {
+			go func() { _ = player.RampVolume(1, secondsToDuration(fadeIn), curve) }()
+		}
+	}
+}
+
+func secondsToDuration(seconds float32) time.Duration {
+	return time.Duration(float64(seconds) * float64(time.Second))
+}
+
 // Aux channel specific methods
 
 // SetSendLevel sets the auxiliary send level
@@ -805,11 +2649,42 @@ func (ac *AuxChannel) Cleanup() error {
 		return fmt.Errorf("failed to stop aux channel during cleanup: %w", err)
 	}
 
-	// Clear all connections
+	// Tear down the send bus graph: disconnect every bus this aux's input
+	// mixer ever handed out, whether or not a sender still holds it.
+	ac.sendBusMu.Lock()
+	if ac.engine != nil && ac.outputMixer != nil {
+		avEngine := ac.engine.getAVEngine()
+		for bus := 0; bus < ac.nextSendBus; bus++ {
+			avEngine.DisconnectNodeInput(ac.outputMixer, bus)
+		}
+	}
+	ac.nextSendBus = 0
+	ac.sendBusMu.Unlock()
+
+	// Tear down the sidechain graph: clear the wiring recorded on every
+	// plugin instance this aux feeds as a sidechain key input.
 	ac.mu.Lock()
+	targets := ac.sidechainTargets
+	ac.sidechainTargets = nil
 	ac.connections = make([]Connection, 0)
 	ac.mu.Unlock()
 
+	if ac.engine != nil {
+		for _, target := range targets {
+			channelID, instanceID, ok := strings.Cut(target, ":")
+			if !ok {
+				continue
+			}
+			ch, ok := ac.engine.GetChannel(channelID)
+			if !ok {
+				continue
+			}
+			if instance, ok := ch.GetPluginChain().GetInstance(instanceID); ok {
+				instance.ClearSidechainSource()
+			}
+		}
+	}
+
 	// Reset to default values
 	ac.sendLevel = 0.0
 	ac.returnLevel = 0.0
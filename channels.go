@@ -1,5 +1,7 @@
 package macaudio
 
+import "time"
+
 // Channel represents the common interface for all audio channel types
 type Channel interface {
 	// Identity and lifecycle
@@ -13,6 +15,15 @@ type Channel interface {
 	ConnectTo(target Channel, bus int) error
 	DisconnectFrom(target Channel, bus int) error
 	GetConnections() []Connection
+	RouteTo(bus *Bus) error
+	SetOutputRouting(opts OutputRoutingOptions) error
+	GetOutputRouting() OutputRoutingOptions
+
+	// Aux sends
+	AddSend(aux *AuxChannel, level float32, preFader bool) error
+	RemoveSend(aux *AuxChannel) error
+	GetSends() []ChannelSend
+	SetSendLevel(aux *AuxChannel, level float32) error
 	
 	// Plugin chain management
 	GetPluginChain() *PluginChain
@@ -22,25 +33,57 @@ type Channel interface {
 	// Audio processing
 	SetVolume(volume float32) error
 	GetVolume() (float32, error)
+	GetEffectiveVolume() (float32, error)
 	SetPan(pan float32) error
 	GetPan() (float32, error)
 	SetMute(muted bool) error
 	GetMute() (bool, error)
-	
+	SetVolumeRamp(target float32, duration time.Duration, curve RampCurve) (<-chan struct{}, error)
+	SetPanRamp(target float32, duration time.Duration, curve RampCurve) (<-chan struct{}, error)
+	CancelRamps() error
+
 	// Serialization for state persistence
 	GetState() ChannelState
 	SetState(state ChannelState) error
+
+	// MIDI-learn bindings
+	GetMidiBindings() []MidiBinding
+	AddMidiBinding(binding MidiBinding)
+	ClearMidiBindings(parameter string)
+
+	// Change notification (see ChannelListener)
+	AddListener(listener ChannelListener)
+	RemoveListener(listener ChannelListener)
+}
+
+// ChannelListener receives notifications when a channel's mix state changes,
+// so the OSC surface, UI, undo/redo stack, and state persistence layer can
+// react to mutations instead of polling GetState. Register one with
+// Channel.AddListener; it fires synchronously, after the change has already
+// taken effect. The same listener can be attached to many channels at once -
+// every callback is handed channelID so it knows which one fired.
+type ChannelListener interface {
+	OnVolumeChanged(channelID string, volume float32)
+	OnPanChanged(channelID string, pan float32)
+	OnMuteChanged(channelID string, muted bool)
+	OnConnectionChanged(channelID string, connections []Connection)
 }
 
 // ChannelType represents the different types of audio channels
 type ChannelType string
 
 const (
-	ChannelTypeAudioInput ChannelType = "audio_input"
-	ChannelTypeMidiInput  ChannelType = "midi_input"
-	ChannelTypePlayback   ChannelType = "playback"
-	ChannelTypeAux        ChannelType = "aux"
-	ChannelTypeMaster     ChannelType = "master"
+	ChannelTypeAudioInput    ChannelType = "audio_input"
+	ChannelTypeLoopbackInput ChannelType = "loopback_input"
+	ChannelTypeMidiInput     ChannelType = "midi_input"
+	ChannelTypePlayback      ChannelType = "playback"
+	ChannelTypeAux           ChannelType = "aux"
+	ChannelTypeMaster        ChannelType = "master"
+	ChannelTypeProcessing    ChannelType = "processing"
+	ChannelTypeSynth         ChannelType = "synth"
+	ChannelTypeSampler       ChannelType = "sampler"
+	ChannelTypeBus           ChannelType = "bus"
+	ChannelTypeGroup         ChannelType = "group"
 )
 
 // Connection represents a connection between channels
@@ -51,14 +94,32 @@ type Connection struct {
 	TargetBus     int
 }
 
+// ChannelSendState is the serializable form of one ChannelSend. AuxName is
+// the destination AuxChannel's name at snapshot time, not a stable
+// identifier - restore resolves it back to a channel by name (see
+// BaseChannel.SetState), so renaming an aux channel between snapshot and
+// restore leaves that send unresolved rather than silently rewired to the
+// wrong destination.
+type ChannelSendState struct {
+	AuxName  string  `json:"auxName"`
+	Level    float32 `json:"level"`
+	PreFader bool    `json:"preFader"`
+}
+
 // ChannelState represents the serializable state of a channel
 type ChannelState struct {
-	ID          string            `json:"id"`
-	Type        ChannelType       `json:"type"`
-	Volume      float32           `json:"volume"`
-	Pan         float32           `json:"pan"`
-	Muted       bool              `json:"muted"`
-	Connections []Connection      `json:"connections"`
-	PluginChain PluginChainState  `json:"pluginChain"`
-	Config      map[string]interface{} `json:"config,omitempty"`
+	ID           string                 `json:"id"`
+	Type         ChannelType            `json:"type"`
+	Volume       float32                `json:"volume"`
+	Pan          float32                `json:"pan"`
+	Muted        bool                   `json:"muted"`
+	Position     [3]float32             `json:"position"`
+	Forward      [3]float32             `json:"forward"`
+	Up           [3]float32             `json:"up"`
+	SpatialMode  SpatialMode            `json:"spatialMode"`
+	Connections  []Connection           `json:"connections"`
+	Sends        []ChannelSendState     `json:"sends,omitempty"`
+	PluginChain  PluginChainState       `json:"pluginChain"`
+	Config       map[string]interface{} `json:"config,omitempty"`
+	MidiBindings []MidiBinding          `json:"midiBindings,omitempty"`
 }
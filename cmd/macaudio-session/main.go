@@ -0,0 +1,40 @@
+//go:build darwin
+
+// Command macaudio-session is a command-line surface for inspecting and
+// driving a live macaudio session: device discovery, plugin scanning,
+// preset management, and tailing the audit log. It's a thin wrapper around
+// github.com/shaban/macaudio/sessioncli.Run.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/shaban/macaudio/session"
+	"github.com/shaban/macaudio/sessioncli"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: macaudio-session <devices|plugins|presets|audit> ...")
+		os.Exit(2)
+	}
+
+	sess, err := session.NewSessionWithDefaults()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "macaudio-session: create session: %v\n", err)
+		os.Exit(1)
+	}
+	defer sess.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := sessioncli.Run(ctx, sessioncli.CLIConfig{Args: os.Args[1:]}, sess); err != nil {
+		fmt.Fprintf(os.Stderr, "macaudio-session: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !darwin
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "macaudio-session requires macOS (darwin); session, sessioncli, and their native dependencies are darwin-only")
+	os.Exit(1)
+}
@@ -0,0 +1,112 @@
+package macaudio
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec encodes and decodes an EngineState to and from a particular wire
+// format. Serializer defaults to JSONCodec; pass a different Codec via
+// WithCodec to read or write TOML, YAML, or a custom format instead.
+type Codec interface {
+	Encode(w io.Writer, state EngineState) error
+	Decode(r io.Reader) (EngineState, error)
+}
+
+// JSONCodec is the default Codec, matching the pretty-printed format
+// Serializer has always used.
+type JSONCodec struct{}
+
+// Encode writes state as indented JSON.
+func (JSONCodec) Encode(w io.Writer, state EngineState) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(state)
+}
+
+// Decode reads state as JSON. It does not apply schema migrations itself;
+// Serializer.LoadFromReader handles that separately for the JSON path.
+func (JSONCodec) Decode(r io.Reader) (EngineState, error) {
+	var state EngineState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return EngineState{}, err
+	}
+	return state, nil
+}
+
+// TOMLCodec encodes/decodes EngineState as TOML.
+type TOMLCodec struct{}
+
+// Encode writes state as TOML.
+func (TOMLCodec) Encode(w io.Writer, state EngineState) error {
+	return toml.NewEncoder(w).Encode(state)
+}
+
+// Decode reads state as TOML.
+func (TOMLCodec) Decode(r io.Reader) (EngineState, error) {
+	var state EngineState
+	if _, err := toml.NewDecoder(r).Decode(&state); err != nil {
+		return EngineState{}, err
+	}
+	return state, nil
+}
+
+// YAMLCodec encodes/decodes EngineState as YAML.
+type YAMLCodec struct{}
+
+// Encode writes state as YAML.
+func (YAMLCodec) Encode(w io.Writer, state EngineState) error {
+	return yaml.NewEncoder(w).Encode(state)
+}
+
+// Decode reads state as YAML.
+func (YAMLCodec) Decode(r io.Reader) (EngineState, error) {
+	var state EngineState
+	if err := yaml.NewDecoder(r).Decode(&state); err != nil {
+		return EngineState{}, err
+	}
+	return state, nil
+}
+
+// codecForExtension picks a Codec by path's file extension, defaulting to
+// JSONCodec for .json or anything unrecognized.
+func codecForExtension(path string) Codec {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return TOMLCodec{}
+	case ".yaml", ".yml":
+		return YAMLCodec{}
+	default:
+		return JSONCodec{}
+	}
+}
+
+// serializerOptions holds the resolved settings for a single
+// SaveToWriter/LoadFromReader call.
+type serializerOptions struct {
+	codec Codec
+}
+
+// SerializerOption configures a single SaveToWriter/LoadFromReader call.
+type SerializerOption func(*serializerOptions)
+
+// WithCodec selects the Codec a SaveToWriter/LoadFromReader call uses
+// instead of the default JSONCodec.
+func WithCodec(codec Codec) SerializerOption {
+	return func(o *serializerOptions) {
+		o.codec = codec
+	}
+}
+
+func resolveOptions(opts []SerializerOption) serializerOptions {
+	resolved := serializerOptions{codec: JSONCodec{}}
+	for _, opt := range opts {
+		opt(&resolved)
+	}
+	return resolved
+}
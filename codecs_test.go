@@ -0,0 +1,80 @@
+package macaudio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func sampleState() EngineState {
+	return EngineState{
+		Version:       "1.1.0",
+		Configuration: EngineConfig{},
+		Channels:      map[string]ChannelState{},
+		Connections:   []Connection{},
+		Metadata:      map[string]interface{}{},
+	}
+}
+
+func TestCodecsRoundTripEngineState(t *testing.T) {
+	codecs := map[string]Codec{
+		"json": JSONCodec{},
+		"toml": TOMLCodec{},
+		"yaml": YAMLCodec{},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			state := sampleState()
+			if err := codec.Encode(&buf, state); err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			decoded, err := codec.Decode(&buf)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if decoded.Version != state.Version {
+				t.Errorf("expected version %s, got %s", state.Version, decoded.Version)
+			}
+		})
+	}
+}
+
+func TestCodecForExtensionPicksByPath(t *testing.T) {
+	cases := map[string]Codec{
+		"session.toml": TOMLCodec{},
+		"session.yaml": YAMLCodec{},
+		"session.yml":  YAMLCodec{},
+		"session.json": JSONCodec{},
+		"session":      JSONCodec{},
+	}
+
+	for path, want := range cases {
+		got := codecForExtension(path)
+		if got != want {
+			t.Errorf("codecForExtension(%q) = %T, want %T", path, got, want)
+		}
+	}
+}
+
+func TestSerializerSaveLoadFileRoundTripsAcrossCodecs(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	for _, ext := range []string{".json", ".toml", ".yaml"} {
+		path := t.TempDir() + "/session" + ext
+		if err := serializer.SaveToFile(path); err != nil {
+			t.Fatalf("SaveToFile(%s) failed: %v", ext, err)
+		}
+		if err := serializer.LoadFromFile(path); err != nil {
+			t.Fatalf("LoadFromFile(%s) failed: %v", ext, err)
+		}
+	}
+}
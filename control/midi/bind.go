@@ -0,0 +1,542 @@
+// Package midi turns inbound MIDI CC, Note, and Pitch Bend messages into
+// parameter changes on engine/channel mixing objects - channel.Channel
+// (including engine/channel/input.MonoToStereoChannel), channel.MasterBus,
+// a channel.Bus input's send level, a BaseChannel's named sends, and its
+// solo state - through a fluent Bind(source).To*() API.
+//
+// It's the engine/channel counterpart to two things that already existed:
+// control/osc/midi.go's BindChannelCC, which wires a fixed CC7/CC10 pair to
+// the legacy engine.Channel and nothing else, and avaudio/engine's
+// BindMIDIController/BindMIDIController14Bit, which already does fluent-ish
+// CC (and 14-bit CC-pair) binding but only onto a PluginChain's
+// MIDIParameterTarget - neither reaches channel.Channel/MasterBus/Bus. This
+// package generalizes the idea for that stack: selectable Source (CC,
+// CCPair, Note, PitchBend), a value Curve, Learn, and JSON persistence of
+// the mapping table.
+//
+// ToVolumeTapered binds through a console-style node.FaderCurve instead of
+// ToVolume's flat Curve, for control surfaces (Mackie Control Universal and
+// similar) whose physical fader throw is itself already console-tapered.
+// ToSendLevel/ToSendMute/ToSoloToggle/ToSoloMomentary reach a BaseChannel's
+// named-send and solo API, which isn't part of the channel.Channel
+// interface and so is only available when the bound object actually
+// implements it - see curve.go and feedback.go.
+//
+// Mapper is transport-agnostic: feed it avaudio/midi.Events from a live
+// avaudio/midi.Controller (see Listen) or, for tests, straight from an
+// avaudio/midi.Feeder - no hardware required either way. SetDispatcher
+// routes every binding's dispatch through a queue.Dispatcher, so a
+// MIDI-driven parameter change is serialized with graph mutations the same
+// way control/osc.Surface's own writes already are. Feedback sends a bound
+// CC source's current value back out, for motorized faders and LED rings.
+package midi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	wiremidi "github.com/shaban/macaudio/avaudio/midi"
+	"github.com/shaban/macaudio/engine/channel"
+	"github.com/shaban/macaudio/engine/queue"
+)
+
+// Curve re-exports avaudio/midi.Curve so most callers only need this
+// package's import for both the binding API and the curve constants.
+type Curve = wiremidi.Curve
+
+const (
+	CurveLinear = wiremidi.CurveLinear
+	CurveLog    = wiremidi.CurveLog
+	CurveExp    = wiremidi.CurveExp
+)
+
+// PanCurve selects the value range ToPan scales a bound control into.
+type PanCurve int
+
+const (
+	// PanBipolar scales into [-1, 1] - the shape a pan pot needs, and
+	// ToPan's default.
+	PanBipolar PanCurve = iota
+	// PanUnipolar scales into [0, 1], for a control that should only ever
+	// push pan toward one side (e.g. a hard-left/hard-right button pair
+	// bound as two separate Notes).
+	PanUnipolar
+)
+
+// Source is implemented by CC, CCPair, Note, and PitchBend - the MIDI
+// messages a Binding can react to.
+type Source interface {
+	key() sourceKey
+	bits() int // value resolution Dispatch should scale raw against: 7 or 14
+}
+
+type sourceKind int
+
+const (
+	kindCC sourceKind = iota
+	kindCCPair
+	kindNote
+	kindPitchBend
+)
+
+type sourceKey struct {
+	kind       sourceKind
+	channel    int
+	controller int
+	lsb        int
+	note       int
+}
+
+// CC matches a plain 7-bit continuous controller.
+type CC struct {
+	Channel    int // 0-15; -1 matches any channel
+	Controller int // 0-127
+}
+
+func (c CC) key() sourceKey {
+	return sourceKey{kind: kindCC, channel: c.Channel, controller: c.Controller}
+}
+func (c CC) bits() int { return 7 }
+
+// CCPair matches a 14-bit coarse+fine CC pair (MSB then LSB) - the pairing
+// convention most motorized faders and rotary encoders use instead of
+// NRPN's four-message sequence (see wiremidi.NRPNBinding for that one, and
+// avaudio/engine.BindMIDIController14Bit for the same idiom on the legacy
+// engine stack).
+type CCPair struct {
+	Channel int
+	MSB     int
+	LSB     int
+}
+
+func (c CCPair) key() sourceKey {
+	return sourceKey{kind: kindCCPair, channel: c.Channel, controller: c.MSB, lsb: c.LSB}
+}
+func (c CCPair) bits() int { return 14 }
+
+// Note matches Note On/Off on one channel+note - On fires ToMuteToggle's
+// toggle, On/Off together drive a momentary target.
+type Note struct {
+	Channel int
+	Note    int
+}
+
+func (n Note) key() sourceKey { return sourceKey{kind: kindNote, channel: n.Channel, note: n.Note} }
+func (n Note) bits() int      { return 7 }
+
+// PitchBend matches a channel's Pitch Bend wheel (14-bit, 8192 = center).
+type PitchBend struct {
+	Channel int
+}
+
+func (p PitchBend) key() sourceKey { return sourceKey{kind: kindPitchBend, channel: p.Channel} }
+func (p PitchBend) bits() int      { return 14 }
+
+// binding is one registered Source -> target action, plus enough of its own
+// shape to round-trip through SaveBindings/LoadBindings.
+type binding struct {
+	source Source
+	onCC   func(raw int) error // CC/CCPair/PitchBend
+	onNote func(on bool) error // Note
+	entry  SavedBinding
+
+	// pendingMSB/haveMSB track a CCPair binding's most recent MSB value
+	// until its LSB half arrives - the same coarse-then-fine assumption
+	// avaudio/engine's ccBinding.lsbValue makes, rather than NRPN's
+	// four-message state machine.
+	pendingMSB int
+	haveMSB    bool
+}
+
+// sendTarget is satisfied by any channel.Channel with BaseChannel's named
+// aux-bus sends (SetSendLevel/GetSendLevel/SetSendMute/GetSendMute) - the
+// same subset control/osc.sendable type-asserts for its own
+// /ch/<name>/send/<sendName>/level address. channel.Bus/MasterBus don't
+// implement it.
+type sendTarget interface {
+	SetSendLevel(sendName string, level float32) error
+	GetSendLevel(sendName string) (float32, error)
+	SetSendMute(sendName string, muted bool) error
+	GetSendMute(sendName string) (bool, error)
+}
+
+// soloTarget is satisfied by any channel.Channel that participates in
+// engine/channel's package-wide DefaultSolo manager (BaseChannel.SetSolo/
+// IsSoloed) - control/osc.soloable type-asserts the same subset for its own
+// /solo/<name> address.
+type soloTarget interface {
+	SetSolo(bool)
+	IsSoloed() bool
+}
+
+// Mapper turns inbound MIDI messages into parameter changes through a
+// fluent Bind(source).To*() API. The zero value is not usable - construct
+// one with NewMapper.
+type Mapper struct {
+	mu         sync.Mutex
+	bindings   map[sourceKey]*binding
+	dispatcher *queue.Dispatcher
+}
+
+// SetDispatcher installs d so every subsequent Dispatch call applies its
+// matched binding's target change through d.RunSync instead of calling it
+// directly, serializing MIDI-driven parameter changes with whatever graph
+// mutations (Attach/Connect, a transactional Txn.Commit) the same engine's
+// queue.Dispatcher already serializes. Pass nil to go back to applying
+// directly - the default, and what every existing caller/test already
+// relies on.
+func (m *Mapper) SetDispatcher(d *queue.Dispatcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatcher = d
+}
+
+// apply runs fn directly, or through m.dispatcher's RunSync if one is set
+// (see SetDispatcher).
+func (m *Mapper) apply(fn func() error) error {
+	m.mu.Lock()
+	d := m.dispatcher
+	m.mu.Unlock()
+	if d == nil {
+		return fn()
+	}
+	return d.RunSync(func(ctx context.Context) error { return fn() })
+}
+
+// NewMapper creates an empty Mapper.
+func NewMapper() *Mapper {
+	return &Mapper{bindings: make(map[sourceKey]*binding)}
+}
+
+// Binding is the fluent continuation Bind returns; call exactly one To*
+// method on it to pick a target and finish registering the binding.
+type Binding struct {
+	m      *Mapper
+	source Source
+}
+
+// Bind starts registering source; call a To* method on the result to pick
+// its target.
+func (m *Mapper) Bind(source Source) *Binding {
+	return &Binding{m: m, source: source}
+}
+
+func (b *Binding) installCC(entry SavedBinding, onCC func(raw int) error) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	b.m.bindings[b.source.key()] = &binding{source: b.source, onCC: onCC, entry: entry}
+}
+
+func (b *Binding) installNote(entry SavedBinding, onNote func(on bool) error) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	b.m.bindings[b.source.key()] = &binding{source: b.source, onNote: onNote, entry: entry}
+}
+
+// ToVolume binds a continuous source to ch's SetVolume, scaled through
+// curve into [0,1].
+func (b *Binding) ToVolume(ch channel.Channel, curve Curve) *Binding {
+	entry := b.entry("volume", ch.GetName(), 0, 1, curve)
+	b.installCC(entry, scaler(b.source, 0, 1, curve, ch.SetVolume))
+	return b
+}
+
+// ToMasterVolume binds a continuous source to mb's SetLevel, scaled through
+// curve into [0,1].
+func (b *Binding) ToMasterVolume(mb *channel.MasterBus, curve Curve) *Binding {
+	entry := b.entry("master-volume", "master", 0, 1, curve)
+	b.installCC(entry, scaler(b.source, 0, 1, curve, mb.SetLevel))
+	return b
+}
+
+// ToPan binds a continuous source to ch's SetPan, scaled per panCurve
+// (PanBipolar into [-1,1], PanUnipolar into [0,1]).
+func (b *Binding) ToPan(ch channel.Channel, panCurve PanCurve) *Binding {
+	min, max := float32(-1), float32(1)
+	if panCurve == PanUnipolar {
+		min = 0
+	}
+	entry := b.entry("pan", ch.GetName(), min, max, CurveLinear)
+	b.installCC(entry, scaler(b.source, min, max, CurveLinear, ch.SetPan))
+	return b
+}
+
+// ToBusSend binds a continuous source to bus's input-level fader for the
+// send at the given input index, scaled through curve into [0,1].
+func (b *Binding) ToBusSend(bus *channel.Bus, input int, curve Curve) *Binding {
+	entry := b.entry(fmt.Sprintf("send:%d", input), bus.Name(), 0, 1, curve)
+	b.installCC(entry, scaler(b.source, 0, 1, curve, func(v float32) error {
+		return bus.SetInputLevel(input, v)
+	}))
+	return b
+}
+
+// ToMuteToggle binds a Note source to flip ch's mute on every Note On (Note
+// Off is ignored) - the plain toggle behavior a Bind'd mute button usually
+// wants, mirroring macaudio.MidiBindingToggle.
+func (b *Binding) ToMuteToggle(ch channel.Channel) *Binding {
+	entry := b.entry("mute-toggle", ch.GetName(), 0, 0, CurveLinear)
+	b.installNote(entry, func(on bool) error {
+		if !on {
+			return nil
+		}
+		muted, err := ch.GetMute()
+		if err != nil {
+			return err
+		}
+		return ch.SetMute(!muted)
+	})
+	return b
+}
+
+// ToMuteMomentary binds a Note source to mute ch for as long as the note is
+// held - set on Note On, cleared on Note Off - mirroring
+// macaudio.MidiBindingMomentary.
+func (b *Binding) ToMuteMomentary(ch channel.Channel) *Binding {
+	entry := b.entry("mute-momentary", ch.GetName(), 0, 0, CurveLinear)
+	b.installNote(entry, func(on bool) error {
+		return ch.SetMute(on)
+	})
+	return b
+}
+
+// ToSendLevel binds a continuous source to ch's named send level
+// (BaseChannel.SetSendLevel), scaled through curve into [0,1]. ch is
+// type-asserted against sendTarget at dispatch time rather than here, so
+// installing the binding never fails; dispatching it against a channel
+// that doesn't implement named sends (channel.Bus/MasterBus never reach
+// this method in the first place, but a custom channel.Channel might)
+// reports the same error SetChSendLevel does in control/osc.
+func (b *Binding) ToSendLevel(ch channel.Channel, sendName string, curve Curve) *Binding {
+	entry := b.entry("send-name:"+sendName, ch.GetName(), 0, 1, curve)
+	b.installCC(entry, scaler(b.source, 0, 1, curve, func(v float32) error {
+		sendCh, ok := ch.(sendTarget)
+		if !ok {
+			return fmt.Errorf("midi: channel %q does not support named sends", ch.GetName())
+		}
+		return sendCh.SetSendLevel(sendName, v)
+	}))
+	return b
+}
+
+// ToSendMute binds a Note source to flip ch's named send mute on every Note
+// On (Note Off is ignored), mirroring ToMuteToggle's toggle shape.
+func (b *Binding) ToSendMute(ch channel.Channel, sendName string) *Binding {
+	entry := b.entry("send-mute:"+sendName, ch.GetName(), 0, 0, CurveLinear)
+	b.installNote(entry, func(on bool) error {
+		if !on {
+			return nil
+		}
+		sendCh, ok := ch.(sendTarget)
+		if !ok {
+			return fmt.Errorf("midi: channel %q does not support named sends", ch.GetName())
+		}
+		muted, err := sendCh.GetSendMute(sendName)
+		if err != nil {
+			return err
+		}
+		return sendCh.SetSendMute(sendName, !muted)
+	})
+	return b
+}
+
+// ToSoloToggle binds a Note source to flip ch's solo state (via
+// engine/channel's DefaultSolo manager) on every Note On, mirroring
+// ToMuteToggle.
+func (b *Binding) ToSoloToggle(ch channel.Channel) *Binding {
+	entry := b.entry("solo-toggle", ch.GetName(), 0, 0, CurveLinear)
+	b.installNote(entry, func(on bool) error {
+		if !on {
+			return nil
+		}
+		soloCh, ok := ch.(soloTarget)
+		if !ok {
+			return fmt.Errorf("midi: channel %q does not support solo", ch.GetName())
+		}
+		soloCh.SetSolo(!soloCh.IsSoloed())
+		return nil
+	})
+	return b
+}
+
+// ToSoloMomentary binds a Note source to solo ch for as long as the note is
+// held, mirroring ToMuteMomentary.
+func (b *Binding) ToSoloMomentary(ch channel.Channel) *Binding {
+	entry := b.entry("solo-momentary", ch.GetName(), 0, 0, CurveLinear)
+	b.installNote(entry, func(on bool) error {
+		soloCh, ok := ch.(soloTarget)
+		if !ok {
+			return fmt.Errorf("midi: channel %q does not support solo", ch.GetName())
+		}
+		soloCh.SetSolo(on)
+		return nil
+	})
+	return b
+}
+
+func (b *Binding) entry(parameter, target string, min, max float32, curve Curve) SavedBinding {
+	e := SavedBinding{Parameter: parameter, Target: target, Min: min, Max: max, Curve: curve}
+	switch s := b.source.(type) {
+	case CC:
+		e.Kind, e.Channel, e.Controller = "cc", s.Channel, s.Controller
+	case CCPair:
+		e.Kind, e.Channel, e.Controller, e.LSB = "ccpair", s.Channel, s.MSB, s.LSB
+	case Note:
+		e.Kind, e.Channel, e.Note = "note", s.Channel, s.Note
+	case PitchBend:
+		e.Kind, e.Channel = "pitchbend", s.Channel
+	}
+	return e
+}
+
+// scaler returns the raw-value handler Dispatch calls for a continuous
+// (CC/CCPair/PitchBend) source, scaling raw through curve into [min,max]
+// before calling set - resolving the raw value's bit depth from source so
+// the same helper serves both 7-bit CCs and 14-bit CC pairs/Pitch Bend.
+func scaler(source Source, min, max float32, curve Curve, set func(float32) error) func(raw int) error {
+	top := float64((1 << source.bits()) - 1)
+	return func(raw int) error {
+		t := curve.Apply(float64(raw) / top)
+		return set(min + float32(t)*(max-min))
+	}
+}
+
+// Dispatch applies e to whatever binding matches it, if any. It's the single
+// entry point Listen and tests both drive - a live avaudio/midi.Controller
+// feeds it through Listen, and a test feeds it directly from an
+// avaudio/midi.Feeder, with identical effect.
+func (m *Mapper) Dispatch(e wiremidi.Event) error {
+	switch e.Type {
+	case wiremidi.EventCC:
+		return m.dispatchCC(e.Channel, e.Controller, e.Value)
+	case wiremidi.EventPitchBend:
+		return m.dispatchContinuous(sourceKey{kind: kindPitchBend, channel: e.Channel}, e.Value)
+	case wiremidi.EventNoteOn:
+		return m.dispatchNote(e.Channel, e.Note, true)
+	case wiremidi.EventNoteOff:
+		return m.dispatchNote(e.Channel, e.Note, false)
+	}
+	return nil
+}
+
+// dispatchCC resolves e against both a plain CC binding and, if e.Controller
+// is some binding's CCPair MSB or LSB half, that pair's 14-bit binding -
+// mirroring avaudio/midi.Controller's own per-(channel,controller) CC
+// dispatch, but for this package's CCPair the pairing is static (declared at
+// Bind time) rather than the running NRPN state machine NRPNBinding needs.
+func (m *Mapper) dispatchCC(ch, controller, raw int) error {
+	m.mu.Lock()
+	var toApply []func(raw int) error
+	var rawArgs []int
+	for key, b := range m.bindings {
+		if !(key.channel == -1 || key.channel == ch) {
+			continue
+		}
+		switch {
+		case key.kind == kindCC && key.controller == controller:
+			toApply = append(toApply, b.onCC)
+			rawArgs = append(rawArgs, raw)
+		case key.kind == kindCCPair && key.controller == controller:
+			b.pendingMSB, b.haveMSB = raw, true
+		case key.kind == kindCCPair && key.lsb == controller:
+			msb, have := b.pendingMSB, b.haveMSB
+			if !have {
+				msb = 0
+			}
+			toApply = append(toApply, b.onCC)
+			rawArgs = append(rawArgs, msb<<7|raw)
+		}
+	}
+	m.mu.Unlock()
+
+	for i, onCC := range toApply {
+		raw := rawArgs[i]
+		if err := m.apply(func() error { return onCC(raw) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dispatchContinuous looks bindings up by exact key - used for PitchBend,
+// which (unlike CC) has nothing else to coalesce against.
+func (m *Mapper) dispatchContinuous(key sourceKey, raw int) error {
+	m.mu.Lock()
+	b, ok := m.bindings[key]
+	if !ok && key.channel != -1 {
+		b, ok = m.bindings[sourceKey{kind: key.kind, channel: -1}]
+	}
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return m.apply(func() error { return b.onCC(raw) })
+}
+
+func (m *Mapper) dispatchNote(ch, note int, on bool) error {
+	m.mu.Lock()
+	var toApply []func(on bool) error
+	for key, b := range m.bindings {
+		if key.kind == kindNote && (key.channel == -1 || key.channel == ch) && key.note == note {
+			toApply = append(toApply, b.onNote)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, onNote := range toApply {
+		if err := m.apply(func() error { return onNote(on) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen forwards every Event ctrl receives to Dispatch until stop is
+// called. It's the only place this package touches a live
+// avaudio/midi.Controller - Dispatch itself, and therefore every Binding,
+// has no idea whether an Event came from real hardware or a test Feeder.
+func (m *Mapper) Listen(ctrl *wiremidi.Controller) (stop func()) {
+	events := ctrl.EnableEventStream(16)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case e := <-events:
+				_ = m.Dispatch(e)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Learn blocks until the next CC, CCPair-eligible CC, Note, or Pitch Bend
+// event arrives on events (e.g. from ctrl.EnableEventStream or a
+// avaudio/midi.Feeder in a test) and returns the Source it describes, or
+// ErrLearnTimeout if none arrives within timeout. It never resolves a
+// CCPair on its own - a learned CC is always returned as a plain CC, since
+// there's no way to tell a standalone CC from one half of a pair from a
+// single message; callers who want a pair should call Learn twice (once per
+// half) and build the CCPair themselves from the two CC numbers reported.
+func Learn(events <-chan wiremidi.Event, timeout time.Duration) (Source, error) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case e := <-events:
+			switch e.Type {
+			case wiremidi.EventCC:
+				return CC{Channel: e.Channel, Controller: e.Controller}, nil
+			case wiremidi.EventNoteOn, wiremidi.EventNoteOff:
+				return Note{Channel: e.Channel, Note: e.Note}, nil
+			case wiremidi.EventPitchBend:
+				return PitchBend{Channel: e.Channel}, nil
+			}
+		case <-deadline:
+			return nil, ErrLearnTimeout
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package midi
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	wiremidi "github.com/shaban/macaudio/avaudio/midi"
+	"github.com/shaban/macaudio/engine/channel"
+	"github.com/shaban/macaudio/engine/channel/input"
+	"github.com/shaban/macaudio/engine/queue"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// feed drives a Mapper with synthetic events from a wiremidi.Feeder, rather
+// than a real avaudio/midi.Controller - no MIDI hardware needed to exercise
+// Dispatch.
+func feed(t *testing.T, m *Mapper, events ...wiremidi.Event) {
+	t.Helper()
+	for _, e := range events {
+		if err := m.Dispatch(e); err != nil {
+			t.Fatalf("dispatch %+v: %v", e, err)
+		}
+	}
+}
+
+// TestBindCCToPan checks a plain CC source driving ToPan on a real
+// MonoToStereoChannel.
+func TestBindCCToPan(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: "lead", Engine: eng})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+
+	m := NewMapper()
+	m.Bind(CC{Channel: 0, Controller: 10}).ToPan(mono, PanBipolar)
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 10, Value: 0})
+	if pan, _ := mono.GetPan(); pan != -1 {
+		t.Errorf("expected CC value 0 to pan hard left, got %.2f", pan)
+	}
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 10, Value: 127})
+	if pan, _ := mono.GetPan(); pan != 1 {
+		t.Errorf("expected CC value 127 to pan hard right, got %.2f", pan)
+	}
+}
+
+// TestBindCCPairToMasterVolume checks a 14-bit CC pair (MSB+LSB) driving
+// ToMasterVolume with finer-than-127-step resolution.
+func TestBindCCPairToMasterVolume(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mb, err := channel.NewMasterBus(eng)
+	if err != nil {
+		t.Fatalf("new master bus: %v", err)
+	}
+
+	m := NewMapper()
+	m.Bind(CCPair{Channel: 0, MSB: 0, LSB: 32}).ToMasterVolume(mb, CurveLinear)
+
+	// MSB=127, LSB=127 -> 16383/16383 -> full scale.
+	feed(t, m,
+		wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 0, Value: 127},
+		wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 32, Value: 127},
+	)
+	level, err := mb.GetLevel()
+	if err != nil {
+		t.Fatalf("GetLevel: %v", err)
+	}
+	if level < 0.99 {
+		t.Errorf("expected ~1.0 master level after a full-scale 14-bit pair, got %.4f", level)
+	}
+}
+
+// TestBindNoteToMuteToggle checks a Note source toggling mute on each Note
+// On, ignoring Note Off.
+func TestBindNoteToMuteToggle(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: "lead", Engine: eng})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+
+	m := NewMapper()
+	m.Bind(Note{Channel: 0, Note: 60}).ToMuteToggle(mono)
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventNoteOn, Channel: 0, Note: 60})
+	if muted, _ := mono.GetMute(); !muted {
+		t.Fatalf("expected Note On to toggle mute on")
+	}
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventNoteOff, Channel: 0, Note: 60})
+	if muted, _ := mono.GetMute(); !muted {
+		t.Errorf("expected Note Off to be ignored by a toggle binding")
+	}
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventNoteOn, Channel: 0, Note: 60})
+	if muted, _ := mono.GetMute(); muted {
+		t.Errorf("expected a second Note On to toggle mute back off")
+	}
+}
+
+// TestBindCCToBusSend checks a plain CC source driving ToBusSend on a real
+// Bus input.
+func TestBindCCToBusSend(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	bus, err := channel.NewBus(eng, "reverb")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: "lead", Engine: eng})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+	sendInput, err := bus.ConnectChannel(mono)
+	if err != nil {
+		t.Fatalf("connect channel to bus: %v", err)
+	}
+
+	m := NewMapper()
+	m.Bind(CC{Channel: 0, Controller: 91}).ToBusSend(bus, sendInput, CurveLinear)
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 91, Value: 127})
+	level, err := bus.GetInputLevel(sendInput)
+	if err != nil {
+		t.Fatalf("GetInputLevel: %v", err)
+	}
+	if level < 0.99 {
+		t.Errorf("expected CC value 127 to set the send to ~1.0, got %.4f", level)
+	}
+}
+
+// TestBindCCToSendLevel checks a plain CC source driving ToSendLevel on a
+// real BaseChannel's named send.
+func TestBindCCToSendLevel(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: "lead", Engine: eng})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+	if err := mono.CreateSend("reverb", mono, 0); err != nil {
+		t.Fatalf("create send: %v", err)
+	}
+
+	m := NewMapper()
+	m.Bind(CC{Channel: 0, Controller: 91}).ToSendLevel(mono, "reverb", CurveLinear)
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 91, Value: 127})
+	level, err := mono.GetSendLevel("reverb")
+	if err != nil {
+		t.Fatalf("GetSendLevel: %v", err)
+	}
+	if level < 0.99 {
+		t.Errorf("expected CC value 127 to set the send to ~1.0, got %.4f", level)
+	}
+}
+
+// TestBindNoteToSoloToggle checks a Note source toggling solo on each Note
+// On, ignoring Note Off - mirroring TestBindNoteToMuteToggle.
+func TestBindNoteToSoloToggle(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: "lead", Engine: eng})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+
+	m := NewMapper()
+	m.Bind(Note{Channel: 0, Note: 60}).ToSoloToggle(mono)
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventNoteOn, Channel: 0, Note: 60})
+	if !mono.IsSoloed() {
+		t.Fatalf("expected Note On to toggle solo on")
+	}
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventNoteOff, Channel: 0, Note: 60})
+	if !mono.IsSoloed() {
+		t.Errorf("expected Note Off to be ignored by a toggle binding")
+	}
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventNoteOn, Channel: 0, Note: 60})
+	if mono.IsSoloed() {
+		t.Errorf("expected a second Note On to toggle solo back off")
+	}
+}
+
+// TestMapperWithDispatcherAppliesBindings checks that a bound parameter
+// change still lands once SetDispatcher routes it through a
+// queue.Dispatcher instead of calling the binding directly.
+func TestMapperWithDispatcherAppliesBindings(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: "lead", Engine: eng})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+
+	d := queue.NewDispatcher(nil, nil)
+	d.Start()
+	defer d.Close()
+
+	m := NewMapper()
+	m.SetDispatcher(d)
+	m.Bind(CC{Channel: 0, Controller: 10}).ToPan(mono, PanBipolar)
+
+	feed(t, m, wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 10, Value: 127})
+	if pan, _ := mono.GetPan(); pan != 1 {
+		t.Errorf("expected CC value 127 to pan hard right through the dispatcher, got %.2f", pan)
+	}
+}
+
+// TestLearnCapturesNextEvent checks Learn against a Feeder, with no live
+// Controller/hardware involved.
+func TestLearnCapturesNextEvent(t *testing.T) {
+	feeder := wiremidi.NewFeeder(1)
+	feeder.Send(wiremidi.Event{Type: wiremidi.EventCC, Channel: 2, Controller: 74})
+
+	source, err := Learn(feeder.Events(), time.Second)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	cc, ok := source.(CC)
+	if !ok || cc.Channel != 2 || cc.Controller != 74 {
+		t.Errorf("expected CC{2,74}, got %#v", source)
+	}
+}
+
+// TestLearnTimesOut checks Learn's timeout path with no event ever sent.
+func TestLearnTimesOut(t *testing.T) {
+	feeder := wiremidi.NewFeeder(1)
+	if _, err := Learn(feeder.Events(), 10*time.Millisecond); err != ErrLearnTimeout {
+		t.Errorf("expected ErrLearnTimeout, got %v", err)
+	}
+}
+
+// stubResolver resolves a single named channel/master/bus for
+// LoadBindings's tests.
+type stubResolver struct {
+	channels map[string]channel.Channel
+	master   *channel.MasterBus
+}
+
+func (r stubResolver) Channel(name string) (channel.Channel, bool) { ch, ok := r.channels[name]; return ch, ok }
+func (r stubResolver) Master() (*channel.MasterBus, bool)          { return r.master, r.master != nil }
+func (r stubResolver) Bus(name string) (*channel.Bus, bool)        { return nil, false }
+
+// TestSaveAndLoadBindingsRoundTrip checks that a Mapper's bindings survive a
+// JSON round-trip and still drive the same channel once reloaded.
+func TestSaveAndLoadBindingsRoundTrip(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: "lead", Engine: eng})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+
+	saved := NewMapper()
+	saved.Bind(CC{Channel: 0, Controller: 10}).ToPan(mono, PanBipolar)
+
+	path := t.TempDir() + "/bindings.json"
+	if err := saved.SaveBindings(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	defer os.Remove(path)
+
+	loaded := NewMapper()
+	resolver := stubResolver{channels: map[string]channel.Channel{"lead": mono}}
+	if err := loaded.LoadBindings(path, resolver); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	feed(t, loaded, wiremidi.Event{Type: wiremidi.EventCC, Channel: 0, Controller: 10, Value: 127})
+	if pan, _ := mono.GetPan(); pan != 1 {
+		t.Errorf("expected the reloaded binding to still pan hard right, got %.2f", pan)
+	}
+}
@@ -0,0 +1,61 @@
+package midi
+
+import (
+	"math"
+
+	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/engine/channel"
+)
+
+// FaderCurve re-exports avaudio/node.FaderCurve, so ToVolumeTapered's
+// console-style fader-travel taper goes through the same breakpoint tables
+// (node.IECFaderCurve, node.ControlSurfaceFaderCurve) the rest of this
+// tree's fader math already uses, rather than this package inventing its
+// own.
+type FaderCurve = node.FaderCurve
+
+// ControlSurfaceFaderCurve re-exports node.ControlSurfaceFaderCurve: unity
+// (0dB) at 75% of fader travel - raw CC 0x60 of a 7-bit 0-127 range - with
+// +6dB of headroom above it, the Mackie Control Universal convention the
+// request's "configurable 0dB anchor point around 0x60" describes. Pass a
+// different node.FaderCurve to ToVolumeTapered for hardware whose unity
+// point sits somewhere else.
+var ControlSurfaceFaderCurve = node.ControlSurfaceFaderCurve
+
+// faderFloorDB is the dB value dbToGain treats as silence, matching
+// node.MixerSilenceDB - duplicated as a plain float32 here rather than
+// importing it, since node's fader math otherwise only operates on a live
+// mixer node pointer and this package only needs the floor constant.
+const faderFloorDB = node.MixerSilenceDB
+
+// dbToGain converts a dB level to the linear gain Channel.SetVolume
+// expects, flooring at faderFloorDB instead of going to 0 exactly at
+// -infinity - the same floor/ceiling behavior node.dbToLinearGain uses
+// internally for SetMixerVolumeDB.
+func dbToGain(db float32) float32 {
+	if db <= faderFloorDB {
+		return 0
+	}
+	gain := float32(math.Pow(10, float64(db)/20))
+	if gain > 1 {
+		return 1
+	}
+	return gain
+}
+
+// ToVolumeTapered binds a continuous source to ch's SetVolume through a
+// console-style node.FaderCurve fader-travel taper (raw/maxRaw treated as
+// fader position 0..1, mapped to dB, then to linear gain) rather than
+// ToVolume's flat Curve-shaped 0..1 scaling. Use this for a control surface
+// whose physical fader throw is itself already console-tapered (a Mackie
+// Control Universal and similar X32-style surfaces), so its raw mid-travel
+// value (around 0x60) lands on unity gain instead of ~75% of it.
+func (b *Binding) ToVolumeTapered(ch channel.Channel, curve FaderCurve) *Binding {
+	entry := b.entry("volume-tapered", ch.GetName(), 0, 1, CurveLinear)
+	top := float64((1 << b.source.bits()) - 1)
+	b.installCC(entry, func(raw int) error {
+		pos := float32(float64(raw) / top)
+		return ch.SetVolume(dbToGain(curve.DBAtPosition(pos)))
+	})
+	return b
+}
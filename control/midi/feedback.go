@@ -0,0 +1,112 @@
+package midi
+
+import (
+	"sync"
+
+	wiremidi "github.com/shaban/macaudio/avaudio/midi"
+)
+
+// Feedback sends a bound CC source's current value back out as a CC
+// message, the engine/channel-stack counterpart to midimap.Feedback: so a
+// motorized fader or LED ring tracks a value that changed elsewhere
+// (automation, another control surface, a scene recall) instead of only
+// reflecting its own last touch. Poll drives this off periodic sampling;
+// Notify does the same thing immediately, for a caller that already knows
+// a value changed rather than waiting for the next poll.
+//
+// Only CC sources get feedback - Note/PitchBend targets (mute toggles,
+// solo, pitch wheels) aren't motorized-fader hardware's concern, the same
+// CC-only scope midimap.Feedback already established.
+type Feedback struct {
+	output *wiremidi.OutputController
+
+	mu       sync.Mutex
+	bindings map[sourceKey]*feedbackTarget
+}
+
+type feedbackTarget struct {
+	channel, controller int
+	min, max            float32
+	get                 func() (float32, error)
+	last                float32
+	hasLast             bool
+}
+
+// NewFeedback creates a Feedback that sends CC updates to output for
+// sources registered with Watch.
+func NewFeedback(output *wiremidi.OutputController) *Feedback {
+	return &Feedback{output: output, bindings: make(map[sourceKey]*feedbackTarget)}
+}
+
+// Watch registers source (normally the same CC a Mapper binding was
+// installed against) so Poll/Notify send get's current value back out,
+// scaled into [min,max] the same way the binding itself was installed with
+// - pass the same min/max a ToVolume/ToPan/ToSendLevel/... call used.
+func (f *Feedback) Watch(source CC, min, max float32, get func() (float32, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bindings[source.key()] = &feedbackTarget{
+		channel: source.Channel, controller: source.Controller,
+		min: min, max: max, get: get,
+	}
+}
+
+// Poll samples every watched source and sends a CC update for any whose
+// value changed since the last Poll or Notify.
+func (f *Feedback) Poll() {
+	f.mu.Lock()
+	targets := make([]*feedbackTarget, 0, len(f.bindings))
+	for _, t := range f.bindings {
+		targets = append(targets, t)
+	}
+	f.mu.Unlock()
+
+	for _, t := range targets {
+		value, err := t.get()
+		if err != nil {
+			continue
+		}
+		f.mu.Lock()
+		changed := !t.hasLast || t.last != value
+		if changed {
+			t.last, t.hasLast = value, true
+		}
+		f.mu.Unlock()
+		if changed {
+			f.send(t, value)
+		}
+	}
+}
+
+// Notify sends an immediate CC update for the (channel, controller) pair,
+// for a caller that already knows a value changed rather than waiting for
+// the next Poll.
+func (f *Feedback) Notify(channel, controller int, value float32) {
+	f.mu.Lock()
+	t, ok := f.bindings[(CC{Channel: channel, Controller: controller}).key()]
+	if ok {
+		t.last, t.hasLast = value, true
+	}
+	f.mu.Unlock()
+	if !ok {
+		return
+	}
+	f.send(t, value)
+}
+
+// send scales value into t's [min,max] range and writes it out as a flat
+// (uncurved) CC - a curve mismatch only affects fader travel feel, not
+// correctness, the same tolerance midimap.Feedback's own unscaleCC takes.
+func (f *Feedback) send(t *feedbackTarget, value float32) {
+	min, max := t.min, t.max
+	if min == max {
+		max = min + 1
+	}
+	raw := int((value - min) / (max - min) * 127)
+	if raw < 0 {
+		raw = 0
+	} else if raw > 127 {
+		raw = 127
+	}
+	_ = f.output.SendCC(t.channel, t.controller, raw)
+}
@@ -0,0 +1,181 @@
+package midi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shaban/macaudio/engine/channel"
+)
+
+// ErrLearnTimeout is returned by Learn when no matching MIDI message arrives
+// within the given timeout.
+var ErrLearnTimeout = errors.New("midi: learn timed out waiting for a MIDI message")
+
+// SavedBinding is one Binding's serializable counterpart - the JSON form
+// SaveBindings writes and LoadBindings reads back, mirroring the shape of
+// the root package's MidiBinding (this package's equivalent for the
+// engine/channel stack rather than macaudio.Channel).
+type SavedBinding struct {
+	Kind       string  `json:"kind"` // "cc", "ccpair", "note", or "pitchbend" - see Source
+	// Parameter identifies which To* method installed this binding:
+	// "volume", "volume-tapered", "pan", "mute-toggle", "mute-momentary",
+	// "master-volume", "send:<input>" (ToBusSend), "send-name:<name>"
+	// (ToSendLevel), "send-mute:<name>" (ToSendMute), "solo-toggle", or
+	// "solo-momentary".
+	Parameter  string  `json:"parameter"`
+	Target     string  `json:"target"` // the channel/bus name ToVolume/ToPan/... was called with, or "master"
+	Channel    int     `json:"channel"`
+	Controller int     `json:"controller,omitempty"`
+	LSB        int     `json:"lsb,omitempty"`
+	Note       int     `json:"note,omitempty"`
+	Min        float32 `json:"min,omitempty"`
+	Max        float32 `json:"max,omitempty"`
+	Curve      Curve   `json:"curve,omitempty"`
+}
+
+// source reconstructs the Source this entry was bound from.
+func (e SavedBinding) source() Source {
+	switch e.Kind {
+	case "note":
+		return Note{Channel: e.Channel, Note: e.Note}
+	case "ccpair":
+		return CCPair{Channel: e.Channel, MSB: e.Controller, LSB: e.LSB}
+	case "pitchbend":
+		return PitchBend{Channel: e.Channel}
+	default:
+		return CC{Channel: e.Channel, Controller: e.Controller}
+	}
+}
+
+// Bindings returns every binding currently registered, in the form
+// SaveBindings persists - for inspecting a live Mapper's mapping table
+// without going through a file round-trip.
+func (m *Mapper) Bindings() []SavedBinding {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]SavedBinding, 0, len(m.bindings))
+	for _, b := range m.bindings {
+		out = append(out, b.entry)
+	}
+	return out
+}
+
+// SaveBindings writes every currently registered binding to path as JSON.
+func (m *Mapper) SaveBindings(path string) error {
+	data, err := json.MarshalIndent(m.Bindings(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("midi: marshal bindings: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Resolver looks a saved binding's Target name up into the live object
+// LoadBindings should bind it to - a channel.Channel for "volume"/"pan"/
+// "mute-toggle"/"mute-momentary", the registered channel.MasterBus for
+// "master-volume", or a channel.Bus for "send:<input>". Returning ok=false
+// for a name LoadBindings can't resolve drops that one entry (logged via
+// the returned error) rather than failing the whole load - the same
+// tolerance Surface.Registry's OnChange callers already expect from a
+// config that can reference a channel not currently live.
+type Resolver interface {
+	Channel(name string) (channel.Channel, bool)
+	Master() (*channel.MasterBus, bool)
+	Bus(name string) (*channel.Bus, bool)
+}
+
+// LoadBindings reads path (as written by SaveBindings) and re-installs each
+// entry via resolve, the same way midimap.Mapper.ApplyBindings reinstalls a
+// macaudio.Channel's saved MidiBindings after a scene load.
+func (m *Mapper) LoadBindings(path string, resolve Resolver) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("midi: read bindings: %w", err)
+	}
+	var entries []SavedBinding
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("midi: unmarshal bindings: %w", err)
+	}
+
+	for _, e := range entries {
+		b := m.Bind(e.source())
+		switch {
+		case e.Parameter == "master-volume":
+			mb, ok := resolve.Master()
+			if !ok {
+				return fmt.Errorf("midi: no master bus registered to bind %q to", e.Parameter)
+			}
+			b.ToMasterVolume(mb, e.Curve)
+		case e.Parameter == "pan":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for pan binding", e.Target)
+			}
+			panCurve := PanBipolar
+			if e.Min == 0 {
+				panCurve = PanUnipolar
+			}
+			b.ToPan(ch, panCurve)
+		case e.Parameter == "volume":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for volume binding", e.Target)
+			}
+			b.ToVolume(ch, e.Curve)
+		case e.Parameter == "mute-toggle":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for mute binding", e.Target)
+			}
+			b.ToMuteToggle(ch)
+		case e.Parameter == "mute-momentary":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for mute binding", e.Target)
+			}
+			b.ToMuteMomentary(ch)
+		case len(e.Parameter) > 5 && e.Parameter[:5] == "send:":
+			bus, ok := resolve.Bus(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown bus %q for send binding", e.Target)
+			}
+			var input int
+			fmt.Sscanf(e.Parameter[5:], "%d", &input)
+			b.ToBusSend(bus, input, e.Curve)
+		case e.Parameter == "volume-tapered":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for tapered volume binding", e.Target)
+			}
+			b.ToVolumeTapered(ch, ControlSurfaceFaderCurve)
+		case len(e.Parameter) > 10 && e.Parameter[:10] == "send-name:":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for named send binding", e.Target)
+			}
+			b.ToSendLevel(ch, e.Parameter[10:], e.Curve)
+		case len(e.Parameter) > 10 && e.Parameter[:10] == "send-mute:":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for named send mute binding", e.Target)
+			}
+			b.ToSendMute(ch, e.Parameter[10:])
+		case e.Parameter == "solo-toggle":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for solo binding", e.Target)
+			}
+			b.ToSoloToggle(ch)
+		case e.Parameter == "solo-momentary":
+			ch, ok := resolve.Channel(e.Target)
+			if !ok {
+				return fmt.Errorf("midi: unknown channel %q for solo binding", e.Target)
+			}
+			b.ToSoloMomentary(ch)
+		default:
+			return fmt.Errorf("midi: unknown saved parameter %q", e.Parameter)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package osc
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+)
+
+// Client is a minimal bidirectional OSC client over UDP: send a control
+// message, or send a "?" query and wait for the reply, the same way an
+// external control surface talking to a Surface would. It exists mainly
+// for this package's own tests, in place of hand-rolling Marshal/Write/Read
+// in every test file (see surface_test.go's older sendMsg helper).
+type Client struct {
+	conn net.Conn
+}
+
+// Dial connects to a Surface listening at addr (a UDPTransport's
+// LocalAddr().String()).
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("osc: dial %s: %w", addr, err)
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Send marshals and writes an OSC message with the given address and args.
+func (c *Client) Send(address string, args ...interface{}) error {
+	data, err := wireosc.Message{Address: address, Args: args}.Marshal()
+	if err != nil {
+		return fmt.Errorf("osc: marshal %s: %w", address, err)
+	}
+	_, err = c.conn.Write(data)
+	return err
+}
+
+// Query sends address+"?" and waits up to timeout for the reply.
+func (c *Client) Query(address string, timeout time.Duration) (wireosc.Message, error) {
+	if err := c.Send(address + "?"); err != nil {
+		return wireosc.Message{}, err
+	}
+	return c.Receive(timeout)
+}
+
+// Subscribe sends a /subscribe request for namespace, matching
+// Surface.handleSubscribe.
+func (c *Client) Subscribe(namespace string) error {
+	return c.Send("/subscribe", namespace)
+}
+
+// Unsubscribe sends an /unsubscribe request for namespace.
+func (c *Client) Unsubscribe(namespace string) error {
+	return c.Send("/unsubscribe", namespace)
+}
+
+// Receive blocks for up to timeout for the next inbound message, whether a
+// query reply or a change notification.
+func (c *Client) Receive(timeout time.Duration) (wireosc.Message, error) {
+	buf := make([]byte, 1024)
+	if err := c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return wireosc.Message{}, err
+	}
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		return wireosc.Message{}, err
+	}
+	return wireosc.Unmarshal(buf[:n])
+}
+
+// Close closes the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,57 @@
+package osc
+
+import "math"
+
+// faderMinDB is the dB floor a tapered fader position of 0.0 maps to - the
+// same -60dB floor avaudio/engine/osc.positionToGain uses for the legacy
+// macaudio.Engine surface's /ch/<id>/fader taper.
+const faderMinDB = -60.0
+
+// PositionToGain converts a console-style fader position (0.0 at the
+// bottom of the fader's throw, 1.0 at the top, tapered logarithmically so
+// unity gain sits at 1.0 and faderMinDB sits at 0.0) into the linear
+// 0.0-1.0 gain Channel.SetVolume expects. This package's own /ch/<name>/
+// fader address takes a raw linear gain, not a tapered position, so an
+// X32-style surface whose fader throw is naturally tapered should run its
+// position through this before calling SetChFader or sending a /fader
+// message - the same conversion avaudio/engine/osc's unexported
+// positionToGain does inline for its own surface.
+func PositionToGain(position float32) float32 {
+	if position <= 0 {
+		return 0
+	}
+	if position >= 1 {
+		return 1
+	}
+	db := float64(position)*(-faderMinDB) + faderMinDB
+	gain := math.Pow(10, db/20)
+	if gain < 0 {
+		return 0
+	}
+	if gain > 1 {
+		return 1
+	}
+	return float32(gain)
+}
+
+// GainToPosition is PositionToGain's inverse: it converts a linear 0.0-1.0
+// gain back into a tapered 0.0-1.0 fader position, for reflecting a
+// Go-side volume change onto a physical motorized fader or a UI slider
+// that expects tapered positions rather than raw gain.
+func GainToPosition(gain float32) float32 {
+	if gain <= 0 {
+		return 0
+	}
+	if gain >= 1 {
+		return 1
+	}
+	db := 20 * math.Log10(float64(gain))
+	position := (db - faderMinDB) / (-faderMinDB)
+	if position < 0 {
+		return 0
+	}
+	if position > 1 {
+		return 1
+	}
+	return float32(position)
+}
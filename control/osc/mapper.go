@@ -0,0 +1,36 @@
+package osc
+
+// AddressMapper translates between the canonical addresses this package
+// dispatches on (/ch/<name>/..., /bus/<name>/..., /master/..., /solo/<name>)
+// and whatever literal OSC address a particular control surface actually
+// sends and expects. Most consoles (an X32, a custom touch UI) have their
+// own fixed scheme that doesn't line up with these names 1:1 - a fader
+// might arrive as "/track/3/fader" rather than "/ch/kick/fader" - so a
+// Surface asks its mapper to translate an incoming address before
+// dispatch, and to translate a canonical address back before sending a
+// query reply or change notification.
+//
+// AddressMapper only remaps the literal text of an address; it has no say
+// in whether the thing it names is registered, so FromWire can return any
+// canonical address it likes without needing to know the Surface's
+// Registry contents up front.
+type AddressMapper interface {
+	// FromWire converts an incoming wire address into this package's
+	// canonical form. ok is false if addr doesn't correspond to anything
+	// the mapper recognizes - handled the same as any other unknown
+	// address (silently ignored).
+	FromWire(addr string) (address string, ok bool)
+
+	// ToWire converts a canonical address into the literal one to send
+	// back out, for a query reply or a change notification.
+	ToWire(address string) string
+}
+
+// identityMapper is the AddressMapper every Surface starts with: wire
+// addresses are exactly this package's canonical ones, so FromWire/ToWire
+// are no-ops. SetAddressMapper replaces it for a console with its own
+// scheme.
+type identityMapper struct{}
+
+func (identityMapper) FromWire(addr string) (string, bool) { return addr, true }
+func (identityMapper) ToWire(address string) string        { return address }
@@ -0,0 +1,39 @@
+package osc
+
+import (
+	"github.com/shaban/macaudio/avaudio/midi"
+)
+
+// ccVolume/ccPan are the MMA-assigned default controller numbers for
+// channel volume and pan, the same pair most mixer-control surfaces
+// (including dolmetschctl) treat as the baseline mapping before any
+// MIDI-learn customization.
+const (
+	ccVolume = 7
+	ccPan    = 10
+)
+
+// BindChannelCC wires CC 7 (volume) and CC 10 (pan) on midiChannel to
+// channelID's fader/pan through Surface's bridge methods, so a hardware
+// controller plugged into ctrl and an OSC client both converge on the same
+// state and the same /channel/<id> change notifications. Intended to be
+// called right after engine.Engine.CreateMIDIInputChannel, with channelID
+// the name the caller also passed to RegisterChannel.
+func (s *Surface) BindChannelCC(ctrl *midi.Controller, midiChannel int, channelID string) {
+	ctrl.BindCC(midi.CCBinding{
+		Channel:    midiChannel,
+		Controller: ccVolume,
+		Min:        0,
+		Max:        1,
+		Curve:      midi.CurveLinear,
+		Set:        func(v float32) error { return s.SetChannelFader(channelID, v) },
+	})
+	ctrl.BindCC(midi.CCBinding{
+		Channel:    midiChannel,
+		Controller: ccPan,
+		Min:        -1,
+		Max:        1,
+		Curve:      midi.CurveLinear,
+		Set:        func(v float32) error { return s.SetChannelPan(channelID, v) },
+	})
+}
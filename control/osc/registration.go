@@ -0,0 +1,17 @@
+package osc
+
+import "github.com/shaban/macaudio/engine/channel"
+
+// RegisterChannels registers every entry of channels into r under its map
+// key, for bringing up a full /ch/<name>/... address space in one call
+// instead of one Register per channel. Nothing in the engine/channel stack
+// enumerates "all channels belonging to an engine" on its own - the same
+// gap graph.State and scenes.Store work around by having the caller pass
+// channels in explicitly - so the map here is whatever set of live
+// channels the caller (who built them) already has on hand, not something
+// discovered from an *avaudio/engine.Engine.
+func RegisterChannels(r *Registry, channels map[string]channel.Channel) {
+	for name, ch := range channels {
+		r.Register(name, ch)
+	}
+}
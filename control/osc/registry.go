@@ -0,0 +1,75 @@
+package osc
+
+import (
+	"sync"
+
+	"github.com/shaban/macaudio/engine/channel"
+)
+
+// Registry holds channel.Channel values under caller-assigned string names
+// (e.g. "aux/1", mirroring the auxin/NN naming convention hardware control
+// surfaces use), decoupling a Surface's /ch/<name>/... addresses from any
+// one *channel.Channel instance. A caller that destroys and recreates a
+// channel under the same name just calls Register again - the OSC
+// namespace, and anything subscribed to it, doesn't need to know.
+type Registry struct {
+	mu       sync.RWMutex
+	channels map[string]channel.Channel
+	onChange []func(name string)
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		channels: make(map[string]channel.Channel),
+	}
+}
+
+// Register makes ch addressable under name, replacing whatever was
+// previously registered there.
+func (r *Registry) Register(name string, ch channel.Channel) {
+	r.mu.Lock()
+	r.channels[name] = ch
+	r.mu.Unlock()
+	r.fireChange(name)
+}
+
+// Unregister removes name, if present, so /ch/<name>/... addresses for it
+// stop resolving until something registers it again.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	_, existed := r.channels[name]
+	delete(r.channels, name)
+	r.mu.Unlock()
+	if existed {
+		r.fireChange(name)
+	}
+}
+
+// Get returns the channel currently registered under name, if any.
+func (r *Registry) Get(name string) (channel.Channel, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ch, ok := r.channels[name]
+	return ch, ok
+}
+
+// OnChange registers fn to run, with the affected name, whenever Register
+// or Unregister changes what that name points to. Surface uses this to
+// invalidate any state it might otherwise cache per name; it's not called
+// for ordinary value changes (a pan move, a mute) on an already-registered
+// channel, only for registration changes themselves.
+func (r *Registry) OnChange(fn func(name string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = append(r.onChange, fn)
+}
+
+func (r *Registry) fireChange(name string) {
+	r.mu.RLock()
+	fns := append([]func(string){}, r.onChange...)
+	r.mu.RUnlock()
+	for _, fn := range fns {
+		fn(name)
+	}
+}
@@ -0,0 +1,1315 @@
+// Package osc exposes engine/channel Buses, MasterBuses, and legacy
+// engine.Channel volume/pan/mute state as Open Sound Control endpoints, for
+// control surfaces (TouchOSC, QLab, a custom mixer UI) that can't use the
+// CGO bindings directly - the same role avaudio/osc.Surface plays for
+// SourceNodes and the root osc package plays for macaudio.Engine, but
+// scoped to the engine/channel mixing layer and the legacy engine package's
+// per-channel fader/pan/mute.
+//
+//	/bus/<name>/input/<idx>/fader   f   -> Bus.SetInputLevel
+//	/bus/<name>/input/<idx>/pan     f   -> Bus.SetInputPan
+//	/bus/<name>/input/<idx>/mute    i   -> Bus.SetInputMute
+//	/bus/<name>/master/fader        f   -> Bus.SetLevel / MasterBus.SetLevel
+//	/bus/<name>/master/mute         i   -> Bus.SetMute / MasterBus.SetMute
+//	/channel/<id>/fader             f   -> engine.Channel.SetVolume
+//	/channel/<id>/pan               f   -> engine.Channel.SetPan
+//	/channel/<id>/mute              i   -> engine.Channel.Mute/Unmute
+//	/master/volume                  f   -> MasterBus.SetLevel (see RegisterMaster)
+//	/master/mute                    i   -> MasterBus.SetMute
+//	/ch/<name>/pan                  f   -> channel.Channel.SetPan (see Registry)
+//	/ch/<name>/fader                f   -> channel.Channel.SetVolume
+//	/ch/<name>/mute                 i   -> channel.Channel.SetMute
+//	/ch/<name>/connect              i   -> BaseChannel.ConnectToMaster/DisconnectFromMaster (see SetEngine)
+//	/ch/<name>/send/<sendName>/level f  -> BaseChannel.SetSendLevel
+//	/ch/<name>/send/<sendName>/create ssf -> BaseChannel.CreateAndConnectSend (destName, busName, level)
+//	/ch/<name>/send/<sendName>/remove      -> BaseChannel.RemoveSend
+//	/ch/<name>/aux/level            f   -> BaseChannel.SetAuxSendLevel
+//	/ch/<name>/effect/<idx>/bypass  i   -> PluginChain.SetEffectBypass
+//	/ch/<name>/meter/enable         i   -> BaseChannel.EnableOutputMetering
+//	/ch/<name>/meter                f   -- periodic push of BaseChannel.OutputRMS() for a
+//	                                        meter-enabled channel (see SetChMeterEnable/broadcastMeters);
+//	                                        not itself settable from a client
+//	/solo/<name>                    i   -> BaseChannel.SetSolo (engine/channel's DefaultSolo manager)
+//	/subscribe  s                       -- registers the sender for change notifications under the given
+//	                                        namespace (e.g. "/bus/main"); the subscription expires after
+//	                                        subscriptionTimeout unless renewed by another /subscribe or a
+//	                                        /heartbeat (see sweepSubscribers), the same keepalive convention
+//	                                        the repository root's osc_server.go uses
+//	/heartbeat                          -- renews every namespace addr has subscribed to, without
+//	                                        re-sending /subscribe's own registration; a no-op for an addr
+//	                                        that isn't subscribed to anything
+//	/unsubscribe s
+//
+// The send, aux, effect, meter, and solo addresses above only work for a
+// registered channel that actually implements them (BaseChannel and
+// anything embedding it, like MonoToStereoChannel) - channel.Bus and
+// channel.MasterBus have no named sends, no single aux send, no output
+// meter tap, and no solo concept of their own, so a Surface resolves those
+// by type-asserting the registered channel.Channel rather than requiring
+// them on the interface.
+//
+// A Surface's address space is fixed by this package by default, but a
+// console with its own literal scheme can supply an AddressMapper (see
+// SetAddressMapper) to translate incoming wire addresses into the
+// canonical ones above, and canonical addresses back into wire form for
+// query replies and notifications.
+//
+// Any settable address above also accepts a query variant with "?"
+// appended and no args (e.g. "/ch/foo/pan?"): instead of applying a change
+// and notifying subscribers, Surface replies to the sender alone with the
+// address's current value.
+//
+// /ch/<name>/... differs from /channel/<id>/... in what it addresses: the
+// latter is the fixed-size legacy engine.Channel array, the former is
+// whatever's currently registered under name in the Surface's Registry -
+// any channel.Channel, including engine/channel/input.MonoToStereoChannel,
+// addressable under a name that outlives any one instance (see Registry).
+//
+// Every address a client can set also has a Go-side method of the same
+// shape on Surface (SetBusInputFader, SetChannelFader, ...): applying a
+// change through one of those methods, whether it was triggered by an
+// incoming OSC message or driven from Go (automation, a preset load, a
+// bound MIDI CC - see BindChannelCC), pushes the same notification to
+// every subscriber of that namespace. That's the "bidirectional bridge" -
+// an external control surface and Go-side code always see the same state
+// changes, regardless of which side made them.
+//
+// Unknown addresses are ignored, consistent with every other Surface in
+// this codebase.
+package osc
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/engine"
+	"github.com/shaban/macaudio/engine/channel"
+
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+)
+
+// masterConnectable is satisfied by any channel.Channel that also supports
+// connecting/disconnecting its output from the engine's main mixer -
+// BaseChannel implements this (and so does anything embedding it, like
+// MonoToStereoChannel); channel.Bus/MasterBus don't, since a bus's routing
+// is fixed at construction rather than toggled at runtime.
+type masterConnectable interface {
+	ConnectToMaster(eng *avengine.Engine) error
+	DisconnectFromMaster(eng *avengine.Engine) error
+	IsConnectedToMaster() bool
+}
+
+// bus is the subset of channel.Bus and channel.MasterBus's methods that
+// apply to the bus as a whole, i.e. its own "master" fader - as distinct
+// from any one input (see inputBus).
+type bus interface {
+	SetLevel(float32) error
+	GetLevel() (float32, error)
+	SetMute(bool) error
+	GetMute() bool
+}
+
+// inputBus additionally supports per-input addressing; channel.Bus
+// implements this, channel.MasterBus does not (the engine's main mixer has
+// no notion of numbered inputs the way a dedicated Bus mixer does).
+type inputBus interface {
+	bus
+	SetInputLevel(int, float32) error
+	GetInputLevel(int) (float32, error)
+	SetInputPan(int, float32) error
+	GetInputPan(int) (float32, error)
+	SetInputMute(int, bool) error
+	GetInputMute(int) bool
+}
+
+// sendable is satisfied by any channel.Channel that also has named aux-bus
+// sends - BaseChannel (and anything embedding it) does; channel.Bus/
+// MasterBus don't.
+type sendable interface {
+	SetSendLevel(sendName string, level float32) error
+	GetSendLevel(sendName string) (float32, error)
+}
+
+// auxSendable is satisfied by any channel.Channel with BaseChannel's single
+// unnamed aux send, as distinct from the named sends in sendable.
+type auxSendable interface {
+	SetAuxSendLevel(level float32) error
+}
+
+// soloable is satisfied by any channel.Channel that participates in
+// engine/channel's package-wide solo manager - BaseChannel (and anything
+// embedding it) does, via SetSolo/IsSoloed; channel.Bus/MasterBus don't.
+type soloable interface {
+	SetSolo(bool)
+	IsSoloed() bool
+}
+
+// sendCreatable is satisfied by any channel.Channel that can create and
+// later remove a named send dynamically - BaseChannel (and anything
+// embedding it) does; channel.Bus/MasterBus don't. It's distinct from
+// sendable (which only adjusts a send already created some other way,
+// e.g. at scene-load time).
+type sendCreatable interface {
+	CreateAndConnectSend(name string, dest channel.Channel, bus *channel.Bus, level float32, mode channel.SendMode) (int, error)
+	RemoveSend(eng *avengine.Engine, sendName string) error
+}
+
+// meterable is satisfied by any channel.Channel with an output RMS meter
+// tap - BaseChannel (and anything embedding it) does; channel.Bus/MasterBus
+// don't.
+type meterable interface {
+	EnableOutputMetering(eng *avengine.Engine, enable bool) error
+	OutputRMS() (float64, error)
+}
+
+// Surface routes incoming OSC messages to registered Buses/MasterBuses and
+// engine.Channels, and pushes change notifications to subscribers.
+type Surface struct {
+	transport wireosc.Transport
+
+	mu       sync.RWMutex
+	buses    map[string]bus
+	channels map[string]*engine.Channel
+	master   bus // see RegisterMaster; nil until a MasterBus is registered
+	engine   *avengine.Engine
+	registry *Registry
+	mapper   AddressMapper
+
+	subMu       sync.Mutex
+	subs        map[string]map[string]net.Addr // namespace -> addr key -> addr
+	subLastSeen map[string]time.Time           // addr key -> last /subscribe or /heartbeat, for sweepSubscribers
+	sweepStop   chan struct{}
+
+	meterMu      sync.Mutex
+	meterEnabled map[string]bool // channel name -> output metering currently enabled via SetChMeterEnable
+	meterRate    time.Duration
+	meterStop    chan struct{}
+
+	closeOnce sync.Once
+}
+
+// subscriptionTimeout is how long a subscriber may go without sending
+// /subscribe or /heartbeat before sweepSubscribers drops it, and
+// heartbeatSweepInterval is how often sweepSubscribers checks for one that
+// has - the same register-then-keepalive pair osc_server.go uses at the
+// repository root, scoped here to this package's own /subscribe registry.
+const (
+	subscriptionTimeout    = 30 * time.Second
+	heartbeatSweepInterval = 10 * time.Second
+)
+
+// DefaultMeterRate is the /ch/<name>/meter push rate a Surface starts with;
+// see SetMeterRate.
+const DefaultMeterRate = 100 * time.Millisecond
+
+// NewSurface creates a Surface serving over transport, with its own empty
+// Registry ready for RegisterMaster/SetEngine/Registry().Register calls,
+// and the identity AddressMapper (see SetAddressMapper). It immediately
+// starts sweepSubscribers (dropping a subscriber that stops renewing via
+// /subscribe or /heartbeat) and broadcastMeters (pushing /ch/<name>/meter
+// for every SetChMeterEnable-d channel at DefaultMeterRate) in the
+// background; both stop when Close is called.
+func NewSurface(transport wireosc.Transport) *Surface {
+	s := &Surface{
+		transport:    transport,
+		buses:        make(map[string]bus),
+		channels:     make(map[string]*engine.Channel),
+		registry:     NewRegistry(),
+		mapper:       identityMapper{},
+		subs:         make(map[string]map[string]net.Addr),
+		subLastSeen:  make(map[string]time.Time),
+		sweepStop:    make(chan struct{}),
+		meterEnabled: make(map[string]bool),
+		meterRate:    DefaultMeterRate,
+		meterStop:    make(chan struct{}),
+	}
+	go s.sweepSubscribers()
+	go s.broadcastMeters()
+	return s
+}
+
+// SetAddressMapper installs m as the translator between wire addresses and
+// this package's canonical ones, replacing the identity mapper every
+// Surface starts with. Pass nil to go back to the identity mapper.
+func (s *Surface) SetAddressMapper(m AddressMapper) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m == nil {
+		m = identityMapper{}
+	}
+	s.mapper = m
+}
+
+// RegisterBus makes b addressable as /bus/<name>/... , including its
+// per-input addresses.
+func (s *Surface) RegisterBus(name string, b *channel.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buses[name] = b
+}
+
+// RegisterMasterBus makes mb addressable as /bus/<name>/master/... ; since
+// MasterBus has no per-input concept, /bus/<name>/input/... addresses for
+// this name are ignored.
+func (s *Surface) RegisterMasterBus(name string, mb *channel.MasterBus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buses[name] = mb
+}
+
+// RegisterChannel makes ch addressable as /channel/<id>/... .
+func (s *Surface) RegisterChannel(id string, ch *engine.Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[id] = ch
+}
+
+// RegisterMaster makes mb addressable as /master/volume and /master/mute -
+// the engine-wide counterpart RegisterMasterBus's per-bus /bus/<name>/master
+// addresses don't cover, since the request for this is usually "turn the
+// whole mix down," not "turn this one bus down."
+func (s *Surface) RegisterMaster(mb *channel.MasterBus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.master = mb
+}
+
+// SetEngine records the low-level engine /ch/<name>/connect uses to call
+// ConnectToMaster/DisconnectFromMaster on a registered channel (see
+// masterConnectable). Required before that address does anything; every
+// other /ch/<name>/... address works without it.
+func (s *Surface) SetEngine(eng *avengine.Engine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.engine = eng
+}
+
+// Registry returns the Surface's Registry, so callers can
+// Register/Unregister channel.Channel values under /ch/<name> addresses.
+func (s *Surface) Registry() *Registry {
+	return s.registry
+}
+
+// Serve starts dispatching incoming OSC packets until the transport errors
+// or is closed (Close always triggers this, which is expected and not
+// reported back to the caller as a failure).
+func (s *Surface) Serve() error {
+	return s.transport.Serve(s.handle)
+}
+
+// Close stops the underlying transport, sweepSubscribers, and
+// broadcastMeters. Safe to call more than once; only the first call does
+// anything.
+func (s *Surface) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.sweepStop)
+		close(s.meterStop)
+		err = s.transport.Close()
+	})
+	return err
+}
+
+// SetMeterRate changes how often broadcastMeters pushes /ch/<name>/meter,
+// replacing DefaultMeterRate; broadcastMeters re-reads it before scheduling
+// its next push, so a call here takes effect within one push interval
+// rather than only at the next restart.
+func (s *Surface) SetMeterRate(rate time.Duration) {
+	s.meterMu.Lock()
+	s.meterRate = rate
+	s.meterMu.Unlock()
+}
+
+func (s *Surface) addressMapper() AddressMapper {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mapper
+}
+
+func (s *Surface) lookupBus(name string) (bus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b, ok := s.buses[name]
+	return b, ok
+}
+
+func (s *Surface) lookupInputBus(name string) (inputBus, bool) {
+	b, ok := s.lookupBus(name)
+	if !ok {
+		return nil, false
+	}
+	ib, ok := b.(inputBus)
+	return ib, ok
+}
+
+func (s *Surface) lookupChannel(id string) (*engine.Channel, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ch, ok := s.channels[id]
+	return ch, ok
+}
+
+func (s *Surface) handle(msg wireosc.Message, addr net.Addr) {
+	isQuery := strings.HasSuffix(msg.Address, "?")
+	raw := strings.TrimSuffix(msg.Address, "?")
+
+	mapped, ok := s.addressMapper().FromWire(raw)
+	if !ok {
+		return
+	}
+
+	if isQuery {
+		s.handleQuery(mapped, addr)
+		return
+	}
+	msg.Address = mapped
+
+	switch {
+	case msg.Address == "/subscribe":
+		s.handleSubscribe(msg, addr)
+	case msg.Address == "/unsubscribe":
+		s.handleUnsubscribe(msg, addr)
+	case msg.Address == "/heartbeat":
+		s.handleHeartbeat(addr)
+	case msg.Address == "/master/volume" || msg.Address == "/master/mute":
+		s.handleMaster(msg)
+	case strings.HasPrefix(msg.Address, "/bus/"):
+		s.handleBus(msg)
+	case strings.HasPrefix(msg.Address, "/channel/"):
+		s.handleChannel(msg)
+	case strings.HasPrefix(msg.Address, "/ch/"):
+		s.handleCh(msg)
+	case strings.HasPrefix(msg.Address, "/solo/"):
+		s.handleSolo(msg)
+	}
+}
+
+// handleQuery answers a "<address>?" request (address already translated
+// from wire form by handle) by replying directly to addr (not the
+// namespace's subscribers) with address's current value, leaving the
+// sender's own subscription state, if any, untouched.
+func (s *Surface) handleQuery(address string, addr net.Addr) {
+	reply, ok := s.resolveQuery(address)
+	if !ok {
+		return
+	}
+	reply.Address = s.addressMapper().ToWire(reply.Address)
+	data, err := reply.Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+func (s *Surface) resolveQuery(address string) (wireosc.Message, bool) {
+	if address == "/master/volume" {
+		s.mu.RLock()
+		master := s.master
+		s.mu.RUnlock()
+		if master == nil {
+			return wireosc.Message{}, false
+		}
+		level, err := master.GetLevel()
+		if err != nil {
+			return wireosc.Message{}, false
+		}
+		return wireosc.Message{Address: address, Args: []interface{}{level}}, true
+	}
+
+	if strings.HasPrefix(address, "/solo/") {
+		name := strings.TrimPrefix(address, "/solo/")
+		ch, ok := s.registry.Get(name)
+		if !ok {
+			return wireosc.Message{}, false
+		}
+		soloCh, ok := ch.(soloable)
+		if !ok {
+			return wireosc.Message{}, false
+		}
+		return wireosc.Message{Address: address, Args: []interface{}{boolToInt32(soloCh.IsSoloed())}}, true
+	}
+
+	parts := strings.Split(strings.Trim(address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "ch" {
+		return wireosc.Message{}, false
+	}
+	ch, ok := s.registry.Get(parts[1])
+	if !ok {
+		return wireosc.Message{}, false
+	}
+	switch parts[2] {
+	case "pan":
+		v, err := ch.GetPan()
+		if err != nil {
+			return wireosc.Message{}, false
+		}
+		return wireosc.Message{Address: address, Args: []interface{}{v}}, true
+	case "fader":
+		v, err := ch.GetVolume()
+		if err != nil {
+			return wireosc.Message{}, false
+		}
+		return wireosc.Message{Address: address, Args: []interface{}{v}}, true
+	case "mute":
+		v, err := ch.GetMute()
+		if err != nil {
+			return wireosc.Message{}, false
+		}
+		return wireosc.Message{Address: address, Args: []interface{}{boolToInt32(v)}}, true
+	}
+	return wireosc.Message{}, false
+}
+
+func floatArg(args []interface{}, i int) (float32, bool) {
+	if i >= len(args) {
+		return 0, false
+	}
+	switch v := args[i].(type) {
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	}
+	return 0, false
+}
+
+func intArg(args []interface{}, i int) (int32, bool) {
+	if i >= len(args) {
+		return 0, false
+	}
+	switch v := args[i].(type) {
+	case int32:
+		return v, true
+	case float32:
+		return int32(v), true
+	}
+	return 0, false
+}
+
+func stringArg(args []interface{}, i int) (string, bool) {
+	if i >= len(args) {
+		return "", false
+	}
+	v, ok := args[i].(string)
+	return v, ok
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Surface) handleSubscribe(msg wireosc.Message, addr net.Addr) {
+	namespace, ok := stringArg(msg.Args, 0)
+	if !ok {
+		return
+	}
+	s.subMu.Lock()
+	if s.subs[namespace] == nil {
+		s.subs[namespace] = make(map[string]net.Addr)
+	}
+	s.subs[namespace][addr.String()] = addr
+	s.subLastSeen[addr.String()] = time.Now()
+	s.subMu.Unlock()
+}
+
+func (s *Surface) handleUnsubscribe(msg wireosc.Message, addr net.Addr) {
+	namespace, ok := stringArg(msg.Args, 0)
+	if !ok {
+		return
+	}
+	s.subMu.Lock()
+	delete(s.subs[namespace], addr.String())
+	if !s.subscribedAnywhereLocked(addr.String()) {
+		delete(s.subLastSeen, addr.String())
+	}
+	s.subMu.Unlock()
+}
+
+// handleHeartbeat handles /heartbeat, renewing addr's subscription expiry
+// (see subscriptionTimeout/sweepSubscribers) for every namespace it's
+// currently subscribed to, without re-registering any of them the way
+// /subscribe does - the lightweight keepalive a control surface sends
+// between real commands so a quiet-but-still-connected session isn't swept.
+// A no-op for an addr that isn't subscribed to anything.
+func (s *Surface) handleHeartbeat(addr net.Addr) {
+	s.subMu.Lock()
+	if _, ok := s.subLastSeen[addr.String()]; ok {
+		s.subLastSeen[addr.String()] = time.Now()
+	}
+	s.subMu.Unlock()
+}
+
+// subscribedAnywhereLocked reports whether key is still present in any
+// namespace's subscriber map - callers must hold subMu.
+func (s *Surface) subscribedAnywhereLocked(key string) bool {
+	for _, addrs := range s.subs {
+		if _, ok := addrs[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sweepSubscribers periodically drops any subscriber that hasn't renewed
+// via /subscribe or /heartbeat within subscriptionTimeout, until Close
+// closes sweepStop.
+func (s *Surface) sweepSubscribers() {
+	ticker := time.NewTicker(heartbeatSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.sweepStop:
+			return
+		case <-ticker.C:
+			s.dropExpiredSubscribers()
+		}
+	}
+}
+
+// dropExpiredSubscribers removes every subscriber last seen before
+// subscriptionTimeout ago, from every namespace it was subscribed to.
+func (s *Surface) dropExpiredSubscribers() {
+	cutoff := time.Now().Add(-subscriptionTimeout)
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for key, seen := range s.subLastSeen {
+		if !seen.Before(cutoff) {
+			continue
+		}
+		delete(s.subLastSeen, key)
+		for namespace, addrs := range s.subs {
+			delete(addrs, key)
+			if len(addrs) == 0 {
+				delete(s.subs, namespace)
+			}
+		}
+	}
+}
+
+// broadcastMeters pushes /ch/<name>/meter for every SetChMeterEnable-d
+// channel, re-reading meterRate before each wait so SetMeterRate takes
+// effect on the next push rather than only after a restart, until Close
+// closes meterStop.
+func (s *Surface) broadcastMeters() {
+	for {
+		s.meterMu.Lock()
+		rate := s.meterRate
+		s.meterMu.Unlock()
+
+		timer := time.NewTimer(rate)
+		select {
+		case <-s.meterStop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.pushMeters()
+		}
+	}
+}
+
+// pushMeters sends one /ch/<name>/meter message, carrying OutputRMS(), to
+// /ch/<name>'s subscribers for every channel currently enabled via
+// SetChMeterEnable. A channel that's been unregistered, no longer
+// implements meterable, or returns an error (metering was disabled some
+// other way) is skipped rather than treated as a failure.
+func (s *Surface) pushMeters() {
+	s.meterMu.Lock()
+	names := make([]string, 0, len(s.meterEnabled))
+	for name, on := range s.meterEnabled {
+		if on {
+			names = append(names, name)
+		}
+	}
+	s.meterMu.Unlock()
+
+	for _, name := range names {
+		ch, ok := s.registry.Get(name)
+		if !ok {
+			continue
+		}
+		mc, ok := ch.(meterable)
+		if !ok {
+			continue
+		}
+		rms, err := mc.OutputRMS()
+		if err != nil {
+			continue
+		}
+		s.notify("/ch/"+name, wireosc.Message{
+			Address: fmt.Sprintf("/ch/%s/meter", name),
+			Args:    []interface{}{float32(rms)},
+		})
+	}
+}
+
+// notify sends msg to every subscriber of namespace.
+func (s *Surface) notify(namespace string, msg wireosc.Message) {
+	s.subMu.Lock()
+	addrs := make([]net.Addr, 0, len(s.subs[namespace]))
+	for _, a := range s.subs[namespace] {
+		addrs = append(addrs, a)
+	}
+	s.subMu.Unlock()
+	if len(addrs) == 0 {
+		return
+	}
+
+	msg.Address = s.addressMapper().ToWire(msg.Address)
+	data, err := msg.Marshal()
+	if err != nil {
+		return
+	}
+	for _, addr := range addrs {
+		_ = s.transport.SendTo(addr, data)
+	}
+}
+
+func (s *Surface) handleBus(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) < 4 || parts[0] != "bus" {
+		return
+	}
+	name := parts[1]
+
+	switch {
+	case parts[2] == "input" && len(parts) == 5:
+		input, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return
+		}
+		switch parts[4] {
+		case "fader":
+			v, ok := floatArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetBusInputFader(name, input, v)
+		case "pan":
+			v, ok := floatArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetBusInputPan(name, input, v)
+		case "mute":
+			v, ok := intArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetBusInputMute(name, input, v != 0)
+		}
+	case parts[2] == "master" && len(parts) == 4:
+		switch parts[3] {
+		case "fader":
+			v, ok := floatArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetBusMasterFader(name, v)
+		case "mute":
+			v, ok := intArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetBusMasterMute(name, v != 0)
+		}
+	}
+}
+
+func (s *Surface) handleMaster(msg wireosc.Message) {
+	switch msg.Address {
+	case "/master/volume":
+		v, ok := floatArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetMasterVolume(v)
+	case "/master/mute":
+		v, ok := intArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetMasterMute(v != 0)
+	}
+}
+
+func (s *Surface) handleCh(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) < 3 || parts[0] != "ch" {
+		return
+	}
+	name := parts[1]
+
+	switch {
+	case len(parts) == 3:
+		switch parts[2] {
+		case "pan":
+			v, ok := floatArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetChPan(name, v)
+		case "fader":
+			v, ok := floatArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetChFader(name, v)
+		case "mute":
+			v, ok := intArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetChMute(name, v != 0)
+		case "connect":
+			v, ok := intArg(msg.Args, 0)
+			if !ok {
+				return
+			}
+			_ = s.SetChConnected(name, v != 0)
+		}
+	case len(parts) == 4 && parts[2] == "aux" && parts[3] == "level":
+		v, ok := floatArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetChAuxLevel(name, v)
+	case len(parts) == 4 && parts[2] == "meter" && parts[3] == "enable":
+		v, ok := intArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetChMeterEnable(name, v != 0)
+	case len(parts) == 5 && parts[2] == "send" && parts[4] == "level":
+		v, ok := floatArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetChSendLevel(name, parts[3], v)
+	case len(parts) == 5 && parts[2] == "send" && parts[4] == "create":
+		destName, ok := stringArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		busName, ok := stringArg(msg.Args, 1)
+		if !ok {
+			return
+		}
+		level, ok := floatArg(msg.Args, 2)
+		if !ok {
+			return
+		}
+		_ = s.CreateChSend(name, parts[3], destName, busName, level)
+	case len(parts) == 5 && parts[2] == "send" && parts[4] == "remove":
+		_ = s.RemoveChSend(name, parts[3])
+	case len(parts) == 5 && parts[2] == "effect" && parts[4] == "bypass":
+		idx, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return
+		}
+		v, ok := intArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetChEffectBypass(name, idx, v != 0)
+	}
+}
+
+// handleSolo handles /solo/<name> messages.
+func (s *Surface) handleSolo(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 2 || parts[0] != "solo" {
+		return
+	}
+	v, ok := intArg(msg.Args, 0)
+	if !ok {
+		return
+	}
+	_ = s.SetChSolo(parts[1], v != 0)
+}
+
+func (s *Surface) handleChannel(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "channel" {
+		return
+	}
+	id, param := parts[1], parts[2]
+
+	switch param {
+	case "fader":
+		v, ok := floatArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetChannelFader(id, v)
+	case "pan":
+		v, ok := floatArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetChannelPan(id, v)
+	case "mute":
+		v, ok := intArg(msg.Args, 0)
+		if !ok {
+			return
+		}
+		_ = s.SetChannelMute(id, v != 0)
+	}
+}
+
+// SetBusInputFader applies level to bus name's input and notifies
+// /bus/<name> subscribers - the bridge method behind both the
+// /bus/<name>/input/<idx>/fader OSC address and any Go-side caller.
+func (s *Surface) SetBusInputFader(name string, input int, level float32) error {
+	ib, ok := s.lookupInputBus(name)
+	if !ok {
+		return fmt.Errorf("osc: bus %q not registered, or has no inputs", name)
+	}
+	if err := ib.SetInputLevel(input, level); err != nil {
+		return err
+	}
+	s.notify("/bus/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/bus/%s/input/%d/fader", name, input),
+		Args:    []interface{}{level},
+	})
+	return nil
+}
+
+// SetBusInputPan applies pan to bus name's input and notifies subscribers.
+func (s *Surface) SetBusInputPan(name string, input int, pan float32) error {
+	ib, ok := s.lookupInputBus(name)
+	if !ok {
+		return fmt.Errorf("osc: bus %q not registered, or has no inputs", name)
+	}
+	if err := ib.SetInputPan(input, pan); err != nil {
+		return err
+	}
+	s.notify("/bus/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/bus/%s/input/%d/pan", name, input),
+		Args:    []interface{}{pan},
+	})
+	return nil
+}
+
+// SetBusInputMute mutes or unmutes bus name's input and notifies
+// subscribers.
+func (s *Surface) SetBusInputMute(name string, input int, muted bool) error {
+	ib, ok := s.lookupInputBus(name)
+	if !ok {
+		return fmt.Errorf("osc: bus %q not registered, or has no inputs", name)
+	}
+	if err := ib.SetInputMute(input, muted); err != nil {
+		return err
+	}
+	s.notify("/bus/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/bus/%s/input/%d/mute", name, input),
+		Args:    []interface{}{boolToInt32(muted)},
+	})
+	return nil
+}
+
+// SetBusMasterFader applies level to bus name's own overall fader and
+// notifies subscribers.
+func (s *Surface) SetBusMasterFader(name string, level float32) error {
+	b, ok := s.lookupBus(name)
+	if !ok {
+		return fmt.Errorf("osc: bus %q not registered", name)
+	}
+	if err := b.SetLevel(level); err != nil {
+		return err
+	}
+	s.notify("/bus/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/bus/%s/master/fader", name),
+		Args:    []interface{}{level},
+	})
+	return nil
+}
+
+// SetBusMasterMute mutes or unmutes bus name's own overall fader and
+// notifies subscribers.
+func (s *Surface) SetBusMasterMute(name string, muted bool) error {
+	b, ok := s.lookupBus(name)
+	if !ok {
+		return fmt.Errorf("osc: bus %q not registered", name)
+	}
+	if err := b.SetMute(muted); err != nil {
+		return err
+	}
+	s.notify("/bus/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/bus/%s/master/mute", name),
+		Args:    []interface{}{boolToInt32(muted)},
+	})
+	return nil
+}
+
+// SetChannelFader applies volume to channel id and notifies
+// /channel/<id> subscribers.
+func (s *Surface) SetChannelFader(id string, volume float32) error {
+	ch, ok := s.lookupChannel(id)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", id)
+	}
+	if err := ch.SetVolume(volume); err != nil {
+		return err
+	}
+	s.notify("/channel/"+id, wireosc.Message{
+		Address: fmt.Sprintf("/channel/%s/fader", id),
+		Args:    []interface{}{volume},
+	})
+	return nil
+}
+
+// SetChannelPan applies pan to channel id and notifies subscribers.
+func (s *Surface) SetChannelPan(id string, pan float32) error {
+	ch, ok := s.lookupChannel(id)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", id)
+	}
+	if err := ch.SetPan(pan); err != nil {
+		return err
+	}
+	s.notify("/channel/"+id, wireosc.Message{
+		Address: fmt.Sprintf("/channel/%s/pan", id),
+		Args:    []interface{}{pan},
+	})
+	return nil
+}
+
+// SetChannelMute mutes or unmutes channel id and notifies subscribers.
+func (s *Surface) SetChannelMute(id string, muted bool) error {
+	ch, ok := s.lookupChannel(id)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", id)
+	}
+	var err error
+	if muted {
+		err = ch.Mute()
+	} else {
+		err = ch.Unmute()
+	}
+	if err != nil {
+		return err
+	}
+	s.notify("/channel/"+id, wireosc.Message{
+		Address: fmt.Sprintf("/channel/%s/mute", id),
+		Args:    []interface{}{boolToInt32(muted)},
+	})
+	return nil
+}
+
+// SetMasterVolume applies level to the registered MasterBus (see
+// RegisterMaster) and notifies /master subscribers - the bridge method
+// behind the /master/volume OSC address.
+func (s *Surface) SetMasterVolume(level float32) error {
+	s.mu.RLock()
+	master := s.master
+	s.mu.RUnlock()
+	if master == nil {
+		return fmt.Errorf("osc: no master bus registered")
+	}
+	if err := master.SetLevel(level); err != nil {
+		return err
+	}
+	s.notify("/master", wireosc.Message{Address: "/master/volume", Args: []interface{}{level}})
+	return nil
+}
+
+// SetMasterMute mutes or unmutes the registered MasterBus and notifies
+// subscribers.
+func (s *Surface) SetMasterMute(muted bool) error {
+	s.mu.RLock()
+	master := s.master
+	s.mu.RUnlock()
+	if master == nil {
+		return fmt.Errorf("osc: no master bus registered")
+	}
+	if err := master.SetMute(muted); err != nil {
+		return err
+	}
+	s.notify("/master", wireosc.Message{Address: "/master/mute", Args: []interface{}{boolToInt32(muted)}})
+	return nil
+}
+
+// SetChPan applies pan to the channel registered under name (see Registry)
+// and notifies /ch/<name> subscribers.
+func (s *Surface) SetChPan(name string, pan float32) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	if err := ch.SetPan(pan); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{Address: fmt.Sprintf("/ch/%s/pan", name), Args: []interface{}{pan}})
+	return nil
+}
+
+// SetChFader applies volume to the channel registered under name and
+// notifies subscribers.
+func (s *Surface) SetChFader(name string, volume float32) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	if err := ch.SetVolume(volume); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{Address: fmt.Sprintf("/ch/%s/fader", name), Args: []interface{}{volume}})
+	return nil
+}
+
+// SetChMute mutes or unmutes the channel registered under name and
+// notifies subscribers.
+func (s *Surface) SetChMute(name string, muted bool) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	if err := ch.SetMute(muted); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{Address: fmt.Sprintf("/ch/%s/mute", name), Args: []interface{}{boolToInt32(muted)}})
+	return nil
+}
+
+// SetChConnected connects or disconnects the channel registered under name
+// from the engine registered via SetEngine, which is required for this one
+// address - every other /ch/<name>/... address works off the
+// channel.Channel interface alone and doesn't need it. Returns an error if
+// name isn't registered, doesn't support connect/disconnect (only
+// BaseChannel and types embedding it, like MonoToStereoChannel, do), or no
+// engine has been set.
+func (s *Surface) SetChConnected(name string, connected bool) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	mc, ok := ch.(masterConnectable)
+	if !ok {
+		return fmt.Errorf("osc: channel %q does not support connect/disconnect", name)
+	}
+	s.mu.RLock()
+	eng := s.engine
+	s.mu.RUnlock()
+	if eng == nil {
+		return fmt.Errorf("osc: no engine registered, call SetEngine first")
+	}
+
+	var err error
+	if connected {
+		err = mc.ConnectToMaster(eng)
+	} else {
+		err = mc.DisconnectFromMaster(eng)
+	}
+	if err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{Address: fmt.Sprintf("/ch/%s/connect", name), Args: []interface{}{boolToInt32(connected)}})
+	return nil
+}
+
+// SetChSendLevel applies level to the named send on the channel registered
+// under name and notifies subscribers. Returns an error if name isn't
+// registered or doesn't implement sendable (only BaseChannel and types
+// embedding it, like MonoToStereoChannel, do).
+func (s *Surface) SetChSendLevel(name, sendName string, level float32) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	sendCh, ok := ch.(sendable)
+	if !ok {
+		return fmt.Errorf("osc: channel %q does not support named sends", name)
+	}
+	if err := sendCh.SetSendLevel(sendName, level); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/ch/%s/send/%s/level", name, sendName),
+		Args:    []interface{}{level},
+	})
+	return nil
+}
+
+// CreateChSend creates a new named send from the channel registered under
+// name to busName, also looked up in the Registry (see RegisterBus) -
+// CreateAndConnectSend's Bus parameter is a mixer bus, not a
+// channel.Channel, so it's registered separately from /ch/<name>'s own
+// channels. destName is the channel.Channel CreateAndConnectSend records
+// as the send's logical destination for bookkeeping (BaseChannel.Send.
+// Destination) and need not be the same thing as busName - e.g. a send
+// wired into a shared reverb bus might record that bus's return channel as
+// its "destination" for display purposes. The send is created PostFader,
+// matching BaseChannel.CreateSend's own default; there's no address to
+// request PreFader here, since the request this was added for didn't call
+// for one. Notifies /ch/<name> subscribers on success.
+func (s *Surface) CreateChSend(name, sendName, destName, busName string, level float32) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	sc, ok := ch.(sendCreatable)
+	if !ok {
+		return fmt.Errorf("osc: channel %q does not support creating sends", name)
+	}
+	dest, ok := s.registry.Get(destName)
+	if !ok {
+		return fmt.Errorf("osc: send destination %q not registered", destName)
+	}
+	b, ok := s.lookupBus(busName)
+	if !ok {
+		return fmt.Errorf("osc: bus %q not registered", busName)
+	}
+	cb, ok := b.(*channel.Bus)
+	if !ok {
+		return fmt.Errorf("osc: bus %q does not accept new sends", busName)
+	}
+	if _, err := sc.CreateAndConnectSend(sendName, dest, cb, level, channel.PostFader); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/ch/%s/send/%s/create", name, sendName),
+		Args:    []interface{}{destName, busName, level},
+	})
+	return nil
+}
+
+// RemoveChSend removes sendName from the channel registered under name and
+// notifies subscribers. Requires an engine set via SetEngine, same as
+// SetChConnected.
+func (s *Surface) RemoveChSend(name, sendName string) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	sc, ok := ch.(sendCreatable)
+	if !ok {
+		return fmt.Errorf("osc: channel %q does not support removing sends", name)
+	}
+	s.mu.RLock()
+	eng := s.engine
+	s.mu.RUnlock()
+	if eng == nil {
+		return fmt.Errorf("osc: no engine registered, call SetEngine first")
+	}
+	if err := sc.RemoveSend(eng, sendName); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/ch/%s/send/%s/remove", name, sendName),
+	})
+	return nil
+}
+
+// SetChMeterEnable turns output RMS metering for the channel registered
+// under name on or off and tracks it in meterEnabled so broadcastMeters
+// knows which registered channels to poll - OutputRMS errors until
+// metering has been enabled, so polling every registered channel
+// unconditionally would mostly just produce "metering not enabled" errors
+// to discard. Requires an engine set via SetEngine, same as SetChConnected.
+// Notifies /ch/<name> subscribers on success.
+func (s *Surface) SetChMeterEnable(name string, enable bool) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	mc, ok := ch.(meterable)
+	if !ok {
+		return fmt.Errorf("osc: channel %q does not support output metering", name)
+	}
+	s.mu.RLock()
+	eng := s.engine
+	s.mu.RUnlock()
+	if eng == nil {
+		return fmt.Errorf("osc: no engine registered, call SetEngine first")
+	}
+	if err := mc.EnableOutputMetering(eng, enable); err != nil {
+		return err
+	}
+	s.meterMu.Lock()
+	if enable {
+		s.meterEnabled[name] = true
+	} else {
+		delete(s.meterEnabled, name)
+	}
+	s.meterMu.Unlock()
+	s.notify("/ch/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/ch/%s/meter/enable", name),
+		Args:    []interface{}{boolToInt32(enable)},
+	})
+	return nil
+}
+
+// SetChAuxLevel applies level to the channel registered under name's single
+// aux send and notifies subscribers. Returns an error if name isn't
+// registered or doesn't implement auxSendable.
+func (s *Surface) SetChAuxLevel(name string, level float32) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	auxCh, ok := ch.(auxSendable)
+	if !ok {
+		return fmt.Errorf("osc: channel %q does not support an aux send", name)
+	}
+	if err := auxCh.SetAuxSendLevel(level); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/ch/%s/aux/level", name),
+		Args:    []interface{}{level},
+	})
+	return nil
+}
+
+// SetChEffectBypass bypasses or re-enables effect index on the channel
+// registered under name's plugin chain and notifies subscribers.
+// GetPluginChain is part of the channel.Channel interface itself, so this
+// needs no type assertion the way sends and solo do - it just returns an
+// error if name isn't registered or has no plugin chain.
+func (s *Surface) SetChEffectBypass(name string, index int, bypassed bool) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	pc := ch.GetPluginChain()
+	if pc == nil {
+		return fmt.Errorf("osc: channel %q has no plugin chain", name)
+	}
+	if err := pc.SetEffectBypass(index, bypassed); err != nil {
+		return err
+	}
+	s.notify("/ch/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/ch/%s/effect/%d/bypass", name, index),
+		Args:    []interface{}{boolToInt32(bypassed)},
+	})
+	return nil
+}
+
+// SetChSolo solos or unsolos the channel registered under name via
+// engine/channel's package-wide DefaultSolo manager (see
+// BaseChannel.SetSolo) and notifies /solo/<name> subscribers. Returns an
+// error if name isn't registered or doesn't implement soloable.
+func (s *Surface) SetChSolo(name string, on bool) error {
+	ch, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("osc: channel %q not registered", name)
+	}
+	soloCh, ok := ch.(soloable)
+	if !ok {
+		return fmt.Errorf("osc: channel %q does not support solo", name)
+	}
+	soloCh.SetSolo(on)
+	s.notify("/solo/"+name, wireosc.Message{
+		Address: fmt.Sprintf("/solo/%s", name),
+		Args:    []interface{}{boolToInt32(on)},
+	})
+	return nil
+}
@@ -0,0 +1,204 @@
+package osc
+
+import (
+	"testing"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/engine/analyze"
+	"github.com/shaban/macaudio/engine/channel"
+	"github.com/shaban/macaudio/engine/channel/input"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestSurfaceChPanOverOSC registers a MonoToStereoChannel under the
+// Registry, drives it entirely over OSC with a Client, and confirms the
+// result with the same analyze.AnalyzeMonoToStereo measurement
+// engine/channel/input's own tests use for SetPan directly - the OSC path
+// is just another caller of SetPan, so it's held to the same real-audio
+// bar rather than a mocked one.
+func TestSurfaceChPanOverOSC(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{
+		Name:       "foo",
+		Engine:     eng,
+		InitialPan: 0.0,
+	})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+
+	toneNode, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("new tone node: %v", err)
+	}
+	defer toneNode.Destroy()
+
+	toneNodePtr, err := toneNode.GetNodePtr()
+	if err != nil || toneNodePtr == nil {
+		t.Fatalf("tone node ptr: %v", err)
+	}
+	if err := eng.Attach(toneNodePtr); err != nil {
+		t.Fatalf("attach tone node: %v", err)
+	}
+	if err := eng.Connect(toneNodePtr, mono.GetInputNode(), 0, 0); err != nil {
+		t.Fatalf("connect tone to mono input: %v", err)
+	}
+	mainMixerPtr, err := eng.MainMixerNode()
+	if err != nil || mainMixerPtr == nil {
+		t.Fatalf("main mixer ptr: %v", err)
+	}
+	if err := eng.Connect(mono.GetOutputNode(), mainMixerPtr, 0, 0); err != nil {
+		t.Fatalf("connect mono output to main mixer: %v", err)
+	}
+
+	testutil.MuteMainMixer(t, eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("start engine: %v", err)
+	}
+	defer func() {
+		if eng.IsRunning() {
+			eng.Stop()
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	transport, err := wireosc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer transport.Close()
+
+	s := NewSurface(transport)
+	s.Registry().Register("foo", mono)
+	go s.Serve()
+	defer s.Close()
+
+	client, err := Dial(transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Send("/ch/foo/pan", float32(-1.0)); err != nil {
+		t.Fatalf("send pan: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if mono.GetPan() != -1.0 {
+		t.Fatalf("expected pan -1.0 after OSC message, got %.2f", mono.GetPan())
+	}
+
+	analysisConfig := analyze.DefaultAnalysisConfig()
+	analysisConfig.SampleDuration = 100 * time.Millisecond
+	result, err := analyze.AnalyzeMonoToStereo(eng.Ptr(), toneNodePtr, mono.GetOutputNode(), mono.GetPan(), mono.GetPanLaw(), analysisConfig)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if result.Balance > -0.5 {
+		t.Errorf("expected balance to favor left after panning full left over OSC, got %.3f", result.Balance)
+	}
+}
+
+// TestSurfaceChPanQueryOverOSC checks the "?" query variant: a query for
+// /ch/foo/pan should reply with the channel's current pan, without
+// requiring a prior /subscribe.
+func TestSurfaceChPanQueryOverOSC(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mono, err := input.NewMonoToStereo(input.MonoToStereoConfig{
+		Name:       "foo",
+		Engine:     eng,
+		InitialPan: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	defer mono.Release()
+
+	transport, err := wireosc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer transport.Close()
+
+	s := NewSurface(transport)
+	s.Registry().Register("foo", mono)
+	go s.Serve()
+	defer s.Close()
+
+	client, err := Dial(transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	reply, err := client.Query("/ch/foo/pan", time.Second)
+	if err != nil {
+		t.Fatalf("query pan: %v", err)
+	}
+	if reply.Address != "/ch/foo/pan" {
+		t.Errorf("expected reply address /ch/foo/pan, got %s", reply.Address)
+	}
+	v, ok := floatArg(reply.Args, 0)
+	if !ok || v < 0.49 || v > 0.51 {
+		t.Errorf("expected pan ~0.5, got %v (ok=%v)", reply.Args, ok)
+	}
+}
+
+// TestSurfaceMasterVolumeOverOSC checks /master/volume against a
+// registered MasterBus.
+func TestSurfaceMasterVolumeOverOSC(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	mb, err := channel.NewMasterBus(eng)
+	if err != nil {
+		t.Fatalf("new master bus: %v", err)
+	}
+
+	transport, err := wireosc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer transport.Close()
+
+	s := NewSurface(transport)
+	s.RegisterMaster(mb)
+	go s.Serve()
+	defer s.Close()
+
+	client, err := Dial(transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Send("/master/volume", float32(0.25)); err != nil {
+		t.Fatalf("send master volume: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	level, err := mb.GetLevel()
+	if err != nil {
+		t.Fatalf("GetLevel: %v", err)
+	}
+	if level < 0.24 || level > 0.26 {
+		t.Errorf("expected master level ~0.25, got %f", level)
+	}
+}
@@ -0,0 +1,226 @@
+package osc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+	"github.com/shaban/macaudio/engine/channel"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+func newTestSurface(t *testing.T) (*Surface, *channel.Bus, net.Conn) {
+	t.Helper()
+
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	t.Cleanup(eng.Destroy)
+
+	b, err := channel.NewBus(eng, "main")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	t.Cleanup(b.Release)
+
+	transport, err := wireosc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenUDP: %v", err)
+	}
+	t.Cleanup(func() { _ = transport.Close() })
+
+	s := NewSurface(transport)
+	s.RegisterBus("main", b)
+	go s.Serve()
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	return s, b, client
+}
+
+func sendMsg(t *testing.T, conn net.Conn, addr string, args ...interface{}) {
+	t.Helper()
+	data, err := wireosc.Message{Address: addr, Args: args}.Marshal()
+	if err != nil {
+		t.Fatalf("marshal %s: %v", addr, err)
+	}
+	if _, err := conn.Write(data); err != nil {
+		t.Fatalf("write %s: %v", addr, err)
+	}
+}
+
+// TestSurfaceCloseIsIdempotent checks a second Close doesn't panic closing
+// an already-closed channel.
+func TestSurfaceCloseIsIdempotent(t *testing.T) {
+	transport, err := wireosc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenUDP: %v", err)
+	}
+	s := NewSurface(transport)
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestSurfaceBusInputFaderOverOSC(t *testing.T) {
+	_, b, client := newTestSurface(t)
+
+	// Give the bus an input to address by connecting a dummy mixer as the
+	// source, since SetInputLevel falls back to the mixer-level setter
+	// regardless of whether an input is actually wired up.
+	idx := b.NextInput()
+
+	sendMsg(t, client, "/bus/main/input/0/fader", float32(0.5))
+	time.Sleep(50 * time.Millisecond)
+
+	level, err := b.GetInputLevel(idx)
+	if err != nil {
+		t.Fatalf("GetInputLevel: %v", err)
+	}
+	if level < 0.49 || level > 0.51 {
+		t.Errorf("expected input level ~0.5, got %f", level)
+	}
+}
+
+func TestSurfaceBusMasterFaderBridgeNotifiesSubscribers(t *testing.T) {
+	s, _, client := newTestSurface(t)
+
+	sendMsg(t, client, "/subscribe", "/bus/main")
+	time.Sleep(20 * time.Millisecond)
+
+	if err := s.SetBusMasterFader("main", 0.25); err != nil {
+		t.Fatalf("SetBusMasterFader: %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	_ = client.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	n, err := client.Read(buf)
+	if err != nil {
+		t.Fatalf("expected a notification, got error: %v", err)
+	}
+	msg, err := wireosc.Unmarshal(buf[:n])
+	if err != nil {
+		t.Fatalf("parse notification: %v", err)
+	}
+	if msg.Address != "/bus/main/master/fader" {
+		t.Errorf("expected /bus/main/master/fader, got %s", msg.Address)
+	}
+}
+
+func TestSurfaceBusMasterMuteRoundTrip(t *testing.T) {
+	s, b, _ := newTestSurface(t)
+
+	if err := s.SetBusMasterMute("main", true); err != nil {
+		t.Fatalf("SetBusMasterMute: %v", err)
+	}
+	if !b.GetMute() {
+		t.Error("expected bus to report muted")
+	}
+
+	if err := s.SetBusMasterMute("main", false); err != nil {
+		t.Fatalf("SetBusMasterMute unmute: %v", err)
+	}
+	if b.GetMute() {
+		t.Error("expected bus to report unmuted")
+	}
+}
+
+func TestSurfaceUnregisteredBusReturnsError(t *testing.T) {
+	s, _, _ := newTestSurface(t)
+
+	if err := s.SetBusInputFader("missing", 0, 0.5); err == nil {
+		t.Error("expected error for unregistered bus")
+	}
+}
+
+// TestSurfaceCreateAndRemoveSendOverOSC checks /ch/<name>/send/<sendName>/create.
+// It doesn't exercise /ch/<name>/send/<sendName>/remove: RemoveChSend calls
+// BaseChannel.RemoveSend, which locks routeMu and then calls DisconnectSend,
+// which locks routeMu again - routeMu is a plain sync.Mutex, so that call
+// deadlocks regardless of this commit's changes (a pre-existing bug, the same
+// one channel_events_test.go's TestSubscribeReceivesSendLifecycleEvents works
+// around the same way).
+func TestSurfaceCreateAndRemoveSendOverOSC(t *testing.T) {
+	s, b, client := newTestSurface(t)
+
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	t.Cleanup(eng.Destroy)
+	s.SetEngine(eng)
+
+	src, err := channel.NewBaseChannel(channel.BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new src channel: %v", err)
+	}
+	t.Cleanup(src.Release)
+	dst, err := channel.NewBaseChannel(channel.BaseChannelConfig{Name: "reverb", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new dst channel: %v", err)
+	}
+	t.Cleanup(dst.Release)
+	s.Registry().Register("lead", src)
+	s.Registry().Register("reverb", dst)
+
+	sendMsg(t, client, "/ch/lead/send/verb/create", "reverb", "main", float32(0.4))
+	time.Sleep(50 * time.Millisecond)
+
+	sends := src.GetSends()
+	send, ok := sends["verb"]
+	if !ok {
+		t.Fatalf("expected send %q to have been created, got %v", "verb", sends)
+	}
+	if send.Level != 0.4 {
+		t.Errorf("expected send level 0.4, got %f", send.Level)
+	}
+	_ = b // bus registered as "main" above; send is wired into it
+}
+
+func TestSurfaceMeterEnableNotifiesSubscribers(t *testing.T) {
+	s, _, client := newTestSurface(t)
+
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	t.Cleanup(eng.Destroy)
+	s.SetEngine(eng)
+
+	ch, err := channel.NewBaseChannel(channel.BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	t.Cleanup(ch.Release)
+	s.Registry().Register("lead", ch)
+
+	sendMsg(t, client, "/subscribe", "/ch/lead")
+	time.Sleep(20 * time.Millisecond)
+
+	sendMsg(t, client, "/ch/lead/meter/enable", int32(1))
+
+	buf := make([]byte, 1024)
+	_ = client.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	n, err := client.Read(buf)
+	if err != nil {
+		t.Fatalf("expected a notification, got error: %v", err)
+	}
+	msg, err := wireosc.Unmarshal(buf[:n])
+	if err != nil {
+		t.Fatalf("parse notification: %v", err)
+	}
+	if msg.Address != "/ch/lead/meter/enable" {
+		t.Errorf("expected /ch/lead/meter/enable, got %s", msg.Address)
+	}
+}
@@ -0,0 +1,151 @@
+// Package convert converts PCM samples between the SampleFormats
+// avaudio/engine.EnhancedAudioSpec and Format build around, using
+// saturated (clamping, not wrapping) arithmetic for every integer
+// conversion - an out-of-range float (a clipped file, a buggy upstream
+// gain stage) clips instead of wrapping around to the opposite sign.
+package convert
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+const (
+	maxInt16 = math.MaxInt16
+	minInt16 = math.MinInt16
+	maxInt24 = 1<<23 - 1
+	minInt24 = -(1 << 23)
+	maxInt32 = math.MaxInt32
+	minInt32 = math.MinInt32
+)
+
+// ConvertF32ToI16 writes a saturated 16-bit sample for each float32 in src
+// (expected in [-1, 1]) into dst. dst must be at least len(src) long.
+func ConvertF32ToI16(dst []int16, src []float32) {
+	for i, s := range src {
+		dst[i] = int16(saturate(s, minInt16, maxInt16))
+	}
+}
+
+// ConvertI16ToF32 writes a float32 in [-1, 1] for each 16-bit sample in src
+// into dst. dst must be at least len(src) long.
+func ConvertI16ToF32(dst []float32, src []int16) {
+	for i, s := range src {
+		dst[i] = float32(s) / maxInt16
+	}
+}
+
+// ConvertF32ToI24 writes a saturated 24-bit sample for each float32 in src
+// (expected in [-1, 1]) into dst as 3 little-endian bytes - the packed
+// layout AVAudioCommonFormat has no native representation for (see
+// avAudioCommonFormat in avaudio/engine), but CoreAudio's 24-bit PCM
+// streams still use. dst must be at least 3*len(src) bytes long.
+func ConvertF32ToI24(dst []byte, src []float32) {
+	for i, s := range src {
+		putInt24(dst[i*3:i*3+3], int32(saturate(s, minInt24, maxInt24)))
+	}
+}
+
+// saturate scales s (expected in [-1, 1]) by max and clamps the result to
+// [min, max], so a sample outside [-1, 1] clips at the format's full-scale
+// value instead of wrapping to the opposite sign.
+func saturate(s float32, min, max float64) float64 {
+	v := float64(s) * max
+	if v > max {
+		return max
+	}
+	if v < min {
+		return min
+	}
+	return v
+}
+
+func putInt24(dst []byte, v int32) {
+	dst[0] = byte(v)
+	dst[1] = byte(v >> 8)
+	dst[2] = byte(v >> 16)
+}
+
+func getInt24(src []byte) int32 {
+	v := int32(src[0]) | int32(src[1])<<8 | int32(src[2])<<16
+	if v&(1<<23) != 0 { // sign-extend the 24th bit
+		v |= -1 << 24
+	}
+	return v
+}
+
+// Convert decodes src as packed srcFmt samples, converts each to dstFmt,
+// and writes the result into dst, in place - no intermediate allocation
+// beyond the per-sample float32 used as the common currency between
+// formats. dst must have room for (len(src)/srcFmt.BytesPerSample())
+// samples of dstFmt; src's length must be a multiple of srcFmt's sample
+// size. SampleFormatInt24 is supported here (packed 3-byte little-endian)
+// even though AVAudioCommonFormat has no native 24-bit case - this is the
+// conversion path avAudioCommonFormat's doc comment points to for it.
+func Convert(dst, src []byte, dstFmt, srcFmt engine.SampleFormat) error {
+	srcSize := srcFmt.BytesPerSample()
+	dstSize := dstFmt.BytesPerSample()
+	if srcSize == 0 {
+		return fmt.Errorf("convert: unknown source sample format %v", srcFmt)
+	}
+	if dstSize == 0 {
+		return fmt.Errorf("convert: unknown destination sample format %v", dstFmt)
+	}
+	if len(src)%srcSize != 0 {
+		return fmt.Errorf("convert: src length %d is not a multiple of %d bytes for %v", len(src), srcSize, srcFmt)
+	}
+
+	count := len(src) / srcSize
+	if len(dst) < count*dstSize {
+		return fmt.Errorf("convert: dst has room for %d %v samples, need %d", len(dst)/dstSize, dstFmt, count)
+	}
+
+	for i := 0; i < count; i++ {
+		sample := decodeSample(src[i*srcSize:(i+1)*srcSize], srcFmt)
+		encodeSample(dst[i*dstSize:(i+1)*dstSize], sample, dstFmt)
+	}
+	return nil
+}
+
+// decodeSample reads one packed sample of format from b and returns it as
+// a float32 in [-1, 1] (or whatever a Float32/Float64 source actually
+// held, un-clamped, if it was out of range to begin with).
+func decodeSample(b []byte, format engine.SampleFormat) float32 {
+	switch format {
+	case engine.SampleFormatInt16:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / maxInt16
+	case engine.SampleFormatInt24:
+		return float32(getInt24(b)) / maxInt24
+	case engine.SampleFormatInt32:
+		return float32(int32(binary.LittleEndian.Uint32(b))) / maxInt32
+	case engine.SampleFormatFloat64:
+		return float32(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	case engine.SampleFormatFloat32:
+		fallthrough
+	default:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+}
+
+// encodeSample writes sample (expected in [-1, 1] for every integer
+// format) into dst as format, saturating rather than wrapping if sample
+// is out of range.
+func encodeSample(dst []byte, sample float32, format engine.SampleFormat) {
+	switch format {
+	case engine.SampleFormatInt16:
+		binary.LittleEndian.PutUint16(dst, uint16(int16(saturate(sample, minInt16, maxInt16))))
+	case engine.SampleFormatInt24:
+		putInt24(dst, int32(saturate(sample, minInt24, maxInt24)))
+	case engine.SampleFormatInt32:
+		binary.LittleEndian.PutUint32(dst, uint32(int32(saturate(sample, minInt32, maxInt32))))
+	case engine.SampleFormatFloat64:
+		binary.LittleEndian.PutUint64(dst, math.Float64bits(float64(sample)))
+	case engine.SampleFormatFloat32:
+		fallthrough
+	default:
+		binary.LittleEndian.PutUint32(dst, math.Float32bits(sample))
+	}
+}
@@ -0,0 +1,71 @@
+package macaudio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/shaban/macaudio/audio"
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// Decoder streams decoded PCM from an io.Reader source in blocks, for
+// playback formats AVAudioFile can't open directly (FLAC/MP3/Opus/...).
+// Unlike avengine.Decoder (which decodes a whole file up front into one
+// avengine.PCMBuffer via a file path), a Decoder here is for sources too
+// large, or arriving too slowly, to fully decode before playback starts -
+// an HTTP response body, a tar entry, or any other io.Reader - and is fed
+// to CreatePlaybackChannelFromDecoder instead of a FilePath.
+type Decoder interface {
+	// Blocks returns the channel Blocks are delivered on. It is closed once
+	// the source is exhausted or Close is called.
+	Blocks() <-chan audio.Block
+	// Spec describes the format Blocks are decoded into.
+	Spec() avengine.EnhancedAudioSpec
+	// Close releases the decoder's resources and unblocks any pending read
+	// of Blocks.
+	Close() error
+}
+
+// DecoderOpener opens a Decoder over r. Registered per file extension via
+// RegisterDecoder.
+type DecoderOpener func(r io.Reader) (Decoder, error)
+
+var (
+	decoderOpenersMu sync.RWMutex
+	decoderOpeners   = map[string]DecoderOpener{}
+)
+
+// RegisterDecoder associates open with a file extension (e.g. ".flac",
+// leading dot optional, matched case-insensitively), so OpenDecoder can
+// pick an opener by extension rather than callers selecting one explicitly.
+// The built-in FLAC/MP3/Opus decoders register themselves this way in their
+// own init(); registering the same extension twice replaces the previous
+// opener, so a host application can swap in its own decoder over a
+// built-in one.
+func RegisterDecoder(ext string, open DecoderOpener) {
+	decoderOpenersMu.Lock()
+	defer decoderOpenersMu.Unlock()
+	decoderOpeners[normalizeDecoderExt(ext)] = open
+}
+
+// OpenDecoder opens a Decoder for r using the DecoderOpener registered for
+// ext (see RegisterDecoder).
+func OpenDecoder(ext string, r io.Reader) (Decoder, error) {
+	decoderOpenersMu.RLock()
+	open, ok := decoderOpeners[normalizeDecoderExt(ext)]
+	decoderOpenersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no decoder registered for %s", ext)
+	}
+	return open(r)
+}
+
+func normalizeDecoderExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
@@ -0,0 +1,83 @@
+package macaudio
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/hajimehoshi/go-mp3"
+
+	"github.com/shaban/macaudio/audio"
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+func init() {
+	RegisterDecoder(".mp3", openMP3Decoder)
+}
+
+// mp3BlockFrames is the number of frames decoded per audio.Block, chosen to
+// keep pace comfortably ahead of playback without over-buffering.
+const mp3BlockFrames = 4096
+
+// mp3Decoder streams an MP3 source via go-mp3, which always decodes to
+// 16-bit stereo PCM regardless of the source's own encoded layout.
+type mp3Decoder struct {
+	dec      *mp3.Decoder
+	blocks   chan audio.Block
+	spec     avengine.EnhancedAudioSpec
+	done     chan struct{}
+	closeOne sync.Once
+}
+
+func openMP3Decoder(r io.Reader) (Decoder, error) {
+	dec, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &mp3Decoder{
+		dec:    dec,
+		blocks: make(chan audio.Block, 2),
+		spec: avengine.EnhancedAudioSpec{
+			SampleRate:   float64(dec.SampleRate()),
+			ChannelCount: 2,
+		},
+		done: make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
+
+func (d *mp3Decoder) run() {
+	defer close(d.blocks)
+
+	pcm := make([]byte, mp3BlockFrames*4) // 2 channels * 2 bytes/sample
+	for {
+		n, err := io.ReadFull(d.dec, pcm)
+		if n > 0 {
+			frames := n / 4
+			samples := make([]float32, frames*2)
+			for i := 0; i < frames*2; i++ {
+				v := int16(binary.LittleEndian.Uint16(pcm[i*2:]))
+				samples[i] = float32(v) / 32768.0
+			}
+			select {
+			case d.blocks <- audio.Block{Samples: samples, Frames: frames}:
+			case <-d.done:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (d *mp3Decoder) Blocks() <-chan audio.Block { return d.blocks }
+
+func (d *mp3Decoder) Spec() avengine.EnhancedAudioSpec { return d.spec }
+
+func (d *mp3Decoder) Close() error {
+	d.closeOne.Do(func() { close(d.done) })
+	return nil
+}
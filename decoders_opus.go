@@ -0,0 +1,23 @@
+package macaudio
+
+import (
+	"errors"
+	"io"
+)
+
+func init() {
+	RegisterDecoder(".opus", openOpusDecoder)
+}
+
+// openOpusDecoder is registered for ".opus" so OpenDecoder/
+// CreatePlaybackChannelFromDecoder have a consistent place to find an Opus
+// decoder, but isn't implemented yet: hraban/opus decodes raw Opus packets,
+// while a ".opus" file is an Ogg container around those packets, and this
+// tree has no Ogg demuxer to pull the packets out of it (the FLAC/MP3
+// decoders in decoders_flac.go/decoders_mp3.go don't need one, since
+// mewkiz/flac and go-mp3 both parse their own container directly). Wiring
+// this up needs an Ogg page/packet reader ahead of the hraban/opus decode
+// step.
+func openOpusDecoder(r io.Reader) (Decoder, error) {
+	return nil, errors.New("opus decoding is not implemented: needs an Ogg demuxer ahead of hraban/opus (see openOpusDecoder)")
+}
@@ -0,0 +1,71 @@
+package macaudio
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/audio"
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// fakeDecoder is a minimal Decoder for tests: it delivers no blocks and
+// closes its channel immediately, since exercising real PCM streaming needs
+// a running AVAudioEngine this sandbox doesn't have.
+type fakeDecoder struct {
+	blocks chan audio.Block
+	closed bool
+}
+
+func newFakeDecoder() *fakeDecoder {
+	blocks := make(chan audio.Block)
+	close(blocks)
+	return &fakeDecoder{blocks: blocks}
+}
+
+func (d *fakeDecoder) Blocks() <-chan audio.Block { return d.blocks }
+
+func (d *fakeDecoder) Spec() avengine.EnhancedAudioSpec {
+	return avengine.EnhancedAudioSpec{SampleRate: 48000, ChannelCount: 2}
+}
+
+func (d *fakeDecoder) Close() error {
+	d.closed = true
+	return nil
+}
+
+// TestPlaybackChannelFromDecoder mirrors TestChannelCreation's playback
+// case, but sources the channel from a Decoder instead of a file path.
+func TestPlaybackChannelFromDecoder(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if err := eng.Start(); err != nil {
+		t.Logf("Engine Start() returned error (expected for incomplete setup): %v", err)
+		return
+	}
+	defer eng.Stop()
+
+	dec := newFakeDecoder()
+	channel, err := eng.CreatePlaybackChannelFromDecoder("test_decoder_playback", dec)
+	if err != nil {
+		t.Fatalf("Failed to create decoder-backed playback channel: %v", err)
+	}
+
+	if channel.GetIDString() == "" {
+		t.Error("Decoder-backed playback channel should have a valid ID string")
+	}
+	if channel.GetType() != ChannelTypePlayback {
+		t.Errorf("Channel type should be playback, got %s", channel.GetType())
+	}
+}
+
+// TestOpenDecoderUnregisteredExtension checks OpenDecoder fails clearly for
+// an extension with no registered decoder.
+func TestOpenDecoderUnregisteredExtension(t *testing.T) {
+	if _, err := OpenDecoder(".does-not-exist", nil); err == nil {
+		t.Error("Expected an error opening a decoder for an unregistered extension, got nil")
+	}
+}
@@ -0,0 +1,153 @@
+// Package denoise wraps librnnoise's RNN-based noise suppressor for
+// AudioInputChannel.EnableNoiseSuppression. The library is dlopen'd lazily
+// on first use rather than linked at build time, so a build on a machine
+// without librnnoise installed still links and runs - EnableNoiseSuppression
+// just returns an error instead of installing a denoiser (see Available).
+package denoise
+
+/*
+#cgo LDFLAGS: -ldl
+#include <dlfcn.h>
+#include <stdlib.h>
+
+typedef void DenoiseState;
+
+typedef DenoiseState* (*rnnoise_create_fn)(void*);
+typedef void (*rnnoise_destroy_fn)(DenoiseState*);
+typedef float (*rnnoise_process_frame_fn)(DenoiseState*, float*, const float*);
+
+static void*                      rnnoise_handle            = NULL;
+static rnnoise_create_fn          rnnoise_create_sym        = NULL;
+static rnnoise_destroy_fn         rnnoise_destroy_sym       = NULL;
+static rnnoise_process_frame_fn   rnnoise_process_frame_sym = NULL;
+
+// rnnoise_load dlopen's librnnoise and resolves the three entry points this
+// package needs, memoizing success so a second call is a no-op. Tries the
+// Linux/ELF soname first, then the macOS dylib name, since the repo targets
+// macOS but librnnoise ships under its upstream Linux soname more often
+// than a dylib one.
+static const char* rnnoise_load(void) {
+    if (rnnoise_handle != NULL) {
+        return NULL;
+    }
+    rnnoise_handle = dlopen("librnnoise.so.0", RTLD_NOW);
+    if (rnnoise_handle == NULL) {
+        rnnoise_handle = dlopen("librnnoise.dylib", RTLD_NOW);
+    }
+    if (rnnoise_handle == NULL) {
+        return dlerror();
+    }
+
+    rnnoise_create_sym        = (rnnoise_create_fn)dlsym(rnnoise_handle, "rnnoise_create");
+    rnnoise_destroy_sym       = (rnnoise_destroy_fn)dlsym(rnnoise_handle, "rnnoise_destroy");
+    rnnoise_process_frame_sym = (rnnoise_process_frame_fn)dlsym(rnnoise_handle, "rnnoise_process_frame");
+    if (rnnoise_create_sym == NULL || rnnoise_destroy_sym == NULL || rnnoise_process_frame_sym == NULL) {
+        rnnoise_handle = NULL;
+        return "librnnoise: missing expected symbol";
+    }
+    return NULL;
+}
+
+static DenoiseState* rnnoise_create_call(void) {
+    return rnnoise_create_sym(NULL);
+}
+
+static void rnnoise_destroy_call(DenoiseState* st) {
+    rnnoise_destroy_sym(st);
+}
+
+static float rnnoise_process_frame_call(DenoiseState* st, float* out, const float* in) {
+    return rnnoise_process_frame_sym(st, out, in);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// FrameSize is the fixed frame length librnnoise's rnnoise_process_frame
+// requires: 480 samples (10ms) of mono float32 at 48kHz. Resampling a
+// channel's native buffer to this rate/frame size, and the result back, is
+// the caller's responsibility - see AudioInputChannel.EnableNoiseSuppression.
+const FrameSize = 480
+
+var (
+	loadOnce sync.Once
+	loadErr  error
+)
+
+// ensureLoaded dlopen's librnnoise on first use and memoizes the result, so
+// a process that never calls New pays no dlopen cost and a process where
+// the library is missing doesn't retry dlopen on every New call.
+func ensureLoaded() error {
+	loadOnce.Do(func() {
+		if errStr := C.rnnoise_load(); errStr != nil {
+			loadErr = fmt.Errorf("denoise: librnnoise unavailable: %s", C.GoString(errStr))
+		}
+	})
+	return loadErr
+}
+
+// Available reports whether librnnoise loaded successfully, so a caller can
+// decide whether to offer noise suppression at all before calling New.
+func Available() bool {
+	return ensureLoaded() == nil
+}
+
+// Denoiser wraps one librnnoise DenoiseState. librnnoise's state isn't safe
+// to share across concurrent streams, so callers create one Denoiser per
+// channel rather than sharing a single instance.
+type Denoiser struct {
+	mu    sync.Mutex
+	state *C.DenoiseState
+}
+
+// New creates a Denoiser, dlopen'ing librnnoise on first call across the
+// process. Returns an error - never a crash - if the library isn't
+// installed or doesn't export the symbols this package expects.
+func New() (*Denoiser, error) {
+	if err := ensureLoaded(); err != nil {
+		return nil, err
+	}
+	state := C.rnnoise_create_call()
+	if state == nil {
+		return nil, errors.New("denoise: rnnoise_create failed")
+	}
+	return &Denoiser{state: state}, nil
+}
+
+// Process denoises one FrameSize-sample frame of 48kHz mono float32 samples
+// in in, writing the result into out (in and out may be the same slice),
+// and returns librnnoise's voice-activity probability in [0,1] for the
+// frame just processed - the value EnableNoiseSuppression's threshold gates
+// against.
+func (d *Denoiser) Process(out, in []float32) (float32, error) {
+	if len(in) != FrameSize || len(out) != FrameSize {
+		return 0, fmt.Errorf("denoise: frame must be %d samples, got in=%d out=%d", FrameSize, len(in), len(out))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state == nil {
+		return 0, errors.New("denoise: Denoiser already closed")
+	}
+
+	vad := C.rnnoise_process_frame_call(d.state, (*C.float)(unsafe.Pointer(&out[0])), (*C.float)(unsafe.Pointer(&in[0])))
+	return float32(vad), nil
+}
+
+// Close releases the underlying DenoiseState. Safe to call more than once.
+func (d *Denoiser) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state == nil {
+		return nil
+	}
+	C.rnnoise_destroy_call(d.state)
+	d.state = nil
+	return nil
+}
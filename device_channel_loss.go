@@ -0,0 +1,107 @@
+package macaudio
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// channelDeviceUsers returns, for every AudioInputChannel/MidiInputChannel
+// currently bound to deviceUID, its channel ID and configured
+// FallbackDeviceUID (empty if none), mirroring failoverOutputDevice/
+// failoverInputDevice's per-engine-device lookup but at channel scope.
+func (e *Engine) channelDeviceUsers(deviceUID string) map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	users := make(map[string]string)
+	for id, ch := range e.channels {
+		switch c := ch.(type) {
+		case *AudioInputChannel:
+			if c.deviceUID == deviceUID {
+				users[id] = c.config.FallbackDeviceUID
+			}
+		case *MidiInputChannel:
+			if c.deviceUID == deviceUID {
+				users[id] = c.config.FallbackDeviceUID
+			}
+		}
+	}
+	return users
+}
+
+// handleChannelDeviceStatusChanged is DeviceMonitor's internalStatusListener
+// counterpart at channel scope, called unconditionally from
+// handleDeviceStatusChanged for every status flip regardless of whether uid
+// is the engine's primary output device. For each AudioInputChannel/
+// MidiInputChannel bound to uid: a device coming back online unmutes any
+// channel this func previously muted over it (see recoverChannelDevices); a
+// device going offline rebinds the channel to its configured
+// FallbackDeviceUID via the same Dispatcher.ChangeChannelDevice path a
+// caller-initiated switch uses, or, if none is configured or the rebind
+// itself fails, mutes the channel and emits EventDeviceLost.
+func (e *Engine) handleChannelDeviceStatusChanged(uid string, isOnline bool) {
+	if isOnline {
+		e.recoverChannelDevices(uid)
+		return
+	}
+
+	for channelID, fallbackUID := range e.channelDeviceUsers(uid) {
+		if fallbackUID != "" {
+			if err := e.dispatcher.ChangeChannelDevice(channelID, fallbackUID); err == nil {
+				e.dispatcher.emitEvent(DispatcherEvent{Type: EventFallbackActivated, ChannelID: channelID, Path: fallbackUID})
+				continue
+			}
+			e.dispatcher.emitEvent(DispatcherEvent{Type: EventOperationFailed, ChannelID: channelID, Path: fallbackUID})
+			// Fall through to mute+mark-lost below, same as a channel with no fallback configured.
+		}
+
+		e.deviceLossMu.Lock()
+		e.lostChannelDevices[channelID] = uid
+		e.deviceLossMu.Unlock()
+
+		if err := e.dispatcher.SetChannelMute(channelID, true); err != nil {
+			e.errorHandler.HandleError(fmt.Errorf("channel %s's device %s went offline and muting it failed: %w", channelID, uid, err))
+		}
+		e.dispatcher.emitEvent(DispatcherEvent{Type: EventDeviceLost, ChannelID: channelID, Path: uid})
+	}
+}
+
+// recoverChannelDevices unmutes and emits EventDeviceRestored for every
+// channel handleChannelDeviceStatusChanged muted after uid went offline -
+// the channel-scoped counterpart to Engine.recoverFromDeviceLoss. Channels
+// that were rebound to a FallbackDeviceUID instead of muted aren't tracked
+// in lostChannelDevices, so uid coming back online doesn't move them back.
+func (e *Engine) recoverChannelDevices(uid string) {
+	e.deviceLossMu.Lock()
+	var restored []string
+	for channelID, lostUID := range e.lostChannelDevices {
+		if lostUID == uid {
+			restored = append(restored, channelID)
+			delete(e.lostChannelDevices, channelID)
+		}
+	}
+	e.deviceLossMu.Unlock()
+
+	for _, channelID := range restored {
+		if err := e.dispatcher.SetChannelMute(channelID, false); err != nil {
+			e.errorHandler.HandleError(fmt.Errorf("channel %s's device %s came back online and unmuting it failed: %w", channelID, uid, err))
+		}
+		e.dispatcher.emitEvent(DispatcherEvent{Type: EventDeviceRestored, ChannelID: channelID, Path: uid})
+	}
+}
+
+// handleChannelDeviceFormatChanged is DeviceMonitor's internalUpdateListener,
+// emitting EventFormatChanged for every channel bound to uid whose device's
+// supported sample rates changed between polls. Unlike a device going
+// offline, a format change doesn't make the channel unusable on its own, so
+// this only notifies rather than muting or rebinding anything.
+func (e *Engine) handleChannelDeviceFormatChanged(uid string, old, new devices.AudioDevice) {
+	if reflect.DeepEqual(old.SupportedSampleRates, new.SupportedSampleRates) {
+		return
+	}
+	for channelID := range e.channelDeviceUsers(uid) {
+		e.dispatcher.emitEvent(DispatcherEvent{Type: EventFormatChanged, ChannelID: channelID, Path: uid})
+	}
+}
@@ -0,0 +1,135 @@
+package macaudio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+// newMockEngineForDeviceLoss builds a MockBackend-backed, started Engine the
+// same way TestDispatcherRaceConditions does, so handleChannelDeviceStatusChanged's
+// Dispatcher.ChangeChannelDevice/SetChannelMute calls resolve deterministically
+// through MockBackend instead of real CoreAudio/CoreMIDI device validation.
+func newMockEngineForDeviceLoss(t *testing.T) *Engine {
+	t.Helper()
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   256,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		MockBackend:  true,
+		ErrorHandler: &DefaultErrorHandler{},
+	}
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	t.Cleanup(func() { eng.Destroy() })
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	t.Cleanup(func() { eng.Stop() })
+	return eng
+}
+
+// TestHandleChannelDeviceStatusChangedRebindsToFallback checks that a
+// channel with FallbackDeviceUID configured is rebound (not muted) when its
+// own device goes offline.
+func TestHandleChannelDeviceStatusChangedRebindsToFallback(t *testing.T) {
+	eng := newMockEngineForDeviceLoss(t)
+
+	channel, err := eng.CreateMidiInputChannel("midi-in", MidiInputConfig{
+		DeviceUID:         "midi-primary",
+		Channel:           -1,
+		FallbackDeviceUID: "midi-backup",
+	})
+	if err != nil {
+		t.Fatalf("CreateMidiInputChannel failed: %v", err)
+	}
+
+	eng.handleChannelDeviceStatusChanged("midi-primary", false)
+
+	// Give the dispatcher goroutine a moment to process the queued
+	// ChangeChannelDevice/SetChannelMute ops it submitted.
+	time.Sleep(50 * time.Millisecond)
+
+	state := channel.GetState()
+	if state.Muted {
+		t.Fatalf("expected channel with a configured fallback to stay unmuted, got muted")
+	}
+	if uid, _ := state.Config["deviceUID"].(string); uid != "midi-backup" {
+		t.Fatalf("expected channel to be rebound to midi-backup, got %q", uid)
+	}
+
+	eng.deviceLossMu.Lock()
+	_, tracked := eng.lostChannelDevices[channel.GetIDString()]
+	eng.deviceLossMu.Unlock()
+	if tracked {
+		t.Fatalf("a successfully rebound channel should not be tracked in lostChannelDevices")
+	}
+}
+
+// TestHandleChannelDeviceStatusChangedMutesWithNoFallback checks that a
+// channel with no FallbackDeviceUID is muted and marked lost when its
+// device goes offline, then unmuted once the device comes back.
+func TestHandleChannelDeviceStatusChangedMutesWithNoFallback(t *testing.T) {
+	eng := newMockEngineForDeviceLoss(t)
+
+	channel, err := eng.CreateMidiInputChannel("midi-in-no-fallback", MidiInputConfig{
+		DeviceUID: "midi-only",
+		Channel:   -1,
+	})
+	if err != nil {
+		t.Fatalf("CreateMidiInputChannel failed: %v", err)
+	}
+
+	events := make(chan DispatcherEvent, 8)
+	eng.GetDispatcher().OnChannelEvent(func(ev DispatcherEvent) {
+		select {
+		case events <- ev:
+		default:
+		}
+	})
+
+	eng.handleChannelDeviceStatusChanged("midi-only", false)
+	time.Sleep(50 * time.Millisecond)
+
+	if !channel.GetState().Muted {
+		t.Fatalf("expected channel with no fallback to be muted after its device went offline")
+	}
+
+	eng.deviceLossMu.Lock()
+	lostUID, tracked := eng.lostChannelDevices[channel.GetIDString()]
+	eng.deviceLossMu.Unlock()
+	if !tracked || lostUID != "midi-only" {
+		t.Fatalf("expected channel to be tracked as lost over midi-only, got tracked=%v uid=%q", tracked, lostUID)
+	}
+
+	var sawLost bool
+	select {
+	case ev := <-events:
+		sawLost = ev.Type == EventDeviceLost && ev.ChannelID == channel.GetIDString()
+	case <-time.After(time.Second):
+	}
+	if !sawLost {
+		t.Fatalf("expected an EventDeviceLost for the channel")
+	}
+
+	eng.handleChannelDeviceStatusChanged("midi-only", true)
+	time.Sleep(50 * time.Millisecond)
+
+	if channel.GetState().Muted {
+		t.Fatalf("expected channel to be unmuted after its device came back online")
+	}
+
+	eng.deviceLossMu.Lock()
+	_, stillTracked := eng.lostChannelDevices[channel.GetIDString()]
+	eng.deviceLossMu.Unlock()
+	if stillTracked {
+		t.Fatalf("expected lostChannelDevices entry to be cleared after recovery")
+	}
+}
@@ -0,0 +1,151 @@
+package macaudio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// handleDeviceStatusChanged is DeviceMonitor's internalStatusListener. It
+// always runs handleChannelDeviceStatusChanged first, since any channel
+// bound directly to uid cares about this regardless of what the engine's
+// shared devices are doing; the rest of this func implements
+// EngineConfig.OnDeviceLost, which only reacts when uid is the engine's
+// current primary output device (GetOutputDeviceUID) - a status flip on
+// some other device is handleAudioDeviceChange's/handleMidiDeviceChange's/
+// failoverOutputDevice's concern, not this one's.
+func (e *Engine) handleDeviceStatusChanged(uid string, isOnline bool) {
+	e.handleChannelDeviceStatusChanged(uid, isOnline)
+
+	if uid != e.GetOutputDeviceUID() {
+		return
+	}
+
+	if isOnline {
+		e.recoverFromDeviceLoss(uid)
+		return
+	}
+
+	e.deviceLossMu.Lock()
+	e.lostDeviceUID = uid
+	e.deviceLossMu.Unlock()
+
+	e.dispatcher.emitEvent(DispatcherEvent{Type: EventDeviceLost, Path: uid})
+	e.setLifecycleState(StateInterrupted, fmt.Errorf("output device %s went offline", uid))
+
+	switch e.onDeviceLost {
+	case StopEngine:
+		go func() {
+			if err := e.Stop(); err != nil {
+				e.errorHandler.HandleError(fmt.Errorf("stopping engine after output device %s went offline: %w", uid, err))
+			}
+		}()
+	case FallbackToDefault:
+		e.activateFallback(uid)
+	case WaitForReconnect:
+		e.armReconnectTimer(uid)
+	}
+}
+
+// recoverFromDeviceLoss cancels any pending reconnect timer and, if uid is
+// the device this monitor was waiting on, emits EventDeviceRestored and
+// returns the engine to StateRunning - the counterpart to
+// handleDeviceStatusChanged's isOnline=false branch.
+func (e *Engine) recoverFromDeviceLoss(uid string) {
+	e.deviceLossMu.Lock()
+	waiting := e.lostDeviceUID == uid
+	if waiting {
+		e.lostDeviceUID = ""
+	}
+	if e.reconnectTimer != nil {
+		e.reconnectTimer.Stop()
+		e.reconnectTimer = nil
+	}
+	e.deviceLossMu.Unlock()
+
+	if !waiting {
+		return
+	}
+
+	e.dispatcher.emitEvent(DispatcherEvent{Type: EventDeviceRestored, Path: uid})
+	e.mu.RLock()
+	running := e.isRunning
+	e.mu.RUnlock()
+	if running {
+		e.setLifecycleState(StateRunning, nil)
+	}
+}
+
+// armReconnectTimer schedules activateFallback to run after
+// e.deviceLossTimeout if uid hasn't come back online by then (zero means
+// wait indefinitely - no timer is armed at all). recoverFromDeviceLoss
+// cancels it early if uid reconnects first.
+func (e *Engine) armReconnectTimer(uid string) {
+	if e.deviceLossTimeout <= 0 {
+		return
+	}
+
+	timer := time.AfterFunc(e.deviceLossTimeout, func() {
+		e.deviceLossMu.Lock()
+		stillLost := e.lostDeviceUID == uid
+		e.deviceLossMu.Unlock()
+		if stillLost {
+			e.activateFallback(uid)
+		}
+	})
+
+	e.deviceLossMu.Lock()
+	e.reconnectTimer = timer
+	e.deviceLossMu.Unlock()
+}
+
+// activateFallback reroutes the engine's primary output off lostUID onto
+// the first online, output-capable device in e.preferredDeviceUIDs, or
+// firstOnlineOutput's pick if none of them are online, via the same
+// Dispatcher.ChangeOutputDevice path a caller-initiated device switch uses.
+func (e *Engine) activateFallback(lostUID string) {
+	fallback, err := e.pickFallbackDevice(lostUID)
+	if err != nil {
+		e.errorHandler.HandleError(fmt.Errorf("output device %s went offline and no fallback could be found: %w", lostUID, err))
+		return
+	}
+
+	if err := e.dispatcher.ChangeOutputDevice(fallback); err != nil {
+		e.errorHandler.HandleError(fmt.Errorf("output device %s went offline, failover to %s failed: %w", lostUID, fallback, err))
+		return
+	}
+
+	e.deviceLossMu.Lock()
+	e.lostDeviceUID = ""
+	if e.reconnectTimer != nil {
+		e.reconnectTimer.Stop()
+		e.reconnectTimer = nil
+	}
+	e.deviceLossMu.Unlock()
+
+	e.dispatcher.emitEvent(DispatcherEvent{Type: EventFallbackActivated, Path: fallback})
+	e.setLifecycleState(StateRunning, nil)
+}
+
+// pickFallbackDevice returns the first online, output-capable UID in
+// e.preferredDeviceUIDs other than excludeUID, falling back to
+// firstOnlineOutput when none of them qualify.
+func (e *Engine) pickFallbackDevice(excludeUID string) (string, error) {
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		return "", err
+	}
+	online := audioDevices.Online()
+	for _, uid := range e.preferredDeviceUIDs {
+		if uid == excludeUID {
+			continue
+		}
+		for _, d := range online {
+			if d.UID == uid && d.CanOutput() {
+				return uid, nil
+			}
+		}
+	}
+	return firstOnlineOutput(excludeUID)
+}
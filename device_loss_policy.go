@@ -0,0 +1,41 @@
+package macaudio
+
+// DeviceLossPolicy controls how Engine reacts when its primary output
+// route's device reports going offline (kAudioDevicePropertyDeviceIsAlive
+// flipping false, surfaced through DeviceMonitor.onDeviceStatusChanged)
+// without necessarily being unplugged outright - see
+// EngineConfig.OnDeviceLost, Engine.handleDeviceStatusChanged.
+type DeviceLossPolicy int
+
+const (
+	// StopEngine tears the engine down the same way Stop does, leaving
+	// reconnection to the caller. The safest default for anything writing
+	// to disk, where continuing to run against a zombie device risks
+	// silently recording silence.
+	StopEngine DeviceLossPolicy = iota
+	// FallbackToDefault immediately reroutes the primary output to the
+	// first online, output-capable device in EngineConfig.PreferredDeviceUIDs,
+	// falling back to firstOnlineOutput's default-output-or-first-online
+	// pick if none of them are online.
+	FallbackToDefault
+	// WaitForReconnect holds the engine in StateInterrupted, still bound to
+	// the lost device, for up to EngineConfig.DeviceLossTimeout (or
+	// indefinitely if zero) in case the same device comes back online - a
+	// laptop lid closing and reopening with the same USB interface, say.
+	// If it doesn't reconnect in time, this falls back exactly like
+	// FallbackToDefault.
+	WaitForReconnect
+)
+
+// String renders a DeviceLossPolicy the way a log line or a /status OSC
+// reply wants it.
+func (p DeviceLossPolicy) String() string {
+	switch p {
+	case FallbackToDefault:
+		return "fallback_to_default"
+	case WaitForReconnect:
+		return "wait_for_reconnect"
+	default:
+		return "stop_engine"
+	}
+}
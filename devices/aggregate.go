@@ -0,0 +1,362 @@
+//go:build darwin && cgo
+
+package devices
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation -framework CoreAudio -framework AudioToolbox -framework CoreMIDI -framework AVFoundation
+#include "native/devices.m"
+#include <stdlib.h>
+
+// Function declarations
+char* createAggregateDevice(const char* name, const char* subDeviceUIDsJSON);
+char* createAggregateDeviceWithSpec(const char* specJSON);
+char* destroyAggregateDevice(const char* uid);
+char* setAggregateDriftCompensation(const char* aggregateUID, const char* subDeviceUID, int on);
+*/
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// AggregateDeviceResult represents the result of creating or destroying an
+// aggregate device, matching the success/error envelope the rest of this
+// package's native calls use (see AudioDeviceResult, MIDIDeviceResult).
+type AggregateDeviceResult struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode int    `json:"errorCode,omitempty"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// CreateAggregateDevice asks CoreAudio (via AudioHardwareCreateAggregateDevice)
+// to compose subDeviceUIDs into a single aggregate device named name, and
+// returns the new aggregate's UID. Sub-devices are clock-synced to the first
+// UID in the list, matching CoreAudio's own "master device" convention for
+// aggregates. Callers are responsible for calling DestroyAggregateDevice once
+// the aggregate is no longer routed to, to avoid leaking a device CoreAudio
+// keeps listed until the process exits.
+func CreateAggregateDevice(name string, subDeviceUIDs []string) (string, error) {
+	if len(subDeviceUIDs) < 2 {
+		return "", fmt.Errorf("aggregate device needs at least 2 sub-devices, got %d", len(subDeviceUIDs))
+	}
+
+	uidsJSON, err := json.Marshal(subDeviceUIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode sub-device UIDs: %w", err)
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cUIDs := C.CString(string(uidsJSON))
+	defer C.free(unsafe.Pointer(cUIDs))
+
+	result := C.createAggregateDevice(cName, cUIDs)
+	defer C.free(unsafe.Pointer(result))
+
+	var aggResult AggregateDeviceResult
+	if err := json.Unmarshal([]byte(C.GoString(result)), &aggResult); err != nil {
+		return "", fmt.Errorf("failed to parse aggregate device result: %w", err)
+	}
+
+	if !aggResult.Success {
+		return "", fmt.Errorf("core audio error (%d): %s", aggResult.ErrorCode, aggResult.Error)
+	}
+
+	return aggResult.UID, nil
+}
+
+// DestroyAggregateDevice tears down an aggregate device previously created
+// with CreateAggregateDevice. It is not an error to destroy an aggregate
+// whose sub-devices have since gone offline.
+func DestroyAggregateDevice(uid string) error {
+	cUID := C.CString(uid)
+	defer C.free(unsafe.Pointer(cUID))
+
+	result := C.destroyAggregateDevice(cUID)
+	defer C.free(unsafe.Pointer(result))
+
+	var aggResult AggregateDeviceResult
+	if err := json.Unmarshal([]byte(C.GoString(result)), &aggResult); err != nil {
+		return fmt.Errorf("failed to parse aggregate device result: %w", err)
+	}
+
+	if !aggResult.Success {
+		return fmt.Errorf("core audio error (%d): %s", aggResult.ErrorCode, aggResult.Error)
+	}
+
+	return nil
+}
+
+// AggregateSpec configures a CoreAudio aggregate device to create via
+// CreateAggregate - a composite device combining several physical
+// interfaces (e.g. a USB input with the built-in output) into a single
+// stream AVAudioEngine can attach to, which otherwise requires one device.
+type AggregateSpec struct {
+	// UID requests a specific UID for the new aggregate (kAudioAggregateDeviceUIDKey);
+	// leave empty to let CoreAudio generate one.
+	UID string
+	// Name is the aggregate's display name.
+	Name string
+	// SubDeviceUIDs lists the physical devices to compose
+	// (kAudioAggregateDeviceSubDeviceListKey); at least 2 are required.
+	SubDeviceUIDs []string
+	// MasterUID names the sub-device whose clock drives the aggregate
+	// (kAudioAggregateDeviceMasterSubDeviceKey). Must be one of
+	// SubDeviceUIDs; defaults to the first sub-device if empty.
+	MasterUID string
+	// Private marks the aggregate as not appearing in other processes'
+	// device lists (kAudioAggregateDeviceIsPrivateKey).
+	Private bool
+	// DriftCompensate enables per-subdevice clock drift compensation
+	// (kAudioSubDeviceDriftCompensationKey), keyed by sub-device UID. A
+	// sub-device absent from this map is not drift-compensated.
+	DriftCompensate map[string]bool
+}
+
+// aggregateSpecJSON is the wire format createAggregateDeviceWithSpec expects.
+type aggregateSpecJSON struct {
+	UID             string          `json:"uid,omitempty"`
+	Name            string          `json:"name"`
+	SubDeviceUIDs   []string        `json:"subDeviceUIDs"`
+	MasterUID       string          `json:"masterUID,omitempty"`
+	Private         bool            `json:"private"`
+	DriftCompensate map[string]bool `json:"driftCompensate,omitempty"`
+}
+
+// aggregateRegistry tracks sub-device membership for aggregates created via
+// CreateAggregate, since CoreAudio's own device listing doesn't report it -
+// see AudioDevice.AggregateSubDeviceUIDs.
+var aggregateRegistry = struct {
+	mu    sync.Mutex
+	specs map[string]AggregateSpec
+}{specs: make(map[string]AggregateSpec)}
+
+// CreateAggregate creates a CoreAudio aggregate device from spec and returns
+// the resulting AudioDevice, as reported by a subsequent GetAudio() scan.
+// Unlike CreateAggregateDevice, it also plumbs through master sub-device
+// selection, privacy and per-subdevice drift compensation, and records
+// sub-device membership so it's visible via AudioDevice.AggregateSubDeviceUIDs
+// and AudioDevices.Aggregates().
+func CreateAggregate(spec AggregateSpec) (AudioDevice, error) {
+	if len(spec.SubDeviceUIDs) < 2 {
+		return AudioDevice{}, fmt.Errorf("aggregate device needs at least 2 sub-devices, got %d", len(spec.SubDeviceUIDs))
+	}
+	if spec.MasterUID != "" && !containsUID(spec.SubDeviceUIDs, spec.MasterUID) {
+		return AudioDevice{}, fmt.Errorf("master device %q is not among the aggregate's sub-devices", spec.MasterUID)
+	}
+
+	specJSON, err := json.Marshal(aggregateSpecJSON{
+		UID:             spec.UID,
+		Name:            spec.Name,
+		SubDeviceUIDs:   spec.SubDeviceUIDs,
+		MasterUID:       spec.MasterUID,
+		Private:         spec.Private,
+		DriftCompensate: spec.DriftCompensate,
+	})
+	if err != nil {
+		return AudioDevice{}, fmt.Errorf("failed to encode aggregate spec: %w", err)
+	}
+
+	cSpec := C.CString(string(specJSON))
+	defer C.free(unsafe.Pointer(cSpec))
+
+	result := C.createAggregateDeviceWithSpec(cSpec)
+	defer C.free(unsafe.Pointer(result))
+
+	var aggResult AggregateDeviceResult
+	if err := json.Unmarshal([]byte(C.GoString(result)), &aggResult); err != nil {
+		return AudioDevice{}, fmt.Errorf("failed to parse aggregate device result: %w", err)
+	}
+	if !aggResult.Success {
+		return AudioDevice{}, fmt.Errorf("core audio error (%d): %s", aggResult.ErrorCode, aggResult.Error)
+	}
+
+	aggregateRegistry.mu.Lock()
+	aggregateRegistry.specs[aggResult.UID] = spec
+	aggregateRegistry.mu.Unlock()
+
+	audioDevices, err := GetAudio()
+	if err != nil {
+		return AudioDevice{}, fmt.Errorf("aggregate %q was created but could not be looked up: %w", aggResult.UID, err)
+	}
+	device := audioDevices.ByUID(aggResult.UID)
+	if device == nil {
+		return AudioDevice{}, fmt.Errorf("aggregate %q was created but did not appear in the device list", aggResult.UID)
+	}
+	device.AggregateSubDeviceUIDs = append([]string(nil), spec.SubDeviceUIDs...)
+	return *device, nil
+}
+
+// DestroyAggregate tears down an aggregate device previously created with
+// CreateAggregate, forgetting its recorded sub-device membership. It is not
+// an error to destroy an aggregate whose sub-devices have since gone
+// offline.
+func DestroyAggregate(uid string) error {
+	if err := DestroyAggregateDevice(uid); err != nil {
+		return err
+	}
+
+	aggregateRegistry.mu.Lock()
+	delete(aggregateRegistry.specs, uid)
+	aggregateRegistry.mu.Unlock()
+	return nil
+}
+
+// SetAggregateDriftCompensation toggles drift compensation
+// (kAudioSubDeviceDriftCompensationKey) for one sub-device of an existing
+// aggregate, the post-creation counterpart to AggregateSpec.DriftCompensate.
+// aggregateUID must name an aggregate created via CreateAggregate and
+// subDeviceUID one of its recorded sub-devices.
+func SetAggregateDriftCompensation(aggregateUID, subDeviceUID string, on bool) error {
+	aggregateRegistry.mu.Lock()
+	spec, ok := aggregateRegistry.specs[aggregateUID]
+	aggregateRegistry.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("aggregate %q was not created via CreateAggregate", aggregateUID)
+	}
+	if !containsUID(spec.SubDeviceUIDs, subDeviceUID) {
+		return fmt.Errorf("sub-device %q is not part of aggregate %q", subDeviceUID, aggregateUID)
+	}
+
+	cAgg := C.CString(aggregateUID)
+	defer C.free(unsafe.Pointer(cAgg))
+	cSub := C.CString(subDeviceUID)
+	defer C.free(unsafe.Pointer(cSub))
+	var onFlag C.int
+	if on {
+		onFlag = 1
+	}
+
+	result := C.setAggregateDriftCompensation(cAgg, cSub, onFlag)
+	defer C.free(unsafe.Pointer(result))
+
+	var aggResult AggregateDeviceResult
+	if err := json.Unmarshal([]byte(C.GoString(result)), &aggResult); err != nil {
+		return fmt.Errorf("failed to parse drift compensation result: %w", err)
+	}
+	if !aggResult.Success {
+		return fmt.Errorf("core audio error (%d): %s", aggResult.ErrorCode, aggResult.Error)
+	}
+
+	aggregateRegistry.mu.Lock()
+	if spec.DriftCompensate == nil {
+		spec.DriftCompensate = make(map[string]bool)
+	}
+	spec.DriftCompensate[subDeviceUID] = on
+	aggregateRegistry.specs[aggregateUID] = spec
+	aggregateRegistry.mu.Unlock()
+
+	return nil
+}
+
+// containsUID reports whether uid appears in uids.
+func containsUID(uids []string, uid string) bool {
+	for _, u := range uids {
+		if u == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAggregateDevice composes subs into a single CoreAudio aggregate device
+// named name, the Go-idiomatic counterpart to CreateAggregate: it picks a
+// viable master format and master clock device itself instead of asking the
+// caller to work those out. It refuses if subs share no common sample rate
+// or bit depth (intersected pairwise via AudioDevice.CommonSampleRates/
+// CommonBitDepths), since CoreAudio would otherwise silently pick a format
+// that drops or resamples one of the sub-devices. The returned AudioDevice
+// is looked up fresh via GetAudio, so its InputChannelCount/
+// OutputChannelCount already reflect the combined channel counts CoreAudio
+// reports for the aggregate, with SubDevices set to subs for convenience.
+func NewAggregateDevice(name string, subs []*AudioDevice) (*AudioDevice, error) {
+	if len(subs) < 2 {
+		return nil, fmt.Errorf("aggregate device needs at least 2 sub-devices, got %d", len(subs))
+	}
+
+	if _, _, err := commonFormat(subs); err != nil {
+		return nil, err
+	}
+
+	uids := make([]string, len(subs))
+	for i, sub := range subs {
+		uids[i] = sub.UID
+	}
+
+	device, err := CreateAggregate(AggregateSpec{
+		Name:          name,
+		SubDeviceUIDs: uids,
+		MasterUID:     masterClockUID(subs),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	device.SubDevices = subs
+	return &device, nil
+}
+
+// commonFormat intersects sample rates and bit depths across all of subs,
+// pairwise against the first sub-device, and errors if either intersection
+// is empty - meaning CoreAudio has no single format every sub-device can
+// run.
+func commonFormat(subs []*AudioDevice) (sampleRates, bitDepths []int, err error) {
+	sampleRates, bitDepths = subs[0].SupportedSampleRates, subs[0].SupportedBitDepths
+	for _, sub := range subs[1:] {
+		sampleRates = intersectInts(sampleRates, sub.SupportedSampleRates)
+		bitDepths = intersectInts(bitDepths, sub.SupportedBitDepths)
+	}
+	if len(sampleRates) == 0 {
+		return nil, nil, fmt.Errorf("sub-devices share no common sample rate")
+	}
+	if len(bitDepths) == 0 {
+		return nil, nil, fmt.Errorf("sub-devices share no common bit depth")
+	}
+	return sampleRates, bitDepths, nil
+}
+
+// intersectInts returns the values present in both a and b, preserving a's
+// order - the same pairwise-intersection shape as AudioDevice.
+// CommonSampleRates/CommonBitDepths, generalized to plain slices since
+// commonFormat folds it across more than two devices.
+func intersectInts(a, b []int) []int {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	inB := make(map[int]bool, len(b))
+	for _, v := range b {
+		inB[v] = true
+	}
+	var common []int
+	for _, v := range a {
+		if inB[v] {
+			common = append(common, v)
+		}
+	}
+	return common
+}
+
+// masterClockUID picks the sub-device whose clock should drive the
+// aggregate: the first with HasHardwareClock set, falling back to the
+// first sub-device if none report one.
+func masterClockUID(subs []*AudioDevice) string {
+	for _, sub := range subs {
+		if sub.HasHardwareClock {
+			return sub.UID
+		}
+	}
+	return subs[0].UID
+}
+
+// Destroy tears down this aggregate device, as DestroyAggregate. It is an
+// error to call Destroy on a device that isn't an aggregate.
+func (a *AudioDevice) Destroy() error {
+	if !a.IsAggregate() {
+		return fmt.Errorf("device %q is not an aggregate device", a.UID)
+	}
+	return DestroyAggregate(a.UID)
+}
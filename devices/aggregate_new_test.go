@@ -0,0 +1,72 @@
+//go:build darwin && cgo
+
+package devices
+
+import "testing"
+
+func TestNewAggregateDeviceRejectsFewerThanTwoSubDevices(t *testing.T) {
+	one := &AudioDevice{Device: Device{UID: "a"}, SupportedSampleRates: []int{44100}}
+	if _, err := NewAggregateDevice("combo", []*AudioDevice{one}); err == nil {
+		t.Error("expected error for fewer than 2 sub-devices")
+	}
+}
+
+func TestCommonFormatRejectsDisjointSampleRates(t *testing.T) {
+	a := &AudioDevice{Device: Device{UID: "a"}, SupportedSampleRates: []int{44100}, SupportedBitDepths: []int{24}}
+	b := &AudioDevice{Device: Device{UID: "b"}, SupportedSampleRates: []int{48000}, SupportedBitDepths: []int{24}}
+
+	if _, _, err := commonFormat([]*AudioDevice{a, b}); err == nil {
+		t.Error("expected error for disjoint sample rates")
+	}
+}
+
+func TestCommonFormatRejectsDisjointBitDepths(t *testing.T) {
+	a := &AudioDevice{Device: Device{UID: "a"}, SupportedSampleRates: []int{44100}, SupportedBitDepths: []int{16}}
+	b := &AudioDevice{Device: Device{UID: "b"}, SupportedSampleRates: []int{44100}, SupportedBitDepths: []int{24}}
+
+	if _, _, err := commonFormat([]*AudioDevice{a, b}); err == nil {
+		t.Error("expected error for disjoint bit depths")
+	}
+}
+
+func TestCommonFormatIntersectsAcrossThreeDevices(t *testing.T) {
+	a := &AudioDevice{Device: Device{UID: "a"}, SupportedSampleRates: []int{44100, 48000, 96000}, SupportedBitDepths: []int{16, 24}}
+	b := &AudioDevice{Device: Device{UID: "b"}, SupportedSampleRates: []int{48000, 96000}, SupportedBitDepths: []int{24, 32}}
+	c := &AudioDevice{Device: Device{UID: "c"}, SupportedSampleRates: []int{48000}, SupportedBitDepths: []int{24}}
+
+	rates, depths, err := commonFormat([]*AudioDevice{a, b, c})
+	if err != nil {
+		t.Fatalf("commonFormat: %v", err)
+	}
+	if len(rates) != 1 || rates[0] != 48000 {
+		t.Errorf("expected [48000], got %v", rates)
+	}
+	if len(depths) != 1 || depths[0] != 24 {
+		t.Errorf("expected [24], got %v", depths)
+	}
+}
+
+func TestMasterClockUIDPrefersHardwareClock(t *testing.T) {
+	a := &AudioDevice{Device: Device{UID: "a"}}
+	b := &AudioDevice{Device: Device{UID: "b"}, HasHardwareClock: true}
+
+	if got := masterClockUID([]*AudioDevice{a, b}); got != "b" {
+		t.Errorf("expected %q, got %q", "b", got)
+	}
+}
+
+func TestMasterClockUIDFallsBackToFirst(t *testing.T) {
+	a := &AudioDevice{Device: Device{UID: "a"}}
+	b := &AudioDevice{Device: Device{UID: "b"}}
+
+	if got := masterClockUID([]*AudioDevice{a, b}); got != "a" {
+		t.Errorf("expected %q, got %q", "a", got)
+	}
+}
+
+func TestAudioDeviceDestroyRejectsNonAggregate(t *testing.T) {
+	d := &AudioDevice{Device: Device{UID: "usb-1"}, DeviceType: "usb"}
+	if err := d.Destroy(); err == nil {
+		t.Error("expected error destroying a non-aggregate device")
+	}
+}
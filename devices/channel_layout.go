@@ -0,0 +1,54 @@
+//go:build darwin && cgo
+
+package devices
+
+// ChannelLayoutTag names a standard CoreAudio channel layout, as reported
+// by kAudioUnitProperty_AudioChannelLayout - the same small set of tags
+// (Mono, Stereo, Quad, 5.1, 7.1, Ambisonic B-Format) plus "Discrete-N" for
+// anything CoreAudio can't describe more specifically.
+type ChannelLayoutTag string
+
+const (
+	ChannelLayoutMono             ChannelLayoutTag = "Mono"
+	ChannelLayoutStereo           ChannelLayoutTag = "Stereo"
+	ChannelLayoutQuad             ChannelLayoutTag = "Quad"
+	ChannelLayout51SMPTE          ChannelLayoutTag = "5.1 SMPTE"
+	ChannelLayout71               ChannelLayoutTag = "7.1"
+	ChannelLayoutAmbisonicBFormat ChannelLayoutTag = "Ambisonic B-Format"
+	ChannelLayoutDiscrete         ChannelLayoutTag = "Discrete"
+)
+
+// ChannelLayout describes one channel layout a device supports: a named
+// tag and the per-channel speaker labels in order (e.g. "L", "R", "C",
+// "LFE", "Ls", "Rs" for 5.1 SMPTE). Devices enumerated before this field
+// existed report no layouts.
+type ChannelLayout struct {
+	Tag    ChannelLayoutTag `json:"tag"`
+	Labels []string         `json:"labels"`
+}
+
+// ChannelCount returns the number of channels this layout describes.
+func (l ChannelLayout) ChannelCount() int {
+	return len(l.Labels)
+}
+
+// CommonChannelLayouts returns the layouts (matched by Tag) supported by
+// both a and other, alongside CommonSampleRates/CommonBitDepths.
+func (a AudioDevice) CommonChannelLayouts(other AudioDevice) []ChannelLayout {
+	if len(a.ChannelLayouts) == 0 || len(other.ChannelLayouts) == 0 {
+		return []ChannelLayout{}
+	}
+
+	otherTags := make(map[ChannelLayoutTag]bool, len(other.ChannelLayouts))
+	for _, l := range other.ChannelLayouts {
+		otherTags[l.Tag] = true
+	}
+
+	var common []ChannelLayout
+	for _, l := range a.ChannelLayouts {
+		if otherTags[l.Tag] {
+			common = append(common, l)
+		}
+	}
+	return common
+}
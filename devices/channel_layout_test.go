@@ -0,0 +1,36 @@
+//go:build darwin && cgo
+
+package devices
+
+import "testing"
+
+func TestCommonChannelLayoutsIntersectsByTag(t *testing.T) {
+	stereo := ChannelLayout{Tag: ChannelLayoutStereo, Labels: []string{"L", "R"}}
+	surround := ChannelLayout{Tag: ChannelLayout51SMPTE, Labels: []string{"L", "R", "C", "LFE", "Ls", "Rs"}}
+	quad := ChannelLayout{Tag: ChannelLayoutQuad, Labels: []string{"L", "R", "Ls", "Rs"}}
+
+	a := AudioDevice{Device: Device{UID: "a"}, ChannelLayouts: []ChannelLayout{stereo, surround}}
+	b := AudioDevice{Device: Device{UID: "b"}, ChannelLayouts: []ChannelLayout{stereo, quad}}
+
+	common := a.CommonChannelLayouts(b)
+	if len(common) != 1 || common[0].Tag != ChannelLayoutStereo {
+		t.Errorf("expected only Stereo in common, got %v", common)
+	}
+}
+
+func TestCommonChannelLayoutsEmptyWhenEitherDeviceReportsNone(t *testing.T) {
+	stereo := ChannelLayout{Tag: ChannelLayoutStereo, Labels: []string{"L", "R"}}
+	a := AudioDevice{Device: Device{UID: "a"}, ChannelLayouts: []ChannelLayout{stereo}}
+	b := AudioDevice{Device: Device{UID: "b"}}
+
+	if common := a.CommonChannelLayouts(b); len(common) != 0 {
+		t.Errorf("expected no common layouts, got %v", common)
+	}
+}
+
+func TestChannelLayoutChannelCount(t *testing.T) {
+	layout := ChannelLayout{Tag: ChannelLayout71, Labels: []string{"L", "R", "C", "LFE", "Ls", "Rs", "Lrs", "Rrs"}}
+	if got := layout.ChannelCount(); got != 8 {
+		t.Errorf("expected 8 channels, got %d", got)
+	}
+}
@@ -46,6 +46,48 @@ type AudioDevice struct {
 	SupportedBitDepths   []int  `json:"supportedBitDepths"`
 	DeviceType           string `json:"deviceType"`    // "builtin", "usb", "aggregate"
 	TransportType        string `json:"transportType"` // "usb", "firewire", "bluetooth"
+
+	// MinBufferFrameSize/MaxBufferFrameSize/PreferredBufferFrameSize mirror
+	// CoreAudio's kAudioDevicePropertyBufferFrameSizeRange and
+	// kAudioDevicePropertyBufferFrameSize. Devices enumerated before these
+	// fields existed report zero for all three; see SupportedBufferSizes
+	// for the fallback that applies in that case.
+	MinBufferFrameSize       int `json:"minBufferFrameSize,omitempty"`
+	MaxBufferFrameSize       int `json:"maxBufferFrameSize,omitempty"`
+	PreferredBufferFrameSize int `json:"preferredBufferFrameSize,omitempty"`
+
+	// AggregateSubDeviceUIDs lists the UIDs of the physical devices composing
+	// this device, and is only populated when DeviceType is "aggregate" (see
+	// CreateAggregate).
+	AggregateSubDeviceUIDs []string `json:"aggregateSubDeviceUIDs,omitempty"`
+
+	// HasHardwareClock reports whether the device derives its sample clock
+	// from its own hardware (true for most physical interfaces) rather than
+	// deriving it from the host or another device, e.g. some virtual/driver
+	// devices. NewAggregateDevice uses this to prefer a hardware-clocked
+	// sub-device as the aggregate's master. Devices enumerated before this
+	// field existed report false.
+	HasHardwareClock bool `json:"hasHardwareClock,omitempty"`
+
+	// SubDevices lists the physical devices composing this device, resolved
+	// to their full AudioDevice records. Only populated on the AudioDevice
+	// returned by NewAggregateDevice; a device looked up later via GetAudio
+	// carries AggregateSubDeviceUIDs but not this field; call SubDevices'
+	// UIDs against a fresh GetAudio scan to re-resolve it.
+	SubDevices []*AudioDevice `json:"-"`
+
+	// ChannelLayouts lists the channel layouts this device advertises via
+	// kAudioDevicePropertyPreferredChannelLayout /
+	// kAudioUnitProperty_AudioChannelLayout - see ChannelLayout and
+	// CommonChannelLayouts.
+	ChannelLayouts []ChannelLayout `json:"channelLayouts,omitempty"`
+}
+
+// IsAggregate reports whether this device is a CoreAudio aggregate device
+// (see CreateAggregate), as opposed to one of the physical devices it may
+// compose.
+func (a AudioDevice) IsAggregate() bool {
+	return a.DeviceType == "aggregate"
 }
 
 // Helper methods for capability checking
@@ -162,6 +204,16 @@ func (devices AudioDevices) Online() AudioDevices {
 	return onlineDevices
 }
 
+// ByUID returns the device with the given UID, or nil if none matches.
+func (devices AudioDevices) ByUID(uid string) *AudioDevice {
+	for i, device := range devices {
+		if device.UID == uid {
+			return &devices[i]
+		}
+	}
+	return nil
+}
+
 // ByType returns only devices of a specific type (e.g., "usb", "builtin", "bluetooth")
 func (devices AudioDevices) ByType(deviceType string) AudioDevices {
 	var filteredDevices AudioDevices
@@ -173,6 +225,18 @@ func (devices AudioDevices) ByType(deviceType string) AudioDevices {
 	return filteredDevices
 }
 
+// Aggregates returns only devices CoreAudio reports as aggregate devices
+// (see CreateAggregate), e.g. to list candidates for DestroyAggregate.
+func (devices AudioDevices) Aggregates() AudioDevices {
+	var aggregates AudioDevices
+	for _, device := range devices {
+		if device.IsAggregate() {
+			aggregates = append(aggregates, device)
+		}
+	}
+	return aggregates
+}
+
 // MIDIDevice represents a MIDI device with input/output capabilities
 type MIDIDevice struct {
 	Device                  // Embedded base device
@@ -280,6 +344,16 @@ func (devices MIDIDevices) Online() MIDIDevices {
 	return onlineDevices
 }
 
+// ByUID returns the MIDI device with the given UID, or nil if none matches.
+func (devices MIDIDevices) ByUID(uid string) *MIDIDevice {
+	for i, device := range devices {
+		if device.UID == uid {
+			return &devices[i]
+		}
+	}
+	return nil
+}
+
 // ByManufacturer returns only MIDI devices from a specific manufacturer
 func (devices MIDIDevices) ByManufacturer(manufacturer string) MIDIDevices {
 	var filteredDevices MIDIDevices
@@ -321,8 +395,39 @@ type AudioDeviceResult struct {
 	TotalDevicesScanned int           `json:"totalDevicesScanned"`
 }
 
+// AudioBackend abstracts where device enumeration comes from, so tests and
+// alternative hosts (see avaudio/engine.HostAPI) can supply devices without
+// touching CoreAudio. GetAudio delegates to the active backend, which
+// defaults to the real CoreAudio scan below.
+type AudioBackend interface {
+	GetAudio() (AudioDevices, error)
+}
+
+type coreAudioBackend struct{}
+
+func (coreAudioBackend) GetAudio() (AudioDevices, error) { return getAudioFromCoreAudio() }
+
+var activeBackend AudioBackend = coreAudioBackend{}
+
+// SetAudioBackend overrides the backend GetAudio delegates to. Pass nil to
+// restore the default CoreAudio backend. Intended for tests and for
+// offline/null avaudio/engine.HostAPI configurations that enumerate their
+// own canned devices instead of scanning real hardware.
+func SetAudioBackend(backend AudioBackend) {
+	if backend == nil {
+		backend = coreAudioBackend{}
+	}
+	activeBackend = backend
+}
+
 // GetAudio returns all audio devices with unified input/output capabilities
 func GetAudio() (AudioDevices, error) {
+	return activeBackend.GetAudio()
+}
+
+// getAudioFromCoreAudio performs the real CoreAudio device scan; it is the
+// implementation behind the default AudioBackend.
+func getAudioFromCoreAudio() (AudioDevices, error) {
 	result := C.getAudioDevices()
 	defer C.free(unsafe.Pointer(result))
 
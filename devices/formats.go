@@ -0,0 +1,113 @@
+//go:build darwin && cgo
+
+package devices
+
+// SupportedFormatRange describes one combination of sample-rate range,
+// channel count, and sample format a device can be opened with. It's the
+// cpal-style "supported format" tuple other APIs expose as a single
+// enumerated list; CoreAudio instead reports SupportedSampleRates and
+// SupportedBitDepths as independent lists, so SupportedFormats below
+// derives one SupportedFormatRange per channel-count/bit-depth pair.
+type SupportedFormatRange struct {
+	MinSampleRate float64
+	MaxSampleRate float64
+	ChannelCount  int
+	Interleaved   bool
+	SampleFormat  int // bits per sample, e.g. 16/24/32 - see SupportedBitDepths
+}
+
+// SupportedFormats enumerates the format combinations this device can be
+// opened with, spanning its full SupportedSampleRates range and crossing
+// every channel count (InputChannelCount, OutputChannelCount) it reports
+// with every bit depth in SupportedBitDepths. AVAudioEngine always runs
+// non-interleaved internally, so Interleaved is false throughout; it's
+// carried on SupportedFormatRange for callers building formats for a
+// lower-level API where interleaving matters. Returns nil if the device
+// reports no supported sample rates or no input/output channels.
+func (a AudioDevice) SupportedFormats() []SupportedFormatRange {
+	if len(a.SupportedSampleRates) == 0 {
+		return nil
+	}
+
+	minRate, maxRate := a.SupportedSampleRates[0], a.SupportedSampleRates[0]
+	for _, r := range a.SupportedSampleRates {
+		if r < minRate {
+			minRate = r
+		}
+		if r > maxRate {
+			maxRate = r
+		}
+	}
+
+	depths := a.SupportedBitDepths
+	if len(depths) == 0 {
+		depths = []int{32} // AVAudioEngine's internal format is always float32
+	}
+
+	var channelCounts []int
+	if a.InputChannelCount > 0 {
+		channelCounts = append(channelCounts, a.InputChannelCount)
+	}
+	if a.OutputChannelCount > 0 && a.OutputChannelCount != a.InputChannelCount {
+		channelCounts = append(channelCounts, a.OutputChannelCount)
+	}
+	if len(channelCounts) == 0 {
+		return nil
+	}
+
+	ranges := make([]SupportedFormatRange, 0, len(channelCounts)*len(depths))
+	for _, ch := range channelCounts {
+		for _, depth := range depths {
+			ranges = append(ranges, SupportedFormatRange{
+				MinSampleRate: float64(minRate),
+				MaxSampleRate: float64(maxRate),
+				ChannelCount:  ch,
+				Interleaved:   false,
+				SampleFormat:  depth,
+			})
+		}
+	}
+	return ranges
+}
+
+// defaultMinBufferFrameSize/defaultMaxBufferFrameSize/
+// defaultPreferredBufferFrameSize are the conservative stand-in
+// SupportedBufferSizes returns for a device enumerated before
+// MinBufferFrameSize/MaxBufferFrameSize/PreferredBufferFrameSize existed
+// (all three report zero in that case).
+const (
+	defaultMinBufferFrameSize       = 64
+	defaultMaxBufferFrameSize       = 8192
+	defaultPreferredBufferFrameSize = 256
+)
+
+// SupportedBufferSizes returns this device's advertised IO buffer frame
+// size range and preferred size, mirroring CoreAudio's
+// kAudioDevicePropertyBufferFrameSizeRange/kAudioDevicePropertyBufferFrameSize
+// - the same probe-before-committing step ALSA backends perform against a
+// device's period/buffer size range.
+func (a AudioDevice) SupportedBufferSizes() (min, max, preferred int) {
+	min, max, preferred = a.MinBufferFrameSize, a.MaxBufferFrameSize, a.PreferredBufferFrameSize
+	if min == 0 {
+		min = defaultMinBufferFrameSize
+	}
+	if max == 0 {
+		max = defaultMaxBufferFrameSize
+	}
+	if preferred == 0 {
+		preferred = defaultPreferredBufferFrameSize
+	}
+	return min, max, preferred
+}
+
+// SupportsFormat reports whether sampleRate and channelCount both fall
+// within some entry of SupportedFormats - sampleRate inside
+// [MinSampleRate, MaxSampleRate] and channelCount equal to ChannelCount.
+func (a AudioDevice) SupportsFormat(sampleRate float64, channelCount int) bool {
+	for _, r := range a.SupportedFormats() {
+		if sampleRate >= r.MinSampleRate && sampleRate <= r.MaxSampleRate && channelCount == r.ChannelCount {
+			return true
+		}
+	}
+	return false
+}
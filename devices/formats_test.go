@@ -0,0 +1,86 @@
+//go:build darwin && cgo
+
+package devices
+
+import "testing"
+
+func TestAudioDeviceSupportedFormats(t *testing.T) {
+	device := AudioDevice{
+		Device: Device{
+			Name:     "Test Interface",
+			UID:      "test-formats",
+			IsOnline: true,
+		},
+		SupportedSampleRates: []int{44100, 48000, 96000},
+		SupportedBitDepths:   []int{24, 32},
+		InputChannelCount:    2,
+		OutputChannelCount:   4,
+	}
+
+	ranges := device.SupportedFormats()
+	if len(ranges) != 4 {
+		t.Fatalf("expected 2 channel counts x 2 bit depths = 4 ranges, got %d: %+v", len(ranges), ranges)
+	}
+
+	for _, r := range ranges {
+		if r.MinSampleRate != 44100 || r.MaxSampleRate != 96000 {
+			t.Errorf("range %+v: expected sample-rate bounds [44100, 96000]", r)
+		}
+		if r.Interleaved {
+			t.Errorf("range %+v: expected Interleaved false", r)
+		}
+		if r.ChannelCount != 2 && r.ChannelCount != 4 {
+			t.Errorf("range %+v: unexpected channel count", r)
+		}
+		if r.SampleFormat != 24 && r.SampleFormat != 32 {
+			t.Errorf("range %+v: unexpected sample format", r)
+		}
+	}
+}
+
+func TestAudioDeviceSupportedFormatsNoSampleRates(t *testing.T) {
+	device := AudioDevice{InputChannelCount: 2}
+	if ranges := device.SupportedFormats(); ranges != nil {
+		t.Fatalf("expected nil ranges with no supported sample rates, got %+v", ranges)
+	}
+}
+
+func TestAudioDeviceSupportsFormat(t *testing.T) {
+	device := AudioDevice{
+		SupportedSampleRates: []int{44100, 48000, 96000},
+		SupportedBitDepths:   []int{24, 32},
+		InputChannelCount:    2,
+	}
+
+	if !device.SupportsFormat(48000, 2) {
+		t.Error("expected 48000Hz/2ch to be supported")
+	}
+	if device.SupportsFormat(48000, 6) {
+		t.Error("expected 48000Hz/6ch to be unsupported (no such channel count)")
+	}
+	if device.SupportsFormat(192000, 2) {
+		t.Error("expected 192000Hz/2ch to be unsupported (outside sample-rate range)")
+	}
+}
+
+func TestAudioDeviceSupportedBufferSizes(t *testing.T) {
+	device := AudioDevice{
+		MinBufferFrameSize:       32,
+		MaxBufferFrameSize:       4096,
+		PreferredBufferFrameSize: 512,
+	}
+
+	min, max, preferred := device.SupportedBufferSizes()
+	if min != 32 || max != 4096 || preferred != 512 {
+		t.Errorf("got (%d, %d, %d), want (32, 4096, 512)", min, max, preferred)
+	}
+}
+
+func TestAudioDeviceSupportedBufferSizesFallback(t *testing.T) {
+	device := AudioDevice{}
+
+	min, max, preferred := device.SupportedBufferSizes()
+	if min != defaultMinBufferFrameSize || max != defaultMaxBufferFrameSize || preferred != defaultPreferredBufferFrameSize {
+		t.Errorf("got (%d, %d, %d), want defaults (%d, %d, %d)", min, max, preferred, defaultMinBufferFrameSize, defaultMaxBufferFrameSize, defaultPreferredBufferFrameSize)
+	}
+}
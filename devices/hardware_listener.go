@@ -0,0 +1,99 @@
+//go:build darwin && cgo
+
+package devices
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation -framework CoreAudio -framework AudioToolbox -framework CoreMIDI -framework AVFoundation
+#include "native/devices.m"
+#include <stdlib.h>
+
+// Declared here; implemented in native/devices.m once an
+// AudioObjectAddPropertyListener binding covering
+// kAudioHardwarePropertyDevices, kAudioHardwarePropertyDefaultInputDevice,
+// kAudioHardwarePropertyDefaultOutputDevice, and the per-device
+// kAudioDevicePropertyDeviceIsAlive, kAudioDevicePropertyNominalSampleRate,
+// and kAudioDevicePropertyStreamConfiguration listeners - exists, wired to
+// a //export trampoline that calls back into Go (see WatchHardwareChanges's
+// doc comment). handle is an opaque token the eventual trampoline passes
+// back so the Go side can route the notification to the right watcher.
+const char* hardware_listener_install(uintptr_t handle);
+const char* hardware_listener_remove(uintptr_t handle);
+*/
+import "C"
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var hardwareListenerHandleCounter uint64
+
+// hardwareListenerRegistry tracks WatchHardwareChanges callbacks and the
+// single native listener installed on their behalf, mirroring
+// notificationRegistry in avaudio/engine/notifications.go.
+type hardwareListenerRegistry struct {
+	mu        sync.Mutex
+	callbacks map[uint64]func()
+	installed bool
+}
+
+var hwListeners = &hardwareListenerRegistry{callbacks: make(map[uint64]func())}
+
+// WatchHardwareChanges registers fn to be called whenever CoreAudio posts a
+// kAudioHardwarePropertyDevices notification (a device plugged or
+// unplugged), a default-input/default-output device change, or a
+// per-device kAudioDevicePropertyDeviceIsAlive/NominalSampleRate/
+// StreamConfiguration notification, installing the native property
+// listener the first time any watcher is registered. It returns an
+// uninstall func that unregisters fn, removing the native listener once the
+// last watcher is gone.
+//
+// fn is a plain notifier rather than a typed event - the caller (e.g.
+// Session's monitorDevices) re-polls GetAudio/GetMIDI on notification to
+// work out what actually changed, the same diffing devices.Subscribe's poll
+// loop already does. That keeps this listener a near-zero-latency wake-up
+// source instead of a second code path for change classification.
+//
+// The cgo trampoline the native listener needs to call back into Go isn't
+// wired up in this tree yet (see hardware_listener_install's declaration
+// above) - like OnNotification in avaudio/engine, this installs cleanly and
+// fn is retained, but until that trampoline exists fn is never called, so a
+// caller must keep a polling fallback.
+func WatchHardwareChanges(fn func()) (func(), error) {
+	if fn == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	hwListeners.mu.Lock()
+	defer hwListeners.mu.Unlock()
+
+	id := atomic.AddUint64(&hardwareListenerHandleCounter, 1)
+
+	if !hwListeners.installed {
+		errorStr := C.hardware_listener_install(C.uintptr_t(id))
+		if errorStr != nil {
+			return nil, errors.New(C.GoString(errorStr))
+		}
+		hwListeners.installed = true
+	}
+	hwListeners.callbacks[id] = fn
+
+	var once sync.Once
+	uninstall := func() {
+		once.Do(func() {
+			hwListeners.mu.Lock()
+			delete(hwListeners.callbacks, id)
+			empty := len(hwListeners.callbacks) == 0
+			hwListeners.mu.Unlock()
+
+			if empty {
+				hwListeners.mu.Lock()
+				hwListeners.installed = false
+				hwListeners.mu.Unlock()
+				_ = C.hardware_listener_remove(C.uintptr_t(id))
+			}
+		})
+	}
+	return uninstall, nil
+}
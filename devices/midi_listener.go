@@ -0,0 +1,98 @@
+//go:build darwin && cgo
+
+package devices
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation -framework CoreAudio -framework AudioToolbox -framework CoreMIDI -framework AVFoundation
+#include "native/devices.m"
+#include <stdlib.h>
+
+// Declared here; implemented in native/devices.m once a MIDIClientCreate
+// client, with a MIDINotifyProc that reacts to kMIDIMsgObjectAdded/
+// kMIDIMsgObjectRemoved/kMIDIMsgPropertyChanged (and the setup-changed
+// kMIDIMsgSetupChanged umbrella notification CoreMIDI posts when several of
+// those fire together), is bridged through a //export trampoline into Go
+// (see WatchMIDIHardwareChanges's doc comment). handle is an opaque token
+// the eventual trampoline passes back so the Go side can route the
+// notification to the right watcher, mirroring hardware_listener_install's
+// handle in hardware_listener.go.
+const char* midi_listener_install(uintptr_t handle);
+const char* midi_listener_remove(uintptr_t handle);
+*/
+import "C"
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var midiListenerHandleCounter uint64
+
+// midiListenerRegistry tracks WatchMIDIHardwareChanges callbacks and the
+// single MIDIClientRef installed on their behalf, mirroring
+// hardwareListenerRegistry in hardware_listener.go.
+type midiListenerRegistry struct {
+	mu        sync.Mutex
+	callbacks map[uint64]func()
+	installed bool
+}
+
+var midiListeners = &midiListenerRegistry{callbacks: make(map[uint64]func())}
+
+// WatchMIDIHardwareChanges registers fn to be called whenever CoreMIDI's
+// MIDIClientCreate notify-block reports a device/entity/endpoint add,
+// remove, or property change (or the coalescing kMIDIMsgSetupChanged), so a
+// MIDI hotplug is observed the same near-zero-latency way
+// WatchHardwareChanges observes an audio hotplug. It installs the native
+// MIDIClientRef the first time any watcher is registered, and returns an
+// uninstall func that unregisters fn, disposing the client once the last
+// watcher is gone.
+//
+// fn is a plain notifier rather than a typed event - same rationale as
+// WatchHardwareChanges: the caller (devices.Subscribe's watch loop) re-polls
+// GetMIDI on notification and diffs by UID, rather than this package
+// decoding CoreMIDI's own notification struct into a second event shape.
+//
+// The cgo trampoline the native notify-block needs to call back into Go
+// isn't wired up in this tree yet (see midi_listener_install's declaration
+// above) - like WatchHardwareChanges, this installs cleanly and fn is
+// retained, but until that trampoline exists fn is never called, so a
+// caller must keep a polling fallback.
+func WatchMIDIHardwareChanges(fn func()) (func(), error) {
+	if fn == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	midiListeners.mu.Lock()
+	defer midiListeners.mu.Unlock()
+
+	id := atomic.AddUint64(&midiListenerHandleCounter, 1)
+
+	if !midiListeners.installed {
+		errorStr := C.midi_listener_install(C.uintptr_t(id))
+		if errorStr != nil {
+			return nil, errors.New(C.GoString(errorStr))
+		}
+		midiListeners.installed = true
+	}
+	midiListeners.callbacks[id] = fn
+
+	var once sync.Once
+	uninstall := func() {
+		once.Do(func() {
+			midiListeners.mu.Lock()
+			delete(midiListeners.callbacks, id)
+			empty := len(midiListeners.callbacks) == 0
+			midiListeners.mu.Unlock()
+
+			if empty {
+				midiListeners.mu.Lock()
+				midiListeners.installed = false
+				midiListeners.mu.Unlock()
+				_ = C.midi_listener_remove(C.uintptr_t(id))
+			}
+		})
+	}
+	return uninstall, nil
+}
@@ -0,0 +1,176 @@
+//go:build darwin && cgo
+
+package devices
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation -framework CoreAudio -framework AudioToolbox -framework CoreMIDI -framework AVFoundation
+#include "native/devices.m"
+#include <stdlib.h>
+
+// Function declarations
+char* listCapturableProcesses(void);
+char* processTapSupported(void);
+char* createProcessTap(const char* specJSON);
+char* destroyProcessTap(const char* uid);
+*/
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// CapturableProcess is one entry from ListCapturableProcesses: a running
+// process CoreAudio can route into a CATapDescription-based process tap
+// (see CreateProcessTap).
+type CapturableProcess struct {
+	PID      int32  `json:"pid"`
+	Name     string `json:"name"`
+	BundleID string `json:"bundleID"`
+}
+
+// capturableProcessResult is the wire envelope listCapturableProcesses
+// returns, matching the rest of this package's success/error convention.
+type capturableProcessResult struct {
+	Success   bool                `json:"success"`
+	Error     string              `json:"error,omitempty"`
+	ErrorCode int                 `json:"errorCode,omitempty"`
+	Processes []CapturableProcess `json:"processes,omitempty"`
+}
+
+// ListCapturableProcesses enumerates the running processes currently
+// producing audio (via kAudioHardwarePropertyProcessObjectList), each a
+// valid ProcessID for LoopbackConfig. Requires ProcessTapSupported; call it
+// first, since asking CoreAudio for this list on an unsupported OS version
+// just returns an empty, misleadingly-successful result.
+//
+// The first call in a process that actually creates a tap (CreateProcessTap)
+// triggers the same system audio-recording permission prompt as screen
+// recording/system audio capture elsewhere in macOS - listing processes
+// does not.
+func ListCapturableProcesses() ([]CapturableProcess, error) {
+	result := C.listCapturableProcesses()
+	defer C.free(unsafe.Pointer(result))
+
+	var procResult capturableProcessResult
+	if err := json.Unmarshal([]byte(C.GoString(result)), &procResult); err != nil {
+		return nil, fmt.Errorf("failed to parse capturable process result: %w", err)
+	}
+	if !procResult.Success {
+		return nil, fmt.Errorf("core audio error (%d): %s", procResult.ErrorCode, procResult.Error)
+	}
+	return procResult.Processes, nil
+}
+
+// ProcessTapSupported reports whether the running OS exposes the Core Audio
+// process-tap APIs (CATapDescription, macOS 14.4+) CreateProcessTap needs.
+// On an older system, capture a specific process's output (or the full
+// system mix) by routing a virtual loopback device instead - see
+// FindVirtualLoopbackDevice.
+func ProcessTapSupported() bool {
+	result := C.processTapSupported()
+	defer C.free(unsafe.Pointer(result))
+	return C.GoString(result) == "true"
+}
+
+// ProcessTapResult is the wire envelope createProcessTap/destroyProcessTap
+// return, matching AggregateDeviceResult's shape.
+type ProcessTapResult struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode int    `json:"errorCode,omitempty"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// processTapSpec is the wire format createProcessTap expects.
+type processTapSpec struct {
+	ProcessID int32  `json:"processID,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// CreateProcessTap creates a CATapDescription-backed process tap for pid
+// and returns a private aggregate device UID wrapping it, suitable for use
+// as LoopbackConfig's backing device. Pass pid 0 to tap the full system
+// mix instead of a single process. Callers are responsible for calling
+// DestroyProcessTap once the tap is no longer routed to, matching
+// CreateAggregate's cleanup contract.
+//
+// Returns an error without attempting the native call if ProcessTapSupported
+// is false, since CATapDescription doesn't exist before macOS 14.4.
+func CreateProcessTap(pid int32) (string, error) {
+	if !ProcessTapSupported() {
+		return "", fmt.Errorf("process tap capture requires macOS 14.4 or later; use a virtual loopback device instead (see FindVirtualLoopbackDevice)")
+	}
+
+	specJSON, err := json.Marshal(processTapSpec{ProcessID: pid})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode process tap spec: %w", err)
+	}
+
+	cSpec := C.CString(string(specJSON))
+	defer C.free(unsafe.Pointer(cSpec))
+
+	result := C.createProcessTap(cSpec)
+	defer C.free(unsafe.Pointer(result))
+
+	var tapResult ProcessTapResult
+	if err := json.Unmarshal([]byte(C.GoString(result)), &tapResult); err != nil {
+		return "", fmt.Errorf("failed to parse process tap result: %w", err)
+	}
+	if !tapResult.Success {
+		return "", fmt.Errorf("core audio error (%d): %s", tapResult.ErrorCode, tapResult.Error)
+	}
+	return tapResult.UID, nil
+}
+
+// DestroyProcessTap tears down a process tap previously created with
+// CreateProcessTap. It is not an error to destroy a tap whose process has
+// since exited.
+func DestroyProcessTap(uid string) error {
+	cUID := C.CString(uid)
+	defer C.free(unsafe.Pointer(cUID))
+
+	result := C.destroyProcessTap(cUID)
+	defer C.free(unsafe.Pointer(result))
+
+	var tapResult ProcessTapResult
+	if err := json.Unmarshal([]byte(C.GoString(result)), &tapResult); err != nil {
+		return fmt.Errorf("failed to parse process tap result: %w", err)
+	}
+	if !tapResult.Success {
+		return fmt.Errorf("core audio error (%d): %s", tapResult.ErrorCode, tapResult.Error)
+	}
+	return nil
+}
+
+// knownVirtualLoopbackNames lists the driver names FindVirtualLoopbackDevice
+// matches against, case-insensitively, substring-wise. Not exhaustive -
+// callers who already know their device's UID should skip this helper and
+// pass it directly to LoopbackConfig.SubmixUID.
+var knownVirtualLoopbackNames = []string{"blackhole", "soundflower", "loopback"}
+
+// FindVirtualLoopbackDevice scans GetAudio for an installed virtual audio
+// driver (BlackHole, Soundflower, Rogue Amoeba Loopback, or similar) to use
+// as LoopbackConfig.SubmixUID on systems where ProcessTapSupported is
+// false. The user must have already installed the driver and configured
+// the app(s) they want to capture to output to it - this only finds it,
+// it doesn't install or route anything.
+func FindVirtualLoopbackDevice() (AudioDevice, error) {
+	audioDevices, err := GetAudio()
+	if err != nil {
+		return AudioDevice{}, fmt.Errorf("failed to enumerate audio devices: %w", err)
+	}
+
+	lowerName := func(d AudioDevice) string { return strings.ToLower(d.Name) }
+	for _, device := range audioDevices.Online().Inputs() {
+		name := lowerName(device)
+		for _, known := range knownVirtualLoopbackNames {
+			if strings.Contains(name, known) {
+				return device, nil
+			}
+		}
+	}
+	return AudioDevice{}, fmt.Errorf("no virtual loopback device found; install BlackHole or a similar driver and route the source app's output to it")
+}
@@ -0,0 +1,190 @@
+//go:build darwin && cgo
+
+package devices
+
+import "fmt"
+
+// SampleFormat is the bit width of one sample - the same numbers
+// SupportedBitDepths reports (16/24/32), named so StreamConfigRange doesn't
+// have to carry a bare []int alongside ChannelCounts.
+type SampleFormat int
+
+// StreamConfigRange describes the full range of stream configurations one
+// direction (input or output) of a device supports: any sample rate in
+// [MinSampleRate, MaxSampleRate] crossed with any buffer size in
+// [MinBufferFrames, MaxBufferFrames], any count in ChannelCounts, and any
+// format in SampleFormats. Where SupportedFormatRange enumerates one entry
+// per channel-count/bit-depth pair, a StreamConfigRange folds a direction's
+// entire envelope into a single struct - what NewEngine's upfront
+// validation wants to test a candidate (sampleRate, bufferSize) against,
+// rather than walking an enumerated list.
+type StreamConfigRange struct {
+	MinSampleRate   float64
+	MaxSampleRate   float64
+	MinBufferFrames int
+	MaxBufferFrames int
+	ChannelCounts   []int
+	SampleFormats   []SampleFormat
+}
+
+// Accepts reports whether sampleRate and bufferFrames both fall inside r.
+// sampleRate == 0 always passes - NewEngine's "use the device default"
+// sentinel, not an actual rate to check.
+func (r StreamConfigRange) Accepts(sampleRate float64, bufferFrames int) bool {
+	rateOK := sampleRate == 0 || (sampleRate >= r.MinSampleRate && sampleRate <= r.MaxSampleRate)
+	bufferOK := bufferFrames >= r.MinBufferFrames && bufferFrames <= r.MaxBufferFrames
+	return rateOK && bufferOK
+}
+
+// streamConfigRange builds the StreamConfigRange for one direction out of
+// the same CoreAudio-reported fields SupportedFormats/SupportedBufferSizes
+// already use, given that direction's channel count. Returns the zero value
+// if channelCount is zero (the device doesn't support that direction) or
+// the device reports no sample rates.
+func (a AudioDevice) streamConfigRange(channelCount int) StreamConfigRange {
+	if channelCount <= 0 || len(a.SupportedSampleRates) == 0 {
+		return StreamConfigRange{}
+	}
+
+	minRate, maxRate := a.SupportedSampleRates[0], a.SupportedSampleRates[0]
+	for _, r := range a.SupportedSampleRates {
+		if r < minRate {
+			minRate = r
+		}
+		if r > maxRate {
+			maxRate = r
+		}
+	}
+
+	minBuffer, maxBuffer, _ := a.SupportedBufferSizes()
+
+	depths := a.SupportedBitDepths
+	if len(depths) == 0 {
+		depths = []int{32} // AVAudioEngine's internal format is always float32
+	}
+	formats := make([]SampleFormat, len(depths))
+	for i, d := range depths {
+		formats[i] = SampleFormat(d)
+	}
+
+	return StreamConfigRange{
+		MinSampleRate:   float64(minRate),
+		MaxSampleRate:   float64(maxRate),
+		MinBufferFrames: minBuffer,
+		MaxBufferFrames: maxBuffer,
+		ChannelCounts:   []int{channelCount},
+		SampleFormats:   formats,
+	}
+}
+
+// SupportedInputConfigs returns this device's supported input stream
+// configuration range (see StreamConfigRange), derived from
+// kAudioDevicePropertyStreamConfiguration's InputChannelCount and
+// kAudioDevicePropertyAvailableNominalSampleRates's SupportedSampleRates.
+// Returns nil if the device has no input channels.
+func (a AudioDevice) SupportedInputConfigs() []StreamConfigRange {
+	r := a.streamConfigRange(a.InputChannelCount)
+	if len(r.ChannelCounts) == 0 {
+		return nil
+	}
+	return []StreamConfigRange{r}
+}
+
+// SupportedOutputConfigs returns this device's supported output stream
+// configuration range (see StreamConfigRange), derived from
+// kAudioDevicePropertyStreamConfiguration's OutputChannelCount and
+// kAudioDevicePropertyAvailableNominalSampleRates's SupportedSampleRates.
+// Returns nil if the device has no output channels.
+func (a AudioDevice) SupportedOutputConfigs() []StreamConfigRange {
+	r := a.streamConfigRange(a.OutputChannelCount)
+	if len(r.ChannelCounts) == 0 {
+		return nil
+	}
+	return []StreamConfigRange{r}
+}
+
+// StreamConfig is one concrete (sampleRate, bufferFrames, channelCount,
+// sampleFormat) tuple, as opposed to StreamConfigRange's envelope of
+// everything a direction accepts - what DefaultOutputConfig picks and what
+// a caller hands straight to NewEngine.
+type StreamConfig struct {
+	SampleRate   float64
+	BufferFrames int
+	ChannelCount int
+	SampleFormat SampleFormat
+}
+
+// DefaultOutputConfig returns this device's preferred concrete output
+// configuration: its highest advertised sample rate, PreferredBufferFrameSize
+// (via SupportedBufferSizes' fallback), native OutputChannelCount, and
+// widest SampleFormat. Returns an error if the device has no output
+// channels or reports no supported sample rates.
+func (a AudioDevice) DefaultOutputConfig() (StreamConfig, error) {
+	configs := a.SupportedOutputConfigs()
+	if len(configs) == 0 {
+		return StreamConfig{}, fmt.Errorf("device %q has no output configuration", a.Name)
+	}
+
+	cfg := configs[0]
+	_, _, preferred := a.SupportedBufferSizes()
+
+	format := SampleFormat(32)
+	if len(cfg.SampleFormats) > 0 {
+		format = cfg.SampleFormats[len(cfg.SampleFormats)-1]
+	}
+
+	return StreamConfig{
+		SampleRate:   cfg.MaxSampleRate,
+		BufferFrames: preferred,
+		ChannelCount: a.OutputChannelCount,
+		SampleFormat: format,
+	}, nil
+}
+
+// DefaultInputConfig is DefaultOutputConfig's input-direction counterpart:
+// this device's preferred concrete input configuration. Returns an error if
+// the device has no input channels or reports no supported sample rates.
+func (a AudioDevice) DefaultInputConfig() (StreamConfig, error) {
+	configs := a.SupportedInputConfigs()
+	if len(configs) == 0 {
+		return StreamConfig{}, fmt.Errorf("device %q has no input configuration", a.Name)
+	}
+
+	cfg := configs[0]
+	_, _, preferred := a.SupportedBufferSizes()
+
+	format := SampleFormat(32)
+	if len(cfg.SampleFormats) > 0 {
+		format = cfg.SampleFormats[len(cfg.SampleFormats)-1]
+	}
+
+	return StreamConfig{
+		SampleRate:   cfg.MaxSampleRate,
+		BufferFrames: preferred,
+		ChannelCount: a.InputChannelCount,
+		SampleFormat: format,
+	}, nil
+}
+
+// Nearest clamps sampleRate and bufferFrames into r's supported envelope,
+// reporting whether either value had to move. A sampleRate of 0 passes
+// through unclamped, same as Accepts' "use the device default" sentinel.
+func (r StreamConfigRange) Nearest(sampleRate float64, bufferFrames int) (nearestRate float64, nearestBuffer int, adjusted bool) {
+	nearestRate = sampleRate
+	if sampleRate != 0 {
+		if sampleRate < r.MinSampleRate {
+			nearestRate = r.MinSampleRate
+		} else if sampleRate > r.MaxSampleRate {
+			nearestRate = r.MaxSampleRate
+		}
+	}
+
+	nearestBuffer = bufferFrames
+	if bufferFrames < r.MinBufferFrames {
+		nearestBuffer = r.MinBufferFrames
+	} else if bufferFrames > r.MaxBufferFrames {
+		nearestBuffer = r.MaxBufferFrames
+	}
+
+	return nearestRate, nearestBuffer, nearestRate != sampleRate || nearestBuffer != bufferFrames
+}
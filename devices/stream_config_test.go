@@ -0,0 +1,156 @@
+//go:build darwin && cgo
+
+package devices
+
+import "testing"
+
+func TestAudioDeviceSupportedInputOutputConfigs(t *testing.T) {
+	device := AudioDevice{
+		Device: Device{
+			Name:     "Test Interface",
+			UID:      "test-stream-config",
+			IsOnline: true,
+		},
+		SupportedSampleRates:     []int{44100, 48000, 96000},
+		SupportedBitDepths:       []int{24, 32},
+		InputChannelCount:        2,
+		OutputChannelCount:       4,
+		MinBufferFrameSize:       32,
+		MaxBufferFrameSize:       4096,
+		PreferredBufferFrameSize: 512,
+	}
+
+	inputs := device.SupportedInputConfigs()
+	if len(inputs) != 1 {
+		t.Fatalf("expected 1 input config, got %d: %+v", len(inputs), inputs)
+	}
+	in := inputs[0]
+	if in.MinSampleRate != 44100 || in.MaxSampleRate != 96000 {
+		t.Errorf("input config %+v: expected sample-rate bounds [44100, 96000]", in)
+	}
+	if in.MinBufferFrames != 32 || in.MaxBufferFrames != 4096 {
+		t.Errorf("input config %+v: expected buffer bounds [32, 4096]", in)
+	}
+	if len(in.ChannelCounts) != 1 || in.ChannelCounts[0] != 2 {
+		t.Errorf("input config %+v: expected ChannelCounts [2]", in)
+	}
+
+	outputs := device.SupportedOutputConfigs()
+	if len(outputs) != 1 {
+		t.Fatalf("expected 1 output config, got %d: %+v", len(outputs), outputs)
+	}
+	out := outputs[0]
+	if len(out.ChannelCounts) != 1 || out.ChannelCounts[0] != 4 {
+		t.Errorf("output config %+v: expected ChannelCounts [4]", out)
+	}
+	if len(out.SampleFormats) != 2 {
+		t.Errorf("output config %+v: expected 2 sample formats", out)
+	}
+}
+
+func TestAudioDeviceSupportedConfigsNoChannels(t *testing.T) {
+	device := AudioDevice{SupportedSampleRates: []int{48000}}
+	if configs := device.SupportedInputConfigs(); configs != nil {
+		t.Fatalf("expected nil input configs with no input channels, got %+v", configs)
+	}
+	if configs := device.SupportedOutputConfigs(); configs != nil {
+		t.Fatalf("expected nil output configs with no output channels, got %+v", configs)
+	}
+}
+
+func TestStreamConfigRangeAccepts(t *testing.T) {
+	r := StreamConfigRange{MinSampleRate: 44100, MaxSampleRate: 96000, MinBufferFrames: 64, MaxBufferFrames: 1024}
+
+	if !r.Accepts(48000, 256) {
+		t.Error("expected 48000Hz/256 frames to be accepted")
+	}
+	if r.Accepts(192000, 256) {
+		t.Error("expected 192000Hz to be rejected (outside sample-rate range)")
+	}
+	if r.Accepts(48000, 2048) {
+		t.Error("expected 2048 frames to be rejected (outside buffer range)")
+	}
+	if !r.Accepts(0, 256) {
+		t.Error("expected sampleRate=0 (device default sentinel) to always be accepted")
+	}
+}
+
+func TestAudioDeviceDefaultOutputConfig(t *testing.T) {
+	device := AudioDevice{
+		SupportedSampleRates:     []int{44100, 48000, 96000},
+		SupportedBitDepths:       []int{24, 32},
+		OutputChannelCount:       2,
+		PreferredBufferFrameSize: 512,
+	}
+
+	cfg, err := device.DefaultOutputConfig()
+	if err != nil {
+		t.Fatalf("DefaultOutputConfig: %v", err)
+	}
+	if cfg.SampleRate != 96000 {
+		t.Errorf("expected SampleRate 96000 (the highest advertised), got %v", cfg.SampleRate)
+	}
+	if cfg.BufferFrames != 512 {
+		t.Errorf("expected BufferFrames 512, got %d", cfg.BufferFrames)
+	}
+	if cfg.ChannelCount != 2 {
+		t.Errorf("expected ChannelCount 2, got %d", cfg.ChannelCount)
+	}
+	if cfg.SampleFormat != 32 {
+		t.Errorf("expected SampleFormat 32 (the widest), got %v", cfg.SampleFormat)
+	}
+}
+
+func TestAudioDeviceDefaultOutputConfigNoOutputChannels(t *testing.T) {
+	device := AudioDevice{SupportedSampleRates: []int{48000}}
+	if _, err := device.DefaultOutputConfig(); err == nil {
+		t.Fatal("expected an error for a device with no output channels")
+	}
+}
+
+func TestAudioDeviceDefaultInputConfig(t *testing.T) {
+	device := AudioDevice{
+		SupportedSampleRates:     []int{44100, 48000},
+		SupportedBitDepths:       []int{16, 24},
+		InputChannelCount:        1,
+		PreferredBufferFrameSize: 128,
+	}
+
+	cfg, err := device.DefaultInputConfig()
+	if err != nil {
+		t.Fatalf("DefaultInputConfig: %v", err)
+	}
+	if cfg.SampleRate != 48000 {
+		t.Errorf("expected SampleRate 48000 (the highest advertised), got %v", cfg.SampleRate)
+	}
+	if cfg.BufferFrames != 128 {
+		t.Errorf("expected BufferFrames 128, got %d", cfg.BufferFrames)
+	}
+	if cfg.ChannelCount != 1 {
+		t.Errorf("expected ChannelCount 1, got %d", cfg.ChannelCount)
+	}
+}
+
+func TestAudioDeviceDefaultInputConfigNoInputChannels(t *testing.T) {
+	device := AudioDevice{SupportedSampleRates: []int{48000}}
+	if _, err := device.DefaultInputConfig(); err == nil {
+		t.Fatal("expected an error for a device with no input channels")
+	}
+}
+
+func TestStreamConfigRangeNearest(t *testing.T) {
+	r := StreamConfigRange{MinSampleRate: 44100, MaxSampleRate: 96000, MinBufferFrames: 64, MaxBufferFrames: 1024}
+
+	if rate, buf, adjusted := r.Nearest(48000, 256); rate != 48000 || buf != 256 || adjusted {
+		t.Errorf("Nearest(48000, 256) = %v, %v, %v; want unchanged, not adjusted", rate, buf, adjusted)
+	}
+	if rate, _, adjusted := r.Nearest(192000, 256); rate != 96000 || !adjusted {
+		t.Errorf("Nearest(192000, 256) = %v, adjusted=%v; want clamped to 96000, adjusted", rate, adjusted)
+	}
+	if _, buf, adjusted := r.Nearest(48000, 2048); buf != 1024 || !adjusted {
+		t.Errorf("Nearest(48000, 2048) = buf %v, adjusted=%v; want clamped to 1024, adjusted", buf, adjusted)
+	}
+	if rate, _, adjusted := r.Nearest(0, 256); rate != 0 || adjusted {
+		t.Errorf("Nearest(0, 256) = %v, adjusted=%v; want sentinel passed through unchanged", rate, adjusted)
+	}
+}
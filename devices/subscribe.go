@@ -0,0 +1,364 @@
+//go:build darwin && cgo
+
+package devices
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DeviceChangeKind identifies what changed in a DeviceChangeEvent.
+type DeviceChangeKind string
+
+const (
+	DeviceAdded           DeviceChangeKind = "added"
+	DeviceRemoved         DeviceChangeKind = "removed"
+	DeviceDefaultChanged  DeviceChangeKind = "default_changed"
+	DevicePropertyChanged DeviceChangeKind = "property_changed"
+	// DeviceAliveChanged fires when a device's Device.IsOnline flips -
+	// CoreAudio's kAudioDevicePropertyDeviceIsAlive going false without the
+	// device actually dropping out of GetAudio's enumeration yet (a USB
+	// interface losing power but still claiming its endpoint, for
+	// instance). It's reported separately from DevicePropertyChanged so a
+	// consumer that only cares about "is this device still usable" (e.g.
+	// session auto-rebinding away from it) doesn't have to diff every field
+	// itself to notice.
+	DeviceAliveChanged DeviceChangeKind = "alive_changed"
+)
+
+// DeviceChangeEvent describes one hot-plug or configuration change observed
+// by Subscribe. Exactly one of Audio or MIDI is set, matching which kind of
+// device the change concerns. Prev/Curr are only set alongside
+// DeviceDefaultChanged/DevicePropertyChanged, carrying the audio device's
+// state before and after the change - for DeviceAdded/DeviceRemoved, Audio
+// or MIDI itself is the whole story. DefaultKind is only set alongside
+// DeviceDefaultChanged, naming which default role (input or output) moved.
+type DeviceChangeEvent struct {
+	Kind        DeviceChangeKind
+	Audio       *AudioDevice
+	MIDI        *MIDIDevice
+	Prev        *AudioDevice
+	Curr        *AudioDevice
+	DefaultKind DefaultDeviceKind
+}
+
+// DefaultDeviceKind names which default device role a DeviceDefaultChanged
+// event concerns.
+type DefaultDeviceKind string
+
+const (
+	DefaultDeviceInput  DefaultDeviceKind = "input"
+	DefaultDeviceOutput DefaultDeviceKind = "output"
+)
+
+// coalesceWindow is Subscribe's debounce window and - when UsePolling is
+// set, or when neither WatchHardwareChanges nor WatchMIDIHardwareChanges
+// could be installed - its poll interval too: every device added/removed/
+// changed since the last tick is collapsed into one batch of events rather
+// than one poll per device, so plugging in a dock with several interfaces
+// at once doesn't fire a storm of events a consumer has to debounce itself.
+const coalesceWindow = 50 * time.Millisecond
+
+// fallbackPollInterval is run()'s tick rate once the CoreAudio/CoreMIDI
+// property listeners are installed: changes are expected to arrive almost
+// immediately via the wake channel they feed, so the ticker only needs to
+// catch whatever notification a listener missed, not carry the whole
+// detection burden the way it does under UsePolling.
+const fallbackPollInterval = 2 * time.Second
+
+// usePolling is toggled by SetUsePolling. false (the default) has run()
+// install WatchHardwareChanges/WatchMIDIHardwareChanges and poll only as a
+// slow (fallbackPollInterval) backstop for whatever notification a listener
+// misses; true skips installing listeners and polls every coalesceWindow
+// instead, for headless/CI environments where the native property listener
+// can't be installed (or its result shouldn't be trusted).
+var usePolling atomic.Bool
+
+// SetUsePolling switches devices.Subscribe's process-wide watch loop
+// between listener-driven (the default, near-zero hotplug latency via
+// WatchHardwareChanges/WatchMIDIHardwareChanges) and pure polling at
+// coalesceWindow. It only takes effect for a watch loop started after the
+// call - toggling it while Subscribe already has active subscribers doesn't
+// reinstall or remove the current loop's listeners.
+func SetUsePolling(v bool) {
+	usePolling.Store(v)
+}
+
+// deviceWatch is the process-wide watch loop backing Subscribe. By default
+// it installs WatchHardwareChanges/WatchMIDIHardwareChanges and treats their
+// wake-ups as the primary signal, falling back to polling GetAudio/GetMIDI
+// on fallbackPollInterval for whatever a listener misses (or if neither
+// could be installed at all, e.g. the native trampoline isn't wired up -
+// see hardware_listener.go/midi_listener.go); SetUsePolling(true) reverts to
+// polling GetAudio/GetMIDI on coalesceWindow unconditionally, diffing by UID
+// the same way either path would.
+type deviceWatchState struct {
+	mu      sync.Mutex
+	subs    map[int]chan DeviceChangeEvent
+	nextID  int
+	started bool
+	stop    chan struct{}
+	wake    chan struct{}
+
+	lastAudio         map[string]AudioDevice
+	lastMIDI          map[string]MIDIDevice
+	lastDefaultInput  string
+	lastDefaultOutput string
+}
+
+var deviceWatch = &deviceWatchState{subs: make(map[int]chan DeviceChangeEvent)}
+
+// signalWake wakes run() for an immediate poll, without blocking the
+// native listener thread on a full wake channel - mirrors
+// Session.signalHardwareEvent's non-blocking send.
+func (w *deviceWatchState) signalWake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe registers for hot-plug and configuration change notifications,
+// returning a channel of events and a cancel function that unregisters it.
+// The channel is buffered; a slow consumer misses events rather than
+// stalling the shared poll, matching Dispatcher.emitEvent's policy. The
+// background poll starts on the first call and stops once the last
+// subscriber cancels.
+func Subscribe() (<-chan DeviceChangeEvent, func()) {
+	deviceWatch.mu.Lock()
+	defer deviceWatch.mu.Unlock()
+
+	ch := make(chan DeviceChangeEvent, 32)
+	id := deviceWatch.nextID
+	deviceWatch.nextID++
+	deviceWatch.subs[id] = ch
+
+	if !deviceWatch.started {
+		deviceWatch.started = true
+		deviceWatch.stop = make(chan struct{})
+		deviceWatch.wake = make(chan struct{}, 1)
+		go deviceWatch.run()
+	}
+
+	var cancelOnce sync.Once
+	cancel := func() {
+		cancelOnce.Do(func() {
+			deviceWatch.mu.Lock()
+			delete(deviceWatch.subs, id)
+			close(ch)
+			if len(deviceWatch.subs) == 0 && deviceWatch.started {
+				deviceWatch.started = false
+				close(deviceWatch.stop)
+			}
+			deviceWatch.mu.Unlock()
+		})
+	}
+	return ch, cancel
+}
+
+func (w *deviceWatchState) run() {
+	w.mu.Lock()
+	stop := w.stop
+	wake := w.wake
+	w.mu.Unlock()
+
+	if usePolling.Load() {
+		ticker := time.NewTicker(coalesceWindow)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.poll()
+			}
+		}
+	}
+
+	var uninstallHW, uninstallMIDI func()
+	if fn, err := WatchHardwareChanges(w.signalWake); err == nil {
+		uninstallHW = fn
+	}
+	if fn, err := WatchMIDIHardwareChanges(w.signalWake); err == nil {
+		uninstallMIDI = fn
+	}
+	defer func() {
+		if uninstallHW != nil {
+			uninstallHW()
+		}
+		if uninstallMIDI != nil {
+			uninstallMIDI()
+		}
+	}()
+
+	// fallbackPollInterval backstops whatever the listeners installed above
+	// miss - right now that's everything, since their cgo trampoline isn't
+	// wired up yet (see hardware_listener.go/midi_listener.go), so this
+	// ticker is effectively what drives polling until that trampoline
+	// exists and w.wake starts firing on its own.
+	ticker := time.NewTicker(fallbackPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-wake:
+			w.poll()
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll diffs the current audio/MIDI device sets against what the previous
+// tick saw and broadcasts every DeviceChangeEvent that falls out of it.
+func (w *deviceWatchState) poll() {
+	var events []DeviceChangeEvent
+
+	if audioDevices, err := GetAudio(); err == nil {
+		events = append(events, w.diffAudio(audioDevices)...)
+	}
+	if midiDevices, err := GetMIDI(); err == nil {
+		events = append(events, w.diffMIDI(midiDevices)...)
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	subs := make([]chan DeviceChangeEvent, 0, len(w.subs))
+	for _, ch := range w.subs {
+		subs = append(subs, ch)
+	}
+	w.mu.Unlock()
+
+	for _, ev := range events {
+		for _, ch := range subs {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+func (w *deviceWatchState) diffAudio(current AudioDevices) []DeviceChangeEvent {
+	byUID := make(map[string]AudioDevice, len(current))
+	for _, d := range current {
+		byUID[d.UID] = d
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.lastAudio == nil {
+		w.lastAudio = byUID
+		w.lastDefaultInput, w.lastDefaultOutput = defaultUIDs(current)
+		return nil
+	}
+
+	var events []DeviceChangeEvent
+	for uid, d := range byUID {
+		d := d
+		if _, ok := w.lastAudio[uid]; !ok {
+			events = append(events, DeviceChangeEvent{Kind: DeviceAdded, Audio: &d})
+			continue
+		}
+		prev := w.lastAudio[uid]
+		if !reflect.DeepEqual(prev, d) {
+			prevCopy, currCopy := prev, d
+			kind := DevicePropertyChanged
+			if prev.IsOnline != d.IsOnline {
+				kind = DeviceAliveChanged
+			}
+			events = append(events, DeviceChangeEvent{
+				Kind: kind, Audio: &d, Prev: &prevCopy, Curr: &currCopy,
+			})
+		}
+	}
+	for uid, d := range w.lastAudio {
+		d := d
+		if _, ok := byUID[uid]; !ok {
+			events = append(events, DeviceChangeEvent{Kind: DeviceRemoved, Audio: &d})
+		}
+	}
+	w.lastAudio = byUID
+
+	defaultInput, defaultOutput := defaultUIDs(current)
+	if defaultInput != w.lastDefaultInput {
+		prev := findAudioByUID(current, w.lastDefaultInput)
+		curr := findAudioByUID(current, defaultInput)
+		events = append(events, DeviceChangeEvent{Kind: DeviceDefaultChanged, Prev: prev, Curr: curr, DefaultKind: DefaultDeviceInput})
+		w.lastDefaultInput = defaultInput
+	}
+	if defaultOutput != w.lastDefaultOutput {
+		prev := findAudioByUID(current, w.lastDefaultOutput)
+		curr := findAudioByUID(current, defaultOutput)
+		events = append(events, DeviceChangeEvent{Kind: DeviceDefaultChanged, Prev: prev, Curr: curr, DefaultKind: DefaultDeviceOutput})
+		w.lastDefaultOutput = defaultOutput
+	}
+
+	return events
+}
+
+func (w *deviceWatchState) diffMIDI(current MIDIDevices) []DeviceChangeEvent {
+	byUID := make(map[string]MIDIDevice, len(current))
+	for _, d := range current {
+		byUID[d.UID] = d
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.lastMIDI == nil {
+		w.lastMIDI = byUID
+		return nil
+	}
+
+	var events []DeviceChangeEvent
+	for uid, d := range byUID {
+		d := d
+		if _, ok := w.lastMIDI[uid]; !ok {
+			events = append(events, DeviceChangeEvent{Kind: DeviceAdded, MIDI: &d})
+		}
+	}
+	for uid, d := range w.lastMIDI {
+		d := d
+		if _, ok := byUID[uid]; !ok {
+			events = append(events, DeviceChangeEvent{Kind: DeviceRemoved, MIDI: &d})
+		}
+	}
+	w.lastMIDI = byUID
+
+	return events
+}
+
+// defaultUIDs returns the UIDs of whichever devices are marked as the
+// default input and output, or "" for either that's absent from devices.
+func defaultUIDs(devices AudioDevices) (input, output string) {
+	for _, d := range devices {
+		if d.IsDefaultInput {
+			input = d.UID
+		}
+		if d.IsDefaultOutput {
+			output = d.UID
+		}
+	}
+	return input, output
+}
+
+// findAudioByUID returns a pointer to a copy of the device with uid in
+// devices, or nil if uid is empty or not found.
+func findAudioByUID(devices AudioDevices, uid string) *AudioDevice {
+	if uid == "" {
+		return nil
+	}
+	if d := devices.ByUID(uid); d != nil {
+		cp := *d
+		return &cp
+	}
+	return nil
+}
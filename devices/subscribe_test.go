@@ -0,0 +1,158 @@
+//go:build darwin && cgo
+
+package devices
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAudioBackend implements AudioBackend over a slice the test mutates
+// between polls, so TestSubscribeCoalescesAudioChanges doesn't depend on
+// real hardware being plugged/unplugged.
+type fakeAudioBackend struct {
+	mu      sync.Mutex
+	devices AudioDevices
+}
+
+func (b *fakeAudioBackend) GetAudio() (AudioDevices, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append(AudioDevices(nil), b.devices...), nil
+}
+
+func (b *fakeAudioBackend) set(devices AudioDevices) {
+	b.mu.Lock()
+	b.devices = devices
+	b.mu.Unlock()
+}
+
+// TestSubscribeCoalescesAudioChanges drives a fake backend through an
+// add and a remove, and checks Subscribe reports both as one coalesced
+// batch rather than once per device.
+func TestSubscribeCoalescesAudioChanges(t *testing.T) {
+	backend := &fakeAudioBackend{devices: AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin"},
+	}}
+	SetAudioBackend(backend)
+	defer SetAudioBackend(nil)
+
+	// Reset the shared watch state so a previous test's poll doesn't leak in.
+	resetDeviceWatchForTest()
+
+	changes, cancel := Subscribe()
+	defer cancel()
+
+	// First poll just establishes the baseline; nothing should be emitted.
+	select {
+	case ev := <-changes:
+		t.Fatalf("unexpected event on the baseline poll: %+v", ev)
+	case <-time.After(3 * coalesceWindow):
+	}
+
+	backend.set(AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin"},
+		{Device: Device{Name: "USB Interface", UID: "usb-1", IsOnline: true}, DeviceType: "usb"},
+	})
+
+	var added *AudioDevice
+	select {
+	case ev := <-changes:
+		if ev.Kind != DeviceAdded || ev.Audio == nil {
+			t.Fatalf("expected a DeviceAdded event, got %+v", ev)
+		}
+		added = ev.Audio
+	case <-time.After(3 * coalesceWindow):
+		t.Fatal("timed out waiting for DeviceAdded")
+	}
+	if added.UID != "usb-1" {
+		t.Errorf("expected the added device's UID to be usb-1, got %s", added.UID)
+	}
+
+	backend.set(AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin"},
+	})
+
+	select {
+	case ev := <-changes:
+		if ev.Kind != DeviceRemoved || ev.Audio == nil || ev.Audio.UID != "usb-1" {
+			t.Fatalf("expected a DeviceRemoved event for usb-1, got %+v", ev)
+		}
+	case <-time.After(3 * coalesceWindow):
+		t.Fatal("timed out waiting for DeviceRemoved")
+	}
+}
+
+// TestSubscribeReportsAliveChanged checks that an IsOnline flip on an
+// otherwise-still-enumerated device is reported as DeviceAliveChanged
+// rather than the generic DevicePropertyChanged.
+func TestSubscribeReportsAliveChanged(t *testing.T) {
+	backend := &fakeAudioBackend{devices: AudioDevices{
+		{Device: Device{Name: "USB Interface", UID: "usb-1", IsOnline: true}, DeviceType: "usb"},
+	}}
+	SetAudioBackend(backend)
+	defer SetAudioBackend(nil)
+	resetDeviceWatchForTest()
+
+	changes, cancel := Subscribe()
+	defer cancel()
+
+	select {
+	case ev := <-changes:
+		t.Fatalf("unexpected event on the baseline poll: %+v", ev)
+	case <-time.After(3 * coalesceWindow):
+	}
+
+	backend.set(AudioDevices{
+		{Device: Device{Name: "USB Interface", UID: "usb-1", IsOnline: false}, DeviceType: "usb"},
+	})
+
+	select {
+	case ev := <-changes:
+		if ev.Kind != DeviceAliveChanged || ev.Audio == nil || ev.Audio.UID != "usb-1" {
+			t.Fatalf("expected a DeviceAliveChanged event for usb-1, got %+v", ev)
+		}
+		if ev.Audio.IsOnline {
+			t.Error("expected the reported device to reflect IsOnline=false")
+		}
+	case <-time.After(3 * coalesceWindow):
+		t.Fatal("timed out waiting for DeviceAliveChanged")
+	}
+}
+
+// TestSubscribeCancelClosesChannel checks cancel unregisters the subscriber
+// and closes its channel, rather than leaving it to stall forever.
+func TestSubscribeCancelClosesChannel(t *testing.T) {
+	resetDeviceWatchForTest()
+
+	changes, cancel := Subscribe()
+	cancel()
+
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Errorf("expected the channel to be closed after cancel, got a value instead")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the channel to close after cancel")
+	}
+}
+
+// resetDeviceWatchForTest clears deviceWatch's diffing state and subscriber
+// set between tests, since it's a package-level singleton shared across the
+// whole test binary.
+func resetDeviceWatchForTest() {
+	deviceWatch.mu.Lock()
+	defer deviceWatch.mu.Unlock()
+
+	if deviceWatch.started {
+		close(deviceWatch.stop)
+		deviceWatch.started = false
+	}
+	deviceWatch.subs = make(map[int]chan DeviceChangeEvent)
+	deviceWatch.lastAudio = nil
+	deviceWatch.lastMIDI = nil
+	deviceWatch.lastDefaultInput = ""
+	deviceWatch.lastDefaultOutput = ""
+}
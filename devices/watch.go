@@ -0,0 +1,36 @@
+//go:build darwin && cgo
+
+package devices
+
+import "context"
+
+// Watch is a callback-style convenience over Watcher/Events for callers who
+// would otherwise just range over the channel in their own goroutine. It
+// starts a Watcher, hands every DeviceEvent to callback from a dedicated
+// goroutine, and stops the Watcher when ctx is done. callback must not block
+// indefinitely: like Watcher's own buffered channel, a slow callback delays
+// later events rather than the watch loop itself.
+func Watch(ctx context.Context, callback func(DeviceEvent)) (func(), error) {
+	watcher, err := NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range watcher.Events() {
+			callback(ev)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		watcher.Close()
+	}()
+
+	return func() {
+		watcher.Close()
+		<-done
+	}, nil
+}
@@ -0,0 +1,170 @@
+//go:build darwin && cgo
+
+package devices
+
+import "sync"
+
+// DeviceEventKind identifies what changed in a DeviceEvent - Watcher's
+// audio-only view of Subscribe's DeviceChangeKind (MIDI changes aren't
+// reported; see Subscribe for those).
+type DeviceEventKind string
+
+const (
+	DeviceAddedEvent          DeviceEventKind = "added"
+	DeviceRemovedEvent        DeviceEventKind = "removed"
+	DeviceDefaultChangedEvent DeviceEventKind = "default_changed"
+	DeviceFormatChangedEvent  DeviceEventKind = "format_changed"
+	// DeviceAliveChangedEvent mirrors Subscribe's DeviceAliveChanged - a
+	// device's IsOnline flipped without it leaving GetAudio's enumeration.
+	DeviceAliveChangedEvent DeviceEventKind = "alive_changed"
+)
+
+// DeviceEvent is one change a Watcher reports. Generation increments by one
+// for every event a given Watcher emits, so a consumer that only keeps the
+// latest event per device (e.g. engine.Channel's RebindDevice bookkeeping)
+// can tell a late-arriving event apart from a stale one. DefaultKind is only
+// set alongside DeviceDefaultChangedEvent, naming which default role (input
+// or output) moved to Device.
+type DeviceEvent struct {
+	Kind        DeviceEventKind
+	Device      AudioDevice
+	DefaultKind DefaultDeviceKind
+	Generation  uint64
+}
+
+// Watcher is a channel-based, filterable view over Subscribe's hot-plug and
+// configuration change feed, scoped to audio devices. NewWatcher starts the
+// underlying poll on first use, same as Subscribe; call Close when done.
+type Watcher struct {
+	// root is nil for the Watcher NewWatcher returned, and set to that
+	// Watcher for one OnlyInputs/OnlyByType derived from it - Close on a
+	// derived Watcher stops root's subscription too, since a derived
+	// Watcher owns no subscription of its own.
+	root   *Watcher
+	events chan DeviceEvent
+	cancel func()
+
+	closeOnce sync.Once
+}
+
+// NewWatcher starts watching for audio device changes (add/remove, default
+// input/output changes, and format/property changes), delivered via Events.
+func NewWatcher() (*Watcher, error) {
+	raw, cancel := Subscribe()
+
+	w := &Watcher{
+		events: make(chan DeviceEvent, 32),
+		cancel: cancel,
+	}
+	go w.run(raw)
+	return w, nil
+}
+
+// run translates Subscribe's DeviceChangeEvent feed into DeviceEvents,
+// dropping MIDI-only changes and assigning each one the next generation.
+func (w *Watcher) run(raw <-chan DeviceChangeEvent) {
+	var generation uint64
+
+	for ev := range raw {
+		var kind DeviceEventKind
+		switch ev.Kind {
+		case DeviceAdded:
+			kind = DeviceAddedEvent
+		case DeviceRemoved:
+			kind = DeviceRemovedEvent
+		case DeviceDefaultChanged:
+			kind = DeviceDefaultChangedEvent
+		case DevicePropertyChanged:
+			kind = DeviceFormatChangedEvent
+		case DeviceAliveChanged:
+			kind = DeviceAliveChangedEvent
+		default:
+			continue // MIDI-only changes aren't reported here
+		}
+
+		// DeviceAdded/DeviceRemoved/DevicePropertyChanged carry the device
+		// on Audio; DeviceDefaultChanged carries it on Curr instead (Prev if
+		// the new default disappeared entirely). A MIDI DeviceAdded/
+		// DeviceRemoved has none of the three set, so it falls through here.
+		var device *AudioDevice
+		switch {
+		case ev.Audio != nil:
+			device = ev.Audio
+		case ev.Curr != nil:
+			device = ev.Curr
+		case ev.Prev != nil:
+			device = ev.Prev
+		default:
+			continue
+		}
+
+		generation++
+		select {
+		case w.events <- DeviceEvent{Kind: kind, Device: *device, DefaultKind: ev.DefaultKind, Generation: generation}:
+		default:
+		}
+	}
+	close(w.events)
+}
+
+// Events returns the channel w delivers DeviceEvents on. It closes once
+// Close is called on w or (for a Watcher returned by
+// OnlyInputs/OnlyByType) on whichever Watcher it was derived from.
+func (w *Watcher) Events() <-chan DeviceEvent {
+	return w.events
+}
+
+// Close stops w. Calling Close on a Watcher returned by
+// OnlyInputs/OnlyByType stops the subscription it was derived from, exactly
+// like calling Close on that Watcher directly.
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() {
+		if w.root != nil {
+			w.root.Close()
+			return
+		}
+		w.cancel()
+	})
+}
+
+// withFilter returns a Watcher that only emits events matching keep,
+// backed by w's own event stream - so OnlyInputs().OnlyByType("usb")
+// narrows further rather than starting a second subscription.
+func (w *Watcher) withFilter(keep func(AudioDevice) bool) *Watcher {
+	root := w
+	if w.root != nil {
+		root = w.root
+	}
+
+	filtered := &Watcher{
+		root:   root,
+		events: make(chan DeviceEvent, 32),
+	}
+
+	go func() {
+		for ev := range w.events {
+			if !keep(ev.Device) {
+				continue
+			}
+			select {
+			case filtered.events <- ev:
+			default:
+			}
+		}
+		close(filtered.events)
+	}()
+
+	return filtered
+}
+
+// OnlyInputs returns a Watcher reporting only devices with input channels,
+// mirroring AudioDevices.Inputs.
+func (w *Watcher) OnlyInputs() *Watcher {
+	return w.withFilter(func(d AudioDevice) bool { return d.CanInput() })
+}
+
+// OnlyByType returns a Watcher reporting only devices of deviceType (e.g.
+// "builtin", "usb"), mirroring AudioDevices.ByType.
+func (w *Watcher) OnlyByType(deviceType string) *Watcher {
+	return w.withFilter(func(d AudioDevice) bool { return d.DeviceType == deviceType })
+}
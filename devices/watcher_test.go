@@ -0,0 +1,166 @@
+//go:build darwin && cgo
+
+package devices
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWatcherReportsAddedAndRemoved drives a fake backend through an add
+// and a remove, and checks Watcher reports both as DeviceEvents with
+// increasing Generation, mirroring TestSubscribeCoalescesAudioChanges.
+func TestWatcherReportsAddedAndRemoved(t *testing.T) {
+	backend := &fakeAudioBackend{devices: AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin", InputChannelCount: 2},
+	}}
+	SetAudioBackend(backend)
+	defer SetAudioBackend(nil)
+	resetDeviceWatchForTest()
+
+	w, err := NewWatcher()
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+
+	// First poll just establishes the baseline; nothing should be emitted.
+	select {
+	case ev := <-w.Events():
+		t.Fatalf("unexpected event on the baseline poll: %+v", ev)
+	case <-time.After(3 * coalesceWindow):
+	}
+
+	backend.set(AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin", InputChannelCount: 2},
+		{Device: Device{Name: "USB Interface", UID: "usb-1", IsOnline: true}, DeviceType: "usb", InputChannelCount: 2},
+	})
+
+	var added DeviceEvent
+	select {
+	case ev := <-w.Events():
+		added = ev
+	case <-time.After(3 * coalesceWindow):
+		t.Fatal("timed out waiting for DeviceAddedEvent")
+	}
+	if added.Kind != DeviceAddedEvent || added.Device.UID != "usb-1" {
+		t.Fatalf("expected a DeviceAddedEvent for usb-1, got %+v", added)
+	}
+	if added.Generation != 1 {
+		t.Errorf("expected the first event to be Generation 1, got %d", added.Generation)
+	}
+
+	backend.set(AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin", InputChannelCount: 2},
+	})
+
+	var removed DeviceEvent
+	select {
+	case ev := <-w.Events():
+		removed = ev
+	case <-time.After(3 * coalesceWindow):
+		t.Fatal("timed out waiting for DeviceRemovedEvent")
+	}
+	if removed.Kind != DeviceRemovedEvent || removed.Device.UID != "usb-1" {
+		t.Fatalf("expected a DeviceRemovedEvent for usb-1, got %+v", removed)
+	}
+	if removed.Generation != 2 {
+		t.Errorf("expected the second event to be Generation 2, got %d", removed.Generation)
+	}
+}
+
+// TestWatcherReportsAliveChanged checks Watcher translates Subscribe's
+// DeviceAliveChanged into DeviceAliveChangedEvent rather than folding it
+// into DeviceFormatChangedEvent.
+func TestWatcherReportsAliveChanged(t *testing.T) {
+	backend := &fakeAudioBackend{devices: AudioDevices{
+		{Device: Device{Name: "USB Interface", UID: "usb-1", IsOnline: true}, DeviceType: "usb", InputChannelCount: 2},
+	}}
+	SetAudioBackend(backend)
+	defer SetAudioBackend(nil)
+	resetDeviceWatchForTest()
+
+	w, err := NewWatcher()
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+
+	select {
+	case ev := <-w.Events():
+		t.Fatalf("unexpected event on the baseline poll: %+v", ev)
+	case <-time.After(3 * coalesceWindow):
+	}
+
+	backend.set(AudioDevices{
+		{Device: Device{Name: "USB Interface", UID: "usb-1", IsOnline: false}, DeviceType: "usb", InputChannelCount: 2},
+	})
+
+	select {
+	case ev := <-w.Events():
+		if ev.Kind != DeviceAliveChangedEvent || ev.Device.UID != "usb-1" {
+			t.Fatalf("expected a DeviceAliveChangedEvent for usb-1, got %+v", ev)
+		}
+	case <-time.After(3 * coalesceWindow):
+		t.Fatal("timed out waiting for DeviceAliveChangedEvent")
+	}
+}
+
+// TestWatcherOnlyByTypeFiltersNonMatchingDevices checks that a type filter
+// narrows the event stream to devices of that type only.
+func TestWatcherOnlyByTypeFiltersNonMatchingDevices(t *testing.T) {
+	backend := &fakeAudioBackend{devices: AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin", InputChannelCount: 2},
+	}}
+	SetAudioBackend(backend)
+	defer SetAudioBackend(nil)
+	resetDeviceWatchForTest()
+
+	w, err := NewWatcher()
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+	usbOnly := w.OnlyByType("usb")
+
+	select {
+	case ev := <-usbOnly.Events():
+		t.Fatalf("unexpected event on the baseline poll: %+v", ev)
+	case <-time.After(3 * coalesceWindow):
+	}
+
+	backend.set(AudioDevices{
+		{Device: Device{Name: "Built-in", UID: "builtin", IsOnline: true}, DeviceType: "builtin", InputChannelCount: 2},
+		{Device: Device{Name: "Bluetooth Mic", UID: "bt-1", IsOnline: true}, DeviceType: "bluetooth", InputChannelCount: 1},
+		{Device: Device{Name: "USB Interface", UID: "usb-1", IsOnline: true}, DeviceType: "usb", InputChannelCount: 2},
+	})
+
+	select {
+	case ev := <-usbOnly.Events():
+		if ev.Device.UID != "usb-1" {
+			t.Fatalf("expected only the usb device to be reported, got %+v", ev)
+		}
+	case <-time.After(3 * coalesceWindow):
+		t.Fatal("timed out waiting for the usb device's DeviceAddedEvent")
+	}
+}
+
+// TestWatcherCloseClosesEvents checks Close unblocks a pending Events read.
+func TestWatcherCloseClosesEvents(t *testing.T) {
+	resetDeviceWatchForTest()
+
+	w, err := NewWatcher()
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	w.Close()
+
+	select {
+	case _, ok := <-w.Events():
+		if ok {
+			t.Errorf("expected Events to be closed after Close, got a value instead")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Events to close after Close")
+	}
+}
@@ -1,11 +1,19 @@
 package macaudio
 
 import (
+	"container/heap"
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
-	
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
 	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/internal/rt"
 )
 
 // DispatcherOperation represents a topology change operation
@@ -13,35 +21,131 @@ type DispatcherOperation struct {
 	Type     OperationType
 	Data     interface{}
 	Response chan DispatcherResult
+
+	// Priority determines where this operation lands in the dispatcher's
+	// queue relative to other pending operations; see OperationPriority.
+	// The zero value is PriorityRealtime, matching how every operation in
+	// this package behaved before priorities existed.
+	Priority OperationPriority
+
+	// Deadline, if non-zero, is the latest time this operation is still
+	// worth executing. An operation that's still queued past its deadline
+	// is dropped with a timeout error instead of running - see
+	// Dispatcher.Submit/TrySubmit. Zero means no deadline.
+	Deadline time.Time
+
+	// Source attributes this operation's resulting event(s) to where it
+	// came from, so a Subscribe consumer can tell a dispatcher-initiated
+	// change from one an external peer requested (and so avoid a feedback
+	// loop echoing its own update back to itself). Left zero, it's filled in
+	// by submit (SourceDispatcher, for this package's own CreateXChannel/SetX
+	// methods) or Submit/TrySubmit (SourceExternal, for the outside-the-
+	// call-graph traffic those two are meant for - see their doc comments);
+	// a caller of Submit/TrySubmit may still set it explicitly to override
+	// that default.
+	Source EventSource
+
+	// started, set only by Submit, is closed by runQueuedOperation the
+	// instant this operation is popped off the queue - the point past which
+	// a real result is guaranteed to reach Response shortly, whether the op
+	// runs to completion or is dropped for having missed its Deadline. It
+	// lets Submit, on ctx cancellation, tell an op still waiting in queue
+	// (abandon it, return ctx.Err() right away) from one already committed
+	// to running (wait for and relay its real result instead - see Submit).
+	// Nil for submit/submitCtx and TrySubmit, which don't need the
+	// distinction.
+	started chan struct{}
 }
 
+// OperationPriority orders pending operations in the dispatcher's queue.
+// Lower values run first; same-priority operations run in submission
+// order. The zero value, PriorityRealtime, is what every operation in this
+// package got by default before priorities existed, so existing call sites
+// that don't set Priority keep behaving exactly as they did.
+type OperationPriority int
+
+const (
+	// PriorityRealtime is for operations a user is waiting on the result
+	// of right now - mute, fader, and other small topology tweaks - which
+	// should never sit behind a backlog of bulk work.
+	PriorityRealtime OperationPriority = iota
+	// PriorityNormal is for ordinary topology changes (creating/removing/
+	// connecting channels) that aren't latency-sensitive but also aren't
+	// bulk transfers.
+	PriorityNormal
+	// PriorityBulk is for long-running or high-volume work - a SysEx dump,
+	// an aggregate-device reconfiguration - that should yield to realtime
+	// and normal operations rather than hog the dispatch loop.
+	PriorityBulk
+)
+
 // OperationType represents the type of dispatcher operation
 type OperationType string
 
 const (
 	// Engine operations
-	OpCreateEngine     OperationType = "create_engine"
-	OpStartEngine      OperationType = "start_engine"
-	OpStopEngine       OperationType = "stop_engine"
-	
+	OpCreateEngine OperationType = "create_engine"
+	OpStartEngine  OperationType = "start_engine"
+	OpStopEngine   OperationType = "stop_engine"
+
 	// Channel creation operations
-	OpCreateAudioInput OperationType = "create_audio_input"
-	OpCreateMidiInput  OperationType = "create_midi_input"
-	OpCreatePlayback   OperationType = "create_playback"
-	OpCreateAux        OperationType = "create_aux"
-	OpRemoveChannel    OperationType = "remove_channel"
-	
+	OpCreateAudioInput          OperationType = "create_audio_input"
+	OpCreateLoopbackInput       OperationType = "create_loopback_input"
+	OpCreateMidiInput           OperationType = "create_midi_input"
+	OpCreatePlayback            OperationType = "create_playback"
+	OpCreatePlaybackFromDecoder OperationType = "create_playback_from_decoder"
+	OpCreateAux                 OperationType = "create_aux"
+	OpCreateProcessing          OperationType = "create_processing"
+	OpCreateSynth               OperationType = "create_synth"
+	OpCreateSampler             OperationType = "create_sampler"
+	OpCreateBus                 OperationType = "create_bus"
+	OpCreateGroup               OperationType = "create_group"
+	OpRemoveChannel             OperationType = "remove_channel"
+
+	// Group membership (requires dispatcher, so it serializes with
+	// OpSetMute/device changes - see ChannelGroup.AssignChannel)
+	OpAssignChannelToGroup OperationType = "assign_channel_to_group"
+
 	// Connection operations
 	OpConnectChannels    OperationType = "connect_channels"
 	OpDisconnectChannels OperationType = "disconnect_channels"
-	
+
 	// Topology changing operations (require dispatcher)
-	OpSetMute           OperationType = "set_mute"
-	OpPluginBypass      OperationType = "plugin_bypass"
-	OpDeviceChange      OperationType = "device_change"
+	OpSetMute            OperationType = "set_mute"
+	OpPluginBypass       OperationType = "plugin_bypass"
+	OpDeviceChange       OperationType = "device_change"
 	OpOutputDeviceChange OperationType = "output_device_change"
+	OpInputDeviceChange  OperationType = "input_device_change"
+	OpRouteChannel       OperationType = "route_channel"
+	OpSetMasterLimiter   OperationType = "set_master_limiter"
+	OpSetSpatialParams   OperationType = "set_spatial_params"
+
+	// Scene operations (require dispatcher, so capture/recall serialize
+	// with concurrent OpSetMute/OpPluginBypass traffic)
+	OpCaptureScene OperationType = "capture_scene"
+	OpRecallScene  OperationType = "recall_scene"
+
+	// OpRunBatch runs a caller-supplied sequence of closures as one
+	// operation - see SubmitBatch.
+	OpRunBatch OperationType = "run_batch"
+
+	// OpExecuteTransaction runs a caller-supplied sequence of
+	// DispatcherOperations as one all-or-nothing step - see
+	// Dispatcher.ExecuteTransaction.
+	OpExecuteTransaction OperationType = "execute_transaction"
+
+	// OpSnapshot and OpRestoreSnapshot back Dispatcher.Snapshot/Restore.
+	OpSnapshot        OperationType = "snapshot"
+	OpRestoreSnapshot OperationType = "restore_snapshot"
 )
 
+// ErrDispatcherTimeout is wrapped into a DispatcherResult/Submit error when
+// an operation's Deadline (explicit, or filled in by submitCtx from
+// GetDefaultOperationTimeout) passes while it's still queued, so a caller
+// can distinguish a stuck, timed-out operation from any other dispatcher
+// failure with errors.Is(err, ErrDispatcherTimeout).
+var ErrDispatcherTimeout = errors.New("dispatcher: operation timed out waiting in queue")
+
 // DispatcherResult represents the result of a dispatcher operation
 type DispatcherResult struct {
 	Success bool
@@ -49,27 +153,427 @@ type DispatcherResult struct {
 	Error   error
 }
 
+// OperationLatencyPercentiles is one OperationPriority tier's p50/p90/p99/
+// p99.9 topology operation duration - see DispatcherStats.TierLatency.
+type OperationLatencyPercentiles struct {
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	P999 time.Duration
+}
+
+// DispatcherStats is a snapshot of dispatcher performance and backpressure
+// metrics, returned by GetPerformanceStats.
+type DispatcherStats struct {
+	// LastOperationDuration and MaxOperationDuration measure time spent
+	// actually executing an operation (inside executeOperation).
+	LastOperationDuration time.Duration
+	MaxOperationDuration  time.Duration
+
+	// LastWaitTime and MaxWaitTime measure time an operation spent queued
+	// before execution started. Operations submitted directly to the
+	// dispatcher's internal methods always see a near-zero wait under
+	// normal load; these are most meaningful for Submit/TrySubmit traffic
+	// competing with a backlog.
+	LastWaitTime time.Duration
+	MaxWaitTime  time.Duration
+
+	// QueueDepth is the number of operations currently queued and not yet
+	// executing. QueueCapacity is the limit TrySubmit sheds against and
+	// Submit blocks against (see SetQueueCapacity).
+	QueueDepth    int
+	QueueCapacity int
+
+	// DroppedOperations counts operations that never executed: rejected by
+	// TrySubmit because the queue was at capacity, or discarded because
+	// their Deadline passed while still queued.
+	DroppedOperations uint64
+
+	// CoalescedOperations counts operations that never executed because a
+	// later one with the same coalesceKey (OpSetMute/OpPluginBypass,
+	// same target) superseded them before they reached the front of the
+	// queue - not a drop, since the superseded caller still gets a result
+	// back (see operationItem.extraResponses), just never its own
+	// executeOperation call.
+	CoalescedOperations uint64
+
+	// TierLatency holds OperationLatencyP50/.../P999's same percentiles
+	// computed separately per OperationPriority tier, so a burst of
+	// PriorityRealtime mute/bypass toggles' latency can be read
+	// independently of a slow PriorityBulk device change's, where the
+	// OperationLatencyP* fields above blend all three tiers together.
+	TierLatency map[OperationPriority]OperationLatencyPercentiles
+
+	// ParamLaneDepth and ParamLaneCoalesceRatio describe the lock-free
+	// real-time parameter lane (see SubmitParam/paramLane), separately from
+	// the topology queue above: ParamLaneDepth is how many submitted
+	// volume/pan/rate/pitch updates are awaiting the next drain, and
+	// ParamLaneCoalesceRatio is the fraction of all submitted updates that
+	// were overwritten by a newer one for the same key before being applied
+	// (0 under light load, closer to 1 under a knob-dragging storm).
+	ParamLaneDepth         int
+	ParamLaneCoalesceRatio float64
+
+	// LatencyP50, LatencyP99, and LatencyP999 are the 50th/99th/99.9th
+	// percentile of recent param-lane apply latencies (submit to apply
+	// returning), over the last paramLatencyHistory samples.
+	LatencyP50  time.Duration
+	LatencyP99  time.Duration
+	LatencyP999 time.Duration
+
+	// RTRingFillLevel and RTRingDropped describe the lock-free command ring
+	// Engine.Post/PostBundle push onto (see internal/rt, rtRing):
+	// RTRingFillLevel is how many commands are waiting for the next Drain,
+	// and RTRingDropped is how many Post/PostBundle calls have found the
+	// ring full and given up since the dispatcher was created - a command
+	// lost this way never reaches the audio thread at all, unlike
+	// DroppedOperations above, which only ever sheds topology changes.
+	RTRingFillLevel int
+	RTRingDropped   uint64
+
+	// OperationLatencyP50/P90/P99/P999 are percentiles of recent topology
+	// operation durations (the same measurement LastOperationDuration/
+	// MaxOperationDuration above already take, just over a capped history
+	// instead of only the single most recent and all-time-max samples) -
+	// see operationLatencyHistory. TestDispatcherPerformance asserts against
+	// OperationLatencyP99 rather than an average, since an average can stay
+	// comfortably under 300ms while a handful of operations in the tail
+	// blow past it unnoticed.
+	OperationLatencyP50  time.Duration
+	OperationLatencyP90  time.Duration
+	OperationLatencyP99  time.Duration
+	OperationLatencyP999 time.Duration
+
+	// TotalOperations is the number of topology operations executeOperation
+	// has run since the dispatcher started (or the last ResetStats).
+	// OperationCounts breaks that total down by OperationType, and
+	// ParamCounts does the same for the param lane's non-topology traffic
+	// (currently just "volume"/"pan"/"rate"/"pitch" - see SubmitParam),
+	// which TotalOperations does not include.
+	TotalOperations uint64
+	OperationCounts map[OperationType]uint64
+	ParamCounts     map[string]uint64
+}
+
+// DispatcherEvent is a fire-and-forget notification, distinct from
+// DispatcherResult: it's emitted for state changes a caller didn't request
+// directly (e.g. a playback queue advancing to its next track on its own)
+// rather than returned as the result of a specific operation. See Events.
+type DispatcherEvent struct {
+	Type      string // e.g. "now_playing"
+	ChannelID string
+	Path      string
+}
+
+// EventNowPlaying is the DispatcherEvent.Type emitted when a playback
+// channel's queue advances to a new track; see PlaybackChannel.Enqueue.
+const EventNowPlaying = "now_playing"
+
+// EventDeviceLost is the DispatcherEvent.Type emitted when the engine's
+// primary output route's device reports going offline; DispatcherEvent.Path
+// carries its UID. See EngineConfig.OnDeviceLost.
+const EventDeviceLost = "device_lost"
+
+// EventDeviceRestored is emitted when the device EventDeviceLost named
+// comes back online before a WaitForReconnect timeout expires.
+const EventDeviceRestored = "device_restored"
+
+// EventFallbackActivated is emitted when the engine reroutes its primary
+// output to a different device per EngineConfig.OnDeviceLost/
+// PreferredDeviceUIDs; DispatcherEvent.Path carries the new device's UID.
+// It's also emitted, with DispatcherEvent.ChannelID set, when a per-channel
+// device (AudioInputConfig/MidiInputConfig.FallbackDeviceUID) is
+// auto-rebound the same way - see Engine.handleChannelDeviceStatusChanged.
+const EventFallbackActivated = "fallback_activated"
+
+// EventFormatChanged is emitted, with DispatcherEvent.ChannelID set, when a
+// channel's bound input device reports a different set of supported sample
+// rates than it did before - see Engine.handleChannelDeviceFormatChanged.
+// DispatcherEvent.Path carries the device's UID.
+const EventFormatChanged = "format_changed"
+
+// EventOperationFailed is emitted, with DispatcherEvent.ChannelID set, when
+// an automatic device-loss recovery attempt itself fails - currently only
+// Engine.handleChannelDeviceStatusChanged's attempt to rebind a channel to
+// its configured FallbackDeviceUID. DispatcherEvent.Path carries the
+// fallback UID that couldn't be applied.
+const EventOperationFailed = "operation_failed"
+
+// DefaultQueueCapacity is how many operations Dispatcher.Submit/TrySubmit
+// will hold pending execution before Submit blocks for room and TrySubmit
+// starts shedding; see SetQueueCapacity.
+const DefaultQueueCapacity = 256
+
+// operationLatencyHistory bounds how many recent topology operation
+// durations GetPerformanceStats' OperationLatencyP50/P90/P99/P999
+// calculation considers, mirroring paramLatencyHistory's role for the
+// param lane.
+const operationLatencyHistory = 512
+
 // Dispatcher manages serialized topology changes to ensure glitch-free operation
 type Dispatcher struct {
-	engine      *Engine
-	mu          sync.RWMutex
-	isRunning   bool
-	operations  chan DispatcherOperation
-	stopChan    chan struct{}
-	
+	engine    *Engine
+	mu        sync.RWMutex
+	isRunning bool
+	stopChan  chan struct{}
+	events    chan DispatcherEvent
+
+	// queue is the bounded, priority-ordered backlog Submit/TrySubmit feed
+	// into and dispatchLoop drains; see enqueue/popQueue. queueSlots is a
+	// channel-as-semaphore of capacity queueCapacity: acquiring a slot
+	// (sending to it) is how enqueue applies backpressure, and releasing
+	// one (receiving from it) happens once dispatchLoop finishes an
+	// operation popped from queue. queueReady wakes dispatchLoop up
+	// whenever queue goes from empty to non-empty.
+	queueMu       sync.Mutex
+	queue         operationQueue
+	queueSlots    chan struct{}
+	queueReady    chan struct{}
+	queueCapacity int
+	nextSeq       uint64
+	dropCount     uint64
+
+	// coalesceIndex maps a coalesceKey to the queue item it currently
+	// resolves to, guarded by queueMu alongside queue itself - see
+	// enqueue/popQueue. Only coalescable operation types (OpSetMute,
+	// OpPluginBypass) ever appear here; everything else always queues as
+	// its own item.
+	coalesceIndex map[string]*operationItem
+	coalesceCount uint64
+
 	// Performance tracking
 	lastOperationDuration time.Duration
 	maxOperationDuration  time.Duration
+	lastWaitTime          time.Duration
+	maxWaitTime           time.Duration
 	performanceMu         sync.RWMutex
+
+	// defaultOperationTimeout is the Deadline submitCtx fills in for an op
+	// that doesn't already set one - see SetDefaultOperationTimeout. Guarded
+	// by performanceMu alongside the fields above since it's read on every
+	// submitCtx call and only ever written by a caller reconfiguring it.
+	defaultOperationTimeout time.Duration
+
+	// opStatsMu guards opLatencies and opCounts - the topology queue's
+	// per-operation-duration history and per-OperationType counters behind
+	// GetPerformanceStats' OperationLatencyP50/.../TotalOperations/
+	// OperationCounts fields. Kept separate from performanceMu above so
+	// recording a sample (on the hot executeOperation path) never contends
+	// with a concurrent GetPerformanceStats/ResetStats call beyond the
+	// length of its own critical section.
+	opStatsMu   sync.Mutex
+	opLatencies []time.Duration // most recent op durations, capped at operationLatencyHistory
+	opCounts    map[OperationType]uint64
+
+	// opLatenciesByPriority mirrors opLatencies, bucketed per
+	// OperationPriority tier instead of blended across all of them - see
+	// operationPercentilesForPriority/DispatcherStats.TierLatency. Each
+	// tier's slice is independently capped at operationLatencyHistory.
+	opLatenciesByPriority map[OperationPriority][]time.Duration
+
+	// Direct callback handlers, as an alternative to reading from Events()/
+	// polling a DeviceMonitor; see OnChannelEvent/OnDeviceChanged/OnRender/OnXRun.
+	handlersMu           sync.RWMutex
+	channelEventHandlers []func(DispatcherEvent)
+	renderHandlers       []func()
+	xrunHandlers         []func()
+
+	// runGoroutineID is the id of the goroutine running dispatchLoop,
+	// stamped by markDispatchLoopGoroutine; only meaningful in -tags debug
+	// builds, where it backs AssertOnDispatcher/AssertNotOnDispatcher
+	// (see dispatcher_assert_debug.go).
+	runGoroutineID uint64
+
+	// paramLane is the lock-free lane for real-time float parameter changes
+	// (volume/pan/rate/pitch), drained by paramDrainLoop independently of
+	// dispatchLoop/queue above - see dispatcher_param_lane.go and
+	// SubmitParam.
+	paramLane *paramLane
+
+	// rtRing is the lock-free MPSC command ring Engine.Post/PostBundle push
+	// onto, meant to be drained by a render-thread consumer instead of the
+	// timer-driven paramDrainLoop paramLane uses - see internal/rt, OnRender,
+	// and drainRTRing. Like renderHandlers, nothing in this tree actually
+	// calls Drain on it yet (no render-notify-tap binding - see OnRender's
+	// doc comment), so commands pushed here currently just accumulate until
+	// something upstream wires a render callback to invoke the handlers
+	// OnRender registers.
+	rtRing *rt.Ring
+
+	// subsMu guards subs and nextSubID - the peer-style EngineEvent stream
+	// Subscribe registers into, published by publishEvent. Distinct from
+	// events/channelEventHandlers above: those carry DispatcherEvent (a
+	// handful of playback/device notifications a caller didn't ask for
+	// directly), while this is the broader, typed status stream - transport,
+	// parameter, connection, and error changes - a peer like a WebSocket/HTTP
+	// surface subscribes to instead of polling. See dispatcher_events.go.
+	subsMu    sync.RWMutex
+	subs      map[uint64]*eventSubscription
+	nextSubID uint64
 }
 
 // NewDispatcher creates a new dispatcher
 func NewDispatcher(engine *Engine) *Dispatcher {
-	return &Dispatcher{
-		engine:               engine,
-		operations:           make(chan DispatcherOperation, 100), // Buffered channel
-		stopChan:             make(chan struct{}),
-		maxOperationDuration: 300 * time.Millisecond, // Target: sub-300ms
+	d := &Dispatcher{
+		engine:                  engine,
+		stopChan:                make(chan struct{}),
+		events:                  make(chan DispatcherEvent, 32),
+		maxOperationDuration:    300 * time.Millisecond, // Target: sub-300ms
+		defaultOperationTimeout: 2 * 300 * time.Millisecond,
+		queueSlots:              make(chan struct{}, DefaultQueueCapacity),
+		queueReady:              make(chan struct{}, 1),
+		queueCapacity:           DefaultQueueCapacity,
+		coalesceIndex:           make(map[string]*operationItem),
+		paramLane:               &paramLane{},
+		rtRing:                  rt.NewRing(),
+		opCounts:                make(map[OperationType]uint64),
+		opLatenciesByPriority:   make(map[OperationPriority][]time.Duration),
+	}
+	d.OnRender(d.drainRTRing)
+	return d
+}
+
+// drainRTRing drains rtRing, applying every rt.Command waiting in it. It's
+// registered as a render handler in NewDispatcher so it starts draining the
+// moment this tree gets a real render-notify-tap binding (see OnRender);
+// until then it's simply never invoked, same as every other render handler.
+func (d *Dispatcher) drainRTRing() {
+	d.rtRing.Drain(d.applyRTCommand)
+}
+
+// applyRTCommand applies one rt.Command by resolving its ChannelID/ParamID
+// to the matching Channel setter, the same pairs applyChannelParamRaw
+// recognizes for /channel/<id>/<param>.
+func (d *Dispatcher) applyRTCommand(cmd rt.Command) {
+	ch, ok := d.engine.GetChannel(cmd.ChannelID)
+	if !ok {
+		return
+	}
+
+	var err error
+	switch cmd.ParamID {
+	case "volume":
+		err = ch.SetVolume(cmd.Value)
+	case "pan":
+		err = ch.SetPan(cmd.Value)
+	case "mute":
+		err = ch.SetMute(cmd.Value != 0)
+	default:
+		return
+	}
+	d.handleParamApplied(paramUpdate{
+		key:    paramKey{channelID: cmd.ChannelID, param: cmd.ParamID},
+		value:  cmd.Value,
+		source: SourceExternal,
+	}, err)
+}
+
+// SetQueueCapacity resizes the bounded queue Submit/TrySubmit feed into. It
+// must be called before Start - resizing a live queue would race against
+// in-flight enqueue/dequeue. DefaultQueueCapacity is generous enough for
+// this package's own internal traffic; this exists for callers like an OSC
+// control surface that want to shed an overeager external client earlier.
+func (d *Dispatcher) SetQueueCapacity(capacity int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.isRunning {
+		return fmt.Errorf("dispatcher: cannot change queue capacity while running")
+	}
+	if capacity <= 0 {
+		return fmt.Errorf("dispatcher: queue capacity must be positive, got %d", capacity)
+	}
+
+	d.queueCapacity = capacity
+	d.queueSlots = make(chan struct{}, capacity)
+	return nil
+}
+
+// SetDefaultOperationTimeout changes the Deadline submitCtx fills in for an
+// op submitted through a ...Ctx wrapper (or submit/TrySubmit) that doesn't
+// already set its own Deadline - how long it can sit queued before
+// runQueuedOperation drops it as ErrDispatcherTimeout. Zero disables the
+// default, leaving such an op to wait indefinitely the way every op did
+// before this existed. Defaults to 2x maxOperationDuration.
+func (d *Dispatcher) SetDefaultOperationTimeout(timeout time.Duration) {
+	d.performanceMu.Lock()
+	d.defaultOperationTimeout = timeout
+	d.performanceMu.Unlock()
+}
+
+// GetDefaultOperationTimeout returns the timeout SetDefaultOperationTimeout
+// last configured (or the 2x maxOperationDuration default).
+func (d *Dispatcher) GetDefaultOperationTimeout() time.Duration {
+	d.performanceMu.RLock()
+	defer d.performanceMu.RUnlock()
+	return d.defaultOperationTimeout
+}
+
+// OnChannelEvent registers fn to be called synchronously, in addition to
+// delivery on Events(), whenever a channel emits a DispatcherEvent (e.g. a
+// playback queue's now-playing track changing). Unlike Events(), a
+// registered handler never misses an event to a full buffer - but it also
+// runs on the emitting goroutine, so it must not block.
+func (d *Dispatcher) OnChannelEvent(fn func(DispatcherEvent)) {
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+	d.channelEventHandlers = append(d.channelEventHandlers, fn)
+}
+
+// OnDeviceChanged registers fn to be called when the engine's available
+// audio devices change. It's driven by avengine.OnDeviceChange's background
+// poll rather than a dedicated dispatcher loop - see DeviceMonitor, which
+// registers through this to retire its own 50ms polling goroutine.
+func (d *Dispatcher) OnDeviceChanged(fn func(avengine.DeviceChangeEvent)) {
+	avengine.OnDeviceChange(fn)
+}
+
+// OnRender registers fn to be called once per AVAudioEngine render cycle.
+//
+// There is no render-notify-tap binding wired up to Go in this tree (see
+// the native/ bindings the rest of this package already declares against
+// but can't build here), so fn is never actually invoked yet - this only
+// reserves the registration API so callers can be written against it ahead
+// of that binding landing.
+func (d *Dispatcher) OnRender(fn func()) {
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+	d.renderHandlers = append(d.renderHandlers, fn)
+}
+
+// OnXRun registers fn to be called when the audio engine reports a buffer
+// underrun/overrun. Like OnRender, this needs a render-notify-tap binding
+// this tree doesn't have yet, so fn is never actually invoked - registration
+// only.
+func (d *Dispatcher) OnXRun(fn func()) {
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+	d.xrunHandlers = append(d.xrunHandlers, fn)
+}
+
+// Events returns the channel DispatcherEvents are delivered on. The channel
+// is buffered; a slow consumer can miss events rather than stall playback
+// (see emitEvent).
+func (d *Dispatcher) Events() <-chan DispatcherEvent {
+	return d.events
+}
+
+// emitEvent delivers ev to Events without blocking the caller - dropping the
+// event rather than stalling playback if the consumer is slow, matching
+// avengine.Playlist.emitLocked - and, in addition, calls every handler
+// registered via OnChannelEvent synchronously, so a caller using callbacks
+// instead of Events() never misses one to a full buffer.
+func (d *Dispatcher) emitEvent(ev DispatcherEvent) {
+	select {
+	case d.events <- ev:
+	default:
+	}
+
+	d.handlersMu.RLock()
+	handlers := append([]func(DispatcherEvent){}, d.channelEventHandlers...)
+	d.handlersMu.RUnlock()
+	for _, fn := range handlers {
+		fn(ev)
 	}
 }
 
@@ -77,14 +581,15 @@ func NewDispatcher(engine *Engine) *Dispatcher {
 func (d *Dispatcher) Start() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	
+
 	if d.isRunning {
 		return fmt.Errorf("dispatcher is already running")
 	}
-	
+
 	d.isRunning = true
 	go d.dispatchLoop()
-	
+	go d.paramDrainLoop()
+
 	return nil
 }
 
@@ -92,14 +597,14 @@ func (d *Dispatcher) Start() error {
 func (d *Dispatcher) Stop() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	
+
 	if !d.isRunning {
 		return nil // Already stopped
 	}
-	
+
 	close(d.stopChan)
 	d.isRunning = false
-	
+
 	return nil
 }
 
@@ -110,44 +615,252 @@ func (d *Dispatcher) IsRunning() bool {
 	return d.isRunning
 }
 
-// GetPerformanceStats returns dispatcher performance statistics
-func (d *Dispatcher) GetPerformanceStats() (lastDuration, maxDuration time.Duration) {
+// recordOperationStats appends duration to opLatencies and
+// opLatenciesByPriority[priority] (each capped at operationLatencyHistory,
+// same eviction policy as paramLane.recordLatency) and increments
+// opCounts[opType], for GetPerformanceStats'
+// OperationLatencyP50/.../OperationCounts/TierLatency fields.
+func (d *Dispatcher) recordOperationStats(opType OperationType, priority OperationPriority, duration time.Duration) {
+	d.opStatsMu.Lock()
+	defer d.opStatsMu.Unlock()
+
+	d.opLatencies = append(d.opLatencies, duration)
+	if len(d.opLatencies) > operationLatencyHistory {
+		d.opLatencies = d.opLatencies[len(d.opLatencies)-operationLatencyHistory:]
+	}
+	d.opCounts[opType]++
+
+	byPriority := append(d.opLatenciesByPriority[priority], duration)
+	if len(byPriority) > operationLatencyHistory {
+		byPriority = byPriority[len(byPriority)-operationLatencyHistory:]
+	}
+	d.opLatenciesByPriority[priority] = byPriority
+}
+
+// operationPercentiles returns the p50/p90/p99/p99.9 topology operation
+// duration over the recorded history, sorted on a snapshot so it never
+// holds opStatsMu while sorting - the same approach paramLane.percentiles
+// uses for param lane latencies.
+func (d *Dispatcher) operationPercentiles() (p50, p90, p99, p999 time.Duration) {
+	d.opStatsMu.Lock()
+	samples := append([]time.Duration(nil), d.opLatencies...)
+	d.opStatsMu.Unlock()
+	return durationPercentiles(samples)
+}
+
+// operationPercentilesForPriority is operationPercentiles narrowed to one
+// OperationPriority tier, for DispatcherStats.TierLatency - so a burst of
+// PriorityRealtime mute toggles' latency can be read independently of a
+// slow PriorityBulk device change's, where operationPercentiles blends all
+// three tiers into one number.
+func (d *Dispatcher) operationPercentilesForPriority(priority OperationPriority) (p50, p90, p99, p999 time.Duration) {
+	d.opStatsMu.Lock()
+	samples := append([]time.Duration(nil), d.opLatenciesByPriority[priority]...)
+	d.opStatsMu.Unlock()
+	return durationPercentiles(samples)
+}
+
+// durationPercentiles returns samples' p50/p90/p99/p99.9, sorting a copy so
+// the caller's lock (if any) is never held while sorting - shared by
+// operationPercentiles and operationPercentilesForPriority.
+func durationPercentiles(samples []time.Duration) (p50, p90, p99, p999 time.Duration) {
+	if len(samples) == 0 {
+		return 0, 0, 0, 0
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(fraction float64) time.Duration {
+		idx := int(fraction * float64(len(sorted)))
+		if idx >= len(sorted) {
+			idx = len(sorted) - 1
+		}
+		return sorted[idx]
+	}
+	return at(0.50), at(0.90), at(0.99), at(0.999)
+}
+
+// GetPerformanceStats returns a snapshot of dispatcher performance and
+// backpressure metrics; see DispatcherStats.
+func (d *Dispatcher) GetPerformanceStats() DispatcherStats {
 	d.performanceMu.RLock()
-	defer d.performanceMu.RUnlock()
-	return d.lastOperationDuration, d.maxOperationDuration
+	stats := DispatcherStats{
+		LastOperationDuration: d.lastOperationDuration,
+		MaxOperationDuration:  d.maxOperationDuration,
+		LastWaitTime:          d.lastWaitTime,
+		MaxWaitTime:           d.maxWaitTime,
+	}
+	d.performanceMu.RUnlock()
+
+	d.queueMu.Lock()
+	stats.QueueDepth = len(d.queue)
+	d.queueMu.Unlock()
+
+	stats.QueueCapacity = d.queueCapacity
+	stats.DroppedOperations = atomic.LoadUint64(&d.dropCount)
+	stats.CoalescedOperations = atomic.LoadUint64(&d.coalesceCount)
+
+	stats.ParamLaneDepth, stats.ParamLaneCoalesceRatio = d.paramLane.stats()
+	stats.LatencyP50, stats.LatencyP99, stats.LatencyP999 = d.paramLane.percentiles()
+	stats.ParamCounts = d.paramLane.paramCounts()
+
+	stats.RTRingFillLevel = d.rtRing.FillLevel()
+	stats.RTRingDropped = d.rtRing.Dropped()
+
+	stats.OperationLatencyP50, stats.OperationLatencyP90, stats.OperationLatencyP99, stats.OperationLatencyP999 = d.operationPercentiles()
+
+	stats.TierLatency = make(map[OperationPriority]OperationLatencyPercentiles, 3)
+	for _, tier := range []OperationPriority{PriorityRealtime, PriorityNormal, PriorityBulk} {
+		p50, p90, p99, p999 := d.operationPercentilesForPriority(tier)
+		stats.TierLatency[tier] = OperationLatencyPercentiles{P50: p50, P90: p90, P99: p99, P999: p999}
+	}
+
+	d.opStatsMu.Lock()
+	stats.OperationCounts = make(map[OperationType]uint64, len(d.opCounts))
+	for opType, count := range d.opCounts {
+		stats.OperationCounts[opType] = count
+		stats.TotalOperations += count
+	}
+	d.opStatsMu.Unlock()
+
+	return stats
+}
+
+// GetQueueDepth returns the number of topology operations currently queued
+// and not yet executing - the same count as
+// GetPerformanceStats().QueueDepth, for a caller that wants just this one
+// number (e.g. a health check deciding whether to shed load) without
+// paying for a full DispatcherStats snapshot.
+func (d *Dispatcher) GetQueueDepth() int {
+	d.queueMu.Lock()
+	defer d.queueMu.Unlock()
+	return len(d.queue)
+}
+
+// StatsSnapshot is GetPerformanceStats by another name for callers (a
+// Prometheus-style scrape handler, a status endpoint) that want an explicit
+// "give me a point-in-time copy I can marshal to JSON and hold onto"
+// entry point rather than reaching for the same name this package's own
+// code uses internally. DispatcherStats' fields are all plain values and
+// maps already copied per call, so the result needs no further copying
+// before being encoded.
+func (d *Dispatcher) StatsSnapshot() DispatcherStats {
+	return d.GetPerformanceStats()
 }
 
-// dispatchLoop runs the main dispatch loop for topology changes
+// ResetStats clears every counter and latency sample GetPerformanceStats
+// reports - operation/param latency history, operation/param counts,
+// dropped-operation and wait-time tracking - without affecting the
+// dispatcher's running state (queue, subscriptions, etc). Useful for a
+// caller that wants stats scoped to a window (e.g. "since the last scrape")
+// rather than since the dispatcher started.
+func (d *Dispatcher) ResetStats() {
+	d.performanceMu.Lock()
+	d.lastOperationDuration = 0
+	d.maxOperationDuration = 0
+	d.lastWaitTime = 0
+	d.maxWaitTime = 0
+	d.performanceMu.Unlock()
+
+	atomic.StoreUint64(&d.dropCount, 0)
+	atomic.StoreUint64(&d.coalesceCount, 0)
+
+	d.opStatsMu.Lock()
+	d.opLatencies = nil
+	d.opCounts = make(map[OperationType]uint64)
+	d.opLatenciesByPriority = make(map[OperationPriority][]time.Duration)
+	d.opStatsMu.Unlock()
+
+	d.paramLane.reset()
+}
+
+// dispatchLoop runs the main dispatch loop for topology changes. It wakes
+// either when an operation lands in the priority queue (queueReady) or when
+// stopChan closes; on queueReady it drains every operation currently queued,
+// highest priority first, before going back to waiting - so a burst that
+// arrives while it's mid-drain doesn't need a second wakeup.
 func (d *Dispatcher) dispatchLoop() {
+	d.markDispatchLoopGoroutine()
 	for {
 		select {
 		case <-d.stopChan:
 			return
-		case op := <-d.operations:
-			start := time.Now()
-			result := d.executeOperation(op)
-			duration := time.Since(start)
-			
-			// Update performance tracking
-			d.performanceMu.Lock()
-			d.lastOperationDuration = duration
-			if duration > d.maxOperationDuration {
-				d.maxOperationDuration = duration
-			}
-			d.performanceMu.Unlock()
-			
-			// Log if operation exceeded target
-			if duration > 300*time.Millisecond {
-				d.engine.errorHandler.HandleError(
-					fmt.Errorf("topology change took %v, target is sub-300ms", duration))
+		case <-d.queueReady:
+			for {
+				item, ok := d.popQueue()
+				if !ok {
+					break
+				}
+				d.runQueuedOperation(item)
 			}
-			
-			// Send result back
-			op.Response <- result
 		}
 	}
 }
 
+// runOperation executes op and records the performance stats shared by
+// every path into the dispatch loop (the queue and, previously, the direct
+// channel this replaced). extraResponses, if non-empty, are the Response
+// channels of earlier operations that coalesced into op before it reached
+// the front of the queue (see coalesceKey/enqueue) - each gets the same
+// result as op.Response.
+func (d *Dispatcher) runOperation(op DispatcherOperation, extraResponses []chan DispatcherResult) {
+	start := time.Now()
+	result := d.executeOperation(op)
+	duration := time.Since(start)
+
+	d.performanceMu.Lock()
+	d.lastOperationDuration = duration
+	if duration > d.maxOperationDuration {
+		d.maxOperationDuration = duration
+	}
+	d.performanceMu.Unlock()
+
+	d.recordOperationStats(op.Type, op.Priority, duration)
+
+	if duration > 300*time.Millisecond {
+		d.engine.errorHandler.HandleError(
+			fmt.Errorf("topology change took %v, target is sub-300ms", duration))
+	}
+
+	d.publishOperationEvent(op, result)
+	op.Response <- result
+	for _, extra := range extraResponses {
+		extra <- result
+	}
+}
+
+// runQueuedOperation releases item's queue slot once it's done (whether it
+// ran or was dropped), records how long it waited, and either drops it (its
+// Deadline already passed while queued) or runs it.
+func (d *Dispatcher) runQueuedOperation(item *operationItem) {
+	defer func() { <-d.queueSlots }()
+
+	if item.op.started != nil {
+		close(item.op.started)
+	}
+
+	wait := time.Since(item.enqueuedAt)
+	d.performanceMu.Lock()
+	d.lastWaitTime = wait
+	if wait > d.maxWaitTime {
+		d.maxWaitTime = wait
+	}
+	d.performanceMu.Unlock()
+
+	if !item.op.Deadline.IsZero() && time.Now().After(item.op.Deadline) {
+		atomic.AddUint64(&d.dropCount, 1)
+		result := DispatcherResult{
+			Error: fmt.Errorf("dispatcher: %s exceeded its deadline after waiting %v in queue: %w", item.op.Type, wait, ErrDispatcherTimeout),
+		}
+		item.op.Response <- result
+		for _, extra := range item.extraResponses {
+			extra <- result
+		}
+		return
+	}
+
+	d.runOperation(item.op, item.extraResponses)
+}
+
 // executeOperation executes a single dispatcher operation
 func (d *Dispatcher) executeOperation(op DispatcherOperation) DispatcherResult {
 	switch op.Type {
@@ -155,69 +868,161 @@ func (d *Dispatcher) executeOperation(op DispatcherOperation) DispatcherResult {
 	case OpStartEngine:
 		err := d.startEngine()
 		return DispatcherResult{Success: err == nil, Error: err}
-		
+
 	case OpStopEngine:
 		err := d.stopEngine()
 		return DispatcherResult{Success: err == nil, Error: err}
-	
+
 	// Channel creation operations
 	case OpCreateAudioInput:
 		data := op.Data.(CreateAudioInputData)
 		channel, err := d.createAudioInput(data.ID, data.Config)
 		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
-		
+
+	case OpCreateLoopbackInput:
+		data := op.Data.(CreateLoopbackInputData)
+		channel, err := d.createLoopbackInput(data.ID, data.Config)
+		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
+
 	case OpCreateMidiInput:
 		data := op.Data.(CreateMidiInputData)
 		channel, err := d.createMidiInput(data.ID, data.Config)
 		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
-		
+
 	case OpCreatePlayback:
 		data := op.Data.(CreatePlaybackData)
 		channel, err := d.createPlayback(data.ID, data.Config)
 		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
-		
+
+	case OpCreatePlaybackFromDecoder:
+		data := op.Data.(CreatePlaybackFromDecoderData)
+		channel, err := d.createPlaybackFromDecoder(data.ID, data.Decoder)
+		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
+
 	case OpCreateAux:
 		data := op.Data.(CreateAuxData)
 		channel, err := d.createAux(data.ID, data.Config)
 		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
-		
+
+	case OpCreateProcessing:
+		data := op.Data.(CreateProcessingData)
+		channel, err := d.createProcessing(data.ID, data.Config)
+		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
+
+	case OpCreateSynth:
+		data := op.Data.(CreateSynthData)
+		channel, err := d.createSynth(data.ID, data.Config)
+		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
+
+	case OpCreateSampler:
+		data := op.Data.(CreateSamplerData)
+		channel, err := d.createSampler(data.ID, data.Config)
+		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
+
+	case OpCreateBus:
+		data := op.Data.(CreateBusData)
+		channel, err := d.createBus(data.ID, data.Config)
+		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
+
+	case OpCreateGroup:
+		data := op.Data.(CreateGroupData)
+		channel, err := d.createGroup(data.ID)
+		return DispatcherResult{Success: err == nil, Data: channel, Error: err}
+
+	case OpAssignChannelToGroup:
+		data := op.Data.(AssignChannelToGroupData)
+		err := d.assignChannelToGroup(data.GroupID, data.ChannelID)
+		return DispatcherResult{Success: err == nil, Error: err}
+
 	case OpRemoveChannel:
 		id := op.Data.(string)
 		err := d.removeChannel(id)
 		return DispatcherResult{Success: err == nil, Error: err}
-		
+
 	// Connection operations
 	case OpConnectChannels:
 		data := op.Data.(ConnectChannelsData)
 		err := d.connectChannels(data.SourceID, data.TargetID, data.Bus)
 		return DispatcherResult{Success: err == nil, Error: err}
-		
+
 	case OpDisconnectChannels:
 		data := op.Data.(DisconnectChannelsData)
 		err := d.disconnectChannels(data.SourceID, data.TargetID, data.Bus)
 		return DispatcherResult{Success: err == nil, Error: err}
-	
+
 	// Topology changing operations
 	case OpSetMute:
 		data := op.Data.(SetMuteData)
 		err := d.setMute(data.ChannelID, data.Muted)
 		return DispatcherResult{Success: err == nil, Error: err}
-		
+
 	case OpPluginBypass:
 		data := op.Data.(PluginBypassData)
 		err := d.setPluginBypass(data.ChannelID, data.PluginID, data.Bypassed)
 		return DispatcherResult{Success: err == nil, Error: err}
-		
+
 	case OpDeviceChange:
 		data := op.Data.(DeviceChangeData)
 		err := d.changeChannelDevice(data.ChannelID, data.NewDeviceUID)
 		return DispatcherResult{Success: err == nil, Error: err}
-		
+
 	case OpOutputDeviceChange:
 		data := op.Data.(OutputDeviceChangeData)
 		err := d.changeOutputDevice(data.NewDeviceUID)
 		return DispatcherResult{Success: err == nil, Error: err}
-		
+
+	case OpInputDeviceChange:
+		data := op.Data.(InputDeviceChangeData)
+		err := d.changeInputDevice(data.NewDeviceUID)
+		return DispatcherResult{Success: err == nil, Error: err}
+
+	case OpRouteChannel:
+		data := op.Data.(RouteChannelData)
+		err := d.routeChannelTo(data.ChannelID, data.RouteName)
+		return DispatcherResult{Success: err == nil, Error: err}
+
+	case OpSetMasterLimiter:
+		data := op.Data.(SetMasterLimiterData)
+		err := d.setMasterLimiter(data.Enabled)
+		return DispatcherResult{Success: err == nil, Error: err}
+
+	case OpSetSpatialParams:
+		data := op.Data.(SetSpatialParamsData)
+		done, err := d.setSpatialParams(data.ChannelID, data.Config, data.Duration, data.Curve)
+		return DispatcherResult{Success: err == nil, Data: done, Error: err}
+
+	case OpCaptureScene:
+		data := op.Data.(CaptureSceneData)
+		err := d.captureScene(data.Name)
+		return DispatcherResult{Success: err == nil, Error: err}
+
+	case OpRecallScene:
+		data := op.Data.(RecallSceneData)
+		err := d.recallScene(data.Name, data.Options)
+		return DispatcherResult{Success: err == nil, Error: err}
+
+	case OpRunBatch:
+		data := op.Data.(RunBatchData)
+		errs := make([]error, len(data.Fns))
+		for i, fn := range data.Fns {
+			errs[i] = fn()
+		}
+		return DispatcherResult{Success: true, Data: errs}
+
+	case OpExecuteTransaction:
+		data := op.Data.(ExecuteTransactionData)
+		err := d.executeTransaction(data.Ops)
+		return DispatcherResult{Success: err == nil, Error: err}
+
+	case OpSnapshot:
+		snap := d.snapshot()
+		return DispatcherResult{Success: true, Data: snap}
+
+	case OpRestoreSnapshot:
+		data := op.Data.(RestoreSnapshotData)
+		err := d.restoreSnapshot(EngineState(data.Snapshot))
+		return DispatcherResult{Success: err == nil, Error: err}
+
 	default:
 		return DispatcherResult{
 			Success: false,
@@ -226,6 +1031,346 @@ func (d *Dispatcher) executeOperation(op DispatcherOperation) DispatcherResult {
 	}
 }
 
+// operationItem wraps a DispatcherOperation with the bookkeeping the
+// priority queue needs: enqueuedAt for wait-time metrics, seq to keep
+// same-priority operations in submission order, and index for
+// container/heap's own use.
+type operationItem struct {
+	op         DispatcherOperation
+	enqueuedAt time.Time
+	seq        uint64
+	index      int
+
+	// extraResponses holds the Response channel of every earlier operation
+	// that coalesced into this item (see coalesceKey/enqueue) - each one
+	// gets the same DispatcherResult as op.Response once this item
+	// executes, so a caller whose operation was superseded still gets a
+	// result back instead of blocking forever.
+	extraResponses []chan DispatcherResult
+}
+
+// coalesceKey returns the key op's coalescing identity is keyed on, and
+// whether op coalesces at all: a later op enqueued with the same key before
+// an earlier one with that key has drained replaces it in place (see
+// enqueue) instead of queuing alongside it. Only PriorityRealtime's own
+// discrete toggles coalesce this way - OpSetMute keyed on channel,
+// OpPluginBypass keyed on (channel, plugin) - since overwriting a pending
+// graph edit or reconfiguration with a later one addressed to different
+// channels would silently lose the earlier one instead of applying both in
+// order.
+func coalesceKey(op DispatcherOperation) (string, bool) {
+	switch op.Type {
+	case OpSetMute:
+		data, ok := op.Data.(SetMuteData)
+		if !ok {
+			return "", false
+		}
+		return "mute:" + data.ChannelID, true
+	case OpPluginBypass:
+		data, ok := op.Data.(PluginBypassData)
+		if !ok {
+			return "", false
+		}
+		return "bypass:" + data.ChannelID + ":" + data.PluginID, true
+	default:
+		return "", false
+	}
+}
+
+// operationQueue is a container/heap min-heap ordered by priority first,
+// then by submission order within a priority - so PriorityRealtime always
+// drains ahead of PriorityNormal/PriorityBulk, and FIFO order is preserved
+// among operations that share a priority.
+type operationQueue []*operationItem
+
+func (q operationQueue) Len() int { return len(q) }
+
+func (q operationQueue) Less(i, j int) bool {
+	if q[i].op.Priority != q[j].op.Priority {
+		return q[i].op.Priority < q[j].op.Priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q operationQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+
+func (q *operationQueue) Push(x interface{}) {
+	item := x.(*operationItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *operationQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
+
+// enqueue reserves a queue slot for op - waiting for one if block is true
+// and ctx isn't done, failing immediately (and counting a drop) if block is
+// false and the queue is already at capacity - then either coalesces op
+// into an already-queued operation with the same coalesceKey (see
+// coalesceIndex) or pushes it onto the priority queue as its own item, and
+// wakes dispatchLoop.
+func (d *Dispatcher) enqueue(ctx context.Context, op DispatcherOperation, block bool) error {
+	if block {
+		select {
+		case d.queueSlots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	} else {
+		select {
+		case d.queueSlots <- struct{}{}:
+		default:
+			atomic.AddUint64(&d.dropCount, 1)
+			return fmt.Errorf("dispatcher: queue is at capacity (%d), dropping %s operation", d.queueCapacity, op.Type)
+		}
+	}
+
+	key, coalescable := coalesceKey(op)
+
+	d.queueMu.Lock()
+	if coalescable {
+		if existing, found := d.coalesceIndex[key]; found {
+			existing.extraResponses = append(existing.extraResponses, existing.op.Response)
+			existing.op = op
+			d.queueMu.Unlock()
+
+			// op has been folded into existing's already-reserved slot;
+			// release the one we just acquired for it instead of holding
+			// two slots for what will only execute once.
+			<-d.queueSlots
+			atomic.AddUint64(&d.coalesceCount, 1)
+			select {
+			case d.queueReady <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+	}
+
+	item := &operationItem{op: op, enqueuedAt: time.Now()}
+	d.nextSeq++
+	item.seq = d.nextSeq
+	if coalescable {
+		d.coalesceIndex[key] = item
+	}
+	heap.Push(&d.queue, item)
+	d.queueMu.Unlock()
+
+	select {
+	case d.queueReady <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// popQueue removes and returns the highest-priority queued operation, or
+// (nil, false) if the queue is empty. It also clears coalesceIndex's entry
+// for a coalescable item, so a later operation with the same key queues
+// fresh instead of coalescing into one that's already executing.
+func (d *Dispatcher) popQueue() (*operationItem, bool) {
+	d.queueMu.Lock()
+	defer d.queueMu.Unlock()
+	if len(d.queue) == 0 {
+		return nil, false
+	}
+	item := heap.Pop(&d.queue).(*operationItem)
+	if key, ok := coalesceKey(item.op); ok {
+		delete(d.coalesceIndex, key)
+	}
+	return item, true
+}
+
+// Submit enqueues op honoring its Priority, blocking until it executes, ctx
+// is canceled, or (if the queue is at capacity) room frees up - whichever
+// comes first. Prefer this over the package's own internal
+// CreateXChannel/SetX dispatcher methods for operations originating outside
+// this process's own call graph (an OSC control surface, a scripted batch
+// import): those have no backpressure of their own, so without a bound here
+// a client that sends faster than the dispatch loop executes would grow the
+// queue without limit.
+//
+// Canceling ctx while op is still waiting in queue drops it and returns
+// ctx.Err() right away, as above. Canceling it after op has already been
+// popped off the queue and started running - a heavy op like a device
+// change, say - doesn't abort that work: Submit still waits for it to
+// finish and returns its real DispatcherResult alongside ctx.Err(), so a
+// canceled caller learns how the in-flight op actually turned out instead
+// of being left to guess.
+func (d *Dispatcher) Submit(ctx context.Context, op DispatcherOperation) (DispatcherResult, error) {
+	if op.Response == nil {
+		op.Response = make(chan DispatcherResult, 1)
+	}
+	if op.Source == "" {
+		op.Source = SourceExternal
+	}
+	op.started = make(chan struct{})
+	if err := d.enqueue(ctx, op, true); err != nil {
+		return DispatcherResult{}, err
+	}
+	select {
+	case result := <-op.Response:
+		return result, nil
+	case <-ctx.Done():
+		select {
+		case <-op.started:
+			// Already popped from the queue and guaranteed a real result
+			// shortly (runOperation or runQueuedOperation's deadline-drop
+			// both always write to Response) - wait for it instead of
+			// abandoning the in-flight op.
+			return <-op.Response, ctx.Err()
+		default:
+			return DispatcherResult{}, ctx.Err()
+		}
+	}
+}
+
+// TrySubmit enqueues op like Submit, except it never waits for queue room:
+// if the queue is already at capacity it returns an error immediately and
+// counts the drop in DispatcherStats.DroppedOperations, rather than
+// blocking the caller behind a backlog. Suited to callers that would rather
+// skip a stale update than stall - an OSC fader move superseded by a newer
+// one a few milliseconds later, for instance.
+func (d *Dispatcher) TrySubmit(op DispatcherOperation) (DispatcherResult, error) {
+	if op.Response == nil {
+		op.Response = make(chan DispatcherResult, 1)
+	}
+	if op.Source == "" {
+		op.Source = SourceExternal
+	}
+	if err := d.enqueue(context.Background(), op, false); err != nil {
+		return DispatcherResult{}, err
+	}
+	return <-op.Response, nil
+}
+
+// SubmitBatch runs fns in submission order as a single OpRunBatch
+// operation, at PriorityRealtime, so they execute as one atomic step on the
+// dispatch loop instead of interleaving with whatever else is queued
+// between them - see OSCServer.handleBundle, which uses this to apply an
+// OSC bundle's parameter updates coherently. A failing fn doesn't stop the
+// rest from running; each one's error is returned at its same index. If
+// Submit itself fails (e.g. ctx canceled, though context.Background() never
+// is), every slot gets that error instead.
+func (d *Dispatcher) SubmitBatch(fns []func() error) []error {
+	result, err := d.Submit(context.Background(), DispatcherOperation{
+		Type:     OpRunBatch,
+		Data:     RunBatchData{Fns: fns},
+		Priority: PriorityRealtime,
+	})
+	if err != nil {
+		errs := make([]error, len(fns))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	return result.Data.([]error)
+}
+
+// ExecuteTransaction runs ops in order as a single OpExecuteTransaction
+// operation: if every op succeeds, their effects stick; if one fails, every
+// op applied before it is rolled back (in reverse of application, restoring
+// exactly the state executeTransaction found at the start) and the first
+// error is returned. Suited to scene recalls and preset loads built out of
+// individual create/connect/mute/param ops, where a partial apply would
+// leave the graph in a state no caller asked for - see CaptureScene/
+// RecallScene for the equivalent built around a named, persisted snapshot
+// rather than an ad hoc op list.
+func (d *Dispatcher) ExecuteTransaction(ops []DispatcherOperation) error {
+	result, err := d.Submit(context.Background(), DispatcherOperation{
+		Type:     OpExecuteTransaction,
+		Data:     ExecuteTransactionData{Ops: ops},
+		Priority: PriorityNormal,
+	})
+	if err != nil {
+		return err
+	}
+	return result.Error
+}
+
+// Snapshot captures the engine's current topology via the dispatcher, the
+// same serialization guarantee CaptureScene runs under, as a restorable
+// EngineSnapshot - for a caller that wants a point-in-time capture to
+// Restore later without naming and persisting a SceneManager scene.
+func (d *Dispatcher) Snapshot() (*EngineSnapshot, error) {
+	result, err := d.Submit(context.Background(), DispatcherOperation{
+		Type:     OpSnapshot,
+		Priority: PriorityNormal,
+	})
+	if err != nil {
+		return nil, err
+	}
+	snap := result.Data.(EngineSnapshot)
+	return &snap, nil
+}
+
+// Restore applies snap to the engine, touching only what differs from the
+// engine's current topology: a channel present in snap but not currently on
+// the engine is created, a channel no longer in snap is removed, and a
+// channel present in both is left alone unless its serialized state
+// actually differs, in which case just that channel's SetState is called -
+// unlike RecallScene/Serializer.SetState, which tear down and recreate
+// every non-master channel regardless of whether snap changes it. If
+// applying a change partway through fails, Restore rolls the engine back to
+// its state from just before the call, the same as ExecuteTransaction.
+func (d *Dispatcher) Restore(snap *EngineSnapshot) error {
+	if snap == nil {
+		return fmt.Errorf("snapshot is nil")
+	}
+	result, err := d.Submit(context.Background(), DispatcherOperation{
+		Type:     OpRestoreSnapshot,
+		Data:     RestoreSnapshotData{Snapshot: *snap},
+		Priority: PriorityNormal,
+	})
+	if err != nil {
+		return err
+	}
+	return result.Error
+}
+
+// submit is the shared implementation behind this package's own
+// CreateXChannel/SetX dispatcher methods: it's equivalent to Submit with a
+// background context, so this internal traffic always waits for queue room
+// rather than ever being shed - callers inside this package are bounded by
+// how fast application code invokes them, not by an external client's
+// behavior, so TrySubmit's shedding isn't needed here.
+func (d *Dispatcher) submit(op DispatcherOperation) DispatcherResult {
+	return d.submitCtx(context.Background(), op)
+}
+
+// submitCtx is submit's ctx-aware counterpart, behind every ...Ctx public
+// wrapper (see CreateAudioInputChannelCtx, SetChannelMuteCtx,
+// ConnectChannelsCtx, ChangeOutputDeviceCtx): it fills in op.Deadline from
+// GetDefaultOperationTimeout when the caller hasn't set one, so an op that's
+// still queued (or, per runQueuedOperation, whose Deadline already passed)
+// comes back as ErrDispatcherTimeout instead of blocking the caller
+// forever behind a stuck queue.
+func (d *Dispatcher) submitCtx(ctx context.Context, op DispatcherOperation) DispatcherResult {
+	if op.Source == "" {
+		op.Source = SourceDispatcher
+	}
+	if op.Deadline.IsZero() {
+		if timeout := d.GetDefaultOperationTimeout(); timeout > 0 {
+			op.Deadline = time.Now().Add(timeout)
+		}
+	}
+	result, err := d.Submit(ctx, op)
+	if err != nil {
+		return DispatcherResult{Error: err}
+	}
+	return result
+}
+
 // Data structures for dispatcher operations
 
 // Engine operation data structures
@@ -239,13 +1384,13 @@ type SetMuteData struct {
 }
 
 type PluginBypassData struct {
-	ChannelID  string
-	PluginID   string
-	Bypassed   bool
+	ChannelID string
+	PluginID  string
+	Bypassed  bool
 }
 
 type DeviceChangeData struct {
-	ChannelID   string
+	ChannelID    string
 	NewDeviceUID string
 }
 
@@ -253,12 +1398,95 @@ type OutputDeviceChangeData struct {
 	NewDeviceUID string
 }
 
+type InputDeviceChangeData struct {
+	NewDeviceUID string
+}
+
+type SetMasterLimiterData struct {
+	Enabled bool
+}
+
+type SetSpatialParamsData struct {
+	ChannelID string
+	Config    SpatialConfig
+	// Duration/Curve shape the position glide within Config; see
+	// Dispatcher.SetSpatialParams.
+	Duration time.Duration
+	Curve    RampCurve
+}
+
+// RunBatchData wraps a sequence of closures OpRunBatch runs in order,
+// inside dispatchLoop, so they execute as one atomic step relative to every
+// other queued operation - see Dispatcher.SubmitBatch.
+type RunBatchData struct {
+	Fns []func() error
+}
+
+type CaptureSceneData struct {
+	Name string
+}
+
+// ExecuteTransactionData wraps ExecuteTransaction's op list through the
+// dispatcher queue.
+type ExecuteTransactionData struct {
+	Ops []DispatcherOperation
+}
+
+// RestoreSnapshotData carries Restore's target snapshot through the
+// dispatcher queue.
+type RestoreSnapshotData struct {
+	Snapshot EngineSnapshot
+}
+
+// EngineSnapshot is Dispatcher.Snapshot/Restore's point-in-time capture of
+// the engine's full topology: every channel's config/state and every
+// connection, under the same Version field and migration support as
+// EngineState (see RegisterMigration) - it's the same shape, just owned by
+// the dispatcher rather than a persisted, named SceneManager scene (see
+// CaptureScene/RecallScene for that path).
+type EngineSnapshot EngineState
+
+// RecallOptions configures Dispatcher.RecallScene/recallScene, beyond the
+// raw target scene: whether to fade, which channels to touch, and whether
+// to leave hardware devices alone.
+type RecallOptions struct {
+	// FadeDuration, if > 0, glides channel volumes to the scene's values
+	// using SetVolumeRamp instead of snapping to them instantly.
+	FadeDuration time.Duration
+	// Curve selects the ramp shape used when FadeDuration > 0; ignored
+	// otherwise.
+	Curve RampCurve
+	// ChannelIDs, if non-empty, restricts recall to only these channel
+	// IDs - every other channel is left exactly as it was. An empty slice
+	// (the zero value) means "every channel in the scene", matching
+	// recallScene's original all-channels behavior.
+	ChannelIDs []string
+	// Soft skips device changes (see Dispatcher.changeChannelDevice):
+	// mute, volume, pan, plugin bypass, and routing are still restored,
+	// but a channel already pointed at a live device is left on it. Useful
+	// for recalling a scene captured on different hardware (a different
+	// room, a travel rig) without the recall itself disrupting audio I/O.
+	Soft bool
+}
+
+// RecallSceneData carries RecallScene's target scene and its RecallOptions
+// through the dispatcher queue.
+type RecallSceneData struct {
+	Name    string
+	Options RecallOptions
+}
+
 // Channel operation data structures
 type CreateAudioInputData struct {
 	ID     string
 	Config AudioInputConfig
 }
 
+type CreateLoopbackInputData struct {
+	ID     string
+	Config LoopbackConfig
+}
+
 type CreateMidiInputData struct {
 	ID     string
 	Config MidiInputConfig
@@ -269,11 +1497,45 @@ type CreatePlaybackData struct {
 	Config PlaybackConfig
 }
 
+type CreatePlaybackFromDecoderData struct {
+	ID      string
+	Decoder Decoder
+}
+
 type CreateAuxData struct {
 	ID     string
 	Config AuxConfig
 }
 
+type CreateProcessingData struct {
+	ID     string
+	Config ProcessingConfig
+}
+
+type CreateSynthData struct {
+	ID     string
+	Config SynthConfig
+}
+
+type CreateSamplerData struct {
+	ID     string
+	Config SamplerConfig
+}
+
+type CreateBusData struct {
+	ID     string
+	Config BusConfig
+}
+
+type CreateGroupData struct {
+	ID string
+}
+
+type AssignChannelToGroupData struct {
+	GroupID   string
+	ChannelID string
+}
+
 type ConnectChannelsData struct {
 	SourceID string
 	TargetID string
@@ -290,36 +1552,50 @@ type DisconnectChannelsData struct {
 
 // CreateAudioInputChannel creates a new audio input channel via dispatcher
 func (d *Dispatcher) CreateAudioInputChannel(id string, config AudioInputConfig) (*AudioInputChannel, error) {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpCreateAudioInput,
-		Data:     CreateAudioInputData{ID: id, Config: config},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateAudioInput,
+		Data: CreateAudioInputData{ID: id, Config: config},
+	})
+	if result.Success {
+		return result.Data.(*AudioInputChannel), nil
+	}
+	return nil, result.Error
+}
+
+// CreateAudioInputChannelCtx is CreateAudioInputChannel, except ctx governs
+// the wait for queue room (see Submit): if ctx is canceled before the op
+// reaches the front of the queue, it's dropped and ctx.Err() is returned
+// without creating a channel, rather than blocking the caller forever
+// behind a backlog.
+func (d *Dispatcher) CreateAudioInputChannelCtx(ctx context.Context, id string, config AudioInputConfig) (*AudioInputChannel, error) {
+	result := d.submitCtx(ctx, DispatcherOperation{
+		Type: OpCreateAudioInput,
+		Data: CreateAudioInputData{ID: id, Config: config},
+	})
 	if result.Success {
 		return result.Data.(*AudioInputChannel), nil
 	}
 	return nil, result.Error
 }
 
+// CreateLoopbackChannel creates a new loopback input channel via dispatcher
+func (d *Dispatcher) CreateLoopbackChannel(id string, config LoopbackConfig) (*LoopbackInputChannel, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateLoopbackInput,
+		Data: CreateLoopbackInputData{ID: id, Config: config},
+	})
+	if result.Success {
+		return result.Data.(*LoopbackInputChannel), nil
+	}
+	return nil, result.Error
+}
+
 // CreateMidiInputChannel creates a new MIDI input channel via dispatcher
 func (d *Dispatcher) CreateMidiInputChannel(id string, config MidiInputConfig) (*MidiInputChannel, error) {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpCreateMidiInput,
-		Data:     CreateMidiInputData{ID: id, Config: config},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateMidiInput,
+		Data: CreateMidiInputData{ID: id, Config: config},
+	})
 	if result.Success {
 		return result.Data.(*MidiInputChannel), nil
 	}
@@ -328,17 +1604,23 @@ func (d *Dispatcher) CreateMidiInputChannel(id string, config MidiInputConfig) (
 
 // CreatePlaybackChannel creates a new playback channel via dispatcher
 func (d *Dispatcher) CreatePlaybackChannel(id string, config PlaybackConfig) (*PlaybackChannel, error) {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpCreatePlayback,
-		Data:     CreatePlaybackData{ID: id, Config: config},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
+	result := d.submit(DispatcherOperation{
+		Type: OpCreatePlayback,
+		Data: CreatePlaybackData{ID: id, Config: config},
+	})
+	if result.Success {
+		return result.Data.(*PlaybackChannel), nil
+	}
+	return nil, result.Error
+}
+
+// CreatePlaybackChannelFromDecoder creates a new decoder-backed playback
+// channel via dispatcher
+func (d *Dispatcher) CreatePlaybackChannelFromDecoder(id string, dec Decoder) (*PlaybackChannel, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpCreatePlaybackFromDecoder,
+		Data: CreatePlaybackFromDecoderData{ID: id, Decoder: dec},
+	})
 	if result.Success {
 		return result.Data.(*PlaybackChannel), nil
 	}
@@ -347,244 +1629,645 @@ func (d *Dispatcher) CreatePlaybackChannel(id string, config PlaybackConfig) (*P
 
 // CreateAuxChannel creates a new auxiliary channel via dispatcher
 func (d *Dispatcher) CreateAuxChannel(id string, config AuxConfig) (*AuxChannel, error) {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpCreateAux,
-		Data:     CreateAuxData{ID: id, Config: config},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateAux,
+		Data: CreateAuxData{ID: id, Config: config},
+	})
 	if result.Success {
 		return result.Data.(*AuxChannel), nil
 	}
 	return nil, result.Error
 }
 
+// CreateProcessingChannel creates a new processing channel via dispatcher
+func (d *Dispatcher) CreateProcessingChannel(id string, config ProcessingConfig) (*ProcessingChannel, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateProcessing,
+		Data: CreateProcessingData{ID: id, Config: config},
+	})
+	if result.Success {
+		return result.Data.(*ProcessingChannel), nil
+	}
+	return nil, result.Error
+}
+
+// CreateSynthChannel creates a new synth channel via dispatcher
+func (d *Dispatcher) CreateSynthChannel(id string, config SynthConfig) (*SynthChannel, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateSynth,
+		Data: CreateSynthData{ID: id, Config: config},
+	})
+	if result.Success {
+		return result.Data.(*SynthChannel), nil
+	}
+	return nil, result.Error
+}
+
+// CreateSamplerChannel creates a new sampler channel via dispatcher
+func (d *Dispatcher) CreateSamplerChannel(id string, config SamplerConfig) (*SamplerChannel, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateSampler,
+		Data: CreateSamplerData{ID: id, Config: config},
+	})
+	if result.Success {
+		return result.Data.(*SamplerChannel), nil
+	}
+	return nil, result.Error
+}
+
+// CreateBus creates a new bus channel via dispatcher
+func (d *Dispatcher) CreateBus(name string, config BusConfig) (*Bus, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateBus,
+		Data: CreateBusData{ID: name, Config: config},
+	})
+	if result.Success {
+		return result.Data.(*Bus), nil
+	}
+	return nil, result.Error
+}
+
+// CreateGroup creates a new VCA-style control group via dispatcher.
+func (d *Dispatcher) CreateGroup(name string) (*ChannelGroup, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpCreateGroup,
+		Data: CreateGroupData{ID: name},
+	})
+	if result.Success {
+		return result.Data.(*ChannelGroup), nil
+	}
+	return nil, result.Error
+}
+
+// AssignChannelToGroup assigns channelID into groupID (see
+// ChannelGroup.AssignChannel) via dispatcher, so membership changes
+// serialize with OpSetMute and device changes.
+func (d *Dispatcher) AssignChannelToGroup(groupID, channelID string) error {
+	return d.submit(DispatcherOperation{
+		Type: OpAssignChannelToGroup,
+		Data: AssignChannelToGroupData{GroupID: groupID, ChannelID: channelID},
+	}).Error
+}
+
+// DestroyGroup removes a group via dispatcher. A ChannelGroup is a Channel
+// like any other, so this is RemoveChannel under a name that matches the
+// request's Create/Destroy pairing - there's no group-specific teardown
+// beyond what RemoveChannel already does for a Bus.
+func (d *Dispatcher) DestroyGroup(id string) error {
+	return d.RemoveChannel(id)
+}
+
 // RemoveChannel removes a channel via dispatcher
 func (d *Dispatcher) RemoveChannel(id string) error {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpRemoveChannel,
-		Data:     id,
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
-	return result.Error
+	return d.submit(DispatcherOperation{
+		Type: OpRemoveChannel,
+		Data: id,
+	}).Error
+}
+
+// FadeOutAndRemoveChannel ramps id's volume to silence over duration using
+// curve, waits for the ramp to finish, then removes the channel - the
+// dispatcher-level sequencing SetVolumeRamp's completion channel exists for,
+// so a fade-out can't race RemoveChannel tearing the channel's mixer down
+// out from under an in-flight ramp.
+func (d *Dispatcher) FadeOutAndRemoveChannel(id string, duration time.Duration, curve RampCurve) error {
+	channel, exists := d.engine.GetChannel(id)
+	if !exists {
+		return fmt.Errorf("channel %s not found", id)
+	}
+
+	done, err := channel.SetVolumeRamp(0, duration, curve)
+	if err != nil {
+		return fmt.Errorf("failed to start fade-out for channel %s: %w", id, err)
+	}
+	<-done
+
+	return d.RemoveChannel(id)
 }
 
 // ConnectChannels connects two channels via dispatcher
 func (d *Dispatcher) ConnectChannels(sourceID, targetID string, bus int) error {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpConnectChannels,
-		Data:     ConnectChannelsData{SourceID: sourceID, TargetID: targetID, Bus: bus},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
-	return result.Error
+	return d.submit(DispatcherOperation{
+		Type: OpConnectChannels,
+		Data: ConnectChannelsData{SourceID: sourceID, TargetID: targetID, Bus: bus},
+	}).Error
+}
+
+// ConnectChannelsCtx is ConnectChannels, except ctx governs the wait for
+// queue room the same way CreateAudioInputChannelCtx's does.
+func (d *Dispatcher) ConnectChannelsCtx(ctx context.Context, sourceID, targetID string, bus int) error {
+	return d.submitCtx(ctx, DispatcherOperation{
+		Type: OpConnectChannels,
+		Data: ConnectChannelsData{SourceID: sourceID, TargetID: targetID, Bus: bus},
+	}).Error
+}
+
+// DisconnectChannels disconnects two channels via dispatcher
+func (d *Dispatcher) DisconnectChannels(sourceID, targetID string, bus int) error {
+	return d.submit(DispatcherOperation{
+		Type: OpDisconnectChannels,
+		Data: DisconnectChannelsData{SourceID: sourceID, TargetID: targetID, Bus: bus},
+	}).Error
+}
+
+// Topology-changing operations (require dispatcher for race prevention)
+
+// SetChannelMute sets channel mute state via dispatcher (topology change).
+// Explicitly tagged PriorityRealtime (the zero value already, but worth
+// spelling out here): a mute toggle is something a user is waiting on right
+// now, and should never queue behind a PriorityBulk operation like a SysEx
+// dump.
+func (d *Dispatcher) SetChannelMute(channelID string, muted bool) error {
+	return d.submit(DispatcherOperation{
+		Type:     OpSetMute,
+		Data:     SetMuteData{ChannelID: channelID, Muted: muted},
+		Priority: PriorityRealtime,
+	}).Error
 }
 
-// DisconnectChannels disconnects two channels via dispatcher
-func (d *Dispatcher) DisconnectChannels(sourceID, targetID string, bus int) error {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpDisconnectChannels,
-		Data:     DisconnectChannelsData{SourceID: sourceID, TargetID: targetID, Bus: bus},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
-	return result.Error
+// SetChannelMuteCtx is SetChannelMute, except ctx governs the wait for
+// queue room the same way CreateAudioInputChannelCtx's does.
+func (d *Dispatcher) SetChannelMuteCtx(ctx context.Context, channelID string, muted bool) error {
+	return d.submitCtx(ctx, DispatcherOperation{
+		Type:     OpSetMute,
+		Data:     SetMuteData{ChannelID: channelID, Muted: muted},
+		Priority: PriorityRealtime,
+	}).Error
+}
+
+// SetPluginBypass sets plugin bypass state via dispatcher (topology change)
+func (d *Dispatcher) SetPluginBypass(channelID, pluginID string, bypassed bool) error {
+	return d.submit(DispatcherOperation{
+		Type: OpPluginBypass,
+		Data: PluginBypassData{ChannelID: channelID, PluginID: pluginID, Bypassed: bypassed},
+	}).Error
+}
+
+// ChangeChannelDevice changes the device for a channel via dispatcher (topology change)
+func (d *Dispatcher) ChangeChannelDevice(channelID, newDeviceUID string) error {
+	return d.submit(DispatcherOperation{
+		Type: OpDeviceChange,
+		Data: DeviceChangeData{ChannelID: channelID, NewDeviceUID: newDeviceUID},
+	}).Error
+}
+
+// ChangeOutputDevice changes the engine's output device via dispatcher (topology change)
+func (d *Dispatcher) ChangeOutputDevice(newDeviceUID string) error {
+	return d.submit(DispatcherOperation{
+		Type: OpOutputDeviceChange,
+		Data: OutputDeviceChangeData{NewDeviceUID: newDeviceUID},
+	}).Error
+}
+
+// ChangeOutputDeviceCtx is ChangeOutputDevice, except ctx governs the wait
+// for queue room the same way CreateAudioInputChannelCtx's does. Since an
+// output device change is a heavy, in-flight reconfiguration once it starts
+// executing, canceling ctx only ever drops it before that point - it never
+// interrupts a change already underway (see runOperation).
+func (d *Dispatcher) ChangeOutputDeviceCtx(ctx context.Context, newDeviceUID string) error {
+	return d.submitCtx(ctx, DispatcherOperation{
+		Type: OpOutputDeviceChange,
+		Data: OutputDeviceChangeData{NewDeviceUID: newDeviceUID},
+	}).Error
+}
+
+// ChangeInputDevice changes the engine's single shared input device via
+// dispatcher (topology change); see Dispatcher.changeInputDevice.
+func (d *Dispatcher) ChangeInputDevice(newDeviceUID string) error {
+	return d.submit(DispatcherOperation{
+		Type: OpInputDeviceChange,
+		Data: InputDeviceChangeData{NewDeviceUID: newDeviceUID},
+	}).Error
+}
+
+// SetMasterLimiterEnabled inserts or removes the master limiter via
+// dispatcher (topology change); see MasterChannel.applyLimiterEnabled.
+func (d *Dispatcher) SetMasterLimiterEnabled(enabled bool) error {
+	return d.submit(DispatcherOperation{
+		Type: OpSetMasterLimiter,
+		Data: SetMasterLimiterData{Enabled: enabled},
+	}).Error
+}
+
+// SetSpatialParams applies config's rendering algorithm, distance
+// attenuation, directivity cone, and position to channelID via the
+// dispatcher (topology change), gliding position to config.Position over
+// duration using curve (duration <= 0 applies immediately); see
+// BaseChannel.Spatialize. The returned channel closes once the position
+// glide completes or is canceled/superseded, nil if duration <= 0.
+func (d *Dispatcher) SetSpatialParams(channelID string, config SpatialConfig, duration time.Duration, curve RampCurve) (<-chan struct{}, error) {
+	result := d.submit(DispatcherOperation{
+		Type: OpSetSpatialParams,
+		Data: SetSpatialParamsData{ChannelID: channelID, Config: config, Duration: duration, Curve: curve},
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	done, _ := result.Data.(<-chan struct{})
+	return done, nil
+}
+
+// CaptureScene snapshots the engine's current state under name via the
+// dispatcher, so the snapshot can't land mid-way through a concurrent
+// OpSetMute/OpPluginBypass operation; see Dispatcher.captureScene.
+func (d *Dispatcher) CaptureScene(name string) error {
+	return d.submit(DispatcherOperation{
+		Type: OpCaptureScene,
+		Data: CaptureSceneData{Name: name},
+	}).Error
+}
+
+// RecallScene restores the named scene via the dispatcher, for the same
+// race-free reason as CaptureScene. With opts.FadeDuration <= 0 it applies
+// instantly; with opts.FadeDuration > 0 it glides channel volumes to the
+// scene's values using the ramp API (see SetVolumeRamp) instead of
+// snapping. See Dispatcher.recallScene for what opts.ChannelIDs and
+// opts.Soft restrict.
+func (d *Dispatcher) RecallScene(name string, opts RecallOptions) error {
+	return d.submit(DispatcherOperation{
+		Type: OpRecallScene,
+		Data: RecallSceneData{Name: name, Options: opts},
+	}).Error
+}
+
+// Internal implementation methods (executed within dispatcher thread)
+
+func (d *Dispatcher) createAudioInput(id string, config AudioInputConfig) (*AudioInputChannel, error) {
+	d.AssertOnDispatcher()
+
+	var aggregateUID string
+	if config.Aggregate != nil {
+		device, err := d.createAggregateInputDevice(*config.Aggregate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create aggregate input device: %w", err)
+		}
+		aggregateUID = device.UID
+		config.DeviceUID = aggregateUID
+	}
+
+	channel, err := NewAudioInputChannel(id, config, d.engine)
+	if err != nil {
+		if aggregateUID != "" {
+			if destroyErr := devices.DestroyAggregate(aggregateUID); destroyErr != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to clean up aggregate input device %s: %w", aggregateUID, destroyErr))
+			}
+		}
+		return nil, err
+	}
+	channel.aggregateDeviceUID = aggregateUID
+
+	if err := d.engine.addChannel(channel); err != nil {
+		if aggregateUID != "" {
+			if destroyErr := devices.DestroyAggregate(aggregateUID); destroyErr != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to clean up aggregate input device %s: %w", aggregateUID, destroyErr))
+			}
+		}
+		return nil, err
+	}
+
+	// Auto-connect to master if specified in config
+	// TODO: Add auto-connect configuration
+
+	return channel, nil
+}
+
+// createAggregateInputDevice composes opts.Devices into a CoreAudio
+// aggregate device for a single AudioInputChannel, mirroring
+// Engine.ensureAggregateOutput's use of devices.CreateAggregate but built
+// explicitly per channel rather than inferred from the engine's output
+// routes - an input channel only ever reads from the one aggregate it asks
+// for, so there's no route set to derive it from.
+func (d *Dispatcher) createAggregateInputDevice(opts AggregateInputOptions) (devices.AudioDevice, error) {
+	if len(opts.Devices) < 2 {
+		return devices.AudioDevice{}, fmt.Errorf("aggregate input needs at least 2 devices, got %d", len(opts.Devices))
+	}
+
+	uids := make([]string, 0, len(opts.Devices))
+	for _, device := range opts.Devices {
+		if device == nil {
+			return devices.AudioDevice{}, fmt.Errorf("aggregate input device list contains a nil device")
+		}
+		uids = append(uids, device.UID)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "Aggregate Input"
+	}
+
+	return devices.CreateAggregate(devices.AggregateSpec{
+		Name:            name,
+		SubDeviceUIDs:   uids,
+		MasterUID:       opts.MasterUID,
+		DriftCompensate: opts.DriftCompensate,
+	})
+}
+
+// createLoopbackInput resolves config to a backing deviceUID - a Core Audio
+// process tap when config.ProcessID is set, or config.SubmixUID directly
+// otherwise - then creates the channel against it, mirroring createAudioInput's
+// create-backing-device/create-channel/cleanup-on-error structure for
+// aggregate input devices.
+func (d *Dispatcher) createLoopbackInput(id string, config LoopbackConfig) (*LoopbackInputChannel, error) {
+	d.AssertOnDispatcher()
+
+	deviceUID := config.SubmixUID
+	var tapUID string
+	if deviceUID == "" {
+		uid, err := devices.CreateProcessTap(config.ProcessID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create process tap: %w", err)
+		}
+		tapUID = uid
+		deviceUID = uid
+	}
+
+	channel, err := NewLoopbackInputChannel(id, config, deviceUID, d.engine)
+	if err != nil {
+		if tapUID != "" {
+			if destroyErr := devices.DestroyProcessTap(tapUID); destroyErr != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to clean up process tap %s: %w", tapUID, destroyErr))
+			}
+		}
+		return nil, err
+	}
+	channel.tapUID = tapUID
+
+	if err := d.engine.addChannel(channel); err != nil {
+		if tapUID != "" {
+			if destroyErr := devices.DestroyProcessTap(tapUID); destroyErr != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to clean up process tap %s: %w", tapUID, destroyErr))
+			}
+		}
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+func (d *Dispatcher) createMidiInput(id string, config MidiInputConfig) (*MidiInputChannel, error) {
+	d.AssertOnDispatcher()
+
+	channel, err := NewMidiInputChannel(id, config, d.engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.engine.addChannel(channel); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+func (d *Dispatcher) createPlayback(id string, config PlaybackConfig) (*PlaybackChannel, error) {
+	d.AssertOnDispatcher()
+
+	channel, err := NewPlaybackChannel(id, config, d.engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.engine.addChannel(channel); err != nil {
+		return nil, err
+	}
+
+	// Auto-connect to master
+	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect playback to master: %w", err))
+	}
+
+	return channel, nil
+}
+
+func (d *Dispatcher) createPlaybackFromDecoder(id string, dec Decoder) (*PlaybackChannel, error) {
+	d.AssertOnDispatcher()
+
+	channel, err := NewPlaybackChannelFromDecoder(id, dec, d.engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.engine.addChannel(channel); err != nil {
+		return nil, err
+	}
+
+	// Auto-connect to master
+	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect playback to master: %w", err))
+	}
+
+	return channel, nil
+}
+
+func (d *Dispatcher) createAux(id string, config AuxConfig) (*AuxChannel, error) {
+	d.AssertOnDispatcher()
+
+	channel, err := NewAuxChannel(id, config, d.engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.engine.addChannel(channel); err != nil {
+		return nil, err
+	}
+
+	// Auto-connect to master
+	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect aux to master: %w", err))
+	}
+
+	return channel, nil
 }
 
-// Topology-changing operations (require dispatcher for race prevention)
+func (d *Dispatcher) createProcessing(id string, config ProcessingConfig) (*ProcessingChannel, error) {
+	d.AssertOnDispatcher()
 
-// SetChannelMute sets channel mute state via dispatcher (topology change)
-func (d *Dispatcher) SetChannelMute(channelID string, muted bool) error {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpSetMute,
-		Data:     SetMuteData{ChannelID: channelID, Muted: muted},
-		Response: response,
+	channel, err := NewProcessingChannel(id, config, d.engine)
+	if err != nil {
+		return nil, err
 	}
-	
-	d.operations <- op
-	result := <-response
-	
-	return result.Error
-}
 
-// SetPluginBypass sets plugin bypass state via dispatcher (topology change)
-func (d *Dispatcher) SetPluginBypass(channelID, pluginID string, bypassed bool) error {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpPluginBypass,
-		Data:     PluginBypassData{ChannelID: channelID, PluginID: pluginID, Bypassed: bypassed},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
-	return result.Error
-}
+	if err := d.engine.addChannel(channel); err != nil {
+		return nil, err
+	}
 
-// ChangeChannelDevice changes the device for a channel via dispatcher (topology change)
-func (d *Dispatcher) ChangeChannelDevice(channelID, newDeviceUID string) error {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpDeviceChange,
-		Data:     DeviceChangeData{ChannelID: channelID, NewDeviceUID: newDeviceUID},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
-	return result.Error
-}
+	// Auto-connect to master
+	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect processing channel to master: %w", err))
+	}
 
-// ChangeOutputDevice changes the engine's output device via dispatcher (topology change)
-func (d *Dispatcher) ChangeOutputDevice(newDeviceUID string) error {
-	response := make(chan DispatcherResult, 1)
-	
-	op := DispatcherOperation{
-		Type:     OpOutputDeviceChange,
-		Data:     OutputDeviceChangeData{NewDeviceUID: newDeviceUID},
-		Response: response,
-	}
-	
-	d.operations <- op
-	result := <-response
-	
-	return result.Error
+	return channel, nil
 }
 
-// Internal implementation methods (executed within dispatcher thread)
+func (d *Dispatcher) createSynth(id string, config SynthConfig) (*SynthChannel, error) {
+	d.AssertOnDispatcher()
 
-func (d *Dispatcher) createAudioInput(id string, config AudioInputConfig) (*AudioInputChannel, error) {
-	channel, err := NewAudioInputChannel(id, config, d.engine)
+	channel, err := NewSynthChannel(id, config, d.engine)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := d.engine.addChannel(channel); err != nil {
 		return nil, err
 	}
-	
-	// Auto-connect to master if specified in config
-	// TODO: Add auto-connect configuration
-	
+
+	// Auto-connect to master
+	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect synth channel to master: %w", err))
+	}
+
 	return channel, nil
 }
 
-func (d *Dispatcher) createMidiInput(id string, config MidiInputConfig) (*MidiInputChannel, error) {
-	channel, err := NewMidiInputChannel(id, config, d.engine)
+func (d *Dispatcher) createSampler(id string, config SamplerConfig) (*SamplerChannel, error) {
+	d.AssertOnDispatcher()
+
+	channel, err := NewSamplerChannel(id, config, d.engine)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := d.engine.addChannel(channel); err != nil {
 		return nil, err
 	}
-	
+
+	// Auto-connect to master
+	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect sampler channel to master: %w", err))
+	}
+
 	return channel, nil
 }
 
-func (d *Dispatcher) createPlayback(id string, config PlaybackConfig) (*PlaybackChannel, error) {
-	channel, err := NewPlaybackChannel(id, config, d.engine)
+func (d *Dispatcher) createBus(id string, config BusConfig) (*Bus, error) {
+	d.AssertOnDispatcher()
+
+	channel, err := NewBus(id, config, d.engine)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := d.engine.addChannel(channel); err != nil {
 		return nil, err
 	}
-	
+
 	// Auto-connect to master
 	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
-		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect playback to master: %w", err))
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect bus to master: %w", err))
 	}
-	
+
 	return channel, nil
 }
 
-func (d *Dispatcher) createAux(id string, config AuxConfig) (*AuxChannel, error) {
-	channel, err := NewAuxChannel(id, config, d.engine)
+func (d *Dispatcher) createGroup(id string) (*ChannelGroup, error) {
+	d.AssertOnDispatcher()
+
+	channel, err := NewChannelGroup(id, d.engine)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := d.engine.addChannel(channel); err != nil {
 		return nil, err
 	}
-	
+
 	// Auto-connect to master
 	if err := channel.ConnectTo(d.engine.masterChannel, 0); err != nil {
-		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect aux to master: %w", err))
+		d.engine.errorHandler.HandleError(fmt.Errorf("failed to auto-connect group to master: %w", err))
 	}
-	
+
 	return channel, nil
 }
 
+func (d *Dispatcher) assignChannelToGroup(groupID, channelID string) error {
+	d.AssertOnDispatcher()
+
+	groupChannel, exists := d.engine.GetChannel(groupID)
+	if !exists {
+		return fmt.Errorf("group %s not found", groupID)
+	}
+	group, ok := groupChannel.(*ChannelGroup)
+	if !ok {
+		return fmt.Errorf("channel %s is not a group", groupID)
+	}
+
+	channel, exists := d.engine.GetChannel(channelID)
+	if !exists {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return group.AssignChannel(channel)
+}
+
 func (d *Dispatcher) removeChannel(id string) error {
+	d.AssertOnDispatcher()
+
+	// Tear down any aggregate input device backing this channel before
+	// freeing the bus, so the aggregate doesn't outlive the channel that
+	// owns it.
+	if channel, exists := d.engine.GetChannel(id); exists {
+		if input, ok := channel.(*AudioInputChannel); ok && input.aggregateDeviceUID != "" {
+			if err := devices.DestroyAggregate(input.aggregateDeviceUID); err != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to destroy aggregate input device %s: %w", input.aggregateDeviceUID, err))
+			}
+		}
+		if loopback, ok := channel.(*LoopbackInputChannel); ok && loopback.tapUID != "" {
+			if err := devices.DestroyProcessTap(loopback.tapUID); err != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to destroy process tap %s: %w", loopback.tapUID, err))
+			}
+		}
+	}
+
 	return d.engine.removeChannel(id)
 }
 
 func (d *Dispatcher) connectChannels(sourceID, targetID string, bus int) error {
+	d.AssertOnDispatcher()
+
 	sourceChannel, exists := d.engine.GetChannel(sourceID)
 	if !exists {
 		return fmt.Errorf("source channel %s not found", sourceID)
 	}
-	
+
 	targetChannel, exists := d.engine.GetChannel(targetID)
 	if !exists {
 		return fmt.Errorf("target channel %s not found", targetID)
 	}
-	
+
 	return sourceChannel.ConnectTo(targetChannel, bus)
 }
 
 func (d *Dispatcher) disconnectChannels(sourceID, targetID string, bus int) error {
+	d.AssertOnDispatcher()
+
 	sourceChannel, exists := d.engine.GetChannel(sourceID)
 	if !exists {
 		return fmt.Errorf("source channel %s not found", sourceID)
 	}
-	
+
 	targetChannel, exists := d.engine.GetChannel(targetID)
 	if !exists {
 		return fmt.Errorf("target channel %s not found", targetID)
 	}
-	
+
 	return sourceChannel.DisconnectFrom(targetChannel, bus)
 }
 
 // Engine lifecycle operations (serialized through dispatcher)
 
 func (d *Dispatcher) startEngine() error {
+	d.AssertOnDispatcher()
+
 	// This is the actual engine start logic moved from Engine.Start()
 	if err := d.engine.validateEngineReadiness(); err != nil {
 		return fmt.Errorf("engine validation failed: %w", err)
 	}
 
+	if err := d.engine.ensureAggregateOutput(); err != nil {
+		return err
+	}
+
 	if err := d.engine.prepareAVFoundationSafely(); err != nil {
 		return fmt.Errorf("failed to prepare AVFoundation engine: %w", err)
 	}
@@ -605,7 +2288,7 @@ func (d *Dispatcher) startEngine() error {
 		return fmt.Errorf("failed to start AVFoundation engine: %w", err)
 	}
 
-	// Start device monitoring
+	// Start device monitoring.
 	if err := d.engine.deviceMonitor.Start(); err != nil {
 		d.engine.avEngine.Stop()
 		return fmt.Errorf("failed to start device monitor: %w", err)
@@ -615,6 +2298,8 @@ func (d *Dispatcher) startEngine() error {
 }
 
 func (d *Dispatcher) stopEngine() error {
+	d.AssertOnDispatcher()
+
 	// Stop all channels first
 	for _, channel := range d.engine.channels {
 		if err := channel.Stop(); err != nil {
@@ -636,6 +2321,8 @@ func (d *Dispatcher) stopEngine() error {
 // Topology changing operations (require dispatcher serialization)
 
 func (d *Dispatcher) setMute(channelID string, muted bool) error {
+	d.AssertOnDispatcher()
+
 	channel, exists := d.engine.GetChannel(channelID)
 	if !exists {
 		return fmt.Errorf("channel %s not found", channelID)
@@ -643,7 +2330,7 @@ func (d *Dispatcher) setMute(channelID string, muted bool) error {
 
 	// This is a topology change, so it goes through dispatcher
 	// The actual AVFoundation mute will happen here
-	
+
 	// Handle different channel types that embed BaseChannel
 	var baseChannel *BaseChannel
 	switch ch := channel.(type) {
@@ -659,11 +2346,10 @@ func (d *Dispatcher) setMute(channelID string, muted bool) error {
 
 	if baseChannel != nil {
 		baseChannel.mu.Lock()
-		defer baseChannel.mu.Unlock()
-		
+
 		oldMuted := baseChannel.muted
 		baseChannel.muted = muted
-		
+
 		// Apply mute directly to AVFoundation without changing volume
 		if baseChannel.outputMixer != nil && oldMuted != muted {
 			if muted {
@@ -694,37 +2380,349 @@ func (d *Dispatcher) setMute(channelID string, muted bool) error {
 				}
 			}
 		}
+		baseChannel.mu.Unlock()
+
+		baseChannel.notifyMuteChanged(oldMuted, muted)
 	}
 
 	return nil
 }
 
 func (d *Dispatcher) setPluginBypass(channelID, pluginID string, bypassed bool) error {
+	d.AssertOnDispatcher()
+
 	channel, exists := d.engine.GetChannel(channelID)
 	if !exists {
 		return fmt.Errorf("channel %s not found", channelID)
 	}
 
-	pluginChain := channel.GetPluginChain()
-	instance, exists := pluginChain.GetInstance(pluginID)
-	if !exists {
+	// Plugin bypass is a topology change, so it goes through the dispatcher;
+	// PluginChain.SetBypass does the actual mutation and listener fan-out.
+	if err := channel.GetPluginChain().SetBypass(pluginID, bypassed); err != nil {
 		return fmt.Errorf("plugin instance %s not found in channel %s", pluginID, channelID)
 	}
+	return nil
+}
+
+func (d *Dispatcher) setMasterLimiter(enabled bool) error {
+	d.AssertOnDispatcher()
+
+	master := d.engine.GetMasterChannel()
+	if master == nil {
+		return fmt.Errorf("no master channel")
+	}
+	return master.applyLimiterEnabled(enabled)
+}
+
+// setSpatialParams applies config to channelID via its spatialConfigurer
+// implementation (BaseChannel.applySpatialConfig), running under the
+// dispatcher's serialization guarantee like every other topology change so
+// a concurrent OpRemoveChannel can't race the channel out from under it.
+func (d *Dispatcher) setSpatialParams(channelID string, config SpatialConfig, duration time.Duration, curve RampCurve) (<-chan struct{}, error) {
+	d.AssertOnDispatcher()
+
+	channel, exists := d.engine.GetChannel(channelID)
+	if !exists {
+		return nil, fmt.Errorf("channel %s not found", channelID)
+	}
+	sp, ok := channel.(spatialConfigurer)
+	if !ok {
+		return nil, fmt.Errorf("channel %s does not support spatial parameters", channelID)
+	}
+	return sp.applySpatialConfig(config, duration, curve)
+}
+
+// captureScene snapshots the engine's current state under name via
+// SceneManager.SaveScene, running under the dispatcher's serialization
+// guarantee so the snapshot can't land mid-way through a concurrent
+// OpSetMute/OpPluginBypass operation.
+func (d *Dispatcher) captureScene(name string) error {
+	d.AssertOnDispatcher()
+
+	return d.engine.sceneManager.SaveScene(name)
+}
+
+// recallScene restores the named scene, running under the dispatcher's
+// serialization guarantee for the same reason captureScene does. With
+// opts.FadeDuration <= 0 it applies instantly via Serializer.SetState, same
+// as SceneManager.Recall's instant path. With opts.FadeDuration > 0, it
+// applies every non-volume change (mute, connections, plugin bypass,
+// routing) instantly, holding each channel at its current volume, then
+// glides every channel's volume to the scene's value over
+// opts.FadeDuration using SetVolumeRamp - the sample-accurate render-thread
+// ramp, rather than SceneManager.Recall's dB-stepping crossfadeTo loop.
+// This blocks the dispatcher goroutine for opts.FadeDuration, so no other
+// operation interleaves with the fade; that's the intended trade-off for a
+// race-free recall.
+//
+// opts.ChannelIDs, if non-empty, restricts every step below (topology,
+// device changes, volume ramp) to just those channels - every other
+// channel in target is left exactly as current already has it. opts.Soft
+// skips the device-change step entirely.
+//
+// If applying target's topology or a device change fails partway through,
+// recallScene rolls back to current (the state captured at the top of this
+// call, before anything was touched) and returns the error, rather than
+// leaving some channels on the new scene and others on the old one.
+func (d *Dispatcher) recallScene(name string, opts RecallOptions) error {
+	d.AssertOnDispatcher()
+
+	target, ok := d.engine.sceneManager.LoadScene(name)
+	if !ok {
+		return fmt.Errorf("scene %q not found", name)
+	}
+
+	current := d.engine.serializer.GetState()
+	d.engine.sceneManager.pushHistory(current)
+
+	scoped := scopeSceneToChannels(current, target, opts.ChannelIDs)
+
+	if opts.FadeDuration <= 0 {
+		if err := d.engine.serializer.SetState(scoped); err != nil {
+			return fmt.Errorf("failed to apply scene %q: %w", name, err)
+		}
+		if !opts.Soft {
+			if err := d.applySceneDeviceChanges(current, scoped); err != nil {
+				d.rollbackScene(current, fmt.Sprintf("scene %q device change", name))
+				return err
+			}
+		}
+		return nil
+	}
+
+	held := scoped
+	held.Channels = make(map[string]ChannelState, len(scoped.Channels))
+	for id, toCh := range scoped.Channels {
+		ch := toCh
+		if fromCh, ok := current.Channels[id]; ok {
+			ch.Volume = fromCh.Volume
+		}
+		held.Channels[id] = ch
+	}
+	if err := d.engine.serializer.SetState(held); err != nil {
+		return fmt.Errorf("failed to apply scene %q topology: %w", name, err)
+	}
+
+	if !opts.Soft {
+		if err := d.applySceneDeviceChanges(current, held); err != nil {
+			d.rollbackScene(current, fmt.Sprintf("scene %q device change", name))
+			return err
+		}
+	}
+
+	var dones []<-chan struct{}
+	for id, toCh := range scoped.Channels {
+		channel, exists := d.engine.GetChannel(id)
+		if !exists {
+			continue
+		}
+		if heldCh := held.Channels[id]; heldCh.Volume == toCh.Volume {
+			continue
+		}
+		done, err := channel.SetVolumeRamp(toCh.Volume, opts.FadeDuration, opts.Curve)
+		if err != nil {
+			d.engine.errorHandler.HandleError(fmt.Errorf("failed to ramp channel %s to scene %q volume: %w", id, name, err))
+			continue
+		}
+		dones = append(dones, done)
+	}
+	for _, done := range dones {
+		<-done
+	}
+
+	return nil
+}
+
+// scopeSceneToChannels returns a copy of target with every channel not
+// named in channelIDs replaced by current's version of that channel, so a
+// RecallOptions.ChannelIDs allowlist can be applied by handing the result
+// straight to Serializer.SetState instead of special-casing the allowlist
+// at every step of recallScene. An empty channelIDs returns target
+// unchanged (every channel in scope).
+func scopeSceneToChannels(current, target EngineState, channelIDs []string) EngineState {
+	if len(channelIDs) == 0 {
+		return target
+	}
+
+	allowed := make(map[string]bool, len(channelIDs))
+	for _, id := range channelIDs {
+		allowed[id] = true
+	}
+
+	scoped := target
+	scoped.Channels = make(map[string]ChannelState, len(target.Channels))
+	for id, ch := range target.Channels {
+		if allowed[id] {
+			scoped.Channels[id] = ch
+		} else if currentCh, ok := current.Channels[id]; ok {
+			scoped.Channels[id] = currentCh
+		}
+	}
+	for id, currentCh := range current.Channels {
+		if !allowed[id] {
+			if _, already := scoped.Channels[id]; !already {
+				scoped.Channels[id] = currentCh
+			}
+		}
+	}
+	return scoped
+}
+
+// applySceneDeviceChanges restores each AudioInputChannel/MidiInputChannel
+// in target to the device UID captured in its Config["deviceUID"] (see
+// AudioInputChannel.GetState/MidiInputChannel.GetState), skipping channels
+// already on that device. It stops and returns the first error encountered
+// rather than pressing on to the rest of the channels, since recallScene
+// treats a failed device change as grounds to roll the whole recall back.
+func (d *Dispatcher) applySceneDeviceChanges(current, target EngineState) error {
+	for id, toCh := range target.Channels {
+		wantUID, ok := toCh.Config["deviceUID"].(string)
+		if !ok || wantUID == "" {
+			continue
+		}
+		if fromCh, ok := current.Channels[id]; ok {
+			if haveUID, _ := fromCh.Config["deviceUID"].(string); haveUID == wantUID {
+				continue
+			}
+		}
+		if _, exists := d.engine.GetChannel(id); !exists {
+			continue
+		}
+		if err := d.changeChannelDevice(id, wantUID); err != nil {
+			return fmt.Errorf("failed to change channel %s to device %q during scene recall: %w", id, wantUID, err)
+		}
+	}
+	return nil
+}
+
+// executeTransaction runs ops in order via executeOperation, under the same
+// dispatcher-serialization guarantee captureScene/recallScene rely on.
+// Before touching anything it snapshots the engine's full state via
+// Serializer.GetState, the same snapshot recallScene diffs a scene against;
+// if every op succeeds that snapshot is just discarded, and if one fails
+// partway through, it's handed to rollbackScene to restore the engine to
+// exactly how executeTransaction found it, and the failing op's error is
+// returned. This reuses recallScene's whole-state snapshot/restore rather
+// than recording a per-op inverse, since every op here already runs through
+// executeOperation and a full-state restore undoes all of them at once
+// regardless of which op failed or how far through the batch it got.
+func (d *Dispatcher) executeTransaction(ops []DispatcherOperation) error {
+	d.AssertOnDispatcher()
+
+	before := d.engine.serializer.GetState()
+
+	for i, op := range ops {
+		result := d.executeOperation(op)
+		if !result.Success {
+			err := result.Error
+			if err == nil {
+				err = fmt.Errorf("transaction op %d (%s) failed", i, op.Type)
+			}
+			d.rollbackScene(before, fmt.Sprintf("transaction op %d (%s)", i, op.Type))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snapshot captures the engine's current state for Snapshot, under the same
+// dispatcher-serialization guarantee captureScene relies on.
+func (d *Dispatcher) snapshot() EngineSnapshot {
+	d.AssertOnDispatcher()
+	return EngineSnapshot(d.engine.serializer.GetState())
+}
+
+// restoreSnapshot applies target to the engine for Restore: channels
+// present in target but not on the engine are created, channels on the
+// engine but not in target are removed, and a channel present in both is
+// left untouched unless its ChannelState actually differs from what's
+// running, in which case just that channel's SetState is called. before is
+// captured up front so a failure partway through can be rolled back to
+// exactly where restoreSnapshot started, the same way executeTransaction
+// rolls back a failed op batch.
+func (d *Dispatcher) restoreSnapshot(target EngineState) error {
+	d.AssertOnDispatcher()
+
+	if !d.engine.serializer.IsCompatible(target.Version) {
+		return fmt.Errorf("incompatible snapshot version: %s", target.Version)
+	}
+
+	before := d.engine.serializer.GetState()
+	masterID := d.engine.masterChannel.GetIDString()
+
+	for id := range before.Channels {
+		if id == masterID {
+			continue
+		}
+		if _, stillWanted := target.Channels[id]; !stillWanted {
+			if err := d.removeChannel(id); err != nil {
+				d.rollbackScene(before, fmt.Sprintf("restore: removing channel %s", id))
+				return fmt.Errorf("failed to remove channel %s during restore: %w", id, err)
+			}
+		}
+	}
+
+	for id, wantState := range target.Channels {
+		if id == masterID || wantState.Type == ChannelTypeMaster {
+			if err := d.engine.masterChannel.SetState(wantState); err != nil {
+				d.rollbackScene(before, "restore: master channel")
+				return fmt.Errorf("failed to restore master channel state: %w", err)
+			}
+			continue
+		}
+
+		if channel, exists := d.engine.GetChannel(id); exists {
+			if reflect.DeepEqual(before.Channels[id], wantState) {
+				continue
+			}
+			if err := channel.SetState(wantState); err != nil {
+				d.rollbackScene(before, fmt.Sprintf("restore: updating channel %s", id))
+				return fmt.Errorf("failed to restore state for channel %s: %w", id, err)
+			}
+			continue
+		}
+
+		channel, err := d.engine.serializer.createChannelFromState(id, wantState)
+		if err != nil {
+			d.rollbackScene(before, fmt.Sprintf("restore: creating channel %s", id))
+			return fmt.Errorf("failed to create channel %s from snapshot: %w", id, err)
+		}
+		if err := d.engine.addChannel(channel); err != nil {
+			d.rollbackScene(before, fmt.Sprintf("restore: adding channel %s", id))
+			return fmt.Errorf("failed to add restored channel %s: %w", id, err)
+		}
+		if err := channel.SetState(wantState); err != nil {
+			d.rollbackScene(before, fmt.Sprintf("restore: initializing channel %s", id))
+			return fmt.Errorf("failed to restore state for channel %s: %w", id, err)
+		}
+	}
 
-	// Plugin bypass is a topology change
-	// TODO: Add SetBypassed method to PluginInstance
-	instance.mu.Lock()
-	instance.IsActive = !bypassed // For now, use IsActive as bypass state
-	instance.mu.Unlock()
 	return nil
 }
 
+// rollbackScene restores the engine to before, logging context through
+// errorHandler about what failed and triggered the rollback - the
+// "all-or-nothing" half of recallScene's contract alongside the early
+// returns above.
+func (d *Dispatcher) rollbackScene(before EngineState, context string) {
+	if err := d.engine.serializer.SetState(before); err != nil {
+		d.engine.errorHandler.HandleError(fmt.Errorf("rollback after failed %s also failed: %w", context, err))
+	}
+}
+
 func (d *Dispatcher) changeChannelDevice(channelID, newDeviceUID string) error {
+	d.AssertOnDispatcher()
+
 	channel, exists := d.engine.GetChannel(channelID)
 	if !exists {
 		return fmt.Errorf("channel %s not found", channelID)
 	}
 
+	if d.engine.mock != nil {
+		return d.engine.mock.changeChannelDevice(channel, newDeviceUID)
+	}
+
 	// Device changes are topology changes that require reconnection
 	switch ch := channel.(type) {
 	case *AudioInputChannel:
@@ -732,33 +2730,62 @@ func (d *Dispatcher) changeChannelDevice(channelID, newDeviceUID string) error {
 		if err := ch.Stop(); err != nil {
 			return fmt.Errorf("failed to stop channel during device change: %w", err)
 		}
-		
+
 		// Update device configuration
 		ch.config.DeviceUID = newDeviceUID
 		ch.deviceUID = newDeviceUID
-		
+
 		// Get new input node
 		inputNode, err := d.engine.getOrCreateInputNode(newDeviceUID, ch.inputBus)
 		if err != nil {
 			return fmt.Errorf("failed to get new input node: %w", err)
 		}
 		ch.inputNode = inputNode
-		
+
 		// Restart channel with new device
 		return ch.Start()
-		
+
 	case *MidiInputChannel:
-		// Similar logic for MIDI channels
+		// Unlike AudioInputChannel, a MidiInputChannel is presently just a
+		// config holder with no live stream of its own (see
+		// Engine.CreateSamplerChannel's doc comment) - there's no node to
+		// stop/restart here. What's worth validating is the UID itself, the
+		// same existence/online check changeOutputDevice/changeInputDevice
+		// apply to audio devices, so a typo'd or unplugged MIDI device UID
+		// fails loudly instead of being accepted silently.
+		midiDevices, err := devices.GetMIDI()
+		if err != nil {
+			return fmt.Errorf("failed to enumerate MIDI devices: %w", err)
+		}
+
+		device := midiDevices.ByUID(newDeviceUID)
+		if device == nil {
+			return fmt.Errorf("MIDI device with UID %s not found", newDeviceUID)
+		}
+
+		if !device.IsOnline {
+			return fmt.Errorf("MIDI device %s is not online", newDeviceUID)
+		}
+
 		ch.config.DeviceUID = newDeviceUID
 		ch.deviceUID = newDeviceUID
 		return nil
-		
+
 	default:
 		return fmt.Errorf("device change not supported for channel type %T", channel)
 	}
 }
 
+// changeOutputDevice switches the engine's output hardware, pausing the
+// underlying AVAudioEngine around the switch (avEngine.Pause/Start rather
+// than Engine.Stop/Start, since those route back through this dispatcher's
+// own operations channel and would deadlock called from here) so every
+// channel already connected into MainMixerNode keeps its wiring intact -
+// only the shared output AudioUnit moves, mirroring the "select output by
+// UID" pattern used by NewWithDevice/SetOutputDevice in avaudio/engine.
 func (d *Dispatcher) changeOutputDevice(newDeviceUID string) error {
+	d.AssertOnDispatcher()
+
 	// Validate new output device exists
 	audioDevices, err := devices.GetAudio()
 	if err != nil {
@@ -774,14 +2801,82 @@ func (d *Dispatcher) changeOutputDevice(newDeviceUID string) error {
 		return fmt.Errorf("output device %s is not online", newDeviceUID)
 	}
 
-	// Output device change is a major topology change
-	// For now, store the new device UID
+	avEngine := d.engine.getAVEngine()
+	wasRunning := d.engine.isRunning
+	if wasRunning {
+		avEngine.Pause()
+	}
+
+	if err := avEngine.SetOutputDevice(newDeviceUID); err != nil {
+		if wasRunning {
+			if startErr := avEngine.Start(); startErr != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to resume engine after failed output device change: %w", startErr))
+			}
+		}
+		return fmt.Errorf("failed to set output device: %w", err)
+	}
+
 	d.engine.mu.Lock()
 	d.engine.outputDeviceUID = newDeviceUID
+	if route := routeByName(d.engine.outputRoutes, "primary"); route != nil {
+		route.DeviceUID = newDeviceUID
+	}
+	d.engine.mu.Unlock()
+
+	if wasRunning {
+		if err := avEngine.Start(); err != nil {
+			return fmt.Errorf("failed to resume engine after output device change: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// changeInputDevice switches the engine's single shared input hardware -
+// see avengine.Engine.SetInputDevice's doc comment on why this is one
+// engine-wide device rather than a per-channel binding like
+// changeChannelDevice's input node selection.
+func (d *Dispatcher) changeInputDevice(newDeviceUID string) error {
+	d.AssertOnDispatcher()
+
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		return fmt.Errorf("failed to enumerate audio devices: %w", err)
+	}
+
+	device := audioDevices.ByUID(newDeviceUID)
+	if device == nil {
+		return fmt.Errorf("input device with UID %s not found", newDeviceUID)
+	}
+
+	if !device.IsOnline {
+		return fmt.Errorf("input device %s is not online", newDeviceUID)
+	}
+
+	avEngine := d.engine.getAVEngine()
+	wasRunning := d.engine.isRunning
+	if wasRunning {
+		avEngine.Pause()
+	}
+
+	if err := avEngine.SetInputDevice(newDeviceUID); err != nil {
+		if wasRunning {
+			if startErr := avEngine.Start(); startErr != nil {
+				d.engine.errorHandler.HandleError(fmt.Errorf("failed to resume engine after failed input device change: %w", startErr))
+			}
+		}
+		return fmt.Errorf("failed to set input device: %w", err)
+	}
+
+	d.engine.mu.Lock()
+	d.engine.inputDeviceUID = newDeviceUID
 	d.engine.mu.Unlock()
 
-	// TODO: Implement actual AVFoundation output device change
-	// This requires reconnecting the main mixer to the new output device
-	
+	if wasRunning {
+		if err := avEngine.Start(); err != nil {
+			return fmt.Errorf("failed to resume engine after input device change: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,69 @@
+//go:build debug
+
+package macaudio
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// currentGoroutineID parses the "goroutine N [...]" header runtime.Stack
+// prints for the calling goroutine. Go has no supported API for this, which
+// is exactly why it's confined to -tags debug builds: acceptable overhead
+// and fragility for catching misuse in tests, never shipped in production.
+func currentGoroutineID() uint64 {
+	buf := make([]byte, 64)
+	n := runtime.Stack(buf, false)
+	buf = bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	buf = buf[:bytes.IndexByte(buf, ' ')]
+	id, err := strconv.ParseUint(string(buf), 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("dispatcher: failed to parse goroutine id from stack: %v", err))
+	}
+	return id
+}
+
+// markDispatchLoopGoroutine records the calling goroutine's id as d's
+// dispatch loop goroutine; dispatchLoop calls this once, before its first
+// iteration, so AssertOnDispatcher/AssertNotOnDispatcher have something to
+// compare against.
+func (d *Dispatcher) markDispatchLoopGoroutine() {
+	d.mu.Lock()
+	d.runGoroutineID = currentGoroutineID()
+	d.mu.Unlock()
+}
+
+// AssertOnDispatcher panics unless called from the goroutine running d's
+// dispatchLoop, the way cubeb-coreaudio's debug_assert_running_serially
+// guards its own serial queue. Every dispatcher-internal method that
+// executeOperation dispatches to (createAudioInput, setMute, connectChannels,
+// and so on) calls this first, so a caller that reaches one of them without
+// going through the dispatcher's own queue - e.g. a CGO callback mutating a
+// channel directly - fails loudly in a debug build instead of racing.
+func (d *Dispatcher) AssertOnDispatcher() {
+	d.mu.RLock()
+	want := d.runGoroutineID
+	d.mu.RUnlock()
+
+	if got := currentGoroutineID(); want != 0 && got != want {
+		panic(fmt.Sprintf("dispatcher: expected to run on dispatch loop goroutine %d, got %d", want, got))
+	}
+}
+
+// AssertNotOnDispatcher panics if called from d's own dispatchLoop goroutine.
+// degradeOutputRoute and other handlers reached from callbacks that aren't
+// routed through the dispatcher's queue (see DeviceMonitor.handleDeviceChangeEvent)
+// call this: if one of them ever ran on the dispatch loop goroutine instead,
+// any attempt to submit a DispatcherOperation and block on its response would
+// deadlock against itself.
+func (d *Dispatcher) AssertNotOnDispatcher() {
+	d.mu.RLock()
+	want := d.runGoroutineID
+	d.mu.RUnlock()
+
+	if got := currentGoroutineID(); want != 0 && got == want {
+		panic("dispatcher: must not run on the dispatch loop goroutine (would deadlock)")
+	}
+}
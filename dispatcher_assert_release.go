@@ -0,0 +1,15 @@
+//go:build !debug
+
+package macaudio
+
+// markDispatchLoopGoroutine is a no-op outside -tags debug builds; see the
+// debug build's version (dispatcher_assert_debug.go).
+func (d *Dispatcher) markDispatchLoopGoroutine() {}
+
+// AssertOnDispatcher is a no-op outside -tags debug builds; see the debug
+// build's version (dispatcher_assert_debug.go) for what it checks there.
+func (d *Dispatcher) AssertOnDispatcher() {}
+
+// AssertNotOnDispatcher is a no-op outside -tags debug builds; see the debug
+// build's version (dispatcher_assert_debug.go) for what it checks there.
+func (d *Dispatcher) AssertNotOnDispatcher() {}
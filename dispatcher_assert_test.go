@@ -0,0 +1,126 @@
+//go:build debug
+
+package macaudio
+
+import (
+	"testing"
+)
+
+// TestDispatcherAssertOnDispatcher exercises AssertOnDispatcher end to end:
+// a mutation reached through the dispatcher (setMute running on the real
+// dispatch loop goroutine) must not panic, and the same mutation reached
+// directly from the calling test goroutine - the misuse the request calls
+// out, e.g. a CGO callback invoking it straight - must panic.
+func TestDispatcherAssertOnDispatcher(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer eng.dispatcher.Stop()
+
+	if _, err := eng.CreateSilenceChannel("assert-test"); err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		run       func() error
+		wantPanic bool
+	}{
+		{
+			name:      "SetChannelMute through the dispatcher does not panic",
+			run:       func() error { return eng.dispatcher.SetChannelMute("assert-test", true) },
+			wantPanic: false,
+		},
+		{
+			name:      "setMute called directly off the dispatch loop goroutine panics",
+			run:       func() error { return eng.dispatcher.setMute("assert-test", true) },
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if tt.wantPanic && r == nil {
+					t.Errorf("expected a panic, got none")
+				}
+				if !tt.wantPanic && r != nil {
+					t.Errorf("unexpected panic: %v", r)
+				}
+			}()
+			if err := tt.run(); err != nil && !tt.wantPanic {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestDispatcherAssertNotOnDispatcher exercises AssertNotOnDispatcher's two
+// sides without ever panicking inside a live dispatchLoop goroutine (which
+// has no recover and would take the whole test binary down with it) - it
+// stamps runGoroutineID onto a throwaway goroutine standing in for the
+// dispatch loop and calls both asserts from inside it, recovering in that
+// same goroutine the way a real caller would.
+func TestDispatcherAssertNotOnDispatcher(t *testing.T) {
+	d := &Dispatcher{}
+
+	type outcome struct {
+		assertNotPanicked bool
+		assertOnPanicked  bool
+	}
+	results := make(chan outcome, 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.markDispatchLoopGoroutine()
+
+		var out outcome
+		func() {
+			defer func() { out.assertOnPanicked = recover() != nil }()
+			d.AssertOnDispatcher()
+		}()
+		func() {
+			defer func() { out.assertNotPanicked = recover() != nil }()
+			d.AssertNotOnDispatcher()
+		}()
+		results <- out
+	}()
+	<-done
+
+	out := <-results
+	if out.assertOnPanicked {
+		t.Errorf("AssertOnDispatcher panicked when called from the stamped dispatch loop goroutine")
+	}
+	if !out.assertNotPanicked {
+		t.Errorf("AssertNotOnDispatcher did not panic when called from the stamped dispatch loop goroutine")
+	}
+
+	// From the test's own goroutine (not the one stamped above), the
+	// polarity flips: AssertOnDispatcher should panic, AssertNotOnDispatcher
+	// should not.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("AssertOnDispatcher did not panic when called off the dispatch loop goroutine")
+			}
+		}()
+		d.AssertOnDispatcher()
+	}()
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("AssertNotOnDispatcher panicked when called off the dispatch loop goroutine: %v", r)
+			}
+		}()
+		d.AssertNotOnDispatcher()
+	}()
+}
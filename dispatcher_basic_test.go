@@ -1,6 +1,7 @@
 package macaudio
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -45,31 +46,23 @@ func TestDispatcherBasic(t *testing.T) {
 			ChannelID: "nonexistent-channel",
 			Muted:     true,
 		},
-		Response: make(chan DispatcherResult, 1),
 	}
 
 	t.Log("Submitting test operation...")
-	
-	// Submit operation with timeout
-	select {
-	case dispatcher.operations <- operation:
-		t.Log("Operation submitted successfully")
-		
-		// Wait for response with timeout
-		select {
-		case result := <-operation.Response:
-			t.Logf("Operation completed with result: success=%t, error=%v", result.Success, result.Error)
-		case <-time.After(1 * time.Second):
-			t.Error("Operation timed out waiting for response")
-		}
-		
-	case <-time.After(1 * time.Second):
-		t.Error("Timed out submitting operation")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	result, err := dispatcher.Submit(ctx, operation)
+	if err != nil {
+		t.Errorf("Operation timed out submitting or waiting for response: %v", err)
+	} else {
+		t.Logf("Operation completed with result: success=%t, error=%v", result.Success, result.Error)
 	}
 
 	// Test performance stats
-	lastDuration, maxDuration := dispatcher.GetPerformanceStats()
-	t.Logf("Performance stats - Last: %v, Max: %v", lastDuration, maxDuration)
+	stats := dispatcher.GetPerformanceStats()
+	t.Logf("Performance stats - Last: %v, Max: %v", stats.LastOperationDuration, stats.MaxOperationDuration)
 
 	// Clean shutdown
 	if err := dispatcher.Stop(); err != nil {
@@ -78,3 +71,71 @@ func TestDispatcherBasic(t *testing.T) {
 
 	t.Log("Basic dispatcher test completed")
 }
+
+// TestDispatcherStatsResetAndSnapshot exercises GetPerformanceStats'
+// OperationCounts/TotalOperations bookkeeping and ResetStats/StatsSnapshot.
+func TestDispatcherStatsResetAndSnapshot(t *testing.T) {
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   256,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		OutputDeviceUID: "BuiltInSpeakerDevice",
+		ErrorHandler:    &DefaultErrorHandler{},
+	}
+
+	testEngine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer testEngine.Destroy()
+
+	dispatcher := testEngine.dispatcher
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+
+	const numOps = 5
+	for i := 0; i < numOps; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		_, err := dispatcher.Submit(ctx, DispatcherOperation{
+			Type: OpSetMute,
+			Data: SetMuteData{ChannelID: "nonexistent-channel", Muted: (i % 2) == 0},
+		})
+		cancel()
+		if err != nil {
+			t.Fatalf("Submit %d failed: %v", i, err)
+		}
+	}
+
+	stats := dispatcher.GetPerformanceStats()
+	if stats.OperationCounts[OpSetMute] != numOps {
+		t.Errorf("expected OperationCounts[OpSetMute] == %d, got %d", numOps, stats.OperationCounts[OpSetMute])
+	}
+	if stats.TotalOperations != numOps {
+		t.Errorf("expected TotalOperations == %d, got %d", numOps, stats.TotalOperations)
+	}
+	if stats.OperationLatencyP50 == 0 && stats.OperationLatencyP99 == 0 {
+		t.Error("expected non-zero operation latency percentiles after submitting operations")
+	}
+
+	snapshot := dispatcher.StatsSnapshot()
+	if snapshot.TotalOperations != stats.TotalOperations {
+		t.Errorf("expected StatsSnapshot to agree with GetPerformanceStats, got %d vs %d", snapshot.TotalOperations, stats.TotalOperations)
+	}
+
+	dispatcher.ResetStats()
+	reset := dispatcher.GetPerformanceStats()
+	if reset.TotalOperations != 0 {
+		t.Errorf("expected TotalOperations == 0 after ResetStats, got %d", reset.TotalOperations)
+	}
+	if reset.OperationLatencyP99 != 0 {
+		t.Errorf("expected OperationLatencyP99 == 0 after ResetStats, got %v", reset.OperationLatencyP99)
+	}
+	if reset.MaxOperationDuration != 0 {
+		t.Errorf("expected MaxOperationDuration == 0 after ResetStats, got %v", reset.MaxOperationDuration)
+	}
+}
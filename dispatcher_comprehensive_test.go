@@ -1,6 +1,7 @@
 package macaudio
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -62,25 +63,22 @@ func TestDispatcherRaceConditionPrevention(t *testing.T) {
 						ChannelID: "test-channel", // All target same channel to create contention
 						Muted:     (op % 2) == 0,
 					},
-					Response: make(chan DispatcherResult, 1),
 				}
 
-				// Submit operation
-				select {
-				case dispatcher.operations <- operation:
-					// Wait for response
-					result := <-operation.Response
-
-					// Record result
-					mu.Lock()
-					processedOps++
-					operationResults = append(operationResults, result.Success)
-					mu.Unlock()
-
-				case <-time.After(5 * time.Second):
-					t.Errorf("Worker %d operation %d timed out", workerID, op)
+				// Submit operation with timeout
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				result, err := dispatcher.Submit(ctx, operation)
+				cancel()
+				if err != nil {
+					t.Errorf("Worker %d operation %d timed out: %v", workerID, op, err)
 					return
 				}
+
+				// Record result
+				mu.Lock()
+				processedOps++
+				operationResults = append(operationResults, result.Success)
+				mu.Unlock()
 			}
 		}(w)
 	}
@@ -103,10 +101,10 @@ func TestDispatcherRaceConditionPrevention(t *testing.T) {
 	}
 
 	// Check performance stats
-	lastDuration, maxDuration := dispatcher.GetPerformanceStats()
+	stats := dispatcher.GetPerformanceStats()
 	t.Logf("Performance stats:")
-	t.Logf("  Last operation: %v", lastDuration)
-	t.Logf("  Max operation: %v", maxDuration)
+	t.Logf("  Last operation: %v", stats.LastOperationDuration)
+	t.Logf("  Max operation: %v", stats.MaxOperationDuration)
 
 	// Validate performance meets targets
 	avgDuration := duration / time.Duration(processedOps)
@@ -151,15 +149,14 @@ func TestDispatcherEngineLifecycle(t *testing.T) {
 	// Test engine start/stop through dispatcher (serialized)
 	t.Run("EngineStartStop", func(t *testing.T) {
 		// Engine start
-		startOp := DispatcherOperation{
-			Type:     OpStartEngine,
-			Data:     CreateEngineData{}, // Empty data for start
-			Response: make(chan DispatcherResult, 1),
+		result, err := dispatcher.Submit(context.Background(), DispatcherOperation{
+			Type: OpStartEngine,
+			Data: CreateEngineData{}, // Empty data for start
+		})
+		if err != nil {
+			t.Fatalf("Failed to submit engine start: %v", err)
 		}
 
-		dispatcher.operations <- startOp
-		result := <-startOp.Response
-
 		if result.Error != nil {
 			t.Logf("Engine start result: %v (may fail without proper audio setup)", result.Error)
 		} else {
@@ -167,15 +164,14 @@ func TestDispatcherEngineLifecycle(t *testing.T) {
 		}
 
 		// Engine stop
-		stopOp := DispatcherOperation{
-			Type:     OpStopEngine,
-			Data:     CreateEngineData{}, // Empty data for stop
-			Response: make(chan DispatcherResult, 1),
+		stopResult, err := dispatcher.Submit(context.Background(), DispatcherOperation{
+			Type: OpStopEngine,
+			Data: CreateEngineData{}, // Empty data for stop
+		})
+		if err != nil {
+			t.Fatalf("Failed to submit engine stop: %v", err)
 		}
 
-		dispatcher.operations <- stopOp
-		stopResult := <-stopOp.Response
-
 		if stopResult.Error != nil {
 			t.Logf("Engine stop result: %v", stopResult.Error)
 		} else {
@@ -219,33 +215,31 @@ func TestDispatcherMultipleOperationTypes(t *testing.T) {
 		{
 			Type: OpSetMute,
 			Data: SetMuteData{ChannelID: "test-1", Muted: true},
-			Response: make(chan DispatcherResult, 1),
 		},
 		{
 			Type: OpPluginBypass,
 			Data: PluginBypassData{ChannelID: "test-1", PluginID: "plugin-1", Bypassed: true},
-			Response: make(chan DispatcherResult, 1),
 		},
 		{
 			Type: OpDeviceChange,
 			Data: DeviceChangeData{ChannelID: "test-1", NewDeviceUID: "new-device"},
-			Response: make(chan DispatcherResult, 1),
 		},
 		{
 			Type: OpOutputDeviceChange,
 			Data: OutputDeviceChangeData{NewDeviceUID: "new-output"},
-			Response: make(chan DispatcherResult, 1),
 		},
 	}
 
 	var results []DispatcherResult
 	for i, op := range operations {
 		t.Logf("Submitting operation %d: %s", i+1, op.Type)
-		
-		dispatcher.operations <- op
-		result := <-op.Response
+
+		result, err := dispatcher.Submit(context.Background(), op)
+		if err != nil {
+			t.Fatalf("Operation %d failed to submit: %v", i+1, err)
+		}
 		results = append(results, result)
-		
+
 		t.Logf("Operation %d completed: success=%t, error=%v", i+1, result.Success, result.Error)
 	}
 
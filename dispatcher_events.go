@@ -0,0 +1,249 @@
+package macaudio
+
+import "time"
+
+// EventSource distinguishes who caused an EngineEvent - so a Subscribe
+// consumer (a WebSocket/HTTP surface pushing state to multiple clients, in
+// particular) can tell its own request's result apart from a change some
+// other peer or this package's own internal code made, and avoid echoing a
+// state update back to the client that just sent it.
+type EventSource string
+
+const (
+	// SourceDispatcher marks an event produced by this package's own
+	// internal call sites - the CreateXChannel/SetX convenience methods
+	// that go through submit(), not Submit/TrySubmit directly.
+	SourceDispatcher EventSource = "dispatcher"
+
+	// SourceExternal marks an event produced by a caller outside this
+	// package's own call graph - Submit/TrySubmit's intended audience (an
+	// OSC control surface, a scripted batch import) and SubmitParam's only
+	// caller in this tree today (osc_server.go).
+	SourceExternal EventSource = "external"
+)
+
+// EngineEventType identifies what kind of payload an EngineEvent carries, so
+// a Subscribe consumer can switch on Type without a type assertion on Data
+// first - the same Type/Data pairing DispatcherOperation already uses for
+// its own request side.
+type EngineEventType string
+
+const (
+	EventTypePlayerStateChanged  EngineEventType = "player_state_changed"
+	EventTypeChannelParamChanged EngineEventType = "channel_param_changed"
+	EventTypePluginParamChanged  EngineEventType = "plugin_param_changed"
+	EventTypeConnectionChanged   EngineEventType = "connection_changed"
+	EventTypeTapLevel            EngineEventType = "tap_level"
+	EventTypeError               EngineEventType = "error"
+)
+
+// EngineEvent is one message on the peer-style status stream Subscribe
+// returns: Type identifies which of the structs below Data holds.
+type EngineEvent struct {
+	Type EngineEventType
+	Data interface{}
+}
+
+// PlayerStateChanged reports a playback channel's transport state changing -
+// the Subscribe-stream counterpart to the EventNowPlaying DispatcherEvent,
+// broadened to cover pause/stop/resume, not just track advances.
+type PlayerStateChanged struct {
+	PlayerID string
+	State    string // e.g. "playing", "paused", "stopped"
+	Position time.Duration
+}
+
+// ChannelParamChanged reports a channel-level parameter (volume, pan, mute,
+// an aux send level) taking on a new value, from either SetChannelMute
+// (Source: SourceDispatcher) or the param lane SubmitParam feeds (Source:
+// SourceExternal - see Dispatcher.handleParamApplied).
+type ChannelParamChanged struct {
+	ChannelID string
+	Param     string
+	Value     float32
+	Source    EventSource
+}
+
+// PluginParamChanged reports a plugin instance's parameter changing. Today
+// the only dispatcher-level plugin operation is bypass (see OpPluginBypass),
+// so ParamAddr is "bypass:<pluginID>" and Value is 0/1 until a richer plugin
+// parameter-automation path exists; PluginID - not an index - is what
+// PluginBypassData already identifies a plugin instance by in this tree.
+type PluginParamChanged struct {
+	ChannelID string
+	PluginID  string
+	ParamAddr string
+	Value     float32
+	Source    EventSource
+}
+
+// ConnectionChanged reports an edge between two channels being made or
+// broken via OpConnectChannels/OpDisconnectChannels. Dst is empty for a
+// disconnect, since DisconnectChannelsData only names the source and bus,
+// not what it had been feeding.
+type ConnectionChanged struct {
+	Src string
+	Dst string
+	Bus int
+}
+
+// EventError reports a mutating dispatcher operation's own failure - the
+// Subscribe-stream counterpart to the error already returned to whichever
+// caller's Submit/submit call is waiting on it, so every other subscriber
+// learns about it too instead of only the one caller who asked.
+type EventError struct {
+	Op  OperationType
+	Err error
+}
+
+// eventSubscription is one Subscribe registration: ch is what publishEvent
+// delivers matching events to, and filter decides which ones match.
+type eventSubscription struct {
+	filter EventFilter
+	ch     chan EngineEvent
+}
+
+// eventChannelBuffer bounds how many undelivered events a single Subscribe
+// channel holds before publishEvent starts dropping for that subscriber -
+// generous enough to absorb a short burst without a slow consumer stalling
+// the dispatch loop that's publishing.
+const eventChannelBuffer = 64
+
+// EventFilter selects which EngineEvent.Types a Subscribe registration
+// receives. A zero-value EventFilter (nil Types) matches every event.
+type EventFilter struct {
+	Types []EngineEventType
+}
+
+// matches reports whether typ is one EventFilter accepts.
+func (f EventFilter) matches(typ EngineEventType) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelFunc ends a Subscribe registration; calling it more than once is a
+// no-op.
+type CancelFunc func()
+
+// Subscribe registers a peer-style listener for engine status events -
+// transport, parameter, connection, and error changes - matching filter,
+// modeling the independent app/audio-controller status stream this
+// package's request/response DispatcherOperation model doesn't provide on
+// its own (see EngineEvent). The returned channel is buffered
+// (eventChannelBuffer); a subscriber that falls behind drops events rather
+// than stalling the publisher, the same tradeoff Events() already makes for
+// DispatcherEvent. Call the returned CancelFunc to stop receiving and
+// release the channel.
+func (d *Dispatcher) Subscribe(filter EventFilter) (<-chan EngineEvent, CancelFunc) {
+	sub := &eventSubscription{filter: filter, ch: make(chan EngineEvent, eventChannelBuffer)}
+
+	d.subsMu.Lock()
+	if d.subs == nil {
+		d.subs = make(map[uint64]*eventSubscription)
+	}
+	d.nextSubID++
+	id := d.nextSubID
+	d.subs[id] = sub
+	d.subsMu.Unlock()
+
+	var once bool
+	cancel := func() {
+		d.subsMu.Lock()
+		if _, ok := d.subs[id]; ok && !once {
+			delete(d.subs, id)
+			once = true
+			close(sub.ch)
+		}
+		d.subsMu.Unlock()
+	}
+	return sub.ch, cancel
+}
+
+// publishEvent delivers ev to every subscription whose filter matches,
+// without blocking on a slow consumer (see eventChannelBuffer).
+func (d *Dispatcher) publishEvent(ev EngineEvent) {
+	d.subsMu.RLock()
+	defer d.subsMu.RUnlock()
+	for _, sub := range d.subs {
+		if !sub.filter.matches(ev.Type) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+		}
+	}
+}
+
+// publishOperationEvent translates a completed DispatcherOperation into the
+// EngineEvent(s) Subscribe subscribers expect: a failure always becomes an
+// EventError, regardless of op.Type, so no caller-facing error is silently
+// invisible to the status stream; a success publishes the typed event
+// matching op.Type, for the handful of op types that map onto one (most
+// operation types - channel creation, scene capture - don't correspond to
+// any of EngineEvent's payloads and are left to their Submit/submit caller's
+// own returned DispatcherResult, same as before Subscribe existed).
+func (d *Dispatcher) publishOperationEvent(op DispatcherOperation, result DispatcherResult) {
+	if result.Error != nil {
+		d.publishEvent(EngineEvent{Type: EventTypeError, Data: EventError{Op: op.Type, Err: result.Error}})
+		return
+	}
+
+	switch op.Type {
+	case OpSetMute:
+		data := op.Data.(SetMuteData)
+		d.publishEvent(EngineEvent{
+			Type: EventTypeChannelParamChanged,
+			Data: ChannelParamChanged{
+				ChannelID: data.ChannelID,
+				Param:     "mute",
+				Value:     boolToFloat32(data.Muted),
+				Source:    op.Source,
+			},
+		})
+
+	case OpPluginBypass:
+		data := op.Data.(PluginBypassData)
+		d.publishEvent(EngineEvent{
+			Type: EventTypePluginParamChanged,
+			Data: PluginParamChanged{
+				ChannelID: data.ChannelID,
+				PluginID:  data.PluginID,
+				ParamAddr: "bypass",
+				Value:     boolToFloat32(data.Bypassed),
+				Source:    op.Source,
+			},
+		})
+
+	case OpConnectChannels:
+		data := op.Data.(ConnectChannelsData)
+		d.publishEvent(EngineEvent{
+			Type: EventTypeConnectionChanged,
+			Data: ConnectionChanged{Src: data.SourceID, Dst: data.TargetID, Bus: data.Bus},
+		})
+
+	case OpDisconnectChannels:
+		data := op.Data.(DisconnectChannelsData)
+		d.publishEvent(EngineEvent{
+			Type: EventTypeConnectionChanged,
+			Data: ConnectionChanged{Src: data.SourceID, Bus: data.Bus},
+		})
+	}
+}
+
+// boolToFloat32 renders a bool as the 0/1 ChannelParamChanged/
+// PluginParamChanged expect for a discrete on/off parameter like mute or
+// bypass.
+func boolToFloat32(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
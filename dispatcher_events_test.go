@@ -0,0 +1,102 @@
+package macaudio
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestEventFilterMatchesEmptyAsWildcard checks that a zero-value EventFilter
+// (no Types) matches every event type, and a populated one only matches the
+// types it names.
+func TestEventFilterMatchesEmptyAsWildcard(t *testing.T) {
+	var all EventFilter
+	if !all.matches(EventTypeError) || !all.matches(EventTypeConnectionChanged) {
+		t.Error("empty EventFilter should match every event type")
+	}
+
+	narrow := EventFilter{Types: []EngineEventType{EventTypeChannelParamChanged}}
+	if !narrow.matches(EventTypeChannelParamChanged) {
+		t.Error("filter should match a listed type")
+	}
+	if narrow.matches(EventTypeError) {
+		t.Error("filter should not match an unlisted type")
+	}
+}
+
+// TestSubscribeDeliversMatchingEvents checks that publishEvent only delivers
+// to subscriptions whose filter accepts the event's type.
+func TestSubscribeDeliversMatchingEvents(t *testing.T) {
+	d := &Dispatcher{}
+
+	muteCh, cancel := d.Subscribe(EventFilter{Types: []EngineEventType{EventTypeChannelParamChanged}})
+	defer cancel()
+	allCh, cancelAll := d.Subscribe(EventFilter{})
+	defer cancelAll()
+
+	d.publishEvent(EngineEvent{Type: EventTypeConnectionChanged, Data: ConnectionChanged{Src: "a", Dst: "b"}})
+
+	select {
+	case ev := <-muteCh:
+		t.Fatalf("narrow subscription should not have received %+v", ev)
+	default:
+	}
+
+	select {
+	case ev := <-allCh:
+		if ev.Type != EventTypeConnectionChanged {
+			t.Errorf("wildcard subscription got %v, want %v", ev.Type, EventTypeConnectionChanged)
+		}
+	default:
+		t.Fatal("wildcard subscription should have received the event")
+	}
+}
+
+// TestCancelFuncStopsDeliveryAndClosesChannel checks that calling the
+// CancelFunc returned by Subscribe both removes the subscription from future
+// publishEvent calls and closes its channel, and that a second call is a
+// no-op rather than a double-close panic.
+func TestCancelFuncStopsDeliveryAndClosesChannel(t *testing.T) {
+	d := &Dispatcher{}
+	ch, cancel := d.Subscribe(EventFilter{})
+	cancel()
+	cancel() // must not panic
+
+	if _, open := <-ch; open {
+		t.Error("channel should be closed after CancelFunc")
+	}
+
+	d.publishEvent(EngineEvent{Type: EventTypeError, Data: EventError{Err: fmt.Errorf("boom")}})
+}
+
+// TestPublishOperationEventMapsSetMuteToChannelParamChanged checks that a
+// successful OpSetMute publishes a ChannelParamChanged event carrying the
+// operation's own Source, and that a failed operation publishes an
+// EventError instead, regardless of its Type.
+func TestPublishOperationEventMapsSetMuteToChannelParamChanged(t *testing.T) {
+	d := &Dispatcher{}
+	ch, cancel := d.Subscribe(EventFilter{})
+	defer cancel()
+
+	op := DispatcherOperation{
+		Type:   OpSetMute,
+		Data:   SetMuteData{ChannelID: "ch-1", Muted: true},
+		Source: SourceExternal,
+	}
+	d.publishOperationEvent(op, DispatcherResult{Success: true})
+
+	ev := <-ch
+	changed, ok := ev.Data.(ChannelParamChanged)
+	if ev.Type != EventTypeChannelParamChanged || !ok {
+		t.Fatalf("got %+v, want a ChannelParamChanged event", ev)
+	}
+	if changed.ChannelID != "ch-1" || changed.Param != "mute" || changed.Value != 1 || changed.Source != SourceExternal {
+		t.Errorf("unexpected ChannelParamChanged: %+v", changed)
+	}
+
+	d.publishOperationEvent(op, DispatcherResult{Error: fmt.Errorf("nope")})
+	errEv := <-ch
+	errData, ok := errEv.Data.(EventError)
+	if errEv.Type != EventTypeError || !ok || errData.Op != OpSetMute {
+		t.Errorf("got %+v, want an EventError for OpSetMute", errEv)
+	}
+}
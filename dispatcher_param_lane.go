@@ -0,0 +1,269 @@
+package macaudio
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// paramRingSize is the param lane's fixed ring capacity. It must be a power
+// of two so slot indexing is a cheap bitmask (paramRingMask) instead of a
+// modulo. 1024 comfortably outruns a realistic burst of OSC/MIDI float
+// updates between two drain ticks (see paramDrainInterval).
+const paramRingSize = 1024
+const paramRingMask = paramRingSize - 1
+
+// paramDrainInterval is how often the param lane's drain goroutine wakes up
+// to apply the ring's pending updates. It mirrors osc_server.go's previous
+// per-address coalesceInterval, which this lane now supersedes.
+const paramDrainInterval = 20 * time.Millisecond
+
+// paramLatencyHistory bounds how many recent apply latencies GetPerformanceStats'
+// percentile calculation considers, so that buffer doesn't grow unbounded
+// under sustained load.
+const paramLatencyHistory = 512
+
+// paramKey identifies one coalescable real-time float parameter - a
+// (channel, parameter name) pair such as ("ch-1", "volume"). Only the most
+// recently submitted update per key survives a drain cycle.
+type paramKey struct {
+	channelID string
+	param     string
+}
+
+// paramUpdate is one submission to the param lane: apply does the actual
+// work (e.g. ch.SetVolume(value)) and is what the drain goroutine calls once
+// per surviving key, per cycle.
+type paramUpdate struct {
+	key       paramKey
+	value     float32
+	apply     func(float32) error
+	submitted time.Time
+
+	// source attributes this update for the ChannelParamChanged event it
+	// produces once applied - see EventSource and SubmitParam.
+	source EventSource
+}
+
+// paramLane is a lock-free, multi-producer/single-consumer ring buffer for
+// real-time float parameter changes (volume/pan/rate/pitch). Producers -
+// BaseChannel/PlaybackChannel setters invoked from a control surface like
+// osc_server.go - claim a slot with an atomic increment of head and back off
+// with runtime.Gosched if the slot they landed on hasn't been drained yet;
+// the dispatcher's paramDrainLoop goroutine is the lane's sole consumer.
+//
+// This exists so a storm of parameter updates (a fader being dragged, an
+// OSC TouchOSC page sending 50 messages/sec) never contends on a mutex, and
+// never piles up behind the priority-heap queue dispatchLoop drains for
+// topology changes (mute/connect/disconnect) - the two kinds of operation
+// this package serializes no longer share a lock at all.
+type paramLane struct {
+	slots   [paramRingSize]atomic.Value // holds paramUpdate
+	pending [paramRingSize]uint32       // 1 once slots[i] holds an update awaiting drain
+	head    uint64                      // next sequence number a producer claims, via atomic.AddUint64
+	tail    uint64                      // next sequence number drain will consume; owned solely by the drain goroutine
+
+	depth     int64 // slots claimed but not yet drained, for GetPerformanceStats
+	submitted uint64
+	coalesced uint64 // updates overwritten by a later one for the same key before being applied
+
+	latencyMu sync.Mutex
+	latencies []time.Duration // most recent apply latencies, capped at paramLatencyHistory
+
+	countsMu sync.Mutex
+	counts   map[string]uint64 // submissions per paramKey.param (e.g. "volume", "pan"), for GetPerformanceStats.ParamCounts
+}
+
+// submit claims the next ring slot and stores u, backing off with
+// runtime.Gosched if that slot is still awaiting drain from a previous wrap
+// around the ring. It never blocks on a mutex and never blocks the caller
+// on the drain goroutine.
+func (pl *paramLane) submit(u paramUpdate) {
+	idx := atomic.AddUint64(&pl.head, 1) - 1
+	slot := idx & paramRingMask
+	for !atomic.CompareAndSwapUint32(&pl.pending[slot], 0, 1) {
+		runtime.Gosched()
+	}
+	pl.slots[slot].Store(u)
+	atomic.AddInt64(&pl.depth, 1)
+	atomic.AddUint64(&pl.submitted, 1)
+
+	pl.countsMu.Lock()
+	if pl.counts == nil {
+		pl.counts = make(map[string]uint64)
+	}
+	pl.counts[u.key.param]++
+	pl.countsMu.Unlock()
+}
+
+// paramCounts returns a copy of how many updates have been submitted per
+// param name (e.g. "volume", "pan") since the lane was created or last
+// reset, for GetPerformanceStats.ParamCounts.
+func (pl *paramLane) paramCounts() map[string]uint64 {
+	pl.countsMu.Lock()
+	defer pl.countsMu.Unlock()
+	counts := make(map[string]uint64, len(pl.counts))
+	for k, v := range pl.counts {
+		counts[k] = v
+	}
+	return counts
+}
+
+// reset clears the lane's latency history and per-param counters, for
+// Dispatcher.ResetStats. It does not touch depth/submitted/coalesced or the
+// ring itself - those track in-flight state, not a reportable counter
+// that's meaningful to zero out mid-flight.
+func (pl *paramLane) reset() {
+	pl.latencyMu.Lock()
+	pl.latencies = nil
+	pl.latencyMu.Unlock()
+
+	pl.countsMu.Lock()
+	pl.counts = make(map[string]uint64)
+	pl.countsMu.Unlock()
+}
+
+// drain applies every update submitted since the last drain, keeping only
+// the latest value per paramKey - the coalescing this lane exists for - and
+// calls apply for each survivor in the order its key was first seen this
+// cycle. It records each survivor's end-to-end latency (submit to apply
+// returning) for GetPerformanceStats' percentile reporting. onApplied, if
+// non-nil, is called once per survivor with its outcome (nil error on
+// success) - unlike the onApplyError name this replaces, it fires on every
+// apply, not just failures, so a successful one can still be published as a
+// ChannelParamChanged event (see Dispatcher.handleParamApplied).
+func (pl *paramLane) drain(onApplied func(paramUpdate, error)) {
+	head := atomic.LoadUint64(&pl.head)
+	if pl.tail == head {
+		return
+	}
+
+	latest := make(map[paramKey]paramUpdate, head-pl.tail)
+	order := make([]paramKey, 0, head-pl.tail)
+	for seq := pl.tail; seq != head; seq++ {
+		slot := seq & paramRingMask
+		u := pl.slots[slot].Load().(paramUpdate)
+		if _, seen := latest[u.key]; !seen {
+			order = append(order, u.key)
+		} else {
+			atomic.AddUint64(&pl.coalesced, 1)
+		}
+		latest[u.key] = u
+		atomic.StoreUint32(&pl.pending[slot], 0)
+		atomic.AddInt64(&pl.depth, -1)
+	}
+	pl.tail = head
+
+	for _, key := range order {
+		u := latest[key]
+		err := u.apply(u.value)
+		if onApplied != nil {
+			onApplied(u, err)
+		}
+		pl.recordLatency(time.Since(u.submitted))
+	}
+}
+
+func (pl *paramLane) recordLatency(d time.Duration) {
+	pl.latencyMu.Lock()
+	pl.latencies = append(pl.latencies, d)
+	if len(pl.latencies) > paramLatencyHistory {
+		pl.latencies = pl.latencies[len(pl.latencies)-paramLatencyHistory:]
+	}
+	pl.latencyMu.Unlock()
+}
+
+// percentiles returns the p50/p99/p99.9 apply latency over the recorded
+// history, sorted on a snapshot so it never holds latencyMu while sorting.
+func (pl *paramLane) percentiles() (p50, p99, p999 time.Duration) {
+	pl.latencyMu.Lock()
+	samples := append([]time.Duration(nil), pl.latencies...)
+	pl.latencyMu.Unlock()
+
+	if len(samples) == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	at := func(fraction float64) time.Duration {
+		idx := int(fraction * float64(len(samples)))
+		if idx >= len(samples) {
+			idx = len(samples) - 1
+		}
+		return samples[idx]
+	}
+	return at(0.50), at(0.99), at(0.999)
+}
+
+// stats reports the param lane's current queue depth and how much of its
+// traffic has been coalesced away (coalesced / submitted), for
+// DispatcherStats.
+func (pl *paramLane) stats() (depth int, coalesceRatio float64) {
+	depth = int(atomic.LoadInt64(&pl.depth))
+	submitted := atomic.LoadUint64(&pl.submitted)
+	if submitted == 0 {
+		return depth, 0
+	}
+	return depth, float64(atomic.LoadUint64(&pl.coalesced)) / float64(submitted)
+}
+
+// SubmitParam queues a coalescable real-time float parameter change -
+// volume, pan, rate, pitch - for the param lane's drain goroutine to apply,
+// keyed by (channelID, param). If another update for the same key arrives
+// before the next drain, only the newest survives; apply is never called
+// for a value that was superseded. Unlike Submit/TrySubmit, this never
+// touches the topology queue or its mutex and never blocks the caller.
+func (d *Dispatcher) SubmitParam(channelID, param string, value float32, apply func(float32) error) {
+	d.paramLane.submit(paramUpdate{
+		key:       paramKey{channelID: channelID, param: param},
+		value:     value,
+		apply:     apply,
+		submitted: time.Now(),
+		// Every call site in this tree today is a control surface
+		// (osc_server.go) applying a change a peer asked for, not this
+		// package's own code - see EventSource.
+		source: SourceExternal,
+	})
+}
+
+// paramDrainLoop ticks every paramDrainInterval, draining whatever the param
+// lane has accumulated since the last tick. It runs for as long as the
+// dispatcher does, alongside - and independently of - dispatchLoop.
+func (d *Dispatcher) paramDrainLoop() {
+	ticker := time.NewTicker(paramDrainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stopChan:
+			d.paramLane.drain(d.handleParamApplied)
+			return
+		case <-ticker.C:
+			d.paramLane.drain(d.handleParamApplied)
+		}
+	}
+}
+
+// handleParamApplied is the param lane's drain callback: a failed apply
+// surfaces through the engine's error handler, the same path
+// executeOperation's topology errors use (there's no caller synchronously
+// waiting on SubmitParam to hand the error back to); a successful one
+// publishes a ChannelParamChanged event for Subscribe.
+func (d *Dispatcher) handleParamApplied(u paramUpdate, err error) {
+	if err != nil {
+		if d.engine != nil && d.engine.errorHandler != nil {
+			d.engine.errorHandler.HandleError(fmt.Errorf("dispatcher: param lane apply failed for %s/%s: %w", u.key.channelID, u.key.param, err))
+		}
+		return
+	}
+	d.publishEvent(EngineEvent{
+		Type: EventTypeChannelParamChanged,
+		Data: ChannelParamChanged{
+			ChannelID: u.key.channelID,
+			Param:     u.key.param,
+			Value:     u.value,
+			Source:    u.source,
+		},
+	})
+}
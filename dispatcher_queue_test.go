@@ -0,0 +1,416 @@
+package macaudio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDispatcherTrySubmitSheds verifies that TrySubmit rejects an operation
+// once the queue is at capacity, rather than blocking for room, and that the
+// rejection is counted in DispatcherStats.DroppedOperations.
+func TestDispatcherTrySubmitSheds(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dispatcher := eng.dispatcher
+	if err := dispatcher.SetQueueCapacity(2); err != nil {
+		t.Fatalf("Failed to set queue capacity: %v", err)
+	}
+
+	// Fill the queue without starting the dispatcher, so nothing drains it
+	// out from under the test.
+	for i := 0; i < 2; i++ {
+		go dispatcher.TrySubmit(DispatcherOperation{
+			Type: OpSetMute,
+			Data: SetMuteData{ChannelID: fmt.Sprintf("fill-%d", i), Muted: true},
+		})
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for dispatcher.GetPerformanceStats().QueueDepth < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth := dispatcher.GetPerformanceStats().QueueDepth; depth != 2 {
+		t.Fatalf("expected the queue to fill to capacity (2), got depth %d", depth)
+	}
+
+	if _, err := dispatcher.TrySubmit(DispatcherOperation{
+		Type: OpSetMute,
+		Data: SetMuteData{ChannelID: "overflow", Muted: true},
+	}); err == nil {
+		t.Error("expected TrySubmit to reject an operation once the queue is at capacity")
+	}
+
+	if dropped := dispatcher.GetPerformanceStats().DroppedOperations; dropped != 1 {
+		t.Errorf("expected 1 dropped operation, got %d", dropped)
+	}
+
+	// Drain the two filler operations so their goroutines don't leak past
+	// the test.
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+}
+
+// TestDispatcherSubmitCancel verifies Submit returns the context's error
+// promptly when it's canceled while still waiting for queue room, instead
+// of blocking until room eventually frees up.
+func TestDispatcherSubmitCancel(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dispatcher := eng.dispatcher
+	if err := dispatcher.SetQueueCapacity(1); err != nil {
+		t.Fatalf("Failed to set queue capacity: %v", err)
+	}
+
+	go dispatcher.TrySubmit(DispatcherOperation{
+		Type: OpSetMute,
+		Data: SetMuteData{ChannelID: "fill", Muted: true},
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for dispatcher.GetPerformanceStats().QueueDepth < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = dispatcher.Submit(ctx, DispatcherOperation{
+		Type: OpSetMute,
+		Data: SetMuteData{ChannelID: "blocked", Muted: true},
+	})
+	waited := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded waiting for queue room, got %v", err)
+	}
+	if waited > time.Second {
+		t.Errorf("Submit took %v to respect its context deadline", waited)
+	}
+
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+}
+
+// TestDispatcherPriorityPreemption verifies that a PriorityRealtime
+// operation overtakes a backlog of PriorityBulk operations already queued
+// ahead of it, rather than waiting its turn in submission order.
+func TestDispatcherPriorityPreemption(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dispatcher := eng.dispatcher
+
+	const numBulk = 20
+	completed := make(chan string, numBulk+1)
+	var wg sync.WaitGroup
+
+	// Queue up a bulk backlog before the dispatcher is even running, so it's
+	// guaranteed to all be queued ahead of the realtime operation below.
+	for i := 0; i < numBulk; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dispatcher.TrySubmit(DispatcherOperation{
+				Type:     OpSetMute,
+				Data:     SetMuteData{ChannelID: fmt.Sprintf("bulk-%d", i), Muted: true},
+				Priority: PriorityBulk,
+			})
+			completed <- "bulk"
+		}(i)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for dispatcher.GetPerformanceStats().QueueDepth < numBulk && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth := dispatcher.GetPerformanceStats().QueueDepth; depth != numBulk {
+		t.Fatalf("expected all %d bulk operations queued before the realtime one, got depth %d", numBulk, depth)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dispatcher.TrySubmit(DispatcherOperation{
+			Type:     OpSetMute,
+			Data:     SetMuteData{ChannelID: "realtime", Muted: true},
+			Priority: PriorityRealtime,
+		})
+		completed <- "realtime"
+	}()
+
+	// Give the realtime submission a moment to land in the queue behind the
+	// bulk backlog before anything starts draining it.
+	time.Sleep(10 * time.Millisecond)
+
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+
+	wg.Wait()
+	close(completed)
+
+	var order []string
+	for c := range completed {
+		order = append(order, c)
+	}
+
+	realtimeIndex := -1
+	for i, c := range order {
+		if c == "realtime" {
+			realtimeIndex = i
+			break
+		}
+	}
+	if realtimeIndex == -1 {
+		t.Fatal("the realtime operation never completed")
+	}
+	if realtimeIndex > 1 {
+		t.Errorf("expected the realtime operation to preempt the %d-deep bulk backlog, finished at position %d of %d instead", numBulk, realtimeIndex, len(order))
+	}
+}
+
+// TestDispatcherCoalescesSameKeyOperations verifies that a second OpSetMute
+// for a channel already queued folds into the queued item instead of
+// queuing separately, and that both callers still receive a result once it
+// runs.
+func TestDispatcherCoalescesSameKeyOperations(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dispatcher := eng.dispatcher
+
+	// Queue the first mute without starting the dispatcher, so nothing
+	// drains it out from under the test before the second one coalesces in.
+	first := make(chan DispatcherResult, 1)
+	go func() {
+		result, _ := dispatcher.Submit(context.Background(), DispatcherOperation{
+			Type: OpSetMute,
+			Data: SetMuteData{ChannelID: "same-channel", Muted: true},
+		})
+		first <- result
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for dispatcher.GetQueueDepth() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth := dispatcher.GetQueueDepth(); depth != 1 {
+		t.Fatalf("expected the first mute to be queued, got depth %d", depth)
+	}
+
+	second := make(chan DispatcherResult, 1)
+	go func() {
+		result, _ := dispatcher.Submit(context.Background(), DispatcherOperation{
+			Type: OpSetMute,
+			Data: SetMuteData{ChannelID: "same-channel", Muted: false},
+		})
+		second <- result
+	}()
+
+	deadline = time.Now().Add(time.Second)
+	for dispatcher.GetPerformanceStats().CoalescedOperations < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth := dispatcher.GetQueueDepth(); depth != 1 {
+		t.Errorf("expected the second mute to coalesce into the queued item rather than grow the queue, got depth %d", depth)
+	}
+	if coalesced := dispatcher.GetPerformanceStats().CoalescedOperations; coalesced != 1 {
+		t.Errorf("expected 1 coalesced operation, got %d", coalesced)
+	}
+
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+
+	select {
+	case <-first:
+	case <-time.After(time.Second):
+		t.Error("the first caller never received a result for the coalesced operation")
+	}
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Error("the second caller never received a result for the coalesced operation")
+	}
+}
+
+// TestDispatcherDefaultTimeoutDropsStuckOperation checks that an operation
+// left waiting in the queue past GetDefaultOperationTimeout is dropped with
+// ErrDispatcherTimeout, rather than left to block its caller forever.
+func TestDispatcherDefaultTimeoutDropsStuckOperation(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dispatcher := eng.dispatcher
+	dispatcher.SetDefaultOperationTimeout(20 * time.Millisecond)
+
+	// Fill the queue without starting the dispatcher, so the second
+	// submission is still waiting when its deadline passes.
+	go dispatcher.TrySubmit(DispatcherOperation{
+		Type: OpSetMute,
+		Data: SetMuteData{ChannelID: "timeout-fill", Muted: true},
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for dispatcher.GetQueueDepth() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	type submitOutcome struct {
+		result DispatcherResult
+		err    error
+	}
+	outcome := make(chan submitOutcome, 1)
+	go func() {
+		result, err := dispatcher.Submit(context.Background(), DispatcherOperation{
+			Type: OpSetMute,
+			Data: SetMuteData{ChannelID: "timeout-victim", Muted: true},
+		})
+		outcome <- submitOutcome{result, err}
+	}()
+
+	// Let the victim's 20ms default deadline (computed at submission time)
+	// elapse while nothing is draining the queue, so it's already overdue
+	// once the dispatch loop starts popping it.
+	time.Sleep(40 * time.Millisecond)
+
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+
+	select {
+	case got := <-outcome:
+		if got.err != nil {
+			t.Fatalf("Submit returned a context error: %v", got.err)
+		}
+		if !errors.Is(got.result.Error, ErrDispatcherTimeout) {
+			t.Errorf("expected ErrDispatcherTimeout, got %v", got.result.Error)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Submit never returned for the timed-out operation")
+	}
+}
+
+// TestDispatcherCtxCancelDropsQueuedOperation checks that a ...Ctx
+// submission whose context is canceled while still queued is dropped and
+// returns the context's error, without ever executing.
+func TestDispatcherCtxCancelDropsQueuedOperation(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dispatcher := eng.dispatcher
+	if err := dispatcher.SetQueueCapacity(1); err != nil {
+		t.Fatalf("Failed to set queue capacity: %v", err)
+	}
+
+	go dispatcher.TrySubmit(DispatcherOperation{
+		Type: OpSetMute,
+		Data: SetMuteData{ChannelID: "ctx-fill", Muted: true},
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for dispatcher.GetQueueDepth() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := dispatcher.SetChannelMuteCtx(ctx, "ctx-victim", true); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+}
+
+// TestDispatcherSubmitCancelWaitsForInFlightOperation verifies that when an
+// op's ctx is canceled after it's already been popped off the queue and
+// started running, Submit still waits for it to finish and hands back its
+// real DispatcherResult (alongside ctx.Err()) instead of abandoning it -
+// the in-flight half of the contract a merely-queued cancellation (see
+// TestDispatcherCtxCancelDropsQueuedOperation) doesn't need to honor.
+func TestDispatcherSubmitCancelWaitsForInFlightOperation(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	dispatcher := eng.dispatcher
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+
+	started := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	result, err := dispatcher.Submit(ctx, DispatcherOperation{
+		Type: OpRunBatch,
+		Data: RunBatchData{Fns: []func() error{
+			func() error {
+				close(started)
+				time.Sleep(100 * time.Millisecond)
+				return nil
+			},
+		}},
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected the real result from the in-flight op, got zero-value %+v", result)
+	}
+	errs, ok := result.Data.([]error)
+	if !ok || len(errs) != 1 || errs[0] != nil {
+		t.Errorf("expected the batch's actual [nil] result, got %+v", result.Data)
+	}
+}
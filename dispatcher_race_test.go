@@ -6,11 +6,15 @@ import (
 	"testing"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/shaban/macaudio/avaudio/engine"
 )
 
-// TestDispatcherRaceConditions tests that dispatcher prevents race conditions
+// TestDispatcherRaceConditions tests that dispatcher prevents race
+// conditions. It runs against a MockBackend engine (see
+// EngineConfig.MockBackend) rather than real hardware, so channel creation
+// and device changes go through the actual Dispatcher/NewAudioInputChannel
+// code paths CI can exercise without a Mac, instead of a hand-built
+// *BaseChannel stuffed directly into Engine.channels.
 func TestDispatcherRaceConditions(t *testing.T) {
 	// Create test engine
 	config := EngineConfig{
@@ -20,8 +24,8 @@ func TestDispatcherRaceConditions(t *testing.T) {
 			BitDepth:     32,
 			ChannelCount: 2,
 		},
-		OutputDeviceUID: "BuiltInSpeakerDevice", // Use built-in speaker
-		ErrorHandler:    &DefaultErrorHandler{},
+		MockBackend:  true,
+		ErrorHandler: &DefaultErrorHandler{},
 	}
 
 	testEngine, err := NewEngine(config)
@@ -41,43 +45,20 @@ func TestDispatcherRaceConditions(t *testing.T) {
 	channelIDs := make([]string, numChannels)
 
 	for i := 0; i < numChannels; i++ {
-		channelID := fmt.Sprintf("test-channel-%d", i)
-		channelIDs[i] = channelID
-
-		// Create a basic channel without device-specific configuration
-		// since we're testing the dispatcher behavior, not actual audio devices
 		config := AudioInputConfig{
-			DeviceUID:       "", // Empty device UID for testing
+			DeviceUID:       "", // No real device behind a mock-backed engine
 			InputBus:        0,
 			MonitoringLevel: 0.5,
 		}
 
-		_, err := testEngine.CreateAudioInputChannel(channelID, config)
+		channel, err := testEngine.CreateAudioInputChannel(fmt.Sprintf("test-channel-%d", i), config)
 		if err != nil {
-			// For testing purposes, create the channel entry manually if device creation fails
-			// This allows us to test the dispatcher logic
-			t.Logf("Warning: Failed to create audio channel %s: %v (continuing with test)", channelID, err)
-			
-			// Create a mock channel for testing dispatcher behavior
-			channelUUID, _ := uuid.Parse(channelID)
-			if channelUUID == uuid.Nil {
-				channelUUID = uuid.New()
-			}
-			
-			baseChannel := &BaseChannel{
-				id:        channelUUID,
-				engine:    testEngine,
-				isRunning: false,
-			}
-			
-			channel := &AudioInputChannel{
-				BaseChannel: baseChannel,
-			}
-			
-			testEngine.mu.Lock()
-			testEngine.channels[channelID] = channel
-			testEngine.mu.Unlock()
+			t.Fatalf("Failed to create audio channel %d: %v", i, err)
 		}
+		// addChannel keys the engine's channel map by the channel's own
+		// GetIDString(), not the name passed to CreateAudioInputChannel -
+		// capture the real ID rather than assuming they match.
+		channelIDs[i] = channel.GetIDString()
 	}
 
 	t.Run("ConcurrentMuteOperations", func(t *testing.T) {
@@ -301,6 +282,13 @@ func testMixedConcurrentOperations(t *testing.T, testEngine *Engine, channelIDs
 	t.Logf("  Operations completed: %d", operationsCompleted)
 	t.Logf("  Operations/sec: %.0f", float64(operationsCompleted)/duration.Seconds())
 
+	stats := testEngine.dispatcher.GetPerformanceStats()
+	t.Logf("  p99 operation latency: %v (mute=%d plugin_bypass=%d device_change=%d)",
+		stats.OperationLatencyP99, stats.OperationCounts[OpSetMute], stats.OperationCounts[OpPluginBypass], stats.OperationCounts[OpDeviceChange])
+	if stats.OperationLatencyP99 > 300*time.Millisecond {
+		t.Errorf("p99 operation latency %v exceeds 300ms target under mixed concurrent load", stats.OperationLatencyP99)
+	}
+
 	// Verify engine is still functional
 	for _, channelID := range channelIDs {
 		channel, exists := testEngine.GetChannel(channelID)
@@ -374,16 +362,21 @@ func TestDispatcherPerformance(t *testing.T) {
 	t.Logf("Dispatcher performance test completed:")
 	t.Logf("  Average operation time: %v", avgDuration)
 	t.Logf("  Target: < 300ms")
-	t.Logf("  Performance margin: %.1fx faster than target", 
+	t.Logf("  Performance margin: %.1fx faster than target",
 		(300*time.Millisecond).Seconds()/avgDuration.Seconds())
 
-	if avgDuration > 300*time.Millisecond {
-		t.Errorf("Average operation time %v exceeds 300ms target", avgDuration)
-	}
-
 	// Get dispatcher performance stats
-	lastDuration, maxDuration := testEngine.dispatcher.GetPerformanceStats()
+	stats := testEngine.dispatcher.GetPerformanceStats()
 	t.Logf("Dispatcher internal stats:")
-	t.Logf("  Last operation: %v", lastDuration)
-	t.Logf("  Max operation: %v", maxDuration)
+	t.Logf("  Last operation: %v", stats.LastOperationDuration)
+	t.Logf("  Max operation: %v", stats.MaxOperationDuration)
+	t.Logf("  p50: %v  p90: %v  p99: %v  p99.9: %v",
+		stats.OperationLatencyP50, stats.OperationLatencyP90, stats.OperationLatencyP99, stats.OperationLatencyP999)
+
+	// p99 is the guarantee that actually matters for real-time control: an
+	// average can stay comfortably under budget while a handful of
+	// operations in the tail blow past it unnoticed.
+	if stats.OperationLatencyP99 > 300*time.Millisecond {
+		t.Errorf("p99 operation latency %v exceeds 300ms target", stats.OperationLatencyP99)
+	}
 }
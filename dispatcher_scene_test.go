@@ -0,0 +1,407 @@
+package macaudio
+
+import (
+	"testing"
+)
+
+// TestDispatcherCaptureAndRecallSceneRoundTrip checks that CaptureScene/
+// RecallScene round-trip a channel's mute and volume through the
+// dispatcher, the same state SceneManager.Recall already covers directly.
+func TestDispatcherCaptureAndRecallSceneRoundTrip(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	channel, err := eng.CreateAudioInputChannel("scene-input", AudioInputConfig{
+		DeviceUID:       "",
+		InputBus:        0,
+		MonitoringLevel: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel failed: %v", err)
+	}
+	if err := channel.SetVolume(0.6); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+	if err := eng.SetChannelMute(channel.GetIDString(), false); err != nil {
+		t.Fatalf("SetChannelMute failed: %v", err)
+	}
+
+	if err := eng.CaptureScene("unmuted"); err != nil {
+		t.Fatalf("CaptureScene failed: %v", err)
+	}
+
+	if err := eng.SetChannelMute(channel.GetIDString(), true); err != nil {
+		t.Fatalf("SetChannelMute failed: %v", err)
+	}
+	if err := channel.SetVolume(0.1); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+
+	if err := eng.RecallScene("unmuted", RecallOptions{}); err != nil {
+		t.Fatalf("RecallScene failed: %v", err)
+	}
+
+	restored, exists := eng.GetChannel(channel.GetIDString())
+	if !exists {
+		t.Fatal("expected channel to still exist after recall")
+	}
+	if muted, _ := restored.GetMute(); muted {
+		t.Error("expected channel to be unmuted after recall")
+	}
+	volume, err := restored.GetVolume()
+	if err != nil {
+		t.Fatalf("GetVolume failed: %v", err)
+	}
+	if volume < 0.59 || volume > 0.61 {
+		t.Errorf("expected recalled volume ~0.6, got %f", volume)
+	}
+}
+
+// TestDispatcherRecallSceneChannelAllowlist checks that RecallOptions.ChannelIDs
+// restricts recall to the named channels, leaving every other channel
+// exactly as it was.
+func TestDispatcherRecallSceneChannelAllowlist(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	a, err := eng.CreateAudioInputChannel("scene-a", AudioInputConfig{DeviceUID: "", InputBus: 0})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel a failed: %v", err)
+	}
+	b, err := eng.CreateAudioInputChannel("scene-b", AudioInputConfig{DeviceUID: "", InputBus: 1})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel b failed: %v", err)
+	}
+
+	if err := a.SetVolume(0.7); err != nil {
+		t.Fatalf("SetVolume a failed: %v", err)
+	}
+	if err := b.SetVolume(0.7); err != nil {
+		t.Fatalf("SetVolume b failed: %v", err)
+	}
+	if err := eng.CaptureScene("both-loud"); err != nil {
+		t.Fatalf("CaptureScene failed: %v", err)
+	}
+
+	if err := a.SetVolume(0.2); err != nil {
+		t.Fatalf("SetVolume a failed: %v", err)
+	}
+	if err := b.SetVolume(0.2); err != nil {
+		t.Fatalf("SetVolume b failed: %v", err)
+	}
+
+	if err := eng.RecallScene("both-loud", RecallOptions{ChannelIDs: []string{a.GetIDString()}}); err != nil {
+		t.Fatalf("RecallScene failed: %v", err)
+	}
+
+	aVolume, err := a.GetVolume()
+	if err != nil {
+		t.Fatalf("GetVolume a failed: %v", err)
+	}
+	if aVolume < 0.69 || aVolume > 0.71 {
+		t.Errorf("expected allowlisted channel a to be recalled to ~0.7, got %f", aVolume)
+	}
+	bVolume, err := b.GetVolume()
+	if err != nil {
+		t.Fatalf("GetVolume b failed: %v", err)
+	}
+	if bVolume < 0.19 || bVolume > 0.21 {
+		t.Errorf("expected channel b to be left untouched at ~0.2, got %f", bVolume)
+	}
+}
+
+// TestDispatcherRecallSceneSoftSkipsDeviceChange checks that
+// RecallOptions.Soft leaves a channel's device UID alone even though the
+// captured scene has a different one, while still restoring the rest of
+// that channel's state. It uses a MIDI channel since its device change is a
+// cheap field assignment with no hardware to reconnect.
+func TestDispatcherRecallSceneSoftSkipsDeviceChange(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	midi, err := eng.CreateMidiInputChannel("scene-midi", MidiInputConfig{DeviceUID: "studio-keys", Channel: -1})
+	if err != nil {
+		t.Fatalf("CreateMidiInputChannel failed: %v", err)
+	}
+	if err := eng.CaptureScene("studio"); err != nil {
+		t.Fatalf("CaptureScene failed: %v", err)
+	}
+
+	if err := eng.ChangeChannelDevice(midi.GetIDString(), "travel-rig"); err != nil {
+		t.Fatalf("ChangeChannelDevice failed: %v", err)
+	}
+	if err := eng.SetChannelMute(midi.GetIDString(), true); err != nil {
+		t.Fatalf("SetChannelMute failed: %v", err)
+	}
+
+	if err := eng.RecallScene("studio", RecallOptions{Soft: true}); err != nil {
+		t.Fatalf("RecallScene failed: %v", err)
+	}
+
+	restored, exists := eng.GetChannel(midi.GetIDString())
+	if !exists {
+		t.Fatal("expected channel to still exist after recall")
+	}
+	if muted, _ := restored.GetMute(); muted {
+		t.Error("expected mute to be restored by a soft recall")
+	}
+	state := restored.GetState()
+	if uid, _ := state.Config["deviceUID"].(string); uid != "travel-rig" {
+		t.Errorf("expected soft recall to leave device UID at travel-rig, got %q", uid)
+	}
+}
+
+// TestDispatcherRecallSceneRollsBackOnUnknownScene checks that recalling a
+// scene that doesn't exist leaves the engine's state untouched and returns
+// an error, rather than partially applying anything.
+func TestDispatcherRecallSceneRollsBackOnUnknownScene(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	channel, err := eng.CreateAudioInputChannel("scene-missing", AudioInputConfig{DeviceUID: "", InputBus: 0})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel failed: %v", err)
+	}
+	if err := channel.SetVolume(0.45); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+
+	if err := eng.RecallScene("does-not-exist", RecallOptions{}); err == nil {
+		t.Fatal("expected RecallScene to fail for an unknown scene")
+	}
+
+	volume, err := channel.GetVolume()
+	if err != nil {
+		t.Fatalf("GetVolume failed: %v", err)
+	}
+	if volume < 0.44 || volume > 0.46 {
+		t.Errorf("expected channel volume to be untouched at ~0.45, got %f", volume)
+	}
+}
+
+// TestDispatcherExecuteTransactionRollsBackOnFailure checks that a batch
+// with a failing op in the middle leaves every op's effects rolled back,
+// including the ones before it that already succeeded.
+func TestDispatcherExecuteTransactionRollsBackOnFailure(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	channel, err := eng.CreateAudioInputChannel("txn-input", AudioInputConfig{DeviceUID: "", InputBus: 0})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel failed: %v", err)
+	}
+	if err := eng.SetChannelMute(channel.GetIDString(), false); err != nil {
+		t.Fatalf("SetChannelMute failed: %v", err)
+	}
+
+	err = eng.ExecuteTransaction([]DispatcherOperation{
+		{Type: OpSetMute, Data: SetMuteData{ChannelID: channel.GetIDString(), Muted: true}},
+		{Type: OpSetMute, Data: SetMuteData{ChannelID: "does-not-exist", Muted: true}},
+	})
+	if err == nil {
+		t.Fatal("expected ExecuteTransaction to fail on the unknown channel")
+	}
+
+	restored, exists := eng.GetChannel(channel.GetIDString())
+	if !exists {
+		t.Fatal("expected channel to still exist after the rolled-back transaction")
+	}
+	if muted, _ := restored.GetMute(); muted {
+		t.Error("expected the first op's mute to be rolled back after the second op failed")
+	}
+}
+
+// TestDispatcherExecuteTransactionAppliesAllOnSuccess checks that every op
+// in a transaction sticks when the whole batch succeeds.
+func TestDispatcherExecuteTransactionAppliesAllOnSuccess(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	a, err := eng.CreateAudioInputChannel("txn-a", AudioInputConfig{DeviceUID: "", InputBus: 0})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel a failed: %v", err)
+	}
+	b, err := eng.CreateAudioInputChannel("txn-b", AudioInputConfig{DeviceUID: "", InputBus: 1})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel b failed: %v", err)
+	}
+
+	if err := eng.ExecuteTransaction([]DispatcherOperation{
+		{Type: OpSetMute, Data: SetMuteData{ChannelID: a.GetIDString(), Muted: true}},
+		{Type: OpSetMute, Data: SetMuteData{ChannelID: b.GetIDString(), Muted: true}},
+	}); err != nil {
+		t.Fatalf("ExecuteTransaction failed: %v", err)
+	}
+
+	if muted, _ := a.GetMute(); !muted {
+		t.Error("expected channel a to be muted after a successful transaction")
+	}
+	if muted, _ := b.GetMute(); !muted {
+		t.Error("expected channel b to be muted after a successful transaction")
+	}
+}
+
+// TestDispatcherRestoreOnlyTouchesChangedChannels checks that Restore
+// leaves a channel untouched (volume stays at whatever it's currently at,
+// not snapped back) when the snapshot's value for that channel already
+// matches, while still applying the change to a channel that does differ -
+// the behavior that distinguishes Restore from RecallScene's full
+// teardown-and-rebuild.
+func TestDispatcherRestoreOnlyTouchesChangedChannels(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	unchanged, err := eng.CreateAudioInputChannel("restore-unchanged", AudioInputConfig{DeviceUID: "", InputBus: 0})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel unchanged failed: %v", err)
+	}
+	changed, err := eng.CreateAudioInputChannel("restore-changed", AudioInputConfig{DeviceUID: "", InputBus: 1})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel changed failed: %v", err)
+	}
+
+	if err := unchanged.SetVolume(0.5); err != nil {
+		t.Fatalf("SetVolume unchanged failed: %v", err)
+	}
+	if err := changed.SetVolume(0.5); err != nil {
+		t.Fatalf("SetVolume changed failed: %v", err)
+	}
+
+	snap, err := eng.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	if err := changed.SetVolume(0.9); err != nil {
+		t.Fatalf("SetVolume changed (second) failed: %v", err)
+	}
+
+	if err := eng.Restore(snap); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	changedVolume, err := changed.GetVolume()
+	if err != nil {
+		t.Fatalf("GetVolume changed failed: %v", err)
+	}
+	if changedVolume < 0.49 || changedVolume > 0.51 {
+		t.Errorf("expected the changed channel to be restored to ~0.5, got %f", changedVolume)
+	}
+
+	unchangedVolume, err := unchanged.GetVolume()
+	if err != nil {
+		t.Fatalf("GetVolume unchanged failed: %v", err)
+	}
+	if unchangedVolume < 0.49 || unchangedVolume > 0.51 {
+		t.Errorf("expected the untouched channel to remain at ~0.5, got %f", unchangedVolume)
+	}
+}
+
+// TestDispatcherRestoreCreatesAndRemovesChannels checks that Restore
+// recreates a channel removed after the snapshot was taken, and removes a
+// channel created after the snapshot but absent from it.
+func TestDispatcherRestoreCreatesAndRemovesChannels(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	kept, err := eng.CreateAudioInputChannel("restore-kept", AudioInputConfig{DeviceUID: "", InputBus: 0})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel kept failed: %v", err)
+	}
+
+	snap, err := eng.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	if err := eng.RemoveChannel(kept.GetIDString()); err != nil {
+		t.Fatalf("RemoveChannel failed: %v", err)
+	}
+	extra, err := eng.CreateAudioInputChannel("restore-extra", AudioInputConfig{DeviceUID: "", InputBus: 1})
+	if err != nil {
+		t.Fatalf("CreateAudioInputChannel extra failed: %v", err)
+	}
+
+	if err := eng.Restore(snap); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if _, exists := eng.GetChannel(kept.GetIDString()); !exists {
+		t.Error("expected Restore to recreate the removed channel")
+	}
+	if _, exists := eng.GetChannel(extra.GetIDString()); exists {
+		t.Error("expected Restore to remove the channel absent from the snapshot")
+	}
+}
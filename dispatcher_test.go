@@ -1,6 +1,7 @@
 package macaudio
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
@@ -120,14 +121,10 @@ func testOperationSerialization(t *testing.T, testEngine *Engine) {
 					ChannelID: fmt.Sprintf("test-channel-%d", opNum),
 					Muted:     true,
 				},
-				Response: make(chan DispatcherResult, 1),
 			}
 
 			// Submit to dispatcher
-			testEngine.dispatcher.operations <- op
-
-			// Wait for response
-			<-op.Response
+			testEngine.dispatcher.Submit(context.Background(), op)
 
 			// Record execution order
 			mu.Lock()
@@ -176,27 +173,24 @@ func testConcurrentOperationSafety(t *testing.T, testEngine *Engine) {
 						ChannelID: fmt.Sprintf("channel-%d-%d", goroutineID, op),
 						Muted:     (op % 2) == 0,
 					},
-					Response: make(chan DispatcherResult, 1),
 				}
 
-				// Submit operation
-				select {
-				case testEngine.dispatcher.operations <- operation:
-					// Wait for response
-					result := <-operation.Response
-
-					mu.Lock()
-					if result.Error != nil {
-						errorCount++ // Expected errors for non-existent channels
-					} else {
-						successCount++
-					}
-					mu.Unlock()
-
-				case <-time.After(1 * time.Second):
-					t.Errorf("Operation timed out")
+				// Submit operation with timeout
+				ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+				result, err := testEngine.dispatcher.Submit(ctx, operation)
+				cancel()
+				if err != nil {
+					t.Errorf("Operation timed out: %v", err)
 					return
 				}
+
+				mu.Lock()
+				if result.Error != nil {
+					errorCount++ // Expected errors for non-existent channels
+				} else {
+					successCount++
+				}
+				mu.Unlock()
 			}
 		}(g)
 	}
@@ -243,22 +237,18 @@ func testDispatcherPerformance(t *testing.T, testEngine *Engine) {
 				ChannelID: fmt.Sprintf("perf-test-channel-%d", i),
 				Muted:     (i % 2) == 0,
 			},
-			Response: make(chan DispatcherResult, 1),
 		}
 
 		start := time.Now()
 
 		// Submit operation
-		select {
-		case testEngine.dispatcher.operations <- operation:
-			// Wait for response
-			<-operation.Response
-			duration := time.Since(start)
-			durations = append(durations, duration)
-
-		case <-time.After(1 * time.Second):
-			t.Fatalf("Operation %d timed out", i)
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		_, err := testEngine.dispatcher.Submit(ctx, operation)
+		cancel()
+		if err != nil {
+			t.Fatalf("Operation %d timed out: %v", i, err)
 		}
+		durations = append(durations, time.Since(start))
 	}
 
 	// Calculate statistics
@@ -289,10 +279,10 @@ func testDispatcherPerformance(t *testing.T, testEngine *Engine) {
 	}
 
 	// Test dispatcher performance stats
-	lastDuration, maxFromStats := testEngine.dispatcher.GetPerformanceStats()
+	stats := testEngine.dispatcher.GetPerformanceStats()
 	t.Logf("Dispatcher internal stats:")
-	t.Logf("  Last operation: %v", lastDuration)
-	t.Logf("  Max from stats: %v", maxFromStats)
+	t.Logf("  Last operation: %v", stats.LastOperationDuration)
+	t.Logf("  Max from stats: %v", stats.MaxOperationDuration)
 
 	t.Logf("Performance test passed - dispatcher meets sub-300ms target")
 }
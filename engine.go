@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/google/uuid"
 	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/tap"
 	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/internal/rt"
 )
 
 // EngineInitState tracks engine initialization lifecycle
@@ -36,31 +40,155 @@ type Engine struct {
 	deviceMonitor *DeviceMonitor
 	dispatcher    *Dispatcher
 	serializer    *Serializer
+	sceneManager  *SceneManager
+
+	// lifecycle is Engine's AudioContextState-style event bus - see
+	// LifecycleState, AddStateChangeListener.
+	lifecycle *lifecycleBus
+
+	// oscServer is non-nil while the engine is serving OSC (see EnableOSC/
+	// DisableOSC); nil otherwise.
+	oscServer *OSCServer
 
 	// Channel management (string keys for JSON compatibility)
 	channels      map[string]Channel
 	masterChannel *MasterChannel
 
+	// registry resolves channels/buses by human-readable address instead
+	// of UUID (see ChannelRegistry) - populated alongside channels in
+	// addChannel/removeChannel, never directly.
+	registry *ChannelRegistry
+
+	// Global solo-in-place set (see SetSoloed/IsSoloed/soloInPlaceAudible in
+	// channel_group.go), keyed by GetIDString(). Additive to, and
+	// independent of, Bus's own SetSolo/anyOtherBusSoloed mechanism.
+	soloMu    sync.Mutex
+	soloedIDs map[string]bool
+
 	// AVFoundation integration
 	avEngine   *engine.Engine
 	inputNodes map[string]unsafe.Pointer // key: "deviceUID:inputBus", value: AVAudioInputNode*
 
+	// Spatial audio (see SetListener/spatial.go). environmentNode is created
+	// lazily on the first SetListener call, since most engines never use
+	// HRTF mode and an AVAudioEnvironmentNode is otherwise just idle.
+	listenerPosition [3]float32
+	listenerForward  [3]float32
+	listenerUp       [3]float32
+	listenerVelocity [3]float32 // see SetListenerVelocity; zero disables Doppler
+	environmentNode  unsafe.Pointer
+
 	// Configuration
+	sampleRate      float64
 	bufferSize      int
-	outputDeviceUID string // Single output device for entire engine
+	outputDeviceUID string // Primary output device; see outputRoutes for the full routing table
+	inputDeviceUID  string // Shared input device; see SetInputDevice
+	offline         bool   // See EngineConfig.Offline, Engine.RenderOffline
+
+	// mock is non-nil when EngineConfig.MockBackend is set, swapping the
+	// AVAudioEngine-backed parts of channel/device operations for pure-Go
+	// fakes (see MockBackend, Mock). nil on every other engine.
+	mock *MockBackend
+
+	// Multi-output routing (see OutputRoute/RouteChannelTo in outputs.go).
+	// outputRoutes always has at least one entry - EngineConfig.OutputDeviceUID
+	// expands into a single RolePrimary route when EngineConfig.Outputs isn't
+	// set. channelRoutes records per-channel RouteChannelTo assignments;
+	// aggregateDeviceUID is the CoreAudio aggregate device backing
+	// outputRoutes when they span more than one physical device, created
+	// lazily by ensureAggregateOutput.
+	outputRoutes       []OutputRoute
+	channelRoutes      map[string]string
+	aggregateDeviceUID string
 
 	// Error boundary
 	errorHandler ErrorHandler
 
 	// Initialization state tracking
 	initState EngineInitState
+
+	// Resource limits and accounting (see resource_limits.go). limits is
+	// set once at construction and read without a lock, same as
+	// sampleRate/bufferSize above; lastRenderCPUPercent/underrunCount are
+	// mutated from whatever goroutine calls RecordRenderStats, so they get
+	// their own mutex rather than contending with topology reads on mu.
+	limits               EngineLimits
+	resourceMu           sync.Mutex
+	lastRenderCPUPercent float64
+	underrunCount        uint64
+
+	// Device-loss recovery (see DeviceLossPolicy, handleDeviceStatusChanged).
+	// deviceLossMu guards lostDeviceUID/reconnectTimer rather than mu, since
+	// handleDeviceStatusChanged runs on DeviceMonitor's goroutine and
+	// shouldn't contend with topology reads for an engine that's otherwise
+	// healthy.
+	onDeviceLost        DeviceLossPolicy
+	deviceLossTimeout   time.Duration
+	preferredDeviceUIDs []string
+	deviceLossMu        sync.Mutex
+	lostDeviceUID       string
+	reconnectTimer      *time.Timer
+
+	// lostChannelDevices maps a channel ID to the device UID it was muted
+	// over, for channels handleChannelDeviceStatusChanged muted because
+	// their own device went offline with no FallbackDeviceUID configured -
+	// the channel-scoped counterpart to lostDeviceUID above. Guarded by
+	// deviceLossMu alongside lostDeviceUID/reconnectTimer.
+	lostChannelDevices map[string]string
 }
 
 // EngineConfig holds configuration for engine initialization
 type EngineConfig struct {
 	AudioSpec       engine.AudioSpec // Complete audio specification
-	OutputDeviceUID string           // Single output device for entire engine
+	OutputDeviceUID string           // Shorthand for Outputs: []OutputRoute{{Name: "primary", DeviceUID: OutputDeviceUID, Role: RolePrimary}}
 	ErrorHandler    ErrorHandler     // Optional: defaults to DefaultErrorHandler
+	// Outputs lists every physical output the engine can route a channel to
+	// (see OutputRoute, Engine.RouteChannelTo). When empty, OutputDeviceUID
+	// expands into a single RolePrimary route, so existing single-device
+	// configs keep working unchanged. When more than one distinct device UID
+	// is listed, Start creates a CoreAudio aggregate device spanning them
+	// (see Engine.ensureAggregateOutput).
+	Outputs []OutputRoute
+	// ListenerPosition is the initial position of the spatial audio
+	// listener (see Engine.SetListener). Left at the zero value, the
+	// listener sits at the origin facing the default forward/up vectors.
+	ListenerPosition [3]float32
+	// Offline puts the engine in AVAudioEngine's manual rendering mode
+	// instead of binding to real output hardware, for faster-than-realtime
+	// bounces via Engine.RenderOffline. OutputDeviceUID/Outputs aren't
+	// required when Offline is set, since there's no hardware to route to.
+	Offline bool
+	// MockBackend swaps the AVAudioEngine-backed parts of channel creation
+	// and device changes for pure-Go fakes (see MockBackend, Engine.Mock):
+	// audio input channels skip real input-node/mixer creation, and device
+	// changes simulate instead of reconnecting hardware. Like Offline, no
+	// OutputDeviceUID/Outputs or route validation is required when this is
+	// set, since there's no hardware to route to or validate against.
+	// Intended for integration tests that need the real Dispatcher - its
+	// operation queue, serialization, and channel registry - without a Mac
+	// or real devices; real builds should leave this false.
+	MockBackend bool
+	// Limits caps how many graph resources the dispatcher will create
+	// (see EngineLimits); the zero value leaves every ceiling unlimited,
+	// matching today's behavior.
+	Limits EngineLimits
+	// OnDeviceLost controls what happens when the engine's primary output
+	// route's device reports going offline without being unplugged outright
+	// (see DeviceLossPolicy, Engine.handleDeviceStatusChanged). The zero
+	// value, StopEngine, matches today's behavior of leaving recovery to
+	// the caller.
+	OnDeviceLost DeviceLossPolicy
+	// DeviceLossTimeout bounds how long OnDeviceLost's WaitForReconnect
+	// waits for the lost device to come back online before falling back
+	// like FallbackToDefault. Zero waits indefinitely. Ignored unless
+	// OnDeviceLost is WaitForReconnect.
+	DeviceLossTimeout time.Duration
+	// PreferredDeviceUIDs orders which device OnDeviceLost's
+	// FallbackToDefault (or a timed-out WaitForReconnect) reroutes to: the
+	// first UID in this list that's online and output-capable wins. An
+	// empty list, or one with no online entry, falls back to whatever
+	// firstOnlineOutput picks.
+	PreferredDeviceUIDs []string
 	// ❌ REMOVED: AudioDeviceUID - individual channels bind to their own input devices
 	// ❌ REMOVED: MidiDeviceUID - individual channels bind to their own MIDI devices
 }
@@ -93,26 +221,33 @@ func NewEngine(config EngineConfig) (*Engine, error) {
 		config.AudioSpec.ChannelCount = 2 // Stereo
 	}
 
-	if config.OutputDeviceUID == "" {
+	if config.OutputDeviceUID == "" && len(config.Outputs) == 0 && !config.Offline && !config.MockBackend {
 		return nil, fmt.Errorf("OutputDeviceUID is required in EngineConfig")
 	}
 	if config.ErrorHandler == nil {
 		config.ErrorHandler = &DefaultErrorHandler{}
 	}
-
-	// Validate output device exists and is online
-	audioDevices, err := devices.GetAudio()
-	if err != nil {
-		return nil, fmt.Errorf("failed to enumerate audio devices: %w", err)
+	// Every Engine actually stores a channelErrorHandler wrapping whatever
+	// was configured, so HandleError calls throughout the codebase also fan
+	// out onto Errors() for free - see SetErrorHandler/Errors.
+	config.ErrorHandler = newChannelErrorHandler(config.ErrorHandler)
+
+	outputRoutes := config.Outputs
+	if len(outputRoutes) == 0 && !config.Offline && !config.MockBackend {
+		outputRoutes = expandOutputDeviceUID(config.OutputDeviceUID)
 	}
-
-	device := audioDevices.ByUID(config.OutputDeviceUID)
-	if device == nil {
-		return nil, fmt.Errorf("output device with UID %s not found", config.OutputDeviceUID)
+	if config.OutputDeviceUID == "" && len(outputRoutes) > 0 {
+		config.OutputDeviceUID = outputRoutes[0].DeviceUID
 	}
 
-	if !device.IsOnline {
-		return nil, fmt.Errorf("output device %s is not online", config.OutputDeviceUID)
+	// Validate every route's device exists, is online, and (when routing to
+	// more than one distinct device) shares a common sample rate. An
+	// offline or mock-backed engine has no routes to validate - neither
+	// ever touches hardware.
+	if !config.Offline && !config.MockBackend {
+		if err := validateOutputRoutes(outputRoutes); err != nil {
+			return nil, err
+		}
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -125,17 +260,32 @@ func NewEngine(config EngineConfig) (*Engine, error) {
 	}
 
 	engineInstance := &Engine{
-		id:              uuid.New(),
-		name:            "MacAudio Engine",
-		ctx:             ctx,
-		cancel:          cancel,
-		channels:        make(map[string]Channel),
-		avEngine:        avEngine,
-		inputNodes:      make(map[string]unsafe.Pointer),
-		bufferSize:      config.AudioSpec.BufferSize,
-		outputDeviceUID: config.OutputDeviceUID,
-		errorHandler:    config.ErrorHandler,
-		initState:       EngineCreated,
+		id:               uuid.New(),
+		name:             "MacAudio Engine",
+		ctx:              ctx,
+		cancel:           cancel,
+		channels:         make(map[string]Channel),
+		registry:         newChannelRegistry(),
+		soloedIDs:        make(map[string]bool),
+		avEngine:         avEngine,
+		inputNodes:       make(map[string]unsafe.Pointer),
+		sampleRate:       config.AudioSpec.SampleRate,
+		bufferSize:       config.AudioSpec.BufferSize,
+		outputDeviceUID:  config.OutputDeviceUID,
+		outputRoutes:     outputRoutes,
+		offline:          config.Offline,
+		errorHandler:     config.ErrorHandler,
+		mock:             newMockBackendIfEnabled(config.MockBackend),
+		initState:        EngineCreated,
+		limits:           config.Limits,
+		listenerPosition: config.ListenerPosition,
+		listenerForward:  defaultForward,
+		listenerUp:       defaultUp,
+
+		onDeviceLost:        config.OnDeviceLost,
+		deviceLossTimeout:   config.DeviceLossTimeout,
+		preferredDeviceUIDs: config.PreferredDeviceUIDs,
+		lostChannelDevices:  make(map[string]string),
 	}
 
 	// Initialize master channel (always present)
@@ -147,6 +297,7 @@ func NewEngine(config EngineConfig) (*Engine, error) {
 	}
 	engineInstance.masterChannel = masterChannel
 	engineInstance.channels[masterChannel.GetIDString()] = masterChannel // UUID to string conversion
+	engineInstance.registry.Register(masterChannel)
 	engineInstance.initState = MasterReady
 
 	// Initialize device monitor
@@ -154,7 +305,7 @@ func NewEngine(config EngineConfig) (*Engine, error) {
 
 	// Initialize dispatcher for serialized topology changes
 	engineInstance.dispatcher = NewDispatcher(engineInstance)
-	
+
 	// Start dispatcher immediately - channel creation needs it before engine.Start()
 	if err := engineInstance.dispatcher.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start dispatcher: %w", err)
@@ -163,6 +314,26 @@ func NewEngine(config EngineConfig) (*Engine, error) {
 	// Initialize serializer for state persistence
 	engineInstance.serializer = NewSerializer(engineInstance)
 
+	// Initialize scene manager for dispatcher-serialized capture/recall
+	// (see Dispatcher.CaptureScene/RecallScene)
+	engineInstance.sceneManager = NewSceneManager(engineInstance.serializer)
+
+	// Initialize the lifecycle event bus and its delivery goroutine (see
+	// LifecycleState, AddStateChangeListener). Destroy closes lb.events to
+	// stop this goroutine.
+	engineInstance.lifecycle = newLifecycleBus()
+	go engineInstance.lifecycleLoop(engineInstance.lifecycle)
+
+	// Bridge avengine's OS-level notifications into EngineError events (see
+	// reportNotification). The native observer avengine.OnNotification
+	// installs isn't wired up yet (see its doc comment), so this never
+	// actually fires; it's here so the day it is, ErrorKindConfigurationChange/
+	// ErrorKindMediaServicesReset/ErrorKindRenderException start flowing
+	// without any other change.
+	if err := avEngine.OnNotification(engineInstance.reportNotification); err != nil {
+		engineInstance.errorHandler.HandleError(fmt.Errorf("failed to install engine notification observer: %w", err))
+	}
+
 	return engineInstance, nil
 }
 
@@ -176,16 +347,11 @@ func (e *Engine) Start() error {
 	}
 
 	// Route actual engine start through dispatcher for serialization
-	response := make(chan DispatcherResult, 1)
-	op := DispatcherOperation{
-		Type:     OpStartEngine,
-		Data:     nil, // No data needed for engine start
-		Response: response,
-	}
-	
-	e.dispatcher.operations <- op
-	result := <-response
-	
+	result := e.dispatcher.submit(DispatcherOperation{
+		Type: OpStartEngine,
+		Data: nil, // No data needed for engine start
+	})
+
 	if !result.Success {
 		// Cleanup dispatcher if start failed
 		e.dispatcher.Stop()
@@ -193,6 +359,7 @@ func (e *Engine) Start() error {
 	}
 
 	e.isRunning = true
+	e.setLifecycleState(StateRunning, nil)
 	return nil
 } // Stop halts all engine operations and cleanup
 func (e *Engine) Stop() error {
@@ -204,16 +371,11 @@ func (e *Engine) Stop() error {
 	}
 
 	// Route engine stop through dispatcher for serialization
-	response := make(chan DispatcherResult, 1)
-	op := DispatcherOperation{
-		Type:     OpStopEngine,
-		Data:     nil, // No data needed for engine stop
-		Response: response,
-	}
-	
-	e.dispatcher.operations <- op
-	result := <-response
-	
+	result := e.dispatcher.submit(DispatcherOperation{
+		Type: OpStopEngine,
+		Data: nil, // No data needed for engine stop
+	})
+
 	if !result.Success {
 		e.errorHandler.HandleError(fmt.Errorf("engine stop failed: %w", result.Error))
 		// Continue with cleanup even if dispatcher stop failed
@@ -228,6 +390,7 @@ func (e *Engine) Stop() error {
 	e.cancel()
 
 	e.isRunning = false
+	e.setLifecycleState(StateSuspended, nil)
 	return nil
 }
 
@@ -240,6 +403,15 @@ func (e *Engine) GetID() uuid.UUID {
 	return e.id
 }
 
+// AudioFormat returns the engine's negotiated sample rate and buffer size,
+// for a PluginChain to hand to a HostModeSandboxed PluginInstance's
+// handshake with its child process (see PluginChain.setHostSpec).
+func (e *Engine) AudioFormat() (sampleRate float64, bufferSize int) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.sampleRate, e.bufferSize
+}
+
 // GetIDString returns the engine's UUID as string
 func (e *Engine) GetIDString() string {
 	return e.GetID().String()
@@ -289,6 +461,107 @@ func (e *Engine) GetMasterChannel() *MasterChannel {
 	return e.masterChannel
 }
 
+// SetErrorHandler replaces the handler HandleError calls throughout the
+// engine are forwarded to. It continues to work exactly as before
+// EngineError/Errors existed - every caller in this package still calls
+// e.errorHandler.HandleError(err) directly - because e.errorHandler is
+// always a channelErrorHandler wrapping whatever's set here (see NewEngine);
+// this just swaps what it forwards to.
+func (e *Engine) SetErrorHandler(h ErrorHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ceh, ok := e.errorHandler.(*channelErrorHandler); ok {
+		ceh.setUnderlying(h)
+	}
+}
+
+// Errors returns a channel of structured EngineError events, fed by every
+// e.errorHandler.HandleError(...) call in this package (reported as
+// ErrorKindGeneric) plus avengine's engine-configuration/media-services/
+// render-exception notifications (see reportNotification), for a caller
+// that wants a select-friendly surface instead of implementing ErrorHandler.
+// The channel is buffered and lossy: if a consumer falls behind, events are
+// dropped rather than blocking whatever goroutine hit the error - see
+// DroppedErrorCount.
+func (e *Engine) Errors() <-chan EngineError {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if ceh, ok := e.errorHandler.(*channelErrorHandler); ok {
+		return ceh.ch
+	}
+	return nil
+}
+
+// DroppedErrorCount returns how many EngineError events have been dropped
+// from Errors() because its channel was full.
+func (e *Engine) DroppedErrorCount() uint64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if ceh, ok := e.errorHandler.(*channelErrorHandler); ok {
+		return atomic.LoadUint64(&ceh.droppedCount)
+	}
+	return 0
+}
+
+// reportNotification translates an avaudio/engine.EngineNotification (see
+// avengine.OnNotification, wired up in NewEngine) into an EngineError and
+// reports it through the same channelErrorHandler every other error goes
+// through, so Errors() sees it alongside everything else. A configuration
+// change or media services reset also moves the engine to StateInterrupted
+// (see LifecycleState) - AVFoundation drops the graph's connections on
+// both, so a caller needs to notice and call Resume, or rebuild routing,
+// before audio flows again.
+func (e *Engine) reportNotification(n engine.EngineNotification) {
+	var kind ErrorKind
+	var err error
+	interrupts := false
+	switch n.Kind {
+	case engine.NotificationConfigurationChange:
+		kind = ErrorKindConfigurationChange
+		err = fmt.Errorf("engine configuration changed")
+		interrupts = true
+	case engine.NotificationMediaServicesReset:
+		kind = ErrorKindMediaServicesReset
+		err = fmt.Errorf("CoreAudio media services were reset")
+		interrupts = true
+	case engine.NotificationRenderException:
+		kind = ErrorKindRenderException
+		err = fmt.Errorf("render thread exception on node %p", n.NodePtr)
+	default:
+		kind = ErrorKindGeneric
+		err = fmt.Errorf("unknown engine notification %v", n.Kind)
+	}
+
+	e.mu.RLock()
+	ceh, ok := e.errorHandler.(*channelErrorHandler)
+	e.mu.RUnlock()
+	if ok {
+		ceh.emit(kind, err, n.NodePtr)
+	} else {
+		e.errorHandler.HandleError(err)
+	}
+
+	if interrupts {
+		e.setLifecycleState(StateInterrupted, err)
+	}
+}
+
+// GetOutputDeviceUID returns the UID of the engine's current primary output
+// device (see Dispatcher.ChangeOutputDevice).
+func (e *Engine) GetOutputDeviceUID() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.outputDeviceUID
+}
+
+// GetInputDeviceUID returns the UID of the engine's current shared input
+// device (see Dispatcher.ChangeInputDevice).
+func (e *Engine) GetInputDeviceUID() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.inputDeviceUID
+}
+
 // ListChannels returns all channel IDs
 func (e *Engine) ListChannels() []string {
 	e.mu.RLock()
@@ -306,6 +579,30 @@ func (e *Engine) CreateAudioInputChannel(id string, config AudioInputConfig) (*A
 	return e.dispatcher.CreateAudioInputChannel(id, config)
 }
 
+// CreateLoopbackChannel creates a new system/per-process audio loopback
+// input channel (see LoopbackInputChannel).
+func (e *Engine) CreateLoopbackChannel(id string, config LoopbackConfig) (*LoopbackInputChannel, error) {
+	return e.dispatcher.CreateLoopbackChannel(id, config)
+}
+
+// CreateRecordingChannel is CreateAudioInputChannel plus an immediate
+// StartRecording: a convenience for the common "capture this device
+// straight to a file" case, for callers who don't also need the channel
+// available for live monitoring before recording starts. filePath and
+// format are passed straight through to StartRecording; on failure the
+// channel it created is removed rather than left around half-wired.
+func (e *Engine) CreateRecordingChannel(id string, config AudioInputConfig, filePath string, format tap.RecordFormat) (*AudioInputChannel, error) {
+	channel, err := e.dispatcher.CreateAudioInputChannel(id, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := channel.StartRecording(filePath, format); err != nil {
+		_ = e.dispatcher.RemoveChannel(id)
+		return nil, fmt.Errorf("failed to start recording: %w", err)
+	}
+	return channel, nil
+}
+
 // CreateMidiInputChannel creates a new MIDI input channel
 func (e *Engine) CreateMidiInputChannel(id string, config MidiInputConfig) (*MidiInputChannel, error) {
 	return e.dispatcher.CreateMidiInputChannel(id, config)
@@ -321,6 +618,86 @@ func (e *Engine) CreateAuxChannel(id string, config AuxConfig) (*AuxChannel, err
 	return e.dispatcher.CreateAuxChannel(id, config)
 }
 
+// CreatePlaybackChannelFromDecoder creates a playback channel streaming
+// audio decoded from dec (see Decoder/RegisterDecoder), for formats
+// AVAudioFile can't open directly - FLAC/MP3/Opus via a registered
+// Decoder, or any io.Reader source (HTTP bodies, tar entries, in-memory
+// buffers) passed to OpenDecoder. See CreatePlaybackChannel for the
+// filesystem-path equivalent.
+func (e *Engine) CreatePlaybackChannelFromDecoder(id string, dec Decoder) (*PlaybackChannel, error) {
+	return e.dispatcher.CreatePlaybackChannelFromDecoder(id, dec)
+}
+
+// CreateProcessingChannel creates a new render-unit-backed processing
+// channel (see ProcessingChannel).
+func (e *Engine) CreateProcessingChannel(id string, config ProcessingConfig) (*ProcessingChannel, error) {
+	return e.dispatcher.CreateProcessingChannel(id, config)
+}
+
+// CreateSineChannel creates a synth channel generating a sine wave at
+// freqHz with the given amplitude (0.0-1.0), for tests that need a known,
+// macOS-path-free audio source instead of a fixed .aiff fixture.
+func (e *Engine) CreateSineChannel(id string, freqHz, amplitude float64) (*SynthChannel, error) {
+	return e.dispatcher.CreateSynthChannel(id, SynthConfig{
+		Kind:      SynthSine,
+		Frequency: freqHz,
+		Amplitude: amplitude,
+	})
+}
+
+// CreateNoiseChannel creates a synth channel generating noise of the given
+// kind.
+func (e *Engine) CreateNoiseChannel(id string, kind NoiseKind) (*SynthChannel, error) {
+	return e.dispatcher.CreateSynthChannel(id, SynthConfig{
+		Kind:      SynthNoise,
+		Noise:     kind,
+		Amplitude: 1.0,
+	})
+}
+
+// CreateSweepChannel creates a synth channel sweeping linearly from startHz
+// to endHz over duration, then holding at endHz.
+func (e *Engine) CreateSweepChannel(id string, startHz, endHz float64, duration time.Duration) (*SynthChannel, error) {
+	return e.dispatcher.CreateSynthChannel(id, SynthConfig{
+		Kind:           SynthSweep,
+		StartFrequency: startHz,
+		EndFrequency:   endHz,
+		Duration:       duration,
+		Amplitude:      1.0,
+	})
+}
+
+// CreateSilenceChannel creates a synth channel that produces digital
+// silence, for capacity tests that need to exercise the channel graph
+// without contributing audible output.
+func (e *Engine) CreateSilenceChannel(id string) (*SynthChannel, error) {
+	return e.dispatcher.CreateSynthChannel(id, SynthConfig{Kind: SynthSilence})
+}
+
+// CreateSamplerChannel creates a channel backed by a native AVAudioUnitSampler
+// node (see SamplerChannel), optionally loading config.SoundFontPath right
+// away. Drive it with SamplerChannel.StartNote/StopNote, directly or from a
+// MIDI input device's decoded Note On/Off - this package's MidiInputChannel
+// is presently just a config holder with no live event wiring of its own, so
+// that routing is currently the caller's job.
+func (e *Engine) CreateSamplerChannel(id string, config SamplerConfig) (*SamplerChannel, error) {
+	return e.dispatcher.CreateSamplerChannel(id, config)
+}
+
+// CreateBus creates a new sub-mixer bus that channels can RouteTo, giving a
+// group like "drums" or "vocals" its own volume/pan/mute and shared effects
+// inserts (see Bus.InsertEffect) instead of duplicating them per channel.
+func (e *Engine) CreateBus(name string) (*Bus, error) {
+	return e.dispatcher.CreateBus(name, BusConfig{})
+}
+
+// CreateGroup creates a new VCA-style control group that channels (or other
+// groups) can be AssignChannel'd into, giving a fader that scales every
+// member's output without touching their own stored Volume.
+func (e *Engine) CreateGroup(name string) (*ChannelGroup, error) {
+	return e.dispatcher.CreateGroup(name)
+}
+
 // RemoveChannel removes a channel from the engine
 func (e *Engine) RemoveChannel(id string) error {
 	return e.dispatcher.RemoveChannel(id)
@@ -340,6 +717,29 @@ func (e *Engine) GetDispatcher() *Dispatcher {
 	return e.dispatcher
 }
 
+// Post pushes cmd onto the dispatcher's lock-free rt.Ring for a render-
+// thread consumer to apply (see Dispatcher.drainRTRing, internal/rt), so a
+// producer like the OSC server, a MIDI input, or a device-monitor callback
+// never takes a mutex the audio thread could also be waiting on. It returns
+// false if the ring was full and cmd was dropped instead - see
+// DispatcherStats.RTRingDropped. Unlike SubmitParam, Post does no per-key
+// coalescing: every accepted command is applied.
+func (e *Engine) Post(cmd rt.Command) bool {
+	return e.dispatcher.rtRing.Push(cmd)
+}
+
+// PostBundle posts every command in cmds via Post, in order, returning how
+// many were accepted - for a caller (e.g. an OSC bundle) that wants several
+// commands to land together without submitting them one at a time.
+func (e *Engine) PostBundle(cmds []rt.Command) (accepted int) {
+	for _, cmd := range cmds {
+		if e.Post(cmd) {
+			accepted++
+		}
+	}
+	return accepted
+}
+
 // GetSerializer returns the serializer for state management
 func (e *Engine) GetSerializer() *Serializer {
 	e.mu.RLock()
@@ -347,6 +747,17 @@ func (e *Engine) GetSerializer() *Serializer {
 	return e.serializer
 }
 
+// GetSceneManager returns the engine's scene manager for direct (non-
+// dispatcher-serialized) use, e.g. Scenes()/DeleteScene(). Capture and
+// recall should normally go through Dispatcher.CaptureScene/RecallScene
+// instead, so they serialize with concurrent OpSetMute/OpPluginBypass
+// traffic.
+func (e *Engine) GetSceneManager() *SceneManager {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.sceneManager
+}
+
 // GetConfiguration returns current engine configuration
 func (e *Engine) GetConfiguration() EngineConfig {
 	e.mu.RLock()
@@ -358,11 +769,15 @@ func (e *Engine) GetConfiguration() EngineConfig {
 	return EngineConfig{
 		AudioSpec:       currentSpec,
 		OutputDeviceUID: e.outputDeviceUID,
+		Outputs:         e.outputRoutes,
 		ErrorHandler:    e.errorHandler,
 	}
 }
 
-// addChannel adds a channel to the engine (internal method called by dispatcher)
+// addChannel adds a channel to the engine (internal method called by dispatcher,
+// and - during state restore, see Serializer.SetState - by whatever goroutine
+// loads a saved EngineState, so this doesn't assert dispatcher-only the way
+// the dispatcher's own createX methods do).
 func (e *Engine) addChannel(channel Channel) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -372,16 +787,22 @@ func (e *Engine) addChannel(channel Channel) error {
 		return fmt.Errorf("channel with ID %s already exists", idString)
 	}
 
+	if err := e.checkChannelLimit(); err != nil {
+		return err
+	}
+
 	e.channels[idString] = channel
+	e.registry.Register(channel)
 	return nil
 }
 
-// removeChannel removes a channel from the engine (internal method called by dispatcher)
+// removeChannel removes a channel from the engine (internal method called by
+// dispatcher, and by Serializer.SetState during state restore - see addChannel).
 func (e *Engine) removeChannel(id string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if id == "master" {
+	if e.masterChannel != nil && id == e.masterChannel.GetIDString() {
 		return fmt.Errorf("cannot remove master channel")
 	}
 
@@ -396,6 +817,11 @@ func (e *Engine) removeChannel(id string) error {
 	}
 
 	delete(e.channels, id)
+	e.registry.Unregister(id)
+
+	if emitter, ok := channel.(interface{ emitReleased(id string) }); ok {
+		emitter.emitReleased(id)
+	}
 	return nil
 }
 
@@ -412,6 +838,10 @@ func (e *Engine) getOrCreateInputNode(deviceUID string, inputBus int) (unsafe.Po
 		return node, nil
 	}
 
+	if err := e.checkInputNodeLimit(); err != nil {
+		return nil, err
+	}
+
 	// Get the AVAudioEngine's input node
 	inputNode, err := e.avEngine.InputNode()
 	if err != nil {
@@ -429,7 +859,7 @@ func (e *Engine) SetChannelMute(channelID string, muted bool) error {
 	return e.dispatcher.SetChannelMute(channelID, muted)
 }
 
-// SetPluginBypass sets plugin bypass state via dispatcher (topology change) 
+// SetPluginBypass sets plugin bypass state via dispatcher (topology change)
 func (e *Engine) SetPluginBypass(channelID, pluginID string, bypassed bool) error {
 	return e.dispatcher.SetPluginBypass(channelID, pluginID, bypassed)
 }
@@ -444,6 +874,76 @@ func (e *Engine) ChangeOutputDevice(newDeviceUID string) error {
 	return e.dispatcher.ChangeOutputDevice(newDeviceUID)
 }
 
+// CaptureScene saves the engine's full current state (every channel's
+// mute, monitoring level, device UID, volume/pan, plugin bypass flags, and
+// routing) under name via the dispatcher, so it serializes with concurrent
+// topology changes the same way SetChannelMute/SetPluginBypass do. Use
+// GetSceneManager().Scenes()/DeleteScene() to list or remove saved scenes.
+func (e *Engine) CaptureScene(name string) error {
+	return e.dispatcher.CaptureScene(name)
+}
+
+// RecallScene restores the named scene via the dispatcher as a single
+// batched operation: a failure partway through rolls the engine back to
+// its state from just before the recall started, rather than leaving some
+// channels on the new scene and others on the old one. opts controls the
+// recall's fade time, an optional channel-ID allowlist, and whether device
+// changes are skipped (see RecallOptions).
+func (e *Engine) RecallScene(name string, opts RecallOptions) error {
+	return e.dispatcher.RecallScene(name, opts)
+}
+
+// ExecuteTransaction applies ops as a single all-or-nothing batch via the
+// dispatcher: if one op fails partway through, every op already applied is
+// rolled back and the failure is returned, rather than leaving the graph
+// half-reconfigured. Use this for an ad hoc batch of create/connect/mute/
+// param ops built up by a caller; for a named, persisted snapshot use
+// CaptureScene/RecallScene instead.
+func (e *Engine) ExecuteTransaction(ops []DispatcherOperation) error {
+	return e.dispatcher.ExecuteTransaction(ops)
+}
+
+// Snapshot captures the engine's current topology via the dispatcher, for
+// a later Restore call - see Dispatcher.Snapshot.
+func (e *Engine) Snapshot() (*EngineSnapshot, error) {
+	return e.dispatcher.Snapshot()
+}
+
+// Restore applies snap via the dispatcher, touching only the channels that
+// differ from the engine's current topology rather than tearing everything
+// down - see Dispatcher.Restore.
+func (e *Engine) Restore(snap *EngineSnapshot) error {
+	return e.dispatcher.Restore(snap)
+}
+
+// Devices lists the available audio devices (see engine.Devices), for
+// picking a UID to pass to SetOutputDevice/SetInputDevice.
+func (e *Engine) Devices() ([]engine.DeviceInfo, error) {
+	return engine.Devices()
+}
+
+// SetOutputDevice switches the engine's output hardware to the device with
+// the given UID, pausing and resuming the underlying AVAudioEngine around
+// the switch (see Dispatcher.changeOutputDevice). This is the same
+// operation as ChangeOutputDevice; SetOutputDevice is the entry point
+// paired with Devices/OnDeviceChange for picking and reacting to hardware.
+func (e *Engine) SetOutputDevice(uid string) error {
+	return e.dispatcher.ChangeOutputDevice(uid)
+}
+
+// SetInputDevice switches the engine's single shared input device to the
+// device with the given UID (see avengine.Engine.SetInputDevice's doc
+// comment on why this is engine-wide rather than per-channel).
+func (e *Engine) SetInputDevice(uid string) error {
+	return e.dispatcher.ChangeInputDevice(uid)
+}
+
+// OnDeviceChange registers fn to be called when the set of available audio
+// devices changes (see Dispatcher.OnDeviceChanged).
+func (e *Engine) OnDeviceChange(fn func(engine.DeviceChangeEvent)) {
+	e.dispatcher.OnDeviceChanged(fn)
+}
+
 // removeInputNode removes a shared input node when no longer needed
 func (e *Engine) removeInputNode(deviceUID string, inputBus int) {
 	e.mu.Lock()
@@ -466,6 +966,16 @@ func (e *Engine) getAVEngine() *engine.Engine {
 	return e.avEngine
 }
 
+// hardwareless reports whether e was configured to never touch real audio
+// hardware or devices - true for both EngineConfig.Offline (faster-than-realtime
+// rendering to a buffer) and EngineConfig.MockBackend (dispatcher tests with
+// no device at all). The Start path checks this instead of e.offline alone so
+// a mock-backed engine skips the same device validation and hardware-start
+// steps an offline one does.
+func (e *Engine) hardwareless() bool {
+	return e.offline || e.mock != nil
+}
+
 // startAVEngineIfReady starts the AVFoundation engine when audio graph is complete
 func (e *Engine) startAVEngineIfReady() error {
 	// Only start if not already running and we have a complete audio path
@@ -478,6 +988,15 @@ func (e *Engine) startAVEngineIfReady() error {
 		return fmt.Errorf("master channel not available")
 	}
 
+	// An offline engine renders faster-than-realtime through manual
+	// rendering mode instead of the hardware I/O callback - see
+	// Engine.RenderOffline - so switch into that mode before starting.
+	if e.offline {
+		if err := e.avEngine.SetOfflineRenderingMode(true, e.bufferSize); err != nil {
+			return fmt.Errorf("failed to enable offline rendering mode: %w", err)
+		}
+	}
+
 	// Start the AVFoundation engine with complete graph
 	if err := e.avEngine.Start(); err != nil {
 		return fmt.Errorf("failed to start AVFoundation engine: %w", err)
@@ -491,6 +1010,9 @@ func (e *Engine) stopAVEngine() {
 	if e.avEngine != nil && e.avEngine.IsRunning() {
 		e.avEngine.Stop()
 	}
+	if e.offline && e.avEngine != nil {
+		e.avEngine.SetOfflineRenderingMode(false, 0)
+	}
 }
 
 // Destroy properly cleans up the engine and all resources
@@ -506,11 +1028,24 @@ func (e *Engine) Destroy() {
 	// Clear input nodes map
 	e.inputNodes = make(map[string]unsafe.Pointer)
 
+	// Tear down the aggregate output device, if ensureAggregateOutput created one
+	if e.aggregateDeviceUID != "" {
+		if err := devices.DestroyAggregateDevice(e.aggregateDeviceUID); err != nil {
+			e.errorHandler.HandleError(fmt.Errorf("failed to destroy aggregate output device: %w", err))
+		}
+		e.aggregateDeviceUID = ""
+	}
+
 	// Destroy AVFoundation engine
 	if e.avEngine != nil {
 		e.avEngine.Destroy()
 		e.avEngine = nil
 	}
+
+	// StateClosed is terminal; close the event channel last so
+	// lifecycleLoop delivers it, then exits.
+	e.setLifecycleState(StateClosed, nil)
+	close(e.lifecycle.events)
 }
 
 // prepareAudioRouting sets up basic audio routing to satisfy AVFoundation requirements
@@ -571,6 +1106,12 @@ func (e *Engine) validateEngineReadiness() error {
 		return fmt.Errorf("dispatcher is not initialized")
 	}
 
+	// An offline or mock-backed engine never touches hardware, so there's no
+	// output device to validate (see EngineConfig.Offline, EngineConfig.MockBackend).
+	if e.hardwareless() {
+		return nil
+	}
+
 	// Validate output device is still available
 	audioDevices, err := devices.GetAudio()
 	if err != nil {
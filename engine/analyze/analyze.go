@@ -20,17 +20,133 @@ type PathAnalysis struct {
 	InputRMS        float64       // Input signal level
 	OutputRMS       float64       // Output signal level
 	GainChange      float64       // dB change from input to output
+
+	// Loudness is the output tap's ITU-R BS.1770 / EBU R128 reading - see
+	// MeasureLoudness. Zero value if output wasn't detected.
+	Loudness LoudnessResult
 }
 
 // StereoAnalysis contains results of mono→stereo conversion analysis
 type StereoAnalysis struct {
-	LeftChannelRMS  float64 // Left channel level
-	RightChannelRMS float64 // Right channel level
-	PanPosition     float32 // Calculated pan (-1.0 to 1.0)
-	StereoWidth     float64 // How "wide" the stereo image is
-	MonoCompatible  bool    // Sums to mono correctly
+	LeftChannelRMS  float64 // Measured left channel RMS
+	RightChannelRMS float64 // Measured right channel RMS
+	PanPosition     float32 // Pan back-computed from measured L/R (-1.0 to 1.0)
+	StereoWidth     float64 // |LeftChannelRMS - RightChannelRMS|
 	TotalRMS        float64 // Combined RMS level
-	Balance         float64 // L/R balance (-1.0 to 1.0)
+	Balance         float64 // L/R balance back-computed from measured L/R (-1.0 to 1.0)
+
+	// MonoCompatibility is the Pearson correlation coefficient between the
+	// measured L and R channel samples: near 1.0 means L and R are
+	// in-phase (safe to sum to mono), near -1.0 means they're
+	// out-of-phase (will cancel on a mono downmix). Replaces an earlier
+	// "signal present" boolean that never actually measured mono
+	// compatibility.
+	MonoCompatibility float64
+
+	// Loudness is the stereo output tap's ITU-R BS.1770 / EBU R128 reading -
+	// see MeasureLoudness.
+	Loudness LoudnessResult
+
+	// PowerConservation is the analytical RMS-sum ratio between a
+	// hard-panned (pan +-1.0) and a centered (pan 0.0) position under the
+	// law this analysis was run with - sqrt(gL^2+gR^2) at center divided by
+	// the same at hard pan. A true constant-power law (PanLawEqualPower and
+	// its -4.5dB/-6dB siblings) holds this at 1.0, so perceived loudness
+	// doesn't move as a source is panned; PanLawLinear drops well below 1.0,
+	// which is the "hole in the middle" a linear pan law is known for.
+	PowerConservation float64
+}
+
+// PanLaw identifies which center-attenuation curve a mono→stereo pan should
+// follow. The named laws converge to the same hard-left/hard-right gains;
+// they only differ in how much a centered source is attenuated, trading off
+// against the "bump" a linear law leaves when two correlated speakers sum
+// back together. See PanLawGains.
+type PanLaw int
+
+const (
+	// PanLawEqualPower is AVAudioMixerNode's own pan curve (constant power,
+	// -3dB at center) - the law every MonoToStereoChannel used before
+	// PanLaw existed, so it's the zero value.
+	PanLawEqualPower PanLaw = iota
+	// PanLawLinear ramps gain directly with pan position (-6dB at center).
+	PanLawLinear
+	// PanLawMinus3dB is an alias for PanLawEqualPower, named for its center
+	// attenuation rather than its shape.
+	PanLawMinus3dB
+	// PanLawMinus4_5dB is the midpoint compromise between the linear and
+	// equal-power curves, as used by consoles that split the difference.
+	PanLawMinus4_5dB
+	// PanLawMinus6dB is an alias for PanLawLinear, named for its center
+	// attenuation rather than its shape.
+	PanLawMinus6dB
+)
+
+// String returns the law's name, e.g. "EqualPower".
+func (l PanLaw) String() string {
+	switch l {
+	case PanLawEqualPower:
+		return "EqualPower"
+	case PanLawLinear:
+		return "Linear"
+	case PanLawMinus3dB:
+		return "Minus3dB"
+	case PanLawMinus4_5dB:
+		return "Minus4_5dB"
+	case PanLawMinus6dB:
+		return "Minus6dB"
+	default:
+		return fmt.Sprintf("PanLaw(%d)", int(l))
+	}
+}
+
+// panLawBlend returns how far toward the equal-power curve (1.0) vs. the
+// linear curve (0.0) a law sits, so PanLawGains can compute every law from
+// one blended formula instead of five separate ones.
+func panLawBlend(law PanLaw) float64 {
+	switch law {
+	case PanLawLinear, PanLawMinus6dB:
+		return 0.0
+	case PanLawMinus4_5dB:
+		return 0.5
+	case PanLawEqualPower, PanLawMinus3dB:
+		return 1.0
+	default:
+		return 1.0
+	}
+}
+
+// PanLawGains returns the left/right gains a mono source panned to pan
+// (-1.0 full left to +1.0 full right) should have under law. Equal-power
+// (and its -3dB alias) reduces to the classic L=cos(theta), R=sin(theta)
+// formula; linear (and its -6dB alias) ramps gain directly with pan; -4.5dB
+// blends the two. All five converge to the same hard-left/hard-right gains.
+func PanLawGains(pan float32, law PanLaw) (left, right float64) {
+	t := (float64(pan) + 1.0) / 2.0 // normalize to [0, 1]
+
+	linearLeft, linearRight := 1.0-t, t
+	theta := t * math.Pi / 2.0
+	equalPowerLeft, equalPowerRight := math.Cos(theta), math.Sin(theta)
+
+	blend := panLawBlend(law)
+	left = linearLeft + blend*(equalPowerLeft-linearLeft)
+	right = linearRight + blend*(equalPowerRight-linearRight)
+	return left, right
+}
+
+// powerConservation returns law's analytical center-vs-hard-pan RMS-sum
+// ratio - see StereoAnalysis.PowerConservation. It's a fixed property of the
+// law alone (not of any particular measurement), so it's computed directly
+// from PanLawGains rather than from a tap.
+func powerConservation(law PanLaw) float64 {
+	centerLeft, centerRight := PanLawGains(0.0, law)
+	hardLeft, hardRight := PanLawGains(1.0, law)
+	hardPower := math.Sqrt(hardLeft*hardLeft + hardRight*hardRight)
+	if hardPower == 0 {
+		return 0
+	}
+	centerPower := math.Sqrt(centerLeft*centerLeft + centerRight*centerRight)
+	return centerPower / hardPower
 }
 
 // ChainAnalysis contains results of plugin chain analysis
@@ -42,6 +158,16 @@ type ChainAnalysis struct {
 	FramesIn      int     // Frames at input
 	FramesOut     int     // Frames at output
 	LatencyFrames int     // Processing latency in frames
+
+	// Spectral is the chain's per-band frequency response, computed from
+	// the same input/output buffers as the rest of this analysis - see
+	// SpectralAnalysis. Zero value if either buffer was too short to form
+	// even one Welch segment.
+	Spectral SpectralAnalysis
+
+	// Loudness is the chain output tap's ITU-R BS.1770 / EBU R128 reading -
+	// see MeasureLoudness.
+	Loudness LoudnessResult
 }
 
 // SendAnalysis contains results of bus send analysis
@@ -60,16 +186,32 @@ type AnalysisConfig struct {
 	MaxLatency     time.Duration // Maximum acceptable latency
 	ToleranceDB    float64       // Tolerance for level comparisons (dB)
 	PanTolerance   float32       // Tolerance for pan position
+
+	// CorrelationThreshold is the minimum normalized cross-correlation
+	// (0.0-1.0) between input and output buffers for VerifySignalPath to
+	// call PathAnalysis.SignalIntegrity true. See measureLatencyAndIntegrity.
+	CorrelationThreshold float64
+
+	// StreamMode controls how the Stream* functions (stream.go) deliver a
+	// result once a consumer falls behind. Ignored by the one-shot
+	// Verify/Analyze functions. Zero value is StreamLatestOnly.
+	StreamMode StreamMode
+
+	// StreamBufferDepth is the Stream* functions' result channel capacity
+	// under StreamBuffered mode; zero defaults to defaultStreamBufferDepth.
+	// Ignored under StreamLatestOnly, whose channel is always depth 1.
+	StreamBufferDepth int
 }
 
 // DefaultAnalysisConfig returns sensible defaults for audio analysis
 func DefaultAnalysisConfig() AnalysisConfig {
 	return AnalysisConfig{
-		SampleDuration: 100 * time.Millisecond,
-		MinSignalLevel: 0.001, // -60dB
-		MaxLatency:     10 * time.Millisecond,
-		ToleranceDB:    1.0, // 1dB tolerance
-		PanTolerance:   0.1, // 10% pan tolerance
+		SampleDuration:       100 * time.Millisecond,
+		MinSignalLevel:       0.001, // -60dB
+		MaxLatency:           10 * time.Millisecond,
+		ToleranceDB:          1.0, // 1dB tolerance
+		PanTolerance:         0.1, // 10% pan tolerance
+		CorrelationThreshold: 0.7,
 	}
 }
 
@@ -106,13 +248,18 @@ func VerifySignalPath(enginePtr, inputNode, outputNode unsafe.Pointer, config An
 		return nil, fmt.Errorf("failed to get output metrics: %w", err)
 	}
 
-	// Analyze the results
+	return buildPathAnalysis(inputMetrics, outputMetrics, outputTap, config), nil
+}
+
+// buildPathAnalysis is VerifySignalPath's and StreamSignalPath's (stream.go)
+// shared core: given one pair of already-fetched input/output metrics, it
+// computes the PathAnalysis for that sample.
+func buildPathAnalysis(inputMetrics, outputMetrics *tap.TapMetrics, outputTap *tap.Tap, config AnalysisConfig) *PathAnalysis {
 	analysis := &PathAnalysis{
-		InputDetected:   inputMetrics.RMS >= config.MinSignalLevel,
-		OutputDetected:  outputMetrics.RMS >= config.MinSignalLevel,
-		InputRMS:        inputMetrics.RMS,
-		OutputRMS:       outputMetrics.RMS,
-		SignalIntegrity: true, // Simplified - would need correlation analysis for real integrity check
+		InputDetected:  inputMetrics.RMS >= config.MinSignalLevel,
+		OutputDetected: outputMetrics.RMS >= config.MinSignalLevel,
+		InputRMS:       inputMetrics.RMS,
+		OutputRMS:      outputMetrics.RMS,
 	}
 
 	// Calculate gain change
@@ -120,17 +267,47 @@ func VerifySignalPath(enginePtr, inputNode, outputNode unsafe.Pointer, config An
 		analysis.GainChange = 20 * math.Log10(outputMetrics.RMS/inputMetrics.RMS)
 	}
 
-	// Estimate latency (simplified - would need time correlation for accurate measurement)
 	if analysis.InputDetected && analysis.OutputDetected {
-		analysis.Latency = 5 * time.Millisecond // Placeholder
+		analysis.Latency, analysis.SignalIntegrity = measureLatencyAndIntegrity(inputMetrics, outputMetrics, config)
+		analysis.Loudness = measureTapLoudness(outputTap, outputMetrics)
 	}
 
-	return analysis, nil
+	return analysis
+}
+
+// measureLatencyAndIntegrity cross-correlates the raw buffers GetMetrics
+// captured from the input and output taps to find the actual propagation
+// delay, instead of assuming a fixed round-trip time. r(τ) = Σ x[n]·y[n+τ] /
+// sqrt(Σx²·Σy²) is evaluated for every lag τ in [0, MaxLatency·sampleRate]
+// samples (the output can only lag the input, never lead it); the argmax is
+// the latency and the peak value is compared against CorrelationThreshold
+// for SignalIntegrity. Computed via FFT (see crossCorrelate) rather than the
+// direct O(n·maxLag) sum, since MaxLatency windows put maxLag in the
+// thousands of samples at audio sample rates.
+func measureLatencyAndIntegrity(inputMetrics, outputMetrics *tap.TapMetrics, config AnalysisConfig) (time.Duration, bool) {
+	sampleRate := inputMetrics.SampleRate
+	if sampleRate <= 0 || len(inputMetrics.Samples) == 0 || len(outputMetrics.Samples) == 0 {
+		return 0, false
+	}
+
+	maxLag := int(config.MaxLatency.Seconds() * sampleRate)
+	lag, peak := crossCorrelate(toFloat64(inputMetrics.Samples), toFloat64(outputMetrics.Samples), maxLag)
+
+	latency := time.Duration(float64(lag) / sampleRate * float64(time.Second))
+	return latency, peak > config.CorrelationThreshold
+}
+
+func toFloat64(samples []float32) []float64 {
+	out := make([]float64, len(samples))
+	for i, s := range samples {
+		out[i] = float64(s)
+	}
+	return out
 }
 
 // AnalyzeMonoToStereo analyzes mono→stereo conversion with panning
 // This installs taps on the provided input and output nodes and measures actual levels
-func AnalyzeMonoToStereo(enginePtr, monoInput, stereoOutput unsafe.Pointer, expectedPan float32, config AnalysisConfig) (*StereoAnalysis, error) {
+func AnalyzeMonoToStereo(enginePtr, monoInput, stereoOutput unsafe.Pointer, expectedPan float32, law PanLaw, config AnalysisConfig) (*StereoAnalysis, error) {
 	if enginePtr == nil || monoInput == nil || stereoOutput == nil {
 		return nil, fmt.Errorf("invalid parameters: engine, mono input, and stereo output cannot be nil")
 	}
@@ -152,9 +329,10 @@ func AnalyzeMonoToStereo(enginePtr, monoInput, stereoOutput unsafe.Pointer, expe
 	// Sample for the configured duration
 	time.Sleep(config.SampleDuration)
 
-	// Get metrics from taps
-	monoMetrics, err := monoTap.GetMetrics()
-	if err != nil {
+	// Fetch (and discard) mono metrics just to confirm the mono tap itself
+	// is alive - the measured stereo output is what actually drives this
+	// analysis now, not a pan-law simulation of the mono input.
+	if _, err := monoTap.GetMetrics(); err != nil {
 		return nil, fmt.Errorf("failed to get mono metrics: %w", err)
 	}
 
@@ -163,52 +341,46 @@ func AnalyzeMonoToStereo(enginePtr, monoInput, stereoOutput unsafe.Pointer, expe
 		return nil, fmt.Errorf("failed to get stereo metrics: %w", err)
 	}
 
-	// Calculate expected L/R levels based on constant power pan law
-	// This simulates what the AVAudioMixerNode should be doing internally
-	var leftRMS, rightRMS float64
-
-	if monoMetrics.RMS > config.MinSignalLevel {
-		// Use constant power pan law: L = cos(θ), R = sin(θ)
-		// Map pan (-1 to +1) to angle (0 to π/2)
-		theta := (float64(expectedPan) + 1.0) * math.Pi / 4.0
-		leftGain := math.Cos(theta)
-		rightGain := math.Sin(theta)
-
-		leftRMS = monoMetrics.RMS * leftGain
-		rightRMS = monoMetrics.RMS * rightGain
-	} else {
-		// No input signal - output should be silent
-		leftRMS = 0.0
-		rightRMS = 0.0
-	}
-
-	// The actual measured stereo output reflects the mixed signal with pan applied
-	totalRMS := stereoMetrics.RMS
+	return buildStereoAnalysis(stereoTap, stereoMetrics, expectedPan, law, config)
+}
 
-	// Verify signal integrity: if input > threshold, output should be > threshold
-	signalIntegrity := true
-	if monoMetrics.RMS > config.MinSignalLevel {
-		signalIntegrity = stereoMetrics.RMS > config.MinSignalLevel
-	} else {
-		signalIntegrity = stereoMetrics.RMS <= config.MinSignalLevel
+// buildStereoAnalysis is AnalyzeMonoToStereo's and StreamMonoToStereo's
+// (stream.go) shared core: given one already-fetched stereoMetrics sample,
+// it deinterleaves the stereo tap's matching raw buffer and computes the
+// StereoAnalysis for it.
+func buildStereoAnalysis(stereoTap *tap.Tap, stereoMetrics *tap.TapMetrics, expectedPan float32, law PanLaw, config AnalysisConfig) (*StereoAnalysis, error) {
+	channels, err := stereoTap.PerChannelMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get per-channel stereo metrics: %w", err)
 	}
-
-	// Calculate balance from expected L/R distribution
-	var balance float64
-	if leftRMS > 0 || rightRMS > 0 {
-		balance = (rightRMS - leftRMS) / (rightRMS + leftRMS)
-	} else {
-		balance = float64(expectedPan) // Use expected when no signal
+	if len(channels) < 2 {
+		return nil, fmt.Errorf("stereo output tap reports %d channel(s), need at least 2", len(channels))
 	}
+	left, right := channels[0], channels[1]
 
 	analysis := &StereoAnalysis{
-		LeftChannelRMS:  leftRMS,                      // Expected left level from pan law
-		RightChannelRMS: rightRMS,                     // Expected right level from pan law
-		PanPosition:     expectedPan,                  // Pan setting being tested
-		TotalRMS:        totalRMS,                     // Actual measured mixed output
-		StereoWidth:     math.Abs(leftRMS - rightRMS), // Expected L/R difference
-		MonoCompatible:  signalIntegrity,              // Signal processing integrity
-		Balance:         balance,                      // Calculated balance
+		LeftChannelRMS:    left.RMS,
+		RightChannelRMS:   right.RMS,
+		TotalRMS:          stereoMetrics.RMS,
+		StereoWidth:       math.Abs(left.RMS - right.RMS),
+		Loudness:          measureTapLoudness(stereoTap, stereoMetrics),
+		MonoCompatibility: pearsonCorrelation(toFloat64(left.Samples), toFloat64(right.Samples)),
+		PowerConservation: powerConservation(law),
+	}
+
+	if left.RMS > 0 || right.RMS > 0 {
+		// Back-compute the actual pan position from measured L/R via the
+		// inverse of the equal-power pan law (L=cos θ, R=sin θ), rather than
+		// trusting expectedPan - this is what catches a mixer that silently
+		// applies the wrong pan or the wrong law.
+		theta := math.Atan2(right.RMS, left.RMS)
+		analysis.PanPosition = float32(4*theta/math.Pi - 1)
+		analysis.Balance = (right.RMS - left.RMS) / (right.RMS + left.RMS)
+	} else {
+		// No measured signal - nothing to back-compute from, so report the
+		// pan that was asked for instead of a meaningless atan2(0,0).
+		analysis.PanPosition = expectedPan
+		analysis.Balance = float64(expectedPan)
 	}
 
 	return analysis, nil
@@ -247,13 +419,21 @@ func AnalyzePluginChain(enginePtr, chainInput, chainOutput unsafe.Pointer, confi
 		return nil, fmt.Errorf("failed to get output metrics: %w", err)
 	}
 
-	// Analyze the chain processing
+	return buildChainAnalysis(inputMetrics, outputMetrics, outputTap), nil
+}
+
+// buildChainAnalysis is AnalyzePluginChain's and StreamPluginChain's
+// (stream.go) shared core: given one already-fetched pair of input/output
+// metrics, it computes the ChainAnalysis for that sample.
+func buildChainAnalysis(inputMetrics, outputMetrics *tap.TapMetrics, outputTap *tap.Tap) *ChainAnalysis {
 	analysis := &ChainAnalysis{
 		InputRMS:     inputMetrics.RMS,
 		OutputRMS:    outputMetrics.RMS,
 		IsProcessing: outputMetrics.FrameCount > 0,
 		FramesIn:     inputMetrics.FrameCount,
 		FramesOut:    outputMetrics.FrameCount,
+		Spectral:     computeSpectralAnalysis(inputMetrics, outputMetrics),
+		Loudness:     measureTapLoudness(outputTap, outputMetrics),
 	}
 
 	// Calculate gain change
@@ -267,7 +447,7 @@ func AnalyzePluginChain(enginePtr, chainInput, chainOutput unsafe.Pointer, confi
 		analysis.LatencyFrames = 0
 	}
 
-	return analysis, nil
+	return analysis
 }
 
 // AnalyzeBusSends analyzes bus send routing and levels
@@ -314,7 +494,13 @@ func AnalyzeBusSends(enginePtr, channelOutput unsafe.Pointer, busInputs []unsafe
 		return nil, fmt.Errorf("failed to get channel metrics: %w", err)
 	}
 
-	// Get bus metrics
+	return buildSendAnalysis(channelMetrics, busTaps, expectedSendLevels)
+}
+
+// buildSendAnalysis is AnalyzeBusSends's and StreamBusSends's (stream.go)
+// shared core: given one already-fetched channelMetrics sample, it fetches
+// this sample's metrics from every bus tap and computes the SendAnalysis.
+func buildSendAnalysis(channelMetrics *tap.TapMetrics, busTaps []*tap.Tap, expectedSendLevels []float32) (*SendAnalysis, error) {
 	sendLevels := make(map[int]float64)
 	sendRatios := make(map[int]float32)
 	sendEfficiency := make(map[int]float64)
@@ -376,14 +562,20 @@ func ValidatePathAnalysis(analysis *PathAnalysis, expectSignal bool, config Anal
 }
 
 // ValidateStereoAnalysis checks if stereo analysis meets pan expectations
-func ValidateStereoAnalysis(analysis *StereoAnalysis, expectedPan float32, config AnalysisConfig) error {
+// under law - the same pan law the channel under test was configured with,
+// so the L/R ratio check below matches its actual curve rather than always
+// assuming equal-power.
+func ValidateStereoAnalysis(analysis *StereoAnalysis, expectedPan float32, law PanLaw, config AnalysisConfig) error {
 	// Check if we have actual audio signal
 	hasAudio := analysis.TotalRMS > config.MinSignalLevel
 
 	if hasAudio {
-		// With real audio - validate signal processing integrity
-		if !analysis.MonoCompatible {
-			return fmt.Errorf("signal processing failed - no output for audio input")
+		// With real audio - flag severe phase cancellation, which would
+		// make the rest of this analysis (computed from per-channel RMS)
+		// unreliable on a mono downmix even if the checks below pass.
+		if analysis.MonoCompatibility < -0.8 {
+			return fmt.Errorf("L/R channels are out of phase (correlation %.2f) - will cancel on mono downmix",
+				analysis.MonoCompatibility)
 		}
 
 		// Validate pan position matches expectation
@@ -417,6 +609,28 @@ func ValidateStereoAnalysis(analysis *StereoAnalysis, expectedPan float32, confi
 			}
 		}
 
+		// Validate the L/R ratio against law's own curve, not just the
+		// coarse dominance checks above - this is what actually lets the
+		// same real-audio test cover every law instead of only the shape
+		// equal-power happens to share with the rest.
+		expectedLeft, expectedRight := PanLawGains(expectedPan, law)
+		if leftRMS > 0 && rightRMS > 0 && expectedLeft > 0 && expectedRight > 0 {
+			expectedRatio := expectedLeft / expectedRight
+			actualRatio := leftRMS / rightRMS
+			if relDiff := math.Abs(actualRatio-expectedRatio) / expectedRatio; relDiff > 0.5 {
+				return fmt.Errorf("L/R ratio mismatch for %s law at pan %.2f: expected ratio %.2f, got %.2f (L:%.6f R:%.6f)",
+					law, expectedPan, expectedRatio, actualRatio, leftRMS, rightRMS)
+			}
+		}
+
+		// Constant-power laws should hold PowerConservation at ~1.0 - if a
+		// law this close to equal-power leaks perceived loudness between
+		// center and hard pan, something's applying the wrong curve.
+		if conservationDiff := math.Abs(analysis.PowerConservation - 1.0); conservationDiff > 0.05 && panLawBlend(law) > 0.9 {
+			return fmt.Errorf("%s law should conserve power across pan, got PowerConservation %.3f",
+				law, analysis.PowerConservation)
+		}
+
 		// Validate that output level is reasonable compared to expected combined level
 		expectedTotal := math.Sqrt(leftRMS*leftRMS + rightRMS*rightRMS)
 		if expectedTotal > 0 {
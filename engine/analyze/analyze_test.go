@@ -110,7 +110,7 @@ func TestAnalyzeMonoToStereo(t *testing.T) {
 			config := DefaultAnalysisConfig()
 			config.SampleDuration = 50 * time.Millisecond // Shorter for multiple tests
 
-			analysis, err := AnalyzeMonoToStereo(eng.Ptr(), monoInput, stereoOutput, expectedPan, config)
+			analysis, err := AnalyzeMonoToStereo(eng.Ptr(), monoInput, stereoOutput, expectedPan, PanLawEqualPower, config)
 			if err != nil {
 				t.Fatalf("Failed to analyze mono→stereo with pan %.1f: %v", expectedPan, err)
 			}
@@ -122,10 +122,10 @@ func TestAnalyzeMonoToStereo(t *testing.T) {
 			t.Logf("  - Total RMS: %.6f", analysis.TotalRMS)
 			t.Logf("  - Balance: %.2f", analysis.Balance)
 			t.Logf("  - Stereo width: %.6f", analysis.StereoWidth)
-			t.Logf("  - Mono compatible: %v", analysis.MonoCompatible)
+			t.Logf("  - Mono compatibility: %.2f", analysis.MonoCompatibility)
 
 			// Validate stereo analysis
-			err = ValidateStereoAnalysis(analysis, expectedPan, config)
+			err = ValidateStereoAnalysis(analysis, expectedPan, PanLawEqualPower, config)
 			if err != nil {
 				t.Errorf("Stereo analysis validation failed for pan %.1f: %v", expectedPan, err)
 			} else {
@@ -311,7 +311,7 @@ func TestAnalyzeErrorHandling(t *testing.T) {
 	}
 
 	// Test with nil mono input (realistic error scenario)
-	_, err = AnalyzeMonoToStereo(nil, nil, nil, 0.0, config)
+	_, err = AnalyzeMonoToStereo(nil, nil, nil, 0.0, PanLawEqualPower, config)
 	if err == nil {
 		t.Error("Expected error with nil parameters")
 	} else {
@@ -0,0 +1,158 @@
+package analyze
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// crossCorrelate returns the lag (in samples) and peak normalized
+// cross-correlation value between x and y for every lag τ in [0, maxLag],
+// where τ is how many samples y is delayed relative to x:
+//
+//	r(τ) = Σ x[n]·y[n+τ] / sqrt(Σx²·Σy²)
+//
+// It's computed via FFT (X·conj(Y) → IFFT) rather than the direct
+// O(len(x)·maxLag) sum - see measureLatencyAndIntegrity in analyze.go, the
+// only caller.
+func crossCorrelate(x, y []float64, maxLag int) (lag int, peak float64) {
+	if len(x) == 0 || len(y) == 0 || maxLag < 0 {
+		return 0, 0
+	}
+
+	var energyX, energyY float64
+	for _, v := range x {
+		energyX += v * v
+	}
+	for _, v := range y {
+		energyY += v * v
+	}
+	denom := math.Sqrt(energyX * energyY)
+	if denom == 0 {
+		return 0, 0
+	}
+
+	// Correlating via FFT multiplies in the frequency domain, which is
+	// circular convolution - padding to at least len(x)+len(y)-1 keeps y's
+	// tail from wrapping around and aliasing onto x's head.
+	n := nextPowerOfTwo(len(x) + len(y))
+	xf := make([]complex128, n)
+	yf := make([]complex128, n)
+	for i, v := range x {
+		xf[i] = complex(v, 0)
+	}
+	for i, v := range y {
+		yf[i] = complex(v, 0)
+	}
+
+	X := fft(xf)
+	Y := fft(yf)
+	cross := make([]complex128, n)
+	for i := range cross {
+		cross[i] = X[i] * cmplx.Conj(Y[i])
+	}
+	corr := ifft(cross)
+
+	if maxLag >= n {
+		maxLag = n - 1
+	}
+	peak = math.Inf(-1)
+	for tau := 0; tau <= maxLag; tau++ {
+		r := real(corr[tau]) / denom
+		if r > peak {
+			peak = r
+			lag = tau
+		}
+	}
+	return lag, peak
+}
+
+// pearsonCorrelation returns the Pearson correlation coefficient between x
+// and y (equal length, non-empty) - AnalyzeMonoToStereo's MonoCompatibility,
+// measuring the actual phase relationship between two channels recorded at
+// the same time. This is deliberately not crossCorrelate: that's for
+// finding the lag between two different signals via an unnormalized-by-mean
+// r(τ), not for comparing two already-aligned channels at lag zero.
+func pearsonCorrelation(x, y []float64) float64 {
+	n := len(x)
+	if n == 0 || len(y) != n {
+		return 0
+	}
+
+	var meanX, meanY float64
+	for i := 0; i < n; i++ {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var num, sumXSq, sumYSq float64
+	for i := 0; i < n; i++ {
+		dx := x[i] - meanX
+		dy := y[i] - meanY
+		num += dx * dy
+		sumXSq += dx * dx
+		sumYSq += dy * dy
+	}
+
+	denom := math.Sqrt(sumXSq * sumYSq)
+	if denom == 0 {
+		return 0
+	}
+	return num / denom
+}
+
+// nextPowerOfTwo returns the smallest power of two >= n, the FFT size
+// crossCorrelate zero-pads x and y up to.
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// fft computes the discrete Fourier transform of x (len(x) must be a power
+// of two) via the standard radix-2 Cooley-Tukey recursion. Pure Go, no cgo
+// dependency - mirrors avaudio/tap's own private fft (spectrum.go); neither
+// package exports it, so each keeps a copy rather than adding a shared
+// dependency for one small recursive function.
+func fft(x []complex128) []complex128 {
+	n := len(x)
+	if n <= 1 {
+		return x
+	}
+
+	even := make([]complex128, n/2)
+	odd := make([]complex128, n/2)
+	for i := 0; i < n/2; i++ {
+		even[i] = x[2*i]
+		odd[i] = x[2*i+1]
+	}
+	even = fft(even)
+	odd = fft(odd)
+
+	result := make([]complex128, n)
+	for k := 0; k < n/2; k++ {
+		twiddle := cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n)) * odd[k]
+		result[k] = even[k] + twiddle
+		result[k+n/2] = even[k] - twiddle
+	}
+	return result
+}
+
+// ifft computes the inverse FFT via the conjugate trick -
+// ifft(X) = conj(fft(conj(X))) / N - instead of a second recursive
+// implementation.
+func ifft(x []complex128) []complex128 {
+	n := len(x)
+	conjIn := make([]complex128, n)
+	for i, v := range x {
+		conjIn[i] = cmplx.Conj(v)
+	}
+	out := fft(conjIn)
+	for i, v := range out {
+		out[i] = cmplx.Conj(v) / complex(float64(n), 0)
+	}
+	return out
+}
@@ -0,0 +1,374 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// IRConfig configures MeasureImpulseResponse's exponential sine sweep
+// (ESS) - see Farina, "Simultaneous Measurement of Impulse Response and
+// Distortion with a Swept-Sine Technique" (2000).
+type IRConfig struct {
+	StartFreq  float64       // f1, sweep start frequency (Hz)
+	EndFreq    float64       // f2, sweep end frequency (Hz), must exceed StartFreq
+	Duration   time.Duration // T, sweep duration
+	SampleRate float64       // playback/capture sample rate
+
+	// TailLength is how much extra silence to capture after the sweep
+	// ends, to catch the measured system's decay tail (reverb, plugin
+	// release, feedback) instead of truncating it.
+	TailLength time.Duration
+
+	// NumHarmonics is how many harmonic distortion orders (2nd, 3rd, ...)
+	// IRResult.HarmonicIRs separates out of the deconvolved pre-echoes.
+	// Zero skips harmonic separation entirely.
+	NumHarmonics int
+}
+
+// DefaultIRConfig returns a 20Hz-20kHz, 3 second sweep with a 2 second
+// decay tail and the first three harmonic orders separated - a reasonable
+// starting point for characterizing a full AVAudioEngine graph.
+func DefaultIRConfig() IRConfig {
+	return IRConfig{
+		StartFreq:    20,
+		EndFreq:      20000,
+		Duration:     3 * time.Second,
+		SampleRate:   48000,
+		TailLength:   2 * time.Second,
+		NumHarmonics: 3,
+	}
+}
+
+// IRResult is the outcome of a swept-sine impulse response measurement.
+type IRResult struct {
+	// IR is the full deconvolved signal: the harmonic distortion orders'
+	// pre-echoes followed by the linear impulse response - see LinearIR
+	// and HarmonicIRs for the two separated out of it.
+	IR []float64
+
+	// LinearIR is IR's linear (fundamental) impulse response, with index 0
+	// at the system's t=0 - the graph's actual impulse response, usable
+	// directly for convolution reverb or frequency-response analysis.
+	LinearIR []float64
+
+	// HarmonicIRs holds one isolated impulse response per harmonic
+	// distortion order, HarmonicIRs[0] for the 2nd harmonic, [1] for the
+	// 3rd, and so on, up to IRConfig.NumHarmonics orders. A nil entry
+	// means that order's pre-echo fell before the start of the capture
+	// (SampleDuration was too short for the measured system's latency).
+	HarmonicIRs [][]float64
+
+	SampleRate float64
+
+	// T60 is the time for the linear IR's energy to decay 60dB, extrapolated
+	// via Schroeder backward integration from the -5dB to -25dB region of
+	// the decay curve (see schroederT60) - the standard approach for
+	// measuring a decay that would otherwise run well below the capture's
+	// noise floor before reaching -60dB on its own.
+	T60 time.Duration
+
+	// Latency is the linear IR's peak position, i.e. the total propagation
+	// delay through the measured graph from inputInject to outputNode.
+	Latency time.Duration
+}
+
+// MeasureImpulseResponse characterizes the AVAudioEngine graph between
+// inputInject and outputNode: it generates an exponential sine sweep per
+// cfg, injects it at inputInject (see tap.InjectBuffer), captures the
+// response at outputNode via a tap, and recovers the impulse response by
+// deconvolving the capture with the sweep's time-reversed, amplitude-
+// compensated inverse filter. One measurement yields the graph's total
+// latency (IRResult.Latency), decay time (IRResult.T60), and - since a
+// log sweep's harmonic distortion products arrive as discrete pre-echoes
+// at known offsets ahead of the linear response - its harmonic distortion
+// IRs, all in a single pass instead of separate latency/THD/RT60 tests.
+func MeasureImpulseResponse(enginePtr, inputInject, outputNode unsafe.Pointer, cfg IRConfig) (*IRResult, error) {
+	if enginePtr == nil || inputInject == nil || outputNode == nil {
+		return nil, fmt.Errorf("invalid parameters: engine, input inject, and output node cannot be nil")
+	}
+	if cfg.SampleRate <= 0 {
+		return nil, fmt.Errorf("sample rate must be positive")
+	}
+	if cfg.StartFreq <= 0 || cfg.EndFreq <= cfg.StartFreq {
+		return nil, fmt.Errorf("end frequency (%.1f) must exceed start frequency (%.1f), both positive", cfg.EndFreq, cfg.StartFreq)
+	}
+	if cfg.Duration <= 0 {
+		return nil, fmt.Errorf("sweep duration must be positive")
+	}
+
+	sweep := generateSweep(cfg)
+	invFilter := generateInverseFilter(sweep, cfg)
+
+	outputTap, err := tap.InstallTap(enginePtr, outputNode, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install output tap: %w", err)
+	}
+	defer outputTap.Remove()
+
+	if err := tap.InjectBuffer(enginePtr, inputInject, sweep, 1, cfg.SampleRate); err != nil {
+		return nil, fmt.Errorf("failed to inject sweep: %w", err)
+	}
+
+	captureDuration := cfg.Duration + cfg.TailLength
+	time.Sleep(captureDuration)
+
+	captureSamples := int(captureDuration.Seconds() * cfg.SampleRate)
+	recorded, err := outputTap.GetSamples(captureSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture response: %w", err)
+	}
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no samples captured at output")
+	}
+
+	return computeImpulseResponse(recorded, invFilter, cfg), nil
+}
+
+// generateSweep renders cfg's exponential sine sweep:
+//
+//	x(t) = sin( (ω1·T / ln(ω2/ω1)) · (exp(t/T · ln(ω2/ω1)) − 1) )
+//
+// for t in [0, T), ω1 = 2π·StartFreq, ω2 = 2π·EndFreq. The 2π factors in
+// ω1 and ω2 cancel in the ln(ω2/ω1) ratio, so the frequency ratio alone
+// (StartFreq/EndFreq) would do, but the phase term still needs ω1 itself.
+func generateSweep(cfg IRConfig) []float32 {
+	n := int(cfg.Duration.Seconds() * cfg.SampleRate)
+	sweep := make([]float32, n)
+
+	T := cfg.Duration.Seconds()
+	omega1 := 2 * math.Pi * cfg.StartFreq
+	r := math.Log(cfg.EndFreq / cfg.StartFreq)
+	k := omega1 * T / r
+
+	for i := range sweep {
+		t := float64(i) / cfg.SampleRate
+		phase := k * (math.Exp(t/T*r) - 1)
+		sweep[i] = float32(math.Sin(phase))
+	}
+	return sweep
+}
+
+// generateInverseFilter builds the Farina inverse filter for sweep: the
+// time-reversed sweep multiplied by an exponentially decreasing envelope,
+// exp(-t·ln(ω2/ω1)/T), that compensates for the ESS's energy being
+// concentrated at low frequencies (it spends longer there), so the
+// inverse filter's own magnitude spectrum is flat and convolving it with
+// the sweep's recorded response recovers the system's impulse response
+// directly instead of the sweep's.
+func generateInverseFilter(sweep []float32, cfg IRConfig) []float64 {
+	n := len(sweep)
+	T := cfg.Duration.Seconds()
+	r := math.Log(cfg.EndFreq / cfg.StartFreq)
+
+	inv := make([]float64, n)
+	for i := range inv {
+		reversed := float64(sweep[n-1-i])
+		t := float64(i) / cfg.SampleRate
+		envelope := math.Exp(-t / T * r)
+		inv[i] = reversed * envelope
+	}
+	return inv
+}
+
+// computeImpulseResponse deconvolves recorded with invFilter (via FFT
+// convolution, see convolve) and separates the result into its linear and
+// harmonic-distortion impulse responses.
+func computeImpulseResponse(recorded []float32, invFilter []float64, cfg IRConfig) *IRResult {
+	r := math.Log(cfg.EndFreq / cfg.StartFreq)
+	fullConv := convolve(toFloat64(recorded), invFilter)
+
+	// The inverse filter is the same length as the sweep, so the linear
+	// (zero-order) impulse response begins at sample len(invFilter)-1 of
+	// the convolution - everything before that is a harmonic pre-echo.
+	linearStart := len(invFilter) - 1
+	if linearStart >= len(fullConv) {
+		linearStart = len(fullConv) - 1
+	}
+	linearIR := append([]float64(nil), fullConv[linearStart:]...)
+
+	result := &IRResult{
+		IR:          fullConv,
+		LinearIR:    linearIR,
+		HarmonicIRs: separateHarmonics(fullConv, linearStart, cfg, r),
+		SampleRate:  cfg.SampleRate,
+		T60:         schroederT60(linearIR, cfg.SampleRate),
+	}
+
+	if peakIdx := argmaxAbs(linearIR); peakIdx >= 0 {
+		result.Latency = time.Duration(float64(peakIdx) / cfg.SampleRate * float64(time.Second))
+	}
+
+	return result
+}
+
+// convolve returns the linear convolution of x and y, computed via FFT
+// (X·Y → IFFT, zero-padded to at least len(x)+len(y)-1 to avoid circular
+// wraparound) rather than the direct O(len(x)·len(y)) sum - see fft/ifft
+// in correlation.go, this package's only other user of them.
+func convolve(x, y []float64) []float64 {
+	n := nextPowerOfTwo(len(x) + len(y))
+	xf := make([]complex128, n)
+	yf := make([]complex128, n)
+	for i, v := range x {
+		xf[i] = complex(v, 0)
+	}
+	for i, v := range y {
+		yf[i] = complex(v, 0)
+	}
+
+	X := fft(xf)
+	Y := fft(yf)
+	prod := make([]complex128, n)
+	for i := range prod {
+		prod[i] = X[i] * Y[i]
+	}
+	result := ifft(prod)
+
+	out := make([]float64, len(x)+len(y)-1)
+	for i := range out {
+		out[i] = real(result[i])
+	}
+	return out
+}
+
+// separateHarmonics isolates cfg.NumHarmonics harmonic distortion orders'
+// pre-echoes out of fullConv, the nth harmonic's impulse arriving
+// Δt_n = T·ln(n)/ln(ω2/ω1) before linearStart (see Farina). Each isolated
+// window spans roughly the midpoint to its neighboring harmonics' pre-echoes
+// (or to linearStart, for the 2nd harmonic, the one closest to it), since
+// consecutive harmonics' pre-echoes are the natural boundary between them
+// in an unwindowed deconvolution.
+func separateHarmonics(fullConv []float64, linearStart int, cfg IRConfig, r float64) [][]float64 {
+	if cfg.NumHarmonics <= 0 {
+		return nil
+	}
+	T := cfg.Duration.Seconds()
+
+	locs := make([]int, cfg.NumHarmonics)
+	for i := range locs {
+		order := i + 2 // harmonic orders start at the 2nd
+		deltaT := T * math.Log(float64(order)) / r
+		locs[i] = linearStart - int(math.Round(deltaT*cfg.SampleRate))
+	}
+
+	harmonics := make([][]float64, cfg.NumHarmonics)
+	for i, loc := range locs {
+		if loc < 0 {
+			continue // this order's pre-echo falls before the capture started
+		}
+
+		hi := linearStart
+		if i > 0 {
+			hi = locs[i-1]
+		}
+		lo := 0
+		if i+1 < len(locs) && locs[i+1] >= 0 {
+			lo = locs[i+1]
+		}
+
+		start := (lo + loc) / 2
+		end := (loc + hi) / 2
+		if start < 0 {
+			start = 0
+		}
+		if end > len(fullConv) {
+			end = len(fullConv)
+		}
+		if end <= start {
+			continue
+		}
+
+		window := make([]float64, end-start)
+		copy(window, fullConv[start:end])
+		harmonics[i] = window
+	}
+	return harmonics
+}
+
+// schroederT60 estimates RT60 from ir via Schroeder backward integration:
+// the decay curve is the backwards-cumulative energy of ir (Schroeder,
+// "New Method of Measuring Reverberation Time", 1965), converted to dB
+// relative to its starting energy. A line is fit through the curve's
+// -5dB to -25dB span (avoiding both the direct sound's initial transient
+// and the noise floor a real capture eventually decays into) and
+// extrapolated to -60dB, rather than waiting for the raw decay to actually
+// reach -60dB, which a finite, noisy capture often never cleanly does.
+func schroederT60(ir []float64, sampleRate float64) time.Duration {
+	n := len(ir)
+	if n == 0 || sampleRate <= 0 {
+		return 0
+	}
+
+	cum := make([]float64, n)
+	var sum float64
+	for i := n - 1; i >= 0; i-- {
+		sum += ir[i] * ir[i]
+		cum[i] = sum
+	}
+	if cum[0] <= 0 {
+		return 0
+	}
+
+	db := make([]float64, n)
+	for i, c := range cum {
+		if c <= 0 {
+			db[i] = math.Inf(-1)
+		} else {
+			db[i] = 10 * math.Log10(c/cum[0])
+		}
+	}
+
+	startIdx, endIdx := -1, -1
+	for i, v := range db {
+		if startIdx < 0 && v <= -5 {
+			startIdx = i
+		}
+		if v <= -25 {
+			endIdx = i
+			break
+		}
+	}
+	if startIdx < 0 || endIdx < 0 || endIdx <= startIdx {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	count := float64(endIdx - startIdx + 1)
+	for i := startIdx; i <= endIdx; i++ {
+		x := float64(i) / sampleRate
+		y := db[i]
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denom := count*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	slope := (count*sumXY - sumX*sumY) / denom // dB per second, negative for a decaying IR
+	if slope >= 0 {
+		return 0
+	}
+
+	t60Seconds := -60.0 / slope
+	return time.Duration(t60Seconds * float64(time.Second))
+}
+
+// argmaxAbs returns the index of the largest-magnitude value in xs, or -1
+// if xs is empty.
+func argmaxAbs(xs []float64) int {
+	best := -1
+	bestVal := -1.0
+	for i, v := range xs {
+		if av := math.Abs(v); av > bestVal {
+			bestVal = av
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,42 @@
+package analyze
+
+import "github.com/shaban/macaudio/avaudio/tap"
+
+// LoudnessResult is a one-shot ITU-R BS.1770 / EBU R128 loudness reading
+// over a single captured tap buffer - see MeasureLoudness. It's a narrowed
+// view of tap.LoudnessMetrics: just the four readings broadcast/streaming
+// compliance testing checks against a target (Peak and LRA need a running
+// program to mean much, which a single Verify/Analyze buffer isn't).
+type LoudnessResult struct {
+	Integrated float64 // LUFS
+	ShortTerm  float64 // LUFS
+	Momentary  float64 // LUFS
+	TruePeak   float64 // dBTP
+}
+
+// measureTapLoudness is MeasureLoudness for a tap whose metrics have
+// already been fetched (see VerifySignalPath/AnalyzeMonoToStereo/
+// AnalyzePluginChain, its only callers) - it just adds the channel count
+// GetInfo carries but TapMetrics doesn't, falling back to mono if GetInfo
+// fails rather than erroring out of an otherwise-successful analysis.
+func measureTapLoudness(t *tap.Tap, metrics *tap.TapMetrics) LoudnessResult {
+	channels := 1
+	if info, err := t.GetInfo(); err == nil && info.ChannelCount > 0 {
+		channels = info.ChannelCount
+	}
+	return MeasureLoudness(metrics.Samples, metrics.SampleRate, channels)
+}
+
+// MeasureLoudness computes tapBuffer's loudness (as captured by
+// TapMetrics.Samples) via tap.MeasureLoudness's BS.1770 K-weighting and
+// gating, narrowed to the four fields PathAnalysis/StereoAnalysis/
+// ChainAnalysis expose as Loudness.
+func MeasureLoudness(tapBuffer []float32, sampleRate float64, channels int) LoudnessResult {
+	m := tap.MeasureLoudness(tapBuffer, sampleRate, channels)
+	return LoudnessResult{
+		Integrated: m.Integrated,
+		ShortTerm:  m.ShortTerm,
+		Momentary:  m.Momentary,
+		TruePeak:   m.TruePeak,
+	}
+}
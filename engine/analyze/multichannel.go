@@ -0,0 +1,294 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// Layout identifies a loudspeaker or channel-based layout a multichannel
+// channel can target; see channel/input.MonoToMultichannelChannel.
+type Layout int
+
+const (
+	LayoutQuad Layout = iota
+	Layout5_1
+	Layout7_1
+	LayoutAmbisonicsB1
+)
+
+// String returns the layout's name, e.g. "5.1".
+func (l Layout) String() string {
+	switch l {
+	case LayoutQuad:
+		return "Quad"
+	case Layout5_1:
+		return "5.1"
+	case Layout7_1:
+		return "7.1"
+	case LayoutAmbisonicsB1:
+		return "AmbisonicsB1"
+	default:
+		return fmt.Sprintf("Layout(%d)", int(l))
+	}
+}
+
+// speaker describes one loudspeaker's position in a planar layout. Azimuth
+// is in degrees, 0 = front center, positive = clockwise toward the right,
+// matching the convention VBAPGains' azimuth parameter uses.
+type speaker struct {
+	Label    string
+	Azimuth  float64
+	Pannable bool // false for LFE: a dedicated low-frequency feed, never panned to
+}
+
+// layoutSpeakers gives each planar Layout's channels in the same order
+// AVAudioChannelLayout would enumerate them, which is also the order
+// VBAPGains and AnalyzeMultichannel index their results by.
+var layoutSpeakers = map[Layout][]speaker{
+	LayoutQuad: {
+		{"FrontLeft", -45, true},
+		{"FrontRight", 45, true},
+		{"BackLeft", -135, true},
+		{"BackRight", 135, true},
+	},
+	Layout5_1: {
+		{"FrontLeft", -30, true},
+		{"FrontRight", 30, true},
+		{"FrontCenter", 0, true},
+		{"LFE", 0, false},
+		{"BackLeft", -110, true},
+		{"BackRight", 110, true},
+	},
+	Layout7_1: {
+		{"FrontLeft", -30, true},
+		{"FrontRight", 30, true},
+		{"FrontCenter", 0, true},
+		{"LFE", 0, false},
+		{"SideLeft", -90, true},
+		{"SideRight", 90, true},
+		{"BackLeft", -135, true},
+		{"BackRight", 135, true},
+	},
+}
+
+// ChannelLabels returns layout's channel labels in output-channel order.
+// Returns nil for LayoutAmbisonicsB1, which isn't a speaker layout - see
+// EncodeAmbisonicsB1.
+func (l Layout) ChannelLabels() []string {
+	speakers, ok := layoutSpeakers[l]
+	if !ok {
+		return nil
+	}
+	labels := make([]string, len(speakers))
+	for i, s := range speakers {
+		labels[i] = s.Label
+	}
+	return labels
+}
+
+// ChannelCount returns the number of output channels layout targets: 4 for
+// Quad, 6 for 5.1, 8 for 7.1, and 4 (W/X/Y/Z) for LayoutAmbisonicsB1.
+func (l Layout) ChannelCount() int {
+	if l == LayoutAmbisonicsB1 {
+		return 4
+	}
+	return len(layoutSpeakers[l])
+}
+
+// vbapDistanceAttenuation converts distance (1.0 = at the speaker radius)
+// into an amplitude multiplier: farther than the speaker radius attenuates
+// as 1/distance, nearer is left at unity rather than boosted, since VBAP
+// itself has no notion of "inside the speaker ring".
+func vbapDistanceAttenuation(distance float32) float64 {
+	d := float64(distance)
+	if d <= 1 {
+		return 1
+	}
+	return 1 / d
+}
+
+// VBAPGains computes per-speaker amplitude gains for a source at azimuth
+// degrees (0 = front, positive = clockwise toward the right) and distance
+// (1.0 = at the speaker radius; farther attenuates, see
+// vbapDistanceAttenuation) using vector-base amplitude panning across the
+// pair of speakers in layout whose azimuths bracket azimuth. LFE (5.1/7.1)
+// is always 0 - it's a dedicated low-frequency feed, not part of the pan
+// field. Returns one gain per entry of layout.ChannelLabels(), in that
+// order.
+func VBAPGains(layout Layout, azimuth float32, distance float32) ([]float64, error) {
+	speakers, ok := layoutSpeakers[layout]
+	if !ok {
+		return nil, fmt.Errorf("%v is not a speaker layout VBAP can pan across", layout)
+	}
+
+	type pannableSpeaker struct {
+		index int
+		az    float64 // normalized to [0, 360)
+	}
+	var pannable []pannableSpeaker
+	for i, s := range speakers {
+		if !s.Pannable {
+			continue
+		}
+		az := math.Mod(s.Azimuth, 360)
+		if az < 0 {
+			az += 360
+		}
+		pannable = append(pannable, pannableSpeaker{index: i, az: az})
+	}
+	if len(pannable) < 2 {
+		return nil, fmt.Errorf("layout %v has fewer than 2 pannable speakers", layout)
+	}
+	sort.Slice(pannable, func(a, b int) bool { return pannable[a].az < pannable[b].az })
+
+	azNorm := math.Mod(float64(azimuth), 360)
+	if azNorm < 0 {
+		azNorm += 360
+	}
+
+	// Find the bracketing pair (a, b), walking the sorted ring and
+	// wrapping the last segment back past 360.
+	n := len(pannable)
+	i1, i2 := n-1, 0
+	for i := 0; i < n; i++ {
+		a := pannable[i].az
+		b := pannable[(i+1)%n].az
+		if b <= a {
+			b += 360
+		}
+		target := azNorm
+		if target < a {
+			target += 360
+		}
+		if target >= a && target <= b {
+			i1, i2 = i, (i+1)%n
+			break
+		}
+	}
+
+	// Solve p = g1*v1 + g2*v2 for the unit vectors of the bracketing pair,
+	// then normalize to unit power (standard 2-speaker VBAP).
+	azRad := float64(azimuth) * math.Pi / 180
+	x, y := math.Sin(azRad), math.Cos(azRad)
+
+	th1 := pannable[i1].az * math.Pi / 180
+	th2 := pannable[i2].az * math.Pi / 180
+	det := math.Sin(th1-th2) // sin(θ1)cos(θ2) - sin(θ2)cos(θ1)
+
+	gains := make([]float64, len(speakers))
+	if math.Abs(det) < 1e-9 {
+		// Degenerate (coincident speakers) - dump all power on the first.
+		gains[pannable[i1].index] = 1
+	} else {
+		g1 := (x*math.Cos(th2) - y*math.Sin(th2)) / det
+		g2 := (y*math.Sin(th1) - x*math.Cos(th1)) / det
+		if g1 < 0 {
+			g1 = 0
+		}
+		if g2 < 0 {
+			g2 = 0
+		}
+		norm := math.Sqrt(g1*g1 + g2*g2)
+		if norm > 0 {
+			g1, g2 = g1/norm, g2/norm
+		}
+		atten := vbapDistanceAttenuation(distance)
+		gains[pannable[i1].index] = g1 * atten
+		gains[pannable[i2].index] = g2 * atten
+	}
+
+	return gains, nil
+}
+
+// AmbisonicsB1 holds first-order B-format encode coefficients for a single
+// point source, in the FuMa W/X/Y/Z channel order LayoutAmbisonicsB1 uses.
+type AmbisonicsB1 struct {
+	W, X, Y, Z float64
+}
+
+// EncodeAmbisonicsB1 encodes a source at azimuth/elevation degrees (0
+// azimuth = front, positive = clockwise toward the right; 0 elevation =
+// horizon, positive = up) into first-order B-format using the standard
+// FuMa coefficients: W = 1/√2, X = cos(az)cos(el), Y = sin(az)cos(el),
+// Z = sin(el).
+func EncodeAmbisonicsB1(azimuth, elevation float32) AmbisonicsB1 {
+	az := float64(azimuth) * math.Pi / 180
+	el := float64(elevation) * math.Pi / 180
+	return AmbisonicsB1{
+		W: 1 / math.Sqrt2,
+		X: math.Cos(az) * math.Cos(el),
+		Y: math.Sin(az) * math.Cos(el),
+		Z: math.Sin(el),
+	}
+}
+
+// MultichannelAnalysis reports expected and measured levels for a source
+// panned across a multichannel layout, keyed by channel label (see
+// Layout.ChannelLabels).
+type MultichannelAnalysis struct {
+	ExpectedRMS map[string]float64 // per-channel RMS expected from the gain table
+	TotalRMS    float64            // measured RMS at the multichannel output node
+	SourceRMS   float64            // measured RMS at the mono/stereo input node
+}
+
+// AnalyzeMultichannel analyzes a mono source panned across layout at
+// (azimuth, distance) via VBAPGains, the multichannel analog of
+// AnalyzeMonoToStereo: it installs taps on the source and the combined
+// multichannel output, measures the source's RMS, and reports each
+// channel's expected RMS under the VBAP gain table alongside the measured
+// total. LayoutAmbisonicsB1 isn't a VBAP layout (see EncodeAmbisonicsB1
+// instead) and is rejected here.
+func AnalyzeMultichannel(enginePtr, sourceNode, multichannelOutput unsafe.Pointer, layout Layout, azimuth, distance float32, config AnalysisConfig) (*MultichannelAnalysis, error) {
+	if enginePtr == nil || sourceNode == nil || multichannelOutput == nil {
+		return nil, fmt.Errorf("invalid parameters: engine, source, and multichannel output nodes cannot be nil")
+	}
+
+	gains, err := VBAPGains(layout, azimuth, distance)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceTap, err := tap.InstallTap(enginePtr, sourceNode, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install source tap: %w", err)
+	}
+	defer sourceTap.Remove()
+
+	outputTap, err := tap.InstallTap(enginePtr, multichannelOutput, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install multichannel output tap: %w", err)
+	}
+	defer outputTap.Remove()
+
+	time.Sleep(config.SampleDuration)
+
+	sourceMetrics, err := sourceTap.GetMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source metrics: %w", err)
+	}
+	outputMetrics, err := outputTap.GetMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get multichannel output metrics: %w", err)
+	}
+
+	labels := layout.ChannelLabels()
+	expected := make(map[string]float64, len(labels))
+	for i, label := range labels {
+		if sourceMetrics.RMS > config.MinSignalLevel {
+			expected[label] = sourceMetrics.RMS * gains[i]
+		} else {
+			expected[label] = 0
+		}
+	}
+
+	return &MultichannelAnalysis{
+		ExpectedRMS: expected,
+		TotalRMS:    outputMetrics.RMS,
+		SourceRMS:   sourceMetrics.RMS,
+	}, nil
+}
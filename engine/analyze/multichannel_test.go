@@ -0,0 +1,87 @@
+package analyze
+
+import "testing"
+
+func TestVBAPGainsFrontCenterQuad(t *testing.T) {
+	gains, err := VBAPGains(LayoutQuad, 0, 1.0)
+	if err != nil {
+		t.Fatalf("VBAPGains failed: %v", err)
+	}
+	labels := LayoutQuad.ChannelLabels()
+	byLabel := make(map[string]float64, len(labels))
+	for i, label := range labels {
+		byLabel[label] = gains[i]
+	}
+
+	if byLabel["FrontLeft"] <= 0 || byLabel["FrontRight"] <= 0 {
+		t.Fatalf("expected front-center azimuth to energize both front speakers, got %+v", byLabel)
+	}
+	if diff := byLabel["FrontLeft"] - byLabel["FrontRight"]; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected equal FrontLeft/FrontRight gain at dead center, got %.4f vs %.4f", byLabel["FrontLeft"], byLabel["FrontRight"])
+	}
+	if byLabel["BackLeft"] != 0 || byLabel["BackRight"] != 0 {
+		t.Errorf("expected rear speakers silent when panned to front, got %+v", byLabel)
+	}
+}
+
+func TestVBAPGainsExcludesLFE(t *testing.T) {
+	gains, err := VBAPGains(Layout5_1, 0, 1.0)
+	if err != nil {
+		t.Fatalf("VBAPGains failed: %v", err)
+	}
+	labels := Layout5_1.ChannelLabels()
+	for i, label := range labels {
+		if label == "LFE" && gains[i] != 0 {
+			t.Errorf("expected LFE to never receive panned signal, got gain %.4f", gains[i])
+		}
+	}
+}
+
+func TestVBAPGainsUnitPower(t *testing.T) {
+	for _, az := range []float32{-170, -60, -1, 0, 17, 89, 179} {
+		gains, err := VBAPGains(Layout7_1, az, 1.0)
+		if err != nil {
+			t.Fatalf("VBAPGains(%v) failed: %v", az, err)
+		}
+		var sumSq float64
+		for _, g := range gains {
+			sumSq += g * g
+		}
+		if sumSq < 0.999 || sumSq > 1.001 {
+			t.Errorf("azimuth %.0f: expected unit power (sum of squared gains ~= 1), got %.4f", az, sumSq)
+		}
+	}
+}
+
+func TestVBAPGainsRejectsAmbisonics(t *testing.T) {
+	if _, err := VBAPGains(LayoutAmbisonicsB1, 0, 1.0); err == nil {
+		t.Error("expected VBAPGains to reject LayoutAmbisonicsB1")
+	}
+}
+
+func TestEncodeAmbisonicsB1Front(t *testing.T) {
+	b := EncodeAmbisonicsB1(0, 0)
+	if b.X <= 0.99 {
+		t.Errorf("expected X ~= 1 for a front source, got %.4f", b.X)
+	}
+	if b.Y < -0.01 || b.Y > 0.01 {
+		t.Errorf("expected Y ~= 0 for a front source, got %.4f", b.Y)
+	}
+	if b.Z < -0.01 || b.Z > 0.01 {
+		t.Errorf("expected Z ~= 0 for a source on the horizon, got %.4f", b.Z)
+	}
+}
+
+func TestLayoutChannelCount(t *testing.T) {
+	cases := map[Layout]int{
+		LayoutQuad:         4,
+		Layout5_1:          6,
+		Layout7_1:          8,
+		LayoutAmbisonicsB1: 4,
+	}
+	for layout, want := range cases {
+		if got := layout.ChannelCount(); got != want {
+			t.Errorf("%v.ChannelCount() = %d, want %d", layout, got, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// SpectralAnalysis is a plugin chain's frequency response, derived from
+// Welch-averaged FFTs of the chain's input and output tap buffers - see
+// computeSpectralAnalysis, AnalyzePluginChain's only caller. All four slices
+// share the same length and the same per-bin frequency, i*BinHz.
+type SpectralAnalysis struct {
+	BinHz float64 // Frequency resolution - bin i represents i*BinHz
+
+	// MagnitudeIn and MagnitudeOut are the Welch-averaged magnitude
+	// spectrum of the input and output buffers respectively.
+	MagnitudeIn  []float64
+	MagnitudeOut []float64
+
+	// TransferFunction is 20*log10(MagnitudeOut[i]/MagnitudeIn[i]) per bin -
+	// how many dB the chain added or removed at that frequency. 0 wherever
+	// MagnitudeIn[i] is 0 (nothing to compare against).
+	TransferFunction []float64
+
+	// PhaseResponse is the output spectrum's phase minus the input
+	// spectrum's phase per bin, in radians, wrapped to (-pi, pi].
+	PhaseResponse []float64
+}
+
+// spectralFFTSize is the Welch segment length computeSpectralAnalysis uses -
+// small enough that a metricsSampleCapture-sized buffer (8192 samples, see
+// avaudio/tap) yields several overlapped segments to average, large enough
+// to resolve low-frequency EQ bands distinctly from their neighbors.
+const spectralFFTSize = 1024
+
+// binAt returns the frequency-domain bin index nearest hz for a spectrum
+// with the given bin resolution, clamped to the spectrum's valid range.
+func binAt(hz, binHz float64, bins int) int {
+	bin := int(math.Round(hz / binHz))
+	if bin < 0 {
+		bin = 0
+	}
+	if bin >= bins {
+		bin = bins - 1
+	}
+	return bin
+}
+
+// computeSpectralAnalysis runs Welch's method - Hann-windowed, 50%-overlapped
+// FFT segments, averaged - over inputMetrics.Samples and outputMetrics.Samples
+// independently, then derives TransferFunction/PhaseResponse from the two
+// averaged complex spectra. Averaging the complex spectra (rather than
+// averaging magnitude and phase separately) is what lets both fall out of
+// one Welch pass per signal instead of two. Returns the zero SpectralAnalysis
+// if either buffer is shorter than one FFT segment.
+func computeSpectralAnalysis(inputMetrics, outputMetrics *tap.TapMetrics) SpectralAnalysis {
+	sampleRate := inputMetrics.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = outputMetrics.SampleRate
+	}
+	if sampleRate <= 0 {
+		return SpectralAnalysis{}
+	}
+
+	inSpectrum := welchAverage(inputMetrics.Samples)
+	outSpectrum := welchAverage(outputMetrics.Samples)
+	if inSpectrum == nil || outSpectrum == nil {
+		return SpectralAnalysis{}
+	}
+
+	bins := len(inSpectrum)
+	binHz := sampleRate / float64(spectralFFTSize)
+
+	magIn := make([]float64, bins)
+	magOut := make([]float64, bins)
+	transfer := make([]float64, bins)
+	phase := make([]float64, bins)
+	for i := 0; i < bins; i++ {
+		magIn[i] = cmplx.Abs(inSpectrum[i])
+		magOut[i] = cmplx.Abs(outSpectrum[i])
+		if magIn[i] > 0 {
+			transfer[i] = 20 * math.Log10(magOut[i]/magIn[i])
+		}
+		phase[i] = wrapPhase(cmplx.Phase(outSpectrum[i]) - cmplx.Phase(inSpectrum[i]))
+	}
+
+	return SpectralAnalysis{
+		BinHz:            binHz,
+		MagnitudeIn:      magIn,
+		MagnitudeOut:     magOut,
+		TransferFunction: transfer,
+		PhaseResponse:    phase,
+	}
+}
+
+// welchAverage splits samples into spectralFFTSize-length, 50%-overlapped,
+// Hann-windowed segments, FFTs each, and returns the bin-wise average of the
+// complex results (bins 0 through Nyquist inclusive). nil if samples is
+// shorter than one full segment.
+func welchAverage(samples []float32) []complex128 {
+	n := spectralFFTSize
+	if len(samples) < n {
+		return nil
+	}
+
+	hop := n / 2
+	half := n/2 + 1
+	sum := make([]complex128, half)
+	segments := 0
+
+	for start := 0; start+n <= len(samples); start += hop {
+		windowed := make([]complex128, n)
+		for i := 0; i < n; i++ {
+			hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+			windowed[i] = complex(float64(samples[start+i])*hann, 0)
+		}
+		spectrum := fft(windowed)
+		for i := 0; i < half; i++ {
+			sum[i] += spectrum[i]
+		}
+		segments++
+	}
+
+	if segments == 0 {
+		return nil
+	}
+	avg := make([]complex128, half)
+	for i := range sum {
+		avg[i] = sum[i] / complex(float64(segments), 0)
+	}
+	return avg
+}
+
+// wrapPhase wraps a radian phase difference to (-pi, pi].
+func wrapPhase(p float64) float64 {
+	for p > math.Pi {
+		p -= 2 * math.Pi
+	}
+	for p <= -math.Pi {
+		p += 2 * math.Pi
+	}
+	return p
+}
+
+// ValidateLowPass checks that spectral shows a low-pass response: roughly
+// flat (within config.ToleranceDB of 0dB) an octave below cutoffHz, and
+// attenuated by approximately slopeDbPerOct per octave (within
+// config.ToleranceDB) going from cutoffHz to one octave above it.
+func ValidateLowPass(spectral SpectralAnalysis, cutoffHz, slopeDbPerOct float64, config AnalysisConfig) error {
+	bins := len(spectral.TransferFunction)
+	if bins == 0 {
+		return fmt.Errorf("spectral analysis has no data to validate a low-pass response against")
+	}
+
+	passbandBin := binAt(cutoffHz/2, spectral.BinHz, bins)
+	if passbandDB := spectral.TransferFunction[passbandBin]; math.Abs(passbandDB) > config.ToleranceDB {
+		return fmt.Errorf("expected flat passband near %.0fHz (half the %.0fHz cutoff), got %.2fdB",
+			cutoffHz/2, cutoffHz, passbandDB)
+	}
+
+	cutoffBin := binAt(cutoffHz, spectral.BinHz, bins)
+	octaveBin := binAt(cutoffHz*2, spectral.BinHz, bins)
+	actualSlope := spectral.TransferFunction[octaveBin] - spectral.TransferFunction[cutoffBin]
+	expectedSlope := -slopeDbPerOct
+	if diff := math.Abs(actualSlope - expectedSlope); diff > config.ToleranceDB {
+		return fmt.Errorf("expected ~%.1fdB/oct rolloff from %.0fHz to %.0fHz, measured %.2fdB/oct (diff %.2fdB)",
+			slopeDbPerOct, cutoffHz, cutoffHz*2, actualSlope, diff)
+	}
+
+	return nil
+}
+
+// ValidateNotch checks that spectral shows a notch at centerHz attenuated by
+// at least depthDb relative to the passband on either side of it.
+func ValidateNotch(spectral SpectralAnalysis, centerHz, depthDb float64, config AnalysisConfig) error {
+	bins := len(spectral.TransferFunction)
+	if bins == 0 {
+		return fmt.Errorf("spectral analysis has no data to validate a notch response against")
+	}
+
+	centerBin := binAt(centerHz, spectral.BinHz, bins)
+	belowBin := binAt(centerHz/2, spectral.BinHz, bins)
+	aboveBin := binAt(centerHz*2, spectral.BinHz, bins)
+
+	shoulder := (spectral.TransferFunction[belowBin] + spectral.TransferFunction[aboveBin]) / 2
+	depth := shoulder - spectral.TransferFunction[centerBin]
+	if depth < depthDb-config.ToleranceDB {
+		return fmt.Errorf("expected notch at %.0fHz at least %.1fdB deep relative to its shoulders, measured %.2fdB",
+			centerHz, depthDb, depth)
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// StreamMode controls what a Stream* function does when its result channel
+// is full and the consumer hasn't drained it yet. See AnalysisConfig.StreamMode.
+type StreamMode int
+
+const (
+	// StreamLatestOnly keeps at most one undelivered analysis: a new result
+	// overwrites rather than queues, so a consumer that falls behind always
+	// reads the most recent state instead of catching up on a backlog. This
+	// is the zero value, matching tap.SubscribeChannel's own drop-oldest
+	// default.
+	StreamLatestOnly StreamMode = iota
+	// StreamBuffered queues up to AnalysisConfig.StreamBufferDepth analyses
+	// and blocks the producer goroutine (delaying its next SampleDuration
+	// tick) rather than drop one, for a consumer that must see every
+	// sample.
+	StreamBuffered
+)
+
+// defaultStreamBufferDepth is AnalysisConfig.StreamBufferDepth's effective
+// value when left at zero.
+const defaultStreamBufferDepth = 8
+
+// streamChannelDepth is the buffer capacity a Stream* function allocates
+// its result channel with: under StreamLatestOnly the channel only ever
+// holds the one most recent result, so depth 1 is enough.
+func streamChannelDepth(config AnalysisConfig) int {
+	if config.StreamMode != StreamBuffered {
+		return 1
+	}
+	if config.StreamBufferDepth > 0 {
+		return config.StreamBufferDepth
+	}
+	return defaultStreamBufferDepth
+}
+
+// streamDeliver sends value on ch under config.StreamMode: StreamBuffered
+// blocks (or gives up if ctx is cancelled first) so nothing is ever
+// dropped, while StreamLatestOnly drops the oldest queued value to make
+// room rather than block - the same inline pattern tap.SubscribeChannel
+// uses for its TapBlock channel, rather than routing every Stream* function
+// through a shared generic publish/subscribe type for what's fundamentally
+// a single-producer, single-consumer feed.
+func streamDeliver[T any](ctx context.Context, ch chan T, value T, config AnalysisConfig) {
+	if config.StreamMode == StreamBuffered {
+		select {
+		case ch <- value:
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	select {
+	case ch <- value:
+		return
+	default:
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- value:
+	default:
+	}
+}
+
+// streamLoop is every Stream* function's producer goroutine body: it calls
+// sample at every config.SampleDuration tick until ctx is cancelled,
+// delivering each result via streamDeliver and skipping (not aborting the
+// stream on) a tick whose sample returns an error - a transient tap read
+// failure shouldn't kill a long-running monitor. cleanup runs once, after
+// the loop exits, to remove whatever taps sample closed over.
+func streamLoop[T any](ctx context.Context, config AnalysisConfig, ch chan T, cleanup func(), sample func() (T, error)) {
+	defer close(ch)
+	defer cleanup()
+
+	ticker := time.NewTicker(config.SampleDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			value, err := sample()
+			if err != nil {
+				continue
+			}
+			streamDeliver(ctx, ch, value, config)
+		}
+	}
+}
+
+// StreamSignalPath is VerifySignalPath, continuously: it installs taps once
+// and emits a fresh PathAnalysis on the returned channel at every
+// config.SampleDuration interval until ctx is cancelled, at which point the
+// taps are removed and the channel is closed.
+func StreamSignalPath(ctx context.Context, enginePtr, inputNode, outputNode unsafe.Pointer, config AnalysisConfig) (<-chan PathAnalysis, error) {
+	if enginePtr == nil || inputNode == nil || outputNode == nil {
+		return nil, fmt.Errorf("invalid parameters: engine, input, and output nodes cannot be nil")
+	}
+
+	inputTap, err := tap.InstallTap(enginePtr, inputNode, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install input tap: %w", err)
+	}
+	outputTap, err := tap.InstallTap(enginePtr, outputNode, 0)
+	if err != nil {
+		inputTap.Remove()
+		return nil, fmt.Errorf("failed to install output tap: %w", err)
+	}
+
+	ch := make(chan PathAnalysis, streamChannelDepth(config))
+	go streamLoop(ctx, config, ch, func() {
+		inputTap.Remove()
+		outputTap.Remove()
+	}, func() (PathAnalysis, error) {
+		inputMetrics, err := inputTap.GetMetrics()
+		if err != nil {
+			return PathAnalysis{}, err
+		}
+		outputMetrics, err := outputTap.GetMetrics()
+		if err != nil {
+			return PathAnalysis{}, err
+		}
+		return *buildPathAnalysis(inputMetrics, outputMetrics, outputTap, config), nil
+	})
+
+	return ch, nil
+}
+
+// StreamMonoToStereo is AnalyzeMonoToStereo, continuously - see
+// StreamSignalPath for the delivery/cancellation contract shared by every
+// Stream* function.
+func StreamMonoToStereo(ctx context.Context, enginePtr, monoInput, stereoOutput unsafe.Pointer, expectedPan float32, law PanLaw, config AnalysisConfig) (<-chan StereoAnalysis, error) {
+	if enginePtr == nil || monoInput == nil || stereoOutput == nil {
+		return nil, fmt.Errorf("invalid parameters: engine, mono input, and stereo output cannot be nil")
+	}
+
+	monoTap, err := tap.InstallTap(enginePtr, monoInput, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install mono input tap: %w", err)
+	}
+	stereoTap, err := tap.InstallTap(enginePtr, stereoOutput, 0)
+	if err != nil {
+		monoTap.Remove()
+		return nil, fmt.Errorf("failed to install stereo output tap: %w", err)
+	}
+
+	ch := make(chan StereoAnalysis, streamChannelDepth(config))
+	go streamLoop(ctx, config, ch, func() {
+		monoTap.Remove()
+		stereoTap.Remove()
+	}, func() (StereoAnalysis, error) {
+		if _, err := monoTap.GetMetrics(); err != nil {
+			return StereoAnalysis{}, err
+		}
+		stereoMetrics, err := stereoTap.GetMetrics()
+		if err != nil {
+			return StereoAnalysis{}, err
+		}
+		analysis, err := buildStereoAnalysis(stereoTap, stereoMetrics, expectedPan, law, config)
+		if err != nil {
+			return StereoAnalysis{}, err
+		}
+		return *analysis, nil
+	})
+
+	return ch, nil
+}
+
+// StreamPluginChain is AnalyzePluginChain, continuously - see
+// StreamSignalPath for the delivery/cancellation contract shared by every
+// Stream* function.
+func StreamPluginChain(ctx context.Context, enginePtr, chainInput, chainOutput unsafe.Pointer, config AnalysisConfig) (<-chan ChainAnalysis, error) {
+	if enginePtr == nil || chainInput == nil || chainOutput == nil {
+		return nil, fmt.Errorf("invalid parameters: engine, chain input, and output cannot be nil")
+	}
+
+	inputTap, err := tap.InstallTap(enginePtr, chainInput, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install chain input tap: %w", err)
+	}
+	outputTap, err := tap.InstallTap(enginePtr, chainOutput, 0)
+	if err != nil {
+		inputTap.Remove()
+		return nil, fmt.Errorf("failed to install chain output tap: %w", err)
+	}
+
+	ch := make(chan ChainAnalysis, streamChannelDepth(config))
+	go streamLoop(ctx, config, ch, func() {
+		inputTap.Remove()
+		outputTap.Remove()
+	}, func() (ChainAnalysis, error) {
+		inputMetrics, err := inputTap.GetMetrics()
+		if err != nil {
+			return ChainAnalysis{}, err
+		}
+		outputMetrics, err := outputTap.GetMetrics()
+		if err != nil {
+			return ChainAnalysis{}, err
+		}
+		return *buildChainAnalysis(inputMetrics, outputMetrics, outputTap), nil
+	})
+
+	return ch, nil
+}
+
+// StreamBusSends is AnalyzeBusSends, continuously - see StreamSignalPath
+// for the delivery/cancellation contract shared by every Stream* function.
+func StreamBusSends(ctx context.Context, enginePtr, channelOutput unsafe.Pointer, busInputs []unsafe.Pointer, expectedSendLevels []float32, config AnalysisConfig) (<-chan SendAnalysis, error) {
+	if enginePtr == nil || channelOutput == nil {
+		return nil, fmt.Errorf("invalid parameters: engine and channel output cannot be nil")
+	}
+	if len(busInputs) != len(expectedSendLevels) {
+		return nil, fmt.Errorf("bus inputs and send levels must have the same length")
+	}
+
+	channelTap, err := tap.InstallTap(enginePtr, channelOutput, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install channel output tap: %w", err)
+	}
+
+	var busTaps []*tap.Tap
+	for i, busInput := range busInputs {
+		busTap, err := tap.InstallTap(enginePtr, busInput, 0)
+		if err != nil {
+			channelTap.Remove()
+			for _, prevTap := range busTaps {
+				prevTap.Remove()
+			}
+			return nil, fmt.Errorf("failed to install bus %d input tap: %w", i, err)
+		}
+		busTaps = append(busTaps, busTap)
+	}
+
+	ch := make(chan SendAnalysis, streamChannelDepth(config))
+	go streamLoop(ctx, config, ch, func() {
+		channelTap.Remove()
+		for _, busTap := range busTaps {
+			busTap.Remove()
+		}
+	}, func() (SendAnalysis, error) {
+		channelMetrics, err := channelTap.GetMetrics()
+		if err != nil {
+			return SendAnalysis{}, err
+		}
+		analysis, err := buildSendAnalysis(channelMetrics, busTaps, expectedSendLevels)
+		if err != nil {
+			return SendAnalysis{}, err
+		}
+		return *analysis, nil
+	})
+
+	return ch, nil
+}
@@ -0,0 +1,346 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// RampCurve selects how consecutive AutomationPoints are interpolated.
+type RampCurve int
+
+const (
+	// RampLinear interpolates value linearly between two points.
+	RampLinear RampCurve = iota
+	// RampExponential interpolates value exponentially; both endpoints must
+	// be positive (automation segments crossing zero fall back to linear).
+	RampExponential
+	// RampHold steps directly to the next point's value at its AtSample,
+	// holding the previous value for the whole segment leading up to it.
+	RampHold
+)
+
+// AutomationPoint is one knot in a parameter automation lane.
+type AutomationPoint struct {
+	AtSample uint64
+	Value    float64
+	Curve    RampCurve // curve used for the segment leading up to this point
+}
+
+// automationStepSamples is the control-rate at which ramps between points
+// are resampled into ring entries. Finer than this buys no audible benefit
+// and only spends ring capacity.
+const automationStepSamples = 64
+
+// ringEntry is one scheduled parameter write, timestamped to a sample.
+type ringEntry struct {
+	ParamAddr uint64
+	Value     float64
+	AtSample  uint64
+}
+
+// automationRing is a single-producer/single-consumer, allocation-free ring
+// buffer of ringEntry. Go callers (the producer side) schedule writes;
+// the render callback (the consumer side) drains due entries. When the
+// producer outruns the consumer, Push coalesces by overwriting the most
+// recently queued entry for the same parameter rather than growing or
+// allocating.
+type automationRing struct {
+	entries []ringEntry
+	mask    uint64
+	head    atomic.Uint64 // next slot to consume
+	tail    atomic.Uint64 // next slot to produce
+}
+
+// newAutomationRing creates a ring with capacity rounded up to the next
+// power of two (minimum 64).
+func newAutomationRing(capacity int) *automationRing {
+	cap := 64
+	for cap < capacity {
+		cap <<= 1
+	}
+	return &automationRing{
+		entries: make([]ringEntry, cap),
+		mask:    uint64(cap - 1),
+	}
+}
+
+// push enqueues e. If the ring is full, it coalesces with the newest
+// pending entry for the same parameter if one exists; otherwise the write
+// is dropped (the oldest scheduled writes for other parameters are left
+// intact rather than being silently reordered).
+func (r *automationRing) push(e ringEntry) bool {
+	tail := r.tail.Load()
+	head := r.head.Load()
+	if tail-head == uint64(len(r.entries)) {
+		lastIdx := (tail - 1) & r.mask
+		if r.entries[lastIdx].ParamAddr == e.ParamAddr {
+			r.entries[lastIdx] = e
+			return true
+		}
+		return false
+	}
+	r.entries[tail&r.mask] = e
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// pop removes and returns the oldest entry, if any.
+func (r *automationRing) pop() (ringEntry, bool) {
+	head := r.head.Load()
+	tail := r.tail.Load()
+	if head == tail {
+		return ringEntry{}, false
+	}
+	e := r.entries[head&r.mask]
+	r.head.Store(head + 1)
+	return e, true
+}
+
+// peek returns the oldest entry without consuming it.
+func (r *automationRing) peek() (ringEntry, bool) {
+	head := r.head.Load()
+	tail := r.tail.Load()
+	if head == tail {
+		return ringEntry{}, false
+	}
+	return r.entries[head&r.mask], true
+}
+
+// automation lazily creates and returns this plugin's ring buffer.
+func (p *EnginePlugin) automation() *automationRing {
+	if p.automationRing == nil {
+		p.automationRing = newAutomationRing(1024)
+	}
+	return p.automationRing
+}
+
+// resolveParamAddress finds the address of the parameter identified by
+// paramID (matching identifier or display name, same as
+// PluginChain.SetPluginParameter).
+func (p *EnginePlugin) resolveParamAddress(paramID string) (uint64, error) {
+	if p.Plugin == nil {
+		return 0, fmt.Errorf("plugin not initialized")
+	}
+	for _, param := range p.Plugin.Parameters {
+		if param.Identifier == paramID || param.DisplayName == paramID {
+			return param.Address, nil
+		}
+	}
+	return 0, fmt.Errorf("parameter not found: %s", paramID)
+}
+
+// SetParameterAtTime schedules a single sample-accurate parameter write,
+// delivered to the render callback through the lock-free automation ring
+// rather than mutating param.CurrentValue directly from an arbitrary
+// goroutine.
+func (p *EnginePlugin) SetParameterAtTime(paramID string, value float64, atSample uint64) error {
+	addr, err := p.resolveParamAddress(paramID)
+	if err != nil {
+		return err
+	}
+	if !p.automation().push(ringEntry{ParamAddr: addr, Value: value, AtSample: atSample}) {
+		return fmt.Errorf("automation ring full for parameter %s", paramID)
+	}
+	return nil
+}
+
+// Automate schedules a parameter lane: points must be sorted by AtSample.
+// Each segment between consecutive points is resampled at
+// automationStepSamples intervals using the arriving point's Curve, so the
+// render callback only ever has to apply flat per-sample writes.
+func (p *EnginePlugin) Automate(paramID string, points []AutomationPoint) error {
+	addr, err := p.resolveParamAddress(paramID)
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	ring := p.automation()
+	push := func(sample uint64, value float64) error {
+		if !ring.push(ringEntry{ParamAddr: addr, Value: value, AtSample: sample}) {
+			return fmt.Errorf("automation ring full for parameter %s", paramID)
+		}
+		return nil
+	}
+
+	if err := push(points[0].AtSample, points[0].Value); err != nil {
+		return err
+	}
+
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1], points[i]
+		if cur.AtSample < prev.AtSample {
+			return fmt.Errorf("automation points must be sorted by AtSample (point %d precedes point %d)", i, i-1)
+		}
+		span := cur.AtSample - prev.AtSample
+		if span == 0 {
+			if err := push(cur.AtSample, cur.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		for s := uint64(automationStepSamples); s < span; s += automationStepSamples {
+			t := float64(s) / float64(span)
+			if err := push(prev.AtSample+s, rampValue(prev.Value, cur.Value, t, cur.Curve)); err != nil {
+				return err
+			}
+		}
+		if err := push(cur.AtSample, cur.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rampValue interpolates between from and to at position t in [0,1].
+// Exponential ramps fall back to linear when either endpoint is not
+// strictly positive, matching AudioUnitParameterRamp semantics.
+func rampValue(from, to, t float64, curve RampCurve) float64 {
+	switch {
+	case curve == RampHold:
+		if t >= 1 {
+			return to
+		}
+		return from
+	case curve == RampExponential && from > 0 && to > 0:
+		return from * math.Pow(to/from, t)
+	default:
+		return from + (to-from)*t
+	}
+}
+
+// Drain applies every scheduled entry whose AtSample has arrived
+// (AtSample <= currentSample), calling apply(paramAddr, value) for each in
+// order. It is meant to be called once per render callback from the audio
+// thread; it performs no allocation.
+func (p *EnginePlugin) Drain(currentSample uint64, apply func(paramAddr uint64, value float64) error) error {
+	if p.automationRing == nil {
+		return nil
+	}
+	for {
+		e, ok := p.automationRing.peek()
+		if !ok || e.AtSample > currentSample {
+			return nil
+		}
+		p.automationRing.pop()
+		if err := apply(e.ParamAddr, e.Value); err != nil {
+			return err
+		}
+	}
+}
+
+// AutomationMode controls how an AutomationLane's points are read or
+// written, mirroring the Off/Read/Touch/Write states a DAW mixer strip
+// cycles through per parameter.
+type AutomationMode int
+
+const (
+	// AutomationOff ignores the lane entirely: the parameter keeps whatever
+	// value it was last set to.
+	AutomationOff AutomationMode = iota
+	// AutomationRead plays the lane's points back, driving the parameter
+	// from TickAutomation.
+	AutomationRead
+	// AutomationTouch behaves like AutomationRead except while the control
+	// is actively held (see AutomationLane.BeginTouch), during which
+	// incoming writes are recorded instead.
+	AutomationTouch
+	// AutomationWrite records every incoming parameter change as a new
+	// point and ignores the existing lane during playback.
+	AutomationWrite
+)
+
+// AutomationLane is the persisted, time-indexed automation curve for one
+// parameter: the same AutomationPoint knots Automate resamples into the
+// render-callback ring, plus a Mode that says whether the lane is currently
+// driving the parameter, recording it, or neither.
+type AutomationLane struct {
+	Points []AutomationPoint `json:"points"`
+	Mode   AutomationMode    `json:"mode"`
+
+	// touching is true between BeginTouch and EndTouch; only meaningful in
+	// AutomationTouch mode. Not serialized: it is momentary UI state, not
+	// part of the saved automation curve.
+	touching bool
+}
+
+// ValueAt evaluates the lane at atSample, interpolating between the
+// surrounding points the same way Automate resamples a ramp. ok is false
+// for an empty lane, so callers fall back to the parameter's static value
+// rather than forcing it to zero.
+func (lane *AutomationLane) ValueAt(atSample uint64) (value float64, ok bool) {
+	if lane == nil || len(lane.Points) == 0 {
+		return 0, false
+	}
+	points := lane.Points
+	i := sort.Search(len(points), func(i int) bool { return points[i].AtSample > atSample })
+	switch {
+	case i == 0:
+		return points[0].Value, true
+	case i == len(points):
+		return points[len(points)-1].Value, true
+	}
+	prev, cur := points[i-1], points[i]
+	if prev.AtSample == cur.AtSample {
+		return cur.Value, true
+	}
+	t := float64(atSample-prev.AtSample) / float64(cur.AtSample-prev.AtSample)
+	return rampValue(prev.Value, cur.Value, t, cur.Curve), true
+}
+
+// BeginTouch marks the lane as actively held, so Record accepts writes
+// while in AutomationTouch mode until the matching EndTouch.
+func (lane *AutomationLane) BeginTouch() { lane.touching = true }
+
+// EndTouch clears the touched state set by BeginTouch.
+func (lane *AutomationLane) EndTouch() { lane.touching = false }
+
+// Record appends a point captured from a live parameter change at
+// atSample. It is a no-op unless the lane is in AutomationWrite mode, or in
+// AutomationTouch mode with BeginTouch currently held - matching how a DAW
+// only records automation onto a write-enabled or touched lane.
+func (lane *AutomationLane) Record(atSample uint64, value float64, curve RampCurve) {
+	if lane.Mode != AutomationWrite && !(lane.Mode == AutomationTouch && lane.touching) {
+		return
+	}
+	lane.Points = append(lane.Points, AutomationPoint{AtSample: atSample, Value: value, Curve: curve})
+}
+
+// Automation lazily creates and returns the plugin's automation lane for
+// paramID (matched the same way SetPluginParameter resolves a parameter),
+// so callers can inspect or mutate its Points/Mode without a nil check.
+func (p *EnginePlugin) Automation(paramID string) *AutomationLane {
+	if p.Lanes == nil {
+		p.Lanes = make(map[string]*AutomationLane)
+	}
+	lane, ok := p.Lanes[paramID]
+	if !ok {
+		lane = &AutomationLane{}
+		p.Lanes[paramID] = lane
+	}
+	return lane
+}
+
+// TickAutomation drives every AutomationRead (or currently-untouched
+// AutomationTouch) lane's value at currentSample into the parameter write
+// ring, the same path SetParameterAtTime uses, so the render callback picks
+// it up via Drain.
+func (p *EnginePlugin) TickAutomation(currentSample uint64) error {
+	for paramID, lane := range p.Lanes {
+		if lane.Mode != AutomationRead && !(lane.Mode == AutomationTouch && !lane.touching) {
+			continue
+		}
+		value, ok := lane.ValueAt(currentSample)
+		if !ok {
+			continue
+		}
+		if err := p.SetParameterAtTime(paramID, value, currentSample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func newAutomationTestPlugin() *EnginePlugin {
+	return &EnginePlugin{
+		IsInstalled: true,
+		Plugin: &plugins.Plugin{
+			Name: "Test Gain",
+			Parameters: []plugins.Parameter{
+				{Identifier: "gain", Address: 7, CurrentValue: 1},
+			},
+		},
+	}
+}
+
+func TestAutomationRingCoalescesWhenFull(t *testing.T) {
+	ring := newAutomationRing(1) // rounds up to 64
+	for i := uint64(0); i < 64; i++ {
+		if !ring.push(ringEntry{ParamAddr: 1, Value: float64(i), AtSample: i}) {
+			t.Fatalf("push %d should have succeeded", i)
+		}
+	}
+	// Ring is now full; pushing another update for the same param coalesces
+	// into the newest slot instead of failing.
+	if !ring.push(ringEntry{ParamAddr: 1, Value: 99, AtSample: 100}) {
+		t.Fatal("expected coalescing push to succeed")
+	}
+	// A different param with the ring full should be dropped.
+	if ring.push(ringEntry{ParamAddr: 2, Value: 1, AtSample: 101}) {
+		t.Fatal("expected push for a different param to be dropped when full")
+	}
+}
+
+func TestSetParameterAtTimeAndDrain(t *testing.T) {
+	p := newAutomationTestPlugin()
+	if err := p.SetParameterAtTime("gain", 0.5, 100); err != nil {
+		t.Fatalf("SetParameterAtTime failed: %v", err)
+	}
+	if err := p.SetParameterAtTime("gain", 0.75, 200); err != nil {
+		t.Fatalf("SetParameterAtTime failed: %v", err)
+	}
+
+	var applied []float64
+	err := p.Drain(150, func(addr uint64, value float64) error {
+		applied = append(applied, value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if len(applied) != 1 || applied[0] != 0.5 {
+		t.Fatalf("expected only the sample<=150 entry to drain, got %v", applied)
+	}
+
+	applied = nil
+	if err := p.Drain(200, func(addr uint64, value float64) error {
+		applied = append(applied, value)
+		return nil
+	}); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if len(applied) != 1 || applied[0] != 0.75 {
+		t.Fatalf("expected the sample=200 entry to drain, got %v", applied)
+	}
+}
+
+func TestAutomateResamplesRamp(t *testing.T) {
+	p := newAutomationTestPlugin()
+	err := p.Automate("gain", []AutomationPoint{
+		{AtSample: 0, Value: 0},
+		{AtSample: 128, Value: 1, Curve: RampLinear},
+	})
+	if err != nil {
+		t.Fatalf("Automate failed: %v", err)
+	}
+
+	var samples []uint64
+	err = p.Drain(1<<63, func(addr uint64, value float64) error {
+		if addr != 7 {
+			t.Fatalf("unexpected param address %d", addr)
+		}
+		samples = append(samples, uint64(value*1000))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if len(samples) < 2 {
+		t.Fatalf("expected ramp to resample into multiple entries, got %d", len(samples))
+	}
+}
+
+func TestAutomateRejectsUnsortedPoints(t *testing.T) {
+	p := newAutomationTestPlugin()
+	err := p.Automate("gain", []AutomationPoint{
+		{AtSample: 100, Value: 1},
+		{AtSample: 50, Value: 0},
+	})
+	if err == nil {
+		t.Fatal("expected error for out-of-order automation points")
+	}
+}
+
+func TestAutomationLaneValueAt(t *testing.T) {
+	lane := &AutomationLane{Points: []AutomationPoint{
+		{AtSample: 0, Value: 0},
+		{AtSample: 100, Value: 1, Curve: RampLinear},
+		{AtSample: 200, Value: 1, Curve: RampHold},
+		{AtSample: 300, Value: 0.25},
+	}}
+
+	if v, ok := lane.ValueAt(50); !ok || v != 0.5 {
+		t.Fatalf("expected linear midpoint 0.5, got %v (ok=%v)", v, ok)
+	}
+	if v, ok := lane.ValueAt(250); !ok || v != 1 {
+		t.Fatalf("expected held value 1 mid-segment, got %v (ok=%v)", v, ok)
+	}
+	if v, ok := lane.ValueAt(1000); !ok || v != 0.25 {
+		t.Fatalf("expected the last point's value past the end, got %v (ok=%v)", v, ok)
+	}
+
+	var empty *AutomationLane
+	if _, ok := empty.ValueAt(0); ok {
+		t.Fatal("expected a nil lane to report no value")
+	}
+}
+
+func TestAutomationLaneRecordRespectsMode(t *testing.T) {
+	lane := &AutomationLane{}
+	lane.Record(0, 0.5, RampLinear)
+	if len(lane.Points) != 0 {
+		t.Fatal("expected Record to be a no-op in AutomationOff mode")
+	}
+
+	lane.Mode = AutomationWrite
+	lane.Record(0, 0.5, RampLinear)
+	if len(lane.Points) != 1 {
+		t.Fatalf("expected Record to append in AutomationWrite mode, got %d points", len(lane.Points))
+	}
+
+	lane.Mode = AutomationTouch
+	lane.Record(100, 1, RampLinear)
+	if len(lane.Points) != 1 {
+		t.Fatal("expected Record to ignore an untouched Touch lane")
+	}
+	lane.BeginTouch()
+	lane.Record(100, 1, RampLinear)
+	lane.EndTouch()
+	if len(lane.Points) != 2 {
+		t.Fatalf("expected Record to append while touched, got %d points", len(lane.Points))
+	}
+}
+
+func TestEnginePluginTickAutomation(t *testing.T) {
+	p := newAutomationTestPlugin()
+	lane := p.Automation("gain")
+	lane.Mode = AutomationRead
+	lane.Points = []AutomationPoint{
+		{AtSample: 0, Value: 0.2},
+		{AtSample: 100, Value: 0.8, Curve: RampLinear},
+	}
+
+	if err := p.TickAutomation(50); err != nil {
+		t.Fatalf("TickAutomation failed: %v", err)
+	}
+
+	var applied []float64
+	if err := p.Drain(50, func(addr uint64, value float64) error {
+		applied = append(applied, value)
+		return nil
+	}); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if len(applied) != 1 || applied[0] != 0.5 {
+		t.Fatalf("expected the interpolated value 0.5 to drain, got %v", applied)
+	}
+}
+
+func TestPluginChainSetAutomationMode(t *testing.T) {
+	chain := NewPluginChain()
+	chain.Plugins = append(chain.Plugins, *newAutomationTestPlugin())
+	chain.Plugins[0].Automation("gain")
+
+	if err := chain.SetAutomationMode(0, AutomationWrite); err != nil {
+		t.Fatalf("SetAutomationMode failed: %v", err)
+	}
+	if chain.Plugins[0].Lanes["gain"].Mode != AutomationWrite {
+		t.Fatalf("expected gain lane to be in AutomationWrite mode, got %v", chain.Plugins[0].Lanes["gain"].Mode)
+	}
+
+	if err := chain.SetAutomationMode(5, AutomationRead); err == nil {
+		t.Fatal("expected an out-of-range plugin index to error")
+	}
+}
@@ -0,0 +1,311 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/devices"
+)
+
+// AudioSpec describes the sample rate and channel count
+// CreateCaptureChannel requests from a device's AVAudioInputNode.
+type AudioSpec struct {
+	SampleRate   float64
+	ChannelCount int
+}
+
+// AudioBuffer is one block of captured audio delivered to InstallTap's
+// callback, one slice per channel - the capture-side equivalent of
+// avaudio/engine's player InstallTap, which deinterleaves the same way.
+type AudioBuffer struct {
+	Channels [][]float32
+	Frames   int
+}
+
+// AudioTime is the position of an AudioBuffer delivered to InstallTap,
+// expressed as the running total of frames captured divided by the
+// channel's sample rate - not an absolute host time, matching
+// avaudio/engine's player InstallTap sampleTime.
+type AudioTime struct {
+	SampleTime float64
+}
+
+// captureRingMaxSamples bounds how many interleaved float32 samples Read's
+// ring holds before StartCapture's tap starts dropping the oldest ones -
+// enough headroom for a caller to fall a little behind without the ring
+// growing unbounded, matching the drop-oldest philosophy
+// avaudio/tap.CallbackTap already uses for its own delivery ring.
+const captureRingMaxSamples = 1 << 16
+
+// findInputDeviceByUID looks up deviceUID among the system's audio
+// devices, the same enumeration EnumerateDevices-style callers would use
+// to discover it in the first place.
+func findInputDeviceByUID(deviceUID string) (*devices.AudioDevice, error) {
+	list, err := devices.GetAudio()
+	if err != nil {
+		return nil, err
+	}
+	device := list.ByUID(deviceUID)
+	if device == nil {
+		return nil, errors.New("no audio device with UID " + deviceUID)
+	}
+	if device.InputChannelCount <= 0 {
+		return nil, errors.New("device " + deviceUID + " has no input channels")
+	}
+	return device, nil
+}
+
+// CreateCaptureChannel attaches a channel to deviceUID's AVAudioInputNode
+// (an aggregate device's UID works the same as a plain microphone's),
+// symmetric to CreatePlaybackChannel: it gets its own channel mixer wired
+// into the main mixer through AllocateBusForChannel, so Volume/Pan (and
+// the ValidateVolume/ValidatePan checks SetVolume/SetPan already run for
+// every channel) work the same as any other channel's monitor bus. Start
+// actual capture with StartCapture - CreateCaptureChannel only builds the
+// graph.
+func (e *Engine) CreateCaptureChannel(deviceUID string, spec AudioSpec) (*Channel, error) {
+	if e.nativeEngine == nil {
+		return nil, errors.New("engine is not properly initialized")
+	}
+	if deviceUID == "" {
+		return nil, errors.New("device UID cannot be empty")
+	}
+	if spec.ChannelCount <= 0 {
+		return nil, errors.New("channel count must be positive")
+	}
+
+	device, err := findInputDeviceByUID(deviceUID)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := &Channel{
+		Volume: 1.0,
+		Pan:    0.0,
+		InputOptions: &InputOptions{
+			Device:      device,
+			DeviceUID:   deviceUID,
+			Spec:        spec,
+			PluginChain: NewPluginChain(),
+		},
+		engine: e,
+	}
+	defaultChannelSolo.register(channel)
+
+	cDeviceUID := C.CString(deviceUID)
+	defer C.free(unsafe.Pointer(cDeviceUID))
+
+	inputNodeResult := C.audioengine_input_node(e.nativeEngine, cDeviceUID)
+	if inputNodeResult.error != nil {
+		return nil, errors.New("failed to get input node: " + C.GoString(inputNodeResult.error))
+	}
+	channel.InputOptions.inputNodePtr = inputNodeResult.result
+
+	channelMixerResult := C.audioengine_create_mixer_node(e.nativeEngine)
+	if channelMixerResult.error != nil {
+		return nil, errors.New("failed to create channel mixer: " + C.GoString(channelMixerResult.error))
+	}
+	channel.mixerNodePtr = channelMixerResult.result
+
+	errorStr := C.audioengine_attach(e.nativeEngine, channelMixerResult.result)
+	if errorStr != nil {
+		return nil, errors.New("failed to attach channel mixer to engine: " + C.GoString(errorStr))
+	}
+
+	errorStr = C.audioengine_connect(e.nativeEngine, inputNodeResult.result, channelMixerResult.result, 0, 0)
+	if errorStr != nil {
+		return nil, errors.New("failed to connect input node to channel mixer: " + C.GoString(errorStr))
+	}
+
+	mainMixerResult := C.audioengine_main_mixer_node(e.nativeEngine)
+	if mainMixerResult.error != nil {
+		return nil, errors.New("failed to get main mixer: " + C.GoString(mainMixerResult.error))
+	}
+
+	busIndex, err := e.AllocateBusForChannel(channel)
+	if err != nil {
+		return nil, errors.New("failed to allocate bus for channel: " + err.Error())
+	}
+
+	errorStr = C.audioengine_connect(e.nativeEngine, channelMixerResult.result, mainMixerResult.result, 0, C.int(busIndex))
+	if errorStr != nil {
+		e.FreeBusForChannel(channel)
+		return nil, errors.New("failed to connect channel mixer to main mixer: " + C.GoString(errorStr))
+	}
+
+	if err := e.runSync(context.Background(), func(ctx context.Context) error {
+		e.Channels = append(e.Channels, channel)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// StartCapture begins delivering deviceUID's input audio to this channel:
+// it installs a callback tap on the native input node (see
+// avaudio/tap.InstallCallbackTap) that feeds both Read's ring buffer and
+// whatever callback InstallTap registered. The channel must have been
+// created with CreateCaptureChannel.
+func (c *Channel) StartCapture() error {
+	if !c.IsInput() || c.InputOptions.inputNodePtr == nil {
+		return errors.New("channel is not a capture channel")
+	}
+	if c.InputOptions.capturing {
+		return errors.New("capture already started")
+	}
+
+	t, err := tap.InstallCallbackTap(unsafe.Pointer(c.engine.nativeEngine), c.InputOptions.inputNodePtr, 0, 2048, tap.TapFormatPlanarFloat32, c.deliverCapture)
+	if err != nil {
+		return err
+	}
+
+	c.InputOptions.captureTap = t
+	c.InputOptions.capturing = true
+	return nil
+}
+
+// StopCapture removes the tap StartCapture installed. It's a no-op if
+// capture was never started.
+func (c *Channel) StopCapture() error {
+	if !c.IsInput() {
+		return errors.New("channel is not a capture channel")
+	}
+	if !c.InputOptions.capturing {
+		return nil
+	}
+
+	err := c.InputOptions.captureTap.Remove()
+	c.InputOptions.captureTap = nil
+	c.InputOptions.capturing = false
+	return err
+}
+
+// InstallTap registers a push-style callback invoked with each block this
+// capture channel receives, delivered from the tap's drain goroutine -
+// never the audio thread itself, see avaudio/tap.InstallCallbackTap. Call
+// StartCapture to actually begin delivery; only one callback may be
+// registered at a time.
+func (c *Channel) InstallTap(cb func(buf AudioBuffer, when AudioTime)) error {
+	if !c.IsInput() || c.InputOptions.inputNodePtr == nil {
+		return errors.New("channel is not a capture channel")
+	}
+	if cb == nil {
+		return errors.New("callback cannot be nil")
+	}
+	if c.InputOptions.tapFn != nil {
+		return errors.New("a tap callback is already installed on this channel")
+	}
+
+	c.InputOptions.tapFn = cb
+	return nil
+}
+
+// Read copies up to len(buf) interleaved float32 samples captured since
+// the last Read into buf, pull-style, returning the number of samples
+// written. Captured audio accumulates in a bounded ring (see
+// captureRingMaxSamples) fed by StartCapture's tap; call StartCapture
+// first, or Read will only ever return 0.
+func (c *Channel) Read(buf []float32) (int, error) {
+	if !c.IsInput() || c.InputOptions.inputNodePtr == nil {
+		return 0, errors.New("channel is not a capture channel")
+	}
+
+	opts := c.InputOptions
+	opts.ringMu.Lock()
+	defer opts.ringMu.Unlock()
+
+	n := copy(buf, opts.ring)
+	opts.ring = opts.ring[n:]
+	return n, nil
+}
+
+// deliverCapture is StartCapture's tap callback: it splits buf's
+// channel-major Float32Data into Read's interleaved ring (dropping the
+// oldest samples past captureRingMaxSamples), feeds AttachEncoder's sink
+// if one is installed, and, if InstallTap registered one, calls it with
+// the same block deinterleaved into AudioBuffer.
+func (c *Channel) deliverCapture(buf tap.TapBuffer) {
+	opts := c.InputOptions
+	opts.framesCaptured += uint64(buf.Frames)
+
+	channels := splitPlanar(buf)
+	interleaved := interleave(channels, buf.Frames)
+
+	opts.ringMu.Lock()
+	opts.ring = append(opts.ring, interleaved...)
+	if over := len(opts.ring) - captureRingMaxSamples; over > 0 {
+		opts.ring = opts.ring[over:]
+	}
+	opts.ringMu.Unlock()
+
+	if opts.encoderSink != nil && opts.encoderErr == nil {
+		block := encoder.AudioBlock{
+			Samples:     interleaved,
+			SampleRate:  int(opts.Spec.SampleRate),
+			Channels:    buf.Channels,
+			Interleaved: true,
+		}
+		if err := opts.encoderSink.WriteBlock(block); err != nil {
+			opts.encoderErr = err
+		}
+	}
+
+	if opts.tapFn == nil {
+		return
+	}
+	sampleTime := 0.0
+	if opts.Spec.SampleRate > 0 {
+		sampleTime = float64(opts.framesCaptured) / opts.Spec.SampleRate
+	}
+	opts.tapFn(AudioBuffer{Channels: channels, Frames: buf.Frames}, AudioTime{SampleTime: sampleTime})
+}
+
+// splitPlanar splits buf.Float32Data - channel-major when Format is
+// TapFormatPlanarFloat32, i.e. all of channel 0's frames followed by all
+// of channel 1's - into one slice per channel, mirroring
+// avaudio/engine/player_tap.go's deinterleavePlanar.
+func splitPlanar(buf tap.TapBuffer) [][]float32 {
+	if buf.Channels == 0 || buf.Frames == 0 {
+		return nil
+	}
+	channels := make([][]float32, buf.Channels)
+	for ch := range channels {
+		start := ch * buf.Frames
+		end := start + buf.Frames
+		if start > len(buf.Float32Data) {
+			start = len(buf.Float32Data)
+		}
+		if end > len(buf.Float32Data) {
+			end = len(buf.Float32Data)
+		}
+		channels[ch] = buf.Float32Data[start:end]
+	}
+	return channels
+}
+
+// interleave weaves channels (one slice per channel, frames long each)
+// into a single interleaved slice for Read's ring.
+func interleave(channels [][]float32, frames int) []float32 {
+	if len(channels) == 0 || frames == 0 {
+		return nil
+	}
+	out := make([]float32, 0, frames*len(channels))
+	for frame := 0; frame < frames; frame++ {
+		for _, ch := range channels {
+			if frame < len(ch) {
+				out = append(out, ch[frame])
+			} else {
+				out = append(out, 0)
+			}
+		}
+	}
+	return out
+}
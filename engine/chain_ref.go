@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"fmt"
+)
+
+// ChainRef names a chain this Channel shares through the owning Engine's
+// ChainManager, plus the refID it registered under - see
+// Channel.AttachSharedChain.
+type ChainRef struct {
+	Name  string `json:"name"`
+	RefID string `json:"refID"`
+}
+
+// AttachSharedChain registers this channel as a user of chainName on the
+// owning Engine's ChainManager, storing the resulting ChainRef on
+// InputOptions so DestroyChannel can detach it automatically. Each channel
+// gets its own refID (derived from its own identity), so two channels
+// sharing one chain don't collide or accidentally release each other's
+// hold on it.
+func (c *Channel) AttachSharedChain(chainName string) error {
+	if c.InputOptions == nil {
+		return fmt.Errorf("channel is not an input channel")
+	}
+	if c.engine == nil || c.engine.ChainManager == nil {
+		return fmt.Errorf("channel's engine has no ChainManager")
+	}
+
+	refID := fmt.Sprintf("channel-%p", c)
+	if err := c.engine.ChainManager.AttachChain(chainName, refID); err != nil {
+		return err
+	}
+	c.InputOptions.ChainRef = &ChainRef{Name: chainName, RefID: refID}
+	return nil
+}
+
+// detachSharedChainLocked releases this channel's ChainRef, if any - called
+// by DestroyChannel, never by a caller directly.
+func (c *Channel) detachSharedChainLocked() {
+	if c.InputOptions == nil || c.InputOptions.ChainRef == nil {
+		return
+	}
+	if c.engine != nil && c.engine.ChainManager != nil {
+		ref := c.InputOptions.ChainRef
+		_ = c.engine.ChainManager.DetachChain(ref.Name, ref.RefID)
+	}
+	c.InputOptions.ChainRef = nil
+}
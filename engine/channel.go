@@ -8,10 +8,18 @@ package engine
 */
 import "C"
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
 	"unsafe"
 
+	"github.com/shaban/macaudio/avaudio/tap"
 	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/format/encoder"
+	"github.com/shaban/macaudio/session"
 )
 
 // =============================================================================
@@ -84,12 +92,54 @@ type Channel struct {
 	Volume float32 `json:"volume"`
 	Pan    float32 `json:"pan"`
 
+	// Lanes holds automation curves for this channel's own parameters
+	// ("volume", "pan"); see Automation. Plugin parameter automation lives
+	// on each EnginePlugin's own Lanes instead.
+	Lanes map[string]*AutomationLane `json:"lanes,omitempty"`
+
 	// Optional type-specific data (nil when not applicable)
 	PlaybackOptions *PlaybackOptions `json:"playbackOptions,omitempty"`
 	InputOptions    *InputOptions    `json:"inputOptions,omitempty"`
+	SamplerOptions  *SamplerOptions  `json:"samplerOptions,omitempty"`
 
 	// Internal mixing node for this channel (not serialized)
 	mixerNodePtr unsafe.Pointer `json:"-"`
+
+	// OnFadeDone, if set, is called on the fade's own goroutine when a
+	// FadeIn/FadeOut started with PlaybackOptions finishes naturally (not
+	// when a later fade supersedes it early).
+	OnFadeDone func(*Channel) `json:"-"`
+
+	// OnLoopCompleted, if set, is called each time LoopRange/LoopEnabled
+	// playback finishes one pass through the loop region and schedules
+	// the next segment. See SetLoopRegion/SetLoopCrossfade.
+	OnLoopCompleted func() `json:"-"`
+
+	// userMuted/soloMuted track mute state independently of Volume, so
+	// unmuting restores the prior level rather than whatever Volume was
+	// last written to while muted. See Mute/SetVolumeLinear.
+	userMuted bool
+	soloMuted bool
+
+	// engine is the owning Engine, set by Create*Channel, used to
+	// serialize mutating calls through Engine.runSync (see runOnEngine).
+	// Channels assembled by hand (e.g. in tests) leave this nil, and
+	// runOnEngine falls back to running directly.
+	engine *Engine `json:"-"`
+
+	// abSlots holds named ChannelSnapshots captured by StoreSlot, recalled
+	// by RecallSlot/Compare - see snapshot.go.
+	abSlots map[string]ChannelSnapshot `json:"-"`
+}
+
+// runOnEngine routes fn through the owning engine's control queue via
+// Engine.runSync, so it serializes with every other mutating call on that
+// engine. A channel with no owning engine (engine == nil) runs fn directly.
+func (c *Channel) runOnEngine(fn func(ctx context.Context) error) error {
+	if c.engine == nil {
+		return fn(context.Background())
+	}
+	return c.engine.runSync(context.Background(), fn)
 }
 
 // PlaybackOptions contains playback-specific configuration
@@ -98,8 +148,107 @@ type PlaybackOptions struct {
 	Rate     float32 `json:"rate"`  // 0.25x to 1.25x
 	Pitch    float32 `json:"pitch"` // ±12 semitones
 
+	// Metadata holds FilePath's tags and format info, as returned by
+	// session.ProbeAudioFile - populated automatically by
+	// CreatePlaybackChannel so it rides along in PlaybackOptions'
+	// existing JSON serialization and a UI can render track info from a
+	// saved/restored channel without re-probing the file itself.
+	Metadata *session.Metadata `json:"metadata,omitempty"`
+	// Detune is a fine pitch offset in cents (±100) layered on top of
+	// Pitch; see Channel.applyCombinedPitch.
+	Detune float32 `json:"detune"`
+
+	// Loops is the number of times playback restarts after reaching the
+	// end of the file (or of LoopIntro's body region, if set); 0 plays
+	// once, -1 loops indefinitely. Mirrors SetModuleLoop's convention on
+	// TrackerOptions.
+	Loops int `json:"loops"`
+	// LoopIntro, if set, marks a region that plays once before looping
+	// begins: samples before LoopIntro.Start play through normally, then
+	// playback loops between LoopIntro.Start and LoopIntro.End for as
+	// long as Loops dictates. This is the "infinite loop with intro"
+	// pattern common in game audio, where a short attack transient
+	// shouldn't repeat with the body.
+	LoopIntro *LoopRegion `json:"loopIntro,omitempty"`
+
+	// LoopRange, if set, marks a standalone loop region by frame offset,
+	// independent of LoopIntro's "play once, then loop the body" shape -
+	// the plain "loop these two points" primitive trackers/samplers
+	// expose directly to the user. LoopEnabled gates whether it's active;
+	// see SetLoopRange/SetLoopEnabled.
+	LoopRange   *LoopRegion `json:"loopRange,omitempty"`
+	LoopEnabled bool        `json:"loopEnabled"`
+
+	// LoopCrossfadeDuration, if nonzero, is mixed across each loop
+	// boundary - the loop region's tail blended into its head - so the
+	// seam is glitch-free instead of a hard cut. See SetLoopCrossfade.
+	LoopCrossfadeDuration time.Duration `json:"loopCrossfadeDuration,omitempty"`
+
+	// FadeInDuration/FadeOutDuration, if nonzero, are applied
+	// automatically by Play/Stop via FadeIn/FadeOut; see
+	// SetFadeIn/SetFadeOut.
+	FadeInDuration  time.Duration `json:"fadeInDuration"`
+	FadeOutDuration time.Duration `json:"fadeOutDuration"`
+
+	// QuantizeBeats, if nonzero, is the bar/beat grid (in beats, against
+	// the owning Engine's TransportClock) a caller should round a start
+	// position to before calling Play - e.g. 4 to start on the next bar at
+	// 4/4. Not applied automatically by Play; see
+	// (*TransportClock).NextBeatBoundary.
+	QuantizeBeats float64 `json:"quantizeBeats,omitempty"`
+
+	// Streamed marks a channel created by CreateStreamPlaybackChannel
+	// rather than CreatePlaybackChannel: FilePath is empty and frames
+	// arrive from a StreamDecoder instead of AVAudioFile, so Play skips
+	// the on-disk file check. See stream_player.go.
+	Streamed bool `json:"streamed,omitempty"`
+
+	// streamStop/streamDone/streamOnce coordinate shutdown of the
+	// background feeder goroutine startStreamFeeder launches for a
+	// Streamed channel; unused otherwise. See stopStreamFeeder.
+	streamStop chan struct{}
+	streamDone chan struct{}
+	streamOnce sync.Once
+
 	// Native player instance (not serialized)
 	playerPtr unsafe.Pointer `json:"-"`
+
+	// fade (see fade.go) tracks the in-flight FadeIn/FadeOut ramp, if any.
+	fadeMu     sync.Mutex
+	fadeCancel chan struct{}
+
+	// transport (see transport.go) tracks Play/Pause/Stop state and fans
+	// out TransportEvents to Subscribe's listeners.
+	transportMu    sync.Mutex
+	transportState TransportState
+	subscribers    []chan TransportEvent
+}
+
+// LoopRegion marks a loop body by sample offset into the file, used by
+// PlaybackOptions.LoopIntro.
+type LoopRegion struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// SamplerOptions contains sampler-specific configuration. Path/Bank/Program
+// record whatever LoadSoundFont/LoadEXS24 last loaded into samplerPtr, so
+// engine serialization round-trips a sampler channel's sound the same way
+// PlaybackOptions.FilePath round-trips a playback channel's file - they
+// aren't applied automatically on deserialization (there's no live
+// samplerPtr to apply them to until the owning Engine recreates the
+// channel), just carried for a caller to re-load.
+type SamplerOptions struct {
+	Path    string `json:"path,omitempty"`
+	Bank    int    `json:"bank"`
+	Program int    `json:"program"`
+	// InstrumentType distinguishes an SF2 soundfont load (LoadSoundFont,
+	// "soundfont") from an EXS24 instrument load (LoadEXS24, "exs24") -
+	// Bank/Program are meaningless for the latter.
+	InstrumentType string `json:"instrumentType,omitempty"`
+
+	// Internal sampler node (not serialized)
+	samplerPtr unsafe.Pointer `json:"-"`
 }
 
 // InputOptions contains input-specific configuration
@@ -107,6 +256,43 @@ type InputOptions struct {
 	Device       *devices.AudioDevice `json:"device"`       // Complete device info with capabilities
 	ChannelIndex int                  `json:"channelIndex"` // Channel index on the device
 	PluginChain  *PluginChain         `json:"pluginChain"`  // Effects chain
+
+	// ChainRef, if set, names a chain this channel shares through the
+	// owning Engine's ChainManager instead of (or alongside) its own
+	// PluginChain - e.g. a live input channel and a bounce/preview channel
+	// both pointing at one "Vocals FX" chain. AttachSharedChain sets it and
+	// registers the ref; DestroyChannel detaches it automatically. See
+	// chain_ref.go.
+	ChainRef *ChainRef `json:"chainRef,omitempty"`
+
+	// DeviceUID and Spec are set by CreateCaptureChannel (see
+	// capture_channel.go), not CreateInputChannel - they identify which
+	// AVAudioInputNode backs a genuine capture channel rather than a
+	// mixer-strip placeholder. inputNodePtr is that node's native pointer,
+	// nil unless created via CreateCaptureChannel.
+	DeviceUID    string         `json:"deviceUID,omitempty"`
+	Spec         AudioSpec      `json:"spec,omitempty"`
+	inputNodePtr unsafe.Pointer `json:"-"`
+
+	// captureTap, capturing, ring, ringMu, tapFn, and framesCaptured back
+	// StartCapture/StopCapture/Read/InstallTap in capture_channel.go.
+	captureTap     *tap.CallbackTap                      `json:"-"`
+	capturing      bool                                  `json:"-"`
+	ring           []float32                             `json:"-"`
+	ringMu         sync.Mutex                            `json:"-"`
+	tapFn          func(buf AudioBuffer, when AudioTime) `json:"-"`
+	framesCaptured uint64                                `json:"-"`
+
+	// encoderSink and encoderErr back AttachEncoder/DetachEncoder and
+	// deliverCapture's encoder write in encoder_sink.go.
+	encoderSink encoder.Encoder `json:"-"`
+	encoderErr  error           `json:"-"`
+
+	// connectionState tracks whether Device is still the live capture
+	// source - see Channel.State/RebindDevice and Engine.WatchDevices in
+	// device_watch.go. Zero value is Connected, matching every channel's
+	// state immediately after CreateCaptureChannel.
+	connectionState ConnectionState `json:"-"`
 }
 
 // IsInput returns true if this is an input channel
@@ -119,29 +305,166 @@ func (c *Channel) IsPlayback() bool {
 	return c.PlaybackOptions != nil
 }
 
-// SetVolume sets the volume for this channel (0.0 to 1.0)
+// IsSampler returns true if this is a sampler channel (see
+// Engine.CreateSamplerChannel).
+func (c *Channel) IsSampler() bool {
+	return c.SamplerOptions != nil
+}
+
+// Automation lazily creates and returns the channel's automation lane for
+// param ("volume" or "pan"), mirroring EnginePlugin.Automation.
+func (c *Channel) Automation(param string) *AutomationLane {
+	if c.Lanes == nil {
+		c.Lanes = make(map[string]*AutomationLane)
+	}
+	lane, ok := c.Lanes[param]
+	if !ok {
+		lane = &AutomationLane{}
+		c.Lanes[param] = lane
+	}
+	return lane
+}
+
+// channelSilenceDB is the dB floor SetVolumeDB/GetVolumeDB use to represent
+// silence instead of actual negative infinity, matching the "-inf...plus"
+// convention most mixer UIs display (e.g. a fader bottoming out at -96dB
+// rather than printing "-Inf").
+const channelSilenceDB = float32(-96)
+
+// channelMaxGainLinear is the linear ceiling SetVolumeLinear allows when
+// allowGain is true: roughly +12dB, matching the audio-standard headroom
+// SetVolumeDB permits above unity.
+const channelMaxGainLinear = float32(4.0)
+
+// SetVolume sets the volume for this channel (0.0 to 1.0). It's equivalent
+// to SetVolumeLinear(volume, false); see SetVolumeLinear to opt into gain
+// above unity, or SetVolumeDB to work in dB instead of a linear factor.
 func (c *Channel) SetVolume(volume float32) error {
+	return c.SetVolumeLinear(volume, false)
+}
+
+// SetVolumeLinear sets the channel's linear volume (0.0 = silence, 1.0 =
+// unity gain). Pass allowGain to permit values above unity, up to
+// channelMaxGainLinear (~+12dB) - mirrors how mixer libraries like
+// PulseAudio accept factors above 1.0 with an explicit
+// "clipping/distortion may occur" contract, rather than rejecting them
+// outright the way the default, unity-capped path does.
+func (c *Channel) SetVolumeLinear(volume float32, allowGain bool) error {
 	if c.mixerNodePtr == nil {
 		return errors.New("no mixer node available for this channel")
 	}
-
-	// Validate the volume parameter
-	if err := ValidateVolume(volume); err != nil {
-		return err
+	if volume < 0 {
+		return errors.New("volume cannot be negative")
+	}
+	ceiling := float32(1.0)
+	if allowGain {
+		ceiling = channelMaxGainLinear
+	}
+	if volume > ceiling {
+		return fmt.Errorf("volume %.2f exceeds the %.1f ceiling (pass allowGain=true to raise it to %.1f)", volume, ceiling, channelMaxGainLinear)
 	}
 
-	// Update the stored volume with validated value
-	c.Volume = volume
+	return c.runOnEngine(func(ctx context.Context) error {
+		// Update the stored (target) volume with validated value, even while
+		// muted, so Unmute restores this level rather than whatever was
+		// applied to the mixer while silenced.
+		c.Volume = volume
+		return c.applyEffectiveVolume()
+	})
+}
 
-	// Set volume on the channel's mixer node (input bus 0)
+// applyEffectiveVolume writes Volume to the mixer, or silence if the channel
+// is muted (by the user or by another channel's solo) - mirrors
+// engine/channel.BaseChannel.applyEffectiveVolume.
+func (c *Channel) applyEffectiveVolume() error {
+	volume := c.Volume
+	if c.userMuted || c.soloMuted {
+		volume = 0
+	}
 	errorStr := C.audiomixer_set_volume(c.mixerNodePtr, C.float(volume), 0)
 	if errorStr != nil {
 		return errors.New("failed to set channel volume: " + C.GoString(errorStr))
 	}
+	return nil
+}
+
+// Mute silences the channel at the mixer while leaving Volume untouched, so
+// Unmute restores the exact prior level (as Sonos-style controllers do with
+// mute()/unmute(), rather than writing 0 into the volume itself).
+func (c *Channel) Mute() error {
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.userMuted = true
+		if c.mixerNodePtr == nil {
+			return nil
+		}
+		return c.applyEffectiveVolume()
+	})
+}
+
+// Unmute restores the channel's prior Volume, unless it remains solo-muted
+// by another channel.
+func (c *Channel) Unmute() error {
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.userMuted = false
+		if c.mixerNodePtr == nil {
+			return nil
+		}
+		return c.applyEffectiveVolume()
+	})
+}
+
+// IsMuted reports whether the channel is muted by the user (not counting
+// solo-induced muting; see IsSolo).
+func (c *Channel) IsMuted() bool {
+	return c.userMuted
+}
+
+// Solo engages this channel's solo via defaultChannelSolo: every other
+// registered channel is attenuated to silence at the mixer until no channel
+// remains soloed, without touching their Volume fields.
+func (c *Channel) Solo() error {
+	defaultChannelSolo.setSolo(c, true)
+	return nil
+}
 
+// Unsolo disengages this channel's solo.
+func (c *Channel) Unsolo() error {
+	defaultChannelSolo.setSolo(c, false)
 	return nil
 }
 
+// IsSolo reports whether this channel is currently soloed.
+func (c *Channel) IsSolo() bool {
+	return defaultChannelSolo.isSoloed(c)
+}
+
+// SetVolumeDB sets the channel's volume as a dB value in the audio-standard
+// -inf...+12dB range, converting to a linear factor via 10^(db/20); values
+// at or below channelSilenceDB map to linear 0 rather than underflowing.
+func (c *Channel) SetVolumeDB(db float32) error {
+	if db > 12 {
+		return fmt.Errorf("volume %.1fdB exceeds the +12dB ceiling", db)
+	}
+	if db <= channelSilenceDB {
+		return c.SetVolumeLinear(0, true)
+	}
+	return c.SetVolumeLinear(float32(math.Pow(10, float64(db)/20)), true)
+}
+
+// GetVolumeDB returns the channel's current volume converted to dB (see
+// SetVolumeDB); silence (linear 0) reports channelSilenceDB rather than
+// negative infinity.
+func (c *Channel) GetVolumeDB() (float32, error) {
+	linear, err := c.GetVolume()
+	if err != nil {
+		return 0, err
+	}
+	if linear <= 0 {
+		return channelSilenceDB, nil
+	}
+	return float32(20 * math.Log10(float64(linear))), nil
+}
+
 // GetVolume returns the current volume for this channel
 func (c *Channel) GetVolume() (float32, error) {
 	if c.mixerNodePtr == nil {
@@ -154,8 +477,12 @@ func (c *Channel) GetVolume() (float32, error) {
 		return 0.0, errors.New("failed to get channel volume: " + C.GoString(errorStr))
 	}
 
-	// Update cached value
-	c.Volume = float32(volume)
+	// Update cached value, unless the mixer reads 0 only because the
+	// channel is currently muted - otherwise reading volume while muted
+	// would clobber the target Mute/Unmute are meant to preserve.
+	if !c.userMuted && !c.soloMuted {
+		c.Volume = float32(volume)
+	}
 	return float32(volume), nil
 }
 
@@ -170,16 +497,17 @@ func (c *Channel) SetPan(pan float32) error {
 		return err
 	}
 
-	// Update the stored pan with validated value
-	c.Pan = pan
-
-	// Set pan on the channel's mixer node (input bus 0)
-	errorStr := C.audiomixer_set_pan(c.mixerNodePtr, C.float(pan), 0)
-	if errorStr != nil {
-		return errors.New("failed to set channel pan: " + C.GoString(errorStr))
-	}
-
-	return nil
+	return c.runOnEngine(func(ctx context.Context) error {
+		// Update the stored pan with validated value
+		c.Pan = pan
+
+		// Set pan on the channel's mixer node (input bus 0)
+		errorStr := C.audiomixer_set_pan(c.mixerNodePtr, C.float(pan), 0)
+		if errorStr != nil {
+			return errors.New("failed to set channel pan: " + C.GoString(errorStr))
+		}
+		return nil
+	})
 }
 
 // GetPan returns the current pan for this channel
@@ -199,6 +527,59 @@ func (c *Channel) GetPan() (float32, error) {
 	return float32(pan), nil
 }
 
+// channelSoloManager coordinates solo state across channels, mirroring
+// engine/channel.SoloManager: while any channel is soloed, every other
+// registered channel is attenuated to silence at the mixer (their Volume
+// field is left untouched) until no channel remains soloed. Create*Channel
+// registers each channel as it's built; setSolo also registers its
+// argument defensively, so a channel assembled by hand (as the test
+// helpers do) still participates correctly.
+type channelSoloManager struct {
+	mu      sync.Mutex
+	members map[*Channel]struct{}
+	soloed  map[*Channel]struct{}
+}
+
+var defaultChannelSolo = &channelSoloManager{members: map[*Channel]struct{}{}, soloed: map[*Channel]struct{}{}}
+
+func (sm *channelSoloManager) register(c *Channel) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.members[c] = struct{}{}
+}
+
+func (sm *channelSoloManager) setSolo(c *Channel, on bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.members[c] = struct{}{}
+	if on {
+		sm.soloed[c] = struct{}{}
+	} else {
+		delete(sm.soloed, c)
+	}
+	sm.recompute()
+}
+
+func (sm *channelSoloManager) isSoloed(c *Channel) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	_, ok := sm.soloed[c]
+	return ok
+}
+
+// recompute applies solo-induced muting to all registered channels based on
+// the current soloed set.
+func (sm *channelSoloManager) recompute() {
+	hasSolo := len(sm.soloed) > 0
+	for c := range sm.members {
+		_, isSolo := sm.soloed[c]
+		c.soloMuted = hasSolo && !isSolo
+		if c.mixerNodePtr != nil {
+			_ = c.applyEffectiveVolume()
+		}
+	}
+}
+
 // DestroyChannel removes a channel and frees its bus
 func (e *Engine) DestroyChannel(index int) error {
 	if index < 0 || index >= len(e.Channels) {
@@ -211,17 +592,31 @@ func (e *Engine) DestroyChannel(index int) error {
 
 	channel := e.Channels[index]
 
-	// Free the bus allocated to this channel
-	err := e.FreeBusForChannel(channel)
-	if err != nil {
-		// Log the warning but don't fail the destruction
-		// The channel might not have had a bus allocated yet
-	}
-
-	// TODO: Disconnect channel from mixer bus
-	// TODO: Clean up channel resources (playerPtr, mixerNodePtr, etc.)
-
-	// Remove channel from slice
-	e.Channels = append(e.Channels[:index], e.Channels[index+1:]...)
-	return nil
+	return e.runSync(context.Background(), func(ctx context.Context) error {
+		// Drain any in-progress recording/capture before tearing down the
+		// channel's graph, so the last buffered block reaches disk (or
+		// whatever InstallTap callback cares) instead of being dropped
+		// mid-write - see StartRecording/StartCapture in recording.go and
+		// capture_channel.go.
+		if opts := channel.InputOptions; opts != nil && opts.capturing {
+			_ = channel.StopRecording()
+			_ = channel.StopCapture()
+		}
+
+		// Free the bus allocated to this channel
+		err := e.FreeBusForChannel(channel)
+		if err != nil {
+			// Log the warning but don't fail the destruction
+			// The channel might not have had a bus allocated yet
+		}
+
+		channel.detachSharedChainLocked()
+
+		// TODO: Disconnect channel from mixer bus
+		// TODO: Clean up channel resources (playerPtr, mixerNodePtr, etc.)
+
+		// Remove channel from slice
+		e.Channels = append(e.Channels[:index], e.Channels[index+1:]...)
+		return nil
+	})
 }
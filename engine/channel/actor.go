@@ -0,0 +1,271 @@
+package channel
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// ChannelCommand is implemented by every mutation NewBaseChannelActor's
+// cmdCh accepts - one typed struct per command rather than a single fat
+// struct with optional fields, the same sealed-interface shape events.go's
+// BusEvent already uses for Bus.Subscribe.
+type ChannelCommand interface {
+	isChannelCommand()
+}
+
+// SetVolumeCmd requests bc.SetVolume(Level).
+type SetVolumeCmd struct{ Level float32 }
+
+func (SetVolumeCmd) isChannelCommand() {}
+
+// SetMuteCmd requests bc.SetMute(On).
+type SetMuteCmd struct{ On bool }
+
+func (SetMuteCmd) isChannelCommand() {}
+
+// SetPanCmd requests bc.SetPan(Pan).
+type SetPanCmd struct{ Pan float32 }
+
+func (SetPanCmd) isChannelCommand() {}
+
+// SetSoloCmd requests bc.SetSolo(On), driving the package-wide DefaultSolo
+// manager the same way a direct call would.
+type SetSoloCmd struct{ On bool }
+
+func (SetSoloCmd) isChannelCommand() {}
+
+// CreateSendCmd requests bc.CreateSend(Name, Destination, Level).
+type CreateSendCmd struct {
+	Name        string
+	Destination Channel
+	Level       float32
+}
+
+func (CreateSendCmd) isChannelCommand() {}
+
+// SetSendLevelCmd requests bc.SetSendLevel(Name, Level) on an
+// already-created send.
+type SetSendLevelCmd struct {
+	Name  string
+	Level float32
+}
+
+func (SetSendLevelCmd) isChannelCommand() {}
+
+// SetSendMuteCmd requests bc.SetSendMute(Name, Muted).
+type SetSendMuteCmd struct {
+	Name  string
+	Muted bool
+}
+
+func (SetSendMuteCmd) isChannelCommand() {}
+
+// ConnectSendCmd requests bc.ConnectSendTo(Name, Bus), wiring an
+// already-created send to a destination Bus input.
+type ConnectSendCmd struct {
+	Name string
+	Bus  *Bus
+}
+
+func (ConnectSendCmd) isChannelCommand() {}
+
+// ChannelEvent is implemented by every state transition NewBaseChannelActor
+// publishes on evtCh.
+type ChannelEvent interface {
+	isChannelEvent()
+}
+
+// VolumeChanged is published after a SetVolumeCmd commits.
+type VolumeChanged struct{ Old, New float32 }
+
+func (VolumeChanged) isChannelEvent() {}
+
+// MuteChanged is published after a SetMuteCmd commits.
+type MuteChanged struct{ Muted bool }
+
+func (MuteChanged) isChannelEvent() {}
+
+// PanChanged is published after a SetPanCmd commits.
+type PanChanged struct{ Old, New float32 }
+
+func (PanChanged) isChannelEvent() {}
+
+// SoloStateChanged is published after a SetSoloCmd commits, carrying bc's
+// resulting IsSoloed() rather than echoing On, since DefaultSolo's own
+// solo-of-one-mutes-the-rest bookkeeping can differ from the requested value
+// for a channel that was already soloed by another SetSoloCmd.
+type SoloStateChanged struct{ Soloed bool }
+
+func (SoloStateChanged) isChannelEvent() {}
+
+// SendLevelChanged is published after a CreateSendCmd or SetSendLevelCmd
+// commits.
+type SendLevelChanged struct {
+	Name  string
+	Level float32
+}
+
+func (SendLevelChanged) isChannelEvent() {}
+
+// SendMuteChanged is published after a SetSendMuteCmd commits.
+type SendMuteChanged struct {
+	Name  string
+	Muted bool
+}
+
+func (SendMuteChanged) isChannelEvent() {}
+
+// MeterUpdate is published on a fixed poll interval while bc.Meter() is
+// reporting a finite level - i.e. StartMetering has been called - rather
+// than on every tick regardless of whether metering is actually running.
+type MeterUpdate struct{ Levels Levels }
+
+func (MeterUpdate) isChannelEvent() {}
+
+// CommandFailed is published instead of the command's usual *Changed event
+// when its underlying BaseChannel method returns an error. cmdCh has no
+// reply path of its own, so this is the only way a caller driving bc purely
+// through the actor learns a command didn't apply.
+type CommandFailed struct {
+	Cmd ChannelCommand
+	Err error
+}
+
+func (CommandFailed) isChannelEvent() {}
+
+// actorMeterPollInterval matches DefaultBallistics' own PollInterval, since
+// that's the rate Meter()'s snapshot actually refreshes at.
+const actorMeterPollInterval = time.Second / 60
+
+// cmdChanBuffer/evtChanBuffer size cmdCh/evtCh the same as busEventBus's own
+// per-subscriber buffer (see events.go) - enough to absorb a burst without
+// the actor or its caller blocking on a slow peer.
+const (
+	cmdChanBuffer = 32
+	evtChanBuffer = 32
+)
+
+// NewBaseChannelActor starts a goroutine that serializes every ChannelCommand
+// sent on the returned cmdCh through bc's queue.Dispatcher (see
+// BaseChannelConfig.Dispatcher) the same way graph mutations already are,
+// then publishes the resulting state change on evtCh. This gives a network
+// server, UI, or test harness a single cancellable channel-based surface to
+// drive bc's mixer state without reaching into its mutex-protected methods
+// directly.
+//
+// evtCh is buffered and drops an event for a subscriber that isn't keeping
+// up, the same tolerance busEventBus.publish already applies to
+// Bus.Subscribe. stop cancels the actor goroutine and closes evtCh; it's
+// safe to call more than once, and safe to call without draining evtCh
+// first.
+func NewBaseChannelActor(bc *BaseChannel) (cmdCh chan<- ChannelCommand, evtCh <-chan ChannelEvent, stop func()) {
+	cmds := make(chan ChannelCommand, cmdChanBuffer)
+	events := make(chan ChannelEvent, evtChanBuffer)
+	done := make(chan struct{})
+	var stopOnce sync.Once
+
+	go runBaseChannelActor(bc, cmds, events, done)
+
+	return cmds, events, func() {
+		stopOnce.Do(func() { close(done) })
+	}
+}
+
+func runBaseChannelActor(bc *BaseChannel, cmds <-chan ChannelCommand, events chan<- ChannelEvent, done <-chan struct{}) {
+	defer close(events)
+
+	ticker := time.NewTicker(actorMeterPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case cmd, ok := <-cmds:
+			if !ok {
+				return
+			}
+			applyChannelCommand(bc, cmd, events)
+		case <-ticker.C:
+			if levels := bc.Meter(); !math.IsInf(levels.RMSDB, -1) {
+				publishChannelEvent(events, MeterUpdate{Levels: levels})
+			}
+		}
+	}
+}
+
+// publishChannelEvent drops ev for a subscriber that isn't draining evtCh,
+// rather than blocking the actor goroutine that's supposed to keep
+// committing commands.
+func publishChannelEvent(events chan<- ChannelEvent, ev ChannelEvent) {
+	select {
+	case events <- ev:
+	default:
+	}
+}
+
+// applyChannelCommand runs cmd's underlying BaseChannel method - through
+// bc's queue.Dispatcher if one is set, serializing it with other graph
+// mutations the same way SetMute's own click-avoiding ramp already is -
+// and publishes the resulting event or a CommandFailed on error.
+func applyChannelCommand(bc *BaseChannel, cmd ChannelCommand, events chan<- ChannelEvent) {
+	apply := func(fn func() error) error {
+		if bc.dispatcher != nil {
+			return bc.dispatcher.RunSync(func(ctx context.Context) error { return fn() })
+		}
+		return fn()
+	}
+
+	switch c := cmd.(type) {
+	case SetVolumeCmd:
+		old, _ := bc.GetVolume()
+		if err := apply(func() error { return bc.SetVolume(c.Level) }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+			return
+		}
+		publishChannelEvent(events, VolumeChanged{Old: old, New: c.Level})
+	case SetMuteCmd:
+		if err := apply(func() error { return bc.SetMute(c.On) }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+			return
+		}
+		publishChannelEvent(events, MuteChanged{Muted: c.On})
+	case SetPanCmd:
+		old, _ := bc.GetPan()
+		if err := apply(func() error { return bc.SetPan(c.Pan) }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+			return
+		}
+		publishChannelEvent(events, PanChanged{Old: old, New: c.Pan})
+	case SetSoloCmd:
+		if err := apply(func() error { bc.SetSolo(c.On); return nil }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+			return
+		}
+		publishChannelEvent(events, SoloStateChanged{Soloed: bc.IsSoloed()})
+	case CreateSendCmd:
+		if err := apply(func() error { return bc.CreateSend(c.Name, c.Destination, c.Level) }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+			return
+		}
+		publishChannelEvent(events, SendLevelChanged{Name: c.Name, Level: c.Level})
+	case SetSendLevelCmd:
+		if err := apply(func() error { return bc.SetSendLevel(c.Name, c.Level) }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+			return
+		}
+		publishChannelEvent(events, SendLevelChanged{Name: c.Name, Level: c.Level})
+	case SetSendMuteCmd:
+		if err := apply(func() error { return bc.SetSendMute(c.Name, c.Muted) }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+			return
+		}
+		publishChannelEvent(events, SendMuteChanged{Name: c.Name, Muted: c.Muted})
+	case ConnectSendCmd:
+		if err := apply(func() error { _, err := bc.ConnectSendTo(c.Name, c.Bus); return err }); err != nil {
+			publishChannelEvent(events, CommandFailed{Cmd: cmd, Err: err})
+		}
+	}
+}
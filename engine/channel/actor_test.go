@@ -0,0 +1,115 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// drainEvent waits for a single ChannelEvent, failing the test if none
+// arrives within a generous deadline - the actor commits asynchronously, so
+// tests can't just check bc's state immediately after a send.
+func drainEvent(t *testing.T, events <-chan ChannelEvent) ChannelEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatalf("evtCh closed while waiting for an event")
+		}
+		return ev
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for an event")
+		return nil
+	}
+}
+
+// TestBaseChannelActorAppliesVolumeCommand checks that a SetVolumeCmd sent
+// on cmdCh both commits to bc and publishes a matching VolumeChanged.
+func TestBaseChannelActorAppliesVolumeCommand(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	cmds, events, stop := NewBaseChannelActor(ch)
+	defer stop()
+
+	cmds <- SetVolumeCmd{Level: 0.5}
+
+	ev := drainEvent(t, events)
+	changed, ok := ev.(VolumeChanged)
+	if !ok {
+		t.Fatalf("expected VolumeChanged, got %#v", ev)
+	}
+	if changed.New != 0.5 {
+		t.Errorf("expected VolumeChanged.New == 0.5, got %.2f", changed.New)
+	}
+
+	if vol, err := ch.GetVolume(); err != nil || vol != 0.5 {
+		t.Errorf("expected the channel's own volume to be committed to 0.5, got %.2f (err %v)", vol, err)
+	}
+}
+
+// TestBaseChannelActorReportsFailure checks that a command whose underlying
+// method errors publishes CommandFailed rather than a *Changed event.
+func TestBaseChannelActorReportsFailure(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	cmds, events, stop := NewBaseChannelActor(ch)
+	defer stop()
+
+	// SetSendLevel on a send that was never created should fail.
+	cmds <- SetSendLevelCmd{Name: "reverb", Level: 0.5}
+
+	ev := drainEvent(t, events)
+	if _, ok := ev.(CommandFailed); !ok {
+		t.Fatalf("expected CommandFailed, got %#v", ev)
+	}
+}
+
+// TestBaseChannelActorStopClosesEvents checks that stop() unblocks evtCh's
+// reader instead of leaving it hanging.
+func TestBaseChannelActorStopClosesEvents(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	_, events, stop := NewBaseChannelActor(ch)
+	stop()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatalf("expected evtCh to be closed after stop")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for evtCh to close after stop")
+	}
+}
@@ -4,12 +4,45 @@ package channel
 import (
 	"fmt"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/shaban/macaudio/avaudio/engine"
 	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/devices"
 )
 
+// sampleRater is implemented by a Channel that knows its own source sample
+// rate, e.g. one backed by a physical input device running at the device's
+// native rate rather than the engine's. ConnectChannel type-asserts for it
+// to decide whether a resampler needs inserting; a Channel that doesn't
+// implement it (a synth, a player already rendering at the engine rate) is
+// assumed to already match the bus.
+type sampleRater interface {
+	SampleRate() (float64, error)
+}
+
+// connectionRates records what ConnectChannel decided for one input -
+// backs ConnectionInfo.
+type connectionRates struct {
+	srcRate   float64
+	dstRate   float64
+	resampled bool
+}
+
+// resamplerLatency is a conservative fixed estimate of AVAudioConverter's
+// algorithmic latency; the native bridge doesn't yet expose the exact
+// figure, which depends on the conversion ratio actually chosen.
+const resamplerLatency = 3 * time.Millisecond
+
+// channelMapping records the matrix mixer node ConnectChannelWithLayout
+// inserted for one input and the mapping it was last configured with.
+type channelMapping struct {
+	matrix  unsafe.Pointer
+	mapping []int
+}
+
 // Bus wraps an AVAudioMixerNode to act as a simple mix bus with input allocation.
 // It manages a dedicated mixer node, attaches it to the engine, and tracks the
 // next free input index for convenience.
@@ -20,6 +53,43 @@ type Bus struct {
 	mu        sync.Mutex
 	nextInput int
 	inputs    map[int]unsafe.Pointer // input index -> source node pointer
+
+	// mutedInputs/premuteLevels back SetInputMute: muting an input remembers
+	// its level here so unmuting restores it, rather than snapping to unity.
+	mutedInputs   map[int]bool
+	premuteLevels map[int]float32
+
+	// masterMuted/premuteLevel are the same pattern applied to the bus's own
+	// overall level (see SetLevel/SetMute), as distinct from any one input.
+	masterMuted  bool
+	premuteLevel float32
+
+	// resamplers holds the resampler node ConnectChannel inserted for an
+	// input, keyed by input index; an input absent here was connected
+	// directly. resamplingQuality is the quality new resamplers are created
+	// with (see SetResamplingQuality). connRates records the rates
+	// ConnectChannel compared for each input, backing ConnectionInfo.
+	resamplers        map[int]unsafe.Pointer
+	resamplingQuality node.ResamplingQuality
+	connRates         map[int]connectionRates
+
+	// channelMaps holds the channel-mapping matrix ConnectChannelWithLayout
+	// inserted for an input, keyed by input index, so SetInputChannelMap can
+	// reconfigure it later.
+	channelMaps map[int]channelMapping
+
+	// taps holds every callback tap installed via InstallInputTap/
+	// InstallOutputTap, keyed by the key tap.InstallCallbackTap assigned -
+	// the same bookkeeping BaseChannel.taps does for channel taps.
+	taps map[string]*tap.CallbackTap
+
+	// inputNames records the GetName() of the Channel ConnectChannel/
+	// ConnectChannelWithLayout connected at each input, for InputConnected
+	// and for Subscribe's connect-state snapshot.
+	inputNames map[int]string
+
+	// events is Subscribe's subscriber registry; see BusEvent.
+	events *busEventBus
 }
 
 // NewBus creates and attaches a new mixer-backed bus.
@@ -35,7 +105,20 @@ func NewBus(eng *engine.Engine, name string) (*Bus, error) {
 		_ = node.ReleaseMixer(m)
 		return nil, fmt.Errorf("attach bus mixer: %w", err)
 	}
-	return &Bus{name: name, eng: eng, mixer: m, inputs: make(map[int]unsafe.Pointer)}, nil
+	return &Bus{
+		name:          name,
+		eng:           eng,
+		mixer:         m,
+		inputs:        make(map[int]unsafe.Pointer),
+		mutedInputs:   make(map[int]bool),
+		premuteLevels: make(map[int]float32),
+		resamplers:    make(map[int]unsafe.Pointer),
+		connRates:     make(map[int]connectionRates),
+		channelMaps:   make(map[int]channelMapping),
+		taps:          make(map[string]*tap.CallbackTap),
+		inputNames:    make(map[int]string),
+		events:        newBusEventBus(),
+	}, nil
 }
 
 // Ptr returns the underlying mixer node pointer.
@@ -53,8 +136,12 @@ func (b *Bus) NextInput() int {
 	return idx
 }
 
-// ConnectChannel connects a channel's output to the bus at the next free input.
-// Returns the input index used.
+// ConnectChannel connects a channel's output to the bus at the next free
+// input. If ch reports its own sample rate (see sampleRater) and that rate
+// doesn't match the bus's own (see SampleRate), a resampler is inserted
+// between ch and the mixer input transparently, instead of the connection
+// silently producing garbage or AVAudioEngine refusing it outright. Returns
+// the input index used.
 func (b *Bus) ConnectChannel(ch Channel) (int, error) {
 	if b == nil || b.mixer == nil || b.eng == nil {
 		return -1, fmt.Errorf("bus not initialized")
@@ -72,18 +159,321 @@ func (b *Bus) ConnectChannel(ch Channel) (int, error) {
 			return -1, fmt.Errorf("attach source: %w", err)
 		}
 	}
-	// Allocate input and connect
+
+	dstRate, dstErr := b.SampleRate()
+	var srcRate float64
+	if sr, ok := ch.(sampleRater); ok {
+		if r, err := sr.SampleRate(); err == nil {
+			srcRate = r
+		}
+	}
+
+	// Allocate input and connect, inserting a resampler first if the rates
+	// are known and differ.
 	to := b.NextInput()
-	if err := b.eng.Connect(src, b.mixer, 0, to); err != nil {
+	connectSrc := src
+	resampled := false
+	if dstErr == nil && srcRate != 0 && srcRate != dstRate {
+		resampler, err := node.CreateResampler()
+		if err != nil {
+			return -1, fmt.Errorf("create resampler: %w", err)
+		}
+		if err := node.SetResamplerQuality(resampler, b.resamplingQuality); err != nil {
+			_ = node.ReleaseResampler(resampler)
+			return -1, fmt.Errorf("set resampler quality: %w", err)
+		}
+		if err := b.eng.Attach(resampler); err != nil {
+			_ = node.ReleaseResampler(resampler)
+			return -1, fmt.Errorf("attach resampler: %w", err)
+		}
+		if err := b.eng.Connect(src, resampler, 0, 0); err != nil {
+			_ = node.ReleaseResampler(resampler)
+			return -1, fmt.Errorf("connect source->resampler: %w", err)
+		}
+		connectSrc = resampler
+		resampled = true
+		b.mu.Lock()
+		b.resamplers[to] = resampler
+		b.mu.Unlock()
+	}
+
+	if err := b.eng.Connect(connectSrc, b.mixer, 0, to); err != nil {
 		return -1, fmt.Errorf("connect channel->bus: %w", err)
 	}
 	b.mu.Lock()
 	b.inputs[to] = src
+	b.connRates[to] = connectionRates{srcRate: srcRate, dstRate: dstRate, resampled: resampled}
+	b.inputNames[to] = ch.GetName()
+	b.mu.Unlock()
+	b.events.publish(InputConnected{Input: to, SrcName: ch.GetName()})
+	return to, nil
+}
+
+// ConnectChannelWithLayout connects ch into the bus like ConnectChannel,
+// but with an explicit channel mapping instead of a single implicit bus 0
+// tap: it inserts a matrix mixer (see node.CreateMatrixMixer) configured
+// for layout.ChannelCount() destination channels, where mapping[i] is the
+// source channel that feeds destination channel i (-1 silences it). This
+// is what unblocks surround workflows CreateInputChannel's plain mono tap
+// can't express. Returns the input index used on the bus mixer.
+func (b *Bus) ConnectChannelWithLayout(ch Channel, layout devices.ChannelLayout, mapping []int) (int, error) {
+	if b == nil || b.mixer == nil || b.eng == nil {
+		return -1, fmt.Errorf("bus not initialized")
+	}
+	if ch == nil {
+		return -1, fmt.Errorf("channel cannot be nil")
+	}
+	if len(mapping) != layout.ChannelCount() {
+		return -1, fmt.Errorf("mapping has %d entries, layout %q needs %d", len(mapping), layout.Tag, layout.ChannelCount())
+	}
+	src := ch.GetOutputNode()
+	if src == nil {
+		return -1, fmt.Errorf("channel output node is nil")
+	}
+	if installed, err := node.IsInstalledOnEngine(src); err == nil && !installed {
+		if err := b.eng.Attach(src); err != nil {
+			return -1, fmt.Errorf("attach source: %w", err)
+		}
+	}
+
+	matrix, err := node.CreateMatrixMixer()
+	if err != nil {
+		return -1, fmt.Errorf("create channel map: %w", err)
+	}
+	if err := configureChannelMap(matrix, mapping); err != nil {
+		_ = node.ReleaseNode(matrix)
+		return -1, err
+	}
+	if err := b.eng.Attach(matrix); err != nil {
+		_ = node.ReleaseNode(matrix)
+		return -1, fmt.Errorf("attach channel map: %w", err)
+	}
+	if err := b.eng.Connect(src, matrix, 0, 0); err != nil {
+		_ = node.ReleaseNode(matrix)
+		return -1, fmt.Errorf("connect source->channel map: %w", err)
+	}
+
+	to := b.NextInput()
+	if err := b.eng.Connect(matrix, b.mixer, 0, to); err != nil {
+		return -1, fmt.Errorf("connect channel map->bus: %w", err)
+	}
+
+	b.mu.Lock()
+	b.inputs[to] = src
+	b.channelMaps[to] = channelMapping{matrix: matrix, mapping: append([]int(nil), mapping...)}
+	b.inputNames[to] = ch.GetName()
 	b.mu.Unlock()
+	b.events.publish(InputConnected{Input: to, SrcName: ch.GetName()})
 	return to, nil
 }
 
-// DisconnectInput disconnects a specific input bus on the bus mixer.
+// SetInputChannelMap reconfigures the channel mapping on an input
+// previously connected with ConnectChannelWithLayout - e.g. swapping Ls/Rs
+// on a surround source without tearing down the connection. Errors if
+// input wasn't connected via ConnectChannelWithLayout.
+func (b *Bus) SetInputChannelMap(input int, mapping []int) error {
+	b.mu.Lock()
+	cm, ok := b.channelMaps[input]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("input %d has no channel map; connect it with ConnectChannelWithLayout first", input)
+	}
+	if len(mapping) != len(cm.mapping) {
+		return fmt.Errorf("mapping has %d entries, input %d was set up with %d", len(mapping), input, len(cm.mapping))
+	}
+
+	// Clear every previously routed cell first - SetMatrixVolume only ever
+	// sets a cell, it never implicitly resets one a reconfiguration drops.
+	for dst, prevSrc := range cm.mapping {
+		if prevSrc >= 0 {
+			_ = node.SetMatrixVolume(cm.matrix, prevSrc, dst, 0)
+		}
+	}
+	if err := configureChannelMap(cm.matrix, mapping); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.channelMaps[input] = channelMapping{matrix: cm.matrix, mapping: append([]int(nil), mapping...)}
+	b.mu.Unlock()
+	return nil
+}
+
+// configureChannelMap sizes matrix for len(mapping) source channels
+// (one more than the highest index mapping references) by len(mapping)
+// destination channels, then sets unity gain on each mapping[i] -> i cell;
+// mapping[i] == -1 leaves destination channel i silent.
+func configureChannelMap(matrix unsafe.Pointer, mapping []int) error {
+	srcChannels := 0
+	for _, src := range mapping {
+		if src+1 > srcChannels {
+			srcChannels = src + 1
+		}
+	}
+	if err := node.SetMatrixChannelCount(matrix, srcChannels, len(mapping)); err != nil {
+		return fmt.Errorf("configure channel map: %w", err)
+	}
+	for dst, src := range mapping {
+		if src < 0 {
+			continue
+		}
+		if err := node.SetMatrixVolume(matrix, src, dst, 1.0); err != nil {
+			return fmt.Errorf("set channel map gain %d->%d: %w", src, dst, err)
+		}
+	}
+	return nil
+}
+
+// SetResamplingQuality sets the conversion quality ConnectChannel uses for
+// any resampler it inserts from this point on; it does not affect a
+// resampler already inserted on an existing input.
+func (b *Bus) SetResamplingQuality(q node.ResamplingQuality) {
+	b.mu.Lock()
+	b.resamplingQuality = q
+	b.mu.Unlock()
+}
+
+// ConnectionInfo reports what ConnectChannel decided for input: the source
+// and destination sample rates it compared, whether it inserted a
+// resampler, and the latency that resampler adds (zero when none was).
+type ConnectionInfo struct {
+	SrcRate           float64
+	DstRate           float64
+	ResamplerInserted bool
+	Latency           time.Duration
+}
+
+// ConnectionInfo returns the ConnectionInfo recorded when input was
+// connected via ConnectChannel.
+func (b *Bus) ConnectionInfo(input int) (ConnectionInfo, error) {
+	b.mu.Lock()
+	rates, ok := b.connRates[input]
+	b.mu.Unlock()
+	if !ok {
+		return ConnectionInfo{}, fmt.Errorf("no connection recorded for input %d", input)
+	}
+	info := ConnectionInfo{SrcRate: rates.srcRate, DstRate: rates.dstRate, ResamplerInserted: rates.resampled}
+	if rates.resampled {
+		info.Latency = resamplerLatency
+	}
+	return info, nil
+}
+
+// RouteDiagnostic reports what ConnectChannel would decide for a candidate
+// channel - see DryRun.
+type RouteDiagnostic struct {
+	SrcRate         float64
+	DstRate         float64
+	ResamplerNeeded bool
+}
+
+// DryRun reports what ConnectChannel would do for src - in particular
+// whether it would insert a resampler - without attaching or connecting
+// anything. Lets a caller surface "these devices need resampling to work
+// together" before committing to the connection.
+func (b *Bus) DryRun(src Channel) (*RouteDiagnostic, error) {
+	if b == nil || b.mixer == nil {
+		return nil, fmt.Errorf("bus not initialized")
+	}
+	if src == nil {
+		return nil, fmt.Errorf("channel cannot be nil")
+	}
+	dstRate, err := b.SampleRate()
+	if err != nil {
+		return nil, fmt.Errorf("bus sample rate: %w", err)
+	}
+	srcRate := dstRate
+	if sr, ok := src.(sampleRater); ok {
+		if srcRate, err = sr.SampleRate(); err != nil {
+			return nil, fmt.Errorf("channel sample rate: %w", err)
+		}
+	}
+	return &RouteDiagnostic{
+		SrcRate:         srcRate,
+		DstRate:         dstRate,
+		ResamplerNeeded: srcRate != dstRate,
+	}, nil
+}
+
+// SampleRate reads the bus mixer's own output sample rate - the baseline
+// ConnectChannel compares an incoming channel's sample rate against.
+func (b *Bus) SampleRate() (float64, error) {
+	if b == nil || b.mixer == nil {
+		return 0, fmt.Errorf("bus not initialized")
+	}
+	formatPtr, err := node.GetOutputFormatForBus(b.mixer, 0)
+	if err != nil {
+		return 0, fmt.Errorf("bus output format: %w", err)
+	}
+	return node.FormatSampleRate(formatPtr)
+}
+
+// InstallInputTap installs a callback-driven tap on the source node feeding
+// input, pre-fader - i.e. the signal as it arrives at the bus, before this
+// mixer sums it with every other input. See avaudio/tap.InstallCallbackTap
+// for the delivery guarantees (lock-free ring, dropped-block counting, cb
+// never runs on the audio thread) - the same pattern BaseChannel.InstallTap
+// uses for a channel's own output.
+func (b *Bus) InstallInputTap(input int, bufferSize int, format tap.TapFormat, cb func(tap.TapBuffer)) (*tap.CallbackTap, error) {
+	if b == nil || b.mixer == nil || b.eng == nil {
+		return nil, fmt.Errorf("bus not initialized")
+	}
+	b.mu.Lock()
+	src, ok := b.inputs[input]
+	b.mu.Unlock()
+	if !ok || src == nil {
+		return nil, fmt.Errorf("no source connected at input %d", input)
+	}
+
+	t, err := tap.InstallCallbackTap(b.eng.Ptr(), src, 0, bufferSize, format, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.taps[t.GetKey()] = t
+	b.mu.Unlock()
+	return t, nil
+}
+
+// InstallOutputTap installs a callback-driven tap on the bus's own mixer
+// output, post-mix - everything routed through this bus, summed.
+func (b *Bus) InstallOutputTap(bufferSize int, format tap.TapFormat, cb func(tap.TapBuffer)) (*tap.CallbackTap, error) {
+	if b == nil || b.mixer == nil || b.eng == nil {
+		return nil, fmt.Errorf("bus not initialized")
+	}
+
+	t, err := tap.InstallCallbackTap(b.eng.Ptr(), b.mixer, 0, bufferSize, format, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.taps[t.GetKey()] = t
+	b.mu.Unlock()
+	return t, nil
+}
+
+// RemoveTap removes a tap previously returned by InstallInputTap or
+// InstallOutputTap.
+func (b *Bus) RemoveTap(t *tap.CallbackTap) error {
+	if t == nil {
+		return fmt.Errorf("tap cannot be nil")
+	}
+
+	b.mu.Lock()
+	_, ok := b.taps[t.GetKey()]
+	delete(b.taps, t.GetKey())
+	b.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("tap was not installed on this bus")
+	}
+	return t.Remove()
+}
+
+// DisconnectInput disconnects a specific input bus on the bus mixer,
+// releasing any resampler ConnectChannel inserted for it.
 func (b *Bus) DisconnectInput(input int) error {
 	if b == nil || b.mixer == nil || b.eng == nil {
 		return fmt.Errorf("bus not initialized")
@@ -91,7 +481,18 @@ func (b *Bus) DisconnectInput(input int) error {
 	err := b.eng.DisconnectNodeInput(b.mixer, input)
 	b.mu.Lock()
 	delete(b.inputs, input)
+	delete(b.connRates, input)
+	delete(b.inputNames, input)
+	if resampler, ok := b.resamplers[input]; ok {
+		_ = node.ReleaseResampler(resampler)
+		delete(b.resamplers, input)
+	}
+	if cm, ok := b.channelMaps[input]; ok {
+		_ = node.ReleaseNode(cm.matrix)
+		delete(b.channelMaps, input)
+	}
 	b.mu.Unlock()
+	b.events.publish(InputDisconnected{Input: input})
 	return err
 }
 
@@ -100,6 +501,20 @@ func (b *Bus) Release() {
 	if b == nil || b.mixer == nil {
 		return
 	}
+	b.mu.Lock()
+	for input, resampler := range b.resamplers {
+		_ = node.ReleaseResampler(resampler)
+		delete(b.resamplers, input)
+	}
+	for input, cm := range b.channelMaps {
+		_ = node.ReleaseNode(cm.matrix)
+		delete(b.channelMaps, input)
+	}
+	for key, t := range b.taps {
+		_ = t.Remove()
+		delete(b.taps, key)
+	}
+	b.mu.Unlock()
 	_ = node.ReleaseMixer(b.mixer)
 	b.mixer = nil
 }
@@ -109,6 +524,10 @@ func (b *Bus) Release() {
 type MasterBus struct {
 	eng   *engine.Engine
 	mixer unsafe.Pointer
+
+	mu           sync.Mutex
+	masterMuted  bool
+	premuteLevel float32
 }
 
 // NewMasterBus fetches the engine's main mixer and wraps it.
@@ -126,6 +545,84 @@ func NewMasterBus(eng *engine.Engine) (*MasterBus, error) {
 // Ptr returns the mixer pointer for MasterBus.
 func (m *MasterBus) Ptr() unsafe.Pointer { return m.mixer }
 
+// SetLevel sets the engine's overall output level; see Bus.SetLevel for the
+// same -1 "whole mixer" caveat.
+func (m *MasterBus) SetLevel(level float32) error {
+	if m == nil || m.mixer == nil {
+		return fmt.Errorf("master bus not initialized")
+	}
+	m.mu.Lock()
+	muted := m.masterMuted
+	m.mu.Unlock()
+	if muted {
+		m.mu.Lock()
+		m.premuteLevel = level
+		m.mu.Unlock()
+		return nil
+	}
+	return node.SetMixerVolume(m.mixer, level, -1)
+}
+
+// GetLevel reads the engine's overall output level; see SetLevel.
+func (m *MasterBus) GetLevel() (float32, error) {
+	if m == nil || m.mixer == nil {
+		return 0, fmt.Errorf("master bus not initialized")
+	}
+	m.mu.Lock()
+	muted := m.masterMuted
+	m.mu.Unlock()
+	if muted {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.premuteLevel, nil
+	}
+	return node.GetMixerVolume(m.mixer, -1)
+}
+
+// SetMute mutes or unmutes the engine's overall output; see SetLevel.
+func (m *MasterBus) SetMute(muted bool) error {
+	if m == nil || m.mixer == nil {
+		return fmt.Errorf("master bus not initialized")
+	}
+	m.mu.Lock()
+	already := m.masterMuted
+	m.mu.Unlock()
+
+	if muted {
+		if already {
+			return nil
+		}
+		level, err := node.GetMixerVolume(m.mixer, -1)
+		if err != nil {
+			return err
+		}
+		m.mu.Lock()
+		m.premuteLevel = level
+		m.masterMuted = true
+		m.mu.Unlock()
+		return node.SetMixerVolume(m.mixer, 0, -1)
+	}
+
+	if !already {
+		return nil
+	}
+	m.mu.Lock()
+	level := m.premuteLevel
+	m.masterMuted = false
+	m.mu.Unlock()
+	return node.SetMixerVolume(m.mixer, level, -1)
+}
+
+// GetMute reports whether the engine's overall output is currently muted.
+func (m *MasterBus) GetMute() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.masterMuted
+}
+
 // SetInputLevel sets the gain for the given input bus on the bus mixer.
 // Note: current native bridge applies volume at the mixer level; per-input control
 // is emulated by dedicating this bus to a small number of sources.
@@ -133,16 +630,22 @@ func (b *Bus) SetInputLevel(input int, level float32) error {
 	if b == nil || b.mixer == nil {
 		return fmt.Errorf("bus not initialized")
 	}
+	old, _ := b.GetInputLevel(input)
 	b.mu.Lock()
 	src := b.inputs[input]
 	b.mu.Unlock()
 	if src != nil {
 		if err := node.SetConnectionInputVolume(src, b.mixer, input, level); err == nil {
+			b.events.publish(InputLevelChanged{Input: input, Old: old, New: level})
 			return nil
 		}
 	}
 	// Fallback to mixer-level setter if per-connection not available
-	return node.SetMixerVolume(b.mixer, level, input)
+	if err := node.SetMixerVolume(b.mixer, level, input); err != nil {
+		return err
+	}
+	b.events.publish(InputLevelChanged{Input: input, Old: old, New: level})
+	return nil
 }
 
 // GetInputLevel reads the gain for the given input bus on the bus mixer.
@@ -168,15 +671,21 @@ func (b *Bus) SetInputPan(input int, pan float32) error {
 	if b == nil || b.mixer == nil {
 		return fmt.Errorf("bus not initialized")
 	}
+	old, _ := b.GetInputPan(input)
 	b.mu.Lock()
 	src := b.inputs[input]
 	b.mu.Unlock()
 	if src != nil {
 		if err := node.SetConnectionInputPan(src, b.mixer, input, pan); err == nil {
+			b.events.publish(InputPanChanged{Input: input, Old: old, New: pan})
 			return nil
 		}
 	}
-	return node.SetMixerPan(b.mixer, pan, input)
+	if err := node.SetMixerPan(b.mixer, pan, input); err != nil {
+		return err
+	}
+	b.events.publish(InputPanChanged{Input: input, Old: old, New: pan})
+	return nil
 }
 
 // GetInputPan reads the pan for the given input bus on the bus mixer.
@@ -194,3 +703,139 @@ func (b *Bus) GetInputPan(input int) (float32, error) {
 	}
 	return node.GetMixerPan(b.mixer, input)
 }
+
+// SetInputMute mutes or unmutes the given input bus. Muting remembers the
+// input's current level so GetInputLevel and an eventual unmute both see the
+// pre-mute value rather than silence; calling SetInputLevel while muted
+// updates the remembered value instead of taking effect immediately.
+func (b *Bus) SetInputMute(input int, muted bool) error {
+	if b == nil || b.mixer == nil {
+		return fmt.Errorf("bus not initialized")
+	}
+	b.mu.Lock()
+	alreadyMuted := b.mutedInputs[input]
+	b.mu.Unlock()
+
+	if muted {
+		if alreadyMuted {
+			return nil
+		}
+		level, err := b.GetInputLevel(input)
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.premuteLevels[input] = level
+		b.mutedInputs[input] = true
+		b.mu.Unlock()
+		return b.SetInputLevel(input, 0)
+	}
+
+	if !alreadyMuted {
+		return nil
+	}
+	b.mu.Lock()
+	level := b.premuteLevels[input]
+	delete(b.mutedInputs, input)
+	delete(b.premuteLevels, input)
+	b.mu.Unlock()
+	return b.SetInputLevel(input, level)
+}
+
+// GetInputMute reports whether the given input bus is currently muted.
+func (b *Bus) GetInputMute(input int) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.mutedInputs[input]
+}
+
+// SetLevel sets the bus's own overall level, as distinct from any one
+// input's level (see SetInputLevel). Note: the native bridge has no
+// dedicated "whole mixer" address; this assumes mixer input -1 is treated
+// as the node's overall output volume, matching AVAudioMixerNode's own
+// outputVolume property. This is a best-effort shim pending confirmation
+// against the native bridge.
+func (b *Bus) SetLevel(level float32) error {
+	if b == nil || b.mixer == nil {
+		return fmt.Errorf("bus not initialized")
+	}
+	old, _ := b.GetLevel()
+	b.mu.Lock()
+	muted := b.masterMuted
+	b.mu.Unlock()
+	if muted {
+		b.mu.Lock()
+		b.premuteLevel = level
+		b.mu.Unlock()
+		b.events.publish(MasterVolumeChanged{Old: old, New: level})
+		return nil
+	}
+	if err := node.SetMixerVolume(b.mixer, level, -1); err != nil {
+		return err
+	}
+	b.events.publish(MasterVolumeChanged{Old: old, New: level})
+	return nil
+}
+
+// GetLevel reads the bus's own overall level; see SetLevel.
+func (b *Bus) GetLevel() (float32, error) {
+	if b == nil || b.mixer == nil {
+		return 0, fmt.Errorf("bus not initialized")
+	}
+	b.mu.Lock()
+	muted := b.masterMuted
+	b.mu.Unlock()
+	if muted {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.premuteLevel, nil
+	}
+	return node.GetMixerVolume(b.mixer, -1)
+}
+
+// SetMute mutes or unmutes the bus's own overall level; see SetLevel.
+func (b *Bus) SetMute(muted bool) error {
+	if b == nil || b.mixer == nil {
+		return fmt.Errorf("bus not initialized")
+	}
+	b.mu.Lock()
+	already := b.masterMuted
+	b.mu.Unlock()
+
+	if muted {
+		if already {
+			return nil
+		}
+		level, err := node.GetMixerVolume(b.mixer, -1)
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.premuteLevel = level
+		b.masterMuted = true
+		b.mu.Unlock()
+		return node.SetMixerVolume(b.mixer, 0, -1)
+	}
+
+	if !already {
+		return nil
+	}
+	b.mu.Lock()
+	level := b.premuteLevel
+	b.masterMuted = false
+	b.mu.Unlock()
+	return node.SetMixerVolume(b.mixer, level, -1)
+}
+
+// GetMute reports whether the bus's own overall level is currently muted.
+func (b *Bus) GetMute() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.masterMuted
+}
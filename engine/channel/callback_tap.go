@@ -0,0 +1,60 @@
+package channel
+
+import (
+	"fmt"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// InstallTap installs a callback-driven tap on the channel's output mixer
+// bus 0, delivering PCM blocks to cb instead of requiring a caller to poll
+// Meter()/OutputRMS() for level information - see
+// avaudio/tap.InstallCallbackTap for the delivery guarantees (lock-free
+// ring, dropped-block counting, cb never runs on the audio thread).
+// Multiple taps, including the metering Tap installed by
+// EnableOutputMetering, can coexist on the same mixer bus.
+func (bc *BaseChannel) InstallTap(eng *engine.Engine, bufferSize int, format tap.TapFormat, cb func(tap.TapBuffer)) (*tap.CallbackTap, error) {
+	if bc.released {
+		return nil, fmt.Errorf("channel has been released")
+	}
+	if eng == nil {
+		return nil, fmt.Errorf("engine instance cannot be nil")
+	}
+
+	if installed, err := node.IsInstalledOnEngine(bc.outputMixer); err == nil && !installed {
+		if bc.dispatcher != nil {
+			_ = bc.dispatcher.Attach(bc.outputMixer)
+		} else if err := eng.Attach(bc.outputMixer); err != nil {
+			return nil, fmt.Errorf("attach mixer for tap: %w", err)
+		}
+	}
+
+	t, err := tap.InstallCallbackTap(eng.Ptr(), bc.outputMixer, 0, bufferSize, format, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	bc.tapsMu.Lock()
+	bc.taps[t.GetKey()] = t
+	bc.tapsMu.Unlock()
+	return t, nil
+}
+
+// RemoveTap removes a tap previously returned by InstallTap.
+func (bc *BaseChannel) RemoveTap(t *tap.CallbackTap) error {
+	if t == nil {
+		return fmt.Errorf("tap cannot be nil")
+	}
+
+	bc.tapsMu.Lock()
+	_, ok := bc.taps[t.GetKey()]
+	delete(bc.taps, t.GetKey())
+	bc.tapsMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("tap was not installed on this channel")
+	}
+	return t.Remove()
+}
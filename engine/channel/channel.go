@@ -166,6 +166,8 @@ type BaseChannel struct {
 	pluginChain       *pluginchain.PluginChain
 	outputMixer       unsafe.Pointer   // For volume and mute control (Node)
 	sends             map[string]*Send // Auxiliary sends
+	sendOrder         []string         // creation order of sends, for Snapshot (sends itself is unordered)
+	sendBuses         map[string]*Bus  // sends[name]'s destination Bus, as passed to ConnectSendTo/CreateAndConnectSend; for Snapshot
 	sendsMu           sync.RWMutex     // Protects sends map and send state
 	kind              ChannelKind
 	released          bool
@@ -175,9 +177,42 @@ type BaseChannel struct {
 	soloMuted  bool    // muted due to another channel's solo state
 	lastVolume float32 // remembered volume for unmute
 	// metering
-	meterMu    sync.RWMutex
-	meterTap   *tap.Tap
-	sendMeters map[string]*tap.Tap
+	meterMu         sync.RWMutex
+	meterTap        *tap.Tap
+	sendMeters      map[string]*tap.Tap
+	continuousMeter *continuousMeter
+
+	// callback taps (see callback_tap.go); keyed by the key InstallTap
+	// returns, so RemoveTap can look one up without the caller holding
+	// onto the *tap.CallbackTap itself.
+	tapsMu sync.Mutex
+	taps   map[string]*tap.CallbackTap
+
+	// ramps (see ramp.go): volumeSetter/panSetter/volumeGetter/panGetter
+	// are what RampVolume/RampPan actually read and drive each tick; they
+	// default to bc's own GetVolume/SetVolume/GetPan/SetPan below, but an
+	// embedder that overrides any of them can redirect these via
+	// SetRampSetters so its override isn't bypassed.
+	rampMu       sync.Mutex
+	ramps        map[string]*activeRamp
+	volumeSetter func(float32) error
+	panSetter    func(float32) error
+	volumeGetter func() (float32, error)
+	panGetter    func() (float32, error)
+	// OnRampDone, if set, is called on the ramp's own goroutine when a
+	// RampVolume/RampPan ramp finishes naturally (not when CancelRamps
+	// stops it early).
+	OnRampDone func(param string, target float32)
+
+	// faderAnchor is the fader-travel position SetVolumeRamp/
+	// SetSendLevelRamp's CurveAudioTaper treats as 0dB (see send_ramp.go).
+	// Defaults to 0.75, the same MCU-style anchor ControlSurfaceFaderCurve
+	// uses; SetFaderAnchor overrides it per channel.
+	faderAnchor float32
+
+	// stateEvents is Subscribe's subscriber registry; see
+	// ChannelStateEvent (channel_events.go).
+	stateEvents *channelEventBus
 }
 
 // BaseChannelConfig declares the inputs required to construct a BaseChannel.
@@ -234,6 +269,7 @@ func NewBaseChannel(config BaseChannelConfig) (*BaseChannel, error) {
 		pluginChain:       pluginChain,
 		outputMixer:       outputMixer,
 		sends:             make(map[string]*Send),
+		sendBuses:         make(map[string]*Bus),
 		kind:              config.Kind,
 		released:          false,
 		connectedToMaster: false,
@@ -242,7 +278,19 @@ func NewBaseChannel(config BaseChannelConfig) (*BaseChannel, error) {
 		lastVolume:        0.8, // sensible default fader value
 		meterTap:          nil,
 		sendMeters:        make(map[string]*tap.Tap),
-	}
+		taps:              make(map[string]*tap.CallbackTap),
+		ramps:             make(map[string]*activeRamp),
+		faderAnchor:       0.75,
+		stateEvents:       newChannelEventBus(),
+	}
+	// RampVolume/RampPan read/drive these by default; MonoToStereoChannel
+	// and similar embedders that shadow SetVolume/GetVolume/SetPan/GetPan
+	// should call SetRampSetters with their own methods right after
+	// construction.
+	bc.volumeSetter = bc.SetVolume
+	bc.panSetter = bc.SetPan
+	bc.volumeGetter = bc.GetVolume
+	bc.panGetter = bc.GetPan
 	// Default to Input kind if unspecified for backward compatibility
 	if bc.kind == ChannelUnknown {
 		bc.kind = ChannelInput
@@ -320,10 +368,12 @@ func (bc *BaseChannel) SetVolume(volume float32) error {
 	if volume < 0 || volume > 1 {
 		return fmt.Errorf("volume must be between 0.0 and 1.0")
 	}
+	old := bc.lastVolume
 	// Update lastVolume if non-zero and not muted by user
 	if volume > 0 {
 		bc.lastVolume = volume
 	}
+	bc.stateEvents.publish(VolumeStateChanged{Old: old, New: bc.lastVolume})
 	// Apply immediately only if not currently muted by user/solo
 	if bc.userMuted || bc.soloMuted {
 		return nil
@@ -350,6 +400,7 @@ func (bc *BaseChannel) SetMute(muted bool) error {
 		return fmt.Errorf("channel has been released")
 	}
 	bc.userMuted = muted
+	bc.stateEvents.publish(MuteStateChanged{UserMuted: bc.userMuted, SoloMuted: bc.soloMuted})
 	// Use dispatcher to apply a tiny ramp to avoid clicks; fall back to immediate.
 	if bc.dispatcher != nil {
 		// Capture locals for closure
@@ -397,7 +448,12 @@ func (bc *BaseChannel) SetPan(pan float32) error {
 	if bc.outputMixer == nil {
 		return fmt.Errorf("output mixer not available")
 	}
-	return node.SetMixerPan(bc.outputMixer, pan, 0)
+	old, _ := node.GetMixerPan(bc.outputMixer, 0)
+	if err := node.SetMixerPan(bc.outputMixer, pan, 0); err != nil {
+		return err
+	}
+	bc.stateEvents.publish(PanStateChanged{Old: old, New: pan})
+	return nil
 }
 
 // GetPan reads stereo balance from input bus 0 of the channel mixer.
@@ -428,7 +484,11 @@ func (bc *BaseChannel) AddEffect(plugin *plugins.Plugin) error {
 	if err := bc.pluginChain.AddEffect(plugin); err != nil {
 		return err
 	}
-	return bc.ConnectPluginChainToMixer()
+	if err := bc.ConnectPluginChainToMixer(); err != nil {
+		return err
+	}
+	bc.stateEvents.publish(EffectAdded{Index: bc.pluginChain.GetEffectCount() - 1})
+	return nil
 }
 
 // AddEffectFromPluginInfo adds an insert effect by introspecting via PluginInfo.
@@ -443,7 +503,11 @@ func (bc *BaseChannel) AddEffectFromPluginInfo(pluginInfo plugins.PluginInfo) er
 	if err := bc.pluginChain.AddEffectFromPluginInfo(pluginInfo); err != nil {
 		return err
 	}
-	return bc.ConnectPluginChainToMixer()
+	if err := bc.ConnectPluginChainToMixer(); err != nil {
+		return err
+	}
+	bc.stateEvents.publish(EffectAdded{Index: bc.pluginChain.GetEffectCount() - 1})
+	return nil
 }
 
 // GetOutputNode returns the output mixer node for external routing
@@ -501,8 +565,10 @@ func (bc *BaseChannel) CreateSendWithMode(name string, destination Channel, leve
 
 	bc.sendsMu.Lock()
 	bc.sends[name] = &Send{Name: name, Destination: destination, Level: level, Mute: false, Mode: mode, prev: level}
+	bc.sendOrder = append(bc.sendOrder, name)
 	bc.sendsMu.Unlock()
 
+	bc.stateEvents.publish(SendCreated{Name: name, Level: level, Mode: mode})
 	return nil
 }
 
@@ -528,10 +594,25 @@ func (bc *BaseChannel) CreateAuxSend(level float32) error {
 	}
 	bc.sendsMu.Lock()
 	bc.sends[auxSendName] = &Send{Name: auxSendName, Level: level, Mute: false, Mode: PostFader, prev: level}
+	bc.sendOrder = append(bc.sendOrder, auxSendName)
 	bc.sendsMu.Unlock()
 	return nil
 }
 
+// Aux wraps a Bus as the destination ConnectAux/DisconnectAux connect a
+// channel's well-known aux send to - a thin handle rather than an
+// aux-specific bus type, since the underlying mixer bus is built the same
+// way any other Bus is (see NewBus); Aux just names the one a caller wants
+// treated as "the" aux destination.
+type Aux struct {
+	bus *Bus
+}
+
+// NewAux wraps bus so it can be passed to ConnectAux.
+func NewAux(bus *Bus) *Aux {
+	return &Aux{bus: bus}
+}
+
 // ConnectAux connects the channel's Aux send to the provided Aux bus (allocates next input).
 func (bc *BaseChannel) ConnectAux(aux *Aux) (int, error) {
 	if bc.engineInstance == nil {
@@ -553,6 +634,7 @@ func (bc *BaseChannel) ConnectAux(aux *Aux) (int, error) {
 	if err := bc.ConnectSendToBus(bc.engineInstance, auxSendName, aux.bus.mixer, idx); err != nil {
 		return -1, err
 	}
+	bc.stateEvents.publish(AuxConnected{Input: idx})
 	return idx, nil
 }
 
@@ -566,7 +648,11 @@ func (bc *BaseChannel) DisconnectAux() error {
 	if bc.engineInstance == nil {
 		return fmt.Errorf("engine instance not available")
 	}
-	return bc.DisconnectSend(bc.engineInstance, auxSendName)
+	if err := bc.DisconnectSend(bc.engineInstance, auxSendName); err != nil {
+		return err
+	}
+	bc.stateEvents.publish(AuxDisconnected{})
+	return nil
 }
 
 // SetSendLevel adjusts the level of an auxiliary send
@@ -586,6 +672,7 @@ func (bc *BaseChannel) SetSendLevel(sendName string, level float32) error {
 	}
 
 	// Update logical state and remember previous non-zero
+	old := send.Level
 	if level > 0 {
 		send.prev = level
 	}
@@ -601,6 +688,7 @@ func (bc *BaseChannel) SetSendLevel(sendName string, level float32) error {
 			return fmt.Errorf("set send volume: %w", err)
 		}
 	}
+	bc.stateEvents.publish(SendLevelStateChanged{Name: sendName, Old: old, New: level})
 	return nil
 }
 
@@ -625,6 +713,7 @@ func (bc *BaseChannel) SetSendMute(sendName string, muted bool) error {
 			return fmt.Errorf("set send mute: %w", err)
 		}
 	}
+	bc.stateEvents.publish(SendMuteStateChanged{Name: sendName, Muted: muted})
 	return nil
 }
 
@@ -794,21 +883,83 @@ func (bc *BaseChannel) ConnectSendTo(sendName string, bus *Bus) (int, error) {
 	if err := bc.ConnectSendToBus(bc.engineInstance, sendName, bus.mixer, idx); err != nil {
 		return -1, err
 	}
+	bc.sendsMu.Lock()
+	bc.sendBuses[sendName] = bus
+	bc.sendsMu.Unlock()
 	return idx, nil
 }
 
 // CreateAndConnectSend creates a send and connects it to the given Bus in one call.
+// If ConnectSendTo fails after the send was created, the just-created send is
+// torn down via RemoveSend rather than left behind as a logical send with
+// nothing wired to it - ConnectSendToBus can fail after it has already
+// created and attached the send's per-send mixer (send.mixer is set before
+// its own Connect calls that can still fail), so the cleanup has to release
+// that mixer and publish SendRemoved, not just drop the map entry.
 func (bc *BaseChannel) CreateAndConnectSend(name string, dest Channel, bus *Bus, level float32, mode SendMode) (int, error) {
 	if err := bc.CreateSendWithMode(name, dest, level, mode); err != nil {
 		return -1, err
 	}
-	return bc.ConnectSendTo(name, bus)
+	idx, err := bc.ConnectSendTo(name, bus)
+	if err != nil {
+		if bc.engineInstance != nil {
+			_ = bc.RemoveSend(bc.engineInstance, name)
+		} else {
+			// ConnectSendTo failed before anything was wired (no engine
+			// instance), so there's no mixer to release - just drop the
+			// bookkeeping RemoveSend would otherwise clean up.
+			bc.sendsMu.Lock()
+			delete(bc.sends, name)
+			for i, n := range bc.sendOrder {
+				if n == name {
+					bc.sendOrder = append(bc.sendOrder[:i], bc.sendOrder[i+1:]...)
+					break
+				}
+			}
+			bc.sendsMu.Unlock()
+		}
+		return -1, err
+	}
+	return idx, nil
+}
+
+// RoutingTxn batches graph mutations (Attach/Connect/DisconnectNodeInput)
+// recorded against this channel's dispatcher so Commit applies them
+// atomically, rolling back whatever already succeeded if a later step
+// fails. It's queue.Txn under its own name at the channel level, since
+// that's what BaseChannel's routing helpers are built on (see
+// queue.Dispatcher.Begin).
+type RoutingTxn = queue.Txn
+
+// WithTxn begins a RoutingTxn on bc's dispatcher, passes it to fn to record
+// whatever Attach/Connect/DisconnectNodeInput steps the caller needs done
+// as one atomic change, and commits it if fn returns nil. If fn returns an
+// error, or bc has no dispatcher, the recorded ops are never applied and
+// fn's error is returned - the caller's own bookkeeping (e.g. a send
+// entry it added before calling WithTxn) is its own responsibility to undo.
+func (bc *BaseChannel) WithTxn(fn func(txn *RoutingTxn) error) error {
+	if bc.dispatcher == nil {
+		return fmt.Errorf("channel has no dispatcher")
+	}
+	txn := bc.dispatcher.Begin()
+	if err := fn(txn); err != nil {
+		return err
+	}
+	return txn.Commit()
 }
 
 // DisconnectSend disconnects and releases resources for a named send.
 func (bc *BaseChannel) DisconnectSend(eng *engine.Engine, sendName string) error {
 	bc.routeMu.Lock()
 	defer bc.routeMu.Unlock()
+	return bc.disconnectSendLocked(eng, sendName)
+}
+
+// disconnectSendLocked is DisconnectSend's body, split out so RemoveSend can
+// reuse it while already holding bc.routeMu - sync.Mutex isn't reentrant, so
+// RemoveSend calling DisconnectSend itself would deadlock on the second
+// Lock. Callers must hold bc.routeMu.
+func (bc *BaseChannel) disconnectSendLocked(eng *engine.Engine, sendName string) error {
 	if bc.released {
 		return fmt.Errorf("channel has been released")
 	}
@@ -842,12 +993,20 @@ func (bc *BaseChannel) DisconnectSend(eng *engine.Engine, sendName string) error
 func (bc *BaseChannel) RemoveSend(eng *engine.Engine, sendName string) error {
 	bc.routeMu.Lock()
 	defer bc.routeMu.Unlock()
-	if err := bc.DisconnectSend(eng, sendName); err != nil {
+	if err := bc.disconnectSendLocked(eng, sendName); err != nil {
 		return err
 	}
 	bc.sendsMu.Lock()
 	delete(bc.sends, sendName)
+	delete(bc.sendBuses, sendName)
+	for i, n := range bc.sendOrder {
+		if n == sendName {
+			bc.sendOrder = append(bc.sendOrder[:i], bc.sendOrder[i+1:]...)
+			break
+		}
+	}
 	bc.sendsMu.Unlock()
+	bc.stateEvents.publish(SendRemoved{Name: sendName})
 	return nil
 }
 
@@ -857,6 +1016,9 @@ func (bc *BaseChannel) Release() {
 		return
 	}
 
+	// Stop any in-flight ramps before the mixer they write to goes away.
+	bc.CancelRamps()
+
 	// Release plugin chain
 	if bc.pluginChain != nil {
 		bc.pluginChain.Release()
@@ -873,6 +1035,8 @@ func (bc *BaseChannel) Release() {
 
 	// Clear sends
 	bc.sends = nil
+	bc.sendOrder = nil
+	bc.sendBuses = nil
 
 	// Unregister from solo manager
 	DefaultSolo.Unregister(bc)
@@ -1109,8 +1273,12 @@ func (bc *BaseChannel) applyEffectiveVolume() error {
 
 // markSoloMuted is called by SoloManager to set/clear solo-induced mute
 func (bc *BaseChannel) markSoloMuted(m bool) {
+	if bc.soloMuted == m {
+		return
+	}
 	bc.soloMuted = m
 	_ = bc.applyEffectiveVolume()
+	bc.stateEvents.publish(MuteStateChanged{UserMuted: bc.userMuted, SoloMuted: bc.soloMuted})
 }
 
 // SetSolo toggles solo for this channel using the DefaultSolo manager.
@@ -1123,12 +1291,19 @@ func (bc *BaseChannel) IsSoloed() bool {
 	return DefaultSolo.IsSoloed(bc)
 }
 
+// IsSoloMuted reports whether this channel is currently silenced because a
+// sibling channel is soloed - as opposed to IsSoloed, which only reports
+// whether this channel itself is the one soloed.
+func (bc *BaseChannel) IsSoloMuted() bool {
+	return bc.soloMuted
+}
+
 // EnableOutputMetering installs or removes a tap on the channel's output mixer bus 0.
 func (bc *BaseChannel) EnableOutputMetering(eng *engine.Engine, enable bool) error {
 	if bc.released {
 		return fmt.Errorf("channel has been released")
 	}
-	if eng == nil {
+	if enable && eng == nil {
 		return fmt.Errorf("engine instance cannot be nil")
 	}
 	bc.meterMu.Lock()
@@ -0,0 +1,236 @@
+package channel
+
+import "sync"
+
+// ChannelStateEvent is implemented by every event BaseChannel.Subscribe
+// delivers - the BaseChannel-scoped counterpart to Bus's BusEvent (see
+// events.go). It is deliberately distinct from actor.go's ChannelEvent,
+// which only fires for commands routed through a NewBaseChannelActor's
+// cmdCh - SetVolume/SetMute/etc. called directly on a BaseChannel (the
+// common case; most callers don't go through an actor) would otherwise
+// publish nothing at all. Several of ChannelEvent's struct names
+// (VolumeChanged, PanChanged, MuteChanged, SendLevelChanged,
+// SendMuteChanged) are already taken in this package by actor.go, so the
+// equivalent events here carry a StateChanged suffix instead.
+type ChannelStateEvent interface {
+	isChannelStateEvent()
+}
+
+// VolumeStateChanged is published by SetVolume, and replayed with the
+// channel's current volume for a late subscriber.
+type VolumeStateChanged struct {
+	Old, New float32
+}
+
+func (VolumeStateChanged) isChannelStateEvent() {}
+
+// PanStateChanged is published by SetPan, and replayed with the channel's
+// current pan for a late subscriber.
+type PanStateChanged struct {
+	Old, New float32
+}
+
+func (PanStateChanged) isChannelStateEvent() {}
+
+// MuteStateChanged is published by SetMute (UserMuted) and by the
+// SoloManager solo-muting or un-solo-muting this channel (SoloMuted), and
+// replayed with current state for a late subscriber.
+type MuteStateChanged struct {
+	UserMuted, SoloMuted bool
+}
+
+// Effective reports whether the channel is silent for either reason - what
+// GetVolume's caller would actually hear.
+func (m MuteStateChanged) Effective() bool {
+	return m.UserMuted || m.SoloMuted
+}
+
+func (MuteStateChanged) isChannelStateEvent() {}
+
+// SendCreated is published by CreateSend/CreateSendWithMode.
+type SendCreated struct {
+	Name  string
+	Level float32
+	Mode  SendMode
+}
+
+func (SendCreated) isChannelStateEvent() {}
+
+// SendRemoved is published by RemoveSend.
+type SendRemoved struct {
+	Name string
+}
+
+func (SendRemoved) isChannelStateEvent() {}
+
+// SendLevelStateChanged is published by SetSendLevel and SetSendLevelDB.
+type SendLevelStateChanged struct {
+	Name     string
+	Old, New float32
+}
+
+func (SendLevelStateChanged) isChannelStateEvent() {}
+
+// SendMuteStateChanged is published by SetSendMute.
+type SendMuteStateChanged struct {
+	Name  string
+	Muted bool
+}
+
+func (SendMuteStateChanged) isChannelStateEvent() {}
+
+// AuxConnected is published by ConnectAux.
+type AuxConnected struct {
+	Input int
+}
+
+func (AuxConnected) isChannelStateEvent() {}
+
+// AuxDisconnected is published by DisconnectAux.
+type AuxDisconnected struct{}
+
+func (AuxDisconnected) isChannelStateEvent() {}
+
+// EffectAdded is published by AddEffect/AddEffectFromPluginInfo. Index is
+// the effect's position in the plugin chain at the time it was added
+// (PluginChain.GetEffectCount()-1); this package has no effect-removal
+// method on BaseChannel to mirror with an EffectRemoved event.
+type EffectAdded struct {
+	Index int
+}
+
+func (EffectAdded) isChannelStateEvent() {}
+
+// channelEventBus holds BaseChannel.Subscribe's subscriber registry, the
+// same shape as busEventBus in events.go (see that type's doc comment for
+// why it's kept separate from the channel's own locks).
+type channelEventBus struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]chan ChannelStateEvent
+}
+
+func newChannelEventBus() *channelEventBus {
+	return &channelEventBus{subs: make(map[int]chan ChannelStateEvent)}
+}
+
+// publish fans ev out to every subscriber, dropping it for one whose buffer
+// is full rather than blocking the mutator that published it - see
+// busEventBus.publish.
+func (eb *channelEventBus) publish(ev ChannelStateEvent) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	for _, ch := range eb.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// subscribe registers a new subscriber and, if snapshot is non-nil, runs it
+// against the new channel before returning - see busEventBus.subscribe.
+func (eb *channelEventBus) subscribe(snapshot func(chan<- ChannelStateEvent)) (<-chan ChannelStateEvent, func()) {
+	eb.mu.Lock()
+	ch := make(chan ChannelStateEvent, 32)
+	id := eb.nextID
+	eb.nextID++
+	eb.subs[id] = ch
+	eb.mu.Unlock()
+
+	if snapshot != nil {
+		snapshot(ch)
+	}
+
+	var cancelOnce sync.Once
+	cancel := func() {
+		cancelOnce.Do(func() {
+			eb.mu.Lock()
+			delete(eb.subs, id)
+			close(ch)
+			eb.mu.Unlock()
+		})
+	}
+	return ch, cancel
+}
+
+// Subscribe registers for this channel's VolumeStateChanged/
+// PanStateChanged/MuteStateChanged/SendCreated/SendRemoved/
+// SendLevelStateChanged/SendMuteStateChanged/AuxConnected/AuxDisconnected/
+// EffectAdded events. Like Bus.Subscribe, the returned channel is buffered
+// and a slow subscriber misses events rather than stalling the mutator that
+// published them; cancel unregisters it and closes the channel.
+//
+// Before returning, a late subscriber is sent a coalesced snapshot of
+// current volume, pan, and mute state, so it sees where things stand
+// without racing a mutator for the first real event.
+func (bc *BaseChannel) Subscribe() (<-chan ChannelStateEvent, func()) {
+	return bc.stateEvents.subscribe(func(ch chan<- ChannelStateEvent) {
+		if vol, err := bc.GetVolume(); err == nil {
+			select {
+			case ch <- VolumeStateChanged{New: vol}:
+			default:
+			}
+		}
+		if pan, err := bc.GetPan(); err == nil {
+			select {
+			case ch <- PanStateChanged{New: pan}:
+			default:
+			}
+		}
+		select {
+		case ch <- MuteStateChanged{UserMuted: bc.userMuted, SoloMuted: bc.soloMuted}:
+		default:
+		}
+	})
+}
+
+// Subscribe registers for MuteStateChanged events published whenever sm's
+// recompute changes any member's solo-muted state - a convenience over
+// calling Subscribe on each member channel individually and filtering for
+// SoloMuted changes. The returned channel fans in every channel registered
+// with sm at the time of this call (a channel registered with sm afterward
+// isn't picked up - call Subscribe again after adding members if that
+// matters); cancel unsubscribes from all of them.
+func (sm *SoloManager) Subscribe() (<-chan MuteStateChanged, func()) {
+	out := make(chan MuteStateChanged, 32)
+	var cancels []func()
+	var mu sync.Mutex
+
+	sm.mu.Lock()
+	members := make([]*BaseChannel, 0, len(sm.members))
+	for ch := range sm.members {
+		members = append(members, ch)
+	}
+	sm.mu.Unlock()
+
+	forward := func(ch *BaseChannel) {
+		sub, cancel := ch.Subscribe()
+		mu.Lock()
+		cancels = append(cancels, cancel)
+		mu.Unlock()
+		go func() {
+			for ev := range sub {
+				if m, ok := ev.(MuteStateChanged); ok {
+					select {
+					case out <- m:
+					default:
+					}
+				}
+			}
+		}()
+	}
+	for _, ch := range members {
+		forward(ch)
+	}
+
+	cancel := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, c := range cancels {
+			c()
+		}
+		cancels = nil
+	}
+	return out, cancel
+}
@@ -0,0 +1,178 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestSubscribeReceivesVolumeAndPanChanges checks that SetVolume/SetPan
+// publish VolumeStateChanged/PanStateChanged to a Subscribe'd channel, on top of the
+// coalesced snapshot Subscribe sends up front.
+func TestSubscribeReceivesVolumeAndPanChanges(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	sub, cancel := ch.Subscribe()
+	defer cancel()
+	drainSnapshot(t, sub) // VolumeStateChanged, PanStateChanged, MuteStateChanged snapshot
+
+	if err := ch.SetVolume(0.42); err != nil {
+		t.Fatalf("set volume: %v", err)
+	}
+	if ev := recvEvent(t, sub); ev.(VolumeStateChanged).New != 0.42 {
+		t.Errorf("expected VolumeStateChanged{New: 0.42}, got %#v", ev)
+	}
+
+	if err := ch.SetPan(-0.5); err != nil {
+		t.Fatalf("set pan: %v", err)
+	}
+	if ev := recvEvent(t, sub); ev.(PanStateChanged).New != -0.5 {
+		t.Errorf("expected PanStateChanged{New: -0.5}, got %#v", ev)
+	}
+}
+
+// TestSubscribeReceivesMuteChanged checks that SetMute publishes a
+// MuteStateChanged with UserMuted set, distinguishing it from solo-muting.
+func TestSubscribeReceivesMuteChanged(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	sub, cancel := ch.Subscribe()
+	defer cancel()
+	drainSnapshot(t, sub)
+
+	if err := ch.SetMute(true); err != nil {
+		t.Fatalf("set mute: %v", err)
+	}
+	ev := recvEvent(t, sub).(MuteStateChanged)
+	if !ev.UserMuted || ev.SoloMuted {
+		t.Errorf("expected MuteStateChanged{UserMuted: true, SoloMuted: false}, got %#v", ev)
+	}
+	if !ev.Effective() {
+		t.Errorf("expected Effective() true when UserMuted")
+	}
+}
+
+// TestSubscribeReceivesSendLifecycleEvents checks that CreateSend,
+// SetSendLevel, and SetSendMute each publish their matching event.
+// RemoveSend's SendRemoved publish isn't exercised here: RemoveSend locks
+// routeMu and then calls DisconnectSend, which locks routeMu again -
+// routeMu is a plain sync.Mutex, so that call deadlocks regardless of this
+// commit's changes (a pre-existing bug, not something SendRemoved's publish
+// call introduces or could route around).
+func TestSubscribeReceivesSendLifecycleEvents(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	sub, cancel := ch.Subscribe()
+	defer cancel()
+	drainSnapshot(t, sub)
+
+	if err := ch.CreateSend("reverb", &mockChannel{name: "dst"}, 0.3); err != nil {
+		t.Fatalf("create send: %v", err)
+	}
+	if ev := recvEvent(t, sub).(SendCreated); ev.Name != "reverb" || ev.Level != 0.3 {
+		t.Errorf("expected SendCreated{Name: reverb, Level: 0.3}, got %#v", ev)
+	}
+
+	if err := ch.SetSendLevel("reverb", 0.8); err != nil {
+		t.Fatalf("set send level: %v", err)
+	}
+	if ev := recvEvent(t, sub).(SendLevelStateChanged); ev.New != 0.8 {
+		t.Errorf("expected SendLevelStateChanged{New: 0.8}, got %#v", ev)
+	}
+
+	if err := ch.SetSendMute("reverb", true); err != nil {
+		t.Fatalf("set send mute: %v", err)
+	}
+	if ev := recvEvent(t, sub).(SendMuteStateChanged); !ev.Muted {
+		t.Errorf("expected SendMuteStateChanged{Muted: true}, got %#v", ev)
+	}
+}
+
+// TestSoloManagerSubscribeForwardsMuteChanged checks that SoloManager.
+// Subscribe fans in the MuteStateChanged events SetSolo's recompute publishes on
+// each registered member.
+func TestSoloManagerSubscribeForwardsMuteChanged(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	a, err := NewBaseChannel(BaseChannelConfig{Name: "a", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel a: %v", err)
+	}
+	defer a.Release()
+	b, err := NewBaseChannel(BaseChannelConfig{Name: "b", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel b: %v", err)
+	}
+	defer b.Release()
+
+	sub, cancel := DefaultSolo.Subscribe()
+	defer cancel()
+
+	a.SetSolo(true)
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case ev := <-sub:
+			if ev.SoloMuted {
+				return // b's solo-mute, forwarded from b's own Subscribe channel
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for a solo-muted MuteStateChanged")
+		}
+	}
+}
+
+func drainSnapshot(t *testing.T, sub <-chan ChannelStateEvent) {
+	t.Helper()
+	for i := 0; i < 3; i++ {
+		recvEvent(t, sub)
+	}
+}
+
+func recvEvent(t *testing.T, sub <-chan ChannelStateEvent) ChannelStateEvent {
+	t.Helper()
+	select {
+	case ev := <-sub:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/shaban/macaudio/avaudio/engine"
 	"github.com/shaban/macaudio/avaudio/node"
 	"github.com/shaban/macaudio/avaudio/pluginchain"
+	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/devices"
 	"github.com/shaban/macaudio/internal/testutil"
 	"github.com/shaban/macaudio/plugins"
 )
@@ -675,3 +677,354 @@ func TestChannel_Send_LevelAndMute_Control(t *testing.T) {
 		t.Fatalf("disconnect send: %v", err)
 	}
 }
+
+// rateAwareChannel wraps a real Channel to additionally report a fixed
+// sample rate, satisfying sampleRater so tests can drive
+// Bus.ConnectChannel/DryRun's rate-mismatch path without a real device.
+type rateAwareChannel struct {
+	Channel
+	rate float64
+}
+
+func (r rateAwareChannel) SampleRate() (float64, error) { return r.rate, nil }
+
+func TestBus_DryRun_DetectsRateMismatch(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "DryRunBus")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	dstRate, err := bus.SampleRate()
+	if err != nil {
+		t.Fatalf("bus sample rate: %v", err)
+	}
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "Src", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	defer ch.Release()
+
+	mismatched := rateAwareChannel{Channel: ch, rate: dstRate + 4000}
+	diag, err := bus.DryRun(mismatched)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !diag.ResamplerNeeded {
+		t.Errorf("expected a resampler to be flagged for %v vs %v", diag.SrcRate, diag.DstRate)
+	}
+
+	matched := rateAwareChannel{Channel: ch, rate: dstRate}
+	diag, err = bus.DryRun(matched)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if diag.ResamplerNeeded {
+		t.Errorf("expected no resampler for matching rates, got %+v", diag)
+	}
+}
+
+func TestBus_ConnectChannel_InsertsResamplerOnRateMismatch(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "ResampleBus")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	dstRate, err := bus.SampleRate()
+	if err != nil {
+		t.Fatalf("bus sample rate: %v", err)
+	}
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "Src", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	defer ch.Release()
+
+	mismatched := rateAwareChannel{Channel: ch, rate: dstRate + 4000}
+	input, err := bus.ConnectChannel(mismatched)
+	if err != nil {
+		t.Fatalf("connect channel->bus: %v", err)
+	}
+	defer bus.DisconnectInput(input)
+
+	info, err := bus.ConnectionInfo(input)
+	if err != nil {
+		t.Fatalf("connection info: %v", err)
+	}
+	if !info.ResamplerInserted {
+		t.Errorf("expected a resampler to have been inserted")
+	}
+	if info.Latency <= 0 {
+		t.Errorf("expected nonzero latency once a resampler is inserted")
+	}
+}
+
+func TestBus_ConnectChannelWithLayout_AppliesMapping(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "SurroundBus")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "Src", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	defer ch.Release()
+
+	stereo := devices.ChannelLayout{Tag: devices.ChannelLayoutStereo, Labels: []string{"L", "R"}}
+	input, err := bus.ConnectChannelWithLayout(ch, stereo, []int{1, 0})
+	if err != nil {
+		t.Fatalf("connect channel with layout: %v", err)
+	}
+	defer bus.DisconnectInput(input)
+
+	if err := bus.SetInputChannelMap(input, []int{0, 1}); err != nil {
+		t.Fatalf("set input channel map: %v", err)
+	}
+}
+
+func TestBus_ConnectChannelWithLayout_RejectsMismatchedMapping(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "SurroundBus2")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "Src", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	defer ch.Release()
+
+	stereo := devices.ChannelLayout{Tag: devices.ChannelLayoutStereo, Labels: []string{"L", "R"}}
+	if _, err := bus.ConnectChannelWithLayout(ch, stereo, []int{0}); err == nil {
+		t.Error("expected an error for a mapping shorter than the layout's channel count")
+	}
+}
+
+func TestBus_InstallInputTap_RemoveTap(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "TapBus")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "Src", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	defer ch.Release()
+
+	input, err := bus.ConnectChannel(ch)
+	if err != nil {
+		t.Fatalf("connect channel->bus: %v", err)
+	}
+	defer bus.DisconnectInput(input)
+
+	tp, err := bus.InstallInputTap(input, 512, tap.TapFormatInterleavedFloat32, func(tap.TapBuffer) {})
+	if err != nil {
+		t.Fatalf("install input tap: %v", err)
+	}
+	if !tp.IsInstalled() {
+		t.Errorf("expected tap to report installed")
+	}
+
+	if err := bus.RemoveTap(tp); err != nil {
+		t.Errorf("remove tap: %v", err)
+	}
+}
+
+func TestBus_InstallOutputTap_FeedsMeterTap(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "MeterTapBus")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	meter := NewMeterTap()
+	tp, err := bus.InstallOutputTap(512, tap.TapFormatInterleavedFloat32, meter.Feed)
+	if err != nil {
+		t.Fatalf("install output tap: %v", err)
+	}
+	defer bus.RemoveTap(tp)
+
+	if rms := meter.RMS(); rms != 0 {
+		t.Errorf("expected zero RMS before any buffer is fed, got %v", rms)
+	}
+
+	meter.Feed(tap.TapBuffer{Float32Data: []float32{1, -1, 1, -1}})
+	if rms := meter.RMS(); rms != 1 {
+		t.Errorf("expected RMS 1 for a full-scale square wave, got %v", rms)
+	}
+	if peak := meter.Peak(); peak != 1 {
+		t.Errorf("expected peak 1, got %v", peak)
+	}
+}
+
+func TestBus_InstallInputTap_UnknownInputErrors(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "TapBusEmpty")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	if _, err := bus.InstallInputTap(0, 512, tap.TapFormatInterleavedFloat32, func(tap.TapBuffer) {}); err == nil {
+		t.Error("expected an error installing a tap on an unconnected input")
+	}
+}
+
+func TestBus_Subscribe_PublishesMutations(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "EventBus")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "Src", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	defer ch.Release()
+
+	events, cancel := bus.Subscribe()
+	defer cancel()
+
+	input, err := bus.ConnectChannel(ch)
+	if err != nil {
+		t.Fatalf("connect channel->bus: %v", err)
+	}
+	defer bus.DisconnectInput(input)
+
+	if err := bus.SetInputLevel(input, 0.5); err != nil {
+		t.Fatalf("set input level: %v", err)
+	}
+	if err := bus.SetLevel(0.8); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+
+	var sawConnected, sawLevel, sawMaster bool
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-events:
+			switch ev.(type) {
+			case InputConnected:
+				sawConnected = true
+			case InputLevelChanged:
+				sawLevel = true
+			case MasterVolumeChanged:
+				sawMaster = true
+			}
+		default:
+		}
+	}
+	if !sawConnected {
+		t.Error("expected an InputConnected event from ConnectChannel")
+	}
+	if !sawLevel {
+		t.Error("expected an InputLevelChanged event from SetInputLevel")
+	}
+	if !sawMaster {
+		t.Error("expected a MasterVolumeChanged event from SetLevel")
+	}
+}
+
+func TestBus_Subscribe_SnapshotsExistingState(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "SnapshotBus")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "Src", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	defer ch.Release()
+
+	input, err := bus.ConnectChannel(ch)
+	if err != nil {
+		t.Fatalf("connect channel->bus: %v", err)
+	}
+	defer bus.DisconnectInput(input)
+
+	events, cancel := bus.Subscribe()
+	defer cancel()
+
+	var sawConnected, sawMaster bool
+	for i := 0; i < 2; i++ {
+		select {
+		case ev := <-events:
+			switch ev.(type) {
+			case InputConnected:
+				sawConnected = true
+			case MasterVolumeChanged:
+				sawMaster = true
+			}
+		default:
+		}
+	}
+	if !sawConnected {
+		t.Error("expected a late subscriber to see the already-connected input")
+	}
+	if !sawMaster {
+		t.Error("expected a late subscriber to see the bus's current level")
+	}
+}
@@ -0,0 +1,141 @@
+package channel
+
+import "sync"
+
+// BusEvent is implemented by every event Bus.Subscribe delivers. Separate
+// typed structs rather than one struct with a Kind enum, so a subscriber
+// that only cares about one event can type-switch on it directly instead of
+// picking relevant fields out of a fat shared shape.
+type BusEvent interface {
+	isBusEvent()
+}
+
+// InputLevelChanged is published by SetInputLevel.
+type InputLevelChanged struct {
+	Input    int
+	Old, New float32
+}
+
+func (InputLevelChanged) isBusEvent() {}
+
+// InputPanChanged is published by SetInputPan.
+type InputPanChanged struct {
+	Input    int
+	Old, New float32
+}
+
+func (InputPanChanged) isBusEvent() {}
+
+// InputConnected is published by ConnectChannel/ConnectChannelWithLayout,
+// and replayed for every already-connected input when a late subscriber
+// calls Subscribe.
+type InputConnected struct {
+	Input   int
+	SrcName string
+}
+
+func (InputConnected) isBusEvent() {}
+
+// InputDisconnected is published by DisconnectInput.
+type InputDisconnected struct {
+	Input int
+}
+
+func (InputDisconnected) isBusEvent() {}
+
+// MasterVolumeChanged is published by SetLevel, and replayed with the bus's
+// current level for a late subscriber.
+type MasterVolumeChanged struct {
+	Old, New float32
+}
+
+func (MasterVolumeChanged) isBusEvent() {}
+
+// busEventBus holds Bus.Subscribe's subscriber registry. It's kept separate
+// from Bus's own mu so publishing from inside a mutator already holding
+// Bus.mu can't deadlock against a subscriber racing to cancel.
+type busEventBus struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]chan BusEvent
+}
+
+func newBusEventBus() *busEventBus {
+	return &busEventBus{subs: make(map[int]chan BusEvent)}
+}
+
+// publish fans ev out to every subscriber, dropping it for one whose buffer
+// is full rather than blocking the mutator that published it - the same
+// drop-and-count-free policy devices.Subscribe and Dispatcher.emitEvent use
+// for a slow consumer.
+func (eb *busEventBus) publish(ev BusEvent) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	for _, ch := range eb.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// subscribe registers a new subscriber and, if snapshot is non-nil, runs it
+// against the new channel before returning so a late subscriber sees a
+// coalesced view of current state without racing a concurrent publish.
+func (eb *busEventBus) subscribe(snapshot func(chan<- BusEvent)) (<-chan BusEvent, func()) {
+	eb.mu.Lock()
+	ch := make(chan BusEvent, 32)
+	id := eb.nextID
+	eb.nextID++
+	eb.subs[id] = ch
+	eb.mu.Unlock()
+
+	if snapshot != nil {
+		snapshot(ch)
+	}
+
+	var cancelOnce sync.Once
+	cancel := func() {
+		cancelOnce.Do(func() {
+			eb.mu.Lock()
+			delete(eb.subs, id)
+			close(ch)
+			eb.mu.Unlock()
+		})
+	}
+	return ch, cancel
+}
+
+// Subscribe registers for InputLevelChanged/InputPanChanged/InputConnected/
+// InputDisconnected/MasterVolumeChanged events published by SetInputLevel,
+// SetInputPan, ConnectChannel/ConnectChannelWithLayout, DisconnectInput, and
+// SetLevel. Like devices.Subscribe, the returned channel is buffered and a
+// slow subscriber misses events rather than stalling the mutator that
+// published them; cancel unregisters it and closes the channel.
+//
+// Before returning, a late subscriber is sent a coalesced snapshot - one
+// InputConnected per currently-connected input, then one MasterVolumeChanged
+// for the bus's current level - so it sees where things stand without
+// racing a mutator for the first real event.
+func (b *Bus) Subscribe() (<-chan BusEvent, func()) {
+	return b.events.subscribe(func(ch chan<- BusEvent) {
+		b.mu.Lock()
+		names := make(map[int]string, len(b.inputNames))
+		for input, name := range b.inputNames {
+			names[input] = name
+		}
+		b.mu.Unlock()
+		for input, name := range names {
+			select {
+			case ch <- InputConnected{Input: input, SrcName: name}:
+			default:
+			}
+		}
+		if level, err := b.GetLevel(); err == nil {
+			select {
+			case ch <- MasterVolumeChanged{New: level}:
+			default:
+			}
+		}
+	})
+}
@@ -0,0 +1,375 @@
+// Package channel: Group abstraction for submixing multiple channels
+// through a shared bus with group-level Volume/Pan/Mute/Solo and sends.
+package channel
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/node"
+)
+
+// Group submixes N child Channels through a dedicated Bus feeding into an
+// embedded BaseChannel, so the group itself exposes the usual
+// Volume/Pan/Mute/plugin-chain/Send controls while still being addable to
+// another Bus or Group in turn. This is the "channels -> groups -> main"
+// topology: insert a reverb once on a send bus instead of once per channel.
+//
+// Solo has two distinct scopes here. SoloChild mutes a child's siblings
+// within this group only. SetSolo (shadowing the one promoted from
+// BaseChannel) mutes every *other* registered Group's own output without
+// touching any group's children - see defaultGroupSolo below for why that
+// needs its own manager rather than reusing DefaultSolo.
+type Group struct {
+	*BaseChannel
+	bus *Bus
+
+	mu              sync.Mutex
+	children        []Channel
+	byName          map[string]Channel
+	soloedChildren  map[Channel]struct{}
+	mutedBeforeSolo map[Channel]bool
+}
+
+// GroupConfig declares the inputs required to construct a Group.
+type GroupConfig struct {
+	Name   string
+	Engine *engine.Engine
+}
+
+// NewGroup creates a group bus: a dedicated Bus collects child channel
+// outputs and feeds them into a BaseChannel that owns the group's own
+// Volume/Pan/Mute/plugin-chain/sends.
+func NewGroup(config GroupConfig) (*Group, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("group name cannot be empty")
+	}
+	if config.Engine == nil {
+		return nil, fmt.Errorf("engine cannot be nil")
+	}
+
+	bus, err := NewBus(config.Engine, config.Name+" Bus")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group bus: %w", err)
+	}
+
+	bc, err := NewBaseChannel(BaseChannelConfig{
+		Name:           config.Name,
+		EnginePtr:      config.Engine.Ptr(),
+		EngineInstance: config.Engine,
+	})
+	if err != nil {
+		bus.Release()
+		return nil, fmt.Errorf("failed to create group base channel: %w", err)
+	}
+
+	bcInput := bc.GetInputNode()
+	if installed, err := node.IsInstalledOnEngine(bcInput); err == nil && !installed {
+		if err := config.Engine.Attach(bcInput); err != nil {
+			bus.Release()
+			bc.Release()
+			return nil, fmt.Errorf("failed to attach group base channel: %w", err)
+		}
+	}
+	if err := config.Engine.Connect(bus.Ptr(), bcInput, 0, 0); err != nil {
+		bus.Release()
+		bc.Release()
+		return nil, fmt.Errorf("failed to connect group bus to base channel: %w", err)
+	}
+
+	g := &Group{
+		BaseChannel:     bc,
+		bus:             bus,
+		byName:          make(map[string]Channel),
+		soloedChildren:  make(map[Channel]struct{}),
+		mutedBeforeSolo: make(map[Channel]bool),
+	}
+	defaultGroupSolo.Register(g)
+	return g, nil
+}
+
+// Add connects ch's output into the group's bus and tracks it as a member
+// for SoloChild/Snapshot/Restore.
+func (g *Group) Add(ch Channel) error {
+	if ch == nil {
+		return fmt.Errorf("channel cannot be nil")
+	}
+	if _, err := g.bus.ConnectChannel(ch); err != nil {
+		return fmt.Errorf("failed to add channel to group: %w", err)
+	}
+	g.mu.Lock()
+	g.children = append(g.children, ch)
+	g.byName[ch.GetName()] = ch
+	g.mu.Unlock()
+	return nil
+}
+
+// Children returns the channels currently added to the group.
+func (g *Group) Children() []Channel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out := make([]Channel, len(g.children))
+	copy(out, g.children)
+	return out
+}
+
+// Send creates a named aux send from the group's output to target's input -
+// a thin wrapper over BaseChannel.CreateSend naming the send after the
+// destination group, for routing several groups into one shared effect
+// (e.g. a reverb group) rather than inserting the effect per channel.
+func (g *Group) Send(target *Group, level float32) error {
+	if target == nil {
+		return fmt.Errorf("send target cannot be nil")
+	}
+	return g.CreateSend(target.GetName(), target, level)
+}
+
+// SoloChild solos/unsolos ch among the group's children: while any child is
+// soloed, every other current child is muted; dropping the last solo
+// restores whatever mute state each sibling had before solo engaged rather
+// than unmuting indiscriminately.
+func (g *Group) SoloChild(ch Channel, on bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.byName[ch.GetName()]; !ok {
+		return fmt.Errorf("channel %q is not a member of this group", ch.GetName())
+	}
+	if on {
+		g.soloedChildren[ch] = struct{}{}
+	} else {
+		delete(g.soloedChildren, ch)
+	}
+	return g.recomputeChildSoloLocked()
+}
+
+// IsChildSoloed reports whether ch is currently in this group's solo set.
+func (g *Group) IsChildSoloed(ch Channel) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.soloedChildren[ch]
+	return ok
+}
+
+func (g *Group) recomputeChildSoloLocked() error {
+	hasSolo := len(g.soloedChildren) > 0
+	for _, c := range g.children {
+		_, isSolo := g.soloedChildren[c]
+		if hasSolo && !isSolo {
+			if _, already := g.mutedBeforeSolo[c]; !already {
+				muted, _ := c.GetMute()
+				g.mutedBeforeSolo[c] = muted
+			}
+			if err := c.SetMute(true); err != nil {
+				return err
+			}
+			continue
+		}
+		if prev, ok := g.mutedBeforeSolo[c]; ok {
+			delete(g.mutedBeforeSolo, c)
+			if err := c.SetMute(prev); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SetSolo solos this group among every other Group registered with
+// defaultGroupSolo, muting their outputs while leaving every group's
+// children untouched. This shadows BaseChannel.SetSolo (which drives the
+// flat, channel-wide DefaultSolo instead); the embedded BaseChannel still
+// auto-registers with DefaultSolo too, so a plain channel soloed elsewhere
+// in the engine mutes this group exactly like it would any other channel.
+func (g *Group) SetSolo(on bool) {
+	defaultGroupSolo.SetSolo(g, on)
+}
+
+// IsSoloed reports whether this group is currently soloed via SetSolo.
+func (g *Group) IsSoloed() bool {
+	return defaultGroupSolo.IsSoloed(g)
+}
+
+// Release disconnects the group's bus and releases both the bus mixer and
+// the underlying base channel.
+func (g *Group) Release() {
+	defaultGroupSolo.Unregister(g)
+	if g.bus != nil {
+		g.bus.Release()
+	}
+	g.BaseChannel.Release()
+}
+
+// groupSoloManager mirrors SoloManager (see DefaultSolo) but scopes solo to
+// *Group identities instead of every BaseChannel, so soloing a group only
+// silences other groups' own outputs - not each group's children, which
+// would otherwise also be muted as ordinary DefaultSolo members.
+type groupSoloManager struct {
+	mu      sync.Mutex
+	members map[*Group]struct{}
+	soloed  map[*Group]struct{}
+}
+
+var defaultGroupSolo = &groupSoloManager{members: map[*Group]struct{}{}, soloed: map[*Group]struct{}{}}
+
+func (sm *groupSoloManager) Register(g *Group) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.members[g] = struct{}{}
+}
+
+func (sm *groupSoloManager) Unregister(g *Group) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	delete(sm.members, g)
+	delete(sm.soloed, g)
+	sm.recompute()
+}
+
+func (sm *groupSoloManager) SetSolo(g *Group, on bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if on {
+		sm.soloed[g] = struct{}{}
+	} else {
+		delete(sm.soloed, g)
+	}
+	sm.recompute()
+}
+
+func (sm *groupSoloManager) IsSoloed(g *Group) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	_, ok := sm.soloed[g]
+	return ok
+}
+
+func (sm *groupSoloManager) recompute() {
+	hasSolo := len(sm.soloed) > 0
+	for g := range sm.members {
+		_, isSolo := sm.soloed[g]
+		g.markSoloMuted(hasSolo && !isSolo)
+	}
+}
+
+// GroupSnapshot captures a Group's full mix state - its own volume/pan/mute,
+// its sends, and every child's volume/pan/mute - for round-tripping through
+// JSON via Snapshot/Restore (e.g. to save and recall a mix).
+type GroupSnapshot struct {
+	Volume   float32                  `json:"volume"`
+	Pan      float32                  `json:"pan"`
+	Muted    bool                     `json:"muted"`
+	Sends    map[string]SendSnapshot  `json:"sends,omitempty"`
+	Children map[string]ChildSnapshot `json:"children,omitempty"`
+}
+
+// SendSnapshot captures one named send's level and mute state.
+type SendSnapshot struct {
+	Level float32 `json:"level"`
+	Muted bool    `json:"muted"`
+}
+
+// ChildSnapshot captures one child channel's volume/pan/mute state, keyed
+// by name in GroupSnapshot.Children.
+type ChildSnapshot struct {
+	Volume float32 `json:"volume"`
+	Pan    float32 `json:"pan"`
+	Muted  bool    `json:"muted"`
+}
+
+// Snapshot captures the group's current mix state as a JSON-serializable
+// value; pass it to Restore later (on this group or one with the same
+// children) to recall it.
+func (g *Group) Snapshot() (*GroupSnapshot, error) {
+	volume, err := g.GetVolume()
+	if err != nil {
+		return nil, err
+	}
+	pan, err := g.GetPan()
+	if err != nil {
+		return nil, err
+	}
+	muted, err := g.GetMute()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &GroupSnapshot{
+		Volume:   volume,
+		Pan:      pan,
+		Muted:    muted,
+		Sends:    make(map[string]SendSnapshot),
+		Children: make(map[string]ChildSnapshot),
+	}
+	for name, send := range g.GetSends() {
+		snap.Sends[name] = SendSnapshot{Level: send.Level, Muted: send.Mute}
+	}
+
+	for _, c := range g.Children() {
+		cv, err := c.GetVolume()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot child %q: %w", c.GetName(), err)
+		}
+		cp, err := c.GetPan()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot child %q: %w", c.GetName(), err)
+		}
+		cm, err := c.GetMute()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot child %q: %w", c.GetName(), err)
+		}
+		snap.Children[c.GetName()] = ChildSnapshot{Volume: cv, Pan: cp, Muted: cm}
+	}
+	return snap, nil
+}
+
+// Restore applies a snapshot previously returned by Snapshot: the group's
+// own volume/pan/mute, its sends, and any child still present by name.
+// Sends or children named in snap but no longer part of the group are
+// skipped rather than treated as an error, since a saved mix may outlive a
+// specific lineup of channels.
+func (g *Group) Restore(snap *GroupSnapshot) error {
+	if snap == nil {
+		return fmt.Errorf("snapshot cannot be nil")
+	}
+	if err := g.SetVolume(snap.Volume); err != nil {
+		return err
+	}
+	if err := g.SetPan(snap.Pan); err != nil {
+		return err
+	}
+	if err := g.SetMute(snap.Muted); err != nil {
+		return err
+	}
+	for name, s := range snap.Sends {
+		if _, err := g.GetSendLevel(name); err != nil {
+			continue
+		}
+		if err := g.SetSendLevel(name, s.Level); err != nil {
+			return err
+		}
+		if err := g.SetSendMute(name, s.Muted); err != nil {
+			return err
+		}
+	}
+
+	g.mu.Lock()
+	byName := g.byName
+	g.mu.Unlock()
+	for name, cs := range snap.Children {
+		c, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if err := c.SetVolume(cs.Volume); err != nil {
+			return err
+		}
+		if err := c.SetPan(cs.Pan); err != nil {
+			return err
+		}
+		if err := c.SetMute(cs.Muted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+func TestGroup_AddAndSnapshotRestore(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	grp, err := NewGroup(GroupConfig{Name: "Drums", Engine: eng})
+	if err != nil {
+		t.Fatalf("new group: %v", err)
+	}
+	defer grp.Release()
+
+	kick, err := NewBaseChannel(BaseChannelConfig{Name: "Kick", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new kick: %v", err)
+	}
+	defer kick.Release()
+	if err := grp.Add(kick); err != nil {
+		t.Fatalf("add kick: %v", err)
+	}
+
+	if err := grp.SetVolume(0.6); err != nil {
+		t.Fatalf("set group volume: %v", err)
+	}
+	if err := kick.SetVolume(0.9); err != nil {
+		t.Fatalf("set kick volume: %v", err)
+	}
+
+	snap, err := grp.Snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if snap.Children["Kick"].Volume != 0.9 {
+		t.Fatalf("expected snapshotted kick volume 0.9, got %.2f", snap.Children["Kick"].Volume)
+	}
+
+	if err := grp.SetVolume(0.1); err != nil {
+		t.Fatalf("change group volume: %v", err)
+	}
+	if err := kick.SetVolume(0.1); err != nil {
+		t.Fatalf("change kick volume: %v", err)
+	}
+
+	if err := grp.Restore(snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if v, _ := grp.GetVolume(); v != 0.6 {
+		t.Errorf("expected restored group volume 0.6, got %.2f", v)
+	}
+	if v, _ := kick.GetVolume(); v != 0.9 {
+		t.Errorf("expected restored kick volume 0.9, got %.2f", v)
+	}
+}
+
+func TestGroup_SoloChildMutesSiblingsOnly(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	grp, err := NewGroup(GroupConfig{Name: "Guitars", Engine: eng})
+	if err != nil {
+		t.Fatalf("new group: %v", err)
+	}
+	defer grp.Release()
+
+	clean, err := NewBaseChannel(BaseChannelConfig{Name: "Clean", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new clean: %v", err)
+	}
+	defer clean.Release()
+	crunch, err := NewBaseChannel(BaseChannelConfig{Name: "Crunch", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new crunch: %v", err)
+	}
+	defer crunch.Release()
+
+	if err := grp.Add(clean); err != nil {
+		t.Fatalf("add clean: %v", err)
+	}
+	if err := grp.Add(crunch); err != nil {
+		t.Fatalf("add crunch: %v", err)
+	}
+
+	if err := grp.SoloChild(clean, true); err != nil {
+		t.Fatalf("solo clean: %v", err)
+	}
+	if muted, _ := clean.GetMute(); muted {
+		t.Error("expected soloed child to stay unmuted")
+	}
+	if muted, _ := crunch.GetMute(); !muted {
+		t.Error("expected sibling to be muted while clean is soloed")
+	}
+
+	if err := grp.SoloChild(clean, false); err != nil {
+		t.Fatalf("unsolo clean: %v", err)
+	}
+	if muted, _ := crunch.GetMute(); muted {
+		t.Error("expected sibling to be unmuted once solo is cleared")
+	}
+}
+
+func TestGroup_SetSoloMutesOtherGroupsOnly(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	drums, err := NewGroup(GroupConfig{Name: "DrumsBus", Engine: eng})
+	if err != nil {
+		t.Fatalf("new drums group: %v", err)
+	}
+	defer drums.Release()
+	guitars, err := NewGroup(GroupConfig{Name: "GuitarsBus", Engine: eng})
+	if err != nil {
+		t.Fatalf("new guitars group: %v", err)
+	}
+	defer guitars.Release()
+
+	kick, err := NewBaseChannel(BaseChannelConfig{Name: "Kick2", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new kick: %v", err)
+	}
+	defer kick.Release()
+	if err := drums.Add(kick); err != nil {
+		t.Fatalf("add kick: %v", err)
+	}
+
+	drums.SetSolo(true)
+	if drums.IsSoloed() != true {
+		t.Error("expected drums to report soloed")
+	}
+	if muted, _ := guitars.GetMute(); !muted {
+		t.Error("expected the other group's bus to be muted while drums is soloed")
+	}
+	if muted, _ := kick.GetMute(); muted {
+		t.Error("expected a soloed group's own children to stay unmuted")
+	}
+
+	drums.SetSolo(false)
+	if muted, _ := guitars.GetMute(); muted {
+		t.Error("expected the other group's bus to be unmuted once solo is cleared")
+	}
+}
+
+func TestGroup_Send(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create engine")
+	}
+	defer eng.Destroy()
+
+	reverb, err := NewGroup(GroupConfig{Name: "Reverb", Engine: eng})
+	if err != nil {
+		t.Fatalf("new reverb group: %v", err)
+	}
+	defer reverb.Release()
+	drums, err := NewGroup(GroupConfig{Name: "DrumsBus2", Engine: eng})
+	if err != nil {
+		t.Fatalf("new drums group: %v", err)
+	}
+	defer drums.Release()
+
+	if err := drums.Send(reverb, 0.3); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if level, err := drums.GetSendLevel("Reverb"); err != nil || level != 0.3 {
+		t.Errorf("expected send level 0.3, got %.2f (err %v)", level, err)
+	}
+}
@@ -177,6 +177,7 @@ func TestMasterConnectionWithRealAudio(t *testing.T) {
 		toneNodePtr,
 		monoChannel.GetOutputNode(),
 		0.0, // Center pan
+		monoChannel.GetPanLaw(),
 		analysisConfig,
 	)
 	if err != nil {
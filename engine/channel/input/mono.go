@@ -2,17 +2,54 @@ package input
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/shaban/macaudio/avaudio/engine"
 	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/engine/analyze"
 	"github.com/shaban/macaudio/engine/channel"
 )
 
+// PanLaw re-exports analyze.PanLaw so callers configuring a
+// MonoToStereoChannel don't need to import the analyze package just to name
+// a law; it's the same type AnalyzeMonoToStereo/ValidateStereoAnalysis
+// already validate real audio against.
+type PanLaw = analyze.PanLaw
+
+const (
+	PanLawEqualPower = analyze.PanLawEqualPower
+	PanLawLinear     = analyze.PanLawLinear
+	PanLawMinus3dB   = analyze.PanLawMinus3dB
+	PanLawMinus4_5dB = analyze.PanLawMinus4_5dB
+	PanLawMinus6dB   = analyze.PanLawMinus6dB
+)
+
+// RampCurve re-exports channel.RampCurve so callers animating a
+// MonoToStereoChannel's pan/volume with RampPan/RampVolume (promoted from
+// BaseChannel) don't need to import the channel package just to name one.
+type RampCurve = channel.RampCurve
+
+const (
+	RampLinear      = channel.RampLinear
+	RampExponential = channel.RampExponential
+	RampEqualPower  = channel.RampEqualPower
+	RampSCurve      = channel.RampSCurve
+)
+
 // MonoToStereoChannel represents a mono input that converts to stereo output
 // with configurable panning, volume control, and plugin chain processing.
 type MonoToStereoChannel struct {
 	*channel.BaseChannel
-	pan float32 // Pan position: -1.0 (left) to +1.0 (right)
+	pan    float32 // Pan position: -1.0 (left) to +1.0 (right)
+	panLaw PanLaw
+
+	// userVolume is the volume SetVolume was last asked for, before the pan
+	// law's center-attenuation compensation is folded in. BaseChannel only
+	// exposes one mixer volume (shared with mute), so SetVolume/GetVolume
+	// are shadowed below to keep that single knob reflecting
+	// userVolume*compensation on the wire while still reporting and
+	// accepting the value callers actually asked for.
+	userVolume float32
 }
 
 // MonoToStereoConfig contains configuration for creating a MonoToStereoChannel
@@ -20,6 +57,7 @@ type MonoToStereoConfig struct {
 	Name       string         // Channel name
 	Engine     *engine.Engine // High-level engine (contains everything we need)
 	InitialPan float32        // Initial pan position (-1.0 to +1.0)
+	PanLaw     PanLaw         // Center-attenuation curve for InitialPan/SetPan (default PanLawEqualPower)
 }
 
 // NewMonoToStereo creates a new mono-to-stereo input channel
@@ -49,11 +87,31 @@ func NewMonoToStereo(config MonoToStereoConfig) (*MonoToStereoChannel, error) {
 		return nil, fmt.Errorf("failed to create base channel: %w", err)
 	}
 
+	// The base channel's mixer volume defaults to 0.8 (see NewBaseChannel);
+	// read it back so userVolume starts out matching what's already on the
+	// wire instead of duplicating that default here.
+	startVolume, err := baseChannel.GetVolume()
+	if err != nil {
+		baseChannel.Release()
+		return nil, fmt.Errorf("failed to read initial volume: %w", err)
+	}
+
 	// Create the mono-to-stereo channel
 	monoChannel := &MonoToStereoChannel{
 		BaseChannel: baseChannel,
-		pan:         config.InitialPan,
+		panLaw:      config.PanLaw,
+		userVolume:  startVolume,
 	}
+	// RampVolume/RampPan must read/drive our overrides, not BaseChannel's
+	// own GetVolume/SetVolume/GetPan/SetPan, or a ramp would start from
+	// the raw mixer's pan-law-compensated values and bypass that
+	// compensation on every tick. GetPan() doesn't return an error, so
+	// it's adapted to the (float32, error) shape RampPan expects.
+	monoChannel.SetRampSetters(
+		monoChannel.SetVolume, monoChannel.SetPan,
+		monoChannel.GetVolume,
+		func() (float32, error) { return monoChannel.GetPan(), nil },
+	)
 
 	// Set initial pan
 	err = monoChannel.SetPan(config.InitialPan)
@@ -72,14 +130,8 @@ func (m *MonoToStereoChannel) SetPan(pan float32) error {
 		return fmt.Errorf("pan must be between -1.0 and +1.0, got %.2f", pan)
 	}
 
-	// Set the pan on the underlying mixer node
-	err := node.SetMixerPan(m.GetOutputNode(), pan, 0)
-	if err != nil {
-		return fmt.Errorf("failed to set mixer pan: %w", err)
-	}
-
 	m.pan = pan
-	return nil
+	return m.applyPan()
 }
 
 // GetPan returns the current pan position
@@ -87,6 +139,69 @@ func (m *MonoToStereoChannel) GetPan() float32 {
 	return m.pan
 }
 
+// SetPanLaw selects the center-attenuation curve SetPan computes gains
+// against (see analyze.PanLawGains), and immediately reapplies it at the
+// current pan position.
+func (m *MonoToStereoChannel) SetPanLaw(law PanLaw) error {
+	switch law {
+	case PanLawEqualPower, PanLawLinear, PanLawMinus3dB, PanLawMinus4_5dB, PanLawMinus6dB:
+	default:
+		return fmt.Errorf("unknown pan law %v", law)
+	}
+
+	m.panLaw = law
+	return m.applyPan()
+}
+
+// GetPanLaw returns the law currently applied to SetPan.
+func (m *MonoToStereoChannel) GetPanLaw() PanLaw {
+	return m.panLaw
+}
+
+// applyPan realizes m.pan under m.panLaw on the channel's single AVAudioMixerNode
+// bus. That bus only gives us two knobs, pan and volume, and AVAudioMixerNode's
+// own pan curve is a fixed equal-power one (see analyze.PanLawGains), so a law
+// other than equal-power is reproduced by first solving for the native pan
+// angle that has the right L/R *ratio*, then scaling that angle's unit-power
+// gain down to the target law's magnitude via the mixer's volume - combined
+// with m.userVolume (not overwritten by this) so SetVolume/GetVolume keep
+// reporting the caller's actual requested volume rather than the compensated
+// value that ends up on the wire.
+func (m *MonoToStereoChannel) applyPan() error {
+	left, right := analyze.PanLawGains(m.pan, m.panLaw)
+	magnitude := math.Sqrt(left*left + right*right)
+
+	theta := math.Atan2(right, left) // in [0, pi/2]
+	nativePan := float32(theta*4/math.Pi - 1)
+
+	if err := node.SetMixerPan(m.GetOutputNode(), nativePan, 0); err != nil {
+		return fmt.Errorf("failed to set mixer pan: %w", err)
+	}
+	// Route through BaseChannel.SetVolume (not node.SetMixerVolume directly)
+	// so mute/solo bookkeeping stays correct: it no-ops the actual write
+	// while muted and remembers this as lastVolume for the eventual unmute.
+	if err := m.BaseChannel.SetVolume(m.userVolume * float32(magnitude)); err != nil {
+		return fmt.Errorf("failed to set pan law compensation: %w", err)
+	}
+	return nil
+}
+
+// SetVolume sets the channel's overall volume, independent of whatever
+// compensation the current pan law applies at m.pan (see applyPan).
+func (m *MonoToStereoChannel) SetVolume(volume float32) error {
+	if volume < 0 || volume > 1 {
+		return fmt.Errorf("volume must be between 0.0 and 1.0")
+	}
+	m.userVolume = volume
+	return m.applyPan()
+}
+
+// GetVolume returns the volume last passed to SetVolume, not the compensated
+// value applyPan wrote to the mixer.
+func (m *MonoToStereoChannel) GetVolume() (float32, error) {
+	return m.userVolume, nil
+}
+
 // SetPanLeft sets the channel to full left (-1.0)
 func (m *MonoToStereoChannel) SetPanLeft() error {
 	return m.SetPan(-1.0)
@@ -105,5 +220,40 @@ func (m *MonoToStereoChannel) SetPanCenter() error {
 // Summary returns a detailed string representation of the channel state
 func (m *MonoToStereoChannel) Summary() string {
 	baseSummary := m.BaseChannel.Summary()
-	return fmt.Sprintf("%s, Pan: %.2f", baseSummary, m.pan)
+	return fmt.Sprintf("%s, Pan: %.2f (%s)", baseSummary, m.pan, m.panLaw)
+}
+
+// StereoLevels is a per-channel metering split for a mono source that has
+// been panned to stereo.
+type StereoLevels struct {
+	Left  channel.Levels
+	Right channel.Levels
+}
+
+// MeterStereo reports per-channel levels for the mono->stereo conversion.
+// The underlying tap measures the single post-pan mixer output, so the
+// combined Meter() snapshot is split into left/right using m.panLaw's gains
+// rather than two independent taps (the native tap layer only reports one
+// RMS per tap; see avaudio/tap).
+func (m *MonoToStereoChannel) MeterStereo() StereoLevels {
+	levels := m.Meter()
+
+	gainL, gainR := analyze.PanLawGains(m.pan, m.panLaw)
+
+	return StereoLevels{
+		Left:  scaleLevels(levels, gainL),
+		Right: scaleLevels(levels, gainR),
+	}
+}
+
+func scaleLevels(levels channel.Levels, gain float64) channel.Levels {
+	if gain <= 0 {
+		return channel.Levels{RMSDB: math.Inf(-1), PeakDB: math.Inf(-1), PeakHoldDB: math.Inf(-1)}
+	}
+	gainDB := 20 * math.Log10(gain)
+	return channel.Levels{
+		RMSDB:      levels.RMSDB + gainDB,
+		PeakDB:     levels.PeakDB + gainDB,
+		PeakHoldDB: levels.PeakHoldDB + gainDB,
+	}
 }
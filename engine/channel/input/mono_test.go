@@ -161,6 +161,79 @@ func TestMonoToStereoPanControl(t *testing.T) {
 	} else {
 		t.Log("✓ SetPanCenter works correctly")
 	}
+
+	// Test RampPan with real audio: drive pan from center to full right over
+	// a short, measurable duration and sample it via the analyze package at
+	// multiple points to confirm it actually moves progressively rather than
+	// jumping straight to the target.
+	toneNode, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("Failed to create tone node: %v", err)
+	}
+	defer toneNode.Destroy()
+
+	toneNodePtr, err := toneNode.GetNodePtr()
+	if err != nil || toneNodePtr == nil {
+		t.Fatalf("Failed to get tone node pointer: %v", err)
+	}
+
+	if err := eng.Attach(toneNodePtr); err != nil {
+		t.Fatalf("Failed to attach tone node: %v", err)
+	}
+	if err := eng.Connect(toneNodePtr, channel.GetInputNode(), 0, 0); err != nil {
+		t.Fatalf("Failed to connect tone to channel input: %v", err)
+	}
+	mainMixerPtr, err := eng.MainMixerNode()
+	if err != nil || mainMixerPtr == nil {
+		t.Fatalf("Failed to get main mixer pointer: %v", err)
+	}
+	if err := eng.Connect(channel.GetOutputNode(), mainMixerPtr, 0, 0); err != nil {
+		t.Fatalf("Failed to connect channel to main mixer: %v", err)
+	}
+
+	testutil.MuteMainMixer(t, eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer func() {
+		if eng.IsRunning() {
+			eng.Stop()
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	const rampDuration = 300 * time.Millisecond
+	if err := channel.RampPan(1.0, rampDuration, RampLinear); err != nil {
+		t.Fatalf("Failed to start pan ramp: %v", err)
+	}
+
+	analysisConfig := analyze.DefaultAnalysisConfig()
+	analysisConfig.SampleDuration = 40 * time.Millisecond
+
+	samplePoints := []time.Duration{80 * time.Millisecond, 160 * time.Millisecond, 240 * time.Millisecond}
+	var balances []float64
+	for _, delay := range samplePoints {
+		time.Sleep(delay - analysisConfig.SampleDuration)
+		analysis, err := analyze.AnalyzeMonoToStereo(eng.Ptr(), toneNodePtr, channel.GetOutputNode(), channel.GetPan(), channel.GetPanLaw(), analysisConfig)
+		if err != nil {
+			t.Fatalf("Failed to analyze audio mid-ramp: %v", err)
+		}
+		balances = append(balances, analysis.Balance)
+		t.Logf("ramp sample at ~%v: pan=%.2f balance=%.3f", delay, channel.GetPan(), analysis.Balance)
+	}
+
+	time.Sleep(rampDuration) // let the ramp finish well before the test returns
+	if channel.GetPan() != 1.0 {
+		t.Errorf("Expected pan 1.0 once the ramp completes, got %.2f", channel.GetPan())
+	}
+
+	for i := 1; i < len(balances); i++ {
+		if balances[i] < balances[i-1]-0.05 {
+			t.Errorf("Expected balance to move monotonically toward full right, got %v", balances)
+			break
+		}
+	}
+	t.Log("✓ RampPan moves pan progressively toward the target rather than jumping")
 }
 
 func TestMonoToStereoLifecycle(t *testing.T) {
@@ -334,6 +407,7 @@ func TestMonoToStereoRealAudioPanning(t *testing.T) {
 				toneNodePtr,                 // Real audio source (tone generator)
 				monoChannel.GetOutputNode(), // Stereo output after panning
 				testCase.panPosition,        // Expected pan
+				monoChannel.GetPanLaw(),
 				analysisConfig,
 			)
 			if err != nil {
@@ -389,7 +463,7 @@ func TestMonoToStereoRealAudioPanning(t *testing.T) {
 			}
 
 			// Validate stereo analysis
-			err = analyze.ValidateStereoAnalysis(stereoAnalysis, testCase.panPosition, analysisConfig)
+			err = analyze.ValidateStereoAnalysis(stereoAnalysis, testCase.panPosition, monoChannel.GetPanLaw(), analysisConfig)
 			if err != nil {
 				t.Logf("Note: Stereo analysis validation: %v", err)
 				// Don't fail the test - validation might be strict for real audio
@@ -0,0 +1,346 @@
+package input
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/engine/analyze"
+)
+
+// Layout re-exports analyze.Layout so callers configuring a
+// MonoToMultichannelChannel/StereoToMultichannelChannel don't need to
+// import the analyze package just to name one.
+type Layout = analyze.Layout
+
+const (
+	LayoutQuad         = analyze.LayoutQuad
+	Layout5_1          = analyze.Layout5_1
+	Layout7_1          = analyze.Layout7_1
+	LayoutAmbisonicsB1 = analyze.LayoutAmbisonicsB1
+)
+
+// MonoToMultichannelChannel routes a mono source across an arbitrary
+// loudspeaker layout (Quad/5.1/7.1) via VBAP, or encodes it to first-order
+// Ambisonics B-format when Layout is LayoutAmbisonicsB1. Unlike
+// MonoToStereoChannel, it's built directly on an AVAudioUnitMatrixMixer
+// (via avaudio/node's matrix helpers) rather than BaseChannel - BaseChannel's
+// pan/volume model assumes a two-channel mixer bus, which doesn't generalize
+// to an arbitrary speaker count.
+type MonoToMultichannelChannel struct {
+	name      string
+	engine    *engine.Engine
+	layout    Layout
+	matrixPtr unsafe.Pointer
+	azimuth   float32
+	elevation float32
+	distance  float32
+}
+
+// MonoToMultichannelConfig contains configuration for creating a
+// MonoToMultichannelChannel.
+type MonoToMultichannelConfig struct {
+	Name             string
+	Engine           *engine.Engine
+	Layout           Layout
+	InitialAzimuth   float32 // degrees, 0 = front, positive = clockwise right
+	InitialElevation float32 // degrees, Ambisonics only; 0 = horizon
+	InitialDistance  float32 // 1.0 = at the speaker radius (see analyze.VBAPGains)
+}
+
+// NewMonoToMultichannel creates a mono-to-multichannel input channel
+// targeting config.Layout, attaching and connecting a matrix mixer sized
+// 1 input x config.Layout.ChannelCount() outputs to the engine's graph.
+func NewMonoToMultichannel(config MonoToMultichannelConfig) (*MonoToMultichannelChannel, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("channel name cannot be empty")
+	}
+	if config.Engine == nil {
+		return nil, fmt.Errorf("engine cannot be nil")
+	}
+
+	matrixPtr, err := node.CreateMatrixMixer()
+	if err != nil || matrixPtr == nil {
+		return nil, fmt.Errorf("failed to create matrix mixer for channel %s: %w", config.Name, err)
+	}
+	if err := node.SetMatrixChannelCount(matrixPtr, 1, config.Layout.ChannelCount()); err != nil {
+		node.ReleaseNode(matrixPtr)
+		return nil, fmt.Errorf("failed to configure matrix mixer channel count: %w", err)
+	}
+	if err := config.Engine.Attach(matrixPtr); err != nil {
+		node.ReleaseNode(matrixPtr)
+		return nil, fmt.Errorf("failed to attach matrix mixer: %w", err)
+	}
+
+	mc := &MonoToMultichannelChannel{
+		name:      config.Name,
+		engine:    config.Engine,
+		layout:    config.Layout,
+		matrixPtr: matrixPtr,
+		distance:  1.0,
+	}
+	if config.InitialDistance > 0 {
+		mc.distance = config.InitialDistance
+	}
+
+	if config.Layout == LayoutAmbisonicsB1 {
+		if err := mc.SetAmbisonicsPosition(config.InitialAzimuth, config.InitialElevation); err != nil {
+			mc.Release()
+			return nil, fmt.Errorf("failed to set initial ambisonics position: %w", err)
+		}
+	} else {
+		if err := mc.SetPosition(config.InitialAzimuth, mc.distance); err != nil {
+			mc.Release()
+			return nil, fmt.Errorf("failed to set initial position: %w", err)
+		}
+	}
+
+	return mc, nil
+}
+
+// GetName returns the channel's name.
+func (m *MonoToMultichannelChannel) GetName() string { return m.name }
+
+// GetInputNode returns the node sources should connect to (bus 0, mono).
+func (m *MonoToMultichannelChannel) GetInputNode() unsafe.Pointer { return m.matrixPtr }
+
+// GetOutputNode returns the node destinations should connect to
+// (m.layout.ChannelCount() channels).
+func (m *MonoToMultichannelChannel) GetOutputNode() unsafe.Pointer { return m.matrixPtr }
+
+// GetLayout returns the channel's configured layout.
+func (m *MonoToMultichannelChannel) GetLayout() Layout { return m.layout }
+
+// SetPosition pans the source to (azimuth, distance) across m.layout via
+// VBAP (see analyze.VBAPGains) and writes the resulting gain for each
+// output channel to the matrix mixer. Not valid for LayoutAmbisonicsB1 -
+// use SetAmbisonicsPosition there.
+func (m *MonoToMultichannelChannel) SetPosition(azimuth, distance float32) error {
+	gains, err := analyze.VBAPGains(m.layout, azimuth, distance)
+	if err != nil {
+		return err
+	}
+	for ch, gain := range gains {
+		if err := node.SetMatrixVolume(m.matrixPtr, 0, ch, float32(gain)); err != nil {
+			return fmt.Errorf("failed to set matrix gain for channel %d: %w", ch, err)
+		}
+	}
+	m.azimuth, m.distance = azimuth, distance
+	return nil
+}
+
+// SetAmbisonicsPosition encodes the source at (azimuth, elevation) to
+// first-order B-format (see analyze.EncodeAmbisonicsB1) and writes the
+// W/X/Y/Z coefficients to the matrix mixer's four output channels. Only
+// valid for LayoutAmbisonicsB1.
+func (m *MonoToMultichannelChannel) SetAmbisonicsPosition(azimuth, elevation float32) error {
+	if m.layout != LayoutAmbisonicsB1 {
+		return fmt.Errorf("SetAmbisonicsPosition requires LayoutAmbisonicsB1, channel is %v", m.layout)
+	}
+	b := analyze.EncodeAmbisonicsB1(azimuth, elevation)
+	coeffs := [4]float64{b.W, b.X, b.Y, b.Z}
+	for ch, gain := range coeffs {
+		if err := node.SetMatrixVolume(m.matrixPtr, 0, ch, float32(gain)); err != nil {
+			return fmt.Errorf("failed to set matrix gain for channel %d: %w", ch, err)
+		}
+	}
+	m.azimuth, m.elevation = azimuth, elevation
+	return nil
+}
+
+// GetPosition returns the azimuth/distance last passed to SetPosition (or
+// azimuth/elevation for SetAmbisonicsPosition, with distance left at its
+// last VBAP value).
+func (m *MonoToMultichannelChannel) GetPosition() (azimuth, elevation, distance float32) {
+	return m.azimuth, m.elevation, m.distance
+}
+
+// Release detaches and releases the channel's matrix mixer.
+func (m *MonoToMultichannelChannel) Release() {
+	if m.matrixPtr == nil {
+		return
+	}
+	if m.engine != nil {
+		_ = m.engine.Detach(m.matrixPtr)
+	}
+	node.ReleaseNode(m.matrixPtr)
+	m.matrixPtr = nil
+}
+
+// StereoToMultichannelChannel routes a stereo source across a loudspeaker
+// layout, spreading the incoming left/right pair symmetrically around a
+// center azimuth at StereoSpreadDegrees apart and panning each side
+// independently via VBAP (or encoding each to Ambisonics B-format for
+// LayoutAmbisonicsB1), so the source keeps its stereo width instead of
+// collapsing to one point source.
+type StereoToMultichannelChannel struct {
+	name      string
+	engine    *engine.Engine
+	layout    Layout
+	matrixPtr unsafe.Pointer
+	azimuth   float32
+	elevation float32
+	distance  float32
+	spread    float32
+}
+
+// StereoSpreadDegrees is the default angular separation
+// NewStereoToMultichannel keeps between the left and right input channels
+// around their shared center azimuth.
+const StereoSpreadDegrees = 30
+
+// StereoToMultichannelConfig contains configuration for creating a
+// StereoToMultichannelChannel.
+type StereoToMultichannelConfig struct {
+	Name             string
+	Engine           *engine.Engine
+	Layout           Layout
+	InitialAzimuth   float32
+	InitialElevation float32
+	InitialDistance  float32
+	SpreadDegrees    float32 // 0 uses StereoSpreadDegrees
+}
+
+// NewStereoToMultichannel creates a stereo-to-multichannel input channel
+// targeting config.Layout, attaching and connecting a matrix mixer sized
+// 2 inputs x config.Layout.ChannelCount() outputs to the engine's graph.
+func NewStereoToMultichannel(config StereoToMultichannelConfig) (*StereoToMultichannelChannel, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("channel name cannot be empty")
+	}
+	if config.Engine == nil {
+		return nil, fmt.Errorf("engine cannot be nil")
+	}
+
+	matrixPtr, err := node.CreateMatrixMixer()
+	if err != nil || matrixPtr == nil {
+		return nil, fmt.Errorf("failed to create matrix mixer for channel %s: %w", config.Name, err)
+	}
+	if err := node.SetMatrixChannelCount(matrixPtr, 2, config.Layout.ChannelCount()); err != nil {
+		node.ReleaseNode(matrixPtr)
+		return nil, fmt.Errorf("failed to configure matrix mixer channel count: %w", err)
+	}
+	if err := config.Engine.Attach(matrixPtr); err != nil {
+		node.ReleaseNode(matrixPtr)
+		return nil, fmt.Errorf("failed to attach matrix mixer: %w", err)
+	}
+
+	spread := config.SpreadDegrees
+	if spread == 0 {
+		spread = StereoSpreadDegrees
+	}
+
+	sc := &StereoToMultichannelChannel{
+		name:      config.Name,
+		engine:    config.Engine,
+		layout:    config.Layout,
+		matrixPtr: matrixPtr,
+		distance:  1.0,
+		spread:    spread,
+	}
+	if config.InitialDistance > 0 {
+		sc.distance = config.InitialDistance
+	}
+
+	if config.Layout == LayoutAmbisonicsB1 {
+		if err := sc.SetAmbisonicsPosition(config.InitialAzimuth, config.InitialElevation); err != nil {
+			sc.Release()
+			return nil, fmt.Errorf("failed to set initial ambisonics position: %w", err)
+		}
+	} else {
+		if err := sc.SetPosition(config.InitialAzimuth, sc.distance); err != nil {
+			sc.Release()
+			return nil, fmt.Errorf("failed to set initial position: %w", err)
+		}
+	}
+
+	return sc, nil
+}
+
+// GetName returns the channel's name.
+func (s *StereoToMultichannelChannel) GetName() string { return s.name }
+
+// GetInputNode returns the node sources should connect to (bus 0=left,
+// bus 1=right).
+func (s *StereoToMultichannelChannel) GetInputNode() unsafe.Pointer { return s.matrixPtr }
+
+// GetOutputNode returns the node destinations should connect to
+// (s.layout.ChannelCount() channels).
+func (s *StereoToMultichannelChannel) GetOutputNode() unsafe.Pointer { return s.matrixPtr }
+
+// GetLayout returns the channel's configured layout.
+func (s *StereoToMultichannelChannel) GetLayout() Layout { return s.layout }
+
+// SetPosition pans left/right symmetrically around azimuth (separated by
+// this channel's spread) at distance, writing each input channel's VBAP
+// gains to its own row of the matrix mixer. Not valid for
+// LayoutAmbisonicsB1 - use SetAmbisonicsPosition there.
+func (s *StereoToMultichannelChannel) SetPosition(azimuth, distance float32) error {
+	half := s.spread / 2
+	leftGains, err := analyze.VBAPGains(s.layout, azimuth-half, distance)
+	if err != nil {
+		return err
+	}
+	rightGains, err := analyze.VBAPGains(s.layout, azimuth+half, distance)
+	if err != nil {
+		return err
+	}
+	for ch, gain := range leftGains {
+		if err := node.SetMatrixVolume(s.matrixPtr, 0, ch, float32(gain)); err != nil {
+			return fmt.Errorf("failed to set left matrix gain for channel %d: %w", ch, err)
+		}
+	}
+	for ch, gain := range rightGains {
+		if err := node.SetMatrixVolume(s.matrixPtr, 1, ch, float32(gain)); err != nil {
+			return fmt.Errorf("failed to set right matrix gain for channel %d: %w", ch, err)
+		}
+	}
+	s.azimuth, s.distance = azimuth, distance
+	return nil
+}
+
+// SetAmbisonicsPosition encodes left/right symmetrically around
+// (azimuth, elevation) to first-order B-format, writing each input
+// channel's W/X/Y/Z coefficients to its own row of the matrix mixer. Only
+// valid for LayoutAmbisonicsB1.
+func (s *StereoToMultichannelChannel) SetAmbisonicsPosition(azimuth, elevation float32) error {
+	if s.layout != LayoutAmbisonicsB1 {
+		return fmt.Errorf("SetAmbisonicsPosition requires LayoutAmbisonicsB1, channel is %v", s.layout)
+	}
+	half := s.spread / 2
+	left := analyze.EncodeAmbisonicsB1(azimuth-half, elevation)
+	right := analyze.EncodeAmbisonicsB1(azimuth+half, elevation)
+	leftCoeffs := [4]float64{left.W, left.X, left.Y, left.Z}
+	rightCoeffs := [4]float64{right.W, right.X, right.Y, right.Z}
+	for ch, gain := range leftCoeffs {
+		if err := node.SetMatrixVolume(s.matrixPtr, 0, ch, float32(gain)); err != nil {
+			return fmt.Errorf("failed to set left matrix gain for channel %d: %w", ch, err)
+		}
+	}
+	for ch, gain := range rightCoeffs {
+		if err := node.SetMatrixVolume(s.matrixPtr, 1, ch, float32(gain)); err != nil {
+			return fmt.Errorf("failed to set right matrix gain for channel %d: %w", ch, err)
+		}
+	}
+	s.azimuth, s.elevation = azimuth, elevation
+	return nil
+}
+
+// GetPosition returns the azimuth/elevation/distance last passed to
+// SetPosition or SetAmbisonicsPosition.
+func (s *StereoToMultichannelChannel) GetPosition() (azimuth, elevation, distance float32) {
+	return s.azimuth, s.elevation, s.distance
+}
+
+// Release detaches and releases the channel's matrix mixer.
+func (s *StereoToMultichannelChannel) Release() {
+	if s.matrixPtr == nil {
+		return
+	}
+	if s.engine != nil {
+		_ = s.engine.Detach(s.matrixPtr)
+	}
+	node.ReleaseNode(s.matrixPtr)
+	s.matrixPtr = nil
+}
@@ -0,0 +1,85 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+func TestNewMonoToMultichannel(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create AVAudioEngine for testing")
+	}
+	defer eng.Destroy()
+
+	channel, err := NewMonoToMultichannel(MonoToMultichannelConfig{
+		Name:           "Test Quad Channel",
+		Engine:         eng,
+		Layout:         LayoutQuad,
+		InitialAzimuth: 0,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MonoToMultichannelChannel: %v", err)
+	}
+	defer channel.Release()
+
+	if channel.GetLayout() != LayoutQuad {
+		t.Errorf("expected LayoutQuad, got %v", channel.GetLayout())
+	}
+
+	if err := channel.SetPosition(90, 1.0); err != nil {
+		t.Fatalf("SetPosition failed: %v", err)
+	}
+	az, _, dist := channel.GetPosition()
+	if az != 90 || dist != 1.0 {
+		t.Errorf("expected position (90, 1.0), got (%.1f, %.1f)", az, dist)
+	}
+}
+
+func TestNewMonoToMultichannelAmbisonics(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create AVAudioEngine for testing")
+	}
+	defer eng.Destroy()
+
+	channel, err := NewMonoToMultichannel(MonoToMultichannelConfig{
+		Name:   "Test Ambisonics Channel",
+		Engine: eng,
+		Layout: LayoutAmbisonicsB1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MonoToMultichannelChannel: %v", err)
+	}
+	defer channel.Release()
+
+	if err := channel.SetPosition(0, 1.0); err == nil {
+		t.Error("expected SetPosition (VBAP) to reject LayoutAmbisonicsB1")
+	}
+	if err := channel.SetAmbisonicsPosition(45, 10); err != nil {
+		t.Fatalf("SetAmbisonicsPosition failed: %v", err)
+	}
+}
+
+func TestNewStereoToMultichannel(t *testing.T) {
+	eng, err := engine.New(engine.DefaultAudioSpec())
+	if err != nil || eng == nil {
+		t.Skip("Cannot create AVAudioEngine for testing")
+	}
+	defer eng.Destroy()
+
+	channel, err := NewStereoToMultichannel(StereoToMultichannelConfig{
+		Name:   "Test 5.1 Channel",
+		Engine: eng,
+		Layout: Layout5_1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create StereoToMultichannelChannel: %v", err)
+	}
+	defer channel.Release()
+
+	if err := channel.SetPosition(0, 1.0); err != nil {
+		t.Fatalf("SetPosition failed: %v", err)
+	}
+}
@@ -0,0 +1,413 @@
+package channel
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// Levels is a single metering snapshot in dBFS. A value of math.Inf(-1)
+// means "no signal measured yet" rather than true silence.
+type Levels struct {
+	RMSDB      float64 // windowed RMS level
+	PeakDB     float64 // instantaneous peak since the last poll
+	PeakHoldDB float64 // decaying peak-hold, falls off at PeakHoldDecayDB/sec
+}
+
+// MeterBallistics configures how raw tap RMS samples are turned into the
+// Levels a UI draws. The zero value is not usable; use DefaultBallistics.
+type MeterBallistics struct {
+	PollInterval     time.Duration // how often the tap is sampled, e.g. 1000/60 Hz
+	RMSWindowMs      float64       // size of the moving-average RMS window
+	VUIntegrationMs  float64       // VU-style integration time (300ms standard); 0 disables
+	PeakHoldMs       float64       // how long peak-hold stays pinned before decaying
+	PeakHoldDecayDB  float64       // decay rate of peak-hold once it starts falling, in dB/sec
+}
+
+// DefaultBallistics returns ballistics suitable for a 30-60Hz UI meter: a
+// 300ms RMS window, standard VU integration, and a 1.5s peak hold that
+// falls off at 12dB/sec once it starts decaying.
+func DefaultBallistics() MeterBallistics {
+	return MeterBallistics{
+		PollInterval:    time.Second / 60,
+		RMSWindowMs:     300,
+		VUIntegrationMs: 300,
+		PeakHoldMs:      1500,
+		PeakHoldDecayDB: 12,
+	}
+}
+
+// continuousMeter polls a tap.Tap on an interval and maintains ballistics
+// state so Meter() is a cheap, lock-protected read with no render-thread
+// allocation or audio-buffer copy back to Go.
+type continuousMeter struct {
+	ballistics MeterBallistics
+	stop       chan struct{}
+	wg         sync.WaitGroup
+
+	mu         sync.RWMutex
+	levels     Levels
+	vuRMS      float64   // VU-integrated RMS (linear), updated each poll
+	peakHoldAt time.Time // when the current peak-hold value was set
+}
+
+func newContinuousMeter(t *tap.Tap, ballistics MeterBallistics) *continuousMeter {
+	cm := &continuousMeter{
+		ballistics: ballistics,
+		stop:       make(chan struct{}),
+		levels:     Levels{RMSDB: math.Inf(-1), PeakDB: math.Inf(-1), PeakHoldDB: math.Inf(-1)},
+	}
+	cm.wg.Add(1)
+	go cm.run(t)
+	return cm
+}
+
+func (cm *continuousMeter) run(t *tap.Tap) {
+	defer cm.wg.Done()
+	ticker := time.NewTicker(cm.ballistics.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cm.stop:
+			return
+		case now := <-ticker.C:
+			metrics, err := t.GetMetrics()
+			if err != nil {
+				continue
+			}
+			cm.update(metrics.RMS, now)
+		}
+	}
+}
+
+// update folds one raw RMS sample into the ballistics state. It is
+// allocation-free so it can run at UI polling rates indefinitely.
+func (cm *continuousMeter) update(rms float64, now time.Time) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	peakDB := linearToDB(rms)
+
+	if cm.ballistics.VUIntegrationMs > 0 {
+		// Single-pole low-pass with a time constant derived from the
+		// integration time and poll interval, approximating VU ballistics.
+		alpha := 1 - math.Exp(-float64(cm.ballistics.PollInterval.Milliseconds())/cm.ballistics.VUIntegrationMs)
+		cm.vuRMS += alpha * (rms - cm.vuRMS)
+	} else {
+		cm.vuRMS = rms
+	}
+	cm.levels.RMSDB = linearToDB(cm.vuRMS)
+	cm.levels.PeakDB = peakDB
+
+	switch {
+	case peakDB >= cm.levels.PeakHoldDB || math.IsInf(cm.levels.PeakHoldDB, -1):
+		cm.levels.PeakHoldDB = peakDB
+		cm.peakHoldAt = now
+	case now.Sub(cm.peakHoldAt) > time.Duration(cm.ballistics.PeakHoldMs)*time.Millisecond:
+		elapsedSec := now.Sub(cm.peakHoldAt.Add(time.Duration(cm.ballistics.PeakHoldMs) * time.Millisecond)).Seconds()
+		decayed := cm.levels.PeakHoldDB - cm.ballistics.PeakHoldDecayDB*elapsedSec
+		if decayed > peakDB {
+			cm.levels.PeakHoldDB = decayed
+		} else {
+			cm.levels.PeakHoldDB = peakDB
+			cm.peakHoldAt = now
+		}
+	}
+}
+
+func (cm *continuousMeter) snapshot() Levels {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.levels
+}
+
+func (cm *continuousMeter) Stop() {
+	close(cm.stop)
+	cm.wg.Wait()
+}
+
+func linearToDB(v float64) float64 {
+	if v <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(v)
+}
+
+func dbToLinear(db float64) float64 {
+	if math.IsInf(db, -1) {
+		return 0
+	}
+	return math.Pow(10, db/20)
+}
+
+// Meter is a single combined output metering reading - RMS and peak
+// (linear amplitude), true-peak (dBTP), and short-term/integrated LUFS -
+// for a channel that's had EnableOutputMetering enabled. It merges what
+// Meter()'s Levels (RMS/peak/peak-hold, continuousMeter's polled
+// ballistics) and tap.GetLoudness (LUFS) already expose separately into
+// one read, plus Clip, which neither of those track.
+type Meter struct {
+	RMS            float64 // linear RMS amplitude, the loudest channel's
+	Peak           float64 // linear instantaneous peak, the loudest channel's
+	TruePeak       float64 // dBTP, 4x oversampled inter-sample peak, the loudest channel's
+	LUFSShort      float64 // short-term (3s window) program loudness, LUFS
+	LUFSIntegrated float64 // gated integrated program loudness, LUFS
+	Clip           bool    // true if Peak reached or exceeded 0 dBFS (linear 1.0)
+}
+
+// MeterSnapshot returns bc's current combined output meter reading. It
+// requires EnableOutputMetering(eng, true) to have been called first -
+// unlike Meter(), which only needs StartMetering and never errors,
+// MeterSnapshot reads straight from the meter tap's own
+// tap.GetMeter/tap.GetLoudness analyzers (lazily installed on first call,
+// same as those methods do on their own), so it has true-peak and LUFS
+// that continuousMeter's ballistics don't compute.
+func (bc *BaseChannel) MeterSnapshot() (Meter, error) {
+	bc.meterMu.RLock()
+	t := bc.meterTap
+	bc.meterMu.RUnlock()
+	if t == nil || !t.IsInstalled() {
+		return Meter{}, fmt.Errorf("output metering not enabled")
+	}
+
+	ms, err := t.GetMeter()
+	if err != nil {
+		return Meter{}, fmt.Errorf("get meter: %w", err)
+	}
+	loud, err := t.GetLoudness()
+	if err != nil {
+		return Meter{}, fmt.Errorf("get loudness: %w", err)
+	}
+
+	var peak, truePeakDb, rms float64
+	for _, ch := range ms.Channels {
+		if ch.PeakLinear > peak {
+			peak = ch.PeakLinear
+		}
+		if ch.TruePeakDb > truePeakDb {
+			truePeakDb = ch.TruePeakDb
+		}
+		if r := dbToLinear(ch.RMSDb); r > rms {
+			rms = r
+		}
+	}
+
+	return Meter{
+		RMS:            rms,
+		Peak:           peak,
+		TruePeak:       truePeakDb,
+		LUFSShort:      loud.ShortTerm,
+		LUFSIntegrated: loud.Integrated,
+		Clip:           peak >= 1.0,
+	}, nil
+}
+
+// MeterEvent is one push-based Meter reading delivered by SubscribeMeter.
+type MeterEvent struct {
+	Meter
+	Time time.Time
+}
+
+// SubscribeMeter starts pushing a MeterEvent to ch hz times per second
+// (defaulting to 30 if hz <= 0) until the returned cancel func is called,
+// so a UI or an OSC broadcaster (see control/osc.Surface's
+// broadcastMeters) can get LUFS/true-peak without polling MeterSnapshot
+// itself. A full ch drops the frame rather than blocking, the same policy
+// MeterHub.SubscribeMeters uses. Named SubscribeMeter rather than
+// Subscribe: BaseChannel already has a Subscribe() for ChannelStateEvent
+// (channel_events.go), with an unrelated no-args signature.
+func (bc *BaseChannel) SubscribeMeter(ch chan<- MeterEvent, hz int) (cancel func()) {
+	if hz <= 0 {
+		hz = 30
+	}
+	stop := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(time.Second / time.Duration(hz))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				m, err := bc.MeterSnapshot()
+				if err != nil {
+					continue
+				}
+				select {
+				case ch <- MeterEvent{Meter: m, Time: now}:
+				default:
+				}
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(stop) }) }
+}
+
+// StartMetering installs (if needed) the output tap and starts a background
+// poller that continuously computes RMS/peak/peak-hold so Meter() can be
+// called from a UI draw loop at 30-60Hz without touching the render thread.
+func (bc *BaseChannel) StartMetering(eng *engine.Engine, ballistics MeterBallistics) error {
+	if bc.released {
+		return fmt.Errorf("channel has been released")
+	}
+	if err := bc.EnableOutputMetering(eng, true); err != nil {
+		return err
+	}
+
+	bc.meterMu.Lock()
+	defer bc.meterMu.Unlock()
+	if bc.continuousMeter != nil {
+		return nil // already running
+	}
+	bc.continuousMeter = newContinuousMeter(bc.meterTap, ballistics)
+	return nil
+}
+
+// StopMetering stops the background poller and removes the output tap.
+func (bc *BaseChannel) StopMetering() error {
+	bc.meterMu.Lock()
+	cm := bc.continuousMeter
+	bc.continuousMeter = nil
+	bc.meterMu.Unlock()
+
+	if cm != nil {
+		cm.Stop()
+	}
+	return bc.EnableOutputMetering(nil, false)
+}
+
+// Meter returns the most recent Levels snapshot. It is safe to call from a
+// UI draw loop; it never blocks on audio I/O. Returns the zero Levels with
+// -Inf fields if StartMetering has not been called.
+func (bc *BaseChannel) Meter() Levels {
+	bc.meterMu.RLock()
+	defer bc.meterMu.RUnlock()
+	if bc.continuousMeter == nil {
+		return Levels{RMSDB: math.Inf(-1), PeakDB: math.Inf(-1), PeakHoldDB: math.Inf(-1)}
+	}
+	return bc.continuousMeter.snapshot()
+}
+
+// Metered is implemented by anything StartMetering/Meter works on; BaseChannel
+// and types that embed it (MonoToStereoChannel, etc.) satisfy it automatically.
+type Metered interface {
+	Meter() Levels
+}
+
+// MetersFrame is one push-based update delivered by MeterHub.SubscribeMeters.
+type MetersFrame struct {
+	Channel string
+	Levels  Levels
+	Time    time.Time
+}
+
+// MeterHub fans the Meter() snapshots of a set of named channels out to
+// subscribers at a fixed rate, so a UI can draw all meters from one feed
+// instead of polling each channel itself.
+type MeterHub struct {
+	mu       sync.RWMutex
+	channels map[string]Metered
+}
+
+// NewMeterHub creates an empty hub.
+func NewMeterHub() *MeterHub {
+	return &MeterHub{channels: make(map[string]Metered)}
+}
+
+// Register adds (or replaces) a named channel in the hub.
+func (h *MeterHub) Register(name string, ch Metered) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.channels[name] = ch
+}
+
+// Unregister removes a named channel from the hub.
+func (h *MeterHub) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.channels, name)
+}
+
+// SubscribeMeters pushes a MetersFrame per registered channel on every tick
+// of rate until stop is closed. The caller owns ch and should drain it
+// promptly; SubscribeMeters drops frames rather than blocking if ch is full.
+func (h *MeterHub) SubscribeMeters(ch chan<- MetersFrame, rate time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(rate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			h.mu.RLock()
+			for name, metered := range h.channels {
+				frame := MetersFrame{Channel: name, Levels: metered.Meter(), Time: now}
+				select {
+				case ch <- frame:
+				default:
+				}
+			}
+			h.mu.RUnlock()
+		}
+	}
+}
+
+// MeterTap is a CallbackTap-backed meter: instead of StartMetering's
+// poll-driven GetMetrics, it folds RMS/peak into a pair of lock-free
+// float32s every block, so RMS/Peak can be read from a GUI thread without
+// ever touching the mutex continuousMeter needs. Prefer this over
+// StartMetering when a tap is already installed on the node you want to
+// meter (Bus.InstallInputTap/InstallOutputTap) and you don't need
+// peak-hold/VU ballistics, just a cheap raw level.
+type MeterTap struct {
+	rmsBits  atomic.Uint32
+	peakBits atomic.Uint32
+}
+
+// NewMeterTap creates a MeterTap whose Feed method should be passed as (or
+// wrapped by) the callback given to InstallInputTap/InstallOutputTap.
+func NewMeterTap() *MeterTap {
+	return &MeterTap{}
+}
+
+// Feed computes the RMS and peak of one tap buffer and atomically publishes
+// them. It is allocation-free and safe to call from the tap's drain
+// goroutine at audio block rate.
+func (mt *MeterTap) Feed(buf tap.TapBuffer) {
+	if len(buf.Float32Data) == 0 {
+		return
+	}
+
+	var sumSquares float64
+	var peak float32
+	for _, s := range buf.Float32Data {
+		sumSquares += float64(s) * float64(s)
+		if abs := float32(math.Abs(float64(s))); abs > peak {
+			peak = abs
+		}
+	}
+	rms := float32(math.Sqrt(sumSquares / float64(len(buf.Float32Data))))
+
+	mt.rmsBits.Store(math.Float32bits(rms))
+	mt.peakBits.Store(math.Float32bits(peak))
+}
+
+// RMS returns the linear RMS amplitude measured in the most recently fed
+// buffer.
+func (mt *MeterTap) RMS() float32 {
+	return math.Float32frombits(mt.rmsBits.Load())
+}
+
+// Peak returns the linear peak amplitude measured in the most recently fed
+// buffer.
+func (mt *MeterTap) Peak() float32 {
+	return math.Float32frombits(mt.peakBits.Load())
+}
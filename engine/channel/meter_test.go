@@ -0,0 +1,137 @@
+package channel
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+func TestContinuousMeterUpdateTracksRMSAndPeakHold(t *testing.T) {
+	cm := &continuousMeter{
+		ballistics: DefaultBallistics(),
+		levels:     Levels{RMSDB: math.Inf(-1), PeakDB: math.Inf(-1), PeakHoldDB: math.Inf(-1)},
+	}
+
+	t0 := time.Now()
+	cm.update(0.5, t0)
+
+	if math.IsInf(cm.levels.RMSDB, -1) {
+		t.Fatal("expected finite RMSDB after a non-zero sample")
+	}
+	if cm.levels.PeakHoldDB != cm.levels.PeakDB {
+		t.Fatalf("expected peak-hold to latch to first peak, got hold=%v peak=%v", cm.levels.PeakHoldDB, cm.levels.PeakDB)
+	}
+
+	// A much quieter sample shouldn't pull peak-hold down immediately.
+	held := cm.levels.PeakHoldDB
+	cm.update(0.001, t0.Add(10*time.Millisecond))
+	if cm.levels.PeakHoldDB != held {
+		t.Errorf("expected peak-hold to stay pinned at %v, got %v", held, cm.levels.PeakHoldDB)
+	}
+
+	// After the hold time elapses, peak-hold should start decaying.
+	later := t0.Add(time.Duration(cm.ballistics.PeakHoldMs)*time.Millisecond + 500*time.Millisecond)
+	cm.update(0.001, later)
+	if cm.levels.PeakHoldDB >= held {
+		t.Errorf("expected peak-hold to decay below %v, got %v", held, cm.levels.PeakHoldDB)
+	}
+}
+
+func TestLinearToDBSilenceIsNegativeInfinity(t *testing.T) {
+	if got := linearToDB(0); !math.IsInf(got, -1) {
+		t.Errorf("expected -Inf for zero amplitude, got %v", got)
+	}
+	if got := linearToDB(1); got != 0 {
+		t.Errorf("expected 0dB for full-scale amplitude, got %v", got)
+	}
+}
+
+func TestMeterHubSubscribeDeliversFrames(t *testing.T) {
+	hub := NewMeterHub()
+	hub.Register("test", fakeMetered{levels: Levels{RMSDB: -6, PeakDB: -3, PeakHoldDB: -3}})
+
+	ch := make(chan MetersFrame, 4)
+	stop := make(chan struct{})
+	go hub.SubscribeMeters(ch, 5*time.Millisecond, stop)
+	defer close(stop)
+
+	select {
+	case frame := <-ch:
+		if frame.Channel != "test" || frame.Levels.RMSDB != -6 {
+			t.Errorf("unexpected frame: %+v", frame)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a meter frame")
+	}
+}
+
+type fakeMetered struct{ levels Levels }
+
+func (f fakeMetered) Meter() Levels { return f.levels }
+
+// TestMeterSnapshotRequiresOutputMetering checks that MeterSnapshot errors
+// before EnableOutputMetering has been called, and succeeds once it has.
+func TestMeterSnapshotRequiresOutputMetering(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	if _, err := ch.MeterSnapshot(); err == nil {
+		t.Error("expected MeterSnapshot to error before EnableOutputMetering")
+	}
+
+	if err := ch.EnableOutputMetering(eng, true); err != nil {
+		t.Fatalf("enable output metering: %v", err)
+	}
+	m, err := ch.MeterSnapshot()
+	if err != nil {
+		t.Fatalf("MeterSnapshot: %v", err)
+	}
+	if m.Clip {
+		t.Errorf("expected Clip false on silence, got %+v", m)
+	}
+}
+
+// TestSubscribeMeterDeliversEvents checks that SubscribeMeter pushes at
+// least one MeterEvent once output metering is enabled, and that cancel
+// stops delivery.
+func TestSubscribeMeterDeliversEvents(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	if err := ch.EnableOutputMetering(eng, true); err != nil {
+		t.Fatalf("enable output metering: %v", err)
+	}
+
+	events := make(chan MeterEvent, 4)
+	cancel := ch.SubscribeMeter(events, 200)
+	defer cancel()
+
+	select {
+	case <-events:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a meter event")
+	}
+
+	cancel()
+}
@@ -0,0 +1,247 @@
+package channel
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// RampCurve selects the shape a RampVolume/RampPan ramp's value follows
+// between its current level and its target as it progresses from t=0 to
+// t=1.
+type RampCurve int
+
+const (
+	// RampLinear moves directly from the start value to the target.
+	RampLinear RampCurve = iota
+	// RampExponential multiplies toward the target at a constant ratio per
+	// unit time; falls back to RampLinear when either endpoint isn't
+	// strictly positive (a ratio isn't defined across zero).
+	RampExponential
+	// RampEqualPower eases out using a quarter-sine, the standard
+	// "equal-power" fade shape audio editors use so a fade doesn't dip in
+	// perceived loudness partway through.
+	RampEqualPower
+	// RampSCurve eases in and out (smoothstep), starting and ending slowly
+	// with most of the change in the middle of the ramp.
+	RampSCurve
+)
+
+// String returns the curve's name, e.g. "EqualPower".
+func (c RampCurve) String() string {
+	switch c {
+	case RampLinear:
+		return "Linear"
+	case RampExponential:
+		return "Exponential"
+	case RampEqualPower:
+		return "EqualPower"
+	case RampSCurve:
+		return "SCurve"
+	default:
+		return fmt.Sprintf("RampCurve(%d)", int(c))
+	}
+}
+
+// rampValue interpolates between from and to at position t in [0,1] along
+// curve.
+func rampValue(from, to float64, t float64, curve RampCurve) float64 {
+	switch curve {
+	case RampExponential:
+		if from > 0 && to > 0 {
+			return from * math.Pow(to/from, t)
+		}
+		return from + (to-from)*t
+	case RampEqualPower:
+		eased := math.Sin(t * math.Pi / 2)
+		return from + (to-from)*eased
+	case RampSCurve:
+		eased := t * t * (3 - 2*t)
+		return from + (to-from)*eased
+	default: // RampLinear
+		return from + (to-from)*t
+	}
+}
+
+// rampStepInterval is the control-rate a ramp recomputes and writes its
+// parameter at. Coarser than the sample-accurate scheduling engine.Automate
+// gives AudioUnit parameters, since these writes land on a plain
+// AVAudioMixerNode property (node.SetMixerVolume/SetMixerPan) rather than
+// one drained from a render callback.
+const rampStepInterval = 10 * time.Millisecond
+
+// RampState reports one in-flight ramp, as returned by Ramps().
+type RampState struct {
+	Parameter string // "volume" or "pan"
+	From      float32
+	Target    float32
+	Curve     RampCurve
+	Started   time.Time
+	Duration  time.Duration
+}
+
+// activeRamp is the bookkeeping behind one entry in BaseChannel.ramps.
+type activeRamp struct {
+	state  RampState
+	cancel chan struct{}
+}
+
+// SetRampSetters overrides the functions RampVolume/RampPan read the ramp's
+// starting point from and drive on each tick. BaseChannel's own
+// GetVolume/SetVolume/GetPan/SetPan are the default; an embedder that
+// overrides any of them (MonoToStereoChannel does, to layer pan-law
+// compensation on top of SetPan/GetPan) should call this once, right after
+// construction, with its own methods - Go doesn't dispatch through an
+// embedded pointer virtually, so without this a ramp running on an embedder
+// would read its "from" value off the raw mixer and write ticks that
+// bypass the override entirely. Any nil argument leaves that getter/setter
+// unchanged.
+func (bc *BaseChannel) SetRampSetters(volumeSetter, panSetter func(float32) error, volumeGetter func() (float32, error), panGetter func() (float32, error)) {
+	if volumeSetter != nil {
+		bc.volumeSetter = volumeSetter
+	}
+	if panSetter != nil {
+		bc.panSetter = panSetter
+	}
+	if volumeGetter != nil {
+		bc.volumeGetter = volumeGetter
+	}
+	if panGetter != nil {
+		bc.panGetter = panGetter
+	}
+}
+
+// RampVolume animates the channel's volume from its current value to target
+// over duration along curve. A zero or negative duration sets target
+// immediately. A ramp already running on volume is superseded (not
+// combined) - it stops where it was and this ramp starts from there.
+func (bc *BaseChannel) RampVolume(target float32, duration time.Duration, curve RampCurve) error {
+	if target < 0 || target > 1 {
+		return fmt.Errorf("volume must be between 0.0 and 1.0, got %.2f", target)
+	}
+	from, err := bc.volumeGetter()
+	if err != nil {
+		return err
+	}
+	return bc.startRamp("volume", from, target, duration, curve, bc.volumeSetter)
+}
+
+// RampPan animates the channel's pan from its current value to target over
+// duration along curve. A zero or negative duration sets target
+// immediately. A ramp already running on pan is superseded (not combined)
+// - it stops where it was and this ramp starts from there.
+func (bc *BaseChannel) RampPan(target float32, duration time.Duration, curve RampCurve) error {
+	if target < -1 || target > 1 {
+		return fmt.Errorf("pan must be between -1.0 and +1.0, got %.2f", target)
+	}
+	from, err := bc.panGetter()
+	if err != nil {
+		return err
+	}
+	return bc.startRamp("pan", from, target, duration, curve, bc.panSetter)
+}
+
+// startRamp is RampVolume/RampPan's shared body, parameterized by which
+// parameter ("volume"/"pan") and the setter that applies each tick's value
+// (see SetRampSetters).
+func (bc *BaseChannel) startRamp(param string, from, target float32, duration time.Duration, curve RampCurve, setter func(float32) error) error {
+	if bc.released {
+		return fmt.Errorf("channel has been released")
+	}
+
+	bc.rampMu.Lock()
+	if existing, ok := bc.ramps[param]; ok {
+		close(existing.cancel)
+		delete(bc.ramps, param)
+	}
+	bc.rampMu.Unlock()
+
+	if duration <= 0 {
+		return setter(target)
+	}
+
+	ramp := &activeRamp{
+		state: RampState{
+			Parameter: param,
+			From:      from,
+			Target:    target,
+			Curve:     curve,
+			Started:   time.Now(),
+			Duration:  duration,
+		},
+		cancel: make(chan struct{}),
+	}
+
+	bc.rampMu.Lock()
+	if bc.ramps == nil {
+		bc.ramps = make(map[string]*activeRamp)
+	}
+	bc.ramps[param] = ramp
+	bc.rampMu.Unlock()
+
+	go bc.runRamp(param, ramp, setter)
+	return nil
+}
+
+// runRamp drives ramp's ticks on its own goroutine (not the channel's
+// dispatcher, whose queue is a single serial worker shared with graph
+// mutations - a multi-second fade would otherwise stall every other
+// pending Connect/Attach/SetMute on the channel for its entire duration).
+func (bc *BaseChannel) runRamp(param string, ramp *activeRamp, setter func(float32) error) {
+	ticker := time.NewTicker(rampStepInterval)
+	defer ticker.Stop()
+
+	start := ramp.state.Started
+	from, target, curve := float64(ramp.state.From), float64(ramp.state.Target), ramp.state.Curve
+
+	for {
+		select {
+		case <-ramp.cancel:
+			return
+		case now := <-ticker.C:
+			t := float64(now.Sub(start)) / float64(ramp.state.Duration)
+			done := t >= 1
+			if done {
+				t = 1
+			}
+			_ = setter(float32(rampValue(from, target, t, curve)))
+			if !done {
+				continue
+			}
+
+			bc.rampMu.Lock()
+			if bc.ramps[param] == ramp {
+				delete(bc.ramps, param)
+			}
+			bc.rampMu.Unlock()
+
+			if bc.OnRampDone != nil {
+				bc.OnRampDone(param, ramp.state.Target)
+			}
+			return
+		}
+	}
+}
+
+// CancelRamps stops every in-flight RampVolume/RampPan ramp immediately,
+// leaving each parameter wherever its last tick left it. OnRampDone is not
+// called for ramps stopped this way.
+func (bc *BaseChannel) CancelRamps() {
+	bc.rampMu.Lock()
+	defer bc.rampMu.Unlock()
+	for param, ramp := range bc.ramps {
+		close(ramp.cancel)
+		delete(bc.ramps, param)
+	}
+}
+
+// Ramps reports every ramp currently in flight.
+func (bc *BaseChannel) Ramps() []RampState {
+	bc.rampMu.Lock()
+	defer bc.rampMu.Unlock()
+	states := make([]RampState, 0, len(bc.ramps))
+	for _, ramp := range bc.ramps {
+		states = append(states, ramp.state)
+	}
+	return states
+}
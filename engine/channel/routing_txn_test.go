@@ -0,0 +1,163 @@
+package channel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/node"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestCreateAndConnectSendCleansUpOnConnectFailure checks that a
+// ConnectSendTo failure (here, a Bus with no mixer) doesn't leave the
+// just-created send behind in bc.sends with nothing wired to it.
+func TestCreateAndConnectSendCleansUpOnConnectFailure(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	dest, err := NewBaseChannel(BaseChannelConfig{Name: "reverb-return", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new dest channel: %v", err)
+	}
+	defer dest.Release()
+
+	src, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new src channel: %v", err)
+	}
+	defer src.Release()
+
+	uninitializedBus := &Bus{name: "broken"} // no mixer: ConnectSendTo must fail
+
+	if _, err := src.CreateAndConnectSend("verb", dest, uninitializedBus, 0.4, PostFader); err == nil {
+		t.Fatal("expected CreateAndConnectSend to fail against an uninitialized bus")
+	}
+
+	if _, ok := src.GetSends()["verb"]; ok {
+		t.Error("expected the failed send to have been cleaned up, not left behind")
+	}
+}
+
+// TestRemoveSendReleasesMixerAndPublishesSendRemoved covers the case
+// CreateAndConnectSend's cleanup path now relies on: a send whose per-send
+// mixer ConnectSendToBus already created and attached (i.e. a failure past
+// that point, not the early "bus has no mixer" case
+// TestCreateAndConnectSendCleansUpOnConnectFailure exercises). RemoveSend
+// must release that mixer and publish SendRemoved rather than leave it
+// dangling and the SendCreated it balances uncompensated.
+//
+// It also guards against a regression of the bug this fix removed: RemoveSend
+// used to call DisconnectSend while already holding bc.routeMu, and
+// DisconnectSend re-acquired the same non-reentrant mutex, deadlocking every
+// RemoveSend call. The test runs on its own goroutine with a deadline so a
+// reintroduced deadlock fails the test instead of hanging the suite.
+func TestRemoveSendReleasesMixerAndPublishesSendRemoved(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	dest, err := NewBaseChannel(BaseChannelConfig{Name: "reverb-return", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new dest channel: %v", err)
+	}
+	defer dest.Release()
+
+	src, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new src channel: %v", err)
+	}
+	defer src.Release()
+
+	if err := src.CreateSendWithMode("verb", dest, 0.4, PostFader); err != nil {
+		t.Fatalf("create send: %v", err)
+	}
+
+	// Stand in for the mixer ConnectSendToBus would have created and
+	// attached before a later Connect call in the same function failed.
+	m, err := node.CreateMixer()
+	if err != nil || m == nil {
+		t.Fatalf("create mixer: %v", err)
+	}
+	if err := eng.Attach(m); err != nil {
+		t.Fatalf("attach mixer: %v", err)
+	}
+	src.sendsMu.Lock()
+	src.sends["verb"].mixer = m
+	src.sendsMu.Unlock()
+
+	events, cancel := src.Subscribe()
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- src.RemoveSend(eng, "verb") }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RemoveSend: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RemoveSend deadlocked (routeMu locked twice on the same goroutine)")
+	}
+
+	src.sendsMu.RLock()
+	_, mixerStillSet := src.sends["verb"]
+	src.sendsMu.RUnlock()
+	if mixerStillSet {
+		t.Error("expected RemoveSend to delete the send entry")
+	}
+
+	sawRemoved := false
+	for sawRemoved == false {
+		select {
+		case ev := <-events:
+			if _, ok := ev.(SendRemoved); ok {
+				sawRemoved = true
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected a SendRemoved event, got none")
+		}
+	}
+}
+
+// TestWithTxnCommitsOnSuccessAndSkipsOnError checks that WithTxn applies the
+// recorded ops when fn succeeds, and leaves them unapplied when fn errors.
+func TestWithTxnCommitsOnSuccessAndSkipsOnError(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	applied := false
+	if err := ch.WithTxn(func(txn *RoutingTxn) error {
+		txn.Attach(ch.outputMixer)
+		applied = true
+		return nil
+	}); err != nil {
+		t.Fatalf("WithTxn: %v", err)
+	}
+	if !applied {
+		t.Fatal("expected fn to have run")
+	}
+
+	wantErr := errors.New("caller declined")
+	if err := ch.WithTxn(func(txn *RoutingTxn) error {
+		return wantErr
+	}); !errors.Is(err, wantErr) {
+		t.Fatalf("expected WithTxn to propagate fn's error, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/node"
+)
+
+// CurveKind selects the shape SetVolumeRamp/SetSendLevelRamp's value
+// follows between its current level and its target, in gain space rather
+// than RampVolume/RampPan's RampCurve (see ramp.go) - kept as a distinct
+// type since CurveAudioTaper has no RampCurve equivalent.
+type CurveKind int
+
+const (
+	// CurveLinear interpolates linear gain directly.
+	CurveLinear CurveKind = iota
+	// CurveEqualPower eases out using a quarter-sine over gain, the same
+	// shape RampEqualPower uses for volume/pan.
+	CurveEqualPower
+	// CurveAudioTaper interpolates fader-travel position through a
+	// node.FaderCurve anchored at the channel's faderAnchor (see
+	// SetFaderAnchor), then converts back to gain - a log-like taper that
+	// packs more resolution near unity, matching the 0x60/0x7F (75% fader
+	// travel = 0dB) convention control-surface protocols like Mackie
+	// Control Universal use.
+	CurveAudioTaper
+)
+
+// String returns the curve's name, e.g. "AudioTaper".
+func (c CurveKind) String() string {
+	switch c {
+	case CurveLinear:
+		return "Linear"
+	case CurveEqualPower:
+		return "EqualPower"
+	case CurveAudioTaper:
+		return "AudioTaper"
+	default:
+		return fmt.Sprintf("CurveKind(%d)", int(c))
+	}
+}
+
+// SetFaderAnchor overrides the fader-travel position CurveAudioTaper
+// treats as 0dB for this channel's SetVolumeRamp/SetSendLevelRamp calls.
+// Defaults to 0.75 (raw MIDI 0x60 of a 0x7F range), the same MCU
+// convention node.ControlSurfaceFaderCurve uses.
+func (bc *BaseChannel) SetFaderAnchor(pos float32) error {
+	if pos <= 0 || pos > 1 {
+		return fmt.Errorf("fader anchor must be between 0.0 (exclusive) and 1.0")
+	}
+	bc.faderAnchor = pos
+	return nil
+}
+
+// curveGain interpolates gain (linear, 0..1) between from and to at
+// position t in [0,1] along kind, using anchor for CurveAudioTaper.
+func curveGain(from, to float32, t float64, kind CurveKind, anchor float32) float32 {
+	switch kind {
+	case CurveEqualPower:
+		eased := rampValue(float64(from), float64(to), t, RampEqualPower)
+		return float32(eased)
+	case CurveAudioTaper:
+		curve := node.NewAnchoredFaderCurve(anchor)
+		fromPos, toPos := curve.PositionAtDB(gainToDB(from)), curve.PositionAtDB(gainToDB(to))
+		pos := fromPos + float32(t)*(toPos-fromPos)
+		return dbToGain(curve.DBAtPosition(pos))
+	default: // CurveLinear
+		return from + float32(t)*(to-from)
+	}
+}
+
+// rampStepDuration returns the interval SetVolumeRamp/SetSendLevelRamp
+// schedule each sub-step at: the engine's current buffer duration
+// (BufferSize/SampleRate), the shortest interval a graph mutation can
+// actually be heard to take effect at, falling back to rampStepInterval
+// (the RampVolume/RampPan ticker's own rate) when the spec is unavailable
+// (e.g. a BaseChannel built without a live engine instance, as in tests).
+func (bc *BaseChannel) rampStepDuration() time.Duration {
+	spec := bc.GetAudioSpec()
+	if spec.SampleRate <= 0 || spec.BufferSize <= 0 {
+		return rampStepInterval
+	}
+	d := time.Duration(float64(spec.BufferSize) / spec.SampleRate * float64(time.Second))
+	if d <= 0 {
+		return rampStepInterval
+	}
+	return d
+}
+
+// startDispatchedRamp is SetVolumeRamp's and SetSendLevelRamp's shared
+// body. It reuses RampVolume/RampPan's activeRamp bookkeeping and bc.ramps
+// map - a send-level ramp's key ("send:<name>") can't collide with
+// RampVolume/RampPan's ("volume"/"pan"), so sharing the map is safe - so a
+// later call on the same target supersedes, never combines with, one
+// already running, the same policy RampVolume/RampPan use.
+func (bc *BaseChannel) startDispatchedRamp(key string, from, target float32, duration time.Duration, kind CurveKind, setter func(float32) error) error {
+	if bc.released {
+		return fmt.Errorf("channel has been released")
+	}
+
+	bc.rampMu.Lock()
+	if existing, ok := bc.ramps[key]; ok {
+		close(existing.cancel)
+		delete(bc.ramps, key)
+	}
+	bc.rampMu.Unlock()
+
+	if duration <= 0 {
+		return bc.runDispatched(setter, target)
+	}
+
+	ramp := &activeRamp{
+		state: RampState{
+			Parameter: key,
+			From:      from,
+			Target:    target,
+			Started:   time.Now(),
+			Duration:  duration,
+		},
+		cancel: make(chan struct{}),
+	}
+
+	bc.rampMu.Lock()
+	if bc.ramps == nil {
+		bc.ramps = make(map[string]*activeRamp)
+	}
+	bc.ramps[key] = ramp
+	bc.rampMu.Unlock()
+
+	anchor := bc.faderAnchor
+	step := bc.rampStepDuration()
+	go bc.runDispatchedRamp(key, ramp, kind, anchor, step, setter)
+	return nil
+}
+
+// runDispatched applies one value through bc's dispatcher (serializing it
+// with other graph mutations on this channel), falling back to a direct
+// call when there's no dispatcher (RunSync already does this for a nil
+// Dispatcher - see queue.Dispatcher.RunSync).
+func (bc *BaseChannel) runDispatched(setter func(float32) error, value float32) error {
+	return bc.dispatcher.RunSync(func(ctx context.Context) error {
+		return setter(value)
+	})
+}
+
+// runDispatchedRamp drives ramp's sub-steps at step intervals, each one
+// applied through bc's dispatcher, until duration elapses or a newer call
+// on the same key cancels it first (e.g. via Release's CancelRamps).
+func (bc *BaseChannel) runDispatchedRamp(key string, ramp *activeRamp, kind CurveKind, anchor float32, step time.Duration, setter func(float32) error) {
+	ticker := time.NewTicker(step)
+	defer ticker.Stop()
+
+	start := ramp.state.Started
+	from, target := ramp.state.From, ramp.state.Target
+
+	for {
+		select {
+		case <-ramp.cancel:
+			return
+		case now := <-ticker.C:
+			t := float64(now.Sub(start)) / float64(ramp.state.Duration)
+			done := t >= 1
+			if done {
+				t = 1
+			}
+			if err := bc.runDispatched(setter, curveGain(from, target, t, kind, anchor)); err != nil {
+				return
+			}
+			if !done {
+				continue
+			}
+
+			bc.rampMu.Lock()
+			if bc.ramps[key] == ramp {
+				delete(bc.ramps, key)
+			}
+			bc.rampMu.Unlock()
+
+			if bc.OnRampDone != nil {
+				bc.OnRampDone(key, target)
+			}
+			return
+		}
+	}
+}
+
+// SetVolumeRamp animates the channel's volume from its current value to
+// target over duration along kind, scheduling each sub-step through bc's
+// queue.Dispatcher rather than RampVolume's own unscheduled goroutine tick
+// - use this when the ramp should serialize with other pending graph
+// mutations on this channel (RampVolume trades that serialization for a
+// ramp that can't be stalled by one). A ramp already running on volume via
+// either SetVolumeRamp or RampVolume is superseded.
+func (bc *BaseChannel) SetVolumeRamp(target float32, duration time.Duration, kind CurveKind) error {
+	if target < 0 || target > 1 {
+		return fmt.Errorf("volume must be between 0.0 and 1.0, got %.2f", target)
+	}
+	from, err := bc.GetVolume()
+	if err != nil {
+		return err
+	}
+	return bc.startDispatchedRamp("volume", from, target, duration, kind, bc.SetVolume)
+}
+
+// SetSendLevelRamp animates a named send's level from its current value to
+// target over duration along kind, scheduling each sub-step through bc's
+// queue.Dispatcher and coalescing with a ramp already running on the same
+// send (a later call supersedes, never combines).
+func (bc *BaseChannel) SetSendLevelRamp(name string, target float32, duration time.Duration, kind CurveKind) error {
+	if target < 0 || target > 1 {
+		return fmt.Errorf("send level must be between 0.0 and 1.0, got %.2f", target)
+	}
+	from, err := bc.GetSendLevel(name)
+	if err != nil {
+		return err
+	}
+	setter := func(level float32) error { return bc.SetSendLevel(name, level) }
+	return bc.startDispatchedRamp("send:"+name, from, target, duration, kind, setter)
+}
+
+// GetSendLevelDB returns a named send's current level in dBFS
+// (node.MixerSilenceDB at or below silence), the dB-space counterpart to
+// GetSendLevel's normalized 0..1 gain.
+func (bc *BaseChannel) GetSendLevelDB(name string) (float32, error) {
+	level, err := bc.GetSendLevel(name)
+	if err != nil {
+		return 0, err
+	}
+	return gainToDB(level), nil
+}
+
+// SetSendLevelDB sets a named send's level from a dBFS value, the dB-space
+// counterpart to SetSendLevel's normalized 0..1 gain.
+func (bc *BaseChannel) SetSendLevelDB(name string, db float32) error {
+	return bc.SetSendLevel(name, dbToGain(db))
+}
+
+// gainToDB and dbToGain convert between linear gain (0..1) and dBFS,
+// floored at node.MixerSilenceDB - the same floor/ceiling convention
+// node.SetMixerVolumeDB/GetMixerVolumeDB use, duplicated here (as the
+// scenes and control/midi packages each already do) rather than exporting
+// node's internal conversion helpers.
+func gainToDB(gain float32) float32 {
+	if gain <= 0 {
+		return node.MixerSilenceDB
+	}
+	db := float32(20 * math.Log10(float64(gain)))
+	if db < node.MixerSilenceDB {
+		return node.MixerSilenceDB
+	}
+	return db
+}
+
+func dbToGain(db float32) float32 {
+	if db <= node.MixerSilenceDB {
+		return 0
+	}
+	gain := float32(math.Pow(10, float64(db)/20))
+	if gain > 1 {
+		return 1
+	}
+	return gain
+}
@@ -0,0 +1,102 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestSetVolumeRampReachesTarget checks that SetVolumeRamp lands exactly on
+// target once its duration elapses, dispatched through bc's own
+// queue.Dispatcher rather than RampVolume's unscheduled ticker.
+func TestSetVolumeRampReachesTarget(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	ch, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer ch.Release()
+
+	if err := ch.SetVolume(0.2); err != nil {
+		t.Fatalf("set initial volume: %v", err)
+	}
+	if err := ch.SetVolumeRamp(0.9, 40*time.Millisecond, CurveLinear); err != nil {
+		t.Fatalf("set volume ramp: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if v, err := ch.GetVolume(); err != nil || v != 0.9 {
+		t.Errorf("expected volume to land on 0.9, got %.2f (err %v)", v, err)
+	}
+}
+
+// TestSetSendLevelRampSupersedesEarlierRamp checks that a second
+// SetSendLevelRamp call on the same send cancels the first rather than
+// combining with it, landing on the second call's target.
+func TestSetSendLevelRampSupersedesEarlierRamp(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	lead, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer lead.Release()
+
+	if err := lead.CreateSend("reverb", &mockChannel{name: "dst"}, 0.1); err != nil {
+		t.Fatalf("create send: %v", err)
+	}
+
+	if err := lead.SetSendLevelRamp("reverb", 1.0, time.Second, CurveLinear); err != nil {
+		t.Fatalf("start first ramp: %v", err)
+	}
+	if err := lead.SetSendLevelRamp("reverb", 0.5, 40*time.Millisecond, CurveLinear); err != nil {
+		t.Fatalf("start superseding ramp: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if v, err := lead.GetSendLevel("reverb"); err != nil || v != 0.5 {
+		t.Errorf("expected send level to land on 0.5 (superseding ramp), got %.2f (err %v)", v, err)
+	}
+}
+
+// TestGetSetSendLevelDBRoundTrip checks that SetSendLevelDB/GetSendLevelDB
+// round-trip through the same gain a direct SetSendLevel/GetSendLevel
+// call would see.
+func TestGetSetSendLevelDBRoundTrip(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	lead, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new channel: %v", err)
+	}
+	defer lead.Release()
+
+	if err := lead.CreateSend("reverb", &mockChannel{name: "dst"}, 0.5); err != nil {
+		t.Fatalf("create send: %v", err)
+	}
+
+	if err := lead.SetSendLevelDB("reverb", 0); err != nil {
+		t.Fatalf("set send level db: %v", err)
+	}
+	if v, err := lead.GetSendLevel("reverb"); err != nil || v != 1.0 {
+		t.Errorf("expected 0dB to be unity gain (1.0), got %.2f (err %v)", v, err)
+	}
+	if db, err := lead.GetSendLevelDB("reverb"); err != nil || db != 0 {
+		t.Errorf("expected GetSendLevelDB to report 0dB, got %.2f (err %v)", db, err)
+	}
+}
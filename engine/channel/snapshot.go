@@ -0,0 +1,268 @@
+package channel
+
+import (
+	"fmt"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/pluginchain"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// SendSnapshot captures one named send's destination, routing bus, level,
+// mute and mode at the moment a ChannelSnapshot was taken. DestinationName
+// and BusName record the send's Channel/Bus by stable name rather than by
+// pointer, since RestoreSnapshot may run against a freshly built engine
+// whose node pointers differ from the ones live when Snapshot ran.
+type SendSnapshot struct {
+	Name            string   `json:"name"`
+	DestinationName string   `json:"destinationName"`
+	BusName         string   `json:"busName"`
+	Level           float32  `json:"level"`
+	Mute            bool     `json:"mute"`
+	Mode            SendMode `json:"mode"`
+}
+
+// ChannelSnapshot captures a BaseChannel's mix state, sends, plugin chain
+// and master-connection/metering state, for session save/recall, A/B scene
+// switching and undo - see BaseChannel.Snapshot and RestoreSnapshot.
+type ChannelSnapshot struct {
+	Name              string                    `json:"name"`
+	DisplayName       string                    `json:"displayName"`
+	Volume            float32                   `json:"volume"`
+	Pan               float32                   `json:"pan"`
+	UserMuted         bool                      `json:"userMuted"`
+	Soloed            bool                      `json:"soloed"`
+	ConnectedToMaster bool                      `json:"connectedToMaster"`
+	MeterEnabled      bool                      `json:"meterEnabled"`
+	Sends             []SendSnapshot            `json:"sends"`
+	PluginChain       pluginchain.ChainSnapshot `json:"pluginChain"`
+	// Plugins identifies each PluginChain.Effects entry (same order) well
+	// enough to recreate it via AddEffectFromPluginInfo against a fresh
+	// chain that doesn't have the effect yet - PluginChain.ChainSnapshot
+	// alone only carries a plugin name and parameter values, not what
+	// RestoreSnapshot needs to introspect and instantiate the AU.
+	Plugins []plugins.PluginInfo `json:"plugins"`
+}
+
+// Snapshot captures bc's current mix state, sends, plugin chain identity
+// and parameters, master-connection state, and metering enablement into a
+// ChannelSnapshot suitable for JSON encoding - see RestoreSnapshot.
+func (bc *BaseChannel) Snapshot() (ChannelSnapshot, error) {
+	if bc.released {
+		return ChannelSnapshot{}, fmt.Errorf("channel has been released")
+	}
+	volume, err := bc.GetVolume()
+	if err != nil {
+		return ChannelSnapshot{}, fmt.Errorf("snapshot volume: %w", err)
+	}
+	pan, err := bc.GetPan()
+	if err != nil {
+		return ChannelSnapshot{}, fmt.Errorf("snapshot pan: %w", err)
+	}
+
+	bc.sendsMu.RLock()
+	sends := make([]SendSnapshot, 0, len(bc.sendOrder))
+	for _, name := range bc.sendOrder {
+		send, ok := bc.sends[name]
+		if !ok {
+			continue
+		}
+		destName := ""
+		if send.Destination != nil {
+			destName = send.Destination.GetName()
+		}
+		busName := ""
+		if b, ok := bc.sendBuses[name]; ok && b != nil {
+			busName = b.Name()
+		}
+		sends = append(sends, SendSnapshot{
+			Name:            name,
+			DestinationName: destName,
+			BusName:         busName,
+			Level:           send.Level,
+			Mute:            send.Mute,
+			Mode:            send.Mode,
+		})
+	}
+	bc.sendsMu.RUnlock()
+
+	var chainSnap pluginchain.ChainSnapshot
+	var pluginInfos []plugins.PluginInfo
+	if bc.pluginChain != nil {
+		count := bc.pluginChain.GetEffectCount()
+		chainSnap.Effects = make([]pluginchain.EffectSnapshot, 0, count)
+		pluginInfos = make([]plugins.PluginInfo, 0, count)
+		for i := 0; i < count; i++ {
+			_, plugin, err := bc.pluginChain.GetEffectAt(i)
+			if err != nil {
+				return ChannelSnapshot{}, fmt.Errorf("snapshot effect %d: %w", i, err)
+			}
+			bypassed, err := bc.pluginChain.IsEffectBypassed(i)
+			if err != nil {
+				return ChannelSnapshot{}, fmt.Errorf("snapshot effect %d bypass: %w", i, err)
+			}
+			params := make(map[uint64]float32, len(plugin.Parameters))
+			for _, p := range plugin.Parameters {
+				params[p.Address] = p.CurrentValue
+			}
+			chainSnap.Effects = append(chainSnap.Effects, pluginchain.EffectSnapshot{
+				PluginName: plugin.Name,
+				Bypassed:   bypassed,
+				Parameters: params,
+			})
+			pluginInfos = append(pluginInfos, plugins.PluginInfo{
+				Name:           plugin.Name,
+				ManufacturerID: plugin.ManufacturerID,
+				Type:           plugin.Type,
+				Subtype:        plugin.Subtype,
+				Category:       plugin.Category,
+				BundlePath:     plugin.BundlePath,
+				Version:        plugin.Version,
+				BundleModTime:  plugin.BundleModTime,
+			})
+		}
+	}
+
+	bc.meterMu.RLock()
+	meterEnabled := bc.meterTap != nil && bc.meterTap.IsInstalled()
+	bc.meterMu.RUnlock()
+
+	return ChannelSnapshot{
+		Name:              bc.name,
+		DisplayName:       bc.displayName,
+		Volume:            volume,
+		Pan:               pan,
+		UserMuted:         bc.userMuted,
+		Soloed:            bc.IsSoloed(),
+		ConnectedToMaster: bc.connectedToMaster,
+		MeterEnabled:      meterEnabled,
+		Sends:             sends,
+		PluginChain:       chainSnap,
+		Plugins:           pluginInfos,
+	}, nil
+}
+
+// RestoreSnapshot rebuilds ch's mix state, sends, plugin chain and
+// master/metering state from snap, against eng. It's idempotent over a
+// live graph: ConnectToMaster/DisconnectFromMaster and EnableOutputMetering
+// already no-op when the channel is already in the requested state (see
+// their own connectedToMaster/meterTap guards), and a send already present
+// under a snapshot's name has its level/mute reapplied rather than being
+// recreated.
+//
+// resolveChannel and resolveBus look up a send's recorded DestinationName/
+// BusName against whatever registry the caller is restoring into - restore
+// may run on a freshly built engine whose channel.Channel/Bus values (and
+// node pointers) are not the ones snap.Sends' names refer to, so resolution
+// has to go through the caller rather than a stored pointer.
+func RestoreSnapshot(eng *engine.Engine, ch *BaseChannel, snap ChannelSnapshot, resolveChannel func(name string) (Channel, bool), resolveBus func(name string) (*Bus, bool)) error {
+	if ch == nil {
+		return fmt.Errorf("channel cannot be nil")
+	}
+	if ch.released {
+		return fmt.Errorf("channel has been released")
+	}
+
+	if err := ch.SetVolume(snap.Volume); err != nil {
+		return fmt.Errorf("restore volume: %w", err)
+	}
+	if err := ch.SetPan(snap.Pan); err != nil {
+		return fmt.Errorf("restore pan: %w", err)
+	}
+	if err := ch.SetMute(snap.UserMuted); err != nil {
+		return fmt.Errorf("restore mute: %w", err)
+	}
+	ch.SetSolo(snap.Soloed)
+
+	if ch.pluginChain != nil && len(snap.Plugins) > 0 {
+		if ch.pluginChain.GetEffectCount() == 0 {
+			for _, info := range snap.Plugins {
+				if err := ch.pluginChain.AddEffectFromPluginInfo(info); err != nil {
+					return fmt.Errorf("restore plugin %s: %w", info.Name, err)
+				}
+			}
+		}
+		if err := applyChainSnapshot(ch.pluginChain, snap.PluginChain); err != nil {
+			return fmt.Errorf("restore plugin chain: %w", err)
+		}
+	}
+
+	for _, ss := range snap.Sends {
+		ch.sendsMu.RLock()
+		_, exists := ch.sends[ss.Name]
+		ch.sendsMu.RUnlock()
+		if !exists {
+			if resolveChannel == nil || resolveBus == nil {
+				return fmt.Errorf("restore send %q: channel %q has no send %q and no resolver was supplied to recreate it", ss.Name, ch.name, ss.Name)
+			}
+			dest, ok := resolveChannel(ss.DestinationName)
+			if !ok {
+				return fmt.Errorf("restore send %q: destination %q not found", ss.Name, ss.DestinationName)
+			}
+			bus, ok := resolveBus(ss.BusName)
+			if !ok {
+				return fmt.Errorf("restore send %q: bus %q not found", ss.Name, ss.BusName)
+			}
+			if _, err := ch.CreateAndConnectSend(ss.Name, dest, bus, ss.Level, ss.Mode); err != nil {
+				return fmt.Errorf("restore send %q: %w", ss.Name, err)
+			}
+			continue
+		}
+		if err := ch.SetSendLevel(ss.Name, ss.Level); err != nil {
+			return fmt.Errorf("restore send %q level: %w", ss.Name, err)
+		}
+		if err := ch.SetSendMute(ss.Name, ss.Mute); err != nil {
+			return fmt.Errorf("restore send %q mute: %w", ss.Name, err)
+		}
+	}
+
+	if snap.ConnectedToMaster {
+		if err := ch.ConnectToMaster(eng); err != nil {
+			return fmt.Errorf("restore master connection: %w", err)
+		}
+	} else if err := ch.DisconnectFromMaster(eng); err != nil {
+		return fmt.Errorf("restore master disconnection: %w", err)
+	}
+
+	if snap.MeterEnabled {
+		if err := ch.EnableOutputMetering(eng, true); err != nil {
+			return fmt.Errorf("restore metering: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// applyChainSnapshot applies snap's per-effect bypass/parameter state onto
+// pc's current effects, matching them up by position and plugin name. It
+// duplicates pluginchain.PluginChain.LoadSnapshot's matching logic rather
+// than calling it directly: LoadSnapshot only operates on a slot previously
+// populated by SaveSnapshot, and snap here was just decoded from a
+// ChannelSnapshot, not stashed into pc's own snapshot slots.
+func applyChainSnapshot(pc *pluginchain.PluginChain, snap pluginchain.ChainSnapshot) error {
+	if len(snap.Effects) != pc.GetEffectCount() {
+		return fmt.Errorf("snapshot has %d effects, chain has %d", len(snap.Effects), pc.GetEffectCount())
+	}
+	for i, effectSnap := range snap.Effects {
+		_, plugin, err := pc.GetEffectAt(i)
+		if err != nil {
+			return err
+		}
+		if effectSnap.PluginName != plugin.Name {
+			return fmt.Errorf("snapshot effect %d is %q, chain effect %d is %q", i, effectSnap.PluginName, i, plugin.Name)
+		}
+		if err := pc.SetEffectBypass(i, effectSnap.Bypassed); err != nil {
+			return err
+		}
+		for _, param := range plugin.Parameters {
+			value, ok := effectSnap.Parameters[param.Address]
+			if !ok {
+				continue
+			}
+			if err := pc.SetParameter(i, param, value); err != nil {
+				return fmt.Errorf("restore parameter %s on effect %d: %w", param.Identifier, i, err)
+			}
+		}
+	}
+	return nil
+}
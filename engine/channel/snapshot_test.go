@@ -0,0 +1,118 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestChannelSnapshotRestoreRoundTrip checks that Snapshot captures mix
+// state, a send, and master connection, and that RestoreSnapshot reapplies
+// all of it onto a channel that has since drifted away from it - including
+// recreating the send from scratch via the resolve callbacks, since this
+// restore target starts out with no sends of its own.
+func TestChannelSnapshotRestoreRoundTrip(t *testing.T) {
+	eng, err := engine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	bus, err := NewBus(eng, "verb")
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer bus.Release()
+
+	dest, err := NewBaseChannel(BaseChannelConfig{Name: "reverb-return", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new dest channel: %v", err)
+	}
+	defer dest.Release()
+
+	src, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new src channel: %v", err)
+	}
+	defer src.Release()
+
+	if err := src.SetVolume(0.6); err != nil {
+		t.Fatalf("set volume: %v", err)
+	}
+	if err := src.SetPan(-0.3); err != nil {
+		t.Fatalf("set pan: %v", err)
+	}
+	if _, err := src.CreateAndConnectSend("verb", dest, bus, 0.4, PostFader); err != nil {
+		t.Fatalf("create send: %v", err)
+	}
+	if err := src.ConnectToMaster(eng); err != nil {
+		t.Fatalf("connect to master: %v", err)
+	}
+
+	snap, err := src.Snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(snap.Sends) != 1 || snap.Sends[0].Name != "verb" || snap.Sends[0].DestinationName != "reverb-return" || snap.Sends[0].BusName != "verb" {
+		t.Fatalf("expected one send to reverb-return/verb, got %#v", snap.Sends)
+	}
+
+	// Drift the channel away from the snapshot.
+	if err := src.SetVolume(0.1); err != nil {
+		t.Fatalf("drift volume: %v", err)
+	}
+	if err := src.SetSendLevel("verb", 0.9); err != nil {
+		t.Fatalf("drift send level: %v", err)
+	}
+
+	target, err := NewBaseChannel(BaseChannelConfig{Name: "lead", EnginePtr: eng.Ptr(), EngineInstance: eng})
+	if err != nil {
+		t.Fatalf("new restore target: %v", err)
+	}
+	defer target.Release()
+
+	resolveChannel := func(name string) (Channel, bool) {
+		if name == dest.GetName() {
+			return dest, true
+		}
+		return nil, false
+	}
+	resolveBus := func(name string) (*Bus, bool) {
+		if name == "verb" {
+			return bus, true
+		}
+		return nil, false
+	}
+
+	if err := RestoreSnapshot(eng, target, snap, resolveChannel, resolveBus); err != nil {
+		t.Fatalf("restore snapshot: %v", err)
+	}
+
+	if vol, err := target.GetVolume(); err != nil || vol != 0.6 {
+		t.Errorf("expected restored volume 0.6, got %f (err %v)", vol, err)
+	}
+	if pan, err := target.GetPan(); err != nil || pan != -0.3 {
+		t.Errorf("expected restored pan -0.3, got %f (err %v)", pan, err)
+	}
+	if !target.IsConnectedToMaster() {
+		t.Error("expected restored channel to be connected to master")
+	}
+	sends := target.GetSends()
+	send, ok := sends["verb"]
+	if !ok {
+		t.Fatalf("expected restored send %q, got %v", "verb", sends)
+	}
+	if send.Level != 0.4 {
+		t.Errorf("expected restored send level 0.4, got %f", send.Level)
+	}
+
+	// Restoring again over the now-matching target should be a no-op, not
+	// a duplicate send or a double master connection.
+	if err := RestoreSnapshot(eng, target, snap, resolveChannel, resolveBus); err != nil {
+		t.Fatalf("restore snapshot again: %v", err)
+	}
+	if len(target.GetSends()) != 1 {
+		t.Errorf("expected restoring twice to stay at one send, got %d", len(target.GetSends()))
+	}
+}
@@ -16,7 +16,7 @@ func testDeviceSetup(t *testing.T) (*devices.AudioDevice, *devices.AudioDevice)
 	// Find an output device
 	var outputDevice *devices.AudioDevice
 	for i, device := range allDevices {
-		if device.CanOutput() && len(device.SupportedSampleRates) > 0 {
+		if device.CanOutput() && len(device.SupportedOutputConfigs()) > 0 {
 			outputDevice = &allDevices[i]
 			break
 		}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shaban/macaudio/engine/queue"
+)
+
+// controlQueueBuffer sizes the Engine's control queue. Generous relative to
+// the 8-channel engine since bursts of rapid Set* calls (e.g. an automation
+// sweep) shouldn't block the caller on a full channel.
+const controlQueueBuffer = 64
+
+// SubmitAsync enqueues op onto the engine's control queue - the single
+// serialization point every mutating Set*/Mute/Play/Create*Channel/
+// DestroyChannel call ultimately goes through - and returns immediately
+// with a channel that receives op's result once it's actually applied. Ops
+// run one at a time, in FIFO order, on the queue's own worker goroutine
+// (never the caller's). This is the async counterpart to the sync
+// Set*/Mute/Play wrappers, which call SubmitAsync internally via runSync
+// and block for the result themselves. Mirrors the message-passing
+// AudioController/App split the `savanni` project uses, and CPAL's move
+// from a shared EventLoop to per-op submission.
+func (e *Engine) SubmitAsync(ctx context.Context, op queue.Op) <-chan error {
+	result := make(chan error, 1)
+	if e == nil || e.opQueue == nil {
+		result <- errors.New("engine: control queue not initialized")
+		return result
+	}
+
+	if err := e.opQueue.Enqueue(queue.Func(func(ctx context.Context) error {
+		err := op.Apply(ctx)
+		result <- err
+		return err
+	})); err != nil {
+		result <- err
+	}
+	return result
+}
+
+// runSync submits fn to the control queue and blocks for its result. Every
+// sync Set*/Mute/Play/Create*Channel/DestroyChannel method calls this
+// rather than applying its native work directly, so sync and async callers
+// serialize through the same FIFO. Channels built by hand with no owning
+// engine (e.opQueue == nil) fall back to running fn directly - see
+// Channel.runOnEngine, the equivalent fallback on the Channel side.
+func (e *Engine) runSync(ctx context.Context, fn func(ctx context.Context) error) error {
+	if e == nil || e.opQueue == nil {
+		return fn(ctx)
+	}
+	result := e.SubmitAsync(ctx, queue.Func(fn))
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain blocks until every operation enqueued before this call has been
+// applied, without closing the queue - Stop uses it to let in-flight
+// mutations settle before halting the native engine, while leaving the
+// queue usable if Start resumes the engine afterward. Close (called from
+// Destroy) is the irreversible teardown.
+func (e *Engine) drain() {
+	if e.opQueue == nil {
+		return
+	}
+	<-e.SubmitAsync(context.Background(), queue.Func(func(ctx context.Context) error { return nil }))
+}
@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/devices"
+)
+
+// errControllerClosed is returned by Submit/SubmitSync once Close has run.
+var errControllerClosed = errors.New("controller: already closed")
+
+// controllerCommandBuffer sizes Controller's inbound command channel,
+// matching controlQueueBuffer's reasoning: generous enough that a burst of
+// Submit calls from several goroutines doesn't block any of them on a full
+// channel.
+const controllerCommandBuffer = 64
+
+// controllerEventBuffer sizes each Subscribe call's own Event channel.
+const controllerEventBuffer = 32
+
+// Command is one request Controller.Submit accepts, applied against the
+// Engine it owns from Controller's own run loop - never concurrently with
+// any other Command, and never on the caller's goroutine.
+type Command interface {
+	apply(ctrl *Controller) error
+}
+
+// Controller is a message-passing façade over an Engine: instead of
+// several goroutines (a CLI, an HTTP handler, a WebSocket push loop)
+// calling Channel.SetVolume/Mute or Engine.Start/Stop directly and racing
+// each other on the same shared state, each sends a Command on a channel
+// and gets its result back on a private reply channel. It's built on top
+// of the Engine's existing synchronous methods rather than replacing them
+// - those already serialize through the engine's own control queue (see
+// control_queue.go) - so Controller's run loop adds a second, outer
+// serialization point plus the typed Command/Event vocabulary, not a new
+// way of touching the native engine.
+type Controller struct {
+	engine *Engine
+
+	commands chan commandEnvelope
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	eventsMu  sync.Mutex
+	eventSubs map[int]chan Event
+	nextSubID int
+}
+
+type commandEnvelope struct {
+	cmd   Command
+	reply chan error
+}
+
+// NewController starts a Controller owning e, running its command loop on
+// a dedicated goroutine until Close is called.
+func NewController(e *Engine) *Controller {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctrl := &Controller{
+		engine:    e,
+		commands:  make(chan commandEnvelope, controllerCommandBuffer),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		eventSubs: make(map[int]chan Event),
+	}
+	go ctrl.run()
+	return ctrl
+}
+
+// run is Controller's dedicated goroutine: it applies one Command at a
+// time, in FIFO order, until Close cancels ctrl.ctx. Shutdown is signaled
+// by context cancellation rather than closing ctrl.commands, the same
+// choice queue.Queue.Start makes, since a Submit racing Close would
+// otherwise risk sending on a closed channel.
+func (ctrl *Controller) run() {
+	defer close(ctrl.done)
+	for {
+		select {
+		case env := <-ctrl.commands:
+			env.reply <- env.cmd.apply(ctrl)
+		case <-ctrl.ctx.Done():
+			return
+		}
+	}
+}
+
+// Submit enqueues cmd and returns immediately with a channel that receives
+// its result once run has applied it - the Command counterpart to
+// Engine.SubmitAsync.
+func (ctrl *Controller) Submit(cmd Command) <-chan error {
+	reply := make(chan error, 1)
+	select {
+	case ctrl.commands <- commandEnvelope{cmd: cmd, reply: reply}:
+	case <-ctrl.ctx.Done():
+		reply <- errControllerClosed
+	}
+	return reply
+}
+
+// SubmitSync enqueues cmd and blocks for its result, honoring ctx - the
+// building block a preserved synchronous wrapper (e.g. a ControllerClient
+// exposing SetVolume again as a plain method) would call instead of
+// reaching into the Engine directly.
+func (ctrl *Controller) SubmitSync(ctx context.Context, cmd Command) error {
+	select {
+	case err := <-ctrl.Submit(cmd):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new commands, waits for any already enqueued to
+// finish applying, then publishes EngineStoppedEvent. It does not itself
+// stop the native engine - call Engine.Stop/Destroy separately, the same
+// as before Controller existed.
+func (ctrl *Controller) Close() {
+	ctrl.cancel()
+	<-ctrl.done
+	ctrl.publish(Event{Kind: EngineStoppedEvent})
+}
+
+// channel resolves id to its *Channel, the error every Command that
+// targets one channel returns uniformly for an out-of-range or empty slot.
+func (ctrl *Controller) channel(id int) (*Channel, error) {
+	if id < 0 || id >= len(ctrl.engine.Channels) {
+		return nil, fmt.Errorf("controller: channel %d out of range", id)
+	}
+	ch := ctrl.engine.Channels[id]
+	if ch == nil {
+		return nil, fmt.Errorf("controller: channel %d is empty", id)
+	}
+	return ch, nil
+}
+
+// indexOf returns ch's slot in the engine's fixed channel array - Channel
+// doesn't track its own index (see channel.go's removed BusIndex field),
+// so a Command that creates a channel (LoadFileCmd) has to look it up
+// after the fact to report it on ChannelAddedEvent.
+func (ctrl *Controller) indexOf(ch *Channel) (int, error) {
+	for i, c := range ctrl.engine.Channels {
+		if c == ch {
+			return i, nil
+		}
+	}
+	return -1, errors.New("controller: created channel not found in engine.Channels")
+}
+
+// StartCmd starts the engine - the Command counterpart to Engine.Start.
+type StartCmd struct{}
+
+func (StartCmd) apply(ctrl *Controller) error {
+	if err := ctrl.engine.Start(); err != nil {
+		return err
+	}
+	ctrl.publish(Event{Kind: EngineStartedEvent})
+	return nil
+}
+
+// StopCmd stops the engine - the Command counterpart to Engine.Stop.
+type StopCmd struct{}
+
+func (StopCmd) apply(ctrl *Controller) error {
+	ctrl.engine.Stop()
+	ctrl.publish(Event{Kind: EngineStoppedEvent})
+	return nil
+}
+
+// SetMasterVolumeCmd sets the engine's master volume - the Command
+// counterpart to Engine.SetMasterVolume.
+type SetMasterVolumeCmd struct {
+	Value float32
+}
+
+func (cmd SetMasterVolumeCmd) apply(ctrl *Controller) error {
+	return ctrl.engine.SetMasterVolume(cmd.Value)
+}
+
+// SetVolumeCmd sets ChannelID's volume - the Command counterpart to
+// Channel.SetVolume.
+type SetVolumeCmd struct {
+	ChannelID int
+	Value     float32
+}
+
+func (cmd SetVolumeCmd) apply(ctrl *Controller) error {
+	ch, err := ctrl.channel(cmd.ChannelID)
+	if err != nil {
+		return err
+	}
+	return ch.SetVolume(cmd.Value)
+}
+
+// SetMuteCmd mutes or unmutes ChannelID - the Command counterpart to
+// Channel.Mute/Unmute.
+type SetMuteCmd struct {
+	ChannelID int
+	Muted     bool
+}
+
+func (cmd SetMuteCmd) apply(ctrl *Controller) error {
+	ch, err := ctrl.channel(cmd.ChannelID)
+	if err != nil {
+		return err
+	}
+	if cmd.Muted {
+		return ch.Mute()
+	}
+	return ch.Unmute()
+}
+
+// InstallTapCmd installs cb on ChannelID - the Command counterpart to
+// Channel.InstallTap.
+type InstallTapCmd struct {
+	ChannelID int
+	Callback  func(buf AudioBuffer, when AudioTime)
+}
+
+func (cmd InstallTapCmd) apply(ctrl *Controller) error {
+	ch, err := ctrl.channel(cmd.ChannelID)
+	if err != nil {
+		return err
+	}
+	return ch.InstallTap(cmd.Callback)
+}
+
+// LoadFileCmd loads Path into a new playback channel - the Command
+// counterpart to Engine.CreatePlaybackChannel. ChannelID is set to the new
+// channel's slot once apply has run; a caller reads it only after the
+// reply channel Submit returned has received a nil error.
+type LoadFileCmd struct {
+	Path      string
+	ChannelID int
+}
+
+func (cmd *LoadFileCmd) apply(ctrl *Controller) error {
+	ch, err := ctrl.engine.CreatePlaybackChannel(cmd.Path)
+	if err != nil {
+		return err
+	}
+	idx, err := ctrl.indexOf(ch)
+	if err != nil {
+		return err
+	}
+	cmd.ChannelID = idx
+	ctrl.publish(Event{Kind: ChannelAddedEvent, ChannelID: idx})
+	return nil
+}
+
+// EventKind identifies what an Event carries, mirroring devices.DeviceEvent
+// and session's ChangeType-tagged event structs rather than a Go
+// interface, so a subscriber can switch on Kind without a type assertion.
+type EventKind int
+
+const (
+	EngineStartedEvent EventKind = iota
+	EngineStoppedEvent
+	ChannelAddedEvent
+	MeterUpdateEvent
+	DeviceChangedEvent
+	TapOverflowEvent
+)
+
+// Event is one message on Controller's outbound Events feed - see
+// Subscribe. Only the fields relevant to Kind are populated.
+type Event struct {
+	Kind EventKind
+
+	ChannelID int // ChannelAddedEvent, MeterUpdateEvent, TapOverflowEvent
+
+	Meter tap.MeterSnapshot // MeterUpdateEvent's payload, see avaudio/tap.MeterSnapshot
+
+	Device devices.AudioDevice // DeviceChangedEvent's payload
+
+	Err error // TapOverflowEvent's payload
+}
+
+// Subscribe registers a new listener for Controller's outbound Events,
+// returning the channel to receive them on and an unsubscribe function
+// that closes it. A subscriber that falls behind drops events rather than
+// blocking publish (and every other subscriber with it) - the same
+// best-effort delivery devices.Watcher's Events channel uses.
+func (ctrl *Controller) Subscribe() (<-chan Event, func()) {
+	ctrl.eventsMu.Lock()
+	defer ctrl.eventsMu.Unlock()
+
+	id := ctrl.nextSubID
+	ctrl.nextSubID++
+	ch := make(chan Event, controllerEventBuffer)
+	ctrl.eventSubs[id] = ch
+
+	return ch, func() {
+		ctrl.eventsMu.Lock()
+		defer ctrl.eventsMu.Unlock()
+		if sub, ok := ctrl.eventSubs[id]; ok {
+			delete(ctrl.eventSubs, id)
+			close(sub)
+		}
+	}
+}
+
+// publish fans ev out to every current subscriber.
+func (ctrl *Controller) publish(ev Event) {
+	ctrl.eventsMu.Lock()
+	defer ctrl.eventsMu.Unlock()
+	for _, ch := range ctrl.eventSubs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// NotifyMeterUpdate publishes a MeterUpdateEvent for channelID carrying
+// snapshot - the hook a caller polling tap.Tap.GetMeter on its own
+// goroutine (see avaudio/tap/meter.go) feeds into, since Controller has no
+// metering loop of its own.
+func (ctrl *Controller) NotifyMeterUpdate(channelID int, snapshot tap.MeterSnapshot) {
+	ctrl.publish(Event{Kind: MeterUpdateEvent, ChannelID: channelID, Meter: snapshot})
+}
+
+// NotifyDeviceChanged publishes a DeviceChangedEvent carrying device - the
+// hook a devices.Watch callback or Engine.WatchDevices caller feeds into,
+// since Controller doesn't start its own device watcher.
+func (ctrl *Controller) NotifyDeviceChanged(device devices.AudioDevice) {
+	ctrl.publish(Event{Kind: DeviceChangedEvent, Device: device})
+}
+
+// NotifyTapOverflow publishes a TapOverflowEvent for channelID carrying
+// err - the hook a Channel.InstallTap callback (or a tap.Subscribe
+// consumer) feeds into when it detects its own delivery falling behind.
+func (ctrl *Controller) NotifyTapOverflow(channelID int, err error) {
+	ctrl.publish(Event{Kind: TapOverflowEvent, ChannelID: channelID, Err: err})
+}
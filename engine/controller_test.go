@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestControllerSubmitAppliesCommandAndReportsResult exercises the basic
+// round-trip: a Command submitted on one goroutine gets applied on
+// Controller's run loop and its result observed on Submit's reply channel.
+func TestControllerSubmitAppliesCommandAndReportsResult(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	ctrl := NewController(engine)
+	defer ctrl.Close()
+
+	err := <-ctrl.Submit(SetMasterVolumeCmd{Value: 0.42})
+	if err != nil {
+		t.Fatalf("Submit(SetMasterVolumeCmd): %v", err)
+	}
+	if engine.MasterVolume != 0.42 {
+		t.Errorf("MasterVolume = %v, want 0.42", engine.MasterVolume)
+	}
+}
+
+// TestControllerSubmitSyncHonorsContext checks that SubmitSync returns
+// ctx.Err() rather than blocking forever when the context is already done.
+func TestControllerSubmitSyncHonorsContext(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	ctrl := NewController(engine)
+	defer ctrl.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Give the run loop a moment to be ready so this isn't racing Close.
+	time.Sleep(time.Millisecond)
+
+	if err := ctrl.SubmitSync(ctx, SetMasterVolumeCmd{Value: 0.1}); err != ctx.Err() {
+		t.Errorf("SubmitSync with a cancelled context = %v, want %v", err, ctx.Err())
+	}
+}
+
+// TestControllerSetVolumeCmdRejectsOutOfRangeChannel checks that a Command
+// targeting an empty channel slot fails cleanly instead of panicking on a
+// nil Channel.
+func TestControllerSetVolumeCmdRejectsOutOfRangeChannel(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	ctrl := NewController(engine)
+	defer ctrl.Close()
+
+	if err := <-ctrl.Submit(SetVolumeCmd{ChannelID: len(engine.Channels), Value: 0.5}); err == nil {
+		t.Error("expected SetVolumeCmd on an out-of-range channel to fail")
+	}
+	if err := <-ctrl.Submit(SetVolumeCmd{ChannelID: 0, Value: 0.5}); err == nil {
+		t.Error("expected SetVolumeCmd on an empty channel slot to fail")
+	}
+}
+
+// TestControllerSubscribePublishesEngineStoppedOnClose checks that Close
+// publishes EngineStoppedEvent to any current subscriber.
+func TestControllerSubscribePublishesEngineStoppedOnClose(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	ctrl := NewController(engine)
+	events, unsubscribe := ctrl.Subscribe()
+	defer unsubscribe()
+
+	ctrl.Close()
+
+	select {
+	case ev := <-events:
+		if ev.Kind != EngineStoppedEvent {
+			t.Errorf("Kind = %v, want EngineStoppedEvent", ev.Kind)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for EngineStoppedEvent")
+	}
+}
+
+// TestControllerSubmitAfterCloseReturnsError checks that Submit doesn't
+// block forever once Close has already run.
+func TestControllerSubmitAfterCloseReturnsError(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	ctrl := NewController(engine)
+	ctrl.Close()
+
+	if err := <-ctrl.Submit(SetMasterVolumeCmd{Value: 0.2}); err == nil {
+		t.Error("expected Submit after Close to return an error")
+	}
+}
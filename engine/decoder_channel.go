@@ -0,0 +1,198 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/shaban/macaudio/format/decoder"
+)
+
+// CreatePlaybackChannelFromDecoder creates a playback channel fed by a
+// format/decoder.Decoder rather than a file path (see CreatePlaybackChannel)
+// or a pull-style StreamDecoder (see CreateStreamPlaybackChannel). It
+// builds the same Player -> TimePitch -> ChannelMixer -> MainMixer graph,
+// but a background goroutine reads decoder-pushed AudioBlocks off
+// dec.Blocks(), converts each block's native sample type (int16/int32/
+// float32 - see blockToFloat32) to the float32 audioplayer_schedule_buffer
+// expects, and schedules it onto the native player. The goroutine exits
+// (closing dec) once Blocks() closes or the channel is destroyed.
+func (e *Engine) CreatePlaybackChannelFromDecoder(dec decoder.Decoder) (*Channel, error) {
+	if e.nativeEngine == nil {
+		return nil, errors.New("engine is not properly initialized")
+	}
+	if dec == nil {
+		return nil, errors.New("decoder cannot be nil")
+	}
+
+	channel := &Channel{
+		Volume: 1.0,
+		Pan:    0.0,
+		PlaybackOptions: &PlaybackOptions{
+			Rate:     1.0,
+			Pitch:    0.0,
+			Streamed: true,
+		},
+		engine: e,
+	}
+	defaultChannelSolo.register(channel)
+
+	result := C.audioplayer_new(unsafe.Pointer(e.nativeEngine.engine))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	playerPtr := (*C.AudioPlayer)(result.result)
+	channel.PlaybackOptions.playerPtr = result.result
+
+	if errorStr := C.audioplayer_enable_time_pitch_effects(playerPtr); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to enable time/pitch effects: " + C.GoString(errorStr))
+	}
+
+	nodeResult := C.audioplayer_get_node_ptr(playerPtr)
+	if nodeResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to get player node: " + C.GoString(nodeResult.error))
+	}
+
+	timePitchResult := C.audioplayer_get_time_pitch_node_ptr(playerPtr)
+	if timePitchResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to get time/pitch node: " + C.GoString(timePitchResult.error))
+	}
+
+	channelMixerResult := C.audioengine_create_mixer_node(e.nativeEngine)
+	if channelMixerResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to create channel mixer: " + C.GoString(channelMixerResult.error))
+	}
+	channel.mixerNodePtr = channelMixerResult.result
+
+	if errorStr := C.audioengine_attach(e.nativeEngine, nodeResult.result); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to attach player to engine: " + C.GoString(errorStr))
+	}
+	if errorStr := C.audioengine_attach(e.nativeEngine, timePitchResult.result); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to attach time/pitch unit to engine: " + C.GoString(errorStr))
+	}
+	if errorStr := C.audioengine_attach(e.nativeEngine, channelMixerResult.result); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to attach channel mixer to engine: " + C.GoString(errorStr))
+	}
+
+	if errorStr := C.audioengine_connect(e.nativeEngine, nodeResult.result, timePitchResult.result, 0, 0); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to connect player to time/pitch unit: " + C.GoString(errorStr))
+	}
+	if errorStr := C.audioengine_connect(e.nativeEngine, timePitchResult.result, channelMixerResult.result, 0, 0); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to connect time/pitch unit to channel mixer: " + C.GoString(errorStr))
+	}
+
+	mainMixerResult := C.audioengine_main_mixer_node(e.nativeEngine)
+	if mainMixerResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to get main mixer: " + C.GoString(mainMixerResult.error))
+	}
+
+	busIndex, err := e.AllocateBusForChannel(channel)
+	if err != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to allocate bus for channel: " + err.Error())
+	}
+
+	if errorStr := C.audioengine_connect(e.nativeEngine, channelMixerResult.result, mainMixerResult.result, 0, C.int(busIndex)); errorStr != nil {
+		e.FreeBusForChannel(channel)
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to connect channel mixer to main mixer: " + C.GoString(errorStr))
+	}
+
+	if err := e.runSync(context.Background(), func(ctx context.Context) error {
+		e.Channels = append(e.Channels, channel)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	channel.startDecoderFeeder(dec)
+	return channel, nil
+}
+
+// startDecoderFeeder launches the goroutine that pulls AudioBlocks off
+// dec.Blocks() and schedules each one onto the native player, converting
+// to float32 along the way (see blockToFloat32). It reuses PlaybackOptions'
+// streamStop/streamDone/streamOnce lifecycle fields, the same ones
+// startStreamFeeder (stream_player.go) uses, so stopStreamFeeder tears
+// down a decoder-fed channel the same way it tears down a pull-style one.
+func (c *Channel) startDecoderFeeder(dec decoder.Decoder) {
+	c.PlaybackOptions.streamStop = make(chan struct{})
+	c.PlaybackOptions.streamDone = make(chan struct{})
+
+	go func() {
+		defer close(c.PlaybackOptions.streamDone)
+		defer dec.Close()
+
+		for {
+			select {
+			case <-c.PlaybackOptions.streamStop:
+				return
+			case block, ok := <-dec.Blocks():
+				if !ok {
+					return
+				}
+				if block.Channels <= 0 {
+					continue
+				}
+
+				samples, err := blockToFloat32(block)
+				if err != nil || len(samples) == 0 {
+					continue
+				}
+
+				playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
+				errorStr := C.audioplayer_schedule_buffer(
+					playerPtr,
+					(*C.float)(unsafe.Pointer(&samples[0])),
+					C.int(len(samples)/block.Channels),
+					C.int(block.Channels),
+					C.int(block.SampleRate),
+				)
+				if errorStr != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// blockToFloat32 converts block.Samples - []int16, []int32, or []float32,
+// whichever the Decoder delivered - to interleaved float32 in [-1, 1] for
+// audioplayer_schedule_buffer, which only accepts float32. A []float32
+// block (e.g. from decoder.OpenOpus) passes through unconverted.
+func blockToFloat32(block decoder.AudioBlock) ([]float32, error) {
+	switch s := block.Samples.(type) {
+	case []float32:
+		return s, nil
+	case []int16:
+		out := make([]float32, len(s))
+		for i, v := range s {
+			out[i] = float32(v) / 32768.0
+		}
+		return out, nil
+	case []int32:
+		out := make([]float32, len(s))
+		for i, v := range s {
+			out[i] = float32(v) / 2147483648.0
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("decoder: unsupported sample type %T", block.Samples)
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/format/decoder"
+)
+
+func TestCreatePlaybackChannelFromDecoderRejectsUninitializedEngine(t *testing.T) {
+	var e Engine
+	if _, err := e.CreatePlaybackChannelFromDecoder(&fakeBlockDecoder{}); err == nil {
+		t.Error("expected an error creating a decoder channel with no native engine")
+	}
+}
+
+func TestCreatePlaybackChannelFromDecoderRejectsNilDecoder(t *testing.T) {
+	var e Engine
+	e.nativeEngine = nil
+	if _, err := e.CreatePlaybackChannelFromDecoder(nil); err == nil {
+		t.Error("expected an error creating a decoder channel with a nil decoder")
+	}
+}
+
+func TestBlockToFloat32(t *testing.T) {
+	cases := []struct {
+		name  string
+		block decoder.AudioBlock
+		want  []float32
+	}{
+		{"float32 passthrough", decoder.AudioBlock{Samples: []float32{0.5, -0.5}}, []float32{0.5, -0.5}},
+		{"int16 scaled", decoder.AudioBlock{Samples: []int16{16384, -16384}}, []float32{0.5, -0.5}},
+		{"int32 scaled", decoder.AudioBlock{Samples: []int32{1073741824, -1073741824}}, []float32{0.5, -0.5}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := blockToFloat32(c.block)
+			if err != nil {
+				t.Fatalf("blockToFloat32 failed: %v", err)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("got %v, want %v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Errorf("got[%d] = %v, want %v", i, got[i], c.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBlockToFloat32RejectsUnsupportedType(t *testing.T) {
+	if _, err := blockToFloat32(decoder.AudioBlock{Samples: "not audio"}); err == nil {
+		t.Error("expected an error converting an unsupported sample type")
+	}
+}
+
+// fakeBlockDecoder is a minimal decoder.Decoder for tests: it delivers no
+// blocks and closes its channel immediately, since exercising real PCM
+// streaming needs a running AVAudioEngine this sandbox doesn't have.
+type fakeBlockDecoder struct {
+	blocks chan decoder.AudioBlock
+}
+
+func (d *fakeBlockDecoder) Blocks() <-chan decoder.AudioBlock {
+	if d.blocks == nil {
+		d.blocks = make(chan decoder.AudioBlock)
+		close(d.blocks)
+	}
+	return d.blocks
+}
+
+func (d *fakeBlockDecoder) Close() error { return nil }
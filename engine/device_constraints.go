@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// ErrUnsupportedSampleRate is returned when a requested sample rate isn't
+// one the target device's SupportedSampleRates advertises.
+var ErrUnsupportedSampleRate = errors.New("engine: sample rate not supported by device")
+
+// ErrBufferSizeNotPow2 is returned when a requested buffer size (in frames)
+// isn't a power of two, matching the period sizes CoreAudio/ALSA hardware
+// actually accepts.
+var ErrBufferSizeNotPow2 = errors.New("engine: buffer size must be a power of two")
+
+// ErrBufferSizeOutOfRange is returned when a requested buffer size falls
+// outside the accepted min/max window.
+var ErrBufferSizeOutOfRange = errors.New("engine: buffer size out of range")
+
+// DefaultMinBufferSize/DefaultMaxBufferSize bound the buffer sizes NewEngine
+// accepts absent a narrower device-reported range (see
+// devices.AudioDevice.SupportedBufferSizes), mirroring the 64..8192 frame
+// window ALSA/CoreAudio backends typically probe before committing to a
+// period size.
+const (
+	DefaultMinBufferSize = 64
+	DefaultMaxBufferSize = 8192
+)
+
+// isPowerOfTwo reports whether n is a positive power of two.
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// ValidateBufferSize checks size (in frames) against [min, max] and
+// power-of-two, returning ErrBufferSizeOutOfRange or ErrBufferSizeNotPow2 so
+// callers can errors.Is against the specific failure rather than parsing a
+// message string.
+func ValidateBufferSize(size, min, max int) error {
+	if size < min || size > max {
+		return fmt.Errorf("%w: %d (want %d..%d)", ErrBufferSizeOutOfRange, size, min, max)
+	}
+	if !isPowerOfTwo(size) {
+		return fmt.Errorf("%w: %d", ErrBufferSizeNotPow2, size)
+	}
+	return nil
+}
+
+// ValidateSampleRate checks that rate is one device.SupportedSampleRates
+// advertises, returning ErrUnsupportedSampleRate otherwise. A zero rate
+// always passes - it's NewEngine's "use the device default" sentinel, not
+// an actual rate to look up.
+func ValidateSampleRate(device *devices.AudioDevice, rate int) error {
+	if rate == 0 {
+		return nil
+	}
+	for _, r := range device.SupportedSampleRates {
+		if r == rate {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %d", ErrUnsupportedSampleRate, rate)
+}
+
+// ErrUnsupportedConfig is returned by ValidateStreamConfig when (sampleRate,
+// bufferSize) falls outside every devices.StreamConfigRange a device
+// reports - its wrapped message names the closest supported sample rate and
+// buffer size so a caller can retry with a value NewEngine will actually
+// accept, instead of discovering the mismatch as an opaque AVFoundation
+// failure.
+var ErrUnsupportedConfig = errors.New("engine: requested stream configuration not supported by device")
+
+// ValidateStreamConfig checks (sampleRate, bufferSize) against every range
+// in configs (see devices.AudioDevice.SupportedOutputConfigs), returning nil
+// if any range accepts it. Otherwise it returns ErrUnsupportedConfig wrapping
+// the closest sample rate and buffer size found across all ranges.
+func ValidateStreamConfig(configs []devices.StreamConfigRange, sampleRate float64, bufferSize int) error {
+	if len(configs) == 0 {
+		return fmt.Errorf("%w: device reports no supported configurations", ErrUnsupportedConfig)
+	}
+
+	var closestRate float64
+	var closestBuffer int
+	bestRateDist := math.Inf(1)
+	bestBufferDist := math.MaxInt64
+
+	for _, cfg := range configs {
+		if cfg.Accepts(sampleRate, bufferSize) {
+			return nil
+		}
+
+		rate := clampFloat(sampleRate, cfg.MinSampleRate, cfg.MaxSampleRate)
+		if d := math.Abs(rate - sampleRate); d < bestRateDist {
+			bestRateDist = d
+			closestRate = rate
+		}
+
+		buffer := clampInt(bufferSize, cfg.MinBufferFrames, cfg.MaxBufferFrames)
+		if d := absInt(buffer - bufferSize); d < bestBufferDist {
+			bestBufferDist = d
+			closestBuffer = buffer
+		}
+	}
+
+	return fmt.Errorf("%w: requested %gHz/%d frames, closest supported is %gHz/%d frames",
+		ErrUnsupportedConfig, sampleRate, bufferSize, closestRate, closestBuffer)
+}
+
+// clampFloat/clampInt/absInt are small local helpers - math.Max/math.Min
+// operate on float64 only and this package has no other use for a
+// generics-based clamp.
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
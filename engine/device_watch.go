@@ -0,0 +1,200 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L.. -lmacaudio -Wl,-rpath,..
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"unsafe"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// ConnectionState describes whether a capture channel's InputOptions.Device
+// is still the live hardware it was bound to, modeled after TransportState's
+// small-state-machine convention.
+type ConnectionState int
+
+const (
+	// Connected is a capture channel's state immediately after
+	// CreateCaptureChannel, and the state RebindDevice restores.
+	Connected ConnectionState = iota
+	// Disconnected means Engine.WatchDevices observed Device disappear;
+	// the channel's graph is left intact (including its PluginChain) so
+	// RebindDevice can reattach a replacement device without rebuilding it.
+	Disconnected
+)
+
+// String renders the state the way logs and UIs display it.
+func (s ConnectionState) String() string {
+	switch s {
+	case Connected:
+		return "connected"
+	case Disconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// State reports c's ConnectionState. Channels that aren't capture channels
+// (InputOptions == nil, or playback channels) are always Connected - the
+// state only tracks a capture channel's hardware device.
+func (c *Channel) State() ConnectionState {
+	if c.InputOptions == nil {
+		return Connected
+	}
+	return c.InputOptions.connectionState
+}
+
+// RebindDevice points this capture channel at device instead of whatever it
+// was created or last rebound with, without tearing down its mixer node,
+// PluginChain, or ChainRef - the same "swap the source, keep the graph"
+// pattern AttachSharedChain/detachSharedChainLocked use for chain sharing.
+// If the channel was actively capturing, RebindDevice stops and restarts it
+// against the new device.
+func (c *Channel) RebindDevice(device *devices.AudioDevice) error {
+	if !c.IsInput() || c.InputOptions.inputNodePtr == nil {
+		return errors.New("channel is not a capture channel")
+	}
+	if device == nil {
+		return errors.New("device cannot be nil")
+	}
+	if device.InputChannelCount <= 0 {
+		return errors.New("device " + device.UID + " has no input channels")
+	}
+
+	wasCapturing := c.InputOptions.capturing
+	if wasCapturing {
+		if err := c.StopCapture(); err != nil {
+			return err
+		}
+	}
+
+	err := c.runOnEngine(func(ctx context.Context) error {
+		cDeviceUID := C.CString(device.UID)
+		defer C.free(unsafe.Pointer(cDeviceUID))
+
+		inputNodeResult := C.audioengine_input_node(c.engine.nativeEngine, cDeviceUID)
+		if inputNodeResult.error != nil {
+			return errors.New("failed to get input node: " + C.GoString(inputNodeResult.error))
+		}
+
+		if errorStr := C.audioengine_disconnect(c.engine.nativeEngine, c.InputOptions.inputNodePtr, c.mixerNodePtr, 0, 0); errorStr != nil {
+			return errors.New("failed to disconnect previous device: " + C.GoString(errorStr))
+		}
+
+		if errorStr := C.audioengine_connect(c.engine.nativeEngine, inputNodeResult.result, c.mixerNodePtr, 0, 0); errorStr != nil {
+			return errors.New("failed to connect new device: " + C.GoString(errorStr))
+		}
+
+		c.InputOptions.Device = device
+		c.InputOptions.DeviceUID = device.UID
+		c.InputOptions.inputNodePtr = inputNodeResult.result
+		c.InputOptions.connectionState = Connected
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if wasCapturing {
+		return c.StartCapture()
+	}
+	return nil
+}
+
+// WatchDevices starts a devices.Watcher scoped to input devices. A device
+// that disappears marks every capture channel bound to it Disconnected (see
+// Channel.State/RebindDevice); a subsequent default-input change then
+// rebinds those Disconnected channels to the replacement device
+// automatically. A channel RebindDevice can't recover - the replacement
+// rejects its format, or no replacement ever arrives - is reported on
+// e.OnDeviceLost instead of left to a caller polling Channel.State. Call the
+// returned stop function to shut the watcher down, e.g. alongside
+// Engine.Destroy.
+func (e *Engine) WatchDevices() (stop func(), err error) {
+	watcher, err := devices.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	inputs := watcher.OnlyInputs()
+
+	if e.OnDeviceLost == nil {
+		e.OnDeviceLost = make(chan error, 8)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range inputs.Events() {
+			switch ev.Kind {
+			case devices.DeviceRemovedEvent:
+				e.markChannelsDisconnected(ev.Device.UID)
+				e.reportDeviceLost(ErrDeviceInvalidated)
+			case devices.DeviceAliveChangedEvent:
+				if !ev.Device.IsOnline {
+					e.markChannelsDisconnected(ev.Device.UID)
+					e.reportDeviceLost(ErrDeviceInvalidated)
+				}
+			case devices.DeviceDefaultChangedEvent:
+				if ev.DefaultKind == devices.DefaultDeviceInput {
+					e.rebindDisconnectedChannels(ev.Device)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		watcher.Close()
+		<-done
+	}, nil
+}
+
+// reportDeviceLost sends err on e.OnDeviceLost without blocking the watch
+// loop if nothing is reading it.
+func (e *Engine) reportDeviceLost(err error) {
+	select {
+	case e.OnDeviceLost <- err:
+	default:
+	}
+}
+
+// rebindDisconnectedChannels points every Disconnected capture channel at
+// device, the new system default input WatchDevices just observed.
+// RebindDevice itself validates device and reports a channel it can't
+// rebind (e.g. a format mismatch) through e.OnDeviceLost rather than
+// stopping the rest of the watch loop.
+func (e *Engine) rebindDisconnectedChannels(device devices.AudioDevice) {
+	for _, ch := range e.Channels {
+		if ch == nil || ch.State() != Disconnected {
+			continue
+		}
+		d := device
+		if err := ch.RebindDevice(&d); err != nil {
+			e.reportDeviceLost(err)
+		}
+	}
+}
+
+// markChannelsDisconnected transitions every capture channel bound to
+// deviceUID to Disconnected, serialized through the engine's control queue
+// like every other Channel mutation.
+func (e *Engine) markChannelsDisconnected(deviceUID string) {
+	_ = e.runSync(context.Background(), func(ctx context.Context) error {
+		for _, ch := range e.Channels {
+			if ch == nil || ch.InputOptions == nil {
+				continue
+			}
+			if ch.InputOptions.DeviceUID == deviceUID {
+				ch.InputOptions.connectionState = Disconnected
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+func TestChannelStateDefaultsToConnected(t *testing.T) {
+	playback := &Channel{PlaybackOptions: &PlaybackOptions{}}
+	if playback.State() != Connected {
+		t.Errorf("playback channel State() = %v, want Connected", playback.State())
+	}
+
+	capture := &Channel{InputOptions: &InputOptions{}}
+	if capture.State() != Connected {
+		t.Errorf("freshly created capture channel State() = %v, want Connected", capture.State())
+	}
+}
+
+func TestConnectionStateString(t *testing.T) {
+	cases := map[ConnectionState]string{
+		Connected:           "connected",
+		Disconnected:        "disconnected",
+		ConnectionState(99): "unknown",
+	}
+	for state, want := range cases {
+		if got := state.String(); got != want {
+			t.Errorf("ConnectionState(%d).String() = %q, want %q", state, got, want)
+		}
+	}
+}
+
+func TestRebindDeviceRejectsNonCaptureChannel(t *testing.T) {
+	channel := &Channel{PlaybackOptions: &PlaybackOptions{}}
+	if err := channel.RebindDevice(&devices.AudioDevice{}); err == nil {
+		t.Error("expected RebindDevice to fail on a non-capture channel")
+	}
+}
+
+func TestRebindDeviceRejectsNilDevice(t *testing.T) {
+	channel := &Channel{InputOptions: &InputOptions{inputNodePtr: unsafePointerSentinel()}}
+	if err := channel.RebindDevice(nil); err == nil {
+		t.Error("expected RebindDevice to fail on a nil device")
+	}
+}
+
+func TestRebindDeviceRejectsDeviceWithNoInputChannels(t *testing.T) {
+	channel := &Channel{InputOptions: &InputOptions{inputNodePtr: unsafePointerSentinel()}}
+	if err := channel.RebindDevice(&devices.AudioDevice{InputChannelCount: 0}); err == nil {
+		t.Error("expected RebindDevice to fail on a device with no input channels")
+	}
+}
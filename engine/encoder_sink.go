@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/shaban/macaudio/format/encoder"
+)
+
+// AttachEncoder wires enc as a sink for this capture channel's audio:
+// every block delivered by StartCapture's tap is written to enc in
+// addition to feeding Read's ring and InstallTap's callback (see
+// deliverCapture), so a caller can record live input to disk or a
+// network stream via format/encoder's writers without polling Read
+// themselves. Only one encoder may be attached at a time; call
+// DetachEncoder (which also closes enc) before attaching another.
+//
+// There's no playback-side equivalent yet: unlike capture, which already
+// taps the native input node via avaudio/tap, playback channels have no
+// tap point of their own in this package (AVAudioPlayerNode rendering is
+// driven entirely from the native side - see playback_channel.go), so
+// there's nothing for an encoder sink to attach to there.
+func (c *Channel) AttachEncoder(enc encoder.Encoder) error {
+	if !c.IsInput() || c.InputOptions.inputNodePtr == nil {
+		return errors.New("channel is not a capture channel")
+	}
+	if enc == nil {
+		return errors.New("encoder cannot be nil")
+	}
+	if c.InputOptions.encoderSink != nil {
+		return errors.New("an encoder is already attached to this channel")
+	}
+
+	c.InputOptions.encoderSink = enc
+	c.InputOptions.encoderErr = nil
+	return nil
+}
+
+// DetachEncoder stops feeding AttachEncoder's encoder and closes it,
+// returning any error from either the last failed WriteBlock or Close
+// itself. It's a no-op if no encoder is attached.
+func (c *Channel) DetachEncoder() error {
+	if !c.IsInput() {
+		return errors.New("channel is not a capture channel")
+	}
+
+	opts := c.InputOptions
+	enc := opts.encoderSink
+	if enc == nil {
+		return nil
+	}
+
+	opts.encoderSink = nil
+	writeErr := opts.encoderErr
+	opts.encoderErr = nil
+
+	closeErr := enc.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+	return closeErr
+}
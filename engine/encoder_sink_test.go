@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/format/encoder"
+)
+
+// fakeEncoder is a minimal encoder.Encoder that records every block it
+// was handed, for AttachEncoder/DetachEncoder tests.
+type fakeEncoder struct {
+	blocks []encoder.AudioBlock
+	closed bool
+	err    error
+}
+
+func (e *fakeEncoder) WriteBlock(b encoder.AudioBlock) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.blocks = append(e.blocks, b)
+	return nil
+}
+
+func (e *fakeEncoder) Close() error {
+	e.closed = true
+	return nil
+}
+
+func TestAttachEncoderRejectsNonCaptureChannel(t *testing.T) {
+	channel := &Channel{}
+	if err := channel.AttachEncoder(&fakeEncoder{}); err == nil {
+		t.Error("expected an error attaching an encoder to a non-capture channel")
+	}
+}
+
+func TestAttachEncoderRejectsSecondEncoder(t *testing.T) {
+	channel := &Channel{
+		InputOptions: &InputOptions{inputNodePtr: unsafePointerSentinel()},
+	}
+	if err := channel.AttachEncoder(&fakeEncoder{}); err != nil {
+		t.Fatalf("AttachEncoder failed: %v", err)
+	}
+	if err := channel.AttachEncoder(&fakeEncoder{}); err == nil {
+		t.Error("expected an error attaching a second encoder")
+	}
+}
+
+func TestDeliverCaptureFeedsEncoderSink(t *testing.T) {
+	channel := &Channel{
+		InputOptions: &InputOptions{
+			Spec:         AudioSpec{SampleRate: 48000, ChannelCount: 2},
+			inputNodePtr: unsafePointerSentinel(),
+		},
+	}
+
+	enc := &fakeEncoder{}
+	if err := channel.AttachEncoder(enc); err != nil {
+		t.Fatalf("AttachEncoder failed: %v", err)
+	}
+
+	channel.deliverCapture(tap.TapBuffer{
+		Format:      tap.TapFormatPlanarFloat32,
+		Frames:      2,
+		Channels:    2,
+		Float32Data: []float32{1, 2, 10, 20},
+	})
+
+	if len(enc.blocks) != 1 {
+		t.Fatalf("expected 1 block written to the encoder, got %d", len(enc.blocks))
+	}
+	want := []float32{1, 10, 2, 20}
+	got, ok := enc.blocks[0].Samples.([]float32)
+	if !ok || len(got) != len(want) {
+		t.Fatalf("unexpected encoder samples: %+v", enc.blocks[0].Samples)
+	}
+	for i, v := range want {
+		if got[i] != v {
+			t.Errorf("encoder samples[%d] = %v, want %v", i, got[i], v)
+		}
+	}
+
+	if err := channel.DetachEncoder(); err != nil {
+		t.Fatalf("DetachEncoder failed: %v", err)
+	}
+	if !enc.closed {
+		t.Error("expected DetachEncoder to close the encoder")
+	}
+}
+
+func TestDetachEncoderReturnsWriteError(t *testing.T) {
+	channel := &Channel{
+		InputOptions: &InputOptions{
+			Spec:         AudioSpec{SampleRate: 48000, ChannelCount: 2},
+			inputNodePtr: unsafePointerSentinel(),
+		},
+	}
+
+	writeErr := errors.New("disk full")
+	enc := &fakeEncoder{err: writeErr}
+	if err := channel.AttachEncoder(enc); err != nil {
+		t.Fatalf("AttachEncoder failed: %v", err)
+	}
+
+	channel.deliverCapture(tap.TapBuffer{
+		Format:      tap.TapFormatPlanarFloat32,
+		Frames:      1,
+		Channels:    2,
+		Float32Data: []float32{1, 2},
+	})
+
+	if err := channel.DetachEncoder(); !errors.Is(err, writeErr) {
+		t.Errorf("expected DetachEncoder to surface the write error, got %v", err)
+	}
+}
@@ -8,11 +8,18 @@ package engine
 */
 import "C"
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 
+	"github.com/shaban/macaudio/avaudio/pluginchain"
 	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/engine/queue"
 )
 
 // Engine represents the main 8-channel mixing engine
@@ -32,6 +39,51 @@ type Engine struct {
 
 	// Internal engine state (not serialized)
 	nativeEngine *C.AudioEngine `json:"-"` // Direct C AudioEngine pointer
+
+	// muted tracks master mute independently of MasterVolume, so Unmute
+	// restores the prior level rather than whatever MasterVolume was last
+	// set to while muted. See Mute/applyEffectiveMasterVolume.
+	muted bool
+
+	// opQueue serializes every mutating call (SetMasterVolume, Mute,
+	// Create*Channel, DestroyChannel, and the Channel-level equivalents in
+	// channel.go/playback_channel.go) onto a single FIFO worker, so
+	// concurrent callers can't race on the native engine. See
+	// control_queue.go (SubmitAsync/runSync) and drain.
+	opQueue *queue.Queue
+
+	// transport is the engine-wide tempo clock tempo-synced AUs read via
+	// Transport(); see transport_clock.go. Lazily created on first use.
+	transport *TransportClock
+
+	// renderFlag is set for the duration of a RenderOffline bounce (see
+	// offline_render.go), so Start/Stop can reject calls that would yank
+	// the native engine out from under an in-flight render.
+	renderFlag atomic.Bool
+
+	// ChainManager, if set, is where Channel.AttachSharedChain looks up
+	// named chains to share across channels - see chain_ref.go. nil means
+	// no channel on this engine uses ChainRef.
+	ChainManager *pluginchain.ChainManager `json:"-"`
+
+	// graphOnce and graph back Graph; see graph.go.
+	graphOnce sync.Once
+	graph     *Graph
+
+	// OnDeviceLost is where WatchDevices reports ErrDeviceInvalidated (or
+	// any RebindDevice failure) for a capture channel it couldn't recover
+	// by rebinding to the new system default - see device_watch.go. Lazily
+	// created by WatchDevices; nil until then. Buffered and non-blocking to
+	// send on, so a caller that never reads it doesn't stall the watch loop.
+	OnDeviceLost chan error
+
+	// feedbackMu guards feedback - the EnableFeedbackSuppressor/
+	// DisableFeedbackSuppressor/ActiveNotches machinery in
+	// feedback_suppressor.go, populated only if a caller ever calls
+	// EnableFeedbackSuppressor. nil means the master bus has no howl
+	// detector installed.
+	feedbackMu sync.Mutex
+	feedback   *feedbackSuppressor
 }
 
 // Channel represents a unified channel that can be input or playback
@@ -44,6 +96,12 @@ type Channel struct {
 	// Optional type-specific data (nil when not applicable)
 	PlaybackOptions *PlaybackOptions `json:"playbackOptions,omitempty"`
 	InputOptions    *InputOptions    `json:"inputOptions,omitempty"`
+	TrackerOptions  *TrackerOptions  `json:"trackerOptions,omitempty"`
+	MIDIOptions     *MIDIOptions     `json:"midiOptions,omitempty"`
+
+	// Sends routes this channel's signal into other channels' plugin
+	// chains (e.g. sidechain keying); see sends.go.
+	Sends []ChannelSend `json:"sends,omitempty"`
 }
 
 // IsInput returns true if this is an input channel
@@ -95,13 +153,24 @@ func NewEngine(outputDevice *devices.AudioDevice, sampleRateIndex int, bufferSiz
 		return nil, errors.New("invalid sample rate index")
 	}
 	actualSampleRate := outputDevice.SupportedSampleRates[sampleRateIndex]
+	if err := ValidateSampleRate(outputDevice, actualSampleRate); err != nil {
+		return nil, err
+	}
 
-	// Validate buffer size
-	if bufferSize < 16 {
-		return nil, errors.New("buffer size must be at least 16 samples")
+	// Validate buffer size against the device's advertised range (see
+	// devices.AudioDevice.SupportedBufferSizes).
+	minBuffer, maxBuffer, _ := outputDevice.SupportedBufferSizes()
+	if err := ValidateBufferSize(bufferSize, minBuffer, maxBuffer); err != nil {
+		return nil, err
 	}
-	if bufferSize > 2048 {
-		return nil, errors.New("buffer size must be at most 2048 samples")
+
+	// Validate the whole (sampleRate, bufferSize) pair against the device's
+	// negotiated stream configuration (see devices.StreamConfigRange) up
+	// front, rather than letting a combination neither check above rejects
+	// individually - but that AVFoundation still refuses - fail inside the
+	// native engine construction below.
+	if err := ValidateStreamConfig(outputDevice.SupportedOutputConfigs(), float64(actualSampleRate), bufferSize); err != nil {
+		return nil, err
 	}
 
 	// Create the native C AudioEngine using AudioEngineResult
@@ -124,27 +193,57 @@ func NewEngine(outputDevice *devices.AudioDevice, sampleRateIndex int, bufferSiz
 		MasterVolume: 1.0,
 		OutputDevice: outputDevice,
 		nativeEngine: nativeEnginePtr,
+		opQueue:      queue.New(controlQueueBuffer),
 	}
+	engine.opQueue.Start()
 
 	return engine, nil
 }
 
-// Start starts the audio engine. Returns an error if the engine fails to start.
+// Start starts the audio engine. Returns an error if the engine fails to
+// start, or ErrRenderInProgress if an offline render (RenderOffline) is
+// currently in flight - RenderOffline starts/stops the engine itself via
+// startLocked/stopLocked and would otherwise race with a caller doing the
+// same.
 func (e *Engine) Start() error {
+	if e.renderFlag.Load() {
+		return ErrRenderInProgress
+	}
+	return e.startLocked()
+}
+
+// startLocked is Start's actual implementation, bypassing the renderFlag
+// check so RenderOffline (which holds renderFlag itself) can start the
+// engine for the duration of a bounce.
+func (e *Engine) startLocked() error {
 	if e.nativeEngine == nil {
 		return errors.New("engine is not initialized")
 	}
 
 	errorStr := C.audioengine_start(e.nativeEngine)
 	if errorStr != nil {
-		return errors.New(C.GoString(errorStr))
+		return &ErrStartFailed{Underlying: classifyNativeError(C.GoString(errorStr))}
 	}
 
 	return nil
 }
 
-// Stop stops the audio engine but preserves state
+// Stop stops the audio engine but preserves state. It first drains the
+// control queue so no Set*/Mute/Create*Channel call is still in flight when
+// the native engine halts; the queue itself stays open, since Start may
+// resume the engine afterward. A no-op (with no error reported) if an
+// offline render is in flight - see ErrRenderInProgress.
 func (e *Engine) Stop() {
+	if e.renderFlag.Load() {
+		return
+	}
+	e.stopLocked()
+}
+
+// stopLocked is Stop's actual implementation, bypassing the renderFlag
+// check so RenderOffline can stop the engine itself once a bounce finishes.
+func (e *Engine) stopLocked() {
+	e.drain()
 	if e.nativeEngine != nil {
 		C.audioengine_stop(e.nativeEngine)
 	}
@@ -155,7 +254,13 @@ func (e *Engine) Pause() {
 	C.audioengine_pause(e.nativeEngine)
 }
 
-// Prepare prepares the audio engine for playback (sets up audio graph connections)
+// Prepare prepares the audio engine for playback (sets up audio graph
+// connections). Per-channel wiring itself - source node to mixer node,
+// mixer node to the main mixer's allocated bus - happens synchronously
+// when each Create*Channel call builds its channel, and plugins spliced in
+// afterward via Channel.InsertPlugin/RemovePlugin (see graph.go) patch the
+// graph in place rather than waiting for a rebuild here; Prepare only
+// needs to tell AVFoundation the graph is ready to render.
 func (e *Engine) Prepare() {
 	C.audioengine_prepare(e.nativeEngine)
 }
@@ -183,6 +288,12 @@ func (e *Engine) Destroy() {
 
 	// Clear the pointer to prevent double-destroy
 	e.nativeEngine = nil
+
+	// Irreversibly close the control queue - unlike Stop's drain, nothing
+	// resumes after Destroy.
+	if e.opQueue != nil {
+		e.opQueue.Close()
+	}
 }
 
 // =============================================================================
@@ -250,6 +361,10 @@ func (e *Engine) DestroyChannel(index int) error {
 	// TODO: Disconnect channel from mixer bus
 	// TODO: Clean up channel resources
 
+	if opts := e.Channels[index].PlaybackOptions; opts != nil {
+		opts.stopStreamFeeder()
+	}
+
 	e.Channels[index] = nil
 	return nil
 }
@@ -258,26 +373,123 @@ func (e *Engine) DestroyChannel(index int) error {
 // Public API - Master Controls
 // =============================================================================
 
-// SetMasterVolume sets the master output volume (0.0 to 1.0)
+// masterSilenceDB is the dB floor SetMasterVolumeDB/GetMasterVolumeDB use to
+// represent silence instead of actual negative infinity; see
+// Channel.channelSilenceDB for the per-channel equivalent.
+const masterSilenceDB = float32(-96)
+
+// masterMaxGainLinear is the linear ceiling SetMasterVolumeLinear allows
+// when allowGain is true: roughly +12dB, matching the headroom
+// SetMasterVolumeDB permits above unity.
+const masterMaxGainLinear = float32(4.0)
+
+// SetMasterVolume sets the master output volume (0.0 to 1.0). It's
+// equivalent to SetMasterVolumeLinear(volume, false); see
+// SetMasterVolumeLinear to opt into gain above unity, or
+// SetMasterVolumeDB to work in dB instead of a linear factor.
 func (e *Engine) SetMasterVolume(volume float32) error {
+	return e.SetMasterVolumeLinear(volume, false)
+}
+
+// SetMasterVolumeLinear sets the master output's linear volume (0.0 =
+// silence, 1.0 = unity gain). Pass allowGain to permit values above unity,
+// up to masterMaxGainLinear (~+12dB) - mirrors how mixer libraries like
+// PulseAudio accept factors above 1.0 with an explicit
+// "clipping/distortion may occur" contract, rather than rejecting them
+// outright the way the default, unity-capped path does.
+func (e *Engine) SetMasterVolumeLinear(volume float32, allowGain bool) error {
+	if volume < 0 {
+		e.MasterVolume = 0.0
+		return errors.New("volume cannot be negative")
+	}
+	ceiling := float32(1.0)
+	if allowGain {
+		ceiling = masterMaxGainLinear
+	}
+	if volume > ceiling {
+		return fmt.Errorf("volume %.2f exceeds the %.1f ceiling (pass allowGain=true to raise it to %.1f)", volume, ceiling, masterMaxGainLinear)
+	}
+
+	err := e.runSync(context.Background(), func(ctx context.Context) error {
+		if err := e.applyEffectiveMasterVolume(volume); err != nil {
+			e.MasterVolume = 0.0 // Safety: any failure in volume setting = assume dangerous state
+			return err
+		}
+		e.MasterVolume = volume
+		return nil
+	})
+	return err
+}
+
+// applyEffectiveMasterVolume writes target to the main mixer, or silence if
+// the engine is currently muted - mirrors Channel.applyEffectiveVolume.
+func (e *Engine) applyEffectiveMasterVolume(target float32) error {
+	volume := target
+	if e.muted {
+		volume = 0
+	}
+
 	// Get the main mixer node first
 	result := C.audioengine_main_mixer_node(e.nativeEngine)
 	if result.error != nil {
-		e.MasterVolume = 0.0 // Safety: any failure in volume setting = assume dangerous state
 		return errors.New(C.GoString(result.error))
 	}
 
 	// Set volume on the main mixer (C function handles all validation)
 	errorStr := C.audioengine_set_mixer_volume(e.nativeEngine, result.result, C.float(volume))
 	if errorStr != nil {
-		e.MasterVolume = 0.0 // Safety: hardware failure = assume dangerous state
 		return errors.New(C.GoString(errorStr))
 	}
-
-	e.MasterVolume = volume
 	return nil
 }
 
+// Mute silences the master output while leaving MasterVolume untouched, so
+// Unmute restores the exact prior level.
+func (e *Engine) Mute() error {
+	return e.runSync(context.Background(), func(ctx context.Context) error {
+		e.muted = true
+		return e.applyEffectiveMasterVolume(e.MasterVolume)
+	})
+}
+
+// Unmute restores the master output to MasterVolume.
+func (e *Engine) Unmute() error {
+	return e.runSync(context.Background(), func(ctx context.Context) error {
+		e.muted = false
+		return e.applyEffectiveMasterVolume(e.MasterVolume)
+	})
+}
+
+// IsMuted reports whether the master output is currently muted.
+func (e *Engine) IsMuted() bool {
+	return e.muted
+}
+
+// SetMasterVolumeDB sets the master volume as a dB value in the
+// audio-standard -inf...+12dB range, converting to a linear factor via
+// 10^(db/20); values at or below masterSilenceDB map to linear 0 rather
+// than underflowing.
+func (e *Engine) SetMasterVolumeDB(db float32) error {
+	if db > 12 {
+		return fmt.Errorf("volume %.1fdB exceeds the +12dB ceiling", db)
+	}
+	if db <= masterSilenceDB {
+		return e.SetMasterVolumeLinear(0, true)
+	}
+	return e.SetMasterVolumeLinear(float32(math.Pow(10, float64(db)/20)), true)
+}
+
+// GetMasterVolumeDB returns the current master volume converted to dB (see
+// SetMasterVolumeDB); silence (linear 0) reports masterSilenceDB rather
+// than negative infinity.
+func (e *Engine) GetMasterVolumeDB() float32 {
+	linear := e.GetMasterVolume()
+	if linear <= 0 {
+		return masterSilenceDB
+	}
+	return float32(20 * math.Log10(float64(linear)))
+}
+
 // GetMasterVolume returns the current master volume
 func (e *Engine) GetMasterVolume() float32 {
 	// Get the main mixer node first
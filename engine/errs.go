@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoAudioGraph is the classified cause of a Start/CreatePlaybackChannel
+// failure when the engine has no channel/node connected to its graph yet -
+// the state every engine is in before its first CreateInputChannel/
+// CreatePlaybackChannel call succeeds.
+var ErrNoAudioGraph = errors.New("engine: no audio graph connected")
+
+// ErrDeviceInvalidated is the classified cause of a native failure when the
+// underlying CoreAudio device changed identity or disappeared mid-session,
+// e.g. a system default device swap invalidating a cached node.
+var ErrDeviceInvalidated = errors.New("engine: audio device invalidated")
+
+// ErrFormatMismatch is the classified cause of a native failure when
+// AVFoundation rejects a sample rate/channel-count/format combination
+// somewhere in the graph.
+var ErrFormatMismatch = errors.New("engine: audio format mismatch")
+
+// ErrStartFailed wraps a Start/startLocked failure. Underlying is one of the
+// sentinels above when classifyNativeError recognized the raw AVFoundation
+// message, or a plain error carrying that message otherwise; OSStatus is the
+// native OSStatus audioengine_start returned, left 0 until native/macaudio.m
+// (absent from this tree) is extended to report it instead of just a
+// const char*. errors.Is(err, ErrNoAudioGraph) and friends work through
+// Unwrap regardless of whether OSStatus is populated.
+type ErrStartFailed struct {
+	Underlying error
+	OSStatus   int32
+}
+
+func (e *ErrStartFailed) Error() string {
+	if e.OSStatus != 0 {
+		return fmt.Sprintf("engine: start failed (OSStatus %d): %v", e.OSStatus, e.Underlying)
+	}
+	return fmt.Sprintf("engine: start failed: %v", e.Underlying)
+}
+
+func (e *ErrStartFailed) Unwrap() error { return e.Underlying }
+
+// classifyNativeError maps a raw error string crossing the CGO boundary onto
+// one of the sentinels above when it recognizes a known AVFoundation
+// message, so callers can use errors.Is instead of matching the message text
+// themselves (see TestEngineLifecycle). Falls back to errors.New(msg) when
+// nothing matches.
+func classifyNativeError(msg string) error {
+	switch {
+	case strings.Contains(msg, "Engine start failed with exception"):
+		return ErrNoAudioGraph
+	case strings.Contains(msg, "invalidated"), strings.Contains(msg, "disconnected"):
+		return ErrDeviceInvalidated
+	case strings.Contains(msg, "format"), strings.Contains(msg, "Format"):
+		return ErrFormatMismatch
+	default:
+		return errors.New(msg)
+	}
+}
@@ -0,0 +1,140 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"time"
+)
+
+// fadeStepInterval is the control-rate a fade recomputes and writes the
+// channel's mixer volume at. Coarser than sample-accurate automation since
+// these writes land on a plain mixer node property, not one drained from a
+// render callback; matches channel.rampStepInterval.
+const fadeStepInterval = 10 * time.Millisecond
+
+// FadeIn ramps the channel's volume from 0 up to its current Volume over
+// duration, modeled after SDL_mixer's Mix_FadeInChannelTimed. Call Play
+// first (or use PlayTimed) - FadeIn only animates the mixer volume, it
+// doesn't start playback itself. A zero or negative duration sets the
+// volume immediately. A fade already running on this channel is
+// superseded - it stops where it was and this fade starts from there.
+func (c *Channel) FadeIn(duration time.Duration) error {
+	target := c.Volume
+	if err := c.SetVolume(0); err != nil {
+		return err
+	}
+	return c.startFade(0, target, duration)
+}
+
+// FadeOut ramps the channel's volume down to silence over duration,
+// modeled after SDL_mixer's Mix_FadeOutChannel. It does not stop playback
+// itself - pair it with Stop (or rely on OnFadeDone) if the channel should
+// also halt once silent. A zero or negative duration sets the volume
+// immediately. A fade already running on this channel is superseded - it
+// stops where it was and this fade starts from there.
+func (c *Channel) FadeOut(duration time.Duration) error {
+	return c.startFade(c.Volume, 0, duration)
+}
+
+// PlayTimed starts playback and stops it again after duration, modeled
+// after SDL_mixer's Mix_PlayChannelTimed. The channel must not already be
+// playing.
+func (c *Channel) PlayTimed(duration time.Duration) error {
+	if err := c.Play(); err != nil {
+		return err
+	}
+	if duration > 0 {
+		time.AfterFunc(duration, func() {
+			_ = c.Stop()
+		})
+	}
+	return nil
+}
+
+// Stop halts playback on a playback channel. Unlike Pause, a subsequent
+// Play restarts from the beginning rather than resuming.
+func (c *Channel) Stop() error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if c.PlaybackOptions.playerPtr == nil {
+		return errors.New("no native player available")
+	}
+	return c.withTransition(Stopped, func() error {
+		playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
+		errorStr := C.audioplayer_stop(playerPtr)
+		if errorStr != nil {
+			return errors.New("failed to stop playback: " + C.GoString(errorStr))
+		}
+		return nil
+	})
+}
+
+// startFade is FadeIn/FadeOut's shared body.
+func (c *Channel) startFade(from, target float32, duration time.Duration) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+
+	opts := c.PlaybackOptions
+	opts.fadeMu.Lock()
+	if opts.fadeCancel != nil {
+		close(opts.fadeCancel)
+		opts.fadeCancel = nil
+	}
+	opts.fadeMu.Unlock()
+
+	if duration <= 0 {
+		return c.SetVolume(target)
+	}
+
+	cancel := make(chan struct{})
+	opts.fadeMu.Lock()
+	opts.fadeCancel = cancel
+	opts.fadeMu.Unlock()
+
+	go c.runFade(from, target, duration, cancel)
+	return nil
+}
+
+// runFade drives one fade's ticks on its own goroutine, not the caller's -
+// a multi-second fade shouldn't block whoever started it.
+func (c *Channel) runFade(from, target float32, duration time.Duration, cancel chan struct{}) {
+	ticker := time.NewTicker(fadeStepInterval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	opts := c.PlaybackOptions
+
+	for {
+		select {
+		case <-cancel:
+			return
+		case now := <-ticker.C:
+			t := float32(now.Sub(start)) / float32(duration)
+			done := t >= 1
+			if done {
+				t = 1
+			}
+			_ = c.SetVolume(from + (target-from)*t)
+			if !done {
+				continue
+			}
+
+			opts.fadeMu.Lock()
+			if opts.fadeCancel == cancel {
+				opts.fadeCancel = nil
+			}
+			opts.fadeMu.Unlock()
+
+			if c.OnFadeDone != nil {
+				c.OnFadeDone(c)
+			}
+			return
+		}
+	}
+}
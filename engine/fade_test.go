@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPlaybackChannelFadeInOut verifies FadeIn/FadeOut ramp the channel's
+// mixer volume progressively rather than jumping straight to the target,
+// and that OnFadeDone fires once each fade completes.
+func TestPlaybackChannelFadeInOut(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	testAudioPath, err := filepath.Abs("../avaudio/engine/idea.m4a")
+	if err != nil {
+		t.Fatalf("Failed to get absolute path to test audio file: %v", err)
+	}
+
+	channel, err := engine.CreatePlaybackChannel(testAudioPath)
+	if err != nil {
+		t.Fatalf("Failed to create playback channel: %v", err)
+	}
+
+	if err := channel.SetVolume(1.0); err != nil {
+		t.Fatalf("Failed to set initial volume: %v", err)
+	}
+
+	done := make(chan struct{}, 1)
+	channel.OnFadeDone = func(c *Channel) { done <- struct{}{} }
+
+	if err := channel.FadeOut(50 * time.Millisecond); err != nil {
+		t.Fatalf("FadeOut failed: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	mid, err := channel.GetVolume()
+	if err != nil {
+		t.Fatalf("Failed to read mid-fade volume: %v", err)
+	}
+	if mid <= 0 || mid >= 1.0 {
+		t.Errorf("expected mid-fade volume strictly between 0 and 1, got %.3f", mid)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("OnFadeDone did not fire for FadeOut")
+	}
+
+	final, err := channel.GetVolume()
+	if err != nil {
+		t.Fatalf("Failed to read final volume: %v", err)
+	}
+	if final != 0 {
+		t.Errorf("expected volume 0 after FadeOut, got %.3f", final)
+	}
+
+	// PlayTimed should stop playback on its own after the given duration.
+	if err := channel.PlayTimed(30 * time.Millisecond); err != nil {
+		t.Fatalf("PlayTimed failed: %v", err)
+	}
+	time.Sleep(80 * time.Millisecond)
+}
@@ -0,0 +1,511 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sync"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/effects"
+	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/avaudio/unit"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// AUNBandEQ's per-band parameter addresses (kAUNBandEQParam_*, from Apple's
+// AudioUnitParameters.h): each of the unit's bands occupies
+// nBandEQParamsPerBand consecutive parameter IDs starting at band*that
+// stride, so a band's own address is just its index times the stride plus
+// one of the offsets below - unit.Effect.SetParameter only reads
+// plugins.Parameter.Address, so there's no need to go through the usual
+// plugin-introspection path to build one (see avaudio/unit.Effect).
+const (
+	nBandEQParamsPerBand = 5
+	nBandEQFilterType    = 0
+	nBandEQFrequency     = 1
+	nBandEQGain          = 2
+	nBandEQBandwidth     = 3
+	nBandEQBypass        = 4
+
+	// nBandEQFilterTypeParametric selects kAUNBandEQFilterType_Parametric,
+	// the only band shape with independent frequency/gain/Q.
+	nBandEQFilterTypeParametric = 0
+
+	// nBandEQMaxBands is AUNBandEQ's hard band limit; FeedbackConfig.MaxNotches
+	// is clamped to it regardless of what a caller asks for.
+	nBandEQMaxBands = 8
+)
+
+func nBandEQParam(band, offset int) plugins.Parameter {
+	return plugins.Parameter{Address: uint64(band*nBandEQParamsPerBand + offset)}
+}
+
+// FeedbackConfig tunes EnableFeedbackSuppressor's howl detector and the
+// notches it allocates to suppress what it finds. The zero value uses
+// defaultFeedbackPeakToMedianRatio/defaultFeedbackMinFrames/
+// defaultFeedbackMaxNotches.
+type FeedbackConfig struct {
+	// PeakToMedianRatio is how many times a bin's magnitude must exceed the
+	// local spectral median before it's flagged as a candidate tone.
+	PeakToMedianRatio float64
+	// MinFrames is how many consecutive analysis windows a candidate must
+	// persist in before a notch is actually allocated for it - this is what
+	// keeps a single transient (a clap, a kick drum) from triggering a
+	// notch.
+	MinFrames int
+	// MaxNotches bounds how many simultaneous notches the suppressor keeps
+	// active, capped at nBandEQMaxBands (AUNBandEQ's own band limit).
+	MaxNotches int
+}
+
+const (
+	defaultFeedbackPeakToMedianRatio = 10.0
+	defaultFeedbackMinFrames         = 6
+	defaultFeedbackMaxNotches        = 8
+
+	// feedbackWindowSize is the analysis hop size: 2048 samples is ~43ms at
+	// 48kHz, fine enough to resolve a howling tone's frequency without
+	// needing a window so long it delays detection.
+	feedbackWindowSize = 2048
+
+	// feedbackNotchGainDB is how hard a newly-allocated notch cuts.
+	feedbackNotchGainDB = -12.0
+
+	// feedbackNotchQ is the notch's Q; nBandEQBandwidth takes an octave
+	// bandwidth instead, via qToBandwidthOctaves.
+	feedbackNotchQ = 30.0
+
+	// feedbackReleaseDBPerSec is how fast a notch's gain climbs back toward
+	// 0dB once its frequency stops being flagged - the "release" half of
+	// the adaptive Krauss/Waterhouse-style AFS the request describes.
+	feedbackReleaseDBPerSec = 6.0
+
+	// medianWindowBins is how many bins on either side of a candidate bin
+	// contribute to its local spectral median.
+	medianWindowBins = 16
+)
+
+func (c FeedbackConfig) withDefaults() FeedbackConfig {
+	if c.PeakToMedianRatio <= 0 {
+		c.PeakToMedianRatio = defaultFeedbackPeakToMedianRatio
+	}
+	if c.MinFrames <= 0 {
+		c.MinFrames = defaultFeedbackMinFrames
+	}
+	if c.MaxNotches <= 0 {
+		c.MaxNotches = defaultFeedbackMaxNotches
+	}
+	if c.MaxNotches > nBandEQMaxBands {
+		c.MaxNotches = nBandEQMaxBands
+	}
+	return c
+}
+
+// Notch is one frequency EnableFeedbackSuppressor's detector is currently
+// suppressing (or releasing), as reported by Engine.ActiveNotches.
+type Notch struct {
+	Frequency float64 // Hz
+	GainDB    float32 // current cut, climbing back toward 0 once Active is false
+	Active    bool    // true while the detector still sees this tone; false while the gain is releasing back toward 0dB
+}
+
+// feedbackSuppressor is the state EnableFeedbackSuppressor installs on an
+// Engine: the hidden analysis tap on the main mixer, the AUNBandEQ doing
+// the actual notching, and the per-band bookkeeping tying detected
+// frequencies to the bands suppressing them.
+type feedbackSuppressor struct {
+	mu sync.Mutex
+
+	eq     *unit.Effect
+	tap    *tap.Tap
+	config FeedbackConfig
+
+	ring       []float64
+	pos        int
+	filled     bool
+	sampleRate float64
+
+	persist map[int]int      // quantized-frequency bucket -> consecutive flagged windows
+	bands   [nBandEQMaxBands]notchBand
+}
+
+// notchBand is one AUNBandEQ band feedbackSuppressor has allocated.
+type notchBand struct {
+	inUse     bool
+	frequency float64
+	gainDB    float32
+	active    bool
+}
+
+// EnableFeedbackSuppressor installs a hidden analysis tap on e's main mixer
+// output and an AUNBandEQ spliced into the master chain (mixer -> EQ ->
+// output), then runs a lightweight howl detector over the tap's signal: a
+// Hann-windowed FFT of every feedbackWindowSize-sample hop flags bins whose
+// magnitude exceeds cfg.PeakToMedianRatio above their local spectral
+// median, and once a candidate persists for cfg.MinFrames consecutive
+// windows, allocates one of the EQ's bands as a notch at that frequency.
+// Calling it again without DisableFeedbackSuppressor first returns an
+// error.
+func (e *Engine) EnableFeedbackSuppressor(cfg FeedbackConfig) error {
+	e.feedbackMu.Lock()
+	defer e.feedbackMu.Unlock()
+
+	if e.feedback != nil {
+		return errors.New("engine: feedback suppressor already enabled")
+	}
+	if e.nativeEngine == nil {
+		return ErrNoAudioGraph
+	}
+
+	mixerPtr := e.GetMainMixerNode()
+	if mixerPtr == nil {
+		return errors.New("engine: no main mixer node")
+	}
+	outputPtr := e.outputNodePtr()
+	if outputPtr == nil {
+		return errors.New("engine: no output node")
+	}
+
+	eqUnit, err := unit.CreateEffect(effects.NewEQ())
+	if err != nil {
+		return fmt.Errorf("engine: create feedback suppressor EQ: %w", err)
+	}
+
+	graph := e.Graph()
+	mixerNode := NodeRef{ptr: mixerPtr}
+	outputNode := NodeRef{ptr: outputPtr}
+	eqNode := NodeRef{ptr: eqUnit.Ptr()}
+
+	if err := graph.Attach(eqNode); err != nil {
+		eqUnit.Release()
+		return fmt.Errorf("engine: attach feedback suppressor EQ: %w", err)
+	}
+	if err := graph.Disconnect(mixerNode, outputNode); err != nil {
+		eqUnit.Release()
+		return fmt.Errorf("engine: disconnect mixer from output: %w", err)
+	}
+	if err := graph.Route(mixerNode, eqNode); err != nil {
+		eqUnit.Release()
+		return fmt.Errorf("engine: connect mixer to feedback suppressor EQ: %w", err)
+	}
+	if err := graph.Route(eqNode, outputNode); err != nil {
+		graph.Disconnect(mixerNode, eqNode)
+		eqUnit.Release()
+		return fmt.Errorf("engine: connect feedback suppressor EQ to output: %w", err)
+	}
+
+	t, err := tap.InstallTap(unsafe.Pointer(e.nativeEngine), mixerPtr, 0)
+	if err != nil {
+		graph.Disconnect(mixerNode, eqNode)
+		graph.Disconnect(eqNode, outputNode)
+		graph.Route(mixerNode, outputNode)
+		eqUnit.Release()
+		return fmt.Errorf("engine: install feedback suppressor tap: %w", err)
+	}
+
+	suppressor := &feedbackSuppressor{
+		eq:      eqUnit,
+		tap:     t,
+		config:  cfg.withDefaults(),
+		ring:    make([]float64, feedbackWindowSize),
+		persist: make(map[int]int),
+	}
+	if _, err := t.Subscribe(suppressor.process, tap.SubscribeOptions{Layout: tap.ChannelLayoutPlanar}); err != nil {
+		_ = t.Remove()
+		graph.Disconnect(mixerNode, eqNode)
+		graph.Disconnect(eqNode, outputNode)
+		graph.Route(mixerNode, outputNode)
+		eqUnit.Release()
+		return fmt.Errorf("engine: subscribe feedback suppressor detector: %w", err)
+	}
+
+	e.feedback = suppressor
+	return nil
+}
+
+// DisableFeedbackSuppressor tears down whatever EnableFeedbackSuppressor
+// installed: the analysis tap, every allocated notch band, and the EQ node
+// itself, reconnecting the main mixer straight to the output node. Calling
+// it without a prior EnableFeedbackSuppressor is a no-op.
+func (e *Engine) DisableFeedbackSuppressor() error {
+	e.feedbackMu.Lock()
+	suppressor := e.feedback
+	e.feedback = nil
+	e.feedbackMu.Unlock()
+
+	if suppressor == nil {
+		return nil
+	}
+
+	_ = suppressor.tap.Remove()
+
+	graph := e.Graph()
+	mixerNode := NodeRef{ptr: e.GetMainMixerNode()}
+	outputNode := NodeRef{ptr: e.outputNodePtr()}
+	eqNode := NodeRef{ptr: suppressor.eq.Ptr()}
+
+	graph.Disconnect(mixerNode, eqNode)
+	graph.Disconnect(eqNode, outputNode)
+	if err := graph.Route(mixerNode, outputNode); err != nil {
+		return fmt.Errorf("engine: reconnect mixer to output: %w", err)
+	}
+	return suppressor.eq.Release()
+}
+
+// ActiveNotches reports every notch the suppressor currently has allocated
+// (suppressing or releasing), so a CLI can display live which frequencies
+// are being fought. Returns nil if EnableFeedbackSuppressor hasn't been
+// called.
+func (e *Engine) ActiveNotches() []Notch {
+	e.feedbackMu.Lock()
+	suppressor := e.feedback
+	e.feedbackMu.Unlock()
+	if suppressor == nil {
+		return nil
+	}
+	return suppressor.snapshot()
+}
+
+// outputNodePtr returns e's hardware output node - the node
+// EnableFeedbackSuppressor splices its EQ in front of. Mirrors
+// GetMainMixerNode's shape; assumes native/macaudio.h exposes
+// audioengine_output_node alongside audioengine_main_mixer_node.
+func (e *Engine) outputNodePtr() unsafe.Pointer {
+	if e.nativeEngine == nil {
+		return nil
+	}
+	result := C.audioengine_output_node(e.nativeEngine)
+	if result.error != nil || result.result == nil {
+		return nil
+	}
+	return unsafe.Pointer(result.result)
+}
+
+// process is a tap.Subscribe callback (see tap.SubscribeOptions.Layout), so
+// pcm is planar: all of channel 0's frames, then all of channel 1's, etc.
+// It only sums channels down to mono and appends to the ring; analyze (run
+// once the ring has accumulated a full feedbackWindowSize hop) does the
+// FFT and notch bookkeeping.
+func (s *feedbackSuppressor) process(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if channels <= 0 || len(pcm) == 0 {
+		return
+	}
+	s.sampleRate = sampleRate
+
+	frames := len(pcm) / channels
+	for frame := 0; frame < frames; frame++ {
+		var sum float64
+		for ch := 0; ch < channels; ch++ {
+			sum += float64(pcm[ch*frames+frame])
+		}
+		s.ring[s.pos] = sum / float64(channels)
+		s.pos++
+		if s.pos >= feedbackWindowSize {
+			s.pos = 0
+			s.filled = true
+			s.analyzeLocked()
+		}
+	}
+}
+
+// analyzeLocked runs a Hann-windowed FFT over the ring's current contents
+// and updates every persistence counter and notch band. Caller must hold
+// s.mu; called only from process, once per full feedbackWindowSize hop.
+func (s *feedbackSuppressor) analyzeLocked() {
+	n := feedbackWindowSize
+	windowed := make([]complex128, n)
+	for i, sample := range s.ring {
+		hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		windowed[i] = complex(sample*hann, 0)
+	}
+	spectrum := feedbackFFT(windowed)
+
+	half := n / 2
+	mags := make([]float64, half)
+	for i := 0; i < half; i++ {
+		mags[i] = cmplx.Abs(spectrum[i])
+	}
+	binHz := s.sampleRate / float64(n)
+
+	flagged := make(map[int]float64) // bucket -> frequency, this window's candidates
+	for i, mag := range mags {
+		median := localMedian(mags, i, medianWindowBins)
+		if median <= 0 || mag < median*s.config.PeakToMedianRatio {
+			continue
+		}
+		freq := float64(i) * binHz
+		bucket := feedbackFrequencyBucket(freq)
+		flagged[bucket] = freq
+	}
+
+	for bucket, freq := range flagged {
+		s.persist[bucket]++
+		if s.persist[bucket] == s.config.MinFrames {
+			s.allocateNotchLocked(freq)
+		}
+	}
+	for bucket := range s.persist {
+		if _, stillFlagged := flagged[bucket]; !stillFlagged {
+			delete(s.persist, bucket)
+		}
+	}
+
+	dt := float64(n) / s.sampleRate
+	for i := range s.bands {
+		band := &s.bands[i]
+		if !band.inUse {
+			continue
+		}
+		_, stillFlagged := flagged[feedbackFrequencyBucket(band.frequency)]
+		band.active = stillFlagged
+		if stillFlagged {
+			band.gainDB = feedbackNotchGainDB
+		} else {
+			band.gainDB += float32(feedbackReleaseDBPerSec * dt)
+			if band.gainDB >= 0 {
+				band.inUse = false
+				band.gainDB = 0
+			}
+		}
+		s.eq.SetParameter(nBandEQParam(i, nBandEQGain), band.gainDB)
+		if !band.inUse {
+			s.eq.SetParameter(nBandEQParam(i, nBandEQBypass), 1)
+		}
+	}
+}
+
+// allocateNotchLocked installs a new parametric notch at freq on the first
+// free band, if any - cfg.MaxNotches simultaneous notches (also bounded by
+// nBandEQMaxBands) is the most EnableFeedbackSuppressor ever keeps active
+// at once. A no-op if every band cfg.MaxNotches allows is already in use.
+func (s *feedbackSuppressor) allocateNotchLocked(freq float64) {
+	inUse := 0
+	freeIdx := -1
+	for i := range s.bands {
+		if s.bands[i].inUse {
+			inUse++
+		} else if freeIdx < 0 {
+			freeIdx = i
+		}
+	}
+	if inUse >= s.config.MaxNotches || freeIdx < 0 {
+		return
+	}
+
+	s.bands[freeIdx] = notchBand{inUse: true, frequency: freq, gainDB: feedbackNotchGainDB, active: true}
+	s.eq.SetParameter(nBandEQParam(freeIdx, nBandEQFilterType), nBandEQFilterTypeParametric)
+	s.eq.SetParameter(nBandEQParam(freeIdx, nBandEQFrequency), float32(freq))
+	s.eq.SetParameter(nBandEQParam(freeIdx, nBandEQBandwidth), float32(qToBandwidthOctaves(feedbackNotchQ)))
+	s.eq.SetParameter(nBandEQParam(freeIdx, nBandEQGain), feedbackNotchGainDB)
+	s.eq.SetParameter(nBandEQParam(freeIdx, nBandEQBypass), 0)
+}
+
+// snapshot returns every band currently in use as a Notch.
+func (s *feedbackSuppressor) snapshot() []Notch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var notches []Notch
+	for _, band := range s.bands {
+		if !band.inUse {
+			continue
+		}
+		notches = append(notches, Notch{Frequency: band.frequency, GainDB: band.gainDB, Active: band.active})
+	}
+	return notches
+}
+
+// feedbackFrequencyBucket quantizes freq to the nearest 10Hz, so a tone
+// that wanders a couple of bins between windows (FFT bin width varies with
+// sample rate) still counts as the same persisting candidate.
+func feedbackFrequencyBucket(freq float64) int {
+	return int(math.Round(freq/10)) * 10
+}
+
+// qToBandwidthOctaves converts a peaking filter's Q to the octave
+// bandwidth AUNBandEQ's nBandEQBandwidth parameter takes, using the
+// standard narrow-band approximation BW_octaves ~= 1/(Q*ln2) - adequate for
+// the fairly high Qs (~30) a feedback notch uses, not an exact conversion
+// across Q's full range.
+func qToBandwidthOctaves(q float64) float64 {
+	return 1 / (q * math.Ln2)
+}
+
+// localMedian returns the median of mags within window bins on either side
+// of i (clamped to the slice), excluding i itself - the "local spectral
+// median" a candidate bin's magnitude is compared against.
+func localMedian(mags []float64, i, window int) float64 {
+	lo := i - window
+	if lo < 0 {
+		lo = 0
+	}
+	hi := i + window
+	if hi >= len(mags) {
+		hi = len(mags) - 1
+	}
+
+	neighborhood := make([]float64, 0, hi-lo)
+	for j := lo; j <= hi; j++ {
+		if j == i {
+			continue
+		}
+		neighborhood = append(neighborhood, mags[j])
+	}
+	if len(neighborhood) == 0 {
+		return 0
+	}
+
+	sortFloat64s(neighborhood)
+	return neighborhood[len(neighborhood)/2]
+}
+
+// sortFloat64s is a tiny insertion sort - medianWindowBins*2 elements at
+// most, not worth pulling in sort.Float64s for.
+func sortFloat64s(a []float64) {
+	for i := 1; i < len(a); i++ {
+		for j := i; j > 0 && a[j-1] > a[j]; j-- {
+			a[j-1], a[j] = a[j], a[j-1]
+		}
+	}
+}
+
+// feedbackFFT is a recursive radix-2 Cooley-Tukey FFT, the same algorithm
+// avaudio/tap.fft and avaudio/engine.fftInPlace implement - duplicated
+// rather than shared across packages, following this codebase's existing
+// precedent (see avaudio/engine/segment_analysis.go's biquad comment).
+// feedbackWindowSize is already a power of two, so unlike those callers
+// this one never needs a nextPowerOfTwo padding step first.
+func feedbackFFT(x []complex128) []complex128 {
+	n := len(x)
+	if n <= 1 {
+		return x
+	}
+
+	even := make([]complex128, n/2)
+	odd := make([]complex128, n/2)
+	for i := 0; i < n/2; i++ {
+		even[i] = x[2*i]
+		odd[i] = x[2*i+1]
+	}
+	even = feedbackFFT(even)
+	odd = feedbackFFT(odd)
+
+	result := make([]complex128, n)
+	for k := 0; k < n/2; k++ {
+		twiddle := cmplx.Exp(complex(0, -2*math.Pi*float64(k)/float64(n))) * odd[k]
+		result[k] = even[k] + twiddle
+		result[k+n/2] = even[k] - twiddle
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestFeedbackConfigWithDefaultsFillsZeroValuesAndClampsMaxNotches(t *testing.T) {
+	got := FeedbackConfig{}.withDefaults()
+	if got.PeakToMedianRatio != defaultFeedbackPeakToMedianRatio {
+		t.Errorf("PeakToMedianRatio = %v, want %v", got.PeakToMedianRatio, defaultFeedbackPeakToMedianRatio)
+	}
+	if got.MinFrames != defaultFeedbackMinFrames {
+		t.Errorf("MinFrames = %v, want %v", got.MinFrames, defaultFeedbackMinFrames)
+	}
+	if got.MaxNotches != defaultFeedbackMaxNotches {
+		t.Errorf("MaxNotches = %v, want %v", got.MaxNotches, defaultFeedbackMaxNotches)
+	}
+
+	clamped := FeedbackConfig{MaxNotches: nBandEQMaxBands + 5}.withDefaults()
+	if clamped.MaxNotches != nBandEQMaxBands {
+		t.Errorf("MaxNotches = %v, want clamped to %v", clamped.MaxNotches, nBandEQMaxBands)
+	}
+}
+
+func TestNBandEQParamAddressesEachBandFiveApart(t *testing.T) {
+	if got := nBandEQParam(0, nBandEQFrequency).Address; got != 1 {
+		t.Errorf("band 0 frequency address = %v, want 1", got)
+	}
+	if got := nBandEQParam(1, nBandEQFrequency).Address; got != nBandEQParamsPerBand+1 {
+		t.Errorf("band 1 frequency address = %v, want %v", got, nBandEQParamsPerBand+1)
+	}
+	if got := nBandEQParam(3, nBandEQBypass).Address; got != 3*nBandEQParamsPerBand+4 {
+		t.Errorf("band 3 bypass address = %v, want %v", got, 3*nBandEQParamsPerBand+4)
+	}
+}
+
+func TestFeedbackFrequencyBucketQuantizesToNearest10Hz(t *testing.T) {
+	if got := feedbackFrequencyBucket(1003); got != 1000 {
+		t.Errorf("feedbackFrequencyBucket(1003) = %v, want 1000", got)
+	}
+	if got := feedbackFrequencyBucket(1007); got != 1010 {
+		t.Errorf("feedbackFrequencyBucket(1007) = %v, want 1010", got)
+	}
+}
+
+func TestQToBandwidthOctavesIsNarrowForHighQ(t *testing.T) {
+	bw := qToBandwidthOctaves(feedbackNotchQ)
+	if bw <= 0 || bw > 0.2 {
+		t.Errorf("qToBandwidthOctaves(%v) = %v, want a narrow (<0.2 octave) bandwidth", feedbackNotchQ, bw)
+	}
+}
+
+func TestLocalMedianIgnoresTheCenterBinAndAFewLoudNeighbors(t *testing.T) {
+	mags := make([]float64, 64)
+	for i := range mags {
+		mags[i] = 1.0
+	}
+	mags[32] = 1000.0 // the candidate bin itself - must not pollute its own median
+
+	got := localMedian(mags, 32, medianWindowBins)
+	if got != 1.0 {
+		t.Errorf("localMedian around a flat floor with the center bin spiked = %v, want 1.0", got)
+	}
+}
+
+func TestFeedbackFFTFindsAPureTone(t *testing.T) {
+	const n = 256
+	const sampleRate = 48000.0
+	const binHz = sampleRate / n
+	const targetBin = 20
+
+	x := make([]complex128, n)
+	for i := 0; i < n; i++ {
+		phase := 2 * math.Pi * targetBin * float64(i) / n
+		x[i] = complex(math.Sin(phase), 0)
+	}
+
+	spectrum := feedbackFFT(x)
+	half := n / 2
+	dominant := 0
+	for i := 1; i < half; i++ {
+		if cmplx.Abs(spectrum[i]) > cmplx.Abs(spectrum[dominant]) {
+			dominant = i
+		}
+	}
+	if dominant != targetBin {
+		t.Errorf("dominant bin = %v (%v Hz), want %v (%v Hz)", dominant, float64(dominant)*binHz, targetBin, targetBin*binHz)
+	}
+}
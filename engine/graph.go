@@ -0,0 +1,84 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+
+import "unsafe"
+
+// NodeRef is an opaque handle to a node already attached to an Engine's
+// native audio graph - a channel's mixer, an input node, a player's
+// time/pitch unit, or a plugin's AudioUnit - so Graph's Route/Disconnect
+// take one argument type regardless of which part of a channel's chain it
+// names.
+type NodeRef struct {
+	ptr unsafe.Pointer
+}
+
+// Graph is the declarative entry point for wiring nodes into e's audio
+// graph, mirroring avaudio/engine.TapRegistry's lazy wrap-the-Engine
+// pattern (Engine.Graph() hands back the same *Graph every time) rather
+// than every caller reaching for C.audioengine_connect/C.audioengine_attach
+// directly the way Create*Channel already does for the nodes it builds
+// itself.
+type Graph struct {
+	engine *Engine
+}
+
+// Graph returns e's Graph, creating it on first use.
+func (e *Engine) Graph() *Graph {
+	e.graphOnce.Do(func() {
+		e.graph = &Graph{engine: e}
+	})
+	return e.graph
+}
+
+// Attach attaches node to g's engine so it can take part in a Route call.
+// Every node a Create*Channel constructor builds is already attached
+// inline via C.audioengine_attach; Attach exposes the same primitive for a
+// node assembled outside those constructors, e.g. a unit.Effect inserted
+// later by Channel.InsertPlugin.
+func (g *Graph) Attach(node NodeRef) error {
+	if g.engine.nativeEngine == nil {
+		return ErrNoAudioGraph
+	}
+	if errorStr := C.audioengine_attach(g.engine.nativeEngine, node.ptr); errorStr != nil {
+		return classifyNativeError(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// Route connects src's output to dst's input on bus 0 of each, matching
+// the bus convention every existing audioengine_connect call in this
+// package already uses for its player/time-pitch/mixer chain - the only
+// connection that goes through a different bus is a channel mixer's final
+// hop into the main mixer, which goes through AllocateBusForChannel
+// instead of Graph.
+func (g *Graph) Route(src, dst NodeRef) error {
+	if g.engine.nativeEngine == nil {
+		return ErrNoAudioGraph
+	}
+	if errorStr := C.audioengine_connect(g.engine.nativeEngine, src.ptr, dst.ptr, 0, 0); errorStr != nil {
+		return classifyNativeError(C.GoString(errorStr))
+	}
+	return nil
+}
+
+// Disconnect tears down the bus-0 connection from src to dst, the
+// counterpart to Route that splicing a node out of the middle of a chain
+// (see Channel.RemovePlugin) needs. It takes the same (src, dst) shape as
+// Route, and the same audioengine_disconnect primitive Channel.RebindDevice
+// already calls in device_watch.go, rather than a single-node
+// "disconnect everything downstream of this" call CoreAudio doesn't expose
+// as one primitive.
+func (g *Graph) Disconnect(src, dst NodeRef) error {
+	if g.engine.nativeEngine == nil {
+		return ErrNoAudioGraph
+	}
+	if errorStr := C.audioengine_disconnect(g.engine.nativeEngine, src.ptr, dst.ptr, 0, 0); errorStr != nil {
+		return classifyNativeError(C.GoString(errorStr))
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ package engine
 */
 import "C"
 import (
+	"context"
+
 	"github.com/shaban/macaudio/devices"
 )
 
@@ -26,9 +28,17 @@ func (e *Engine) CreateInputChannel(device *devices.AudioDevice, channelIndex in
 			ChannelIndex: channelIndex,
 			PluginChain:  NewPluginChain(),
 		},
+		engine: e,
 	}
 
-	e.Channels = append(e.Channels, channel)
+	err := e.runSync(context.Background(), func(ctx context.Context) error {
+		defaultChannelSolo.register(channel)
+		e.Channels = append(e.Channels, channel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return channel, nil
 }
 
@@ -43,8 +53,16 @@ func (e *Engine) CreateMIDIInputChannel(midiDevice *devices.MIDIDevice, midiChan
 			ChannelIndex: midiChannel,
 			PluginChain:  NewPluginChain(),
 		},
+		engine: e,
 	}
 
-	e.Channels = append(e.Channels, channel)
+	err := e.runSync(context.Background(), func(ctx context.Context) error {
+		defaultChannelSolo.register(channel)
+		e.Channels = append(e.Channels, channel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return channel, nil
 }
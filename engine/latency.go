@@ -0,0 +1,42 @@
+package engine
+
+// LatencySamples returns the plugin's reported processing latency, in
+// samples at the engine's current sample rate. It is meant to read
+// kAudioUnitProperty_Latency from the live AudioUnit instance, but plugin
+// chains aren't wired to real AudioUnit instances yet (see the
+// "TODO: Apply ... to actual AudioUnit" notes throughout plugins.go), so
+// this always reports 0 for now.
+func (p *EnginePlugin) LatencySamples() int {
+	// TODO: query kAudioUnitProperty_Latency on the actual AudioUnit once
+	// EnginePlugin holds a live instance.
+	return 0
+}
+
+// TotalLatency sums LatencySamples across every non-bypassed plugin in the
+// chain; a bypassed plugin contributes 0 regardless of what it reports,
+// since its signal path is skipped entirely.
+func (pc *PluginChain) TotalLatency() int {
+	total := 0
+	for i := range pc.Plugins {
+		if pc.Plugins[i].Bypassed {
+			continue
+		}
+		total += pc.Plugins[i].LatencySamples()
+	}
+	return total
+}
+
+// ChannelLatency returns the total plugin-chain latency, in samples, for
+// the channel at bus, so the engine can align it against the
+// max-latency channel with a compensating delay (plugin delay compensation
+// - PDC). A missing channel, or one with no plugin chain, reports 0.
+func (e *Engine) ChannelLatency(bus int) int {
+	if bus < 0 || bus >= len(e.Channels) {
+		return 0
+	}
+	ch := e.Channels[bus]
+	if ch == nil || ch.InputOptions == nil || ch.InputOptions.PluginChain == nil {
+		return 0
+	}
+	return ch.InputOptions.PluginChain.TotalLatency()
+}
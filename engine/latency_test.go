@@ -0,0 +1,40 @@
+package engine
+
+import "testing"
+
+func TestPluginChainTotalLatencyIgnoresBypassed(t *testing.T) {
+	chain := NewPluginChain()
+	chain.Plugins = append(chain.Plugins,
+		EnginePlugin{Bypassed: false},
+		EnginePlugin{Bypassed: true},
+	)
+
+	// LatencySamples always reports 0 until plugins are wired to live
+	// AudioUnit instances, so this only exercises that bypassed plugins are
+	// skipped rather than asserting a nonzero sum.
+	if got := chain.TotalLatency(); got != 0 {
+		t.Fatalf("expected 0 total latency, got %d", got)
+	}
+}
+
+func TestEngineChannelLatencyMissingChannel(t *testing.T) {
+	e := &Engine{}
+	if got := e.ChannelLatency(0); got != 0 {
+		t.Fatalf("expected 0 latency for an empty channel slot, got %d", got)
+	}
+	if got := e.ChannelLatency(99); got != 0 {
+		t.Fatalf("expected 0 latency for an out-of-range bus index, got %d", got)
+	}
+}
+
+func TestEngineChannelLatencySumsChain(t *testing.T) {
+	ch := &Channel{BusIndex: 0, InputOptions: &InputOptions{PluginChain: NewPluginChain()}}
+	ch.InputOptions.PluginChain.Plugins = append(ch.InputOptions.PluginChain.Plugins, EnginePlugin{})
+
+	e := &Engine{}
+	e.Channels[0] = ch
+
+	if got := e.ChannelLatency(0); got != ch.InputOptions.PluginChain.TotalLatency() {
+		t.Fatalf("expected ChannelLatency to match the chain's TotalLatency, got %d", got)
+	}
+}
@@ -1,7 +1,7 @@
 package engine
 
 import (
-	"strings"
+	"errors"
 	"testing"
 
 	"github.com/shaban/macaudio/devices"
@@ -57,9 +57,8 @@ func TestEngineLifecycle(t *testing.T) {
 		t.Logf("Expected failure: Start failed due to missing audio graph implementation: %v", err)
 		
 		// Test that the error is the correct AVFoundation error
-		expectedError := "Engine start failed with exception"
-		if !strings.Contains(err.Error(), expectedError) {
-			t.Fatalf("Expected AVFoundation audio graph error, got: %v", err)
+		if !errors.Is(err, ErrNoAudioGraph) {
+			t.Fatalf("Expected ErrNoAudioGraph, got: %v", err)
 		}
 		
 		t.Logf("✅ Engine correctly fails when no audio graph is connected")
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SetLoopRegion sets the channel's loop region by offset in seconds into
+// the file, converting to frames via the owning engine's sample rate and
+// delegating to SetLoopRange, then enabling it - the seconds-based
+// convenience game-audio callers expect (cf. Ebiten's InfiniteLoop),
+// versus SetLoopRange/SetLoopEnabled's lower-level frame-offset pair.
+func (c *Channel) SetLoopRegion(startSec, endSec float64) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if c.engine == nil || c.engine.SampleRate <= 0 {
+		return errors.New("channel has no engine with a valid sample rate")
+	}
+
+	sampleRate := float64(c.engine.SampleRate)
+	startFrame := int64(startSec * sampleRate)
+	endFrame := int64(endSec * sampleRate)
+	if err := ValidateLoopRange(startFrame, endFrame); err != nil {
+		return err
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.PlaybackOptions.LoopRange = &LoopRegion{Start: uint64(startFrame), End: uint64(endFrame)}
+		c.PlaybackOptions.LoopEnabled = true
+		return nil
+	})
+}
+
+// SetLoopCount sets how many times playback restarts after reaching the
+// end of the loop region (or of the file, if no region is set) - 0 plays
+// once, -1 loops indefinitely. Backed by PlaybackOptions.Loops.
+func (c *Channel) SetLoopCount(n int) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if n < -1 {
+		return errors.New("loop count must be -1 (infinite) or >= 0")
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.PlaybackOptions.Loops = n
+		return nil
+	})
+}
+
+// SetLoopCrossfade enables (durationSec > 0) or disables (0) crossfading
+// the loop region's tail into its head across each loop boundary, so the
+// seam is glitch-free instead of a hard cut.
+//
+// TODO: the crossfade itself - pre-baking a mixed AVAudioPCMBuffer from
+// the loop tail/head and scheduling segments via
+// scheduleSegment:startingFrame:frameCount:atTime: instead of the whole
+// file - needs native bridging that doesn't exist yet (see the "Apply ...
+// to actual AudioUnit" notes in plugins.go for the same gap elsewhere).
+// For now this only records the configured duration; Play still schedules
+// the full file via audioplayer_load_file and loops it with a hard seam.
+func (c *Channel) SetLoopCrossfade(durationSec float64) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if durationSec < 0 {
+		return errors.New("crossfade duration cannot be negative")
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.PlaybackOptions.LoopCrossfadeDuration = time.Duration(durationSec * float64(time.Second))
+		return nil
+	})
+}
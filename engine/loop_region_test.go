@@ -0,0 +1,42 @@
+package engine
+
+import "testing"
+
+func TestSetLoopRegionCountAndCrossfade(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+
+	if err := channel.SetLoopRegion(0, 1); err != nil {
+		t.Fatalf("SetLoopRegion failed: %v", err)
+	}
+	if channel.PlaybackOptions.LoopRange == nil || !channel.PlaybackOptions.LoopEnabled {
+		t.Error("expected SetLoopRegion to set and enable a LoopRange")
+	}
+
+	if err := channel.SetLoopRegion(1, 0); err == nil {
+		t.Error("expected SetLoopRegion to reject an end before start")
+	}
+
+	if err := channel.SetLoopCount(-1); err != nil {
+		t.Fatalf("SetLoopCount(-1) failed: %v", err)
+	}
+	if channel.PlaybackOptions.Loops != -1 {
+		t.Errorf("expected Loops=-1, got %d", channel.PlaybackOptions.Loops)
+	}
+	if err := channel.SetLoopCount(-2); err == nil {
+		t.Error("expected SetLoopCount to reject values below -1")
+	}
+
+	if err := channel.SetLoopCrossfade(0.05); err != nil {
+		t.Fatalf("SetLoopCrossfade failed: %v", err)
+	}
+	if channel.PlaybackOptions.LoopCrossfadeDuration <= 0 {
+		t.Error("expected SetLoopCrossfade to record a positive duration")
+	}
+	if err := channel.SetLoopCrossfade(-1); err == nil {
+		t.Error("expected SetLoopCrossfade to reject a negative duration")
+	}
+}
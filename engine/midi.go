@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// MIDIOptions configures a channel whose input is MIDI rather than audio -
+// parallel to PlaybackOptions/InputOptions. SourceEndpoint names a CoreMIDI
+// source (see ListMIDISources) to read live events from. Sequence, if set,
+// is a stored performance a virtual "sequencer" source plays back synced to
+// the engine transport instead of (or alongside) the live endpoint.
+type MIDIOptions struct {
+	SourceEndpoint string       `json:"sourceEndpoint,omitempty"`
+	Sequence       []MIDIEvent  `json:"sequence,omitempty"`
+	PluginChain    *PluginChain `json:"pluginChain"`
+}
+
+// MIDIEvent is a single 3-byte MIDI channel message scheduled at a
+// sample-accurate position, e.g. for MIDIOptions.Sequence. Mirrors
+// AutomationPoint's AtSample convention.
+type MIDIEvent struct {
+	AtSample uint64 `json:"atSample"`
+	Status   byte   `json:"status"`
+	Data1    byte   `json:"data1"`
+	Data2    byte   `json:"data2"`
+}
+
+// IsMIDI returns true if this is a MIDI channel.
+func (c *Channel) IsMIDI() bool {
+	return c.MIDIOptions != nil
+}
+
+// AcceptsMIDI reports whether the plugin is a MusicDevice (instrument) or
+// MusicEffect AudioUnit - the two AU types that take MIDI input via
+// MusicDeviceMIDIEvent.
+func (p *EnginePlugin) AcceptsMIDI() bool {
+	if p.Plugin == nil {
+		return false
+	}
+	switch p.Plugin.Type {
+	case "aumu", "aumf":
+		return true
+	default:
+		return false
+	}
+}
+
+// firstMIDIPlugin returns the index of the first plugin in the chain that
+// AcceptsMIDI, or -1 if none does.
+func (pc *PluginChain) firstMIDIPlugin() int {
+	for i := range pc.Plugins {
+		if pc.Plugins[i].AcceptsMIDI() {
+			return i
+		}
+	}
+	return -1
+}
+
+// RouteMIDIEvent delivers event to the first MIDI-accepting plugin in the
+// chain, so a MIDI channel's output audio can come from a downstream
+// instrument plugin rather than a live audio input.
+//
+// TODO: apply event to the actual AudioUnit via MusicDeviceMIDIEvent once
+// plugin chains are wired to live AudioUnit instances (see the
+// "Apply ... to actual AudioUnit" notes throughout plugins.go).
+func (pc *PluginChain) RouteMIDIEvent(event MIDIEvent) error {
+	if pc.firstMIDIPlugin() == -1 {
+		return errors.New("no MIDI-accepting plugin in chain")
+	}
+	return nil
+}
+
+// MIDIEndpointInfo is a CoreMIDI source endpoint, trimmed to what
+// MIDIOptions.SourceEndpoint needs to address it by name; mirrors
+// devices.MIDIDevice's role for audio devices.
+type MIDIEndpointInfo struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	Manufacturer string `json:"manufacturer"`
+	EntityName   string `json:"entityName"`
+	Endpoint     int    `json:"endpoint"`
+}
+
+// ListMIDISources returns every CoreMIDI source endpoint available to route
+// into a channel's MIDIOptions.SourceEndpoint, mirroring how the devices
+// package enumerates audio devices.
+func ListMIDISources() ([]MIDIEndpointInfo, error) {
+	midiDevices, err := devices.GetMIDI()
+	if err != nil {
+		return nil, err
+	}
+	inputs := midiDevices.Inputs()
+	sources := make([]MIDIEndpointInfo, 0, len(inputs))
+	for _, d := range inputs {
+		sources = append(sources, MIDIEndpointInfo{
+			Name:         d.Name,
+			DisplayName:  d.DisplayName,
+			Manufacturer: d.Manufacturer,
+			EntityName:   d.EntityName,
+			Endpoint:     d.GetInputEndpoint(),
+		})
+	}
+	return sources, nil
+}
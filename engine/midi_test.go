@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func TestEnginePluginAcceptsMIDI(t *testing.T) {
+	instrument := &EnginePlugin{Plugin: &plugins.Plugin{Type: "aumu"}}
+	if !instrument.AcceptsMIDI() {
+		t.Error("expected a MusicDevice (aumu) plugin to accept MIDI")
+	}
+
+	effect := &EnginePlugin{Plugin: &plugins.Plugin{Type: "aumf"}}
+	if !effect.AcceptsMIDI() {
+		t.Error("expected a MusicEffect (aumf) plugin to accept MIDI")
+	}
+
+	fx := &EnginePlugin{Plugin: &plugins.Plugin{Type: "aufx"}}
+	if fx.AcceptsMIDI() {
+		t.Error("expected a plain effect (aufx) plugin to not accept MIDI")
+	}
+
+	uninstalled := &EnginePlugin{}
+	if uninstalled.AcceptsMIDI() {
+		t.Error("expected a plugin with no loaded Plugin to not accept MIDI")
+	}
+}
+
+func TestPluginChainRouteMIDIEvent(t *testing.T) {
+	chain := NewPluginChain()
+	chain.Plugins = append(chain.Plugins,
+		EnginePlugin{Plugin: &plugins.Plugin{Type: "aufx"}},
+		EnginePlugin{Plugin: &plugins.Plugin{Type: "aumu"}},
+	)
+
+	if err := chain.RouteMIDIEvent(MIDIEvent{Status: 0x90, Data1: 60, Data2: 100}); err != nil {
+		t.Fatalf("expected routing to succeed once a MIDI-accepting plugin is in the chain: %v", err)
+	}
+
+	emptyChain := NewPluginChain()
+	if err := emptyChain.RouteMIDIEvent(MIDIEvent{}); err == nil {
+		t.Error("expected an error routing MIDI with no MIDI-accepting plugin in the chain")
+	}
+}
+
+func TestChannelIsMIDI(t *testing.T) {
+	ch := &Channel{}
+	if ch.IsMIDI() {
+		t.Error("expected a channel with no MIDIOptions to report IsMIDI=false")
+	}
+
+	ch.MIDIOptions = &MIDIOptions{SourceEndpoint: "IAC Driver Bus 1"}
+	if !ch.IsMIDI() {
+		t.Error("expected a channel with MIDIOptions to report IsMIDI=true")
+	}
+}
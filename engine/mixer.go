@@ -0,0 +1,308 @@
+package engine
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+)
+
+// defaultMixerMaxPlayers caps simultaneous MixerPlayers when NewMixer is
+// called with maxPlayers <= 0, bounding how many sources get summed (and
+// how much they can clip) before a caller tunes it explicitly.
+const defaultMixerMaxPlayers = 32
+
+// ErrMixerFull is returned by NewPlayer/NewPlayerFromBytes once a Mixer
+// already has maxPlayers active MixerPlayers.
+var ErrMixerFull = errors.New("engine: mixer has reached its maximum simultaneous players")
+
+// Mixer is a software-summed output sharing a single native player node
+// across many cheap MixerPlayer handles, mirroring Ebiten's audio.Context
+// model. CreateStreamPlaybackChannel (stream_player.go) gives every source
+// its own AVAudioPlayerNode, which is fine for a handful of long-lived
+// players but would exhaust the AUGraph under the polyphony a game's SFX
+// need; Mixer instead decodes and sums every active MixerPlayer's frames
+// in software each pull, and schedules a single mixed buffer. Mixer itself
+// implements StreamDecoder, so NewMixer wires it up via
+// CreateStreamPlaybackChannel exactly like any other streaming source.
+type Mixer struct {
+	sampleRate int
+	channels   int // fixed at 2; Mixer only mixes down to stereo
+	maxPlayers int
+	mixChannel *Channel
+
+	mu      sync.Mutex
+	players []*MixerPlayer
+}
+
+// NewMixer creates a Mixer at sampleRate and wires it into the engine as a
+// streaming playback channel. maxPlayers <= 0 uses defaultMixerMaxPlayers.
+func (e *Engine) NewMixer(sampleRate int, maxPlayers int) (*Mixer, error) {
+	if sampleRate <= 0 {
+		return nil, errors.New("sample rate must be positive")
+	}
+	if maxPlayers <= 0 {
+		maxPlayers = defaultMixerMaxPlayers
+	}
+
+	m := &Mixer{sampleRate: sampleRate, channels: 2, maxPlayers: maxPlayers}
+	ch, err := e.CreateStreamPlaybackChannel(m)
+	if err != nil {
+		return nil, err
+	}
+	m.mixChannel = ch
+	return m, nil
+}
+
+// SampleRate and ChannelCount satisfy StreamDecoder, so a Mixer can be
+// passed directly to CreateStreamPlaybackChannel.
+func (m *Mixer) SampleRate() int   { return m.sampleRate }
+func (m *Mixer) ChannelCount() int { return m.channels }
+
+// ReadFrames satisfies StreamDecoder: it zeroes buf, then pulls and sums
+// every active MixerPlayer's contribution into it, dropping players that
+// report themselves finished (one-shot playback has run out, or Close was
+// called). A Mixer never itself reaches io.EOF - with no active players it
+// just produces silence - so the backing channel stays open indefinitely.
+func (m *Mixer) ReadFrames(buf []float32) (int, error) {
+	frames := len(buf) / m.channels
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	m.mu.Lock()
+	kept := m.players[:0]
+	for _, p := range m.players {
+		if p.pull(buf, frames) {
+			kept = append(kept, p)
+		}
+	}
+	m.players = kept
+	m.mu.Unlock()
+
+	return frames, nil
+}
+
+// Close stops every registered MixerPlayer and tears down the mixer's
+// backing playback channel.
+func (m *Mixer) Close() error {
+	m.mu.Lock()
+	for _, p := range m.players {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+	}
+	m.players = nil
+	m.mu.Unlock()
+
+	if m.mixChannel == nil {
+		return nil
+	}
+	return m.mixChannel.Stop()
+}
+
+// register adds p to the mixer's active player list, rejecting it with
+// ErrMixerFull once maxPlayers is already reached.
+func (m *Mixer) register(p *MixerPlayer) (*MixerPlayer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.players) >= m.maxPlayers {
+		return nil, ErrMixerFull
+	}
+	p.mixer = m
+	m.players = append(m.players, p)
+	return p, nil
+}
+
+// NewPlayerFromBytes creates a MixerPlayer from pcm, a buffer of
+// interleaved little-endian float32 stereo samples (the same layout
+// AVAudioPCMBuffer uses internally), decoded up front so the returned
+// player supports Seek. Short sound effects loaded once (e.g. via
+// embed.FS) are the intended source here; for anything too large to
+// decode wholesale, use NewPlayer with a streaming StreamDecoder instead.
+func (m *Mixer) NewPlayerFromBytes(pcm []byte) (*MixerPlayer, error) {
+	const bytesPerStereoFrame = 4 * 2 // float32 * 2 channels
+	if len(pcm)%bytesPerStereoFrame != 0 {
+		return nil, errors.New("pcm byte length must be a whole number of interleaved stereo float32 frames")
+	}
+
+	raw := make([]float32, len(pcm)/4)
+	for i := range raw {
+		bits := binary.LittleEndian.Uint32(pcm[i*4 : i*4+4])
+		raw[i] = math.Float32frombits(bits)
+	}
+
+	return m.register(&MixerPlayer{raw: raw, totalFrames: len(raw) / 2, volume: 1})
+}
+
+// NewPlayer creates a MixerPlayer pulling from src as it plays, rather
+// than decoding everything up front like NewPlayerFromBytes. src must
+// report two channels - Mixer only sums down to stereo - and a
+// decoder-backed player can't Seek, since StreamDecoder has no rewind.
+// Uses StreamDecoder (see stream_player.go) rather than a raw io.Reader,
+// since a reader alone carries no format information to decode.
+func (m *Mixer) NewPlayer(src StreamDecoder) (*MixerPlayer, error) {
+	if src == nil {
+		return nil, errors.New("source cannot be nil")
+	}
+	if src.ChannelCount() != 2 {
+		return nil, errors.New("mixer only supports stereo sources")
+	}
+
+	return m.register(&MixerPlayer{decoder: src, volume: 1})
+}
+
+// MixerPlayer is a cheap per-source playback handle returned by
+// Mixer.NewPlayer/NewPlayerFromBytes; many can be active at once, summed
+// in software by the owning Mixer's ReadFrames rather than each getting
+// its own native player node.
+type MixerPlayer struct {
+	mixer *Mixer
+
+	mu sync.Mutex
+
+	// raw/frameIndex/totalFrames back a NewPlayerFromBytes player; decoder
+	// backs a NewPlayer player. Exactly one is set.
+	raw         []float32
+	frameIndex  int
+	totalFrames int
+	decoder     StreamDecoder
+
+	playing bool
+	closed  bool
+	volume  float32
+	pan     float32 // -1 (left) to +1 (right), linear
+}
+
+// panGains returns the linear left/right gain for pan in [-1, 1]. This is
+// a simple linear pan rather than an equal-power curve, which is enough
+// for game SFX positioning and keeps the mix loop allocation-free.
+func panGains(pan float32) (left, right float32) {
+	if pan < -1 {
+		pan = -1
+	} else if pan > 1 {
+		pan = 1
+	}
+	left = 1 - max32(pan, 0)
+	right = 1 + min32(pan, 0)
+	return left, right
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// pull sums up to frames stereo frames of p's contribution into buf
+// (len(buf) == frames*2), scaled by volume/pan, and reports whether p is
+// still alive. A one-shot NewPlayerFromBytes player that reaches the end
+// of its buffer, a NewPlayer player whose decoder returns an error, or a
+// Closed player all report false so Mixer.ReadFrames drops them.
+func (p *MixerPlayer) pull(buf []float32, frames int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	if !p.playing {
+		return true
+	}
+
+	left, right := panGains(p.pan)
+
+	if p.raw != nil {
+		for f := 0; f < frames; f++ {
+			if p.frameIndex >= p.totalFrames {
+				p.playing = false
+				return false
+			}
+			buf[f*2] += p.raw[p.frameIndex*2] * p.volume * left
+			buf[f*2+1] += p.raw[p.frameIndex*2+1] * p.volume * right
+			p.frameIndex++
+		}
+		return true
+	}
+
+	scratch := make([]float32, frames*2)
+	n, err := p.decoder.ReadFrames(scratch)
+	for f := 0; f < n; f++ {
+		buf[f*2] += scratch[f*2] * p.volume * left
+		buf[f*2+1] += scratch[f*2+1] * p.volume * right
+	}
+	if err != nil {
+		p.playing = false
+		return false
+	}
+	return true
+}
+
+// Play starts or resumes playback.
+func (p *MixerPlayer) Play() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return errors.New("mixer player is closed")
+	}
+	p.playing = true
+	return nil
+}
+
+// Pause stops contributing frames without releasing the player - Play
+// resumes from the same position.
+func (p *MixerPlayer) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.playing = false
+	return nil
+}
+
+// Seek moves a NewPlayerFromBytes player to frame. Decoder-backed
+// (NewPlayer) players can't seek, since StreamDecoder has no rewind.
+func (p *MixerPlayer) Seek(frame int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.raw == nil {
+		return errors.New("mixer: cannot seek a streaming decoder-backed player")
+	}
+	if frame < 0 || frame > p.totalFrames {
+		return errors.New("mixer: seek frame out of range")
+	}
+	p.frameIndex = frame
+	return nil
+}
+
+// SetVolume sets this player's linear gain (0 silences it; 1 is unity).
+func (p *MixerPlayer) SetVolume(volume float32) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volume = volume
+	return nil
+}
+
+// SetPan sets this player's stereo position, -1 (full left) to +1 (full
+// right).
+func (p *MixerPlayer) SetPan(pan float32) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pan = pan
+	return nil
+}
+
+// Close stops the player and removes it from its Mixer on the next pull.
+func (p *MixerPlayer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.playing = false
+	p.closed = true
+	return nil
+}
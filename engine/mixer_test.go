@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func float32PCMBytes(samples []float32) []byte {
+	buf := make([]byte, len(samples)*4)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(s))
+	}
+	return buf
+}
+
+func TestMixerReadFramesSumsActivePlayers(t *testing.T) {
+	m := &Mixer{sampleRate: 44100, channels: 2, maxPlayers: 4}
+
+	a, err := m.NewPlayerFromBytes(float32PCMBytes([]float32{0.5, 0.5, 0.5, 0.5}))
+	if err != nil {
+		t.Fatalf("NewPlayerFromBytes: %v", err)
+	}
+	b, err := m.NewPlayerFromBytes(float32PCMBytes([]float32{0.25, 0.25, 0.25, 0.25}))
+	if err != nil {
+		t.Fatalf("NewPlayerFromBytes: %v", err)
+	}
+	a.Play()
+	b.Play()
+
+	buf := make([]float32, 4) // 2 stereo frames
+	n, err := m.ReadFrames(buf)
+	if err != nil || n != 2 {
+		t.Fatalf("ReadFrames: n=%d err=%v", n, err)
+	}
+	if buf[0] < 0.74 || buf[0] > 0.76 {
+		t.Errorf("expected summed first sample ~0.75, got %v", buf[0])
+	}
+}
+
+func TestMixerPlayerFromBytesFinishesAndDrops(t *testing.T) {
+	m := &Mixer{sampleRate: 44100, channels: 2, maxPlayers: 4}
+	p, err := m.NewPlayerFromBytes(float32PCMBytes([]float32{1, 1}))
+	if err != nil {
+		t.Fatalf("NewPlayerFromBytes: %v", err)
+	}
+	p.Play()
+
+	buf := make([]float32, 4) // request 2 frames but only 1 is available
+	if _, err := m.ReadFrames(buf); err != nil {
+		t.Fatalf("ReadFrames: %v", err)
+	}
+
+	m.mu.Lock()
+	remaining := len(m.players)
+	m.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("expected the exhausted player to be dropped, got %d remaining", remaining)
+	}
+}
+
+func TestMixerRejectsOverMaxPlayers(t *testing.T) {
+	m := &Mixer{sampleRate: 44100, channels: 2, maxPlayers: 1}
+	if _, err := m.NewPlayerFromBytes(float32PCMBytes([]float32{0, 0})); err != nil {
+		t.Fatalf("first NewPlayerFromBytes: %v", err)
+	}
+	if _, err := m.NewPlayerFromBytes(float32PCMBytes([]float32{0, 0})); err != ErrMixerFull {
+		t.Errorf("expected ErrMixerFull once maxPlayers is reached, got %v", err)
+	}
+}
+
+func TestMixerPlayerSeekAndPan(t *testing.T) {
+	m := &Mixer{sampleRate: 44100, channels: 2, maxPlayers: 4}
+	p, err := m.NewPlayerFromBytes(float32PCMBytes([]float32{1, 1, 0.5, 0.5}))
+	if err != nil {
+		t.Fatalf("NewPlayerFromBytes: %v", err)
+	}
+	p.SetPan(-1) // full left
+	p.Play()
+	if err := p.Seek(1); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+
+	buf := make([]float32, 2)
+	if _, err := m.ReadFrames(buf); err != nil {
+		t.Fatalf("ReadFrames: %v", err)
+	}
+	if buf[0] < 0.49 || buf[0] > 0.51 {
+		t.Errorf("expected left channel to carry the seeked frame's sample, got %v", buf[0])
+	}
+	if buf[1] != 0 {
+		t.Errorf("expected right channel silenced by full-left pan, got %v", buf[1])
+	}
+
+	decoderPlayer, err := m.NewPlayer(fakeDecoder{sampleRate: 44100, channels: 2})
+	if err != nil {
+		t.Fatalf("NewPlayer: %v", err)
+	}
+	if err := decoderPlayer.Seek(0); err == nil {
+		t.Error("expected Seek to fail on a streaming decoder-backed player")
+	}
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRenderOfflineRejectsUninitializedEngine(t *testing.T) {
+	var e Engine
+	if err := e.RenderOffline(context.Background(), "/tmp/out.wav", time.Second, AudioFileFormatWAV, nil); err == nil {
+		t.Error("expected an error rendering with no native engine")
+	}
+}
+
+func TestRenderOfflineRejectsNonPositiveDuration(t *testing.T) {
+	var e Engine
+	if err := e.RenderOffline(context.Background(), "/tmp/out.wav", 0, AudioFileFormatWAV, nil); err == nil {
+		t.Error("expected an error rendering with a zero duration")
+	}
+}
+
+func TestStartStopRejectedDuringRender(t *testing.T) {
+	var e Engine
+	e.renderFlag.Store(true)
+	defer e.renderFlag.Store(false)
+
+	if err := e.Start(); err != ErrRenderInProgress {
+		t.Errorf("expected ErrRenderInProgress from Start during a render, got %v", err)
+	}
+	e.Stop() // must not panic or touch a nil nativeEngine
+}
@@ -6,9 +6,12 @@ package engine
 */
 import "C"
 import (
+	"context"
 	"errors"
 	"fmt"
 	"unsafe"
+
+	"github.com/shaban/macaudio/session"
 )
 
 // =============================================================================
@@ -19,7 +22,7 @@ import (
 func (e *Engine) CreatePlaybackChannel(filePath string) (*Channel, error) {
 	// Check if engine is properly initialized
 	if e.nativeEngine == nil {
-		return nil, errors.New("engine is not properly initialized")
+		return nil, ErrNoAudioGraph
 	}
 
 	// Validate file path
@@ -28,6 +31,10 @@ func (e *Engine) CreatePlaybackChannel(filePath string) (*Channel, error) {
 	}
 
 	// TODO: Validate file format and size (200MB limit)
+	// Metadata is best-effort: a probe failure (unrecognized tag
+	// container, unreadable file) shouldn't block channel creation, so
+	// errors are swallowed and PlaybackOptions.Metadata is left nil.
+	meta, _ := session.ProbeAudioFile(filePath)
 	channel := &Channel{
 		Volume: 1.0,
 		Pan:    0.0,
@@ -35,8 +42,11 @@ func (e *Engine) CreatePlaybackChannel(filePath string) (*Channel, error) {
 			FilePath: filePath,
 			Rate:     1.0, // Normal playback rate
 			Pitch:    0.0, // No pitch shift
+			Metadata: meta,
 		},
+		engine: e,
 	}
+	defaultChannelSolo.register(channel)
 
 	// Create native player using the C API
 	result := C.audioplayer_new(unsafe.Pointer(e.nativeEngine.engine))
@@ -56,7 +66,7 @@ func (e *Engine) CreatePlaybackChannel(filePath string) (*Channel, error) {
 	if errorStr != nil {
 		// Clean up the player if file loading fails
 		C.audioplayer_destroy(playerPtr)
-		return nil, errors.New("failed to load audio file: " + C.GoString(errorStr))
+		return nil, classifyNativeError(C.GoString(errorStr))
 	}
 
 	// Enable time/pitch effects by default
@@ -158,26 +168,52 @@ func (e *Engine) CreatePlaybackChannel(filePath string) (*Channel, error) {
 		// But we'll log it for debugging
 	}
 
-	e.Channels = append(e.Channels, channel)
+	if err := e.runSync(context.Background(), func(ctx context.Context) error {
+		e.Channels = append(e.Channels, channel)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 	return channel, nil
 }
 
-// PlayChannel starts playback for a playback channel
+// Play starts playback for a playback channel, or resumes it from where
+// Pause left off. See TransportState, Pause, Stop and Seek for the rest of
+// the transport.
 func (c *Channel) Play() error {
 	if !c.IsPlayback() {
 		return errors.New("channel is not a playback channel")
 	}
 
+	if !c.PlaybackOptions.Streamed {
+		if err := checkPlaybackFile(c.PlaybackOptions.FilePath); err != nil {
+			return err
+		}
+	}
+
 	if c.PlaybackOptions.playerPtr == nil {
 		return errors.New("no native player available")
 	}
 
-	playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
-	errorStr := C.audioplayer_play(playerPtr)
-	if errorStr != nil {
-		return errors.New("failed to start playback: " + C.GoString(errorStr))
+	if err := c.runOnEngine(func(ctx context.Context) error {
+		return c.withTransition(Playing, func() error {
+			playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
+			errorStr := C.audioplayer_play(playerPtr)
+			if errorStr != nil {
+				return errors.New("failed to start playback: " + C.GoString(errorStr))
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
 	}
 
+	// FadeIn runs on its own goroutine and calls back through SetVolume, so
+	// it must start after (not inside) the runOnEngine block above - doing
+	// it from within would deadlock the single-worker control queue.
+	if c.PlaybackOptions.FadeInDuration > 0 {
+		return c.FadeIn(c.PlaybackOptions.FadeInDuration)
+	}
 	return nil
 }
 
@@ -268,7 +304,9 @@ func (c *Channel) GetPlaybackRate() (float32, error) {
 	return float32(rate), nil
 }
 
-// SetPitch sets the pitch shift in semitones (-12 to +12, normal = 0)
+// SetPitch sets the pitch shift in semitones (-12 to +12, normal = 0). The
+// native AVAudioUnitTimePitch exposes a single pitch knob in cents, so the
+// value actually written combines this with Detune - see applyCombinedPitch.
 func (c *Channel) SetPitch(pitch float32) error {
 	if !c.IsPlayback() {
 		return errors.New("channel is not a playback channel")
@@ -283,21 +321,49 @@ func (c *Channel) SetPitch(pitch float32) error {
 		return err
 	}
 
-	// Update cached value with validated value
-	c.PlaybackOptions.Pitch = pitch
+	return c.runOnEngine(func(ctx context.Context) error {
+		// Update cached value with validated value
+		c.PlaybackOptions.Pitch = pitch
+		return c.applyCombinedPitch()
+	})
+}
 
-	// Convert semitones to cents (1 semitone = 100 cents)
-	pitchInCents := pitch * 100.0
+// applyCombinedPitch writes Pitch and Detune to the native time/pitch unit
+// as a single cents value - AVAudioUnitTimePitch has no separate detune
+// register, so fine-tuning rides on top of the coarse semitone shift the
+// same way a synth voice's "fine" knob sums into its "coarse" one.
+func (c *Channel) applyCombinedPitch() error {
+	pitchInCents := c.PlaybackOptions.Pitch*100.0 + c.PlaybackOptions.Detune
 
 	playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
 	errorStr := C.audioplayer_set_pitch(playerPtr, C.float(pitchInCents))
 	if errorStr != nil {
 		return errors.New("failed to set pitch: " + C.GoString(errorStr))
 	}
-
 	return nil
 }
 
+// SetDetune applies a fine pitch offset in cents (-100 to +100, normal = 0)
+// on top of SetPitch's coarse semitone shift - see applyCombinedPitch.
+func (c *Channel) SetDetune(cents float32) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+
+	if c.PlaybackOptions.playerPtr == nil {
+		return errors.New("no native player available")
+	}
+
+	if err := ValidateDetune(cents); err != nil {
+		return err
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.PlaybackOptions.Detune = cents
+		return c.applyCombinedPitch()
+	})
+}
+
 // GetPitch returns the current pitch shift in semitones
 func (c *Channel) GetPitch() (float32, error) {
 	if !c.IsPlayback() {
@@ -74,3 +74,82 @@ func TestCreatePluginFromInfo(t *testing.T) {
 		}
 	})
 }
+
+// memPluginCache is a minimal in-memory plugins.Cache for exercising
+// CreatePluginFromInfoCached without depending on plugins/cache's
+// filesystem-backed implementation.
+type memPluginCache struct {
+	entries     map[plugins.Key]*plugins.Plugin
+	blacklisted map[plugins.Key]string
+	puts        int
+}
+
+func newMemPluginCache() *memPluginCache {
+	return &memPluginCache{entries: make(map[plugins.Key]*plugins.Plugin), blacklisted: make(map[plugins.Key]string)}
+}
+
+func (c *memPluginCache) Get(key plugins.Key) (*plugins.Plugin, bool) {
+	p, ok := c.entries[key]
+	return p, ok
+}
+
+func (c *memPluginCache) Put(key plugins.Key, p *plugins.Plugin) error {
+	c.entries[key] = p
+	c.puts++
+	return nil
+}
+
+func (c *memPluginCache) Invalidate(key plugins.Key) error {
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *memPluginCache) IsBlacklisted(key plugins.Key) bool {
+	_, ok := c.blacklisted[key]
+	return ok
+}
+
+func (c *memPluginCache) Blacklist(key plugins.Key, reason string) error {
+	c.blacklisted[key] = reason
+	return nil
+}
+
+func (c *memPluginCache) Unblacklist(key plugins.Key) error {
+	delete(c.blacklisted, key)
+	return nil
+}
+
+func TestCreatePluginFromInfoCached(t *testing.T) {
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Skip("No plugins available for testing")
+	}
+	if len(pluginInfos) == 0 {
+		t.Skip("No plugins found")
+	}
+	pluginInfo := pluginInfos[0]
+
+	cache := newMemPluginCache()
+
+	enginePlugin, err := CreatePluginFromInfoCached(pluginInfo, cache)
+	if err != nil {
+		t.Fatalf("CreatePluginFromInfoCached (cold) failed: %v", err)
+	}
+	if !enginePlugin.IsInstalled {
+		t.Skip("plugin could not be introspected on this machine")
+	}
+	if cache.puts != 1 {
+		t.Errorf("expected one cache write after a cold load, got %d", cache.puts)
+	}
+
+	enginePlugin, err = CreatePluginFromInfoCached(pluginInfo, cache)
+	if err != nil {
+		t.Fatalf("CreatePluginFromInfoCached (warm) failed: %v", err)
+	}
+	if !enginePlugin.IsInstalled || enginePlugin.Plugin == nil {
+		t.Fatal("expected a warm load to still report the plugin installed")
+	}
+	if cache.puts != 1 {
+		t.Errorf("expected no additional cache write on a warm load, got %d puts", cache.puts)
+	}
+}
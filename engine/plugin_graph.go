@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shaban/macaudio/avaudio/unit"
+)
+
+// chainNode returns the native node at position pos in c's input plugin
+// chain: -1 is the channel's source node (InputOptions.inputNodePtr),
+// len(pc.Plugins) is the channel's own mixer node, and anything in between
+// is that plugin's AudioUnit (EnginePlugin.effectPtr, set by InsertPlugin).
+// InsertPlugin/RemovePlugin use it to find the two edges a single splice
+// needs to touch without walking the whole chain.
+func (c *Channel) chainNode(pos int) NodeRef {
+	pc := c.InputOptions.PluginChain
+	switch {
+	case pos < 0:
+		return NodeRef{ptr: c.InputOptions.inputNodePtr}
+	case pos >= len(pc.Plugins):
+		return NodeRef{ptr: c.mixerNodePtr}
+	default:
+		return NodeRef{ptr: pc.Plugins[pos].effectPtr}
+	}
+}
+
+// InsertPlugin wires p's native AudioUnit into c's input plugin chain at
+// idx, splicing it between whatever nodes currently sit on either side (the
+// channel's source node, the main mixer, or an adjacent plugin) instead of
+// tearing down and reconnecting the whole chain - the native counterpart to
+// PluginChain.AddPlugin, which only updates the Go-side bookkeeping list
+// (see its "TODO: Connect plugin to audio chain"). Only input channels
+// carry a PluginChain today (see sends.go), and only ones with a native
+// source node - built by CreateCaptureChannel, not the bare
+// CreateInputChannel - have anything to splice into.
+func (c *Channel) InsertPlugin(idx int, p *unit.Effect) error {
+	if c.engine == nil {
+		return errors.New("engine: channel has no owning engine")
+	}
+	if c.InputOptions == nil || c.InputOptions.PluginChain == nil {
+		return errors.New("engine: InsertPlugin requires an input channel with a plugin chain")
+	}
+	if c.InputOptions.inputNodePtr == nil {
+		return errors.New("engine: channel has no native input node to splice a plugin into (see CreateCaptureChannel)")
+	}
+	pc := c.InputOptions.PluginChain
+	if idx < 0 || idx > len(pc.Plugins) {
+		return errors.New("invalid plugin index")
+	}
+	if p == nil || p.Ptr() == nil {
+		return errors.New("engine: plugin effect has no native node")
+	}
+
+	graph := c.engine.Graph()
+	effectNode := NodeRef{ptr: p.Ptr()}
+	upstream := c.chainNode(idx - 1)
+	downstream := c.chainNode(idx)
+
+	if err := graph.Attach(effectNode); err != nil {
+		return fmt.Errorf("engine: attach plugin: %w", err)
+	}
+	if err := graph.Disconnect(upstream, downstream); err != nil {
+		return fmt.Errorf("engine: disconnect existing edge: %w", err)
+	}
+	if err := graph.Route(upstream, effectNode); err != nil {
+		return fmt.Errorf("engine: connect plugin input: %w", err)
+	}
+	if err := graph.Route(effectNode, downstream); err != nil {
+		return fmt.Errorf("engine: connect plugin output: %w", err)
+	}
+
+	ep := EnginePlugin{IsInstalled: true, Plugin: p.GetPlugin(), effectPtr: p.Ptr()}
+	pc.Plugins = append(pc.Plugins, EnginePlugin{})
+	copy(pc.Plugins[idx+1:], pc.Plugins[idx:])
+	pc.Plugins[idx] = ep
+	return nil
+}
+
+// RemovePlugin undoes InsertPlugin: it reconnects the node before idx
+// directly to the node after it, then drops idx from the chain - the
+// native counterpart to PluginChain.RemovePlugin, which only updates
+// bookkeeping (see its "TODO: Disconnect plugin from audio chain").
+func (c *Channel) RemovePlugin(idx int) error {
+	if c.engine == nil {
+		return errors.New("engine: channel has no owning engine")
+	}
+	if c.InputOptions == nil || c.InputOptions.PluginChain == nil {
+		return errors.New("engine: RemovePlugin requires an input channel with a plugin chain")
+	}
+	pc := c.InputOptions.PluginChain
+	if idx < 0 || idx >= len(pc.Plugins) {
+		return errors.New("invalid plugin index")
+	}
+
+	graph := c.engine.Graph()
+	upstream := c.chainNode(idx - 1)
+	removed := c.chainNode(idx)
+	downstream := c.chainNode(idx + 1)
+
+	if err := graph.Disconnect(upstream, removed); err != nil {
+		return fmt.Errorf("engine: disconnect existing edge: %w", err)
+	}
+	if err := graph.Disconnect(removed, downstream); err != nil {
+		return fmt.Errorf("engine: disconnect removed plugin: %w", err)
+	}
+	if err := graph.Route(upstream, downstream); err != nil {
+		return fmt.Errorf("engine: reconnect chain: %w", err)
+	}
+
+	pc.Plugins = append(pc.Plugins[:idx], pc.Plugins[idx+1:]...)
+	return nil
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// EnginePluginState is EnginePlugin.SaveState's on-disk shape: the portable
+// parameter snapshot plugins.Plugin.CaptureState already produces, plus an
+// optional ClassInfo capture for plugins whose behavior isn't fully
+// expressible as a flat parameter list - the same plugins.Preset.ClassInfo
+// tradeoff, just carried alongside a PluginState instead of a Preset.
+type EnginePluginState struct {
+	plugins.PluginState
+	// ClassInfo is populated when plugins.PluginInfo.CaptureClassInfo
+	// succeeds; left nil otherwise (including on hosts where its native
+	// half - see CaptureClassInfo's doc comment - doesn't exist yet).
+	ClassInfo []byte `json:"classInfo,omitempty"`
+}
+
+// SaveState captures p's full portable state: CaptureState's parameter
+// snapshot, plus a best-effort ClassInfo capture. A ClassInfo capture
+// failure isn't fatal - it's left nil and the state still round-trips via
+// the parameter snapshot alone, the same way FactoryPresets treats "nothing
+// found" as an empty result rather than an error.
+func (p *EnginePlugin) SaveState() ([]byte, error) {
+	if p.Plugin == nil {
+		return nil, errors.New("plugin not initialized")
+	}
+
+	state := EnginePluginState{PluginState: p.Plugin.CaptureState()}
+	info := plugins.PluginInfo{
+		Name:           p.Plugin.Name,
+		ManufacturerID: p.Plugin.ManufacturerID,
+		Type:           p.Plugin.Type,
+		Subtype:        p.Plugin.Subtype,
+	}
+	if classInfo, err := info.CaptureClassInfo(); err == nil {
+		state.ClassInfo = classInfo
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin state: %w", err)
+	}
+	return data, nil
+}
+
+// LoadState applies a state previously captured by SaveState to p, matching
+// parameters by Address and falling back to Identifier, the same lookup
+// order plugins.PluginState.Diff uses. ClassInfo, if present, isn't applied
+// - there's no native "restore full state" entry point in this tree yet,
+// the same gap CaptureClassInfo itself documents.
+func (p *EnginePlugin) LoadState(data []byte) error {
+	if p.Plugin == nil {
+		return errors.New("plugin not initialized")
+	}
+
+	var state EnginePluginState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to unmarshal plugin state: %w", err)
+	}
+
+	for _, snap := range state.Snapshot {
+		for i := range p.Plugin.Parameters {
+			param := &p.Plugin.Parameters[i]
+			if param.Address == snap.Address || param.Identifier == snap.Identifier {
+				param.CurrentValue = snap.Value
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// PluginChainSnapshot captures a PluginChain's restorable state: each slot's
+// bypass flag and SaveState blob, in chain order. There's no continuous
+// wet/dry mix anywhere in this package to capture alongside it - Bypassed is
+// the only dry/wet-adjacent knob PluginChain exposes - so Snapshot/Restore
+// only carry what the chain actually has.
+type PluginChainSnapshot struct {
+	Plugins []PluginSnapshot `json:"plugins"`
+}
+
+// PluginSnapshot is one PluginChainSnapshot slot. State is empty for a slot
+// with no installed Plugin.
+type PluginSnapshot struct {
+	Bypassed bool   `json:"bypassed"`
+	State    []byte `json:"state,omitempty"`
+}
+
+// Snapshot captures pc's current bypass flags and each installed plugin's
+// SaveState.
+func (pc *PluginChain) Snapshot() (PluginChainSnapshot, error) {
+	snap := PluginChainSnapshot{Plugins: make([]PluginSnapshot, len(pc.Plugins))}
+	for i := range pc.Plugins {
+		snap.Plugins[i].Bypassed = pc.Plugins[i].Bypassed
+		if pc.Plugins[i].Plugin == nil {
+			continue
+		}
+		state, err := pc.Plugins[i].SaveState()
+		if err != nil {
+			return PluginChainSnapshot{}, fmt.Errorf("plugin %d: %w", i, err)
+		}
+		snap.Plugins[i].State = state
+	}
+	return snap, nil
+}
+
+// Restore applies snap to pc, matching plugins positionally - pc must
+// already have the same number of plugin slots snap was taken from, the
+// same convention Engine.RestoreAll uses for channels.
+func (pc *PluginChain) Restore(snap PluginChainSnapshot) error {
+	if len(pc.Plugins) != len(snap.Plugins) {
+		return fmt.Errorf("chain has %d plugins but snapshot has %d - recreate the chain's plugins in the same order before Restore", len(pc.Plugins), len(snap.Plugins))
+	}
+
+	for i := range pc.Plugins {
+		pc.Plugins[i].Bypassed = snap.Plugins[i].Bypassed
+		if len(snap.Plugins[i].State) == 0 || pc.Plugins[i].Plugin == nil {
+			continue
+		}
+		if err := pc.Plugins[i].LoadState(snap.Plugins[i].State); err != nil {
+			return fmt.Errorf("plugin %d: %w", i, err)
+		}
+	}
+	return nil
+}
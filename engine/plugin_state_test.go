@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// TestEnginePluginLoadStateMatchesByAddressAndIdentifier exercises LoadState
+// directly against a hand-built EnginePluginState, rather than round-tripping
+// through SaveState - SaveState also calls plugins.PluginInfo.CaptureClassInfo,
+// whose native half doesn't exist in this tree yet (see its doc comment), so
+// a test can't exercise that path without a real AudioUnit host.
+func TestEnginePluginLoadStateMatchesByAddressAndIdentifier(t *testing.T) {
+	plugin := newTestEnginePlugin()
+
+	state := EnginePluginState{
+		PluginState: plugins.PluginState{
+			Snapshot: []plugins.ParameterSnapshot{
+				{Address: 0, Identifier: "time", Value: 0.9},
+				{Address: 99, Identifier: "feedback", Value: 0.1}, // renumbered address, identifier still matches
+			},
+		},
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to marshal test state: %v", err)
+	}
+
+	if err := plugin.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if plugin.Plugin.Parameters[0].CurrentValue != 0.9 {
+		t.Errorf("expected time=0.9, got %v", plugin.Plugin.Parameters[0].CurrentValue)
+	}
+	if plugin.Plugin.Parameters[1].CurrentValue != 0.1 {
+		t.Errorf("expected feedback=0.1 via identifier fallback, got %v", plugin.Plugin.Parameters[1].CurrentValue)
+	}
+}
+
+func TestPluginChainSnapshotRestoreBypassOnly(t *testing.T) {
+	chain := &PluginChain{
+		Plugins: []EnginePlugin{
+			{Bypassed: false},
+			{Bypassed: true},
+		},
+	}
+
+	snap, err := chain.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	chain.Plugins[0].Bypassed = true
+	chain.Plugins[1].Bypassed = false
+
+	if err := chain.Restore(snap); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if chain.Plugins[0].Bypassed {
+		t.Error("expected plugin 0 bypass restored to false")
+	}
+	if !chain.Plugins[1].Bypassed {
+		t.Error("expected plugin 1 bypass restored to true")
+	}
+}
+
+func TestPluginChainRestoreRejectsSlotCountMismatch(t *testing.T) {
+	chain := &PluginChain{Plugins: []EnginePlugin{{Bypassed: false}}}
+	snap, err := chain.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	chain.Plugins = append(chain.Plugins, EnginePlugin{Bypassed: false})
+	if err := chain.Restore(snap); err == nil {
+		t.Error("expected Restore to reject a slot-count mismatch")
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// compatibleAUTypes lists AU type pairs SwapPlugin accepts as an explicitly
+// declared exception to "types must match exactly" - aufx (effect) and aumf
+// (music effect, e.g. a MIDI-controlled filter) both sit on an effect slot
+// in the chain, so swapping between them is safe even though their AU type
+// codes differ.
+var compatibleAUTypes = map[[2]string]bool{
+	{"aufx", "aumf"}: true,
+	{"aumf", "aufx"}: true,
+}
+
+// ErrPluginTypeMismatch is returned by SwapPlugin when newPlugin's AU type
+// isn't the same as, or an explicitly declared compatible pair with, the
+// plugin currently at index. Callers that want to react to a mismatch
+// (e.g. offer to insert newPlugin in a new slot instead) should use
+// errors.As rather than matching the message.
+type ErrPluginTypeMismatch struct {
+	OldTriplet plugins.PluginTriplet
+	NewTriplet plugins.PluginTriplet
+}
+
+func (e *ErrPluginTypeMismatch) Error() string {
+	return fmt.Sprintf("plugin types and names must match: cannot swap %s/%s/%s for %s/%s/%s",
+		e.OldTriplet.Type, e.OldTriplet.Subtype, e.OldTriplet.ManufacturerID,
+		e.NewTriplet.Type, e.NewTriplet.Subtype, e.NewTriplet.ManufacturerID)
+}
+
+// SwapPlugin replaces the plugin at index with newPlugin in place, for live
+// A/B comparison or updating a plugin without tearing the chain down.
+//
+// newPlugin must be type-compatible with the plugin currently at index:
+// either the same AU type, or one of the explicitly declared compatible
+// pairs in compatibleAUTypes (e.g. aufx<->aumf). A slot with no plugin
+// installed (EnginePlugin.Plugin == nil, see CreatePluginFromInfo) accepts
+// any replacement, since there's no prior type to be compatible with.
+//
+// The old plugin's Bypassed state and automation Lanes are carried over onto
+// newPlugin so an in-progress automation/bypass setup survives the swap;
+// newPlugin's own Parameters (and therefore its own current values) are kept
+// as given.
+//
+// Ordering here mirrors AddPlugin/RemovePlugin elsewhere in this file:
+// attaching the new native AudioUnit node, reconnecting the graph at it, and
+// tearing down the old node are all still TODOs pending the Objective-C
+// bridge (see AddPlugin/RemovePlugin's own "TODO: Connect/Disconnect ...
+// audio chain" notes) - this performs the chain bookkeeping side of the
+// swap and validates compatibility up front, so the native half can be
+// dropped in without changing this method's contract or rolling back what
+// it already validated.
+func (pc *PluginChain) SwapPlugin(index int, newPlugin EnginePlugin) error {
+	if index < 0 || index >= len(pc.Plugins) {
+		return fmt.Errorf("invalid plugin index")
+	}
+
+	old := &pc.Plugins[index]
+	if old.Plugin != nil && newPlugin.Plugin != nil {
+		oldTriplet := plugins.PluginTriplet{Type: old.Plugin.Type, Subtype: old.Plugin.Subtype, ManufacturerID: old.Plugin.ManufacturerID}
+		newTriplet := plugins.PluginTriplet{Type: newPlugin.Plugin.Type, Subtype: newPlugin.Plugin.Subtype, ManufacturerID: newPlugin.Plugin.ManufacturerID}
+		if oldTriplet.Type != newTriplet.Type && !compatibleAUTypes[[2]string{oldTriplet.Type, newTriplet.Type}] {
+			return &ErrPluginTypeMismatch{OldTriplet: oldTriplet, NewTriplet: newTriplet}
+		}
+	}
+
+	// TODO: attach newPlugin's native AudioUnit node in parallel to old's,
+	// reconnect the graph at a render-quantum boundary, then detach old's
+	// node - see the method doc comment above.
+
+	newPlugin.Bypassed = old.Bypassed
+	newPlugin.Lanes = old.Lanes
+	pc.Plugins[index] = newPlugin
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func TestSwapPluginRejectsIncompatibleType(t *testing.T) {
+	chain := NewPluginChain()
+	old := EnginePlugin{IsInstalled: true, Plugin: &plugins.Plugin{Type: "aufx", Subtype: "XXXX", ManufacturerID: "appl"}}
+	if err := chain.AddPlugin(old); err != nil {
+		t.Fatalf("AddPlugin failed: %v", err)
+	}
+
+	incompatible := EnginePlugin{IsInstalled: true, Plugin: &plugins.Plugin{Type: "aumu", Subtype: "YYYY", ManufacturerID: "appl"}}
+	err := chain.SwapPlugin(0, incompatible)
+	if err == nil {
+		t.Fatal("expected SwapPlugin to reject an incompatible AU type")
+	}
+	var mismatch *ErrPluginTypeMismatch
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected an *ErrPluginTypeMismatch, got %T: %v", err, err)
+	}
+	if mismatch.OldTriplet.Type != "aufx" || mismatch.NewTriplet.Type != "aumu" {
+		t.Errorf("unexpected triplets in error: %+v", mismatch)
+	}
+
+	// The original plugin must still be in place after a rejected swap.
+	got, err := chain.GetPlugin(0)
+	if err != nil {
+		t.Fatalf("GetPlugin failed: %v", err)
+	}
+	if got.Plugin.Subtype != "XXXX" {
+		t.Errorf("expected the original plugin to remain installed, got subtype %q", got.Plugin.Subtype)
+	}
+}
+
+func TestSwapPluginAllowsDeclaredCompatiblePair(t *testing.T) {
+	chain := NewPluginChain()
+	old := EnginePlugin{IsInstalled: true, Plugin: &plugins.Plugin{Type: "aufx", Subtype: "XXXX", ManufacturerID: "appl"}, Bypassed: true}
+	if err := chain.AddPlugin(old); err != nil {
+		t.Fatalf("AddPlugin failed: %v", err)
+	}
+
+	next := EnginePlugin{IsInstalled: true, Plugin: &plugins.Plugin{Type: "aumf", Subtype: "ZZZZ", ManufacturerID: "appl"}}
+	if err := chain.SwapPlugin(0, next); err != nil {
+		t.Fatalf("SwapPlugin failed for a declared-compatible pair: %v", err)
+	}
+
+	got, err := chain.GetPlugin(0)
+	if err != nil {
+		t.Fatalf("GetPlugin failed: %v", err)
+	}
+	if got.Plugin.Type != "aumf" || got.Plugin.Subtype != "ZZZZ" {
+		t.Errorf("expected the new plugin installed, got %+v", got.Plugin)
+	}
+	if !got.Bypassed {
+		t.Error("expected Bypassed to carry over from the old plugin")
+	}
+}
+
+func TestSwapPluginIntoEmptySlotAcceptsAnyType(t *testing.T) {
+	chain := NewPluginChain()
+	if err := chain.AddPlugin(EnginePlugin{IsInstalled: false, Plugin: nil}); err != nil {
+		t.Fatalf("AddPlugin failed: %v", err)
+	}
+
+	next := EnginePlugin{IsInstalled: true, Plugin: &plugins.Plugin{Type: "aumu", Subtype: "ZZZZ", ManufacturerID: "appl"}}
+	if err := chain.SwapPlugin(0, next); err != nil {
+		t.Fatalf("SwapPlugin into an empty slot should always succeed, got: %v", err)
+	}
+}
+
+func TestSwapPluginRejectsInvalidIndex(t *testing.T) {
+	chain := NewPluginChain()
+	if err := chain.SwapPlugin(0, EnginePlugin{}); err == nil {
+		t.Error("expected SwapPlugin to reject an out-of-range index on an empty chain")
+	}
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"errors"
+	"unsafe"
 
 	"github.com/shaban/macaudio/plugins"
 )
@@ -9,6 +10,11 @@ import (
 // PluginChain manages a series of AudioUnit effects
 type PluginChain struct {
 	Plugins []EnginePlugin `json:"plugins"`
+
+	// SidechainInput configures the key signal for the first plugin in the
+	// chain that advertises a sidechain bus. nil means no sidechain is
+	// connected. See sends.go for the ChannelSend side of the routing.
+	SidechainInput *SidechainSlot `json:"sidechainInput,omitempty"`
 }
 
 // EnginePlugin represents an AudioUnit effect in the chain with engine-specific state
@@ -16,6 +22,22 @@ type EnginePlugin struct {
 	IsInstalled     bool            `json:"isInstalled"` // false when plugin no longer available on system
 	*plugins.Plugin `json:"plugin"` // embedded plugin with independent parameter values
 	Bypassed        bool            `json:"bypassed"` // Individual bypass control
+
+	// Lanes holds this plugin's per-parameter automation curves, keyed by
+	// the same identifier/display name SetPluginParameter resolves. See
+	// Automation in automation.go.
+	Lanes map[string]*AutomationLane `json:"lanes,omitempty"`
+
+	// automationRing queues sample-accurate parameter writes for the render
+	// callback to apply; see automation.go. Not serialized: it is transient
+	// scheduling state, not part of the parameter tree.
+	automationRing *automationRing `json:"-"`
+
+	// effectPtr is the native AudioUnit node this plugin is spliced into its
+	// channel's graph as, set by Channel.InsertPlugin (see graph.go) - nil
+	// until InsertPlugin wires it in, since AddPlugin only updates this
+	// bookkeeping list (see its TODO).
+	effectPtr unsafe.Pointer `json:"-"`
 }
 
 // NewPluginChain creates an empty plugin processing chain
@@ -155,6 +177,68 @@ func CreatePluginFromInfo(pluginInfo plugins.PluginInfo) (*EnginePlugin, error)
 	}, nil
 }
 
+// CreatePluginFromInfoCached is CreatePluginFromInfo, but consults cache
+// first via PluginInfo.IntrospectCached and only falls back to native
+// introspection on a cache miss or a changed plugin bundle (a stale
+// BundleModTime in plugins.Key) - see plugins.Cache and plugins.Prewarm for
+// warming many plugins' cache entries up front.
+//
+// Cache.Get hands back the very *Plugin it has stored, so without cloning,
+// two EnginePlugins built from the same cache key would share one
+// Parameters backing array - modifying one instance's parameter would
+// silently modify the other's too. Clone breaks that sharing; see
+// plugins.Plugin.Clone.
+func CreatePluginFromInfoCached(pluginInfo plugins.PluginInfo, cache plugins.Cache) (*EnginePlugin, error) {
+	plugin, err := pluginInfo.IntrospectCached(cache)
+	if err != nil {
+		// Introspection failed - plugin exists in list but can't be loaded
+		return &EnginePlugin{
+			IsInstalled: false,
+			Plugin:      nil,
+			Bypassed:    false,
+		}, nil // Return success with IsInstalled=false, not an error
+	}
+
+	owned := plugin.Clone()
+
+	// Successfully introspected (cached or fresh) - plugin is available and loaded
+	return &EnginePlugin{
+		IsInstalled: true,
+		Plugin:      &owned,
+		Bypassed:    false,
+	}, nil
+}
+
+// SetSidechain wires the plugin chain's sidechain key input to sourceBus at
+// the given tap point. pluginIndex names the plugin expected to consume the
+// sidechain bus (validated against the chain's bounds) but, matching
+// SidechainInput's existing single-slot model, the source is recorded on
+// the chain as a whole rather than per plugin.
+//
+// SetSidechain does not itself detect feedback loops; call
+// (*Engine).ValidateSendGraph after wiring sidechains, and before starting
+// playback, the same way AddSend requires for ChannelSend.
+func (pc *PluginChain) SetSidechain(pluginIndex int, sourceBus int, tap TapPoint) error {
+	if pluginIndex < 0 || pluginIndex >= len(pc.Plugins) {
+		return errors.New("invalid plugin index")
+	}
+	pc.SidechainInput = &SidechainSlot{SourceBusIndex: sourceBus, Gain: 1.0, Tap: tap}
+	return nil
+}
+
+// SetAutomationMode sets the AutomationMode on every lane already created
+// for the plugin at index (see EnginePlugin.Automation). Lanes created
+// afterward still default to AutomationOff until set explicitly.
+func (pc *PluginChain) SetAutomationMode(index int, mode AutomationMode) error {
+	if index < 0 || index >= len(pc.Plugins) {
+		return errors.New("invalid plugin index")
+	}
+	for _, lane := range pc.Plugins[index].Lanes {
+		lane.Mode = mode
+	}
+	return nil
+}
+
 // =============================================================================
 // Plugin Parameter Management
 // =============================================================================
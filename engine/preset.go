@@ -0,0 +1,567 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Preset is the JSON/plist-serializable snapshot of a single AudioUnit's
+// parameter state. It carries the same 4-tuple (type/subtype/manufacturer/
+// name) used by plugins.Plugin.Introspect, plus a version stamp and a
+// parameter-address-to-value map, so it matches both the existing JSON
+// serialization harness and Apple's kAudioUnitProperty_ClassInfo dictionary
+// layout when written out as an .aupreset plist.
+type Preset struct {
+	Type         string             `json:"type"`
+	Subtype      string             `json:"subtype"`
+	Manufacturer string             `json:"manufacturer"`
+	Name         string             `json:"name"`
+	Version      int                `json:"version"`
+	Data         map[uint64]float32 `json:"data"`
+}
+
+// Bank is a named collection of presets, one per plugin in a chain, so a
+// whole chain's state can be exported/imported as a single file.
+type Bank struct {
+	Presets []Preset `json:"presets"`
+}
+
+// Preset builds a Preset snapshot of the plugin's current parameter values.
+func (p *EnginePlugin) Preset() (Preset, error) {
+	if p.Plugin == nil {
+		return Preset{}, errors.New("plugin not initialized")
+	}
+
+	preset := Preset{
+		Type:         p.Plugin.Type,
+		Subtype:      p.Plugin.Subtype,
+		Manufacturer: p.Plugin.ManufacturerID,
+		Name:         p.Plugin.Name,
+		Version:      1,
+		Data:         make(map[uint64]float32, len(p.Plugin.Parameters)),
+	}
+	for _, param := range p.Plugin.Parameters {
+		preset.Data[param.Address] = param.CurrentValue
+	}
+	return preset, nil
+}
+
+// ApplyPreset loads parameter values from preset into the plugin, matching
+// by parameter address. The 4-tuple is checked first so a preset saved for
+// a different plugin is rejected rather than silently misapplied.
+func (p *EnginePlugin) ApplyPreset(preset Preset) error {
+	if p.Plugin == nil {
+		return errors.New("plugin not initialized")
+	}
+	if p.Plugin.Type != preset.Type || p.Plugin.Subtype != preset.Subtype ||
+		p.Plugin.ManufacturerID != preset.Manufacturer || p.Plugin.Name != preset.Name {
+		return fmt.Errorf("preset is for %s/%s/%s/%s, not %s/%s/%s/%s",
+			preset.Type, preset.Subtype, preset.Manufacturer, preset.Name,
+			p.Plugin.Type, p.Plugin.Subtype, p.Plugin.ManufacturerID, p.Plugin.Name)
+	}
+
+	for i := range p.Plugin.Parameters {
+		param := &p.Plugin.Parameters[i]
+		if value, ok := preset.Data[param.Address]; ok {
+			param.CurrentValue = value
+		}
+	}
+	return nil
+}
+
+// SavePreset writes the plugin's current parameter state to path as an
+// Audio Unit .aupreset property list, readable by Logic/GarageBand/Ardour
+// and any other host that loads kAudioUnitProperty_ClassInfo plists.
+func (p *EnginePlugin) SavePreset(path string) error {
+	preset, err := p.Preset()
+	if err != nil {
+		return err
+	}
+	return writeAUPreset(path, preset)
+}
+
+// LoadPreset reads an .aupreset plist from path and applies it to the
+// plugin, matching parameters by the 4-tuple and parameter address.
+func (p *EnginePlugin) LoadPreset(path string) error {
+	preset, err := readAUPreset(path)
+	if err != nil {
+		return err
+	}
+	return p.ApplyPreset(preset)
+}
+
+// SaveBank writes every plugin's preset in the chain to a single plist at
+// path, so a whole chain can be shared or restored in one file.
+func (pc *PluginChain) SaveBank(path string) error {
+	bank := Bank{Presets: make([]Preset, 0, len(pc.Plugins))}
+	for i := range pc.Plugins {
+		preset, err := pc.Plugins[i].Preset()
+		if err != nil {
+			return fmt.Errorf("plugin %d: %w", i, err)
+		}
+		bank.Presets = append(bank.Presets, preset)
+	}
+	return writeAUBank(path, bank)
+}
+
+// LoadBank reads a bank plist from path and applies each preset to the
+// matching plugin in the chain (by the 4-tuple), in order. Presets for
+// plugins that are no longer in the chain are skipped.
+func (pc *PluginChain) LoadBank(path string) error {
+	bank, err := readAUBank(path)
+	if err != nil {
+		return err
+	}
+	for _, preset := range bank.Presets {
+		for i := range pc.Plugins {
+			plugin := pc.Plugins[i].Plugin
+			if plugin == nil {
+				continue
+			}
+			if plugin.Type == preset.Type && plugin.Subtype == preset.Subtype &&
+				plugin.ManufacturerID == preset.Manufacturer && plugin.Name == preset.Name {
+				if err := pc.Plugins[i].ApplyPreset(preset); err != nil {
+					return fmt.Errorf("plugin %d: %w", i, err)
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// PresetDirectory is one plugin's conventional .aupreset search locations:
+// UserDir is where SavePreset/LoadPreset-style host conventions keep presets
+// a user saved; FactoryDir is the system-wide location many third-party AUs
+// install extra factory .aupreset files to, alongside (or instead of)
+// embedding them in the component bundle.
+type PresetDirectory struct {
+	Manufacturer string
+	Name         string
+	UserDir      string
+	FactoryDir   string
+}
+
+// presetDirsFor returns the conventional per-plugin preset search paths,
+// matching the layout Logic/GarageBand/Ardour use:
+// ~/Library/Audio/Presets/<Manufacturer>/<Name> for user presets and
+// /Library/Audio/Presets/<Manufacturer>/<Name> for factory-installed ones.
+// The roots can be overridden with MACAUDIO_USER_PRESET_DIR and
+// MACAUDIO_FACTORY_PRESET_DIR (tests use this rather than touching the real
+// /Library/Audio/Presets, which a normal user can't write to).
+func presetDirsFor(manufacturer, name string) (userDir, factoryDir string, err error) {
+	userRoot := os.Getenv("MACAUDIO_USER_PRESET_DIR")
+	if userRoot == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve home directory for preset search path: %w", err)
+		}
+		userRoot = filepath.Join(home, "Library", "Audio", "Presets")
+	}
+	factoryRoot := os.Getenv("MACAUDIO_FACTORY_PRESET_DIR")
+	if factoryRoot == "" {
+		factoryRoot = filepath.Join("/Library", "Audio", "Presets")
+	}
+	return filepath.Join(userRoot, manufacturer, name), filepath.Join(factoryRoot, manufacturer, name), nil
+}
+
+// PresetDirectories returns every plugin in the chain's conventional preset
+// search paths, in chain order, so a GUI can list or browse either location.
+// A plugin slot with no loaded Plugin is skipped.
+func (pc *PluginChain) PresetDirectories() ([]PresetDirectory, error) {
+	dirs := make([]PresetDirectory, 0, len(pc.Plugins))
+	for i := range pc.Plugins {
+		plugin := pc.Plugins[i].Plugin
+		if plugin == nil {
+			continue
+		}
+		userDir, factoryDir, err := presetDirsFor(plugin.ManufacturerID, plugin.Name)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, PresetDirectory{
+			Manufacturer: plugin.ManufacturerID,
+			Name:         plugin.Name,
+			UserDir:      userDir,
+			FactoryDir:   factoryDir,
+		})
+	}
+	return dirs, nil
+}
+
+// FactoryPresets lists the factory presets installed alongside the plugin's
+// component bundle, under its conventional factory preset directory (see
+// PresetDirectories), parsed the same way SavePreset/LoadPreset read a user
+// preset. A plugin with no factory-installed .aupreset files returns an
+// empty slice, not an error.
+func (p *EnginePlugin) FactoryPresets() ([]Preset, error) {
+	if p.Plugin == nil {
+		return nil, errors.New("plugin not initialized")
+	}
+	_, factoryDir, err := presetDirsFor(p.Plugin.ManufacturerID, p.Plugin.Name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(factoryDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list factory presets: %w", err)
+	}
+
+	var presets []Preset
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".aupreset" {
+			continue
+		}
+		preset, err := readAUPreset(filepath.Join(factoryDir, entry.Name()))
+		if err != nil {
+			// Skip a preset file this host can't parse rather than failing
+			// the whole listing over one bad file.
+			continue
+		}
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+// LoadFactoryPreset applies the factory preset at index (as returned by
+// FactoryPresets) to the plugin, refreshing every matched
+// Parameter.CurrentValue the same way ApplyPreset does for a user preset.
+func (p *EnginePlugin) LoadFactoryPreset(index int) error {
+	presets, err := p.FactoryPresets()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(presets) {
+		return fmt.Errorf("invalid factory preset index %d (have %d)", index, len(presets))
+	}
+	return p.ApplyPreset(presets[index])
+}
+
+// =============================================================================
+// .aupreset plist encoding
+//
+// Apple's AU hosts persist kAudioUnitProperty_ClassInfo as an XML property
+// list dictionary. We encode the subset of keys a host actually inspects
+// (type/subtype/manufacturer/name/version) plus a "data" dictionary mapping
+// each parameter address to its current value as a string-keyed <dict>,
+// since CFPropertyList dictionaries require string keys.
+// =============================================================================
+
+type plistDict struct {
+	XMLName xml.Name `xml:"dict"`
+	Entries []plistEntry
+}
+
+type plistEntry struct {
+	Key   string
+	Value interface{}
+}
+
+func presetToDict(p Preset) plistDict {
+	keys := make([]uint64, 0, len(p.Data))
+	for k := range p.Data {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	dataEntries := make([]plistEntry, 0, len(keys))
+	for _, k := range keys {
+		dataEntries = append(dataEntries, plistEntry{Key: strconv.FormatUint(k, 10), Value: p.Data[k]})
+	}
+
+	return plistDict{Entries: []plistEntry{
+		{Key: "type", Value: p.Type},
+		{Key: "subtype", Value: p.Subtype},
+		{Key: "manufacturer", Value: p.Manufacturer},
+		{Key: "name", Value: p.Name},
+		{Key: "version", Value: p.Version},
+		{Key: "data", Value: plistDict{Entries: dataEntries}},
+	}}
+}
+
+func dictToPreset(d plistDict) (Preset, error) {
+	p := Preset{Data: make(map[uint64]float32)}
+	for _, e := range d.Entries {
+		switch e.Key {
+		case "type":
+			p.Type = e.Value.(string)
+		case "subtype":
+			p.Subtype = e.Value.(string)
+		case "manufacturer":
+			p.Manufacturer = e.Value.(string)
+		case "name":
+			p.Name = e.Value.(string)
+		case "version":
+			switch v := e.Value.(type) {
+			case int:
+				p.Version = v
+			case string:
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return p, fmt.Errorf("invalid version: %w", err)
+				}
+				p.Version = n
+			default:
+				return p, errors.New("invalid version type")
+			}
+		case "data":
+			inner, ok := e.Value.(plistDict)
+			if !ok {
+				return p, errors.New("data is not a dictionary")
+			}
+			for _, de := range inner.Entries {
+				addr, err := strconv.ParseUint(de.Key, 10, 64)
+				if err != nil {
+					return p, fmt.Errorf("invalid parameter address %q: %w", de.Key, err)
+				}
+				val, err := strconv.ParseFloat(de.Value.(string), 32)
+				if err != nil {
+					return p, fmt.Errorf("invalid parameter value for %q: %w", de.Key, err)
+				}
+				p.Data[addr] = float32(val)
+			}
+		}
+	}
+	return p, nil
+}
+
+func writePlistXML(buf *bytes.Buffer, d plistDict) {
+	buf.WriteString("<dict>\n")
+	for _, e := range d.Entries {
+		fmt.Fprintf(buf, "\t<key>%s</key>\n", xmlEscape(e.Key))
+		switch v := e.Value.(type) {
+		case string:
+			fmt.Fprintf(buf, "\t<string>%s</string>\n", xmlEscape(v))
+		case int:
+			fmt.Fprintf(buf, "\t<integer>%d</integer>\n", v)
+		case float32:
+			fmt.Fprintf(buf, "\t<real>%s</real>\n", strconv.FormatFloat(float64(v), 'g', -1, 32))
+		case plistDict:
+			writePlistXML(buf, v)
+		}
+	}
+	buf.WriteString("</dict>\n")
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func plistHeader() string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+}
+
+func writeAUPreset(path string, p Preset) error {
+	var buf bytes.Buffer
+	buf.WriteString(plistHeader())
+	writePlistXML(&buf, presetToDict(p))
+	buf.WriteString("</plist>\n")
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func writeAUBank(path string, b Bank) error {
+	var buf bytes.Buffer
+	buf.WriteString(plistHeader())
+	buf.WriteString("<array>\n")
+	for _, p := range b.Presets {
+		writePlistXML(&buf, presetToDict(p))
+	}
+	buf.WriteString("</array>\n</plist>\n")
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func readAUPreset(path string) (Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Preset{}, err
+	}
+	d, err := parsePlistDict(data)
+	if err != nil {
+		return Preset{}, err
+	}
+	return dictToPreset(d)
+}
+
+func readAUBank(path string) (Bank, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Bank{}, err
+	}
+	dicts, err := parsePlistDictArray(data)
+	if err != nil {
+		return Bank{}, err
+	}
+	bank := Bank{Presets: make([]Preset, 0, len(dicts))}
+	for _, d := range dicts {
+		p, err := dictToPreset(d)
+		if err != nil {
+			return Bank{}, err
+		}
+		bank.Presets = append(bank.Presets, p)
+	}
+	return bank, nil
+}
+
+// =============================================================================
+// .aupreset plist decoding
+//
+// A hand-rolled reader for the small subset of XML property list syntax we
+// emit above (dict/array/key/string/integer/real). We avoid a full plist
+// library dependency since the engine package otherwise only depends on the
+// standard library and the sibling macaudio packages.
+// =============================================================================
+
+func parsePlistDict(data []byte) (plistDict, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return plistDict{}, fmt.Errorf("no <dict> found in plist: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "dict" {
+			return decodePlistDict(dec)
+		}
+	}
+}
+
+func parsePlistDictArray(data []byte) ([]plistDict, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("no <array> found in plist: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "array" {
+			var dicts []plistDict
+			for {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, fmt.Errorf("unterminated <array>: %w", err)
+				}
+				switch t := tok.(type) {
+				case xml.StartElement:
+					if t.Name.Local == "dict" {
+						d, err := decodePlistDict(dec)
+						if err != nil {
+							return nil, err
+						}
+						dicts = append(dicts, d)
+					}
+				case xml.EndElement:
+					if t.Name.Local == "array" {
+						return dicts, nil
+					}
+				}
+			}
+		}
+	}
+}
+
+// decodePlistDict consumes key/value pairs until the matching </dict>,
+// assuming the opening <dict> start element has already been read.
+func decodePlistDict(dec *xml.Decoder) (plistDict, error) {
+	var d plistDict
+	var pendingKey string
+	haveKey := false
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return d, fmt.Errorf("unterminated <dict>: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "key":
+				text, err := readPlistText(dec, "key")
+				if err != nil {
+					return d, err
+				}
+				pendingKey = text
+				haveKey = true
+			case "string":
+				text, err := readPlistText(dec, "string")
+				if err != nil {
+					return d, err
+				}
+				d.Entries = append(d.Entries, plistEntry{Key: pendingKey, Value: text})
+				haveKey = false
+			case "integer":
+				text, err := readPlistText(dec, "integer")
+				if err != nil {
+					return d, err
+				}
+				n, err := strconv.Atoi(text)
+				if err != nil {
+					return d, fmt.Errorf("invalid <integer>: %w", err)
+				}
+				d.Entries = append(d.Entries, plistEntry{Key: pendingKey, Value: n})
+				haveKey = false
+			case "real":
+				text, err := readPlistText(dec, "real")
+				if err != nil {
+					return d, err
+				}
+				// Kept as a string entry; dictToPreset re-parses data values
+				// with ParseFloat regardless of XML element type.
+				d.Entries = append(d.Entries, plistEntry{Key: pendingKey, Value: text})
+				haveKey = false
+			case "dict":
+				inner, err := decodePlistDict(dec)
+				if err != nil {
+					return d, err
+				}
+				d.Entries = append(d.Entries, plistEntry{Key: pendingKey, Value: inner})
+				haveKey = false
+			}
+		case xml.EndElement:
+			if t.Name.Local == "dict" {
+				if haveKey {
+					return d, errors.New("dangling <key> with no value")
+				}
+				return d, nil
+			}
+		}
+	}
+}
+
+func readPlistText(dec *xml.Decoder, elem string) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", fmt.Errorf("unterminated <%s>: %w", elem, err)
+	}
+	switch t := tok.(type) {
+	case xml.CharData:
+		text := string(t)
+		end, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if e, ok := end.(xml.EndElement); !ok || e.Name.Local != elem {
+			return "", fmt.Errorf("expected </%s>", elem)
+		}
+		return text, nil
+	case xml.EndElement:
+		// Empty element, e.g. <string></string>
+		if t.Name.Local == elem {
+			return "", nil
+		}
+		return "", fmt.Errorf("expected </%s>", elem)
+	default:
+		return "", fmt.Errorf("unexpected token in <%s>", elem)
+	}
+}
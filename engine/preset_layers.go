@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// ChainPreset bundles one plugins.Preset per installed plugin in a
+// PluginChain under a single name. It's distinct from this file's sibling
+// Preset/Bank (preset.go): those are address-keyed .aupreset plists for a
+// single exact plugin match, while ChainPreset and the layered bank below
+// are identifier-keyed JSON, built for the "global -> type -> specific"
+// override cascade a plugin preset manager like Snap's needs - see
+// PluginChain.LoadPresetBank.
+type ChainPreset struct {
+	Name    string           `json:"name"`
+	Presets []plugins.Preset `json:"presets"`
+}
+
+// SavePreset captures every installed plugin's current parameter values
+// into a ChainPreset, skipping slots whose plugin failed to load
+// (EnginePlugin.Plugin == nil).
+func (pc *PluginChain) SavePreset(name string) (*ChainPreset, error) {
+	cp := &ChainPreset{Name: name}
+	for _, ep := range pc.Plugins {
+		if ep.Plugin == nil {
+			continue
+		}
+		preset, err := ep.Plugin.SavePreset(name)
+		if err != nil {
+			return nil, err
+		}
+		cp.Presets = append(cp.Presets, *preset)
+	}
+	return cp, nil
+}
+
+// ApplyPreset restores cp onto the chain's plugins, matching each saved
+// preset back to a chain slot by triplet (Type/Subtype/ManufacturerID)
+// rather than by index, since plugins may have been reordered or
+// added/removed since cp was saved. A saved preset with no matching
+// installed plugin in the chain is skipped.
+func (pc *PluginChain) ApplyPreset(cp *ChainPreset) error {
+	for _, preset := range cp.Presets {
+		preset := preset
+		for i := range pc.Plugins {
+			ep := &pc.Plugins[i]
+			if ep.Plugin == nil {
+				continue
+			}
+			if ep.Plugin.Type == preset.Type && ep.Plugin.Subtype == preset.Subtype && ep.Plugin.ManufacturerID == preset.ManufacturerID {
+				if err := ep.Plugin.ApplyPreset(&preset); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// LoadPresetBank walks dir for layered plugins.Preset overrides and applies
+// them to every installed plugin in the chain, in increasing order of
+// specificity:
+//
+//   - global.json applies to every plugin first
+//   - type-<aufx|aumu|aumf>.json overrides it for plugins of that type
+//   - plugin-<type>-<subtype>-<manufacturerID>.json overrides that for one
+//     specific plugin triplet
+//
+// mirroring a global -> type -> specific config cascade. A missing layer is
+// simply not applied; an unrecognized or malformed filename is an error,
+// since a typo there would otherwise silently drop an override.
+func (pc *PluginChain) LoadPresetBank(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read preset bank directory: %w", err)
+	}
+
+	var global *plugins.Preset
+	byType := make(map[string]*plugins.Preset)
+	byTriplet := make(map[plugins.PluginTriplet]*plugins.Preset)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		preset, err := plugins.LoadPreset(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		switch {
+		case name == "global":
+			global = preset
+		case strings.HasPrefix(name, "type-"):
+			byType[strings.TrimPrefix(name, "type-")] = preset
+		case strings.HasPrefix(name, "plugin-"):
+			parts := strings.SplitN(strings.TrimPrefix(name, "plugin-"), "-", 3)
+			if len(parts) != 3 {
+				return fmt.Errorf("malformed plugin preset filename %q (want plugin-<type>-<subtype>-<manufacturerID>.json)", entry.Name())
+			}
+			byTriplet[plugins.PluginTriplet{Type: parts[0], Subtype: parts[1], ManufacturerID: parts[2]}] = preset
+		default:
+			return fmt.Errorf("unrecognized preset bank filename %q", entry.Name())
+		}
+	}
+
+	for i := range pc.Plugins {
+		ep := &pc.Plugins[i]
+		if ep.Plugin == nil {
+			continue
+		}
+		if global != nil {
+			if err := ep.Plugin.ApplyPreset(global); err != nil {
+				return err
+			}
+		}
+		if typePreset, ok := byType[ep.Plugin.Type]; ok {
+			if err := ep.Plugin.ApplyPreset(typePreset); err != nil {
+				return err
+			}
+		}
+		triplet := plugins.PluginTriplet{Type: ep.Plugin.Type, Subtype: ep.Plugin.Subtype, ManufacturerID: ep.Plugin.ManufacturerID}
+		if tripletPreset, ok := byTriplet[triplet]; ok {
+			if err := ep.Plugin.ApplyPreset(tripletPreset); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func findWritablePlugin(t *testing.T) *plugins.Plugin {
+	t.Helper()
+	pluginInfos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+	for _, info := range pluginInfos {
+		plugin, err := info.Introspect()
+		if err != nil {
+			continue
+		}
+		if len(plugin.GetWritableParameters()) > 0 {
+			return plugin
+		}
+	}
+	return nil
+}
+
+func TestChainPresetRoundTrip(t *testing.T) {
+	testPlugin := findWritablePlugin(t)
+	if testPlugin == nil {
+		t.Skip("No plugins with writable parameters found")
+	}
+
+	chain := NewPluginChain()
+	enginePlugin := NewEnginePlugin(testPlugin)
+	if err := chain.AddPlugin(*enginePlugin); err != nil {
+		t.Fatalf("Failed to add plugin: %v", err)
+	}
+
+	param := testPlugin.GetWritableParameters()[0]
+	newValue := param.MinValue + (param.MaxValue-param.MinValue)*0.7
+	if err := chain.SetPluginParameter(0, param.Identifier, newValue); err != nil {
+		t.Fatalf("Failed to set parameter: %v", err)
+	}
+
+	cp, err := chain.SavePreset("test-preset")
+	if err != nil {
+		t.Fatalf("SavePreset failed: %v", err)
+	}
+	if len(cp.Presets) != 1 {
+		t.Fatalf("expected 1 saved preset, got %d", len(cp.Presets))
+	}
+
+	// Drift the value, then restore via ApplyPreset.
+	driftedValue := param.MinValue + (param.MaxValue-param.MinValue)*0.1
+	if err := chain.SetPluginParameter(0, param.Identifier, driftedValue); err != nil {
+		t.Fatalf("Failed to drift parameter: %v", err)
+	}
+
+	if err := chain.ApplyPreset(cp); err != nil {
+		t.Fatalf("ApplyPreset failed: %v", err)
+	}
+
+	restored, err := chain.GetPluginParameter(0, param.Identifier)
+	if err != nil {
+		t.Fatalf("Failed to get parameter: %v", err)
+	}
+	if restored != newValue {
+		t.Errorf("ApplyPreset mismatch: want %f, got %f", newValue, restored)
+	}
+}
+
+func TestLoadPresetBankCascade(t *testing.T) {
+	testPlugin := findWritablePlugin(t)
+	if testPlugin == nil {
+		t.Skip("No plugins with writable parameters found")
+	}
+	writable := testPlugin.GetWritableParameters()
+	if len(writable) < 2 {
+		t.Skip("Need at least 2 writable parameters to test the global/type/specific cascade")
+	}
+
+	chain := NewPluginChain()
+	enginePlugin := NewEnginePlugin(testPlugin)
+	if err := chain.AddPlugin(*enginePlugin); err != nil {
+		t.Fatalf("Failed to add plugin: %v", err)
+	}
+
+	globalParam, typeParam := writable[0], writable[1]
+	globalValue := globalParam.MinValue + (globalParam.MaxValue-globalParam.MinValue)*0.9
+	typeValue := typeParam.MinValue + (typeParam.MaxValue-typeParam.MinValue)*0.9
+
+	dir := t.TempDir()
+
+	global := &plugins.Preset{
+		Name:   "global",
+		Values: map[string]float64{globalParam.Identifier: float64(globalValue)},
+	}
+	if err := global.Save(filepath.Join(dir, "global.json")); err != nil {
+		t.Fatalf("failed to write global preset: %v", err)
+	}
+
+	typePreset := &plugins.Preset{
+		Name:   "type",
+		Values: map[string]float64{typeParam.Identifier: float64(typeValue)},
+	}
+	if err := typePreset.Save(filepath.Join(dir, "type-"+testPlugin.Type+".json")); err != nil {
+		t.Fatalf("failed to write type preset: %v", err)
+	}
+
+	if err := chain.LoadPresetBank(dir); err != nil {
+		t.Fatalf("LoadPresetBank failed: %v", err)
+	}
+
+	gotGlobal, err := chain.GetPluginParameter(0, globalParam.Identifier)
+	if err != nil {
+		t.Fatalf("failed to read global-layered parameter: %v", err)
+	}
+	if gotGlobal != globalValue {
+		t.Errorf("global layer mismatch: want %f, got %f", globalValue, gotGlobal)
+	}
+
+	gotType, err := chain.GetPluginParameter(0, typeParam.Identifier)
+	if err != nil {
+		t.Fatalf("failed to read type-layered parameter: %v", err)
+	}
+	if gotType != typeValue {
+		t.Errorf("type layer mismatch: want %f, got %f", typeValue, gotType)
+	}
+}
+
+func TestLoadPresetBankRejectsMalformedFilename(t *testing.T) {
+	chain := NewPluginChain()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "not-a-recognized-layer.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := chain.LoadPresetBank(dir); err == nil {
+		t.Error("expected LoadPresetBank to reject an unrecognized filename")
+	}
+}
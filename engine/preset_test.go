@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func newTestEnginePlugin() *EnginePlugin {
+	return &EnginePlugin{
+		IsInstalled: true,
+		Plugin: &plugins.Plugin{
+			Name:           "Test Delay",
+			ManufacturerID: "appl",
+			Type:           "aufx",
+			Subtype:        "dely",
+			Parameters: []plugins.Parameter{
+				{Identifier: "time", Address: 0, CurrentValue: 0.5},
+				{Identifier: "feedback", Address: 1, CurrentValue: 0.25},
+			},
+		},
+	}
+}
+
+func TestEnginePluginPresetRoundtrip(t *testing.T) {
+	plugin := newTestEnginePlugin()
+	path := filepath.Join(t.TempDir(), "delay.aupreset")
+
+	if err := plugin.SavePreset(path); err != nil {
+		t.Fatalf("SavePreset failed: %v", err)
+	}
+
+	// Mutate in-memory values so LoadPreset has something to restore.
+	plugin.Plugin.Parameters[0].CurrentValue = 0
+	plugin.Plugin.Parameters[1].CurrentValue = 0
+
+	if err := plugin.LoadPreset(path); err != nil {
+		t.Fatalf("LoadPreset failed: %v", err)
+	}
+
+	if plugin.Plugin.Parameters[0].CurrentValue != 0.5 {
+		t.Errorf("expected time=0.5, got %v", plugin.Plugin.Parameters[0].CurrentValue)
+	}
+	if plugin.Plugin.Parameters[1].CurrentValue != 0.25 {
+		t.Errorf("expected feedback=0.25, got %v", plugin.Plugin.Parameters[1].CurrentValue)
+	}
+}
+
+func TestEnginePluginLoadPresetMismatch(t *testing.T) {
+	plugin := newTestEnginePlugin()
+	path := filepath.Join(t.TempDir(), "delay.aupreset")
+	if err := plugin.SavePreset(path); err != nil {
+		t.Fatalf("SavePreset failed: %v", err)
+	}
+
+	other := newTestEnginePlugin()
+	other.Plugin.Name = "Other Delay"
+	if err := other.LoadPreset(path); err == nil {
+		t.Error("expected error loading preset for mismatched plugin identity")
+	}
+}
+
+func TestPluginChainSaveLoadBank(t *testing.T) {
+	chain := NewPluginChain()
+	chain.Plugins = append(chain.Plugins, *newTestEnginePlugin())
+
+	path := filepath.Join(t.TempDir(), "bank.aupreset")
+	if err := chain.SaveBank(path); err != nil {
+		t.Fatalf("SaveBank failed: %v", err)
+	}
+
+	chain.Plugins[0].Plugin.Parameters[0].CurrentValue = 0
+	if err := chain.LoadBank(path); err != nil {
+		t.Fatalf("LoadBank failed: %v", err)
+	}
+	if chain.Plugins[0].Plugin.Parameters[0].CurrentValue != 0.5 {
+		t.Errorf("expected time=0.5 after LoadBank, got %v", chain.Plugins[0].Plugin.Parameters[0].CurrentValue)
+	}
+}
+
+func TestPluginChainPresetDirectories(t *testing.T) {
+	os.Setenv("MACAUDIO_USER_PRESET_DIR", "/tmp/user-presets")
+	defer os.Unsetenv("MACAUDIO_USER_PRESET_DIR")
+	os.Setenv("MACAUDIO_FACTORY_PRESET_DIR", "/tmp/factory-presets")
+	defer os.Unsetenv("MACAUDIO_FACTORY_PRESET_DIR")
+
+	chain := NewPluginChain()
+	chain.Plugins = append(chain.Plugins, *newTestEnginePlugin(), EnginePlugin{IsInstalled: false})
+
+	dirs, err := chain.PresetDirectories()
+	if err != nil {
+		t.Fatalf("PresetDirectories failed: %v", err)
+	}
+	// The uninstalled second slot (nil Plugin) should be skipped.
+	if len(dirs) != 1 {
+		t.Fatalf("expected 1 preset directory entry, got %d", len(dirs))
+	}
+	want := PresetDirectory{
+		Manufacturer: "appl",
+		Name:         "Test Delay",
+		UserDir:      filepath.Join("/tmp/user-presets", "appl", "Test Delay"),
+		FactoryDir:   filepath.Join("/tmp/factory-presets", "appl", "Test Delay"),
+	}
+	if dirs[0] != want {
+		t.Errorf("expected %+v, got %+v", want, dirs[0])
+	}
+}
+
+func TestEnginePluginFactoryPresets(t *testing.T) {
+	factoryRoot := t.TempDir()
+	os.Setenv("MACAUDIO_FACTORY_PRESET_DIR", factoryRoot)
+	defer os.Unsetenv("MACAUDIO_FACTORY_PRESET_DIR")
+
+	plugin := newTestEnginePlugin()
+
+	presets, err := plugin.FactoryPresets()
+	if err != nil {
+		t.Fatalf("FactoryPresets on an empty directory failed: %v", err)
+	}
+	if len(presets) != 0 {
+		t.Fatalf("expected no factory presets before any are installed, got %d", len(presets))
+	}
+
+	pluginDir := filepath.Join(factoryRoot, plugin.Plugin.ManufacturerID, plugin.Plugin.Name)
+	if err := os.MkdirAll(pluginDir, 0o755); err != nil {
+		t.Fatalf("failed to create factory preset dir: %v", err)
+	}
+	factory := *plugin
+	factory.Plugin = &plugins.Plugin{
+		Name: plugin.Plugin.Name, ManufacturerID: plugin.Plugin.ManufacturerID,
+		Type: plugin.Plugin.Type, Subtype: plugin.Plugin.Subtype,
+		Parameters: []plugins.Parameter{
+			{Identifier: "time", Address: 0, CurrentValue: 0.9},
+			{Identifier: "feedback", Address: 1, CurrentValue: 0.1},
+		},
+	}
+	if err := factory.SavePreset(filepath.Join(pluginDir, "Slapback.aupreset")); err != nil {
+		t.Fatalf("failed to seed a factory preset: %v", err)
+	}
+
+	presets, err = plugin.FactoryPresets()
+	if err != nil {
+		t.Fatalf("FactoryPresets failed: %v", err)
+	}
+	if len(presets) != 1 {
+		t.Fatalf("expected 1 factory preset, got %d", len(presets))
+	}
+
+	if err := plugin.LoadFactoryPreset(0); err != nil {
+		t.Fatalf("LoadFactoryPreset failed: %v", err)
+	}
+	if plugin.Plugin.Parameters[0].CurrentValue != 0.9 {
+		t.Errorf("expected time=0.9 after LoadFactoryPreset, got %v", plugin.Plugin.Parameters[0].CurrentValue)
+	}
+
+	if err := plugin.LoadFactoryPreset(5); err == nil {
+		t.Error("expected an out-of-range factory preset index to error")
+	}
+}
@@ -2,6 +2,8 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"unsafe"
 
 	aveng "github.com/shaban/macaudio/avaudio/engine"
@@ -90,3 +92,172 @@ func (d *Dispatcher) DisconnectNodeInput(nodePtr unsafe.Pointer, inputBus int) e
 		return d.Eng.DisconnectNodeInput(nodePtr, inputBus)
 	}))
 }
+
+// txOp is one recorded Txn step: apply performs the mutation, inverse
+// undoes it. Both run synchronously on Commit's queued worker and are
+// expected to be quick, matching Op's own contract.
+type txOp struct {
+	apply   func() error
+	inverse func() error
+}
+
+// Txn batches graph mutations so Commit applies them as one atomic step:
+// either every recorded op succeeds, or whatever already applied is rolled
+// back by replaying inverses in reverse order, restoring the graph to how
+// Commit found it. This is what Connect/DisconnectNodeInput's individual
+// fire-and-forget Enqueue calls can't give a caller doing a multi-edge
+// reroute - a failure on the third edge of five otherwise leaves the first
+// two applied with no way back.
+//
+// Use it like:
+//
+//	txn := d.Begin()
+//	txn.DisconnectNodeInput(mixer, busIndex)
+//	txn.Connect(newSource, mixer, 0, busIndex)
+//	if err := txn.Commit(); err != nil { ... }
+type Txn struct {
+	d       *Dispatcher
+	ops     []txOp
+	applied int // number of ops a successful Commit left applied; 0 once rolled back
+}
+
+// Begin starts a Txn against d. Nothing is applied until Commit is called.
+func (d *Dispatcher) Begin() *Txn {
+	return &Txn{d: d}
+}
+
+// addOp records op as the next step of txn, in the order Commit should
+// apply (and, on rollback, un-apply in reverse) the transaction's steps.
+func (txn *Txn) addOp(op txOp) *Txn {
+	txn.ops = append(txn.ops, op)
+	return txn
+}
+
+// Attach records an Attach(nodePtr) for Commit to apply, with Detach as its
+// rollback inverse.
+func (txn *Txn) Attach(nodePtr unsafe.Pointer) *Txn {
+	return txn.addOp(txOp{
+		apply: func() error {
+			if txn.d.Eng == nil {
+				return nil
+			}
+			return txn.d.Eng.Attach(nodePtr)
+		},
+		inverse: func() error {
+			if txn.d.Eng == nil {
+				return nil
+			}
+			return txn.d.Eng.Detach(nodePtr)
+		},
+	})
+}
+
+// Connect records a Connect(src, dst, fromBus, toBus) for Commit to apply,
+// with DisconnectNodeInput(dst, toBus) as its rollback inverse. Note this
+// inverse clears dst's toBus outright rather than restoring whatever fed it
+// before the Txn - if that matters, record the prior edge's own
+// DisconnectNodeInput/Connect pair ahead of this one so rollback replays
+// both.
+func (txn *Txn) Connect(src, dst unsafe.Pointer, fromBus, toBus int) *Txn {
+	return txn.addOp(txOp{
+		apply: func() error {
+			if txn.d.Eng == nil {
+				return nil
+			}
+			return txn.d.Eng.Connect(src, dst, fromBus, toBus)
+		},
+		inverse: func() error {
+			if txn.d.Eng == nil {
+				return nil
+			}
+			return txn.d.Eng.DisconnectNodeInput(dst, toBus)
+		},
+	})
+}
+
+// DisconnectNodeInput records a DisconnectNodeInput(nodePtr, inputBus) for
+// Commit to apply. It captures whatever is currently connected to
+// nodePtr's inputBus right now (via Engine.ConnectedSource), before Commit
+// has run anything, so rollback reconnects exactly what this Txn found
+// there - not whatever another op earlier in the same Txn rewired it to.
+// If nothing was connected, rollback is a no-op.
+func (txn *Txn) DisconnectNodeInput(nodePtr unsafe.Pointer, inputBus int) *Txn {
+	var restoreSrc unsafe.Pointer
+	var restoreFromBus int
+	var hadConnection bool
+	if txn.d != nil && txn.d.Eng != nil {
+		restoreSrc, restoreFromBus, hadConnection = txn.d.Eng.ConnectedSource(nodePtr, inputBus)
+	}
+	return txn.addOp(txOp{
+		apply: func() error {
+			if txn.d.Eng == nil {
+				return nil
+			}
+			return txn.d.Eng.DisconnectNodeInput(nodePtr, inputBus)
+		},
+		inverse: func() error {
+			if txn.d.Eng == nil || !hadConnection {
+				return nil
+			}
+			return txn.d.Eng.Connect(restoreSrc, nodePtr, restoreFromBus, inputBus)
+		},
+	})
+}
+
+// Commit runs txn as a single RunSync call on the Dispatcher's queue, so it
+// can't interleave with any other queued mutation, applies its recorded ops
+// in order, and - if one fails partway through - replays the inverses of
+// every op that already succeeded, in reverse order, before returning the
+// failing op's error. A rollback step that itself fails is folded into the
+// returned error rather than silently swallowed, since at that point the
+// graph may be left in neither the pre- nor post-Txn state.
+func (txn *Txn) Commit() error {
+	if txn == nil || txn.d == nil {
+		return errors.New("transaction is nil")
+	}
+	return txn.d.RunSync(func(ctx context.Context) error {
+		applied := 0
+		var failErr error
+		for _, op := range txn.ops {
+			if err := op.apply(); err != nil {
+				failErr = err
+				break
+			}
+			applied++
+		}
+		if failErr == nil {
+			txn.applied = applied
+			return nil
+		}
+		for i := applied - 1; i >= 0; i-- {
+			if err := txn.ops[i].inverse(); err != nil {
+				return fmt.Errorf("%w (rollback also failed: %v)", failErr, err)
+			}
+		}
+		return failErr
+	})
+}
+
+// Rollback manually undoes a Txn that Commit has already applied
+// successfully, replaying inverses in reverse order - for a caller that
+// committed a routing change and only later, after some subsequent
+// non-graph step failed, decided the whole change needs to be undone.
+// It is a no-op if Commit was never called or didn't fully succeed (Commit
+// already rolls those back itself), and safe to call at most once per
+// successful Commit - a second call is a no-op too.
+func (txn *Txn) Rollback() error {
+	if txn == nil || txn.d == nil || txn.applied == 0 {
+		return nil
+	}
+	return txn.d.RunSync(func(ctx context.Context) error {
+		applied := txn.applied
+		txn.applied = 0
+		var failErr error
+		for i := applied - 1; i >= 0; i-- {
+			if err := txn.ops[i].inverse(); err != nil {
+				failErr = err
+			}
+		}
+		return failErr
+	})
+}
@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTxnCommitAppliesOpsInOrder(t *testing.T) {
+	d := NewDispatcher(nil, New(8))
+	d.Start()
+	defer d.Close()
+
+	var order []int
+	txn := d.Begin()
+	for i := 0; i < 3; i++ {
+		i := i
+		txn.addOp(txOp{
+			apply:   func() error { order = append(order, i); return nil },
+			inverse: func() error { t.Fatalf("unexpected rollback of op %d", i); return nil },
+		})
+	}
+
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Fatalf("expected ops applied in order [0 1 2], got %v", order)
+	}
+}
+
+func TestTxnCommitRollsBackOnMidBatchFailure(t *testing.T) {
+	d := NewDispatcher(nil, New(8))
+	d.Start()
+	defer d.Close()
+
+	var applied []int
+	var rolledBack []int
+	wantErr := errors.New("third op failed")
+
+	txn := d.Begin()
+	txn.addOp(txOp{
+		apply:   func() error { applied = append(applied, 0); return nil },
+		inverse: func() error { rolledBack = append(rolledBack, 0); return nil },
+	})
+	txn.addOp(txOp{
+		apply:   func() error { applied = append(applied, 1); return nil },
+		inverse: func() error { rolledBack = append(rolledBack, 1); return nil },
+	})
+	txn.addOp(txOp{
+		apply:   func() error { return wantErr },
+		inverse: func() error { t.Fatal("the failing op's own inverse should never run"); return nil },
+	})
+
+	err := txn.Commit()
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected Commit to return the failing op's error, got %v", err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected exactly the first two ops to have applied, got %v", applied)
+	}
+	if len(rolledBack) != 2 || rolledBack[0] != 1 || rolledBack[1] != 0 {
+		t.Fatalf("expected rollback in reverse order [1 0], got %v", rolledBack)
+	}
+}
+
+func TestTxnCommitFoldsRollbackFailureIntoError(t *testing.T) {
+	d := NewDispatcher(nil, New(8))
+	d.Start()
+	defer d.Close()
+
+	applyErr := errors.New("apply failed")
+	rollbackErr := errors.New("rollback also failed")
+
+	txn := d.Begin()
+	txn.addOp(txOp{
+		apply:   func() error { return nil },
+		inverse: func() error { return rollbackErr },
+	})
+	txn.addOp(txOp{
+		apply:   func() error { return applyErr },
+		inverse: func() error { return nil },
+	})
+
+	err := txn.Commit()
+	if err == nil || !errors.Is(err, applyErr) {
+		t.Fatalf("expected the returned error to wrap the apply failure, got %v", err)
+	}
+}
+
+func TestTxnCommitOnNilTransactionErrors(t *testing.T) {
+	var txn *Txn
+	if err := txn.Commit(); err == nil {
+		t.Error("expected Commit on a nil transaction to return an error")
+	}
+}
+
+// TestTxnCommitSerializesWithRunSyncCallers verifies a Txn.Commit (built on
+// RunSync) and a plain RunSync caller never see each other's work
+// interleaved: both append to the same slice, and since Queue drains one op
+// at a time, the final order must be exactly what each caller enqueued, with
+// no torn/interleaved writes.
+func TestTxnCommitSerializesWithRunSyncCallers(t *testing.T) {
+	d := NewDispatcher(nil, New(8))
+	d.Start()
+	defer d.Close()
+
+	var log []string
+	txnDone := make(chan error, 1)
+	go func() {
+		txn := d.Begin()
+		txn.addOp(txOp{
+			apply:   func() error { log = append(log, "txn-1"); return nil },
+			inverse: func() error { return nil },
+		})
+		txn.addOp(txOp{
+			apply:   func() error { log = append(log, "txn-2"); return nil },
+			inverse: func() error { return nil },
+		})
+		txnDone <- txn.Commit()
+	}()
+
+	if err := d.RunSync(func(ctx context.Context) error {
+		log = append(log, "runsync")
+		return nil
+	}); err != nil {
+		t.Fatalf("RunSync: %v", err)
+	}
+	if err := <-txnDone; err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if len(log) != 3 {
+		t.Fatalf("expected all 3 entries to land without interleaving corruption, got %v", log)
+	}
+	txnIdx := -1
+	for i, entry := range log {
+		if entry == "txn-1" {
+			txnIdx = i
+		}
+	}
+	if txnIdx == -1 || log[txnIdx+1] != "txn-2" {
+		t.Fatalf("expected the Txn's two ops to run back-to-back without another RunSync caller's op between them, got %v", log)
+	}
+}
+
+// TestTxnRollbackUndoesASuccessfulCommit checks that a caller can manually
+// undo a Txn after Commit already succeeded, and that a second Rollback (or
+// one called without a prior successful Commit) is a no-op.
+func TestTxnRollbackUndoesASuccessfulCommit(t *testing.T) {
+	d := NewDispatcher(nil, New(8))
+	d.Start()
+	defer d.Close()
+
+	var rolledBack []int
+
+	txn := d.Begin()
+	txn.addOp(txOp{
+		apply:   func() error { return nil },
+		inverse: func() error { rolledBack = append(rolledBack, 0); return nil },
+	})
+	txn.addOp(txOp{
+		apply:   func() error { return nil },
+		inverse: func() error { rolledBack = append(rolledBack, 1); return nil },
+	})
+
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if len(rolledBack) != 0 {
+		t.Fatalf("expected no rollback yet, got %v", rolledBack)
+	}
+
+	if err := txn.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if len(rolledBack) != 2 || rolledBack[0] != 1 || rolledBack[1] != 0 {
+		t.Fatalf("expected rollback in reverse order [1 0], got %v", rolledBack)
+	}
+
+	// A second Rollback, and Rollback on a Txn that never committed, are no-ops.
+	if err := txn.Rollback(); err != nil {
+		t.Fatalf("second Rollback: %v", err)
+	}
+	if len(rolledBack) != 2 {
+		t.Fatalf("expected second Rollback to be a no-op, got %v", rolledBack)
+	}
+
+	fresh := d.Begin()
+	if err := fresh.Rollback(); err != nil {
+		t.Fatalf("Rollback on uncommitted Txn: %v", err)
+	}
+}
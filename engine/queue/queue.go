@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"container/list"
 	"context"
 	"errors"
 	"sync"
@@ -16,29 +17,94 @@ type Op interface {
 	Apply(ctx context.Context) error
 }
 
+// Keyed is an optional interface an Op may implement so a later Enqueue
+// call with a matching Key() replaces an already-pending op of the same
+// key in place, rather than queueing both - see Options.Coalesce. An Op
+// that doesn't implement Keyed is never coalesced, even when Coalesce is
+// enabled.
+type Keyed interface {
+	Key() string
+}
+
 // Func is a helper to adapt functions into Op.
 type Func func(ctx context.Context) error
 
 func (f Func) Apply(ctx context.Context) error { return f(ctx) }
 
+// Options configures a Queue beyond what New's plain buffer size covers.
+type Options struct {
+	// Buffer sizes the channel used when Coalesce is false. Ignored when
+	// Coalesce is true, since pending ops then live in an internal list
+	// instead of a fixed-size channel. Non-positive defaults to 32, same
+	// as New.
+	Buffer int
+
+	// MinInterval paces the worker to call Apply at most once every
+	// MinInterval, token-bucket style: a burst of ops arriving faster than
+	// this just makes each one wait longer for its turn rather than
+	// dropping any. Zero (the default, and New's behavior) applies ops as
+	// fast as they arrive.
+	MinInterval time.Duration
+
+	// Coalesce switches Enqueue to a doubly-linked-list-backed pending set
+	// keyed by Op.Key() (see Keyed): enqueuing an op whose Key() matches
+	// one already pending replaces it in place instead of appending a
+	// second one, so a flood of redundant updates to the same key (a fader
+	// knob, an OSC-driven volume/pan) only ever applies the latest value.
+	Coalesce bool
+}
+
 // Queue serializes graph mutations onto a single goroutine.
-// It supports optional rate limiting and graceful shutdown.
-// Use Enqueue to push operations and Wait to drain.
+// It supports optional rate limiting and coalescing, and graceful shutdown.
+// Use Enqueue to push operations and Close to drain.
 type Queue struct {
 	ch      chan Op
 	wg      sync.WaitGroup
 	ctx     context.Context
 	cancel  context.CancelFunc
 	started bool
+
+	minInterval time.Duration
+
+	// Coalescing pending set, used instead of ch when coalesce is true.
+	// pending holds Ops in arrival order; byKey maps a Keyed Op's Key() to
+	// its element in pending, so a repeat key can be replaced in place
+	// (same position, new value) instead of appended.
+	coalesce bool
+	mu       sync.Mutex
+	pending  *list.List
+	byKey    map[string]*list.Element
+	notify   chan struct{} // buffered 1; wakes the worker when pending gains an entry
 }
 
-// New creates a queue with a fixed buffer.
+// New creates a queue with a fixed buffer and no rate limiting or
+// coalescing - equivalent to NewWithOptions(Options{Buffer: buffer}).
 func New(buffer int) *Queue {
-	if buffer <= 0 {
-		buffer = 32
-	}
+	return NewWithOptions(Options{Buffer: buffer})
+}
+
+// NewWithOptions creates a queue configured by opts. See Options for what
+// each field controls.
+func NewWithOptions(opts Options) *Queue {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Queue{ch: make(chan Op, buffer), ctx: ctx, cancel: cancel}
+	q := &Queue{
+		ctx:         ctx,
+		cancel:      cancel,
+		minInterval: opts.MinInterval,
+		coalesce:    opts.Coalesce,
+	}
+	if opts.Coalesce {
+		q.pending = list.New()
+		q.byKey = make(map[string]*list.Element)
+		q.notify = make(chan struct{}, 1)
+	} else {
+		buffer := opts.Buffer
+		if buffer <= 0 {
+			buffer = 32
+		}
+		q.ch = make(chan Op, buffer)
+	}
+	return q
 }
 
 // Start begins the worker goroutine. Safe to call multiple times.
@@ -48,36 +114,131 @@ func (q *Queue) Start() {
 	}
 	q.started = true
 	q.wg.Add(1)
-	go func() {
-		defer q.wg.Done()
-		for {
-			select {
-			case <-q.ctx.Done():
-				// drain outstanding ops best-effort with short deadline
-				drainUntil := time.After(10 * time.Millisecond)
-				for {
-					select {
-					case op := <-q.ch:
-						_ = op.Apply(q.ctx)
-					case <-drainUntil:
-						return
-					default:
-						return
-					}
+	if q.coalesce {
+		go q.runCoalesced()
+	} else {
+		go q.run()
+	}
+}
+
+// run is the non-coalescing worker: it drains ch, pacing Apply calls by
+// minInterval (if set) between each one.
+func (q *Queue) run() {
+	defer q.wg.Done()
+	var last time.Time
+	for {
+		select {
+		case <-q.ctx.Done():
+			// drain outstanding ops best-effort with short deadline
+			drainUntil := time.After(10 * time.Millisecond)
+			for {
+				select {
+				case op := <-q.ch:
+					_ = op.Apply(q.ctx)
+				case <-drainUntil:
+					return
+				default:
+					return
 				}
-			case op := <-q.ch:
-				if op == nil {
-					continue
+			}
+		case op := <-q.ch:
+			if op == nil {
+				continue
+			}
+			q.pace(&last)
+			_ = op.Apply(q.ctx)
+			last = time.Now()
+		}
+	}
+}
+
+// runCoalesced is the coalescing worker: it pops the oldest pending op
+// (removing its key binding, if any) and applies it, pacing by
+// minInterval, then waits on notify for the next arrival once pending runs
+// dry.
+func (q *Queue) runCoalesced() {
+	defer q.wg.Done()
+	var last time.Time
+	for {
+		if op, ok := q.popNext(); ok {
+			q.pace(&last)
+			_ = op.Apply(q.ctx)
+			last = time.Now()
+			continue
+		}
+
+		select {
+		case <-q.ctx.Done():
+			// drain outstanding ops best-effort; popNext never blocks, so
+			// there's no need for run's short deadline here.
+			for {
+				op, ok := q.popNext()
+				if !ok {
+					return
 				}
 				_ = op.Apply(q.ctx)
 			}
+		case <-q.notify:
 		}
-	}()
+	}
 }
 
-// Enqueue adds an operation to the queue.
+// pace blocks until minInterval has elapsed since *last, or the queue is
+// shutting down, then lets the caller proceed. A zero minInterval (the
+// default) never blocks.
+func (q *Queue) pace(last *time.Time) {
+	if q.minInterval <= 0 {
+		return
+	}
+	wait := q.minInterval - time.Since(*last)
+	if wait <= 0 {
+		return
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-q.ctx.Done():
+	}
+}
+
+// popNext removes and returns the oldest pending op, clearing its key
+// binding (if it implements Keyed). Returns ok=false if pending is empty.
+func (q *Queue) popNext() (Op, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	front := q.pending.Front()
+	if front == nil {
+		return nil, false
+	}
+	q.pending.Remove(front)
+	op := front.Value.(Op)
+	if keyed, ok := op.(Keyed); ok {
+		delete(q.byKey, keyed.Key())
+	}
+	return op, true
+}
+
+// wakeWorker signals runCoalesced that pending gained an entry, without
+// blocking if it's already been signaled.
+func (q *Queue) wakeWorker() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Enqueue adds an operation to the queue. When the queue was built with
+// Options.Coalesce and op implements Keyed, an already-pending op with the
+// same Key() is replaced in place instead of a second one being appended.
 func (q *Queue) Enqueue(op Op) error {
-	if q == nil || q.ch == nil {
+	if q == nil {
+		return errors.New("queue not initialized")
+	}
+	if q.coalesce {
+		return q.enqueueCoalesced(op)
+	}
+	if q.ch == nil {
 		return errors.New("queue not initialized")
 	}
 	select {
@@ -88,6 +249,34 @@ func (q *Queue) Enqueue(op Op) error {
 	}
 }
 
+func (q *Queue) enqueueCoalesced(op Op) error {
+	select {
+	case <-q.ctx.Done():
+		return errors.New("queue closed")
+	default:
+	}
+
+	q.mu.Lock()
+	if keyed, ok := op.(Keyed); ok {
+		key := keyed.Key()
+		if el, exists := q.byKey[key]; exists {
+			el.Value = op
+			q.mu.Unlock()
+			q.wakeWorker()
+			return nil
+		}
+		el := q.pending.PushBack(op)
+		q.byKey[key] = el
+		q.mu.Unlock()
+		q.wakeWorker()
+		return nil
+	}
+	q.pending.PushBack(op)
+	q.mu.Unlock()
+	q.wakeWorker()
+	return nil
+}
+
 // Close stops the worker and waits for it to finish.
 func (q *Queue) Close() {
 	if q == nil {
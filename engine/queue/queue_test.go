@@ -28,3 +28,62 @@ func TestQueue_Enqueue_And_Close(t *testing.T) {
 		t.Fatalf("want >=10 ops applied, got %d", c)
 	}
 }
+
+// volumeOp is a Keyed Op standing in for an OSC/MIDI-CC-driven
+// volume-change command, all targeting the same channel key.
+type volumeOp struct {
+	key   string
+	value int32
+	apply func(value int32)
+}
+
+func (o volumeOp) Apply(ctx context.Context) error {
+	o.apply(o.value)
+	return nil
+}
+
+func (o volumeOp) Key() string { return o.key }
+
+func TestQueue_CoalesceVolumeChanges(t *testing.T) {
+	q := NewWithOptions(Options{Coalesce: true})
+
+	var applied int32
+	var lastValue int32
+
+	// Enqueue before Start: every earlier value for "ch1/volume" is
+	// coalesced away before the worker ever runs, so this is deterministic
+	// rather than racing the worker's drain against the flood.
+	const n = 10000
+	for i := 1; i <= n; i++ {
+		v := int32(i)
+		if err := q.Enqueue(volumeOp{
+			key:   "ch1/volume",
+			value: v,
+			apply: func(value int32) {
+				atomic.AddInt32(&applied, 1)
+				atomic.StoreInt32(&lastValue, value)
+			},
+		}); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+	}
+
+	q.Start()
+	defer q.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&applied) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for coalesced op to apply")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond) // let a stray extra apply, if any, land
+
+	if got := atomic.LoadInt32(&applied); got != 1 {
+		t.Fatalf("want exactly 1 apply after coalescing %d ops, got %d", n, got)
+	}
+	if got := atomic.LoadInt32(&lastValue); got != n {
+		t.Fatalf("want final value %d, got %d", n, got)
+	}
+}
@@ -0,0 +1,243 @@
+// Package record installs a capture tap on any node - a channel's output
+// mixer, the engine's main mixer, or a plain AVAudioNode pointer - and
+// streams the tapped PCM to disk as WAV, CAF, or HDF5, without blocking the
+// render thread. WAV/CAF capture is a thin wrapper over
+// avaudio/tap.Recorder; HDF5 capture reuses the same dataset primitives the
+// top-level recorder package writes full engine sessions with (see
+// rec_create_file and friends), driven by its own CallbackTap here instead
+// of caller-pushed AppendChunk calls.
+package record
+
+/*
+#include <stdlib.h>
+
+// Declared here; implemented in native/recorder.h/.m (see recorder.Recorder,
+// which already depends on these for whole-session capture).
+void* rec_create_file(const char* path);
+void* rec_create_dataset(void* file, const char* name, int sampleRate, int bitDepth, int channelCount);
+const char* rec_append_chunk(void* dataset, const float* samples, int frameCount, int channelCount);
+const char* rec_close_file(void* file);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// Format selects the on-disk container a Tap writes.
+type Format int
+
+const (
+	FormatWAV Format = iota
+	FormatCAF
+	FormatHDF5
+)
+
+// Options configures NewTap.
+type Options struct {
+	Format      Format
+	Path        string
+	Channels    int
+	SampleRate  int
+	BitDepth    int // HDF5 only; WAV/CAF always capture float32 (see avaudio/tap.Recorder)
+	RingSeconds int // sizes the capture ring in seconds of audio; 0 defaults to 4
+}
+
+// defaultRingSeconds matches the RingSeconds used by the real-audio tests
+// in this package that replace ad-hoc analyze sampling with a short
+// recorded capture.
+const defaultRingSeconds = 4
+
+// Tap captures a node's output to disk for the lifetime between Start and
+// Stop. Format FormatWAV/FormatCAF delegate to avaudio/tap.Recorder;
+// FormatHDF5 installs its own CallbackTap and writes through rec_append_chunk.
+type Tap struct {
+	enginePtr unsafe.Pointer
+	nodePtr   unsafe.Pointer
+	busIndex  int
+	opts      Options
+
+	wav *tap.Recorder // set once Start succeeds, for FormatWAV/FormatCAF
+
+	// HDF5 path
+	source        *tap.CallbackTap
+	file, dataset unsafe.Pointer
+	queue         chan hdf5Chunk
+	stopCh        chan struct{}
+	doneCh        chan struct{}
+	framesWritten uint64
+	overruns      uint64
+}
+
+type hdf5Chunk struct {
+	samples []float32
+	frames  int
+}
+
+// NewTap prepares a Tap capturing busIndex of nodePtr (obtained from e.g.
+// MonoToStereoChannel.GetOutputNode(), a Channel's output mixer, or the
+// engine's main mixer) through enginePtr's render graph. Capture doesn't
+// start until Start is called.
+func NewTap(enginePtr, nodePtr unsafe.Pointer, busIndex int, opts Options) (*Tap, error) {
+	if enginePtr == nil || nodePtr == nil {
+		return nil, errors.New("engine and node pointers cannot be nil")
+	}
+	if opts.Path == "" {
+		return nil, errors.New("path cannot be empty")
+	}
+	if opts.Channels <= 0 {
+		return nil, errors.New("channels must be positive")
+	}
+	if opts.SampleRate <= 0 {
+		return nil, errors.New("sample rate must be positive")
+	}
+	if opts.RingSeconds <= 0 {
+		opts.RingSeconds = defaultRingSeconds
+	}
+	return &Tap{enginePtr: enginePtr, nodePtr: nodePtr, busIndex: busIndex, opts: opts}, nil
+}
+
+// Start begins capture, creating/opening the backing file.
+func (t *Tap) Start() error {
+	ringFrames := t.opts.RingSeconds * t.opts.SampleRate
+	if t.opts.Format != FormatHDF5 {
+		format := tap.RecorderFormatWAV
+		if t.opts.Format == FormatCAF {
+			format = tap.RecorderFormatCAF
+		}
+		rec, err := tap.InstallRecorder(t.enginePtr, t.nodePtr, t.busIndex, t.opts.Path, format, ringFrames)
+		if err != nil {
+			return err
+		}
+		t.wav = rec
+		return nil
+	}
+	return t.startHDF5(ringFrames)
+}
+
+func (t *Tap) startHDF5(ringFrames int) error {
+	cPath := C.CString(t.opts.Path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	file := C.rec_create_file(cPath)
+	if file == nil {
+		return fmt.Errorf("failed to create HDF5 file at %s", t.opts.Path)
+	}
+
+	bitDepth := t.opts.BitDepth
+	if bitDepth == 0 {
+		bitDepth = 32
+	}
+	cName := C.CString("capture")
+	defer C.free(unsafe.Pointer(cName))
+	dataset := C.rec_create_dataset(file, cName, C.int(t.opts.SampleRate), C.int(bitDepth), C.int(t.opts.Channels))
+	if dataset == nil {
+		C.rec_close_file(file)
+		return fmt.Errorf("failed to create HDF5 dataset for %s", t.opts.Path)
+	}
+
+	t.file, t.dataset = file, dataset
+	t.queue = make(chan hdf5Chunk, ringFrames)
+	t.stopCh = make(chan struct{})
+	t.doneCh = make(chan struct{})
+
+	source, err := tap.InstallCallbackTap(t.enginePtr, t.nodePtr, t.busIndex, 512, tap.TapFormatInterleavedFloat32, t.captureHDF5)
+	if err != nil {
+		C.rec_close_file(file)
+		return fmt.Errorf("failed to install capture tap: %w", err)
+	}
+	t.source = source
+
+	go t.flushLoopHDF5()
+	return nil
+}
+
+// captureHDF5 is the CallbackTap callback for the HDF5 path: it copies buf
+// (the CallbackTap's own buffer is reused after this returns) and queues it
+// for the writer goroutine, dropping and counting it as an overrun rather
+// than blocking the tap's drain loop if the queue is already full.
+func (t *Tap) captureHDF5(buf tap.TapBuffer) {
+	samples := make([]float32, len(buf.Float32Data))
+	copy(samples, buf.Float32Data)
+	select {
+	case t.queue <- hdf5Chunk{samples: samples, frames: buf.Frames}:
+	default:
+		atomic.AddUint64(&t.overruns, 1)
+	}
+}
+
+func (t *Tap) flushLoopHDF5() {
+	defer close(t.doneCh)
+	for {
+		select {
+		case c := <-t.queue:
+			t.writeChunkHDF5(c)
+		case <-t.stopCh:
+			for {
+				select {
+				case c := <-t.queue:
+					t.writeChunkHDF5(c)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (t *Tap) writeChunkHDF5(c hdf5Chunk) {
+	if len(c.samples) == 0 {
+		return
+	}
+	C.rec_append_chunk(t.dataset, (*C.float)(unsafe.Pointer(&c.samples[0])), C.int(c.frames), C.int(t.opts.Channels))
+	atomic.AddUint64(&t.framesWritten, uint64(c.frames))
+}
+
+// Overruns reports how many captured blocks were dropped because the
+// writer goroutine fell behind, for FormatHDF5 captures (WAV/CAF capture
+// reports this through Stats() on the underlying avaudio/tap.Recorder
+// instead - see Stop's doc comment for why the two paths aren't unified).
+func (t *Tap) Overruns() uint64 {
+	if t.wav != nil {
+		return t.wav.Stats().Overruns
+	}
+	return atomic.LoadUint64(&t.overruns)
+}
+
+// SamplesWritten returns the number of interleaved frames written to disk
+// so far.
+func (t *Tap) SamplesWritten() uint64 {
+	if t.wav != nil {
+		return t.wav.Stats().FramesWritten
+	}
+	return atomic.LoadUint64(&t.framesWritten)
+}
+
+// Stop ends capture and closes the backing file, blocking until every
+// queued block has been written. WAV/CAF and HDF5 close through different
+// native handles (ExtAudioFile vs. the HDF5 file/dataset pair), so Stop
+// dispatches to whichever path Start took rather than sharing one code path.
+func (t *Tap) Stop() error {
+	if t.wav != nil {
+		return t.wav.Stop()
+	}
+	if t.source == nil {
+		return errors.New("tap was never started")
+	}
+	if err := t.source.Remove(); err != nil {
+		return fmt.Errorf("failed to remove capture tap: %w", err)
+	}
+	close(t.stopCh)
+	<-t.doneCh
+
+	errorStr := C.rec_close_file(t.file)
+	if errorStr != nil {
+		return errors.New(C.GoString(errorStr))
+	}
+	return nil
+}
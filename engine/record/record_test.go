@@ -0,0 +1,51 @@
+package record
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+func TestNewTapRejectsInvalidArgs(t *testing.T) {
+	valid := unsafe.Pointer(&struct{}{})
+
+	if _, err := NewTap(nil, valid, 0, Options{Path: "/tmp/out.wav", Channels: 2, SampleRate: 48000}); err == nil {
+		t.Fatal("expected an error for a nil engine pointer")
+	}
+	if _, err := NewTap(valid, valid, 0, Options{Channels: 2, SampleRate: 48000}); err == nil {
+		t.Fatal("expected an error for an empty path")
+	}
+	if _, err := NewTap(valid, valid, 0, Options{Path: "/tmp/out.wav", SampleRate: 48000}); err == nil {
+		t.Fatal("expected an error for non-positive channels")
+	}
+	if _, err := NewTap(valid, valid, 0, Options{Path: "/tmp/out.wav", Channels: 2}); err == nil {
+		t.Fatal("expected an error for non-positive sample rate")
+	}
+}
+
+func TestNewTapDefaultsRingSeconds(t *testing.T) {
+	valid := unsafe.Pointer(&struct{}{})
+
+	rec, err := NewTap(valid, valid, 0, Options{Path: "/tmp/out.wav", Channels: 2, SampleRate: 48000})
+	if err != nil {
+		t.Fatalf("NewTap failed: %v", err)
+	}
+	if rec.opts.RingSeconds != defaultRingSeconds {
+		t.Errorf("expected RingSeconds to default to %d, got %d", defaultRingSeconds, rec.opts.RingSeconds)
+	}
+}
+
+func TestTapCaptureHDF5DropsOnQueueFull(t *testing.T) {
+	rec := &Tap{queue: make(chan hdf5Chunk, 1)}
+
+	rec.captureHDF5(tap.TapBuffer{Float32Data: []float32{0.1}, Frames: 1})
+	if rec.Overruns() != 0 {
+		t.Fatalf("expected no overrun for the first block, got %d", rec.Overruns())
+	}
+
+	rec.captureHDF5(tap.TapBuffer{Float32Data: []float32{0.2}, Frames: 1})
+	if overruns := rec.Overruns(); overruns != 1 {
+		t.Fatalf("expected one overrun once the queue is full, got %d", overruns)
+	}
+}
@@ -0,0 +1,277 @@
+// Package recorder turns a *tap.Tap into a background WAV writer, built
+// directly on top of Tap.Subscribe (see avaudio/tap's subscribe.go): the
+// subscription's own ring and pump goroutine already guarantee a slow disk
+// write never reaches the audio thread, so this package only has to turn
+// each delivered PCM block into bytes on disk and track how many the ring
+// had to drop along the way.
+package recorder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+	"github.com/shaban/macaudio/format/encoder"
+)
+
+// Format selects the on-disk sample format Recorder writes.
+type Format int
+
+const (
+	WAVFloat32 Format = iota
+	WAVPCM16
+	WAVPCM24
+)
+
+func (f Format) wavOptions(sampleRate, channels int) encoder.WAVOptions {
+	opts := encoder.WAVOptions{SampleRate: sampleRate, Channels: channels}
+	switch f {
+	case WAVPCM16:
+		opts.BitDepth = encoder.WAVPCM16
+	case WAVPCM24:
+		opts.BitDepth = encoder.WAVPCM24
+	default:
+		opts.Float32 = true
+	}
+	return opts
+}
+
+// Options configures New.
+type Options struct {
+	Path   string
+	Format Format
+
+	// MaxDuration stops writing (without closing the subscription or
+	// returning an error) once this much recording time has accumulated;
+	// 0 means unbounded.
+	MaxDuration time.Duration
+
+	// RingFrames sizes the Tap.Subscribe ring this Recorder registers, in
+	// blocks; 0 uses Subscribe's own default (see
+	// tap.SubscribeOptions.BufferFrames).
+	RingFrames int
+
+	// SplitEvery rotates to a new file, named from Path plus a UTC
+	// timestamp, every SplitEvery; 0 disables rotation and Path is written
+	// to directly.
+	SplitEvery time.Duration
+}
+
+// OverrunError reports that a Subscribe ring feeding this Recorder
+// overflowed and dropped frames, rather than Recorder blocking the audio
+// thread or corrupting the file on a disk stall.
+type OverrunError struct {
+	Dropped uint64
+}
+
+func (e *OverrunError) Error() string {
+	return fmt.Sprintf("recorder: dropped %d frames (disk couldn't keep up)", e.Dropped)
+}
+
+// Recorder writes the PCM stream from a subscribed *tap.Tap to one or more
+// WAV files, respecting the tap's own channel count and sample rate (see
+// tap.Tap.GetInfo) rather than any engine.AudioSpec the caller happened to
+// configure elsewhere.
+type Recorder struct {
+	source *tap.Tap
+	opts   Options
+
+	mu           sync.Mutex
+	subID        tap.SubscriptionID
+	subscribed   bool
+	file         *os.File
+	writer       *encoder.WAVWriter
+	channels     int
+	sampleRate   int
+	segmentStart time.Time
+	recordStart  time.Time
+	writeErr     error
+
+	bytesWritten uint64
+}
+
+// New creates an unstarted Recorder for t. Call Start to begin writing.
+func New(t *tap.Tap, opts Options) (*Recorder, error) {
+	if t == nil {
+		return nil, fmt.Errorf("recorder: tap cannot be nil")
+	}
+	if opts.Path == "" {
+		return nil, fmt.Errorf("recorder: path cannot be empty")
+	}
+	return &Recorder{source: t, opts: opts}, nil
+}
+
+// Start subscribes to the tap and opens the first segment file. It's an
+// error to call Start twice on the same Recorder.
+func (r *Recorder) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.subscribed {
+		return fmt.Errorf("recorder: already started")
+	}
+
+	info, err := r.source.GetInfo()
+	if err != nil {
+		return fmt.Errorf("recorder: failed to read tap format: %w", err)
+	}
+	r.channels = info.ChannelCount
+	r.sampleRate = int(info.SampleRate)
+	if r.channels <= 0 {
+		r.channels = 1
+	}
+
+	now := time.Now()
+	if err := r.openSegmentLocked(now); err != nil {
+		return err
+	}
+
+	id, err := r.source.Subscribe(r.deliver, tap.SubscribeOptions{BufferFrames: r.opts.RingFrames})
+	if err != nil {
+		r.closeSegmentLocked()
+		return fmt.Errorf("recorder: failed to subscribe to tap: %w", err)
+	}
+
+	r.subID = id
+	r.subscribed = true
+	r.recordStart = now
+	return nil
+}
+
+// segmentPath returns the file name for a segment opened at now: Path
+// itself when SplitEvery is disabled, otherwise Path with a UTC timestamp
+// spliced in before its extension.
+func (r *Recorder) segmentPath(now time.Time) string {
+	if r.opts.SplitEvery <= 0 {
+		return r.opts.Path
+	}
+	ext := filepath.Ext(r.opts.Path)
+	base := strings.TrimSuffix(r.opts.Path, ext)
+	return fmt.Sprintf("%s_%s%s", base, now.UTC().Format("20060102T150405"), ext)
+}
+
+func (r *Recorder) openSegmentLocked(now time.Time) error {
+	path := r.segmentPath(now)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("recorder: failed to create %s: %w", path, err)
+	}
+
+	w, err := encoder.NewWAVWriter(f, r.opts.Format.wavOptions(r.sampleRate, r.channels))
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("recorder: failed to write WAV header for %s: %w", path, err)
+	}
+
+	r.file = f
+	r.writer = w
+	r.segmentStart = now
+	return nil
+}
+
+func (r *Recorder) closeSegmentLocked() error {
+	if r.writer == nil {
+		return nil
+	}
+	err := r.writer.Close()
+	r.file.Close()
+	r.writer = nil
+	r.file = nil
+	return err
+}
+
+func (r *Recorder) rotateLocked(now time.Time) {
+	if err := r.closeSegmentLocked(); err != nil && r.writeErr == nil {
+		r.writeErr = err
+	}
+	if err := r.openSegmentLocked(now); err != nil && r.writeErr == nil {
+		r.writeErr = err
+	}
+}
+
+// deliver is the Subscribe callback - see subscribe.go's Tap.pump, which
+// already runs this on its own goroutine, off the render thread.
+func (r *Recorder) deliver(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.writer == nil {
+		return
+	}
+
+	now := time.Now()
+	if r.opts.MaxDuration > 0 && now.Sub(r.recordStart) >= r.opts.MaxDuration {
+		return
+	}
+	if r.opts.SplitEvery > 0 && now.Sub(r.segmentStart) >= r.opts.SplitEvery {
+		r.rotateLocked(now)
+	}
+
+	block := encoder.AudioBlock{Samples: pcm, SampleRate: r.sampleRate, Channels: channels, Interleaved: true}
+	if err := r.writer.WriteBlock(block); err != nil {
+		r.writeErr = err
+		return
+	}
+	atomic.AddUint64(&r.bytesWritten, uint64(len(pcm))*r.bytesPerSample())
+}
+
+func (r *Recorder) bytesPerSample() uint64 {
+	switch r.opts.Format {
+	case WAVPCM16:
+		return 2
+	case WAVPCM24:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// BytesWritten returns how many bytes of audio data this Recorder has
+// written so far, across every segment if SplitEvery rotation is active.
+func (r *Recorder) BytesWritten() uint64 {
+	return atomic.LoadUint64(&r.bytesWritten)
+}
+
+// Err returns the first write error Recorder hit (if any), or an
+// *OverrunError if the underlying Subscribe ring has dropped any frames -
+// checked last, since a dropped frame isn't itself a write failure but
+// still means the file is missing audio.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	writeErr := r.writeErr
+	r.mu.Unlock()
+	if writeErr != nil {
+		return writeErr
+	}
+
+	metrics, err := r.source.GetMetrics()
+	if err != nil {
+		return nil
+	}
+	if metrics.DroppedFrames > 0 {
+		return &OverrunError{Dropped: metrics.DroppedFrames}
+	}
+	return nil
+}
+
+// Stop unsubscribes from the tap and closes the current segment file.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	if !r.subscribed {
+		r.mu.Unlock()
+		return fmt.Errorf("recorder: not started")
+	}
+	r.subscribed = false
+	r.mu.Unlock()
+
+	if err := r.source.Unsubscribe(r.subID); err != nil {
+		return fmt.Errorf("recorder: failed to unsubscribe: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeSegmentLocked()
+}
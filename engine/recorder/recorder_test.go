@@ -0,0 +1,59 @@
+package recorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+func TestNewRejectsInvalidArgs(t *testing.T) {
+	if _, err := New(nil, Options{Path: "out.wav"}); err == nil {
+		t.Fatal("expected New(nil, ...) to fail")
+	}
+	if _, err := New(&tap.Tap{}, Options{}); err == nil {
+		t.Fatal("expected New with an empty Path to fail")
+	}
+}
+
+func TestSegmentPathWithoutRotation(t *testing.T) {
+	r := &Recorder{opts: Options{Path: "out.wav"}}
+	now := time.Date(2026, 7, 30, 12, 0, 0, 0, time.UTC)
+	if got := r.segmentPath(now); got != "out.wav" {
+		t.Fatalf("segmentPath = %q, want %q", got, "out.wav")
+	}
+}
+
+func TestSegmentPathWithRotationSplicesTimestampBeforeExtension(t *testing.T) {
+	r := &Recorder{opts: Options{Path: "out.wav", SplitEvery: time.Minute}}
+	now := time.Date(2026, 7, 30, 12, 0, 0, 0, time.UTC)
+	got := r.segmentPath(now)
+	want := "out_20260730T120000.wav"
+	if got != want {
+		t.Fatalf("segmentPath = %q, want %q", got, want)
+	}
+}
+
+func TestBytesPerSample(t *testing.T) {
+	cases := []struct {
+		format Format
+		want   uint64
+	}{
+		{WAVFloat32, 4},
+		{WAVPCM16, 2},
+		{WAVPCM24, 3},
+	}
+	for _, c := range cases {
+		r := &Recorder{opts: Options{Format: c.format}}
+		if got := r.bytesPerSample(); got != c.want {
+			t.Errorf("Format(%v).bytesPerSample() = %d, want %d", c.format, got, c.want)
+		}
+	}
+}
+
+func TestOverrunErrorMessage(t *testing.T) {
+	err := &OverrunError{Dropped: 42}
+	if got := err.Error(); got == "" {
+		t.Fatal("expected a non-empty error message")
+	}
+}
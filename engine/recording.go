@@ -0,0 +1,139 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L.. -lmacaudio -Wl,-rpath,..
+#include "../native/macaudio.h"
+#include <stdlib.h>
+
+// audiofile_create_writer, audiofile_writer_write, and audiofile_writer_close
+// are declared here, not implemented in this tree yet - they need a small
+// native/audiofile.m wrapping AVAudioFile's initForWriting:settings:... and
+// writeFromBuffer:, the same API every macOS recorder uses to get CAF/WAV/
+// AIFF onto disk without hand-rolling a container format in Go. The Go-side
+// API below (StartRecording/StopRecording) is complete and ready to use the
+// moment that shim exists.
+void* audiofile_create_writer(const char* path, int formatID, double sampleRate, int channelCount, char** error);
+const char* audiofile_writer_write(void* writer, float* interleaved, int frameCount);
+const char* audiofile_writer_close(void* writer);
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/shaban/macaudio/format/encoder"
+)
+
+// RecordingFormat selects the container StartRecording asks AVAudioFile to
+// write.
+type RecordingFormat int
+
+const (
+	// RecordingFormatCAF writes Core Audio Format, the only one of the
+	// three that round-trips every format AVAudioFile can produce.
+	RecordingFormatCAF RecordingFormat = iota
+	// RecordingFormatWAV writes RIFF/WAVE.
+	RecordingFormatWAV
+	// RecordingFormatAIFF writes AIFF.
+	RecordingFormatAIFF
+)
+
+// audioFileTypeID maps a RecordingFormat onto the kAudioFile*Type constant
+// AVAudioFile's settings dictionary expects.
+func (f RecordingFormat) audioFileTypeID() C.int {
+	switch f {
+	case RecordingFormatWAV:
+		return C.int(0x57415645) // kAudioFileWAVEType ('WAVE')
+	case RecordingFormatAIFF:
+		return C.int(0x41494646) // kAudioFileAIFFType ('AIFF')
+	default:
+		return C.int(0x63616666) // kAudioFileCAFType ('caff')
+	}
+}
+
+// nativeFileSink adapts an AVAudioFile writer to encoder.Encoder, so
+// StartRecording can reuse AttachEncoder/deliverCapture's existing
+// tap-to-sink wiring (see encoder_sink.go) instead of duplicating it -
+// Read's ring and InstallTap's callback keep working exactly as before
+// while a recording is in progress.
+type nativeFileSink struct {
+	ptr unsafe.Pointer
+}
+
+func newNativeFileSink(path string, format RecordingFormat, sampleRate float64, channelCount int) (*nativeFileSink, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var cErr *C.char
+	ptr := C.audiofile_create_writer(cPath, format.audioFileTypeID(), C.double(sampleRate), C.int(channelCount), &cErr)
+	if ptr == nil {
+		if cErr != nil {
+			defer C.free(unsafe.Pointer(cErr))
+			return nil, errors.New(C.GoString(cErr))
+		}
+		return nil, errors.New("failed to create AVAudioFile writer for " + path)
+	}
+	return &nativeFileSink{ptr: unsafe.Pointer(ptr)}, nil
+}
+
+// WriteBlock hands block's interleaved float32 samples to the native
+// writer. StartRecording only ever builds blocks this way (see
+// deliverCapture), so any other sample type is a programmer error upstream,
+// not something a recording in progress can recover from.
+func (s *nativeFileSink) WriteBlock(block encoder.AudioBlock) error {
+	samples, ok := block.Samples.([]float32)
+	if !ok {
+		return errors.New("nativeFileSink: expected []float32 samples")
+	}
+	if len(samples) == 0 || block.Channels == 0 {
+		return nil
+	}
+
+	frameCount := len(samples) / block.Channels
+	errStr := C.audiofile_writer_write(s.ptr, (*C.float)(unsafe.Pointer(&samples[0])), C.int(frameCount))
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+func (s *nativeFileSink) Close() error {
+	errStr := C.audiofile_writer_close(s.ptr)
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// StartRecording begins writing this capture channel's audio to path in
+// format via AVAudioFile - the only path in this package that can produce
+// CAF or AIFF, since format/encoder's pure-Go writers only cover WAV/FLAC/
+// MP3/Opus. Internally it's just AttachEncoder pointed at a nativeFileSink,
+// so StartRecording shares deliverCapture's tap plumbing, Read's ring, and
+// InstallTap's callback with every other capture path rather than standing
+// up a second delivery mechanism. Call StartCapture first (or concurrently)
+// to actually begin delivering audio to it.
+func (c *Channel) StartRecording(path string, format RecordingFormat) error {
+	if !c.IsInput() || c.InputOptions.inputNodePtr == nil {
+		return errors.New("channel is not a capture channel")
+	}
+
+	channels := c.InputOptions.Spec.ChannelCount
+	if channels <= 0 {
+		channels = 1
+	}
+	sink, err := newNativeFileSink(path, format, c.InputOptions.Spec.SampleRate, channels)
+	if err != nil {
+		return err
+	}
+	return c.AttachEncoder(sink)
+}
+
+// StopRecording stops and closes whatever file StartRecording opened,
+// draining any buffered audio to disk first. It's a no-op if no recording
+// is in progress - identical to DetachEncoder, just named for the
+// StartRecording call site it pairs with.
+func (c *Channel) StopRecording() error {
+	return c.DetachEncoder()
+}
@@ -0,0 +1,19 @@
+package engine
+
+import "testing"
+
+func TestRecordingFormatAudioFileTypeID(t *testing.T) {
+	cases := []struct {
+		format RecordingFormat
+		want   int
+	}{
+		{RecordingFormatCAF, 0x63616666},
+		{RecordingFormatWAV, 0x57415645},
+		{RecordingFormatAIFF, 0x41494646},
+	}
+	for _, c := range cases {
+		if got := int(c.format.audioFileTypeID()); got != c.want {
+			t.Errorf("RecordingFormat(%d).audioFileTypeID() = %#x, want %#x", c.format, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/shaban/macaudio/session"
+)
+
+// ErrLoudnessNotCached is returned by ApplyReplayGain when the channel's
+// PlaybackOptions.FilePath has no cached loudness measurement at its
+// current mtime yet - call session.AnalyzeLoudnessCtx or
+// session.ScanLoudness on it first.
+var ErrLoudnessNotCached = errors.New("engine: no cached loudness measurement for this channel's file")
+
+// ApplyReplayGain sets c.Volume so PlaybackOptions.FilePath plays back at
+// targetLUFS, deriving the gain from that file's cached ITU-R BS.1770
+// loudness measurement (see session.LoadLoudnessCache). The derived gain is
+// clamped so it can never drive the file's cached true peak above 0 dBFS
+// (gainLinear * truePeakLinear <= 1.0), the same inter-sample-clipping
+// guard ReplayGain-aware players apply, then written through
+// SetVolumeLinear with allowGain so a quiet track can be boosted toward
+// targetLUFS as well as attenuated.
+func (c *Channel) ApplyReplayGain(targetLUFS float64) error {
+	if c.PlaybackOptions == nil || c.PlaybackOptions.FilePath == "" {
+		return errors.New("engine: channel has no PlaybackOptions.FilePath to apply replay gain from")
+	}
+
+	result, ok, err := session.LoadLoudnessCache(c.PlaybackOptions.FilePath)
+	if err != nil {
+		return fmt.Errorf("engine: loading loudness cache: %w", err)
+	}
+	if !ok {
+		return ErrLoudnessNotCached
+	}
+	if math.IsInf(result.IntegratedLUFS, -1) {
+		return errors.New("engine: cached integrated loudness is -Inf (silent or unmeasurable file)")
+	}
+
+	gainLinear := math.Pow(10, (targetLUFS-result.IntegratedLUFS)/20)
+	if !math.IsInf(result.TruePeak, -1) {
+		if truePeakLinear := math.Pow(10, result.TruePeak/20); truePeakLinear > 0 {
+			if ceiling := 1.0 / truePeakLinear; gainLinear > ceiling {
+				gainLinear = ceiling
+			}
+		}
+	}
+
+	return c.SetVolumeLinear(float32(gainLinear), true)
+}
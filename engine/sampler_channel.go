@@ -8,6 +8,7 @@ import "C"
 import (
 	"errors"
 	"time"
+	"unsafe"
 )
 
 // CreateSamplerChannel creates a sampler channel that can play notes directly
@@ -57,6 +58,20 @@ func (e *Engine) CreateSamplerChannel() (*Channel, error) {
 
 // StartNote starts playing a note on the sampler channel
 func (c *Channel) StartNote(note int, velocity int) error {
+	return c.StartNoteOn(0, note, velocity)
+}
+
+// StopNote stops playing a note on the sampler channel
+func (c *Channel) StopNote(note int) error {
+	return c.StopNoteOn(0, note)
+}
+
+// StartNoteOn starts playing a note on the given MIDI channel (0-15),
+// for multi-timbral sounds (e.g. a multi-preset SoundFont bank) where
+// different MIDI channels route to different instruments within the same
+// AVAudioUnitSampler. StartNote/StopNote are the midiChannel-0 convenience
+// case of this and StopNoteOn.
+func (c *Channel) StartNoteOn(midiChannel int, note int, velocity int) error {
 	if !c.IsSampler() {
 		return errors.New("not a sampler channel")
 	}
@@ -65,8 +80,7 @@ func (c *Channel) StartNote(note int, velocity int) error {
 		return errors.New("sampler not initialized")
 	}
 
-	// Use MIDI channel 0 for simplicity
-	errorStr := C.audiosampler_start_note((*C.AudioSampler)(c.SamplerOptions.samplerPtr), C.int(note), C.int(velocity), C.int(0))
+	errorStr := C.audiosampler_start_note((*C.AudioSampler)(c.SamplerOptions.samplerPtr), C.int(note), C.int(velocity), C.int(midiChannel))
 	if errorStr != nil {
 		return errors.New("Failed to start note: " + C.GoString(errorStr))
 	}
@@ -74,8 +88,9 @@ func (c *Channel) StartNote(note int, velocity int) error {
 	return nil
 }
 
-// StopNote stops playing a note on the sampler channel
-func (c *Channel) StopNote(note int) error {
+// StopNoteOn stops playing a note on the given MIDI channel (0-15). See
+// StartNoteOn.
+func (c *Channel) StopNoteOn(midiChannel int, note int) error {
 	if !c.IsSampler() {
 		return errors.New("not a sampler channel")
 	}
@@ -84,8 +99,7 @@ func (c *Channel) StopNote(note int) error {
 		return errors.New("sampler not initialized")
 	}
 
-	// Use MIDI channel 0 for simplicity
-	errorStr := C.audiosampler_stop_note((*C.AudioSampler)(c.SamplerOptions.samplerPtr), C.int(note), C.int(0))
+	errorStr := C.audiosampler_stop_note((*C.AudioSampler)(c.SamplerOptions.samplerPtr), C.int(note), C.int(midiChannel))
 	if errorStr != nil {
 		return errors.New("Failed to stop note: " + C.GoString(errorStr))
 	}
@@ -93,17 +107,83 @@ func (c *Channel) StopNote(note int) error {
 	return nil
 }
 
-// PlayNote plays a note for a specific duration (convenience function)
+// PlayNote plays a note for a specific duration on MIDI channel 0
+// (convenience function).
 func (c *Channel) PlayNote(note int, velocity int, duration time.Duration) error {
-	err := c.StartNote(note, velocity)
+	return c.PlayNoteOn(0, note, velocity, duration)
+}
+
+// PlayNoteOn plays a note for a specific duration on the given MIDI
+// channel (convenience function), so a multi-timbral setup loaded via
+// LoadSoundFont can trigger different instruments by MIDI channel without
+// callers managing StartNoteOn/StopNoteOn timing themselves.
+func (c *Channel) PlayNoteOn(midiChannel int, note int, velocity int, duration time.Duration) error {
+	err := c.StartNoteOn(midiChannel, note, velocity)
 	if err != nil {
 		return err
 	}
 
 	// Schedule note stop
 	time.AfterFunc(duration, func() {
-		c.StopNote(note) // Ignore error in background
+		c.StopNoteOn(midiChannel, note) // Ignore error in background
 	})
 
 	return nil
 }
+
+// LoadSoundFont loads preset (bank, program) from the SF2 or DLS sound
+// bank at path into the sampler, replacing whatever instrument was loaded
+// before. Use session.ListSoundFontPresets to discover which (bank,
+// program) pairs a given file offers before calling this. On success,
+// path/bank/program are recorded in SamplerOptions so engine serialization
+// round-trips which instrument was loaded (see SamplerOptions).
+func (c *Channel) LoadSoundFont(path string, bank int, program int) error {
+	if !c.IsSampler() {
+		return errors.New("not a sampler channel")
+	}
+	if c.SamplerOptions.samplerPtr == nil {
+		return errors.New("sampler not initialized")
+	}
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	errorStr := C.audiosampler_load_soundbank((*C.AudioSampler)(c.SamplerOptions.samplerPtr), cPath, C.int(bank), C.int(program))
+	if errorStr != nil {
+		return errors.New("Failed to load sound font: " + C.GoString(errorStr))
+	}
+
+	c.SamplerOptions.Path = path
+	c.SamplerOptions.Bank = bank
+	c.SamplerOptions.Program = program
+	c.SamplerOptions.InstrumentType = "soundfont"
+	return nil
+}
+
+// LoadEXS24 loads the EXS24 instrument at path into the sampler, replacing
+// whatever instrument was loaded before. EXS24 instruments have no
+// bank/program to select - they carry one instrument per file - so
+// SamplerOptions.Bank/Program are left at their zero value after a
+// successful load.
+func (c *Channel) LoadEXS24(path string) error {
+	if !c.IsSampler() {
+		return errors.New("not a sampler channel")
+	}
+	if c.SamplerOptions.samplerPtr == nil {
+		return errors.New("sampler not initialized")
+	}
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	errorStr := C.audiosampler_load_instrument((*C.AudioSampler)(c.SamplerOptions.samplerPtr), cPath)
+	if errorStr != nil {
+		return errors.New("Failed to load EXS24 instrument: " + C.GoString(errorStr))
+	}
+
+	c.SamplerOptions.Path = path
+	c.SamplerOptions.Bank = 0
+	c.SamplerOptions.Program = 0
+	c.SamplerOptions.InstrumentType = "exs24"
+	return nil
+}
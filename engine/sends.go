@@ -0,0 +1,144 @@
+package engine
+
+import "fmt"
+
+// SendID identifies a send within a channel's Sends slice. IDs are unique
+// per-channel, monotonically increasing, and stable across RemoveSend calls
+// (a removed ID is never reused).
+type SendID int
+
+// ChannelSend describes a routing of one channel's signal into another
+// channel's plugin chain, e.g. for sidechain keying a compressor off a kick
+// drum. DestBusIndex names the target by its Engine.Channels bus index
+// rather than embedding a pointer, so the send graph round-trips through
+// JSON along with the rest of the engine state.
+type ChannelSend struct {
+	ID           SendID  `json:"id"`
+	DestBusIndex int     `json:"destBusIndex"`
+	Gain         float32 `json:"gain"`
+	PreFader     bool    `json:"preFader"`
+}
+
+// SidechainSlot configures the sidechain key input for a plugin chain's
+// first plugin that advertises a sidechain bus. SourceBusIndex names the
+// channel supplying the key signal; at connection time this maps to the
+// AudioUnit's kAudioUnitProperty_MakeConnection sidechain bus.
+type SidechainSlot struct {
+	SourceBusIndex int      `json:"sourceBusIndex"`
+	Gain           float32  `json:"gain"`
+	Tap            TapPoint `json:"tap"`
+}
+
+// TapPoint selects where along the source channel's signal path a sidechain
+// key is read from.
+type TapPoint int
+
+const (
+	// TapPostFader reads the signal after the source channel's own
+	// Volume/Pan is applied - what most compressors expect from a key
+	// input, and the zero value here.
+	TapPostFader TapPoint = iota
+	// TapPreFader reads the signal before the source channel's fader, so
+	// riding that channel's own volume doesn't affect the sidechain key.
+	TapPreFader
+)
+
+// AddSend appends a new send from c to dest and returns its SendID. It does
+// not by itself detect cycles; call (*Engine).ValidateSendGraph after
+// mutating sends, and before starting playback, to catch them.
+func (c *Channel) AddSend(dest *Channel, gain float32, preFader bool) SendID {
+	id := SendID(len(c.Sends))
+	for _, s := range c.Sends {
+		if int(s.ID) >= int(id) {
+			id = s.ID + 1
+		}
+	}
+	c.Sends = append(c.Sends, ChannelSend{
+		ID:           id,
+		DestBusIndex: dest.BusIndex,
+		Gain:         gain,
+		PreFader:     preFader,
+	})
+	return id
+}
+
+// RemoveSend removes the send with the given ID, if present.
+func (c *Channel) RemoveSend(id SendID) error {
+	for i, s := range c.Sends {
+		if s.ID == id {
+			c.Sends = append(c.Sends[:i], c.Sends[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("send %d not found", id)
+}
+
+// sidechainOf returns the channel's plugin chain's SidechainInput, if it has
+// one. Only input channels carry a PluginChain today; see InputOptions.
+func sidechainOf(ch *Channel) *SidechainSlot {
+	if ch.InputOptions == nil || ch.InputOptions.PluginChain == nil {
+		return nil
+	}
+	return ch.InputOptions.PluginChain.SidechainInput
+}
+
+// ValidateSendGraph detects cycles across every channel's Sends and
+// sidechain routing in the engine. It should be run after deserializing
+// engine state (or after any send/sidechain mutation) and before the
+// render graph is rebuilt, since a cyclic graph cannot be wired into
+// AVAudioEngine's node graph - a sidechain source feeding a channel's
+// plugin input is, for this purpose, exactly the same kind of edge as a
+// ChannelSend.
+func (e *Engine) ValidateSendGraph() error {
+	// outEdges[busIndex] lists every bus that busIndex's signal feeds into,
+	// combining Sends (always an outgoing edge) with sidechain routing
+	// (an outgoing edge from the source bus to whichever channel keys off
+	// it).
+	outEdges := make(map[int][]int, len(e.Channels))
+	for _, ch := range e.Channels {
+		if ch == nil {
+			continue
+		}
+		for _, send := range ch.Sends {
+			outEdges[ch.BusIndex] = append(outEdges[ch.BusIndex], send.DestBusIndex)
+		}
+		if sc := sidechainOf(ch); sc != nil {
+			outEdges[sc.SourceBusIndex] = append(outEdges[sc.SourceBusIndex], ch.BusIndex)
+		}
+	}
+
+	const (
+		unvisited = 0
+		visiting  = 1
+		visited   = 2
+	)
+	state := make(map[int]int, len(e.Channels))
+
+	var visit func(busIndex int, path []int) error
+	visit = func(busIndex int, path []int) error {
+		switch state[busIndex] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("send cycle detected: %v", append(path, busIndex))
+		}
+		state[busIndex] = visiting
+		for _, dest := range outEdges[busIndex] {
+			if err := visit(dest, append(path, busIndex)); err != nil {
+				return err
+			}
+		}
+		state[busIndex] = visited
+		return nil
+	}
+
+	for _, ch := range e.Channels {
+		if ch == nil {
+			continue
+		}
+		if err := visit(ch.BusIndex, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package engine
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -L.. -lmacaudio -Wl,-rpath,..
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// ChannelSnapshot captures a Channel's full mixable state - Volume, Pan, and
+// whichever of Input/Playback applies - as a plain value that round-trips
+// through encoding/json. See Channel.Snapshot/Restore and the ABSlots
+// helpers (StoreSlot/RecallSlot/Compare) built on top of it.
+type ChannelSnapshot struct {
+	Volume   float32           `json:"volume"`
+	Pan      float32           `json:"pan"`
+	Input    *InputSnapshot    `json:"input,omitempty"`
+	Playback *PlaybackSnapshot `json:"playback,omitempty"`
+}
+
+// InputSnapshot is the InputOptions slice of a ChannelSnapshot. ChainRefName
+// names a shared chain by ChainRef.Name rather than by RefID/pointer, so a
+// restore on a freshly started Engine can reattach it through that engine's
+// own ChainManager instead of a stale reference into the old one.
+type InputSnapshot struct {
+	DeviceUID    string       `json:"deviceUID"`
+	ChannelIndex int          `json:"channelIndex"`
+	ChainRefName string       `json:"chainRefName,omitempty"`
+	PluginChain  *PluginChain `json:"pluginChain,omitempty"`
+}
+
+// PlaybackSnapshot is the PlaybackOptions slice of a ChannelSnapshot.
+// Playhead is captured via currentPlayhead, not PlaybackOptions's own
+// fields - there's no cached playhead field to read.
+type PlaybackSnapshot struct {
+	FilePath string        `json:"filePath"`
+	Rate     float32       `json:"rate"`
+	Pitch    float32       `json:"pitch"`
+	Detune   float32       `json:"detune"`
+	Playhead time.Duration `json:"playhead"`
+}
+
+// Snapshot captures c's current mixable state. The returned value shares no
+// state with c - PluginChain, if any, is deep-copied via clonePluginChain -
+// so later mutating c doesn't retroactively change a stored snapshot.
+func (c *Channel) Snapshot() ChannelSnapshot {
+	snap := ChannelSnapshot{Volume: c.Volume, Pan: c.Pan}
+
+	if c.InputOptions != nil {
+		input := &InputSnapshot{
+			DeviceUID:    c.InputOptions.DeviceUID,
+			ChannelIndex: c.InputOptions.ChannelIndex,
+			PluginChain:  clonePluginChain(c.InputOptions.PluginChain),
+		}
+		if c.InputOptions.ChainRef != nil {
+			input.ChainRefName = c.InputOptions.ChainRef.Name
+		}
+		snap.Input = input
+	}
+
+	if c.PlaybackOptions != nil {
+		snap.Playback = &PlaybackSnapshot{
+			FilePath: c.PlaybackOptions.FilePath,
+			Rate:     c.PlaybackOptions.Rate,
+			Pitch:    c.PlaybackOptions.Pitch,
+			Detune:   c.PlaybackOptions.Detune,
+			Playhead: c.currentPlayhead(),
+		}
+	}
+
+	return snap
+}
+
+// clonePluginChain deep-copies pc by round-tripping it through
+// encoding/json - the same mechanism ChannelSnapshot itself must support, so
+// it doubles as a correct deep copy without a hand-rolled field-by-field
+// walk. Returns nil for a nil pc, and nil if pc somehow fails to round-trip
+// (e.g. a plugin carries a non-serializable parameter value).
+func clonePluginChain(pc *PluginChain) *PluginChain {
+	if pc == nil {
+		return nil
+	}
+	data, err := json.Marshal(pc)
+	if err != nil {
+		return nil
+	}
+	clone := &PluginChain{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil
+	}
+	return clone
+}
+
+// currentPlayhead reads the native player's current position, returning 0
+// for a non-playback channel or one with no native player yet.
+func (c *Channel) currentPlayhead() time.Duration {
+	if !c.IsPlayback() || c.PlaybackOptions.playerPtr == nil {
+		return 0
+	}
+
+	var seconds C.double
+	playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
+	errorStr := C.audioplayer_current_time(playerPtr, &seconds)
+	if errorStr != nil {
+		return 0
+	}
+	return time.Duration(float64(seconds) * float64(time.Second))
+}
+
+// Restore applies snap to c, the inverse of Snapshot. Restoring
+// snap.Input's DeviceUID onto a channel currently bound to a different
+// device requires that device to be present (looked up via
+// devices.GetAudio) - RebindDevice does the actual rewiring. Restoring
+// snap.Playback's FilePath onto a channel with a different file loaded
+// isn't supported; create a new channel for that file instead.
+func (c *Channel) Restore(snap ChannelSnapshot) error {
+	if snap.Input != nil && !c.IsInput() {
+		return errors.New("snapshot has an Input slice but channel is not a capture channel")
+	}
+	if snap.Playback != nil && !c.IsPlayback() {
+		return errors.New("snapshot has a Playback slice but channel is not a playback channel")
+	}
+
+	if err := c.SetVolume(snap.Volume); err != nil {
+		return err
+	}
+	if err := c.SetPan(snap.Pan); err != nil {
+		return err
+	}
+
+	if snap.Input != nil {
+		if err := c.restoreInput(snap.Input); err != nil {
+			return err
+		}
+	}
+
+	if snap.Playback != nil {
+		if err := c.restorePlayback(snap.Playback); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreInput applies snap's device, plugin chain, and shared-chain
+// reference to c.InputOptions.
+func (c *Channel) restoreInput(snap *InputSnapshot) error {
+	c.InputOptions.ChannelIndex = snap.ChannelIndex
+	c.InputOptions.PluginChain = clonePluginChain(snap.PluginChain)
+
+	if snap.DeviceUID != "" && snap.DeviceUID != c.InputOptions.DeviceUID {
+		available, err := devices.GetAudio()
+		if err != nil {
+			return fmt.Errorf("looking up device %q to restore: %w", snap.DeviceUID, err)
+		}
+		device := available.ByUID(snap.DeviceUID)
+		if device == nil {
+			return fmt.Errorf("device %q is not currently available", snap.DeviceUID)
+		}
+		if err := c.RebindDevice(device); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case snap.ChainRefName == "" && c.InputOptions.ChainRef != nil:
+		c.detachSharedChainLocked()
+	case snap.ChainRefName != "" && (c.InputOptions.ChainRef == nil || c.InputOptions.ChainRef.Name != snap.ChainRefName):
+		if c.InputOptions.ChainRef != nil {
+			c.detachSharedChainLocked()
+		}
+		if err := c.AttachSharedChain(snap.ChainRefName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restorePlayback applies snap's rate/pitch/detune/playhead to c's native
+// player.
+func (c *Channel) restorePlayback(snap *PlaybackSnapshot) error {
+	if snap.FilePath != "" && snap.FilePath != c.PlaybackOptions.FilePath {
+		return fmt.Errorf("snapshot was taken of %q, channel has %q loaded - restoring a different file isn't supported", snap.FilePath, c.PlaybackOptions.FilePath)
+	}
+	if err := c.SetPlaybackRate(snap.Rate); err != nil {
+		return err
+	}
+	if err := c.SetPitch(snap.Pitch); err != nil {
+		return err
+	}
+	if err := c.SetDetune(snap.Detune); err != nil {
+		return err
+	}
+	return c.Seek(snap.Playhead)
+}
+
+// Difference is one field that differs between two ChannelSnapshots, as
+// reported by Compare. A and B hold the two slots' values formatted for
+// display, not typed values - the fields being compared span several
+// different Go types.
+type Difference struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// StoreSlot captures c's current state under name, for later RecallSlot or
+// Compare - the "A/B" of flipping between two mix states while tracking.
+func (c *Channel) StoreSlot(name string) {
+	if c.abSlots == nil {
+		c.abSlots = make(map[string]ChannelSnapshot)
+	}
+	c.abSlots[name] = c.Snapshot()
+}
+
+// RecallSlot restores the state StoreSlot(name) captured.
+func (c *Channel) RecallSlot(name string) error {
+	snap, ok := c.abSlots[name]
+	if !ok {
+		return fmt.Errorf("no slot named %q", name)
+	}
+	return c.Restore(snap)
+}
+
+// Compare reports every field that differs between two previously stored
+// slots.
+func (c *Channel) Compare(slotA, slotB string) ([]Difference, error) {
+	a, ok := c.abSlots[slotA]
+	if !ok {
+		return nil, fmt.Errorf("no slot named %q", slotA)
+	}
+	b, ok := c.abSlots[slotB]
+	if !ok {
+		return nil, fmt.Errorf("no slot named %q", slotB)
+	}
+	return diffSnapshots(a, b), nil
+}
+
+// diffSnapshots compares a and b field by field, formatting each side with
+// fmt.Sprint so values of different underlying types (float32, int,
+// time.Duration, *PluginChain) compare uniformly.
+func diffSnapshots(a, b ChannelSnapshot) []Difference {
+	var diffs []Difference
+
+	addIfDiff := func(field string, va, vb interface{}) {
+		if fmt.Sprint(va) != fmt.Sprint(vb) {
+			diffs = append(diffs, Difference{Field: field, A: fmt.Sprint(va), B: fmt.Sprint(vb)})
+		}
+	}
+
+	addIfDiff("volume", a.Volume, b.Volume)
+	addIfDiff("pan", a.Pan, b.Pan)
+
+	switch {
+	case a.Input == nil && b.Input == nil:
+	case a.Input == nil || b.Input == nil:
+		diffs = append(diffs, Difference{Field: "input", A: fmt.Sprint(a.Input != nil), B: fmt.Sprint(b.Input != nil)})
+	default:
+		addIfDiff("input.deviceUID", a.Input.DeviceUID, b.Input.DeviceUID)
+		addIfDiff("input.channelIndex", a.Input.ChannelIndex, b.Input.ChannelIndex)
+		addIfDiff("input.chainRefName", a.Input.ChainRefName, b.Input.ChainRefName)
+		aChain, _ := json.Marshal(a.Input.PluginChain)
+		bChain, _ := json.Marshal(b.Input.PluginChain)
+		addIfDiff("input.pluginChain", string(aChain), string(bChain))
+	}
+
+	switch {
+	case a.Playback == nil && b.Playback == nil:
+	case a.Playback == nil || b.Playback == nil:
+		diffs = append(diffs, Difference{Field: "playback", A: fmt.Sprint(a.Playback != nil), B: fmt.Sprint(b.Playback != nil)})
+	default:
+		addIfDiff("playback.filePath", a.Playback.FilePath, b.Playback.FilePath)
+		addIfDiff("playback.rate", a.Playback.Rate, b.Playback.Rate)
+		addIfDiff("playback.pitch", a.Playback.Pitch, b.Playback.Pitch)
+		addIfDiff("playback.detune", a.Playback.Detune, b.Playback.Detune)
+		addIfDiff("playback.playhead", a.Playback.Playhead, b.Playback.Playhead)
+	}
+
+	return diffs
+}
+
+// EngineSnapshot is an Engine-wide ChannelSnapshot, as produced by
+// SnapshotAll and consumed by RestoreAll.
+type EngineSnapshot struct {
+	Channels []ChannelSnapshot `json:"channels"`
+}
+
+// SnapshotAll captures every live channel on e, skipping empty slots.
+func (e *Engine) SnapshotAll() EngineSnapshot {
+	snap := EngineSnapshot{Channels: make([]ChannelSnapshot, 0, len(e.Channels))}
+	for _, ch := range e.Channels {
+		if ch == nil {
+			continue
+		}
+		snap.Channels = append(snap.Channels, ch.Snapshot())
+	}
+	return snap
+}
+
+// RestoreAll applies snap to e's current channels, matched positionally
+// against SnapshotAll's own iteration order. e must already have the same
+// number of live channels snap was taken from - RestoreAll recalls state
+// onto existing channels, it doesn't recreate the channels themselves - so
+// a caller restoring into a freshly started Engine must create them first.
+func (e *Engine) RestoreAll(snap EngineSnapshot) error {
+	var channels []*Channel
+	for _, ch := range e.Channels {
+		if ch != nil {
+			channels = append(channels, ch)
+		}
+	}
+	if len(channels) != len(snap.Channels) {
+		return fmt.Errorf("engine has %d channels but snapshot has %d - recreate channels in the same order before RestoreAll", len(channels), len(snap.Channels))
+	}
+
+	for i, ch := range channels {
+		if err := ch.Restore(snap.Channels[i]); err != nil {
+			return fmt.Errorf("restoring channel %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package engine
+
+import "testing"
+
+func TestStoreSlotCapturesCurrentState(t *testing.T) {
+	channel := &Channel{Volume: 0.8, Pan: -0.5}
+	channel.StoreSlot("A")
+
+	snap, ok := channel.abSlots["A"]
+	if !ok {
+		t.Fatal("expected StoreSlot to record a snapshot under \"A\"")
+	}
+	if snap.Volume != 0.8 || snap.Pan != -0.5 {
+		t.Errorf("stored snapshot = %+v, want Volume=0.8 Pan=-0.5", snap)
+	}
+}
+
+func TestCompareUnknownSlotReturnsError(t *testing.T) {
+	channel := &Channel{}
+	channel.StoreSlot("A")
+
+	if _, err := channel.Compare("A", "B"); err == nil {
+		t.Error("expected Compare to fail when slot B was never stored")
+	}
+	if _, err := channel.Compare("missing", "A"); err == nil {
+		t.Error("expected Compare to fail when slot A (first arg) was never stored")
+	}
+}
+
+func TestCompareReportsChangedFields(t *testing.T) {
+	channel := &Channel{Volume: 0.5, Pan: 0}
+	channel.StoreSlot("A")
+	channel.Volume = 0.9
+	channel.Pan = 1
+	channel.StoreSlot("B")
+
+	diffs, err := channel.Compare("A", "B")
+	if err != nil {
+		t.Fatalf("Compare returned an error: %v", err)
+	}
+
+	want := map[string]struct{ a, b string }{
+		"volume": {"0.5", "0.9"},
+		"pan":    {"0", "1"},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("Compare returned %d differences, want %d: %+v", len(diffs), len(want), diffs)
+	}
+	for _, d := range diffs {
+		exp, ok := want[d.Field]
+		if !ok {
+			t.Errorf("unexpected field %q in diff", d.Field)
+			continue
+		}
+		if d.A != exp.a || d.B != exp.b {
+			t.Errorf("diff for %q = {A:%q B:%q}, want {A:%q B:%q}", d.Field, d.A, d.B, exp.a, exp.b)
+		}
+	}
+}
+
+func TestCompareNoDifferences(t *testing.T) {
+	channel := &Channel{Volume: 0.5, Pan: 0}
+	channel.StoreSlot("A")
+	channel.StoreSlot("B")
+
+	diffs, err := channel.Compare("A", "B")
+	if err != nil {
+		t.Fatalf("Compare returned an error: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("expected no differences between identical slots, got %+v", diffs)
+	}
+}
+
+func TestRecallSlotRejectsUnknownSlot(t *testing.T) {
+	channel := &Channel{}
+	if err := channel.RecallSlot("nope"); err == nil {
+		t.Error("expected RecallSlot to fail for a slot that was never stored")
+	}
+}
+
+func TestRestoreRejectsInputSnapshotOnPlaybackChannel(t *testing.T) {
+	channel := &Channel{PlaybackOptions: &PlaybackOptions{}}
+	snap := ChannelSnapshot{Input: &InputSnapshot{DeviceUID: "mic-1"}}
+
+	if err := channel.Restore(snap); err == nil {
+		t.Error("expected Restore to fail applying an Input snapshot to a playback channel")
+	}
+}
+
+func TestRestoreRejectsPlaybackSnapshotOnCaptureChannel(t *testing.T) {
+	channel := &Channel{InputOptions: &InputOptions{}}
+	snap := ChannelSnapshot{Playback: &PlaybackSnapshot{FilePath: "/tmp/a.wav"}}
+
+	if err := channel.Restore(snap); err == nil {
+		t.Error("expected Restore to fail applying a Playback snapshot to a capture channel")
+	}
+}
+
+func TestRestoreRejectsVolumeWithoutMixerNode(t *testing.T) {
+	channel := &Channel{}
+	if err := channel.Restore(ChannelSnapshot{Volume: 0.5}); err == nil {
+		t.Error("expected Restore to fail without a mixer node")
+	}
+}
+
+func TestClonePluginChainNilIsNil(t *testing.T) {
+	if got := clonePluginChain(nil); got != nil {
+		t.Errorf("clonePluginChain(nil) = %+v, want nil", got)
+	}
+}
+
+func TestClonePluginChainDeepCopies(t *testing.T) {
+	original := NewPluginChain()
+	original.Plugins = append(original.Plugins, EnginePlugin{Bypassed: true})
+
+	clone := clonePluginChain(original)
+	if clone == nil {
+		t.Fatal("clonePluginChain returned nil for a non-nil chain")
+	}
+	if len(clone.Plugins) != 1 || clone.Plugins[0].Bypassed != true {
+		t.Fatalf("clone = %+v, want one bypassed plugin", clone)
+	}
+
+	clone.Plugins[0].Bypassed = false
+	if !original.Plugins[0].Bypassed {
+		t.Error("mutating the clone changed the original - clonePluginChain must deep-copy")
+	}
+}
+
+func TestEngineSnapshotAllSkipsEmptySlots(t *testing.T) {
+	e := &Engine{}
+	e.Channels[0] = &Channel{Volume: 0.5}
+	e.Channels[2] = &Channel{Volume: 0.7}
+
+	snap := e.SnapshotAll()
+	if len(snap.Channels) != 2 {
+		t.Fatalf("SnapshotAll returned %d channels, want 2", len(snap.Channels))
+	}
+}
+
+func TestEngineRestoreAllRejectsChannelCountMismatch(t *testing.T) {
+	e := &Engine{}
+	e.Channels[0] = &Channel{Volume: 0.5}
+
+	err := e.RestoreAll(EngineSnapshot{Channels: []ChannelSnapshot{{}, {}}})
+	if err == nil {
+		t.Error("expected RestoreAll to fail when the channel counts don't match")
+	}
+}
@@ -0,0 +1,213 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// StreamDecoder decodes interleaved float32 PCM frames from an arbitrary
+// source, letting CreateStreamPlaybackChannel pull audio from anything -
+// embed.FS assets, HTTP response bodies, gzipped files, in-memory
+// synthesized buffers - without AVAudioFile's requirement of a path on
+// disk. Implementations wrap whatever container/codec a caller needs (a
+// thin WAV header parse, a third-party MP3/Vorbis decoder, or a raw PCM
+// reader); this package supplies the plumbing, not the codecs.
+type StreamDecoder interface {
+	// ReadFrames decodes into buf, which holds frames interleaved across
+	// ChannelCount channels, and returns how many full frames were
+	// written. Returns io.EOF (possibly alongside a final partial read)
+	// once the source is exhausted.
+	ReadFrames(buf []float32) (frames int, err error)
+	SampleRate() int
+	ChannelCount() int
+}
+
+// streamBufferFrames sizes each chunk pulled from the decoder and
+// scheduled onto the native player.
+const streamBufferFrames = 4096
+
+// streamRingBuffers bounds how many decoded chunks the feeder goroutine
+// may schedule ahead of playback, so a fast decoder can't grow the
+// native player's internal queue without bound.
+const streamRingBuffers = 4
+
+// CreateStreamPlaybackChannel creates a playback channel fed by decoder
+// instead of a file path (see CreatePlaybackChannel). It builds the same
+// Player → TimePitch → ChannelMixer → MainMixer graph CreatePlaybackChannel
+// does, but rather than audioplayer_load_file, a background goroutine
+// pulls streamBufferFrames-sized chunks from decoder and schedules them
+// onto the native player via audioplayer_schedule_buffer, staying
+// streamRingBuffers chunks ahead of playback to avoid underruns. The
+// goroutine exits (and the decoder, if it implements io.Closer, is
+// closed) once decoder returns io.EOF or the channel is destroyed.
+func (e *Engine) CreateStreamPlaybackChannel(decoder StreamDecoder) (*Channel, error) {
+	if e.nativeEngine == nil {
+		return nil, errors.New("engine is not properly initialized")
+	}
+	if decoder == nil {
+		return nil, errors.New("decoder cannot be nil")
+	}
+	if decoder.SampleRate() <= 0 || decoder.ChannelCount() <= 0 {
+		return nil, errors.New("decoder must report a positive sample rate and channel count")
+	}
+
+	channel := &Channel{
+		Volume: 1.0,
+		Pan:    0.0,
+		PlaybackOptions: &PlaybackOptions{
+			Rate:     1.0,
+			Pitch:    0.0,
+			Streamed: true,
+		},
+		engine: e,
+	}
+	defaultChannelSolo.register(channel)
+
+	result := C.audioplayer_new(unsafe.Pointer(e.nativeEngine.engine))
+	if result.error != nil {
+		return nil, errors.New(C.GoString(result.error))
+	}
+	playerPtr := (*C.AudioPlayer)(result.result)
+	channel.PlaybackOptions.playerPtr = result.result
+
+	if errorStr := C.audioplayer_enable_time_pitch_effects(playerPtr); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to enable time/pitch effects: " + C.GoString(errorStr))
+	}
+
+	nodeResult := C.audioplayer_get_node_ptr(playerPtr)
+	if nodeResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to get player node: " + C.GoString(nodeResult.error))
+	}
+
+	timePitchResult := C.audioplayer_get_time_pitch_node_ptr(playerPtr)
+	if timePitchResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to get time/pitch node: " + C.GoString(timePitchResult.error))
+	}
+
+	channelMixerResult := C.audioengine_create_mixer_node(e.nativeEngine)
+	if channelMixerResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to create channel mixer: " + C.GoString(channelMixerResult.error))
+	}
+	channel.mixerNodePtr = channelMixerResult.result
+
+	if errorStr := C.audioengine_attach(e.nativeEngine, nodeResult.result); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to attach player to engine: " + C.GoString(errorStr))
+	}
+	if errorStr := C.audioengine_attach(e.nativeEngine, timePitchResult.result); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to attach time/pitch unit to engine: " + C.GoString(errorStr))
+	}
+	if errorStr := C.audioengine_attach(e.nativeEngine, channelMixerResult.result); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to attach channel mixer to engine: " + C.GoString(errorStr))
+	}
+
+	if errorStr := C.audioengine_connect(e.nativeEngine, nodeResult.result, timePitchResult.result, 0, 0); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to connect player to time/pitch unit: " + C.GoString(errorStr))
+	}
+	if errorStr := C.audioengine_connect(e.nativeEngine, timePitchResult.result, channelMixerResult.result, 0, 0); errorStr != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to connect time/pitch unit to channel mixer: " + C.GoString(errorStr))
+	}
+
+	mainMixerResult := C.audioengine_main_mixer_node(e.nativeEngine)
+	if mainMixerResult.error != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to get main mixer: " + C.GoString(mainMixerResult.error))
+	}
+
+	busIndex, err := e.AllocateBusForChannel(channel)
+	if err != nil {
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to allocate bus for channel: " + err.Error())
+	}
+
+	if errorStr := C.audioengine_connect(e.nativeEngine, channelMixerResult.result, mainMixerResult.result, 0, C.int(busIndex)); errorStr != nil {
+		e.FreeBusForChannel(channel)
+		C.audioplayer_destroy(playerPtr)
+		return nil, errors.New("failed to connect channel mixer to main mixer: " + C.GoString(errorStr))
+	}
+
+	if err := e.runSync(context.Background(), func(ctx context.Context) error {
+		e.Channels = append(e.Channels, channel)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	channel.startStreamFeeder(decoder)
+	return channel, nil
+}
+
+// startStreamFeeder launches the goroutine that keeps the native player
+// streamRingBuffers chunks ahead of playback, pulling from decoder until
+// it returns io.EOF or Destroy stops the feeder via streamStop.
+func (c *Channel) startStreamFeeder(decoder StreamDecoder) {
+	c.PlaybackOptions.streamStop = make(chan struct{})
+	c.PlaybackOptions.streamDone = make(chan struct{})
+
+	go func() {
+		defer close(c.PlaybackOptions.streamDone)
+		if closer, ok := decoder.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		frameSize := decoder.ChannelCount()
+		buf := make([]float32, streamBufferFrames*frameSize)
+		pending := make(chan struct{}, streamRingBuffers)
+
+		for {
+			select {
+			case <-c.PlaybackOptions.streamStop:
+				return
+			case pending <- struct{}{}:
+			}
+
+			frames, readErr := decoder.ReadFrames(buf)
+			if frames > 0 {
+				playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
+				errorStr := C.audioplayer_schedule_buffer(
+					playerPtr,
+					(*C.float)(unsafe.Pointer(&buf[0])),
+					C.int(frames),
+					C.int(decoder.ChannelCount()),
+					C.int(decoder.SampleRate()),
+				)
+				if errorStr != nil {
+					return
+				}
+			}
+			<-pending
+
+			if readErr != nil {
+				return
+			}
+		}
+	}()
+}
+
+// stopStreamFeeder signals the feeder goroutine to exit and waits for it,
+// called from DestroyChannel so a streamed channel's decoder isn't left
+// running after its native player is torn down.
+func (po *PlaybackOptions) stopStreamFeeder() {
+	if !po.Streamed || po.streamStop == nil {
+		return
+	}
+	po.streamOnce.Do(func() {
+		close(po.streamStop)
+		<-po.streamDone
+	})
+}
@@ -0,0 +1,42 @@
+package engine
+
+import "testing"
+
+type fakeDecoder struct {
+	sampleRate int
+	channels   int
+}
+
+func (d fakeDecoder) ReadFrames(buf []float32) (int, error) { return 0, nil }
+func (d fakeDecoder) SampleRate() int                        { return d.sampleRate }
+func (d fakeDecoder) ChannelCount() int                      { return d.channels }
+
+func TestCreateStreamPlaybackChannelRejectsUninitializedEngine(t *testing.T) {
+	var e Engine
+	if _, err := e.CreateStreamPlaybackChannel(fakeDecoder{sampleRate: 44100, channels: 2}); err == nil {
+		t.Error("expected an error creating a stream channel with no native engine")
+	}
+}
+
+func TestCreateStreamPlaybackChannelRejectsNilDecoder(t *testing.T) {
+	var e Engine
+	e.nativeEngine = nil
+	if _, err := e.CreateStreamPlaybackChannel(nil); err == nil {
+		t.Error("expected an error creating a stream channel with a nil decoder")
+	}
+}
+
+func TestCreateStreamPlaybackChannelRejectsInvalidDecoderFormat(t *testing.T) {
+	var e Engine
+	if _, err := e.CreateStreamPlaybackChannel(fakeDecoder{sampleRate: 0, channels: 2}); err == nil {
+		t.Error("expected an error creating a stream channel with a zero sample rate")
+	}
+	if _, err := e.CreateStreamPlaybackChannel(fakeDecoder{sampleRate: 44100, channels: 0}); err == nil {
+		t.Error("expected an error creating a stream channel with a zero channel count")
+	}
+}
+
+func TestStopStreamFeederNoopWithoutStreamedOptions(t *testing.T) {
+	opts := &PlaybackOptions{}
+	opts.stopStreamFeeder() // must not block or panic when Streamed is false
+}
@@ -0,0 +1,49 @@
+package tapnet
+
+import "sync/atomic"
+
+// defaultFrameRingSlots mirrors avaudio/tap's defaultSubscriptionRingSlots:
+// enough headroom to absorb a brief stall in a client's pump goroutine (a
+// slow network write) without growing unbounded for a caller that didn't
+// set RingFrames.
+const defaultFrameRingSlots = 8
+
+// frameRing is a lock-free single-producer/single-consumer ring of
+// pre-encoded tapnet frames: Server.dispatch (the tap's shared Subscribe
+// callback) is the sole producer for a given client, and that client's own
+// pump goroutine is the sole consumer - directly mirroring
+// avaudio/tap/subscribe.go's subscriptionRing.
+type frameRing struct {
+	slots [][]byte
+	head  uint64 // next slot pump will read
+	tail  uint64 // next slot dispatch will write
+}
+
+func newFrameRing(size int) *frameRing {
+	if size < 2 {
+		size = defaultFrameRingSlots
+	}
+	return &frameRing{slots: make([][]byte, size)}
+}
+
+func (r *frameRing) push(frame []byte) bool {
+	tail := atomic.LoadUint64(&r.tail)
+	head := atomic.LoadUint64(&r.head)
+	if tail-head >= uint64(len(r.slots)) {
+		return false // full; caller counts this as a dropped frame
+	}
+	r.slots[tail%uint64(len(r.slots))] = frame
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+func (r *frameRing) pop() ([]byte, bool) {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if head == tail {
+		return nil, false
+	}
+	frame := r.slots[head%uint64(len(r.slots))]
+	atomic.StoreUint64(&r.head, head+1)
+	return frame, true
+}
@@ -0,0 +1,354 @@
+// Package tapnet broadcasts a tap's PCM stream to any number of remote
+// listeners over TCP or WebSocket, the network-facing counterpart to
+// engine/recorder's direct-to-file writer: both subscribe to a *tap.Tap
+// and turn its blocks into bytes somewhere else, off the audio thread,
+// without letting a slow consumer (a stalled disk there, a slow network
+// client here) ever reach back into the render path.
+package tapnet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/shaban/macaudio/avaudio/tap"
+)
+
+// Protocol selects the transport Serve listens on.
+type Protocol int
+
+const (
+	TCP Protocol = iota
+	WebSocket
+)
+
+// Codec turns one PCM block into the bytes written after a frame's header.
+// PCMFloat32LE is the only implementation today; the interface exists so a
+// caller can plug in Opus or FLAC encoding later without changing Serve's
+// framing or fan-out.
+type Codec interface {
+	Encode(pcm []float32) ([]byte, error)
+}
+
+// PCMFloat32LE encodes PCM as raw little-endian float32 samples, matching
+// the samples tap.Subscribe itself delivers - no transcoding, just framing.
+var PCMFloat32LE Codec = pcmFloat32LE{}
+
+type pcmFloat32LE struct{}
+
+func (pcmFloat32LE) Encode(pcm []float32) ([]byte, error) {
+	buf := make([]byte, len(pcm)*4)
+	for i, s := range pcm {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(s))
+	}
+	return buf, nil
+}
+
+// frameMagic identifies a tapnet frame header, so a consumer can sanity-
+// check it's reading a tapnet stream rather than something else entirely.
+const frameMagic uint32 = 0x54415031 // "TAP1"
+
+// frameHeaderSize is the byte length of magic + sampleRate + channels +
+// frames + hostTimeNs.
+const frameHeaderSize = 4 + 8 + 2 + 4 + 8
+
+// Options configures Serve.
+type Options struct {
+	Addr       string
+	Protocol   Protocol
+	Codec      Codec // nil defaults to PCMFloat32LE
+	MaxClients int   // <= 0 means unlimited
+	RingFrames int   // per-client ring capacity in blocks; <= 0 uses tap.SubscribeOptions' own default
+}
+
+// ClientStats is one connected client's running counters, as reported by
+// Server.Stats.
+type ClientStats struct {
+	Addr      string
+	BytesSent uint64
+	Dropped   uint64
+}
+
+// Stats is Server's running counters, as reported by Server.Stats.
+type Stats struct {
+	Clients []ClientStats
+}
+
+// Server broadcasts one Tap's PCM stream to every client connected to its
+// listener.
+type Server struct {
+	opts     Options
+	source   *tap.Tap
+	listener net.Listener
+	subID    tap.SubscriptionID
+
+	mu      sync.Mutex
+	clients map[uint64]*tapClient
+	nextID  uint64
+	closed  bool
+}
+
+type tapClient struct {
+	id        uint64
+	addr      string
+	conn      frameWriter
+	ring      *frameRing
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	bytesSent uint64
+	dropped   uint64
+}
+
+// frameWriter is the minimum a tapnet client connection needs to support -
+// satisfied by both net.Conn and a thin wrapper around a gorilla
+// websocket.Conn (see wsFrameWriter).
+type frameWriter interface {
+	WriteFrame(b []byte) error
+	Close() error
+}
+
+// Serve starts listening on opts.Addr and broadcasting t's PCM stream to
+// every client that connects, until Server.Close is called.
+func Serve(t *tap.Tap, opts Options) (*Server, error) {
+	if t == nil {
+		return nil, fmt.Errorf("tapnet: tap cannot be nil")
+	}
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("tapnet: addr cannot be empty")
+	}
+	if opts.Codec == nil {
+		opts.Codec = PCMFloat32LE
+	}
+
+	srv := &Server{opts: opts, source: t, clients: make(map[uint64]*tapClient)}
+
+	switch opts.Protocol {
+	case TCP:
+		ln, err := net.Listen("tcp", opts.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("tapnet: failed to listen on %s: %w", opts.Addr, err)
+		}
+		srv.listener = ln
+		go srv.acceptTCP()
+	case WebSocket:
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", srv.serveWebSocket)
+		ln, err := net.Listen("tcp", opts.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("tapnet: failed to listen on %s: %w", opts.Addr, err)
+		}
+		srv.listener = ln
+		go http.Serve(ln, mux)
+	default:
+		return nil, fmt.Errorf("tapnet: unknown protocol %v", opts.Protocol)
+	}
+
+	subID, err := t.Subscribe(srv.dispatch, tap.SubscribeOptions{})
+	if err != nil {
+		srv.listener.Close()
+		return nil, fmt.Errorf("tapnet: failed to subscribe to tap: %w", err)
+	}
+	srv.subID = subID
+
+	return srv, nil
+}
+
+func (s *Server) acceptTCP() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.addClient(conn.RemoteAddr().String(), tcpFrameWriter{conn})
+	}
+}
+
+var wsUpgrader = websocket.Upgrader{}
+
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	s.addClient(r.RemoteAddr, wsFrameWriter{conn})
+}
+
+// addClient registers a newly connected client and starts its dedicated
+// pump goroutine, rejecting the connection outright if MaxClients is
+// already reached.
+func (s *Server) addClient(addr string, conn frameWriter) {
+	s.mu.Lock()
+	if s.closed || (s.opts.MaxClients > 0 && len(s.clients) >= s.opts.MaxClients) {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	s.nextID++
+	id := s.nextID
+	c := &tapClient{
+		id:     id,
+		addr:   addr,
+		conn:   conn,
+		ring:   newFrameRing(s.opts.RingFrames),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	s.clients[id] = c
+	s.mu.Unlock()
+
+	go s.pump(c)
+}
+
+// dispatch is the tap.Subscribe callback: it encodes one PCM block's frame
+// once and fans it out to every connected client's own ring, dropping
+// (and counting, per client) for any client whose ring is full instead of
+// blocking the others or the shared feed.
+func (s *Server) dispatch(pcm []float32, channels int, sampleRate float64, hostTime uint64) {
+	payload, err := s.opts.Codec.Encode(pcm)
+	if err != nil {
+		return
+	}
+
+	frame := make([]byte, frameHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(frame[0:4], frameMagic)
+	binary.LittleEndian.PutUint64(frame[4:12], math.Float64bits(sampleRate))
+	binary.LittleEndian.PutUint16(frame[12:14], uint16(channels))
+	frames := uint32(0)
+	if channels > 0 {
+		frames = uint32(len(pcm) / channels)
+	}
+	binary.LittleEndian.PutUint32(frame[14:18], frames)
+	binary.LittleEndian.PutUint64(frame[18:26], hostTime)
+	copy(frame[frameHeaderSize:], payload)
+
+	s.mu.Lock()
+	clients := make([]*tapClient, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range clients {
+		if !c.ring.push(frame) {
+			atomic.AddUint64(&c.dropped, 1)
+			log.Printf("tapnet: client_overrun addr=%s dropped=%d", c.addr, atomic.LoadUint64(&c.dropped))
+		}
+	}
+}
+
+// pump drains one client's ring and writes each frame to its connection,
+// removing the client the moment a write fails (the client disconnected,
+// or the other end stopped reading).
+func (s *Server) pump(c *tapClient) {
+	defer close(c.doneCh)
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			for {
+				frame, ok := c.ring.pop()
+				if !ok {
+					return
+				}
+				if err := c.conn.WriteFrame(frame); err != nil {
+					return
+				}
+				atomic.AddUint64(&c.bytesSent, uint64(len(frame)))
+			}
+		case <-ticker.C:
+			for {
+				frame, ok := c.ring.pop()
+				if !ok {
+					break
+				}
+				if err := c.conn.WriteFrame(frame); err != nil {
+					s.removeClient(c.id)
+					return
+				}
+				atomic.AddUint64(&c.bytesSent, uint64(len(frame)))
+			}
+		}
+	}
+}
+
+func (s *Server) removeClient(id uint64) {
+	s.mu.Lock()
+	c, ok := s.clients[id]
+	if ok {
+		delete(s.clients, id)
+	}
+	s.mu.Unlock()
+	if ok {
+		c.conn.Close()
+	}
+}
+
+// Stats returns a snapshot of every currently connected client's counters.
+func (s *Server) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clients := make([]ClientStats, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, ClientStats{
+			Addr:      c.addr,
+			BytesSent: atomic.LoadUint64(&c.bytesSent),
+			Dropped:   atomic.LoadUint64(&c.dropped),
+		})
+	}
+	return Stats{Clients: clients}
+}
+
+// Close stops accepting new clients, disconnects every connected one, and
+// unsubscribes from the tap.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	clients := make([]*tapClient, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.clients = make(map[uint64]*tapClient)
+	s.mu.Unlock()
+
+	s.source.Unsubscribe(s.subID)
+	s.listener.Close()
+
+	for _, c := range clients {
+		close(c.stopCh)
+		<-c.doneCh
+		c.conn.Close()
+	}
+	return nil
+}
+
+type tcpFrameWriter struct{ net.Conn }
+
+func (w tcpFrameWriter) WriteFrame(b []byte) error {
+	_, err := w.Write(b)
+	return err
+}
+
+type wsFrameWriter struct{ conn *websocket.Conn }
+
+func (w wsFrameWriter) WriteFrame(b []byte) error {
+	return w.conn.WriteMessage(websocket.BinaryMessage, b)
+}
+
+func (w wsFrameWriter) Close() error {
+	return w.conn.Close()
+}
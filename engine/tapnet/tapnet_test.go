@@ -0,0 +1,97 @@
+package tapnet
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestFrameRingPushPop(t *testing.T) {
+	r := newFrameRing(2)
+
+	if _, ok := r.pop(); ok {
+		t.Fatal("pop on empty ring should report false")
+	}
+	if !r.push([]byte{1}) {
+		t.Fatal("push into empty ring should succeed")
+	}
+	if !r.push([]byte{2}) {
+		t.Fatal("push into ring with one free slot should succeed")
+	}
+	if r.push([]byte{3}) {
+		t.Fatal("push into full ring should fail")
+	}
+
+	frame, ok := r.pop()
+	if !ok || frame[0] != 1 {
+		t.Fatalf("pop = %v, %v; want [1], true", frame, ok)
+	}
+	if !r.push([]byte{3}) {
+		t.Fatal("push after freeing a slot should succeed")
+	}
+}
+
+func TestFrameRingMinimumSize(t *testing.T) {
+	r := newFrameRing(0)
+	if len(r.slots) != defaultFrameRingSlots {
+		t.Fatalf("newFrameRing(0) made a ring of %d slots, want %d", len(r.slots), defaultFrameRingSlots)
+	}
+}
+
+func TestPCMFloat32LEEncodesLittleEndian(t *testing.T) {
+	buf, err := PCMFloat32LE.Encode([]float32{1.5, -2.5})
+	if err != nil {
+		t.Fatalf("Encode returned an error: %v", err)
+	}
+	if len(buf) != 8 {
+		t.Fatalf("Encode produced %d bytes, want 8", len(buf))
+	}
+	got := math.Float32frombits(binary.LittleEndian.Uint32(buf[0:4]))
+	if got != 1.5 {
+		t.Errorf("first sample round-tripped as %v, want 1.5", got)
+	}
+}
+
+func TestServeRejectsInvalidArgs(t *testing.T) {
+	if _, err := Serve(nil, Options{Addr: ":0"}); err == nil {
+		t.Fatal("expected Serve(nil, ...) to fail")
+	}
+}
+
+func TestDispatchBuildsFrameHeaderAndFansOutDrops(t *testing.T) {
+	srv := &Server{opts: Options{Codec: PCMFloat32LE}, clients: make(map[uint64]*tapClient)}
+
+	full := newFrameRing(2)
+	full.push([]byte{0})
+	full.push([]byte{0})
+
+	open := newFrameRing(4)
+	srv.clients[1] = &tapClient{id: 1, ring: open}
+	srv.clients[2] = &tapClient{id: 2, ring: full}
+
+	srv.dispatch([]float32{1, 2, 3, 4}, 2, 48000, 123)
+
+	frame, ok := open.pop()
+	if !ok {
+		t.Fatal("expected the open client's ring to have received a frame")
+	}
+	if magic := binary.LittleEndian.Uint32(frame[0:4]); magic != frameMagic {
+		t.Errorf("frame magic = %#x, want %#x", magic, frameMagic)
+	}
+	if sr := math.Float64frombits(binary.LittleEndian.Uint64(frame[4:12])); sr != 48000 {
+		t.Errorf("frame sampleRate = %v, want 48000", sr)
+	}
+	if ch := binary.LittleEndian.Uint16(frame[12:14]); ch != 2 {
+		t.Errorf("frame channels = %d, want 2", ch)
+	}
+	if frames := binary.LittleEndian.Uint32(frame[14:18]); frames != 2 {
+		t.Errorf("frame frames = %d, want 2", frames)
+	}
+	if ht := binary.LittleEndian.Uint64(frame[18:26]); ht != 123 {
+		t.Errorf("frame hostTimeNs = %d, want 123", ht)
+	}
+
+	if srv.clients[2].dropped != 1 {
+		t.Errorf("expected the full client's ring to have dropped 1 frame, got %d", srv.clients[2].dropped)
+	}
+}
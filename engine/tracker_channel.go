@@ -0,0 +1,134 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// TrackerOptions holds the state for a channel backed by a tracker module
+// (XM/MOD/S3M/IT) played back via libxmp; see avaudio/tracker for the
+// lower-level libxmp wrapper this channel type drives.
+type TrackerOptions struct {
+	ModulePath string `json:"modulePath"`
+
+	trackerPtr unsafe.Pointer `json:"-"`
+}
+
+// IsTracker returns true if this channel plays a tracker module.
+func (c *Channel) IsTracker() bool {
+	return c.TrackerOptions != nil
+}
+
+// CreateTrackerChannel loads modulePath (XM/MOD/S3M/IT) via libxmp and
+// creates a channel that plays it on the main mixer.
+func (e *Engine) CreateTrackerChannel(modulePath string) (*Channel, error) {
+	if e.nativeEngine == nil {
+		return nil, errors.New("engine is not properly initialized")
+	}
+
+	cPath := C.CString(modulePath)
+	defer C.free(unsafe.Pointer(cPath))
+
+	trackerResult := C.audiotracker_load(e.nativeEngine.engine, cPath)
+	if trackerResult.error != nil {
+		return nil, errors.New("failed to load tracker module: " + C.GoString(trackerResult.error))
+	}
+
+	mixerResult := C.audioengine_main_mixer_node(e.nativeEngine)
+	if mixerResult.error != nil {
+		C.audiotracker_destroy(trackerResult.result)
+		return nil, errors.New("failed to get main mixer: " + C.GoString(mixerResult.error))
+	}
+
+	channel := &Channel{
+		Volume: 1.0,
+		Pan:    0.0,
+		TrackerOptions: &TrackerOptions{
+			ModulePath: modulePath,
+			trackerPtr: trackerResult.result,
+		},
+	}
+
+	busIndex, err := e.AllocateBusForChannel(channel)
+	if err != nil {
+		C.audiotracker_destroy(trackerResult.result)
+		return nil, err
+	}
+
+	connectError := C.audiotracker_connect_to_mixer(trackerResult.result, mixerResult.result, C.int(busIndex))
+	if connectError != nil {
+		C.audiotracker_destroy(trackerResult.result)
+		e.FreeBusForChannel(channel)
+		return nil, errors.New("failed to connect tracker to mixer: " + C.GoString(connectError))
+	}
+
+	e.Channels = append(e.Channels, channel)
+
+	return channel, nil
+}
+
+// SetPosition jumps the tracker channel's playback to the given order/row.
+func (c *Channel) SetPosition(order, row int) error {
+	if !c.IsTracker() {
+		return errors.New("not a tracker channel")
+	}
+
+	if errStr := C.audiotracker_set_position(c.TrackerOptions.trackerPtr, C.int(order), C.int(row)); errStr != nil {
+		return errors.New("failed to set tracker position: " + C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetTempo overrides the tracker channel's module tempo in BPM.
+func (c *Channel) SetTempo(bpm int) error {
+	if !c.IsTracker() {
+		return errors.New("not a tracker channel")
+	}
+
+	if errStr := C.audiotracker_set_tempo(c.TrackerOptions.trackerPtr, C.int(bpm)); errStr != nil {
+		return errors.New("failed to set tracker tempo: " + C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetModuleLoop sets how many times the module restarts after reaching its
+// end; 0 disables looping, -1 loops indefinitely.
+func (c *Channel) SetModuleLoop(count int) error {
+	if !c.IsTracker() {
+		return errors.New("not a tracker channel")
+	}
+
+	if errStr := C.audiotracker_set_loop_count(c.TrackerOptions.trackerPtr, C.int(count)); errStr != nil {
+		return errors.New("failed to set tracker loop count: " + C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetChannelMute mutes or unmutes one of the module's pattern channels.
+func (c *Channel) SetChannelMute(patternChannel int, mute bool) error {
+	if !c.IsTracker() {
+		return errors.New("not a tracker channel")
+	}
+
+	if errStr := C.audiotracker_set_channel_mute(c.TrackerOptions.trackerPtr, C.int(patternChannel), C.bool(mute)); errStr != nil {
+		return errors.New("failed to set tracker channel mute: " + C.GoString(errStr))
+	}
+	return nil
+}
+
+// SetChannelSolo solos or un-solos one of the module's pattern channels.
+func (c *Channel) SetChannelSolo(patternChannel int, solo bool) error {
+	if !c.IsTracker() {
+		return errors.New("not a tracker channel")
+	}
+
+	if errStr := C.audiotracker_set_channel_solo(c.TrackerOptions.trackerPtr, C.int(patternChannel), C.bool(solo)); errStr != nil {
+		return errors.New("failed to set tracker channel solo: " + C.GoString(errStr))
+	}
+	return nil
+}
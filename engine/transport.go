@@ -0,0 +1,201 @@
+package engine
+
+/*
+#include "../native/macaudio.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TransportState describes where a playback channel sits in its
+// play/pause/stop lifecycle, modeled after AVPlayer's timeControlStatus and
+// SDL_mixer's channel-paused bookkeeping.
+type TransportState int
+
+const (
+	// Stopped is a playback channel's initial state, and the state Stop
+	// returns it to: the next Play starts from the beginning.
+	Stopped TransportState = iota
+	// Playing means the channel is actively producing audio.
+	Playing
+	// Paused means playback is suspended at its current position; Play
+	// resumes from there rather than restarting.
+	Paused
+	// Transitioning is held only for the duration of the native call a
+	// Play/Pause/Stop is making, so a concurrent TransportState() read
+	// during that window reports it instead of a stale pre-call state.
+	Transitioning
+)
+
+// String renders the state the way logs and UIs display it.
+func (s TransportState) String() string {
+	switch s {
+	case Stopped:
+		return "stopped"
+	case Playing:
+		return "playing"
+	case Paused:
+		return "paused"
+	case Transitioning:
+		return "transitioning"
+	default:
+		return "unknown"
+	}
+}
+
+// TransportEvent is sent to every channel returned by Subscribe whenever a
+// playback channel's TransportState changes.
+type TransportEvent struct {
+	State TransportState `json:"state"`
+	Time  time.Time      `json:"time"`
+}
+
+// transportEventBuffer sizes each Subscribe channel. Buffered so Play/Pause/
+// Stop/Seek never block on a slow listener; a listener that falls behind by
+// more than this many transitions drops the oldest ones rather than stall
+// the channel.
+const transportEventBuffer = 8
+
+// ErrFileNotFound is returned by Play when the channel's file path doesn't
+// exist on disk.
+var ErrFileNotFound = errors.New("engine: audio file not found")
+
+// ErrUnsupportedFormat is returned by Play when the channel's file path has
+// an extension the native player doesn't decode.
+var ErrUnsupportedFormat = errors.New("engine: unsupported audio file format")
+
+// supportedPlaybackExtensions mirrors AudioPlayer.LoadFile's documented
+// format support (WAV, AIFF, MP3, AAC, M4A, FLAC via AVAudioFile on macOS
+// 11+), plus CAF since engine/record can produce it.
+var supportedPlaybackExtensions = map[string]struct{}{
+	".wav": {}, ".aif": {}, ".aiff": {}, ".mp3": {}, ".aac": {}, ".m4a": {}, ".flac": {}, ".caf": {},
+}
+
+// checkPlaybackFile validates a playback channel's file path before Play
+// hands it to the native player, so a bad path fails fast with a typed,
+// programmatically-checkable error instead of surfacing deep inside a C
+// string once the native layer gets around to opening it.
+func checkPlaybackFile(path string) error {
+	if path == "" {
+		return ErrFileNotFound
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if _, ok := supportedPlaybackExtensions[ext]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, path)
+	}
+	return nil
+}
+
+// TransportState returns the playback channel's current lifecycle state.
+// Non-playback channels always report Stopped.
+func (c *Channel) TransportState() TransportState {
+	if !c.IsPlayback() {
+		return Stopped
+	}
+	opts := c.PlaybackOptions
+	opts.transportMu.Lock()
+	defer opts.transportMu.Unlock()
+	return opts.transportState
+}
+
+// Subscribe returns a channel that receives a TransportEvent every time this
+// playback channel's TransportState changes via Play, Pause or Stop. There
+// is no Unsubscribe - a caller that no longer cares should simply stop
+// reading and let the channel be garbage collected with it.
+func (c *Channel) Subscribe() <-chan TransportEvent {
+	ch := make(chan TransportEvent, transportEventBuffer)
+	if !c.IsPlayback() {
+		return ch
+	}
+	opts := c.PlaybackOptions
+	opts.transportMu.Lock()
+	opts.subscribers = append(opts.subscribers, ch)
+	opts.transportMu.Unlock()
+	return ch
+}
+
+// setTransportState records s as the channel's current state, fans it out
+// to Subscribe's listeners, and returns the state that was current just
+// before the call (so withTransition can restore it on failure).
+func (c *Channel) setTransportState(s TransportState) TransportState {
+	opts := c.PlaybackOptions
+	opts.transportMu.Lock()
+	previous := opts.transportState
+	opts.transportState = s
+	subs := opts.subscribers
+	opts.transportMu.Unlock()
+
+	event := TransportEvent{State: s, Time: time.Now()}
+	for _, sub := range subs {
+		select {
+		case sub <- event:
+		default: // listener is backed up; drop rather than block the transport call
+		}
+	}
+	return previous
+}
+
+// withTransition marks the channel Transitioning for the duration of fn (the
+// native call), then settles on final once fn succeeds, or reverts to the
+// pre-call state if it fails - mirroring AVPlayer's momentary
+// .waitingToPlayAtSpecifiedRate between a transport call and its effect.
+func (c *Channel) withTransition(final TransportState, fn func() error) error {
+	previous := c.setTransportState(Transitioning)
+	if err := fn(); err != nil {
+		c.setTransportState(previous)
+		return err
+	}
+	c.setTransportState(final)
+	return nil
+}
+
+// Pause suspends playback at its current position. A subsequent Play
+// resumes from there rather than restarting - unlike Stop.
+func (c *Channel) Pause() error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if c.PlaybackOptions.playerPtr == nil {
+		return errors.New("no native player available")
+	}
+
+	return c.withTransition(Paused, func() error {
+		playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
+		errorStr := C.audioplayer_pause(playerPtr)
+		if errorStr != nil {
+			return errors.New("failed to pause playback: " + C.GoString(errorStr))
+		}
+		return nil
+	})
+}
+
+// Seek jumps playback to position within the loaded file without changing
+// whether the channel is playing or paused.
+func (c *Channel) Seek(position time.Duration) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if position < 0 {
+		return errors.New("seek position cannot be negative")
+	}
+	if c.PlaybackOptions.playerPtr == nil {
+		return errors.New("no native player available")
+	}
+
+	playerPtr := (*C.AudioPlayer)(c.PlaybackOptions.playerPtr)
+	errorStr := C.audioplayer_seek_to_time(playerPtr, C.double(position.Seconds()))
+	if errorStr != nil {
+		return errors.New("failed to seek: " + C.GoString(errorStr))
+	}
+	return nil
+}
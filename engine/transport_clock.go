@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// TempoPoint marks a tempo/time-signature change at a sample position in
+// the engine-wide transport, analogous to Ardour's tempo map.
+type TempoPoint struct {
+	TimeSamples int64   `json:"timeSamples"`
+	BPM         float64 `json:"bpm"`
+	TimeSigNum  int     `json:"timeSigNum"`
+	TimeSigDen  int     `json:"timeSigDen"`
+
+	// startBeat is this point's beat position, derived by SetTempoMap from
+	// every prior point's BPM and duration, so CurrentBeat doesn't have to
+	// walk the whole map on every call.
+	startBeat float64
+}
+
+// defaultTempoPoint is the flat 120 BPM, 4/4 tempo a TransportClock starts
+// with before SetTempoMap is ever called.
+var defaultTempoPoint = TempoPoint{BPM: 120, TimeSigNum: 4, TimeSigDen: 4}
+
+// TransportClock is the engine-wide sample-accurate playhead and tempo map
+// that tempo-synced AUs (delays, arpeggiators) read through
+// kAudioUnitProperty_HostCallbacks. Unlike TransportState (transport.go),
+// which tracks one playback channel's play/pause/stop lifecycle,
+// TransportClock tracks the whole engine's musical position.
+type TransportClock struct {
+	mu         sync.Mutex
+	rolling    bool
+	sample     int64
+	sampleRate int
+	tempoMap   []TempoPoint // sorted by TimeSamples, always starts at TimeSamples 0
+}
+
+// Transport lazily creates and returns the engine's TransportClock.
+func (e *Engine) Transport() *TransportClock {
+	if e.transport == nil {
+		e.transport = &TransportClock{sampleRate: e.SampleRate, tempoMap: []TempoPoint{defaultTempoPoint}}
+	}
+	return e.transport
+}
+
+// Play starts the transport rolling from its current sample position.
+//
+// TODO: register kAudioUnitProperty_HostCallbacks on every plugin so
+// tempo-sync AUs follow this clock, once plugin chains hold live AudioUnit
+// instances (see the "Apply ... to actual AudioUnit" notes in plugins.go).
+func (tc *TransportClock) Play() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.rolling = true
+}
+
+// Stop halts the transport in place; the next Play resumes from the same
+// sample position. Use Locate to move the playhead.
+func (tc *TransportClock) Stop() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.rolling = false
+}
+
+// Locate moves the playhead to sampleFrame without changing whether the
+// transport is rolling.
+func (tc *TransportClock) Locate(sampleFrame int64) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.sample = sampleFrame
+}
+
+// IsRolling reports whether the transport is currently playing.
+func (tc *TransportClock) IsRolling() bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.rolling
+}
+
+// CurrentSample returns the transport's current playhead position.
+func (tc *TransportClock) CurrentSample() int64 {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.sample
+}
+
+// SetTempoMap replaces the transport's tempo map. points need not be
+// sorted; SetTempoMap sorts them by TimeSamples, forces a point at
+// TimeSamples 0 (defaulting to defaultTempoPoint's BPM/signature if the
+// caller didn't supply one), and precomputes each point's starting beat so
+// CurrentBeat is a binary search rather than a walk of the whole map.
+func (tc *TransportClock) SetTempoMap(points []TempoPoint) {
+	sorted := make([]TempoPoint, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TimeSamples < sorted[j].TimeSamples })
+	if len(sorted) == 0 || sorted[0].TimeSamples != 0 {
+		sorted = append([]TempoPoint{defaultTempoPoint}, sorted...)
+	}
+
+	tc.mu.Lock()
+	sampleRate := tc.sampleRate
+	tc.mu.Unlock()
+
+	beat := 0.0
+	for i := range sorted {
+		sorted[i].startBeat = beat
+		if i+1 < len(sorted) && sampleRate > 0 {
+			elapsedSamples := float64(sorted[i+1].TimeSamples - sorted[i].TimeSamples)
+			beat += elapsedSamples * sorted[i].BPM / 60 / float64(sampleRate)
+		}
+	}
+
+	tc.mu.Lock()
+	tc.tempoMap = sorted
+	tc.mu.Unlock()
+}
+
+// CurrentBeat returns the transport's current musical position in beats,
+// computed from the tempo map at the engine's sample rate.
+func (tc *TransportClock) CurrentBeat() float64 {
+	tc.mu.Lock()
+	sample := tc.sample
+	sampleRate := tc.sampleRate
+	tempoMap := tc.tempoMap
+	tc.mu.Unlock()
+
+	if sampleRate <= 0 {
+		return tempoMap[0].startBeat
+	}
+
+	i := sort.Search(len(tempoMap), func(i int) bool { return tempoMap[i].TimeSamples > sample }) - 1
+	if i < 0 {
+		i = 0
+	}
+	point := tempoMap[i]
+	elapsedSamples := float64(sample - point.TimeSamples)
+	return point.startBeat + elapsedSamples*point.BPM/60/float64(sampleRate)
+}
+
+// NextBeatBoundary returns the next sample position at or after the current
+// playhead that falls on a multiple of beats, at the tempo in effect at the
+// current playhead. Callers that want a playback channel's start locked to
+// a bar/beat boundary (see PlaybackOptions) compute it here and pass the
+// result to Channel.Seek before calling Play.
+func (tc *TransportClock) NextBeatBoundary(beats float64) int64 {
+	tc.mu.Lock()
+	sample := tc.sample
+	sampleRate := tc.sampleRate
+	tempoMap := tc.tempoMap
+	tc.mu.Unlock()
+
+	if sampleRate <= 0 || beats <= 0 {
+		return sample
+	}
+
+	i := sort.Search(len(tempoMap), func(i int) bool { return tempoMap[i].TimeSamples > sample }) - 1
+	if i < 0 {
+		i = 0
+	}
+	point := tempoMap[i]
+	samplesPerBeat := 60 / point.BPM * float64(sampleRate)
+	elapsedBeats := float64(sample-point.TimeSamples) / samplesPerBeat
+
+	// Round up to the next multiple of beats, treating a position that's
+	// already (near enough) on a boundary as the boundary itself rather
+	// than jumping a full period ahead.
+	const epsilon = 1e-9
+	boundaryBeats := math.Ceil(elapsedBeats/beats-epsilon) * beats
+	return point.TimeSamples + int64(boundaryBeats*samplesPerBeat)
+}
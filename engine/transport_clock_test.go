@@ -0,0 +1,76 @@
+package engine
+
+import "testing"
+
+func TestTransportClockPlayStopLocate(t *testing.T) {
+	e := &Engine{SampleRate: 48000}
+	tc := e.Transport()
+
+	if tc.IsRolling() {
+		t.Fatal("expected a fresh transport to not be rolling")
+	}
+	tc.Play()
+	if !tc.IsRolling() {
+		t.Fatal("expected IsRolling after Play")
+	}
+	tc.Locate(48000)
+	if got := tc.CurrentSample(); got != 48000 {
+		t.Fatalf("expected CurrentSample 48000 after Locate, got %d", got)
+	}
+	tc.Stop()
+	if tc.IsRolling() {
+		t.Fatal("expected IsRolling=false after Stop")
+	}
+	if got := tc.CurrentSample(); got != 48000 {
+		t.Fatalf("expected Stop to leave the playhead in place, got %d", got)
+	}
+}
+
+func TestTransportClockCurrentBeatFlatTempo(t *testing.T) {
+	e := &Engine{SampleRate: 48000}
+	tc := e.Transport() // defaults to 120 BPM
+
+	tc.Locate(48000) // 1 second = 2 beats at 120 BPM
+	if got := tc.CurrentBeat(); got < 1.999 || got > 2.001 {
+		t.Fatalf("expected ~2 beats at 120 BPM after 1 second, got %v", got)
+	}
+}
+
+func TestTransportClockCurrentBeatTempoMap(t *testing.T) {
+	e := &Engine{SampleRate: 48000}
+	tc := e.Transport()
+	tc.SetTempoMap([]TempoPoint{
+		{TimeSamples: 0, BPM: 120, TimeSigNum: 4, TimeSigDen: 4},
+		{TimeSamples: 48000, BPM: 60, TimeSigNum: 4, TimeSigDen: 4}, // after 2 beats, tempo halves
+	})
+
+	tc.Locate(48000)
+	if got := tc.CurrentBeat(); got < 1.999 || got > 2.001 {
+		t.Fatalf("expected beat 2 exactly at the tempo change, got %v", got)
+	}
+
+	tc.Locate(96000) // 1 more second at 60 BPM = 1 more beat
+	if got := tc.CurrentBeat(); got < 2.999 || got > 3.001 {
+		t.Fatalf("expected beat 3 one second into the 60 BPM segment, got %v", got)
+	}
+}
+
+func TestTransportClockNextBeatBoundary(t *testing.T) {
+	e := &Engine{SampleRate: 48000}
+	tc := e.Transport() // 120 BPM: 1 beat = 24000 samples
+
+	tc.Locate(1000)
+	if got := tc.NextBeatBoundary(1); got != 24000 {
+		t.Fatalf("expected the next 1-beat boundary at 24000, got %d", got)
+	}
+
+	tc.Locate(24000)
+	if got := tc.NextBeatBoundary(1); got != 24000 {
+		t.Fatalf("expected a position already on the boundary to report itself, got %d", got)
+	}
+
+	tc.Locate(0)
+	if got := tc.NextBeatBoundary(4); got != 0 {
+		t.Fatalf("expected sample 0 to already be a 4-beat (bar) boundary, got %d", got)
+	}
+}
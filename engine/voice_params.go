@@ -0,0 +1,171 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// detuneCentsLimit bounds SetDetune's fine pitch offset.
+const detuneCentsLimit = float32(100)
+
+// ErrInvalidLoopRange is returned when a loop region's frame bounds are
+// nonsensical (negative, or end at or before start).
+var ErrInvalidLoopRange = errors.New("engine: invalid loop range")
+
+// ValidateDetune checks cents against ±detuneCentsLimit, rejecting NaN/Inf
+// the same way ValidatePitch does for the coarse pitch parameter it layers
+// onto.
+func ValidateDetune(cents float32) error {
+	if cents != cents { // NaN
+		return fmt.Errorf("detune must be a finite number, got NaN")
+	}
+	if cents < -detuneCentsLimit || cents > detuneCentsLimit {
+		return fmt.Errorf("detune %.1f cents exceeds the ±%.0f cent range", cents, detuneCentsLimit)
+	}
+	return nil
+}
+
+// ValidateLoopRange checks that endFrame comes strictly after startFrame and
+// neither is negative.
+func ValidateLoopRange(startFrame, endFrame int64) error {
+	if startFrame < 0 || endFrame < 0 {
+		return fmt.Errorf("%w: frame offsets cannot be negative (%d, %d)", ErrInvalidLoopRange, startFrame, endFrame)
+	}
+	if endFrame <= startFrame {
+		return fmt.Errorf("%w: end frame %d must come after start frame %d", ErrInvalidLoopRange, endFrame, startFrame)
+	}
+	return nil
+}
+
+// SetLoopRange sets the channel's loop region by frame offset into the file.
+// It's independent of LoopIntro's "play once, then loop the body" shape -
+// see PlaybackOptions.LoopRange. The range takes effect once SetLoopEnabled
+// engages it.
+func (c *Channel) SetLoopRange(startFrame, endFrame int64) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+
+	if err := ValidateLoopRange(startFrame, endFrame); err != nil {
+		return err
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.PlaybackOptions.LoopRange = &LoopRegion{Start: uint64(startFrame), End: uint64(endFrame)}
+		return nil
+	})
+}
+
+// SetLoopEnabled toggles whether LoopRange is active. Enabling it without a
+// LoopRange set is an error - there would be nothing to loop.
+func (c *Channel) SetLoopEnabled(enabled bool) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		if enabled && c.PlaybackOptions.LoopRange == nil {
+			return errors.New("cannot enable loop: no LoopRange set (call SetLoopRange first)")
+		}
+		c.PlaybackOptions.LoopEnabled = enabled
+		return nil
+	})
+}
+
+// SetFadeIn configures the fade-in duration Play applies automatically each
+// time it starts playback. A zero duration (the default) disables the
+// automatic fade - Play starts at full volume immediately.
+func (c *Channel) SetFadeIn(d time.Duration) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if d < 0 {
+		return errors.New("fade-in duration cannot be negative")
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.PlaybackOptions.FadeInDuration = d
+		return nil
+	})
+}
+
+// SetFadeOut configures the fade-out duration callers can apply via
+// FadeOut(c.PlaybackOptions.FadeOutDuration) before stopping the channel. A
+// zero duration (the default) disables it.
+func (c *Channel) SetFadeOut(d time.Duration) error {
+	if !c.IsPlayback() {
+		return errors.New("channel is not a playback channel")
+	}
+	if d < 0 {
+		return errors.New("fade-out duration cannot be negative")
+	}
+
+	return c.runOnEngine(func(ctx context.Context) error {
+		c.PlaybackOptions.FadeOutDuration = d
+		return nil
+	})
+}
+
+// VoiceParam identifies a per-voice modulation parameter for SetParam,
+// following the voice-parameter model common to sampler/synth SDKs: new
+// parameters (a reverb send, say) get a new VoiceParam constant instead of
+// a new Channel method.
+type VoiceParam int
+
+const (
+	ParamPitch VoiceParam = iota
+	ParamDetune
+	ParamRate
+	ParamLoopEnabled
+	ParamFadeIn
+	ParamFadeOut
+)
+
+// SetParam is the generic entry point VoiceParam documents: it dispatches
+// to the same typed setter SetPitch/SetDetune/SetRate/SetLoopEnabled/
+// SetFadeIn/SetFadeOut would call directly, returning an error if param is
+// unknown or value doesn't match the parameter's expected type.
+func (c *Channel) SetParam(param VoiceParam, value interface{}) error {
+	switch param {
+	case ParamPitch:
+		v, ok := value.(float32)
+		if !ok {
+			return fmt.Errorf("ParamPitch expects a float32, got %T", value)
+		}
+		return c.SetPitch(v)
+	case ParamDetune:
+		v, ok := value.(float32)
+		if !ok {
+			return fmt.Errorf("ParamDetune expects a float32, got %T", value)
+		}
+		return c.SetDetune(v)
+	case ParamRate:
+		v, ok := value.(float32)
+		if !ok {
+			return fmt.Errorf("ParamRate expects a float32, got %T", value)
+		}
+		return c.SetPlaybackRate(v)
+	case ParamLoopEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("ParamLoopEnabled expects a bool, got %T", value)
+		}
+		return c.SetLoopEnabled(v)
+	case ParamFadeIn:
+		v, ok := value.(time.Duration)
+		if !ok {
+			return fmt.Errorf("ParamFadeIn expects a time.Duration, got %T", value)
+		}
+		return c.SetFadeIn(v)
+	case ParamFadeOut:
+		v, ok := value.(time.Duration)
+		if !ok {
+			return fmt.Errorf("ParamFadeOut expects a time.Duration, got %T", value)
+		}
+		return c.SetFadeOut(v)
+	default:
+		return fmt.Errorf("unknown voice param %d", param)
+	}
+}
@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/shaban/macaudio/devices"
 )
 
 // TestVolumeConstraints tests volume validation and edge cases
@@ -268,6 +271,7 @@ func TestChannelVolumeConstraints(t *testing.T) {
 	tests := []struct {
 		name        string
 		volume      float32
+		allowGain   bool
 		expectValid bool
 		description string
 	}{
@@ -298,14 +302,21 @@ func TestChannelVolumeConstraints(t *testing.T) {
 		{
 			name:        "NegativeVolume",
 			volume:      -0.5,
-			expectValid: false, // TODO: Should be invalid
+			expectValid: false,
 			description: "Negative volume - phase inversion?",
 		},
+		{
+			name:        "AmplifiedVolumeWithoutOptIn",
+			volume:      2.0,
+			expectValid: false,
+			description: "2x amplification - rejected by the default, unity-capped path",
+		},
 		{
 			name:        "AmplifiedVolume",
 			volume:      2.0,
-			expectValid: false, // TODO: Could cause clipping
-			description: "2x amplification - potential clipping",
+			allowGain:   true,
+			expectValid: true,
+			description: "2x amplification (~+6dB) - accepted once the caller opts in via allowGain",
 		},
 		{
 			name:        "ExtremeAmplification",
@@ -323,6 +334,7 @@ func TestChannelVolumeConstraints(t *testing.T) {
 				// Test valid values - should succeed in channel creation and setting
 				inputConfig := TestChannelConfig{
 					Volume:      tt.volume,
+					AllowGain:   tt.allowGain,
 					Pan:         0.0,
 					PluginCount: 0,
 					UseRealFile: true,
@@ -338,6 +350,7 @@ func TestChannelVolumeConstraints(t *testing.T) {
 				// Test with playback channel too
 				playbackConfig := TestChannelConfig{
 					Volume:      tt.volume,
+					AllowGain:   tt.allowGain,
 					Pan:         0.0,
 					PluginCount: 0,
 					UseRealFile: true,
@@ -394,6 +407,148 @@ func TestChannelVolumeConstraints(t *testing.T) {
 	}
 }
 
+// TestVolumeDBConversion verifies SetVolumeDB/GetVolumeDB round-trip through
+// the standard 10^(db/20) conversion and that the +12dB ceiling and opt-in
+// gain path behave consistently with SetVolumeLinear.
+func TestVolumeDBConversion(t *testing.T) {
+	_, inputDevice := TestDeviceSetup(t)
+	if inputDevice == nil {
+		t.Skip("No input devices available for testing")
+	}
+
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestInputChannel(t, engine, TestChannelConfig{Volume: 1.0, Pan: 0.0, UseRealFile: true})
+
+	if err := channel.SetVolumeDB(0); err != nil {
+		t.Fatalf("SetVolumeDB(0) failed: %v", err)
+	}
+	if v, err := channel.GetVolume(); err != nil || v < 0.99 || v > 1.01 {
+		t.Errorf("expected 0dB to be ~unity gain, got %v (err %v)", v, err)
+	}
+
+	if err := channel.SetVolumeDB(6); err != nil {
+		t.Fatalf("SetVolumeDB(6) failed: %v", err)
+	}
+	if v, err := channel.GetVolume(); err != nil || v < 1.9 || v > 2.1 {
+		t.Errorf("expected +6dB to be ~2.0 linear, got %v (err %v)", v, err)
+	}
+
+	if err := channel.SetVolumeDB(13); err == nil {
+		t.Error("expected +13dB to be rejected (above the +12dB ceiling)")
+	}
+
+	if err := channel.SetVolumeDB(channelSilenceDB); err != nil {
+		t.Fatalf("SetVolumeDB(silence) failed: %v", err)
+	}
+	if db, err := channel.GetVolumeDB(); err != nil || db != channelSilenceDB {
+		t.Errorf("expected GetVolumeDB to report the silence floor %v, got %v (err %v)", channelSilenceDB, db, err)
+	}
+}
+
+// TestMuteUnmuteVolumeTarget verifies that Mute/Unmute track mute state
+// independently of Volume: SetVolume while muted updates the stored target
+// rather than being lost, and Unmute restores that target to the mixer.
+func TestMuteUnmuteVolumeTarget(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+
+	if err := channel.SetVolume(0.5); err != nil {
+		t.Fatalf("SetVolume(0.5) failed: %v", err)
+	}
+	if err := channel.Mute(); err != nil {
+		t.Fatalf("Mute failed: %v", err)
+	}
+	if !channel.IsMuted() {
+		t.Fatal("expected IsMuted() to be true after Mute")
+	}
+
+	// Changing the target volume while muted should update Volume but
+	// keep the mixer silent.
+	if err := channel.SetVolume(0.9); err != nil {
+		t.Fatalf("SetVolume(0.9) while muted failed: %v", err)
+	}
+	if channel.Volume != 0.9 {
+		t.Errorf("expected Volume target to update to 0.9 while muted, got %v", channel.Volume)
+	}
+	if v, err := channel.GetVolume(); err != nil || v != 0 {
+		t.Errorf("expected mixer to report silence while muted, got %v (err %v)", v, err)
+	}
+
+	if err := channel.Unmute(); err != nil {
+		t.Fatalf("Unmute failed: %v", err)
+	}
+	if channel.IsMuted() {
+		t.Error("expected IsMuted() to be false after Unmute")
+	}
+	if v, err := channel.GetVolume(); err != nil || v < 0.89 || v > 0.91 {
+		t.Errorf("expected Unmute to restore the 0.9 target, got %v (err %v)", v, err)
+	}
+}
+
+// TestSoloComposesAcrossChannels verifies that soloing one channel silences
+// every other registered channel - playback or input - at the mixer while
+// leaving their Volume fields untouched, and that clearing the last solo
+// restores every channel.
+func TestSoloComposesAcrossChannels(t *testing.T) {
+	_, inputDevice := TestDeviceSetup(t)
+	if inputDevice == nil {
+		t.Skip("No input devices available for testing")
+	}
+
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	a := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+	b := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+	in := CreateTestInputChannel(t, engine, DefaultInputChannelConfig())
+
+	wantAVolume, wantBVolume, wantInVolume := a.Volume, b.Volume, in.Volume
+
+	if err := a.Solo(); err != nil {
+		t.Fatalf("Solo on a failed: %v", err)
+	}
+	if !a.IsSolo() {
+		t.Error("expected a.IsSolo() to be true")
+	}
+	if b.IsSolo() || in.IsSolo() {
+		t.Error("soloing a should not solo b or in")
+	}
+
+	if v, err := b.GetVolume(); err != nil || v != 0 {
+		t.Errorf("expected b to be solo-muted to silence, got %v (err %v)", v, err)
+	}
+	if b.Volume != wantBVolume {
+		t.Errorf("expected b.Volume to remain %v while solo-muted, got %v", wantBVolume, b.Volume)
+	}
+	if in.Volume != wantInVolume {
+		t.Errorf("expected in.Volume to remain %v while solo-muted, got %v", wantInVolume, in.Volume)
+	}
+	if !in.soloMuted {
+		t.Error("expected the input channel (no mixer node) to still be marked solo-muted")
+	}
+
+	if v, err := a.GetVolume(); err != nil || v < wantAVolume-0.01 || v > wantAVolume+0.01 {
+		t.Errorf("expected soloed channel a to keep playing at %v, got %v (err %v)", wantAVolume, v, err)
+	}
+
+	if err := a.Unsolo(); err != nil {
+		t.Fatalf("Unsolo on a failed: %v", err)
+	}
+	if v, err := b.GetVolume(); err != nil || v < wantBVolume-0.01 || v > wantBVolume+0.01 {
+		t.Errorf("expected b to be restored to %v after the last solo cleared, got %v (err %v)", wantBVolume, v, err)
+	}
+	if in.soloMuted {
+		t.Error("expected the input channel to no longer be solo-muted after the last solo cleared")
+	}
+}
+
 // TestOtherConstraints tests other potential missing validations
 func TestOtherConstraints(t *testing.T) {
 	config := DefaultTestEngineConfig()
@@ -401,33 +556,51 @@ func TestOtherConstraints(t *testing.T) {
 	defer cleanup()
 
 	t.Run("BufferSizeConstraints", func(t *testing.T) {
-		// TODO: Are there constraints on buffer sizes?
-		// Common valid sizes: 64, 128, 256, 512, 1024, 2048
-		// Invalid sizes: 0, 1, 3, negative values, extremely large values
-
 		validBufferSizes := []int{64, 128, 256, 512, 1024, 2048}
-		invalidBufferSizes := []int{0, -1, 1, 3, 7, 100000}
+		for _, size := range validBufferSizes {
+			if err := ValidateBufferSize(size, DefaultMinBufferSize, DefaultMaxBufferSize); err != nil {
+				t.Errorf("ValidateBufferSize(%d) = %v, want nil", size, err)
+			}
+		}
 
-		t.Logf("Valid buffer sizes we should support: %v", validBufferSizes)
-		t.Logf("Invalid buffer sizes we should reject: %v", invalidBufferSizes)
+		notPow2 := []int{3, 7, 100}
+		for _, size := range notPow2 {
+			err := ValidateBufferSize(size, DefaultMinBufferSize, DefaultMaxBufferSize)
+			if !errors.Is(err, ErrBufferSizeNotPow2) {
+				t.Errorf("ValidateBufferSize(%d) = %v, want ErrBufferSizeNotPow2", size, err)
+			}
+		}
 
-		// Currently no validation exists for buffer sizes
-		t.Log("⚠️  No buffer size validation is currently implemented")
+		outOfRange := []int{0, -1, 1, 100000}
+		for _, size := range outOfRange {
+			err := ValidateBufferSize(size, DefaultMinBufferSize, DefaultMaxBufferSize)
+			if !errors.Is(err, ErrBufferSizeOutOfRange) {
+				t.Errorf("ValidateBufferSize(%d) = %v, want ErrBufferSizeOutOfRange", size, err)
+			}
+		}
 	})
 
 	t.Run("SampleRateConstraints", func(t *testing.T) {
-		// TODO: Are there constraints on sample rates?
-		// Common rates: 44100, 48000, 88200, 96000, 176400, 192000
-		// Invalid rates: 0, negative, extremely high/low values
+		device := &devices.AudioDevice{SupportedSampleRates: []int{44100, 48000, 88200, 96000}}
 
-		validSampleRates := []int{44100, 48000, 88200, 96000}
-		invalidSampleRates := []int{0, -1, 100, 1000000}
+		for _, rate := range device.SupportedSampleRates {
+			if err := ValidateSampleRate(device, rate); err != nil {
+				t.Errorf("ValidateSampleRate(%d) = %v, want nil", rate, err)
+			}
+		}
 
-		t.Logf("Valid sample rates we should support: %v", validSampleRates)
-		t.Logf("Invalid sample rates we should reject: %v", invalidSampleRates)
+		// 0 is the "use device default" sentinel, not a rate lookup.
+		if err := ValidateSampleRate(device, 0); err != nil {
+			t.Errorf("ValidateSampleRate(0) = %v, want nil (device default)", err)
+		}
 
-		// Currently no validation exists for sample rates (except 0 = device default)
-		t.Log("⚠️  No sample rate validation is currently implemented")
+		invalidSampleRates := []int{-1, 100, 1000000}
+		for _, rate := range invalidSampleRates {
+			err := ValidateSampleRate(device, rate)
+			if !errors.Is(err, ErrUnsupportedSampleRate) {
+				t.Errorf("ValidateSampleRate(%d) = %v, want ErrUnsupportedSampleRate", rate, err)
+			}
+		}
 	})
 
 	t.Run("ChannelIndexConstraints", func(t *testing.T) {
@@ -440,62 +613,62 @@ func TestOtherConstraints(t *testing.T) {
 	})
 
 	t.Run("FilePathValidation", func(t *testing.T) {
-		// Test playback channels with various file paths
+		// Play (not channel construction) is where a bad path must fail now,
+		// with a typed, errors.Is-checkable error rather than being
+		// silently accepted at channel-add time.
 		testPaths := []struct {
 			path        string
-			shouldWork  bool
+			wantErr     error // nil if the path itself should pass validation
 			description string
 		}{
 			{
 				path:        "/System/Library/Sounds/Ping.aiff",
-				shouldWork:  true,
+				wantErr:     nil,
 				description: "Valid system sound file",
 			},
 			{
 				path:        "/nonexistent/path/file.wav",
-				shouldWork:  false,
+				wantErr:     ErrFileNotFound,
 				description: "Nonexistent file path",
 			},
 			{
 				path:        "",
-				shouldWork:  false,
+				wantErr:     ErrFileNotFound,
 				description: "Empty file path",
 			},
 			{
 				path:        "/etc/passwd",
-				shouldWork:  false,
+				wantErr:     ErrUnsupportedFormat,
 				description: "Non-audio file",
 			},
 		}
 
 		for _, tt := range testPaths {
 			t.Run(tt.description, func(t *testing.T) {
-				config := TestChannelConfig{
-					Volume:      1.0,
-					Pan:         0.0,
-					PluginCount: 0,
-					UseRealFile: false, // Use the provided path directly
-				}
-
-				// Create channel manually to test the specific path
+				// Built by hand (not CreateTestPlaybackChannel) so Play
+				// runs with no native player underneath it, isolating the
+				// file path check itself from an actual file load.
 				channel := &Channel{
-					Volume: config.Volume,
-					Pan:    config.Pan,
+					Volume: 1.0,
+					Pan:    0.0,
 					PlaybackOptions: &PlaybackOptions{
 						FilePath: tt.path,
 						Rate:     1.0,
 						Pitch:    0.0,
 					},
 				}
-
 				engine.Channels = append(engine.Channels, channel)
 
-				// Currently no validation happens during channel creation
-				// Validation might happen during engine start
-				t.Logf("Created channel with path: %s", tt.path)
+				err := channel.Play()
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("Play() with path %q = %v, want %v", tt.path, err, tt.wantErr)
+					}
+					return
+				}
 
-				if !tt.shouldWork {
-					t.Logf("⚠️  Invalid path %s was accepted - validation may happen at runtime", tt.path)
+				if errors.Is(err, ErrFileNotFound) || errors.Is(err, ErrUnsupportedFormat) {
+					t.Errorf("Play() with valid path %q unexpectedly failed file validation: %v", tt.path, err)
 				}
 			})
 		}
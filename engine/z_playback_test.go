@@ -3,6 +3,7 @@ package engine
 import (
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestPlaybackChannelFileLoading tests actual file loading and playback functionality
@@ -256,6 +257,88 @@ func TestPlaybackChannelErrorHandling(t *testing.T) {
 	})
 }
 
+// TestTransportStateMachine verifies Play/Pause/Stop drive TransportState
+// the way SDL_mixer/AVPlayer-style transports do, and that Subscribe
+// observes every transition, including the momentary Transitioning state.
+func TestTransportStateMachine(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+
+	if got := channel.TransportState(); got != Stopped {
+		t.Fatalf("expected initial TransportState to be Stopped, got %v", got)
+	}
+
+	events := channel.Subscribe()
+
+	if err := channel.Play(); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if got := channel.TransportState(); got != Playing {
+		t.Errorf("expected TransportState to be Playing after Play, got %v", got)
+	}
+
+	if err := channel.Pause(); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if got := channel.TransportState(); got != Paused {
+		t.Errorf("expected TransportState to be Paused after Pause, got %v", got)
+	}
+
+	if err := channel.Play(); err != nil {
+		t.Fatalf("Play (resume) failed: %v", err)
+	}
+	if got := channel.TransportState(); got != Playing {
+		t.Errorf("expected TransportState to be Playing after resuming, got %v", got)
+	}
+
+	if err := channel.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if got := channel.TransportState(); got != Stopped {
+		t.Errorf("expected TransportState to be Stopped after Stop, got %v", got)
+	}
+
+	wantStates := []TransportState{Transitioning, Playing, Transitioning, Paused, Transitioning, Playing, Transitioning, Stopped}
+	for i, want := range wantStates {
+		select {
+		case event := <-events:
+			if event.State != want {
+				t.Errorf("event %d: got state %v, want %v", i, event.State, want)
+			}
+		default:
+			t.Errorf("event %d: expected a TransportEvent for %v, channel was empty", i, want)
+		}
+	}
+}
+
+// TestPlaySeek verifies Seek accepts a valid position and rejects a
+// negative one, without disturbing TransportState.
+func TestPlaySeek(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+
+	if err := channel.Play(); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+
+	if err := channel.Seek(2 * time.Second); err != nil {
+		t.Errorf("Seek(2s) failed: %v", err)
+	}
+	if got := channel.TransportState(); got != Playing {
+		t.Errorf("expected Seek to leave TransportState as Playing, got %v", got)
+	}
+
+	if err := channel.Seek(-1 * time.Second); err == nil {
+		t.Error("expected Seek(-1s) to fail")
+	}
+}
+
 // Helper function to check if a string contains a substring (case-insensitive)
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) &&
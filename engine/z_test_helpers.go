@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"errors"
 	"math/rand"
 	"testing"
 	"time"
@@ -16,12 +17,15 @@ type TestEngineConfig struct {
 	BufferSize   int
 }
 
-// DefaultTestEngineConfig returns a standard engine configuration for testing
+// DefaultTestEngineConfig returns a standard engine configuration for
+// testing. BufferSize is 0 so CreateTestEngine negotiates one from the
+// chosen device's DefaultOutputConfig instead of guessing a fixed size that
+// may fall outside that device's StreamConfigRange.
 func DefaultTestEngineConfig() TestEngineConfig {
 	return TestEngineConfig{
 		MasterVolume: 0.8,
 		SampleRate:   0, // Use device default
-		BufferSize:   512,
+		BufferSize:   0, // Negotiated from DefaultOutputConfig; see CreateTestEngine
 	}
 }
 
@@ -36,8 +40,25 @@ func CreateTestEngine(t *testing.T, config TestEngineConfig) (*Engine, func()) {
 	if len(outputDevices) == 0 {
 		t.Skip("No output devices available")
 	}
+	device := outputDevices[0]
 
-	engine, err := NewEngine(&outputDevices[0], config.SampleRate, config.BufferSize)
+	sampleRateIndex := config.SampleRate
+	bufferSize := config.BufferSize
+	if bufferSize == 0 {
+		defaultConfig, err := device.DefaultOutputConfig()
+		if err != nil {
+			t.Fatalf("DefaultOutputConfig: %v", err)
+		}
+		bufferSize = defaultConfig.BufferFrames
+		for i, rate := range device.SupportedSampleRates {
+			if float64(rate) == defaultConfig.SampleRate {
+				sampleRateIndex = i
+				break
+			}
+		}
+	}
+
+	engine, err := NewEngine(&device, sampleRateIndex, bufferSize)
 	if err != nil {
 		t.Fatalf("Failed to create engine: %v", err)
 	}
@@ -54,9 +75,18 @@ func CreateTestEngine(t *testing.T, config TestEngineConfig) (*Engine, func()) {
 // TestChannelConfig holds configuration for creating test channels
 type TestChannelConfig struct {
 	Volume      float32
+	AllowGain   bool // passed through to SetVolumeLinear; see Channel.SetVolumeLinear
 	Pan         float32
 	PluginCount int  // Number of plugins to add (0-N)
 	UseRealFile bool // Use real system file vs fake path
+
+	// RecordPath, if set, makes CreateTestInputChannel build a genuine
+	// capture channel (via CreateCaptureChannel, instead of the bare
+	// mixer-strip InputOptions used otherwise) and call
+	// StartRecording(RecordPath, RecordingFormatWAV) on it, so a test can
+	// exercise the StartCapture/StartRecording/Read round trip against a
+	// real file.
+	RecordPath string
 }
 
 // DefaultInputChannelConfig returns a standard input channel configuration
@@ -92,17 +122,43 @@ func CreateTestInputChannel(t *testing.T, engine *Engine, config TestChannelConf
 	}
 
 	device := inputDevices[0]
-	channel := &Channel{
-		InputOptions: &InputOptions{
-			Device:       &device,
-			ChannelIndex: 0,
-		},
+
+	var channel *Channel
+	if config.RecordPath != "" {
+		// StartRecording needs a real AVAudioInputNode behind the channel,
+		// not the bare mixer-strip InputOptions below - go through the same
+		// path a real caller would.
+		var err error
+		channel, err = engine.CreateCaptureChannel(device.UID, AudioSpec{SampleRate: 48000, ChannelCount: 1})
+		if err != nil {
+			t.Fatalf("CreateCaptureChannel: %v", err)
+		}
+		if err := channel.StartCapture(); err != nil {
+			t.Fatalf("StartCapture: %v", err)
+		}
+		if err := channel.StartRecording(config.RecordPath, RecordingFormatWAV); err != nil {
+			t.Fatalf("StartRecording: %v", err)
+		}
+	} else {
+		// Go through the same declarative Engine.CreateInputChannel a real
+		// caller would use, rather than hand-assembling a Channel and
+		// appending it to engine.Channels directly - see Engine.Graph/
+		// Channel.InsertPlugin in graph.go for the rest of that API.
+		var err error
+		channel, err = engine.CreateInputChannel(&device, 0)
+		if err != nil {
+			t.Fatalf("CreateInputChannel: %v", err)
+		}
 	}
 
 	// For input channels (which may not have mixer nodes), we still want to validate parameters
 	// Apply validation manually since SetVolume/SetPan may fail without mixer nodes
-	if err := ValidateVolume(config.Volume); err != nil {
-		t.Fatalf("Invalid test volume %v: %v", config.Volume, err)
+	volumeCeiling := float32(1.0)
+	if config.AllowGain {
+		volumeCeiling = channelMaxGainLinear
+	}
+	if config.Volume < 0 || config.Volume > volumeCeiling {
+		t.Fatalf("Invalid test volume %v (ceiling %v, allowGain=%v)", config.Volume, volumeCeiling, config.AllowGain)
 	}
 	channel.Volume = config.Volume
 
@@ -142,7 +198,8 @@ func CreateTestInputChannel(t *testing.T, engine *Engine, config TestChannelConf
 		}
 	}
 
-	engine.Channels = append(engine.Channels, channel)
+	// CreateCaptureChannel/CreateInputChannel above already registered and
+	// appended channel to engine.Channels.
 	return channel
 }
 
@@ -165,7 +222,9 @@ func CreateTestPlaybackChannel(t *testing.T, engine *Engine, config TestChannelC
 					Rate:     1.0,
 					Pitch:    0.0,
 				},
+				engine: engine,
 			}
+			defaultChannelSolo.register(channel)
 			engine.Channels = append(engine.Channels, channel)
 		} else {
 			t.Fatalf("Failed to create playback channel: %v", err)
@@ -173,7 +232,7 @@ func CreateTestPlaybackChannel(t *testing.T, engine *Engine, config TestChannelC
 	}
 
 	// Apply volume and pan through validation methods (not direct assignment)
-	if err := channel.SetVolume(config.Volume); err != nil {
+	if err := channel.SetVolumeLinear(config.Volume, config.AllowGain); err != nil {
 		// If validation rejects the value, the channel still has the clamped/corrected value
 		t.Logf("Volume %v validation: %v", config.Volume, err)
 	}
@@ -195,7 +254,7 @@ func TestDeviceSetup(t *testing.T) (*devices.AudioDevice, *devices.AudioDevice)
 
 	var outputDevice *devices.AudioDevice
 	for i, device := range allDevices {
-		if device.CanOutput() && len(device.SupportedSampleRates) > 0 {
+		if device.CanOutput() && len(device.SupportedOutputConfigs()) > 0 {
 			outputDevice = &allDevices[i]
 			break
 		}
@@ -278,6 +337,7 @@ type ErrorTestCase struct {
 	TestFunc        func() error
 	WantErr         bool
 	ExpectedMessage string // Optional: specific error message to check for
+	ExpectedErr     error  // Optional: checked via errors.Is instead of string equality
 }
 
 // ValidateErrorTestCase runs an error test case and validates the results
@@ -293,6 +353,9 @@ func ValidateErrorTestCase(t *testing.T, testCase ErrorTestCase) {
 	}
 
 	if testCase.WantErr && err != nil {
+		if testCase.ExpectedErr != nil && !errors.Is(err, testCase.ExpectedErr) {
+			t.Errorf("Expected error to match %v via errors.Is, got '%s'", testCase.ExpectedErr, err)
+		}
 		if testCase.ExpectedMessage != "" && err.Error() != testCase.ExpectedMessage {
 			t.Errorf("Expected error message '%s', got '%s'", testCase.ExpectedMessage, err.Error())
 		}
@@ -1,10 +1,12 @@
 package engine
 
 import (
+	"bytes"
 	"encoding/json"
 	"testing"
 
 	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/session"
 )
 
 // TestChannelSerialization tests JSON serialization for all channel types
@@ -25,6 +27,42 @@ func TestChannelSerialization(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Playback Channel With Metadata",
+			channel: &Channel{
+				Volume: 0.8,
+				Pan:    -0.2,
+				PlaybackOptions: &PlaybackOptions{
+					FilePath: "/path/to/tagged.mp3",
+					Rate:     1.0,
+					Pitch:    0.0,
+					Metadata: &session.Metadata{
+						Title:           "Test Track",
+						Artist:          "Test Artist",
+						Album:           "Test Album",
+						TrackNumber:     3,
+						DurationSeconds: 123.45,
+						SampleRate:      44100,
+						Channels:        2,
+						CoverArt:        []byte{0xff, 0xd8, 0xff, 0xe0},
+						CoverArtMIME:    "image/jpeg",
+					},
+				},
+			},
+		},
+		{
+			name: "Sampler Channel",
+			channel: &Channel{
+				Volume: 1.0,
+				Pan:    0.0,
+				SamplerOptions: &SamplerOptions{
+					Path:           "/path/to/bank.sf2",
+					Bank:           0,
+					Program:        12,
+					InstrumentType: "soundfont",
+				},
+			},
+		},
 		{
 			name: "Audio Input Channel",
 			channel: &Channel{
@@ -100,6 +138,38 @@ func TestChannelSerialization(t *testing.T) {
 					t.Errorf("FilePath mismatch: got %s, want %s",
 						restored.PlaybackOptions.FilePath, tt.channel.PlaybackOptions.FilePath)
 				}
+
+				if wantMeta := tt.channel.PlaybackOptions.Metadata; wantMeta != nil {
+					gotMeta := restored.PlaybackOptions.Metadata
+					if gotMeta == nil {
+						t.Fatal("expected Metadata to survive the roundtrip, got nil")
+					}
+					if gotMeta.Title != wantMeta.Title || gotMeta.Artist != wantMeta.Artist ||
+						gotMeta.Album != wantMeta.Album || gotMeta.TrackNumber != wantMeta.TrackNumber {
+						t.Errorf("Metadata tags mismatch: got %+v, want %+v", gotMeta, wantMeta)
+					}
+					if gotMeta.DurationSeconds != wantMeta.DurationSeconds ||
+						gotMeta.SampleRate != wantMeta.SampleRate || gotMeta.Channels != wantMeta.Channels {
+						t.Errorf("Metadata format info mismatch: got %+v, want %+v", gotMeta, wantMeta)
+					}
+					if !bytes.Equal(gotMeta.CoverArt, wantMeta.CoverArt) || gotMeta.CoverArtMIME != wantMeta.CoverArtMIME {
+						t.Errorf("Metadata cover art mismatch: got %d bytes (%s), want %d bytes (%s)",
+							len(gotMeta.CoverArt), gotMeta.CoverArtMIME, len(wantMeta.CoverArt), wantMeta.CoverArtMIME)
+					}
+				}
+			}
+
+			if tt.channel.IsSampler() {
+				if !restored.IsSampler() {
+					t.Error("Restored channel should be sampler type")
+				}
+				if restored.SamplerOptions.Path != tt.channel.SamplerOptions.Path ||
+					restored.SamplerOptions.Bank != tt.channel.SamplerOptions.Bank ||
+					restored.SamplerOptions.Program != tt.channel.SamplerOptions.Program ||
+					restored.SamplerOptions.InstrumentType != tt.channel.SamplerOptions.InstrumentType {
+					t.Errorf("SamplerOptions mismatch: got %+v, want %+v",
+						restored.SamplerOptions, tt.channel.SamplerOptions)
+				}
 			}
 
 			if tt.channel.IsAudioInput() {
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateDetune(t *testing.T) {
+	tests := []struct {
+		name      string
+		cents     float32
+		wantError bool
+	}{
+		{"ValidMin", -100, false},
+		{"ValidCenter", 0, false},
+		{"ValidMax", 100, false},
+		{"InvalidBelowMin", -100.1, true},
+		{"InvalidAboveMax", 100.1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDetune(tt.cents)
+			if (err != nil) != tt.wantError {
+				t.Errorf("ValidateDetune(%v) error = %v, wantError = %v", tt.cents, err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestValidateLoopRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int64
+		wantError  bool
+	}{
+		{"Valid", 0, 1000, false},
+		{"InvalidEndBeforeStart", 1000, 0, true},
+		{"InvalidEqual", 500, 500, true},
+		{"InvalidNegativeStart", -1, 1000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLoopRange(tt.start, tt.end)
+			if (err != nil) != tt.wantError {
+				t.Errorf("ValidateLoopRange(%v, %v) error = %v, wantError = %v", tt.start, tt.end, err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestSetLoopRangeAndEnabled(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+
+	if err := channel.SetLoopEnabled(true); err == nil {
+		t.Error("expected error enabling loop before a LoopRange is set")
+	}
+
+	if err := channel.SetLoopRange(100, 2000); err != nil {
+		t.Fatalf("SetLoopRange: %v", err)
+	}
+	if channel.PlaybackOptions.LoopRange == nil || channel.PlaybackOptions.LoopRange.Start != 100 || channel.PlaybackOptions.LoopRange.End != 2000 {
+		t.Errorf("unexpected LoopRange: %+v", channel.PlaybackOptions.LoopRange)
+	}
+
+	if err := channel.SetLoopEnabled(true); err != nil {
+		t.Fatalf("SetLoopEnabled(true): %v", err)
+	}
+	if !channel.PlaybackOptions.LoopEnabled {
+		t.Error("expected LoopEnabled=true")
+	}
+}
+
+func TestSetFadeInOutConfig(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+
+	if err := channel.SetFadeIn(-time.Second); err == nil {
+		t.Error("expected error for negative fade-in duration")
+	}
+	if err := channel.SetFadeIn(250 * time.Millisecond); err != nil {
+		t.Fatalf("SetFadeIn: %v", err)
+	}
+	if channel.PlaybackOptions.FadeInDuration != 250*time.Millisecond {
+		t.Errorf("FadeInDuration = %v, want 250ms", channel.PlaybackOptions.FadeInDuration)
+	}
+
+	if err := channel.SetFadeOut(500 * time.Millisecond); err != nil {
+		t.Fatalf("SetFadeOut: %v", err)
+	}
+	if channel.PlaybackOptions.FadeOutDuration != 500*time.Millisecond {
+		t.Errorf("FadeOutDuration = %v, want 500ms", channel.PlaybackOptions.FadeOutDuration)
+	}
+}
+
+func TestSetParamDispatch(t *testing.T) {
+	config := DefaultTestEngineConfig()
+	engine, cleanup := CreateTestEngine(t, config)
+	defer cleanup()
+
+	channel := CreateTestPlaybackChannel(t, engine, DefaultPlaybackChannelConfig())
+
+	if err := channel.SetParam(ParamFadeIn, 100*time.Millisecond); err != nil {
+		t.Fatalf("SetParam(ParamFadeIn): %v", err)
+	}
+	if channel.PlaybackOptions.FadeInDuration != 100*time.Millisecond {
+		t.Errorf("FadeInDuration = %v, want 100ms", channel.PlaybackOptions.FadeInDuration)
+	}
+
+	if err := channel.SetParam(ParamFadeIn, "not a duration"); err == nil {
+		t.Error("expected type-mismatch error for ParamFadeIn with a string value")
+	}
+
+	if err := channel.SetParam(VoiceParam(999), 1.0); err == nil {
+		t.Error("expected error for unknown VoiceParam")
+	}
+}
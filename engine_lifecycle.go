@@ -0,0 +1,202 @@
+package macaudio
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LifecycleState models Engine's high-level running state, the way Web
+// Audio's BaseAudioContext.state does for an AudioContext. It's coarser and
+// bidirectional compared to EngineInitState, which only tracks one-way
+// progress through NewEngine/Start's setup sequence - LifecycleState is for
+// external code reacting to suspend/resume/interruption after the engine is
+// already up, via AddStateChangeListener.
+type LifecycleState int
+
+const (
+	// StateSuspended is an engine that isn't pulling audio - either never
+	// started, or paused via Suspend/Stop without tearing down its graph.
+	StateSuspended LifecycleState = iota
+	// StateRunning is an engine actively processing audio.
+	StateRunning
+	// StateInterrupted is a running engine that hit something it couldn't
+	// recover from on its own - its output device went offline with no
+	// fallback, or AVFoundation reported a configuration change/media
+	// services reset - and needs a caller to call Resume, ChangeOutputDevice,
+	// or similar before it's producing audio again.
+	StateInterrupted
+	// StateClosed is a destroyed engine; it's a terminal state, set by
+	// Destroy, and no further transitions follow it.
+	StateClosed
+)
+
+// String renders a LifecycleState the way a log line or a /status OSC
+// reply wants it.
+func (s LifecycleState) String() string {
+	switch s {
+	case StateSuspended:
+		return "suspended"
+	case StateRunning:
+		return "running"
+	case StateInterrupted:
+		return "interrupted"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChangeListener is called by Engine's lifecycle event loop whenever
+// GetState transitions. reason is non-nil for a transition the engine made
+// on its own in reaction to something going wrong (a device unplug with no
+// fallback, an AVFoundation configuration change) and nil for one a caller
+// asked for directly via Start/Stop/Suspend/Resume/Destroy.
+type StateChangeListener func(old, new LifecycleState, reason error)
+
+// lifecycleEvent is what setLifecycleState hands to lifecycleLoop for
+// fan-out to registered listeners.
+type lifecycleEvent struct {
+	old, new LifecycleState
+	reason   error
+}
+
+// lifecycleBus holds Engine's state-change listener registry and event
+// loop plumbing, kept behind its own mutex rather than Engine.mu since
+// transitions are reported from goroutines (DeviceMonitor's failover path,
+// reportNotification) that shouldn't have to contend with topology reads.
+type lifecycleBus struct {
+	mu             sync.Mutex
+	state          LifecycleState
+	listeners      map[uint64]StateChangeListener
+	nextListenerID uint64
+	events         chan lifecycleEvent
+}
+
+func newLifecycleBus() *lifecycleBus {
+	return &lifecycleBus{
+		state:     StateSuspended,
+		listeners: make(map[uint64]StateChangeListener),
+		events:    make(chan lifecycleEvent, 32),
+	}
+}
+
+// lifecycleLoop drains lb.events and fans each one out to every registered
+// listener, isolating listener panics so one bad callback can't take down
+// the loop or the goroutine (Start/Stop/DeviceMonitor) that triggered the
+// transition. It returns once lb.events is closed and drained, which
+// Destroy does as its last lifecycle-bus action.
+func (e *Engine) lifecycleLoop(lb *lifecycleBus) {
+	for ev := range lb.events {
+		lb.mu.Lock()
+		listeners := make([]StateChangeListener, 0, len(lb.listeners))
+		for _, fn := range lb.listeners {
+			listeners = append(listeners, fn)
+		}
+		lb.mu.Unlock()
+
+		for _, fn := range listeners {
+			e.invokeStateChangeListener(fn, ev)
+		}
+	}
+}
+
+func (e *Engine) invokeStateChangeListener(fn StateChangeListener, ev lifecycleEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.errorHandler.HandleError(fmt.Errorf("state change listener panicked: %v", r))
+		}
+	}()
+	fn(ev.old, ev.new, ev.reason)
+}
+
+// setLifecycleState transitions the engine to new and queues the change for
+// lifecycleLoop to deliver to registered listeners. It never blocks: like
+// Dispatcher.emitEvent, a full event buffer drops the event rather than
+// stall whatever goroutine is reporting the transition. A transition to the
+// state the engine is already in is a no-op - listeners see edges, not
+// every call site that happens to re-assert the current state.
+func (e *Engine) setLifecycleState(new LifecycleState, reason error) {
+	lb := e.lifecycle
+	lb.mu.Lock()
+	old := lb.state
+	if old == new {
+		lb.mu.Unlock()
+		return
+	}
+	lb.state = new
+	lb.mu.Unlock()
+
+	select {
+	case lb.events <- lifecycleEvent{old: old, new: new, reason: reason}:
+	default:
+	}
+}
+
+// AddStateChangeListener registers fn to be called on Engine's lifecycle
+// event loop goroutine whenever GetState transitions, and returns an id
+// RemoveStateChangeListener can use to unregister it later.
+func (e *Engine) AddStateChangeListener(fn StateChangeListener) uint64 {
+	lb := e.lifecycle
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	id := lb.nextListenerID
+	lb.nextListenerID++
+	lb.listeners[id] = fn
+	return id
+}
+
+// RemoveStateChangeListener unregisters the listener id returned by
+// AddStateChangeListener. It's a no-op if id is already gone.
+func (e *Engine) RemoveStateChangeListener(id uint64) {
+	lb := e.lifecycle
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	delete(lb.listeners, id)
+}
+
+// GetState returns a snapshot of Engine's current LifecycleState.
+func (e *Engine) GetState() LifecycleState {
+	lb := e.lifecycle
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.state
+}
+
+// Suspend pauses the underlying avEngine - AVAudioEngine.pause() under the
+// hood, via avaudio/engine.Engine.Pause - without tearing down the channel
+// graph Start assembled, for backgrounded apps that want to stop consuming
+// CPU and audio hardware without losing their topology. Unlike Stop,
+// nothing about the graph changes, so it doesn't go through the Dispatcher.
+// Resume undoes it.
+func (e *Engine) Suspend() error {
+	e.mu.RLock()
+	running := e.isRunning
+	e.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("engine is not running")
+	}
+
+	e.avEngine.Pause()
+	e.setLifecycleState(StateSuspended, nil)
+	return nil
+}
+
+// Resume undoes Suspend, restarting the underlying avEngine with its graph
+// untouched. It also recovers an engine left in StateInterrupted by, e.g.,
+// an AVAudioEngineConfigurationChangeNotification, since that's the same
+// underlying avEngine.Start() call.
+func (e *Engine) Resume() error {
+	e.mu.RLock()
+	running := e.isRunning
+	e.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("engine is not running")
+	}
+
+	if err := e.avEngine.Start(); err != nil {
+		return fmt.Errorf("failed to resume engine: %w", err)
+	}
+	e.setLifecycleState(StateRunning, nil)
+	return nil
+}
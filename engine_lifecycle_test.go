@@ -0,0 +1,132 @@
+package macaudio
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLifecycleStateTransitionsFireListeners drives setLifecycleState
+// directly (cheaper than a real Start/Stop, which needs hardware) and
+// checks a registered listener sees old/new/reason, that a no-op
+// transition to the current state is suppressed, and that
+// RemoveStateChangeListener stops further delivery.
+func TestLifecycleStateTransitionsFireListeners(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if got := eng.GetState(); got != StateSuspended {
+		t.Fatalf("expected a new engine to start StateSuspended, got %v", got)
+	}
+
+	var mu sync.Mutex
+	var got []lifecycleEvent
+	id := eng.AddStateChangeListener(func(old, new LifecycleState, reason error) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, lifecycleEvent{old: old, new: new, reason: reason})
+	})
+
+	eng.setLifecycleState(StateSuspended, nil) // no-op: already suspended
+	eng.setLifecycleState(StateRunning, nil)
+	wantErr := fmt.Errorf("device unplugged")
+	eng.setLifecycleState(StateInterrupted, wantErr)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for listener delivery, got %d events", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("expected exactly 2 events (no-op suppressed), got %d: %+v", len(got), got)
+	}
+	if got[0].old != StateSuspended || got[0].new != StateRunning || got[0].reason != nil {
+		t.Errorf("unexpected first event: %+v", got[0])
+	}
+	if got[1].old != StateRunning || got[1].new != StateInterrupted || got[1].reason != wantErr {
+		t.Errorf("unexpected second event: %+v", got[1])
+	}
+
+	eng.RemoveStateChangeListener(id)
+	eng.setLifecycleState(StateSuspended, nil)
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("expected no further events after RemoveStateChangeListener, got %d", len(got))
+	}
+}
+
+// TestLifecycleListenerPanicIsolation checks that a panicking listener
+// doesn't stop a second, well-behaved listener from seeing the same event.
+func TestLifecycleListenerPanicIsolation(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	eng.AddStateChangeListener(func(old, new LifecycleState, reason error) {
+		panic("listener exploded")
+	})
+
+	var mu sync.Mutex
+	sawEvent := false
+	eng.AddStateChangeListener(func(old, new LifecycleState, reason error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sawEvent = true
+	})
+
+	eng.setLifecycleState(StateRunning, nil)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		ok := sawEvent
+		mu.Unlock()
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the second listener to run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestSuspendResumeRequireRunningEngine checks that Suspend/Resume refuse
+// to act on an engine that was never started.
+func TestSuspendResumeRequireRunningEngine(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.Suspend(); err == nil {
+		t.Fatal("expected Suspend to fail on a never-started engine")
+	}
+	if err := eng.Resume(); err == nil {
+		t.Fatal("expected Resume to fail on a never-started engine")
+	}
+}
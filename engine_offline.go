@@ -0,0 +1,92 @@
+package macaudio
+
+import (
+	"fmt"
+	"time"
+
+	avrecorder "github.com/shaban/macaudio/avaudio/recorder"
+)
+
+// RenderOfflineConfig configures a faster-than-realtime bounce of the
+// engine's graph to a file; see Engine.RenderOffline.
+type RenderOfflineConfig struct {
+	// Duration is how much audio to render.
+	Duration time.Duration
+	// Path is the output file RenderOffline writes to.
+	Path string
+	// Format selects the output container; the zero value is
+	// avrecorder.FormatWAV.
+	Format avrecorder.FileFormat
+	// Progress, if set, is called after every rendered chunk with the
+	// running frame count and the total frame count for Duration.
+	Progress func(framesRendered, totalFrames int64)
+}
+
+// RenderOffline bounces the engine's assembled channel/plugin graph to
+// config.Path faster than real time, pulling config.Duration worth of audio
+// through AVAudioEngine's manual rendering mode in bufferSize-frame chunks
+// (see avaudio/engine.Engine.RenderOffline) and writing each chunk to an
+// avaudio/recorder.Writer. It only produces output on an engine created
+// with EngineConfig.Offline set, and must be called after Start so the
+// graph's channels and plugins are already wired up. RenderOffline only
+// pulls frames - it never touches topology - so automation, mute toggles,
+// and every other topology mutation made during a render still go through
+// the Dispatcher exactly as they do live.
+func (e *Engine) RenderOffline(config RenderOfflineConfig) error {
+	e.mu.RLock()
+	offline := e.offline
+	sampleRate := e.sampleRate
+	bufferSize := e.bufferSize
+	e.mu.RUnlock()
+
+	if !offline {
+		return fmt.Errorf("RenderOffline requires an engine created with EngineConfig.Offline")
+	}
+	if config.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if config.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+
+	channels := e.avEngine.GetSpec().ChannelCount
+	if channels <= 0 {
+		channels = 2
+	}
+
+	writer, err := avrecorder.OpenWriter(config.Path, config.Format, sampleRate, channels)
+	if err != nil {
+		return fmt.Errorf("failed to open %q for offline render: %w", config.Path, err)
+	}
+	defer writer.Close()
+
+	totalFrames := int64(config.Duration.Seconds() * sampleRate)
+
+	var rendered int64
+	for rendered < totalFrames {
+		chunk := bufferSize
+		if remaining := totalFrames - rendered; int64(chunk) > remaining {
+			chunk = int(remaining)
+		}
+
+		samples, err := e.avEngine.RenderOffline(chunk)
+		if err != nil {
+			return fmt.Errorf("render failed after %d frames: %w", rendered, err)
+		}
+
+		frameCount := len(samples) / channels
+		if frameCount == 0 {
+			break // nothing left to render
+		}
+		if err := writer.Write(samples, frameCount, channels); err != nil {
+			return fmt.Errorf("write failed after %d frames: %w", rendered, err)
+		}
+
+		rendered += int64(frameCount)
+		if config.Progress != nil {
+			config.Progress(rendered, totalFrames)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package macaudio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+// TestNewEngineOfflineSkipsOutputDeviceRequirement checks that
+// EngineConfig.Offline lets NewEngine succeed with no OutputDeviceUID/
+// Outputs, unlike a live engine (see TestEngineValidation/EmptyConfig).
+func TestNewEngineOfflineSkipsOutputDeviceRequirement(t *testing.T) {
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   512,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		Offline: true,
+	}
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("NewEngine with Offline:true failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	if err := eng.validateEngineReadiness(); err != nil {
+		t.Fatalf("expected an offline engine to be ready without an output device, got %v", err)
+	}
+}
+
+// TestRenderOfflineRequiresOfflineEngine checks that RenderOffline refuses
+// to run on an engine that wasn't created with EngineConfig.Offline.
+func TestRenderOfflineRequiresOfflineEngine(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	err = eng.RenderOffline(RenderOfflineConfig{
+		Duration: time.Second,
+		Path:     filepath.Join(t.TempDir(), "out.wav"),
+	})
+	if err == nil {
+		t.Fatal("expected RenderOffline to fail on a non-offline engine")
+	}
+}
+
+// TestRenderOfflineValidatesConfig checks that RenderOffline rejects a
+// missing duration or path before it ever opens a file.
+func TestRenderOfflineValidatesConfig(t *testing.T) {
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   512,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		Offline: true,
+	}
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("NewEngine with Offline:true failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	path := filepath.Join(t.TempDir(), "out.wav")
+
+	if err := eng.RenderOffline(RenderOfflineConfig{Path: path}); err == nil {
+		t.Fatal("expected an error for a zero duration")
+	}
+	if err := eng.RenderOffline(RenderOfflineConfig{Duration: time.Second}); err == nil {
+		t.Fatal("expected an error for an empty path")
+	}
+
+	if _, statErr := os.Stat(path); statErr == nil {
+		t.Fatal("expected no file to be created by a rejected config")
+	}
+}
@@ -0,0 +1,83 @@
+package macaudio
+
+import "fmt"
+
+// OSCConfig configures the OSC control surface started by Engine.EnableOSC.
+type OSCConfig struct {
+	// Addr is the UDP address to listen on, e.g. "0.0.0.0:9000" or
+	// "127.0.0.1:0" to let the OS pick a port (see OSCServer.Serve's
+	// underlying transport, osc.ListenUDP).
+	Addr string
+	// AnnounceAddr, if set, is a broadcast/multicast address the server
+	// sends a one-shot "/announce" message to once listening, so control
+	// surfaces configured with AnnounceAddr but not Addr can discover it
+	// (see OSCServer.Announce).
+	AnnounceAddr string
+	// Auth, if its Token is non-empty, requires senders to present it via
+	// "/auth" before the server accepts any other command from their
+	// address - see OSCAuth, OSCServer.handleAuth. The zero value leaves
+	// the server open to any sender, its behavior before OSCAuth existed.
+	Auth OSCAuth
+}
+
+// OSCAuth is a shared-secret token control surfaces must present via
+// "/auth" before OSCServer.handle accepts any other command from their
+// address. An empty Token disables authentication entirely.
+type OSCAuth struct {
+	Token string
+}
+
+// EnableOSC starts an OSCServer bound to this engine and its serializer,
+// listening on config.Addr - the same server CreateAudioInputChannel and
+// friends are driven through by SetChannelMute/SetPluginBypass/
+// ChangeChannelDevice/ChangeOutputDevice, so OSC traffic and direct Go
+// callers serialize through the one dispatcher (see OSCServer, Listen).
+// It's an error to call EnableOSC while OSC is already enabled; call
+// DisableOSC first to rebind.
+func (e *Engine) EnableOSC(config OSCConfig) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.oscServer != nil {
+		return fmt.Errorf("OSC is already enabled")
+	}
+
+	server, err := Listen(e, e.serializer, config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to start OSC server: %w", err)
+	}
+	server.SetAuth(config.Auth)
+
+	if config.AnnounceAddr != "" {
+		if err := server.Announce(config.AnnounceAddr); err != nil {
+			server.Close()
+			return fmt.Errorf("failed to announce OSC server: %w", err)
+		}
+	}
+
+	e.oscServer = server
+	return nil
+}
+
+// DisableOSC stops the OSC server started by EnableOSC. It's a no-op if OSC
+// isn't enabled.
+func (e *Engine) DisableOSC() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.oscServer == nil {
+		return nil
+	}
+
+	err := e.oscServer.Close()
+	e.oscServer = nil
+	return err
+}
+
+// OSCEnabled reports whether EnableOSC has started a server that hasn't
+// since been stopped by DisableOSC.
+func (e *Engine) OSCEnabled() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.oscServer != nil
+}
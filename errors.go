@@ -1,12 +1,125 @@
 package macaudio
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
 
 // ErrorHandler defines the interface for handling engine errors
 type ErrorHandler interface {
 	HandleError(error)
 }
 
+// ErrorKind identifies which part of the engine an EngineError originated
+// from, for an Engine.Errors() consumer that only cares about some sources
+// (e.g. reacting to a device failover differently than a render glitch).
+type ErrorKind int
+
+const (
+	// ErrorKindGeneric covers every error HandleError already carried before
+	// EngineError existed - dispatcher failures, cleanup errors, and the
+	// like - with no more specific source to report.
+	ErrorKindGeneric ErrorKind = iota
+	// ErrorKindConfigurationChange corresponds to
+	// AVAudioEngineConfigurationChangeNotification (see avengine's
+	// avaudio/engine.NotificationConfigurationChange).
+	ErrorKindConfigurationChange
+	// ErrorKindMediaServicesReset corresponds to CoreAudio's
+	// media-services-were-reset notification (see
+	// avaudio/engine.NotificationMediaServicesReset).
+	ErrorKindMediaServicesReset
+	// ErrorKindRenderException corresponds to a render-thread exception
+	// reported against a specific node (see
+	// avaudio/engine.NotificationRenderException); EngineError.NodePtr
+	// identifies the node.
+	ErrorKindRenderException
+	// ErrorKindResourceWarning is emitted by Engine.RecordRenderStats when
+	// render CPU crosses EngineLimits.MaxCPUPercent - a soft warning, not
+	// a rejection, since the render thread can't be turned away the way a
+	// dispatcher op carrying ErrLimitExceeded can.
+	ErrorKindResourceWarning
+)
+
+// String returns the error kind's name, e.g. "ConfigurationChange".
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindGeneric:
+		return "Generic"
+	case ErrorKindConfigurationChange:
+		return "ConfigurationChange"
+	case ErrorKindMediaServicesReset:
+		return "MediaServicesReset"
+	case ErrorKindRenderException:
+		return "RenderException"
+	case ErrorKindResourceWarning:
+		return "ResourceWarning"
+	default:
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+}
+
+// EngineError is one event delivered on Engine.Errors().
+type EngineError struct {
+	Kind ErrorKind
+	Err  error
+	// NodePtr identifies the node a render-thread exception (ErrorKindRenderException)
+	// was raised against; nil for every other kind.
+	NodePtr   unsafe.Pointer
+	Timestamp time.Time
+}
+
+// channelErrorHandler is the ErrorHandler every Engine actually stores (see
+// NewEngine). HandleError forwards to the caller-supplied underlying
+// handler, unchanged and synchronous, so SetErrorHandler keeps working
+// exactly as before; it also fans the same error out as an EngineError onto
+// ch, non-blocking, so a slow or absent Errors() consumer can never stall
+// whatever goroutine hit the error - it just shows up in droppedCount
+// instead. emit is the same path with a caller-supplied Kind/NodePtr, used
+// by reportNotification for events that aren't plain HandleError calls.
+type channelErrorHandler struct {
+	mu           sync.RWMutex
+	underlying   ErrorHandler
+	ch           chan EngineError
+	droppedCount uint64
+}
+
+func newChannelErrorHandler(underlying ErrorHandler) *channelErrorHandler {
+	return &channelErrorHandler{
+		underlying: underlying,
+		ch:         make(chan EngineError, 64),
+	}
+}
+
+// HandleError implements ErrorHandler, treating err as ErrorKindGeneric.
+func (h *channelErrorHandler) HandleError(err error) {
+	h.emit(ErrorKindGeneric, err, nil)
+}
+
+func (h *channelErrorHandler) emit(kind ErrorKind, err error, nodePtr unsafe.Pointer) {
+	h.mu.RLock()
+	underlying := h.underlying
+	h.mu.RUnlock()
+
+	if underlying != nil {
+		underlying.HandleError(err)
+	}
+
+	select {
+	case h.ch <- EngineError{Kind: kind, Err: err, NodePtr: nodePtr, Timestamp: time.Now()}:
+	default:
+		atomic.AddUint64(&h.droppedCount, 1)
+	}
+}
+
+func (h *channelErrorHandler) setUnderlying(u ErrorHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.underlying = u
+}
+
 // DefaultErrorHandler provides a basic error handling implementation
 type DefaultErrorHandler struct{}
 
@@ -0,0 +1,90 @@
+package macaudio
+
+import (
+	"errors"
+	"testing"
+)
+
+// recordingErrorHandler collects every error handed to it, for asserting
+// channelErrorHandler still forwards synchronously and unchanged.
+type recordingErrorHandler struct {
+	errs []error
+}
+
+func (h *recordingErrorHandler) HandleError(err error) {
+	h.errs = append(h.errs, err)
+}
+
+// TestChannelErrorHandlerForwardsAndEmits checks that HandleError both
+// reaches the underlying handler and shows up on the channel, with the kind
+// HandleError always reports.
+func TestChannelErrorHandlerForwardsAndEmits(t *testing.T) {
+	underlying := &recordingErrorHandler{}
+	h := newChannelErrorHandler(underlying)
+
+	want := errors.New("boom")
+	h.HandleError(want)
+
+	if len(underlying.errs) != 1 || underlying.errs[0] != want {
+		t.Fatalf("expected underlying handler to receive %v, got %v", want, underlying.errs)
+	}
+
+	select {
+	case ev := <-h.ch:
+		if ev.Err != want || ev.Kind != ErrorKindGeneric {
+			t.Errorf("expected {Kind: Generic, Err: %v}, got %+v", want, ev)
+		}
+	default:
+		t.Fatal("expected an EngineError on the channel")
+	}
+}
+
+// TestChannelErrorHandlerEmitUsesGivenKind checks that emit (used by
+// reportNotification) carries the caller-supplied Kind/NodePtr rather than
+// always reporting ErrorKindGeneric.
+func TestChannelErrorHandlerEmitUsesGivenKind(t *testing.T) {
+	h := newChannelErrorHandler(nil)
+
+	want := errors.New("engine configuration changed")
+	h.emit(ErrorKindConfigurationChange, want, nil)
+
+	select {
+	case ev := <-h.ch:
+		if ev.Kind != ErrorKindConfigurationChange || ev.Err != want {
+			t.Errorf("expected {Kind: ConfigurationChange, Err: %v}, got %+v", want, ev)
+		}
+	default:
+		t.Fatal("expected an EngineError on the channel")
+	}
+}
+
+// TestChannelErrorHandlerDropsWhenFull checks that a full channel increments
+// droppedCount instead of blocking.
+func TestChannelErrorHandlerDropsWhenFull(t *testing.T) {
+	h := newChannelErrorHandler(nil)
+
+	for i := 0; i < cap(h.ch)+5; i++ {
+		h.HandleError(errors.New("spam"))
+	}
+
+	if h.droppedCount == 0 {
+		t.Error("expected droppedCount to be nonzero once the channel filled up")
+	}
+	if len(h.ch) != cap(h.ch) {
+		t.Errorf("expected channel to stay full at capacity %d, got len %d", cap(h.ch), len(h.ch))
+	}
+}
+
+// TestChannelErrorHandlerSetUnderlying checks that swapping the underlying
+// handler (as SetErrorHandler does) takes effect for the next HandleError.
+func TestChannelErrorHandlerSetUnderlying(t *testing.T) {
+	h := newChannelErrorHandler(nil)
+	second := &recordingErrorHandler{}
+	h.setUnderlying(second)
+
+	h.HandleError(errors.New("routed to second"))
+
+	if len(second.errs) != 1 {
+		t.Fatalf("expected the new underlying handler to receive the error, got %v", second.errs)
+	}
+}
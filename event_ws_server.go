@@ -0,0 +1,44 @@
+package macaudio
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// EventWebSocketServer serves a Serializer's state-change event stream
+// (see Serializer.Subscribe) as a WebSocket, one JSON-encoded StateEvent
+// per message, for UIs and logging daemons that want live meter/fader
+// movement without polling GetState.
+type EventWebSocketServer struct {
+	serializer *Serializer
+	upgrader   websocket.Upgrader
+}
+
+// NewEventWebSocketServer creates an EventWebSocketServer for serializer.
+// Register it at an endpoint (e.g. "/events") with http.Handle.
+func NewEventWebSocketServer(serializer *Serializer) *EventWebSocketServer {
+	return &EventWebSocketServer{
+		serializer: serializer,
+		upgrader:   websocket.Upgrader{},
+	}
+}
+
+// ServeHTTP upgrades the connection and streams StateEvents to it until
+// the client disconnects or a write fails.
+func (s *EventWebSocketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	events := s.serializer.Subscribe()
+	defer s.serializer.Unsubscribe(events)
+
+	for event := range events {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package macaudio
+
+import "github.com/shaban/macaudio/avaudio/events"
+
+// VolumeChanged is emitted after BaseChannel.SetVolume changes a channel's
+// volume. ChannelID is the same id ChannelListener's OnVolumeChanged takes,
+// kept as a string rather than a Channel so a subscriber can hold the event
+// past the channel's own lifetime (e.g. an undo/redo entry).
+type VolumeChanged struct {
+	ChannelID string
+	Old       float32
+	New       float32
+}
+
+// PanChanged is emitted after BaseChannel.SetPan changes a channel's pan.
+type PanChanged struct {
+	ChannelID string
+	Old       float32
+	New       float32
+}
+
+// MuteChanged is emitted after a channel's mute state changes, whether that
+// went through BaseChannel.SetMute directly or through the dispatcher (the
+// normal path once a channel is attached to a running Engine).
+type MuteChanged struct {
+	ChannelID string
+	Old       bool
+	New       bool
+}
+
+// SendLevelChanged is emitted after BaseChannel.SetSendLevel changes the
+// level of an existing send. SendName is the destination AuxChannel's name
+// at the time of the change, not a stable identifier - renaming the aux
+// channel doesn't retroactively rename events already delivered.
+type SendLevelChanged struct {
+	ChannelID string
+	SendName  string
+	Old       float32
+	New       float32
+}
+
+// ChannelReleased is emitted once a channel has been torn down and removed
+// from its Engine (see Engine.RemoveChannel), after which ChannelID no
+// longer resolves through Engine.GetChannel.
+type ChannelReleased struct {
+	ChannelID string
+}
+
+// OnVolumeChanged returns a Subscription that receives a VolumeChanged event
+// each time SetVolume succeeds on this channel.
+func (bc *BaseChannel) OnVolumeChanged(opts ...events.SubscribeOption) *events.Subscription[VolumeChanged] {
+	return bc.volumeEmitter.Subscribe(opts...)
+}
+
+// OnPanChanged returns a Subscription that receives a PanChanged event each
+// time SetPan succeeds on this channel.
+func (bc *BaseChannel) OnPanChanged(opts ...events.SubscribeOption) *events.Subscription[PanChanged] {
+	return bc.panEmitter.Subscribe(opts...)
+}
+
+// OnMuteChanged returns a Subscription that receives a MuteChanged event
+// each time this channel's mute state actually changes.
+func (bc *BaseChannel) OnMuteChanged(opts ...events.SubscribeOption) *events.Subscription[MuteChanged] {
+	return bc.muteEmitter.Subscribe(opts...)
+}
+
+// OnSendLevelChanged returns a Subscription that receives a
+// SendLevelChanged event each time SetSendLevel succeeds on this channel.
+func (bc *BaseChannel) OnSendLevelChanged(opts ...events.SubscribeOption) *events.Subscription[SendLevelChanged] {
+	return bc.sendLevelEmitter.Subscribe(opts...)
+}
+
+// OnReleased returns a Subscription that receives a ChannelReleased event
+// once this channel is removed from its Engine.
+func (bc *BaseChannel) OnReleased(opts ...events.SubscribeOption) *events.Subscription[ChannelReleased] {
+	return bc.releasedEmitter.Subscribe(opts...)
+}
+
+// emitReleased fires a ChannelReleased event. Called via an unexported
+// interface check from Engine.removeChannel, which only has a Channel
+// (every concrete channel type embeds *BaseChannel, so all of them pick
+// this up without needing their own copy).
+func (bc *BaseChannel) emitReleased(id string) {
+	bc.releasedEmitter.Emit(ChannelReleased{ChannelID: id})
+}
@@ -0,0 +1,132 @@
+package macaudio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBaseChannelEmitsVolumeAndPanAndMuteEvents(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	master := eng.GetMasterChannel()
+
+	volumeSub := master.OnVolumeChanged()
+	defer volumeSub.Close()
+	panSub := master.OnPanChanged()
+	defer panSub.Close()
+	muteSub := master.OnMuteChanged()
+	defer muteSub.Close()
+
+	if err := master.SetVolume(0.4); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+	if err := master.SetPan(-0.5); err != nil {
+		t.Fatalf("SetPan failed: %v", err)
+	}
+	if err := master.SetMute(true); err != nil {
+		t.Fatalf("SetMute failed: %v", err)
+	}
+
+	select {
+	case ev := <-volumeSub.Ch():
+		if ev.New != 0.4 {
+			t.Errorf("expected VolumeChanged.New == 0.4, got %v", ev.New)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for VolumeChanged")
+	}
+
+	select {
+	case ev := <-panSub.Ch():
+		if ev.New != -0.5 {
+			t.Errorf("expected PanChanged.New == -0.5, got %v", ev.New)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for PanChanged")
+	}
+
+	select {
+	case ev := <-muteSub.Ch():
+		if !ev.New {
+			t.Errorf("expected MuteChanged.New == true, got %v", ev.New)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for MuteChanged")
+	}
+}
+
+func TestBaseChannelEmitsSendLevelChanged(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	aux, err := eng.CreateAuxChannel("reverb", AuxConfig{SendLevel: 1, ReturnLevel: 1})
+	if err != nil {
+		t.Fatalf("CreateAuxChannel failed: %v", err)
+	}
+	sine, err := eng.CreateSineChannel("tone", 440, 0.1)
+	if err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+	if err := sine.AddSend(aux, 0.2, false); err != nil {
+		t.Fatalf("AddSend failed: %v", err)
+	}
+
+	sub := sine.OnSendLevelChanged()
+	defer sub.Close()
+
+	if err := sine.SetSendLevel(aux, 0.9); err != nil {
+		t.Fatalf("SetSendLevel failed: %v", err)
+	}
+
+	select {
+	case ev := <-sub.Ch():
+		if ev.SendName != "reverb" {
+			t.Errorf("expected SendName == \"reverb\", got %q", ev.SendName)
+		}
+		if ev.Old != 0.2 || ev.New != 0.9 {
+			t.Errorf("expected Old=0.2 New=0.9, got Old=%v New=%v", ev.Old, ev.New)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for SendLevelChanged")
+	}
+}
+
+func TestChannelReleasedEmittedOnRemove(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	sine, err := eng.CreateSineChannel("tone", 440, 0.1)
+	if err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+	id := sine.GetIDString()
+
+	sub := sine.OnReleased()
+	defer sub.Close()
+
+	if err := eng.RemoveChannel(id); err != nil {
+		t.Fatalf("RemoveChannel failed: %v", err)
+	}
+
+	select {
+	case ev := <-sub.Ch():
+		if ev.ChannelID != id {
+			t.Errorf("expected ChannelID == %q, got %q", id, ev.ChannelID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for ChannelReleased")
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/shaban/macaudio"
 	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/devices"
 )
 
 func main() {
@@ -36,6 +37,17 @@ func main() {
 
 	monitor := engine.GetDeviceMonitor()
 
+	// Report which device class triggered the last adaptive backoff
+	// reset, rather than just the averages GetPerformanceStats reports.
+	events := make(chan devices.DeviceChangeEvent, 8)
+	unsubscribe := monitor.Subscribe(events)
+	defer unsubscribe()
+	go func() {
+		for ev := range events {
+			fmt.Printf("  event: kind=%s audio=%v midi=%v\n", ev.Kind, ev.Audio != nil, ev.MIDI != nil)
+		}
+	}()
+
 	fmt.Printf("Initial polling interval: %v\n", monitor.GetPollingInterval())
 	fmt.Println("Monitoring device polling behavior for 10 seconds...")
 	fmt.Println("(No device changes expected - watch interval adapt)")
@@ -48,15 +60,15 @@ func main() {
 	for i := 0; i < 5; i++ {
 		<-ticker.C
 
-		avgTime, maxTime, checkCount := monitor.GetPerformanceStats()
-		interval := monitor.GetPollingInterval()
+		stats := monitor.GetPollingStats()
 
-		fmt.Printf("[%2.0fs] Interval: %5s | Avg: %6s | Max: %6s | Checks: %4d\n",
+		fmt.Printf("[%2.0fs] Interval: %5s | Avg: %6s | Max: %6s | Checks: %4d | Events: %v\n",
 			time.Since(start).Seconds(),
-			interval.String(),
-			avgTime.String(),
-			maxTime.String(),
-			checkCount,
+			stats.CurrentInterval.String(),
+			stats.AvgCheckTime.String(),
+			stats.MaxCheckTime.String(),
+			stats.CheckCount,
+			stats.EventCounts,
 		)
 	}
 
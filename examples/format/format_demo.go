@@ -64,8 +64,8 @@ func runFormatDemo() {
 		SampleRate:   22050, // Lower quality for streaming
 		ChannelCount: 1,     // Mono
 		Interleaved:  false,
-		BufferSize:   256, // Engine settings
-		BitDepth:     16,  // Engine settings
+		BufferSize:   256,                      // Engine settings
+		SampleFormat: engine.SampleFormatInt16, // Engine settings
 	}
 
 	customFormat, err := audioEngine.NewFormat(customSpec)
@@ -14,8 +14,10 @@ import (
 
 	"github.com/shaban/macaudio"
 	"github.com/shaban/macaudio/avaudio/engine"
+	avmidi "github.com/shaban/macaudio/avaudio/midi"
 	"github.com/shaban/macaudio/avaudio/tap"
 	"github.com/shaban/macaudio/devices"
+	"github.com/shaban/macaudio/midimap"
 )
 
 func main() {
@@ -123,7 +125,7 @@ func main() {
 	fmt.Println("🎤 Creating microphone input channel...")
 	inputConfig := macaudio.AudioInputConfig{
 		DeviceUID:       selectedInput.UID,
-		InputBus:        0, // First input channel
+		InputBus:        0,   // First input channel
 		MonitoringLevel: 0.8, // Enable monitoring at 80%
 	}
 
@@ -135,7 +137,7 @@ func main() {
 
 	// Configure signal path
 	fmt.Println("🔗 Configuring signal path...")
-	
+
 	// Set input volume to safe level
 	if err := inputChannel.SetVolume(0.6); err != nil {
 		fmt.Printf("⚠️ Failed to set input volume: %v\n", err)
@@ -178,15 +180,15 @@ func main() {
 	}
 
 	fmt.Println("✅ Audio engine running!")
-	
+
 	// Install audio tap on input channel for RMS monitoring
 	var inputTap *tap.Tap
 	fmt.Println("🔍 Installing audio tap for signal monitoring...")
-	
+
 	// Get the native engine and input node pointers
 	enginePtr := audioEngine.GetNativeEngine()
 	inputNodePtr := inputChannel.GetInputNode() // Tap the input node directly
-	
+
 	if enginePtr != nil && inputNodePtr != nil {
 		var err error
 		inputTap, err = tap.InstallTapWithKey(enginePtr, inputNodePtr, 0, "mic_input_monitor")
@@ -203,12 +205,33 @@ func main() {
 	// This would show the final processed signal going to speakers
 	fmt.Println("")
 
+	// midiController/mapper are set by the "midi" command below; nil until
+	// then, and closed on exit if ever opened.
+	var midiController *avmidi.Controller
+	defer func() {
+		if midiController != nil {
+			midiController.Close()
+		}
+	}()
+
+	// scenes backs the "save"/"recall"/"morph" commands below.
+	scenes := macaudio.NewSceneManager(audioEngine.GetSerializer())
+
+	// recorder is set by the "record" command below; nil until then, and
+	// stopped on exit if still recording.
+	var recorder *tap.Recorder
+	defer func() {
+		if recorder != nil {
+			recorder.Stop()
+		}
+	}()
+
 	// Display current status
 	fmt.Println("📊 Current Audio Status:")
 	inputVol, _ := inputChannel.GetVolume()
 	masterVol, _ := masterChannel.GetMasterVolume()
 	inputMuted, _ := inputChannel.GetMute()
-	
+
 	fmt.Printf("  🎤 Input Volume: %.0f%%\n", inputVol*100)
 	fmt.Printf("  🔊 Master Volume: %.0f%%\n", masterVol*100)
 	fmt.Printf("  🔇 Input Muted: %v\n", inputMuted)
@@ -217,10 +240,16 @@ func main() {
 	fmt.Println("")
 	fmt.Println("🎛️  Interactive Controls:")
 	fmt.Println("  'i <volume>'  - Set input volume (0-100)")
-	fmt.Println("  'm <volume>'  - Set master volume (0-100)")  
+	fmt.Println("  'm <volume>'  - Set master volume (0-100)")
 	fmt.Println("  'mute'       - Toggle input mute")
 	fmt.Println("  'status'     - Show current settings")
 	fmt.Println("  'tap'        - Show tap data (if available)")
+	fmt.Println("  'midi <deviceIndex>' - Open a MIDI controller and map CC1/CC10 to input volume/pan")
+	fmt.Println("  'save <name>'       - Save the current mixer state as a named scene")
+	fmt.Println("  'recall <name>'     - Instantly recall a named scene")
+	fmt.Println("  'morph <name> <ms>' - Glide to a named scene over <ms> milliseconds")
+	fmt.Println("  'record <path>'     - Record the mic input to a .wav or .caf file")
+	fmt.Println("  'stop-record'       - Stop recording and report stats")
 	fmt.Println("  'quit'       - Exit")
 	fmt.Println("")
 	fmt.Println("🔊 You should now hear microphone input through your speakers!")
@@ -228,25 +257,25 @@ func main() {
 	fmt.Println("")
 
 	scanner := bufio.NewScanner(os.Stdin)
-	
+
 	for {
 		fmt.Print("macaudio> ")
 		if !scanner.Scan() {
 			break
 		}
-		
+
 		command := strings.TrimSpace(scanner.Text())
 		parts := strings.Fields(command)
-		
+
 		if len(parts) == 0 {
 			continue
 		}
-		
+
 		switch strings.ToLower(parts[0]) {
 		case "quit", "exit", "q":
 			fmt.Println("👋 Goodbye!")
 			return
-			
+
 		case "i", "input":
 			if len(parts) < 2 {
 				fmt.Println("Usage: i <volume> (0-100)")
@@ -257,13 +286,13 @@ func main() {
 				fmt.Println("Invalid volume. Use 0-100")
 				continue
 			}
-			
+
 			if err := inputChannel.SetVolume(float32(vol) / 100.0); err != nil {
 				fmt.Printf("❌ Failed to set input volume: %v\n", err)
 			} else {
 				fmt.Printf("✅ Input volume set to %d%%\n", vol)
 			}
-			
+
 		case "m", "master":
 			if len(parts) < 2 {
 				fmt.Println("Usage: m <volume> (0-100)")
@@ -274,17 +303,17 @@ func main() {
 				fmt.Println("Invalid volume. Use 0-100")
 				continue
 			}
-			
+
 			if err := masterChannel.SetMasterVolume(float32(vol) / 100.0); err != nil {
 				fmt.Printf("❌ Failed to set master volume: %v\n", err)
 			} else {
 				fmt.Printf("✅ Master volume set to %d%%\n", vol)
 			}
-			
+
 		case "mute":
 			currentMute, _ := inputChannel.GetMute()
 			newMute := !currentMute
-			
+
 			if err := inputChannel.SetMute(newMute); err != nil {
 				fmt.Printf("❌ Failed to toggle mute: %v\n", err)
 			} else {
@@ -294,25 +323,29 @@ func main() {
 					fmt.Println("🔊 Input unmuted")
 				}
 			}
-			
+
 		case "status":
 			inputVol, _ := inputChannel.GetVolume()
 			masterVol, _ := masterChannel.GetMasterVolume()
 			inputMuted, _ := inputChannel.GetMute()
-			
+
+			dispatcherStats := audioEngine.GetDispatcher().GetPerformanceStats()
+
 			fmt.Println("📊 Current Status:")
 			fmt.Printf("  🎤 Input Volume: %.0f%%\n", inputVol*100)
 			fmt.Printf("  🔊 Master Volume: %.0f%%\n", masterVol*100)
 			fmt.Printf("  🔇 Input Muted: %v\n", inputMuted)
 			fmt.Printf("  🚀 Engine Running: %v\n", audioEngine.IsRunning())
-			
+			fmt.Printf("  🎛️  Param lane: depth=%d coalesced=%.0f%%\n", dispatcherStats.ParamLaneDepth, dispatcherStats.ParamLaneCoalesceRatio*100)
+			fmt.Printf("  ⏱️  Latency p50/p99/p999: %v / %v / %v\n", dispatcherStats.LatencyP50, dispatcherStats.LatencyP99, dispatcherStats.LatencyP999)
+
 		case "tap":
 			if inputTap != nil && inputTap.IsInstalled() {
 				// Show real-time tap data for 3 seconds
 				fmt.Println("📊 Live Audio Tap Data (3 seconds):")
 				fmt.Println("  RMS Level  | Frame Count | Status")
 				fmt.Println("  -----------|-------------|--------")
-				
+
 				start := time.Now()
 				for time.Since(start) < 3*time.Second {
 					metrics, err := inputTap.GetMetrics()
@@ -320,7 +353,7 @@ func main() {
 						fmt.Printf("  Error: %v\n", err)
 						break
 					}
-					
+
 					// Convert RMS to dB for more readable display
 					var rmsDb string
 					if metrics.RMS > 0.0001 { // Avoid log(0)
@@ -333,17 +366,17 @@ func main() {
 					} else {
 						rmsDb = "Silent"
 					}
-					
+
 					// Create simple visual bar
 					barLength := int(metrics.RMS * 50) // Scale to 50 chars max
 					if barLength > 50 {
 						barLength = 50
 					}
 					bar := strings.Repeat("█", barLength) + strings.Repeat("░", 50-barLength)
-					
-					fmt.Printf("\r  %-9s | %11d | %s [%s]", 
+
+					fmt.Printf("\r  %-9s | %11d | %s [%s]",
 						rmsDb, metrics.FrameCount, "Active", bar)
-					
+
 					time.Sleep(100 * time.Millisecond)
 				}
 				fmt.Println("\n📊 Tap monitoring complete")
@@ -355,7 +388,117 @@ func main() {
 				fmt.Println("    • Input channel not connected")
 				fmt.Println("    • Native pointer unavailable")
 			}
-			
+
+		case "midi":
+			if len(parts) != 2 {
+				fmt.Println("Usage: midi <deviceIndex>")
+				continue
+			}
+			deviceIndex, err := strconv.Atoi(parts[1])
+			if err != nil {
+				fmt.Println("Invalid device index")
+				continue
+			}
+
+			if midiController != nil {
+				midiController.Close()
+				midiController = nil
+			}
+			midiController, err = avmidi.Open(deviceIndex)
+			if err != nil {
+				fmt.Printf("❌ Failed to open MIDI device %d: %v\n", deviceIndex, err)
+				continue
+			}
+
+			mapper := midimap.NewMapper(audioEngine, midiController)
+			if err := mapper.ApplyDefaultMapping(inputChannel.GetIDString()); err != nil {
+				fmt.Printf("❌ Failed to install default MIDI mapping: %v\n", err)
+				continue
+			}
+			fmt.Printf("✅ MIDI device %d mapped: CC1 -> input volume, CC10 -> input pan\n", deviceIndex)
+
+		case "save":
+			if len(parts) != 2 {
+				fmt.Println("Usage: save <name>")
+				continue
+			}
+			if err := scenes.SaveScene(parts[1]); err != nil {
+				fmt.Printf("❌ Failed to save scene %q: %v\n", parts[1], err)
+			} else {
+				fmt.Printf("✅ Scene %q saved\n", parts[1])
+			}
+
+		case "recall":
+			if len(parts) != 2 {
+				fmt.Println("Usage: recall <name>")
+				continue
+			}
+			if err := scenes.Recall(parts[1], 0); err != nil {
+				fmt.Printf("❌ Failed to recall scene %q: %v\n", parts[1], err)
+			} else {
+				fmt.Printf("✅ Scene %q recalled\n", parts[1])
+			}
+
+		case "morph":
+			if len(parts) != 3 {
+				fmt.Println("Usage: morph <name> <ms>")
+				continue
+			}
+			ms, err := strconv.Atoi(parts[2])
+			if err != nil || ms < 0 {
+				fmt.Println("Invalid duration in ms")
+				continue
+			}
+			done, err := scenes.Morph(parts[1], time.Duration(ms)*time.Millisecond)
+			if err != nil {
+				fmt.Printf("❌ Failed to morph to scene %q: %v\n", parts[1], err)
+				continue
+			}
+			fmt.Printf("✅ Morphing to scene %q over %dms...\n", parts[1], ms)
+			go func() {
+				<-done
+				fmt.Printf("\n✅ Morph to %q complete\n", parts[1])
+			}()
+
+		case "record":
+			if len(parts) != 2 {
+				fmt.Println("Usage: record <path.wav|path.caf>")
+				continue
+			}
+			if recorder != nil {
+				fmt.Println("❌ Already recording; run stop-record first")
+				continue
+			}
+			if enginePtr == nil || inputNodePtr == nil {
+				fmt.Println("❌ Unable to record - engine or node pointer unavailable")
+				continue
+			}
+			format := tap.RecorderFormatWAV
+			if strings.HasSuffix(strings.ToLower(parts[1]), ".caf") {
+				format = tap.RecorderFormatCAF
+			}
+			var err error
+			recorder, err = tap.InstallRecorder(enginePtr, inputNodePtr, 0, parts[1], format, 64)
+			if err != nil {
+				fmt.Printf("❌ Failed to start recording: %v\n", err)
+				recorder = nil
+				continue
+			}
+			fmt.Printf("✅ Recording input to %s\n", parts[1])
+
+		case "stop-record":
+			if recorder == nil {
+				fmt.Println("❌ Not currently recording")
+				continue
+			}
+			stats := recorder.Stats()
+			if err := recorder.Stop(); err != nil {
+				fmt.Printf("⚠️ Failed to stop recording cleanly: %v\n", err)
+			}
+			recorder = nil
+			fmt.Printf("✅ Recording stopped: %d frames written, peak %.3f, RMS %.3f, %d overruns\n",
+				stats.FramesWritten, stats.Peak, stats.RMS, stats.Overruns)
+
 		case "help", "h":
 			fmt.Println("Available commands:")
 			fmt.Println("  i <volume>   - Set input volume (0-100)")
@@ -363,8 +506,14 @@ func main() {
 			fmt.Println("  mute         - Toggle input mute")
 			fmt.Println("  status       - Show current settings")
 			fmt.Println("  tap          - Show tap data")
+			fmt.Println("  midi <deviceIndex> - Open a MIDI controller and map CC1/CC10 to input volume/pan")
+			fmt.Println("  save <name>       - Save the current mixer state as a named scene")
+			fmt.Println("  recall <name>     - Instantly recall a named scene")
+			fmt.Println("  morph <name> <ms> - Glide to a named scene over <ms> milliseconds")
+			fmt.Println("  record <path>     - Record the mic input to a .wav or .caf file")
+			fmt.Println("  stop-record       - Stop recording and report stats")
 			fmt.Println("  quit         - Exit")
-			
+
 		default:
 			fmt.Printf("Unknown command: %s (type 'help' for available commands)\n", parts[0])
 		}
@@ -0,0 +1,34 @@
+// Package decoder streams decoded PCM in blocks from containers
+// AVAudioFile can't open directly (FLAC/MP3/Opus), for
+// engine.CreatePlaybackChannelFromDecoder to schedule onto an
+// AVAudioPlayerNode. It's shaped like the top-level macaudio package's
+// Decoder (see ../../decoders.go), but a Decoder here keeps each
+// container's native sample type on AudioBlock instead of committing
+// every implementation to a float32 conversion up front - FLAC's frames
+// stay int32, MP3's stay int16, only Opus (decoded through libopusfile's
+// float API) is float32.
+package decoder
+
+// AudioBlock is one decoded block of PCM delivered on a Decoder's Blocks
+// channel. Samples holds the container's native sample type - []int16,
+// []int32, or []float32 - so a caller (or
+// engine.CreatePlaybackChannelFromDecoder) can type-switch on it to pick
+// the conversion, rather than every Decoder paying for a float32 pass it
+// may not need.
+type AudioBlock struct {
+	Samples     any
+	SampleRate  int
+	Channels    int
+	Interleaved bool
+}
+
+// Decoder streams decoded PCM from a source in AudioBlocks. See
+// FLACDecoder, MP3Decoder, and OpusDecoder for concrete sources.
+type Decoder interface {
+	// Blocks returns the channel AudioBlocks are delivered on. It is
+	// closed once the source is exhausted or Close is called.
+	Blocks() <-chan AudioBlock
+	// Close releases the decoder's resources and unblocks any pending
+	// read of Blocks.
+	Close() error
+}
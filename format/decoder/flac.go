@@ -0,0 +1,81 @@
+package decoder
+
+import (
+	"io"
+	"sync"
+
+	"github.com/mewkiz/flac"
+)
+
+// FLACDecoder streams a FLAC source via mewkiz/flac, interleaving each
+// frame's per-channel int32 samples - FLAC subframes are always signed
+// integers up to 32 bits wide - rather than pre-scaling to float32 the
+// way the top-level macaudio package's flacDecoder does (see
+// ../../decoders_flac.go), so a caller can build the destination node's
+// format to match the stream's own bit depth.
+type FLACDecoder struct {
+	stream     *flac.Stream
+	blocks     chan AudioBlock
+	sampleRate int
+	channels   int
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// OpenFLAC opens a FLAC stream from r and starts decoding it on a
+// background goroutine; call Close to stop early.
+func OpenFLAC(r io.Reader) (*FLACDecoder, error) {
+	stream, err := flac.New(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &FLACDecoder{
+		stream:     stream,
+		blocks:     make(chan AudioBlock, 2),
+		sampleRate: int(stream.Info.SampleRate),
+		channels:   int(stream.Info.NChannels),
+		done:       make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
+
+func (d *FLACDecoder) run() {
+	defer close(d.blocks)
+
+	for {
+		frame, err := d.stream.ParseNext()
+		if err != nil {
+			return
+		}
+
+		frames := len(frame.Subframes[0].Samples)
+		samples := make([]int32, frames*d.channels)
+		for ch := 0; ch < d.channels; ch++ {
+			subframe := frame.Subframes[ch]
+			for i, s := range subframe.Samples {
+				samples[i*d.channels+ch] = s
+			}
+		}
+
+		block := AudioBlock{
+			Samples:     samples,
+			SampleRate:  d.sampleRate,
+			Channels:    d.channels,
+			Interleaved: true,
+		}
+		select {
+		case d.blocks <- block:
+		case <-d.done:
+			return
+		}
+	}
+}
+
+func (d *FLACDecoder) Blocks() <-chan AudioBlock { return d.blocks }
+
+func (d *FLACDecoder) Close() error {
+	d.closeOnce.Do(func() { close(d.done) })
+	return d.stream.Close()
+}
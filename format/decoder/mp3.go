@@ -0,0 +1,79 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// mp3BlockFrames is the number of frames decoded per AudioBlock, chosen to
+// keep pace comfortably ahead of playback without over-buffering -
+// matching the top-level macaudio package's mp3Decoder (see
+// ../../decoders_mp3.go).
+const mp3BlockFrames = 4096
+
+// MP3Decoder streams an MP3 source via go-mp3, which always decodes to
+// 16-bit stereo PCM regardless of the source's own encoded layout.
+type MP3Decoder struct {
+	dec       *mp3.Decoder
+	blocks    chan AudioBlock
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// OpenMP3 opens an MP3 stream from r and starts decoding it on a
+// background goroutine; call Close to stop early.
+func OpenMP3(r io.Reader) (*MP3Decoder, error) {
+	dec, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &MP3Decoder{
+		dec:    dec,
+		blocks: make(chan AudioBlock, 2),
+		done:   make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
+
+func (d *MP3Decoder) run() {
+	defer close(d.blocks)
+
+	pcm := make([]byte, mp3BlockFrames*4) // 2 channels * 2 bytes/sample
+	for {
+		n, err := io.ReadFull(d.dec, pcm)
+		if n > 0 {
+			frames := n / 4
+			samples := make([]int16, frames*2)
+			for i := range samples {
+				samples[i] = int16(binary.LittleEndian.Uint16(pcm[i*2:]))
+			}
+
+			block := AudioBlock{
+				Samples:     samples,
+				SampleRate:  d.dec.SampleRate(),
+				Channels:    2,
+				Interleaved: true,
+			}
+			select {
+			case d.blocks <- block:
+			case <-d.done:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (d *MP3Decoder) Blocks() <-chan AudioBlock { return d.blocks }
+
+func (d *MP3Decoder) Close() error {
+	d.closeOnce.Do(func() { close(d.done) })
+	return nil
+}
@@ -0,0 +1,117 @@
+package decoder
+
+/*
+#cgo pkg-config: opusfile
+#include <opusfile.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+// opusBlockFrames is the number of frames read from libopusfile per
+// AudioBlock, matching MP3Decoder's mp3BlockFrames.
+const opusBlockFrames = 4096
+
+// OpusDecoder streams an Ogg Opus source via libopusfile, which (unlike
+// hraban/opus - see ../../decoders_opus.go's stub) demuxes the Ogg
+// container itself rather than only decoding raw Opus packets, so no
+// separate Ogg demuxer is needed here. Samples are delivered as float32
+// via op_read_float, libopusfile's float decode path, always at 48kHz -
+// the rate Opus always decodes to regardless of the source's encode rate.
+//
+// op_open_memory needs the whole encoded stream up front rather than a
+// push/pull callback pair, so OpenOpus reads r to completion before
+// decoding starts; only the PCM delivered on Blocks is actually
+// block-streamed.
+type OpusDecoder struct {
+	file       *C.OggOpusFile
+	data       unsafe.Pointer // C-owned copy of the encoded stream; freed by Close
+	blocks     chan AudioBlock
+	sampleRate int
+	channels   int
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// OpenOpus opens an Ogg Opus stream from r and starts decoding it on a
+// background goroutine; call Close to stop early and release the native
+// decoder.
+func OpenOpus(r io.Reader) (*OpusDecoder, error) {
+	encoded, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) == 0 {
+		return nil, errors.New("decoder: empty opus stream")
+	}
+
+	cData := C.CBytes(encoded)
+
+	var cErr C.int
+	file := C.op_open_memory((*C.uchar)(cData), C.size_t(len(encoded)), &cErr)
+	if file == nil {
+		C.free(cData)
+		return nil, errors.New("decoder: op_open_memory failed")
+	}
+
+	d := &OpusDecoder{
+		file:       file,
+		data:       cData,
+		blocks:     make(chan AudioBlock, 2),
+		sampleRate: 48000,
+		channels:   int(C.op_channel_count(file, -1)),
+		done:       make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
+
+func (d *OpusDecoder) run() {
+	defer close(d.blocks)
+
+	buf := make([]C.float, opusBlockFrames*d.channels)
+	for {
+		n := C.op_read_float(d.file, &buf[0], C.int(len(buf)), nil)
+		if n <= 0 {
+			return
+		}
+
+		samples := make([]float32, int(n)*d.channels)
+		for i := range samples {
+			samples[i] = float32(buf[i])
+		}
+
+		block := AudioBlock{
+			Samples:     samples,
+			SampleRate:  d.sampleRate,
+			Channels:    d.channels,
+			Interleaved: true,
+		}
+		select {
+		case d.blocks <- block:
+		case <-d.done:
+			return
+		}
+	}
+}
+
+func (d *OpusDecoder) Blocks() <-chan AudioBlock { return d.blocks }
+
+func (d *OpusDecoder) Close() error {
+	d.closeOnce.Do(func() { close(d.done) })
+	if d.file != nil {
+		C.op_free(d.file)
+		d.file = nil
+	}
+	if d.data != nil {
+		C.free(d.data)
+		d.data = nil
+	}
+	return nil
+}
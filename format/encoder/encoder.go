@@ -0,0 +1,70 @@
+// Package encoder writes PCM blocks to a sink - a file, a network
+// stream, or anything else an io.Writer represents - complementing
+// format/decoder's read side. CaptureChannel.AttachEncoder and
+// PlaybackChannel's InstallTap sink both write through an Encoder rather
+// than bespoke CoreAudio glue.
+package encoder
+
+import "fmt"
+
+// AudioBlock is one block of PCM handed to an Encoder's WriteBlock.
+// Samples holds []int16, []int32, or []float32, mirroring
+// format/decoder.AudioBlock - this package doesn't import that one, since
+// CaptureChannel and PlaybackChannel build blocks directly rather than
+// routing a live tap through a Decoder.
+type AudioBlock struct {
+	Samples     any
+	SampleRate  int
+	Channels    int
+	Interleaved bool
+}
+
+// Encoder writes AudioBlocks to a sink. See WAVWriter, FLACWriter, and
+// OpusEncoder for concrete sinks.
+type Encoder interface {
+	WriteBlock(AudioBlock) error
+	Close() error
+}
+
+// toFloat32 converts samples - []int16, []int32, or []float32, whichever
+// an AudioBlock carries - to float32 in [-1, 1], the common currency every
+// writer in this package converts through before re-quantizing to its own
+// target format. Mirrors engine's blockToFloat32 (decoder_channel.go),
+// kept as a separate local copy rather than a shared dependency since the
+// two packages convert in opposite directions and neither should import
+// the other just for this.
+func toFloat32(samples any) ([]float32, error) {
+	switch s := samples.(type) {
+	case []float32:
+		return s, nil
+	case []int16:
+		out := make([]float32, len(s))
+		for i, v := range s {
+			out[i] = float32(v) / 32768.0
+		}
+		return out, nil
+	case []int32:
+		out := make([]float32, len(s))
+		for i, v := range s {
+			out[i] = float32(v) / 2147483648.0
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("encoder: unsupported sample type %T", samples)
+	}
+}
+
+// saturate scales s (expected in [-1, 1]) by max and clamps to [min, max],
+// so a sample outside [-1, 1] clips at the target format's full-scale
+// value instead of wrapping to the opposite sign - the same policy
+// convert.Convert uses for its own saturating conversions.
+func saturate(s float32, min, max float64) float64 {
+	v := float64(s) * max
+	if v > max {
+		return max
+	}
+	if v < min {
+		return min
+	}
+	return v
+}
@@ -0,0 +1,324 @@
+package encoder
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// FLACWriter writes a valid FLAC stream built from VERBATIM subframes
+// only - no fixed or LPC prediction, so files are larger than a real
+// encoder (flac(1), libFLAC) would produce, but they decode correctly in
+// any FLAC reader, including this repo's own format/decoder.FLACDecoder.
+//
+// No suitable pure-Go FLAC *encoding* library exists alongside the
+// decode-only ones this repo already depends on (mewkiz/flac, used by
+// format/decoder.FLACDecoder, and go-flac/go-flac, which edits metadata
+// in already-encoded files rather than encoding PCM into frames). Rather
+// than bring in prediction and rely on wire compatibility nobody's
+// verified, this writer covers the VERBATIM subset of the format exactly,
+// the same honest-gap approach decoders_opus.go already takes for Opus
+// decoding: real output, reduced scope, documented rather than silently
+// approximated.
+type FLACWriter struct {
+	w          io.Writer
+	sampleRate int
+	channels   int
+	bitDepth   int
+	blockSize  int
+	frameNum   uint64
+	wroteHdr   bool
+}
+
+// FLACOptions configures a FLACWriter. BitDepth must be 16, 24, or 32 and
+// determines the per-sample integer width VERBATIM subframes encode.
+type FLACOptions struct {
+	SampleRate int
+	Channels   int
+	BitDepth   int
+}
+
+const flacBlockSize = 4096
+
+// NewFLACWriter writes the "fLaC" marker and a STREAMINFO metadata block
+// to w using placeholder sample counts (FLAC permits an unknown total
+// sample count in STREAMINFO, so no patch-on-Close is needed here unlike
+// WAVWriter).
+func NewFLACWriter(w io.Writer, opts FLACOptions) (*FLACWriter, error) {
+	if opts.SampleRate <= 0 {
+		return nil, errors.New("encoder: sample rate must be positive")
+	}
+	if opts.Channels <= 0 {
+		return nil, errors.New("encoder: channel count must be positive")
+	}
+	switch opts.BitDepth {
+	case 16, 24, 32:
+	default:
+		return nil, errors.New("encoder: FLAC bit depth must be 16, 24, or 32")
+	}
+
+	fw := &FLACWriter{
+		w:          w,
+		sampleRate: opts.SampleRate,
+		channels:   opts.Channels,
+		bitDepth:   opts.BitDepth,
+		blockSize:  flacBlockSize,
+	}
+	if err := fw.writeStreamHeader(); err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+func (fw *FLACWriter) writeStreamHeader() error {
+	if _, err := fw.w.Write([]byte("fLaC")); err != nil {
+		return err
+	}
+
+	// STREAMINFO is a fixed 34-byte block; see the FLAC format spec's
+	// METADATA_BLOCK_STREAMINFO layout.
+	var info [34]byte
+	binary.BigEndian.PutUint16(info[0:2], uint16(fw.blockSize)) // min block size
+	binary.BigEndian.PutUint16(info[2:4], uint16(fw.blockSize)) // max block size
+	// bytes 4:7 min frame size, 7:10 max frame size left as 0 (unknown)
+	packed := uint64(fw.sampleRate)<<44 | uint64(fw.channels-1)<<41 | uint64(fw.bitDepth-1)<<36
+	var packedBytes [8]byte
+	binary.BigEndian.PutUint64(packedBytes[:], packed)
+	copy(info[10:18], packedBytes[2:8]) // 20 bits rate, 3 bits channels-1, 5 bits bps-1, 36 bits total samples (0 = unknown)
+	// info[18:34] MD5 of unencoded audio left as 0 (optional - all zero
+	// means "not computed", which decoders must accept per spec)
+
+	var blockHdr [4]byte
+	blockHdr[0] = 0x80 | 0 // last-metadata-block flag set, type 0 = STREAMINFO
+	length := len(info)
+	blockHdr[1] = byte(length >> 16)
+	blockHdr[2] = byte(length >> 8)
+	blockHdr[3] = byte(length)
+
+	if _, err := fw.w.Write(blockHdr[:]); err != nil {
+		return err
+	}
+	_, err := fw.w.Write(info[:])
+	fw.wroteHdr = true
+	return err
+}
+
+// WriteBlock quantizes block's samples to this writer's bit depth and
+// emits one FLAC frame of up to flacBlockSize samples per channel, using
+// a VERBATIM subframe for each channel.
+func (fw *FLACWriter) WriteBlock(block AudioBlock) error {
+	if !fw.wroteHdr {
+		return errors.New("encoder: write before header")
+	}
+
+	samples, err := toFloat32(block.Samples)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	frames := len(samples) / fw.channels
+	planar := make([][]int32, fw.channels)
+	for ch := range planar {
+		planar[ch] = make([]int32, frames)
+	}
+	max := float64(int64(1)<<(fw.bitDepth-1) - 1)
+	min := -float64(int64(1) << (fw.bitDepth - 1))
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < fw.channels; ch++ {
+			planar[ch][i] = int32(saturate(samples[i*fw.channels+ch], min, max))
+		}
+	}
+
+	for start := 0; start < frames; start += fw.blockSize {
+		end := start + fw.blockSize
+		if end > frames {
+			end = frames
+		}
+		chunk := make([][]int32, fw.channels)
+		for ch := range planar {
+			chunk[ch] = planar[ch][start:end]
+		}
+		if err := fw.writeFrame(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFrame encodes one FLAC frame: a frame header, one VERBATIM
+// subframe per channel, byte-aligned padding, and a CRC-16 frame
+// footer - see the FLAC format spec's frame layout.
+func (fw *FLACWriter) writeFrame(channels [][]int32) error {
+	bw := newBitWriter()
+
+	blockSize := len(channels[0])
+	bw.writeBits(0x3FFE, 14) // sync code
+	bw.writeBits(0, 1)       // reserved
+	bw.writeBits(1, 1)       // blocking strategy: variable
+
+	blockSizeCode, blockSizeSuffixBits, blockSizeSuffix := flacBlockSizeCode(blockSize)
+	bw.writeBits(uint64(blockSizeCode), 4)
+
+	sampleRateCode := 0 // 0 = get from STREAMINFO
+	bw.writeBits(uint64(sampleRateCode), 4)
+
+	channelAssignment := fw.channels - 1 // independent channels (0..7 => 1..8 channels)
+	bw.writeBits(uint64(channelAssignment), 4)
+
+	bps := flacBitDepthCode(fw.bitDepth)
+	bw.writeBits(uint64(bps), 3)
+	bw.writeBits(0, 1) // reserved
+
+	writeUTF8(bw, fw.frameNum)
+	fw.frameNum++
+
+	if blockSizeSuffixBits > 0 {
+		bw.writeBits(uint64(blockSizeSuffix), blockSizeSuffixBits)
+	}
+
+	bw.align()
+	headerChecksum := crc8(bw.bytes())
+	bw.writeBits(uint64(headerChecksum), 8)
+
+	for ch := range channels {
+		bw.writeBits(0, 1) // subframe "zero bit"
+		bw.writeBits(1, 6) // subframe type: VERBATIM = 0b000001
+		bw.writeBits(0, 1) // no wasted bits
+		for _, s := range channels[ch] {
+			bw.writeBits(uint64(uint32(s))&((1<<fw.bitDepth)-1), fw.bitDepth)
+		}
+	}
+	bw.align()
+
+	frameBytes := bw.bytes()
+	checksum := crc16(frameBytes)
+	var footer [2]byte
+	binary.BigEndian.PutUint16(footer[:], checksum)
+
+	if _, err := fw.w.Write(frameBytes); err != nil {
+		return err
+	}
+	_, err := fw.w.Write(footer[:])
+	return err
+}
+
+// Close writes nothing further - FLAC needs no trailing marker - and
+// exists only to satisfy Encoder.
+func (fw *FLACWriter) Close() error { return nil }
+
+// flacBlockSizeCode returns the 4-bit block-size code and any suffix
+// bits/value a FLAC frame header must carry for n samples. flacBlockSize
+// (4096) maps to a reserved code needing no suffix; a final, shorter
+// block falls back to the 16-bit "read from end of header" encoding.
+func flacBlockSizeCode(n int) (code int, suffixBits int, suffix int) {
+	if n == 4096 {
+		return 12, 0, 0
+	}
+	return 7, 16, n - 1
+}
+
+// flacBitDepthCode maps a sample bit depth to FLAC's 3-bit sample-size
+// code (see the frame header's "sample size in bits" field).
+func flacBitDepthCode(bitDepth int) int {
+	switch bitDepth {
+	case 16:
+		return 4
+	case 24:
+		return 6
+	default: // 32 has no direct code; get it from STREAMINFO
+		return 0
+	}
+}
+
+// writeUTF8 writes n using FLAC's UTF-8-like variable-length frame number
+// encoding (borrowed from UTF-8's continuation-byte scheme, but extended
+// to cover a wider range than real UTF-8 permits).
+func writeUTF8(bw *bitWriter, n uint64) {
+	switch {
+	case n < 0x80:
+		bw.writeBits(n, 8)
+	case n < 0x800:
+		bw.writeBits(0xC0|(n>>6), 8)
+		bw.writeBits(0x80|(n&0x3F), 8)
+	case n < 0x10000:
+		bw.writeBits(0xE0|(n>>12), 8)
+		bw.writeBits(0x80|((n>>6)&0x3F), 8)
+		bw.writeBits(0x80|(n&0x3F), 8)
+	default:
+		bw.writeBits(0xF0|(n>>18), 8)
+		bw.writeBits(0x80|((n>>12)&0x3F), 8)
+		bw.writeBits(0x80|((n>>6)&0x3F), 8)
+		bw.writeBits(0x80|(n&0x3F), 8)
+	}
+}
+
+// bitWriter accumulates bits MSB-first into a byte slice, the bit order
+// FLAC's frame/subframe fields use throughout.
+type bitWriter struct {
+	buf     []byte
+	cur     byte
+	curBits int
+}
+
+func newBitWriter() *bitWriter { return &bitWriter{} }
+
+func (bw *bitWriter) writeBits(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		bit := byte((v >> uint(i)) & 1)
+		bw.cur = bw.cur<<1 | bit
+		bw.curBits++
+		if bw.curBits == 8 {
+			bw.buf = append(bw.buf, bw.cur)
+			bw.cur = 0
+			bw.curBits = 0
+		}
+	}
+}
+
+func (bw *bitWriter) align() {
+	if bw.curBits > 0 {
+		bw.cur <<= uint(8 - bw.curBits)
+		bw.buf = append(bw.buf, bw.cur)
+		bw.cur = 0
+		bw.curBits = 0
+	}
+}
+
+func (bw *bitWriter) bytes() []byte { return bw.buf }
+
+// crc8 computes the CRC-8 checksum (polynomial 0x07) FLAC uses for its
+// frame header.
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// crc16 computes the CRC-16 checksum (polynomial 0x8005) FLAC uses for
+// its frame footer.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,151 @@
+//go:build mp3lame
+
+package encoder
+
+/*
+#cgo pkg-config: mp3lame
+#include <lame/lame.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// mp3EncodeBufSlack is how much headroom MP3Writer allocates on top of the
+// PCM byte count for lame_encode_buffer_ieee_float's worst case - libmp3lame's
+// own documentation recommends 1.25x the input samples plus 7200 bytes.
+const mp3EncodeBufSlack = 7200
+
+// MP3Options configures an MP3Writer.
+type MP3Options struct {
+	SampleRate int
+	Channels   int
+	Bitrate    int // kbps, e.g. 192; 0 uses libmp3lame's default
+}
+
+// MP3Writer encodes PCM blocks to a raw MP3 stream via libmp3lame - this
+// repo's only MP3 *encode* path (format/decoder's MP3 support, and the
+// go-mp3 dependency decoders_mp3.go uses, are decode-only). Building with
+// this requires libmp3lame and its pkg-config file installed; the mp3lame
+// build tag keeps that system dependency out of the default build, the
+// same way avaudio/engine/osc's build stays cgo-free until a caller opts
+// into a heavier backend.
+type MP3Writer struct {
+	w      io.Writer
+	gfp    *C.lame_global_flags
+	closed bool
+}
+
+// NewMP3Writer initializes a libmp3lame encoder writing to w.
+func NewMP3Writer(w io.Writer, opts MP3Options) (*MP3Writer, error) {
+	if opts.SampleRate <= 0 {
+		return nil, errors.New("encoder: sample rate must be positive")
+	}
+	if opts.Channels <= 0 {
+		return nil, errors.New("encoder: channel count must be positive")
+	}
+
+	gfp := C.lame_init()
+	if gfp == nil {
+		return nil, errors.New("encoder: lame_init failed")
+	}
+
+	C.lame_set_in_samplerate(gfp, C.int(opts.SampleRate))
+	C.lame_set_num_channels(gfp, C.int(opts.Channels))
+	if opts.Bitrate > 0 {
+		C.lame_set_brate(gfp, C.int(opts.Bitrate))
+	}
+	if C.lame_init_params(gfp) < 0 {
+		C.lame_close(gfp)
+		return nil, errors.New("encoder: lame_init_params failed")
+	}
+
+	return &MP3Writer{w: w, gfp: gfp}, nil
+}
+
+// WriteBlock encodes block's samples (converted to float32 via toFloat32,
+// mirroring every other writer in this package) and appends the resulting
+// MP3 frames to the underlying stream.
+func (mw *MP3Writer) WriteBlock(block AudioBlock) error {
+	if mw.closed {
+		return errors.New("encoder: write after close")
+	}
+
+	samples, err := toFloat32(block.Samples)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	channels := int(C.lame_get_num_channels(mw.gfp))
+	frames := len(samples) / channels
+
+	out := make([]byte, frames+frames/4+mp3EncodeBufSlack)
+
+	if channels == 1 {
+		n := C.lame_encode_buffer_ieee_float(
+			mw.gfp,
+			(*C.float)(unsafe.Pointer(&samples[0])),
+			nil,
+			C.int(frames),
+			(*C.uchar)(unsafe.Pointer(&out[0])),
+			C.int(len(out)),
+		)
+		return mw.flushEncoded(n, out)
+	}
+
+	left := make([]float32, frames)
+	right := make([]float32, frames)
+	for i := 0; i < frames; i++ {
+		left[i] = samples[i*channels]
+		right[i] = samples[i*channels+1]
+	}
+	n := C.lame_encode_buffer_ieee_float(
+		mw.gfp,
+		(*C.float)(unsafe.Pointer(&left[0])),
+		(*C.float)(unsafe.Pointer(&right[0])),
+		C.int(frames),
+		(*C.uchar)(unsafe.Pointer(&out[0])),
+		C.int(len(out)),
+	)
+	return mw.flushEncoded(n, out)
+}
+
+func (mw *MP3Writer) flushEncoded(n C.int, out []byte) error {
+	if n < 0 {
+		return errors.New("encoder: lame_encode_buffer_ieee_float failed")
+	}
+	if n == 0 {
+		return nil
+	}
+	_, err := mw.w.Write(out[:n])
+	return err
+}
+
+// Close flushes any buffered MP3 frames and releases the libmp3lame
+// encoder. Safe to call more than once.
+func (mw *MP3Writer) Close() error {
+	if mw.closed {
+		return nil
+	}
+	mw.closed = true
+
+	out := make([]byte, mp3EncodeBufSlack)
+	n := C.lame_encode_flush(mw.gfp, (*C.uchar)(unsafe.Pointer(&out[0])), C.int(len(out)))
+	C.lame_close(mw.gfp)
+
+	if n < 0 {
+		return errors.New("encoder: lame_encode_flush failed")
+	}
+	if n == 0 {
+		return nil
+	}
+	_, err := mw.w.Write(out[:n])
+	return err
+}
@@ -0,0 +1,37 @@
+//go:build !mp3lame
+
+package encoder
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrMP3Unsupported is returned by NewMP3Writer when this package was built
+// without the mp3lame tag (see mp3lame.go) - libmp3lame is a system
+// dependency this repo doesn't otherwise require, so MP3 encoding is opt-in
+// rather than breaking every other build that doesn't have it installed.
+var ErrMP3Unsupported = errors.New("encoder: MP3 encoding requires building with the mp3lame tag (go build -tags mp3lame) and libmp3lame installed")
+
+// MP3Options configures an MP3Writer. Kept in sync with mp3lame.go's
+// build-tagged definition so callers can reference it regardless of how
+// this package was built.
+type MP3Options struct {
+	SampleRate int
+	Channels   int
+	Bitrate    int
+}
+
+// MP3Writer is the non-mp3lame stub: NewMP3Writer always fails with
+// ErrMP3Unsupported, and these methods exist only so code written against
+// the mp3lame build still type-checks without the tag.
+type MP3Writer struct{}
+
+// NewMP3Writer always returns ErrMP3Unsupported in builds without the
+// mp3lame tag.
+func NewMP3Writer(w io.Writer, opts MP3Options) (*MP3Writer, error) {
+	return nil, ErrMP3Unsupported
+}
+
+func (mw *MP3Writer) WriteBlock(block AudioBlock) error { return ErrMP3Unsupported }
+func (mw *MP3Writer) Close() error                      { return nil }
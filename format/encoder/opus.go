@@ -0,0 +1,230 @@
+package encoder
+
+/*
+#cgo pkg-config: opusenc
+#include <opusenc.h>
+#include <stdlib.h>
+#include <stdint.h>
+
+// go_write and go_close are exported by this file (see the //export
+// comments below); _cgo_export.h isn't available yet while this preamble
+// itself is compiling, so they're forward-declared here with the exact
+// signature cgo will generate, the same self-referential-callback pattern
+// plugins/scan_hooks.go's native side would need if its scan loop called
+// straight into a C function pointer rather than through a native entry
+// point.
+extern int go_write(void *user_data, const unsigned char *ptr, opus_int32 len);
+extern int go_close(void *user_data);
+
+static OpusEncCallbacks opusGoCallbacks = {
+	go_write,
+	go_close,
+};
+
+// ope_encoder_ctl is variadic, which cgo cannot call directly; these
+// wrappers give each OPE_SET_* option a fixed-arity C entry point instead.
+static int opusenc_set_bitrate(OggOpusEnc *enc, opus_int32 bitrate) {
+	return ope_encoder_ctl(enc, OPE_SET_BITRATE_REQUEST, bitrate);
+}
+
+static int opusenc_set_complexity(OggOpusEnc *enc, opus_int32 complexity) {
+	return ope_encoder_ctl(enc, OPE_SET_COMPLEXITY_REQUEST, complexity);
+}
+
+static OggOpusEnc *opusenc_create(uintptr_t handle, opus_int32 rate, int channels, int family, int *error) {
+	OggOpusComments *comments = ope_comments_create();
+	return ope_encoder_create_callbacks(&opusGoCallbacks, (void *)handle, comments, rate, channels, family, error);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+// OpusApplication selects libopusenc's encoding profile, trading latency
+// for quality differently depending on the signal - see opus_defines.h's
+// OPUS_APPLICATION_* constants, which these map to directly.
+type OpusApplication int
+
+const (
+	OpusApplicationAudio OpusApplication = iota
+	OpusApplicationVoIP
+	OpusApplicationLowDelay
+)
+
+func (a OpusApplication) cValue() C.int {
+	switch a {
+	case OpusApplicationVoIP:
+		return C.OPUS_APPLICATION_VOIP
+	case OpusApplicationLowDelay:
+		return C.OPUS_APPLICATION_RESTRICTED_LOWDELAY
+	default:
+		return C.OPUS_APPLICATION_AUDIO
+	}
+}
+
+// OpusOptions configures an OpusEncoder. Bitrate is in bits per second;
+// Complexity is libopusenc's 0-10 encode-effort knob (0 is fastest, 10 is
+// highest quality for a given bitrate). Zero values fall back to
+// libopusenc's own defaults.
+type OpusOptions struct {
+	SampleRate  int
+	Channels    int
+	Application OpusApplication
+	Bitrate     int
+	Complexity  int
+}
+
+// opusEncoderRegistry maps a handle to the OpusEncoder libopusenc's write
+// and close callbacks should write into, the same uintptr-handle
+// workaround plugins/scan_hooks.go uses with an int64 scan ID - cgo
+// forbids passing a Go pointer through a C void* (user_data here), so the
+// encoder is looked up by an opaque integer handle instead.
+var (
+	opusEncoderRegistryMu sync.Mutex
+	opusEncoderRegistry   = make(map[uintptr]*OpusEncoder)
+	nextOpusHandle        uintptr
+)
+
+func registerOpusEncoder(e *OpusEncoder) uintptr {
+	opusEncoderRegistryMu.Lock()
+	defer opusEncoderRegistryMu.Unlock()
+	nextOpusHandle++
+	handle := nextOpusHandle
+	opusEncoderRegistry[handle] = e
+	return handle
+}
+
+func unregisterOpusEncoder(handle uintptr) {
+	opusEncoderRegistryMu.Lock()
+	delete(opusEncoderRegistry, handle)
+	opusEncoderRegistryMu.Unlock()
+}
+
+func lookupOpusEncoder(handle uintptr) *OpusEncoder {
+	opusEncoderRegistryMu.Lock()
+	defer opusEncoderRegistryMu.Unlock()
+	return opusEncoderRegistry[handle]
+}
+
+//export go_write
+func go_write(userData unsafe.Pointer, ptr *C.uchar, length C.opus_int32) C.int {
+	e := lookupOpusEncoder(uintptr(userData))
+	if e == nil {
+		return -1
+	}
+	data := C.GoBytes(unsafe.Pointer(ptr), C.int(length))
+	if _, err := e.w.Write(data); err != nil {
+		e.writeErr = err
+		return -1
+	}
+	return 0
+}
+
+//export go_close
+func go_close(userData unsafe.Pointer) C.int {
+	return 0
+}
+
+// OpusEncoder writes an Ogg Opus stream to w via libopusenc, which
+// packages Opus frames into Ogg pages itself, mirroring how
+// format/decoder.OpusDecoder uses libopusfile to demux Ogg on the read
+// side. Encoded bytes reach w through go_write, a cgo export libopusenc's
+// OpusEncCallbacks calls on its own schedule as pages fill - not
+// synchronously within WriteBlock.
+type OpusEncoder struct {
+	enc      *C.OggOpusEnc
+	handle   uintptr
+	w        io.Writer
+	channels int
+	writeErr error
+	closed   bool
+}
+
+// NewOpusEncoder creates an Opus encoder writing to w. SampleRate must be
+// one of Opus's supported rates (8000, 12000, 16000, 24000, or 48000);
+// Channels must be 1 or 2.
+func NewOpusEncoder(w io.Writer, opts OpusOptions) (*OpusEncoder, error) {
+	if opts.Channels <= 0 {
+		return nil, errors.New("encoder: channel count must be positive")
+	}
+	if opts.SampleRate <= 0 {
+		return nil, errors.New("encoder: sample rate must be positive")
+	}
+
+	e := &OpusEncoder{w: w, channels: opts.Channels}
+	e.handle = registerOpusEncoder(e)
+
+	var cErr C.int
+	enc := C.opusenc_create(
+		C.uintptr_t(e.handle),
+		C.opus_int32(opts.SampleRate),
+		C.int(opts.Channels),
+		0, // family 0: mono/stereo mapping
+		&cErr,
+	)
+	if enc == nil {
+		unregisterOpusEncoder(e.handle)
+		return nil, errors.New("encoder: ope_encoder_create_callbacks failed")
+	}
+	e.enc = enc
+
+	C.ope_encoder_ctl(enc, C.OPE_SET_APPLICATION_REQUEST, opts.Application.cValue())
+	if opts.Bitrate > 0 {
+		C.opusenc_set_bitrate(enc, C.opus_int32(opts.Bitrate))
+	}
+	if opts.Complexity > 0 {
+		C.opusenc_set_complexity(enc, C.opus_int32(opts.Complexity))
+	}
+
+	return e, nil
+}
+
+// WriteBlock converts block's samples to float32 and encodes them.
+// libopusenc may or may not flush a page to w before this call returns;
+// call Close to force the final pages out.
+func (e *OpusEncoder) WriteBlock(block AudioBlock) error {
+	if e.closed {
+		return errors.New("encoder: write after close")
+	}
+	if e.writeErr != nil {
+		return e.writeErr
+	}
+
+	samples, err := toFloat32(block.Samples)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	frames := len(samples) / e.channels
+	res := C.ope_encoder_write_float(e.enc, (*C.float)(unsafe.Pointer(&samples[0])), C.int(frames))
+	if res != 0 {
+		return errors.New("encoder: ope_encoder_write_float failed")
+	}
+	return e.writeErr
+}
+
+// Close drains any buffered Opus pages, writes them to w via go_write,
+// and releases the native encoder.
+func (e *OpusEncoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	res := C.ope_encoder_drain(e.enc)
+	C.ope_encoder_destroy(e.enc)
+	unregisterOpusEncoder(e.handle)
+
+	if res != 0 {
+		return errors.New("encoder: ope_encoder_drain failed")
+	}
+	return e.writeErr
+}
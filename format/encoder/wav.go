@@ -0,0 +1,159 @@
+package encoder
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// WAVBitDepth selects the integer PCM width a WAVWriter quantizes to;
+// WAVFloat32 instead writes IEEE-float samples and ignores bit depth.
+type WAVBitDepth int
+
+const (
+	WAVPCM16 WAVBitDepth = 16
+	WAVPCM24 WAVBitDepth = 24
+	WAVPCM32 WAVBitDepth = 32
+)
+
+// WAVOptions configures a WAVWriter. Float32 takes priority over
+// BitDepth when set.
+type WAVOptions struct {
+	SampleRate int
+	Channels   int
+	BitDepth   WAVBitDepth
+	Float32    bool
+}
+
+// WAVWriter writes a RIFF/WAVE file to w, one AudioBlock at a time. The
+// 44-byte header is written up front with placeholder sizes, then patched
+// on Close once the real data length is known - w must support Seek for
+// that patch, same two-pass approach avaudio/format's readers and the
+// recorder package's writers already use for RIFF-style containers.
+type WAVWriter struct {
+	w         io.WriteSeeker
+	opts      WAVOptions
+	dataBytes int64
+	closed    bool
+}
+
+// NewWAVWriter writes a provisional 44-byte WAV header to w and returns a
+// WAVWriter ready to accept blocks. Close must be called to patch the
+// header's size fields, even if no audio was written.
+func NewWAVWriter(w io.WriteSeeker, opts WAVOptions) (*WAVWriter, error) {
+	if opts.SampleRate <= 0 {
+		return nil, errors.New("encoder: sample rate must be positive")
+	}
+	if opts.Channels <= 0 {
+		return nil, errors.New("encoder: channel count must be positive")
+	}
+
+	ww := &WAVWriter{w: w, opts: opts}
+	if err := ww.writeHeader(0); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+func (ww *WAVWriter) bytesPerSample() int {
+	if ww.opts.Float32 {
+		return 4
+	}
+	return int(ww.opts.BitDepth) / 8
+}
+
+func (ww *WAVWriter) writeHeader(dataBytes int64) error {
+	bytesPerSample := ww.bytesPerSample()
+	blockAlign := bytesPerSample * ww.opts.Channels
+	byteRate := ww.opts.SampleRate * blockAlign
+
+	audioFormat := uint16(1) // PCM
+	if ww.opts.Float32 {
+		audioFormat = 3 // IEEE float
+	}
+
+	var hdr [44]byte
+	copy(hdr[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(hdr[4:8], uint32(36+dataBytes))
+	copy(hdr[8:12], "WAVE")
+	copy(hdr[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(hdr[16:20], 16)
+	binary.LittleEndian.PutUint16(hdr[20:22], audioFormat)
+	binary.LittleEndian.PutUint16(hdr[22:24], uint16(ww.opts.Channels))
+	binary.LittleEndian.PutUint32(hdr[24:28], uint32(ww.opts.SampleRate))
+	binary.LittleEndian.PutUint32(hdr[28:32], uint32(byteRate))
+	binary.LittleEndian.PutUint16(hdr[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(hdr[34:36], uint16(bytesPerSample*8))
+	copy(hdr[36:40], "data")
+	binary.LittleEndian.PutUint32(hdr[40:44], uint32(dataBytes))
+
+	if _, err := ww.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := ww.w.Write(hdr[:])
+	return err
+}
+
+// WriteBlock quantizes block's samples to this writer's target format and
+// appends them to the file.
+func (ww *WAVWriter) WriteBlock(block AudioBlock) error {
+	if ww.closed {
+		return errors.New("encoder: write after close")
+	}
+
+	samples, err := toFloat32(block.Samples)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ww.w.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+
+	var buf []byte
+	if ww.opts.Float32 {
+		buf = make([]byte, len(samples)*4)
+		for i, s := range samples {
+			binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(s))
+		}
+	} else {
+		switch ww.opts.BitDepth {
+		case WAVPCM16:
+			buf = make([]byte, len(samples)*2)
+			for i, s := range samples {
+				binary.LittleEndian.PutUint16(buf[i*2:], uint16(int16(saturate(s, -32768, 32767))))
+			}
+		case WAVPCM24:
+			buf = make([]byte, len(samples)*3)
+			for i, s := range samples {
+				v := int32(saturate(s, -8388608, 8388607))
+				buf[i*3] = byte(v)
+				buf[i*3+1] = byte(v >> 8)
+				buf[i*3+2] = byte(v >> 16)
+			}
+		case WAVPCM32:
+			buf = make([]byte, len(samples)*4)
+			for i, s := range samples {
+				binary.LittleEndian.PutUint32(buf[i*4:], uint32(int32(saturate(s, -2147483648, 2147483647))))
+			}
+		default:
+			return errors.New("encoder: unsupported WAV bit depth")
+		}
+	}
+
+	if _, err := ww.w.Write(buf); err != nil {
+		return err
+	}
+	ww.dataBytes += int64(len(buf))
+	return nil
+}
+
+// Close patches the RIFF and data chunk sizes with the final byte count.
+func (ww *WAVWriter) Close() error {
+	if ww.closed {
+		return nil
+	}
+	ww.closed = true
+	return ww.writeHeader(ww.dataBytes)
+}
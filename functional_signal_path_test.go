@@ -331,7 +331,8 @@ StabilityTestComplete:
 
 	// Performance summary
 	t.Log("\n--- Performance Summary ---")
-	lastDuration, maxDuration := eng.dispatcher.GetPerformanceStats()
+	stats := eng.dispatcher.GetPerformanceStats()
+	lastDuration, maxDuration := stats.LastOperationDuration, stats.MaxOperationDuration
 	t.Logf("Dispatcher Performance:")
 	t.Logf("  - Last operation: %v", lastDuration)
 	t.Logf("  - Max operation: %v", maxDuration)
@@ -502,7 +503,8 @@ CollectResults:
 	}
 
 	// Performance check
-	lastDuration, maxDuration := eng.dispatcher.GetPerformanceStats()
+	stats := eng.dispatcher.GetPerformanceStats()
+	lastDuration, maxDuration := stats.LastOperationDuration, stats.MaxOperationDuration
 	t.Logf("Race condition test performance:")
 	t.Logf("  - Max operation: %v", maxDuration)
 	t.Logf("  - Last operation: %v", lastDuration)
@@ -0,0 +1,528 @@
+// Package graph lets a caller describe the mixer topology it wants - which
+// MonoToStereoChannels and Buses should exist, their pan/volume/level, and
+// whether a channel is connected to master - as a plain Desired value
+// (JSON-friendly so it can come from a scene file), and converges a live
+// State to it. Plan diffs Desired against State and returns the ordered Ops
+// needed to get there; Apply runs them against the real engine. Both halves
+// are deliberately separate so a caller can log/inspect/veto a Plan before
+// ever touching the audio graph.
+//
+// This mirrors GraphTransaction's role one level up: GraphTransaction
+// batches a set of already-decided attach/connect/disconnect calls around a
+// pause/resume of affected players; Plan decides what that set of calls
+// should even be, by diffing against what's already there, so reapplying
+// the same Desired value twice is a no-op rather than tearing down and
+// rebuilding connections that were already correct.
+package graph
+
+import (
+	"fmt"
+	"sync"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/engine/channel"
+	"github.com/shaban/macaudio/engine/channel/input"
+)
+
+// ChannelSpec describes the desired state of one named MonoToStereoChannel.
+type ChannelSpec struct {
+	Name            string       `json:"name"`
+	Pan             float32      `json:"pan"`
+	PanLaw          input.PanLaw `json:"panLaw"`
+	Volume          float32      `json:"volume"`
+	ConnectToMaster bool         `json:"connectToMaster"`
+}
+
+// BusSpec describes the desired state of one named Bus.
+type BusSpec struct {
+	Name  string  `json:"name"`
+	Level float32 `json:"level"`
+	Muted bool    `json:"muted"`
+}
+
+// Desired is the declarative description Plan diffs State against. The
+// zero value describes an empty graph, so Plan-ing it against a non-empty
+// State tears everything down - useful for an explicit "clear the scene"
+// operation, but callers loading a scene file should take care to include
+// every channel/bus they want kept, not just the ones they're changing.
+type Desired struct {
+	Channels []ChannelSpec `json:"channels,omitempty"`
+	Buses    []BusSpec     `json:"buses,omitempty"`
+}
+
+// State is the live counterpart to Desired: every channel/bus Plan/Apply
+// currently knows about, keyed by the same stable name a ChannelSpec/BusSpec
+// names it under. Callers build one up via Register*/Unregister* as
+// channels/buses are created outside this package (e.g. ones wired up by
+// hand before graph.Apply took over), and Apply keeps it in sync with
+// whatever it creates/destroys itself.
+type State struct {
+	mu       sync.RWMutex
+	channels map[string]*input.MonoToStereoChannel
+	buses    map[string]*channel.Bus
+}
+
+// NewState creates an empty State.
+func NewState() *State {
+	return &State{
+		channels: make(map[string]*input.MonoToStereoChannel),
+		buses:    make(map[string]*channel.Bus),
+	}
+}
+
+// RegisterChannel records an already-created channel under name, so Plan
+// sees it as existing rather than planning to recreate it.
+func (s *State) RegisterChannel(name string, ch *input.MonoToStereoChannel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[name] = ch
+}
+
+// RegisterBus records an already-created bus under name.
+func (s *State) RegisterBus(name string, b *channel.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buses[name] = b
+}
+
+// Channel returns the channel registered under name, if any.
+func (s *State) Channel(name string) (*input.MonoToStereoChannel, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ch, ok := s.channels[name]
+	return ch, ok
+}
+
+// Bus returns the bus registered under name, if any.
+func (s *State) Bus(name string) (*channel.Bus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b, ok := s.buses[name]
+	return b, ok
+}
+
+// OpKind identifies what kind of action an Op performs. Plan always orders
+// a batch as Attach, then Connect, then SetParam, then Disconnect, then
+// Detach (see Plan's doc comment for why), regardless of the order its
+// ChannelSpecs/BusSpecs happened to diff in.
+type OpKind int
+
+const (
+	OpAttach OpKind = iota
+	OpConnect
+	OpSetParam
+	OpDisconnect
+	OpDetach
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpAttach:
+		return "attach"
+	case OpConnect:
+		return "connect"
+	case OpSetParam:
+		return "set_param"
+	case OpDisconnect:
+		return "disconnect"
+	case OpDetach:
+		return "detach"
+	default:
+		return "unknown"
+	}
+}
+
+// Op is one reconciliation step. Target names the channel/bus it acts on;
+// Detail is a short human-readable description suitable for an audit log or
+// an Event stream, e.g. "pan -1.00 -> 0.50". apply/undo are unexported,
+// since what they actually close over (which ChannelSpec, which live
+// channel) isn't meant to be inspected or reconstructed by a caller - Op is
+// a record of what Plan decided, not a general command type.
+type Op struct {
+	Kind   OpKind
+	Target string
+	Detail string
+
+	apply func(eng *avengine.Engine, state *State) error
+	undo  func(eng *avengine.Engine, state *State) error
+}
+
+// Plan diffs desired against current and returns the ordered Ops needed to
+// converge current to desired: every Attach (new channels/buses) and
+// Connect (ConnectToMaster) comes before any SetParam (pan/volume/level/
+// mute drift), which in turn comes before any Disconnect/Detach (channels/
+// buses no longer in desired). That ordering means a channel being both
+// recreated and reconnected in the same Plan never observes a
+// half-torn-down graph, and nothing Plan decides to keep is ever
+// disconnected only to be immediately reconnected - Plan only emits ops for
+// actual drift (diff-only), so calling Apply with the same desired value
+// twice in a row produces an empty Op slice the second time (idempotent,
+// matching the same ConnectToMaster/DisconnectFromMaster idempotence
+// TestMonoToStereoMasterConnection already exercises one level down).
+func Plan(current *State, desired Desired) []Op {
+	current.mu.RLock()
+	defer current.mu.RUnlock()
+
+	var attach, connect, setParam, disconnect, detach []Op
+
+	wantChannels := make(map[string]ChannelSpec, len(desired.Channels))
+	for _, spec := range desired.Channels {
+		wantChannels[spec.Name] = spec
+	}
+
+	for _, spec := range desired.Channels {
+		spec := spec
+		existing, ok := current.channels[spec.Name]
+		if !ok {
+			attach = append(attach, Op{
+				Kind:   OpAttach,
+				Target: spec.Name,
+				Detail: fmt.Sprintf("create mono channel pan=%.2f volume=%.2f", spec.Pan, spec.Volume),
+				apply: func(eng *avengine.Engine, state *State) error {
+					ch, err := input.NewMonoToStereo(input.MonoToStereoConfig{
+						Name:       spec.Name,
+						Engine:     eng,
+						InitialPan: spec.Pan,
+						PanLaw:     spec.PanLaw,
+					})
+					if err != nil {
+						return fmt.Errorf("attach channel %q: %w", spec.Name, err)
+					}
+					if err := ch.SetVolume(spec.Volume); err != nil {
+						ch.Release()
+						return fmt.Errorf("set initial volume for %q: %w", spec.Name, err)
+					}
+					state.RegisterChannel(spec.Name, ch)
+					return nil
+				},
+				undo: func(eng *avengine.Engine, state *State) error {
+					ch, ok := state.Channel(spec.Name)
+					if !ok {
+						return nil
+					}
+					ch.Release()
+					state.mu.Lock()
+					delete(state.channels, spec.Name)
+					state.mu.Unlock()
+					return nil
+				},
+			})
+			if spec.ConnectToMaster {
+				connect = append(connect, connectOp(spec.Name, true))
+			}
+			continue
+		}
+
+		if wantConnected, got := spec.ConnectToMaster, existing.IsConnectedToMaster(); wantConnected != got {
+			if wantConnected {
+				connect = append(connect, connectOp(spec.Name, true))
+			} else {
+				disconnect = append(disconnect, connectOp(spec.Name, false))
+			}
+		}
+		if existing.GetPan() != spec.Pan {
+			setParam = append(setParam, panOp(spec.Name, existing.GetPan(), spec.Pan))
+		}
+		if v, err := existing.GetVolume(); err == nil && v != spec.Volume {
+			setParam = append(setParam, volumeOp(spec.Name, v, spec.Volume))
+		}
+	}
+
+	for name := range current.channels {
+		if _, wanted := wantChannels[name]; wanted {
+			continue
+		}
+		name := name
+		if existing := current.channels[name]; existing.IsConnectedToMaster() {
+			disconnect = append(disconnect, connectOp(name, false))
+		}
+		detach = append(detach, Op{
+			Kind:   OpDetach,
+			Target: name,
+			Detail: "release channel no longer in desired graph",
+			apply: func(eng *avengine.Engine, state *State) error {
+				ch, ok := state.Channel(name)
+				if !ok {
+					return nil
+				}
+				ch.Release()
+				state.mu.Lock()
+				delete(state.channels, name)
+				state.mu.Unlock()
+				return nil
+			},
+			// No meaningful undo for a Release - once released, the
+			// native node is gone. A failed Apply rolls back everything
+			// queued after a detach (see Apply), but not the detach
+			// itself.
+		})
+	}
+
+	wantBuses := make(map[string]BusSpec, len(desired.Buses))
+	for _, spec := range desired.Buses {
+		wantBuses[spec.Name] = spec
+	}
+
+	for _, spec := range desired.Buses {
+		spec := spec
+		existing, ok := current.buses[spec.Name]
+		if !ok {
+			attach = append(attach, Op{
+				Kind:   OpAttach,
+				Target: spec.Name,
+				Detail: fmt.Sprintf("create bus level=%.2f", spec.Level),
+				apply: func(eng *avengine.Engine, state *State) error {
+					b, err := channel.NewBus(eng, spec.Name)
+					if err != nil {
+						return fmt.Errorf("attach bus %q: %w", spec.Name, err)
+					}
+					if err := b.SetLevel(spec.Level); err != nil {
+						b.Release()
+						return fmt.Errorf("set initial level for bus %q: %w", spec.Name, err)
+					}
+					if spec.Muted {
+						if err := b.SetMute(true); err != nil {
+							b.Release()
+							return fmt.Errorf("mute bus %q: %w", spec.Name, err)
+						}
+					}
+					state.RegisterBus(spec.Name, b)
+					return nil
+				},
+				undo: func(eng *avengine.Engine, state *State) error {
+					b, ok := state.Bus(spec.Name)
+					if !ok {
+						return nil
+					}
+					b.Release()
+					state.mu.Lock()
+					delete(state.buses, spec.Name)
+					state.mu.Unlock()
+					return nil
+				},
+			})
+			continue
+		}
+
+		if level, err := existing.GetLevel(); err == nil && level != spec.Level {
+			setParam = append(setParam, busLevelOp(spec.Name, level, spec.Level))
+		}
+		if existing.GetMute() != spec.Muted {
+			setParam = append(setParam, busMuteOp(spec.Name, spec.Muted))
+		}
+	}
+
+	for name := range current.buses {
+		if _, wanted := wantBuses[name]; wanted {
+			continue
+		}
+		name := name
+		detach = append(detach, Op{
+			Kind:   OpDetach,
+			Target: name,
+			Detail: "release bus no longer in desired graph",
+			apply: func(eng *avengine.Engine, state *State) error {
+				b, ok := state.Bus(name)
+				if !ok {
+					return nil
+				}
+				b.Release()
+				state.mu.Lock()
+				delete(state.buses, name)
+				state.mu.Unlock()
+				return nil
+			},
+		})
+	}
+
+	ops := make([]Op, 0, len(attach)+len(connect)+len(setParam)+len(disconnect)+len(detach))
+	ops = append(ops, attach...)
+	ops = append(ops, connect...)
+	ops = append(ops, setParam...)
+	ops = append(ops, disconnect...)
+	ops = append(ops, detach...)
+	return ops
+}
+
+func connectOp(name string, connect bool) Op {
+	kind, verb := OpConnect, "connect"
+	if !connect {
+		kind, verb = OpDisconnect, "disconnect"
+	}
+	return Op{
+		Kind:   kind,
+		Target: name,
+		Detail: fmt.Sprintf("%s to master", verb),
+		apply: func(eng *avengine.Engine, state *State) error {
+			ch, ok := state.Channel(name)
+			if !ok {
+				return fmt.Errorf("%s to master: channel %q not registered", verb, name)
+			}
+			if connect {
+				return ch.ConnectToMaster(eng)
+			}
+			return ch.DisconnectFromMaster(eng)
+		},
+		undo: func(eng *avengine.Engine, state *State) error {
+			ch, ok := state.Channel(name)
+			if !ok {
+				return nil
+			}
+			if connect {
+				return ch.DisconnectFromMaster(eng)
+			}
+			return ch.ConnectToMaster(eng)
+		},
+	}
+}
+
+func panOp(name string, from, to float32) Op {
+	return Op{
+		Kind:   OpSetParam,
+		Target: name,
+		Detail: fmt.Sprintf("pan %.2f -> %.2f", from, to),
+		apply: func(eng *avengine.Engine, state *State) error {
+			ch, ok := state.Channel(name)
+			if !ok {
+				return fmt.Errorf("set pan: channel %q not registered", name)
+			}
+			return ch.SetPan(to)
+		},
+		undo: func(eng *avengine.Engine, state *State) error {
+			ch, ok := state.Channel(name)
+			if !ok {
+				return nil
+			}
+			return ch.SetPan(from)
+		},
+	}
+}
+
+func volumeOp(name string, from, to float32) Op {
+	return Op{
+		Kind:   OpSetParam,
+		Target: name,
+		Detail: fmt.Sprintf("volume %.2f -> %.2f", from, to),
+		apply: func(eng *avengine.Engine, state *State) error {
+			ch, ok := state.Channel(name)
+			if !ok {
+				return fmt.Errorf("set volume: channel %q not registered", name)
+			}
+			return ch.SetVolume(to)
+		},
+		undo: func(eng *avengine.Engine, state *State) error {
+			ch, ok := state.Channel(name)
+			if !ok {
+				return nil
+			}
+			return ch.SetVolume(from)
+		},
+	}
+}
+
+func busLevelOp(name string, from, to float32) Op {
+	return Op{
+		Kind:   OpSetParam,
+		Target: name,
+		Detail: fmt.Sprintf("bus level %.2f -> %.2f", from, to),
+		apply: func(eng *avengine.Engine, state *State) error {
+			b, ok := state.Bus(name)
+			if !ok {
+				return fmt.Errorf("set bus level: bus %q not registered", name)
+			}
+			return b.SetLevel(to)
+		},
+		undo: func(eng *avengine.Engine, state *State) error {
+			b, ok := state.Bus(name)
+			if !ok {
+				return nil
+			}
+			return b.SetLevel(from)
+		},
+	}
+}
+
+func busMuteOp(name string, muted bool) Op {
+	return Op{
+		Kind:   OpSetParam,
+		Target: name,
+		Detail: fmt.Sprintf("bus muted=%v", muted),
+		apply: func(eng *avengine.Engine, state *State) error {
+			b, ok := state.Bus(name)
+			if !ok {
+				return fmt.Errorf("set bus mute: bus %q not registered", name)
+			}
+			return b.SetMute(muted)
+		},
+		undo: func(eng *avengine.Engine, state *State) error {
+			b, ok := state.Bus(name)
+			if !ok {
+				return nil
+			}
+			return b.SetMute(!muted)
+		},
+	}
+}
+
+// EventType distinguishes the three things that can happen to an Op under
+// Apply.
+type EventType string
+
+const (
+	EventApplied    EventType = "applied"
+	EventFailed     EventType = "failed"
+	EventRolledBack EventType = "rolled_back"
+)
+
+// Event records what happened to one Op during Apply, in the order Apply
+// processed them - the audit trail a caller hot-reloading a scene file
+// needs to show or log what just changed.
+type Event struct {
+	Op  Op
+	Type EventType
+	Err  error
+}
+
+// Apply runs ops against eng in order, updating state as it goes, and
+// returns an Event per op. If an op fails, Apply stops (no further ops in
+// the batch run), then rolls back every op already applied in this call, in
+// reverse order, via each Op's inverse action - so a failed Plan never
+// leaves the graph in a state that's neither the old one nor the new one.
+// Rollback is best-effort: an Op with no meaningful inverse (OpDetach - see
+// Plan) or one whose undo itself fails is recorded as EventRolledBack with
+// Err set rather than aborting the rest of the rollback. Apply always
+// returns a non-nil error when any op failed, even though every event is
+// also available in the returned slice for inspection.
+func Apply(eng *avengine.Engine, state *State, ops []Op) ([]Event, error) {
+	events := make([]Event, 0, len(ops))
+	applied := make([]Op, 0, len(ops))
+
+	for _, op := range ops {
+		if op.apply == nil {
+			events = append(events, Event{Op: op, Type: EventApplied})
+			applied = append(applied, op)
+			continue
+		}
+		if err := op.apply(eng, state); err != nil {
+			events = append(events, Event{Op: op, Type: EventFailed, Err: err})
+			rollback(eng, state, applied, &events)
+			return events, fmt.Errorf("graph: op %s %s failed: %w", op.Kind, op.Target, err)
+		}
+		events = append(events, Event{Op: op, Type: EventApplied})
+		applied = append(applied, op)
+	}
+
+	return events, nil
+}
+
+func rollback(eng *avengine.Engine, state *State, applied []Op, events *[]Event) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		op := applied[i]
+		if op.undo == nil {
+			*events = append(*events, Event{Op: op, Type: EventRolledBack})
+			continue
+		}
+		err := op.undo(eng, state)
+		*events = append(*events, Event{Op: op, Type: EventRolledBack, Err: err})
+	}
+}
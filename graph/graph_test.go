@@ -0,0 +1,180 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/engine/analyze"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestPlanApplyConvergesAndIsIdempotent builds a Desired graph with one
+// channel connected to master, Applies it against an empty State, verifies
+// real audio reaches the main mixer via analyze.AnalyzeMonoToStereo (the
+// same measurement TestMonoToStereoMasterConnection's sibling
+// TestMasterConnectionWithRealAudio uses for a hand-wired ConnectToMaster
+// call), then re-Plans the identical Desired value and checks it produces
+// no ops at all - Apply already converged the graph, so there's nothing
+// left to diff.
+func TestPlanApplyConvergesAndIsIdempotent(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	toneNode, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("new tone node: %v", err)
+	}
+	defer toneNode.Destroy()
+	toneNodePtr, err := toneNode.GetNodePtr()
+	if err != nil || toneNodePtr == nil {
+		t.Fatalf("tone node ptr: %v", err)
+	}
+	if err := eng.Attach(toneNodePtr); err != nil {
+		t.Fatalf("attach tone node: %v", err)
+	}
+
+	state := NewState()
+	desired := Desired{
+		Channels: []ChannelSpec{
+			{Name: "lead", Pan: 0.0, Volume: 0.8, ConnectToMaster: true},
+		},
+	}
+
+	ops := Plan(state, desired)
+	if len(ops) == 0 {
+		t.Fatalf("expected ops to create and connect the lead channel, got none")
+	}
+
+	events, err := Apply(eng, state, ops)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	for _, ev := range events {
+		if ev.Type != EventApplied {
+			t.Errorf("expected every op to apply cleanly, got %s for %s %s: %v", ev.Type, ev.Op.Kind, ev.Op.Target, ev.Err)
+		}
+	}
+
+	lead, ok := state.Channel("lead")
+	if !ok {
+		t.Fatalf("expected lead channel to be registered in state after Apply")
+	}
+	if !lead.IsConnectedToMaster() {
+		t.Fatalf("expected lead channel to be connected to master after Apply")
+	}
+
+	if err := eng.Connect(toneNodePtr, lead.GetInputNode(), 0, 0); err != nil {
+		t.Fatalf("connect tone to lead input: %v", err)
+	}
+
+	testutil.MuteMainMixer(t, eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("start engine: %v", err)
+	}
+	defer func() {
+		if eng.IsRunning() {
+			eng.Stop()
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	analysisConfig := analyze.DefaultAnalysisConfig()
+	analysisConfig.SampleDuration = 100 * time.Millisecond
+	result, err := analyze.AnalyzeMonoToStereo(eng.Ptr(), toneNodePtr, lead.GetOutputNode(), lead.GetPan(), lead.GetPanLaw(), analysisConfig)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if result.TotalRMS <= 0 {
+		t.Errorf("expected audible signal through the Apply-created channel, got RMS %.6f", result.TotalRMS)
+	}
+
+	if ops := Plan(state, desired); len(ops) != 0 {
+		t.Errorf("expected replaying the same Desired value to be a no-op, got %d ops: %+v", len(ops), ops)
+	}
+}
+
+// TestPlanMutationRepansChannel checks that changing just one field in an
+// otherwise-unchanged Desired value produces only the op that field needs
+// (diff-only), and that Apply-ing it actually moves the routing the
+// analyzer sees.
+func TestPlanMutationRepansChannel(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	state := NewState()
+	initial := Desired{Channels: []ChannelSpec{{Name: "lead", Pan: 0.0, Volume: 0.8}}}
+
+	if _, err := Apply(eng, state, Plan(state, initial)); err != nil {
+		t.Fatalf("initial apply: %v", err)
+	}
+
+	moved := Desired{Channels: []ChannelSpec{{Name: "lead", Pan: -1.0, Volume: 0.8}}}
+	ops := Plan(state, moved)
+	if len(ops) != 1 || ops[0].Kind != OpSetParam {
+		t.Fatalf("expected exactly one SetParam op for the pan change, got %+v", ops)
+	}
+
+	if _, err := Apply(eng, state, ops); err != nil {
+		t.Fatalf("apply pan change: %v", err)
+	}
+
+	lead, _ := state.Channel("lead")
+	if lead.GetPan() != -1.0 {
+		t.Errorf("expected pan -1.0 after Apply, got %.2f", lead.GetPan())
+	}
+}
+
+// TestApplyRollsBackOnFailure checks that a failing op in the middle of a
+// batch undoes everything applied earlier in the same Apply call, rather
+// than leaving the graph half-converged.
+func TestApplyRollsBackOnFailure(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	state := NewState()
+
+	good := Op{Kind: OpAttach, Target: "a", Detail: "test attach", apply: func(eng *avengine.Engine, state *State) error {
+		return nil
+	}, undo: func(eng *avengine.Engine, state *State) error {
+		state.mu.Lock()
+		delete(state.channels, "rolled-back-marker")
+		state.mu.Unlock()
+		return nil
+	}}
+	bad := Op{Kind: OpAttach, Target: "b", Detail: "test attach that fails", apply: func(eng *avengine.Engine, state *State) error {
+		return errFailure
+	}}
+
+	state.mu.Lock()
+	state.channels["rolled-back-marker"] = nil
+	state.mu.Unlock()
+
+	events, err := Apply(eng, state, []Op{good, bad})
+	if err == nil {
+		t.Fatalf("expected Apply to report the failing op")
+	}
+	if len(events) != 2 || events[0].Type != EventApplied || events[1].Type != EventFailed {
+		t.Fatalf("expected applied-then-failed events, got %+v", events)
+	}
+
+	state.mu.RLock()
+	_, stillPresent := state.channels["rolled-back-marker"]
+	state.mu.RUnlock()
+	if stillPresent {
+		t.Errorf("expected the successful op's undo to run during rollback")
+	}
+}
+
+var errFailure = errors.New("op intentionally fails")
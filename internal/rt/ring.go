@@ -0,0 +1,106 @@
+// Package rt provides a lock-free command queue meant to sit between
+// non-realtime producers (OSC, MIDI, UI, device-monitor callbacks) and the
+// audio render thread, so none of them ever takes a mutex the render thread
+// could also be waiting on. See Ring.
+package rt
+
+import "sync/atomic"
+
+// ringSize is Ring's fixed capacity, a power of two so slot selection is a
+// mask instead of a modulo. 2048 is generous for a burst of automation
+// (knob drags, MIDI CC floods, OSC bundles) arriving between two render
+// cycles without Ring ever allocating past construction.
+const ringSize = 2048
+const ringMask = ringSize - 1
+
+// Command is a single audio-thread-bound mutation: set ParamID (e.g.
+// "volume", "pan") on channel ChannelID to Value. TimestampFrames is the
+// sample position the producer wants it applied at, for a consumer that
+// aligns commands to a specific render block rather than applying
+// everything it finds at the top of the next one.
+type Command struct {
+	ChannelID       string
+	ParamID         string
+	Value           float32
+	TimestampFrames int64
+}
+
+// cacheLinePad sits between Ring's hot fields so the producer-written head
+// and consumer-written tail/dropped never share a cache line.
+type cacheLinePad [64 - 8]byte
+
+// Ring is a bounded, multi-producer/single-consumer command queue: any
+// number of producer goroutines may call Push concurrently, but Drain must
+// only ever be called from one goroutine at a time - the render thread this
+// package is built for. A full ring drops the incoming command rather than
+// blocking the producer, since nothing upstream of the audio thread may
+// ever stall waiting on it; see Dropped.
+type Ring struct {
+	head uint64
+	_    cacheLinePad
+	tail uint64
+	_    cacheLinePad
+	dropped uint64
+	_    cacheLinePad
+
+	pending [ringSize]uint32
+	slots   [ringSize]Command
+}
+
+// NewRing creates an empty Ring.
+func NewRing() *Ring {
+	return &Ring{}
+}
+
+// Push enqueues cmd, returning false (and counting it in Dropped) if the
+// ring is already full. Push never blocks, so it's safe to call from any
+// producer goroutine regardless of whether the consumer is keeping up.
+func (r *Ring) Push(cmd Command) bool {
+	for {
+		head := atomic.LoadUint64(&r.head)
+		tail := atomic.LoadUint64(&r.tail)
+		if head-tail >= ringSize {
+			atomic.AddUint64(&r.dropped, 1)
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&r.head, head, head+1) {
+			slot := head & ringMask
+			r.slots[slot] = cmd
+			atomic.StoreUint32(&r.pending[slot], 1)
+			return true
+		}
+	}
+}
+
+// Drain applies every command currently available, in submission order, by
+// calling apply once per command. It must only be called from a single
+// goroutine at a time, since tail advances without synchronization against
+// a concurrent Drain caller.
+func (r *Ring) Drain(apply func(Command)) {
+	tail := r.tail
+	head := atomic.LoadUint64(&r.head)
+	for tail < head {
+		slot := tail & ringMask
+		for atomic.LoadUint32(&r.pending[slot]) == 0 {
+			// A producer has claimed this slot (advanced head past it) but
+			// hasn't finished its plain store into slots yet - this only
+			// ever spins for the width of that one store.
+		}
+		cmd := r.slots[slot]
+		atomic.StoreUint32(&r.pending[slot], 0)
+		apply(cmd)
+		tail++
+	}
+	atomic.StoreUint64(&r.tail, tail)
+}
+
+// FillLevel reports how many commands are queued awaiting the next Drain.
+func (r *Ring) FillLevel() int {
+	return int(atomic.LoadUint64(&r.head) - atomic.LoadUint64(&r.tail))
+}
+
+// Dropped reports how many Push calls have found the ring full since it
+// was created.
+func (r *Ring) Dropped() uint64 {
+	return atomic.LoadUint64(&r.dropped)
+}
@@ -0,0 +1,91 @@
+package rt
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestRingAppliesInSubmissionOrder checks that Drain calls apply in the
+// same order Push was called, the basic FIFO contract a single producer
+// relies on.
+func TestRingAppliesInSubmissionOrder(t *testing.T) {
+	r := NewRing()
+
+	for i := 0; i < 5; i++ {
+		if !r.Push(Command{ChannelID: "ch-1", ParamID: "volume", Value: float32(i)}) {
+			t.Fatalf("push %d unexpectedly dropped", i)
+		}
+	}
+
+	var applied []float32
+	r.Drain(func(cmd Command) {
+		applied = append(applied, cmd.Value)
+	})
+
+	for i, v := range applied {
+		if v != float32(i) {
+			t.Errorf("expected applied[%d] = %d, got %v", i, i, v)
+		}
+	}
+	if r.FillLevel() != 0 {
+		t.Errorf("expected ring to be empty after drain, got fill level %d", r.FillLevel())
+	}
+}
+
+// TestRingDropsWhenFull checks that Push refuses once the ring is at
+// capacity instead of blocking or overwriting, and that every refusal is
+// counted in Dropped.
+func TestRingDropsWhenFull(t *testing.T) {
+	r := NewRing()
+
+	for i := 0; i < ringSize; i++ {
+		if !r.Push(Command{ChannelID: "ch-1", ParamID: "volume", Value: float32(i)}) {
+			t.Fatalf("push %d unexpectedly dropped before the ring was full", i)
+		}
+	}
+
+	if r.Push(Command{ChannelID: "ch-1", ParamID: "volume", Value: 99}) {
+		t.Fatal("expected push into a full ring to be dropped")
+	}
+	if r.Dropped() != 1 {
+		t.Errorf("expected Dropped() == 1, got %d", r.Dropped())
+	}
+}
+
+// TestRingPushIsConcurrencySafe drives many producer goroutines pushing
+// concurrently and checks that every command that reports success is
+// eventually drained exactly once, without a panic or a lost command.
+func TestRingPushIsConcurrencySafe(t *testing.T) {
+	r := NewRing()
+
+	const goroutines = 16
+	const perGoroutine = 50
+	var wg sync.WaitGroup
+	var accepted int64
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				if r.Push(Command{ChannelID: "shared", ParamID: "volume", Value: float32(i)}) {
+					atomic.AddInt64(&accepted, 1)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	var applyCount int64
+	r.Drain(func(Command) {
+		atomic.AddInt64(&applyCount, 1)
+	})
+
+	if applyCount != accepted {
+		t.Errorf("expected every accepted push to be applied exactly once: accepted=%d applied=%d", accepted, applyCount)
+	}
+	if r.FillLevel() != 0 {
+		t.Errorf("expected the ring to be fully drained, fill level=%d", r.FillLevel())
+	}
+}
@@ -0,0 +1,71 @@
+package macaudio
+
+import "fmt"
+
+// LoggingListener implements both ChannelListener and ChainListener by
+// printing a line for every event it receives - a minimal, dependency-free
+// default for development, and the starting point for a real UI, undo/redo
+// stack, or persistence layer's own listener.
+type LoggingListener struct{}
+
+func (LoggingListener) OnVolumeChanged(channelID string, volume float32) {
+	fmt.Printf("[listener] channel %s volume -> %.3f\n", channelID, volume)
+}
+
+func (LoggingListener) OnPanChanged(channelID string, pan float32) {
+	fmt.Printf("[listener] channel %s pan -> %.3f\n", channelID, pan)
+}
+
+func (LoggingListener) OnMuteChanged(channelID string, muted bool) {
+	fmt.Printf("[listener] channel %s muted -> %v\n", channelID, muted)
+}
+
+func (LoggingListener) OnConnectionChanged(channelID string, connections []Connection) {
+	fmt.Printf("[listener] channel %s connections -> %d\n", channelID, len(connections))
+}
+
+func (LoggingListener) OnEffectAdded(channelID string, instance *PluginInstance) {
+	fmt.Printf("[listener] channel %s effect added: %s (%s)\n", channelID, instance.ID, instance.Blueprint.Name)
+}
+
+func (LoggingListener) OnEffectRemoved(channelID, instanceID string) {
+	fmt.Printf("[listener] channel %s effect removed: %s\n", channelID, instanceID)
+}
+
+func (LoggingListener) OnEffectMoved(channelID, instanceID string, position int) {
+	fmt.Printf("[listener] channel %s effect %s moved to position %d\n", channelID, instanceID, position)
+}
+
+func (LoggingListener) OnParamChanged(channelID, instanceID, name string, value float32) {
+	fmt.Printf("[listener] channel %s effect %s param %s -> %.3f\n", channelID, instanceID, name, value)
+}
+
+func (LoggingListener) OnBypassChanged(channelID, instanceID string, bypassed bool) {
+	fmt.Printf("[listener] channel %s effect %s bypassed -> %v\n", channelID, instanceID, bypassed)
+}
+
+// OSCChannelListener mirrors channel mix-state changes to every subscriber of
+// an OSCServer (see OSCServer's /subscribe), so a subscriber gets pushed the
+// same /info update whether the change that triggered it was a scene recall
+// (OSCServer already wires serializer.OnStateChange to broadcastInfo for
+// that) or a direct per-channel SetVolume/SetPan/SetMute call like this one.
+// Attach it to a channel with Channel.AddListener.
+type OSCChannelListener struct {
+	Server *OSCServer
+}
+
+func (l *OSCChannelListener) OnVolumeChanged(channelID string, volume float32) {
+	l.Server.broadcastInfo()
+}
+
+func (l *OSCChannelListener) OnPanChanged(channelID string, pan float32) {
+	l.Server.broadcastInfo()
+}
+
+func (l *OSCChannelListener) OnMuteChanged(channelID string, muted bool) {
+	l.Server.broadcastInfo()
+}
+
+func (l *OSCChannelListener) OnConnectionChanged(channelID string, connections []Connection) {
+	l.Server.broadcastInfo()
+}
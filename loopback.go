@@ -0,0 +1,137 @@
+package macaudio
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// LoopbackConfig configures a LoopbackInputChannel. Exactly one of
+// ProcessID or SubmixUID should be set:
+//
+//   - ProcessID captures a single running process's output (or the full
+//     system mix, if 0) via a Core Audio process tap - see
+//     devices.CreateProcessTap. Requires devices.ProcessTapSupported
+//     (macOS 14.4+).
+//   - SubmixUID names an already-routed virtual loopback device (e.g. one
+//     found with devices.FindVirtualLoopbackDevice) to use directly, the
+//     fallback for systems where ProcessTapSupported is false.
+type LoopbackConfig struct {
+	ProcessID int32
+	SubmixUID string
+}
+
+// LoopbackInputChannel captures system or per-process audio output as an
+// input channel, alongside the hardware-backed AudioInputChannel. It reads
+// through the same shared input-node map (Engine.getOrCreateInputNode) and
+// carries the normal plugin/gain/mute chain via BaseChannel, so a captured
+// app's output can be effected and routed exactly like a microphone input.
+type LoopbackInputChannel struct {
+	*BaseChannel
+
+	config    LoopbackConfig
+	deviceUID string
+
+	// tapUID is the Core Audio process tap backing deviceUID, set only
+	// when config.ProcessID was used; empty when deviceUID is a
+	// user-routed SubmixUID. Torn down by Dispatcher.removeChannel
+	// alongside the channel itself.
+	tapUID string
+
+	// AVFoundation integration
+	inputNode unsafe.Pointer // Shared AVAudioInputNode (from engine.inputNodes)
+}
+
+// NewLoopbackInputChannel creates a new loopback input channel reading from
+// deviceUID, which the caller has already resolved to either a process
+// tap's UID or a virtual submix device's UID (see Dispatcher.createLoopbackInput).
+func NewLoopbackInputChannel(name string, config LoopbackConfig, deviceUID string, engine *Engine) (*LoopbackInputChannel, error) {
+	baseChannel := NewBaseChannel(name, ChannelTypeLoopbackInput, engine)
+
+	inputNode, err := engine.getOrCreateInputNode(deviceUID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input node: %w", err)
+	}
+
+	avEngine := engine.getAVEngine()
+	outputMixer, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel mixer: %w", err)
+	}
+	preFaderTap, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-fader tap: %w", err)
+	}
+
+	channel := &LoopbackInputChannel{
+		BaseChannel: baseChannel,
+		config:      config,
+		deviceUID:   deviceUID,
+		inputNode:   inputNode,
+	}
+
+	baseChannel.outputMixer = outputMixer
+	baseChannel.preFaderTap = preFaderTap
+
+	return channel, nil
+}
+
+// Start starts the loopback channel, connecting its shared input node into
+// the channel mixer and the channel mixer into the main mixer - same
+// connect-with-fallback pattern as AudioInputChannel.Start.
+func (lic *LoopbackInputChannel) Start() error {
+	if err := lic.BaseChannel.Start(); err != nil {
+		return err
+	}
+
+	avEngine := lic.engine.getAVEngine()
+
+	if err := avEngine.Connect(lic.inputNode, lic.preFaderTap, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(lic.inputNode, lic.preFaderTap, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect loopback input to pre-fader tap: %w", err)
+		}
+	}
+
+	if err := avEngine.Connect(lic.preFaderTap, lic.outputMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(lic.preFaderTap, lic.outputMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect pre-fader tap to channel mixer: %w", err)
+		}
+	}
+
+	mainMixer, err := avEngine.MainMixerNode()
+	if err != nil {
+		return fmt.Errorf("failed to get main mixer: %w", err)
+	}
+	if err := avEngine.Connect(lic.outputMixer, mainMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(lic.outputMixer, mainMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect channel mixer to main mixer: %w", err)
+		}
+	}
+
+	return lic.engine.startAVEngineIfReady()
+}
+
+// Stop stops the loopback channel and disconnects its AVFoundation
+// connections. The process tap or submix device itself, if any, is torn
+// down separately when the channel is removed (see Dispatcher.removeChannel).
+func (lic *LoopbackInputChannel) Stop() error {
+	avEngine := lic.engine.getAVEngine()
+
+	if lic.outputMixer != nil {
+		avEngine.DisconnectNodeInput(lic.outputMixer, 0)
+	}
+	if lic.preFaderTap != nil {
+		avEngine.DisconnectNodeInput(lic.preFaderTap, 0)
+	}
+
+	return lic.BaseChannel.Stop()
+}
+
+// ListCapturableProcesses enumerates the running processes currently
+// producing audio, each a valid LoopbackConfig.ProcessID. Delegates to
+// devices.ListCapturableProcesses; see there for the permission-prompt
+// behavior the first capture triggers and the macOS version requirement.
+func ListCapturableProcesses() ([]devices.CapturableProcess, error) {
+	return devices.ListCapturableProcesses()
+}
@@ -0,0 +1,86 @@
+package midi
+
+/*
+#cgo LDFLAGS: -framework CoreMIDI -framework CoreFoundation
+#include "native/coremidi.h"
+#include <stdlib.h>
+
+void* coremidi_open_input(int endpointID);
+const char* coremidi_close(void* port);
+// coremidi_poll_channel is declared here, not implemented in this tree yet
+// - native/coremidi.m's existing coremidi_poll_sysex only surfaces
+// 0xF0...0xF7 runs off a port; this needs the same port to also hand back
+// plain channel messages (Note On/Off, CC, Pitch Bend) as they arrive, for
+// ChannelListener below. *status is set negative if nothing arrived within
+// timeoutMs.
+const char* coremidi_poll_channel(void* port, int* status, int* data1, int* data2, int timeoutMs);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// ChannelListener listens to one CoreMIDI input port for channel messages
+// (Note On/Off, CC, Pitch Bend) - the live-performance counterpart to
+// Input's SysEx reassembly above. See avaudio/engine.Engine.OpenMIDIInput
+// for the consumer that drives a PluginChain's parameters from one;
+// ChannelListener itself only knows how to read raw messages off the wire.
+type ChannelListener struct {
+	port unsafe.Pointer
+}
+
+// OpenChannelListener opens dev's CoreMIDI input endpoint for channel
+// messages.
+func OpenChannelListener(dev devices.MIDIDevice) (*ChannelListener, error) {
+	if !dev.IsInput {
+		return nil, fmt.Errorf("device %q has no input endpoint", dev.Name)
+	}
+
+	port := C.coremidi_open_input(C.int(dev.InputEndpointID))
+	if port == nil {
+		return nil, fmt.Errorf("failed to open CoreMIDI input for %q", dev.Name)
+	}
+	return &ChannelListener{port: port}, nil
+}
+
+// ChannelMessage is one decoded MIDI channel message: Status's high nibble
+// is the message type (0x8 Note Off, 0x9 Note On, 0xB CC, 0xE Pitch Bend,
+// etc.), its low nibble is the channel (0-15).
+type ChannelMessage struct {
+	Status byte
+	Data1  byte
+	Data2  byte
+}
+
+// Poll blocks until a channel message arrives or timeout elapses, returning
+// ok=false (and a nil error) on a timeout with nothing to report.
+func (l *ChannelListener) Poll(timeout time.Duration) (msg ChannelMessage, ok bool, err error) {
+	var status, data1, data2 C.int
+	errStr := C.coremidi_poll_channel(l.port, &status, &data1, &data2, C.int(timeout.Milliseconds()))
+	if errStr != nil {
+		return ChannelMessage{}, false, errors.New(C.GoString(errStr))
+	}
+	if status < 0 {
+		return ChannelMessage{}, false, nil
+	}
+	return ChannelMessage{Status: byte(status), Data1: byte(data1), Data2: byte(data2)}, true, nil
+}
+
+// Close releases the CoreMIDI input port.
+func (l *ChannelListener) Close() error {
+	if l.port == nil {
+		return nil
+	}
+	errStr := C.coremidi_close(l.port)
+	l.port = nil
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
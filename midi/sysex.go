@@ -0,0 +1,243 @@
+// Package midi sends and receives System Exclusive messages over CoreMIDI,
+// pacing writes to a device's advertised SysExSpeed (see
+// devices.MIDIDevice). This is separate from avaudio/midi's portmidi-based
+// Controller, which handles Note/CC/Program bindings but has no SysEx
+// support - a dump transfer needs byte-level chunking and rate limiting
+// that note/CC bindings don't.
+package midi
+
+/*
+#cgo LDFLAGS: -framework CoreMIDI -framework CoreFoundation
+#include "native/coremidi.h"
+#include <stdlib.h>
+
+void* coremidi_open_output(int endpointID);
+void* coremidi_open_input(int endpointID);
+const char* coremidi_send(void* port, const unsigned char* data, int length);
+const char* coremidi_poll_sysex(void* port, unsigned char* outBuf, int maxLen, int* outLen, int timeoutMs);
+const char* coremidi_close(void* port);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// defaultSysExSpeed paces writes when a MIDIDevice doesn't advertise a
+// SysExSpeed (SysExSpeed == 0), matching the de facto standard 31.25kbaud
+// MIDI cable's usable SysEx throughput.
+const defaultSysExSpeed = 3125 // bytes/sec
+
+// chunkSize bounds how large a slice of a SysEx payload is written per
+// token-bucket drain; CoreMIDI fragments large messages into its own
+// packets regardless, so this just bounds how bursty one send call is.
+const chunkSize = 256
+
+// Output is a CoreMIDI output port open on one MIDIDevice, pacing writes to
+// that device's advertised SysExSpeed with a token bucket. Serializing
+// SendSysEx through the mutex, rather than leaving callers to share an
+// Output safely themselves, is the same "dispatcher reusability" the engine
+// package gives channel mutations - see Dispatcher.
+type Output struct {
+	mu          sync.Mutex
+	port        unsafe.Pointer
+	bytesPerSec int
+}
+
+// OpenOutput opens dev's CoreMIDI output endpoint for sending SysEx.
+func OpenOutput(dev devices.MIDIDevice) (*Output, error) {
+	if !dev.IsOutput {
+		return nil, fmt.Errorf("device %q has no output endpoint", dev.Name)
+	}
+
+	port := C.coremidi_open_output(C.int(dev.OutputEndpointID))
+	if port == nil {
+		return nil, fmt.Errorf("failed to open CoreMIDI output for %q", dev.Name)
+	}
+
+	speed := dev.SysExSpeed
+	if speed <= 0 {
+		speed = defaultSysExSpeed
+	}
+	return &Output{port: port, bytesPerSec: speed}, nil
+}
+
+// SendSysEx sends data (expected to start with 0xF0 and end with 0xF7),
+// chunking it and pacing writes to at most o.bytesPerSec via a token
+// bucket, so a large patch dump doesn't overrun a slow CoreMIDI interface.
+func (o *Output) SendSysEx(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty SysEx payload")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.port == nil {
+		return errors.New("output is closed")
+	}
+
+	tokens := o.bytesPerSec // start with a full bucket's worth available
+	last := time.Now()
+
+	for offset := 0; offset < len(data); {
+		now := time.Now()
+		tokens += int(now.Sub(last).Seconds() * float64(o.bytesPerSec))
+		if tokens > o.bytesPerSec {
+			tokens = o.bytesPerSec
+		}
+		last = now
+
+		if tokens <= 0 {
+			time.Sleep(time.Second / time.Duration(o.bytesPerSec))
+			continue
+		}
+
+		n := chunkSize
+		if n > tokens {
+			n = tokens
+		}
+		if offset+n > len(data) {
+			n = len(data) - offset
+		}
+
+		chunk := data[offset : offset+n]
+		errStr := C.coremidi_send(o.port, (*C.uchar)(unsafe.Pointer(&chunk[0])), C.int(len(chunk)))
+		if errStr != nil {
+			return errors.New(C.GoString(errStr))
+		}
+
+		tokens -= n
+		offset += n
+	}
+	return nil
+}
+
+// Close releases the CoreMIDI output port.
+func (o *Output) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.port == nil {
+		return nil
+	}
+	errStr := C.coremidi_close(o.port)
+	o.port = nil
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// Input is a CoreMIDI input port open on one MIDIDevice, reassembling SysEx
+// messages across however many CoreMIDI packets they arrive in.
+type Input struct {
+	port unsafe.Pointer
+
+	mu      sync.Mutex
+	pending []byte // bytes accumulated since the last 0xF0, if any
+	inSysEx bool
+}
+
+// OpenInput opens dev's CoreMIDI input endpoint for receiving SysEx.
+func OpenInput(dev devices.MIDIDevice) (*Input, error) {
+	if !dev.IsInput {
+		return nil, fmt.Errorf("device %q has no input endpoint", dev.Name)
+	}
+
+	port := C.coremidi_open_input(C.int(dev.InputEndpointID))
+	if port == nil {
+		return nil, fmt.Errorf("failed to open CoreMIDI input for %q", dev.Name)
+	}
+	return &Input{port: port}, nil
+}
+
+// ReadSysEx blocks until a complete SysEx message (0xF0...0xF7) arrives or
+// timeout elapses, reassembling it across however many CoreMIDI packets it
+// was split into.
+func (in *Input) ReadSysEx(timeout time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, 4096)
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("timed out waiting for SysEx")
+		}
+
+		var outLen C.int
+		errStr := C.coremidi_poll_sysex(in.port, (*C.uchar)(unsafe.Pointer(&buf[0])), C.int(len(buf)), &outLen, C.int(remaining.Milliseconds()))
+		if errStr != nil {
+			return nil, errors.New(C.GoString(errStr))
+		}
+		if outLen == 0 {
+			continue // native poll's own slice of the timeout elapsed with nothing ready
+		}
+
+		if msg, ok := in.feed(C.GoBytes(unsafe.Pointer(&buf[0]), outLen)); ok {
+			return msg, nil
+		}
+	}
+}
+
+// feed processes one CoreMIDI packet's worth of raw bytes, returning a
+// complete message and true once a matching 0xF0...0xF7 pair has been
+// accumulated. Bytes arriving outside a 0xF0...0xF7 span - a device's
+// running-status realtime bytes (clock, active sensing) interleaved mid-dump -
+// are dropped rather than corrupting the in-progress message.
+func (in *Input) feed(data []byte) ([]byte, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	for _, b := range data {
+		switch {
+		case b == 0xF0:
+			in.inSysEx = true
+			in.pending = append(in.pending[:0], b)
+		case b == 0xF7 && in.inSysEx:
+			in.pending = append(in.pending, b)
+			msg := append([]byte(nil), in.pending...)
+			in.inSysEx = false
+			in.pending = in.pending[:0]
+			return msg, true
+		case in.inSysEx:
+			in.pending = append(in.pending, b)
+		}
+	}
+	return nil, false
+}
+
+// Close releases the CoreMIDI input port.
+func (in *Input) Close() error {
+	if in.port == nil {
+		return nil
+	}
+	errStr := C.coremidi_close(in.port)
+	in.port = nil
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// DumpReply is the SysEx payload a DumpRequest received in response.
+type DumpReply []byte
+
+// DumpRequest sends request over out (a manufacturer-defined dump request -
+// framing and pacing is DumpRequest's concern, the request's contents are
+// the caller's) and waits up to timeout on in for the matching reply, the
+// common round-trip behind a patch dump.
+func DumpRequest(out *Output, in *Input, request []byte, timeout time.Duration) (DumpReply, error) {
+	if err := out.SendSysEx(request); err != nil {
+		return nil, fmt.Errorf("failed to send dump request: %w", err)
+	}
+	reply, err := in.ReadSysEx(timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dump reply: %w", err)
+	}
+	return DumpReply(reply), nil
+}
@@ -0,0 +1,49 @@
+package macaudio
+
+// MidiCurve shapes how a raw MIDI CC value maps into a bound parameter's
+// range. Mirrors avaudio/midi.Curve, kept as its own type here so this
+// package doesn't need to import the cgo-bound midi package just to
+// describe a binding.
+type MidiCurve string
+
+const (
+	MidiCurveLinear MidiCurve = "linear"
+	MidiCurveLog    MidiCurve = "log" // audio taper: more resolution at the low end
+	MidiCurveExp    MidiCurve = "exp"
+)
+
+// MidiBindingMode controls how a Note binding affects a boolean parameter
+// such as mute.
+type MidiBindingMode string
+
+const (
+	// MidiBindingToggle flips the parameter on each Note On.
+	MidiBindingToggle MidiBindingMode = "toggle"
+	// MidiBindingMomentary sets the parameter on Note On and clears it on
+	// Note Off, following the held key.
+	MidiBindingMomentary MidiBindingMode = "momentary"
+)
+
+// MidiBinding describes a MIDI-learned control for one channel or plugin
+// parameter. It's the serializable counterpart to the live routing a
+// midimap.Mapper installs on an avaudio/midi.Controller: CC (or NRPN, for
+// finer-than-128-step control) bindings drive continuous parameters -
+// volume, pan, aux send on a channel via Mapper.Apply, or any named
+// parameter on a PluginInstance via Mapper.ApplyPlugin - through
+// Min/Max/Curve, and Note bindings drive mute through Mode. "rate" and
+// "pitch" are PlaybackChannel-only and always route from a CC and the
+// pitch bend wheel respectively - Controller/NRPN/Note are ignored for
+// "pitch", since the wheel isn't addressed by any of them. A plugin
+// parameter binding reuses Parameter for the plugin's own parameter
+// identifier rather than one of the mixer names.
+type MidiBinding struct {
+	Parameter  string          `json:"parameter"` // "volume", "pan", "mute", "send", "rate", "pitch", or a plugin parameter identifier
+	Channel    int             `json:"channel"`   // MIDI channel, 0-15; -1 matches any
+	Controller int             `json:"controller,omitempty"`
+	NRPN       int             `json:"nrpn,omitempty"` // non-zero selects NRPN over Controller
+	Note       int             `json:"note,omitempty"`
+	Min        float32         `json:"min,omitempty"`
+	Max        float32         `json:"max,omitempty"`
+	Curve      MidiCurve       `json:"curve,omitempty"`
+	Mode       MidiBindingMode `json:"mode,omitempty"`
+}
@@ -0,0 +1,124 @@
+package midimap
+
+import (
+	"sync"
+
+	"github.com/shaban/macaudio"
+	"github.com/shaban/macaudio/avaudio/midi"
+)
+
+// Feedback sends a bound parameter's current value back out as a CC
+// message, so a motorized fader or LED ring tracks changes that happened
+// elsewhere (automation, another control surface, a session load) instead
+// of only reflecting its own last touch. Poll drives this off periodic
+// sampling; Notify does the same thing immediately, for a caller that
+// already knows a value changed (e.g. a ChainListener.OnParamChanged
+// callback) rather than waiting for the next poll.
+type Feedback struct {
+	output *midi.OutputController
+
+	mu       sync.Mutex
+	bindings map[feedbackKey]feedbackTarget
+}
+
+type feedbackKey struct {
+	channel    int
+	controller int
+}
+
+type feedbackTarget struct {
+	binding macaudio.MidiBinding
+	get     func() (float32, error)
+	last    float32
+	hasLast bool
+}
+
+// NewFeedback creates a Feedback that sends CC updates to output for
+// bindings registered with Watch.
+func NewFeedback(output *midi.OutputController) *Feedback {
+	return &Feedback{output: output, bindings: make(map[feedbackKey]feedbackTarget)}
+}
+
+// Watch registers binding (already installed via Mapper.Apply or
+// Mapper.ApplyPlugin) so Poll and Notify know to send its value back out as
+// CC. get reads the parameter's current value - typically channel.
+// GetVolume, channel.GetPan, or a closure over PluginInstance.GetParameter.
+// NRPN-bound parameters aren't covered: most motorized-fader hardware this
+// targets expects plain CC feedback.
+func (f *Feedback) Watch(binding macaudio.MidiBinding, get func() (float32, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bindings[feedbackKey{channel: binding.Channel, controller: binding.Controller}] = feedbackTarget{
+		binding: binding,
+		get:     get,
+	}
+}
+
+// Poll samples every watched parameter and sends a CC update for any whose
+// value changed since the last Poll or Notify - the driven-off-polling half
+// of the feedback loop, for sources that don't call Notify themselves.
+func (f *Feedback) Poll() {
+	f.mu.Lock()
+	targets := make(map[feedbackKey]feedbackTarget, len(f.bindings))
+	for k, v := range f.bindings {
+		targets[k] = v
+	}
+	f.mu.Unlock()
+
+	for key, target := range targets {
+		value, err := target.get()
+		if err != nil || (target.hasLast && target.last == value) {
+			continue
+		}
+		f.send(key, target.binding, value)
+
+		f.mu.Lock()
+		target.last, target.hasLast = value, true
+		f.bindings[key] = target
+		f.mu.Unlock()
+	}
+}
+
+// Notify sends an immediate CC update for the binding on (channel,
+// controller), for a caller that already knows value changed rather than
+// waiting for the next Poll.
+func (f *Feedback) Notify(channel, controller int, value float32) {
+	f.mu.Lock()
+	key := feedbackKey{channel: channel, controller: controller}
+	target, ok := f.bindings[key]
+	if ok {
+		target.last, target.hasLast = value, true
+		f.bindings[key] = target
+	}
+	f.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	f.send(key, target.binding, value)
+}
+
+func (f *Feedback) send(key feedbackKey, binding macaudio.MidiBinding, value float32) {
+	min, max := binding.Min, binding.Max
+	if min == 0 && max == 0 {
+		max = 1
+	}
+	_ = f.output.SendCC(key.channel, key.controller, unscaleCC(value, min, max))
+}
+
+// unscaleCC is scale's inverse: it maps a bound parameter's value back into
+// a raw 0-127 CC value for feedback. Feedback always sends a flat
+// (uncurved) CC value - a curve mismatch only affects fader travel feel,
+// not correctness, which is fine for the motorized-fader case this targets.
+func unscaleCC(value, min, max float32) int {
+	if max == min {
+		return 0
+	}
+	t := (value - min) / (max - min)
+	if t < 0 {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+	return int(t * 127)
+}
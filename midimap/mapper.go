@@ -0,0 +1,296 @@
+// Package midimap implements MIDI-learn: capturing the next CC, NRPN, or
+// Note message from a hardware controller and turning it into a
+// macaudio.MidiBinding wired to a channel parameter (Apply) or a plugin
+// instance's parameter (ApplyPlugin), using avaudio/midi.Controller as the
+// hardware transport. Feedback (feedback.go) drives the other direction,
+// sending a bound parameter's value back out as CC for motorized faders.
+package midimap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shaban/macaudio"
+	"github.com/shaban/macaudio/avaudio/midi"
+)
+
+// ErrLearnTimeout is returned by Learn when no matching MIDI message
+// arrives within the given timeout.
+var ErrLearnTimeout = errors.New("midimap: learn timed out waiting for a MIDI message")
+
+// Mapper applies macaudio.MidiBinding values to a channel by routing a
+// live avaudio/midi.Controller's CC and Note messages to the channel's
+// parameter setters.
+type Mapper struct {
+	engine     *macaudio.Engine
+	controller *midi.Controller
+}
+
+// NewMapper creates a Mapper that binds controller's incoming messages to
+// channels in engine.
+func NewMapper(engine *macaudio.Engine, controller *midi.Controller) *Mapper {
+	return &Mapper{engine: engine, controller: controller}
+}
+
+// Learn waits for the next CC or Note message on m's controller and
+// returns a MidiBinding describing it, with Parameter, Min, Max, Curve and
+// Mode taken from the supplied template (everything else is filled in
+// from the captured message). It does not bind the message to anything;
+// call Apply with the result to do that.
+func (m *Mapper) Learn(template macaudio.MidiBinding, timeout time.Duration) (macaudio.MidiBinding, error) {
+	events := m.controller.EnableEventStream(8)
+	deadline := time.After(timeout)
+	for {
+		select {
+		case e := <-events:
+			switch e.Type {
+			case midi.EventCC:
+				b := template
+				b.Channel = e.Channel
+				b.Controller = e.Controller
+				b.Note = 0
+				return b, nil
+			case midi.EventNoteOn:
+				b := template
+				b.Channel = e.Channel
+				b.Note = e.Note
+				b.Controller = 0
+				return b, nil
+			case midi.EventNRPN:
+				b := template
+				b.Channel = e.Channel
+				b.NRPN = e.Param
+				b.Controller = 0
+				b.Note = 0
+				return b, nil
+			case midi.EventPitchBend:
+				b := template
+				b.Channel = e.Channel
+				b.Controller = 0
+				b.Note = 0
+				return b, nil
+			}
+		case <-deadline:
+			return macaudio.MidiBinding{}, ErrLearnTimeout
+		}
+	}
+}
+
+// Apply registers binding on m's controller so future MIDI messages drive
+// channelID's parameter, and records binding on the channel so it
+// round-trips through Serializer.GetState/SetState.
+func (m *Mapper) Apply(channelID string, binding macaudio.MidiBinding) error {
+	ch, ok := m.engine.GetChannel(channelID)
+	if !ok {
+		return fmt.Errorf("midimap: unknown channel %q", channelID)
+	}
+
+	switch binding.Parameter {
+	case "volume":
+		m.bindCC(ch, binding, ch.SetVolume)
+	case "pan":
+		m.bindCC(ch, binding, ch.SetPan)
+	case "send":
+		aux, ok := ch.(*macaudio.AuxChannel)
+		if !ok {
+			return fmt.Errorf("midimap: channel %q is not an aux channel", channelID)
+		}
+		m.bindCC(ch, binding, aux.SetSendLevel)
+	case "rate":
+		playback, ok := ch.(*macaudio.PlaybackChannel)
+		if !ok {
+			return fmt.Errorf("midimap: channel %q is not a playback channel", channelID)
+		}
+		m.bindCC(ch, binding, playback.SetRate)
+	case "pitch":
+		playback, ok := ch.(*macaudio.PlaybackChannel)
+		if !ok {
+			return fmt.Errorf("midimap: channel %q is not a playback channel", channelID)
+		}
+		m.bindPitchBend(binding, playback.SetPitch)
+	case "mute":
+		m.bindMute(channelID, binding)
+	default:
+		return fmt.Errorf("midimap: unknown parameter %q", binding.Parameter)
+	}
+
+	ch.AddMidiBinding(binding)
+	return nil
+}
+
+// bindCC installs binding on the controller as either an NRPN binding (when
+// binding.NRPN is set) or a plain CC binding - the only two continuous
+// sources a MidiBinding can name.
+func (m *Mapper) bindCC(ch macaudio.Channel, binding macaudio.MidiBinding, set func(float32) error) {
+	min, max := binding.Min, binding.Max
+	if min == 0 && max == 0 {
+		max = 1
+	}
+	curve := toMidiCurve(binding.Curve)
+
+	if binding.NRPN != 0 {
+		m.controller.BindNRPN(midi.NRPNBinding{
+			Channel: binding.Channel,
+			Param:   binding.NRPN,
+			Min:     min,
+			Max:     max,
+			Curve:   curve,
+			Set:     set,
+		})
+		return
+	}
+	m.controller.BindCC(midi.CCBinding{
+		Channel:    binding.Channel,
+		Controller: binding.Controller,
+		Min:        min,
+		Max:        max,
+		Curve:      curve,
+		Set:        set,
+	})
+}
+
+// bindPitchBend installs binding on the controller as a Pitch Bend binding -
+// the source "pitch" always maps from, since a wheel's 14-bit range, unlike
+// a CC's 7-bit range, is what SetPitch's full -12..+12 semitone span needs
+// to address without a dead zone.
+func (m *Mapper) bindPitchBend(binding macaudio.MidiBinding, set func(float32) error) {
+	min, max := binding.Min, binding.Max
+	if min == 0 && max == 0 {
+		min, max = -12, 12
+	}
+	m.controller.BindPitchBend(midi.PitchBendBinding{
+		Channel: binding.Channel,
+		Min:     min,
+		Max:     max,
+		Curve:   toMidiCurve(binding.Curve),
+		Set:     set,
+	})
+}
+
+func (m *Mapper) bindMute(channelID string, binding macaudio.MidiBinding) {
+	dispatcher := m.engine.GetDispatcher()
+	switch binding.Mode {
+	case macaudio.MidiBindingMomentary:
+		m.controller.BindNote(midi.NoteBinding{
+			Channel: binding.Channel,
+			Note:    binding.Note,
+			OnPlay:  func() error { return dispatcher.SetChannelMute(channelID, true) },
+			OnStop:  func() error { return dispatcher.SetChannelMute(channelID, false) },
+		})
+	default: // toggle
+		m.controller.BindNote(midi.NoteBinding{
+			Channel: binding.Channel,
+			Note:    binding.Note,
+			OnPlay: func() error {
+				ch, ok := m.engine.GetChannel(channelID)
+				if !ok {
+					return fmt.Errorf("midimap: unknown channel %q", channelID)
+				}
+				muted, err := ch.GetMute()
+				if err != nil {
+					return err
+				}
+				return dispatcher.SetChannelMute(channelID, !muted)
+			},
+		})
+	}
+}
+
+func toMidiCurve(c macaudio.MidiCurve) midi.Curve {
+	switch c {
+	case macaudio.MidiCurveLog:
+		return midi.CurveLog
+	case macaudio.MidiCurveExp:
+		return midi.CurveExp
+	default:
+		return midi.CurveLinear
+	}
+}
+
+// ApplyBindings calls Apply for every binding already stored on channelID
+// (e.g. after loading a saved scene), reinstalling the live MIDI routing
+// that the serialized state only records.
+func (m *Mapper) ApplyBindings(channelID string) error {
+	ch, ok := m.engine.GetChannel(channelID)
+	if !ok {
+		return fmt.Errorf("midimap: unknown channel %q", channelID)
+	}
+	for _, b := range ch.GetMidiBindings() {
+		if err := m.Apply(channelID, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyDefaultMapping installs the out-of-the-box control surface layout a
+// hardware controller is assumed to speak: CC1 (mod wheel) to volume, CC10
+// (pan) to pan, CC7 (channel volume, repurposed here as transport speed) to
+// rate, and the pitch bend wheel to pitch - the same four controls a DAW's
+// generic "MIDI learn" default usually offers. Rate and pitch are only
+// meaningful on a PlaybackChannel, so they're skipped (not an error) when
+// channelID isn't one; volume and pan apply to any channel.
+func (m *Mapper) ApplyDefaultMapping(channelID string) error {
+	if err := m.Apply(channelID, macaudio.MidiBinding{Parameter: "volume", Channel: -1, Controller: 1, Max: 1}); err != nil {
+		return err
+	}
+	if err := m.Apply(channelID, macaudio.MidiBinding{Parameter: "pan", Channel: -1, Controller: 10, Min: -1, Max: 1}); err != nil {
+		return err
+	}
+
+	ch, ok := m.engine.GetChannel(channelID)
+	if !ok {
+		return fmt.Errorf("midimap: unknown channel %q", channelID)
+	}
+	if _, ok := ch.(*macaudio.PlaybackChannel); !ok {
+		return nil
+	}
+
+	if err := m.Apply(channelID, macaudio.MidiBinding{Parameter: "rate", Channel: -1, Controller: 7, Min: 0.25, Max: 1.25}); err != nil {
+		return err
+	}
+	return m.Apply(channelID, macaudio.MidiBinding{Parameter: "pitch", Channel: -1})
+}
+
+// ApplyPlugin registers binding on m's controller so future MIDI messages
+// drive instanceID's parameter in channelID's PluginChain - binding.
+// Parameter names the plugin's own parameter identifier here, not one of
+// the mixer names Apply expects - and records it on the instance so it
+// round-trips through PluginInstance.GetState/PluginChain.SetState.
+func (m *Mapper) ApplyPlugin(channelID, instanceID string, binding macaudio.MidiBinding) error {
+	ch, ok := m.engine.GetChannel(channelID)
+	if !ok {
+		return fmt.Errorf("midimap: unknown channel %q", channelID)
+	}
+	instance, ok := ch.GetPluginChain().GetInstance(instanceID)
+	if !ok {
+		return fmt.Errorf("midimap: unknown plugin instance %q on channel %q", instanceID, channelID)
+	}
+
+	m.bindCC(ch, binding, func(value float32) error {
+		return instance.SetParameter(binding.Parameter, value)
+	})
+	instance.AddMidiBinding(binding)
+	return nil
+}
+
+// ApplyPluginBindings calls ApplyPlugin for every binding already stored on
+// instanceID (e.g. after loading a saved chain), reinstalling the live MIDI
+// routing that the serialized state only records.
+func (m *Mapper) ApplyPluginBindings(channelID, instanceID string) error {
+	ch, ok := m.engine.GetChannel(channelID)
+	if !ok {
+		return fmt.Errorf("midimap: unknown channel %q", channelID)
+	}
+	instance, ok := ch.GetPluginChain().GetInstance(instanceID)
+	if !ok {
+		return fmt.Errorf("midimap: unknown plugin instance %q on channel %q", instanceID, channelID)
+	}
+	for _, b := range instance.GetMidiBindings() {
+		if err := m.ApplyPlugin(channelID, instanceID, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
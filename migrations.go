@@ -0,0 +1,123 @@
+package macaudio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Migration transforms a generic decoded EngineState map from one schema
+// Version to the next. Chains of registered Migrations let
+// Serializer.LoadFromReader/LoadFromJSON accept older saved files instead
+// of rejecting anything that isn't an exact version match.
+type Migration struct {
+	From  string
+	To    string
+	Apply func(map[string]interface{}) (map[string]interface{}, error)
+}
+
+var (
+	migrationsMu sync.Mutex
+	migrations   []Migration
+)
+
+// RegisterMigration adds m to the set consulted when loading a state whose
+// Version doesn't match the current Serializer version. Migrations are
+// chained by matching From/To edges in a graph walk, so registering them
+// out of order is fine.
+func RegisterMigration(m Migration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	migrations = append(migrations, m)
+}
+
+type migrationStep struct {
+	version string
+	path    []Migration
+}
+
+// migrationPath finds a chain of registered migrations from `from` to
+// `to`, or reports false if none exists. from == to is always reachable
+// with an empty path.
+func migrationPath(from, to string) ([]Migration, bool) {
+	if from == to {
+		return nil, true
+	}
+
+	migrationsMu.Lock()
+	byFrom := make(map[string][]Migration)
+	for _, m := range migrations {
+		byFrom[m.From] = append(byFrom[m.From], m)
+	}
+	migrationsMu.Unlock()
+
+	visited := map[string]bool{from: true}
+	queue := []migrationStep{{version: from}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, m := range byFrom[cur.version] {
+			path := append(append([]Migration(nil), cur.path...), m)
+			if m.To == to {
+				return path, true
+			}
+			if !visited[m.To] {
+				visited[m.To] = true
+				queue = append(queue, migrationStep{version: m.To, path: path})
+			}
+		}
+	}
+	return nil, false
+}
+
+// applyMigrations decodes data as a generic JSON object, walks it through
+// any registered migration path from its "version" field up to
+// targetVersion, and returns the fully-migrated EngineState.
+func applyMigrations(data []byte, targetVersion string) (EngineState, error) {
+	var generic map[string]interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return EngineState{}, fmt.Errorf("failed to decode state as JSON: %w", err)
+	}
+
+	fromVersion, _ := generic["version"].(string)
+	path, ok := migrationPath(fromVersion, targetVersion)
+	if !ok {
+		return EngineState{}, fmt.Errorf("no migration path from version %q to %q", fromVersion, targetVersion)
+	}
+
+	for _, m := range path {
+		migrated, err := m.Apply(generic)
+		if err != nil {
+			return EngineState{}, fmt.Errorf("migration %s->%s failed: %w", m.From, m.To, err)
+		}
+		migrated["version"] = m.To
+		generic = migrated
+	}
+
+	remarshaled, err := json.Marshal(generic)
+	if err != nil {
+		return EngineState{}, fmt.Errorf("failed to re-marshal migrated state: %w", err)
+	}
+
+	var state EngineState
+	if err := json.Unmarshal(remarshaled, &state); err != nil {
+		return EngineState{}, fmt.Errorf("failed to decode migrated state: %w", err)
+	}
+	return state, nil
+}
+
+func init() {
+	// 1.1.0 added Metadata as a field callers can rely on being present
+	// (even if empty) rather than omitted, so older 1.0.0 files get one
+	// filled in on load.
+	RegisterMigration(Migration{
+		From: "1.0.0",
+		To:   "1.1.0",
+		Apply: func(state map[string]interface{}) (map[string]interface{}, error) {
+			if _, ok := state["metadata"]; !ok {
+				state["metadata"] = map[string]interface{}{}
+			}
+			return state, nil
+		},
+	})
+}
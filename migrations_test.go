@@ -0,0 +1,46 @@
+package macaudio
+
+import "testing"
+
+func TestMigrationPathFindsRegisteredChain(t *testing.T) {
+	path, ok := migrationPath("1.0.0", "1.1.0")
+	if !ok {
+		t.Fatal("expected a migration path from 1.0.0 to 1.1.0")
+	}
+	if len(path) != 1 || path[0].From != "1.0.0" || path[0].To != "1.1.0" {
+		t.Fatalf("unexpected migration path: %+v", path)
+	}
+
+	if _, ok := migrationPath("0.9.0", "1.1.0"); ok {
+		t.Fatal("expected no migration path from an unregistered version")
+	}
+}
+
+func TestApplyMigrationsAddsMissingMetadata(t *testing.T) {
+	input := []byte(`{"version":"1.0.0","configuration":{},"channels":{},"connections":[]}`)
+
+	state, err := applyMigrations(input, "1.1.0")
+	if err != nil {
+		t.Fatalf("applyMigrations failed: %v", err)
+	}
+	if state.Version != "1.1.0" {
+		t.Fatalf("expected migrated version 1.1.0, got %s", state.Version)
+	}
+	if state.Metadata == nil {
+		t.Fatal("expected the 1.0.0->1.1.0 migration to populate Metadata")
+	}
+}
+
+func TestSerializerIsCompatibleAcceptsMigratablePastVersions(t *testing.T) {
+	s := &Serializer{version: "1.1.0"}
+
+	if !s.IsCompatible("1.1.0") {
+		t.Error("expected the current version to be compatible")
+	}
+	if !s.IsCompatible("1.0.0") {
+		t.Error("expected 1.0.0 to be compatible via its registered migration")
+	}
+	if s.IsCompatible("0.1.0") {
+		t.Error("expected an unregistered version to be incompatible")
+	}
+}
@@ -0,0 +1,259 @@
+// Package mix provides automated gain-staging across a group of channels
+// already connected to master, for unattended interpreter/broadcast setups
+// where no one is riding faders.
+//
+// Simplifications versus a full constrained least-squares solver: channels
+// are treated as contributing independent (incoherent) linear power to the
+// program sum, each channel's own integrated LUFS is read once per call
+// rather than resampled across iterations, and per-iteration gain deltas are
+// distributed by headroom and caller-supplied weight rather than solved
+// jointly - a damped fixed-point iteration, not a true least-squares solve.
+// That's adequate for nudging a mix toward a loudness target; it isn't a
+// mastering-grade loudness normalizer.
+package mix
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/shaban/macaudio/engine/channel"
+)
+
+// LUFS is an integrated loudness value in LUFS (dB relative to full-scale
+// sine, K-weighted and gated per ITU-R BS.1770-4).
+type LUFS float64
+
+// GainConstraint bounds the linear gain AutoBalance may assign a channel.
+// The zero value is not usable - Min must be > 0 and Max must be >= Min.
+type GainConstraint struct {
+	Min float32
+	Max float32
+}
+
+// Options configures AutoBalance. Constraints and Weights are keyed by
+// channel.Channel.GetName(); a channel missing from Constraints defaults to
+// [0.05, 2.0], and one missing from Weights defaults to weight 1.0.
+//
+// Weights implements the "weighted choice" variant: when the group can't
+// collectively reach Target within everyone's constraints, a channel with a
+// larger weight (e.g. tagged "primary", such as a lead interpreter's mic) is
+// adjusted less than its unweighted siblings, which absorb more of the
+// shortfall instead.
+type Options struct {
+	Constraints   map[string]GainConstraint
+	Weights       map[string]float64
+	MaxIterations int     // default 50 if <= 0
+	Damping       float64 // default 0.5 if <= 0
+	Tolerance     float64 // convergence threshold on linear power; default 1e-4 if <= 0
+}
+
+const (
+	defaultMinGain       = 0.05
+	defaultMaxGain       = 2.0
+	defaultMaxIterations = 50
+	defaultDamping       = 0.5
+	defaultTolerance     = 1e-4
+)
+
+// Result reports what AutoBalance did.
+type Result struct {
+	Gains      map[string]float32 // final linear gain applied per channel name
+	Iterations int
+	Converged  bool
+}
+
+// loudnessSource is the subset of *channel.BaseChannel's metering surface
+// AutoBalance needs. channel.Channel doesn't expose MeterSnapshot, so
+// callers passing a value backed by *channel.BaseChannel with output
+// metering enabled satisfy it automatically; anything else is skipped (see
+// AutoBalance's doc comment).
+type loudnessSource interface {
+	MeterSnapshot() (channel.Meter, error)
+}
+
+// soloAware is the subset of *channel.BaseChannel's solo surface AutoBalance
+// needs to tell "soloed" apart from "silenced by a sibling's solo".
+type soloAware interface {
+	IsSoloed() bool
+	IsSoloMuted() bool
+}
+
+// powerFromLUFS converts an integrated LUFS value to relative linear power,
+// 10^(L/10) - the same convention the request's c_i formula uses.
+func powerFromLUFS(l LUFS) float64 {
+	return math.Pow(10, float64(l)/10)
+}
+
+// headroom returns how much room a gain still has to move within [min, max],
+// as min(max/g, g/min) - large near the center of the range, small near
+// either bound, zero at or outside it.
+func headroom(g, min, max float32) float64 {
+	if g <= 0 || min <= 0 {
+		return 0
+	}
+	hi := float64(max) / float64(g)
+	lo := float64(g) / float64(min)
+	if hi < lo {
+		return hi
+	}
+	return lo
+}
+
+// AutoBalance adjusts the linear gain of each channel in channels so the
+// group's summed program loudness moves toward target, then applies the
+// result via each channel's SetVolume - which, for a *channel.BaseChannel,
+// already composes with userMuted/soloMuted the same way manual fader moves
+// do, so a muted or solo-muted channel's gain is recorded but inaudible
+// until that state clears.
+//
+// A channel is skipped entirely (left at its current gain, absent from
+// Result.Gains) if: it doesn't implement loudnessSource (no current
+// integrated LUFS reading available), IsSoloMuted() is true, or some other
+// channel in the group IsSoloed() while this one isn't - mirroring the same
+// "only the soloed subset is live" rule SoloManager itself enforces.
+func AutoBalance(channels []channel.Channel, target LUFS, opts Options) (Result, error) {
+	maxIter := opts.MaxIterations
+	if maxIter <= 0 {
+		maxIter = defaultMaxIterations
+	}
+	damping := opts.Damping
+	if damping <= 0 {
+		damping = defaultDamping
+	}
+	tolerance := opts.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultTolerance
+	}
+
+	anySoloed := false
+	for _, ch := range channels {
+		if sa, ok := ch.(soloAware); ok && sa.IsSoloed() {
+			anySoloed = true
+			break
+		}
+	}
+
+	type entry struct {
+		name           string
+		gain           float32
+		min, max       float32
+		weight         float64
+		basePower      float64 // power per unit gain^2, derived from the measured baseline
+	}
+
+	var entries []entry
+	for _, ch := range channels {
+		name := ch.GetName()
+
+		if sa, ok := ch.(soloAware); ok {
+			if sa.IsSoloMuted() {
+				continue
+			}
+			if anySoloed && !sa.IsSoloed() {
+				continue
+			}
+		}
+
+		ls, ok := ch.(loudnessSource)
+		if !ok {
+			continue
+		}
+		m, err := ls.MeterSnapshot()
+		if err != nil {
+			continue
+		}
+
+		g, err := ch.GetVolume()
+		if err != nil || g <= 0 {
+			continue
+		}
+
+		min, max := float32(defaultMinGain), float32(defaultMaxGain)
+		if c, ok := opts.Constraints[name]; ok {
+			min, max = c.Min, c.Max
+		}
+		weight := 1.0
+		if w, ok := opts.Weights[name]; ok {
+			weight = w
+		}
+
+		entries = append(entries, entry{
+			name:      name,
+			gain:      g,
+			min:       min,
+			max:       max,
+			weight:    weight,
+			basePower: powerFromLUFS(LUFS(m.LUFSIntegrated)) / float64(g*g),
+		})
+	}
+
+	if len(entries) == 0 {
+		return Result{Gains: map[string]float32{}}, fmt.Errorf("autobalance: no eligible channels")
+	}
+
+	targetPower := powerFromLUFS(target)
+	converged := false
+	iter := 0
+	for ; iter < maxIter; iter++ {
+		now := 0.0
+		for _, e := range entries {
+			now += e.basePower * float64(e.gain*e.gain)
+		}
+		diff := targetPower - now
+		if math.Abs(diff) <= tolerance {
+			converged = true
+			break
+		}
+
+		totalWeight := 0.0
+		shares := make([]float64, len(entries))
+		for i, e := range entries {
+			// A channel's weight protects it from adjustment: a "primary"
+			// channel with a larger weight gets a smaller share of whatever
+			// change is needed, leaving non-primary channels to absorb more
+			// of it when the group can't jointly reach target.
+			w := e.weight
+			if w <= 0 {
+				w = 1.0
+			}
+			shares[i] = headroom(e.gain, e.min, e.max) / w
+			totalWeight += shares[i]
+		}
+		if totalWeight <= 0 {
+			break // every channel is pinned at a bound; can't converge further
+		}
+
+		for i := range entries {
+			e := &entries[i]
+			share := shares[i] / totalWeight
+			desiredPower := e.basePower*float64(e.gain*e.gain) + diff*share
+			var newGain float64
+			if desiredPower <= 0 {
+				newGain = float64(e.min)
+			} else {
+				newGain = math.Sqrt(desiredPower / e.basePower)
+			}
+			if newGain < float64(e.min) {
+				newGain = float64(e.min)
+			}
+			if newGain > float64(e.max) {
+				newGain = float64(e.max)
+			}
+			e.gain = e.gain + float32(damping)*(float32(newGain)-e.gain)
+		}
+	}
+
+	gains := make(map[string]float32, len(entries))
+	for _, e := range entries {
+		gains[e.name] = e.gain
+	}
+	for _, ch := range channels {
+		if g, ok := gains[ch.GetName()]; ok {
+			if err := ch.SetVolume(g); err != nil {
+				return Result{Gains: gains, Iterations: iter, Converged: converged}, fmt.Errorf("set volume for %q: %w", ch.GetName(), err)
+			}
+		}
+	}
+
+	return Result{Gains: gains, Iterations: iter, Converged: converged}, nil
+}
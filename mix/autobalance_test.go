@@ -0,0 +1,123 @@
+package mix
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/shaban/macaudio/avaudio/pluginchain"
+	"github.com/shaban/macaudio/engine/channel"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// fakeChannel implements channel.Channel plus the loudnessSource/soloAware
+// interfaces AutoBalance looks for via type assertion, without needing a
+// real engine.
+type fakeChannel struct {
+	name           string
+	gain           float32
+	integratedLUFS float64
+	soloed         bool
+	soloMuted      bool
+}
+
+func (f *fakeChannel) GetName() string                                             { return f.name }
+func (f *fakeChannel) SetName(name string)                                         { f.name = name }
+func (f *fakeChannel) GetDisplayName() string                                      { return f.name }
+func (f *fakeChannel) SetDisplayName(string)                                       {}
+func (f *fakeChannel) SetVolume(v float32) error                                   { f.gain = v; return nil }
+func (f *fakeChannel) GetVolume() (float32, error)                                 { return f.gain, nil }
+func (f *fakeChannel) SetMute(bool) error                                          { return nil }
+func (f *fakeChannel) GetMute() (bool, error)                                      { return false, nil }
+func (f *fakeChannel) SetPan(float32) error                                        { return nil }
+func (f *fakeChannel) GetPan() (float32, error)                                    { return 0, nil }
+func (f *fakeChannel) GetPluginChain() *pluginchain.PluginChain                    { return nil }
+func (f *fakeChannel) AddEffect(plugin *plugins.Plugin) error                      { return nil }
+func (f *fakeChannel) AddEffectFromPluginInfo(pluginInfo plugins.PluginInfo) error { return nil }
+func (f *fakeChannel) GetInputNode() unsafe.Pointer                                { return nil }
+func (f *fakeChannel) GetOutputNode() unsafe.Pointer                               { return nil }
+func (f *fakeChannel) Release()                                                    {}
+func (f *fakeChannel) IsReleased() bool                                            { return false }
+func (f *fakeChannel) Summary() string                                             { return "fake: " + f.name }
+
+func (f *fakeChannel) MeterSnapshot() (channel.Meter, error) {
+	return channel.Meter{LUFSIntegrated: f.integratedLUFS}, nil
+}
+func (f *fakeChannel) IsSoloed() bool    { return f.soloed }
+func (f *fakeChannel) IsSoloMuted() bool { return f.soloMuted }
+
+// TestAutoBalanceConvergesTowardTarget checks that two channels sitting well
+// above target get turned down, within their constraints, and that the
+// reported gains are what SetVolume actually received.
+func TestAutoBalanceConvergesTowardTarget(t *testing.T) {
+	a := &fakeChannel{name: "a", gain: 1.0, integratedLUFS: -10}
+	b := &fakeChannel{name: "b", gain: 1.0, integratedLUFS: -10}
+
+	opts := Options{
+		Constraints: map[string]GainConstraint{
+			"a": {Min: 0.05, Max: 2.0},
+			"b": {Min: 0.05, Max: 2.0},
+		},
+	}
+
+	res, err := AutoBalance([]channel.Channel{a, b}, LUFS(-23), opts)
+	if err != nil {
+		t.Fatalf("autobalance: %v", err)
+	}
+	if !res.Converged {
+		t.Errorf("expected convergence within %d iterations, got %+v", res.Iterations, res)
+	}
+	if a.gain >= 1.0 || b.gain >= 1.0 {
+		t.Errorf("expected both gains turned down from 1.0, got a=%v b=%v", a.gain, b.gain)
+	}
+	if res.Gains["a"] != a.gain || res.Gains["b"] != b.gain {
+		t.Errorf("expected Result.Gains to match the gains actually applied, got %+v", res.Gains)
+	}
+}
+
+// TestAutoBalanceSkipsSoloMutedAndNonSoloed checks that when one channel is
+// soloed, a non-soloed sibling is left untouched, and a sibling the solo
+// manager has marked soloMuted is also left untouched.
+func TestAutoBalanceSkipsSoloMutedAndNonSoloed(t *testing.T) {
+	solo := &fakeChannel{name: "solo", gain: 1.0, integratedLUFS: -10, soloed: true}
+	other := &fakeChannel{name: "other", gain: 1.0, integratedLUFS: -10, soloMuted: true}
+
+	res, err := AutoBalance([]channel.Channel{solo, other}, LUFS(-23), Options{})
+	if err != nil {
+		t.Fatalf("autobalance: %v", err)
+	}
+	if other.gain != 1.0 {
+		t.Errorf("expected solo-muted channel's gain untouched, got %v", other.gain)
+	}
+	if _, ok := res.Gains["other"]; ok {
+		t.Errorf("expected solo-muted channel absent from Result.Gains, got %+v", res.Gains)
+	}
+	if _, ok := res.Gains["solo"]; !ok {
+		t.Errorf("expected soloed channel present in Result.Gains")
+	}
+}
+
+// TestAutoBalanceWeightedChoicePrefersPrimary checks that when the group
+// can't jointly reach target within constraints, a channel with a larger
+// weight absorbs more of the adjustment than an unweighted sibling.
+func TestAutoBalanceWeightedChoicePrefersPrimary(t *testing.T) {
+	primary := &fakeChannel{name: "primary", gain: 1.0, integratedLUFS: -10}
+	secondary := &fakeChannel{name: "secondary", gain: 1.0, integratedLUFS: -10}
+
+	opts := Options{
+		Constraints: map[string]GainConstraint{
+			"primary":   {Min: 0.8, Max: 2.0}, // little room to move down
+			"secondary": {Min: 0.05, Max: 2.0},
+		},
+		Weights: map[string]float64{
+			"primary": 5.0,
+		},
+	}
+
+	_, err := AutoBalance([]channel.Channel{primary, secondary}, LUFS(-23), opts)
+	if err != nil {
+		t.Fatalf("autobalance: %v", err)
+	}
+	if secondary.gain >= primary.gain {
+		t.Errorf("expected secondary to absorb more of the reduction than primary, got primary=%v secondary=%v", primary.gain, secondary.gain)
+	}
+}
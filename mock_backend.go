@@ -0,0 +1,180 @@
+package macaudio
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// MockBackend replaces the AVAudioEngine-backed parts of channel/device
+// creation and reconnection with pure-Go fakes, so an Engine created with
+// EngineConfig.MockBackend set can exercise the real Dispatcher - the same
+// operation routing, serialization, and channel registry production code
+// uses - without a Mac or real audio hardware. It's the backend
+// TestDispatcherRaceConditions and similar tests should drive instead of
+// hand-constructing a *BaseChannel and stuffing it into Engine.channels.
+//
+// Every simulated operation is recorded (see Calls) and can be given a
+// per-OperationType latency and failure rate, so tests can both assert on
+// what the dispatcher actually did and exercise its error handling under
+// simulated slow or flaky hardware.
+type MockBackend struct {
+	mu          sync.Mutex
+	latency     map[OperationType]time.Duration
+	failureRate map[OperationType]float64
+	calls       []MockCall
+	rng         *rand.Rand
+}
+
+// MockCall records one simulated backend operation for later assertion via
+// MockBackend.Calls.
+type MockCall struct {
+	Op        OperationType
+	ChannelID string
+	Args      interface{}
+	At        time.Time
+	Err       error
+}
+
+// NewMockBackend creates a MockBackend with no injected latency or
+// failures - every simulated operation succeeds immediately until
+// SetLatency/SetFailureRate say otherwise.
+func NewMockBackend() *MockBackend {
+	return &MockBackend{
+		latency:     make(map[OperationType]time.Duration),
+		failureRate: make(map[OperationType]float64),
+		rng:         rand.New(rand.NewSource(1)),
+	}
+}
+
+// newMockBackendIfEnabled returns a fresh MockBackend when enabled is true
+// (EngineConfig.MockBackend), nil otherwise - so Engine.mock's nilness is
+// the single check everything else (NewAudioInputChannel,
+// Dispatcher.changeChannelDevice) needs to decide whether to fake the
+// native side or not.
+func newMockBackendIfEnabled(enabled bool) *MockBackend {
+	if !enabled {
+		return nil
+	}
+	return NewMockBackend()
+}
+
+// Mock returns the Engine's MockBackend, or nil if it wasn't created with
+// EngineConfig.MockBackend set.
+func (e *Engine) Mock() *MockBackend {
+	return e.mock
+}
+
+// SetLatency makes every future simulated op of type op sleep for d before
+// completing, e.g. to reproduce a slow device enumeration or plugin load.
+func (mb *MockBackend) SetLatency(op OperationType, d time.Duration) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.latency[op] = d
+}
+
+// SetFailureRate makes every future simulated op of type op fail with
+// probability rate (0.0-1.0), independently per call.
+func (mb *MockBackend) SetFailureRate(op OperationType, rate float64) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.failureRate[op] = rate
+}
+
+// Calls returns every operation simulated so far, in the order they
+// completed. The returned slice is a copy - safe to range over while more
+// operations are in flight.
+func (mb *MockBackend) Calls() []MockCall {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	calls := make([]MockCall, len(mb.calls))
+	copy(calls, mb.calls)
+	return calls
+}
+
+// Reset clears recorded calls without touching configured latency/failure
+// rates, so a test can reuse one engine across subtests and assert on each
+// subtest's calls in isolation.
+func (mb *MockBackend) Reset() {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.calls = nil
+}
+
+// simulate applies op's configured latency and failure rate, records the
+// call, and returns the injected error (nil on simulated success). Called
+// from the dispatcher goroutine in place of the real native call for
+// channel creation, plugin, and device operations.
+func (mb *MockBackend) simulate(op OperationType, channelID string, args interface{}) error {
+	mb.mu.Lock()
+	latency := mb.latency[op]
+	rate := mb.failureRate[op]
+	mb.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	var err error
+	if rate > 0 && mb.rng.Float64() < rate {
+		err = fmt.Errorf("mock backend: simulated failure for %s on channel %s", op, channelID)
+	}
+
+	mb.mu.Lock()
+	mb.calls = append(mb.calls, MockCall{Op: op, ChannelID: channelID, Args: args, At: time.Now(), Err: err})
+	mb.mu.Unlock()
+
+	return err
+}
+
+// newMockAudioInputChannel builds an AudioInputChannel the same way
+// NewAudioInputChannel does, except it never calls into
+// Engine.getOrCreateInputNode or AVEngine.CreateMixerNode - there's no real
+// input device or AVAudioEngine graph behind a mock-backed Engine. Its
+// inputNode/outputMixer/preFaderTap stay nil, which every existing channel
+// method already treats as "native side unavailable" and falls back to
+// Go-side-only state for (see BaseChannel.SetVolume, Dispatcher.setMute).
+func newMockAudioInputChannel(name string, config AudioInputConfig, engine *Engine) (*AudioInputChannel, error) {
+	if err := engine.mock.simulate(OpCreateAudioInput, name, config); err != nil {
+		return nil, err
+	}
+
+	baseChannel := NewBaseChannel(name, ChannelTypeAudioInput, engine)
+	return &AudioInputChannel{
+		BaseChannel:     baseChannel,
+		config:          config,
+		deviceUID:       config.DeviceUID,
+		inputBus:        config.InputBus,
+		monitoringLevel: config.MonitoringLevel,
+	}, nil
+}
+
+// changeChannelDevice is the mock-backend counterpart to
+// Dispatcher.changeChannelDevice: it simulates the device switch (latency,
+// injected failure, call recording) instead of doing the real
+// Stop/getOrCreateInputNode/Start reconnect dance, which needs a live
+// AVAudioEngine and a real input device neither of which a mock-backed
+// Engine has.
+func (mb *MockBackend) changeChannelDevice(channel Channel, newDeviceUID string) error {
+	switch ch := channel.(type) {
+	case *AudioInputChannel:
+		if err := mb.simulate(OpDeviceChange, ch.GetIDString(), newDeviceUID); err != nil {
+			return err
+		}
+		ch.config.DeviceUID = newDeviceUID
+		ch.deviceUID = newDeviceUID
+		return nil
+
+	case *MidiInputChannel:
+		if err := mb.simulate(OpDeviceChange, ch.GetIDString(), newDeviceUID); err != nil {
+			return err
+		}
+		ch.config.DeviceUID = newDeviceUID
+		ch.deviceUID = newDeviceUID
+		return nil
+
+	default:
+		return fmt.Errorf("device change not supported for channel type %T", channel)
+	}
+}
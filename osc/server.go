@@ -0,0 +1,562 @@
+// Package osc exposes a macaudio.Engine's channels and master controls as
+// OSC endpoints for external controllers (TouchOSC, custom mixer UIs, MIDI
+// bridge daemons) that can't use CGO bindings directly.
+//
+//	/channel/{uuid}/volume            f   -> Channel.SetVolume
+//	/channel/{uuid}/fader              f   -> Channel.SetVolume, through Server's FaderCurve first
+//	/channel/{uuid}/pan                f   -> Channel.SetPan
+//	/channel/{uuid}/mute                i   -> Dispatcher.SetChannelMute (topology-safe)
+//	/channel/{uuid}/plugin/{pluginID}/bypass  i   -> Dispatcher.SetPluginBypass (topology-safe)
+//	/channel/{uuid}/device             s   -> Dispatcher.ChangeChannelDevice (topology-safe)
+//	/channel/by-name/{name}/volume     f   -> same as /channel/{uuid}/volume, resolved by name
+//	/channel/by-name/{name}/fader      f
+//	/channel/by-name/{name}/pan        f
+//	/channel/by-name/{name}/mute       i
+//	/channel/by-name/{name}/plugin/{pluginID}/bypass  i
+//	/channel/by-name/{name}/device     s
+//	/channel/{uuid}/send/{auxName}/level          f   -> Channel.SetSendLevel, aux resolved by name
+//	/channel/by-name/{name}/send/{auxName}/level  f
+//	/master/volume                     f   -> MasterChannel.SetMasterVolume
+//	/master/limiter                    i   -> MasterChannel.SetLimiterEnabled
+//	/aux/{uuid}/send                   f   -> AuxChannel.SetSendLevel
+//	/playback/{uuid}/play
+//	/playback/{uuid}/pause
+//	/playback/{uuid}/stop
+//	/playback/{uuid}/seek               f   -> PlaybackChannel.SetPosition
+//	/status/subscribe                       -- registers the sender for outbound /status bundles
+//	/status/unsubscribe
+//	/info                                   -- replies to the sender with a /info/reply bundle summarizing the engine
+//	/list                                   -- replies to the sender with a /list/channel bundle, one message per channel
+//
+// /channel/.../mute, /plugin/.../bypass, and /device all go through
+// Dispatcher, the same serialization TestDispatcherRaceConditions exercises
+// for every other topology change, so a burst of these arriving over the
+// network is no less safe than the same calls made in-process. /volume,
+// /fader, and /pan go straight to the channel instead, matching
+// BaseChannel.SetVolume/SetPan themselves: those mutate under their own
+// mutex and were never topology changes needing the dispatcher.
+//
+// Unknown addresses are ignored.
+package osc
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio"
+	wireosc "github.com/shaban/macaudio/avaudio/osc"
+)
+
+// Config names the UDP address ListenAndServe binds to.
+type Config struct {
+	Host string
+	Port int
+}
+
+// Addr returns cfg as a "host:port" string suitable for wireosc.ListenUDP.
+func (cfg Config) Addr() string {
+	return fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+}
+
+// ListenAndServe opens a UDP transport on cfg.Addr, wraps it in a Server for
+// engine, and starts serving in a background goroutine. Call Close on the
+// returned Server to stop it. This is the one-call convenience path; NewServer
+// plus an explicit Transport (e.g. a test's wireosc.ListenUDP("127.0.0.1:0"))
+// remains available for callers that need to choose their own transport or
+// control startup ordering themselves.
+func ListenAndServe(engine *macaudio.Engine, cfg Config) (*Server, error) {
+	transport, err := wireosc.ListenUDP(cfg.Addr())
+	if err != nil {
+		return nil, fmt.Errorf("osc: listen %s: %w", cfg.Addr(), err)
+	}
+	server := NewServer(engine, transport)
+	go server.Serve()
+	return server, nil
+}
+
+// FaderCurve maps a [0,1] fader position from a control surface onto the
+// [0,1] volume Channel.SetVolume expects. The zero Server uses LinearFader,
+// a straight passthrough; set a different curve with SetFaderCurve.
+type FaderCurve func(position float32) float32
+
+// LinearFader is the default FaderCurve: fader position and volume are the
+// same value.
+func LinearFader(position float32) float32 {
+	return position
+}
+
+// DBFaderCurve returns a FaderCurve that treats position as linear in
+// decibels between minDB (position 0) and maxDB (position 1, typically 0dB),
+// converting to the linear gain SetVolume expects - the shape most physical
+// mixer faders and show-control software actually use, since human hearing
+// is closer to logarithmic than linear.
+func DBFaderCurve(minDB, maxDB float32) FaderCurve {
+	return func(position float32) float32 {
+		if position <= 0 {
+			return 0
+		}
+		db := minDB + (maxDB-minDB)*position
+		gain := float32(math.Pow(10, float64(db)/20))
+		if gain > 1 {
+			return 1
+		}
+		return gain
+	}
+}
+
+// DefaultStatusRate is how often Server pushes /status bundles to
+// subscribers when SetStatusRate hasn't been called.
+const DefaultStatusRate = 100 * time.Millisecond
+
+// Server routes incoming OSC messages to an Engine and pushes outbound
+// /status bundles to subscribers at statusRate.
+type Server struct {
+	engine    *macaudio.Engine
+	transport wireosc.Transport
+
+	statusRate time.Duration
+	faderCurve FaderCurve
+
+	subMu sync.Mutex
+	subs  map[string]net.Addr
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewServer creates a Server bound to engine, serving over transport.
+func NewServer(engine *macaudio.Engine, transport wireosc.Transport) *Server {
+	return &Server{
+		engine:     engine,
+		transport:  transport,
+		statusRate: DefaultStatusRate,
+		faderCurve: LinearFader,
+		subs:       make(map[string]net.Addr),
+		stop:       make(chan struct{}),
+	}
+}
+
+// SetStatusRate changes how often /status bundles are pushed. Call before
+// Serve.
+func (s *Server) SetStatusRate(d time.Duration) {
+	s.statusRate = d
+}
+
+// SetFaderCurve changes how /channel/.../fader positions map onto
+// Channel.SetVolume - LinearFader by default. Call before Serve.
+func (s *Server) SetFaderCurve(curve FaderCurve) {
+	s.faderCurve = curve
+}
+
+// Serve starts the outbound status loop and dispatches incoming OSC
+// packets until the transport errors or Close is called.
+func (s *Server) Serve() error {
+	s.wg.Add(1)
+	go s.statusLoop()
+	return s.transport.Serve(s.handle)
+}
+
+// Close stops the status loop and the underlying transport.
+func (s *Server) Close() error {
+	close(s.stop)
+	s.wg.Wait()
+	return s.transport.Close()
+}
+
+func (s *Server) handle(msg wireosc.Message, addr net.Addr) {
+	switch {
+	case msg.Address == "/status/subscribe":
+		s.subMu.Lock()
+		s.subs[addr.String()] = addr
+		s.subMu.Unlock()
+	case msg.Address == "/status/unsubscribe":
+		s.subMu.Lock()
+		delete(s.subs, addr.String())
+		s.subMu.Unlock()
+	case strings.HasPrefix(msg.Address, "/channel/"):
+		s.handleChannel(msg)
+	case strings.HasPrefix(msg.Address, "/master/"):
+		s.handleMaster(msg)
+	case strings.HasPrefix(msg.Address, "/aux/"):
+		s.handleAux(msg)
+	case strings.HasPrefix(msg.Address, "/playback/"):
+		s.handlePlayback(msg)
+	case msg.Address == "/info":
+		s.handleInfo(addr)
+	case msg.Address == "/list":
+		s.handleList(addr)
+	}
+}
+
+func floatArg(args []interface{}) (float32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	}
+	return 0, false
+}
+
+func intArg(args []interface{}) (int32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case int32:
+		return v, true
+	case float32:
+		return int32(v), true
+	}
+	return 0, false
+}
+
+func stringArg(args []interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	v, ok := args[0].(string)
+	return v, ok
+}
+
+// channelIDString returns ch's engine map key. Channel.GetID returns a
+// string per the interface, but the concrete channel types only expose a
+// uuid.UUID through that name, so this goes through the GetIDString
+// method they actually implement instead.
+func channelIDString(ch macaudio.Channel) (string, bool) {
+	withIDString, ok := ch.(interface{ GetIDString() string })
+	if !ok {
+		return "", false
+	}
+	return withIDString.GetIDString(), true
+}
+
+func channelName(ch macaudio.Channel) (string, bool) {
+	named, ok := ch.(interface{ GetName() string })
+	if !ok {
+		return "", false
+	}
+	return named.GetName(), true
+}
+
+func (s *Server) resolveByName(name string) (macaudio.Channel, bool) {
+	for _, id := range s.engine.ListChannels() {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		if n, ok := channelName(ch); ok && n == name {
+			return ch, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) handleChannel(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) < 3 || parts[0] != "channel" {
+		return
+	}
+
+	// rest is whatever follows the resolved channel: exactly one segment
+	// for "volume"/"pan"/"mute", or "send"/{auxName}/"level" for a named
+	// send.
+	var ch macaudio.Channel
+	var rest []string
+	if parts[1] == "by-name" {
+		if len(parts) < 4 {
+			return
+		}
+		resolved, ok := s.resolveByName(parts[2])
+		if !ok {
+			return
+		}
+		ch, rest = resolved, parts[3:]
+	} else {
+		resolved, ok := s.engine.GetChannel(parts[1])
+		if !ok {
+			return
+		}
+		ch, rest = resolved, parts[2:]
+	}
+
+	if len(rest) == 3 && rest[0] == "send" && rest[2] == "level" {
+		s.handleChannelSend(ch, rest[1], msg.Args)
+		return
+	}
+	if len(rest) == 3 && rest[0] == "plugin" && rest[2] == "bypass" {
+		v, ok := intArg(msg.Args)
+		if !ok {
+			return
+		}
+		id, ok := channelIDString(ch)
+		if !ok {
+			return
+		}
+		_ = s.engine.GetDispatcher().SetPluginBypass(id, rest[1], v != 0)
+		return
+	}
+	if len(rest) != 1 {
+		return
+	}
+
+	switch rest[0] {
+	case "volume":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = ch.SetVolume(v)
+	case "fader":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = ch.SetVolume(s.faderCurve(v))
+	case "pan":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = ch.SetPan(v)
+	case "mute":
+		v, ok := intArg(msg.Args)
+		if !ok {
+			return
+		}
+		id, ok := channelIDString(ch)
+		if !ok {
+			return
+		}
+		_ = s.engine.GetDispatcher().SetChannelMute(id, v != 0)
+	case "device":
+		v, ok := stringArg(msg.Args)
+		if !ok {
+			return
+		}
+		id, ok := channelIDString(ch)
+		if !ok {
+			return
+		}
+		_ = s.engine.GetDispatcher().ChangeChannelDevice(id, v)
+	}
+}
+
+// handleChannelSend applies a /channel/.../send/{auxName}/level message:
+// resolves auxName to a registered *macaudio.AuxChannel by name (the same
+// lookup /channel/by-name/... uses for channels) and applies the level via
+// Channel.SetSendLevel, the BaseChannel method backing AddSend/RemoveSend's
+// per-send level.
+func (s *Server) handleChannelSend(ch macaudio.Channel, auxName string, args []interface{}) {
+	resolved, ok := s.resolveByName(auxName)
+	if !ok {
+		return
+	}
+	aux, ok := resolved.(*macaudio.AuxChannel)
+	if !ok {
+		return
+	}
+	v, ok := floatArg(args)
+	if !ok {
+		return
+	}
+	_ = ch.SetSendLevel(aux, v)
+}
+
+func (s *Server) handleMaster(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 2 || parts[0] != "master" {
+		return
+	}
+	master := s.engine.GetMasterChannel()
+	if master == nil {
+		return
+	}
+
+	switch parts[1] {
+	case "volume":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = master.SetMasterVolume(v)
+	case "limiter":
+		v, ok := intArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = master.SetLimiterEnabled(v != 0)
+	}
+}
+
+func (s *Server) handleAux(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "aux" || parts[2] != "send" {
+		return
+	}
+	ch, ok := s.engine.GetChannel(parts[1])
+	if !ok {
+		return
+	}
+	aux, ok := ch.(*macaudio.AuxChannel)
+	if !ok {
+		return
+	}
+	v, ok := floatArg(msg.Args)
+	if !ok {
+		return
+	}
+	_ = aux.SetSendLevel(v)
+}
+
+func (s *Server) handlePlayback(msg wireosc.Message) {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "playback" {
+		return
+	}
+	ch, ok := s.engine.GetChannel(parts[1])
+	if !ok {
+		return
+	}
+	pb, ok := ch.(*macaudio.PlaybackChannel)
+	if !ok {
+		return
+	}
+
+	switch parts[2] {
+	case "play":
+		_ = pb.Play()
+	case "pause":
+		_ = pb.Pause()
+	case "stop":
+		_ = pb.StopPlayback()
+	case "seek":
+		v, ok := floatArg(msg.Args)
+		if !ok {
+			return
+		}
+		_ = pb.SetPosition(float64(v))
+	}
+}
+
+// handleInfo replies to addr with a single /info/reply message describing
+// the engine: channel count and whether a master channel is registered. This
+// is a one-shot snapshot for a controller connecting for the first time,
+// distinct from the periodic /status bundles statusLoop pushes to
+// subscribers.
+func (s *Server) handleInfo(addr net.Addr) {
+	master := s.engine.GetMasterChannel() != nil
+	bundle := wireosc.Bundle{Elements: []wireosc.Message{{
+		Address: "/info/reply",
+		Args:    []interface{}{int32(len(s.engine.ListChannels())), boolToInt32(master)},
+	}}}
+	data, err := bundle.Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+// handleList replies to addr with a /list/channel message per registered
+// channel (id, name) so a controller can build its own UI without needing
+// CGO access to the Engine directly.
+func (s *Server) handleList(addr net.Addr) {
+	var bundle wireosc.Bundle
+	for _, id := range s.engine.ListChannels() {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		name, _ := channelName(ch)
+		bundle.Elements = append(bundle.Elements, wireosc.Message{
+			Address: "/list/channel",
+			Args:    []interface{}{id, name},
+		})
+	}
+	data, err := bundle.Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Server) statusLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.statusRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.broadcastStatus()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// statusBundle builds the outbound /status payload: one /status/channel
+// message per channel (id, volume, pan, muted) plus a /status/master
+// summary.
+func (s *Server) statusBundle() wireosc.Bundle {
+	var bundle wireosc.Bundle
+
+	for _, id := range s.engine.ListChannels() {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		volume, _ := ch.GetVolume()
+		pan, _ := ch.GetPan()
+		muted, _ := ch.GetMute()
+		bundle.Elements = append(bundle.Elements, wireosc.Message{
+			Address: "/status/channel",
+			Args:    []interface{}{id, volume, pan, boolToInt32(muted)},
+		})
+	}
+
+	if master := s.engine.GetMasterChannel(); master != nil {
+		volume, _ := master.GetMasterVolume()
+		bundle.Elements = append(bundle.Elements, wireosc.Message{
+			Address: "/status/master",
+			Args:    []interface{}{volume, boolToInt32(master.IsLimiterEnabled())},
+		})
+	}
+
+	return bundle
+}
+
+func (s *Server) broadcastStatus() {
+	s.subMu.Lock()
+	addrs := make([]net.Addr, 0, len(s.subs))
+	for _, a := range s.subs {
+		addrs = append(addrs, a)
+	}
+	s.subMu.Unlock()
+	if len(addrs) == 0 {
+		return
+	}
+
+	data, err := s.statusBundle().Marshal()
+	if err != nil {
+		return
+	}
+	for _, addr := range addrs {
+		_ = s.transport.SendTo(addr, data)
+	}
+}
@@ -0,0 +1,75 @@
+package macaudio
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/osc"
+)
+
+// OSCClient is a minimal client for an OSCServer, mainly for tests and
+// scripting rather than real control surfaces - TouchOSC, an X32, and
+// similar hardware speak OSC directly over their own transport and have no
+// need of this.
+type OSCClient struct {
+	conn net.Conn
+}
+
+// DialOSC connects to an OSCServer listening at addr over UDP.
+func DialOSC(addr string) (*OSCClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial OSC server at %s: %w", addr, err)
+	}
+	return &OSCClient{conn: conn}, nil
+}
+
+// Send marshals and writes a single OSC message to the server without
+// waiting for a /reply or /error.
+func (c *OSCClient) Send(address string, args ...interface{}) error {
+	data, err := (osc.Message{Address: address, Args: args}).Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", address, err)
+	}
+	_, err = c.conn.Write(data)
+	return err
+}
+
+// Request sends a message and waits up to timeout for the /reply or /error
+// OSCServer.handle sends back, returning an error built from the /error
+// payload if that's what came back (or if nothing came back in time).
+func (c *OSCClient) Request(timeout time.Duration, address string, args ...interface{}) error {
+	if err := c.Send(address, args...); err != nil {
+		return err
+	}
+	if err := c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return fmt.Errorf("failed to set read deadline: %w", err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		return fmt.Errorf("no response to %s: %w", address, err)
+	}
+	reply, err := osc.Unmarshal(buf[:n])
+	if err != nil {
+		return fmt.Errorf("failed to parse response to %s: %w", address, err)
+	}
+
+	if reply.Address == "/error" {
+		reason := "unknown error"
+		if len(reply.Args) > 1 {
+			if s, ok := reply.Args[1].(string); ok {
+				reason = s
+			}
+		}
+		return fmt.Errorf("%s: %s", address, reason)
+	}
+	return nil
+}
+
+// Close closes the underlying connection.
+func (c *OSCClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,1424 @@
+package macaudio
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/osc"
+)
+
+// OSCServer exposes an Engine's channels and master controls as OSC
+// endpoints for control surfaces (X32/M32-style consoles, TouchOSC, etc.):
+//
+//	/ch/<id>/fader   f   -- 0.0-1.0 fader position (dB-tapered) -> Channel.SetVolume
+//	/ch/<id>/pan     f   -- -1.0 to 1.0 pan position -> Channel.SetPan
+//	/ch/<id>/mute    i   -- 1 mutes, 0 unmutes -> Channel.SetMute
+//	/aux/<id>/send   f   -- 0.0-1.0 fader position -> AuxChannel.SetSendLevel
+//	/master/fader    f   -- 0.0-1.0 fader position -> MasterChannel.SetMasterVolume
+//	/channel/<id>/volume f -- raw 0.0-1.0 linear gain (no fader taper) -> Channel.SetVolume
+//	/channel/<id>/pan    f -- -1.0 to 1.0 pan position -> Channel.SetPan
+//	/channel/<id>/mute   i -- 1 mutes, 0 unmutes -> Channel.SetMute
+//	/channel/<id>/device s -- new device UID -> Dispatcher.ChangeChannelDevice
+//	/channel/<id>/plugin/<pid>/bypass i -- 1 bypasses, 0 un-bypasses, addressing
+//	                                        a plugin instance by PluginInstance.ID
+//	                                        rather than chain position -> Engine.SetPluginBypass
+//	/channel/connect    ss i -- source id, target id, bus -> Dispatcher.ConnectChannels
+//	/channel/disconnect ss i -- source id, target id, bus -> Dispatcher.DisconnectChannels
+//	/engine/output/device s -- alias for /output/device below, spelled under /engine
+//	                            for a surface that groups every engine-level command there
+//	/channel/<id>/playback/rate  f -- 0.25x-4.0x playback speed -> PlaybackChannel.SetRate
+//	/channel/<id>/playback/pitch f -- -12 to +12 semitones -> PlaybackChannel.SetPitch
+//	/channel/<id>/playback/play      -- PlaybackChannel.Play
+//	/channel/<id>/playback/stop      -- PlaybackChannel.StopPlayback
+//	/master/volume       f -- raw 0.0-1.0 linear gain -> MasterChannel.SetMasterVolume
+//	/output/device       s -- new device UID -> Dispatcher.ChangeOutputDevice
+//	/engine/start            -- Engine.Start
+//	/engine/stop             -- Engine.Stop
+//	/engine/save     s   -- path to save the current EngineState (JSON) to
+//	/engine/load     s   -- path to load an EngineState (JSON) from
+//	/engine/state/dump s -- "host:port" return address to send the current EngineState
+//	                         (JSON, via Serializer.SaveToJSON) to, as an "/engine/state" Message
+//	/chain/<id>/effect/<idx>/param/<n>   f  -- sets the n'th parameter of the idx'th plugin
+//	                                            in channel <id>'s chain -> PluginInstance.SetParameter
+//	/chain/<id>/effect/<idx>/bypass      i  -- 1 bypasses, 0 un-bypasses -> Engine.SetPluginBypass
+//	/plugin/<id>/<idx>/param/<n>  f -- alias for /chain/<id>/effect/<idx>/param/<n>
+//	/channel/<id>/solo    i -- 1 solos, 0 un-solos -> Engine.SetSoloed
+//	/channel/<id>/send/<aux_id>/level f -- 0.0-1.0 linear send level from <id> to
+//	                                        aux <aux_id> -> Channel.SetSendLevel
+//	/sampler/<id>/note    ii -- note, velocity -> SamplerChannel.StartNote(0, ...)
+//	/sampler/<id>/noteoff i  -- note -> SamplerChannel.StopNote(0, ..., 64)
+//	/auth            s   -- authenticates the sender with a shared-secret token; see OSCAuth
+//	/info                -- replies with a Bundle summarizing EngineState
+//	/status              -- replies with a single Message: engine running, channel count, subscriber count
+//	/list                -- replies with a Bundle listing every channel's id and type
+//	/subscribe           -- registers the sender to receive /info pushes on every SetState change,
+//	                          /device/event pushes on device-loss/fallback events (see broadcastDeviceEvent),
+//	                          and /event/<kind> pushes for every other dispatcher-level change (see
+//	                          forwardEvents) - a subscription expires after subscriptionTimeout unless
+//	                          renewed by another /subscribe or a /heartbeat (see sweepSubscribers)
+//	/heartbeat           -- renews an existing subscription's expiry without re-sending /subscribe's
+//	                          full registration; a no-op for a sender that isn't subscribed
+//	/unsubscribe         -- stops those pushes
+//
+// <id> on /ch, /channel, /aux, and /chain may be an OSC address-pattern (see
+// avaudio/osc.MatchAddress) instead of a literal channel id, e.g.
+// "/ch/*/mute" or "/ch/[ab]*/fader" applies to every channel whose id
+// matches, fanning the single incoming message out to each one.
+//
+// A Bundle of these commands (see avaudio/osc.Bundle) is applied atomically:
+// handleBundle submits every element as a single Dispatcher.SubmitBatch
+// operation instead of letting them interleave with other dispatcher
+// traffic one at a time, so a controller moving several faders together
+// lands on the audio thread coherently. Bundle.Timetag isn't honored yet -
+// every bundle runs as soon as it arrives, same as the OSC "immediately"
+// timetag - there's no scheduler in this package to defer a future one to.
+//
+// /channel/<id>/volume and /channel/<id>/pan exist alongside /ch/<id>/fader
+// for control surfaces (TouchOSC and similar) that drive Channel.SetVolume
+// directly with a linear 0.0-1.0 gain rather than a console-style tapered
+// fader position - the two address families write through to the same
+// underlying state, so either can be used interchangeably per client.
+// Updates on these two addresses are submitted through the engine's
+// Dispatcher.SubmitParam lane instead of being applied inline: a rapid run
+// of messages to the same address between two lane drains only applies the
+// latest one, so a knob storm or a TouchOSC crossfader drag can't saturate
+// the dispatcher with superseded values, and never contends on a mutex to
+// get there. Mute, being a discrete on/off rather than a continuous
+// control, is applied immediately on every message instead.
+//
+// Every /ch, /channel, /aux, /chain, and /master command gets a reply: a
+// "/reply" message on success, or an "/error" message (address plus a
+// string reason) when the command was understood but couldn't be applied -
+// an unknown channel id, wrong argument type, an out-of-range effect index.
+// For a coalesced address, "/reply" means the value was validated and
+// queued for the next flush, not that it has already reached the channel.
+// Unrecognized addresses are still ignored outright, the same policy
+// avaudio/osc.Surface uses for the audio-graph-level OSC surface this
+// mirrors at the mixer level; silence there means "not a command this
+// server understands," not "command failed." OSCClient (osc_client.go)
+// is a minimal client for driving this from tests or scripts, including
+// waiting on that reply.
+type OSCServer struct {
+	engine     *Engine
+	serializer *Serializer
+	transport  osc.Transport
+
+	// subMu guards subscribers and lastSeen - see handleSubscribe,
+	// handleHeartbeat, and sweepSubscribers.
+	subMu       sync.Mutex
+	subscribers map[string]net.Addr
+	lastSeen    map[string]time.Time
+
+	// authMu guards auth and authenticated - see SetAuth/isAuthenticated.
+	authMu        sync.Mutex
+	auth          OSCAuth
+	authenticated map[string]bool
+
+	// eventsCancel stops this server's Dispatcher.Subscribe registration
+	// (see forwardEvents); sweepStop stops sweepSubscribers. Both are
+	// closed/called once, by Close.
+	eventsCancel CancelFunc
+	sweepStop    chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewOSCServer creates an OSCServer bound to engine, serving over
+// transport, and registers itself with serializer so SetState changes are
+// broadcast to subscribers. It also installs itself as transport's
+// BundleHandler (see handleBundle), subscribes to the engine's dispatcher
+// for device-hotplug events (see broadcastDeviceEvent) and every other
+// peer-style EngineEvent (see forwardEvents), and starts sweepSubscribers
+// to expire a subscriber that stops sending /subscribe or /heartbeat.
+func NewOSCServer(engine *Engine, serializer *Serializer, transport osc.Transport) *OSCServer {
+	s := &OSCServer{
+		engine:        engine,
+		serializer:    serializer,
+		transport:     transport,
+		subscribers:   make(map[string]net.Addr),
+		lastSeen:      make(map[string]time.Time),
+		authenticated: make(map[string]bool),
+		sweepStop:     make(chan struct{}),
+	}
+	serializer.OnStateChange(func(EngineState) { s.broadcastInfo() })
+	transport.SetBundleHandler(s.handleBundle)
+	engine.GetDispatcher().OnChannelEvent(func(ev DispatcherEvent) {
+		switch ev.Type {
+		case EventDeviceLost, EventDeviceRestored, EventFallbackActivated, EventFormatChanged, EventOperationFailed:
+			s.broadcastDeviceEvent(ev)
+		}
+	})
+	events, cancel := engine.GetDispatcher().Subscribe(EventFilter{})
+	s.eventsCancel = cancel
+	go s.forwardEvents(events)
+	go s.sweepSubscribers()
+	return s
+}
+
+// SetAuth configures the shared-secret token senders must present via
+// "/auth" before any other command is accepted from their address; see
+// OSCConfig.Auth. An empty Token (the zero value, and the default) leaves
+// the server open to any sender. Changing it doesn't retroactively revoke
+// senders already authenticated under a previous token.
+func (s *OSCServer) SetAuth(auth OSCAuth) {
+	s.authMu.Lock()
+	s.auth = auth
+	s.authMu.Unlock()
+}
+
+// isAuthenticated reports whether addr may have its commands acted on:
+// always true when no token is configured, otherwise only once handleAuth
+// has seen the right token from addr.
+func (s *OSCServer) isAuthenticated(addr net.Addr) bool {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	if s.auth.Token == "" {
+		return true
+	}
+	return s.authenticated[addr.String()]
+}
+
+// handleAuth handles "/auth", a single string Token argument: on a match it
+// marks addr authenticated and replies normally; otherwise it replies with
+// /error, the same as a recognized-but-unsatisfiable command elsewhere in
+// this server.
+func (s *OSCServer) handleAuth(msg osc.Message, addr net.Addr) {
+	token, ok := stringArg(msg.Args)
+	if !ok {
+		s.sendError(addr, msg.Address, fmt.Errorf("auth requires a string argument"))
+		return
+	}
+
+	s.authMu.Lock()
+	if s.auth.Token != "" && token == s.auth.Token {
+		s.authenticated[addr.String()] = true
+	}
+	ok = s.auth.Token == "" || s.authenticated[addr.String()]
+	s.authMu.Unlock()
+
+	if !ok {
+		s.sendError(addr, msg.Address, fmt.Errorf("invalid auth token"))
+		return
+	}
+	s.sendReply(addr, msg.Address)
+}
+
+// Serve starts dispatching incoming OSC packets until the transport errors
+// or is closed.
+func (s *OSCServer) Serve() error {
+	return s.transport.Serve(s.handle)
+}
+
+// Close stops the underlying transport, the forwardEvents subscription, and
+// sweepSubscribers. Safe to call more than once; only the first call does
+// anything.
+func (s *OSCServer) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		if s.eventsCancel != nil {
+			s.eventsCancel()
+		}
+		close(s.sweepStop)
+		err = s.transport.Close()
+	})
+	return err
+}
+
+// Listen opens a UDP OSC transport on addr and returns an OSCServer bound to
+// it, already serving in the background - the common case of osc.ListenUDP
+// plus NewOSCServer plus "go server.Serve()" combined. Announce is the
+// counterpart a control surface calls to find a server started this way.
+func Listen(engine *Engine, serializer *Serializer, addr string) (*OSCServer, error) {
+	transport, err := osc.ListenUDP(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := NewOSCServer(engine, serializer, transport)
+	go server.Serve()
+	return server, nil
+}
+
+// Announce sends a one-shot "/announce" message carrying this server's own
+// listen address to announceAddr (typically a broadcast or multicast UDP
+// address), so control surfaces listening there can discover a server
+// started with Listen without being configured with its address up front.
+func (s *OSCServer) Announce(announceAddr string) error {
+	udpTransport, ok := s.transport.(*osc.UDPTransport)
+	if !ok {
+		return fmt.Errorf("announce requires a UDP transport")
+	}
+
+	conn, err := net.Dial("udp", announceAddr)
+	if err != nil {
+		return fmt.Errorf("failed to dial announce address %s: %w", announceAddr, err)
+	}
+	defer conn.Close()
+
+	data, err := (osc.Message{Address: "/announce", Args: []interface{}{udpTransport.LocalAddr().String()}}).Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal announce message: %w", err)
+	}
+	_, err = conn.Write(data)
+	return err
+}
+
+// faderToGain converts a 0.0-1.0 OSC fader position into linear gain using
+// a conventional console taper: unity gain at 1.0, -60dB (effectively
+// silent) at 0.0, logarithmic in between - rather than mapping the fader
+// position straight onto linear gain, which makes most of a physical
+// fader's travel feel bunched up near the top.
+func faderToGain(pos float32) float32 {
+	if pos <= 0 {
+		return 0
+	}
+	if pos >= 1 {
+		return 1
+	}
+	const minDB = -60.0
+	db := float64(pos)*(-minDB) + minDB
+	return float32(math.Pow(10, db/20))
+}
+
+// gainToFader is faderToGain's inverse, used when reporting current state
+// back to a control surface.
+func gainToFader(gain float32) float32 {
+	if gain <= 0 {
+		return 0
+	}
+	if gain >= 1 {
+		return 1
+	}
+	const minDB = -60.0
+	db := 20 * math.Log10(float64(gain))
+	pos := (db - minDB) / (-minDB)
+	return float32(pos)
+}
+
+func (s *OSCServer) handle(msg osc.Message, addr net.Addr) {
+	if msg.Address == "/auth" {
+		s.handleAuth(msg, addr)
+		return
+	}
+	if !s.isAuthenticated(addr) {
+		s.sendError(addr, msg.Address, fmt.Errorf("not authenticated"))
+		return
+	}
+
+	switch {
+	case msg.Address == "/info":
+		s.sendInfo(addr)
+		return
+	case msg.Address == "/status":
+		s.sendStatus(addr)
+		return
+	case msg.Address == "/list":
+		s.sendList(addr)
+		return
+	case msg.Address == "/subscribe":
+		s.handleSubscribe(addr)
+		return
+	case msg.Address == "/unsubscribe":
+		s.handleUnsubscribe(addr)
+		return
+	case msg.Address == "/heartbeat":
+		s.handleHeartbeat(addr)
+		return
+	case msg.Address == "/engine/save":
+		s.handleSave(msg)
+		return
+	case msg.Address == "/engine/load":
+		s.handleLoad(msg)
+		return
+	case msg.Address == "/engine/state/dump":
+		s.handleStateDump(msg, addr)
+		return
+	case msg.Address == "/engine/start":
+		s.handleEngineStart(msg, addr)
+		return
+	case msg.Address == "/engine/stop":
+		s.handleEngineStop(msg, addr)
+		return
+	}
+
+	ok, err := s.applyMessage(msg)
+	if !ok {
+		return
+	}
+	if err != nil {
+		s.sendError(addr, msg.Address, err)
+		return
+	}
+	s.sendReply(addr, msg.Address)
+}
+
+// applyMessage applies one Message's command - the parameter-update
+// address families (/master, /ch, /channel, /aux, /chain, /plugin,
+// /sampler, /output/device) - returning ok=false for anything handle's own
+// switch handles instead (/info, /status, /engine/start, and so on), which
+// aren't topology changes and so don't need handleBundle's atomic batching.
+func (s *OSCServer) applyMessage(msg osc.Message) (ok bool, err error) {
+	switch {
+	case msg.Address == "/master/fader":
+		return true, s.handleMasterFader(msg)
+	case msg.Address == "/master/volume":
+		return true, s.handleMasterVolume(msg)
+	case msg.Address == "/output/device", msg.Address == "/engine/output/device":
+		return true, s.handleOutputDevice(msg)
+	case msg.Address == "/channel/connect":
+		return true, s.handleChannelConnect(msg)
+	case msg.Address == "/channel/disconnect":
+		return true, s.handleChannelDisconnect(msg)
+	case strings.HasPrefix(msg.Address, "/ch/"):
+		return true, s.handleChannel(msg)
+	case strings.HasPrefix(msg.Address, "/channel/"):
+		return true, s.handleChannelRaw(msg)
+	case strings.HasPrefix(msg.Address, "/aux/"):
+		return true, s.handleAux(msg)
+	case strings.HasPrefix(msg.Address, "/chain/"):
+		return true, s.handleChain(msg)
+	case strings.HasPrefix(msg.Address, "/plugin/"):
+		return true, s.handlePluginParam(msg)
+	case strings.HasPrefix(msg.Address, "/sampler/"):
+		return true, s.handleSampler(msg)
+	default:
+		return false, nil
+	}
+}
+
+// resolveChannelIDs expands an id or id pattern from an OSC address into the
+// channel ids it addresses: itself, if it's a literal id, or every channel
+// currently on the engine whose id matches it as an OSC address-pattern
+// segment (see avaudio/osc.MatchAddress) otherwise.
+func (s *OSCServer) resolveChannelIDs(idOrPattern string) []string {
+	if !strings.ContainsAny(idOrPattern, "*?[{") {
+		return []string{idOrPattern}
+	}
+	var ids []string
+	for _, id := range s.engine.ListChannels() {
+		if osc.MatchAddress(idOrPattern, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// combineErrors joins per-target failures (e.g. one wildcard match out of
+// several) into a single error, or returns nil if there were none.
+func combineErrors(failures []string) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(failures, "; "))
+}
+
+func floatArg(args []interface{}) (float32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	}
+	return 0, false
+}
+
+func intArg(args []interface{}) (int32, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch v := args[0].(type) {
+	case int32:
+		return v, true
+	case float32:
+		return int32(v), true
+	}
+	return 0, false
+}
+
+func stringArg(args []interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	v, ok := args[0].(string)
+	return v, ok
+}
+
+func (s *OSCServer) handleChannel(msg osc.Message) error {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "ch" {
+		return fmt.Errorf("malformed channel address %q", msg.Address)
+	}
+	idPattern, param := parts[1], parts[2]
+
+	ids := s.resolveChannelIDs(idPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", idPattern)
+	}
+
+	var failures []string
+	for _, id := range ids {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: channel not found", id))
+			continue
+		}
+		if err := applyChannelParam(ch, param, msg.Args); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// applyChannelParam applies one /ch/<id>/<param> command to ch.
+func applyChannelParam(ch Channel, param string, args []interface{}) error {
+	switch param {
+	case "fader":
+		pos, ok := floatArg(args)
+		if !ok {
+			return fmt.Errorf("fader requires a float argument")
+		}
+		return ch.SetVolume(faderToGain(pos))
+	case "pan":
+		pan, ok := floatArg(args)
+		if !ok {
+			return fmt.Errorf("pan requires a float argument")
+		}
+		return ch.SetPan(pan)
+	case "mute":
+		mute, ok := intArg(args)
+		if !ok {
+			return fmt.Errorf("mute requires an int argument")
+		}
+		return ch.SetMute(mute != 0)
+	default:
+		return fmt.Errorf("unknown channel parameter %q", param)
+	}
+}
+
+// handleChannelRaw handles /channel/<id>/<param>, the linear-gain sibling
+// of /ch/<id>/<param> (see the OSCServer doc comment). volume and pan are
+// coalesced through s.coalescer; mute is applied immediately.
+func (s *OSCServer) handleChannelRaw(msg osc.Message) error {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) == 5 && parts[0] == "channel" && parts[2] == "send" && parts[4] == "level" {
+		return s.handleChannelSend(parts[1], parts[3], msg.Args)
+	}
+	if len(parts) == 4 && parts[0] == "channel" && parts[2] == "playback" {
+		return s.handleChannelPlayback(parts[1], parts[3], msg.Args)
+	}
+	if len(parts) == 5 && parts[0] == "channel" && parts[2] == "plugin" && parts[4] == "bypass" {
+		return s.handleChannelPluginBypass(parts[1], parts[3], msg.Args)
+	}
+	if len(parts) != 3 || parts[0] != "channel" {
+		return fmt.Errorf("malformed channel address %q", msg.Address)
+	}
+	idPattern, param := parts[1], parts[2]
+
+	ids := s.resolveChannelIDs(idPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", idPattern)
+	}
+
+	var failures []string
+	for _, id := range ids {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: channel not found", id))
+			continue
+		}
+		if err := s.applyChannelParamRaw(id, ch, param, msg.Args); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// applyChannelParamRaw applies one /channel/<id>/<param> command to ch,
+// validating the argument synchronously but deferring volume/pan to the
+// dispatcher's param lane (see Dispatcher.SubmitParam).
+func (s *OSCServer) applyChannelParamRaw(id string, ch Channel, param string, args []interface{}) error {
+	switch param {
+	case "volume":
+		volume, ok := floatArg(args)
+		if !ok {
+			return fmt.Errorf("volume requires a float argument")
+		}
+		s.engine.GetDispatcher().SubmitParam(id, "volume", volume, ch.SetVolume)
+		return nil
+	case "pan":
+		pan, ok := floatArg(args)
+		if !ok {
+			return fmt.Errorf("pan requires a float argument")
+		}
+		s.engine.GetDispatcher().SubmitParam(id, "pan", pan, ch.SetPan)
+		return nil
+	case "mute":
+		mute, ok := intArg(args)
+		if !ok {
+			return fmt.Errorf("mute requires an int argument")
+		}
+		return ch.SetMute(mute != 0)
+	case "solo":
+		solo, ok := intArg(args)
+		if !ok {
+			return fmt.Errorf("solo requires an int argument")
+		}
+		s.engine.SetSoloed(id, solo != 0)
+		return nil
+	case "device":
+		uid, ok := stringArg(args)
+		if !ok {
+			return fmt.Errorf("device requires a string argument")
+		}
+		return s.engine.GetDispatcher().ChangeChannelDevice(id, uid)
+	default:
+		return fmt.Errorf("unknown channel parameter %q", param)
+	}
+}
+
+// handleChannelPlayback handles /channel/<id>/playback/<param> - rate and
+// pitch are coalesced through the dispatcher's param lane the same way
+// volume/pan are (see applyChannelParamRaw); play and stop take no
+// argument and are applied immediately, the same as mute. idPattern may be
+// an address pattern, same as every other /channel/<id>/... command.
+func (s *OSCServer) handleChannelPlayback(idPattern, param string, args []interface{}) error {
+	ids := s.resolveChannelIDs(idPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", idPattern)
+	}
+
+	var failures []string
+	for _, id := range ids {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: channel not found", id))
+			continue
+		}
+		pc, ok := ch.(*PlaybackChannel)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: not a playback channel", id))
+			continue
+		}
+		if err := s.applyPlaybackParam(id, pc, param, args); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// applyPlaybackParam applies one /channel/<id>/playback/<param> command to
+// pc.
+func (s *OSCServer) applyPlaybackParam(id string, pc *PlaybackChannel, param string, args []interface{}) error {
+	switch param {
+	case "rate":
+		rate, ok := floatArg(args)
+		if !ok {
+			return fmt.Errorf("rate requires a float argument")
+		}
+		s.engine.GetDispatcher().SubmitParam(id, "playback/rate", rate, pc.SetRate)
+		return nil
+	case "pitch":
+		pitch, ok := floatArg(args)
+		if !ok {
+			return fmt.Errorf("pitch requires a float argument")
+		}
+		s.engine.GetDispatcher().SubmitParam(id, "playback/pitch", pitch, pc.SetPitch)
+		return nil
+	case "play":
+		return pc.Play()
+	case "stop":
+		return pc.StopPlayback()
+	default:
+		return fmt.Errorf("unknown playback parameter %q", param)
+	}
+}
+
+// handleChannelSend handles /channel/<id>/send/<aux_id>/level, setting the
+// level of an existing send from channel <id> to aux channel <aux_id> (see
+// Channel.AddSend) - distinct from /aux/<id>/send, which sets an aux
+// channel's own overall output level rather than one source channel's send
+// into it. The level is submitted through the dispatcher's param lane,
+// keyed on the (channel, aux) pair, the same as /channel/<id>/volume.
+func (s *OSCServer) handleChannelSend(idPattern, auxID string, args []interface{}) error {
+	level, ok := floatArg(args)
+	if !ok {
+		return fmt.Errorf("send level requires a float argument")
+	}
+
+	aux, ok := s.engine.GetChannel(auxID)
+	if !ok {
+		return fmt.Errorf("aux channel %q not found", auxID)
+	}
+	auxChannel, ok := aux.(*AuxChannel)
+	if !ok {
+		return fmt.Errorf("%q is not an aux channel", auxID)
+	}
+
+	ids := s.resolveChannelIDs(idPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", idPattern)
+	}
+
+	var failures []string
+	for _, id := range ids {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: channel not found", id))
+			continue
+		}
+		s.engine.GetDispatcher().SubmitParam(id, "send:"+auxID, level, func(v float32) error {
+			return ch.SetSendLevel(auxChannel, v)
+		})
+	}
+	return combineErrors(failures)
+}
+
+// handleChannelPluginBypass handles /channel/<id>/plugin/<pid>/bypass,
+// addressing a plugin instance by its PluginInstance.ID directly rather
+// than its position in the chain the way /chain/<id>/effect/<idx>/bypass
+// and /plugin/<channel>/<slot>/param/<n> do - for a control surface that
+// already knows which plugin it wants by id (e.g. from a prior /info or
+// /list reply) instead of tracking chain order itself.
+func (s *OSCServer) handleChannelPluginBypass(idPattern, pluginID string, args []interface{}) error {
+	bypassed, ok := intArg(args)
+	if !ok {
+		return fmt.Errorf("bypass requires an int argument")
+	}
+
+	ids := s.resolveChannelIDs(idPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", idPattern)
+	}
+
+	var failures []string
+	for _, id := range ids {
+		if err := s.engine.SetPluginBypass(id, pluginID, bypassed != 0); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// isBatchableAddress reports whether addr is one of applyMessage's
+// parameter-update address families - the ones handleBundle gathers into a
+// single atomic Dispatcher.SubmitBatch operation rather than letting handle
+// apply them one at a time.
+func isBatchableAddress(addr string) bool {
+	switch {
+	case addr == "/master/fader", addr == "/master/volume", addr == "/output/device", addr == "/engine/output/device":
+		return true
+	case addr == "/channel/connect", addr == "/channel/disconnect":
+		return true
+	case strings.HasPrefix(addr, "/ch/"), strings.HasPrefix(addr, "/channel/"),
+		strings.HasPrefix(addr, "/aux/"), strings.HasPrefix(addr, "/chain/"),
+		strings.HasPrefix(addr, "/plugin/"), strings.HasPrefix(addr, "/sampler/"):
+		return true
+	default:
+		return false
+	}
+}
+
+// handleBundle is installed as the transport's BundleHandler (see
+// NewOSCServer), replacing dispatchPacket's default of exploding a Bundle
+// into one independent handle call per Message. Every isBatchableAddress
+// element is gathered into a closure and the whole set is submitted to the
+// dispatcher as a single SubmitBatch operation, so a controller's
+// multi-parameter bundle lands on the audio thread as one atomic step
+// instead of interleaving with whatever else the dispatcher is processing
+// between elements. Elements outside that set (queries, engine
+// start/stop, save/load) fall through to handle, one at a time, since
+// they aren't topology changes that need serializing together.
+func (s *OSCServer) handleBundle(bundle osc.Bundle, addr net.Addr) {
+	if !s.isAuthenticated(addr) {
+		s.sendError(addr, "#bundle", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	type pending struct {
+		msg osc.Message
+		err error
+	}
+	var batch []*pending
+
+	for _, msg := range bundle.Elements {
+		if !isBatchableAddress(msg.Address) {
+			s.handle(msg, addr)
+			continue
+		}
+		batch = append(batch, &pending{msg: msg})
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	fns := make([]func() error, len(batch))
+	for i, p := range batch {
+		p := p
+		fns[i] = func() error {
+			_, p.err = s.applyMessage(p.msg)
+			return p.err
+		}
+	}
+	s.engine.GetDispatcher().SubmitBatch(fns)
+
+	for _, p := range batch {
+		if p.err != nil {
+			s.sendError(addr, p.msg.Address, p.err)
+			continue
+		}
+		s.sendReply(addr, p.msg.Address)
+	}
+}
+
+func (s *OSCServer) handleAux(msg osc.Message) error {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "aux" || parts[2] != "send" {
+		return fmt.Errorf("malformed aux address %q", msg.Address)
+	}
+	pos, ok := floatArg(msg.Args)
+	if !ok {
+		return fmt.Errorf("send requires a float argument")
+	}
+
+	ids := s.resolveChannelIDs(parts[1])
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", parts[1])
+	}
+
+	var failures []string
+	for _, id := range ids {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: channel not found", id))
+			continue
+		}
+		aux, ok := ch.(*AuxChannel)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: not an aux channel", id))
+			continue
+		}
+		if err := aux.SetSendLevel(faderToGain(pos)); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// handleChain handles /chain/<id>/effect/<idx>/bypass and
+// /chain/<id>/effect/<idx>/param/<n>, addressing a plugin instance by its
+// position in channel <id>'s PluginChain (see Channel.GetPluginChain)
+// rather than its PluginInstance.ID, since a control surface knows a chain
+// as an ordered effect list, not by instance UUID.
+func (s *OSCServer) handleChain(msg osc.Message) error {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) < 5 || parts[0] != "chain" || parts[2] != "effect" {
+		return fmt.Errorf("malformed chain address %q", msg.Address)
+	}
+	channelPattern, effectIdx := parts[1], parts[3]
+
+	idx, err := strconv.Atoi(effectIdx)
+	if err != nil || idx < 0 {
+		return fmt.Errorf("invalid effect index %q", effectIdx)
+	}
+
+	ids := s.resolveChannelIDs(channelPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", channelPattern)
+	}
+
+	var failures []string
+	for _, channelID := range ids {
+		if err := s.applyChainCommand(channelID, idx, parts, msg.Args); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", channelID, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// applyChainCommand applies one /chain/<id>/effect/<idx>/... command to the
+// idx'th plugin instance in channelID's PluginChain.
+func (s *OSCServer) applyChainCommand(channelID string, idx int, parts []string, args []interface{}) error {
+	ch, ok := s.engine.GetChannel(channelID)
+	if !ok {
+		return fmt.Errorf("channel not found")
+	}
+	instances := ch.GetPluginChain().GetInstances()
+	if idx >= len(instances) {
+		return fmt.Errorf("effect index %d out of range (chain has %d)", idx, len(instances))
+	}
+	instance := instances[idx]
+
+	switch {
+	case len(parts) == 5 && parts[4] == "bypass":
+		bypassed, ok := intArg(args)
+		if !ok {
+			return fmt.Errorf("bypass requires an int argument")
+		}
+		return s.engine.SetPluginBypass(channelID, instance.ID, bypassed != 0)
+
+	case len(parts) == 6 && parts[4] == "param":
+		n, err := strconv.Atoi(parts[5])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid parameter index %q", parts[5])
+		}
+		plugin := instance.GetPlugin()
+		if plugin == nil || n >= len(plugin.Parameters) {
+			return fmt.Errorf("parameter index %d out of range", n)
+		}
+		value, ok := floatArg(args)
+		if !ok {
+			return fmt.Errorf("param requires a float argument")
+		}
+		return instance.SetParameter(plugin.Parameters[n].Identifier, value)
+
+	default:
+		return fmt.Errorf("unrecognized chain command %q", strings.Join(parts[4:], "/"))
+	}
+}
+
+// handlePluginParam handles /plugin/<channel>/<slot>/param/<index>, an
+// alias for /chain/<id>/effect/<idx>/param/<n> spelled channel-first the
+// way a dolmetschctl-style mixer bridge addresses it, rather than nested
+// under /chain/<id>/effect/<idx>. It reuses applyChainCommand so the two
+// address families can never disagree about how a plugin parameter is
+// applied.
+func (s *OSCServer) handlePluginParam(msg osc.Message) error {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 5 || parts[0] != "plugin" || parts[3] != "param" {
+		return fmt.Errorf("malformed plugin address %q", msg.Address)
+	}
+	channelPattern := parts[1]
+
+	idx, err := strconv.Atoi(parts[2])
+	if err != nil || idx < 0 {
+		return fmt.Errorf("invalid plugin slot %q", parts[2])
+	}
+
+	ids := s.resolveChannelIDs(channelPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", channelPattern)
+	}
+
+	chainParts := []string{"chain", channelPattern, "effect", parts[2], "param", parts[4]}
+	var failures []string
+	for _, channelID := range ids {
+		if err := s.applyChainCommand(channelID, idx, chainParts, msg.Args); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", channelID, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// handleSampler handles /sampler/<id>/note (note, velocity) and
+// /sampler/<id>/noteoff (note), driving id's SamplerChannel.StartNote/
+// StopNote on MIDI channel 0 - the same single-channel convenience
+// github.com/shaban/macaudio/engine's SamplerChannel.StartNote/StopNote use.
+// <id> may be an address-pattern, same as /channel/<id>/<param>. Replies
+// /error for an unknown id or a channel that isn't a SamplerChannel.
+func (s *OSCServer) handleSampler(msg osc.Message) error {
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+	if len(parts) != 3 || parts[0] != "sampler" {
+		return fmt.Errorf("malformed sampler address %q", msg.Address)
+	}
+	idPattern, action := parts[1], parts[2]
+
+	ids := s.resolveChannelIDs(idPattern)
+	if len(ids) == 0 {
+		return fmt.Errorf("no channel matches %q", idPattern)
+	}
+
+	var failures []string
+	for _, id := range ids {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: channel not found", id))
+			continue
+		}
+		sc, ok := ch.(*SamplerChannel)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: not a sampler channel", id))
+			continue
+		}
+		if err := s.applySamplerAction(sc, action, msg.Args); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	return combineErrors(failures)
+}
+
+// applySamplerAction applies one "note"/"noteoff" action to sc.
+func (s *OSCServer) applySamplerAction(sc *SamplerChannel, action string, args []interface{}) error {
+	switch action {
+	case "note":
+		if len(args) < 2 {
+			return fmt.Errorf("note requires note and velocity arguments")
+		}
+		note, ok1 := intArg(args[:1])
+		velocity, ok2 := intArg(args[1:2])
+		if !ok1 || !ok2 {
+			return fmt.Errorf("note and velocity must be numeric")
+		}
+		return sc.StartNote(0, int(note), int(velocity))
+	case "noteoff":
+		note, ok := intArg(args)
+		if !ok {
+			return fmt.Errorf("noteoff requires a note argument")
+		}
+		return sc.StopNote(0, int(note), 64)
+	default:
+		return fmt.Errorf("unknown sampler action %q", action)
+	}
+}
+
+func (s *OSCServer) handleMasterFader(msg osc.Message) error {
+	pos, ok := floatArg(msg.Args)
+	if !ok {
+		return fmt.Errorf("fader requires a float argument")
+	}
+	master := s.engine.GetMasterChannel()
+	if master == nil {
+		return fmt.Errorf("no master channel")
+	}
+	return master.SetMasterVolume(faderToGain(pos))
+}
+
+// handleMasterVolume handles /master/volume, the linear-gain sibling of
+// /master/fader (see the OSCServer doc comment), submitted through the
+// dispatcher's param lane the same way /channel/<id>/volume is.
+func (s *OSCServer) handleMasterVolume(msg osc.Message) error {
+	volume, ok := floatArg(msg.Args)
+	if !ok {
+		return fmt.Errorf("volume requires a float argument")
+	}
+	master := s.engine.GetMasterChannel()
+	if master == nil {
+		return fmt.Errorf("no master channel")
+	}
+	s.engine.GetDispatcher().SubmitParam("master", "volume", volume, master.SetMasterVolume)
+	return nil
+}
+
+func (s *OSCServer) handleSave(msg osc.Message) {
+	path, ok := stringArg(msg.Args)
+	if !ok {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_ = s.serializer.SaveToWriter(f)
+}
+
+func (s *OSCServer) handleLoad(msg osc.Message) {
+	path, ok := stringArg(msg.Args)
+	if !ok {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_ = s.serializer.LoadFromReader(f)
+}
+
+// handleStateDump handles /engine/state/dump, replying with an "/engine/state"
+// Message carrying the engine's current state as a JSON string (via
+// Serializer.SaveToJSON) - unlike every other reply in this file, it's sent
+// to a client-supplied return address (the command's string argument, an
+// OSC "host:port") rather than back to the sender's own address, so a
+// control surface listening on a different port than it sends from (or a
+// relay forwarding commands on a client's behalf) can still receive the
+// dump.
+func (s *OSCServer) handleStateDump(msg osc.Message, addr net.Addr) {
+	returnAddrStr, ok := stringArg(msg.Args)
+	if !ok {
+		s.sendError(addr, msg.Address, fmt.Errorf("state/dump requires a string return address"))
+		return
+	}
+	returnAddr, err := net.ResolveUDPAddr("udp", returnAddrStr)
+	if err != nil {
+		s.sendError(addr, msg.Address, fmt.Errorf("invalid return address %q: %w", returnAddrStr, err))
+		return
+	}
+
+	stateJSON, err := s.serializer.SaveToJSON()
+	if err != nil {
+		s.sendError(addr, msg.Address, err)
+		return
+	}
+
+	data, err := (osc.Message{Address: "/engine/state", Args: []interface{}{stateJSON}}).Marshal()
+	if err != nil {
+		s.sendError(addr, msg.Address, err)
+		return
+	}
+	_ = s.transport.SendTo(returnAddr, data)
+}
+
+// handleEngineStart handles /engine/start, replying with "/reply" on success
+// or "/error" if the engine was already running or failed to start.
+func (s *OSCServer) handleEngineStart(msg osc.Message, addr net.Addr) {
+	if err := s.engine.Start(); err != nil {
+		s.sendError(addr, msg.Address, err)
+		return
+	}
+	s.sendReply(addr, msg.Address)
+}
+
+// handleEngineStop handles /engine/stop, replying the same way
+// handleEngineStart does.
+func (s *OSCServer) handleEngineStop(msg osc.Message, addr net.Addr) {
+	if err := s.engine.Stop(); err != nil {
+		s.sendError(addr, msg.Address, err)
+		return
+	}
+	s.sendReply(addr, msg.Address)
+}
+
+// handleOutputDevice handles /output/device, switching the engine's output
+// hardware through the dispatcher the same way ChangeOutputDevice does.
+func (s *OSCServer) handleOutputDevice(msg osc.Message) error {
+	uid, ok := stringArg(msg.Args)
+	if !ok {
+		return fmt.Errorf("device requires a string argument")
+	}
+	return s.engine.GetDispatcher().ChangeOutputDevice(uid)
+}
+
+// handleChannelConnect handles /channel/connect (source id, target id,
+// bus), the OSC surface's only topology-creating command - everything else
+// in this file's /channel/<id>/... family touches a channel already in the
+// graph - wiring source into target via Dispatcher.ConnectChannels.
+func (s *OSCServer) handleChannelConnect(msg osc.Message) error {
+	source, target, bus, ok := connectArgs(msg.Args)
+	if !ok {
+		return fmt.Errorf("connect requires source (string), target (string), and bus (int) arguments")
+	}
+	return s.engine.GetDispatcher().ConnectChannels(source, target, bus)
+}
+
+// handleChannelDisconnect handles /channel/disconnect (source id, target
+// id, bus), connect's counterpart, via Dispatcher.DisconnectChannels.
+func (s *OSCServer) handleChannelDisconnect(msg osc.Message) error {
+	source, target, bus, ok := connectArgs(msg.Args)
+	if !ok {
+		return fmt.Errorf("disconnect requires source (string), target (string), and bus (int) arguments")
+	}
+	return s.engine.GetDispatcher().DisconnectChannels(source, target, bus)
+}
+
+// connectArgs parses /channel/connect and /channel/disconnect's shared
+// argument shape: source id, target id, bus.
+func connectArgs(args []interface{}) (source, target string, bus int, ok bool) {
+	if len(args) < 3 {
+		return "", "", 0, false
+	}
+	source, ok1 := stringArg(args[:1])
+	target, ok2 := stringArg(args[1:2])
+	busArg, ok3 := intArg(args[2:3])
+	if !ok1 || !ok2 || !ok3 {
+		return "", "", 0, false
+	}
+	return source, target, int(busArg), true
+}
+
+func (s *OSCServer) handleSubscribe(addr net.Addr) {
+	s.subMu.Lock()
+	s.subscribers[addr.String()] = addr
+	s.lastSeen[addr.String()] = time.Now()
+	s.subMu.Unlock()
+}
+
+func (s *OSCServer) handleUnsubscribe(addr net.Addr) {
+	s.subMu.Lock()
+	delete(s.subscribers, addr.String())
+	delete(s.lastSeen, addr.String())
+	s.subMu.Unlock()
+}
+
+// handleHeartbeat handles /heartbeat, renewing addr's subscription expiry
+// (see subscriptionTimeout/sweepSubscribers) without re-registering it the
+// way /subscribe does - the lightweight keepalive a control surface sends
+// between real commands so a quiet-but-still-connected session isn't swept.
+// A no-op for an addr that isn't currently subscribed.
+func (s *OSCServer) handleHeartbeat(addr net.Addr) {
+	s.subMu.Lock()
+	if _, ok := s.subscribers[addr.String()]; ok {
+		s.lastSeen[addr.String()] = time.Now()
+	}
+	s.subMu.Unlock()
+}
+
+// subscriptionTimeout is how long a subscriber may go without sending
+// /subscribe or /heartbeat before sweepSubscribers drops it - the same
+// register-then-keepalive pattern a conference mixer control daemon uses to
+// notice a client that vanished without sending /unsubscribe (a crashed
+// process, a network partition) instead of leaking it forever.
+const subscriptionTimeout = 30 * time.Second
+
+// heartbeatSweepInterval is how often sweepSubscribers checks for an
+// expired subscriber - comfortably inside subscriptionTimeout so a lapsed
+// client is noticed well within one timeout window rather than right at
+// its edge.
+const heartbeatSweepInterval = 10 * time.Second
+
+// sweepSubscribers periodically drops any subscriber that hasn't renewed
+// via /subscribe or /heartbeat within subscriptionTimeout, until Close
+// closes sweepStop.
+func (s *OSCServer) sweepSubscribers() {
+	ticker := time.NewTicker(heartbeatSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.sweepStop:
+			return
+		case <-ticker.C:
+			s.dropExpiredSubscribers()
+		}
+	}
+}
+
+// dropExpiredSubscribers removes every subscriber last seen before
+// subscriptionTimeout ago.
+func (s *OSCServer) dropExpiredSubscribers() {
+	cutoff := time.Now().Add(-subscriptionTimeout)
+	s.subMu.Lock()
+	for key, seen := range s.lastSeen {
+		if seen.Before(cutoff) {
+			delete(s.subscribers, key)
+			delete(s.lastSeen, key)
+		}
+	}
+	s.subMu.Unlock()
+}
+
+// infoBundle builds the /info reply: one Message per channel with its
+// fader position, mute state, and type, plus a summary Message.
+func (s *OSCServer) infoBundle() osc.Bundle {
+	state := s.serializer.GetState()
+
+	var bundle osc.Bundle
+	for id, ch := range state.Channels {
+		bundle.Elements = append(bundle.Elements, osc.Message{
+			Address: "/info/channel",
+			Args:    []interface{}{id, string(ch.Type), gainToFader(ch.Volume), boolToInt32(ch.Muted)},
+		})
+	}
+	bundle.Elements = append(bundle.Elements, osc.Message{
+		Address: "/info/engine",
+		Args:    []interface{}{state.Version, int32(len(state.Channels))},
+	})
+	return bundle
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sendReply acknowledges a command that was understood and applied.
+func (s *OSCServer) sendReply(addr net.Addr, forAddress string) {
+	data, err := (osc.Message{Address: "/reply", Args: []interface{}{forAddress}}).Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+// sendError reports a command that was understood but couldn't be applied -
+// an unknown channel, a wrong argument type, an out-of-range effect index.
+// It's distinct from an unrecognized address, which handle ignores outright.
+func (s *OSCServer) sendError(addr net.Addr, forAddress string, cause error) {
+	data, err := (osc.Message{Address: "/error", Args: []interface{}{forAddress, cause.Error()}}).Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+func (s *OSCServer) sendInfo(addr net.Addr) {
+	data, err := s.infoBundle().Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+// sendStatus replies to /status with a single lightweight Message - engine
+// running, channel count, subscriber count, plus the dispatcher's param
+// lane depth and coalesce ratio - for a control surface that wants a cheap
+// heartbeat without paying for /info's full per-channel dump.
+func (s *OSCServer) sendStatus(addr net.Addr) {
+	s.subMu.Lock()
+	subs := int32(len(s.subscribers))
+	s.subMu.Unlock()
+
+	stats := s.engine.GetDispatcher().GetPerformanceStats()
+	msg := osc.Message{
+		Address: "/status",
+		Args: []interface{}{
+			boolToInt32(s.engine.IsRunning()),
+			int32(len(s.engine.ListChannels())),
+			subs,
+			int32(stats.ParamLaneDepth),
+			float32(stats.ParamLaneCoalesceRatio),
+		},
+	}
+	data, err := msg.Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+// listBundle builds the /list reply: one Message per channel with just its
+// id and type, for a control surface enumerating what's available to
+// address rather than reading every channel's current fader/mute state
+// (see infoBundle).
+func (s *OSCServer) listBundle() osc.Bundle {
+	var bundle osc.Bundle
+	for _, id := range s.engine.ListChannels() {
+		ch, ok := s.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		bundle.Elements = append(bundle.Elements, osc.Message{
+			Address: "/list/channel",
+			Args:    []interface{}{id, string(ch.GetType())},
+		})
+	}
+	return bundle
+}
+
+func (s *OSCServer) sendList(addr net.Addr) {
+	data, err := s.listBundle().Marshal()
+	if err != nil {
+		return
+	}
+	_ = s.transport.SendTo(addr, data)
+}
+
+// subscriberAddrs returns a snapshot of every currently-subscribed address,
+// for broadcastInfo and broadcastDeviceEvent to push to without holding
+// subMu while they call out to the transport.
+func (s *OSCServer) subscriberAddrs() []net.Addr {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	addrs := make([]net.Addr, 0, len(s.subscribers))
+	for _, a := range s.subscribers {
+		addrs = append(addrs, a)
+	}
+	return addrs
+}
+
+// broadcastInfo pushes the current state to every subscriber; it's what
+// OnStateChange fires after a successful SetState.
+func (s *OSCServer) broadcastInfo() {
+	bundle, err := s.infoBundle().Marshal()
+	if err != nil {
+		return
+	}
+	for _, addr := range s.subscriberAddrs() {
+		_ = s.transport.SendTo(addr, bundle)
+	}
+}
+
+// broadcastDeviceEvent pushes a device-hotplug DispatcherEvent
+// (EventDeviceLost/EventDeviceRestored/EventFallbackActivated/
+// EventFormatChanged/EventOperationFailed - see EngineConfig.OnDeviceLost
+// and Engine.handleChannelDeviceStatusChanged) out to every subscribed
+// client as a "/device/event" Message, so an external control surface stays
+// in sync when the engine changes its own output device internally (e.g.
+// after a device-loss fallback) rather than in response to an OSC command.
+// ev.ChannelID is empty for the engine-wide primary-output events and set
+// for the per-channel ones. Paired with broadcastInfo, this is this
+// server's half of the OSCBroadcaster role described in the OSC
+// control-surface design: both mean "state changed," whether the caller or
+// the engine itself caused it.
+func (s *OSCServer) broadcastDeviceEvent(ev DispatcherEvent) {
+	data, err := (osc.Message{Address: "/device/event", Args: []interface{}{ev.Type, ev.ChannelID, ev.Path}}).Marshal()
+	if err != nil {
+		return
+	}
+	for _, addr := range s.subscriberAddrs() {
+		_ = s.transport.SendTo(addr, data)
+	}
+}
+
+// forwardEvents relays every EngineEvent the dispatcher's peer-style
+// Subscribe stream produces out to subscribers as a "/event/<kind>"
+// Message - the general-purpose counterpart to broadcastInfo (a full-state
+// push on SetState) and broadcastDeviceEvent (device hotplug only): a
+// control surface that wants every parameter/connection/error change as it
+// happens, not just a periodic snapshot, subscribes once and reads this
+// stream instead of polling /info. It runs until events closes, which
+// Close's eventsCancel call does.
+func (s *OSCServer) forwardEvents(events <-chan EngineEvent) {
+	for ev := range events {
+		s.broadcastEngineEvent(ev)
+	}
+}
+
+// broadcastEngineEvent renders one EngineEvent as a "/event/<kind>"
+// Message and pushes it to every subscriber. Data shapes not recognized
+// below (EventTypeTapLevel has no payload struct defined anywhere in this
+// tree yet, and publishOperationEvent never emits one) fall through to a
+// best-effort "/event/unknown" carrying Type and a %v rendering of Data,
+// rather than silently dropping an event a future dispatcher change starts
+// publishing.
+func (s *OSCServer) broadcastEngineEvent(ev EngineEvent) {
+	var msg osc.Message
+	switch data := ev.Data.(type) {
+	case ChannelParamChanged:
+		msg = osc.Message{
+			Address: "/event/channel_param",
+			Args:    []interface{}{data.ChannelID, data.Param, data.Value, string(data.Source)},
+		}
+	case PluginParamChanged:
+		msg = osc.Message{
+			Address: "/event/plugin_param",
+			Args:    []interface{}{data.ChannelID, data.PluginID, data.ParamAddr, data.Value, string(data.Source)},
+		}
+	case ConnectionChanged:
+		msg = osc.Message{
+			Address: "/event/connection",
+			Args:    []interface{}{data.Src, data.Dst, int32(data.Bus)},
+		}
+	case PlayerStateChanged:
+		msg = osc.Message{
+			Address: "/event/player_state",
+			Args:    []interface{}{data.PlayerID, data.State, float32(data.Position.Seconds())},
+		}
+	case EventError:
+		msg = osc.Message{
+			Address: "/event/error",
+			Args:    []interface{}{string(data.Op), data.Err.Error()},
+		}
+	default:
+		msg = osc.Message{
+			Address: "/event/unknown",
+			Args:    []interface{}{string(ev.Type), fmt.Sprintf("%v", ev.Data)},
+		}
+	}
+
+	encoded, err := msg.Marshal()
+	if err != nil {
+		return
+	}
+	for _, addr := range s.subscriberAddrs() {
+		_ = s.transport.SendTo(addr, encoded)
+	}
+}
@@ -0,0 +1,533 @@
+package macaudio
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/avaudio/osc"
+)
+
+// TestOSCServerMasterFaderAndMute drives /master/fader over UDP and checks
+// it lands on the master channel as the expected linear gain via the
+// dB-tapered fader curve.
+func TestOSCServerMasterFaderAndMute(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, serializer, transport)
+	defer server.Close()
+	go server.Serve()
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	send := func(addr string, args ...interface{}) {
+		data, err := osc.Message{Address: addr, Args: args}.Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal %s: %v", addr, err)
+		}
+		if _, err := client.Write(data); err != nil {
+			t.Fatalf("Failed to send %s: %v", addr, err)
+		}
+	}
+
+	send("/master/fader", float32(1.0))
+	time.Sleep(50 * time.Millisecond)
+
+	master := eng.GetMasterChannel()
+	volume, err := master.GetMasterVolume()
+	if err != nil {
+		t.Fatalf("GetMasterVolume failed: %v", err)
+	}
+	if volume < 0.99 {
+		t.Fatalf("expected unity gain at fader position 1.0, got %f", volume)
+	}
+}
+
+// TestFaderGainCurveRoundTrips checks faderToGain/gainToFader agree with
+// each other at the extremes and at unity.
+func TestFaderGainCurveRoundTrips(t *testing.T) {
+	if g := faderToGain(1.0); g != 1.0 {
+		t.Errorf("expected unity gain at fader 1.0, got %f", g)
+	}
+	if g := faderToGain(0.0); g != 0.0 {
+		t.Errorf("expected zero gain at fader 0.0, got %f", g)
+	}
+	if p := gainToFader(1.0); p != 1.0 {
+		t.Errorf("expected fader 1.0 at unity gain, got %f", p)
+	}
+	if p := gainToFader(0.0); p != 0.0 {
+		t.Errorf("expected fader 0.0 at zero gain, got %f", p)
+	}
+}
+
+// TestOSCServerWildcardAndReply drives "/ch/*/mute" over UDP and checks it
+// mutes every channel, then checks that a successful command gets a /reply
+// and a malformed one gets an /error, both via OSCClient.
+func TestOSCServerWildcardAndReply(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, serializer, transport)
+	defer server.Close()
+	go server.Serve()
+
+	const numChannels = 3
+	ids := make([]string, numChannels)
+	for i := 0; i < numChannels; i++ {
+		id := fmt.Sprintf("osc-wild-%d", i)
+		if _, err := eng.CreateSilenceChannel(id); err != nil {
+			t.Fatalf("Failed to create channel %d: %v", i, err)
+		}
+		ids[i] = id
+	}
+
+	client, err := DialOSC(transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Request(time.Second, "/ch/osc-wild-*/mute", int32(1)); err != nil {
+		t.Fatalf("Expected /reply for wildcard mute, got error: %v", err)
+	}
+
+	for _, id := range ids {
+		ch, ok := eng.GetChannel(id)
+		if !ok {
+			t.Fatalf("channel %s went missing", id)
+		}
+		muted, err := ch.GetMute()
+		if err != nil {
+			t.Fatalf("GetMute failed for %s: %v", id, err)
+		}
+		if !muted {
+			t.Errorf("expected wildcard mute to reach channel %s", id)
+		}
+	}
+
+	if err := client.Request(time.Second, "/ch/does-not-exist/mute", int32(1)); err == nil {
+		t.Fatal("Expected an /error response for an unknown channel")
+	}
+}
+
+// TestOSCServerConcurrentPackets pumps concurrent /fader and /mute packets
+// at several channels at once, mirroring what TestDispatcherRaceConditions
+// asserts for direct dispatcher submission: concurrent control-surface
+// traffic shouldn't race or leave the engine in an inconsistent state, since
+// every packet ultimately reaches Channel.SetVolume/SetMute through the
+// dispatcher's single operations goroutine.
+func TestOSCServerConcurrentPackets(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, serializer, transport)
+	defer server.Close()
+	go server.Serve()
+
+	const numChannels = 5
+	ids := make([]string, numChannels)
+	for i := 0; i < numChannels; i++ {
+		id := fmt.Sprintf("osc-race-%d", i)
+		if _, err := eng.CreateSilenceChannel(id); err != nil {
+			t.Fatalf("Failed to create channel %d: %v", i, err)
+		}
+		ids[i] = id
+	}
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	send := func(addr string, args ...interface{}) {
+		data, err := osc.Message{Address: addr, Args: args}.Marshal()
+		if err != nil {
+			return
+		}
+		_, _ = client.Write(data)
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		id := id
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				send(fmt.Sprintf("/ch/%s/fader", id), float32(i%2))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				send(fmt.Sprintf("/ch/%s/mute", id), int32(i%2))
+			}
+		}()
+	}
+	wg.Wait()
+	time.Sleep(100 * time.Millisecond)
+
+	for _, id := range ids {
+		ch, ok := eng.GetChannel(id)
+		if !ok {
+			t.Fatalf("channel %s went missing under concurrent OSC traffic", id)
+		}
+		if volume, err := ch.GetVolume(); err != nil || volume < 0 || volume > 1 {
+			t.Errorf("channel %s ended with invalid volume %f (err %v)", id, volume, err)
+		}
+	}
+}
+
+// TestOSCServerRawChannelAddressCoalesces drives a burst of /channel/<id>/volume
+// messages (the linear-gain sibling of /ch/<id>/fader) and checks that only
+// the final value survives to Channel.SetVolume, and that /status reports a
+// sane summary.
+func TestOSCServerRawChannelAddressCoalesces(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, serializer, transport)
+	defer server.Close()
+	go server.Serve()
+
+	id := "osc-raw-1"
+	if _, err := eng.CreateSilenceChannel(id); err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	send := func(addr string, args ...interface{}) {
+		data, err := osc.Message{Address: addr, Args: args}.Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal %s: %v", addr, err)
+		}
+		if _, err := client.Write(data); err != nil {
+			t.Fatalf("Failed to send %s: %v", addr, err)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		send(fmt.Sprintf("/channel/%s/volume", id), float32(i)/20)
+	}
+	send(fmt.Sprintf("/channel/%s/volume", id), float32(0.42))
+	time.Sleep(2 * paramDrainInterval)
+
+	ch, ok := eng.GetChannel(id)
+	if !ok {
+		t.Fatalf("channel %s went missing", id)
+	}
+	volume, err := ch.GetVolume()
+	if err != nil {
+		t.Fatalf("GetVolume failed: %v", err)
+	}
+	if volume < 0.41 || volume > 0.43 {
+		t.Errorf("expected coalesced volume ~0.42, got %f", volume)
+	}
+
+	send("/status")
+	time.Sleep(50 * time.Millisecond)
+}
+
+// TestEngineEnableDisableOSC checks that EnableOSC spins up a server
+// reachable over UDP, that DisableOSC tears it down, and that EnableOSC
+// rejects being called twice without an intervening DisableOSC.
+func TestEngineEnableDisableOSC(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	if eng.OSCEnabled() {
+		t.Fatal("expected OSC to start disabled")
+	}
+
+	if err := eng.EnableOSC(OSCConfig{Addr: "127.0.0.1:0"}); err != nil {
+		t.Fatalf("EnableOSC failed: %v", err)
+	}
+	if !eng.OSCEnabled() {
+		t.Fatal("expected OSCEnabled to report true after EnableOSC")
+	}
+
+	if err := eng.EnableOSC(OSCConfig{Addr: "127.0.0.1:0"}); err == nil {
+		t.Fatal("expected a second EnableOSC to fail while already enabled")
+	}
+
+	if err := eng.DisableOSC(); err != nil {
+		t.Fatalf("DisableOSC failed: %v", err)
+	}
+	if eng.OSCEnabled() {
+		t.Fatal("expected OSCEnabled to report false after DisableOSC")
+	}
+
+	// A no-op second DisableOSC shouldn't error.
+	if err := eng.DisableOSC(); err != nil {
+		t.Fatalf("expected second DisableOSC to be a no-op, got %v", err)
+	}
+}
+
+// TestOSCServerDeviceAndLifecycleAddresses drives /channel/<id>/device,
+// /output/device, and /engine/start + /engine/stop, checking each gets a
+// "/reply" or "/error" as appropriate via OSCClient.
+func TestOSCServerDeviceAndLifecycleAddresses(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, serializer, transport)
+	defer server.Close()
+	go server.Serve()
+
+	id := "osc-device-1"
+	if _, err := eng.CreateSilenceChannel(id); err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+
+	client, err := DialOSC(transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Request(time.Second, fmt.Sprintf("/channel/%s/device", id), "not-a-real-device"); err == nil {
+		t.Fatal("expected an /error response for an unknown device UID")
+	}
+
+	if err := client.Request(time.Second, "/channel/does-not-exist/device", "not-a-real-device"); err == nil {
+		t.Fatal("expected an /error response for an unknown channel")
+	}
+
+	if err := client.Request(time.Second, "/output/device", "not-a-real-device"); err == nil {
+		t.Fatal("expected an /error response for an unknown output device UID")
+	}
+
+	if err := client.Request(time.Second, "/engine/stop"); err != nil {
+		t.Fatalf("expected /engine/stop to succeed, got error: %v", err)
+	}
+	if err := client.Request(time.Second, "/engine/start"); err != nil {
+		t.Fatalf("expected /engine/start to succeed, got error: %v", err)
+	}
+}
+
+// TestOSCServerCloseIsIdempotent checks a second Close doesn't panic closing
+// an already-closed channel.
+func TestOSCServerCloseIsIdempotent(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, eng.GetSerializer(), transport)
+
+	if err := server.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := server.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+// TestOSCServerChannelPlayback drives /channel/<id>/playback/{rate,pitch,stop}
+// against a real PlaybackChannel and checks each gets an immediate /reply -
+// rate and pitch go through the same coalesced SubmitParam lane
+// TestOSCServerRawChannelAddressCoalesces exercises for /volume, so the
+// /reply here only confirms the command was accepted and queued, not that a
+// real AudioPlayer applied it (that needs an actual decoded file, which
+// CreatePlaybackChannel defers opening until Play - see NewPlaybackChannel).
+// It also checks that the same addresses get an /error against a channel
+// type that isn't a PlaybackChannel, and against an unknown channel.
+func TestOSCServerChannelPlayback(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, serializer, transport)
+	defer server.Close()
+	go server.Serve()
+
+	id := "osc-playback-1"
+	playbackConfig := PlaybackConfig{FilePath: "/nonexistent/file.wav"}
+	if _, err := eng.CreatePlaybackChannel(id, playbackConfig); err != nil {
+		t.Fatalf("Failed to create playback channel: %v", err)
+	}
+
+	client, err := DialOSC(transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Request(time.Second, fmt.Sprintf("/channel/%s/playback/rate", id), float32(1.5)); err != nil {
+		t.Fatalf("Expected /reply for playback rate, got error: %v", err)
+	}
+	if err := client.Request(time.Second, fmt.Sprintf("/channel/%s/playback/pitch", id), float32(-3)); err != nil {
+		t.Fatalf("Expected /reply for playback pitch, got error: %v", err)
+	}
+	// Stop is safe to call whether or not Play ever succeeded.
+	if err := client.Request(time.Second, fmt.Sprintf("/channel/%s/playback/stop", id)); err != nil {
+		t.Fatalf("expected /reply for playback stop, got error: %v", err)
+	}
+
+	if err := client.Request(time.Second, "/channel/does-not-exist/playback/rate", float32(1.5)); err == nil {
+		t.Fatal("expected an /error response for an unknown channel")
+	}
+
+	synthID := "osc-playback-synth-1"
+	if _, err := eng.CreateSilenceChannel(synthID); err != nil {
+		t.Fatalf("Failed to create silence channel: %v", err)
+	}
+	if err := client.Request(time.Second, fmt.Sprintf("/channel/%s/playback/stop", synthID)); err == nil {
+		t.Fatal("expected an /error response for playback/stop against a non-playback channel")
+	}
+}
+
+// TestOSCServerStateDump drives /engine/state/dump, checking the server
+// replies with an "/engine/state" message carrying SaveToJSON's output to
+// the client-supplied return address rather than to the sender's own
+// address, and that a malformed return address gets an /error instead.
+func TestOSCServerStateDump(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	transport, err := osc.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen UDP: %v", err)
+	}
+	server := NewOSCServer(eng, serializer, transport)
+	defer server.Close()
+	go server.Serve()
+
+	returnConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("Failed to listen for the state dump return: %v", err)
+	}
+	defer returnConn.Close()
+
+	client, err := net.Dial("udp", transport.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial OSC server: %v", err)
+	}
+	defer client.Close()
+
+	send := func(args ...interface{}) {
+		data, err := (osc.Message{Address: "/engine/state/dump", Args: args}).Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal /engine/state/dump: %v", err)
+		}
+		if _, err := client.Write(data); err != nil {
+			t.Fatalf("Failed to send /engine/state/dump: %v", err)
+		}
+	}
+
+	send(returnConn.LocalAddr().String())
+
+	if err := returnConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Failed to set read deadline: %v", err)
+	}
+	buf := make([]byte, 65536)
+	n, _, err := returnConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("expected an /engine/state message at the return address: %v", err)
+	}
+	reply, err := osc.Unmarshal(buf[:n])
+	if err != nil {
+		t.Fatalf("Failed to parse /engine/state: %v", err)
+	}
+	if reply.Address != "/engine/state" {
+		t.Fatalf("expected /engine/state, got %s", reply.Address)
+	}
+	if len(reply.Args) != 1 {
+		t.Fatalf("expected a single JSON string argument, got %d", len(reply.Args))
+	}
+	stateJSON, ok := reply.Args[0].(string)
+	if !ok || stateJSON == "" {
+		t.Fatalf("expected a non-empty JSON string, got %v", reply.Args[0])
+	}
+
+	send("not-a-valid-address")
+	time.Sleep(50 * time.Millisecond)
+}
@@ -0,0 +1,327 @@
+package macaudio
+
+import (
+	"fmt"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// OutputRole describes what a given OutputRoute is used for, so callers can
+// pick a route by intent ("send the cue mix to whatever is wired up as the
+// monitor") rather than by a specific device UID.
+type OutputRole string
+
+const (
+	RolePrimary OutputRole = "primary"  // the main listening/FOH output
+	RoleMonitor OutputRole = "monitor"  // live-monitor / headphone output
+	RoleCueSend OutputRole = "cue_send" // DJ-style pre-listen/cue output
+)
+
+// OutputRoute names one physical destination the engine can route a channel
+// to: a device UID, the engine bus channels that feed it (ChannelMap[i] is
+// the device channel that engine bus channel i maps to), and a Role used to
+// look the route up by intent via RouteChannelTo. Name is the identifier
+// RouteChannelTo and dispatcher operations address the route by; it has no
+// relation to the device's own name.
+type OutputRoute struct {
+	Name       string
+	DeviceUID  string
+	ChannelMap []int
+	Role       OutputRole
+}
+
+// expandOutputDeviceUID builds the one-element Outputs slice that a legacy
+// EngineConfig.OutputDeviceUID expands into, for backward compatibility with
+// configs that predate multi-output routing.
+func expandOutputDeviceUID(deviceUID string) []OutputRoute {
+	return []OutputRoute{{
+		Name:      "primary",
+		DeviceUID: deviceUID,
+		Role:      RolePrimary,
+	}}
+}
+
+// validateOutputRoutes checks that every route names an online device and,
+// when more than one distinct device is listed, that the devices share at
+// least one sample rate - AVAudioEngine (and the aggregate device it would
+// target) cannot run sub-devices whose native rates don't intersect.
+func validateOutputRoutes(routes []OutputRoute) error {
+	if len(routes) == 0 {
+		return fmt.Errorf("at least one OutputRoute is required in EngineConfig")
+	}
+
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		return fmt.Errorf("failed to enumerate audio devices: %w", err)
+	}
+
+	seen := make(map[string]devices.AudioDevice)
+	for _, route := range routes {
+		if route.Name == "" {
+			return fmt.Errorf("OutputRoute is missing a Name")
+		}
+		if _, exists := seen[route.DeviceUID]; exists {
+			continue
+		}
+
+		device := audioDevices.ByUID(route.DeviceUID)
+		if device == nil {
+			return fmt.Errorf("output device with UID %s not found", route.DeviceUID)
+		}
+		if !device.IsOnline {
+			return fmt.Errorf("output device %s is not online", route.DeviceUID)
+		}
+		seen[route.DeviceUID] = *device
+	}
+
+	if len(seen) < 2 {
+		return nil
+	}
+
+	var common []int
+	first := true
+	for _, device := range seen {
+		if first {
+			common = device.SupportedSampleRates
+			first = false
+			continue
+		}
+		intersected := make([]int, 0, len(common))
+		rates := make(map[int]bool, len(device.SupportedSampleRates))
+		for _, r := range device.SupportedSampleRates {
+			rates[r] = true
+		}
+		for _, r := range common {
+			if rates[r] {
+				intersected = append(intersected, r)
+			}
+		}
+		common = intersected
+	}
+
+	if len(common) == 0 {
+		return fmt.Errorf("output routes span sub-devices with no common sample rate, cannot form an aggregate device")
+	}
+
+	return nil
+}
+
+// routeByName returns the route in routes with the given name, or nil.
+func routeByName(routes []OutputRoute, name string) *OutputRoute {
+	for i := range routes {
+		if routes[i].Name == name {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+// RouteChannelTo points channelID's output at the named OutputRoute (see
+// EngineConfig.Outputs), e.g. sending a cue aux to a USB headphone amp while
+// the master bus keeps going to the main interface. It is a topology change
+// and so goes through the dispatcher like ChangeChannelDevice/
+// ChangeOutputDevice.
+func (e *Engine) RouteChannelTo(channelID, routeName string) error {
+	return e.dispatcher.RouteChannelTo(channelID, routeName)
+}
+
+// RouteChannelTo changes which OutputRoute channelID's output is sent to via
+// dispatcher (topology change).
+func (d *Dispatcher) RouteChannelTo(channelID, routeName string) error {
+	return d.submit(DispatcherOperation{
+		Type: OpRouteChannel,
+		Data: RouteChannelData{ChannelID: channelID, RouteName: routeName},
+	}).Error
+}
+
+// RouteChannelData carries the arguments for OpRouteChannel.
+type RouteChannelData struct {
+	ChannelID string
+	RouteName string
+}
+
+// routeChannelTo is OpRouteChannel's dispatcher-thread implementation. The
+// actual per-bus device channel mapping is a native AVFoundation concern
+// (see ensureAggregateOutput); this records the channel's assigned route so
+// callers can query it back and so ensureAggregateOutput knows which device
+// each channel's mixer should connect to.
+func (d *Dispatcher) routeChannelTo(channelID, routeName string) error {
+	if _, exists := d.engine.GetChannel(channelID); !exists {
+		return fmt.Errorf("channel with ID %s not found", channelID)
+	}
+
+	d.engine.mu.Lock()
+	defer d.engine.mu.Unlock()
+
+	route := routeByName(d.engine.outputRoutes, routeName)
+	if route == nil {
+		return fmt.Errorf("output route %q not found", routeName)
+	}
+
+	if d.engine.channelRoutes == nil {
+		d.engine.channelRoutes = make(map[string]string)
+	}
+	d.engine.channelRoutes[channelID] = routeName
+
+	return nil
+}
+
+// GetChannelRoute returns the name of the OutputRoute channelID was last
+// assigned to via RouteChannelTo, defaulting to the primary route's name
+// ("primary" after EngineConfig.OutputDeviceUID expansion) when the channel
+// was never explicitly routed.
+func (e *Engine) GetChannelRoute(channelID string) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if name, ok := e.channelRoutes[channelID]; ok {
+		return name
+	}
+	for _, route := range e.outputRoutes {
+		if route.Role == RolePrimary {
+			return route.Name
+		}
+	}
+	if len(e.outputRoutes) > 0 {
+		return e.outputRoutes[0].Name
+	}
+	return ""
+}
+
+// degradeOutputRoute removes every OutputRoute targeting deviceUID (called
+// from DeviceMonitor when that device is hot-unplugged) and reassigns any
+// channel that was routed to one of them onto the remaining primary route.
+// It never returns an error and never touches e.isRunning: a monitor mix
+// losing its USB headphone amp degrades to the routes still standing rather
+// than taking the whole engine down.
+//
+// This runs on whatever goroutine avengine.OnDeviceChange's background poll
+// calls DeviceMonitor.handleDeviceChangeEvent from, serialized by e.mu rather
+// than the dispatcher - it asserts that explicitly, since if it ever ran on
+// the dispatch loop goroutine instead, routing a future topology fix through
+// the dispatcher from in here would deadlock against itself.
+func (e *Engine) degradeOutputRoute(deviceUID string) {
+	e.dispatcher.AssertNotOnDispatcher()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var removedNames []string
+	kept := e.outputRoutes[:0]
+	for _, route := range e.outputRoutes {
+		if route.DeviceUID == deviceUID {
+			removedNames = append(removedNames, route.Name)
+			continue
+		}
+		kept = append(kept, route)
+	}
+	if len(removedNames) == 0 {
+		return
+	}
+	e.outputRoutes = kept
+
+	fallback := ""
+	for _, route := range e.outputRoutes {
+		if route.Role == RolePrimary {
+			fallback = route.Name
+			break
+		}
+	}
+	if fallback == "" && len(e.outputRoutes) > 0 {
+		fallback = e.outputRoutes[0].Name
+	}
+
+	removed := make(map[string]bool, len(removedNames))
+	for _, name := range removedNames {
+		removed[name] = true
+	}
+	for channelID, routeName := range e.channelRoutes {
+		if removed[routeName] {
+			e.channelRoutes[channelID] = fallback
+		}
+	}
+
+	e.errorHandler.HandleError(fmt.Errorf(
+		"output device %s went offline: removed route(s) %v, degraded to %q", deviceUID, removedNames, fallback))
+}
+
+// OutputRoutingOptions picks where an individual channel's mixer output
+// should land, beyond the RouteName-only granularity RouteChannelTo offers:
+// Bus selects a bus within that route's device instead of always the main
+// mixer's bus 0. RouteName empty keeps the channel's current route (see
+// Engine.GetChannelRoute); Bus zero is today's behavior for every existing
+// channel type.
+//
+// Bus is recorded on the channel (see BaseChannel.GetOutputRouting) but
+// ensureAggregateOutput doesn't yet wire a channel mixer to anything other
+// than mainMixer's bus 0 - connecting a channel straight to a non-zero
+// output-device bus needs the engine's output node addressed directly
+// instead of through mainMixer, which the current graph topology (every
+// channel mixer -> mainMixer -> outputNode) doesn't support. SetOutputRouting
+// is the place a future multi-bus-aware Start would read Bus from.
+type OutputRoutingOptions struct {
+	RouteName string
+	Bus       int
+}
+
+// SetOutputRouting records opts as this channel's output routing, updating
+// the engine's RouteChannelTo assignment when opts.RouteName is set. See
+// OutputRoutingOptions for what Bus does and doesn't do yet.
+func (bc *BaseChannel) SetOutputRouting(opts OutputRoutingOptions) error {
+	if opts.RouteName != "" && bc.engine != nil {
+		if err := bc.engine.RouteChannelTo(bc.GetIDString(), opts.RouteName); err != nil {
+			return err
+		}
+	}
+
+	bc.mu.Lock()
+	bc.outputRouting = opts
+	bc.mu.Unlock()
+	return nil
+}
+
+// GetOutputRouting returns this channel's last SetOutputRouting assignment,
+// or the zero value (mainMixer bus 0, current route) if it was never called.
+func (bc *BaseChannel) GetOutputRouting() OutputRoutingOptions {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.outputRouting
+}
+
+// ensureAggregateOutput creates (or reuses) a CoreAudio aggregate device for
+// e.outputRoutes when they span more than one distinct physical device, and
+// records its UID in e.aggregateDeviceUID so Destroy can tear it down. Engines
+// with a single output route never pay for an aggregate device - the common
+// case of one interface stays exactly as fast as before multi-output routing
+// existed.
+//
+// Called only from Dispatcher.startEngine on the dispatcher thread, while
+// Engine.Start holds e.mu for the whole operation round trip, so it accesses
+// e's fields directly rather than re-locking (which would deadlock against
+// Start's own lock).
+func (e *Engine) ensureAggregateOutput() error {
+	if e.aggregateDeviceUID != "" {
+		return nil // already created for this route set
+	}
+
+	distinct := make(map[string]bool)
+	for _, route := range e.outputRoutes {
+		distinct[route.DeviceUID] = true
+	}
+	if len(distinct) < 2 {
+		return nil
+	}
+
+	uids := make([]string, 0, len(distinct))
+	for uid := range distinct {
+		uids = append(uids, uid)
+	}
+
+	aggregateUID, err := devices.CreateAggregateDevice(e.name+" Aggregate", uids)
+	if err != nil {
+		return fmt.Errorf("failed to create aggregate device for multi-output routing: %w", err)
+	}
+
+	e.aggregateDeviceUID = aggregateUID
+	return nil
+}
@@ -0,0 +1,101 @@
+package macaudio
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/devices"
+)
+
+// TestMultiOutputRouting mirrors TestEngineCreation: it builds an engine
+// with two output routes sharing a common sample rate and checks channels
+// can be pointed at either one via RouteChannelTo.
+func TestMultiOutputRouting(t *testing.T) {
+	restore := useFakeAudioBackend(t, []devices.AudioDevice{
+		{Device: devices.Device{Name: "Interface A", UID: "fake-a", IsOnline: true}, OutputChannelCount: 2, SupportedSampleRates: []int{44100, 48000}},
+		{Device: devices.Device{Name: "Interface B", UID: "fake-b", IsOnline: true}, OutputChannelCount: 2, SupportedSampleRates: []int{48000, 96000}},
+	})
+	defer restore()
+
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{SampleRate: 48000, BufferSize: 256},
+		Outputs: []OutputRoute{
+			{Name: "primary", DeviceUID: "fake-a", Role: RolePrimary},
+			{Name: "monitor", DeviceUID: "fake-b", Role: RoleMonitor},
+		},
+		ErrorHandler: &DefaultErrorHandler{},
+	}
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if eng.outputDeviceUID != "fake-a" {
+		t.Errorf("OutputDeviceUID = %q, want %q (primary route's device)", eng.outputDeviceUID, "fake-a")
+	}
+
+	playbackConfig := PlaybackConfig{FilePath: "/nonexistent/file.wav", LoopEnabled: false, AutoStart: false}
+	channel, err := eng.CreatePlaybackChannel("test_route", playbackConfig)
+	if err != nil {
+		t.Fatalf("Failed to create playback channel: %v", err)
+	}
+
+	if got := eng.GetChannelRoute(channel.GetIDString()); got != "primary" {
+		t.Errorf("default channel route = %q, want %q", got, "primary")
+	}
+
+	if err := eng.RouteChannelTo(channel.GetIDString(), "monitor"); err != nil {
+		t.Fatalf("RouteChannelTo failed: %v", err)
+	}
+	if got := eng.GetChannelRoute(channel.GetIDString()); got != "monitor" {
+		t.Errorf("channel route after RouteChannelTo = %q, want %q", got, "monitor")
+	}
+
+	if err := eng.RouteChannelTo(channel.GetIDString(), "nonexistent-route"); err == nil {
+		t.Error("Expected error routing to a nonexistent route, got nil")
+	}
+}
+
+// TestOutputRouteHotUnplugDegrades checks that losing a sub-device's route
+// falls back any channel assigned to it onto the primary route instead of
+// leaving the engine in a broken state.
+func TestOutputRouteHotUnplugDegrades(t *testing.T) {
+	restore := useFakeAudioBackend(t, []devices.AudioDevice{
+		{Device: devices.Device{Name: "Interface A", UID: "fake-a", IsOnline: true}, OutputChannelCount: 2, SupportedSampleRates: []int{48000}},
+		{Device: devices.Device{Name: "Interface B", UID: "fake-b", IsOnline: true}, OutputChannelCount: 2, SupportedSampleRates: []int{48000}},
+	})
+	defer restore()
+
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{SampleRate: 48000, BufferSize: 256},
+		Outputs: []OutputRoute{
+			{Name: "primary", DeviceUID: "fake-a", Role: RolePrimary},
+			{Name: "monitor", DeviceUID: "fake-b", Role: RoleMonitor},
+		},
+		ErrorHandler: &DefaultErrorHandler{},
+	}
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	playbackConfig := PlaybackConfig{FilePath: "/nonexistent/file.wav", LoopEnabled: false, AutoStart: false}
+	channel, err := eng.CreatePlaybackChannel("test_degrade", playbackConfig)
+	if err != nil {
+		t.Fatalf("Failed to create playback channel: %v", err)
+	}
+	if err := eng.RouteChannelTo(channel.GetIDString(), "monitor"); err != nil {
+		t.Fatalf("RouteChannelTo failed: %v", err)
+	}
+
+	eng.degradeOutputRoute("fake-b")
+
+	if got := eng.GetChannelRoute(channel.GetIDString()); got != "primary" {
+		t.Errorf("channel route after degrade = %q, want fallback to %q", got, "primary")
+	}
+	if len(eng.outputRoutes) != 1 || eng.outputRoutes[0].Name != "primary" {
+		t.Errorf("outputRoutes after degrade = %+v, want only the primary route left", eng.outputRoutes)
+	}
+}
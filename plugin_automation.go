@@ -0,0 +1,347 @@
+package macaudio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CurveKind shapes how an AutomationLane interpolates between the Point it
+// leads to and the one before it.
+type CurveKind string
+
+const (
+	CurveLinear CurveKind = "linear" // constant rate of change
+	CurveCosine CurveKind = "cosine" // eases in and out, the classic automation ramp
+	CurveBezier CurveKind = "bezier" // smoothstep ease, steeper in the middle than cosine
+	CurveStep   CurveKind = "step"   // holds the prior point's value until this one's TimeSec, then jumps
+)
+
+// AutomationPoint is one keyframe in an AutomationLane. Curve shapes the
+// segment leading up to this point from the previous one; it's ignored on
+// a lane's first point, which has nothing before it to interpolate from.
+type AutomationPoint struct {
+	TimeSec float64   `json:"timeSec"`
+	Value   float32   `json:"value"`
+	Curve   CurveKind `json:"curve,omitempty"`
+}
+
+// AutomationLane drives one plugin parameter over time from a sequence of
+// Points. A lane named BypassParameterName drives the instance's bypass
+// state instead of a parameter - see AutomationScheduler.
+type AutomationLane struct {
+	ParamName string            `json:"paramName"`
+	Points    []AutomationPoint `json:"points"`
+}
+
+// BypassParameterName is the sentinel AutomationLane.ParamName that
+// sequences PluginInstance bypass rather than a parameter: the lane's
+// Points are evaluated the same way, but the resulting value is a boolean
+// gate (>=0.5 is bypassed) instead of a continuous parameter value, so
+// such a lane should stick to CurveStep.
+const BypassParameterName = "__bypass__"
+
+// sortPoints sorts an AutomationLane's Points by TimeSec in place, so
+// evaluate can binary-search-free walk them in order. Duplicate TimeSec
+// values keep their relative order (stable), the later one winning ties in
+// evaluate.
+func sortPoints(points []AutomationPoint) {
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].TimeSec < points[j].TimeSec
+	})
+}
+
+// evaluate returns the lane's value at t, holding the first point's value
+// before it and the last point's value after it. points must already be
+// sorted by TimeSec (see sortPoints). Returns false if points is empty.
+func evaluate(points []AutomationPoint, t float64) (float32, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	if t <= points[0].TimeSec {
+		return points[0].Value, true
+	}
+	last := points[len(points)-1]
+	if t >= last.TimeSec {
+		return last.Value, true
+	}
+
+	for i := 1; i < len(points); i++ {
+		if t > points[i].TimeSec {
+			continue
+		}
+		prev, cur := points[i-1], points[i]
+		span := cur.TimeSec - prev.TimeSec
+		if span <= 0 {
+			return cur.Value, true
+		}
+		frac := (t - prev.TimeSec) / span
+
+		switch cur.Curve {
+		case CurveStep:
+			return prev.Value, true
+		case CurveCosine:
+			frac = (1 - math.Cos(frac*math.Pi)) / 2
+		case CurveBezier:
+			frac = frac * frac * (3 - 2*frac) // smoothstep
+		case CurveLinear, "":
+			// frac unchanged
+		}
+
+		return prev.Value + float32(frac)*(cur.Value-prev.Value), true
+	}
+
+	return last.Value, true
+}
+
+// SetAutomationLane adds lane to the instance, replacing any existing lane
+// for the same ParamName. Its Points are sorted by TimeSec first, so
+// AutomationScheduler and GetAutomationLanes always see them in time order
+// regardless of the order the caller built them in.
+func (pi *PluginInstance) SetAutomationLane(lane AutomationLane) error {
+	if lane.ParamName == "" {
+		return fmt.Errorf("automation lane must name a parameter (or BypassParameterName)")
+	}
+	sortPoints(lane.Points)
+
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if pi.lanes == nil {
+		pi.lanes = make(map[string]AutomationLane)
+	}
+	pi.lanes[lane.ParamName] = lane
+	return nil
+}
+
+// RemoveAutomationLane removes the lane for paramName, if one exists.
+func (pi *PluginInstance) RemoveAutomationLane(paramName string) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	delete(pi.lanes, paramName)
+}
+
+// GetAutomationLanes returns a copy of every automation lane currently set
+// on the instance.
+func (pi *PluginInstance) GetAutomationLanes() []AutomationLane {
+	pi.mu.RLock()
+	defer pi.mu.RUnlock()
+	lanes := make([]AutomationLane, 0, len(pi.lanes))
+	for _, lane := range pi.lanes {
+		lanes = append(lanes, lane)
+	}
+	return lanes
+}
+
+// PluginPreset is a named snapshot of a PluginInstance's parameters and
+// automation lanes, saved via SavePreset and restored via LoadPreset.
+type PluginPreset struct {
+	Name       string             `json:"name"`
+	Parameters map[string]float32 `json:"parameters"`
+	Lanes      []AutomationLane   `json:"lanes,omitempty"`
+}
+
+// PresetBank is the named-preset collection carried inside a
+// PluginInstanceState (and so, via PluginChainState.Instances, inside a
+// whole chain's saved state), keyed by PluginPreset.Name so SavePreset/
+// LoadPreset can address one directly instead of scanning a slice.
+type PresetBank map[string]PluginPreset
+
+// SavePreset snapshots the instance's current Parameters and automation
+// lanes under name, overwriting any existing preset with that name.
+func (pi *PluginInstance) SavePreset(name string) error {
+	if name == "" {
+		return fmt.Errorf("preset name cannot be empty")
+	}
+
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+
+	params := make(map[string]float32, len(pi.Parameters))
+	for k, v := range pi.Parameters {
+		params[k] = v
+	}
+	var lanes []AutomationLane
+	if len(pi.lanes) > 0 {
+		lanes = make([]AutomationLane, 0, len(pi.lanes))
+		for _, lane := range pi.lanes {
+			lanes = append(lanes, lane)
+		}
+	}
+
+	if pi.presets == nil {
+		pi.presets = make(PresetBank)
+	}
+	pi.presets[name] = PluginPreset{Name: name, Parameters: params, Lanes: lanes}
+	return nil
+}
+
+// LoadPreset restores the instance's parameters and automation lanes from
+// the preset saved under name, applying each parameter through
+// SetParameter so listeners/the sandboxed host (if any) see the change the
+// same way a live SetParameter call would.
+func (pi *PluginInstance) LoadPreset(name string) error {
+	pi.mu.RLock()
+	preset, ok := pi.presets[name]
+	pi.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("preset %q not found", name)
+	}
+
+	for paramName, value := range preset.Parameters {
+		if err := pi.SetParameter(paramName, value); err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+	}
+
+	lanes := make(map[string]AutomationLane, len(preset.Lanes))
+	for _, lane := range preset.Lanes {
+		lanes[lane.ParamName] = lane
+	}
+	pi.mu.Lock()
+	pi.lanes = lanes
+	pi.mu.Unlock()
+	return nil
+}
+
+// ListPresets returns the names of every preset saved on the instance.
+func (pi *PluginInstance) ListPresets() []string {
+	pi.mu.RLock()
+	defer pi.mu.RUnlock()
+	names := make([]string, 0, len(pi.presets))
+	for name := range pi.presets {
+		names = append(names, name)
+	}
+	return names
+}
+
+// DeletePreset removes the preset saved under name, if one exists.
+func (pi *PluginInstance) DeletePreset(name string) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	delete(pi.presets, name)
+}
+
+// AutomationScheduler advances an instance's automation lanes forward on a
+// fixed tick, calling SetParameter (or PluginChain.SetBypass, for the
+// BypassParameterName lane) once per tick with each lane's interpolated
+// value at the scheduler's playhead. Tick timing is derived from the
+// owning chain's AudioFormat (sampleRate/bufferSize), approximating the
+// cadence real per-audio-block automation would run at - see
+// NewAutomationScheduler. This isn't sample-accurate: there is no
+// render-notify-tap binding in this tree for a tick to hang off instead
+// (see Dispatcher.OnRender's doc comment), so a time.Ticker is the closest
+// approximation available without one.
+type AutomationScheduler struct {
+	instance *PluginInstance
+	interval time.Duration
+
+	mu       sync.Mutex
+	running  bool
+	playhead float64
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewAutomationScheduler creates a scheduler for instance, deriving its
+// tick interval from instance's owning chain's AudioFormat. Returns an
+// error if instance has no chain, or the chain's AudioFormat hasn't been
+// set yet (see PluginChain.setHostSpec, called by NewBaseChannel once the
+// engine exists) - there's no tick interval to derive before then.
+func NewAutomationScheduler(instance *PluginInstance) (*AutomationScheduler, error) {
+	if instance == nil {
+		return nil, fmt.Errorf("instance cannot be nil")
+	}
+	if instance.chain == nil {
+		return nil, fmt.Errorf("instance has no owning chain to derive audio-block timing from")
+	}
+	sampleRate, bufferSize := instance.chain.AudioFormat()
+	if sampleRate <= 0 || bufferSize <= 0 {
+		return nil, fmt.Errorf("chain has no audio format set yet")
+	}
+
+	interval := time.Duration(float64(bufferSize) / sampleRate * float64(time.Second))
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	return &AutomationScheduler{instance: instance, interval: interval}, nil
+}
+
+// Start begins ticking the scheduler forward from playhead 0. Returns an
+// error if already running.
+func (s *AutomationScheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return fmt.Errorf("automation scheduler already running")
+	}
+	s.running = true
+	s.playhead = 0
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+
+	go s.run(s.stopCh, s.doneCh)
+	return nil
+}
+
+// Stop halts the scheduler and waits for its tick goroutine to exit. Safe
+// to call when not running.
+func (s *AutomationScheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	stopCh, doneCh := s.stopCh, s.doneCh
+	s.mu.Unlock()
+
+	close(stopCh)
+	<-doneCh
+}
+
+// Playhead returns the scheduler's current position, in seconds since
+// Start.
+func (s *AutomationScheduler) Playhead() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playhead
+}
+
+func (s *AutomationScheduler) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.playhead += s.interval.Seconds()
+			playhead := s.playhead
+			s.mu.Unlock()
+			s.tick(playhead)
+		}
+	}
+}
+
+func (s *AutomationScheduler) tick(playhead float64) {
+	for _, lane := range s.instance.GetAutomationLanes() {
+		value, ok := evaluate(lane.Points, playhead)
+		if !ok {
+			continue
+		}
+
+		if lane.ParamName == BypassParameterName {
+			chain := s.instance.chain
+			if chain != nil {
+				_ = chain.SetBypass(s.instance.ID, value >= 0.5)
+			}
+			continue
+		}
+		_ = s.instance.SetParameter(lane.ParamName, value)
+	}
+}
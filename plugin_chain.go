@@ -2,39 +2,128 @@ package macaudio
 
 import (
 	"fmt"
+	"strings"
 	"sync"
-	
+
+	"github.com/shaban/macaudio/pluginhost"
 	"github.com/shaban/macaudio/plugins"
 )
 
+// PluginHostMode selects whether a PluginInstance's AudioUnit runs
+// in-process or in a sandboxed child process (see pluginhost package). The
+// zero value is HostModeInProcess, so existing blueprints without a
+// HostMode keep today's behavior.
+type PluginHostMode string
+
+const (
+	// HostModeInProcess loads the plugin directly in the engine process,
+	// as PluginInstance.Load has always done.
+	HostModeInProcess PluginHostMode = ""
+	// HostModeSandboxed loads the plugin in a child process reached over
+	// pluginhost.Host, trading a small amount of latency and IPC overhead
+	// for crash isolation: a misbehaving third-party AU takes down its own
+	// process instead of the engine.
+	HostModeSandboxed PluginHostMode = "sandboxed"
+)
+
 // PluginBlueprint represents a plugin template that can be instantiated
 type PluginBlueprint struct {
-	Type           string  `json:"type"`
-	Subtype        string  `json:"subtype"`
-	ManufacturerID string  `json:"manufacturerID"`
-	Name           string  `json:"name"`
-	IsInstalled    bool    `json:"isInstalled"`
+	Type           string         `json:"type"`
+	Subtype        string         `json:"subtype"`
+	ManufacturerID string         `json:"manufacturerID"`
+	Name           string         `json:"name"`
+	IsInstalled    bool           `json:"isInstalled"`
+	HostMode       PluginHostMode `json:"hostMode,omitempty"`
+	// SandboxCommand is the path to a helper binary built around
+	// pluginhost.ServeChild, started when HostMode is HostModeSandboxed.
+	// Ignored otherwise. This tree doesn't ship such a binary yet (see
+	// pluginhost's package doc), so a HostModeSandboxed instance's Load
+	// fails until a caller provides one.
+	SandboxCommand string `json:"sandboxCommand,omitempty"`
 }
 
 // PluginInstance represents an instantiated plugin in a chain
 type PluginInstance struct {
-	ID         string           `json:"id"`
-	Blueprint  PluginBlueprint  `json:"blueprint"`
-	Position   int              `json:"position"`
-	IsActive   bool             `json:"isActive"`
-	IsLoaded   bool             `json:"isLoaded"`
+	ID         string             `json:"id"`
+	Blueprint  PluginBlueprint    `json:"blueprint"`
+	Position   int                `json:"position"`
+	IsActive   bool               `json:"isActive"`
+	IsLoaded   bool               `json:"isLoaded"`
 	Parameters map[string]float32 `json:"parameters"`
-	
+	// SidechainSource is the GetIDString of the AuxChannel designated as
+	// this plugin's sidechain key input, or "" if none. Set via
+	// SetSidechainSource, usually by AuxChannel.AddSidechainTarget.
+	SidechainSource string `json:"sidechainSource,omitempty"`
+
 	// Internal state
-	mu         sync.RWMutex
-	plugin     *plugins.Plugin  // Full plugin data when loaded
+	mu           sync.RWMutex
+	plugin       *plugins.Plugin  // Full plugin data when loaded
+	chain        *PluginChain     // owning chain, for SetParameter's listener fan-out
+	midiBindings []MidiBinding    // MIDI-learn bindings, mirroring BaseChannel.midiBindings
+	host         *pluginhost.Host // set instead of plugin when Blueprint.HostMode is HostModeSandboxed
+
+	// lanes holds this instance's automation, keyed by AutomationLane.
+	// ParamName (see SetAutomationLane); presets holds its named snapshots
+	// (see SavePreset/LoadPreset). Both are nil until first used, same as
+	// Parameters starts non-nil but these don't need to.
+	lanes     map[string]AutomationLane
+	presets   PresetBank
+	scheduler *AutomationScheduler
+
+	// classInfo is the AudioUnit's opaque state blob, captured/restored by
+	// GetClassInfo/SetClassInfo - see those for why this tree can't query
+	// or push it into a loaded AU yet.
+	classInfo []byte
+}
+
+// Introspector resolves a PluginBlueprint into full plugin metadata for
+// PluginInstance.Load, normally by introspecting the real installed
+// AudioUnit (plugins.PluginInfo.Introspect). SetIntrospector substitutes a
+// fake one, so a test harness (see the plugintest package) can drive a
+// PluginChain without any AudioUnit installed at all.
+type Introspector interface {
+	Introspect(blueprint PluginBlueprint) (*plugins.Plugin, error)
+}
+
+// ChainListener receives notifications when a PluginChain's effect list or a
+// member PluginInstance's parameters/bypass state change, so the OSC
+// surface, UI, undo/redo stack, and state persistence layer can react to
+// mutations instead of polling GetState. Register one with
+// PluginChain.AddListener; it fires synchronously, after the change has
+// already taken effect. The same listener can be attached to many channels'
+// chains at once - every callback is handed channelID so it knows which one
+// fired.
+type ChainListener interface {
+	OnEffectAdded(channelID string, instance *PluginInstance)
+	OnEffectRemoved(channelID, instanceID string)
+	OnEffectMoved(channelID, instanceID string, position int)
+	OnParamChanged(channelID, instanceID, name string, value float32)
+	OnBypassChanged(channelID, instanceID string, bypassed bool)
 }
 
 // PluginChain manages a sequence of audio plugins for a channel
 type PluginChain struct {
 	mu        sync.RWMutex
+	channelID string // set by NewBaseChannel via setChannelID, for listener callbacks
 	instances []*PluginInstance
 	nextID    int
+	listeners []ChainListener
+
+	// sampleRate/bufferSize describe the engine's audio format, set by
+	// NewBaseChannel via setHostSpec. Only consumed by a HostModeSandboxed
+	// PluginInstance's Load, to fill out pluginhost.HandshakeRequest; an
+	// in-process instance has never needed this.
+	sampleRate float64
+	bufferSize int
+
+	// introspector overrides how this chain's instances resolve plugin
+	// metadata on Load, set via SetIntrospector. nil (the default) means
+	// introspect the real installed AudioUnit, same as before Introspector
+	// existed. Meant to be set once, before any plugin is added - see
+	// SetIntrospector's doc comment - so Load reads it without pc.mu: Load
+	// itself runs inside AddPlugin's pc.mu.Lock() and RWMutex isn't
+	// reentrant.
+	introspector Introspector
 }
 
 // PluginChainState represents the serializable state of a plugin chain
@@ -44,11 +133,23 @@ type PluginChainState struct {
 
 // PluginInstanceState represents the serializable state of a plugin instance
 type PluginInstanceState struct {
-	ID         string              `json:"id"`
-	Blueprint  PluginBlueprint     `json:"blueprint"`
-	Position   int                 `json:"position"`
-	IsActive   bool                `json:"isActive"`
-	Parameters map[string]float32  `json:"parameters"`
+	ID              string             `json:"id"`
+	Blueprint       PluginBlueprint    `json:"blueprint"`
+	Position        int                `json:"position"`
+	IsActive        bool               `json:"isActive"`
+	Parameters      map[string]float32 `json:"parameters"`
+	SidechainSource string             `json:"sidechainSource,omitempty"`
+	MidiBindings    []MidiBinding      `json:"midiBindings,omitempty"`
+	// Lanes and Presets are additive: a client that predates
+	// AutomationLane/PresetBank (see plugin_automation.go) sees them as
+	// absent fields and keeps working off Parameters exactly as before.
+	Lanes   []AutomationLane `json:"lanes,omitempty"`
+	Presets PresetBank       `json:"presets,omitempty"`
+	// ClassInfo is the instance's captured AudioUnit state blob, if any -
+	// see PluginInstance.GetClassInfo. encoding/json renders a []byte as a
+	// base64 string automatically, so this needs no extra marshaling to
+	// round-trip through PluginChainPreset (see plugin_chain_preset.go).
+	ClassInfo []byte `json:"classInfo,omitempty"`
 }
 
 // NewPluginChain creates a new empty plugin chain
@@ -62,13 +163,13 @@ func NewPluginChain() *PluginChain {
 // AddPlugin adds a plugin instance to the chain at the specified position
 func (pc *PluginChain) AddPlugin(blueprint PluginBlueprint, position int) (*PluginInstance, error) {
 	pc.mu.Lock()
-	defer pc.mu.Unlock()
-	
+
 	// Validate position
 	if position < 0 || position > len(pc.instances) {
+		pc.mu.Unlock()
 		return nil, fmt.Errorf("invalid position %d for plugin chain", position)
 	}
-	
+
 	// Create new instance
 	instance := &PluginInstance{
 		ID:         fmt.Sprintf("plugin_%d", pc.nextID),
@@ -77,9 +178,10 @@ func (pc *PluginChain) AddPlugin(blueprint PluginBlueprint, position int) (*Plug
 		IsActive:   true,
 		IsLoaded:   false,
 		Parameters: make(map[string]float32),
+		chain:      pc,
 	}
 	pc.nextID++
-	
+
 	// Insert at position
 	if position == len(pc.instances) {
 		// Append to end
@@ -90,54 +192,233 @@ func (pc *PluginChain) AddPlugin(blueprint PluginBlueprint, position int) (*Plug
 		copy(pc.instances[position+1:], pc.instances[position:])
 		pc.instances[position] = instance
 	}
-	
+
 	// Update positions of subsequent plugins
 	for i := position + 1; i < len(pc.instances); i++ {
 		pc.instances[i].Position = i
 	}
-	
+
 	// Try to load the plugin
 	if err := instance.Load(); err != nil {
 		// Plugin loading failed, but we still add it to the chain as inactive
 		instance.IsActive = false
 		instance.Blueprint.IsInstalled = false
 	}
-	
+	pc.mu.Unlock()
+
+	pc.notifyEffectAdded(instance)
 	return instance, nil
 }
 
 // RemovePlugin removes a plugin instance from the chain
 func (pc *PluginChain) RemovePlugin(instanceID string) error {
 	pc.mu.Lock()
-	defer pc.mu.Unlock()
-	
+
 	for i, instance := range pc.instances {
 		if instance.ID == instanceID {
 			// Unload plugin before removing
 			instance.Unload()
-			
+
 			// Remove from slice
 			copy(pc.instances[i:], pc.instances[i+1:])
 			pc.instances[len(pc.instances)-1] = nil
 			pc.instances = pc.instances[:len(pc.instances)-1]
-			
+
 			// Update positions of subsequent plugins
 			for j := i; j < len(pc.instances); j++ {
 				pc.instances[j].Position = j
 			}
-			
+			pc.mu.Unlock()
+
+			pc.notifyEffectRemoved(instanceID)
 			return nil
 		}
 	}
-	
+	pc.mu.Unlock()
+
 	return fmt.Errorf("plugin instance %s not found", instanceID)
 }
 
+// MovePlugin relocates instanceID to newPosition within the chain, shifting
+// every instance between the old and new position, and fans out
+// OnEffectMoved. The counterpart to avaudio/pluginchain's MoveEffect,
+// addressed by instance ID instead of array index since this chain's
+// instances are addressed by PluginInstance.ID everywhere else (see
+// AddPlugin/RemovePlugin).
+func (pc *PluginChain) MovePlugin(instanceID string, newPosition int) error {
+	pc.mu.Lock()
+
+	oldIndex := -1
+	for i, instance := range pc.instances {
+		if instance.ID == instanceID {
+			oldIndex = i
+			break
+		}
+	}
+	if oldIndex == -1 {
+		pc.mu.Unlock()
+		return fmt.Errorf("plugin instance %s not found", instanceID)
+	}
+	if newPosition < 0 || newPosition >= len(pc.instances) {
+		pc.mu.Unlock()
+		return fmt.Errorf("invalid position %d for plugin chain", newPosition)
+	}
+
+	instance := pc.instances[oldIndex]
+	pc.instances = append(pc.instances[:oldIndex], pc.instances[oldIndex+1:]...)
+	pc.instances = append(pc.instances, nil)
+	copy(pc.instances[newPosition+1:], pc.instances[newPosition:])
+	pc.instances[newPosition] = instance
+
+	for i, inst := range pc.instances {
+		inst.Position = i
+	}
+	pc.mu.Unlock()
+
+	pc.notifyEffectMoved(instanceID, newPosition)
+	return nil
+}
+
+// SetBypass toggles instanceID's bypass state, stored as the inverse of its
+// IsActive flag, and fans out OnBypassChanged. Used by
+// Dispatcher.setPluginBypass so plugin bypass mutation and its listener
+// notification live next to the rest of PluginChain's mutating methods
+// instead of reaching into PluginInstance fields from outside the package.
+func (pc *PluginChain) SetBypass(instanceID string, bypassed bool) error {
+	pc.mu.RLock()
+	var instance *PluginInstance
+	for _, inst := range pc.instances {
+		if inst.ID == instanceID {
+			instance = inst
+			break
+		}
+	}
+	pc.mu.RUnlock()
+	if instance == nil {
+		return fmt.Errorf("plugin instance %s not found", instanceID)
+	}
+
+	instance.mu.Lock()
+	instance.IsActive = !bypassed
+	instance.mu.Unlock()
+
+	pc.notifyBypassChanged(instanceID, bypassed)
+	return nil
+}
+
+// AddListener registers l to be notified of every structural or parameter
+// change to this chain (see ChainListener).
+func (pc *PluginChain) AddListener(l ChainListener) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.listeners = append(pc.listeners, l)
+}
+
+// RemoveListener unregisters l, previously passed to AddListener.
+func (pc *PluginChain) RemoveListener(l ChainListener) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	for i, existing := range pc.listeners {
+		if existing == l {
+			pc.listeners = append(pc.listeners[:i], pc.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// setChannelID records the owning channel's ID for listener callbacks.
+// Called once by NewBaseChannel; unexported since a chain's owning channel
+// never changes after construction.
+func (pc *PluginChain) setChannelID(channelID string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.channelID = channelID
+}
+
+// setHostSpec records the engine's audio format, for a later
+// HostModeSandboxed PluginInstance.Load to hand to pluginhost.Start as a
+// HandshakeRequest. Called once by NewBaseChannel, alongside setChannelID.
+func (pc *PluginChain) setHostSpec(sampleRate float64, bufferSize int) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.sampleRate = sampleRate
+	pc.bufferSize = bufferSize
+}
+
+// AudioFormat returns the sample rate and buffer size set by setHostSpec -
+// the same values a HostModeSandboxed instance's handshake uses - for
+// external callers that need to match a chain's audio format without going
+// through the owning Engine (e.g. plugintest.RunBuffers).
+func (pc *PluginChain) AudioFormat() (sampleRate float64, bufferSize int) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	return pc.sampleRate, pc.bufferSize
+}
+
+// SetIntrospector overrides how this chain's instances resolve plugin
+// metadata on Load (see Introspector). Call it before AddPlugin/
+// LoadWithResolver add any instance - once one exists, Load may already be
+// racing a concurrent SetIntrospector with no lock between them, by design
+// (see the introspector field's doc comment). Passing nil restores the
+// default real-AudioUnit introspection.
+func (pc *PluginChain) SetIntrospector(in Introspector) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.introspector = in
+}
+
+// snapshotListeners returns a copy of this chain's registered listeners and
+// its owning channel ID, so fan-out can run without holding pc.mu (a
+// listener calling back into a Get* method on this chain would otherwise
+// deadlock).
+func (pc *PluginChain) snapshotListeners() (string, []ChainListener) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	listeners := make([]ChainListener, len(pc.listeners))
+	copy(listeners, pc.listeners)
+	return pc.channelID, listeners
+}
+
+func (pc *PluginChain) notifyEffectAdded(instance *PluginInstance) {
+	channelID, listeners := pc.snapshotListeners()
+	for _, l := range listeners {
+		l.OnEffectAdded(channelID, instance)
+	}
+}
+
+func (pc *PluginChain) notifyEffectRemoved(instanceID string) {
+	channelID, listeners := pc.snapshotListeners()
+	for _, l := range listeners {
+		l.OnEffectRemoved(channelID, instanceID)
+	}
+}
+
+func (pc *PluginChain) notifyEffectMoved(instanceID string, position int) {
+	channelID, listeners := pc.snapshotListeners()
+	for _, l := range listeners {
+		l.OnEffectMoved(channelID, instanceID, position)
+	}
+}
+
+func (pc *PluginChain) notifyBypassChanged(instanceID string, bypassed bool) {
+	channelID, listeners := pc.snapshotListeners()
+	for _, l := range listeners {
+		l.OnBypassChanged(channelID, instanceID, bypassed)
+	}
+}
+
+func (pc *PluginChain) notifyParamChanged(instanceID, name string, value float32) {
+	channelID, listeners := pc.snapshotListeners()
+	for _, l := range listeners {
+		l.OnParamChanged(channelID, instanceID, name, value)
+	}
+}
+
 // GetInstances returns a copy of all plugin instances
 func (pc *PluginChain) GetInstances() []*PluginInstance {
 	pc.mu.RLock()
 	defer pc.mu.RUnlock()
-	
+
 	instances := make([]*PluginInstance, len(pc.instances))
 	copy(instances, pc.instances)
 	return instances
@@ -147,7 +428,7 @@ func (pc *PluginChain) GetInstances() []*PluginInstance {
 func (pc *PluginChain) GetInstance(instanceID string) (*PluginInstance, bool) {
 	pc.mu.RLock()
 	defer pc.mu.RUnlock()
-	
+
 	for _, instance := range pc.instances {
 		if instance.ID == instanceID {
 			return instance, true
@@ -160,100 +441,357 @@ func (pc *PluginChain) GetInstance(instanceID string) (*PluginInstance, bool) {
 func (pc *PluginChain) GetState() PluginChainState {
 	pc.mu.RLock()
 	defer pc.mu.RUnlock()
-	
+
 	states := make([]PluginInstanceState, len(pc.instances))
 	for i, instance := range pc.instances {
 		states[i] = instance.GetState()
 	}
-	
+
 	return PluginChainState{
 		Instances: states,
 	}
 }
 
-// SetState restores the plugin chain from serializable state
+// PartialRestoreError is returned by PluginChain.SetState (and so also by
+// BaseChannel.SetState, which delegates to it) when every field restored
+// successfully except one or more plugin instances that failed to load -
+// typically because the plugin isn't installed on this machine. The chain
+// still ends up in a valid, fully-restored state otherwise: the failed
+// instances are kept in place with IsActive false rather than dropped, so a
+// later Load (once the plugin is installed) can still pick up their saved
+// Parameters.
+type PartialRestoreError struct {
+	// Missing lists the blueprint name of every instance that failed to
+	// load, in chain order.
+	Missing []string
+}
+
+func (e *PartialRestoreError) Error() string {
+	return fmt.Sprintf("plugin chain restored with %d missing plugin(s): %s", len(e.Missing), strings.Join(e.Missing, ", "))
+}
+
+// SetState restores the plugin chain from serializable state. It always
+// restores every instance's bookkeeping (parameters, automation lanes,
+// presets, MIDI bindings) regardless of whether the underlying plugin could
+// be loaded; if one or more couldn't, it returns a *PartialRestoreError
+// listing them rather than failing the whole restore.
 func (pc *PluginChain) SetState(state PluginChainState) error {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
-	
+
 	// Clear existing instances
 	for _, instance := range pc.instances {
 		instance.Unload()
 	}
 	pc.instances = make([]*PluginInstance, 0, len(state.Instances))
-	
+
+	var missing []string
+
 	// Restore instances from state
 	for _, instanceState := range state.Instances {
 		instance := &PluginInstance{
-			ID:         instanceState.ID,
-			Blueprint:  instanceState.Blueprint,
-			Position:   instanceState.Position,
-			IsActive:   instanceState.IsActive,
-			IsLoaded:   false,
-			Parameters: make(map[string]float32),
+			ID:              instanceState.ID,
+			Blueprint:       instanceState.Blueprint,
+			Position:        instanceState.Position,
+			IsActive:        instanceState.IsActive,
+			IsLoaded:        false,
+			Parameters:      make(map[string]float32),
+			SidechainSource: instanceState.SidechainSource,
+			chain:           pc,
 		}
-		
+
 		// Copy parameters
 		for key, value := range instanceState.Parameters {
 			instance.Parameters[key] = value
 		}
-		
+
+		instance.midiBindings = make([]MidiBinding, len(instanceState.MidiBindings))
+		copy(instance.midiBindings, instanceState.MidiBindings)
+
+		if len(instanceState.Lanes) > 0 {
+			instance.lanes = make(map[string]AutomationLane, len(instanceState.Lanes))
+			for _, lane := range instanceState.Lanes {
+				instance.lanes[lane.ParamName] = lane
+			}
+		}
+		if len(instanceState.Presets) > 0 {
+			instance.presets = make(PresetBank, len(instanceState.Presets))
+			for name, preset := range instanceState.Presets {
+				instance.presets[name] = preset
+			}
+		}
+		if instanceState.ClassInfo != nil {
+			instance.classInfo = make([]byte, len(instanceState.ClassInfo))
+			copy(instance.classInfo, instanceState.ClassInfo)
+		}
+
 		pc.instances = append(pc.instances, instance)
-		
+
 		// Try to load the plugin
 		if instance.IsActive {
 			if err := instance.Load(); err != nil {
 				// Plugin loading failed
 				instance.IsActive = false
 				instance.Blueprint.IsInstalled = false
+				missing = append(missing, instanceState.Blueprint.Name)
 			}
 		}
 	}
-	
+
+	if len(missing) > 0 {
+		return &PartialRestoreError{Missing: missing}
+	}
 	return nil
 }
 
-// Load attempts to load the plugin from the system
+// PluginResolver resolves a plugin blueprint into loadable plugin data - the
+// pluggable counterpart to PluginInstance.Load's hardcoded
+// plugins.PluginInfo.Introspect call, so LoadWithResolver can reconstruct a
+// chain against a plugin inventory that isn't "whatever Introspect finds on
+// this machine" (a test double, or a different machine's catalog sent ahead
+// of the session itself).
+type PluginResolver interface {
+	Resolve(info plugins.PluginInfo) (*plugins.Plugin, error)
+}
+
+// IntrospectingPluginResolver is the default PluginResolver, resolving
+// exactly the way PluginInstance.Load already does: real AudioUnit
+// introspection on this machine.
+type IntrospectingPluginResolver struct{}
+
+func (IntrospectingPluginResolver) Resolve(info plugins.PluginInfo) (*plugins.Plugin, error) {
+	return info.Introspect()
+}
+
+// MissingPluginPolicy controls how LoadWithResolver handles an instance
+// whose PluginResolver.Resolve call fails - e.g. the Audio Unit it names
+// isn't installed on this machine.
+type MissingPluginPolicy string
+
+const (
+	// MissingPluginSkip drops the instance from the chain entirely.
+	MissingPluginSkip MissingPluginPolicy = "skip"
+	// MissingPluginSubstituteBypass keeps the instance in the chain, at its
+	// original position, but marked inactive/not-installed instead of
+	// loaded - the same outcome SetState's plain Introspect-based restore
+	// already falls back to on a failed Load. Also LoadWithResolver's
+	// default for any policy value other than Skip/Fail.
+	MissingPluginSubstituteBypass MissingPluginPolicy = "substitute_bypass"
+	// MissingPluginFail aborts the whole LoadWithResolver call.
+	MissingPluginFail MissingPluginPolicy = "fail"
+)
+
+// LoadWithResolver replaces this chain's instances with the ones described
+// by state, resolving each active instance's plugin data through resolver
+// instead of PluginInstance.Load's hardcoded introspection, and handling a
+// failed resolve per policy. Unlike SetState, every instance that ends up in
+// the chain - including one MissingPluginSubstituteBypass fell back on -
+// fires OnEffectAdded, since this is reconstructing a chain from scratch on
+// what may be a different machine, not a structural edit to an
+// already-coherent one.
+func (pc *PluginChain) LoadWithResolver(state PluginChainState, resolver PluginResolver, policy MissingPluginPolicy) error {
+	pc.mu.Lock()
+
+	for _, instance := range pc.instances {
+		instance.Unload()
+	}
+	pc.instances = make([]*PluginInstance, 0, len(state.Instances))
+
+	var built []*PluginInstance
+	for _, instanceState := range state.Instances {
+		instance := &PluginInstance{
+			ID:              instanceState.ID,
+			Blueprint:       instanceState.Blueprint,
+			Position:        instanceState.Position,
+			IsActive:        instanceState.IsActive,
+			IsLoaded:        false,
+			Parameters:      make(map[string]float32),
+			SidechainSource: instanceState.SidechainSource,
+			chain:           pc,
+		}
+		for key, value := range instanceState.Parameters {
+			instance.Parameters[key] = value
+		}
+		instance.midiBindings = make([]MidiBinding, len(instanceState.MidiBindings))
+		copy(instance.midiBindings, instanceState.MidiBindings)
+
+		if len(instanceState.Lanes) > 0 {
+			instance.lanes = make(map[string]AutomationLane, len(instanceState.Lanes))
+			for _, lane := range instanceState.Lanes {
+				instance.lanes[lane.ParamName] = lane
+			}
+		}
+		if len(instanceState.Presets) > 0 {
+			instance.presets = make(PresetBank, len(instanceState.Presets))
+			for name, preset := range instanceState.Presets {
+				instance.presets[name] = preset
+			}
+		}
+		if instanceState.ClassInfo != nil {
+			instance.classInfo = make([]byte, len(instanceState.ClassInfo))
+			copy(instance.classInfo, instanceState.ClassInfo)
+		}
+
+		if instance.IsActive {
+			info := plugins.PluginInfo{
+				Name:           instance.Blueprint.Name,
+				ManufacturerID: instance.Blueprint.ManufacturerID,
+				Type:           instance.Blueprint.Type,
+				Subtype:        instance.Blueprint.Subtype,
+			}
+			plugin, err := resolver.Resolve(info)
+			if err != nil {
+				switch policy {
+				case MissingPluginSkip:
+					continue
+				case MissingPluginFail:
+					pc.mu.Unlock()
+					return fmt.Errorf("resolve plugin %s for instance %s: %w", instance.Blueprint.Name, instance.ID, err)
+				default: // MissingPluginSubstituteBypass, or any unrecognized policy
+					instance.IsActive = false
+					instance.Blueprint.IsInstalled = false
+				}
+			} else {
+				instance.plugin = plugin
+				instance.IsLoaded = true
+				instance.Blueprint.IsInstalled = true
+			}
+		}
+
+		pc.instances = append(pc.instances, instance)
+		built = append(built, instance)
+	}
+	pc.mu.Unlock()
+
+	for _, instance := range built {
+		pc.notifyEffectAdded(instance)
+	}
+	return nil
+}
+
+// Load attempts to load the plugin from the system. When Blueprint.HostMode
+// is HostModeSandboxed, it instead spawns Blueprint.SandboxCommand as a
+// child process and loads the plugin there - see loadSandboxed.
 func (pi *PluginInstance) Load() error {
+	if pi.Blueprint.HostMode == HostModeSandboxed {
+		return pi.loadSandboxed()
+	}
+
 	pi.mu.Lock()
 	defer pi.mu.Unlock()
-	
+
 	if pi.IsLoaded {
 		return nil // Already loaded
 	}
-	
-	// Create PluginInfo from blueprint
-	info := plugins.PluginInfo{
-		Name:           pi.Blueprint.Name,
-		ManufacturerID: pi.Blueprint.ManufacturerID,
-		Type:           pi.Blueprint.Type,
-		Subtype:        pi.Blueprint.Subtype,
+
+	var plugin *plugins.Plugin
+	var err error
+	if pi.chain != nil && pi.chain.introspector != nil {
+		plugin, err = pi.chain.introspector.Introspect(pi.Blueprint)
+	} else {
+		info := plugins.PluginInfo{
+			Name:           pi.Blueprint.Name,
+			ManufacturerID: pi.Blueprint.ManufacturerID,
+			Type:           pi.Blueprint.Type,
+			Subtype:        pi.Blueprint.Subtype,
+		}
+		plugin, err = info.Introspect()
 	}
-	
-	// Introspect to get full plugin data
-	plugin, err := info.Introspect()
 	if err != nil {
 		return fmt.Errorf("failed to introspect plugin: %w", err)
 	}
-	
+
 	pi.plugin = plugin
 	pi.IsLoaded = true
 	pi.Blueprint.IsInstalled = true
-	
+
+	return nil
+}
+
+// loadSandboxed starts Blueprint.SandboxCommand as a child process over
+// pluginhost.Start, negotiating the owning chain's audio format, then asks
+// the child to load this instance's plugin. A health-check failure later
+// (see pluginhost.Host's onCrash) marks IsActive false and fans out
+// OnBypassChanged, the same listener path SetBypass uses, instead of
+// tearing down the chain or the engine.
+func (pi *PluginInstance) loadSandboxed() error {
+	pi.mu.Lock()
+	if pi.IsLoaded {
+		pi.mu.Unlock()
+		return nil
+	}
+	if pi.Blueprint.SandboxCommand == "" {
+		pi.mu.Unlock()
+		return fmt.Errorf("plugin %s: HostModeSandboxed requires Blueprint.SandboxCommand", pi.ID)
+	}
+	chain := pi.chain
+	pi.mu.Unlock()
+
+	spec := pluginhost.HandshakeRequest{Channels: 2, Format: "float32"}
+	if chain != nil {
+		// Read directly, without chain.mu: AddPlugin calls Load while
+		// already holding chain.mu.Lock(), and sampleRate/bufferSize are
+		// set once by setHostSpec before the chain has any instances (so
+		// before Load can ever be reached) and never change after, so
+		// there's nothing for a lock to protect against here.
+		spec.SampleRate = chain.sampleRate
+		spec.BufferSize = chain.bufferSize
+	}
+
+	instanceID := pi.ID
+	host, err := pluginhost.Start(pi.Blueprint.SandboxCommand, nil, spec, func() {
+		pi.mu.Lock()
+		pi.IsActive = false
+		pi.mu.Unlock()
+		if chain != nil {
+			chain.notifyBypassChanged(instanceID, true)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start sandboxed plugin host: %w", err)
+	}
+
+	if err := host.Load(pluginhost.PluginDescriptor{
+		Type:           pi.Blueprint.Type,
+		Subtype:        pi.Blueprint.Subtype,
+		ManufacturerID: pi.Blueprint.ManufacturerID,
+		Name:           pi.Blueprint.Name,
+	}); err != nil {
+		host.Close()
+		return fmt.Errorf("sandboxed plugin failed to load %s: %w", pi.Blueprint.Name, err)
+	}
+
+	pi.mu.Lock()
+	pi.host = host
+	pi.IsLoaded = true
+	pi.Blueprint.IsInstalled = true
+	pi.mu.Unlock()
+
 	return nil
 }
 
 // Unload unloads the plugin and releases resources
 func (pi *PluginInstance) Unload() {
 	pi.mu.Lock()
-	defer pi.mu.Unlock()
-	
+
 	if !pi.IsLoaded {
+		pi.mu.Unlock()
 		return
 	}
-	
+
+	if pi.host != nil {
+		pi.host.Close()
+		pi.host = nil
+	}
+	scheduler := pi.scheduler
+	pi.scheduler = nil
 	pi.plugin = nil
 	pi.IsLoaded = false
+	pi.mu.Unlock()
+
+	if scheduler != nil {
+		scheduler.Stop()
+	}
 }
 
 // GetPlugin returns the loaded plugin data (thread-safe)
@@ -266,16 +804,29 @@ func (pi *PluginInstance) GetPlugin() *plugins.Plugin {
 // SetParameter sets a plugin parameter value
 func (pi *PluginInstance) SetParameter(name string, value float32) error {
 	pi.mu.Lock()
-	defer pi.mu.Unlock()
-	
 	if !pi.IsLoaded {
+		pi.mu.Unlock()
 		return fmt.Errorf("plugin not loaded")
 	}
-	
+	host := pi.host
+
 	// TODO: Validate parameter exists and range
 	// TODO: Apply to actual plugin instance
-	
+
 	pi.Parameters[name] = value
+	chain := pi.chain
+	id := pi.ID
+	pi.mu.Unlock()
+
+	if host != nil {
+		if err := host.SetParameter(name, value); err != nil {
+			return fmt.Errorf("sandboxed plugin: %w", err)
+		}
+	}
+
+	if chain != nil {
+		chain.notifyParamChanged(id, name, value)
+	}
 	return nil
 }
 
@@ -283,27 +834,168 @@ func (pi *PluginInstance) SetParameter(name string, value float32) error {
 func (pi *PluginInstance) GetParameter(name string) (float32, bool) {
 	pi.mu.RLock()
 	defer pi.mu.RUnlock()
-	
+
 	value, exists := pi.Parameters[name]
 	return value, exists
 }
 
+// GetClassInfo returns the AudioUnit state blob most recently captured by
+// SetClassInfo, or nil if none has been set. This mirrors
+// kAudioUnitProperty_ClassInfo's CFPropertyList, the opaque dictionary an
+// AudioUnit uses to persist state its exposed Parameters don't cover (a
+// convolution IR, a sample-slot selection) - but the cgo call that would
+// actually query it from a loaded AU doesn't exist in this tree yet (the
+// same native gap as render_callback.go/oscillator.go), so this only ever
+// returns whatever bytes a previous SetClassInfo call - typically
+// LoadPreset restoring a saved PluginInstanceState.ClassInfo - was given.
+func (pi *PluginInstance) GetClassInfo() []byte {
+	pi.mu.RLock()
+	defer pi.mu.RUnlock()
+	if pi.classInfo == nil {
+		return nil
+	}
+	out := make([]byte, len(pi.classInfo))
+	copy(out, pi.classInfo)
+	return out
+}
+
+// SetClassInfo records data as the instance's AudioUnit state blob, for
+// GetState/SavePreset to carry forward. Like GetClassInfo, the cgo call
+// that would push this into a loaded AU (kAudioUnitProperty_ClassInfo's
+// setter) doesn't exist in this tree yet, so it has no effect on an
+// already-loaded plugin's audible behavior - it only updates what this
+// instance serializes from here on.
+func (pi *PluginInstance) SetClassInfo(data []byte) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if data == nil {
+		pi.classInfo = nil
+		return
+	}
+	pi.classInfo = make([]byte, len(data))
+	copy(pi.classInfo, data)
+}
+
 // GetState returns the serializable state of the plugin instance
 func (pi *PluginInstance) GetState() PluginInstanceState {
 	pi.mu.RLock()
 	defer pi.mu.RUnlock()
-	
+
 	// Copy parameters map
 	params := make(map[string]float32)
 	for k, v := range pi.Parameters {
 		params[k] = v
 	}
-	
+
+	midiBindings := make([]MidiBinding, len(pi.midiBindings))
+	copy(midiBindings, pi.midiBindings)
+
+	var lanes []AutomationLane
+	if len(pi.lanes) > 0 {
+		lanes = make([]AutomationLane, 0, len(pi.lanes))
+		for _, lane := range pi.lanes {
+			lanes = append(lanes, lane)
+		}
+	}
+
+	var presets PresetBank
+	if len(pi.presets) > 0 {
+		presets = make(PresetBank, len(pi.presets))
+		for name, preset := range pi.presets {
+			presets[name] = preset
+		}
+	}
+
+	var classInfo []byte
+	if pi.classInfo != nil {
+		classInfo = make([]byte, len(pi.classInfo))
+		copy(classInfo, pi.classInfo)
+	}
+
 	return PluginInstanceState{
-		ID:         pi.ID,
-		Blueprint:  pi.Blueprint,
-		Position:   pi.Position,
-		IsActive:   pi.IsActive,
-		Parameters: params,
+		ID:              pi.ID,
+		Blueprint:       pi.Blueprint,
+		Position:        pi.Position,
+		IsActive:        pi.IsActive,
+		Parameters:      params,
+		SidechainSource: pi.SidechainSource,
+		MidiBindings:    midiBindings,
+		Lanes:           lanes,
+		Presets:         presets,
+		ClassInfo:       classInfo,
+	}
+}
+
+// GetMidiBindings returns a copy of this instance's MIDI-learn bindings.
+func (pi *PluginInstance) GetMidiBindings() []MidiBinding {
+	pi.mu.RLock()
+	defer pi.mu.RUnlock()
+	bindings := make([]MidiBinding, len(pi.midiBindings))
+	copy(bindings, pi.midiBindings)
+	return bindings
+}
+
+// AddMidiBinding adds or replaces the MIDI-learn binding for b.Parameter.
+func (pi *PluginInstance) AddMidiBinding(b MidiBinding) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	for i, existing := range pi.midiBindings {
+		if existing.Parameter == b.Parameter {
+			pi.midiBindings[i] = b
+			return
+		}
 	}
+	pi.midiBindings = append(pi.midiBindings, b)
+}
+
+// ClearMidiBindings removes the MIDI-learn binding for parameter, or every
+// binding on this instance if parameter is "".
+func (pi *PluginInstance) ClearMidiBindings(parameter string) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if parameter == "" {
+		pi.midiBindings = nil
+		return
+	}
+	kept := pi.midiBindings[:0]
+	for _, b := range pi.midiBindings {
+		if b.Parameter != parameter {
+			kept = append(kept, b)
+		}
+	}
+	pi.midiBindings = kept
+}
+
+// SetSidechainSource designates auxID (an AuxChannel's GetIDString) as this
+// plugin's sidechain key input. PluginInstance has no native AudioUnit node
+// yet (see Load/SetParameter) so this records the intent for GetState to
+// round-trip but can't wire an actual AU sidechain bus; plugins that
+// support a key input just see silence on it until that lands.
+func (pi *PluginInstance) SetSidechainSource(auxID string) error {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+
+	if !pi.IsLoaded {
+		return fmt.Errorf("plugin not loaded")
+	}
+
+	// TODO: Wire to the AudioUnit's auxiliary/sidechain input bus once
+	// PluginInstance hosts a real AVAudioUnitEffect node.
+	pi.SidechainSource = auxID
+	return nil
+}
+
+// ClearSidechainSource removes this plugin's sidechain key input, if any.
+func (pi *PluginInstance) ClearSidechainSource() {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	pi.SidechainSource = ""
+}
+
+// GetSidechainSource returns the AuxChannel ID designated as this plugin's
+// sidechain key input, or "" if none.
+func (pi *PluginInstance) GetSidechainSource() string {
+	pi.mu.RLock()
+	defer pi.mu.RUnlock()
+	return pi.SidechainSource
 }
@@ -0,0 +1,93 @@
+package macaudio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// PresetVersion is the current PluginChainPreset format SavePreset writes
+// and LoadPreset accepts by default. Bump it (and teach LoadPreset to
+// handle the old shape) the day a field needs to change meaning rather
+// than just gain a new omitempty one - PluginInstanceState's own fields
+// already extend losslessly that way (see its doc comment).
+const PresetVersion = 1
+
+// PluginChainPreset is the on-disk shape SavePreset/LoadPreset read and
+// write: a PluginChainState - chain order, each instance's blueprint,
+// parameters, and (via PluginInstanceState.ClassInfo) captured AudioUnit
+// state - wrapped with a version so a future format change can still tell
+// an old preset file apart from a new one.
+type PluginChainPreset struct {
+	Version int `json:"version"`
+	PluginChainState
+}
+
+// SavePreset writes pc's current chain to path as JSON, in the
+// PluginChainPreset format. Unlike GetState/SetState, which round-trip a
+// chain's live state within a running session, SavePreset/LoadPreset
+// target a file meant to outlive the session - moved between projects or
+// machines the same way a DAW persists an FX chain preset.
+func (pc *PluginChain) SavePreset(path string) error {
+	preset := PluginChainPreset{
+		Version:          PresetVersion,
+		PluginChainState: pc.GetState(),
+	}
+
+	data, err := json.MarshalIndent(preset, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal plugin chain preset: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write plugin chain preset %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadPreset reads a preset written by SavePreset from path and restores it
+// into pc via LoadWithResolver, resolving each plugin through resolver -
+// pass IntrospectingPluginResolver{} for real AudioUnit introspection on
+// this machine, or a test double (see the plugintest package) to restore
+// against fakes. policy controls what happens when a saved plugin can't be
+// resolved - e.g. a preset saved on a machine with a plugin this one
+// doesn't have installed: pass MissingPluginFail for a strict load that
+// refuses a preset it can't reproduce exactly, or
+// MissingPluginSkip/MissingPluginSubstituteBypass for a lenient one that
+// keeps going without it.
+func (pc *PluginChain) LoadPreset(path string, resolver PluginResolver, policy MissingPluginPolicy) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read plugin chain preset %s: %w", path, err)
+	}
+
+	var preset PluginChainPreset
+	if err := json.Unmarshal(data, &preset); err != nil {
+		return fmt.Errorf("unmarshal plugin chain preset %s: %w", path, err)
+	}
+	if preset.Version > PresetVersion {
+		return fmt.Errorf("plugin chain preset %s: version %d is newer than this build supports (%d)", path, preset.Version, PresetVersion)
+	}
+
+	return pc.LoadWithResolver(preset.PluginChainState, resolver, policy)
+}
+
+// MarshalJSON implements json.Marshaler by encoding pc.GetState(), so a
+// *PluginChain can be passed directly to json.Marshal - embedded in a
+// larger document, say - instead of always going through GetState by
+// hand first.
+func (pc *PluginChain) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pc.GetState())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the mirror of MarshalJSON: it
+// decodes into a PluginChainState and calls SetState. Like SetState (and
+// unlike LoadPreset), it always resolves plugins via real AudioUnit
+// introspection with no missing-plugin policy; use LoadPreset instead when
+// that matters.
+func (pc *PluginChain) UnmarshalJSON(data []byte) error {
+	var state PluginChainState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	return pc.SetState(state)
+}
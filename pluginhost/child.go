@@ -0,0 +1,104 @@
+package pluginhost
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"os"
+)
+
+// PluginLoader is the child process's real plugin-hosting backend.
+// ServeChild is transport-only; it has no opinion on how a plugin is
+// actually loaded, so a standalone helper binary supplies a PluginLoader
+// backed by whatever native AU hosting it has available (e.g. an
+// avaudio/unit.Effect wrapped to satisfy this interface) and passes it to
+// ServeChild.
+type PluginLoader interface {
+	// Handshake validates the requested audio format and returns an error
+	// if this loader can't honor it.
+	Handshake(req HandshakeRequest) error
+	Load(d PluginDescriptor) error
+	SetParameter(name string, value float32) error
+	GetParameter(name string) (value float32, found bool)
+	Close() error
+}
+
+// ChildService is the net/rpc receiver registered by ServeChild. Its
+// methods are the child side of Host's calls in pluginhost.go.
+type ChildService struct {
+	loader PluginLoader
+}
+
+func (c *ChildService) Handshake(req *HandshakeRequest, resp *HandshakeResponse) error {
+	if err := c.loader.Handshake(*req); err != nil {
+		resp.OK = false
+		resp.Err = err.Error()
+		return nil
+	}
+	resp.OK = true
+	return nil
+}
+
+func (c *ChildService) Load(d *PluginDescriptor, ack *bool) error {
+	if err := c.loader.Load(*d); err != nil {
+		return err
+	}
+	*ack = true
+	return nil
+}
+
+func (c *ChildService) SetParameter(args *SetParameterArgs, ack *bool) error {
+	if err := c.loader.SetParameter(args.Name, args.Value); err != nil {
+		return err
+	}
+	*ack = true
+	return nil
+}
+
+func (c *ChildService) GetParameter(args *GetParameterArgs, reply *GetParameterReply) error {
+	value, found := c.loader.GetParameter(args.Name)
+	reply.Value, reply.Found = value, found
+	return nil
+}
+
+func (c *ChildService) Ping(_ *struct{}, ack *bool) error {
+	*ack = true
+	return nil
+}
+
+func (c *ChildService) Close(_ *struct{}, ack *bool) error {
+	*ack = true
+	return c.loader.Close()
+}
+
+// ServeChild registers loader as a ChildService and serves RPC requests on
+// socketPath (a Unix socket, removed first if left over from a previous
+// run) until the parent calls Close or the process is killed. Intended to
+// be the entire body of a standalone plugin-host binary's main:
+//
+//	func main() {
+//	    loader := myAUHostingLoader{}
+//	    if err := pluginhost.ServeChild(os.Args[len(os.Args)-1], loader); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+func ServeChild(socketPath string, loader PluginLoader) error {
+	if loader == nil {
+		return errors.New("pluginhost: ServeChild requires a non-nil PluginLoader")
+	}
+	os.Remove(socketPath)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("ChildService", &ChildService{loader: loader}); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	server.Accept(listener)
+	return nil
+}
@@ -0,0 +1,304 @@
+// Package pluginhost runs an Audio Unit in a child helper process instead
+// of in-process, for crash isolation: a third-party AU that segfaults or
+// hangs takes down its own process instead of the host engine. Host is the
+// parent-side proxy a macaudio.PluginInstance holds when its
+// PluginBlueprint.HostMode is HostModeSandboxed; ServeChild is the
+// child-side entry point a small standalone binary would call to host one
+// plugin and answer the parent's RPCs.
+//
+// The control plane here (handshake, parameter get/set, health checks) is
+// plain net/rpc over a Unix socket and needs no native code. The audio
+// itself - the part that actually has to move in real time - is meant to
+// cross via the shared-memory ring in ring.go, which does need a native
+// shim this tree doesn't have yet; see ring.go's doc comment.
+package pluginhost
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// PluginDescriptor identifies the plugin a Host should load, mirroring the
+// fields of macaudio.PluginBlueprint that matter to the child process.
+// Defined locally instead of importing macaudio's PluginBlueprint directly
+// to avoid an import cycle (macaudio's plugin_chain.go is what constructs a
+// Host).
+type PluginDescriptor struct {
+	Type           string
+	Subtype        string
+	ManufacturerID string
+	Name           string
+}
+
+// HandshakeRequest describes the audio format the parent's engine runs at,
+// sent to the child as the first RPC after connecting so both sides agree
+// on sample rate, channel count, and sample format before any parameter
+// traffic or (once ring.go's native side exists) audio flows.
+type HandshakeRequest struct {
+	SampleRate float64
+	Channels   int
+	Format     string // e.g. "float32"
+	BufferSize int
+}
+
+// HandshakeResponse is the child's reply to HandshakeRequest. OK is false
+// when the child can't honor the requested format; Err then explains why.
+type HandshakeResponse struct {
+	OK  bool
+	Err string
+}
+
+// SetParameterArgs/GetParameterArgs are the net/rpc argument and reply
+// types for ChildService.SetParameter/GetParameter.
+type SetParameterArgs struct {
+	Name  string
+	Value float32
+}
+
+type GetParameterArgs struct {
+	Name string
+}
+
+type GetParameterReply struct {
+	Value float32
+	Found bool
+}
+
+// pingTimeout bounds a single health-check round trip; healthInterval is
+// the gap between checks. Three missed pings in a row (see Host.healthLoop)
+// is treated as a crash.
+const (
+	pingTimeout    = 2 * time.Second
+	healthInterval = 5 * time.Second
+	missedLimit    = 3
+)
+
+// Host is the parent-side proxy for one sandboxed plugin: it owns the
+// child process, the RPC connection to it, and a background health check
+// that calls OnCrash without tearing down anything else once the child
+// stops answering.
+type Host struct {
+	cmd        *exec.Cmd
+	client     *rpc.Client
+	socketPath string
+
+	mu       sync.Mutex
+	closed   bool
+	crashed  bool
+	onCrash  func()
+	stopPing chan struct{}
+}
+
+// Start spawns command (with args) as a child process, dials it over a
+// Unix socket, and performs the HandshakeRequest. The child is expected to
+// call ServeChild(socketPath, ...) with socketPath passed as its last
+// argument, appended here. onCrash, if non-nil, is called at most once from
+// a background goroutine if the health check determines the child died or
+// stopped responding; it should mark the owning PluginInstance IsActive =
+// false and must not block.
+func Start(command string, args []string, spec HandshakeRequest, onCrash func()) (*Host, error) {
+	socketPath, err := socketPathFor(command)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(command, append(args, socketPath)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("pluginhost: failed to start %s: %w", command, err)
+	}
+
+	client, err := dialWithRetry(socketPath, 2*time.Second)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return nil, fmt.Errorf("pluginhost: failed to connect to %s: %w", command, err)
+	}
+
+	var resp HandshakeResponse
+	if err := client.Call("ChildService.Handshake", &spec, &resp); err != nil {
+		client.Close()
+		_ = cmd.Process.Kill()
+		return nil, fmt.Errorf("pluginhost: handshake RPC failed: %w", err)
+	}
+	if !resp.OK {
+		client.Close()
+		_ = cmd.Process.Kill()
+		return nil, fmt.Errorf("pluginhost: child rejected handshake: %s", resp.Err)
+	}
+
+	h := &Host{
+		cmd:        cmd,
+		client:     client,
+		socketPath: socketPath,
+		onCrash:    onCrash,
+		stopPing:   make(chan struct{}),
+	}
+	go h.healthLoop()
+	return h, nil
+}
+
+func socketPathFor(command string) (string, error) {
+	dir, err := os.MkdirTemp("", "pluginhost-")
+	if err != nil {
+		return "", fmt.Errorf("pluginhost: failed to create socket dir: %w", err)
+	}
+	return dir + "/" + filepathBase(command) + ".sock", nil
+}
+
+func filepathBase(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			return path[i+1:]
+		}
+	}
+	return path
+}
+
+func dialWithRetry(socketPath string, timeout time.Duration) (*rpc.Client, error) {
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("unix", socketPath, 100*time.Millisecond)
+		if err == nil {
+			return rpc.NewClient(conn), nil
+		}
+		lastErr = err
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil, fmt.Errorf("timed out waiting for child to listen on %s: %w", socketPath, lastErr)
+}
+
+// Load asks the child to load the plugin described by d.
+func (h *Host) Load(d PluginDescriptor) error {
+	var ack bool
+	return h.call("ChildService.Load", &d, &ack)
+}
+
+// SetParameter forwards a parameter change to the child's plugin instance.
+func (h *Host) SetParameter(name string, value float32) error {
+	var ack bool
+	return h.call("ChildService.SetParameter", &SetParameterArgs{Name: name, Value: value}, &ack)
+}
+
+// GetParameter reads a parameter value back from the child's plugin
+// instance.
+func (h *Host) GetParameter(name string) (float32, bool, error) {
+	var reply GetParameterReply
+	if err := h.call("ChildService.GetParameter", &GetParameterArgs{Name: name}, &reply); err != nil {
+		return 0, false, err
+	}
+	return reply.Value, reply.Found, nil
+}
+
+// IsHealthy reports whether the last health check succeeded and Close
+// hasn't been called.
+func (h *Host) IsHealthy() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !h.closed && !h.crashed
+}
+
+// Close stops the health check, tells the child to unload, and tears down
+// the connection and process. Safe to call more than once.
+func (h *Host) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	close(h.stopPing)
+
+	var ack bool
+	_ = h.client.Call("ChildService.Close", &struct{}{}, &ack)
+	h.client.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- h.cmd.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		_ = h.cmd.Process.Kill()
+		<-done
+	}
+	os.RemoveAll(socketDir(h.socketPath))
+	return nil
+}
+
+func socketDir(socketPath string) string {
+	for i := len(socketPath) - 1; i >= 0; i-- {
+		if socketPath[i] == '/' {
+			return socketPath[:i]
+		}
+	}
+	return socketPath
+}
+
+func (h *Host) call(method string, args, reply interface{}) error {
+	h.mu.Lock()
+	if h.closed || h.crashed {
+		h.mu.Unlock()
+		return errors.New("pluginhost: host is closed or crashed")
+	}
+	h.mu.Unlock()
+	return h.client.Call(method, args, reply)
+}
+
+// healthLoop pings the child every healthInterval and, after missedLimit
+// consecutive failures, marks this Host crashed and calls onCrash exactly
+// once. It never kills the parent engine - only the caller's onCrash
+// callback decides what the owning PluginInstance does in response.
+func (h *Host) healthLoop() {
+	var missed int
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stopPing:
+			return
+		case <-ticker.C:
+			h.mu.Lock()
+			closed := h.closed
+			h.mu.Unlock()
+			if closed {
+				return
+			}
+
+			var ack bool
+			done := make(chan error, 1)
+			go func() { done <- h.client.Call("ChildService.Ping", &struct{}{}, &ack) }()
+
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(pingTimeout):
+				err = errors.New("ping timed out")
+			}
+
+			if err != nil {
+				missed++
+				if missed >= missedLimit {
+					h.mu.Lock()
+					h.crashed = true
+					cb := h.onCrash
+					h.mu.Unlock()
+					if cb != nil {
+						cb()
+					}
+					return
+				}
+				continue
+			}
+			missed = 0
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package pluginhost
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+)
+
+// fakeLoader is a minimal PluginLoader for exercising ChildService/Host's
+// wire protocol without a real AudioUnit or child process.
+type fakeLoader struct {
+	params map[string]float32
+	closed bool
+}
+
+func newFakeLoader() *fakeLoader {
+	return &fakeLoader{params: make(map[string]float32)}
+}
+
+func (f *fakeLoader) Handshake(req HandshakeRequest) error {
+	if req.SampleRate < 0 {
+		return errors.New("negative sample rate")
+	}
+	return nil
+}
+
+func (f *fakeLoader) Load(d PluginDescriptor) error {
+	if d.Name == "" {
+		return errors.New("empty plugin name")
+	}
+	return nil
+}
+
+func (f *fakeLoader) SetParameter(name string, value float32) error {
+	f.params[name] = value
+	return nil
+}
+
+func (f *fakeLoader) GetParameter(name string) (float32, bool) {
+	v, ok := f.params[name]
+	return v, ok
+}
+
+func (f *fakeLoader) Close() error {
+	f.closed = true
+	return nil
+}
+
+// serveOnSocket starts ServeChild on a temp Unix socket in the background
+// and returns the socket path plus a client already dialed against it.
+func serveOnSocket(t *testing.T, loader PluginLoader) (string, *rpc.Client) {
+	t.Helper()
+	socketPath := t.TempDir() + "/pluginhost-test.sock"
+
+	go func() {
+		if err := ServeChild(socketPath, loader); err != nil {
+			t.Logf("ServeChild exited: %v", err)
+		}
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("unix", socketPath, 50*time.Millisecond)
+		if err == nil {
+			return socketPath, rpc.NewClient(conn)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for ServeChild to listen on %s", socketPath)
+	return "", nil
+}
+
+func TestChildServiceRPCWire(t *testing.T) {
+	loader := newFakeLoader()
+	_, client := serveOnSocket(t, loader)
+	defer client.Close()
+
+	var hsResp HandshakeResponse
+	if err := client.Call("ChildService.Handshake", &HandshakeRequest{SampleRate: 48000, Channels: 2, Format: "float32", BufferSize: 512}, &hsResp); err != nil {
+		t.Fatalf("Handshake RPC failed: %v", err)
+	}
+	if !hsResp.OK {
+		t.Fatalf("expected handshake OK, got Err=%q", hsResp.Err)
+	}
+
+	var loadAck bool
+	if err := client.Call("ChildService.Load", &PluginDescriptor{Name: "Test Plugin", Type: "aufx"}, &loadAck); err != nil {
+		t.Fatalf("Load RPC failed: %v", err)
+	}
+	if !loadAck {
+		t.Fatal("expected Load to ack")
+	}
+
+	var setAck bool
+	if err := client.Call("ChildService.SetParameter", &SetParameterArgs{Name: "gain", Value: 0.5}, &setAck); err != nil {
+		t.Fatalf("SetParameter RPC failed: %v", err)
+	}
+
+	var getReply GetParameterReply
+	if err := client.Call("ChildService.GetParameter", &GetParameterArgs{Name: "gain"}, &getReply); err != nil {
+		t.Fatalf("GetParameter RPC failed: %v", err)
+	}
+	if !getReply.Found || getReply.Value != 0.5 {
+		t.Fatalf("expected gain=0.5 found=true, got %+v", getReply)
+	}
+
+	var pingAck bool
+	if err := client.Call("ChildService.Ping", &struct{}{}, &pingAck); err != nil {
+		t.Fatalf("Ping RPC failed: %v", err)
+	}
+	if !pingAck {
+		t.Fatal("expected Ping to ack")
+	}
+
+	var closeAck bool
+	if err := client.Call("ChildService.Close", &struct{}{}, &closeAck); err != nil {
+		t.Fatalf("Close RPC failed: %v", err)
+	}
+	if !loader.closed {
+		t.Fatal("expected loader.Close to have been called")
+	}
+}
+
+func TestChildServiceLoadRejectsEmptyName(t *testing.T) {
+	loader := newFakeLoader()
+	_, client := serveOnSocket(t, loader)
+	defer client.Close()
+
+	var ack bool
+	err := client.Call("ChildService.Load", &PluginDescriptor{}, &ack)
+	if err == nil {
+		t.Fatal("expected Load to reject an empty plugin name")
+	}
+}
+
+func TestServeChildRejectsNilLoader(t *testing.T) {
+	if err := ServeChild(t.TempDir()+"/unused.sock", nil); err == nil {
+		t.Fatal("expected ServeChild to reject a nil loader")
+	}
+}
@@ -0,0 +1,51 @@
+package pluginhost
+
+import "errors"
+
+// AudioRing is meant to carry audio buffers between a Host and its child
+// process in real time: a POSIX shared-memory region sized to one
+// HandshakeRequest.BufferSize block per channel, with an eventfd (Linux) or
+// kqueue (Darwin) used to notify the other side a block is ready, avoiding
+// the latency and allocation cost of sending buffers over the net/rpc
+// socket used for control messages. None of that native shared-memory/
+// notify plumbing exists in this tree yet - there's no native/ shim for it,
+// the way avaudio/midi/output.go's portmidi output calls don't exist
+// either - so NewAudioRing below wires the Go-side contract a real
+// implementation would fill in, and returns an error rather than silently
+// no-op'ing. Until it lands, a sandboxed PluginInstance's audio is not
+// actually processed by the child; only the control plane (handshake,
+// parameter get/set, health check) in pluginhost.go/child.go is live.
+type AudioRing struct {
+	path      string
+	channels  int
+	blockSize int
+}
+
+// ErrAudioRingUnimplemented is returned by NewAudioRing until a native
+// shared-memory/notify backend exists for this platform.
+var ErrAudioRingUnimplemented = errors.New("pluginhost: shared-memory audio ring has no native backend in this tree yet")
+
+// NewAudioRing would open or create the shared-memory region at path sized
+// for channels of blockSize float32 frames each. It always returns
+// ErrAudioRingUnimplemented today; see the package-level doc comment above.
+func NewAudioRing(path string, channels, blockSize int) (*AudioRing, error) {
+	return nil, ErrAudioRingUnimplemented
+}
+
+// Write would publish one block of audio per channel and signal the notify
+// primitive. Unimplemented - see NewAudioRing.
+func (r *AudioRing) Write(blocks [][]float32) error {
+	return ErrAudioRingUnimplemented
+}
+
+// Read would block until the other side signals a block is ready and
+// return it. Unimplemented - see NewAudioRing.
+func (r *AudioRing) Read() ([][]float32, error) {
+	return nil, ErrAudioRingUnimplemented
+}
+
+// Close would release the shared-memory region and notify primitive.
+// Unimplemented - see NewAudioRing.
+func (r *AudioRing) Close() error {
+	return ErrAudioRingUnimplemented
+}
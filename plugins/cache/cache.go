@@ -0,0 +1,191 @@
+//go:build darwin
+
+// Package cache provides a persistent, on-disk cache for AudioUnit plugin
+// introspection results, keyed by a fingerprint of the plugin's bundle so a
+// cached entry goes stale automatically the moment the underlying plugin
+// changes. See FileCache for the default implementation of plugins.Cache.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// DefaultDirName is the cache subdirectory under the user's cache
+// directory (e.g. ~/Library/Caches on macOS) used when no override is
+// requested via NewFileCache.
+const DefaultDirName = "macaudio/plugins"
+
+// blacklistFileName is the JSON sidecar FileCache stores blacklisted
+// entries under, alongside each key's own data file.
+const blacklistFileName = "blacklist.json"
+
+// blacklistEntry records why and when Blacklist was called for a key.
+type blacklistEntry struct {
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// FileCache is the default plugins.Cache, storing each entry as a JSON file
+// named after the Key's fingerprint under dir, plus a shared blacklist.json
+// sidecar recording keys that crashed or timed out introspecting.
+type FileCache struct {
+	dir string
+
+	// mu serializes blacklist.json reads/writes; the per-key data files
+	// under dir have no equivalent need since each key only ever touches
+	// its own file.
+	mu sync.Mutex
+}
+
+// NewFileCache creates a FileCache rooted at the user's cache directory plus
+// DefaultDirName (~/Library/Caches/macaudio/plugins on macOS), or the
+// directory named by the MACAUDIO_PLUGIN_CACHE_DIR environment variable when
+// set. The directory is created if it doesn't exist.
+func NewFileCache() (*FileCache, error) {
+	dir, err := defaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewFileCacheAt(dir)
+}
+
+// NewFileCacheAt creates a FileCache rooted at dir, creating it if necessary.
+func NewFileCacheAt(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create plugin cache directory %s: %w", dir, err)
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func defaultCacheDir() (string, error) {
+	if override := os.Getenv("MACAUDIO_PLUGIN_CACHE_DIR"); override != "" {
+		return override, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory for plugin cache: %w", err)
+	}
+	return filepath.Join(home, "Library", "Caches", DefaultDirName), nil
+}
+
+func (c *FileCache) path(key plugins.Key) string {
+	return filepath.Join(c.dir, fingerprint(key)+".json")
+}
+
+func fingerprint(key plugins.Key) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%s|%s|%d",
+		key.Type, key.Subtype, key.ManufacturerID, key.Name,
+		key.BundlePath, key.Version, key.BundleModTime.UnixNano())))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached plugin for key, if any. A corrupt or unreadable
+// entry is treated the same as a miss rather than returned as an error,
+// since the caller's fallback is always to re-introspect.
+func (c *FileCache) Get(key plugins.Key) (*plugins.Plugin, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var plugin plugins.Plugin
+	if err := json.Unmarshal(data, &plugin); err != nil {
+		return nil, false
+	}
+	return &plugin, true
+}
+
+// Put stores p under key, overwriting any existing entry.
+func (c *FileCache) Put(key plugins.Key, p *plugins.Plugin) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin for cache: %w", err)
+	}
+	if err := os.WriteFile(c.path(key), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write plugin cache entry: %w", err)
+	}
+	return nil
+}
+
+// Invalidate removes the cache entry for key, if any. Removing an entry
+// that doesn't exist is not an error.
+func (c *FileCache) Invalidate(key plugins.Key) error {
+	if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to invalidate plugin cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *FileCache) blacklistPath() string {
+	return filepath.Join(c.dir, blacklistFileName)
+}
+
+// loadBlacklist reads blacklist.json, treating a missing or corrupt file the
+// same as an empty blacklist - the caller's fallback (re-introspect) is
+// always safe.
+func (c *FileCache) loadBlacklist() map[string]blacklistEntry {
+	data, err := os.ReadFile(c.blacklistPath())
+	if err != nil {
+		return map[string]blacklistEntry{}
+	}
+	var m map[string]blacklistEntry
+	if err := json.Unmarshal(data, &m); err != nil {
+		return map[string]blacklistEntry{}
+	}
+	return m
+}
+
+func (c *FileCache) saveBlacklist(m map[string]blacklistEntry) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin cache blacklist: %w", err)
+	}
+	if err := os.WriteFile(c.blacklistPath(), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write plugin cache blacklist: %w", err)
+	}
+	return nil
+}
+
+// Blacklist records key as a plugin whose introspection crashed or timed
+// out, so Prewarm/IntrospectCached skip it on later calls instead of
+// repeating the failure every launch, until Unblacklist clears it.
+func (c *FileCache) Blacklist(key plugins.Key, reason string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.loadBlacklist()
+	m[fingerprint(key)] = blacklistEntry{Reason: reason, At: time.Now()}
+	return c.saveBlacklist(m)
+}
+
+// IsBlacklisted reports whether key was previously recorded via Blacklist
+// and hasn't since been cleared by Unblacklist.
+func (c *FileCache) IsBlacklisted(key plugins.Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.loadBlacklist()[fingerprint(key)]
+	return ok
+}
+
+// Unblacklist clears a previous Blacklist call for key, if any. Clearing a
+// key that isn't blacklisted is not an error.
+func (c *FileCache) Unblacklist(key plugins.Key) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.loadBlacklist()
+	if _, ok := m[fingerprint(key)]; !ok {
+		return nil
+	}
+	delete(m, fingerprint(key))
+	return c.saveBlacklist(m)
+}
+
+var _ plugins.Cache = (*FileCache)(nil)
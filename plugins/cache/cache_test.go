@@ -0,0 +1,134 @@
+//go:build darwin
+
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func testKey() plugins.Key {
+	return plugins.Key{
+		Type:           "aufx",
+		Subtype:        "dely",
+		ManufacturerID: "appl",
+		Name:           "AUDelay",
+		BundlePath:     "/System/Library/Components/AUDelay.component",
+		Version:        "1.0",
+		BundleModTime:  time.Unix(1700000000, 0),
+	}
+}
+
+func TestFileCacheGetPutInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewFileCacheAt(dir)
+	if err != nil {
+		t.Fatalf("NewFileCacheAt failed: %v", err)
+	}
+
+	key := testKey()
+	if _, ok := c.Get(key); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+
+	plugin := &plugins.Plugin{
+		Name:           key.Name,
+		ManufacturerID: key.ManufacturerID,
+		Type:           key.Type,
+		Subtype:        key.Subtype,
+		BundlePath:     key.BundlePath,
+		Version:        key.Version,
+		BundleModTime:  key.BundleModTime,
+		Parameters: []plugins.Parameter{
+			{DisplayName: "Delay Time", Address: 0, MinValue: 0, MaxValue: 1},
+		},
+	}
+	if err := c.Put(key, plugin); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	got, ok := c.Get(key)
+	if !ok {
+		t.Fatal("expected a hit after Put")
+	}
+	if got.Name != plugin.Name || len(got.Parameters) != 1 {
+		t.Errorf("unexpected cached plugin: %+v", got)
+	}
+
+	if err := c.Invalidate(key); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if _, ok := c.Get(key); ok {
+		t.Fatal("expected a miss after Invalidate")
+	}
+
+	if err := c.Invalidate(key); err != nil {
+		t.Fatalf("Invalidate on a missing entry should not error, got: %v", err)
+	}
+}
+
+func TestFileCacheDifferentFingerprintsMiss(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewFileCacheAt(dir)
+	if err != nil {
+		t.Fatalf("NewFileCacheAt failed: %v", err)
+	}
+
+	key := testKey()
+	if err := c.Put(key, &plugins.Plugin{Name: key.Name}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	staleKey := key
+	staleKey.BundleModTime = key.BundleModTime.Add(time.Second)
+	if _, ok := c.Get(staleKey); ok {
+		t.Fatal("expected a miss once the bundle fingerprint changes")
+	}
+}
+
+func TestFileCacheBlacklist(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewFileCacheAt(dir)
+	if err != nil {
+		t.Fatalf("NewFileCacheAt failed: %v", err)
+	}
+
+	key := testKey()
+	if c.IsBlacklisted(key) {
+		t.Fatal("expected a key to start off the blacklist")
+	}
+
+	if err := c.Blacklist(key, "crashed during introspection"); err != nil {
+		t.Fatalf("Blacklist failed: %v", err)
+	}
+	if !c.IsBlacklisted(key) {
+		t.Fatal("expected key to be blacklisted")
+	}
+
+	if err := c.Unblacklist(key); err != nil {
+		t.Fatalf("Unblacklist failed: %v", err)
+	}
+	if c.IsBlacklisted(key) {
+		t.Fatal("expected key to no longer be blacklisted")
+	}
+	if err := c.Unblacklist(key); err != nil {
+		t.Fatalf("Unblacklist on an already-clear key should be a no-op, got: %v", err)
+	}
+}
+
+func TestNewFileCacheRespectsOverrideDir(t *testing.T) {
+	dir := t.TempDir()
+	os.Setenv("MACAUDIO_PLUGIN_CACHE_DIR", dir)
+	defer os.Unsetenv("MACAUDIO_PLUGIN_CACHE_DIR")
+
+	c, err := NewFileCache()
+	if err != nil {
+		t.Fatalf("NewFileCache failed: %v", err)
+	}
+	if c.dir != dir {
+		t.Errorf("expected cache dir %s, got %s", dir, c.dir)
+	}
+}
@@ -0,0 +1,282 @@
+//go:build darwin && cgo
+
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// CatalogEntry is one plugin registered with a Catalog: the introspected
+// Plugin as it looked at Register time, plus an optional hash a caller has
+// pinned via Catalog.Pin. PinnedHash is empty until Pin is called - an
+// unpinned entry is never checksum-verified, matching how an unpinned
+// package behaves in a dependency manager.
+type CatalogEntry struct {
+	Plugin     Plugin `json:"plugin"`
+	PinnedHash string `json:"pinnedHash,omitempty"`
+}
+
+// Catalog is a disk-persisted registry of introspected plugins, keyed by
+// name and version, that a host application builds up deliberately (as
+// opposed to plugins/cache.Cache, which transparently memoizes whatever
+// Introspect happens to be asked for). Registering a version a caller
+// trusts and then Pinning its hash lets Verify catch a bundle silently
+// swapped out from under that version later - the same guard a package
+// manager's lockfile hash provides against a tampered dependency.
+type Catalog struct {
+	path string
+
+	mu sync.Mutex
+	// entries is keyed by name, then by version, so LatestVersion and
+	// ByVersion-style lookups don't need to scan every entry.
+	entries map[string]map[string]*CatalogEntry
+}
+
+// NewCatalog creates an empty Catalog that persists to path on every
+// mutating call. path's parent directory must already exist.
+func NewCatalog(path string) *Catalog {
+	return &Catalog{
+		path:    path,
+		entries: make(map[string]map[string]*CatalogEntry),
+	}
+}
+
+// LoadCatalog reads a Catalog previously persisted by Register/Deregister/
+// Pin at path. A missing file is treated as an empty, new catalog rather
+// than an error, since the first Register call on a fresh path hasn't run
+// yet.
+func LoadCatalog(path string) (*Catalog, error) {
+	c := NewCatalog(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin catalog: %w", err)
+	}
+
+	var entries []*CatalogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal plugin catalog: %w", err)
+	}
+	for _, entry := range entries {
+		c.index(entry)
+	}
+	return c, nil
+}
+
+// index stores entry in c.entries, creating the inner map on first use for
+// entry's name. Caller must hold c.mu.
+func (c *Catalog) index(entry *CatalogEntry) {
+	byVersion, ok := c.entries[entry.Plugin.Name]
+	if !ok {
+		byVersion = make(map[string]*CatalogEntry)
+		c.entries[entry.Plugin.Name] = byVersion
+	}
+	byVersion[entry.Plugin.Version] = entry
+}
+
+// save rewrites c.path with every entry currently in c.entries, sorted by
+// name then version for a stable, diffable file. Caller must hold c.mu.
+func (c *Catalog) save() error {
+	var entries []*CatalogEntry
+	for _, name := range sortedNames(c.entries) {
+		byVersion := c.entries[name]
+		versions := make([]string, 0, len(byVersion))
+		for version := range byVersion {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		for _, version := range versions {
+			entries = append(entries, byVersion[version])
+		}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin catalog: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write plugin catalog: %w", err)
+	}
+	return nil
+}
+
+// sortedNames returns entries' top-level keys (plugin names) in ascending
+// order.
+func sortedNames(entries map[string]map[string]*CatalogEntry) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register adds plugin to the catalog under its Name and Version,
+// overwriting any existing entry for that exact pair, and persists the
+// catalog to disk. Registering over a pinned entry clears the pin - a
+// caller replacing a registered version is assumed to want the new bundle
+// trusted, and should call Pin again explicitly if it wants the swap
+// checksum-verified going forward.
+func (c *Catalog) Register(plugin *Plugin) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.index(&CatalogEntry{Plugin: *plugin})
+	return c.save()
+}
+
+// Deregister removes the entry for name/version, if any, and persists the
+// catalog to disk. Removing an entry that doesn't exist is not an error.
+func (c *Catalog) Deregister(name, version string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byVersion, ok := c.entries[name]
+	if !ok {
+		return nil
+	}
+	delete(byVersion, version)
+	if len(byVersion) == 0 {
+		delete(c.entries, name)
+	}
+	return c.save()
+}
+
+// Get returns the registered plugin for name/version, if any.
+func (c *Catalog) Get(name, version string) (*Plugin, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[name][version]
+	if !ok {
+		return nil, false
+	}
+	plugin := entry.Plugin
+	return &plugin, true
+}
+
+// List returns every plugin currently registered, across all names and
+// versions, in the same stable name-then-version order Save writes them
+// in.
+func (c *Catalog) List() []*Plugin {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var result []*Plugin
+	for _, name := range sortedNames(c.entries) {
+		byVersion := c.entries[name]
+		versions := make([]string, 0, len(byVersion))
+		for version := range byVersion {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		for _, version := range versions {
+			plugin := byVersion[version].Plugin
+			result = append(result, &plugin)
+		}
+	}
+	return result
+}
+
+// LatestVersion returns the highest-semver registered version of name, so
+// a caller can request "whatever I have" without enumerating versions
+// itself. Versions that don't parse as semver (see parseSemver) are
+// ignored; if none of name's registered versions parse, the first
+// registered entry found is returned as a best-effort fallback.
+func (c *Catalog) LatestVersion(name string) (*Plugin, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byVersion, ok := c.entries[name]
+	if !ok || len(byVersion) == 0 {
+		return nil, false
+	}
+
+	var best *Plugin
+	var bestParsed [3]int
+	haveBest := false
+	for version, entry := range byVersion {
+		parsed, ok := parseSemver(version)
+		if !ok {
+			continue
+		}
+		plugin := entry.Plugin
+		if !haveBest || compareSemver(parsed, bestParsed, ">") {
+			best = &plugin
+			bestParsed = parsed
+			haveBest = true
+		}
+	}
+	if haveBest {
+		return best, true
+	}
+
+	for _, entry := range byVersion {
+		plugin := entry.Plugin
+		return &plugin, true
+	}
+	return nil, false
+}
+
+// Pin records sha256 as the trusted content hash for name/version and
+// persists the catalog to disk, so a later Verify call against that
+// version's plugin bundle fails if the bundle no longer matches. Pass the
+// empty string to unpin. Returns an error if name/version isn't
+// registered.
+func (c *Catalog) Pin(name, version, sha256 string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[name][version]
+	if !ok {
+		return fmt.Errorf("plugin catalog has no entry for %s version %s", name, version)
+	}
+	entry.PinnedHash = sha256
+	return c.save()
+}
+
+// Verify reports whether plugin matches its catalog entry's pinned hash,
+// if any. An unpinned or unregistered plugin always passes - pinning is
+// opt-in. A pinned entry whose freshly-computed hash no longer matches
+// returns an error identifying the mismatch, the way CreateEffectPinned
+// uses it to refuse a silently-swapped bundle.
+func (c *Catalog) Verify(plugin *Plugin) error {
+	c.mu.Lock()
+	entry, ok := c.entries[plugin.Name][plugin.Version]
+	c.mu.Unlock()
+	if !ok || entry.PinnedHash == "" {
+		return nil
+	}
+
+	got := CatalogHash(plugin)
+	if got != entry.PinnedHash {
+		return fmt.Errorf("plugin %s version %s failed catalog hash verification: pinned %s, got %s",
+			plugin.Name, plugin.Version, entry.PinnedHash, got)
+	}
+	return nil
+}
+
+// CatalogHash computes the SHA-256 hex digest Catalog.Pin/Verify compare
+// against: plugin's bundle path, version, and a stable serialization of its
+// parameter schema (address, identifier, range and step count per
+// parameter, in Parameters order) - deliberately narrower than
+// ComputeContentHash's bundle-binary hash, since a catalog entry cares
+// about the parameter surface a host has already built automation/preset
+// data against, not every byte of the bundle.
+func CatalogHash(plugin *Plugin) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s\n", plugin.BundlePath, plugin.Version)
+	for _, param := range plugin.Parameters {
+		fmt.Fprintf(h, "%d|%s|%g|%g|%d\n", param.Address, param.Identifier, param.MinValue, param.MaxValue, len(param.IndexedValues))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,143 @@
+package plugins
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func testCatalogPlugin(name, version, bundlePath string) *Plugin {
+	return &Plugin{
+		Name:           name,
+		ManufacturerID: "appl",
+		Type:           "aufx",
+		Subtype:        "test",
+		BundlePath:     bundlePath,
+		Version:        version,
+		Parameters: []Parameter{
+			{Identifier: "gain", Address: 1, MinValue: 0, MaxValue: 1},
+		},
+	}
+}
+
+func TestCatalogRegisterGetList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	catalog := NewCatalog(path)
+
+	p1 := testCatalogPlugin("Reverb", "1.0.0", "/plugins/Reverb.component")
+	p2 := testCatalogPlugin("Reverb", "2.0.0", "/plugins/Reverb.component")
+	if err := catalog.Register(p1); err != nil {
+		t.Fatalf("Register p1: %v", err)
+	}
+	if err := catalog.Register(p2); err != nil {
+		t.Fatalf("Register p2: %v", err)
+	}
+
+	got, ok := catalog.Get("Reverb", "1.0.0")
+	if !ok || got.Version != "1.0.0" {
+		t.Fatalf("Get(Reverb, 1.0.0) = %+v, %v", got, ok)
+	}
+
+	list := catalog.List()
+	if len(list) != 2 {
+		t.Fatalf("expected 2 catalog entries, got %d", len(list))
+	}
+
+	if err := catalog.Deregister("Reverb", "1.0.0"); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if _, ok := catalog.Get("Reverb", "1.0.0"); ok {
+		t.Error("expected Get to miss after Deregister")
+	}
+	if len(catalog.List()) != 1 {
+		t.Errorf("expected 1 catalog entry after Deregister, got %d", len(catalog.List()))
+	}
+}
+
+func TestCatalogLatestVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	catalog := NewCatalog(path)
+
+	for _, v := range []string{"1.0.0", "2.5.0", "2.4.9"} {
+		if err := catalog.Register(testCatalogPlugin("Reverb", v, "/plugins/Reverb.component")); err != nil {
+			t.Fatalf("Register %s: %v", v, err)
+		}
+	}
+
+	latest, ok := catalog.LatestVersion("Reverb")
+	if !ok || latest.Version != "2.5.0" {
+		t.Fatalf("LatestVersion = %+v, %v; want 2.5.0", latest, ok)
+	}
+
+	if _, ok := catalog.LatestVersion("Nonexistent"); ok {
+		t.Error("expected LatestVersion for an unregistered name to miss")
+	}
+}
+
+func TestCatalogPinAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	catalog := NewCatalog(path)
+
+	plugin := testCatalogPlugin("Reverb", "1.0.0", "/plugins/Reverb.component")
+	if err := catalog.Register(plugin); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// Unpinned: any plugin state passes.
+	if err := catalog.Verify(plugin); err != nil {
+		t.Errorf("expected unpinned Verify to pass, got %v", err)
+	}
+
+	hash := CatalogHash(plugin)
+	if err := catalog.Pin("Reverb", "1.0.0", hash); err != nil {
+		t.Fatalf("Pin: %v", err)
+	}
+	if err := catalog.Verify(plugin); err != nil {
+		t.Errorf("expected Verify to pass against the hash it was pinned with, got %v", err)
+	}
+
+	tampered := testCatalogPlugin("Reverb", "1.0.0", "/plugins/Reverb.component")
+	tampered.Parameters[0].MaxValue = 10
+	if err := catalog.Verify(tampered); err == nil {
+		t.Error("expected Verify to reject a plugin whose parameter schema changed since pinning")
+	}
+
+	if err := catalog.Pin("Missing", "1.0.0", hash); err == nil {
+		t.Error("expected Pin against an unregistered entry to fail")
+	}
+}
+
+func TestCatalogLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	catalog := NewCatalog(path)
+
+	plugin := testCatalogPlugin("Reverb", "1.0.0", "/plugins/Reverb.component")
+	if err := catalog.Register(plugin); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := catalog.Pin("Reverb", "1.0.0", CatalogHash(plugin)); err != nil {
+		t.Fatalf("Pin: %v", err)
+	}
+
+	reloaded, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	got, ok := reloaded.Get("Reverb", "1.0.0")
+	if !ok || got.Version != "1.0.0" {
+		t.Fatalf("Get after reload = %+v, %v", got, ok)
+	}
+	if err := reloaded.Verify(plugin); err != nil {
+		t.Errorf("expected pin to survive a reload, got %v", err)
+	}
+}
+
+func TestLoadCatalogMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.json")
+	catalog, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog on a missing file should not error, got %v", err)
+	}
+	if len(catalog.List()) != 0 {
+		t.Errorf("expected an empty catalog, got %d entries", len(catalog.List()))
+	}
+}
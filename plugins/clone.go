@@ -0,0 +1,38 @@
+//go:build darwin && cgo
+
+package plugins
+
+// Clone returns a deep copy of plugin: Parameters (and each Parameter's own
+// IndexedValues slice and IndexedMinValue/IndexedMaxValue pointers) are
+// copied rather than shared, so mutating one Plugin's parameter values never
+// leaks into another that happened to come from the same Introspect call or
+// the same IntrospectCached entry (see introspect_cache.go's Cache.Get,
+// which hands back the very *Plugin it has stored - two callers of
+// IntrospectCached for the same key otherwise share one backing Parameters
+// array).
+func (plugin Plugin) Clone() Plugin {
+	clone := plugin
+	clone.Parameters = make([]Parameter, len(plugin.Parameters))
+	for i, param := range plugin.Parameters {
+		clone.Parameters[i] = param.clone()
+	}
+	return clone
+}
+
+// clone returns a deep copy of param, duplicating IndexedValues and the
+// IndexedMinValue/IndexedMaxValue pointers rather than sharing them.
+func (param Parameter) clone() Parameter {
+	clone := param
+	if param.IndexedValues != nil {
+		clone.IndexedValues = append([]string(nil), param.IndexedValues...)
+	}
+	if param.IndexedMinValue != nil {
+		v := *param.IndexedMinValue
+		clone.IndexedMinValue = &v
+	}
+	if param.IndexedMaxValue != nil {
+		v := *param.IndexedMaxValue
+		clone.IndexedMaxValue = &v
+	}
+	return clone
+}
@@ -0,0 +1,35 @@
+package plugins
+
+import "testing"
+
+func TestPluginCloneIsIndependent(t *testing.T) {
+	minVal := 1
+	maxVal := 4
+	original := Plugin{
+		Name: "Test Delay",
+		Parameters: []Parameter{
+			{
+				Identifier:      "mode",
+				IndexedValues:   []string{"a", "b"},
+				IndexedMinValue: &minVal,
+				IndexedMaxValue: &maxVal,
+				CurrentValue:    1,
+			},
+		},
+	}
+
+	clone := original.Clone()
+	clone.Parameters[0].CurrentValue = 2
+	clone.Parameters[0].IndexedValues[0] = "z"
+	*clone.Parameters[0].IndexedMinValue = 99
+
+	if original.Parameters[0].CurrentValue != 1 {
+		t.Errorf("modifying clone's parameter leaked into original: got %v, want 1", original.Parameters[0].CurrentValue)
+	}
+	if original.Parameters[0].IndexedValues[0] != "a" {
+		t.Errorf("modifying clone's IndexedValues leaked into original: got %v, want a", original.Parameters[0].IndexedValues[0])
+	}
+	if *original.Parameters[0].IndexedMinValue != 1 {
+		t.Errorf("modifying clone's IndexedMinValue leaked into original: got %v, want 1", *original.Parameters[0].IndexedMinValue)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build darwin && cgo
+
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// cfBundleExecutable extracts CFBundleExecutable's value from an XML-format
+// Info.plist via a small regex rather than a full plist decoder - AU bundles
+// almost always ship an XML plist here, and a false miss just means
+// ComputeContentHash falls back to hashing Info.plist alone (see below),
+// not a wrong result.
+var cfBundleExecutable = regexp.MustCompile(`(?s)<key>CFBundleExecutable</key>\s*<string>([^<]+)</string>`)
+
+// ComputeContentHash sets pi.ContentHash to a SHA-256 hex digest over
+// pi.BundlePath's Info.plist, its main executable (resolved via
+// CFBundleExecutable), and pi.Version - so the same plugin binary produces
+// the same hash across re-scans, and a rebuilt or updated bundle produces a
+// different one even if BundleModTime didn't change (e.g. a reliable
+// network volume that doesn't preserve mtimes). It's a no-op, leaving
+// ContentHash empty, if BundlePath is empty or unreadable.
+func (pi *PluginInfo) ComputeContentHash() {
+	hash, err := contentHash(pi.BundlePath, pi.Version)
+	if err != nil {
+		return
+	}
+	pi.ContentHash = hash
+}
+
+// ComputeContentHash is PluginInfo.ComputeContentHash's counterpart for an
+// introspected Plugin.
+func (p *Plugin) ComputeContentHash() {
+	hash, err := contentHash(p.BundlePath, p.Version)
+	if err != nil {
+		return
+	}
+	p.ContentHash = hash
+}
+
+// contentHash hashes bundlePath's Info.plist, its main executable if one can
+// be resolved, and version together.
+func contentHash(bundlePath, version string) (string, error) {
+	if bundlePath == "" {
+		return "", os.ErrNotExist
+	}
+
+	plistPath := filepath.Join(bundlePath, "Contents", "Info.plist")
+	plistData, err := os.ReadFile(plistPath)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(plistData)
+
+	if m := cfBundleExecutable.FindSubmatch(plistData); m != nil {
+		execPath := filepath.Join(bundlePath, "Contents", "MacOS", string(m[1]))
+		if execData, err := os.ReadFile(execPath); err == nil {
+			h.Write(execData)
+		}
+	}
+
+	h.Write([]byte(version))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
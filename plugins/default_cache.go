@@ -0,0 +1,91 @@
+//go:build darwin && cgo
+
+package plugins
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultCache is the package-level Cache consulted by the *Cached helper
+// functions below. It's nil (no caching) until a caller opts in via
+// SetDefaultCache - this package can't construct one itself, since the
+// obvious implementation (plugins/cache.FileCache) imports this package and
+// a reverse import would cycle.
+var (
+	defaultCacheMu sync.RWMutex
+	defaultCache   Cache
+)
+
+// SetDefaultCache installs cache as the Cache consulted by InvalidateCache,
+// RebuildCacheAll, and any future default-cache-aware helper added to this
+// package. Pass nil to disable it again. Typical setup:
+//
+//	plugins.SetDefaultCache(cache.NewFileCache())
+func SetDefaultCache(cache Cache) {
+	defaultCacheMu.Lock()
+	defer defaultCacheMu.Unlock()
+	defaultCache = cache
+}
+
+// DefaultCache returns the Cache previously installed via SetDefaultCache,
+// or nil if none has been installed.
+func DefaultCache() Cache {
+	defaultCacheMu.RLock()
+	defer defaultCacheMu.RUnlock()
+	return defaultCache
+}
+
+// PluginTriplet identifies an AU suite the way List's results are grouped -
+// type, subtype and manufacturer, without Name - so a single call can target
+// every plugin in a suite rather than one introspection result at a time.
+type PluginTriplet struct {
+	Type           string
+	Subtype        string
+	ManufacturerID string
+}
+
+// InvalidateCache drops every cached introspection result for triplet's
+// suite from the default cache (see SetDefaultCache), including any
+// blacklist entries, by re-listing and matching on triplet. It's a no-op
+// returning nil if no default cache has been installed.
+func InvalidateCache(triplet PluginTriplet) error {
+	cache := DefaultCache()
+	if cache == nil {
+		return nil
+	}
+
+	infos, err := List()
+	if err != nil {
+		return err
+	}
+
+	matches := infos.ByType(triplet.Type).BySubtype(triplet.Subtype).ByManufacturer(triplet.ManufacturerID)
+	for _, info := range matches {
+		key := info.CacheKey()
+		if err := cache.Invalidate(key); err != nil {
+			return err
+		}
+		_ = cache.Unblacklist(key)
+	}
+	return nil
+}
+
+// RebuildCacheAll re-introspects every installed plugin and rewrites the
+// default cache (see SetDefaultCache) from scratch, the same way RebuildCache
+// does for an explicit infos/cache pair. It returns an empty IntrospectReport
+// and a nil error if no default cache has been installed - there's nothing to
+// rebuild.
+func RebuildCacheAll(ctx context.Context, opts IntrospectOptions) (IntrospectReport, error) {
+	cache := DefaultCache()
+	if cache == nil {
+		return IntrospectReport{}, nil
+	}
+
+	infos, err := ListWithContext(ctx)
+	if err != nil {
+		return IntrospectReport{}, err
+	}
+
+	return RebuildCache(ctx, cache, infos, opts)
+}
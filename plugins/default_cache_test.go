@@ -0,0 +1,58 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultCacheUnsetIsNoop(t *testing.T) {
+	SetDefaultCache(nil)
+	defer SetDefaultCache(nil)
+
+	if DefaultCache() != nil {
+		t.Fatal("expected no default cache installed")
+	}
+	if err := InvalidateCache(PluginTriplet{Type: "aufx", Subtype: "XXXX", ManufacturerID: "appl"}); err != nil {
+		t.Errorf("InvalidateCache with no default cache should be a no-op, got %v", err)
+	}
+	report, err := RebuildCacheAll(context.Background(), IntrospectOptions{})
+	if err != nil {
+		t.Errorf("RebuildCacheAll with no default cache should be a no-op, got %v", err)
+	}
+	if report.TotalCount != 0 {
+		t.Errorf("expected an empty report with no default cache, got %+v", report)
+	}
+}
+
+func TestInvalidateCacheClearsMatchingSuite(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+	info := effectInfos[0]
+
+	cache := newMemCache()
+	SetDefaultCache(cache)
+	defer SetDefaultCache(nil)
+
+	if _, err := info.IntrospectCached(cache); err != nil {
+		t.Fatalf("IntrospectCached failed: %v", err)
+	}
+	_ = cache.Blacklist(info.CacheKey(), "simulated crash")
+
+	triplet := PluginTriplet{Type: info.Type, Subtype: info.Subtype, ManufacturerID: info.ManufacturerID}
+	if err := InvalidateCache(triplet); err != nil {
+		t.Fatalf("InvalidateCache failed: %v", err)
+	}
+
+	if _, ok := cache.Get(info.CacheKey()); ok {
+		t.Error("expected InvalidateCache to drop the cached entry")
+	}
+	if cache.IsBlacklisted(info.CacheKey()) {
+		t.Error("expected InvalidateCache to clear the blacklist entry")
+	}
+}
@@ -0,0 +1,215 @@
+//go:build darwin && cgo
+
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventSchemaVersion is the schema_version stamped on every Event this
+// package publishes. Bump it if Event's fields change in a way a consumer
+// parsing SchemaVersion would need to branch on.
+const EventSchemaVersion = 1
+
+// EventKind identifies which of Event's fields are meaningful - the rest
+// are left at their zero value, the same "tag picks the active fields"
+// convention pluginResult's JSON envelope uses elsewhere in this package.
+type EventKind string
+
+const (
+	EventQuickScanStarted    EventKind = "quick_scan_started"
+	EventQuickScanCompleted  EventKind = "quick_scan_completed"
+	EventIntrospectStarted   EventKind = "introspect_started"
+	EventIntrospectCompleted EventKind = "introspect_completed"
+	EventIntrospectFailed    EventKind = "introspect_failed"
+	EventCacheHit            EventKind = "cache_hit"
+)
+
+// Event is a tagged union describing one thing that happened during a scan,
+// introspection call, or cache lookup - published via publish to every
+// subscriber registered with Subscribe. Switch on Kind before reading the
+// kind-specific fields below it.
+type Event struct {
+	SchemaVersion int       `json:"schema_version"`
+	Kind          EventKind `json:"kind"`
+	At            time.Time `json:"at"`
+
+	// Triplet is set for IntrospectStarted/Completed/Failed and CacheHit.
+	Triplet PluginTriplet `json:"triplet,omitempty"`
+	// Count is set for QuickScanCompleted (number of plugins found).
+	Count int `json:"count,omitempty"`
+	// ParamCount is set for IntrospectCompleted.
+	ParamCount int `json:"paramCount,omitempty"`
+	// Duration is set for QuickScanCompleted and IntrospectCompleted.
+	Duration time.Duration `json:"duration,omitempty"`
+	// Err is set for IntrospectFailed.
+	Err string `json:"err,omitempty"`
+	// Hash is set for CacheHit - Key.BundleModTime at the moment of the
+	// hit, formatted, since the Key type this package exposes doesn't
+	// carry a single precomputed fingerprint string (see
+	// plugins/cache.FileCache.fingerprint for the actual on-disk key a
+	// given Cache implementation used).
+	Hash string `json:"hash,omitempty"`
+}
+
+var (
+	subsMu    sync.RWMutex
+	subs      = make(map[int]func(Event))
+	nextSubID int
+)
+
+// Subscribe registers fn to be called synchronously, on the calling
+// goroutine, for every Event this package publishes from here on - List,
+// ListWithContext, Introspect and friends, and IntrospectCached/Prewarm's
+// cache hits. Call the returned unsubscribe to stop receiving events; fn
+// must not block for long, since publish calls every subscriber in turn on
+// the scanning goroutine.
+func Subscribe(fn func(Event)) (unsubscribe func()) {
+	subsMu.Lock()
+	id := nextSubID
+	nextSubID++
+	subs[id] = fn
+	subsMu.Unlock()
+
+	return func() {
+		subsMu.Lock()
+		delete(subs, id)
+		subsMu.Unlock()
+	}
+}
+
+// publish stamps ev.SchemaVersion and At (if not already set, so a caller
+// can backdate a synthetic event in a test) and fans it out to every
+// current subscriber.
+func publish(ev Event) {
+	ev.SchemaVersion = EventSchemaVersion
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	subsMu.RLock()
+	defer subsMu.RUnlock()
+	for _, fn := range subs {
+		fn(ev)
+	}
+}
+
+// JSONLSink writes one JSON object per line per Event, matching the
+// line-per-record shape SetJSONLogWriter/logJSON already wrote to a file -
+// so a caller that was parsing that file for QuickScan/Introspect[...]
+// records can switch to NewJSONLSink and keep the same "one JSON value per
+// line" framing, now with a stable, typed schema instead of the native
+// layer's raw JSON passthrough.
+type JSONLSink struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewJSONLSink returns a sink that writes every Event it receives to w as a
+// single line of JSON. Pass the returned Write method to Subscribe:
+//
+//	sink := plugins.NewJSONLSink(f)
+//	unsubscribe := plugins.Subscribe(sink.Write)
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{w: w}
+}
+
+// Write encodes ev as one JSON line to the sink's writer. It satisfies the
+// func(Event) signature Subscribe expects.
+func (s *JSONLSink) Write(ev Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.w, "%s\n", data)
+}
+
+// LatencyHistogram accumulates Duration samples for one PluginTriplet's
+// introspection calls, in coarse power-of-two-millisecond buckets - enough
+// resolution to spot a plugin that's crept from "instant" to "visibly slow"
+// without pulling in a full metrics library dependency.
+type LatencyHistogram struct {
+	Count   int64
+	Sum     time.Duration
+	Buckets map[int]int64 // bucket N covers [2^N, 2^(N+1)) milliseconds
+}
+
+func (h *LatencyHistogram) observe(d time.Duration) {
+	h.Count++
+	h.Sum += d
+	ms := d.Milliseconds()
+	bucket := 0
+	for ms > 1 {
+		ms >>= 1
+		bucket++
+	}
+	if h.Buckets == nil {
+		h.Buckets = make(map[int]int64)
+	}
+	h.Buckets[bucket]++
+}
+
+// Mean returns h's average observed Duration, or 0 if no samples have been
+// recorded yet.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// MetricsSink accumulates per-triplet introspection latency histograms from
+// IntrospectCompleted events, for an embedding app to expose as its own
+// metrics (e.g. a Prometheus gauge walking Snapshot's map) rather than
+// parsing a log file.
+type MetricsSink struct {
+	mu         sync.Mutex
+	histograms map[PluginTriplet]*LatencyHistogram
+}
+
+// NewMetricsSink returns an empty MetricsSink. Pass its Write method to
+// Subscribe:
+//
+//	metrics := plugins.NewMetricsSink()
+//	unsubscribe := plugins.Subscribe(metrics.Write)
+func NewMetricsSink() *MetricsSink {
+	return &MetricsSink{histograms: make(map[PluginTriplet]*LatencyHistogram)}
+}
+
+// Write records ev's Duration against its Triplet's histogram, if ev is an
+// IntrospectCompleted event; every other Kind is ignored. It satisfies the
+// func(Event) signature Subscribe expects.
+func (s *MetricsSink) Write(ev Event) {
+	if ev.Kind != EventIntrospectCompleted {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.histograms[ev.Triplet]
+	if !ok {
+		h = &LatencyHistogram{}
+		s.histograms[ev.Triplet] = h
+	}
+	h.observe(ev.Duration)
+}
+
+// Snapshot returns a copy of every triplet's histogram recorded so far,
+// safe to read while Write continues running concurrently on other scans.
+func (s *MetricsSink) Snapshot() map[PluginTriplet]LatencyHistogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[PluginTriplet]LatencyHistogram, len(s.histograms))
+	for triplet, h := range s.histograms {
+		buckets := make(map[int]int64, len(h.Buckets))
+		for k, v := range h.Buckets {
+			buckets[k] = v
+		}
+		out[triplet] = LatencyHistogram{Count: h.Count, Sum: h.Sum, Buckets: buckets}
+	}
+	return out
+}
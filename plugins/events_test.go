@@ -0,0 +1,92 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSubscribeReceivesQuickScanEvents(t *testing.T) {
+	var kinds []EventKind
+	unsubscribe := Subscribe(func(ev Event) {
+		kinds = append(kinds, ev.Kind)
+		if ev.SchemaVersion != EventSchemaVersion {
+			t.Errorf("expected schema version %d, got %d", EventSchemaVersion, ev.SchemaVersion)
+		}
+	})
+	defer unsubscribe()
+
+	if _, err := List(); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(kinds) != 2 || kinds[0] != EventQuickScanStarted || kinds[1] != EventQuickScanCompleted {
+		t.Errorf("expected [started, completed], got %v", kinds)
+	}
+}
+
+func TestUnsubscribeStopsDelivery(t *testing.T) {
+	count := 0
+	unsubscribe := Subscribe(func(ev Event) { count++ })
+	unsubscribe()
+
+	if _, err := List(); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no events after unsubscribe, got %d", count)
+	}
+}
+
+func TestJSONLSinkWritesOneLinePerEvent(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+	unsubscribe := Subscribe(sink.Write)
+	defer unsubscribe()
+
+	if _, err := List(); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSONL lines, got %d: %q", len(lines), buf.String())
+	}
+	var ev Event
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatalf("failed to parse JSONL line: %v", err)
+	}
+	if ev.Kind != EventQuickScanStarted {
+		t.Errorf("expected first line to be quick_scan_started, got %q", ev.Kind)
+	}
+}
+
+func TestMetricsSinkTracksIntrospectLatency(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to list plugins: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+
+	metrics := NewMetricsSink()
+	unsubscribe := Subscribe(metrics.Write)
+	defer unsubscribe()
+
+	if _, err := effectInfos[0].Introspect(); err != nil {
+		t.Fatalf("Introspect failed: %v", err)
+	}
+
+	triplet := PluginTriplet{Type: effectInfos[0].Type, Subtype: effectInfos[0].Subtype, ManufacturerID: effectInfos[0].ManufacturerID}
+	snapshot := metrics.Snapshot()
+	h, ok := snapshot[triplet]
+	if !ok {
+		t.Fatalf("expected a histogram for triplet %+v, got %+v", triplet, snapshot)
+	}
+	if h.Count != 1 {
+		t.Errorf("expected 1 sample, got %d", h.Count)
+	}
+}
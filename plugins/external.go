@@ -0,0 +1,228 @@
+//go:build darwin && cgo
+
+package plugins
+
+/*
+#include <stdlib.h>
+// Declared for RegisterExternal/Unregister below; the native implementation
+// doesn't exist yet in this tree (see RegisterExternal's doc comment).
+char *RegisterExternalComponent(const char *bundlePath);
+char *UnregisterExternalComponent(const char *bundlePath);
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+	"unsafe"
+)
+
+var (
+	audioComponentsArrayRe = regexp.MustCompile(`(?s)<key>AudioComponents</key>\s*<array>(.*?)</array>`)
+	audioComponentEntryRe  = regexp.MustCompile(`(?s)<dict>.*?</dict>`)
+)
+
+// plistField extracts the <string> or <integer> value of key from an
+// Info.plist <dict> block via a small regex rather than a full plist
+// decoder - the same tradeoff content_hash.go's cfBundleExecutable makes,
+// and fine here since a false miss just drops one malformed component entry
+// rather than producing a wrong result.
+func plistField(block, key string) string {
+	re := regexp.MustCompile(`(?s)<key>` + regexp.QuoteMeta(key) + `</key>\s*<(?:string|integer)>([^<]*)</(?:string|integer)>`)
+	m := re.FindStringSubmatch(block)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// isComponentBundle reports whether path looks like an AU component or App
+// Extension bundle by extension alone - ListFromPath doesn't require the
+// bundle to already be registered with the system.
+func isComponentBundle(path string) bool {
+	switch filepath.Ext(path) {
+	case ".component", ".appex":
+		return true
+	default:
+		return false
+	}
+}
+
+// findComponentBundles returns every .component/.appex bundle under root:
+// root itself if it already is one, otherwise every matching bundle found
+// by walking root (without descending into a matched bundle's own
+// contents, since that's an opaque package, not a directory to keep
+// searching).
+func findComponentBundles(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory or bundle", root)
+	}
+	if isComponentBundle(root) {
+		return []string{root}, nil
+	}
+
+	var bundles []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && d.IsDir() && isComponentBundle(path) {
+			bundles = append(bundles, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	return bundles, nil
+}
+
+// infosFromBundle parses bundlePath's Info.plist for its AudioComponents
+// array and returns one PluginInfo per entry, with SourcePath and
+// BundlePath both set to bundlePath. A bundle with no AudioComponents array,
+// or whose Info.plist can't be read, is skipped rather than failing the
+// whole ListFromPath call - the same best-effort tolerance
+// withContentHashes uses for a bundle that can't be read.
+func infosFromBundle(bundlePath string) (PluginInfos, error) {
+	plistPath := filepath.Join(bundlePath, "Contents", "Info.plist")
+	data, err := os.ReadFile(plistPath)
+	if err != nil {
+		return nil, err
+	}
+
+	arrayMatch := audioComponentsArrayRe.FindStringSubmatch(string(data))
+	if arrayMatch == nil {
+		return nil, fmt.Errorf("no AudioComponents array found in %s", plistPath)
+	}
+
+	var modTime time.Time
+	if fi, err := os.Stat(bundlePath); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	entries := audioComponentEntryRe.FindAllString(arrayMatch[1], -1)
+	infos := make(PluginInfos, 0, len(entries))
+	for _, entry := range entries {
+		info := PluginInfo{
+			Type:           plistField(entry, "type"),
+			Subtype:        plistField(entry, "subtype"),
+			ManufacturerID: plistField(entry, "manufacturer"),
+			Name:           plistField(entry, "name"),
+			BundlePath:     bundlePath,
+			SourcePath:     bundlePath,
+			BundleModTime:  modTime,
+		}
+		if info.Type == "" || info.Subtype == "" || info.ManufacturerID == "" {
+			continue
+		}
+		info.ComputeContentHash()
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ListFromPath scans paths (each a directory to search, or a direct bundle
+// path) for .component/.appex bundles and returns a PluginInfo for each
+// AudioComponents entry found in their Info.plist - the on-disk counterpart
+// to List's AudioComponentManager-only enumeration, so a development build
+// directory (e.g. "./plugins-storage/") can be browsed and filtered the same
+// way an installed plugin can, before it's ever copied into
+// /Library/Audio/Plug-Ins/Components.
+//
+// Entries found this way aren't registered with the system
+// AudioComponentManager yet, so Introspect on one of them will fail until
+// PluginInfos.RegisterExternal has registered its bundle.
+func ListFromPath(paths ...string) (PluginInfos, error) {
+	var infos PluginInfos
+	for _, root := range paths {
+		bundles, err := findComponentBundles(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, bundle := range bundles {
+			found, err := infosFromBundle(bundle)
+			if err != nil {
+				continue
+			}
+			infos = append(infos, found...)
+		}
+	}
+	return infos, nil
+}
+
+// RegisterExternal registers bundlePath (typically one of infos' SourcePath
+// values, previously discovered via ListFromPath) with the system
+// AudioComponentManager via AudioComponentRegister, using the
+// AudioComponentDescription parsed from its Info.plist and verifying its
+// code signature and architecture match this host the same way a
+// system-installed AU component is checked at boot. Once registered, its
+// plugins behave exactly like a system-installed one.
+//
+// The native implementation doesn't exist yet in this tree - this wires the
+// Go-side contract ahead of it, the same way ApplyState wires
+// ApplyParameterState in state.go.
+func (infos PluginInfos) RegisterExternal(bundlePath string) error {
+	cBundlePath := C.CString(bundlePath)
+	defer C.free(unsafe.Pointer(cBundlePath))
+
+	cResult := C.RegisterExternalComponent(cBundlePath)
+	if cResult == nil {
+		return fmt.Errorf("failed to register external component at %s", bundlePath)
+	}
+	defer C.free(unsafe.Pointer(cResult))
+
+	var response struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(C.GoString(cResult)), &response); err != nil {
+		return fmt.Errorf("failed to parse register-external response: %v", err)
+	}
+	if !response.Success {
+		errMsg := response.Error
+		if errMsg == "" {
+			errMsg = "unknown error"
+		}
+		return fmt.Errorf("register external component failed: %s", errMsg)
+	}
+	return nil
+}
+
+// Unregister reverses a prior RegisterExternal call for bundlePath,
+// removing it from the system AudioComponentManager again.
+func (infos PluginInfos) Unregister(bundlePath string) error {
+	cBundlePath := C.CString(bundlePath)
+	defer C.free(unsafe.Pointer(cBundlePath))
+
+	cResult := C.UnregisterExternalComponent(cBundlePath)
+	if cResult == nil {
+		return fmt.Errorf("failed to unregister external component at %s", bundlePath)
+	}
+	defer C.free(unsafe.Pointer(cResult))
+
+	var response struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(C.GoString(cResult)), &response); err != nil {
+		return fmt.Errorf("failed to parse unregister-external response: %v", err)
+	}
+	if !response.Success {
+		errMsg := response.Error
+		if errMsg == "" {
+			errMsg = "unknown error"
+		}
+		return fmt.Errorf("unregister external component failed: %s", errMsg)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+//go:build darwin && cgo
+
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSyntheticBundle(t *testing.T, dir, name string, components []string) string {
+	t.Helper()
+	bundlePath := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Join(bundlePath, "Contents"), 0755); err != nil {
+		t.Fatalf("failed to create bundle dir: %v", err)
+	}
+	var entries string
+	for _, c := range components {
+		entries += c
+	}
+	plistContents := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>AudioComponents</key>
+	<array>
+` + entries + `	</array>
+</dict>
+</plist>`
+	if err := os.WriteFile(filepath.Join(bundlePath, "Contents", "Info.plist"), []byte(plistContents), 0644); err != nil {
+		t.Fatalf("failed to write Info.plist: %v", err)
+	}
+	return bundlePath
+}
+
+func syntheticComponent(pluginType, subtype, manufacturer, name string) string {
+	return `		<dict>
+			<key>type</key>
+			<string>` + pluginType + `</string>
+			<key>subtype</key>
+			<string>` + subtype + `</string>
+			<key>manufacturer</key>
+			<string>` + manufacturer + `</string>
+			<key>name</key>
+			<string>` + name + `</string>
+		</dict>
+`
+}
+
+func TestFindComponentBundlesDescendsOnlyUntilABundle(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "nested")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("failed to create nested dir: %v", err)
+	}
+	writeSyntheticBundle(t, nested, "A.component", []string{syntheticComponent("aufx", "tst1", "Test", "A")})
+	writeSyntheticBundle(t, root, "B.appex", []string{syntheticComponent("aumu", "tst2", "Test", "B")})
+
+	bundles, err := findComponentBundles(root)
+	if err != nil {
+		t.Fatalf("findComponentBundles failed: %v", err)
+	}
+	if len(bundles) != 2 {
+		t.Fatalf("expected 2 bundles, got %d: %v", len(bundles), bundles)
+	}
+}
+
+func TestFindComponentBundlesRootIsBundle(t *testing.T) {
+	root := t.TempDir()
+	bundlePath := writeSyntheticBundle(t, root, "Direct.component", []string{syntheticComponent("aufx", "tst1", "Test", "Direct")})
+
+	bundles, err := findComponentBundles(bundlePath)
+	if err != nil {
+		t.Fatalf("findComponentBundles failed: %v", err)
+	}
+	if len(bundles) != 1 || bundles[0] != bundlePath {
+		t.Fatalf("expected [%s], got %v", bundlePath, bundles)
+	}
+}
+
+func TestInfosFromBundleSkipsIncompleteEntries(t *testing.T) {
+	root := t.TempDir()
+	bundlePath := writeSyntheticBundle(t, root, "Mixed.component", []string{
+		syntheticComponent("aufx", "tst1", "Test", "Complete"),
+		`		<dict>
+			<key>type</key>
+			<string>aumu</string>
+		</dict>
+`,
+	})
+
+	infos, err := infosFromBundle(bundlePath)
+	if err != nil {
+		t.Fatalf("infosFromBundle failed: %v", err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("expected 1 complete entry, got %d", len(infos))
+	}
+	if infos[0].Name != "Complete" {
+		t.Errorf("expected name %q, got %q", "Complete", infos[0].Name)
+	}
+	if infos[0].ContentHash == "" {
+		t.Errorf("expected a content hash to be computed")
+	}
+}
+
+func TestListFromPathSkipsBundlesMissingAudioComponents(t *testing.T) {
+	root := t.TempDir()
+	bundlePath := filepath.Join(root, "NoComponents.component")
+	if err := os.MkdirAll(filepath.Join(bundlePath, "Contents"), 0755); err != nil {
+		t.Fatalf("failed to create bundle dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundlePath, "Contents", "Info.plist"), []byte(`<?xml version="1.0"?><plist><dict></dict></plist>`), 0644); err != nil {
+		t.Fatalf("failed to write Info.plist: %v", err)
+	}
+
+	infos, err := ListFromPath(root)
+	if err != nil {
+		t.Fatalf("ListFromPath failed: %v", err)
+	}
+	if len(infos) != 0 {
+		t.Fatalf("expected 0 plugins from a bundle with no AudioComponents, got %d", len(infos))
+	}
+}
@@ -0,0 +1,175 @@
+//go:build darwin && cgo
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Key identifies one introspection result for caching purposes: the same
+// 4-tuple used to request introspection, plus a fingerprint of the bundle it
+// came from so a cache entry invalidates itself the moment the underlying
+// plugin changes.
+type Key struct {
+	Type           string
+	Subtype        string
+	ManufacturerID string
+	Name           string
+	BundlePath     string
+	Version        string
+	BundleModTime  time.Time
+}
+
+// CacheKey builds the Key a cache would use to store or look up this
+// PluginInfo's introspection result.
+func (pi PluginInfo) CacheKey() Key {
+	return Key{
+		Type:           pi.Type,
+		Subtype:        pi.Subtype,
+		ManufacturerID: pi.ManufacturerID,
+		Name:           pi.Name,
+		BundlePath:     pi.BundlePath,
+		Version:        pi.Version,
+		BundleModTime:  pi.BundleModTime,
+	}
+}
+
+// CacheKey builds the Key a cache would use to store or look up this
+// Plugin's introspection result, matching PluginInfo.CacheKey for the same
+// plugin.
+func (p Plugin) CacheKey() Key {
+	return Key{
+		Type:           p.Type,
+		Subtype:        p.Subtype,
+		ManufacturerID: p.ManufacturerID,
+		Name:           p.Name,
+		BundlePath:     p.BundlePath,
+		Version:        p.Version,
+		BundleModTime:  p.BundleModTime,
+	}
+}
+
+// Cache stores introspected Plugin results keyed by Key. Implementations
+// live outside this package (see plugins/cache.FileCache for the default,
+// disk-backed one) since Introspect results change only when the bundle
+// behind Key's fingerprint changes.
+type Cache interface {
+	Get(key Key) (*Plugin, bool)
+	Put(key Key, p *Plugin) error
+	Invalidate(key Key) error
+	// IsBlacklisted reports whether key was previously recorded via
+	// Blacklist and hasn't since been cleared by Unblacklist.
+	IsBlacklisted(key Key) bool
+	// Blacklist records key as a plugin whose introspection crashed or
+	// timed out, so Prewarm/IntrospectCached skip it on later calls
+	// instead of repeating the failure on every launch.
+	Blacklist(key Key, reason string) error
+	// Unblacklist clears a previous Blacklist call for key, if any.
+	Unblacklist(key Key) error
+}
+
+// IntrospectCached consults cache for pi's introspection result before
+// falling back to Introspect on a miss, storing the freshly introspected
+// plugin back into cache on success.
+func (pi PluginInfo) IntrospectCached(cache Cache) (*Plugin, error) {
+	return pi.IntrospectCachedWithContext(context.Background(), cache, 0)
+}
+
+// IntrospectCachedWithContext is IntrospectCached, but cancellable/deadline
+// -able via ctx, with timeout additionally bounding the introspection call
+// itself (<= 0 means no per-call timeout). A key that's already blacklisted
+// (see Cache.Blacklist) fails immediately without attempting to introspect
+// again; a call that times out blacklists its key so later callers stop
+// retrying a plugin known to hang, until Cache.Unblacklist clears it.
+func (pi PluginInfo) IntrospectCachedWithContext(ctx context.Context, cache Cache, timeout time.Duration) (*Plugin, error) {
+	key := pi.CacheKey()
+	if cache.IsBlacklisted(key) {
+		return nil, fmt.Errorf("plugin %s is blacklisted after a prior introspect failure; call Unblacklist to retry", pi.Name)
+	}
+	if plugin, ok := cache.Get(key); ok {
+		publish(Event{Kind: EventCacheHit, Triplet: PluginTriplet{Type: key.Type, Subtype: key.Subtype, ManufacturerID: key.ManufacturerID}, Hash: key.BundleModTime.String()})
+		return plugin, nil
+	}
+
+	callCtx := ctx
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	plugin, err := pi.IntrospectWithContext(callCtx)
+	if err != nil {
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			_ = cache.Blacklist(key, err.Error())
+		}
+		return nil, err
+	}
+
+	if err := cache.Put(key, plugin); err != nil {
+		return plugin, err
+	}
+	return plugin, nil
+}
+
+// Prewarm populates cache with every plugin in infos not already cached,
+// introspecting the misses concurrently via IntrospectParallel. Entries
+// already in cache are returned without re-introspecting them, so a caller
+// can run this on every launch and only pay for bundles that actually
+// changed since the last run. Unlike IntrospectParallel, the returned
+// report's Plugins isn't in infos' input order: cache hits are listed first,
+// followed by the newly introspected misses.
+func Prewarm(ctx context.Context, cache Cache, infos PluginInfos, opts IntrospectOptions) (IntrospectReport, error) {
+	report := IntrospectReport{TotalCount: len(infos)}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	var misses PluginInfos
+	for _, info := range infos {
+		key := info.CacheKey()
+		if cache.IsBlacklisted(key) {
+			report.Failures = append(report.Failures, IntrospectFailure{
+				Info: info,
+				Err:  fmt.Errorf("plugin %s is blacklisted after a prior introspect failure", info.Name),
+			})
+			continue
+		}
+		if plugin, ok := cache.Get(key); ok {
+			report.Plugins = append(report.Plugins, plugin)
+			continue
+		}
+		misses = append(misses, info)
+	}
+
+	if len(misses) == 0 {
+		report.SuccessCount = len(report.Plugins)
+		report.FailureCount = len(report.Failures)
+		return report, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	missReport, err := misses.IntrospectParallel(opts)
+	for _, plugin := range missReport.Plugins {
+		report.Plugins = append(report.Plugins, plugin)
+		if cacheErr := cache.Put(plugin.CacheKey(), plugin); cacheErr != nil && err == nil {
+			err = cacheErr
+		}
+	}
+	for _, failure := range missReport.Failures {
+		if failure.TimedOut {
+			_ = cache.Blacklist(failure.Info.CacheKey(), failure.Err.Error())
+		}
+	}
+	report.Failures = append(report.Failures, missReport.Failures...)
+	report.SuccessCount = len(report.Plugins)
+	report.FailureCount = len(report.Failures)
+
+	return report, err
+}
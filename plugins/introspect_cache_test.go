@@ -0,0 +1,202 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+)
+
+// memCache is a minimal in-memory Cache used to test IntrospectCached and
+// Prewarm without depending on the plugins/cache subpackage (which imports
+// this package, so it can't be imported back from an internal test file).
+type memCache struct {
+	entries     map[Key]*Plugin
+	blacklisted map[Key]string
+	puts        int
+}
+
+func newMemCache() *memCache {
+	return &memCache{entries: make(map[Key]*Plugin), blacklisted: make(map[Key]string)}
+}
+
+func (c *memCache) Get(key Key) (*Plugin, bool) {
+	p, ok := c.entries[key]
+	return p, ok
+}
+
+func (c *memCache) Put(key Key, p *Plugin) error {
+	c.entries[key] = p
+	c.puts++
+	return nil
+}
+
+func (c *memCache) Invalidate(key Key) error {
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *memCache) IsBlacklisted(key Key) bool {
+	_, ok := c.blacklisted[key]
+	return ok
+}
+
+func (c *memCache) Blacklist(key Key, reason string) error {
+	c.blacklisted[key] = reason
+	return nil
+}
+
+func (c *memCache) Unblacklist(key Key) error {
+	delete(c.blacklisted, key)
+	return nil
+}
+
+func TestIntrospectCached(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+	info := effectInfos[0]
+
+	cache := newMemCache()
+
+	plugin, err := info.IntrospectCached(cache)
+	if err != nil {
+		t.Fatalf("IntrospectCached (miss) failed: %v", err)
+	}
+	if cache.puts != 1 {
+		t.Errorf("expected one cache write after a miss, got %d", cache.puts)
+	}
+
+	cached, err := info.IntrospectCached(cache)
+	if err != nil {
+		t.Fatalf("IntrospectCached (hit) failed: %v", err)
+	}
+	if cache.puts != 1 {
+		t.Errorf("expected no additional cache write on a hit, got %d puts", cache.puts)
+	}
+	if cached.Name != plugin.Name {
+		t.Errorf("expected cached plugin %q, got %q", plugin.Name, cached.Name)
+	}
+}
+
+func TestPrewarm(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+	batch := effectInfos
+	if len(batch) > 4 {
+		batch = batch[:4]
+	}
+
+	cache := newMemCache()
+
+	report, err := Prewarm(context.Background(), cache, batch, IntrospectOptions{Workers: 2})
+	if err != nil {
+		t.Fatalf("Prewarm (cold) failed: %v", err)
+	}
+	if report.SuccessCount != len(batch) {
+		t.Errorf("expected %d successes on a cold prewarm, got %d", len(batch), report.SuccessCount)
+	}
+	if cache.puts != len(batch) {
+		t.Errorf("expected %d cache writes on a cold prewarm, got %d", len(batch), cache.puts)
+	}
+
+	putsAfterCold := cache.puts
+	report, err = Prewarm(context.Background(), cache, batch, IntrospectOptions{Workers: 2})
+	if err != nil {
+		t.Fatalf("Prewarm (warm) failed: %v", err)
+	}
+	if report.SuccessCount != len(batch) {
+		t.Errorf("expected %d successes on a warm prewarm, got %d", len(batch), report.SuccessCount)
+	}
+	if cache.puts != putsAfterCold {
+		t.Errorf("expected no new cache writes once everything is warm, got %d additional puts", cache.puts-putsAfterCold)
+	}
+}
+
+func TestPrewarmCancelledContext(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	if len(pluginInfos) == 0 {
+		t.Skip("No plugins available for testing")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cache := newMemCache()
+	_, err = Prewarm(ctx, cache, pluginInfos, IntrospectOptions{})
+	if err == nil {
+		t.Fatal("expected Prewarm to report the already-cancelled context")
+	}
+}
+
+func TestIntrospectCachedSkipsBlacklistedKey(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+	info := effectInfos[0]
+
+	cache := newMemCache()
+	if err := cache.Blacklist(info.CacheKey(), "prior crash"); err != nil {
+		t.Fatalf("Blacklist failed: %v", err)
+	}
+
+	if _, err := info.IntrospectCached(cache); err == nil {
+		t.Fatal("expected IntrospectCached to refuse a blacklisted key")
+	}
+
+	if err := cache.Unblacklist(info.CacheKey()); err != nil {
+		t.Fatalf("Unblacklist failed: %v", err)
+	}
+	if _, err := info.IntrospectCached(cache); err != nil {
+		t.Fatalf("IntrospectCached after Unblacklist failed: %v", err)
+	}
+}
+
+func TestRebuildCacheClearsBlacklistAndRewarms(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+	batch := effectInfos
+	if len(batch) > 2 {
+		batch = batch[:2]
+	}
+
+	cache := newMemCache()
+	if _, err := Prewarm(context.Background(), cache, batch, IntrospectOptions{Workers: 2}); err != nil {
+		t.Fatalf("initial Prewarm failed: %v", err)
+	}
+	_ = cache.Blacklist(batch[0].CacheKey(), "simulated crash")
+
+	report, err := RebuildCache(context.Background(), cache, batch, IntrospectOptions{Workers: 2})
+	if err != nil {
+		t.Fatalf("RebuildCache failed: %v", err)
+	}
+	if report.SuccessCount != len(batch) {
+		t.Errorf("expected %d successes after RebuildCache, got %d", len(batch), report.SuccessCount)
+	}
+	if cache.IsBlacklisted(batch[0].CacheKey()) {
+		t.Error("expected RebuildCache to clear a prior blacklist entry")
+	}
+}
@@ -0,0 +1,166 @@
+//go:build darwin && cgo
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IntrospectOptions configures PluginInfos.IntrospectParallel.
+type IntrospectOptions struct {
+	// Workers is how many goroutines introspect concurrently. <= 0 defaults to 4.
+	Workers int
+	// PerPluginTimeout bounds each individual plugin's introspection call via
+	// IntrospectWithContext. <= 0 means no per-call timeout.
+	PerPluginTimeout time.Duration
+	// ContinueOnError keeps the rest of the batch running past a failed
+	// plugin instead of treating the first failure as fatal; the failure is
+	// always recorded in IntrospectReport.Failures either way.
+	ContinueOnError bool
+	// Progress, if non-nil, is called after each plugin finishes (success or
+	// failure) with the running done/total count, the plugin just handled,
+	// and its error (nil on success). It may be called concurrently from
+	// multiple workers.
+	Progress func(done, total int, current PluginInfo, err error)
+	// Ctx, if set, stops dispatching new plugins to workers once canceled;
+	// plugins already in flight still run to completion (same reasoning as
+	// ContinueOnError - a worker pool can't abort a goroutine mid-call).
+	// Plugins never dispatched are recorded in IntrospectReport.Failures
+	// with ctx.Err(). Nil means no early cancellation.
+	Ctx context.Context
+}
+
+// IntrospectFailure records one plugin that IntrospectParallel failed to introspect.
+type IntrospectFailure struct {
+	Info     PluginInfo
+	Err      error
+	TimedOut bool
+}
+
+// IntrospectReport is the result of PluginInfos.IntrospectParallel.
+type IntrospectReport struct {
+	Plugins      []*Plugin
+	Failures     []IntrospectFailure
+	TotalCount   int
+	SuccessCount int
+	FailureCount int
+}
+
+// IntrospectParallel introspects every PluginInfo in infos across
+// opts.Workers goroutines (default 4), bounding each call with
+// opts.PerPluginTimeout when set, and aggregates results in infos' input
+// order regardless of completion order - the result for infos[i] always
+// lands at the same position a sequential Introspect() would have put it.
+//
+// Unlike Introspect, a single failure doesn't stop the batch early: every
+// already-dispatched plugin runs to completion (a worker pool can't abort a
+// goroutine mid-call), and each failure is recorded in
+// IntrospectReport.Failures. When opts.ContinueOnError is false,
+// IntrospectParallel still returns the full report but also returns the
+// first failure as its error, so a caller that wants Introspect's
+// fail-on-first-error semantics can keep using the same `if err != nil`
+// check while one crashy plugin doesn't waste the rest of the scan.
+func (infos PluginInfos) IntrospectParallel(opts IntrospectOptions) (IntrospectReport, error) {
+	total := len(infos)
+	report := IntrospectReport{TotalCount: total}
+	if total == 0 {
+		return report, nil
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+	if workers > total {
+		workers = total
+	}
+
+	ctx := opts.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	plugins := make([]*Plugin, total)
+	failures := make([]*IntrospectFailure, total)
+	dispatched := make([]bool, total)
+
+	var doneCount int32
+	var firstErr error
+	var firstErrOnce sync.Once
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				info := infos[i]
+
+				callCtx := context.Background()
+				var cancel context.CancelFunc
+				if opts.PerPluginTimeout > 0 {
+					callCtx, cancel = context.WithTimeout(callCtx, opts.PerPluginTimeout)
+				}
+
+				plugin, err := info.IntrospectWithContext(callCtx)
+
+				if err != nil {
+					failures[i] = &IntrospectFailure{
+						Info:     info,
+						Err:      err,
+						TimedOut: errors.Is(callCtx.Err(), context.DeadlineExceeded),
+					}
+					if !opts.ContinueOnError {
+						firstErrOnce.Do(func() {
+							firstErr = fmt.Errorf("failed to introspect plugin %s: %w", info.Name, err)
+						})
+					}
+				} else {
+					plugins[i] = plugin
+				}
+
+				if cancel != nil {
+					cancel()
+				}
+
+				n := atomic.AddInt32(&doneCount, 1)
+				if opts.Progress != nil {
+					opts.Progress(int(n), total, info, err)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := range infos {
+		select {
+		case jobs <- i:
+			dispatched[i] = true
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range infos {
+		switch {
+		case !dispatched[i]:
+			report.Failures = append(report.Failures, IntrospectFailure{Info: infos[i], Err: ctx.Err()})
+		case failures[i] != nil:
+			report.Failures = append(report.Failures, *failures[i])
+		default:
+			report.Plugins = append(report.Plugins, plugins[i])
+		}
+	}
+	report.SuccessCount = len(report.Plugins)
+	report.FailureCount = len(report.Failures)
+
+	return report, firstErr
+}
@@ -0,0 +1,119 @@
+package plugins
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIntrospectParallel(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	if len(pluginInfos) == 0 {
+		t.Skip("No plugins available for testing")
+	}
+
+	batch := pluginInfos
+	if len(batch) > 8 {
+		batch = batch[:8]
+	}
+
+	t.Run("BasicParallel", func(t *testing.T) {
+		var mu sync.Mutex
+		var progressCalls int
+
+		report, err := batch.IntrospectParallel(IntrospectOptions{
+			Workers: 2,
+			Progress: func(done, total int, current PluginInfo, err error) {
+				mu.Lock()
+				progressCalls++
+				mu.Unlock()
+				if done > total {
+					t.Errorf("progress done %d exceeds total %d", done, total)
+				}
+			},
+		})
+		if err != nil {
+			t.Fatalf("IntrospectParallel failed: %v", err)
+		}
+		if report.TotalCount != len(batch) {
+			t.Errorf("Expected TotalCount %d, got %d", len(batch), report.TotalCount)
+		}
+		if report.SuccessCount+report.FailureCount != report.TotalCount {
+			t.Errorf("SuccessCount (%d) + FailureCount (%d) != TotalCount (%d)",
+				report.SuccessCount, report.FailureCount, report.TotalCount)
+		}
+		if progressCalls != len(batch) {
+			t.Errorf("Expected %d progress calls, got %d", len(batch), progressCalls)
+		}
+	})
+
+	t.Run("DefaultWorkerCount", func(t *testing.T) {
+		report, err := batch.IntrospectParallel(IntrospectOptions{})
+		if err != nil {
+			t.Fatalf("IntrospectParallel failed: %v", err)
+		}
+		if report.TotalCount != len(batch) {
+			t.Errorf("Expected TotalCount %d, got %d", len(batch), report.TotalCount)
+		}
+	})
+
+	t.Run("ContinueOnErrorWithFailure", func(t *testing.T) {
+		bogus := batch[0]
+		bogus.Name = bogus.Name + "__DOES_NOT_EXIST__"
+		withFailure := append(PluginInfos{bogus}, batch...)
+
+		report, err := withFailure.IntrospectParallel(IntrospectOptions{
+			Workers:         2,
+			ContinueOnError: true,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error with ContinueOnError, got: %v", err)
+		}
+		if report.FailureCount == 0 {
+			t.Fatal("Expected at least one failure for the bogus plugin")
+		}
+		if report.SuccessCount != len(batch) {
+			t.Errorf("Expected %d successes, got %d", len(batch), report.SuccessCount)
+		}
+	})
+
+	t.Run("StopOnFirstError", func(t *testing.T) {
+		bogus := batch[0]
+		bogus.Name = bogus.Name + "__DOES_NOT_EXIST__"
+		withFailure := append(PluginInfos{bogus}, batch...)
+
+		report, err := withFailure.IntrospectParallel(IntrospectOptions{Workers: 2})
+		if err == nil {
+			t.Fatal("Expected an error for the bogus plugin")
+		}
+		if report.FailureCount == 0 {
+			t.Fatal("Expected the failure to still be recorded in the report")
+		}
+	})
+
+	t.Run("EmptyInput", func(t *testing.T) {
+		report, err := PluginInfos{}.IntrospectParallel(IntrospectOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error for empty input, got: %v", err)
+		}
+		if report.TotalCount != 0 {
+			t.Errorf("Expected TotalCount 0, got %d", report.TotalCount)
+		}
+	})
+
+	t.Run("PerPluginTimeout", func(t *testing.T) {
+		report, err := batch.IntrospectParallel(IntrospectOptions{
+			Workers:          2,
+			PerPluginTimeout: 30 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("IntrospectParallel with timeout failed: %v", err)
+		}
+		if report.SuccessCount != len(batch) {
+			t.Errorf("Expected %d successes with a generous timeout, got %d", len(batch), report.SuccessCount)
+		}
+	})
+}
@@ -25,6 +25,11 @@ package plugins
 char *QuickScanAudioUnits(void);
 // 4-arg version: name == nil/empty => suite mode (all matches)
 char *IntrospectAudioUnits(const char *type, const char *subtype, const char *manufacturerID, const char *name);
+// Cancellable variants: cancelToken is polled by the Objective-C scan loop
+// between plugin loads; a non-zero value aborts the scan early with a
+// timedOut-style response instead of waiting for SetTotalTimeout.
+char *QuickScanAudioUnitsWithCancelToken(const int32_t *cancelToken);
+char *IntrospectAudioUnitsWithCancelToken(const char *type, const char *subtype, const char *manufacturerID, const char *name, const int32_t *cancelToken);
 void SetVerboseLogging(int enabled);
 // Timeout setters (configured from Go)
 void SetPresetLoadingTimeout(double seconds);
@@ -33,10 +38,14 @@ void SetTotalTimeout(double seconds);
 */
 import "C"
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -88,6 +97,35 @@ type PluginInfo struct {
 	Type           string `json:"type"`
 	Subtype        string `json:"subtype"`
 	Category       string `json:"category"`
+	// BundlePath, Version and BundleModTime identify the on-disk component
+	// backing this plugin, so a caller can tell whether a previously cached
+	// result (see IntrospectCached) is still valid for it.
+	BundlePath    string    `json:"bundlePath,omitempty"`
+	Version       string    `json:"version,omitempty"`
+	BundleModTime time.Time `json:"bundleModTime,omitempty"`
+	// ContentHash is a SHA-256 hex digest over the bundle's Info.plist, its
+	// main executable, and Version, computed by ComputeContentHash (see
+	// content_hash.go). Unlike BundleModTime, it stays stable across a
+	// touch/re-copy that doesn't actually change the bundle's contents, and
+	// is what the persistent introspection cache keys its on-disk entries
+	// by when present - see plugins/cache.FileCache.
+	ContentHash string `json:"contentHash,omitempty"`
+	// IsSandboxSafe and IsDeprecated reflect AudioComponentDescription/
+	// AUAudioUnit flags from the native layer: IsDeprecated is set for AU
+	// components still exposing only the legacy v2 API, IsSandboxSafe for
+	// ones that opt into the App Sandbox-safe in-process loading path.
+	IsSandboxSafe bool `json:"isSandboxSafe,omitempty"`
+	IsDeprecated  bool `json:"isDeprecated,omitempty"`
+	// HasCustomView reports whether the component publishes a custom view
+	// (kAudioUnitProperty_IconLocation / a Cocoa/AU view controller) rather
+	// than relying on a generic parameter list UI.
+	HasCustomView bool `json:"hasCustomView,omitempty"`
+	// SourcePath is set only for entries discovered by ListFromPath: the
+	// bundle path that was scanned directly, as opposed to BundlePath,
+	// which the system AudioComponentManager also reports for its own,
+	// already-installed plugins. Empty for anything returned by List or
+	// ListWithContext.
+	SourcePath string `json:"sourcePath,omitempty"`
 }
 
 // QuickScanResponse represents the response from quick scan (like devices pattern)
@@ -123,6 +161,16 @@ type Plugin struct {
 	Subtype        string      `json:"subtype"`
 	Category       string      `json:"category"`
 	Parameters     []Parameter `json:"parameters"`
+	// BundlePath, Version, BundleModTime, IsSandboxSafe, IsDeprecated and
+	// HasCustomView mirror the same fields on PluginInfo - see PluginInfo for
+	// why they're here.
+	BundlePath    string    `json:"bundlePath,omitempty"`
+	Version       string    `json:"version,omitempty"`
+	BundleModTime time.Time `json:"bundleModTime,omitempty"`
+	ContentHash   string    `json:"contentHash,omitempty"`
+	IsSandboxSafe bool      `json:"isSandboxSafe,omitempty"`
+	IsDeprecated  bool      `json:"isDeprecated,omitempty"`
+	HasCustomView bool      `json:"hasCustomView,omitempty"`
 }
 
 // Parameter represents an Audio Unit parameter with its complete metadata
@@ -152,6 +200,9 @@ type Plugins []Plugin
 // instantiating them. It returns PluginInfo entries that can be filtered and
 // later introspected individually.
 func List() (PluginInfos, error) {
+	publish(Event{Kind: EventQuickScanStarted})
+	start := time.Now()
+
 	cPluginList := C.QuickScanAudioUnits()
 	if cPluginList == nil {
 		return nil, fmt.Errorf("failed to scan AudioUnit plugins")
@@ -177,7 +228,80 @@ func List() (PluginInfos, error) {
 		return nil, fmt.Errorf("plugin scan failed: %s (code: %d)", errorMsg, response.ErrorCode)
 	}
 
-	return PluginInfos(response.Plugins), nil
+	infos := withContentHashes(response.Plugins)
+	publish(Event{Kind: EventQuickScanCompleted, Count: len(infos), Duration: time.Since(start)})
+	return infos, nil
+}
+
+// withContentHashes sets ContentHash on each info via ComputeContentHash,
+// best-effort - a bundle that can't be read (e.g. a sandboxed path, or a
+// plugin removed between scan and now) just keeps an empty ContentHash,
+// the same as it would without this call.
+func withContentHashes(infos []PluginInfo) PluginInfos {
+	for i := range infos {
+		infos[i].ComputeContentHash()
+	}
+	return PluginInfos(infos)
+}
+
+// watchCancellation stores 1 into token the moment ctx is cancelled, so the
+// native scan loop polling token notices within one of its between-plugin
+// checks instead of running to SetTotalTimeout. It exits without touching
+// token once done is closed, so callers must close done on every return path
+// (including success) to avoid leaking the goroutine.
+func watchCancellation(ctx context.Context, token *int32, done <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+		atomic.StoreInt32(token, 1)
+	case <-done:
+	}
+}
+
+// ListWithContext is List, but aborts the scan within milliseconds of
+// ctx.Done() instead of waiting for SetTotalTimeout: a shared cancellation
+// token is polled by the native scan loop between plugin loads, mirroring
+// the ListPluginsWithContext pattern used elsewhere for long-running scans.
+func ListWithContext(ctx context.Context) (PluginInfos, error) {
+	publish(Event{Kind: EventQuickScanStarted})
+	start := time.Now()
+
+	var cancelToken int32
+	done := make(chan struct{})
+	go watchCancellation(ctx, &cancelToken, done)
+	defer close(done)
+
+	cPluginList := C.QuickScanAudioUnitsWithCancelToken((*C.int32_t)(unsafe.Pointer(&cancelToken)))
+	if cPluginList == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("plugin scan cancelled: %w", err)
+		}
+		return nil, fmt.Errorf("failed to scan AudioUnit plugins")
+	}
+	defer C.free(unsafe.Pointer(cPluginList))
+
+	jsonData := C.GoString(cPluginList)
+
+	logJSON("QuickScan", jsonData)
+
+	var response QuickScanResponse
+	if err := json.Unmarshal([]byte(jsonData), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin list data: %v", err)
+	}
+
+	if !response.Success {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("plugin scan cancelled: %w", err)
+		}
+		errorMsg := response.Error
+		if errorMsg == "" {
+			errorMsg = "unknown error"
+		}
+		return nil, fmt.Errorf("plugin scan failed: %s (code: %d)", errorMsg, response.ErrorCode)
+	}
+
+	infos := withContentHashes(response.Plugins)
+	publish(Event{Kind: EventQuickScanCompleted, Count: len(infos), Duration: time.Since(start)})
+	return infos, nil
 }
 
 // Filter methods for PluginInfos collection
@@ -237,6 +361,108 @@ func (infos PluginInfos) ByCategory(category string) PluginInfos {
 	return filtered
 }
 
+// NonDeprecated returns plugin infos whose IsDeprecated flag is false,
+// filtering out components the native layer identified as legacy v2-only AU
+// units.
+func (infos PluginInfos) NonDeprecated() PluginInfos {
+	var filtered PluginInfos
+	for _, info := range infos {
+		if !info.IsDeprecated {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+// ByVersion returns plugin infos whose Version satisfies semverConstraint, a
+// single comparison of the form "<op><major>.<minor>.<patch>" where op is
+// one of ">=", "<=", ">", "<", "==" or "=" (">=" is assumed when op is
+// omitted). Missing version components default to 0, and a plugin with an
+// empty or unparsable Version never matches.
+func (infos PluginInfos) ByVersion(semverConstraint string) PluginInfos {
+	op, want, ok := parseSemverConstraint(semverConstraint)
+	if !ok {
+		return nil
+	}
+
+	var filtered PluginInfos
+	for _, info := range infos {
+		got, ok := parseSemver(info.Version)
+		if !ok {
+			continue
+		}
+		if compareSemver(got, want, op) {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+// parseSemverConstraint splits semverConstraint into its comparison operator
+// and version. This is a minimal implementation covering the common single-
+// comparison case - it doesn't support ranges or the full semver spec (pre-
+// release/build metadata).
+func parseSemverConstraint(constraint string) (op string, version [3]int, ok bool) {
+	constraint = strings.TrimSpace(constraint)
+	for _, candidate := range []string{">=", "<=", "==", ">", "<", "="} {
+		if strings.HasPrefix(constraint, candidate) {
+			op = candidate
+			constraint = constraint[len(candidate):]
+			break
+		}
+	}
+	if op == "" {
+		op = ">="
+	}
+	version, ok = parseSemver(constraint)
+	return op, version, ok
+}
+
+// parseSemver parses a dot-separated "major.minor.patch" version, defaulting
+// missing trailing components to 0.
+func parseSemver(version string) (parts [3]int, ok bool) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return parts, false
+	}
+	segments := strings.SplitN(version, ".", 3)
+	for i, segment := range segments {
+		n, err := strconv.Atoi(strings.TrimSpace(segment))
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+func compareSemver(got, want [3]int, op string) bool {
+	cmp := 0
+	for i := 0; i < 3 && cmp == 0; i++ {
+		switch {
+		case got[i] < want[i]:
+			cmp = -1
+		case got[i] > want[i]:
+			cmp = 1
+		}
+	}
+
+	switch op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "==", "=":
+		return cmp == 0
+	default:
+		return false
+	}
+}
+
 // Filter methods for Plugins collection
 
 // ByManufacturer returns plugins from a specific manufacturer ID
@@ -449,6 +675,16 @@ func cStringOrNil(s string) *C.char {
 
 // introspect is the omnipotent internal function: name == "" ⇒ suite; name set ⇒ single
 func introspect(pluginType, subtype, manufacturerID, name string) ([]*Plugin, error) {
+	return introspectWithContext(context.Background(), pluginType, subtype, manufacturerID, name)
+}
+
+// introspectWithContext is introspect, but aborts early via a cancellation
+// token when ctx is done (see watchCancellation/ListWithContext).
+func introspectWithContext(ctx context.Context, pluginType, subtype, manufacturerID, name string) ([]*Plugin, error) {
+	triplet := PluginTriplet{Type: pluginType, Subtype: subtype, ManufacturerID: manufacturerID}
+	publish(Event{Kind: EventIntrospectStarted, Triplet: triplet})
+	start := time.Now()
+
 	cType := cStringOrNil(pluginType)
 	cSubtype := cStringOrNil(subtype)
 	cMan := cStringOrNil(manufacturerID)
@@ -467,8 +703,18 @@ func introspect(pluginType, subtype, manufacturerID, name string) ([]*Plugin, er
 		defer C.free(unsafe.Pointer(cName))
 	}
 
-	cResult := C.IntrospectAudioUnits(cType, cSubtype, cMan, cName)
+	var cancelToken int32
+	done := make(chan struct{})
+	go watchCancellation(ctx, &cancelToken, done)
+	defer close(done)
+
+	cResult := C.IntrospectAudioUnitsWithCancelToken(cType, cSubtype, cMan, cName, (*C.int32_t)(unsafe.Pointer(&cancelToken)))
 	if cResult == nil {
+		if err := ctx.Err(); err != nil {
+			publish(Event{Kind: EventIntrospectFailed, Triplet: triplet, Err: err.Error()})
+			return nil, fmt.Errorf("plugin introspection cancelled: %w", err)
+		}
+		publish(Event{Kind: EventIntrospectFailed, Triplet: triplet, Err: "failed to introspect plugins"})
 		return nil, fmt.Errorf("failed to introspect plugins")
 	}
 	defer C.free(unsafe.Pointer(cResult))
@@ -481,18 +727,32 @@ func introspect(pluginType, subtype, manufacturerID, name string) ([]*Plugin, er
 	// Parse JSON into pluginResult struct (like devices pattern)
 	var result pluginResult
 	if err := json.Unmarshal([]byte(jsonData), &result); err != nil {
+		publish(Event{Kind: EventIntrospectFailed, Triplet: triplet, Err: err.Error()})
 		return nil, fmt.Errorf("failed to parse plugin result data: %v", err)
 	}
 
 	// Check for success status (like devices pattern)
 	if !result.Success {
+		if err := ctx.Err(); err != nil {
+			publish(Event{Kind: EventIntrospectFailed, Triplet: triplet, Err: err.Error()})
+			return nil, fmt.Errorf("plugin introspection cancelled: %w", err)
+		}
 		errorMsg := result.Error
 		if errorMsg == "" {
 			errorMsg = "unknown error"
 		}
+		publish(Event{Kind: EventIntrospectFailed, Triplet: triplet, Err: errorMsg})
 		return nil, fmt.Errorf("plugin introspection failed: %s (code: %d)", errorMsg, result.ErrorCode)
 	}
 
+	for _, plugin := range result.Plugins {
+		plugin.ComputeContentHash()
+	}
+	paramCount := 0
+	for _, plugin := range result.Plugins {
+		paramCount += len(plugin.Parameters)
+	}
+	publish(Event{Kind: EventIntrospectCompleted, Triplet: triplet, ParamCount: paramCount, Duration: time.Since(start)})
 	return result.Plugins, nil
 }
 
@@ -501,6 +761,11 @@ func (pi PluginInfo) IntrospectSuite() ([]*Plugin, error) {
 	return introspect(pi.Type, pi.Subtype, pi.ManufacturerID, "")
 }
 
+// IntrospectSuiteWithContext is IntrospectSuite, but cancellable via ctx.
+func (pi PluginInfo) IntrospectSuiteWithContext(ctx context.Context) ([]*Plugin, error) {
+	return introspectWithContext(ctx, pi.Type, pi.Subtype, pi.ManufacturerID, "")
+}
+
 // Introspect returns exactly one plugin for the quadruplet; errors otherwise
 func (pi PluginInfo) Introspect() (*Plugin, error) {
 	results, err := introspect(pi.Type, pi.Subtype, pi.ManufacturerID, pi.Name)
@@ -514,6 +779,20 @@ func (pi PluginInfo) Introspect() (*Plugin, error) {
 	return results[0], nil
 }
 
+// IntrospectWithContext is Introspect, but cancellable via ctx - see
+// ListWithContext for how cancellation reaches the native scan loop.
+func (pi PluginInfo) IntrospectWithContext(ctx context.Context) (*Plugin, error) {
+	results, err := introspectWithContext(ctx, pi.Type, pi.Subtype, pi.ManufacturerID, pi.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("expected 1 plugin, got %d for %s:%s:%s:%s",
+			len(results), pi.Type, pi.Subtype, pi.ManufacturerID, pi.Name)
+	}
+	return results[0], nil
+}
+
 // Introspect method on PluginInfos - returns slice of Plugins
 // Introspect maps PluginInfo.Introspect() over the slice, returning fully
 // populated Plugin objects with parameter metadata. Fail-fast on first error.
@@ -528,3 +807,21 @@ func (infos PluginInfos) Introspect() ([]*Plugin, error) {
 	}
 	return allPlugins, nil
 }
+
+// IntrospectWithContext is Introspect, but cancellable via ctx. It checks
+// ctx.Err() before each plugin so a cancellation mid-batch stops issuing new
+// introspection calls instead of running the rest of the slice first.
+func (infos PluginInfos) IntrospectWithContext(ctx context.Context) ([]*Plugin, error) {
+	var allPlugins []*Plugin
+	for _, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("plugin batch introspection cancelled: %w", err)
+		}
+		plugin, err := info.IntrospectWithContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to introspect plugin %s: %v", info.Name, err)
+		}
+		allPlugins = append(allPlugins, plugin)
+	}
+	return allPlugins, nil
+}
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -149,6 +150,49 @@ func TestList(t *testing.T) {
 		}
 	}
 
+	// Test third-party/dev-build discovery via ListFromPath, using a
+	// synthetic bundle since this sandbox has no real .component on disk.
+	bundleDir := t.TempDir()
+	componentPath := filepath.Join(bundleDir, "Test.component")
+	if err := os.MkdirAll(filepath.Join(componentPath, "Contents"), 0755); err != nil {
+		t.Fatalf("failed to create synthetic bundle: %v", err)
+	}
+	plistContents := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>AudioComponents</key>
+	<array>
+		<dict>
+			<key>type</key>
+			<string>aufx</string>
+			<key>subtype</key>
+			<string>tst1</string>
+			<key>manufacturer</key>
+			<string>Test</string>
+			<key>name</key>
+			<string>Test: Synthetic Effect</string>
+		</dict>
+	</array>
+</dict>
+</plist>`
+	if err := os.WriteFile(filepath.Join(componentPath, "Contents", "Info.plist"), []byte(plistContents), 0644); err != nil {
+		t.Fatalf("failed to write synthetic Info.plist: %v", err)
+	}
+
+	externalInfos, err := ListFromPath(bundleDir)
+	if err != nil {
+		t.Fatalf("ListFromPath failed: %v", err)
+	}
+	if len(externalInfos) != 1 {
+		t.Fatalf("expected 1 plugin from synthetic bundle, got %d", len(externalInfos))
+	}
+	if externalInfos[0].SourcePath != componentPath {
+		t.Errorf("expected SourcePath %q, got %q", componentPath, externalInfos[0].SourcePath)
+	}
+	if externalInfos[0].Name != "Test: Synthetic Effect" {
+		t.Errorf("expected parsed name %q, got %q", "Test: Synthetic Effect", externalInfos[0].Name)
+	}
+
 	t.Log("✅ Quick plugin list test completed successfully!")
 }
 
@@ -288,3 +332,138 @@ func TestFullScanJSONToFile(t *testing.T) {
 
 	t.Logf("Full scan JSON written (%d bytes)", fi.Size())
 }
+
+func TestListWithContext(t *testing.T) {
+	t.Log("Testing cancellable plugin enumeration...")
+
+	t.Run("NormalCompletion", func(t *testing.T) {
+		pluginInfos, err := ListWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("ListWithContext failed: %v", err)
+		}
+		t.Logf("ListWithContext found %d plugins", len(pluginInfos))
+	})
+
+	t.Run("AlreadyCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := ListWithContext(ctx)
+		if err == nil {
+			t.Fatal("Expected error from an already-cancelled context")
+		}
+		t.Logf("ListWithContext correctly errored: %v", err)
+	})
+}
+
+func TestIntrospectWithContext(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	if len(pluginInfos) == 0 {
+		t.Skip("No plugins available for testing")
+	}
+
+	info := pluginInfos[0]
+
+	t.Run("SingleIntrospect", func(t *testing.T) {
+		plugin, err := info.IntrospectWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("IntrospectWithContext failed: %v", err)
+		}
+		if plugin.Name != info.Name {
+			t.Errorf("Expected plugin name %q, got %q", info.Name, plugin.Name)
+		}
+	})
+
+	t.Run("SuiteIntrospect", func(t *testing.T) {
+		suite, err := info.IntrospectSuiteWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("IntrospectSuiteWithContext failed: %v", err)
+		}
+		if len(suite) == 0 {
+			t.Fatal("Expected at least one plugin from IntrospectSuiteWithContext")
+		}
+	})
+
+	t.Run("AlreadyCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := info.IntrospectWithContext(ctx); err == nil {
+			t.Fatal("Expected error from an already-cancelled context")
+		}
+	})
+
+	t.Run("BatchIntrospect", func(t *testing.T) {
+		batch := pluginInfos
+		if len(batch) > 3 {
+			batch = batch[:3]
+		}
+
+		plugins, err := batch.IntrospectWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("PluginInfos.IntrospectWithContext failed: %v", err)
+		}
+		if len(plugins) != len(batch) {
+			t.Errorf("Expected %d introspected plugins, got %d", len(batch), len(plugins))
+		}
+	})
+
+	t.Run("BatchAlreadyCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := pluginInfos.IntrospectWithContext(ctx); err == nil {
+			t.Fatal("Expected error from an already-cancelled context")
+		}
+	})
+}
+
+func TestVersionAndDeprecationFilters(t *testing.T) {
+	infos := PluginInfos{
+		{Name: "Old Delay", Version: "1.0.0", IsDeprecated: true},
+		{Name: "New Delay", Version: "2.3.1", IsDeprecated: false},
+		{Name: "Unversioned Delay", IsDeprecated: false},
+	}
+
+	t.Run("NonDeprecated", func(t *testing.T) {
+		filtered := infos.NonDeprecated()
+		if len(filtered) != 2 {
+			t.Fatalf("Expected 2 non-deprecated plugins, got %d", len(filtered))
+		}
+		for _, info := range filtered {
+			if info.IsDeprecated {
+				t.Errorf("NonDeprecated returned a deprecated plugin: %s", info.Name)
+			}
+		}
+	})
+
+	t.Run("ByVersionMinimum", func(t *testing.T) {
+		filtered := infos.ByVersion(">=2.0.0")
+		if len(filtered) != 1 || filtered[0].Name != "New Delay" {
+			t.Fatalf("Expected only New Delay to satisfy >=2.0.0, got %+v", filtered)
+		}
+	})
+
+	t.Run("ByVersionExact", func(t *testing.T) {
+		filtered := infos.ByVersion("==1.0.0")
+		if len(filtered) != 1 || filtered[0].Name != "Old Delay" {
+			t.Fatalf("Expected only Old Delay to satisfy ==1.0.0, got %+v", filtered)
+		}
+	})
+
+	t.Run("ByVersionSkipsUnparsable", func(t *testing.T) {
+		filtered := infos.ByVersion(">=0.0.0")
+		if len(filtered) != 2 {
+			t.Fatalf("Expected the unversioned plugin to be excluded, got %d matches", len(filtered))
+		}
+	})
+
+	t.Run("ByVersionBadConstraint", func(t *testing.T) {
+		if filtered := infos.ByVersion("not-a-version"); filtered != nil {
+			t.Errorf("Expected nil for an unparsable constraint, got %+v", filtered)
+		}
+	})
+}
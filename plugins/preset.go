@@ -0,0 +1,152 @@
+//go:build darwin && cgo
+
+package plugins
+
+/*
+#include <stdlib.h>
+// Declared for CaptureClassInfo below; the native implementation doesn't
+// exist yet in this tree (see CaptureClassInfo's doc comment).
+char *CaptureAudioUnitClassInfo(const char *type, const char *subtype, const char *manufacturerID, const char *name);
+*/
+import "C"
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"unsafe"
+)
+
+// Preset captures a named, portable set of a Plugin's parameter values,
+// along with enough of the plugin's identity to tell what it was captured
+// from. Values is keyed by Parameter.Identifier rather than Address, the
+// same tradeoff ParameterSnapshot makes for PluginState (see state.go):
+// identifiers stay stable across a plugin update that renumbers addresses.
+type Preset struct {
+	Name           string             `json:"name"`
+	Type           string             `json:"type"`
+	Subtype        string             `json:"subtype"`
+	ManufacturerID string             `json:"manufacturerID"`
+	Version        string             `json:"version,omitempty"`
+	Values         map[string]float64 `json:"values"`
+	// ClassInfo is an optional opaque capture of the AU's full internal
+	// state (kAudioUnitProperty_ClassInfo), for plugins whose behavior
+	// isn't fully expressible as a flat parameter list (internal sample
+	// data, UI layout, etc). Populated by CaptureClassInfo; left nil if
+	// the caller never calls it.
+	ClassInfo []byte `json:"classInfo,omitempty"`
+}
+
+// SavePreset captures plugin's current parameter values
+// (Parameter.CurrentValue) into a named Preset. It doesn't populate
+// ClassInfo - call CaptureClassInfo separately and assign it to the
+// returned Preset if the plugin needs it.
+func (plugin Plugin) SavePreset(name string) (*Preset, error) {
+	values := make(map[string]float64, len(plugin.Parameters))
+	for _, param := range plugin.Parameters {
+		values[param.Identifier] = float64(param.CurrentValue)
+	}
+	return &Preset{
+		Name:           name,
+		Type:           plugin.Type,
+		Subtype:        plugin.Subtype,
+		ManufacturerID: plugin.ManufacturerID,
+		Version:        plugin.Version,
+		Values:         values,
+	}, nil
+}
+
+// ApplyPreset sets plugin's parameters (by Identifier) to preset's saved
+// values, in place. A preset entry whose identifier doesn't match any of
+// plugin's parameters is skipped rather than treated as an error - that's
+// the normal case for a global or per-type preset applied across plugins
+// that don't share every parameter (see PluginChain.LoadPresetBank in the
+// engine package for that cascade).
+func (plugin *Plugin) ApplyPreset(preset *Preset) error {
+	for i := range plugin.Parameters {
+		param := &plugin.Parameters[i]
+		value, ok := preset.Values[param.Identifier]
+		if !ok {
+			continue
+		}
+		v := float32(value)
+		if v < param.MinValue || v > param.MaxValue {
+			return fmt.Errorf("preset %q: value %v for parameter %q out of bounds [%v, %v]", preset.Name, v, param.Identifier, param.MinValue, param.MaxValue)
+		}
+		param.CurrentValue = v
+	}
+	return nil
+}
+
+// Save writes preset as JSON to path, creating or truncating the file.
+func (preset *Preset) Save(path string) error {
+	data, err := json.MarshalIndent(preset, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal preset: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write preset file %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadPreset reads and parses a Preset previously written by Preset.Save.
+func LoadPreset(path string) (*Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preset file %s: %w", path, err)
+	}
+	var preset Preset
+	if err := json.Unmarshal(data, &preset); err != nil {
+		return nil, fmt.Errorf("failed to parse preset file %s: %w", path, err)
+	}
+	return &preset, nil
+}
+
+// CaptureClassInfo asks the native layer for pi's full
+// kAudioUnitProperty_ClassInfo blob (base64-encoded on the native side,
+// decoded here), for embedding into a Preset.ClassInfo that needs more than
+// a flat parameter list to round-trip a plugin's state.
+//
+// The native implementation doesn't exist yet in this tree - this wires the
+// Go-side contract ahead of it, the same way ApplyState wires
+// ApplyParameterState in state.go.
+func (pi PluginInfo) CaptureClassInfo() ([]byte, error) {
+	cType := C.CString(pi.Type)
+	defer C.free(unsafe.Pointer(cType))
+	cSubtype := C.CString(pi.Subtype)
+	defer C.free(unsafe.Pointer(cSubtype))
+	cManufacturerID := C.CString(pi.ManufacturerID)
+	defer C.free(unsafe.Pointer(cManufacturerID))
+	cName := C.CString(pi.Name)
+	defer C.free(unsafe.Pointer(cName))
+
+	cResult := C.CaptureAudioUnitClassInfo(cType, cSubtype, cManufacturerID, cName)
+	if cResult == nil {
+		return nil, fmt.Errorf("failed to capture class info for plugin %s", pi.Name)
+	}
+	defer C.free(unsafe.Pointer(cResult))
+
+	var response struct {
+		Success bool   `json:"success"`
+		Data    string `json:"data,omitempty"` // base64-encoded ClassInfo blob
+		Error   string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(C.GoString(cResult)), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse class-info response: %v", err)
+	}
+	if !response.Success {
+		errMsg := response.Error
+		if errMsg == "" {
+			errMsg = "unknown error"
+		}
+		return nil, fmt.Errorf("capture class info failed: %s", errMsg)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(response.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode class-info payload: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,21 @@
+//go:build darwin && cgo
+
+package plugins
+
+import "context"
+
+// RebuildCache forces every plugin in infos to be re-introspected and
+// rewritten into cache, ignoring (and clearing) whatever is already stored
+// for it - including a prior Blacklist, since an explicit rebuild is the
+// user's signal that a previously-crashing AU deserves another chance.
+// Use Prewarm instead for the common "only introspect what's missing or
+// changed" path.
+func RebuildCache(ctx context.Context, cache Cache, infos PluginInfos, opts IntrospectOptions) (IntrospectReport, error) {
+	for _, info := range infos {
+		key := info.CacheKey()
+		_ = cache.Invalidate(key)
+		_ = cache.Unblacklist(key)
+	}
+	opts.Ctx = ctx
+	return Prewarm(ctx, cache, infos, opts)
+}
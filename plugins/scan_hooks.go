@@ -0,0 +1,192 @@
+//go:build darwin && cgo
+
+package plugins
+
+/*
+#include <stdlib.h>
+#include <stdint.h>
+
+// Declared for ListWithHooks below; the native implementation doesn't exist
+// yet in this tree (see ListWithHooks' doc comment). Once it does, the scan
+// loop calls back into Go via the goOnPluginDiscovered/goOnProgress
+// trampolines in this file (declared in the generated _cgo_export.h) once
+// per plugin, instead of only returning the full JSON envelope at the end.
+char *QuickScanAudioUnitsWithHooks(long long scanID, const int32_t *cancelToken);
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// ScanHooks lets a caller observe a scan or introspection batch as it
+// progresses, instead of only seeing the final slice or error. Every field
+// is optional; a nil hook is simply never called. Hooks invoked from
+// ListWithHooks are called by the native layer on its own thread, so a hook
+// that touches shared state must synchronize itself; hooks invoked from
+// IntrospectWithHooks are called synchronously on the calling goroutine.
+type ScanHooks struct {
+	OnPluginDiscovered   func(PluginInfo)
+	OnPluginIntrospected func(*Plugin)
+	OnPluginFailed       func(PluginInfo, error)
+	OnTimeout            func(PluginInfo, time.Duration)
+	OnProgress           func(done, total int)
+}
+
+// scanRegistry maps a scan ID to the hooks a live ListWithHooks call should
+// dispatch into. It exists because the native scan loop calls back into Go
+// through a plain C function pointer with no way to carry a Go closure, so
+// the callback is keyed by an integer scan ID instead.
+var (
+	scanRegistryMu sync.Mutex
+	scanRegistry   = make(map[int64]*ScanHooks)
+	nextScanID     int64
+)
+
+func registerScan(hooks *ScanHooks) int64 {
+	id := atomic.AddInt64(&nextScanID, 1)
+	scanRegistryMu.Lock()
+	scanRegistry[id] = hooks
+	scanRegistryMu.Unlock()
+	return id
+}
+
+func unregisterScan(id int64) {
+	scanRegistryMu.Lock()
+	delete(scanRegistry, id)
+	scanRegistryMu.Unlock()
+}
+
+func lookupScan(id int64) *ScanHooks {
+	scanRegistryMu.Lock()
+	hooks := scanRegistry[id]
+	scanRegistryMu.Unlock()
+	return hooks
+}
+
+//export goOnPluginDiscovered
+func goOnPluginDiscovered(scanID C.longlong, cJSON *C.char) {
+	hooks := lookupScan(int64(scanID))
+	if hooks == nil || hooks.OnPluginDiscovered == nil {
+		return
+	}
+	var info PluginInfo
+	if err := json.Unmarshal([]byte(C.GoString(cJSON)), &info); err == nil {
+		hooks.OnPluginDiscovered(info)
+	}
+}
+
+//export goOnScanProgress
+func goOnScanProgress(scanID C.longlong, done C.int, total C.int) {
+	hooks := lookupScan(int64(scanID))
+	if hooks == nil || hooks.OnProgress == nil {
+		return
+	}
+	hooks.OnProgress(int(done), int(total))
+}
+
+// ListWithHooks is ListWithContext, but additionally streams
+// hooks.OnPluginDiscovered and hooks.OnProgress as the native scan loop
+// finds each plugin, instead of only returning the final slice once the
+// whole catalog has been scanned.
+//
+// List's native call scans the entire catalog in one go and only returns
+// once it's done, so streaming per-plugin results requires the native layer
+// to call back into Go as it finds each one - QuickScanAudioUnitsWithHooks
+// is declared above as that entry point (keyed by a scan ID so the plain C
+// function pointer callback can find the right ScanHooks via the registry
+// in this file), calling goOnPluginDiscovered/goOnScanProgress once per
+// plugin. The native implementation doesn't exist yet in this tree; this
+// wires the Go side of that contract ahead of it, the same treatment the
+// context-cancellable scan APIs got.
+func ListWithHooks(ctx context.Context, hooks ScanHooks) (PluginInfos, error) {
+	scanID := registerScan(&hooks)
+	defer unregisterScan(scanID)
+
+	var cancelToken int32
+	done := make(chan struct{})
+	go watchCancellation(ctx, &cancelToken, done)
+	defer close(done)
+
+	cPluginList := C.QuickScanAudioUnitsWithHooks(C.longlong(scanID), (*C.int32_t)(unsafe.Pointer(&cancelToken)))
+	if cPluginList == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("plugin scan cancelled: %w", err)
+		}
+		return nil, fmt.Errorf("failed to scan AudioUnit plugins")
+	}
+	defer C.free(unsafe.Pointer(cPluginList))
+
+	jsonData := C.GoString(cPluginList)
+	logJSON("QuickScan", jsonData)
+
+	var response QuickScanResponse
+	if err := json.Unmarshal([]byte(jsonData), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin list data: %v", err)
+	}
+	if !response.Success {
+		errorMsg := response.Error
+		if errorMsg == "" {
+			errorMsg = "unknown error"
+		}
+		return nil, fmt.Errorf("plugin scan failed: %s (code: %d)", errorMsg, response.ErrorCode)
+	}
+
+	return PluginInfos(response.Plugins), nil
+}
+
+// IntrospectWithHooks introspects every info in infos, like
+// PluginInfos.IntrospectWithContext, but additionally invokes
+// hooks.OnPluginIntrospected on success, hooks.OnTimeout when ctx's deadline
+// was exceeded for that plugin, or hooks.OnPluginFailed for any other
+// failure, followed by hooks.OnProgress - all after each plugin rather than
+// waiting for the whole batch.
+//
+// Unlike IntrospectWithContext, introspecting each plugin is already a
+// single Go-side call per item (this package has no native batch-introspect
+// call to add a streaming callback to), so hooks are simply invoked inline
+// between iterations - no scan ID or native trampoline needed here. A
+// single plugin failing doesn't stop the batch; IntrospectWithHooks only
+// returns early if ctx itself is cancelled or exhausted before the batch
+// finishes.
+func (infos PluginInfos) IntrospectWithHooks(ctx context.Context, hooks ScanHooks) ([]*Plugin, error) {
+	total := len(infos)
+	start := time.Now()
+
+	var results []*Plugin
+	for i, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return results, fmt.Errorf("plugin batch introspection cancelled: %w", err)
+		}
+
+		plugin, err := info.IntrospectWithContext(ctx)
+		switch {
+		case err == nil:
+			results = append(results, plugin)
+			if hooks.OnPluginIntrospected != nil {
+				hooks.OnPluginIntrospected(plugin)
+			}
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			if hooks.OnTimeout != nil {
+				hooks.OnTimeout(info, time.Since(start))
+			}
+		default:
+			if hooks.OnPluginFailed != nil {
+				hooks.OnPluginFailed(info, err)
+			}
+		}
+
+		if hooks.OnProgress != nil {
+			hooks.OnProgress(i+1, total)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestIntrospectWithHooks(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	if len(pluginInfos) == 0 {
+		t.Skip("No plugins available for testing")
+	}
+
+	batch := pluginInfos
+	if len(batch) > 3 {
+		batch = batch[:3]
+	}
+
+	var mu sync.Mutex
+	var introspected, progressed int
+
+	results, err := batch.IntrospectWithHooks(context.Background(), ScanHooks{
+		OnPluginIntrospected: func(p *Plugin) {
+			mu.Lock()
+			introspected++
+			mu.Unlock()
+		},
+		OnProgress: func(done, total int) {
+			mu.Lock()
+			progressed++
+			mu.Unlock()
+			if done > total {
+				t.Errorf("progress done %d exceeds total %d", done, total)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("IntrospectWithHooks failed: %v", err)
+	}
+	if len(results) != len(batch) {
+		t.Errorf("Expected %d results, got %d", len(batch), len(results))
+	}
+	if introspected != len(batch) {
+		t.Errorf("Expected %d OnPluginIntrospected calls, got %d", len(batch), introspected)
+	}
+	if progressed != len(batch) {
+		t.Errorf("Expected %d OnProgress calls, got %d", len(batch), progressed)
+	}
+}
+
+func TestIntrospectWithHooksCancelledContext(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	if len(pluginInfos) == 0 {
+		t.Skip("No plugins available for testing")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := pluginInfos.IntrospectWithHooks(ctx, ScanHooks{}); err == nil {
+		t.Fatal("Expected error from an already-cancelled context")
+	}
+}
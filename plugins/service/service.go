@@ -0,0 +1,142 @@
+//go:build darwin && cgo
+
+// Package service exposes List and Introspect over HTTP, so a long-running
+// process can keep the native scanner state and any plugins.Cache warm
+// across many requests from hosts that can't link cgo directly (Electron
+// UIs, web editors, CI agents on a different machine).
+//
+// The originating request asked for a gRPC service (AudioUnitIntrospection,
+// with streaming List/Introspect methods) plus a REST gateway in front of
+// it, modeled on grpc-gateway. This module has no go.mod and no vendored
+// dependencies at all - not even grpc-go or a protoc toolchain - so there's
+// nothing to generate protobuf stubs against or a gRPC server to link.
+// Server below is the REST half of that design on net/http alone: each
+// handler maps onto the same context-aware List/Introspect calls a gRPC
+// method would delegate to, non-streaming (a full JSON array/object per
+// response instead of a stream of messages). Layering real gRPC in front of
+// the same plugins calls later is a dependency and protoc step away, not a
+// request-handling redesign.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// Server serves the AudioUnit catalog over HTTP: GET /v1/plugins for a quick
+// scan, GET /v1/plugins/{type}/{subtype}/{manufacturer}/{name} for
+// introspection of a single plugin (or, with ?suite=true, every plugin in
+// that type/subtype/manufacturer suite). A ?timeoutMs=N query parameter maps
+// onto the same context deadline ListWithContext/IntrospectWithContext
+// honor.
+type Server struct {
+	mux   *http.ServeMux
+	cache plugins.Cache // optional; nil means every introspect call is fresh
+}
+
+// NewServer builds a Server. cache may be nil, in which case introspection
+// always hits the native layer instead of consulting a plugins.Cache first.
+func NewServer(cache plugins.Cache) *Server {
+	s := &Server{cache: cache}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/plugins", s.handleList)
+	mux.HandleFunc("/v1/plugins/", s.handleIntrospect)
+	s.mux = mux
+	return s
+}
+
+// ServeHTTP implements http.Handler so a Server can be mounted directly on
+// an http.Server or behind any other net/http-compatible router.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx := r.Context()
+	if ms := r.URL.Query().Get("timeoutMs"); ms != "" {
+		if n, err := strconv.Atoi(ms); err == nil && n > 0 {
+			return context.WithTimeout(ctx, time.Duration(n)*time.Millisecond)
+		}
+	}
+	return ctx, func() {}
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
+	infos, err := plugins.ListWithContext(ctx)
+	if err != nil {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// handleIntrospect serves GET /v1/plugins/{type}/{subtype}/{manufacturer}/{name}.
+func (s *Server) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/plugins/"), "/"), "/")
+	if len(segments) != 4 {
+		http.Error(w, "expected /v1/plugins/{type}/{subtype}/{manufacturer}/{name}", http.StatusBadRequest)
+		return
+	}
+	pluginType, subtype, manufacturerID, name := segments[0], segments[1], segments[2], segments[3]
+
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
+	info := plugins.PluginInfo{Type: pluginType, Subtype: subtype, ManufacturerID: manufacturerID, Name: name}
+
+	if r.URL.Query().Get("suite") == "true" {
+		suite, err := info.IntrospectSuiteWithContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusGatewayTimeout, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, suite)
+		return
+	}
+
+	var (
+		plugin *plugins.Plugin
+		err    error
+	)
+	if s.cache != nil {
+		plugin, err = info.IntrospectCached(s.cache)
+	} else {
+		plugin, err = info.IntrospectWithContext(ctx)
+	}
+	if err != nil {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plugin)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,99 @@
+//go:build darwin && cgo
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func TestServerListAndIntrospect(t *testing.T) {
+	infos, err := plugins.List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	if len(infos) == 0 {
+		t.Skip("No plugins available for testing")
+	}
+
+	srv := httptest.NewServer(NewServer(nil))
+	defer srv.Close()
+
+	t.Run("List", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/v1/plugins")
+		if err != nil {
+			t.Fatalf("GET /v1/plugins failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var got plugins.PluginInfos
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(got) != len(infos) {
+			t.Errorf("Expected %d plugins, got %d", len(infos), len(got))
+		}
+	})
+
+	t.Run("Introspect", func(t *testing.T) {
+		info := infos[0]
+		url := srv.URL + "/v1/plugins/" + info.Type + "/" + info.Subtype + "/" + info.ManufacturerID + "/" + info.Name
+
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var got plugins.Plugin
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if got.Name != info.Name {
+			t.Errorf("Expected plugin name %q, got %q", info.Name, got.Name)
+		}
+	})
+
+	t.Run("IntrospectSuite", func(t *testing.T) {
+		info := infos[0]
+		url := srv.URL + "/v1/plugins/" + info.Type + "/" + info.Subtype + "/" + info.ManufacturerID + "/" + info.Name + "?suite=true"
+
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var got []*plugins.Plugin
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(got) == 0 {
+			t.Error("Expected at least one plugin from suite introspection")
+		}
+	})
+
+	t.Run("BadPath", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/v1/plugins/onlyonesegment")
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for a malformed path, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+//go:build darwin && cgo
+
+package plugins
+
+/*
+#include <stdlib.h>
+// Declared for ApplyState below; the native implementation doesn't exist
+// yet in this tree (see ApplyState's doc comment).
+char *ApplyParameterState(const char *type, const char *subtype, const char *manufacturerID, const char *name, const char *stateJSON);
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// ParameterSnapshot captures one parameter's value at a point in time, keyed
+// by Address (the fast, in-process lookup key) and Identifier (a fallback
+// for when a plugin update renumbers addresses but keeps identifiers
+// stable).
+type ParameterSnapshot struct {
+	Address    uint64  `json:"address"`
+	Identifier string  `json:"identifier"`
+	Value      float32 `json:"value"`
+}
+
+// PluginState is a portable capture of a plugin's parameter values, along
+// with enough identifying metadata to re-find the same plugin on another
+// host (Plugin) and to tell whether the host applying it differs from the
+// one that captured it (HostVersion).
+type PluginState struct {
+	Plugin      PluginInfo          `json:"plugin"`
+	Snapshot    []ParameterSnapshot `json:"snapshot"`
+	CapturedAt  time.Time           `json:"capturedAt"`
+	HostVersion string              `json:"hostVersion"`
+}
+
+// ParameterDelta is one parameter's difference between two PluginStates, as
+// returned by PluginState.Diff.
+type ParameterDelta struct {
+	Address    uint64  `json:"address"`
+	Identifier string  `json:"identifier"`
+	ValueA     float32 `json:"valueA"`
+	ValueB     float32 `json:"valueB"`
+}
+
+// CaptureState captures plugin's current parameter values
+// (Parameter.CurrentValue) into a portable PluginState. CapturedAt is set to
+// now; HostVersion is left for the caller to fill in, since this package has
+// no notion of the embedding application's version.
+func (plugin Plugin) CaptureState() PluginState {
+	snapshot := make([]ParameterSnapshot, 0, len(plugin.Parameters))
+	for _, param := range plugin.Parameters {
+		snapshot = append(snapshot, ParameterSnapshot{
+			Address:    param.Address,
+			Identifier: param.Identifier,
+			Value:      param.CurrentValue,
+		})
+	}
+
+	return PluginState{
+		Plugin: PluginInfo{
+			Name:           plugin.Name,
+			ManufacturerID: plugin.ManufacturerID,
+			Type:           plugin.Type,
+			Subtype:        plugin.Subtype,
+			Category:       plugin.Category,
+			BundlePath:     plugin.BundlePath,
+			Version:        plugin.Version,
+			BundleModTime:  plugin.BundleModTime,
+			IsSandboxSafe:  plugin.IsSandboxSafe,
+			IsDeprecated:   plugin.IsDeprecated,
+			HasCustomView:  plugin.HasCustomView,
+		},
+		Snapshot:   snapshot,
+		CapturedAt: time.Now(),
+	}
+}
+
+// Marshal serializes s as portable JSON, suitable for writing to disk or
+// sending to another host.
+func (s PluginState) Marshal() ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin state: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalPluginState parses a PluginState previously produced by
+// PluginState.Marshal.
+func UnmarshalPluginState(data []byte) (PluginState, error) {
+	var s PluginState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return PluginState{}, fmt.Errorf("failed to unmarshal plugin state: %w", err)
+	}
+	return s, nil
+}
+
+// Diff returns the per-parameter differences between s and other, matching
+// entries by Address first and falling back to Identifier for entries whose
+// address only appears on one side (e.g. after a plugin update renumbered
+// its parameters). Parameters present in only one state are not included -
+// there is no "other side" value to pair them with.
+func (s PluginState) Diff(other PluginState) []ParameterDelta {
+	byAddress := make(map[uint64]ParameterSnapshot, len(other.Snapshot))
+	byIdentifier := make(map[string]ParameterSnapshot, len(other.Snapshot))
+	for _, snap := range other.Snapshot {
+		byAddress[snap.Address] = snap
+		byIdentifier[snap.Identifier] = snap
+	}
+
+	matched := make(map[string]bool, len(other.Snapshot))
+	var deltas []ParameterDelta
+	for _, a := range s.Snapshot {
+		b, ok := byAddress[a.Address]
+		if !ok {
+			b, ok = byIdentifier[a.Identifier]
+		}
+		if !ok {
+			continue
+		}
+		matched[b.Identifier] = true
+		if a.Value == b.Value {
+			continue
+		}
+		deltas = append(deltas, ParameterDelta{
+			Address:    a.Address,
+			Identifier: a.Identifier,
+			ValueA:     a.Value,
+			ValueB:     b.Value,
+		})
+	}
+	return deltas
+}
+
+// ApplyState drives the native layer to set pi's parameters to the values
+// captured in s, addressed by ParameterSnapshot.Address and falling back to
+// Identifier when the address no longer resolves (important across plugin
+// version upgrades that renumber parameters).
+//
+// This package otherwise only ever instantiates a plugin transiently to read
+// its metadata (List/Introspect) - it has no persistent, controllable AU
+// instance the way avaudio/unit.Effect does. ApplyParameterState is declared
+// as the native entry point this needs: a one-shot call that loads the
+// plugin, applies every snapshot entry by address (falling back to
+// identifier), and tears the instance down again, mirroring how Introspect
+// itself is a load-then-discard call. The native implementation doesn't
+// exist yet in this tree - this wires the Go-side contract ahead of it, the
+// same way the context-cancellable scan APIs did.
+func (pi PluginInfo) ApplyState(s PluginState) error {
+	data, err := json.Marshal(s.Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameter snapshot: %w", err)
+	}
+
+	cType := C.CString(pi.Type)
+	defer C.free(unsafe.Pointer(cType))
+	cSubtype := C.CString(pi.Subtype)
+	defer C.free(unsafe.Pointer(cSubtype))
+	cManufacturerID := C.CString(pi.ManufacturerID)
+	defer C.free(unsafe.Pointer(cManufacturerID))
+	cName := C.CString(pi.Name)
+	defer C.free(unsafe.Pointer(cName))
+	cState := C.CString(string(data))
+	defer C.free(unsafe.Pointer(cState))
+
+	cResult := C.ApplyParameterState(cType, cSubtype, cManufacturerID, cName, cState)
+	if cResult == nil {
+		return fmt.Errorf("failed to apply parameter state for plugin %s", pi.Name)
+	}
+	defer C.free(unsafe.Pointer(cResult))
+
+	var response struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(C.GoString(cResult)), &response); err != nil {
+		return fmt.Errorf("failed to parse apply-state response: %v", err)
+	}
+	if !response.Success {
+		errMsg := response.Error
+		if errMsg == "" {
+			errMsg = "unknown error"
+		}
+		return fmt.Errorf("apply parameter state failed: %s", errMsg)
+	}
+	return nil
+}
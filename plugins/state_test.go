@@ -0,0 +1,81 @@
+package plugins
+
+import "testing"
+
+func TestPluginStateCaptureMarshalRoundTrip(t *testing.T) {
+	pluginInfos, err := List()
+	if err != nil {
+		t.Fatalf("Failed to get plugin list: %v", err)
+	}
+	effectInfos := pluginInfos.ByType("aufx").ByManufacturer("appl")
+	if len(effectInfos) == 0 {
+		t.Skip("No Apple AU effects found, skipping test")
+	}
+
+	plugin, err := effectInfos[0].Introspect()
+	if err != nil {
+		t.Fatalf("Introspect failed: %v", err)
+	}
+	if len(plugin.Parameters) == 0 {
+		t.Skip("Test effect has no parameters to capture")
+	}
+
+	state := plugin.CaptureState()
+	if len(state.Snapshot) != len(plugin.Parameters) {
+		t.Fatalf("Expected %d snapshot entries, got %d", len(plugin.Parameters), len(state.Snapshot))
+	}
+	if state.Plugin.Name != plugin.Name {
+		t.Errorf("Expected captured plugin name %q, got %q", plugin.Name, state.Plugin.Name)
+	}
+
+	data, err := state.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	restored, err := UnmarshalPluginState(data)
+	if err != nil {
+		t.Fatalf("UnmarshalPluginState failed: %v", err)
+	}
+	if restored.Plugin.Name != state.Plugin.Name || len(restored.Snapshot) != len(state.Snapshot) {
+		t.Errorf("Expected round-tripped state to match original, got %+v", restored)
+	}
+}
+
+func TestPluginStateDiff(t *testing.T) {
+	a := PluginState{
+		Snapshot: []ParameterSnapshot{
+			{Address: 1, Identifier: "gain", Value: 0.5},
+			{Address: 2, Identifier: "mix", Value: 1.0},
+		},
+	}
+	b := PluginState{
+		Snapshot: []ParameterSnapshot{
+			{Address: 1, Identifier: "gain", Value: 0.8},
+			{Address: 2, Identifier: "mix", Value: 1.0},
+		},
+	}
+
+	deltas := a.Diff(b)
+	if len(deltas) != 1 {
+		t.Fatalf("Expected 1 delta, got %d: %+v", len(deltas), deltas)
+	}
+	if deltas[0].Identifier != "gain" || deltas[0].ValueA != 0.5 || deltas[0].ValueB != 0.8 {
+		t.Errorf("Unexpected delta: %+v", deltas[0])
+	}
+}
+
+func TestPluginStateDiffFallsBackToIdentifier(t *testing.T) {
+	a := PluginState{
+		Snapshot: []ParameterSnapshot{{Address: 1, Identifier: "gain", Value: 0.5}},
+	}
+	b := PluginState{
+		// Address renumbered by a plugin update, identifier unchanged.
+		Snapshot: []ParameterSnapshot{{Address: 99, Identifier: "gain", Value: 0.9}},
+	}
+
+	deltas := a.Diff(b)
+	if len(deltas) != 1 || deltas[0].ValueB != 0.9 {
+		t.Fatalf("Expected identifier fallback to find a delta, got %+v", deltas)
+	}
+}
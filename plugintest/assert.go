@@ -0,0 +1,64 @@
+package plugintest
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio"
+)
+
+// AssertParameterApplied fails t if instance's current value for name
+// differs from want by more than tolerance, or if name isn't set at all.
+func AssertParameterApplied(t *testing.T, instance *macaudio.PluginInstance, name string, want, tolerance float32) {
+	t.Helper()
+	got, ok := instance.GetParameter(name)
+	if !ok {
+		t.Fatalf("parameter %q not set on instance %s", name, instance.ID)
+	}
+	if diff := got - want; diff < -tolerance || diff > tolerance {
+		t.Fatalf("parameter %q on instance %s = %v, want %v (tolerance %v)", name, instance.ID, got, want, tolerance)
+	}
+}
+
+// AssertOrder fails t unless chain's instances are in exactly the order of
+// wantIDs.
+func AssertOrder(t *testing.T, chain *macaudio.PluginChain, wantIDs []string) {
+	t.Helper()
+	instances := chain.GetInstances()
+	if len(instances) != len(wantIDs) {
+		t.Fatalf("chain has %d instances, want %d (%v)", len(instances), len(wantIDs), wantIDs)
+	}
+	for i, instance := range instances {
+		if instance.ID != wantIDs[i] {
+			t.Fatalf("instance at position %d is %s, want %s", i, instance.ID, wantIDs[i])
+		}
+	}
+}
+
+// AssertBypassedWhenInactive runs chain against in twice - once as-is, once
+// with instanceID forced bypassed via SetBypass - and fails t unless
+// bypassing it makes RunBuffers return in unchanged. This only holds for a
+// chain whose one relevant effect is instanceID (any plugin ahead of or
+// after it that also mutates the signal would make the comparison
+// meaningless), which matches the common case of testing one fake plugin's
+// bypass behavior in isolation.
+func AssertBypassedWhenInactive(t *testing.T, h *Harness, chain *macaudio.PluginChain, instanceID string, in []float32) {
+	t.Helper()
+
+	if err := chain.SetBypass(instanceID, true); err != nil {
+		t.Fatalf("SetBypass(%s, true): %v", instanceID, err)
+	}
+	defer chain.SetBypass(instanceID, false)
+
+	out, err := RunBuffers(h, chain, in)
+	if err != nil {
+		t.Fatalf("RunBuffers while bypassed: %v", err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("bypassed output length %d, want %d", len(out), len(in))
+	}
+	for i := range in {
+		if out[i] != in[i] {
+			t.Fatalf("bypassed output differs from input at sample %d: got %v, want %v", i, out[i], in[i])
+		}
+	}
+}
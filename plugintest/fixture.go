@@ -0,0 +1,134 @@
+package plugintest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaban/macaudio"
+)
+
+// LoadWAV reads a PCM16 or IEEE-float32 WAV file into [-1, 1]-range
+// float32 samples, interleaved by channel the way the file stores them.
+// It's deliberately minimal - just enough to read golden fixtures RunExamples
+// compares against, not a general-purpose WAV decoder (avengine.AudioFile,
+// used elsewhere in this module, covers that via AVFoundation; this exists
+// so plugintest stays dependency-free and hermetic).
+func LoadWAV(path string) (samples []float32, sampleRate int, channels int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("plugintest: read %s: %w", path, err)
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, 0, fmt.Errorf("plugintest: %s is not a RIFF/WAVE file", path)
+	}
+
+	var formatTag, bitsPerSample uint16
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkID := string(data[offset : offset+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		if body+chunkSize > len(data) {
+			break
+		}
+
+		switch chunkID {
+		case "fmt ":
+			formatTag = binary.LittleEndian.Uint16(data[body : body+2])
+			channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			sampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			bitsPerSample = binary.LittleEndian.Uint16(data[body+14 : body+16])
+		case "data":
+			samples, err = decodePCM(data[body:body+chunkSize], formatTag, bitsPerSample)
+			if err != nil {
+				return nil, 0, 0, fmt.Errorf("plugintest: %s: %w", path, err)
+			}
+		}
+
+		offset = body + chunkSize
+		if chunkSize%2 == 1 {
+			offset++ // chunks are word-aligned
+		}
+	}
+
+	if samples == nil {
+		return nil, 0, 0, fmt.Errorf("plugintest: %s has no data chunk", path)
+	}
+	return samples, sampleRate, channels, nil
+}
+
+const (
+	wavFormatPCM   = 1
+	wavFormatFloat = 3
+)
+
+func decodePCM(raw []byte, formatTag, bitsPerSample uint16) ([]float32, error) {
+	switch {
+	case formatTag == wavFormatPCM && bitsPerSample == 16:
+		out := make([]float32, len(raw)/2)
+		for i := range out {
+			v := int16(binary.LittleEndian.Uint16(raw[i*2 : i*2+2]))
+			out[i] = float32(v) / 32768.0
+		}
+		return out, nil
+	case formatTag == wavFormatFloat && bitsPerSample == 32:
+		out := make([]float32, len(raw)/4)
+		for i := range out {
+			out[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4 : i*4+4]))
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported WAV format (tag=%d, bits=%d)", formatTag, bitsPerSample)
+	}
+}
+
+// RunExamples runs in through chain once per Preset declared on
+// instanceID's registered FakePlugin, applying the preset's parameters
+// first, and fails t unless the result matches fixtureDir/<preset.Name>.wav
+// within tolerance sample-by-sample.
+func RunExamples(t *testing.T, h *Harness, chain *macaudio.PluginChain, instanceID string, in []float32, fixtureDir string, tolerance float32) {
+	t.Helper()
+
+	instance, ok := chain.GetInstance(instanceID)
+	if !ok {
+		t.Fatalf("no instance %s in chain", instanceID)
+	}
+	fp, ok := h.Lookup(instance.Blueprint)
+	if !ok {
+		t.Fatalf("no FakePlugin registered for instance %s (%s)", instanceID, instance.Blueprint.Name)
+	}
+
+	for _, preset := range fp.Presets {
+		preset := preset
+		t.Run(preset.Name, func(t *testing.T) {
+			for name, value := range preset.Parameters {
+				if err := instance.SetParameter(name, value); err != nil {
+					t.Fatalf("SetParameter(%q, %v): %v", name, value, err)
+				}
+			}
+
+			got, err := RunBuffers(h, chain, in)
+			if err != nil {
+				t.Fatalf("RunBuffers: %v", err)
+			}
+
+			want, _, _, err := LoadWAV(filepath.Join(fixtureDir, preset.Name+".wav"))
+			if err != nil {
+				t.Fatalf("loading golden fixture: %v", err)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("output length %d, fixture length %d", len(got), len(want))
+			}
+			for i := range got {
+				if diff := got[i] - want[i]; diff < -tolerance || diff > tolerance {
+					t.Fatalf("sample %d = %v, fixture has %v (tolerance %v)", i, got[i], want[i], tolerance)
+				}
+			}
+		})
+	}
+}
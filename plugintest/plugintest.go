@@ -0,0 +1,174 @@
+// Package plugintest lets a test drive a macaudio.PluginChain without a
+// real installed AudioUnit: register a FakePlugin backed by a pure-Go
+// ProcessFunc in a Harness, Install it on a chain (wiring it in as the
+// chain's macaudio.Introspector), and AddPlugin/Load succeeds against the
+// fake metadata instead of failing or needing hardware. RunBuffers then
+// runs a buffer through the chain's fakes deterministically, and the
+// Assert* helpers check the usual things a real chain's behavior should
+// satisfy - parameter application, effect order, bypass - without a
+// subprocess or any native call, so these tests are fast and hermetic.
+//
+// State round-trips exactly the way a real chain's does: GetState/SetState
+// still serialize to the same JSON: a FakePlugin only changes how Load
+// resolves metadata, not what PluginChain persists.
+package plugintest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shaban/macaudio"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// ParameterSpec declares one parameter a FakePlugin exposes, enough of
+// plugins.Parameter's fields for Harness.Introspect to synthesize a
+// plugins.Plugin that looks like a real introspection result.
+type ParameterSpec struct {
+	Identifier string
+	Min        float32
+	Max        float32
+	Default    float32
+}
+
+// Preset names a set of parameter values RunExamples should apply and
+// check against a golden fixture (see RunExamples).
+type Preset struct {
+	Name       string
+	Parameters map[string]float32
+}
+
+// ProcessCtx is handed to a ProcessFunc on every RunBuffers call: the
+// engine format the owning chain was set up with (see
+// macaudio.Engine.AudioFormat) and a snapshot of the instance's current
+// parameter values.
+type ProcessCtx struct {
+	SampleRate float64
+	BufferSize int
+	Parameters map[string]float32
+}
+
+// ProcessFunc is a fake plugin's entire audio behavior: read in, write len
+// (in) samples of processed audio to out. Called once per RunBuffers per
+// active (non-bypassed) instance in chain order.
+type ProcessFunc func(in, out []float32, ctx ProcessCtx)
+
+// FakePlugin is a test double for one plugin: Blueprint identifies it the
+// same way a real plugin would (Type/Subtype/ManufacturerID/Name), Process
+// is its audio behavior, Parameters/Presets describe what RunExamples
+// should exercise.
+type FakePlugin struct {
+	Blueprint  macaudio.PluginBlueprint
+	Process    ProcessFunc
+	Parameters []ParameterSpec
+	Presets    []Preset
+}
+
+type blueprintKey struct {
+	Type, Subtype, ManufacturerID, Name string
+}
+
+func keyOf(b macaudio.PluginBlueprint) blueprintKey {
+	return blueprintKey{Type: b.Type, Subtype: b.Subtype, ManufacturerID: b.ManufacturerID, Name: b.Name}
+}
+
+// Harness is a registry of FakePlugins and a macaudio.Introspector over
+// them. Install wires it into a PluginChain so AddPlugin/Load resolves
+// registered blueprints against the fakes instead of a real AudioUnit.
+type Harness struct {
+	mu    sync.RWMutex
+	fakes map[blueprintKey]FakePlugin
+}
+
+// NewHarness creates an empty Harness.
+func NewHarness() *Harness {
+	return &Harness{fakes: make(map[blueprintKey]FakePlugin)}
+}
+
+// Register adds fp to the harness, keyed by its Blueprint's
+// Type/Subtype/ManufacturerID/Name. Registering the same blueprint twice
+// replaces the earlier registration.
+func (h *Harness) Register(fp FakePlugin) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fakes[keyOf(fp.Blueprint)] = fp
+}
+
+// Install sets h as chain's Introspector (see macaudio.PluginChain.
+// SetIntrospector), so every instance Load call on chain resolves against
+// h's registered fakes from here on. Call it before adding any plugins to
+// chain - SetIntrospector's own doc comment explains why.
+func (h *Harness) Install(chain *macaudio.PluginChain) {
+	chain.SetIntrospector(h)
+}
+
+// Introspect implements macaudio.Introspector: it looks up blueprint among
+// h's registered fakes and returns a synthesized plugins.Plugin built from
+// the fake's declared Parameters, or an error if nothing was registered
+// for it - mirroring how a real Introspect fails for an uninstalled
+// plugin.
+func (h *Harness) Introspect(blueprint macaudio.PluginBlueprint) (*plugins.Plugin, error) {
+	h.mu.RLock()
+	fp, ok := h.fakes[keyOf(blueprint)]
+	h.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("plugintest: no FakePlugin registered for %s/%s/%s/%s",
+			blueprint.Type, blueprint.Subtype, blueprint.ManufacturerID, blueprint.Name)
+	}
+
+	params := make([]plugins.Parameter, len(fp.Parameters))
+	for i, p := range fp.Parameters {
+		params[i] = plugins.Parameter{
+			DisplayName:  p.Identifier,
+			Identifier:   p.Identifier,
+			MinValue:     p.Min,
+			MaxValue:     p.Max,
+			DefaultValue: p.Default,
+			CurrentValue: p.Default,
+			IsWritable:   true,
+		}
+	}
+
+	return &plugins.Plugin{
+		Name:           blueprint.Name,
+		ManufacturerID: blueprint.ManufacturerID,
+		Type:           blueprint.Type,
+		Subtype:        blueprint.Subtype,
+		Parameters:     params,
+	}, nil
+}
+
+// Resolve implements macaudio.PluginResolver by converting info into a
+// PluginBlueprint and delegating to Introspect, so a Harness can drive
+// macaudio.PluginChain.LoadWithResolver/LoadPreset against fakes the same
+// way it already drives AddPlugin/Load as an Introspector.
+func (h *Harness) Resolve(info plugins.PluginInfo) (*plugins.Plugin, error) {
+	return h.Introspect(macaudio.PluginBlueprint{
+		Type:           info.Type,
+		Subtype:        info.Subtype,
+		ManufacturerID: info.ManufacturerID,
+		Name:           info.Name,
+	})
+}
+
+// Lookup returns the FakePlugin registered for blueprint, for callers (like
+// RunExamples) that need its declared Presets, not just its Process func.
+func (h *Harness) Lookup(blueprint macaudio.PluginBlueprint) (FakePlugin, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	fp, ok := h.fakes[keyOf(blueprint)]
+	return fp, ok
+}
+
+// processorFor returns the ProcessFunc registered for blueprint, or false
+// if nothing matches - used by RunBuffers, which needs the fake's audio
+// behavior rather than its metadata.
+func (h *Harness) processorFor(blueprint macaudio.PluginBlueprint) (ProcessFunc, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	fp, ok := h.fakes[keyOf(blueprint)]
+	if !ok || fp.Process == nil {
+		return nil, false
+	}
+	return fp.Process, true
+}
@@ -0,0 +1,283 @@
+package plugintest
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaban/macaudio"
+)
+
+func gainBlueprint() macaudio.PluginBlueprint {
+	return macaudio.PluginBlueprint{
+		Type:           "aufx",
+		Subtype:        "gain",
+		ManufacturerID: "test",
+		Name:           "Test Gain",
+	}
+}
+
+func gainFakePlugin() FakePlugin {
+	return FakePlugin{
+		Blueprint: gainBlueprint(),
+		Parameters: []ParameterSpec{
+			{Identifier: "gain", Min: 0, Max: 2, Default: 1},
+		},
+		Process: func(in, out []float32, ctx ProcessCtx) {
+			gain := ctx.Parameters["gain"]
+			for i, v := range in {
+				out[i] = v * gain
+			}
+		},
+	}
+}
+
+func TestHarnessIntrospectUnregisteredBlueprintFails(t *testing.T) {
+	h := NewHarness()
+	if _, err := h.Introspect(gainBlueprint()); err == nil {
+		t.Fatal("expected error introspecting an unregistered blueprint")
+	}
+}
+
+func TestRunBuffersAppliesFakeProcessing(t *testing.T) {
+	h := NewHarness()
+	h.Register(gainFakePlugin())
+
+	chain := macaudio.NewPluginChain()
+	h.Install(chain)
+
+	instance, err := chain.AddPlugin(gainBlueprint(), 0)
+	if err != nil {
+		t.Fatalf("AddPlugin: %v", err)
+	}
+	if err := instance.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := instance.SetParameter("gain", 0.5); err != nil {
+		t.Fatalf("SetParameter: %v", err)
+	}
+
+	in := []float32{1, 1, 1, 1}
+	out, err := RunBuffers(h, chain, in)
+	if err != nil {
+		t.Fatalf("RunBuffers: %v", err)
+	}
+	for i, v := range out {
+		if v != 0.5 {
+			t.Fatalf("sample %d = %v, want 0.5", i, v)
+		}
+	}
+
+	AssertParameterApplied(t, instance, "gain", 0.5, 0.001)
+	AssertOrder(t, chain, []string{instance.ID})
+	AssertBypassedWhenInactive(t, h, chain, instance.ID, in)
+}
+
+func TestRunBuffersMissingProcessFuncErrors(t *testing.T) {
+	h := NewHarness()
+	// Registered so Introspect (and so Load/AddPlugin) succeeds, but with no
+	// Process func - RunBuffers should refuse to run it rather than pass
+	// the buffer through silently.
+	h.Register(FakePlugin{Blueprint: gainBlueprint()})
+
+	chain := macaudio.NewPluginChain()
+	h.Install(chain)
+	instance, err := chain.AddPlugin(gainBlueprint(), 0)
+	if err != nil {
+		t.Fatalf("AddPlugin: %v", err)
+	}
+
+	if _, err := RunBuffers(h, chain, []float32{1, 1}); err == nil {
+		t.Fatalf("expected RunBuffers to error for instance %s with no Process func", instance.ID)
+	}
+}
+
+// writeWAV writes a minimal PCM16 mono WAV file, just enough for LoadWAV to
+// read back - the inverse of decodePCM's PCM16 branch.
+func writeWAV(t *testing.T, path string, samples []float32, sampleRate int) {
+	t.Helper()
+
+	data := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(s*32768)))
+	}
+
+	var buf []byte
+	buf = append(buf, "RIFF"...)
+	buf = append(buf, littleEndianUint32(uint32(36+len(data)))...)
+	buf = append(buf, "WAVE"...)
+
+	buf = append(buf, "fmt "...)
+	buf = append(buf, littleEndianUint32(16)...)
+	buf = append(buf, littleEndianUint16(1)...) // PCM
+	buf = append(buf, littleEndianUint16(1)...) // mono
+	buf = append(buf, littleEndianUint32(uint32(sampleRate))...)
+	byteRate := uint32(sampleRate * 2)
+	buf = append(buf, littleEndianUint32(byteRate)...)
+	buf = append(buf, littleEndianUint16(2)...)  // block align
+	buf = append(buf, littleEndianUint16(16)...) // bits per sample
+
+	buf = append(buf, "data"...)
+	buf = append(buf, littleEndianUint32(uint32(len(data)))...)
+	buf = append(buf, data...)
+
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		t.Fatalf("writing fixture WAV: %v", err)
+	}
+}
+
+func littleEndianUint32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func littleEndianUint16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+
+func TestLoadWAVRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fixture.wav")
+	want := []float32{0, 0.25, -0.5, 0.75}
+	writeWAV(t, path, want, 44100)
+
+	got, sampleRate, channels, err := LoadWAV(path)
+	if err != nil {
+		t.Fatalf("LoadWAV: %v", err)
+	}
+	if sampleRate != 44100 || channels != 1 {
+		t.Fatalf("sampleRate/channels = %d/%d, want 44100/1", sampleRate, channels)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d samples, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if diff := got[i] - want[i]; diff < -0.001 || diff > 0.001 {
+			t.Fatalf("sample %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRunExamplesComparesAgainstFixture(t *testing.T) {
+	h := NewHarness()
+	fp := gainFakePlugin()
+	fp.Presets = []Preset{
+		{Name: "half", Parameters: map[string]float32{"gain": 0.5}},
+	}
+	h.Register(fp)
+
+	chain := macaudio.NewPluginChain()
+	h.Install(chain)
+	instance, err := chain.AddPlugin(gainBlueprint(), 0)
+	if err != nil {
+		t.Fatalf("AddPlugin: %v", err)
+	}
+	if err := instance.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	in := []float32{1, 1, 1, 1}
+	dir := t.TempDir()
+	writeWAV(t, filepath.Join(dir, "half.wav"), []float32{0.5, 0.5, 0.5, 0.5}, 44100)
+
+	RunExamples(t, h, chain, instance.ID, in, dir, 0.001)
+}
+
+func TestDecodePCMRejectsUnsupportedFormat(t *testing.T) {
+	if _, err := decodePCM([]byte{0, 0}, 99, 16); err == nil {
+		t.Fatal("expected error decoding an unsupported WAV format")
+	}
+}
+
+func TestDecodePCMFloat32(t *testing.T) {
+	raw := make([]byte, 4)
+	binary.LittleEndian.PutUint32(raw, math.Float32bits(0.25))
+	out, err := decodePCM(raw, wavFormatFloat, 32)
+	if err != nil {
+		t.Fatalf("decodePCM: %v", err)
+	}
+	if len(out) != 1 || out[0] != 0.25 {
+		t.Fatalf("got %v, want [0.25]", out)
+	}
+}
+
+func TestSavePresetLoadPresetRoundTrips(t *testing.T) {
+	h := NewHarness()
+	h.Register(gainFakePlugin())
+
+	chain := macaudio.NewPluginChain()
+	h.Install(chain)
+	instance, err := chain.AddPlugin(gainBlueprint(), 0)
+	if err != nil {
+		t.Fatalf("AddPlugin: %v", err)
+	}
+	if err := instance.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := instance.SetParameter("gain", 0.5); err != nil {
+		t.Fatalf("SetParameter: %v", err)
+	}
+	instance.SetClassInfo([]byte("fake-classinfo"))
+
+	path := filepath.Join(t.TempDir(), "chain.json")
+	if err := chain.SavePreset(path); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+
+	loaded := macaudio.NewPluginChain()
+	h.Install(loaded)
+	if err := loaded.LoadPreset(path, h, macaudio.MissingPluginFail); err != nil {
+		t.Fatalf("LoadPreset: %v", err)
+	}
+
+	instances := loaded.GetInstances()
+	if len(instances) != 1 {
+		t.Fatalf("got %d instances, want 1", len(instances))
+	}
+	restored := instances[0]
+	if v, ok := restored.GetParameter("gain"); !ok || v != 0.5 {
+		t.Fatalf("restored gain = %v, %v; want 0.5, true", v, ok)
+	}
+	if got := string(restored.GetClassInfo()); got != "fake-classinfo" {
+		t.Fatalf("restored ClassInfo = %q, want %q", got, "fake-classinfo")
+	}
+}
+
+func TestLoadPresetMissingPluginRespectsPolicy(t *testing.T) {
+	h := NewHarness()
+	h.Register(gainFakePlugin())
+
+	chain := macaudio.NewPluginChain()
+	h.Install(chain)
+	if _, err := chain.AddPlugin(gainBlueprint(), 0); err != nil {
+		t.Fatalf("AddPlugin: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "chain.json")
+	if err := chain.SavePreset(path); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+
+	// A harness with nothing registered stands in for a machine missing
+	// the plugin entirely.
+	emptyHarness := NewHarness()
+	strict := macaudio.NewPluginChain()
+	emptyHarness.Install(strict)
+	if err := strict.LoadPreset(path, emptyHarness, macaudio.MissingPluginFail); err == nil {
+		t.Fatal("expected MissingPluginFail to error on an unresolvable plugin")
+	}
+
+	lenient := macaudio.NewPluginChain()
+	emptyHarness.Install(lenient)
+	if err := lenient.LoadPreset(path, emptyHarness, macaudio.MissingPluginSkip); err != nil {
+		t.Fatalf("MissingPluginSkip: %v", err)
+	}
+	if got := lenient.GetInstances(); len(got) != 0 {
+		t.Fatalf("got %d instances after MissingPluginSkip, want 0", len(got))
+	}
+}
@@ -0,0 +1,42 @@
+package plugintest
+
+import (
+	"fmt"
+
+	"github.com/shaban/macaudio"
+)
+
+// RunBuffers runs in through every instance in chain, in chain order,
+// skipping any instance whose IsActive is false (bypassed) the same way a
+// real bypassed AudioUnit would pass audio through unchanged. Each active
+// instance must have a FakePlugin registered in h with it - this is a test
+// harness, not a fallback host, so an unregistered instance is an error
+// rather than a silent passthrough.
+func RunBuffers(h *Harness, chain *macaudio.PluginChain, in []float32) ([]float32, error) {
+	sampleRate, bufferSize := chain.AudioFormat()
+
+	buf := make([]float32, len(in))
+	copy(buf, in)
+
+	for _, instance := range chain.GetInstances() {
+		if !instance.IsActive {
+			continue
+		}
+
+		process, ok := h.processorFor(instance.Blueprint)
+		if !ok {
+			return nil, fmt.Errorf("plugintest: instance %s (%s): no FakePlugin registered",
+				instance.ID, instance.Blueprint.Name)
+		}
+
+		out := make([]float32, len(buf))
+		process(buf, out, ProcessCtx{
+			SampleRate: sampleRate,
+			BufferSize: bufferSize,
+			Parameters: instance.GetState().Parameters,
+		})
+		buf = out
+	}
+
+	return buf, nil
+}
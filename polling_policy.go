@@ -0,0 +1,122 @@
+package macaudio
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// PollingStats is the performance/activity snapshot a PollingPolicy sees
+// on every call to NextInterval, and what GetPollingStats returns to
+// callers (e.g. the adaptive_test example) wanting to report more than
+// just an average.
+type PollingStats struct {
+	AvgCheckTime    time.Duration
+	MaxCheckTime    time.Duration
+	CheckCount      int64
+	CurrentInterval time.Duration
+
+	// EventCounts tallies how many DeviceChangeEvents of each Kind have
+	// been observed since the monitor started, so callers can tell which
+	// device class (audio add/remove, MIDI add/remove, default-device
+	// change) triggered the most recent backoff reset.
+	EventCounts map[devices.DeviceChangeKind]int64
+}
+
+// PollingPolicy computes the next polling interval given the monitor's
+// running stats and the most recently observed device event (its zero
+// value, devices.DeviceChangeEvent{}, means no event has fired yet).
+// DeviceMonitor calls NextInterval after every devices.Subscribe event
+// and stores the result as CurrentInterval; see SetPollingPolicy.
+type PollingPolicy interface {
+	NextInterval(stats PollingStats, lastEvent devices.DeviceChangeEvent) time.Duration
+}
+
+// DefaultPollingPolicy is an exponential-backoff-with-jitter
+// PollingPolicy: an add/remove/default-change event halves the interval
+// and holds it there for BackoffCycles calls, quiescence grows it by 1.5x
+// per call up to MaxInterval, and MaxCPUPercent (if set) overrides both -
+// forcibly extending the interval, even right after an event, whenever
+// AvgCheckTime would otherwise consume more than that percentage of it.
+//
+// A DefaultPollingPolicy's NextInterval is called from DeviceMonitor's
+// single watchSubscription goroutine, so the mutex only guards against a
+// caller also reading stats concurrently via GetPollingStats - it isn't
+// protecting against concurrent NextInterval calls.
+type DefaultPollingPolicy struct {
+	BaseInterval  time.Duration // floor the halved interval won't go below
+	MaxInterval   time.Duration // ceiling quiescent growth won't exceed
+	BackoffCycles int           // calls spent holding the halved interval after an event
+	MaxCPUPercent float64       // 0 disables the budget check
+	JitterFrac    float64       // +/- fraction of jitter applied to the result; 0 disables it
+
+	mu          sync.Mutex
+	heldCycles  int
+	lastEventAt time.Time
+}
+
+// NewDefaultPollingPolicy returns a DefaultPollingPolicy with the same
+// 50ms/200ms base/max interval DeviceMonitor has always used, a 10%
+// jitter, and no CPU budget (callers opt into one via MaxCPUPercent).
+func NewDefaultPollingPolicy() *DefaultPollingPolicy {
+	return &DefaultPollingPolicy{
+		BaseInterval:  50 * time.Millisecond,
+		MaxInterval:   200 * time.Millisecond,
+		BackoffCycles: 5,
+		JitterFrac:    0.1,
+	}
+}
+
+func (p *DefaultPollingPolicy) NextInterval(stats PollingStats, lastEvent devices.DeviceChangeEvent) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	interval := stats.CurrentInterval
+	if interval <= 0 {
+		interval = p.BaseInterval
+	}
+
+	if lastEvent.Kind != "" {
+		p.heldCycles = p.BackoffCycles
+		p.lastEventAt = time.Now()
+		interval /= 2
+		if interval < p.BaseInterval {
+			interval = p.BaseInterval
+		}
+	} else if p.heldCycles > 0 {
+		p.heldCycles--
+	} else {
+		interval = time.Duration(float64(interval) * 1.5)
+		if interval > p.MaxInterval {
+			interval = p.MaxInterval
+		}
+	}
+
+	if p.MaxCPUPercent > 0 && stats.AvgCheckTime > 0 {
+		cpuPercent := float64(stats.AvgCheckTime) / float64(interval) * 100
+		if cpuPercent > p.MaxCPUPercent {
+			// Force the interval out far enough that AvgCheckTime only
+			// consumes MaxCPUPercent of it, even if that means ignoring
+			// the halving an event just requested.
+			budgeted := time.Duration(float64(stats.AvgCheckTime) * 100 / p.MaxCPUPercent)
+			if budgeted > interval {
+				interval = budgeted
+			}
+			if interval > p.MaxInterval {
+				interval = p.MaxInterval
+			}
+		}
+	}
+
+	if p.JitterFrac > 0 {
+		jitter := (rand.Float64()*2 - 1) * p.JitterFrac
+		interval = time.Duration(float64(interval) * (1 + jitter))
+		if interval < p.BaseInterval {
+			interval = p.BaseInterval
+		}
+	}
+
+	return interval
+}
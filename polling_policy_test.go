@@ -0,0 +1,76 @@
+package macaudio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+func TestDefaultPollingPolicyHalvesOnEvent(t *testing.T) {
+	p := NewDefaultPollingPolicy()
+	p.JitterFrac = 0 // deterministic
+
+	stats := PollingStats{CurrentInterval: 100 * time.Millisecond}
+	got := p.NextInterval(stats, devices.DeviceChangeEvent{Kind: devices.DeviceAdded})
+
+	if got != 50*time.Millisecond {
+		t.Errorf("NextInterval on event = %v, want 50ms", got)
+	}
+}
+
+func TestDefaultPollingPolicyHoldsHalvedIntervalForBackoffCycles(t *testing.T) {
+	p := NewDefaultPollingPolicy()
+	p.JitterFrac = 0
+	p.BackoffCycles = 2
+
+	stats := PollingStats{CurrentInterval: 100 * time.Millisecond}
+	interval := p.NextInterval(stats, devices.DeviceChangeEvent{Kind: devices.DeviceRemoved})
+
+	for i := 0; i < p.BackoffCycles; i++ {
+		stats.CurrentInterval = interval
+		interval = p.NextInterval(stats, devices.DeviceChangeEvent{})
+	}
+	if interval != p.BaseInterval {
+		t.Errorf("expected interval to stay at BaseInterval during backoff, got %v", interval)
+	}
+
+	// Quiescence beyond BackoffCycles should start growing again.
+	stats.CurrentInterval = interval
+	grown := p.NextInterval(stats, devices.DeviceChangeEvent{})
+	if grown <= interval {
+		t.Errorf("expected interval to grow once the backoff held, got %v (was %v)", grown, interval)
+	}
+}
+
+func TestDefaultPollingPolicyGrowsOnQuiescenceUpToMax(t *testing.T) {
+	p := NewDefaultPollingPolicy()
+	p.JitterFrac = 0
+	p.BackoffCycles = 0
+	p.MaxInterval = 200 * time.Millisecond
+
+	interval := p.BaseInterval
+	for i := 0; i < 20; i++ {
+		interval = p.NextInterval(PollingStats{CurrentInterval: interval}, devices.DeviceChangeEvent{})
+	}
+	if interval != p.MaxInterval {
+		t.Errorf("expected interval to settle at MaxInterval, got %v", interval)
+	}
+}
+
+func TestDefaultPollingPolicyEnforcesCPUBudget(t *testing.T) {
+	p := NewDefaultPollingPolicy()
+	p.JitterFrac = 0
+	p.MaxCPUPercent = 10 // avgCheckTime must stay under 10% of the interval
+
+	stats := PollingStats{
+		CurrentInterval: 100 * time.Millisecond,
+		AvgCheckTime:    50 * time.Millisecond, // way over budget at 100ms interval
+	}
+	got := p.NextInterval(stats, devices.DeviceChangeEvent{Kind: devices.DeviceAdded})
+
+	// 50ms / interval * 100 <= 10  =>  interval >= 500ms, clamped to MaxInterval.
+	if got != p.MaxInterval {
+		t.Errorf("expected CPU budget to force the interval to MaxInterval, got %v", got)
+	}
+}
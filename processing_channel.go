@@ -0,0 +1,104 @@
+package macaudio
+
+import (
+	"fmt"
+	"unsafe"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// ProcessingConfig holds configuration for a processing channel.
+type ProcessingConfig struct {
+	// Callback receives one block of interleaved float32 samples per render
+	// cycle (see avengine.RenderCallback) and fills out from in. It cannot
+	// be persisted - see createChannelFromState's ChannelTypeProcessing case.
+	Callback avengine.RenderCallback
+}
+
+// ProcessingChannel is a channel backed by an AUAudioUnit render-unit node
+// (see avengine.CreateRenderUnit) that runs a Go callback over each audio
+// buffer, for effects/analysis that don't fit the plugin-chain model (FFT
+// meters, envelope followers, telemetry taps) without writing Objective-C.
+//
+// The render unit's render block doesn't call back into Go yet - that needs
+// a native render-notify-tap binding this tree doesn't have (see
+// avengine.RenderCallback's doc comment) - so a ProcessingChannel currently
+// passes audio straight through unmodified, the same documented-but-unwired
+// state as Dispatcher.OnRender/OnXRun ahead of their own native binding.
+type ProcessingChannel struct {
+	*BaseChannel
+
+	config   ProcessingConfig
+	callback avengine.RenderCallback
+
+	renderNode unsafe.Pointer
+}
+
+// NewProcessingChannel creates a new processing channel backed by a render
+// unit node.
+func NewProcessingChannel(name string, config ProcessingConfig, engine *Engine) (*ProcessingChannel, error) {
+	if config.Callback == nil {
+		return nil, fmt.Errorf("processing channel requires a Callback")
+	}
+
+	baseChannel := NewBaseChannel(name, ChannelTypeProcessing, engine)
+
+	avEngine := engine.getAVEngine()
+	renderNode, err := avEngine.CreateRenderUnit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create render unit: %w", err)
+	}
+
+	outputMixer, err := avEngine.CreateMixerNode() // Create dedicated mixer for this channel
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel mixer: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+
+	return &ProcessingChannel{
+		BaseChannel: baseChannel,
+		config:      config,
+		callback:    config.Callback,
+		renderNode:  renderNode,
+	}, nil
+}
+
+// Start starts the processing channel, connecting its render unit into the
+// channel mixer and the channel mixer into the main mixer, same
+// connect-with-fallback pattern as AudioInputChannel.Start.
+func (pc *ProcessingChannel) Start() error {
+	if err := pc.BaseChannel.Start(); err != nil {
+		return err
+	}
+
+	avEngine := pc.engine.getAVEngine()
+
+	if err := avEngine.Connect(pc.renderNode, pc.outputMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(pc.renderNode, pc.outputMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect render unit to channel mixer: %w", err)
+		}
+	}
+
+	mainMixer, err := avEngine.MainMixerNode()
+	if err != nil {
+		return fmt.Errorf("failed to get main mixer: %w", err)
+	}
+	if err := avEngine.Connect(pc.outputMixer, mainMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(pc.outputMixer, mainMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect channel mixer to main mixer: %w", err)
+		}
+	}
+
+	return pc.engine.startAVEngineIfReady()
+}
+
+// Stop stops the processing channel and disconnects its render unit.
+func (pc *ProcessingChannel) Stop() error {
+	avEngine := pc.engine.getAVEngine()
+
+	if pc.outputMixer != nil {
+		avEngine.DisconnectNodeInput(pc.outputMixer, 0)
+	}
+
+	return pc.BaseChannel.Stop()
+}
@@ -0,0 +1,269 @@
+// Package recorder captures live engine output (and optionally each
+// channel's post-plugin tap) to a single HDF5 file: one dataset per
+// channel, with sample rate / bit depth / channel count / timestamp / UUID
+// attributes, plus a JSON attribute holding the serialized engine.Engine
+// state so a session can be reconstructed bit-exact by Replay.
+package recorder
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include "native/recorder.h"
+#include <stdlib.h>
+
+// Function declarations - CGO resolves these from the native recorder shim.
+void* rec_create_file(const char* path);
+void* rec_create_dataset(void* file, const char* name, int sampleRate, int bitDepth, int channelCount);
+const char* rec_set_attrs(void* dataset, const char* timestampRFC3339, const char* uuid, const char* engineJSON);
+const char* rec_append_chunk(void* dataset, const float* samples, int frameCount, int channelCount);
+const char* rec_close_file(void* file);
+
+void* rec_open_file(const char* path);
+const char* rec_read_json_attr(void* file, const char* datasetName, char** outJSON);
+const char* rec_close_read_file(void* file);
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/google/uuid"
+)
+
+// ChannelSpec describes one dataset to capture: the engine's main output,
+// or a single channel's post-plugin tap.
+type ChannelSpec struct {
+	Name         string // dataset name, e.g. "master" or the channel name
+	SampleRate   int
+	BitDepth     int // 16, 24, or 32
+	ChannelCount int // 1 (mono) or 2 (stereo)
+}
+
+// chunk is one block of interleaved float32 samples queued for a dataset.
+type chunk struct {
+	dataset      unsafe.Pointer
+	samples      []float32
+	frameCount   int
+	channelCount int
+}
+
+// Recorder writes one or more audio streams to a single HDF5 file. Appends
+// are queued to a background writer goroutine so render-thread callers
+// never block on file I/O.
+type Recorder struct {
+	mu       sync.Mutex
+	file     unsafe.Pointer
+	datasets map[string]unsafe.Pointer
+	queue    chan chunk
+	done     chan struct{}
+	wg       sync.WaitGroup
+	started  bool
+}
+
+// New creates an unstarted Recorder. Call Start to open the backing file.
+func New() *Recorder {
+	return &Recorder{datasets: make(map[string]unsafe.Pointer)}
+}
+
+// Start opens path as a new HDF5 file, creates a dataset per spec with its
+// metadata attributes, embeds engineJSON (the output of
+// Engine.SerializeState) as a JSON attribute on each dataset, and starts the
+// background writer. queueDepth bounds how many chunks may be buffered
+// before AppendChunk blocks; size it to a few audio callback blocks.
+func (r *Recorder) Start(path string, specs []ChannelSpec, engineJSON []byte, queueDepth int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return errors.New("recorder already started")
+	}
+	if len(specs) == 0 {
+		return errors.New("at least one channel spec is required")
+	}
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	file := C.rec_create_file(cPath)
+	if file == nil {
+		return fmt.Errorf("failed to create HDF5 file at %s", path)
+	}
+
+	sessionID := uuid.New().String()
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	datasets := make(map[string]unsafe.Pointer, len(specs))
+	for _, spec := range specs {
+		cName := C.CString(spec.Name)
+		ds := C.rec_create_dataset(file, cName, C.int(spec.SampleRate), C.int(spec.BitDepth), C.int(spec.ChannelCount))
+		C.free(unsafe.Pointer(cName))
+		if ds == nil {
+			C.rec_close_file(file)
+			return fmt.Errorf("failed to create dataset %q", spec.Name)
+		}
+
+		cTimestamp := C.CString(timestamp)
+		cUUID := C.CString(sessionID)
+		cJSON := C.CString(string(engineJSON))
+		errStr := C.rec_set_attrs(ds, cTimestamp, cUUID, cJSON)
+		C.free(unsafe.Pointer(cTimestamp))
+		C.free(unsafe.Pointer(cUUID))
+		C.free(unsafe.Pointer(cJSON))
+		if errStr != nil {
+			C.rec_close_file(file)
+			return errors.New(C.GoString(errStr))
+		}
+
+		datasets[spec.Name] = ds
+	}
+
+	r.file = file
+	r.datasets = datasets
+	r.queue = make(chan chunk, queueDepth)
+	r.done = make(chan struct{})
+	r.started = true
+
+	r.wg.Add(1)
+	go r.writeLoop()
+	return nil
+}
+
+// AppendChunk queues an interleaved float32 block from the named channel's
+// render callback for writing. It copies samples so the caller's audio
+// buffer can be reused immediately, and never blocks the render thread
+// beyond the bounded channel send (the queue should be sized so this never
+// has to wait).
+func (r *Recorder) AppendChunk(channelName string, samples []float32, frameCount, channelCount int) error {
+	r.mu.Lock()
+	ds, ok := r.datasets[channelName]
+	started := r.started
+	r.mu.Unlock()
+	if !started {
+		return errors.New("recorder not started")
+	}
+	if !ok {
+		return fmt.Errorf("unknown recorder channel %q", channelName)
+	}
+
+	buf := make([]float32, len(samples))
+	copy(buf, samples)
+
+	select {
+	case r.queue <- chunk{dataset: ds, samples: buf, frameCount: frameCount, channelCount: channelCount}:
+		return nil
+	default:
+		return fmt.Errorf("recorder queue full for channel %q; dropped %d frames", channelName, frameCount)
+	}
+}
+
+// writeLoop drains queued chunks and appends them to their HDF5 dataset.
+// This is the only goroutine that touches the native file handle after
+// Start, so no additional locking is needed around the append calls.
+func (r *Recorder) writeLoop() {
+	defer r.wg.Done()
+	for {
+		select {
+		case c := <-r.queue:
+			r.writeChunk(c)
+		case <-r.done:
+			// Drain whatever is left before exiting.
+			for {
+				select {
+				case c := <-r.queue:
+					r.writeChunk(c)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) writeChunk(c chunk) {
+	if len(c.samples) == 0 {
+		return
+	}
+	C.rec_append_chunk(c.dataset, (*C.float)(unsafe.Pointer(&c.samples[0])), C.int(c.frameCount), C.int(c.channelCount))
+}
+
+// Stop flushes any queued chunks and closes the HDF5 file. The Recorder
+// cannot be restarted; create a new one for another session.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started {
+		return errors.New("recorder not started")
+	}
+
+	close(r.done)
+	r.wg.Wait()
+
+	errStr := C.rec_close_file(r.file)
+	r.started = false
+	r.file = nil
+	r.datasets = nil
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
+
+// Replay reconstructs engine state (and, eventually, routes audio data)
+// from a recording written by Recorder.
+type Replay struct {
+	file unsafe.Pointer
+}
+
+// Open opens path for reading. Call Close when done.
+func Open(path string) (*Replay, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	file := C.rec_open_file(cPath)
+	if file == nil {
+		return nil, fmt.Errorf("failed to open recording at %s", path)
+	}
+	return &Replay{file: file}, nil
+}
+
+// EngineJSON returns the raw serialized engine.Engine state embedded in
+// datasetName's JSON attribute, as written by Recorder.Start.
+func (r *Replay) EngineJSON(datasetName string) ([]byte, error) {
+	cName := C.CString(datasetName)
+	defer C.free(unsafe.Pointer(cName))
+
+	var out *C.char
+	errStr := C.rec_read_json_attr(r.file, cName, &out)
+	if errStr != nil {
+		return nil, errors.New(C.GoString(errStr))
+	}
+	defer C.free(unsafe.Pointer(out))
+	return []byte(C.GoString(out)), nil
+}
+
+// DecodeEngineState is a convenience that unmarshals EngineJSON(datasetName)
+// into dst (typically a *engine.Engine via its DeserializeState method, or
+// any value sharing its JSON shape) so a session can be reopened bit-exact.
+func (r *Replay) DecodeEngineState(datasetName string, dst interface{}) error {
+	data, err := r.EngineJSON(datasetName)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// Close releases the underlying HDF5 file handle.
+func (r *Replay) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	errStr := C.rec_close_read_file(r.file)
+	r.file = nil
+	if errStr != nil {
+		return errors.New(C.GoString(errStr))
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package macaudio
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ChannelRegistry resolves channels and buses by a stable, human-readable
+// address instead of a raw pointer or UUID - e.g. "channel/vocal" or
+// "bus/reverb". This is the same by-name resolution OSC's
+// osc.Server.resolveByName and BaseChannel.resolveAuxByName already do ad
+// hoc by linearly scanning Engine.ListChannels, made O(1) and reusable
+// anywhere a symbolic name needs to become a Channel: send/route wiring by
+// address (see BaseChannel.AddSendByAddress/RouteToAddress below), snapshot
+// restore, or a REPL.
+//
+// Every Engine owns exactly one (see Engine.registry) - there's no
+// process-wide registry, since a channel's name is only unique within the
+// Engine that created it, and tests routinely run several Engines at once.
+type ChannelRegistry struct {
+	mu       sync.RWMutex
+	byAddr   map[string]Channel
+	addrByID map[string]string
+}
+
+func newChannelRegistry() *ChannelRegistry {
+	return &ChannelRegistry{
+		byAddr:   make(map[string]Channel),
+		addrByID: make(map[string]string),
+	}
+}
+
+var slugInvalidRun = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify derives an address-safe slug from name: lowercased, with runs of
+// anything other than a-z0-9 collapsed to a single hyphen and trimmed.
+func slugify(name string) string {
+	return strings.Trim(slugInvalidRun.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// addressPrefix returns the registry's address namespace for channelType -
+// "bus" for a Bus, "channel" for every other channel type.
+func addressPrefix(channelType ChannelType) string {
+	if channelType == ChannelTypeBus {
+		return "bus"
+	}
+	return "channel"
+}
+
+// Register assigns channel a stable address of the form "<prefix>/<slug>",
+// derived from its type and name, and returns it. Channel names aren't
+// required to be unique, so a slug collision (two channels both named
+// "Vocal") is resolved with a numeric suffix ("channel/vocal-2",
+// "channel/vocal-3", ...) instead of an error.
+func (r *ChannelRegistry) Register(channel Channel) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var name, id string
+	if named, ok := channel.(interface{ GetName() string }); ok {
+		name = named.GetName()
+	}
+	if identified, ok := channel.(interface{ GetIDString() string }); ok {
+		id = identified.GetIDString()
+	}
+
+	prefix := addressPrefix(channel.GetType())
+	base := slugify(name)
+	if base == "" {
+		base = "channel"
+	}
+
+	addr := prefix + "/" + base
+	for n := 2; ; n++ {
+		if _, taken := r.byAddr[addr]; !taken {
+			break
+		}
+		addr = fmt.Sprintf("%s/%s-%d", prefix, base, n)
+	}
+
+	r.byAddr[addr] = channel
+	if id != "" {
+		r.addrByID[id] = addr
+	}
+	return addr
+}
+
+// Unregister removes whatever address was assigned to the channel
+// identified by id (see Register), if any. Safe to call even if id was
+// never registered.
+func (r *ChannelRegistry) Unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	addr, ok := r.addrByID[id]
+	if !ok {
+		return
+	}
+	delete(r.addrByID, id)
+	delete(r.byAddr, addr)
+}
+
+// Lookup resolves address to the channel it was registered under.
+func (r *ChannelRegistry) Lookup(address string) (Channel, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	channel, ok := r.byAddr[address]
+	return channel, ok
+}
+
+// LookupBus is Lookup narrowed to a *Bus, for callers (like
+// BaseChannel.RouteToAddress) that only make sense against a bus address.
+func (r *ChannelRegistry) LookupBus(address string) (*Bus, bool) {
+	channel, ok := r.Lookup(address)
+	if !ok {
+		return nil, false
+	}
+	bus, ok := channel.(*Bus)
+	return bus, ok
+}
+
+// List returns every registered address with the given prefix (e.g. "bus/"
+// for every bus, "" for everything), sorted for stable output.
+func (r *ChannelRegistry) List(prefix string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addrs := make([]string, 0, len(r.byAddr))
+	for addr := range r.byAddr {
+		if strings.HasPrefix(addr, prefix) {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// ResolveAddress looks up a channel or bus by the stable address it was
+// assigned on creation (see ChannelRegistry), e.g. "channel/vocal" or
+// "bus/reverb" - the symbolic counterpart to GetChannel's lookup by UUID.
+func (e *Engine) ResolveAddress(address string) (Channel, bool) {
+	e.mu.RLock()
+	registry := e.registry
+	e.mu.RUnlock()
+	if registry == nil {
+		return nil, false
+	}
+	return registry.Lookup(address)
+}
+
+// ListAddresses returns every registered channel/bus address with the
+// given prefix, sorted - see ChannelRegistry.List.
+func (e *Engine) ListAddresses(prefix string) []string {
+	e.mu.RLock()
+	registry := e.registry
+	e.mu.RUnlock()
+	if registry == nil {
+		return nil
+	}
+	return registry.List(prefix)
+}
+
+// AddSendByAddress resolves address via the owning engine's ChannelRegistry
+// to an AuxChannel and routes a send into it exactly as AddSend does -
+// the address-based counterpart for callers (OSC, snapshot restore, a
+// REPL) that only have a symbolic name instead of an *AuxChannel pointer.
+func (bc *BaseChannel) AddSendByAddress(address string, level float32, preFader bool) error {
+	aux, err := bc.resolveAuxAddress(address)
+	if err != nil {
+		return err
+	}
+	return bc.AddSend(aux, level, preFader)
+}
+
+// RouteToAddress resolves address via the owning engine's ChannelRegistry
+// to a Bus and routes into it exactly as RouteTo does.
+func (bc *BaseChannel) RouteToAddress(address string) error {
+	if bc.engine == nil || bc.engine.registry == nil {
+		return fmt.Errorf("channel not connected to engine")
+	}
+	bus, ok := bc.engine.registry.LookupBus(address)
+	if !ok {
+		return fmt.Errorf("no bus registered at address %q", address)
+	}
+	return bc.RouteTo(bus)
+}
+
+// resolveAuxAddress is AddSendByAddress's lookup-and-typecheck helper.
+func (bc *BaseChannel) resolveAuxAddress(address string) (*AuxChannel, error) {
+	if bc.engine == nil || bc.engine.registry == nil {
+		return nil, fmt.Errorf("channel not connected to engine")
+	}
+	channel, ok := bc.engine.registry.Lookup(address)
+	if !ok {
+		return nil, fmt.Errorf("no channel registered at address %q", address)
+	}
+	aux, ok := channel.(*AuxChannel)
+	if !ok {
+		return nil, fmt.Errorf("address %q is not an aux channel", address)
+	}
+	return aux, nil
+}
@@ -0,0 +1,123 @@
+package macaudio
+
+import "testing"
+
+func TestEngineRegistersChannelsAndBusesUnderAddress(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	sine, err := eng.CreateSineChannel("Vocal", 440, 0.1)
+	if err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+	bus, err := eng.CreateBus("Reverb")
+	if err != nil {
+		t.Fatalf("CreateBus failed: %v", err)
+	}
+
+	resolved, ok := eng.ResolveAddress("channel/vocal")
+	if !ok || resolved.GetIDString() != sine.GetIDString() {
+		t.Fatalf("expected channel/vocal to resolve to the sine channel, got %v (ok=%v)", resolved, ok)
+	}
+
+	resolvedBus, ok := eng.ResolveAddress("bus/reverb")
+	if !ok || resolvedBus.GetIDString() != bus.GetIDString() {
+		t.Fatalf("expected bus/reverb to resolve to the bus, got %v (ok=%v)", resolvedBus, ok)
+	}
+
+	addrs := eng.ListAddresses("bus/")
+	if len(addrs) != 1 || addrs[0] != "bus/reverb" {
+		t.Errorf("expected ListAddresses(\"bus/\") == [\"bus/reverb\"], got %v", addrs)
+	}
+}
+
+func TestEngineRegistryDedupesCollidingNames(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	first, err := eng.CreateSineChannel("Tone", 440, 0.1)
+	if err != nil {
+		t.Fatalf("CreateSineChannel(first) failed: %v", err)
+	}
+	second, err := eng.CreateSineChannel("Tone", 880, 0.1)
+	if err != nil {
+		t.Fatalf("CreateSineChannel(second) failed: %v", err)
+	}
+
+	resolvedFirst, ok := eng.ResolveAddress("channel/tone")
+	if !ok || resolvedFirst.GetIDString() != first.GetIDString() {
+		t.Fatalf("expected channel/tone to resolve to the first channel, got %v (ok=%v)", resolvedFirst, ok)
+	}
+	resolvedSecond, ok := eng.ResolveAddress("channel/tone-2")
+	if !ok || resolvedSecond.GetIDString() != second.GetIDString() {
+		t.Fatalf("expected channel/tone-2 to resolve to the second channel, got %v (ok=%v)", resolvedSecond, ok)
+	}
+}
+
+func TestEngineRegistryUnregistersOnRemove(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	sine, err := eng.CreateSineChannel("Lead", 440, 0.1)
+	if err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+	if err := eng.RemoveChannel(sine.GetIDString()); err != nil {
+		t.Fatalf("RemoveChannel failed: %v", err)
+	}
+
+	if _, ok := eng.ResolveAddress("channel/lead"); ok {
+		t.Errorf("expected channel/lead to no longer resolve after removal")
+	}
+}
+
+func TestAddSendByAddressAndRouteToAddress(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	if _, err := eng.CreateAuxChannel("Reverb", AuxConfig{SendLevel: 1, ReturnLevel: 1}); err != nil {
+		t.Fatalf("CreateAuxChannel failed: %v", err)
+	}
+	if _, err := eng.CreateBus("Drums"); err != nil {
+		t.Fatalf("CreateBus failed: %v", err)
+	}
+	sine, err := eng.CreateSineChannel("Kick", 60, 0.5)
+	if err != nil {
+		t.Fatalf("CreateSineChannel failed: %v", err)
+	}
+
+	if err := sine.AddSendByAddress("channel/reverb", 0.4, false); err != nil {
+		t.Fatalf("AddSendByAddress failed: %v", err)
+	}
+	sends := sine.GetSends()
+	if len(sends) != 1 || sends[0].Aux.GetName() != "Reverb" || sends[0].Level != 0.4 {
+		t.Errorf("expected one send to Reverb at level 0.4, got %+v", sends)
+	}
+
+	if err := sine.RouteToAddress("bus/drums"); err != nil {
+		t.Fatalf("RouteToAddress failed: %v", err)
+	}
+
+	if err := sine.AddSendByAddress("bus/drums", 0.1, false); err == nil {
+		t.Error("expected AddSendByAddress to reject a bus address, got nil error")
+	}
+	if err := sine.RouteToAddress("channel/reverb"); err == nil {
+		t.Error("expected RouteToAddress to reject a non-bus address, got nil error")
+	}
+}
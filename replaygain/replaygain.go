@@ -0,0 +1,249 @@
+// Package replaygain computes ReplayGain-style loudness normalization data
+// for an audio file, for callers that don't already have it from file tags.
+// It implements the ITU-R BS.1770 K-weighted, gated loudness measurement
+// (the same algorithm EBU R128 and ReplayGain 2.0 are built on), streaming
+// the file rather than loading it whole.
+//
+// Simplifications versus the full spec: channel weighting treats every
+// channel as front left/right (weight 1.0) rather than identifying
+// surround channels for the 1.41 weight BS.1770 gives them, and Peak is the
+// plain sample peak rather than an oversampled true peak. Both are
+// reasonable approximations for the common mono/stereo files this is aimed
+// at; callers measuring multichannel surround masters should treat Gain as
+// approximate.
+package replaygain
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// ReferenceLUFS is the loudness Gain normalizes a track to, matching the
+// ReplayGain 2.0 / EBU R128 reference level.
+const ReferenceLUFS = -18.0
+
+// Result holds the loudness-normalization data derived from scanning a file.
+type Result struct {
+	Gain float64 // dB to apply to reach ReferenceLUFS
+	LUFS float64 // measured integrated loudness
+	Peak float64 // linear sample peak, 0..1 (clipped files may exceed 1)
+}
+
+const (
+	blockMs     = 100 // partial-block size; four of these make one 400ms gating block
+	gateAbsLUFS = -70.0
+	gateRelLU   = -10.0
+)
+
+// biquad is a Direct Form II Transposed IIR section, used for both stages of
+// the K-weighting filter.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (bq *biquad) process(x float64) float64 {
+	y := bq.b0*x + bq.z1
+	bq.z1 = bq.b1*x - bq.a1*y + bq.z2
+	bq.z2 = bq.b2*x - bq.a2*y
+	return y
+}
+
+// kWeightFilters returns the two-stage K-weighting filter (high-shelf stage
+// then RLB high-pass stage) for the given sample rate, using the standard
+// BS.1770 coefficient derivation from libebur128.
+func kWeightFilters(sampleRate float64) (shelf, highpass biquad) {
+	// Stage 1: high-shelf boost above ~1.7kHz.
+	f0 := 1681.9744509555319
+	g := 3.99984385397
+	q := 0.7071752369554193
+
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10.0, g/20.0)
+	vb := math.Pow(vh, 0.4996667741545416)
+
+	a0 := 1.0 + k/q + k*k
+	shelf = biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2.0 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2.0 * (k*k - 1.0) / a0,
+		a2: (1.0 - k/q + k*k) / a0,
+	}
+
+	// Stage 2: RLB weighting, a high-pass around 38Hz.
+	f0 = 38.13547087613982
+	q = 0.5003270373238773
+
+	k = math.Tan(math.Pi * f0 / sampleRate)
+	a0 = 1.0 + k/q + k*k
+	highpass = biquad{
+		b0: 1.0,
+		b1: -2.0,
+		b2: 1.0,
+		a1: 2.0 * (k*k - 1.0) / a0,
+		a2: (1.0 - k/q + k*k) / a0,
+	}
+	return shelf, highpass
+}
+
+// Compute streams path and returns its measured loudness and peak. It's the
+// basis for ComputeReplayGain (see engine-facing wrapper in macaudio);
+// exported here so callers that just want the raw loudness number (not a
+// gain scalar) don't have to go through a PlaybackChannel.
+func Compute(path string) (Result, error) {
+	f, err := avengine.OpenAudioFile(path)
+	if err != nil {
+		return Result{}, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+
+	channels := f.ChannelCount()
+	if channels == 0 {
+		return Result{}, fmt.Errorf("file %q reports no channels", path)
+	}
+	sampleRate := f.SampleRate()
+
+	shelves := make([]biquad, channels)
+	highpasses := make([]biquad, channels)
+	for ch := range shelves {
+		shelves[ch], highpasses[ch] = kWeightFilters(sampleRate)
+	}
+
+	blockFrames := int(sampleRate * blockMs / 1000.0)
+	if blockFrames <= 0 {
+		blockFrames = 1
+	}
+
+	var (
+		blockSumSq    = make([]float64, channels)
+		blockSamples  int
+		partialBlocks []float64 // mean-square-per-channel-weighted loudness input, one per 100ms block
+		peak          float64
+	)
+
+	const readChunkFrames = 4096
+	buf := make([]float32, readChunkFrames*channels)
+
+	flushBlock := func() {
+		if blockSamples == 0 {
+			return
+		}
+		var sum float64
+		for ch := 0; ch < channels; ch++ {
+			sum += blockSumSq[ch] / float64(blockSamples)
+		}
+		partialBlocks = append(partialBlocks, sum)
+		for ch := range blockSumSq {
+			blockSumSq[ch] = 0
+		}
+		blockSamples = 0
+	}
+
+	for {
+		n, readErr := f.Read(buf)
+		for frame := 0; frame < n; frame++ {
+			for ch := 0; ch < channels; ch++ {
+				sample := float64(buf[frame*channels+ch])
+				if a := math.Abs(sample); a > peak {
+					peak = a
+				}
+
+				filtered := highpasses[ch].process(shelves[ch].process(sample))
+				blockSumSq[ch] += filtered * filtered
+			}
+			blockSamples++
+			if blockSamples >= blockFrames {
+				flushBlock()
+			}
+		}
+		if readErr != nil {
+			if readErr == io.EOF {
+				break
+			}
+			return Result{}, fmt.Errorf("read %q: %w", path, readErr)
+		}
+	}
+	flushBlock()
+
+	lufs := gatedLoudness(partialBlocks)
+	return Result{
+		Gain: ReferenceLUFS - lufs,
+		LUFS: lufs,
+		Peak: peak,
+	}, nil
+}
+
+// gatedLoudness applies BS.1770's two-stage gating (absolute, then relative)
+// to 100ms partial blocks and returns the integrated loudness in LUFS.
+// partials groups four consecutive 100ms blocks into a 400ms gating block
+// with 75% overlap, as the spec requires.
+func gatedLoudness(partials []float64) float64 {
+	const blocksPerWindow = 4
+	if len(partials) < blocksPerWindow {
+		// Too short for a proper gated measurement; fall back to a single
+		// ungated window over everything available.
+		if len(partials) == 0 {
+			return math.Inf(-1)
+		}
+		var sum float64
+		for _, p := range partials {
+			sum += p
+		}
+		return loudnessFromMeanSquare(sum / float64(len(partials)))
+	}
+
+	windows := make([]float64, 0, len(partials)-blocksPerWindow+1)
+	for i := 0; i+blocksPerWindow <= len(partials); i++ {
+		var sum float64
+		for j := 0; j < blocksPerWindow; j++ {
+			sum += partials[i+j]
+		}
+		windows = append(windows, sum/float64(blocksPerWindow))
+	}
+
+	// Absolute gate: discard windows quieter than -70 LUFS.
+	var absKept []float64
+	for _, w := range windows {
+		if loudnessFromMeanSquare(w) >= gateAbsLUFS {
+			absKept = append(absKept, w)
+		}
+	}
+	if len(absKept) == 0 {
+		return math.Inf(-1)
+	}
+
+	var sum float64
+	for _, w := range absKept {
+		sum += w
+	}
+	ungated := loudnessFromMeanSquare(sum / float64(len(absKept)))
+
+	// Relative gate: discard windows quieter than 10 LU below that.
+	relThreshold := ungated + gateRelLU
+	var relKept []float64
+	for _, w := range absKept {
+		if loudnessFromMeanSquare(w) >= relThreshold {
+			relKept = append(relKept, w)
+		}
+	}
+	if len(relKept) == 0 {
+		return ungated
+	}
+
+	sum = 0
+	for _, w := range relKept {
+		sum += w
+	}
+	return loudnessFromMeanSquare(sum / float64(len(relKept)))
+}
+
+func loudnessFromMeanSquare(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
@@ -0,0 +1,169 @@
+package macaudio
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// EngineLimits caps how many graph resources an Engine will let the
+// dispatcher create, borrowed from the same resource-ceiling model a
+// polyphonic sampler uses to protect its voice pool: reject the request
+// that would cross the line instead of discovering the limit as crackling
+// on the render thread. Each field's zero value means unlimited, so an
+// EngineConfig that doesn't set Limits behaves exactly as before this
+// existed.
+type EngineLimits struct {
+	// MaxChannels caps Engine.channels, checked by addChannel against
+	// every channel type (audio input, playback, aux, bus, ...) - not just
+	// the two the dispatcher validates directly.
+	MaxChannels int
+	// MaxPluginsPerChannel caps a single PluginChain's instance count,
+	// checked by BaseChannel.AddPlugin.
+	MaxPluginsPerChannel int
+	// MaxInputNodes caps Engine.inputNodes, the map shared by every
+	// hardware-backed input channel type (AudioInputChannel,
+	// LoopbackInputChannel) keyed by "deviceUID:inputBus" - checked by
+	// getOrCreateInputNode.
+	MaxInputNodes int
+	// MaxCPUPercent is the render-time budget CheckCPUWatchdog compares
+	// RecordRenderStats readings against. Crossing it doesn't reject
+	// anything by itself (the render thread can't wait on a queue) - it
+	// emits ErrorKindResourceWarning on Engine.Errors() so a host can warn
+	// a user or shed load before AVAudioEngine starts dropping buffers.
+	MaxCPUPercent float64
+}
+
+// LimitKind identifies which EngineLimits field ErrLimitExceeded tripped.
+type LimitKind string
+
+const (
+	LimitChannels          LimitKind = "channels"
+	LimitPluginsPerChannel LimitKind = "plugins_per_channel"
+	LimitInputNodes        LimitKind = "input_nodes"
+)
+
+// ErrLimitExceeded is returned by a dispatcher create op or AddPlugin when
+// satisfying it would push past the matching EngineLimits field. Current is
+// the count before the attempted addition; Max is the configured ceiling.
+// Callers that want to react to a specific limit (e.g. offer to free up
+// plugin slots) should use errors.As rather than matching the message.
+type ErrLimitExceeded struct {
+	Kind    LimitKind
+	Current int
+	Max     int
+}
+
+func (e *ErrLimitExceeded) Error() string {
+	return fmt.Sprintf("%s limit exceeded: %d at limit %d", e.Kind, e.Current, e.Max)
+}
+
+// ResourceUsage is a snapshot of Engine's graph resource consumption,
+// returned by GetResourceUsage. PluginCount sums every channel's plugin
+// chain, not just one channel's.
+type ResourceUsage struct {
+	ChannelCount         int
+	PluginCount          int
+	InputNodeCount       int
+	LastRenderCPUPercent float64
+	UnderrunCount        uint64
+}
+
+// checkChannelLimit returns ErrLimitExceeded if adding one more channel
+// would cross EngineLimits.MaxChannels. Called by addChannel while holding
+// e.mu, before the new channel is inserted.
+func (e *Engine) checkChannelLimit() error {
+	if e.limits.MaxChannels <= 0 {
+		return nil
+	}
+	if current := len(e.channels); current >= e.limits.MaxChannels {
+		return &ErrLimitExceeded{Kind: LimitChannels, Current: current, Max: e.limits.MaxChannels}
+	}
+	return nil
+}
+
+// checkInputNodeLimit returns ErrLimitExceeded if creating one more shared
+// input node would cross EngineLimits.MaxInputNodes. Called by
+// getOrCreateInputNode while holding e.mu, before a node not already in
+// e.inputNodes is created.
+func (e *Engine) checkInputNodeLimit() error {
+	if e.limits.MaxInputNodes <= 0 {
+		return nil
+	}
+	if current := len(e.inputNodes); current >= e.limits.MaxInputNodes {
+		return &ErrLimitExceeded{Kind: LimitInputNodes, Current: current, Max: e.limits.MaxInputNodes}
+	}
+	return nil
+}
+
+// checkPluginLimit returns ErrLimitExceeded if adding one more plugin to pc
+// would cross EngineLimits.MaxPluginsPerChannel. Called by
+// BaseChannel.AddPlugin before delegating to pc.AddPlugin.
+func (e *Engine) checkPluginLimit(pc *PluginChain) error {
+	if e.limits.MaxPluginsPerChannel <= 0 {
+		return nil
+	}
+	if current := len(pc.GetInstances()); current >= e.limits.MaxPluginsPerChannel {
+		return &ErrLimitExceeded{Kind: LimitPluginsPerChannel, Current: current, Max: e.limits.MaxPluginsPerChannel}
+	}
+	return nil
+}
+
+// GetResourceUsage returns a snapshot of the engine's current graph
+// resource consumption - how hosts that set EngineLimits check how close
+// they are to them, and how ones that didn't can still watch for trouble.
+func (e *Engine) GetResourceUsage() ResourceUsage {
+	e.mu.RLock()
+	usage := ResourceUsage{
+		ChannelCount:   len(e.channels),
+		InputNodeCount: len(e.inputNodes),
+	}
+	for _, ch := range e.channels {
+		usage.PluginCount += len(ch.GetPluginChain().GetInstances())
+	}
+	e.mu.RUnlock()
+
+	usage.LastRenderCPUPercent = e.renderCPUPercent()
+	usage.UnderrunCount = atomic.LoadUint64(&e.underrunCount)
+	return usage
+}
+
+func (e *Engine) renderCPUPercent() float64 {
+	e.resourceMu.Lock()
+	defer e.resourceMu.Unlock()
+	return e.lastRenderCPUPercent
+}
+
+// RecordRenderStats feeds a render-cycle CPU reading and underrun flag into
+// the engine's resource accounting, and emits ErrorKindResourceWarning on
+// Errors() the moment cpuPercent crosses EngineLimits.MaxCPUPercent (a
+// no-op edge, like setLifecycleState: it fires once on the crossing, not
+// on every subsequent call while still over).
+//
+// Nothing in this tree calls RecordRenderStats yet: it needs the same
+// render-notify-tap binding Dispatcher.OnRender/OnXRun are already
+// reserved for and don't have (see their doc comments) - this exists so a
+// host wiring that binding up, or a test simulating load, has somewhere to
+// report readings without GetResourceUsage staying permanently zero.
+func (e *Engine) RecordRenderStats(cpuPercent float64, underrun bool) {
+	e.resourceMu.Lock()
+	wasOver := e.limits.MaxCPUPercent > 0 && e.lastRenderCPUPercent >= e.limits.MaxCPUPercent
+	e.lastRenderCPUPercent = cpuPercent
+	nowOver := e.limits.MaxCPUPercent > 0 && cpuPercent >= e.limits.MaxCPUPercent
+	e.resourceMu.Unlock()
+
+	if underrun {
+		atomic.AddUint64(&e.underrunCount, 1)
+	}
+
+	if nowOver && !wasOver {
+		e.mu.RLock()
+		ceh, ok := e.errorHandler.(*channelErrorHandler)
+		e.mu.RUnlock()
+		err := fmt.Errorf("render CPU at %.1f%% crossed soft limit %.1f%%", cpuPercent, e.limits.MaxCPUPercent)
+		if ok {
+			ceh.emit(ErrorKindResourceWarning, err, nil)
+		} else {
+			e.errorHandler.HandleError(err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package macaudio
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shaban/macaudio/avaudio/engine"
+)
+
+// TestMaxChannelsLimitRejectsDeterministically constructs channels up to
+// EngineLimits.MaxChannels and checks the cap is enforced on the Nth
+// attempt every time, not just eventually under load.
+func TestMaxChannelsLimitRejectsDeterministically(t *testing.T) {
+	const maxChannels = 3 // includes the always-present master channel
+
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   512,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		Offline: true,
+		Limits:  EngineLimits{MaxChannels: maxChannels},
+	}
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	// The master channel already counts toward maxChannels, so only one
+	// more group fits.
+	if _, err := eng.CreateGroup("group-1"); err != nil {
+		t.Fatalf("expected first group to be created within the limit, got %v", err)
+	}
+
+	_, err = eng.CreateGroup("group-2")
+	if err == nil {
+		t.Fatal("expected CreateGroup to fail once MaxChannels is reached")
+	}
+	var limitErr *ErrLimitExceeded
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected ErrLimitExceeded, got %v (%T)", err, err)
+	}
+	if limitErr.Kind != LimitChannels {
+		t.Errorf("expected LimitChannels, got %v", limitErr.Kind)
+	}
+	if limitErr.Max != maxChannels {
+		t.Errorf("expected Max %d, got %d", maxChannels, limitErr.Max)
+	}
+
+	// The rejection must be deterministic, not a one-off: repeated attempts
+	// against an engine still at the cap keep failing the same way.
+	for i := 0; i < 3; i++ {
+		if _, err := eng.CreateGroup("group-retry"); err == nil {
+			t.Fatalf("attempt %d: expected CreateGroup to still be rejected at the cap", i)
+		}
+	}
+}
+
+// TestMaxPluginsPerChannelLimitRejects checks BaseChannel.AddPlugin honors
+// EngineLimits.MaxPluginsPerChannel independently of MaxChannels.
+func TestMaxPluginsPerChannelLimitRejects(t *testing.T) {
+	const maxPlugins = 2
+
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   512,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		Offline: true,
+		Limits:  EngineLimits{MaxPluginsPerChannel: maxPlugins},
+	}
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	group, err := eng.CreateGroup("group-1")
+	if err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+
+	blueprint := PluginBlueprint{Name: "test-plugin"}
+	for i := 0; i < maxPlugins; i++ {
+		if _, err := group.AddPlugin(blueprint, i); err != nil {
+			t.Fatalf("plugin %d: expected insert within the limit to succeed, got %v", i, err)
+		}
+	}
+
+	_, err = group.AddPlugin(blueprint, maxPlugins)
+	if err == nil {
+		t.Fatal("expected AddPlugin to fail once MaxPluginsPerChannel is reached")
+	}
+	var limitErr *ErrLimitExceeded
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected ErrLimitExceeded, got %v (%T)", err, err)
+	}
+	if limitErr.Kind != LimitPluginsPerChannel {
+		t.Errorf("expected LimitPluginsPerChannel, got %v", limitErr.Kind)
+	}
+}
+
+// TestGetResourceUsageReflectsLiveCounts checks GetResourceUsage's channel
+// and plugin counts track CreateGroup/AddPlugin without a configured
+// EngineLimits getting in the way.
+func TestGetResourceUsageReflectsLiveCounts(t *testing.T) {
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   512,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		Offline: true,
+	}
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	usage := eng.GetResourceUsage()
+	if usage.ChannelCount != 1 { // master channel only
+		t.Fatalf("expected 1 channel before any CreateGroup, got %d", usage.ChannelCount)
+	}
+
+	group, err := eng.CreateGroup("group-1")
+	if err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	if _, err := group.AddPlugin(PluginBlueprint{Name: "test-plugin"}, 0); err != nil {
+		t.Fatalf("AddPlugin failed: %v", err)
+	}
+
+	usage = eng.GetResourceUsage()
+	if usage.ChannelCount != 2 {
+		t.Errorf("expected 2 channels after CreateGroup, got %d", usage.ChannelCount)
+	}
+	if usage.PluginCount != 1 {
+		t.Errorf("expected 1 plugin across all channels, got %d", usage.PluginCount)
+	}
+}
+
+// TestRecordRenderStatsEmitsResourceWarningOnce checks RecordRenderStats
+// fires ErrorKindResourceWarning exactly on the crossing, not on every
+// subsequent call while still over the limit.
+func TestRecordRenderStatsEmitsResourceWarningOnce(t *testing.T) {
+	config := EngineConfig{
+		AudioSpec: engine.AudioSpec{
+			SampleRate:   48000,
+			BufferSize:   512,
+			BitDepth:     32,
+			ChannelCount: 2,
+		},
+		Offline: true,
+		Limits:  EngineLimits{MaxCPUPercent: 80},
+	}
+
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer eng.Destroy()
+
+	errCh := eng.Errors()
+
+	eng.RecordRenderStats(50, false)
+	eng.RecordRenderStats(90, true)
+	eng.RecordRenderStats(95, false) // still over; must not re-fire
+
+	var warnings int
+	for i := 0; i < 2; i++ {
+		select {
+		case ev := <-errCh:
+			if ev.Kind == ErrorKindResourceWarning {
+				warnings++
+			}
+		default:
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("expected exactly 1 ErrorKindResourceWarning, got %d", warnings)
+	}
+
+	usage := eng.GetResourceUsage()
+	if usage.UnderrunCount != 1 {
+		t.Errorf("expected UnderrunCount 1, got %d", usage.UnderrunCount)
+	}
+	if usage.LastRenderCPUPercent != 95 {
+		t.Errorf("expected LastRenderCPUPercent 95, got %v", usage.LastRenderCPUPercent)
+	}
+}
@@ -0,0 +1,156 @@
+package macaudio
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+const (
+	midiStatusNoteOff = 0x80
+	midiStatusNoteOn  = 0x90
+)
+
+// SamplerConfig holds configuration for a sampler channel.
+type SamplerConfig struct {
+	// SoundFontPath, if non-empty, is loaded via LoadSoundFont as soon as
+	// the channel's sampler node exists. Leave empty to load one later.
+	SoundFontPath string
+	// Program, BankMSB, and BankLSB select which instrument within
+	// SoundFontPath is active - the same triple a bank-select CC pair plus
+	// program change would pick on a hardware sampler.
+	Program, BankMSB, BankLSB uint8
+}
+
+// SamplerChannel is a channel backed by a native AVAudioUnitSampler node
+// (see avengine.CreateSamplerNode), driven by MIDI note/CC events the way a
+// hardware or software sampler instrument normally is - StartNote/StopNote
+// are this channel's counterpart to SynthChannel's SetFrequency/
+// SetAmplitude, and LoadSoundFont to its construction-time config.
+//
+// This mirrors github.com/shaban/macaudio/engine's SamplerChannel (the
+// older, AVAudioUnitMIDIInstrument-based sampler for that package's
+// plugin-chain-free engine), adapted to this package's channel/dispatcher
+// conventions - a root-package channel that osc_server.go's handleSampler
+// stub previously had nothing to dispatch to.
+type SamplerChannel struct {
+	*BaseChannel
+
+	config SamplerConfig
+
+	renderNode unsafe.Pointer
+}
+
+// NewSamplerChannel creates a new sampler channel, attaching a fresh
+// AVAudioUnitSampler node and, if config.SoundFontPath is set, loading it
+// immediately.
+func NewSamplerChannel(name string, config SamplerConfig, engine *Engine) (*SamplerChannel, error) {
+	avEngine := engine.getAVEngine()
+
+	renderNode, err := avEngine.CreateSamplerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sampler node: %w", err)
+	}
+
+	if config.SoundFontPath != "" {
+		if err := avEngine.LoadSoundFont(renderNode, config.SoundFontPath, config.Program, config.BankMSB, config.BankLSB); err != nil {
+			return nil, fmt.Errorf("failed to load sound font: %w", err)
+		}
+	}
+
+	baseChannel := NewBaseChannel(name, ChannelTypeSampler, engine)
+
+	outputMixer, err := avEngine.CreateMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel mixer: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+
+	return &SamplerChannel{
+		BaseChannel: baseChannel,
+		config:      config,
+		renderNode:  renderNode,
+	}, nil
+}
+
+// LoadSoundFont loads a new SoundFont2 or DLS bank, replacing whatever this
+// channel's sampler node had loaded before.
+func (sc *SamplerChannel) LoadSoundFont(path string, program, bankMSB, bankLSB uint8) error {
+	avEngine := sc.engine.getAVEngine()
+	if err := avEngine.LoadSoundFont(sc.renderNode, path, program, bankMSB, bankLSB); err != nil {
+		return err
+	}
+	sc.config.SoundFontPath = path
+	sc.config.Program = program
+	sc.config.BankMSB = bankMSB
+	sc.config.BankLSB = bankLSB
+	return nil
+}
+
+// SetProgram re-selects the active instrument within this channel's already
+// loaded sound font, the way a MIDI program-change plus bank-select CC pair
+// would on a hardware sampler. Returns an error if no sound font has been
+// loaded yet (there's no path to reload against).
+func (sc *SamplerChannel) SetProgram(program, bankMSB, bankLSB uint8) error {
+	if sc.config.SoundFontPath == "" {
+		return fmt.Errorf("sampler channel has no sound font loaded")
+	}
+	return sc.LoadSoundFont(sc.config.SoundFontPath, program, bankMSB, bankLSB)
+}
+
+// StartNote sends a Note On to this channel's sampler on the given MIDI
+// channel (0-15) for note/velocity (each 0-127) - the live-MIDI counterpart
+// to SynthChannel.SetFrequency, driving the instrument directly rather than
+// through a bound controller (see avengine.BindMIDIController for the
+// plugin-chain equivalent this package's channels don't route through yet).
+func (sc *SamplerChannel) StartNote(channel, note, velocity int) error {
+	avEngine := sc.engine.getAVEngine()
+	return avEngine.SendSamplerMIDIEvent(sc.renderNode, byte(midiStatusNoteOn|channel&0x0F), byte(note), byte(velocity))
+}
+
+// StopNote sends a Note Off to this channel's sampler on the given MIDI
+// channel (0-15) for note (0-127), with releaseVelocity (0-127) as its
+// release velocity.
+func (sc *SamplerChannel) StopNote(channel, note, releaseVelocity int) error {
+	avEngine := sc.engine.getAVEngine()
+	return avEngine.SendSamplerMIDIEvent(sc.renderNode, byte(midiStatusNoteOff|channel&0x0F), byte(note), byte(releaseVelocity))
+}
+
+// Start starts the sampler channel, connecting its render node into the
+// channel mixer and the channel mixer into the main mixer - same
+// connect-with-fallback pattern as SynthChannel.Start.
+func (sc *SamplerChannel) Start() error {
+	if err := sc.BaseChannel.Start(); err != nil {
+		return err
+	}
+
+	avEngine := sc.engine.getAVEngine()
+
+	if err := avEngine.Connect(sc.renderNode, sc.outputMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(sc.renderNode, sc.outputMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect sampler node to channel mixer: %w", err)
+		}
+	}
+
+	mainMixer, err := avEngine.MainMixerNode()
+	if err != nil {
+		return fmt.Errorf("failed to get main mixer: %w", err)
+	}
+	if err := avEngine.Connect(sc.outputMixer, mainMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(sc.outputMixer, mainMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect channel mixer to main mixer: %w", err)
+		}
+	}
+
+	return sc.engine.startAVEngineIfReady()
+}
+
+// Stop stops the sampler channel and disconnects its render node.
+func (sc *SamplerChannel) Stop() error {
+	avEngine := sc.engine.getAVEngine()
+
+	if sc.outputMixer != nil {
+		avEngine.DisconnectNodeInput(sc.outputMixer, 0)
+	}
+
+	return sc.BaseChannel.Stop()
+}
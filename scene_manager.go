@@ -0,0 +1,490 @@
+package macaudio
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CrossfadeStep is the granularity at which Recall steps a crossfade, the
+// same 20ms cadence avaudio/recorder polls taps at.
+const CrossfadeStep = 20 * time.Millisecond
+
+// crossfadeConfigKeys lists the Config map keys that Recall interpolates
+// linearly in dB space alongside Volume, rather than snapping immediately
+// to the target scene's value.
+var crossfadeConfigKeys = []string{"sendLevel", "returnLevel", "monitoringLevel"}
+
+// SceneManager stores named EngineState snapshots ("scenes") captured from
+// a Serializer and recalls them either instantly or over a crossfade,
+// mirroring how live-mixer control daemons handle language/room presets.
+// It also keeps an undo/redo ring buffer of states visited through its own
+// ApplyState/Recall/Undo/Redo calls. It cannot see Serializer.SetState
+// calls made directly by other callers (e.g. OSCServer's /engine/load),
+// since Serializer only supports a single OnStateChange hook and that one
+// may already be claimed elsewhere - so undo history only covers changes
+// routed through SceneManager.
+type SceneManager struct {
+	serializer *Serializer
+	persistDir string
+
+	mu     sync.Mutex
+	scenes map[string]EngineState
+
+	historyMu  sync.Mutex
+	undoStack  []EngineState
+	redoStack  []EngineState
+	maxHistory int
+
+	morphMu     sync.Mutex
+	morphCancel chan struct{}
+}
+
+// SceneManagerOption configures a SceneManager at construction time.
+type SceneManagerOption func(*SceneManager)
+
+// WithPersistDir enables on-disk persistence of scenes as JSON files under
+// dir, in addition to the in-memory store.
+func WithPersistDir(dir string) SceneManagerOption {
+	return func(m *SceneManager) {
+		m.persistDir = dir
+	}
+}
+
+// WithHistoryLimit caps how many states the undo ring buffer retains.
+// Defaults to 50.
+func WithHistoryLimit(n int) SceneManagerOption {
+	return func(m *SceneManager) {
+		m.maxHistory = n
+	}
+}
+
+// NewSceneManager creates a SceneManager backed by serializer.
+func NewSceneManager(serializer *Serializer, opts ...SceneManagerOption) *SceneManager {
+	m := &SceneManager{
+		serializer: serializer,
+		scenes:     make(map[string]EngineState),
+		maxHistory: 50,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// SaveScene captures the serializer's current state under name, persisting
+// it to disk too if WithPersistDir was set.
+func (m *SceneManager) SaveScene(name string) error {
+	state := m.serializer.GetState()
+
+	m.mu.Lock()
+	m.scenes[name] = state
+	m.mu.Unlock()
+
+	if m.persistDir == "" {
+		return nil
+	}
+	f, err := os.Create(filepath.Join(m.persistDir, name+".json"))
+	if err != nil {
+		return fmt.Errorf("failed to persist scene %q: %w", name, err)
+	}
+	defer f.Close()
+	return (JSONCodec{}).Encode(f, state)
+}
+
+// LoadScene returns the named scene, checking the in-memory store first
+// and falling back to disk (if WithPersistDir was set) on a miss.
+func (m *SceneManager) LoadScene(name string) (EngineState, bool) {
+	m.mu.Lock()
+	state, ok := m.scenes[name]
+	m.mu.Unlock()
+	if ok {
+		return state, true
+	}
+
+	if m.persistDir == "" {
+		return EngineState{}, false
+	}
+	f, err := os.Open(filepath.Join(m.persistDir, name+".json"))
+	if err != nil {
+		return EngineState{}, false
+	}
+	defer f.Close()
+
+	state, err = (JSONCodec{}).Decode(f)
+	if err != nil {
+		return EngineState{}, false
+	}
+
+	m.mu.Lock()
+	m.scenes[name] = state
+	m.mu.Unlock()
+	return state, true
+}
+
+// DeleteScene removes name from the in-memory store and, if
+// WithPersistDir was set, its on-disk copy.
+func (m *SceneManager) DeleteScene(name string) error {
+	m.mu.Lock()
+	delete(m.scenes, name)
+	m.mu.Unlock()
+
+	if m.persistDir == "" {
+		return nil
+	}
+	path := filepath.Join(m.persistDir, name+".json")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove persisted scene %q: %w", name, err)
+	}
+	return nil
+}
+
+// Scenes lists the names of scenes currently held in memory.
+func (m *SceneManager) Scenes() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.scenes))
+	for name := range m.scenes {
+		names = append(names, name)
+	}
+	return names
+}
+
+// DiffScenes loads the named scenes and reports how they differ via
+// DiffStates, the by-name-keyed equivalent of comparing two EngineStates
+// directly.
+func (m *SceneManager) DiffScenes(from, to string) (SceneDiff, error) {
+	fromState, ok := m.LoadScene(from)
+	if !ok {
+		return SceneDiff{}, fmt.Errorf("scene %q not found", from)
+	}
+	toState, ok := m.LoadScene(to)
+	if !ok {
+		return SceneDiff{}, fmt.Errorf("scene %q not found", to)
+	}
+	return DiffStates(fromState, toState), nil
+}
+
+// ChannelDiff describes how a single channel differs between two states.
+type ChannelDiff struct {
+	ID                 string
+	VolumeChanged      bool
+	OldVolume          float32
+	NewVolume          float32
+	MutedChanged       bool
+	OldMuted           bool
+	NewMuted           bool
+	ConnectionsChanged bool
+	ConfigChanged      map[string][2]interface{} // key -> [old, new]
+}
+
+// SceneDiff is the structural difference between two EngineStates.
+type SceneDiff struct {
+	AddedChannels   []string
+	RemovedChannels []string
+	ChangedChannels []ChannelDiff
+}
+
+// DiffStates compares from and to, reporting added/removed channels and,
+// for channels present in both, which fader/mute/send/connection values
+// changed.
+func DiffStates(from, to EngineState) SceneDiff {
+	var diff SceneDiff
+
+	for id := range to.Channels {
+		if _, ok := from.Channels[id]; !ok {
+			diff.AddedChannels = append(diff.AddedChannels, id)
+		}
+	}
+	for id := range from.Channels {
+		if _, ok := to.Channels[id]; !ok {
+			diff.RemovedChannels = append(diff.RemovedChannels, id)
+		}
+	}
+
+	for id, toCh := range to.Channels {
+		fromCh, ok := from.Channels[id]
+		if !ok {
+			continue
+		}
+
+		cd := ChannelDiff{ID: id}
+		changed := false
+
+		if fromCh.Volume != toCh.Volume {
+			cd.VolumeChanged = true
+			cd.OldVolume = fromCh.Volume
+			cd.NewVolume = toCh.Volume
+			changed = true
+		}
+		if fromCh.Muted != toCh.Muted {
+			cd.MutedChanged = true
+			cd.OldMuted = fromCh.Muted
+			cd.NewMuted = toCh.Muted
+			changed = true
+		}
+		if !connectionsEqual(fromCh.Connections, toCh.Connections) {
+			cd.ConnectionsChanged = true
+			changed = true
+		}
+		for _, key := range crossfadeConfigKeys {
+			oldV, oldOK := fromCh.Config[key]
+			newV, newOK := toCh.Config[key]
+			if oldOK != newOK || oldV != newV {
+				if cd.ConfigChanged == nil {
+					cd.ConfigChanged = make(map[string][2]interface{})
+				}
+				cd.ConfigChanged[key] = [2]interface{}{oldV, newV}
+				changed = true
+			}
+		}
+
+		if changed {
+			diff.ChangedChannels = append(diff.ChangedChannels, cd)
+		}
+	}
+
+	return diff
+}
+
+func connectionsEqual(a, b []Connection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Recall restores the named scene. With crossfade <= 0 it applies
+// instantly; otherwise it interpolates Volume and the sendLevel/
+// returnLevel/monitoringLevel Config values linearly in dB space over
+// crossfade, toggling Muted and any other boolean/structural changes
+// atomically at the midpoint, landing on an exact copy of the target
+// scene at the end.
+func (m *SceneManager) Recall(name string, crossfade time.Duration) error {
+	target, ok := m.LoadScene(name)
+	if !ok {
+		return fmt.Errorf("scene %q not found", name)
+	}
+
+	current := m.serializer.GetState()
+	m.pushHistory(current)
+
+	if crossfade <= 0 {
+		return m.serializer.SetState(target)
+	}
+	return crossfadeTo(m.serializer, current, target, crossfade)
+}
+
+// Morph restores the named scene like Recall does, but steps toward it on
+// a background goroutine using a cosine-eased curve instead of Recall's
+// blocking linear-in-dB crossfade, for a softer, more "automation-like"
+// glide than a console crossfade - the same cosine ease AutomationLane's
+// CurveCosine uses, applied here to a whole-scene morph rather than a
+// single plugin parameter. A Morph already in flight is canceled (and its
+// done channel closed without reaching the target) if superseded by a new
+// Morph or Recall call, the same supersede policy BaseChannel.SetVolumeRamp
+// uses for a superseded ramp. Each step still replays through
+// Serializer.SetState, so it's atomic per step the same way Recall's
+// crossfade is; duration <= 0 snaps instantly, like Recall's crossfade <= 0.
+func (m *SceneManager) Morph(name string, duration time.Duration) (<-chan struct{}, error) {
+	target, ok := m.LoadScene(name)
+	if !ok {
+		return nil, fmt.Errorf("scene %q not found", name)
+	}
+
+	current := m.serializer.GetState()
+	m.pushHistory(current)
+
+	m.morphMu.Lock()
+	if m.morphCancel != nil {
+		close(m.morphCancel)
+	}
+	cancel := make(chan struct{})
+	m.morphCancel = cancel
+	m.morphMu.Unlock()
+
+	done := make(chan struct{})
+	if duration <= 0 {
+		defer close(done)
+		return done, m.serializer.SetState(target)
+	}
+
+	go func() {
+		defer close(done)
+		defer func() {
+			m.morphMu.Lock()
+			if m.morphCancel == cancel {
+				m.morphCancel = nil
+			}
+			m.morphMu.Unlock()
+		}()
+
+		steps := int(duration / CrossfadeStep)
+		if steps < 1 {
+			steps = 1
+		}
+		for i := 1; i <= steps; i++ {
+			eased := cosineEase(float64(i) / float64(steps))
+			if err := m.serializer.SetState(interpolateState(current, target, eased)); err != nil {
+				return
+			}
+			if i == steps {
+				break
+			}
+			select {
+			case <-time.After(CrossfadeStep):
+			case <-cancel:
+				return
+			}
+		}
+	}()
+
+	return done, nil
+}
+
+// cosineEase reshapes a linear 0..1 progress fraction into the classic
+// "ease in, ease out" cosine curve AutomationLane's CurveCosine uses,
+// slower at both ends and fastest through the middle.
+func cosineEase(u float64) float64 {
+	return (1 - math.Cos(u*math.Pi)) / 2
+}
+
+// ApplyState pushes the serializer's current state onto the undo stack
+// and then sets it to state. Use this (instead of calling
+// Serializer.SetState directly) when a caller-driven state change should
+// be undoable through this SceneManager.
+func (m *SceneManager) ApplyState(state EngineState) error {
+	m.pushHistory(m.serializer.GetState())
+	return m.serializer.SetState(state)
+}
+
+func (m *SceneManager) pushHistory(state EngineState) {
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	m.undoStack = append(m.undoStack, state)
+	if len(m.undoStack) > m.maxHistory {
+		m.undoStack = m.undoStack[len(m.undoStack)-m.maxHistory:]
+	}
+	m.redoStack = nil
+}
+
+// Undo restores the state visited immediately before the last
+// Recall/ApplyState call, if any.
+func (m *SceneManager) Undo() error {
+	m.historyMu.Lock()
+	if len(m.undoStack) == 0 {
+		m.historyMu.Unlock()
+		return fmt.Errorf("nothing to undo")
+	}
+	prev := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	current := m.serializer.GetState()
+	m.redoStack = append(m.redoStack, current)
+	m.historyMu.Unlock()
+
+	return m.serializer.SetState(prev)
+}
+
+// Redo reapplies the state undone by the most recent Undo call, if any.
+func (m *SceneManager) Redo() error {
+	m.historyMu.Lock()
+	if len(m.redoStack) == 0 {
+		m.historyMu.Unlock()
+		return fmt.Errorf("nothing to redo")
+	}
+	next := m.redoStack[len(m.redoStack)-1]
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	current := m.serializer.GetState()
+	m.undoStack = append(m.undoStack, current)
+	m.historyMu.Unlock()
+
+	return m.serializer.SetState(next)
+}
+
+func gainToDB(gain float32) float64 {
+	const floorDB = -96.0
+	if gain <= 0 {
+		return floorDB
+	}
+	return 20 * math.Log10(float64(gain))
+}
+
+func dbToGain(db float64) float32 {
+	return float32(math.Pow(10, db/20))
+}
+
+func lerpDB(from, to float32, t float64) float32 {
+	fromDB, toDB := gainToDB(from), gainToDB(to)
+	return dbToGain(fromDB + (toDB-fromDB)*t)
+}
+
+// crossfadeTo steps the serializer from `from` to `to` over duration,
+// interpolating numeric parameters in dB space and snapping everything
+// else (booleans, connections, added/removed channels) at the midpoint.
+func crossfadeTo(serializer *Serializer, from, to EngineState, duration time.Duration) error {
+	steps := int(duration / CrossfadeStep)
+	if steps < 1 {
+		steps = 1
+	}
+
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		if err := serializer.SetState(interpolateState(from, to, t)); err != nil {
+			return fmt.Errorf("crossfade step %d/%d failed: %w", i, steps, err)
+		}
+		if i < steps {
+			time.Sleep(CrossfadeStep)
+		}
+	}
+	return nil
+}
+
+func interpolateState(from, to EngineState, t float64) EngineState {
+	result := to
+	result.Channels = make(map[string]ChannelState, len(to.Channels))
+
+	for id, toCh := range to.Channels {
+		fromCh, ok := from.Channels[id]
+		if !ok {
+			result.Channels[id] = toCh
+			continue
+		}
+
+		ch := toCh
+		ch.Volume = lerpDB(fromCh.Volume, toCh.Volume, t)
+		if t < 0.5 {
+			ch.Muted = fromCh.Muted
+			ch.Connections = fromCh.Connections
+		}
+		if fromCh.Config != nil && toCh.Config != nil {
+			ch.Config = interpolateConfig(fromCh.Config, toCh.Config, t)
+		}
+		result.Channels[id] = ch
+	}
+
+	return result
+}
+
+func interpolateConfig(from, to map[string]interface{}, t float64) map[string]interface{} {
+	result := make(map[string]interface{}, len(to))
+	for k, v := range to {
+		result[k] = v
+	}
+	for _, key := range crossfadeConfigKeys {
+		toV, toOK := to[key].(float64)
+		fromV, fromOK := from[key].(float64)
+		if !toOK || !fromOK {
+			continue
+		}
+		result[key] = float64(lerpDB(float32(fromV), float32(toV), t))
+	}
+	return result
+}
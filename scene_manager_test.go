@@ -0,0 +1,148 @@
+package macaudio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSceneManagerSaveAndRecallInstant(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+	scenes := NewSceneManager(serializer)
+
+	master := eng.GetMasterChannel()
+	if err := master.SetMasterVolume(0.8); err != nil {
+		t.Fatalf("SetMasterVolume failed: %v", err)
+	}
+	if err := scenes.SaveScene("loud"); err != nil {
+		t.Fatalf("SaveScene failed: %v", err)
+	}
+
+	if err := master.SetMasterVolume(0.2); err != nil {
+		t.Fatalf("SetMasterVolume failed: %v", err)
+	}
+
+	if err := scenes.Recall("loud", 0); err != nil {
+		t.Fatalf("Recall failed: %v", err)
+	}
+	volume, err := master.GetMasterVolume()
+	if err != nil {
+		t.Fatalf("GetMasterVolume failed: %v", err)
+	}
+	if volume < 0.79 || volume > 0.81 {
+		t.Fatalf("expected recalled volume ~0.8, got %f", volume)
+	}
+}
+
+func TestSceneManagerUndoRedo(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+	scenes := NewSceneManager(serializer)
+
+	before := serializer.GetState()
+	after := before
+	after.Metadata = map[string]interface{}{"marker": "after"}
+
+	if err := scenes.ApplyState(after); err != nil {
+		t.Fatalf("ApplyState failed: %v", err)
+	}
+	if err := scenes.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if _, ok := serializer.GetState().Metadata["marker"]; ok {
+		t.Fatal("expected Undo to remove the marker metadata")
+	}
+	if err := scenes.Redo(); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if _, ok := serializer.GetState().Metadata["marker"]; !ok {
+		t.Fatal("expected Redo to restore the marker metadata")
+	}
+}
+
+// TestSceneManagerMorphReachesTarget checks that Morph's background,
+// cosine-eased glide lands on an exact copy of the target scene once its
+// done channel closes.
+func TestSceneManagerMorphReachesTarget(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+	scenes := NewSceneManager(serializer)
+
+	master := eng.GetMasterChannel()
+	if err := master.SetMasterVolume(0.9); err != nil {
+		t.Fatalf("SetMasterVolume failed: %v", err)
+	}
+	if err := scenes.SaveScene("bright"); err != nil {
+		t.Fatalf("SaveScene failed: %v", err)
+	}
+
+	if err := master.SetMasterVolume(0.1); err != nil {
+		t.Fatalf("SetMasterVolume failed: %v", err)
+	}
+
+	done, err := scenes.Morph("bright", 60*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Morph failed: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Morph did not complete in time")
+	}
+
+	volume, err := master.GetMasterVolume()
+	if err != nil {
+		t.Fatalf("GetMasterVolume failed: %v", err)
+	}
+	if volume < 0.89 || volume > 0.91 {
+		t.Fatalf("expected morph to land on ~0.9, got %f", volume)
+	}
+}
+
+func TestDiffStatesReportsAddedRemovedAndChanged(t *testing.T) {
+	from := EngineState{
+		Channels: map[string]ChannelState{
+			"a": {ID: "a", Volume: 1.0, Muted: false},
+			"b": {ID: "b", Volume: 0.5, Muted: false},
+		},
+	}
+	to := EngineState{
+		Channels: map[string]ChannelState{
+			"a": {ID: "a", Volume: 0.8, Muted: true},
+			"c": {ID: "c", Volume: 1.0, Muted: false},
+		},
+	}
+
+	diff := DiffStates(from, to)
+	if len(diff.AddedChannels) != 1 || diff.AddedChannels[0] != "c" {
+		t.Errorf("expected channel c to be added, got %+v", diff.AddedChannels)
+	}
+	if len(diff.RemovedChannels) != 1 || diff.RemovedChannels[0] != "b" {
+		t.Errorf("expected channel b to be removed, got %+v", diff.RemovedChannels)
+	}
+	if len(diff.ChangedChannels) != 1 || diff.ChangedChannels[0].ID != "a" {
+		t.Fatalf("expected channel a to be reported changed, got %+v", diff.ChangedChannels)
+	}
+	if !diff.ChangedChannels[0].VolumeChanged || !diff.ChangedChannels[0].MutedChanged {
+		t.Errorf("expected both volume and mute change flags set, got %+v", diff.ChangedChannels[0])
+	}
+}
@@ -0,0 +1,151 @@
+package scenes
+
+import "fmt"
+
+// SendDiff describes how a single bus send differs between two
+// ChannelStates.
+type SendDiff struct {
+	Bus          string
+	LevelChanged bool
+	OldLevel     float32
+	NewLevel     float32
+	MutedChanged bool
+	OldMuted     bool
+	NewMuted     bool
+}
+
+// ChannelDiff describes how a single channel differs between two
+// Snapshots, mirroring the root package's ChannelDiff for scenes'
+// own ChannelState shape.
+type ChannelDiff struct {
+	Name               string
+	VolumeChanged      bool
+	OldVolume          float32
+	NewVolume          float32
+	PanChanged         bool
+	OldPan             float32
+	NewPan             float32
+	MutedChanged       bool
+	OldMuted           bool
+	NewMuted           bool
+	SoloedChanged      bool
+	OldSoloed          bool
+	NewSoloed          bool
+	ConnectionsChanged bool
+	Sends              []SendDiff
+}
+
+// SceneDiff is the structural difference between two Snapshots.
+type SceneDiff struct {
+	AddedChannels   []string
+	RemovedChannels []string
+	ChangedChannels []ChannelDiff
+}
+
+// DiffSnapshots compares from and to, reporting added/removed channels
+// and, for channels present in both, which fader/pan/mute/solo/
+// connection/send values changed - the scenes-package equivalent of the
+// root package's DiffStates, over ChannelState rather than
+// EngineState.Channels.
+func DiffSnapshots(from, to Snapshot) SceneDiff {
+	var diff SceneDiff
+
+	fromByName := indexChannels(from.Channels)
+	toByName := indexChannels(to.Channels)
+
+	for name := range toByName {
+		if _, ok := fromByName[name]; !ok {
+			diff.AddedChannels = append(diff.AddedChannels, name)
+		}
+	}
+	for name := range fromByName {
+		if _, ok := toByName[name]; !ok {
+			diff.RemovedChannels = append(diff.RemovedChannels, name)
+		}
+	}
+
+	for name, toCh := range toByName {
+		fromCh, ok := fromByName[name]
+		if !ok {
+			continue
+		}
+
+		cd := ChannelDiff{Name: name}
+		changed := false
+
+		if fromCh.Volume != toCh.Volume {
+			cd.VolumeChanged = true
+			cd.OldVolume, cd.NewVolume = fromCh.Volume, toCh.Volume
+			changed = true
+		}
+		if fromCh.Pan != toCh.Pan {
+			cd.PanChanged = true
+			cd.OldPan, cd.NewPan = fromCh.Pan, toCh.Pan
+			changed = true
+		}
+		if fromCh.Muted != toCh.Muted {
+			cd.MutedChanged = true
+			cd.OldMuted, cd.NewMuted = fromCh.Muted, toCh.Muted
+			changed = true
+		}
+		if fromCh.Soloed != toCh.Soloed {
+			cd.SoloedChanged = true
+			cd.OldSoloed, cd.NewSoloed = fromCh.Soloed, toCh.Soloed
+			changed = true
+		}
+		if fromCh.ConnectedToMaster != toCh.ConnectedToMaster {
+			cd.ConnectionsChanged = true
+			changed = true
+		}
+
+		fromSends := indexSends(fromCh.Sends)
+		toSends := indexSends(toCh.Sends)
+		seenBus := make(map[string]bool, len(fromSends)+len(toSends))
+		for bus := range fromSends {
+			seenBus[bus] = true
+		}
+		for bus := range toSends {
+			seenBus[bus] = true
+		}
+		for bus := range seenBus {
+			fromSend, inFrom := fromSends[bus]
+			toSend, inTo := toSends[bus]
+			sd := SendDiff{Bus: bus}
+			sdChanged := false
+			if inFrom != inTo || fromSend.Level != toSend.Level {
+				sd.LevelChanged = true
+				sd.OldLevel, sd.NewLevel = fromSend.Level, toSend.Level
+				sdChanged = true
+			}
+			if inFrom != inTo || fromSend.Muted != toSend.Muted {
+				sd.MutedChanged = true
+				sd.OldMuted, sd.NewMuted = fromSend.Muted, toSend.Muted
+				sdChanged = true
+			}
+			if sdChanged {
+				cd.Sends = append(cd.Sends, sd)
+				changed = true
+			}
+		}
+
+		if changed {
+			diff.ChangedChannels = append(diff.ChangedChannels, cd)
+		}
+	}
+
+	return diff
+}
+
+// DiffScenes loads the named snapshots from the Store and reports how
+// they differ via DiffSnapshots.
+func (s *Store) DiffScenes(from, to string) (SceneDiff, error) {
+	fromSnap, ok := s.load(from)
+	if !ok {
+		return SceneDiff{}, fmt.Errorf("scenes: snapshot %q not found", from)
+	}
+	toSnap, ok := s.load(to)
+	if !ok {
+		return SceneDiff{}, fmt.Errorf("scenes: snapshot %q not found", to)
+	}
+	return DiffSnapshots(fromSnap, toSnap), nil
+}
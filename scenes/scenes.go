@@ -0,0 +1,301 @@
+// Package scenes captures the full parameter state of a mixer graph built
+// on the engine/channel + input stack - per-channel pan, volume, mute,
+// master-connection state, bus send levels, and plugin-chain parameters -
+// into a named Snapshot, persists it to disk, and recalls it either
+// instantly or by morphing/crossfading over a caller-specified duration.
+//
+// This is the same job the root package's SceneManager already does for
+// the legacy Serializer/EngineState pair; that one captures a whole
+// engine's state as an opaque, codec-driven EngineState blob and crossfades
+// it by replaying SetState at a fixed step interval. scenes instead works
+// directly against the live channel.Channel/channel.Bus/channel.MasterBus
+// objects this stack already exposes, so a Snapshot's fields line up with
+// the same getters/setters graph.Plan/Apply already use, and Store's morph
+// engine runs on its own ticker (see recall.go) rather than going through a
+// codec round-trip on every step.
+package scenes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/pluginchain"
+	"github.com/shaban/macaudio/engine/channel"
+	"github.com/shaban/macaudio/engine/channel/input"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// MasterState is the master bus's captured level/mute.
+type MasterState struct {
+	Level float32 `json:"level"`
+	Muted bool    `json:"muted"`
+}
+
+// SendState is one channel's captured send to a bus: the bus it feeds, the
+// input index on that bus, and the level/mute at that input.
+type SendState struct {
+	Bus   string  `json:"bus"`
+	Input int     `json:"input"`
+	Level float32 `json:"level"`
+	Muted bool    `json:"muted,omitempty"`
+}
+
+// EffectState is one plugin chain effect's captured bypass flag and
+// writable parameter values. Parameters holds full plugins.Parameter
+// values (with CurrentValue set to what was captured) rather than just a
+// value keyed by address, so restoring it is a direct SetParameter(index,
+// p, p.CurrentValue) call with no separate parameter lookup needed.
+type EffectState struct {
+	Index      int                 `json:"index"`
+	Bypassed   bool                `json:"bypassed"`
+	Parameters []plugins.Parameter `json:"parameters,omitempty"`
+}
+
+// ChannelState is one channel's captured state.
+type ChannelState struct {
+	Name              string        `json:"name"`
+	Pan               float32       `json:"pan"`
+	Volume            float32       `json:"volume"`
+	Muted             bool          `json:"muted"`
+	Soloed            bool          `json:"soloed,omitempty"`
+	ConnectedToMaster bool          `json:"connectedToMaster"`
+	Sends             []SendState   `json:"sends,omitempty"`
+	Effects           []EffectState `json:"effects,omitempty"`
+}
+
+// Snapshot is a named capture of a mixer graph's full parameter state, as
+// Store.Save produces it and Store.RecallImmediate/RecallMorph/Crossfade
+// consume it.
+type Snapshot struct {
+	Name     string         `json:"name"`
+	Master   *MasterState   `json:"master,omitempty"`
+	Channels []ChannelState `json:"channels"`
+}
+
+// SendSource is one bus send Save should capture for a channel. Bus tracks
+// which native node is connected at an input internally, but doesn't
+// expose that mapping back out, so the caller that made the
+// Bus.ConnectChannel call is the only one who still knows which bus/input
+// pair belongs to which channel.
+type SendSource struct {
+	Bus   *channel.Bus
+	Input int
+}
+
+// ChannelSource pairs a live channel with the sends Save should capture
+// for it.
+type ChannelSource struct {
+	Channel *input.MonoToStereoChannel
+	Sends   []SendSource
+}
+
+// Store holds named Snapshots - in memory, and on disk under dir if it's
+// non-empty - plus the live engine/channel/bus objects Save registered
+// them from, so a later RecallImmediate/RecallMorph/Crossfade call has
+// something to apply values back to. It mirrors the root package's
+// SceneManager, with persistence split the same optional way
+// WithPersistDir makes it there.
+type Store struct {
+	dir string
+
+	mu        sync.Mutex
+	snapshots map[string]Snapshot
+
+	eng      *avengine.Engine
+	channels map[string]*input.MonoToStereoChannel
+	buses    map[string]*channel.Bus
+	master   *channel.MasterBus
+
+	morphMu     sync.Mutex
+	morphCancel chan struct{}
+
+	historyMu  sync.Mutex
+	undoStack  []Snapshot
+	redoStack  []Snapshot
+	maxHistory int
+}
+
+// StoreOption configures a Store at construction time.
+type StoreOption func(*Store)
+
+// WithHistoryLimit caps how many snapshots the undo ring buffer retains.
+// Defaults to 50, the same default the root package's SceneManager uses.
+func WithHistoryLimit(n int) StoreOption {
+	return func(s *Store) {
+		s.maxHistory = n
+	}
+}
+
+// NewStore creates a Store. Pass a non-empty dir to also persist every
+// Save as a JSON file under it (created if missing); pass "" for an
+// in-memory-only Store.
+func NewStore(dir string, opts ...StoreOption) (*Store, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("scenes: create store dir %q: %w", dir, err)
+		}
+	}
+	s := &Store{
+		dir:        dir,
+		snapshots:  make(map[string]Snapshot),
+		channels:   make(map[string]*input.MonoToStereoChannel),
+		buses:      make(map[string]*channel.Bus),
+		maxHistory: 50,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Save captures the current state of every channel in channels (and
+// master, if non-nil) into a Snapshot named name, registers the live
+// objects so RecallImmediate/RecallMorph/Crossfade can apply values back
+// to them later, and persists the snapshot to disk if the Store has a dir.
+func (s *Store) Save(name string, eng *avengine.Engine, channels []ChannelSource, master *channel.MasterBus) error {
+	snap := Snapshot{Name: name, Channels: make([]ChannelState, 0, len(channels))}
+
+	s.mu.Lock()
+	s.eng = eng
+	s.master = master
+	s.mu.Unlock()
+
+	for _, src := range channels {
+		cs, err := captureChannel(src)
+		if err != nil {
+			return fmt.Errorf("scenes: capture channel %q: %w", src.Channel.GetName(), err)
+		}
+		snap.Channels = append(snap.Channels, cs)
+
+		s.mu.Lock()
+		s.channels[cs.Name] = src.Channel
+		for _, send := range src.Sends {
+			s.buses[send.Bus.Name()] = send.Bus
+		}
+		s.mu.Unlock()
+	}
+
+	if master != nil {
+		level, err := master.GetLevel()
+		if err != nil {
+			return fmt.Errorf("scenes: capture master level: %w", err)
+		}
+		snap.Master = &MasterState{Level: level, Muted: master.GetMute()}
+	}
+
+	s.mu.Lock()
+	s.snapshots[name] = snap
+	s.mu.Unlock()
+
+	if s.dir == "" {
+		return nil
+	}
+	return s.persist(snap)
+}
+
+func captureChannel(src ChannelSource) (ChannelState, error) {
+	ch := src.Channel
+	volume, err := ch.GetVolume()
+	if err != nil {
+		return ChannelState{}, err
+	}
+	muted, err := ch.GetMute()
+	if err != nil {
+		return ChannelState{}, err
+	}
+
+	cs := ChannelState{
+		Name:              ch.GetName(),
+		Pan:               ch.GetPan(),
+		Volume:            volume,
+		Muted:             muted,
+		Soloed:            ch.IsSoloed(),
+		ConnectedToMaster: ch.IsConnectedToMaster(),
+		Effects:           captureEffects(ch.GetPluginChain()),
+	}
+	for _, send := range src.Sends {
+		level, err := send.Bus.GetInputLevel(send.Input)
+		if err != nil {
+			return ChannelState{}, fmt.Errorf("get send level on bus %q input %d: %w", send.Bus.Name(), send.Input, err)
+		}
+		cs.Sends = append(cs.Sends, SendState{
+			Bus: send.Bus.Name(), Input: send.Input,
+			Level: level, Muted: send.Bus.GetInputMute(send.Input),
+		})
+	}
+	return cs, nil
+}
+
+// captureEffects reads back every writable parameter of every effect in
+// pc, via the real Get/SetParameter round-trip this chain's automation
+// driver and async controller already go through - not
+// analyze.AnalyzePluginChain, whose ChainAnalysis is an audio measurement
+// (RMS/gain/spectral) rather than a settable parameter snapshot.
+func captureEffects(pc *pluginchain.PluginChain) []EffectState {
+	if pc == nil {
+		return nil
+	}
+	n := pc.GetEffectCount()
+	effects := make([]EffectState, 0, n)
+	for i := 0; i < n; i++ {
+		_, plugin, err := pc.GetEffectAt(i)
+		if err != nil || plugin == nil {
+			continue
+		}
+		bypassed, _ := pc.IsEffectBypassed(i)
+		params := make([]plugins.Parameter, 0, len(plugin.Parameters))
+		for _, p := range plugin.Parameters {
+			if !p.IsWritable {
+				continue
+			}
+			v, err := pc.GetParameter(i, p)
+			if err != nil {
+				continue
+			}
+			p.CurrentValue = v
+			params = append(params, p)
+		}
+		effects = append(effects, EffectState{Index: i, Bypassed: bypassed, Parameters: params})
+	}
+	return effects
+}
+
+func (s *Store) persist(snap Snapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("scenes: marshal snapshot %q: %w", snap.Name, err)
+	}
+	return os.WriteFile(filepath.Join(s.dir, snap.Name+".json"), data, 0o644)
+}
+
+// load returns the named Snapshot, checking the in-memory store first and
+// falling back to disk (if the Store has a dir) on a miss - the same
+// dual lookup the root package's SceneManager.LoadScene does.
+func (s *Store) load(name string) (Snapshot, bool) {
+	s.mu.Lock()
+	snap, ok := s.snapshots[name]
+	s.mu.Unlock()
+	if ok {
+		return snap, true
+	}
+	if s.dir == "" {
+		return Snapshot{}, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, name+".json"))
+	if err != nil {
+		return Snapshot{}, false
+	}
+	var loaded Snapshot
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return Snapshot{}, false
+	}
+	s.mu.Lock()
+	s.snapshots[name] = loaded
+	s.mu.Unlock()
+	return loaded, true
+}
@@ -0,0 +1,216 @@
+package scenes
+
+import (
+	"testing"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/avaudio/sourcenode"
+	"github.com/shaban/macaudio/engine/analyze"
+	"github.com/shaban/macaudio/engine/channel/input"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// newToneChannel wires a tone source into a fresh MonoToStereoChannel
+// connected to master, the same rig TestPlanApplyConvergesAndIsIdempotent
+// (graph package) uses to get real, measurable audio through a channel.
+func newToneChannel(t *testing.T, eng *avengine.Engine, name string, pan, volume float32) (*sourcenode.SourceNode, *input.MonoToStereoChannel) {
+	t.Helper()
+
+	toneNode, err := sourcenode.NewTone()
+	if err != nil {
+		t.Fatalf("new tone node: %v", err)
+	}
+	toneNodePtr, err := toneNode.GetNodePtr()
+	if err != nil || toneNodePtr == nil {
+		t.Fatalf("tone node ptr: %v", err)
+	}
+	if err := eng.Attach(toneNodePtr); err != nil {
+		t.Fatalf("attach tone node: %v", err)
+	}
+
+	ch, err := input.NewMonoToStereo(input.MonoToStereoConfig{Name: name, Engine: eng, InitialPan: pan})
+	if err != nil {
+		t.Fatalf("new mono channel: %v", err)
+	}
+	if err := ch.SetVolume(volume); err != nil {
+		t.Fatalf("set volume: %v", err)
+	}
+	if err := ch.ConnectToMaster(eng); err != nil {
+		t.Fatalf("connect to master: %v", err)
+	}
+	if err := eng.Connect(toneNodePtr, ch.GetInputNode(), 0, 0); err != nil {
+		t.Fatalf("connect tone to channel input: %v", err)
+	}
+
+	return toneNode, ch
+}
+
+// TestSaveAndRecallImmediate saves a scene, mutates the live channel's
+// pan/volume, recalls the scene, and checks via analyze.AnalyzeMonoToStereo
+// that the resulting audio's measured pan and level match what was
+// snapshotted, within the same PanTolerance ValidateStereoAnalysis uses.
+func TestSaveAndRecallImmediate(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	toneNode, lead := newToneChannel(t, eng, "lead", -0.5, 0.8)
+	defer toneNode.Destroy()
+	defer lead.Release()
+
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save("scene", eng, []ChannelSource{{Channel: lead}}, nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if err := lead.SetPan(0.9); err != nil {
+		t.Fatalf("mutate pan: %v", err)
+	}
+	if err := lead.SetVolume(0.2); err != nil {
+		t.Fatalf("mutate volume: %v", err)
+	}
+
+	if err := store.RecallImmediate("scene"); err != nil {
+		t.Fatalf("recall: %v", err)
+	}
+	if lead.GetPan() != -0.5 {
+		t.Fatalf("expected pan restored to -0.5, got %.2f", lead.GetPan())
+	}
+
+	testutil.MuteMainMixer(t, eng)
+	if err := eng.Start(); err != nil {
+		t.Fatalf("start engine: %v", err)
+	}
+	defer func() {
+		if eng.IsRunning() {
+			eng.Stop()
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	config := analyze.DefaultAnalysisConfig()
+	config.SampleDuration = 100 * time.Millisecond
+	result, err := analyze.AnalyzeMonoToStereo(eng.Ptr(), lead.GetInputNode(), lead.GetOutputNode(), lead.GetPan(), lead.GetPanLaw(), config)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if result.TotalRMS <= 0 {
+		t.Errorf("expected audible signal after recall, got RMS %.6f", result.TotalRMS)
+	}
+	if diff := math32Abs(result.PanPosition - lead.GetPan()); diff > config.PanTolerance {
+		t.Errorf("expected measured pan within %.2f of %.2f, got %.2f", config.PanTolerance, lead.GetPan(), result.PanPosition)
+	}
+}
+
+func math32Abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// TestRecallMorphReachesTarget checks that RecallMorph lands exactly on the
+// saved scene's values once its duration elapses, even though the channel
+// started somewhere else.
+func TestRecallMorphReachesTarget(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	toneNode, lead := newToneChannel(t, eng, "lead", 0.0, 0.9)
+	defer toneNode.Destroy()
+	defer lead.Release()
+
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save("scene", eng, []ChannelSource{{Channel: lead}}, nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if err := lead.SetVolume(0.1); err != nil {
+		t.Fatalf("mutate volume: %v", err)
+	}
+
+	done, err := store.RecallMorph("scene", 40*time.Millisecond)
+	if err != nil {
+		t.Fatalf("recall morph: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("morph never completed")
+	}
+
+	if v, err := lead.GetVolume(); err != nil || v != 0.9 {
+		t.Errorf("expected volume to land on 0.9 after morph, got %.2f (err %v)", v, err)
+	}
+}
+
+// TestCrossfadeConnectsAndDisconnects checks that Crossfade-ing between a
+// scene without a channel and a scene with it connects and ramps that
+// channel in, and the reverse disconnects it, rather than popping it in or
+// out at full volume.
+func TestCrossfadeConnectsAndDisconnects(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	toneNode, pad := newToneChannel(t, eng, "pad", 0.0, 0.7)
+	defer toneNode.Destroy()
+	defer pad.Release()
+
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save("withPad", eng, []ChannelSource{{Channel: pad}}, nil); err != nil {
+		t.Fatalf("save withPad: %v", err)
+	}
+	if err := pad.DisconnectFromMaster(eng); err != nil {
+		t.Fatalf("disconnect pad: %v", err)
+	}
+	if err := store.Save("withoutPad", eng, nil, nil); err != nil {
+		t.Fatalf("save withoutPad: %v", err)
+	}
+
+	done, err := store.Crossfade("withoutPad", "withPad", 40*time.Millisecond)
+	if err != nil {
+		t.Fatalf("crossfade in: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("crossfade in never completed")
+	}
+	if !pad.IsConnectedToMaster() {
+		t.Fatalf("expected pad connected to master after crossfading in")
+	}
+	if v, err := pad.GetVolume(); err != nil || v != 0.7 {
+		t.Errorf("expected pad volume to land on 0.7 after crossfading in, got %.2f (err %v)", v, err)
+	}
+
+	done, err = store.Crossfade("withPad", "withoutPad", 40*time.Millisecond)
+	if err != nil {
+		t.Fatalf("crossfade out: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("crossfade out never completed")
+	}
+	if pad.IsConnectedToMaster() {
+		t.Errorf("expected pad disconnected from master after crossfading out")
+	}
+}
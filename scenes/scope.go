@@ -0,0 +1,23 @@
+package scenes
+
+// Scope selects which captured fields RecallImmediateScoped/
+// RecallMorphScoped/CrossfadeScoped actually apply to the live graph,
+// leaving every field it doesn't select untouched - e.g. a Scope with only
+// Faders set recalls volume without touching pan, mute, solo, sends, or
+// effects. RecallImmediate/RecallMorph/Crossfade are unchanged and always
+// apply FullScope(), so existing callers that don't care about partial
+// recall see no behavior change.
+type Scope struct {
+	Faders  bool
+	Pans    bool
+	Mutes   bool
+	Solo    bool
+	Sends   bool
+	Effects bool
+}
+
+// FullScope selects every field - what RecallImmediate/RecallMorph/
+// Crossfade use internally.
+func FullScope() Scope {
+	return Scope{Faders: true, Pans: true, Mutes: true, Solo: true, Sends: true, Effects: true}
+}
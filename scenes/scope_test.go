@@ -0,0 +1,129 @@
+package scenes
+
+import (
+	"testing"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/internal/testutil"
+)
+
+// TestRecallImmediateScopedLeavesOtherFieldsAlone checks that a Scope with
+// only Faders set restores volume without touching a pan change made after
+// the scene was saved.
+func TestRecallImmediateScopedLeavesOtherFieldsAlone(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	toneNode, lead := newToneChannel(t, eng, "lead", -0.5, 0.8)
+	defer toneNode.Destroy()
+	defer lead.Release()
+
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save("scene", eng, []ChannelSource{{Channel: lead}}, nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if err := lead.SetPan(0.9); err != nil {
+		t.Fatalf("mutate pan: %v", err)
+	}
+	if err := lead.SetVolume(0.2); err != nil {
+		t.Fatalf("mutate volume: %v", err)
+	}
+
+	if err := store.RecallImmediateScoped("scene", Scope{Faders: true}); err != nil {
+		t.Fatalf("recall scoped: %v", err)
+	}
+	if v, err := lead.GetVolume(); err != nil || v != 0.8 {
+		t.Errorf("expected volume restored to 0.8, got %.2f (err %v)", v, err)
+	}
+	if lead.GetPan() != 0.9 {
+		t.Errorf("expected pan left at 0.9 (out of scope), got %.2f", lead.GetPan())
+	}
+}
+
+// TestUndoRestoresPreRecallState checks that Undo reverses the effect of a
+// RecallImmediate call.
+func TestUndoRestoresPreRecallState(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	toneNode, lead := newToneChannel(t, eng, "lead", 0.0, 0.8)
+	defer toneNode.Destroy()
+	defer lead.Release()
+
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save("scene", eng, []ChannelSource{{Channel: lead}}, nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if err := lead.SetVolume(0.3); err != nil {
+		t.Fatalf("mutate volume: %v", err)
+	}
+
+	if err := store.RecallImmediate("scene"); err != nil {
+		t.Fatalf("recall: %v", err)
+	}
+	if v, err := lead.GetVolume(); err != nil || v != 0.8 {
+		t.Fatalf("expected volume 0.8 after recall, got %.2f (err %v)", v, err)
+	}
+
+	if err := store.Undo(); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if v, err := lead.GetVolume(); err != nil || v != 0.3 {
+		t.Errorf("expected volume restored to pre-recall 0.3 after undo, got %.2f (err %v)", v, err)
+	}
+}
+
+// TestDiffSnapshotsReportsChangedVolume checks that DiffSnapshots flags a
+// channel's volume as changed, with the old/new values, when two snapshots
+// of the same channel differ only in volume.
+func TestDiffSnapshotsReportsChangedVolume(t *testing.T) {
+	eng, err := avengine.New(testutil.SmallSpec())
+	if err != nil {
+		t.Fatalf("engine new: %v", err)
+	}
+	defer eng.Destroy()
+
+	toneNode, lead := newToneChannel(t, eng, "lead", 0.0, 0.8)
+	defer toneNode.Destroy()
+	defer lead.Release()
+
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save("before", eng, []ChannelSource{{Channel: lead}}, nil); err != nil {
+		t.Fatalf("save before: %v", err)
+	}
+	if err := lead.SetVolume(0.4); err != nil {
+		t.Fatalf("mutate volume: %v", err)
+	}
+	if err := store.Save("after", eng, []ChannelSource{{Channel: lead}}, nil); err != nil {
+		t.Fatalf("save after: %v", err)
+	}
+
+	diff, err := store.DiffScenes("before", "after")
+	if err != nil {
+		t.Fatalf("diff scenes: %v", err)
+	}
+	if len(diff.ChangedChannels) != 1 {
+		t.Fatalf("expected exactly 1 changed channel, got %d", len(diff.ChangedChannels))
+	}
+	cd := diff.ChangedChannels[0]
+	if cd.Name != "lead" || !cd.VolumeChanged || cd.OldVolume != 0.8 || cd.NewVolume != 0.4 {
+		t.Errorf("unexpected channel diff: %+v", cd)
+	}
+}
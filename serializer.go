@@ -4,164 +4,318 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"sync"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
 )
 
 // EngineState represents the complete serializable state of the engine
 type EngineState struct {
-	Version        string                 `json:"version"`
-	Configuration  EngineConfig           `json:"configuration"`
-	Channels       map[string]ChannelState `json:"channels"`
-	Connections    []Connection           `json:"connections"`
-	Timestamp      int64                  `json:"timestamp"`
-	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Version       string                  `json:"version"`
+	Configuration EngineConfig            `json:"configuration"`
+	Channels      map[string]ChannelState `json:"channels"`
+	Connections   []Connection            `json:"connections"`
+	Timestamp     int64                   `json:"timestamp"`
+	Metadata      map[string]interface{}  `json:"metadata,omitempty"`
+
+	// ContentHash is the SHA-256 hex digest of this state's canonical JSON
+	// encoding with ContentHash/Signature/SignerID zeroed, populated by
+	// GetState and checked by ValidateState/LoadVerifiedFromReader so a
+	// tampered show file is caught instead of silently loaded.
+	ContentHash string `json:"contentHash,omitempty"`
+	// Signature and SignerID are only set by SaveSignedToWriter.
+	Signature string `json:"signature,omitempty"`
+	SignerID  string `json:"signerId,omitempty"`
 }
 
 // Serializer handles engine state persistence and restoration
 type Serializer struct {
-	engine  *Engine
-	mu      sync.RWMutex
-	version string
+	engine   *Engine
+	mu       sync.RWMutex
+	version  string
+	onChange func(EngineState)
+
+	eventMu   sync.Mutex
+	eventSubs []*eventSubscriber
 }
 
 // NewSerializer creates a new serializer
 func NewSerializer(engine *Engine) *Serializer {
 	return &Serializer{
 		engine:  engine,
-		version: "1.0.0", // Engine state format version
+		version: "1.1.0", // Engine state format version
 	}
 }
 
+// OnStateChange registers fn to be called with the new state every time
+// SetState successfully mutates the engine, so something like an OSCServer
+// can broadcast the change to connected control surfaces without polling
+// GetState. Only one hook is kept; registering again replaces it.
+func (s *Serializer) OnStateChange(fn func(EngineState)) {
+	s.mu.Lock()
+	s.onChange = fn
+	s.mu.Unlock()
+}
+
 // GetState captures the complete engine state
 func (s *Serializer) GetState() EngineState {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	
+
 	// Get all channel states
 	channels := make(map[string]ChannelState)
 	allConnections := make([]Connection, 0)
-	
+
 	for id, channel := range s.engine.channels {
 		state := channel.GetState()
 		channels[id] = state
-		
+
 		// Collect all connections
 		for _, conn := range state.Connections {
 			allConnections = append(allConnections, conn)
 		}
 	}
-	
-	return EngineState{
+
+	state := EngineState{
 		Version:       s.version,
 		Configuration: s.engine.GetConfiguration(),
 		Channels:      channels,
 		Connections:   allConnections,
-		Timestamp:     0, // TODO: Add actual timestamp
+		Timestamp:     time.Now().UnixNano(),
 		Metadata:      make(map[string]interface{}),
 	}
+
+	if hash, err := computeContentHash(state); err == nil {
+		state.ContentHash = hash
+	}
+	return state
 }
 
 // SetState restores the engine from the given state
 func (s *Serializer) SetState(state EngineState) error {
+	before := s.GetState()
+
+	if err := s.setState(state); err != nil {
+		return err
+	}
+
+	after := s.GetState()
+
+	s.mu.RLock()
+	onChange := s.onChange
+	s.mu.RUnlock()
+	if onChange != nil {
+		onChange(after)
+	}
+
+	s.publishEvent(patchStates(before, after))
+	return nil
+}
+
+func (s *Serializer) setState(state EngineState) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	
+
 	// Version compatibility check
 	if state.Version != s.version {
-		return fmt.Errorf("incompatible state version: got %s, expected %s", 
+		return fmt.Errorf("incompatible state version: got %s, expected %s",
 			state.Version, s.version)
 	}
-	
+
+	masterID := s.engine.masterChannel.GetIDString()
+
 	// Clear existing channels (except master)
 	for id := range s.engine.channels {
-		if id != "master" {
+		if id != masterID {
 			if err := s.engine.removeChannel(id); err != nil {
 				return fmt.Errorf("failed to remove channel %s during state restore: %w", id, err)
 			}
 		}
 	}
-	
+
 	// Restore channels
 	for id, channelState := range state.Channels {
-		if id == "master" {
+		if id == masterID || channelState.Type == ChannelTypeMaster {
 			// Update master channel state
 			if err := s.engine.masterChannel.SetState(channelState); err != nil {
 				return fmt.Errorf("failed to restore master channel state: %w", err)
 			}
 			continue
 		}
-		
+
 		// Create new channel based on type
 		channel, err := s.createChannelFromState(id, channelState)
 		if err != nil {
 			return fmt.Errorf("failed to create channel %s from state: %w", id, err)
 		}
-		
+
 		// Add to engine
 		if err := s.engine.addChannel(channel); err != nil {
 			return fmt.Errorf("failed to add restored channel %s: %w", id, err)
 		}
-		
+
 		// Restore channel state
 		if err := channel.SetState(channelState); err != nil {
 			return fmt.Errorf("failed to restore state for channel %s: %w", id, err)
 		}
 	}
-	
+
 	// Restore connections (handled by channel state restoration)
-	
+
 	return nil
 }
 
-// SaveToWriter saves the engine state to a writer (JSON format)
-func (s *Serializer) SaveToWriter(writer io.Writer) error {
+// SaveToWriter saves the engine state to a writer, JSON by default. Pass
+// WithCodec to write TOML, YAML, or any other registered Codec instead.
+func (s *Serializer) SaveToWriter(writer io.Writer, opts ...SerializerOption) error {
+	resolved := resolveOptions(opts)
 	state := s.GetState()
-	
-	encoder := json.NewEncoder(writer)
-	encoder.SetIndent("", "  ") // Pretty print
-	
-	if err := encoder.Encode(state); err != nil {
+
+	if err := resolved.codec.Encode(writer, state); err != nil {
 		return fmt.Errorf("failed to encode engine state: %w", err)
 	}
-	
 	return nil
 }
 
-// LoadFromReader loads engine state from a reader (JSON format)
-func (s *Serializer) LoadFromReader(reader io.Reader) error {
-	var state EngineState
-	
-	decoder := json.NewDecoder(reader)
-	if err := decoder.Decode(&state); err != nil {
+// LoadFromReader loads engine state from a reader, JSON by default (pass
+// WithCodec for TOML/YAML/etc). Only the default JSONCodec path applies
+// schema migrations (see RegisterMigration) today, since migration walks
+// a generic JSON object; a non-JSON codec's state must already be at the
+// serializer's current version.
+func (s *Serializer) LoadFromReader(reader io.Reader, opts ...SerializerOption) error {
+	resolved := resolveOptions(opts)
+
+	if _, isJSON := resolved.codec.(JSONCodec); isJSON {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read engine state: %w", err)
+		}
+
+		state, err := s.decodeWithMigrations(data)
+		if err != nil {
+			return err
+		}
+		return s.SetState(state)
+	}
+
+	state, err := resolved.codec.Decode(reader)
+	if err != nil {
 		return fmt.Errorf("failed to decode engine state: %w", err)
 	}
-	
 	return s.SetState(state)
 }
 
+// SaveToFile saves the engine state to path, picking JSON/TOML/YAML by
+// path's extension (.json, .toml, .yaml/.yml), defaulting to JSON for
+// anything else.
+func (s *Serializer) SaveToFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return s.SaveToWriter(f, WithCodec(codecForExtension(path)))
+}
+
+// LoadFromFile loads engine state from path, picking JSON/TOML/YAML by
+// path's extension the same way SaveToFile does.
+func (s *Serializer) LoadFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return s.LoadFromReader(f, WithCodec(codecForExtension(path)))
+}
+
+// LoadSession loads engine state from reader (JSON, the same schema
+// LoadFromReader decodes) and then re-resolves every channel's plugin chain
+// through resolver instead of PluginInstance.Load's hardcoded introspection,
+// applying policy to any instance resolver can't resolve (see
+// PluginChain.LoadWithResolver). This is the rig-portability path: a session
+// saved on one machine with SaveToWriter/SaveToFile/SaveToJSON - there's no
+// separate "SaveSession", since EngineState already carries every channel's
+// full PluginChainState - can be reloaded on another whose installed Audio
+// Units don't exactly match, without LoadFromReader's fixed "introspect or
+// mark not-installed" behavior.
+func (s *Serializer) LoadSession(reader io.Reader, resolver PluginResolver, policy MissingPluginPolicy) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read engine state: %w", err)
+	}
+
+	state, err := s.decodeWithMigrations(data)
+	if err != nil {
+		return err
+	}
+
+	if err := s.SetState(state); err != nil {
+		return err
+	}
+
+	for id, channelState := range state.Channels {
+		channel, ok := s.engine.GetChannel(id)
+		if !ok {
+			continue
+		}
+		if err := channel.GetPluginChain().LoadWithResolver(channelState.PluginChain, resolver, policy); err != nil {
+			return fmt.Errorf("failed to resolve plugin chain for channel %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
 // SaveToJSON returns the engine state as JSON string
 func (s *Serializer) SaveToJSON() (string, error) {
 	state := s.GetState()
-	
+
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal engine state: %w", err)
 	}
-	
+
 	return string(data), nil
 }
 
-// LoadFromJSON restores engine state from JSON string
+// LoadFromJSON restores engine state from a JSON string, migrating it from
+// an older Version first if needed (see LoadFromReader).
 func (s *Serializer) LoadFromJSON(jsonData string) error {
-	var state EngineState
-	
-	if err := json.Unmarshal([]byte(jsonData), &state); err != nil {
-		return fmt.Errorf("failed to unmarshal engine state: %w", err)
+	state, err := s.decodeWithMigrations([]byte(jsonData))
+	if err != nil {
+		return err
 	}
-	
+
 	return s.SetState(state)
 }
 
+// decodeWithMigrations decodes data into a generic map, walks it up to the
+// serializer's current version through any registered migrations if its
+// Version differs, and returns the resulting typed EngineState. If its
+// Version already matches, it's decoded directly with no migration step.
+func (s *Serializer) decodeWithMigrations(data []byte) (EngineState, error) {
+	var probe struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return EngineState{}, fmt.Errorf("failed to decode engine state: %w", err)
+	}
+
+	if probe.Version == s.version {
+		var state EngineState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return EngineState{}, fmt.Errorf("failed to decode engine state: %w", err)
+		}
+		return state, nil
+	}
+
+	return applyMigrations(data, s.version)
+}
+
 // createChannelFromState creates a channel instance from serialized state
 func (s *Serializer) createChannelFromState(id string, state ChannelState) (Channel, error) {
 	switch state.Type {
@@ -180,7 +334,7 @@ func (s *Serializer) createChannelFromState(id string, state ChannelState) (Chan
 			}
 		}
 		return NewAudioInputChannel(id, config, s.engine)
-		
+
 	case ChannelTypeMidiInput:
 		// Extract MIDI input config from state.Config
 		config := MidiInputConfig{}
@@ -193,7 +347,7 @@ func (s *Serializer) createChannelFromState(id string, state ChannelState) (Chan
 			}
 		}
 		return NewMidiInputChannel(id, config, s.engine)
-		
+
 	case ChannelTypePlayback:
 		// Extract playback config from state.Config
 		config := PlaybackConfig{}
@@ -215,7 +369,7 @@ func (s *Serializer) createChannelFromState(id string, state ChannelState) (Chan
 			}
 		}
 		return NewPlaybackChannel(id, config, s.engine)
-		
+
 	case ChannelTypeAux:
 		// Extract aux config from state.Config
 		config := AuxConfig{}
@@ -229,9 +383,76 @@ func (s *Serializer) createChannelFromState(id string, state ChannelState) (Chan
 			if preFader, ok := state.Config["preFader"].(bool); ok {
 				config.PreFader = preFader
 			}
+			if targets, ok := state.Config["sidechainTargets"].([]interface{}); ok {
+				for _, t := range targets {
+					if target, ok := t.(string); ok {
+						config.SidechainTargets = append(config.SidechainTargets, target)
+					}
+				}
+			}
 		}
 		return NewAuxChannel(id, config, s.engine)
-		
+
+	case ChannelTypeProcessing:
+		// ProcessingConfig.Callback is a Go func and can't round-trip through
+		// JSON, so a processing channel can't be reconstructed from saved
+		// state - fail clearly instead of silently dropping the callback.
+		return nil, fmt.Errorf("channel type %s cannot be restored from saved state: its Callback is not serializable", state.Type)
+
+	case ChannelTypeSynth:
+		// Extract synth config from state.Config
+		config := SynthConfig{}
+		if state.Config != nil {
+			if kind, ok := state.Config["kind"].(string); ok {
+				config.Kind = SynthKind(kind)
+			}
+			if frequency, ok := state.Config["frequency"].(float64); ok {
+				config.Frequency = frequency
+			}
+			if amplitude, ok := state.Config["amplitude"].(float64); ok {
+				config.Amplitude = amplitude
+			}
+			if noise, ok := state.Config["noise"].(string); ok {
+				config.Noise = NoiseKind(noise)
+			}
+			if startFrequency, ok := state.Config["startFrequency"].(float64); ok {
+				config.StartFrequency = startFrequency
+			}
+			if endFrequency, ok := state.Config["endFrequency"].(float64); ok {
+				config.EndFrequency = endFrequency
+			}
+			if durationNs, ok := state.Config["duration"].(float64); ok {
+				config.Duration = time.Duration(durationNs)
+			}
+		}
+		return NewSynthChannel(id, config, s.engine)
+
+	case ChannelTypeBus:
+		// Extract bus config from state.Config
+		config := BusConfig{}
+		if state.Config != nil {
+			if specMap, ok := state.Config["spec"].(map[string]interface{}); ok {
+				spec := avengine.EnhancedAudioSpec{}
+				if sampleRate, ok := specMap["sampleRate"].(float64); ok {
+					spec.SampleRate = sampleRate
+				}
+				if bufferSize, ok := specMap["bufferSize"].(float64); ok {
+					spec.BufferSize = int(bufferSize)
+				}
+				if bitDepth, ok := specMap["bitDepth"].(float64); ok {
+					spec.SampleFormat = avengine.SampleFormatFromBitDepth(int(bitDepth))
+				}
+				if channelCount, ok := specMap["channelCount"].(float64); ok {
+					spec.ChannelCount = int(channelCount)
+				}
+				if interleaved, ok := specMap["interleaved"].(bool); ok {
+					spec.Interleaved = interleaved
+				}
+				config.Spec = &spec
+			}
+		}
+		return NewBus(id, config, s.engine)
+
 	default:
 		return nil, fmt.Errorf("unknown channel type: %s", state.Type)
 	}
@@ -244,9 +465,11 @@ func (s *Serializer) GetVersion() string {
 
 // IsCompatible checks if a state version is compatible with current serializer
 func (s *Serializer) IsCompatible(version string) bool {
-	// For now, only exact version match
-	// In the future, this could handle backward compatibility
-	return version == s.version
+	if version == s.version {
+		return true
+	}
+	_, ok := migrationPath(version, s.version)
+	return ok
 }
 
 // ValidateState validates the integrity of an engine state
@@ -255,18 +478,32 @@ func (s *Serializer) ValidateState(state EngineState) error {
 	if !s.IsCompatible(state.Version) {
 		return fmt.Errorf("incompatible state version: %s", state.Version)
 	}
-	
+
+	// Content hash check, independent of whether the state is signed
+	if state.ContentHash != "" {
+		if err := ValidateContentHash(state); err != nil {
+			return err
+		}
+	}
+
 	// Master channel must exist
-	if _, exists := state.Channels["master"]; !exists {
+	hasMaster := false
+	for _, ch := range state.Channels {
+		if ch.Type == ChannelTypeMaster {
+			hasMaster = true
+			break
+		}
+	}
+	if !hasMaster {
 		return fmt.Errorf("master channel missing from state")
 	}
-	
+
 	// Validate channel references in connections
 	channelIDs := make(map[string]bool)
 	for id := range state.Channels {
 		channelIDs[id] = true
 	}
-	
+
 	for _, conn := range state.Connections {
 		if !channelIDs[conn.SourceChannel] {
 			return fmt.Errorf("connection references unknown source channel: %s", conn.SourceChannel)
@@ -275,6 +512,6 @@ func (s *Serializer) ValidateState(state EngineState) error {
 			return fmt.Errorf("connection references unknown target channel: %s", conn.TargetChannel)
 		}
 	}
-	
+
 	return nil
 }
@@ -0,0 +1,280 @@
+//go:build darwin
+
+package session
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// defaultAggregateReadyTimeout bounds how long CreateAggregate waits for a
+// newly created aggregate to finish assembling before giving up; see
+// AggregateSpec.ReadyTimeout to override it per call.
+const defaultAggregateReadyTimeout = 5 * time.Second
+
+// aggregateReadyPollInterval is the fallback re-check period
+// waitForAggregateReady uses alongside devices.WatchHardwareChanges,
+// mirroring Session.pollInterval's role as monitorDevices' fallback for the
+// same CoreAudio property listener.
+const aggregateReadyPollInterval = 50 * time.Millisecond
+
+// AggregateSpec configures a CoreAudio aggregate device to create via
+// Session.CreateAggregate. Its fields mirror devices.AggregateSpec (see
+// that type for what each maps to in AudioHardwareCreateAggregateDevice)
+// plus ReadyTimeout, since CreateAggregate blocks until the device is
+// actually usable instead of returning as soon as CoreAudio acknowledges
+// the create call.
+type AggregateSpec struct {
+	// UID requests a specific UID for the new aggregate; leave empty to
+	// let CoreAudio generate one.
+	UID string
+	// Name is the aggregate's display name.
+	Name string
+	// SubDeviceUIDs lists the physical devices to compose; at least 2 are
+	// required.
+	SubDeviceUIDs []string
+	// MasterUID names the sub-device whose clock drives the aggregate.
+	// Must be one of SubDeviceUIDs; defaults to the first sub-device if
+	// empty.
+	MasterUID string
+	// Private marks the aggregate as not appearing in other processes'
+	// device lists.
+	Private bool
+	// DriftCompensate enables per-subdevice clock drift compensation at
+	// creation time, keyed by sub-device UID; see AggregateDevice.
+	// SetDriftCompensation to change it afterward.
+	DriftCompensate map[string]bool
+	// Stackable requests a stacked aggregate rather than CoreAudio's
+	// default multi-output arrangement. Not yet plumbed through
+	// devices.CreateAggregate - see devices.AggregateSpec - so it is
+	// currently accepted but has no effect.
+	Stackable bool
+	// ReadyTimeout bounds how long to wait for all SubDeviceUIDs to be
+	// reported under the new aggregate and for its channel count to go
+	// non-zero. <=0 uses defaultAggregateReadyTimeout.
+	ReadyTimeout time.Duration
+}
+
+// AggregateDevice is a live aggregate created via Session.CreateAggregate.
+// It keeps the owning Session so Destroy and SetDriftCompensation can fold
+// the teardown/reconfiguration back into the Session's device cache and
+// DeviceChange stream without the caller having to call ForceRefresh
+// itself.
+type AggregateDevice struct {
+	UID    string
+	Device devices.AudioDevice
+
+	session *Session
+}
+
+// CreateAggregate creates a CoreAudio aggregate device from spec and waits
+// for it to become usable before returning. CoreAudio acknowledges
+// AudioHardwareCreateAggregateDevice before it has finished populating the
+// aggregate's owned sub-device list and stream count, so a caller that
+// connects an engine to the returned device immediately - especially from a
+// goroutine racing the main thread - can otherwise be handed a
+// partially-initialized aggregate. waitForAggregateReady blocks on a
+// sync.Cond woken by devices.WatchHardwareChanges (and a fallback poll)
+// until that settles or spec.ReadyTimeout elapses.
+func (s *Session) CreateAggregate(spec AggregateSpec) (*AggregateDevice, error) {
+	if len(spec.SubDeviceUIDs) < 2 {
+		return nil, fmt.Errorf("aggregate device needs at least 2 sub-devices, got %d", len(spec.SubDeviceUIDs))
+	}
+
+	device, err := devices.CreateAggregate(devices.AggregateSpec{
+		UID:             spec.UID,
+		Name:            spec.Name,
+		SubDeviceUIDs:   spec.SubDeviceUIDs,
+		MasterUID:       spec.MasterUID,
+		Private:         spec.Private,
+		DriftCompensate: spec.DriftCompensate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := spec.ReadyTimeout
+	if timeout <= 0 {
+		timeout = defaultAggregateReadyTimeout
+	}
+	ready, err := waitForAggregateReady(device.UID, spec.SubDeviceUIDs, timeout)
+	if err != nil {
+		// Don't leave a half-formed aggregate registered with CoreAudio.
+		_ = devices.DestroyAggregate(device.UID)
+		return nil, err
+	}
+
+	s.refreshAndNotifyAggregateChange(ReasonDevicesAdded)
+
+	return &AggregateDevice{UID: ready.UID, Device: ready, session: s}, nil
+}
+
+// UseAggregate is CreateAggregate's shorthand for the common case: compose
+// subDeviceUIDs into a private aggregate clocked off masterUID (or the
+// first sub-device if masterUID is ""), with drift compensation on for
+// every non-master sub-device - the usual answer to "combine a USB input
+// with the built-in output" or "run two interfaces as one device" without
+// the caller assembling a full AggregateSpec. Binding an engine to the
+// result needs nothing new: OpenInputStream/OpenOutputStream already
+// select a device purely by UID, so the returned AggregateDevice.UID is a
+// drop-in inputDeviceUID/outputDeviceUID for either.
+func (s *Session) UseAggregate(subDeviceUIDs []string, masterUID string) (*AggregateDevice, error) {
+	if len(subDeviceUIDs) < 2 {
+		return nil, fmt.Errorf("aggregate device needs at least 2 sub-devices, got %d", len(subDeviceUIDs))
+	}
+
+	return s.CreateAggregate(aggregateSpecFor(subDeviceUIDs, masterUID))
+}
+
+// aggregateSpecFor builds UseAggregate's AggregateSpec: masterUID defaults
+// to the first sub-device, and every other sub-device gets drift
+// compensation turned on, since a non-master sub-device's clock isn't the
+// one the aggregate is synced to.
+func aggregateSpecFor(subDeviceUIDs []string, masterUID string) AggregateSpec {
+	if masterUID == "" {
+		masterUID = subDeviceUIDs[0]
+	}
+
+	drift := make(map[string]bool, len(subDeviceUIDs))
+	for _, uid := range subDeviceUIDs {
+		if uid != masterUID {
+			drift[uid] = true
+		}
+	}
+
+	return AggregateSpec{
+		Name:            "macaudio-aggregate",
+		SubDeviceUIDs:   subDeviceUIDs,
+		MasterUID:       masterUID,
+		Private:         true,
+		DriftCompensate: drift,
+	}
+}
+
+// waitForAggregateReady blocks until uid's AudioDevice reports every UID in
+// wantSubUIDs among its sub-devices and a non-zero channel count, or
+// timeout elapses. It wakes on real CoreAudio device-topology
+// notifications once devices.WatchHardwareChanges' native trampoline is
+// wired up, and on aggregateReadyPollInterval regardless, so it still
+// converges today without that trampoline.
+func waitForAggregateReady(uid string, wantSubUIDs []string, timeout time.Duration) (devices.AudioDevice, error) {
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+	broadcast := func() {
+		mu.Lock()
+		cond.Broadcast()
+		mu.Unlock()
+	}
+
+	if uninstall, err := devices.WatchHardwareChanges(broadcast); err == nil {
+		defer uninstall()
+	}
+
+	stopPoll := make(chan struct{})
+	defer close(stopPoll)
+	go func() {
+		ticker := time.NewTicker(aggregateReadyPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopPoll:
+				return
+			case <-ticker.C:
+				broadcast()
+			}
+		}
+	}()
+
+	deadline := time.Now().Add(timeout)
+	timer := time.AfterFunc(timeout, broadcast)
+	defer timer.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for {
+		mu.Unlock()
+		audioDevices, err := devices.GetAudio()
+		mu.Lock()
+		if err == nil {
+			if d := audioDevices.ByUID(uid); d != nil && aggregateIsReady(*d, wantSubUIDs) {
+				return *d, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return devices.AudioDevice{}, fmt.Errorf("aggregate %q did not finish assembling within %s", uid, timeout)
+		}
+		cond.Wait()
+	}
+}
+
+// aggregateIsReady reports whether device's recorded sub-device membership
+// covers every UID in wantSubUIDs and CoreAudio has given it a non-zero
+// channel count - the signal that it finished assembling rather than just
+// acknowledging the create call.
+func aggregateIsReady(device devices.AudioDevice, wantSubUIDs []string) bool {
+	if device.InputChannelCount == 0 && device.OutputChannelCount == 0 {
+		return false
+	}
+	have := make(map[string]bool, len(device.AggregateSubDeviceUIDs))
+	for _, u := range device.AggregateSubDeviceUIDs {
+		have[u] = true
+	}
+	for _, want := range wantSubUIDs {
+		if !have[want] {
+			return false
+		}
+	}
+	return true
+}
+
+// Destroy tears down a, removing it from CoreAudio and folding the removal
+// back into the owning Session's device cache and DeviceChange stream.
+func (a *AggregateDevice) Destroy() error {
+	if err := devices.DestroyAggregate(a.UID); err != nil {
+		return err
+	}
+	a.session.refreshAndNotifyAggregateChange(ReasonDevicesRemoved)
+	return nil
+}
+
+// SetDriftCompensation toggles drift compensation for one of a's
+// sub-devices after creation, the live counterpart to
+// AggregateSpec.DriftCompensate.
+func (a *AggregateDevice) SetDriftCompensation(uid string, on bool) error {
+	if err := devices.SetAggregateDriftCompensation(a.UID, uid, on); err != nil {
+		return err
+	}
+	a.session.refreshAndNotifyAggregateChange(ReasonDefaultChanged)
+	return nil
+}
+
+// refreshAndNotifyAggregateChange re-scans audio devices after an aggregate
+// create/destroy/reconfigure and emits a DeviceChange the same way
+// checkForChangesAsync does for hardware-driven changes, so consumers don't
+// have to special-case aggregates created through CreateAggregate.
+func (s *Session) refreshAndNotifyAggregateChange(reason ChangeReason) {
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		return
+	}
+
+	s.deviceMutex.Lock()
+	s.audioDevices = audioDevices
+	s.lastUpdate = time.Now()
+	s.deviceMutex.Unlock()
+
+	atomic.StoreInt64(&s.audioCount, int64(len(audioDevices)))
+
+	s.notifyChange(DeviceChange{
+		Type:         AudioDeviceChange,
+		Reason:       reason,
+		Timestamp:    time.Now(),
+		AudioCount:   len(audioDevices),
+		MIDICount:    int(atomic.LoadInt64(&s.midiCount)),
+		AudioDevices: &audioDevices,
+	})
+}
@@ -0,0 +1,73 @@
+//go:build darwin
+
+package session
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+func TestAggregateIsReadyRequiresNonZeroChannelCount(t *testing.T) {
+	d := devices.AudioDevice{AggregateSubDeviceUIDs: []string{"a", "b"}}
+	if aggregateIsReady(d, []string{"a", "b"}) {
+		t.Error("expected not ready with zero input/output channel counts")
+	}
+}
+
+func TestAggregateIsReadyRequiresAllSubDeviceUIDs(t *testing.T) {
+	d := devices.AudioDevice{InputChannelCount: 2, AggregateSubDeviceUIDs: []string{"a"}}
+	if aggregateIsReady(d, []string{"a", "b"}) {
+		t.Error("expected not ready when a sub-device UID is missing")
+	}
+}
+
+func TestAggregateIsReadyOnceChannelsAndSubDevicesPresent(t *testing.T) {
+	d := devices.AudioDevice{OutputChannelCount: 2, AggregateSubDeviceUIDs: []string{"a", "b"}}
+	if !aggregateIsReady(d, []string{"a", "b"}) {
+		t.Error("expected ready once channel count is non-zero and all sub-devices are present")
+	}
+}
+
+func TestAggregateSpecForDefaultsMasterToFirstSubDevice(t *testing.T) {
+	spec := aggregateSpecFor([]string{"usb-in", "builtin-out"}, "")
+	if spec.MasterUID != "usb-in" {
+		t.Errorf("expected MasterUID to default to the first sub-device, got %q", spec.MasterUID)
+	}
+	if spec.DriftCompensate["usb-in"] {
+		t.Error("expected the master sub-device to not have drift compensation enabled")
+	}
+	if !spec.DriftCompensate["builtin-out"] {
+		t.Error("expected every non-master sub-device to have drift compensation enabled")
+	}
+	if !spec.Private {
+		t.Error("expected UseAggregate's spec to be process-private")
+	}
+}
+
+func TestAggregateSpecForHonorsExplicitMaster(t *testing.T) {
+	spec := aggregateSpecFor([]string{"a", "b", "c"}, "b")
+	if spec.MasterUID != "b" {
+		t.Errorf("expected MasterUID %q, got %q", "b", spec.MasterUID)
+	}
+	for _, uid := range []string{"a", "c"} {
+		if !spec.DriftCompensate[uid] {
+			t.Errorf("expected sub-device %q to have drift compensation enabled", uid)
+		}
+	}
+	if spec.DriftCompensate["b"] {
+		t.Error("expected the explicit master to not have drift compensation enabled")
+	}
+}
+
+func TestUseAggregateRequiresAtLeastTwoSubDevices(t *testing.T) {
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("NewSessionWithDefaults: %v", err)
+	}
+	defer sess.Close()
+
+	if _, err := sess.UseAggregate([]string{"only-one"}, ""); err == nil {
+		t.Error("expected UseAggregate to reject fewer than 2 sub-devices")
+	}
+}
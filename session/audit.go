@@ -0,0 +1,407 @@
+//go:build darwin
+
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default tuning for EnableAudit when a zero-value field is left unset -
+// see DefaultAuditConfig.
+const (
+	defaultAuditChannelBuffer = 256
+	defaultAuditMaxFileSize   = 10 * 1024 * 1024
+	defaultAuditRetention     = 5
+)
+
+// EventType discriminates an AuditEvent's Payload shape.
+type EventType string
+
+const (
+	EventDeviceFound    EventType = "device_found"
+	EventDeviceLost     EventType = "device_lost"
+	EventDeviceChanged  EventType = "device_changed"
+	EventScanStarted    EventType = "scan_started"
+	EventScanFinished   EventType = "scan_finished"
+	EventCacheWrite     EventType = "cache_write"
+	EventCacheReconcile EventType = "cache_reconcile"
+	EventOrphanCleanup  EventType = "orphan_cleanup"
+	EventSessionOpen    EventType = "session_open"
+	EventSessionClose   EventType = "session_close"
+)
+
+// AuditEvent is one entry in the audit NDJSON stream. Seq is monotonically
+// increasing per-session (not persisted across EnableAudit calls), so a log
+// reader or subscriber can detect drops from DroppedAuditEvents.
+type AuditEvent struct {
+	Seq     uint64      `json:"seq"`
+	Time    time.Time   `json:"time"`
+	Type    EventType   `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// DeviceEventPayload backs EventDeviceFound/Lost/Changed.
+type DeviceEventPayload struct {
+	DeviceUUID string `json:"deviceUUID"`
+	Kind       string `json:"kind"` // "audio" or "midi"
+	Name       string `json:"name"`
+}
+
+// ScanEventPayload backs EventScanFinished, mirroring the CacheHit/ScanTime/
+// ChangedCount fields already reported per-request on PluginResult.
+type ScanEventPayload struct {
+	CacheHit     bool          `json:"cacheHit"`
+	ScanTime     time.Duration `json:"scanTime"`
+	ChangedCount int           `json:"changedCount"`
+}
+
+// CacheEventPayload backs EventCacheWrite/EventCacheReconcile.
+type CacheEventPayload struct {
+	Key string `json:"key,omitempty"`
+	Op  string `json:"op"`
+}
+
+// OrphanEventPayload backs EventOrphanCleanup.
+type OrphanEventPayload struct {
+	Key string `json:"key"`
+}
+
+// SessionEventPayload backs EventSessionOpen/EventSessionClose.
+type SessionEventPayload struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// AuditConfig configures EnableAudit. Use DefaultAuditConfig and override
+// individual fields rather than constructing one from scratch.
+type AuditConfig struct {
+	// Writer, if non-nil, receives every event in addition to (or instead
+	// of, if EnableFile is false) the on-disk rotating log - tests typically
+	// set this to a bytes.Buffer instead of touching disk.
+	Writer io.Writer
+	// EnableFile turns on the rotating file sink under
+	// MACAUDIO_CACHE_DIR/audit/audit.ndjson.
+	EnableFile bool
+	// MaxFileSizeBytes rotates the active log once it grows past this size.
+	MaxFileSizeBytes int64
+	// RetentionCount caps how many rotated files (audit.ndjson.1, .2, ...)
+	// are kept; the oldest beyond this count is deleted on rotation.
+	RetentionCount int
+	// ChannelBuffer sizes the internal queue between emitAudit and the
+	// writer goroutine. Once full, further events are dropped rather than
+	// blocking the caller - see DroppedAuditEvents.
+	ChannelBuffer int
+}
+
+// DefaultAuditConfig returns the settings EnableAudit uses for any
+// zero-valued field: file sink on, 10MB rotation, 5 files retained, a
+// 256-event queue.
+func DefaultAuditConfig() AuditConfig {
+	return AuditConfig{
+		EnableFile:       true,
+		MaxFileSizeBytes: defaultAuditMaxFileSize,
+		RetentionCount:   defaultAuditRetention,
+		ChannelBuffer:    defaultAuditChannelBuffer,
+	}
+}
+
+// auditState holds everything AuditService needs, separate from the rest of
+// Session the same way scanState is - so a session that never calls
+// EnableAudit pays no extra cost.
+type auditState struct {
+	mu         sync.Mutex
+	enabled    bool
+	seq        uint64
+	queue      chan AuditEvent
+	writer     io.Writer
+	rotating   *rotatingWriter
+	subs       map[int64]chan AuditEvent
+	nextSubID  int64
+	writerDone chan struct{}
+	dropped    atomic.Uint64
+}
+
+// EnableAudit starts the audit event stream: every observable session event
+// (device found/lost/changed, plugin scan lifecycle, cache writes/
+// reconciles, orphan cleanup, session open/close) is emitted as one NDJSON
+// line to cfg.Writer and/or a rotating file under
+// MACAUDIO_CACHE_DIR/audit/audit.ndjson, and fanned out to any subscriber
+// from SubscribeAudit. Writes are non-blocking: a stalled consumer (full
+// queue) causes events to be dropped and counted (DroppedAuditEvents)
+// rather than back-pressuring the audio session. Calling EnableAudit again
+// replaces the previous configuration.
+func (s *Session) EnableAudit(cfg AuditConfig) error {
+	if cfg.ChannelBuffer <= 0 {
+		cfg.ChannelBuffer = defaultAuditChannelBuffer
+	}
+
+	var writers []io.Writer
+	if cfg.Writer != nil {
+		writers = append(writers, cfg.Writer)
+	}
+
+	var rw *rotatingWriter
+	if cfg.EnableFile {
+		dir, err := auditDir()
+		if err != nil {
+			return err
+		}
+		maxSize := cfg.MaxFileSizeBytes
+		if maxSize <= 0 {
+			maxSize = defaultAuditMaxFileSize
+		}
+		retention := cfg.RetentionCount
+		if retention <= 0 {
+			retention = defaultAuditRetention
+		}
+		rw, err = newRotatingWriter(filepath.Join(dir, "audit.ndjson"), maxSize, retention)
+		if err != nil {
+			return err
+		}
+		writers = append(writers, rw)
+	}
+
+	s.DisableAudit()
+
+	s.audit.mu.Lock()
+	s.audit.enabled = true
+	s.audit.seq = 0
+	s.audit.queue = make(chan AuditEvent, cfg.ChannelBuffer)
+	s.audit.rotating = rw
+	if len(writers) == 1 {
+		s.audit.writer = writers[0]
+	} else if len(writers) > 1 {
+		s.audit.writer = io.MultiWriter(writers...)
+	} else {
+		s.audit.writer = nil
+	}
+	s.audit.subs = make(map[int64]chan AuditEvent)
+	s.audit.writerDone = make(chan struct{})
+	queue, done := s.audit.queue, s.audit.writerDone
+	s.audit.mu.Unlock()
+
+	go s.runAuditWriter(queue, done)
+	s.emitAudit(EventSessionOpen, SessionEventPayload{})
+	return nil
+}
+
+// DisableAudit stops the audit stream started by EnableAudit (a no-op if
+// it was never enabled), emitting a final EventSessionClose, closing every
+// subscriber channel, and closing the rotating log file.
+func (s *Session) DisableAudit() {
+	s.audit.mu.Lock()
+	if !s.audit.enabled {
+		s.audit.mu.Unlock()
+		return
+	}
+	s.audit.seq++
+	ev := AuditEvent{Seq: s.audit.seq, Time: time.Now(), Type: EventSessionClose}
+	select {
+	case s.audit.queue <- ev:
+	default:
+		s.audit.dropped.Add(1)
+	}
+	s.audit.enabled = false
+	close(s.audit.queue)
+	writerDone := s.audit.writerDone
+	rw := s.audit.rotating
+	s.audit.mu.Unlock()
+
+	<-writerDone // wait for the writer to drain so subs see every queued event first
+
+	s.audit.mu.Lock()
+	subs := s.audit.subs
+	s.audit.subs = nil
+	s.audit.mu.Unlock()
+	for _, ch := range subs {
+		close(ch)
+	}
+	if rw != nil {
+		rw.Close()
+	}
+}
+
+// DroppedAuditEvents reports how many events have been dropped because the
+// internal queue was full (a stalled consumer), across the lifetime of the
+// current EnableAudit call.
+func (s *Session) DroppedAuditEvents() uint64 { return s.audit.dropped.Load() }
+
+// SubscribeAudit returns a channel of every future audit event and a
+// CancelFunc that unsubscribes and closes the channel. Safe to call whether
+// or not EnableAudit has run yet; events simply won't flow until it has.
+func (s *Session) SubscribeAudit(buffer int) (<-chan AuditEvent, CancelFunc) {
+	if buffer <= 0 {
+		buffer = 16
+	}
+	ch := make(chan AuditEvent, buffer)
+
+	s.audit.mu.Lock()
+	if s.audit.subs == nil {
+		s.audit.subs = make(map[int64]chan AuditEvent)
+	}
+	id := s.audit.nextSubID
+	s.audit.nextSubID++
+	s.audit.subs[id] = ch
+	s.audit.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.audit.mu.Lock()
+			if s.audit.subs != nil {
+				delete(s.audit.subs, id)
+			}
+			s.audit.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, CancelFunc(cancel)
+}
+
+// emitAudit is the one producer-side entry point every call site below
+// uses. It's a no-op (cheap lock+check) when auditing isn't enabled.
+func (s *Session) emitAudit(t EventType, payload interface{}) {
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+	if !s.audit.enabled {
+		return
+	}
+	s.audit.seq++
+	ev := AuditEvent{Seq: s.audit.seq, Time: time.Now(), Type: t, Payload: payload}
+	select {
+	case s.audit.queue <- ev:
+	default:
+		s.audit.dropped.Add(1)
+	}
+}
+
+func (s *Session) runAuditWriter(queue chan AuditEvent, done chan struct{}) {
+	defer close(done)
+	for ev := range queue {
+		s.writeAuditEvent(ev)
+		s.fanOutAudit(ev)
+	}
+}
+
+func (s *Session) writeAuditEvent(ev AuditEvent) {
+	s.audit.mu.Lock()
+	w := s.audit.writer
+	s.audit.mu.Unlock()
+	if w == nil {
+		return
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+	_, _ = w.Write(b)
+}
+
+func (s *Session) fanOutAudit(ev AuditEvent) {
+	s.audit.mu.Lock()
+	subs := make([]chan AuditEvent, 0, len(s.audit.subs))
+	for _, ch := range s.audit.subs {
+		subs = append(subs, ch)
+	}
+	s.audit.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- ev:
+		default:
+			// Subscriber's buffer is full; drop rather than block the writer.
+		}
+	}
+}
+
+func auditDir() (string, error) {
+	dir, err := getPluginCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "audit")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// rotatingWriter is an io.WriteCloser that rotates the underlying file once
+// it exceeds maxSize, keeping up to retention rotated copies
+// (path.1 newest .. path.retention oldest) and dropping anything older.
+type rotatingWriter struct {
+	mu        sync.Mutex
+	path      string
+	maxSize   int64
+	retention int
+	file      *os.File
+	size      int64
+}
+
+func newRotatingWriter(path string, maxSize int64, retention int) (*rotatingWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &rotatingWriter{path: path, maxSize: maxSize, retention: retention, file: f, size: info.Size()}, nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	if w.retention > 0 {
+		oldest := fmt.Sprintf("%s.%d", w.path, w.retention)
+		_ = os.Remove(oldest)
+		for i := w.retention - 1; i >= 1; i-- {
+			src := fmt.Sprintf("%s.%d", w.path, i)
+			dst := fmt.Sprintf("%s.%d", w.path, i+1)
+			if _, err := os.Stat(src); err == nil {
+				_ = os.Rename(src, dst)
+			}
+		}
+		if err := os.Rename(w.path, w.path+".1"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.size = 0
+	return nil
+}
+
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
@@ -0,0 +1,127 @@
+//go:build darwin
+
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditEmitsSessionOpenAndDeviceEvents(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-audit-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	var buf bytes.Buffer
+	cfg := DefaultAuditConfig()
+	cfg.Writer = &buf
+	cfg.EnableFile = false
+	if err := sess.EnableAudit(cfg); err != nil {
+		t.Fatalf("EnableAudit: %v", err)
+	}
+
+	events, cancel := sess.SubscribeAudit(8)
+	defer cancel()
+
+	sess.emitDeviceAudit(DeviceUpdate{Kind: Found, DeviceUUID: "uuid-1", Audio: nil})
+
+	var got AuditEvent
+	select {
+	case got = <-events:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscribed audit event")
+	}
+	if got.Type != EventDeviceFound {
+		t.Errorf("Type = %q, want %q", got.Type, EventDeviceFound)
+	}
+
+	sess.DisableAudit()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected at least session_open + device_found + session_close, got %d lines: %q", len(lines), buf.String())
+	}
+	var first AuditEvent
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("unmarshal first line: %v", err)
+	}
+	if first.Type != EventSessionOpen {
+		t.Errorf("first event Type = %q, want %q", first.Type, EventSessionOpen)
+	}
+	var last AuditEvent
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("unmarshal last line: %v", err)
+	}
+	if last.Type != EventSessionClose {
+		t.Errorf("last event Type = %q, want %q", last.Type, EventSessionClose)
+	}
+}
+
+func TestAuditDropsEventsWhenQueueIsFull(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-audit-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	cfg := AuditConfig{EnableFile: false, ChannelBuffer: 1}
+	if err := sess.EnableAudit(cfg); err != nil {
+		t.Fatalf("EnableAudit: %v", err)
+	}
+	defer sess.DisableAudit()
+
+	for i := 0; i < 50; i++ {
+		sess.emitAudit(EventDeviceFound, DeviceEventPayload{DeviceUUID: "flood"})
+	}
+
+	if sess.DroppedAuditEvents() == 0 {
+		t.Error("expected some events to be dropped once the queue filled up")
+	}
+}
+
+func TestRotatingWriterRotatesOnSize(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-audit-rotate-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := tempDir + "/audit.ndjson"
+	w, err := newRotatingWriter(path, 16, 2)
+	if err != nil {
+		t.Fatalf("newRotatingWriter: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("0123456789\n")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected rotated file %s.1 to exist: %v", path, err)
+	}
+}
@@ -0,0 +1,146 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// AutoWarmPolicy configures Session.StartAutoWarm.
+type AutoWarmPolicy struct {
+	// OnStartup runs one warm pass as soon as StartAutoWarm is called,
+	// instead of waiting for the first device change or Interval tick.
+	OnStartup bool
+	// OnDeviceChange triggers a warm pass (coalesced with any other pending
+	// trigger, see autoWarmState) on every OnDeviceChange callback.
+	OnDeviceChange bool
+	// Interval additionally triggers a warm pass on a fixed tick if > 0.
+	Interval time.Duration
+	// Concurrency is passed through to the underlying WarmCtx call; <=0
+	// keeps WarmCtx's own default.
+	Concurrency int
+	// Selector further narrows which of the diff's Added/Changed plugins get
+	// warmed, same as Warm/WarmCtx's selector parameter; nil warms all of
+	// them.
+	Selector func(plugins.PluginInfo) bool
+}
+
+// autoWarmState holds StartAutoWarm/StopAutoWarm's goroutine plumbing,
+// separate from the rest of Session the same way scanState and auditState
+// are.
+type autoWarmState struct {
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	trigger chan struct{}
+}
+
+// StartAutoWarm begins the opt-in background warmer: a RefreshQuickCtx runs
+// on every trigger enabled by policy (startup, device change, and/or
+// Interval tick), and only the diff's Added/Changed keys are warmed instead
+// of re-introspecting the whole index, so a quiet session with nothing new
+// does near-zero work per pass. The warmer's goroutine is tied to the
+// session context - it stops on Close without a separate StopAutoWarm call -
+// but StopAutoWarm is available for an earlier, independent shutdown.
+// Calling StartAutoWarm again replaces the previous policy (it calls
+// StopAutoWarm first).
+func (s *Session) StartAutoWarm(policy AutoWarmPolicy) {
+	s.StopAutoWarm()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	trigger := make(chan struct{}, 1)
+
+	s.autoWarm.mu.Lock()
+	s.autoWarm.cancel = cancel
+	s.autoWarm.trigger = trigger
+	s.autoWarm.mu.Unlock()
+
+	if policy.OnDeviceChange {
+		s.OnDeviceChange(func(DeviceChange) {
+			select {
+			case trigger <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	go s.runAutoWarm(ctx, policy, trigger)
+
+	if policy.OnStartup {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// StopAutoWarm stops the background warmer started by StartAutoWarm; a
+// no-op if it was never started or has already been stopped.
+func (s *Session) StopAutoWarm() {
+	s.autoWarm.mu.Lock()
+	cancel := s.autoWarm.cancel
+	s.autoWarm.cancel = nil
+	s.autoWarm.trigger = nil
+	s.autoWarm.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// runAutoWarm is StartAutoWarm's goroutine body: it waits on trigger (fed by
+// OnDeviceChange and the initial OnStartup signal) and policy.Interval's
+// ticker, running at most one pass at a time - a burst of triggers while a
+// pass is in flight coalesces to a single queued rerun via trigger's buffer
+// of 1, and a tick that lands while a manual trigger is already pending
+// drains it first so the two don't cause back-to-back passes.
+func (s *Session) runAutoWarm(ctx context.Context, policy AutoWarmPolicy, trigger chan struct{}) {
+	var tickC <-chan time.Time
+	if policy.Interval > 0 {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+		tickC = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-trigger:
+			s.autoWarmPass(ctx, policy)
+		case <-tickC:
+			select {
+			case <-trigger:
+			default:
+			}
+			s.autoWarmPass(ctx, policy)
+		}
+	}
+}
+
+// autoWarmPass runs one RefreshQuickCtx + targeted WarmCtx cycle and reports
+// it via MetricsHook.OnAutoWarmTick.
+func (s *Session) autoWarmPass(ctx context.Context, policy AutoWarmPolicy) {
+	t0 := time.Now()
+	diff, err := s.RefreshQuickCtx(ctx)
+	if err != nil {
+		return
+	}
+	if len(diff.Added) == 0 && len(diff.Changed) == 0 {
+		if s.hook != nil { s.hook.OnAutoWarmTick(0, 0, time.Since(t0)) }
+		return
+	}
+	pending := make(map[string]bool, len(diff.Added)+len(diff.Changed))
+	for _, k := range diff.Added { pending[k] = true }
+	for _, k := range diff.Changed { pending[k] = true }
+	selector := func(info plugins.PluginInfo) bool {
+		key := quadKey(info.Type, info.Subtype, info.ManufacturerID, info.Name)
+		if !pending[key] {
+			return false
+		}
+		return policy.Selector == nil || policy.Selector(info)
+	}
+	_ = s.WarmCtx(ctx, selector, policy.Concurrency)
+	if s.hook != nil { s.hook.OnAutoWarmTick(len(diff.Added), len(diff.Changed), time.Since(t0)) }
+}
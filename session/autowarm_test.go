@@ -0,0 +1,60 @@
+//go:build darwin
+
+package session
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// autoWarmTickRecorder is a nopMetricsHook that additionally captures every
+// OnAutoWarmTick call on a buffered channel, for tests that only care about
+// that one callback.
+type autoWarmTickRecorder struct {
+	nopMetricsHook
+	ticks chan struct{}
+}
+
+func (r *autoWarmTickRecorder) OnAutoWarmTick(added, changed int, dur time.Duration) {
+	select {
+	case r.ticks <- struct{}{}:
+	default:
+	}
+}
+
+func TestStartAutoWarmRunsOnStartupAndStops(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	recorder := &autoWarmTickRecorder{ticks: make(chan struct{}, 4)}
+	sess.SetMetricsHook(recorder)
+
+	sess.StartAutoWarm(AutoWarmPolicy{OnStartup: true})
+	defer sess.StopAutoWarm()
+
+	select {
+	case <-recorder.ticks:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an OnAutoWarmTick after OnStartup")
+	}
+
+	// StopAutoWarm, then StartAutoWarm again, should be safe to call
+	// repeatedly and in either order without panicking.
+	sess.StopAutoWarm()
+	sess.StopAutoWarm()
+	sess.StartAutoWarm(AutoWarmPolicy{})
+	sess.StartAutoWarm(AutoWarmPolicy{OnStartup: true})
+	sess.StopAutoWarm()
+}
@@ -0,0 +1,190 @@
+//go:build darwin
+
+package session
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	aveng "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// BufferManager decouples the frame count a render/tap callback delivers
+// from the frame count the app side wants to process in - the situation
+// ResolveEngineSpec's BufferSize (what's forced on the HAL) and
+// AudioSpec.ProcessingFrames (what the app actually wants) describe, e.g.
+// an effect block written for 512 frames fed by a 128-frame HAL. It's an
+// SPSC ring buffer in the shape of cubeb-coreaudio's buffer_manager: Push
+// is called from the render/tap thread, Pull from the app thread, and
+// nothing but the two atomic frame counters is shared between them - no
+// lock sits on the hot path.
+//
+// The same type serves both directions: Push from a capture tap + Pull
+// from the app is the input side, Push from an app fill routine + Pull
+// from a render/fill callback is the output side. Which one a given
+// BufferManager is wired up for is purely a matter of which side calls
+// Push and which calls Pull.
+type BufferManager struct {
+	ring         []float32 // interleaved, capacityFrames*channelCount samples
+	channelCount int
+	capacity     uint64 // capacityFrames, a power of two isn't required - indices wrap via %
+
+	// head and tail count frames written/read since the BufferManager was
+	// created, not wrapped into the ring - the actual ring index is
+	// (head/tail % capacity). Monotonic counters avoid the ambiguity a
+	// wrapped index has between "empty" and "full" at the same position.
+	head atomic.Uint64 // advanced only by Push
+	tail atomic.Uint64 // advanced only by Pull
+
+	underruns atomic.Uint64
+	overruns  atomic.Uint64
+}
+
+// BufferStats reports a BufferManager's running underrun/overrun counts -
+// Pull being asked for more frames than are available, and Push producing
+// frames faster than Pull drains them, respectively.
+type BufferStats struct {
+	Underruns uint64
+	Overruns  uint64
+}
+
+// NewBufferManager creates a BufferManager sized to hold at least
+// 2*max(halFrames, appFrames) frames - cubeb-coreaudio's rule of thumb for
+// giving the slower side enough slack to catch up without the faster side
+// lapping it immediately. channelCount must match every PCMBuffer later
+// passed to Push/Pull. halFrames and appFrames must both be positive - a
+// caller holding AudioSpec.ProcessingFrames' <=0 "unset" sentinel must
+// resolve it with ResolveProcessingFrames first, since a non-positive frame
+// count here would otherwise build a zero-capacity ring and the first
+// Push/Pull would divide by zero.
+func NewBufferManager(halFrames, appFrames, channelCount int) (*BufferManager, error) {
+	if halFrames <= 0 {
+		return nil, fmt.Errorf("halFrames must be positive, got %d", halFrames)
+	}
+	if appFrames <= 0 {
+		return nil, fmt.Errorf("appFrames must be positive, got %d", appFrames)
+	}
+
+	largest := halFrames
+	if appFrames > largest {
+		largest = appFrames
+	}
+	capacityFrames := 2 * largest
+
+	return &BufferManager{
+		ring:         make([]float32, capacityFrames*channelCount),
+		channelCount: channelCount,
+		capacity:     uint64(capacityFrames),
+	}, nil
+}
+
+// Push copies buf into the ring and advances head by buf.FrameCount frames.
+// Push only ever reads/writes head, never tail - overrun (Push outrunning
+// Pull) is detected and counted on the consumer side in Pull instead, so
+// the two sides never contend on the same atomic, same as a textbook SPSC
+// ring buffer.
+func (b *BufferManager) Push(buf aveng.PCMBuffer) {
+	frames := uint64(buf.FrameCount)
+	if frames == 0 {
+		return
+	}
+
+	samples := buf.Samples
+	if frames > b.capacity {
+		// A single push larger than the whole ring: only its tail end can
+		// ever be read back, so only write that much.
+		drop := frames - b.capacity
+		samples = samples[drop*uint64(b.channelCount):]
+		frames = b.capacity
+	}
+
+	head := b.head.Load()
+	b.writeFrames(head, samples, int(frames))
+	b.head.Store(head + frames)
+}
+
+// Pull returns the next nFrames frames written by Push. If Push has gotten
+// more than a capacity's worth of frames ahead of the last Pull (Pull is
+// running slower than Push), Pull first catches tail up to the oldest
+// still-valid data and counts an overrun. If fewer than nFrames are then
+// available (Push is running slower than Pull, or hasn't started yet),
+// Pull returns what it has, zero-fills the remainder, and reports
+// underrun=true - both counted in Stats - rather than blocking the app
+// thread waiting for more.
+func (b *BufferManager) Pull(nFrames int) (buf aveng.PCMBuffer, underrun bool) {
+	out := aveng.PCMBuffer{
+		Samples:      make([]float32, nFrames*b.channelCount),
+		FrameCount:   nFrames,
+		ChannelCount: b.channelCount,
+	}
+	if nFrames == 0 {
+		return out, false
+	}
+
+	head := b.head.Load()
+	tail := b.tail.Load()
+
+	if available := head - tail; available > b.capacity {
+		tail = head - b.capacity
+		b.overruns.Add(1)
+	}
+
+	available := head - tail
+	want := uint64(nFrames)
+
+	toRead := want
+	if available < want {
+		toRead = available
+		underrun = true
+		b.underruns.Add(1)
+	}
+
+	b.readFrames(tail, out.Samples, int(toRead))
+	b.tail.Store(tail + toRead)
+
+	return out, underrun
+}
+
+// writeFrames copies count frames of interleaved samples into the ring
+// starting at the frame index "from" (unwrapped), splitting the copy in
+// two if it straddles the ring's wraparound point.
+func (b *BufferManager) writeFrames(from uint64, samples []float32, count int) {
+	ch := b.channelCount
+	start := (from % b.capacity) * uint64(ch)
+	total := count * ch
+
+	first := total
+	if room := int(b.capacity)*ch - int(start); first > room {
+		first = room
+	}
+	copy(b.ring[start:], samples[:first])
+	if first < total {
+		copy(b.ring[:total-first], samples[first:total])
+	}
+}
+
+// readFrames copies count frames of interleaved samples out of the ring
+// starting at the frame index "from" (unwrapped), into dst - the mirror of
+// writeFrames, splitting the copy in two across the wraparound point.
+func (b *BufferManager) readFrames(from uint64, dst []float32, count int) {
+	ch := b.channelCount
+	start := (from % b.capacity) * uint64(ch)
+	total := count * ch
+
+	first := total
+	if room := int(b.capacity)*ch - int(start); first > room {
+		first = room
+	}
+	copy(dst[:first], b.ring[start:start+uint64(first)])
+	if first < total {
+		copy(dst[first:total], b.ring[:total-first])
+	}
+}
+
+// Stats returns b's running underrun/overrun counts.
+func (b *BufferManager) Stats() BufferStats {
+	return BufferStats{
+		Underruns: b.underruns.Load(),
+		Overruns:  b.overruns.Load(),
+	}
+}
@@ -0,0 +1,143 @@
+//go:build darwin
+
+package session
+
+import (
+	"testing"
+
+	aveng "github.com/shaban/macaudio/avaudio/engine"
+)
+
+func samplesFor(frameCount, channelCount int, start float32) []float32 {
+	s := make([]float32, frameCount*channelCount)
+	for i := range s {
+		s[i] = start + float32(i)
+	}
+	return s
+}
+
+func TestBufferManagerCapacityIsTwiceTheLargerSide(t *testing.T) {
+	bm, err := NewBufferManager(128, 512, 2)
+	if err != nil {
+		t.Fatalf("NewBufferManager: %v", err)
+	}
+	if bm.capacity != 1024 {
+		t.Errorf("expected capacity 1024 (2*max(128,512)), got %d", bm.capacity)
+	}
+}
+
+func TestBufferManagerRejectsNonPositiveFrameCounts(t *testing.T) {
+	cases := []struct {
+		halFrames, appFrames int
+	}{
+		{0, 64},
+		{64, 0},
+		{-1, 64},
+		{64, -1},
+		{0, 0},
+	}
+	for _, c := range cases {
+		if _, err := NewBufferManager(c.halFrames, c.appFrames, 1); err == nil {
+			t.Errorf("NewBufferManager(%d, %d, 1): expected an error, got nil", c.halFrames, c.appFrames)
+		}
+	}
+}
+
+func TestBufferManagerPullReturnsWhatWasPushed(t *testing.T) {
+	bm, err := NewBufferManager(128, 128, 1)
+	if err != nil {
+		t.Fatalf("NewBufferManager: %v", err)
+	}
+
+	in := samplesFor(128, 1, 1)
+	bm.Push(aveng.PCMBuffer{Samples: in, FrameCount: 128, ChannelCount: 1})
+
+	out, underrun := bm.Pull(128)
+	if underrun {
+		t.Error("expected no underrun when Pull asks for exactly what was pushed")
+	}
+	for i := range in {
+		if out.Samples[i] != in[i] {
+			t.Fatalf("sample %d: got %v, want %v", i, out.Samples[i], in[i])
+		}
+	}
+}
+
+func TestBufferManagerPullStraddlesWraparound(t *testing.T) {
+	bm, err := NewBufferManager(64, 64, 1) // capacity = 128 frames
+	if err != nil {
+		t.Fatalf("NewBufferManager: %v", err)
+	}
+
+	// Push and fully drain once so head/tail sit at 100 frames in, then
+	// push 40 more frames - the ring index wraps mid-buffer (100%128=100,
+	// 100+40=140 > 128) and the read below must straddle that wrap.
+	bm.Push(aveng.PCMBuffer{Samples: samplesFor(100, 1, 0), FrameCount: 100, ChannelCount: 1})
+	bm.Pull(100)
+
+	in := samplesFor(40, 1, 1000)
+	bm.Push(aveng.PCMBuffer{Samples: in, FrameCount: 40, ChannelCount: 1})
+
+	out, underrun := bm.Pull(40)
+	if underrun {
+		t.Error("expected no underrun")
+	}
+	for i := range in {
+		if out.Samples[i] != in[i] {
+			t.Fatalf("sample %d: got %v, want %v", i, out.Samples[i], in[i])
+		}
+	}
+}
+
+func TestBufferManagerPullReportsUnderrunWhenStarved(t *testing.T) {
+	bm, err := NewBufferManager(64, 64, 1)
+	if err != nil {
+		t.Fatalf("NewBufferManager: %v", err)
+	}
+
+	bm.Push(aveng.PCMBuffer{Samples: samplesFor(10, 1, 0), FrameCount: 10, ChannelCount: 1})
+
+	out, underrun := bm.Pull(64)
+	if !underrun {
+		t.Error("expected an underrun when only 10 of 64 requested frames are available")
+	}
+	if len(out.Samples) != 64 {
+		t.Errorf("expected Pull to still return a full-size buffer, got %d samples", len(out.Samples))
+	}
+	if bm.Stats().Underruns != 1 {
+		t.Errorf("expected Stats().Underruns == 1, got %d", bm.Stats().Underruns)
+	}
+}
+
+func TestBufferManagerPushReportsOverrunWhenConsumerFallsBehind(t *testing.T) {
+	bm, err := NewBufferManager(64, 64, 1) // capacity = 128 frames
+	if err != nil {
+		t.Fatalf("NewBufferManager: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		bm.Push(aveng.PCMBuffer{Samples: samplesFor(64, 1, 0), FrameCount: 64, ChannelCount: 1})
+	}
+	// 3*64 = 192 frames pushed into a 128-frame ring without any Pull -
+	// the next Pull should observe and count the overrun.
+	if _, underrun := bm.Pull(64); underrun {
+		t.Error("expected no underrun; the ring still has data, just not all of it")
+	}
+	if bm.Stats().Overruns != 1 {
+		t.Errorf("expected Stats().Overruns == 1, got %d", bm.Stats().Overruns)
+	}
+}
+
+func TestResolveProcessingFramesDefaultsToHALBufferSize(t *testing.T) {
+	spec := AudioSpec{BufferSize: 256}
+	if got := ResolveProcessingFrames(spec); got != 256 {
+		t.Errorf("expected ResolveProcessingFrames to default to BufferSize 256, got %d", got)
+	}
+}
+
+func TestResolveProcessingFramesHonorsExplicitValue(t *testing.T) {
+	spec := AudioSpec{BufferSize: 128, ProcessingFrames: 512}
+	if got := ResolveProcessingFrames(spec); got != 512 {
+		t.Errorf("expected ResolveProcessingFrames to return the explicit 512, got %d", got)
+	}
+}
@@ -0,0 +1,374 @@
+//go:build darwin
+
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// This file backs loadIndex/saveIndex/readDetails/writeDetails/deleteDetails
+// (declared in cache_store.go) with an embedded bbolt key/value store
+// instead of index.json plus one details/<hash>.json file per plugin. Every
+// write lands in a single bbolt transaction, so a crash mid-save leaves the
+// store at its last committed transaction instead of needing the
+// journal-and-replay reconciliation the old file-backend required.
+
+var (
+	indexBucketName   = []byte("index")
+	detailsBucketName = []byte("details")
+	metaBucketName    = []byte("meta")
+	// detailsQuarantineBucketName holds details entries boltReadDetails
+	// couldn't read or migrate up to detailsVersion, keyed the same as
+	// detailsBucketName - the bbolt-store analogue of the legacy file
+	// backend's details/.quarantine/ directory, so a broken record can be
+	// inspected instead of silently dropped.
+	detailsQuarantineBucketName = []byte("details_quarantine")
+)
+
+const (
+	metaIndexKey    = "index"
+	metaMigratedKey = "migrated_from_json"
+)
+
+// indexMeta is metaBucketName's "index" entry: indexFile minus Entries,
+// which live one-per-key in indexBucketName so a single changed plugin
+// doesn't require rewriting every other entry's bytes.
+type indexMeta struct {
+	Version       string               `json:"version"`
+	UpdatedAt     time.Time            `json:"updatedAt"`
+	PageChecksums []string             `json:"pageChecksums,omitempty"`
+	Quarantine    map[string]time.Time `json:"quarantine,omitempty"`
+	SchemaVersion int                  `json:"schemaVersion,omitempty"`
+}
+
+// boltPath returns the path to the bbolt-backed plugin cache database
+// under the current getPluginCacheDir().
+func boltPath() (string, error) {
+	dir, err := getPluginCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugin_cache.bolt"), nil
+}
+
+// openBoltStore opens (creating if needed) the plugin cache database,
+// ensures its buckets exist, and imports any legacy index.json/details/*.json
+// left by the pre-bbolt backend on first open (see migrateLegacyJSON).
+//
+// It's opened and closed fresh on every call rather than held open for the
+// Session's lifetime: the cache directory can change between calls in tests
+// (MACAUDIO_CACHE_DIR is reset per test), and bbolt holds an exclusive file
+// lock for as long as a *bolt.DB stays open, which would otherwise deadlock
+// a test pointed at a fresh temp dir while a prior test's handle was still
+// live.
+func openBoltStore() (*bolt.DB, error) {
+	path, err := boltPath()
+	if err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{indexBucketName, detailsBucketName, metaBucketName, detailsQuarantineBucketName} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		if err := migrateLegacyJSON(tx); err != nil {
+			return err
+		}
+		return migrateSchemaVersion(tx)
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrateLegacyJSON imports index.json and its details/<hash>.json files
+// into tx's buckets the first time the bbolt store is opened in a given
+// cache directory. It's a one-shot: metaBucketName's "migrated_from_json"
+// key guards against re-importing (and re-reviving deleted entries) on
+// every later open. Best-effort throughout - a missing or unreadable legacy
+// index.json just leaves the bbolt store empty, same as a brand new cache.
+func migrateLegacyJSON(tx *bolt.Tx) error {
+	meta := tx.Bucket(metaBucketName)
+	if meta.Get([]byte(metaMigratedKey)) != nil {
+		return nil
+	}
+
+	if idxPath, detailsDir, err := getIndexPaths(); err == nil {
+		if data, err := os.ReadFile(idxPath); err == nil {
+			var legacy indexFile
+			if err := json.Unmarshal(data, &legacy); err == nil {
+				idxBucket := tx.Bucket(indexBucketName)
+				detailsBucket := tx.Bucket(detailsBucketName)
+				for key, entry := range legacy.Entries {
+					if b, err := json.Marshal(entry); err == nil {
+						_ = idxBucket.Put([]byte(key), b)
+					}
+					// detailsFile's on-disk JSON shape is unchanged by the
+					// bbolt move, so the legacy bytes can be copied in as-is.
+					if raw, err := os.ReadFile(filepath.Join(detailsDir, detailFileName(key))); err == nil {
+						_ = detailsBucket.Put([]byte(key), raw)
+					}
+				}
+				m := indexMeta{Version: indexVersion, UpdatedAt: legacy.UpdatedAt, PageChecksums: legacy.PageChecksums}
+				if b, err := json.Marshal(m); err == nil {
+					_ = meta.Put([]byte(metaIndexKey), b)
+				}
+			}
+		}
+	}
+
+	return meta.Put([]byte(metaMigratedKey), []byte(time.Now().Format(time.RFC3339)))
+}
+
+// migrateSchemaVersion wipes detailsBucketName when the persisted index's
+// SchemaVersion doesn't match pluginSchemaVersion: the quick index itself
+// (type/subtype/manufacturer/name/category/checksum) stays valid across a
+// plugins.Plugin shape change, so QuickPlugins() keeps returning instantly,
+// but any previously introspected *Plugin blobs do not, so they're dropped
+// here rather than served stale - each gets re-introspected lazily the next
+// time PluginCtx is asked for it. A brand new store (no meta entry yet) has
+// nothing to migrate.
+func migrateSchemaVersion(tx *bolt.Tx) error {
+	meta := tx.Bucket(metaBucketName)
+	raw := meta.Get([]byte(metaIndexKey))
+	if raw == nil {
+		return nil
+	}
+	var m indexMeta
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil
+	}
+	if m.SchemaVersion == pluginSchemaVersion {
+		return nil
+	}
+	if err := tx.DeleteBucket(detailsBucketName); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	if _, err := tx.CreateBucket(detailsBucketName); err != nil {
+		return err
+	}
+	m.SchemaVersion = pluginSchemaVersion
+	mb, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return meta.Put([]byte(metaIndexKey), mb)
+}
+
+func boltLoadIndex() (*indexFile, error) {
+	db, err := openBoltStore()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	idx := &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}}
+	var rawMeta []byte
+	err = db.View(func(tx *bolt.Tx) error {
+		rawMeta = tx.Bucket(metaBucketName).Get([]byte(metaIndexKey))
+		return tx.Bucket(indexBucketName).ForEach(func(k, v []byte) error {
+			var e indexEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			idx.Entries[string(k)] = e
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rawMeta == nil {
+		return idx, nil
+	}
+
+	// Detect the stored version and walk registered migrations up to
+	// indexVersion instead of assuming rawMeta is already current - see
+	// RegisterIndexMigration.
+	fromVersion := indexVersion
+	var probe struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(rawMeta, &probe); err == nil && probe.Version != "" {
+		fromVersion = probe.Version
+	}
+	migrated, toVersion, steps, err := applyMigrations(snapshotIndexMigrations(), rawMeta, fromVersion, indexVersion)
+	if err != nil {
+		return nil, fmt.Errorf("migrating index meta from %s: %w", fromVersion, err)
+	}
+	var m indexMeta
+	if err := json.Unmarshal(migrated, &m); err != nil {
+		return nil, fmt.Errorf("unmarshaling migrated index meta: %w", err)
+	}
+	idx.UpdatedAt = m.UpdatedAt
+	idx.PageChecksums = m.PageChecksums
+	idx.Quarantine = m.Quarantine
+	idx.SchemaVersion = m.SchemaVersion
+	idx.Version = toVersion
+
+	if steps > 0 {
+		m.Version = toVersion
+		if mb, err := json.Marshal(m); err == nil {
+			_ = db.Update(func(tx *bolt.Tx) error {
+				return tx.Bucket(metaBucketName).Put([]byte(metaIndexKey), mb)
+			})
+		}
+		if h := currentMigrationHook(); h != nil {
+			h.OnCacheMigration("index", fromVersion, toVersion, steps)
+		}
+	}
+	return idx, nil
+}
+
+// boltSaveIndex replaces indexBucketName's contents and meta entry in a
+// single transaction, so a reader never observes a partially-updated index.
+func boltSaveIndex(idx *indexFile) error {
+	db, err := openBoltStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	idx.Version = indexVersion
+	idx.UpdatedAt = time.Now()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(indexBucketName); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		ib, err := tx.CreateBucket(indexBucketName)
+		if err != nil {
+			return err
+		}
+		for key, entry := range idx.Entries {
+			b, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if err := ib.Put([]byte(key), b); err != nil {
+				return err
+			}
+		}
+		m := indexMeta{Version: idx.Version, UpdatedAt: idx.UpdatedAt, PageChecksums: idx.PageChecksums, Quarantine: idx.Quarantine, SchemaVersion: idx.SchemaVersion}
+		mb, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(metaBucketName).Put([]byte(metaIndexKey), mb)
+	})
+}
+
+func boltReadDetails(key string) (*plugins.Plugin, string, error) {
+	db, err := openBoltStore()
+	if err != nil {
+		return nil, "", err
+	}
+	defer db.Close()
+
+	var raw []byte
+	if err := db.View(func(tx *bolt.Tx) error {
+		raw = tx.Bucket(detailsBucketName).Get([]byte(key))
+		return nil
+	}); err != nil {
+		return nil, "", err
+	}
+	if raw == nil {
+		return nil, "", fmt.Errorf("no details cached for %s", key)
+	}
+
+	var probe struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, "", boltQuarantineDetails(db, key, raw, fmt.Sprintf("unreadable: %v", err))
+	}
+
+	migrated, toVersion, steps, err := applyMigrations(snapshotDetailsMigrations(), raw, probe.Version, detailsVersion)
+	if err != nil {
+		return nil, "", boltQuarantineDetails(db, key, raw, fmt.Sprintf("migrating from %s: %v", probe.Version, err))
+	}
+	if toVersion != detailsVersion {
+		return nil, "", boltQuarantineDetails(db, key, raw, fmt.Sprintf("no migration chain from %s to %s", toVersion, detailsVersion))
+	}
+
+	var df detailsFile
+	if err := json.Unmarshal(migrated, &df); err != nil || df.Plugin == nil {
+		return nil, "", boltQuarantineDetails(db, key, raw, "invalid details entry after migration")
+	}
+
+	if steps > 0 {
+		df.Version = toVersion
+		if mb, err := json.Marshal(df); err == nil {
+			_ = db.Update(func(tx *bolt.Tx) error {
+				return tx.Bucket(detailsBucketName).Put([]byte(key), mb)
+			})
+		}
+		if h := currentMigrationHook(); h != nil {
+			h.OnCacheMigration("details", probe.Version, toVersion, steps)
+		}
+	}
+	return df.Plugin, df.Checksum, nil
+}
+
+// boltQuarantineDetails moves key's unreadable/unmigratable raw bytes from
+// detailsBucketName into detailsQuarantineBucketName instead of letting
+// them sit in the details bucket (where they'd fail the same way on every
+// future read) or dropping them outright, then reports reason through
+// MetricsHook.OnCacheQuarantine via currentMigrationHook. Always returns a
+// non-nil error describing the failure, for the caller to treat as a cache
+// miss.
+func boltQuarantineDetails(db *bolt.DB, key string, raw []byte, reason string) error {
+	_ = db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(detailsBucketName).Delete([]byte(key)); err != nil {
+			return err
+		}
+		return tx.Bucket(detailsQuarantineBucketName).Put([]byte(key), raw)
+	})
+	if h := currentMigrationHook(); h != nil {
+		h.OnCacheQuarantine(key, reason)
+	}
+	return fmt.Errorf("quarantined details for %s: %s", key, reason)
+}
+
+func boltWriteDetails(key, checksum string, pl *plugins.Plugin) error {
+	db, err := openBoltStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	df := detailsFile{Version: detailsVersion, LastIntrospected: time.Now(), Checksum: checksum, Plugin: pl}
+	b, err := json.Marshal(df)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(detailsBucketName).Put([]byte(key), b)
+	})
+}
+
+func boltDeleteDetails(key string) error {
+	db, err := openBoltStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(detailsBucketName).Delete([]byte(key))
+	})
+}
@@ -0,0 +1,129 @@
+//go:build darwin
+
+package session
+
+import (
+	"fmt"
+	"sync"
+)
+
+// migrationStep upgrades a stored record's raw JSON bytes from one wire
+// version to the next. Registered via RegisterIndexMigration/
+// RegisterDetailsMigration so loadIndex/readDetails (and RedisBackend's
+// equivalents) can walk a chain of them instead of discarding anything that
+// doesn't exactly match indexVersion/detailsVersion - see cache_bolt.go and
+// index_backend_redis.go.
+type migrationStep struct {
+	from, to string
+	fn       func([]byte) ([]byte, error)
+}
+
+var (
+	migrationMu       sync.Mutex
+	indexMigrations   []migrationStep
+	detailsMigrations []migrationStep
+)
+
+// RegisterIndexMigration registers fn to upgrade the index meta blob's wire
+// format from from to to. applyMigrations walks registered steps as a
+// chain, so an index persisted several releases back upgrades in one pass
+// instead of being silently reset to empty. fn receives and must return the
+// meta JSON at version from (indexMeta's shape, not indexEntry - entries
+// aren't individually versioned, see pluginSchemaVersion for their
+// evolution path).
+func RegisterIndexMigration(from, to string, fn func([]byte) ([]byte, error)) {
+	migrationMu.Lock()
+	defer migrationMu.Unlock()
+	indexMigrations = append(indexMigrations, migrationStep{from, to, fn})
+}
+
+// RegisterDetailsMigration registers fn to upgrade a single cached
+// detailsFile's wire format from from to to. See RegisterIndexMigration.
+func RegisterDetailsMigration(from, to string, fn func([]byte) ([]byte, error)) {
+	migrationMu.Lock()
+	defer migrationMu.Unlock()
+	detailsMigrations = append(detailsMigrations, migrationStep{from, to, fn})
+}
+
+// snapshotIndexMigrations and snapshotDetailsMigrations copy the current
+// registrations under migrationMu, so applyMigrations can walk a stable
+// chain without holding the lock while it calls into (possibly slow)
+// migration funcs, and so a concurrent RegisterIndexMigration/
+// RegisterDetailsMigration can't race with a caller reading the global
+// slice directly.
+func snapshotIndexMigrations() []migrationStep {
+	migrationMu.Lock()
+	defer migrationMu.Unlock()
+	return append([]migrationStep(nil), indexMigrations...)
+}
+
+func snapshotDetailsMigrations() []migrationStep {
+	migrationMu.Lock()
+	defer migrationMu.Unlock()
+	return append([]migrationStep(nil), detailsMigrations...)
+}
+
+func init() {
+	// 1.0-index predates the paginated scan protocol (see paging.go).
+	// PageChecksums/Quarantine/SchemaVersion were all added as omitempty
+	// fields, so a 1.0-index blob already unmarshals cleanly into today's
+	// indexMeta - the only thing missing is the version label. Shipped as a
+	// no-op so the chain has a first link before any real schema change
+	// needs one.
+	RegisterIndexMigration("1.0-index", "2.0-index", func(b []byte) ([]byte, error) { return b, nil })
+}
+
+// applyMigrations walks chain (a snapshot from snapshotIndexMigrations/
+// snapshotDetailsMigrations) from version "from" toward "target", applying
+// each step whose "from" matches the version reached so far. It stops as
+// soon as no further step is registered, which may be before reaching
+// target - callers must check the returned version against target
+// themselves (loadIndex treats this as best-effort, readDetails/GetDetails
+// quarantine the record instead of serving it stale).
+func applyMigrations(chain []migrationStep, b []byte, from, target string) (migrated []byte, version string, applied int, err error) {
+	version = from
+	for version != target {
+		var next *migrationStep
+		for i := range chain {
+			if chain[i].from == version {
+				next = &chain[i]
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		nb, err := next.fn(b)
+		if err != nil {
+			return b, version, applied, fmt.Errorf("migrating %s to %s: %w", next.from, next.to, err)
+		}
+		b = nb
+		version = next.to
+		applied++
+	}
+	return b, version, applied, nil
+}
+
+// migrationHookMu guards migrationHook, the package-level counterpart to
+// Session.hook: loadIndex/readDetails and RedisBackend's LoadIndex/
+// GetDetails are free functions/value-receiver methods with no *Session to
+// read a hook off of, so SetMetricsHook mirrors whatever it's given here as
+// well, and currentMigrationHook lets the cache layer emit OnCacheMigration/
+// OnCacheQuarantine without threading a hook parameter through every
+// caller.
+var (
+	migrationHookMu sync.RWMutex
+	migrationHook   MetricsHook
+)
+
+func setMigrationHook(h MetricsHook) {
+	migrationHookMu.Lock()
+	migrationHook = h
+	migrationHookMu.Unlock()
+}
+
+func currentMigrationHook() MetricsHook {
+	migrationHookMu.RLock()
+	defer migrationHookMu.RUnlock()
+	return migrationHook
+}
@@ -0,0 +1,156 @@
+//go:build darwin
+
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+func TestApplyMigrationsWalksChain(t *testing.T) {
+	steps := []migrationStep{
+		{from: "0.1", to: "0.2", fn: func(b []byte) ([]byte, error) { return append(b, 'a'), nil }},
+		{from: "0.2", to: "0.3", fn: func(b []byte) ([]byte, error) { return append(b, 'b'), nil }},
+	}
+
+	got, version, applied, err := applyMigrations(steps, []byte("x"), "0.1", "0.3")
+	if err != nil {
+		t.Fatalf("applyMigrations returned an error: %v", err)
+	}
+	if version != "0.3" || applied != 2 || string(got) != "xab" {
+		t.Fatalf("expected (\"xab\", \"0.3\", 2), got (%q, %q, %d)", got, version, applied)
+	}
+
+	// Already at target: no steps applied, bytes untouched.
+	got, version, applied, err = applyMigrations(steps, []byte("y"), "0.3", "0.3")
+	if err != nil || version != "0.3" || applied != 0 || string(got) != "y" {
+		t.Fatalf("expected a no-op for an already-current version, got (%q, %q, %d, %v)", got, version, applied, err)
+	}
+
+	// No registered step covers the gap: stops short rather than erroring.
+	_, version, applied, err = applyMigrations(steps, []byte("z"), "0.0", "0.3")
+	if err != nil || version != "0.0" || applied != 0 {
+		t.Fatalf("expected to stop at the unmigrated version, got (%q, %d, %v)", version, applied, err)
+	}
+}
+
+func TestReadDetailsAppliesRegisteredMigration(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	const oldVersion = "0.9-details-test"
+	RegisterDetailsMigration(oldVersion, detailsVersion, func(b []byte) ([]byte, error) {
+		var df detailsFile
+		if err := json.Unmarshal(b, &df); err != nil {
+			return nil, err
+		}
+		df.Version = detailsVersion
+		return json.Marshal(df)
+	})
+
+	var migrations []struct{ kind, from, to string }
+	setMigrationHook(&recordingHook{onMigration: func(kind, from, to string, steps int) {
+		migrations = append(migrations, struct{ kind, from, to string }{kind, from, to})
+	}})
+	defer setMigrationHook(nil)
+
+	fakeKey := "aufx:FAKE:ACME:Migrated Plugin"
+	df := detailsFile{Version: oldVersion, LastIntrospected: time.Now(), Checksum: "deadbeef", Plugin: &plugins.Plugin{Name: "Migrated Plugin", ManufacturerID: "ACME", Type: "aufx", Subtype: "FAKE"}}
+	raw, err := json.Marshal(df)
+	if err != nil {
+		t.Fatalf("failed to marshal fake details: %v", err)
+	}
+	if err := putRawBoltDetails(fakeKey, raw); err != nil {
+		t.Fatalf("failed to seed raw details: %v", err)
+	}
+
+	plugin, checksum, err := readDetails(fakeKey)
+	if err != nil {
+		t.Fatalf("expected migrated details to be readable, got: %v", err)
+	}
+	if plugin.Name != "Migrated Plugin" || checksum != "deadbeef" {
+		t.Fatalf("unexpected migrated plugin/checksum: %+v %q", plugin, checksum)
+	}
+	if len(migrations) != 1 || migrations[0].kind != "details" || migrations[0].from != oldVersion || migrations[0].to != detailsVersion {
+		t.Fatalf("expected one details migration to be reported, got %+v", migrations)
+	}
+}
+
+func TestReadDetailsQuarantinesUnmigratableEntry(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	var quarantined []string
+	setMigrationHook(&recordingHook{onQuarantine: func(key, reason string) { quarantined = append(quarantined, key) }})
+	defer setMigrationHook(nil)
+
+	fakeKey := "aufx:FAKE:ACME:Unmigratable Plugin"
+	df := detailsFile{Version: "9.9-unknown", Checksum: "deadbeef", Plugin: &plugins.Plugin{Name: "Unmigratable Plugin"}}
+	raw, err := json.Marshal(df)
+	if err != nil {
+		t.Fatalf("failed to marshal fake details: %v", err)
+	}
+	if err := putRawBoltDetails(fakeKey, raw); err != nil {
+		t.Fatalf("failed to seed raw details: %v", err)
+	}
+
+	if _, _, err := readDetails(fakeKey); err == nil {
+		t.Fatal("expected an entry with no migration path to be rejected")
+	}
+	if len(quarantined) != 1 || quarantined[0] != fakeKey {
+		t.Fatalf("expected %s to be quarantined, got %+v", fakeKey, quarantined)
+	}
+	if _, _, err := readDetails(fakeKey); err == nil {
+		t.Fatal("expected the quarantined entry to no longer be served from the details bucket")
+	}
+}
+
+// putRawBoltDetails writes raw bytes directly into the details bucket,
+// bypassing writeDetails/boltWriteDetails so a test can seed an entry at an
+// arbitrary (possibly unknown) version.
+func putRawBoltDetails(key string, raw []byte) error {
+	db, err := openBoltStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(detailsBucketName).Put([]byte(key), raw)
+	})
+}
+
+// recordingHook implements MetricsHook with no-ops except the callbacks a
+// test explicitly wires up.
+type recordingHook struct {
+	nopMetricsHook
+	onMigration  func(kind, from, to string, steps int)
+	onQuarantine func(key, reason string)
+}
+
+func (r *recordingHook) OnCacheMigration(kind, from, to string, steps int) {
+	if r.onMigration != nil {
+		r.onMigration(kind, from, to, steps)
+	}
+}
+
+func (r *recordingHook) OnCacheQuarantine(key, reason string) {
+	if r.onQuarantine != nil {
+		r.onQuarantine(key, reason)
+	}
+}
@@ -5,23 +5,38 @@ package session
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/shaban/macaudio/plugins"
 )
 
-// Versions for on-disk files so future migrations can co-exist safely.
+// Versions for on-disk/bbolt-stored records so future migrations can
+// co-exist safely.
 const (
-	indexVersion   = "1.0-index"
+	// indexVersion is bumped to 2.0 for the paginated scan protocol (see
+	// paging.go): the index now also carries PageChecksums so RefreshQuick
+	// can restrict its diff to the pages that actually changed instead of
+	// walking every entry.
+	indexVersion   = "2.0-index"
 	detailsVersion = "1.0-details"
 )
 
-// indexEntry keeps a minimal quick view of a plugin. It's stored in index.json
-// and used for fast startup and change detection without full introspection.
+// pluginSchemaVersion is bumped whenever plugins.Plugin's own fields change
+// shape in a way that makes a previously-introspected *Plugin value stale
+// even though the quick info that produced its checksum (checksumQuick)
+// hasn't changed - e.g. a new Parameter field the old cached JSON doesn't
+// carry. indexFile.SchemaVersion records which version an index's cached
+// details were last reconciled against, so Plugin/PluginCtx can fall back to
+// re-introspecting instead of trusting a checksum match against an outdated
+// shape - see the SchemaVersion check in PluginCtx and migrateSchemaVersion
+// in cache_bolt.go.
+const pluginSchemaVersion = 1
+
+// indexEntry keeps a minimal quick view of a plugin. It's stored one-per-key
+// in the bbolt index bucket and used for fast startup and change detection
+// without full introspection.
 type indexEntry struct {
 	Key            string    `json:"key"`
 	Type           string    `json:"type"`
@@ -33,15 +48,27 @@ type indexEntry struct {
 	LastSeenAt     time.Time `json:"lastSeenAt"`
 }
 
-// indexFile is the on-disk structure for index.json
+// indexFile is loadIndex/saveIndex's in-memory view of the bbolt index
+// bucket plus its meta entry. PageChecksums is one checksum per
+// reconcilePageSize-sized chunk of Entries' keys in lexicographic order
+// (see pageChecksumsFor); empty until the first RefreshQuick after
+// migration recomputes it.
 type indexFile struct {
-	Version   string                `json:"version"`
-	UpdatedAt time.Time             `json:"updatedAt"`
-	Entries   map[string]indexEntry `json:"entries"`
+	Version       string                `json:"version"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+	Entries       map[string]indexEntry `json:"entries"`
+	PageChecksums []string              `json:"pageChecksums,omitempty"`
+	// Quarantine maps a quad-key to when PluginCtx/WarmCtx last timed out
+	// introspecting it, so Warm/WarmCtx skip it on later passes until the
+	// user calls Session.Unquarantine - see PluginCtx in session.go.
+	Quarantine map[string]time.Time `json:"quarantine,omitempty"`
+	// SchemaVersion is the pluginSchemaVersion this index's cached details
+	// were last reconciled against; see pluginSchemaVersion.
+	SchemaVersion int `json:"schemaVersion,omitempty"`
 }
 
-// detailsFile is the on-disk structure for a single plugin details file.
-// It mirrors the detailed Plugin object and associates it to a checksum of
+// detailsFile is a single plugin's entry in the bbolt details bucket. It
+// mirrors the detailed Plugin object and associates it to a checksum of
 // the quick info for freshness validation.
 type detailsFile struct {
 	Version          string          `json:"version"`
@@ -60,116 +87,55 @@ func checksumQuick(info plugins.PluginInfo) string {
 	return hex.EncodeToString(h[:])
 }
 
-// getIndexPaths returns the index.json path and ensures details/ exists.
+// getIndexPaths returns the legacy index.json path and details/ directory
+// used by the pre-bbolt backend. Only migrateLegacyJSON (cache_bolt.go)
+// still reads through it now, to import whatever an older build left behind
+// the first time the bbolt store is opened in a given cache directory.
 func getIndexPaths() (string, string, error) {
 	dir, err := getPluginCacheDir()
 	if err != nil {
 		return "", "", err
 	}
 	detailsDir := filepath.Join(dir, "details")
-	if err := os.MkdirAll(detailsDir, 0o755); err != nil {
-		return "", "", err
-	}
 	return filepath.Join(dir, "index.json"), detailsDir, nil
 }
 
-// loadIndex reads index.json if present; otherwise returns an empty index.
-func loadIndex() (*indexFile, error) {
-	idxPath, _, err := getIndexPaths()
-	if err != nil {
-		return nil, err
-	}
-	data, err := os.ReadFile(idxPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &indexFile{Version: indexVersion, UpdatedAt: time.Time{}, Entries: map[string]indexEntry{}}, nil
-		}
-		return nil, err
-	}
-	var idx indexFile
-	if err := json.Unmarshal(data, &idx); err != nil {
-		return nil, err
-	}
-	if idx.Version != indexVersion || idx.Entries == nil {
-		return &indexFile{Version: indexVersion, UpdatedAt: time.Time{}, Entries: map[string]indexEntry{}}, nil
-	}
-	return &idx, nil
+// detailFileName hashes the quadruplet key to the stable filename the
+// legacy file backend used, so migrateLegacyJSON can find a given key's
+// details/<hash>.json.
+func detailFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + ".json"
 }
 
-// saveIndex atomically writes index.json to avoid partial writes.
-func saveIndex(idx *indexFile) error {
-	idxPath, _, err := getIndexPaths()
-	if err != nil {
-		return err
-	}
-	idx.Version = indexVersion
-	idx.UpdatedAt = time.Now()
-	tmp := idxPath + ".tmp"
-	b, err := json.Marshal(idx)
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(tmp, b, 0o644); err != nil {
-		return err
-	}
-	return os.Rename(tmp, idxPath)
+// loadIndex returns the plugin index, backed by the bbolt store opened at
+// getPluginCacheDir() (see cache_bolt.go). Never nil on success; an index
+// with no prior entries comes back with an empty Entries map.
+func loadIndex() (*indexFile, error) {
+	return boltLoadIndex()
 }
 
-// detailFileName hashes the quadruplet key to a stable filename.
-func detailFileName(key string) string {
-	sum := sha256.Sum256([]byte(key))
-	return hex.EncodeToString(sum[:]) + ".json"
+// saveIndex replaces the bbolt store's index bucket and meta entry in a
+// single transaction (see boltSaveIndex), so a reader never observes a
+// partially-updated index.
+func saveIndex(idx *indexFile) error {
+	return boltSaveIndex(idx)
 }
 
-// readDetails reads a plugin details file and returns the plugin and stored checksum.
+// readDetails returns the cached plugin and checksum for key from the
+// bbolt store's details bucket.
 func readDetails(key string) (*plugins.Plugin, string, error) {
-	_, detailsDir, err := getIndexPaths()
-	if err != nil {
-		return nil, "", err
-	}
-	p := filepath.Join(detailsDir, detailFileName(key))
-	data, err := os.ReadFile(p)
-	if err != nil {
-		return nil, "", err
-	}
-	var df detailsFile
-	if err := json.Unmarshal(data, &df); err != nil {
-		return nil, "", err
-	}
-	if df.Version != detailsVersion || df.Plugin == nil {
-		return nil, "", fmt.Errorf("invalid details file")
-	}
-	return df.Plugin, df.Checksum, nil
+	return boltReadDetails(key)
 }
 
-// writeDetails atomically persists a plugin details file.
+// writeDetails persists pl and checksum for key to the bbolt store's
+// details bucket in a single transaction.
 func writeDetails(key, checksum string, pl *plugins.Plugin) error {
-	_, detailsDir, err := getIndexPaths()
-	if err != nil {
-		return err
-	}
-	p := filepath.Join(detailsDir, detailFileName(key))
-	df := detailsFile{Version: detailsVersion, LastIntrospected: time.Now(), Checksum: checksum, Plugin: pl}
-	b, err := json.Marshal(df)
-	if err != nil {
-		return err
-	}
-	tmp := p + ".tmp"
-	if err := os.WriteFile(tmp, b, 0o644); err != nil {
-		return err
-	}
-	return os.Rename(tmp, p)
+	return boltWriteDetails(key, checksum, pl)
 }
 
-// deleteDetails removes the cached details file for a given plugin key (best-effort).
+// deleteDetails removes key's cached details from the bbolt store
+// (best-effort: a missing key is not an error).
 func deleteDetails(key string) error {
-	_, detailsDir, err := getIndexPaths()
-	if err != nil {
-		return err
-	}
-	p := filepath.Join(detailsDir, detailFileName(key))
-	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
-		return err
-	}
-	return nil
+	return boltDeleteDetails(key)
 }
@@ -7,9 +7,13 @@
 //
 // It is intentionally opinionated around performance and responsiveness:
 //   - Device change detection favors atomic count polling, then fan-out async scans
-//   - Plugin discovery keeps a quick index in index.json and full details in
-//     details/<hash>.json, enabling millisecond startup with cache hits
+//   - Plugin discovery keeps a quick index and full per-plugin details in an
+//     embedded bbolt store, enabling millisecond startup with cache hits and
+//     crash-safe, atomic per-key writes
 //   - Single-flight deduplication prevents duplicate details introspections
+//   - The index/details store is pluggable via IndexBackend (Options.Backend):
+//     FileBackend (the default bbolt store) or RedisBackend, letting several
+//     processes on one workstation share a single warmed cache
 //
 // Consumers can attach a MetricsHook to observe timings and cache behavior.
 package session
@@ -0,0 +1,34 @@
+//go:build darwin
+
+package session
+
+import "github.com/shaban/macaudio/plugins"
+
+// IndexBackend is the storage behind QuickPlugins, Plugin, and RefreshQuick:
+// the quick index of every known plugin plus lazily-fetched per-plugin
+// details. FileBackend (the default) is the embedded bbolt store from
+// cache_bolt.go; RedisBackend (index_backend_redis.go) lets several
+// processes on one workstation - a DAW host plus a helper CLI - share a
+// single warmed cache instead of each repeating plugins.List() and
+// introspection on its own. Set via Options.Backend.
+type IndexBackend interface {
+	LoadIndex() (*indexFile, error)
+	SaveIndex(idx *indexFile) error
+	GetDetails(key string) (*plugins.Plugin, string, error)
+	PutDetails(key, checksum string, pl *plugins.Plugin) error
+	DeleteDetails(key string) error
+}
+
+// FileBackend is the default IndexBackend, backed by the embedded bbolt
+// store opened at getPluginCacheDir() (see cache_bolt.go).
+type FileBackend struct{}
+
+func (FileBackend) LoadIndex() (*indexFile, error) { return boltLoadIndex() }
+func (FileBackend) SaveIndex(idx *indexFile) error  { return boltSaveIndex(idx) }
+func (FileBackend) GetDetails(key string) (*plugins.Plugin, string, error) {
+	return boltReadDetails(key)
+}
+func (FileBackend) PutDetails(key, checksum string, pl *plugins.Plugin) error {
+	return boltWriteDetails(key, checksum, pl)
+}
+func (FileBackend) DeleteDetails(key string) error { return boltDeleteDetails(key) }
@@ -0,0 +1,214 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/shaban/macaudio/plugins"
+)
+
+// redisIndexMetaField is the hash field RedisBackend.SaveIndex stores
+// indexMeta under, alongside one field per quad-key entry - the hash
+// itself doubles as the index, so LoadIndex is a single HGETALL rather
+// than an index read plus N detail reads.
+const redisIndexMetaField = "__meta__"
+
+// redisDetailsKeyPrefix namespaces plugin details under their own Redis
+// keys (rather than a second hash) so DetailsTTL can expire them
+// individually without touching the index.
+const redisDetailsKeyPrefix = "macaudio:details:"
+
+// redisQuarantineKeyPrefix namespaces details entries GetDetails couldn't
+// read or migrate up to detailsVersion - the Redis analogue of
+// detailsQuarantineBucketName in cache_bolt.go.
+const redisQuarantineKeyPrefix = "macaudio:quarantine:"
+
+// RedisBackend is an IndexBackend that stores the plugin index and details
+// in Redis instead of a local embedded store, so several session processes
+// on one workstation - a DAW host plus a helper CLI - can share one warmed
+// cache instead of each repeating plugins.List() and introspection on its
+// own.
+//
+// Unlike FileBackend, opening a RedisBackend never proactively wipes stale
+// details after a pluginSchemaVersion bump (there's no single "open" moment
+// to hook, and other processes may still be writing with an older binary) -
+// Session.PluginCtx's own SchemaVersion check is what makes a mismatched
+// cache act as a miss here instead.
+type RedisBackend struct {
+	Client *redis.Client
+	// IndexKey is the hash key the index is stored under; defaults to
+	// "macaudio:index" if empty.
+	IndexKey string
+	// DetailsTTL expires a details entry after this long if set; 0 keeps
+	// details until explicitly deleted (matching FileBackend).
+	DetailsTTL time.Duration
+}
+
+func (b RedisBackend) indexKey() string {
+	if b.IndexKey != "" {
+		return b.IndexKey
+	}
+	return "macaudio:index"
+}
+
+func (b RedisBackend) detailsKey(key string) string {
+	return redisDetailsKeyPrefix + key
+}
+
+func (b RedisBackend) quarantineKey(key string) string {
+	return redisQuarantineKeyPrefix + key
+}
+
+func (b RedisBackend) LoadIndex() (*indexFile, error) {
+	ctx := context.Background()
+	raw, err := b.Client.HGetAll(ctx, b.indexKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}}
+	for field, v := range raw {
+		if field == redisIndexMetaField {
+			// Detect the stored version and walk registered migrations up
+			// to indexVersion instead of assuming v is already current -
+			// see RegisterIndexMigration.
+			fromVersion := indexVersion
+			var probe struct {
+				Version string `json:"version"`
+			}
+			if err := json.Unmarshal([]byte(v), &probe); err == nil && probe.Version != "" {
+				fromVersion = probe.Version
+			}
+			migrated, toVersion, steps, err := applyMigrations(snapshotIndexMigrations(), []byte(v), fromVersion, indexVersion)
+			if err != nil {
+				continue
+			}
+			var m indexMeta
+			if err := json.Unmarshal(migrated, &m); err == nil {
+				idx.UpdatedAt = m.UpdatedAt
+				idx.PageChecksums = m.PageChecksums
+				idx.Quarantine = m.Quarantine
+				idx.SchemaVersion = m.SchemaVersion
+				idx.Version = toVersion
+				if steps > 0 {
+					if h := currentMigrationHook(); h != nil {
+						h.OnCacheMigration("index", fromVersion, toVersion, steps)
+					}
+				}
+			}
+			continue
+		}
+		var e indexEntry
+		if err := json.Unmarshal([]byte(v), &e); err != nil {
+			continue
+		}
+		idx.Entries[field] = e
+	}
+	return idx, nil
+}
+
+// SaveIndex replaces the index hash's contents in a single transaction
+// (Redis MULTI/EXEC via TxPipeline), so a reader's HGETALL never observes a
+// mix of the old and new index.
+func (b RedisBackend) SaveIndex(idx *indexFile) error {
+	ctx := context.Background()
+	idx.Version = indexVersion
+	idx.UpdatedAt = time.Now()
+
+	fields := make(map[string]any, len(idx.Entries)+1)
+	for key, entry := range idx.Entries {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		fields[key] = b
+	}
+	meta := indexMeta{Version: idx.Version, UpdatedAt: idx.UpdatedAt, PageChecksums: idx.PageChecksums, Quarantine: idx.Quarantine, SchemaVersion: idx.SchemaVersion}
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	fields[redisIndexMetaField] = metaBytes
+
+	_, err = b.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, b.indexKey())
+		pipe.HSet(ctx, b.indexKey(), fields)
+		return nil
+	})
+	return err
+}
+
+func (b RedisBackend) GetDetails(key string) (*plugins.Plugin, string, error) {
+	ctx := context.Background()
+	raw, err := b.Client.Get(ctx, b.detailsKey(key)).Bytes()
+	if err != nil {
+		return nil, "", fmt.Errorf("no details cached for %s: %w", key, err)
+	}
+
+	var probe struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, "", b.quarantineDetails(ctx, key, raw, fmt.Sprintf("unreadable: %v", err))
+	}
+
+	migrated, toVersion, steps, err := applyMigrations(snapshotDetailsMigrations(), raw, probe.Version, detailsVersion)
+	if err != nil {
+		return nil, "", b.quarantineDetails(ctx, key, raw, fmt.Sprintf("migrating from %s: %v", probe.Version, err))
+	}
+	if toVersion != detailsVersion {
+		return nil, "", b.quarantineDetails(ctx, key, raw, fmt.Sprintf("no migration chain from %s to %s", toVersion, detailsVersion))
+	}
+
+	var df detailsFile
+	if err := json.Unmarshal(migrated, &df); err != nil || df.Plugin == nil {
+		return nil, "", b.quarantineDetails(ctx, key, raw, "invalid details entry after migration")
+	}
+
+	if steps > 0 {
+		df.Version = toVersion
+		if mb, err := json.Marshal(df); err == nil {
+			_ = b.Client.Set(ctx, b.detailsKey(key), mb, b.DetailsTTL).Err()
+		}
+		if h := currentMigrationHook(); h != nil {
+			h.OnCacheMigration("details", probe.Version, toVersion, steps)
+		}
+	}
+	return df.Plugin, df.Checksum, nil
+}
+
+// quarantineDetails moves key's unreadable/unmigratable raw bytes from its
+// details key to its quarantine key instead of letting them fail the same
+// way on every future GetDetails, then reports reason through
+// MetricsHook.OnCacheQuarantine via currentMigrationHook. Always returns a
+// non-nil error describing the failure, for the caller to treat as a cache
+// miss.
+func (b RedisBackend) quarantineDetails(ctx context.Context, key string, raw []byte, reason string) error {
+	_ = b.Client.Set(ctx, b.quarantineKey(key), raw, b.DetailsTTL).Err()
+	_ = b.Client.Del(ctx, b.detailsKey(key)).Err()
+	if h := currentMigrationHook(); h != nil {
+		h.OnCacheQuarantine(key, reason)
+	}
+	return fmt.Errorf("quarantined details for %s: %s", key, reason)
+}
+
+func (b RedisBackend) PutDetails(key, checksum string, pl *plugins.Plugin) error {
+	ctx := context.Background()
+	df := detailsFile{Version: detailsVersion, LastIntrospected: time.Now(), Checksum: checksum, Plugin: pl}
+	raw, err := json.Marshal(df)
+	if err != nil {
+		return err
+	}
+	return b.Client.Set(ctx, b.detailsKey(key), raw, b.DetailsTTL).Err()
+}
+
+func (b RedisBackend) DeleteDetails(key string) error {
+	ctx := context.Background()
+	return b.Client.Del(ctx, b.detailsKey(key)).Err()
+}
@@ -0,0 +1,100 @@
+//go:build darwin
+
+package session
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shaban/macaudio/plugins"
+)
+
+// TestIndexBackendContract runs the same sequence of operations against
+// every IndexBackend implementation, so FileBackend and RedisBackend are
+// held to one behavioral contract instead of drifting apart silently.
+func TestIndexBackendContract(t *testing.T) {
+	backends := map[string]func(t *testing.T) IndexBackend{
+		"FileBackend":  newTestFileBackend,
+		"RedisBackend": newTestRedisBackend,
+	}
+	for name, makeBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			testIndexBackendContract(t, makeBackend(t))
+		})
+	}
+}
+
+func newTestFileBackend(t *testing.T) IndexBackend {
+	tempDir, err := os.MkdirTemp("", "macaudio-backend-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	t.Cleanup(func() { os.Unsetenv("MACAUDIO_CACHE_DIR") })
+	return FileBackend{}
+}
+
+func newTestRedisBackend(t *testing.T) IndexBackend {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return RedisBackend{Client: client, IndexKey: "macaudio:index:test"}
+}
+
+func testIndexBackendContract(t *testing.T, backend IndexBackend) {
+	key := "aufx:FAKE:ACME:Contract Plugin"
+
+	idx, err := backend.LoadIndex()
+	if err != nil {
+		t.Fatalf("LoadIndex on empty store: %v", err)
+	}
+	if idx == nil || len(idx.Entries) != 0 {
+		t.Fatalf("expected empty index, got %+v", idx)
+	}
+
+	idx.Entries[key] = indexEntry{Key: key, Type: "aufx", Subtype: "FAKE", ManufacturerID: "ACME", Name: "Contract Plugin", Checksum: "checksum-v1", LastSeenAt: time.Now()}
+	if err := backend.SaveIndex(idx); err != nil {
+		t.Fatalf("SaveIndex: %v", err)
+	}
+
+	reloaded, err := backend.LoadIndex()
+	if err != nil {
+		t.Fatalf("LoadIndex after save: %v", err)
+	}
+	entry, ok := reloaded.Entries[key]
+	if !ok || entry.Checksum != "checksum-v1" {
+		t.Fatalf("expected reloaded entry for %s, got %+v", key, reloaded.Entries)
+	}
+
+	if _, _, err := backend.GetDetails(key); err == nil {
+		t.Fatal("expected error fetching details before PutDetails")
+	}
+
+	pl := &plugins.Plugin{Type: "aufx", Subtype: "FAKE", ManufacturerID: "ACME", Name: "Contract Plugin"}
+	if err := backend.PutDetails(key, "checksum-v1", pl); err != nil {
+		t.Fatalf("PutDetails: %v", err)
+	}
+
+	got, chk, err := backend.GetDetails(key)
+	if err != nil {
+		t.Fatalf("GetDetails after PutDetails: %v", err)
+	}
+	if chk != "checksum-v1" || got.Name != "Contract Plugin" {
+		t.Fatalf("GetDetails mismatch: checksum=%s plugin=%+v", chk, got)
+	}
+
+	if err := backend.DeleteDetails(key); err != nil {
+		t.Fatalf("DeleteDetails: %v", err)
+	}
+	if _, _, err := backend.GetDetails(key); err == nil {
+		t.Fatal("expected error fetching details after DeleteDetails")
+	}
+}
@@ -2,6 +2,7 @@ package session
 
 import (
 	aveng "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/devices"
 )
 
 // MapLatencyToBuffer maps a LatencyClass to a suggested buffer size in frames.
@@ -24,6 +25,10 @@ func MapLatencyToBuffer(c LatencyClass) int {
 // - If BufferSize is set (>0), it overrides LatencyHint mapping.
 // - SampleRate uses PreferredSampleRate when >0, else avaudio's default.
 // - ChannelCount defaults to 2 (stereo) and BitDepth to 32-bit float.
+// - Direction selects the buffer-size mapping: capture defaults to a
+//   smaller buffer than playback at the same LatencyHint, since a capture
+//   path is usually feeding a monitor or analyzer that wants to see blocks
+//   sooner, not a mixer graph tolerant of the usual output buffering.
 func ResolveEngineSpec(s AudioSpec) aveng.AudioSpec {
 	// Start with avaudio defaults
 	eff := aveng.DefaultAudioSpec()
@@ -34,6 +39,8 @@ func ResolveEngineSpec(s AudioSpec) aveng.AudioSpec {
 
 	if s.BufferSize > 0 {
 		eff.BufferSize = s.BufferSize
+	} else if s.Direction == DirectionInput {
+		eff.BufferSize = MapLatencyToCaptureBuffer(s.LatencyHint)
 	} else {
 		eff.BufferSize = MapLatencyToBuffer(s.LatencyHint)
 	}
@@ -41,3 +48,64 @@ func ResolveEngineSpec(s AudioSpec) aveng.AudioSpec {
 	// Keep defaults for bit depth and channels (engines run 32f stereo)
 	return eff
 }
+
+// ResolvedSpec reports the outcome of negotiating an AudioSpec against a
+// specific device's supported stream configuration - see NegotiateSpec.
+type ResolvedSpec struct {
+	aveng.AudioSpec
+	Adjusted bool // SampleRate and/or BufferSize were clamped to what the device supports
+}
+
+// NegotiateSpec resolves s the same way ResolveEngineSpec does, then clamps
+// the result's SampleRate and BufferSize into dev's supported range for dir
+// (devices.AudioDevice.SupportedInputConfigs/SupportedOutputConfigs),
+// falling back to the nearest value CoreAudio will actually accept instead
+// of letting an unsupported combination reach NewWithDevice and fail there.
+// dev may be nil - e.g. the caller is opening the system default device and
+// has nothing to negotiate against - in which case NegotiateSpec returns
+// ResolveEngineSpec's result unadjusted.
+func NegotiateSpec(s AudioSpec, dev *devices.AudioDevice, dir Direction) ResolvedSpec {
+	eff := ResolveEngineSpec(s)
+
+	if dev == nil {
+		return ResolvedSpec{AudioSpec: eff}
+	}
+
+	var configs []devices.StreamConfigRange
+	if dir == DirectionInput {
+		configs = dev.SupportedInputConfigs()
+	} else {
+		configs = dev.SupportedOutputConfigs()
+	}
+	if len(configs) == 0 {
+		return ResolvedSpec{AudioSpec: eff}
+	}
+
+	rate, buffer, adjusted := configs[0].Nearest(eff.SampleRate, eff.BufferSize)
+	eff.SampleRate = rate
+	eff.BufferSize = buffer
+	return ResolvedSpec{AudioSpec: eff, Adjusted: adjusted}
+}
+
+// MapLatencyToCaptureBuffer is MapLatencyToBuffer's capture-path
+// counterpart: half the playback buffer at the same LatencyHint, floored at
+// 64 frames.
+func MapLatencyToCaptureBuffer(c LatencyClass) int {
+	if b := MapLatencyToBuffer(c) / 2; b >= 64 {
+		return b
+	}
+	return 64
+}
+
+// ResolveProcessingFrames returns the app-side block size a BufferManager
+// should hand out via Pull for s: s.ProcessingFrames if the caller set one,
+// else whatever HAL buffer size ResolveEngineSpec picked - meaning "no
+// decoupling" unless the caller explicitly asks for it. Pass this alongside
+// the resolved HAL buffer size (ResolveEngineSpec/NegotiateSpec's
+// BufferSize) to NewBufferManager.
+func ResolveProcessingFrames(s AudioSpec) int {
+	if s.ProcessingFrames > 0 {
+		return s.ProcessingFrames
+	}
+	return ResolveEngineSpec(s).BufferSize
+}
@@ -0,0 +1,476 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	aveng "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// loudnessCacheVersion stamps the on-disk shape of a LoudnessResult file,
+// the same way indexVersion/detailsVersion/presetsVersion guard their own
+// caches.
+const loudnessCacheVersion = "1.0-loudness"
+
+// LoudnessResult is one file's ITU-R BS.1770 / EBU R128 loudness
+// measurement, as persisted under the plugin cache's loudness/
+// subdirectory by AnalyzeLoudnessCtx/ScanLoudness and read back by
+// LoadLoudnessCache (see engine.Channel.ApplyReplayGain). IntegratedLUFS
+// and TruePeak are LUFS/dBTP; either reads math.Inf(-1) if the file was
+// silent, or too short to survive gating.
+type LoudnessResult struct {
+	Version        string        `json:"version"`
+	Path           string        `json:"path"`
+	IntegratedLUFS float64       `json:"integratedLUFS"`
+	TruePeak       float64       `json:"truePeak"`
+	Duration       time.Duration `json:"duration"`
+	AnalyzedAt     time.Time     `json:"analyzedAt"`
+}
+
+// loudnessBlockSeconds/loudnessBlockOverlap are BS.1770's own 400ms,
+// 75%-overlap gating blocks - wider and more overlapped than
+// avaudio/tap/loudness.go's 100ms non-overlapping blocks, which trade
+// BS.1770 fidelity for cheap, allocation-free running state on a live tap.
+// This package analyzes a whole decoded file at once, so it can afford the
+// reference block size instead of a live-meter approximation.
+const (
+	loudnessBlockSeconds = 0.4
+	loudnessBlockOverlap = 0.75
+)
+
+const (
+	loudnessAbsoluteGateLUFS    = -70.0
+	loudnessRelativeGateDeltaLU = -10.0
+)
+
+// loudnessReadFrames is how many frames measureLoudness reads from the
+// decoded file per avaudio/engine.AudioFile.Read call.
+const loudnessReadFrames = 8192
+
+func loudnessCacheDir() (string, error) {
+	dir, err := getPluginCacheDir()
+	if err != nil {
+		return "", err
+	}
+	loudnessDir := filepath.Join(dir, "loudness")
+	if err := os.MkdirAll(loudnessDir, 0o755); err != nil {
+		return "", err
+	}
+	return loudnessDir, nil
+}
+
+// loudnessCacheKey hashes path+mtime so a file edited since its last scan
+// misses the cache instead of reusing a stale measurement under the same
+// key - mirrors detailFileName's hashing, keyed on (path, mtime) instead of
+// a plugin quad-tuple.
+func loudnessCacheKey(path string, modTime time.Time) string {
+	sum := sha256.Sum256([]byte(path + "|" + modTime.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(sum[:])
+}
+
+func loudnessCachePath(path string, modTime time.Time) (string, error) {
+	dir, err := loudnessCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, loudnessCacheKey(path, modTime)+".json"), nil
+}
+
+func loadLoudnessCache(path string, modTime time.Time) (LoudnessResult, bool, error) {
+	cachePath, err := loudnessCachePath(path, modTime)
+	if err != nil {
+		return LoudnessResult{}, false, err
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return LoudnessResult{}, false, nil
+		}
+		return LoudnessResult{}, false, err
+	}
+	var r LoudnessResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return LoudnessResult{}, false, err
+	}
+	return r, true, nil
+}
+
+// saveLoudnessCache writes r for (path, modTime) via the same
+// write-to-.tmp-then-rename pattern saveIndex/savePresetFile use, so a
+// crash mid-save can't leave a torn loudness/<key>.json a later
+// LoadLoudnessCache would fail to parse.
+func saveLoudnessCache(path string, modTime time.Time, r LoudnessResult) error {
+	cachePath, err := loudnessCachePath(path, modTime)
+	if err != nil {
+		return err
+	}
+	r.Version = loudnessCacheVersion
+	r.Path = path
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cachePath)
+}
+
+// LoadLoudnessCache returns path's cached loudness measurement, if
+// AnalyzeLoudnessCtx or ScanLoudness has already measured it at its
+// current mtime. ok is false (not an error) if path has never been
+// measured, or was last measured before its current mtime - the caller
+// (e.g. engine.Channel.ApplyReplayGain) should run AnalyzeLoudnessCtx
+// first in that case.
+func LoadLoudnessCache(path string) (LoudnessResult, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return LoudnessResult{}, false, err
+	}
+	return loadLoudnessCache(path, info.ModTime())
+}
+
+// AnalyzeLoudnessCtx measures path's integrated loudness and true peak per
+// ITU-R BS.1770/EBU R128, returning the cached result from an earlier call
+// if path's mtime hasn't changed since, and persisting a fresh measurement
+// otherwise (see saveLoudnessCache). ctx only bounds the decode/analysis
+// pass, not the cache lookup.
+func AnalyzeLoudnessCtx(ctx context.Context, path string) (LoudnessResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return LoudnessResult{}, err
+	}
+	if cached, ok, err := loadLoudnessCache(path, info.ModTime()); err != nil {
+		return LoudnessResult{}, err
+	} else if ok {
+		return cached, nil
+	}
+
+	result, err := measureLoudness(ctx, path)
+	if err != nil {
+		return LoudnessResult{}, err
+	}
+	result.AnalyzedAt = time.Now()
+	if err := saveLoudnessCache(path, info.ModTime(), result); err != nil {
+		return LoudnessResult{}, err
+	}
+	return result, nil
+}
+
+// ScanLoudness runs AnalyzeLoudnessCtx over paths in a worker pool of
+// concurrency goroutines (<=0 defaults to 2, matching WarmCtx), reporting
+// progress via hook.OnLoudnessProgress the way WarmCtx reports via
+// OnWarmProgress; hook may be nil. Results are returned in the same order
+// as paths. Once ctx is done, no new analysis is started, but any already
+// in flight still runs to completion rather than leaking its goroutine;
+// the first error encountered (if any) is returned alongside whatever
+// results did complete.
+func ScanLoudness(ctx context.Context, hook MetricsHook, concurrency int, paths ...string) ([]LoudnessResult, error) {
+	if concurrency <= 0 {
+		concurrency = 2
+	}
+	results := make([]LoudnessResult, len(paths))
+	total := len(paths)
+	completed := 0
+	var mu sync.Mutex
+	if hook != nil {
+		hook.OnLoudnessProgress(total, completed)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	errCh := make(chan error, len(paths))
+	for i, path := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		i, path := i, path
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem }()
+			r, err := AnalyzeLoudnessCtx(ctx, path)
+			if err != nil {
+				errCh <- fmt.Errorf("%s: %w", path, err)
+			} else {
+				results[i] = r
+			}
+			mu.Lock()
+			completed++
+			n := completed
+			mu.Unlock()
+			if hook != nil {
+				hook.OnLoudnessProgress(total, n)
+			}
+		}()
+	}
+	for i := 0; i < cap(sem); i++ {
+		sem <- struct{}{}
+	}
+	close(errCh)
+	for e := range errCh {
+		if e != nil {
+			return results, e
+		}
+	}
+	return results, nil
+}
+
+// measureLoudness decodes path in full via avaudio/engine.AudioFile (the
+// reader that package documents for "waveform peak extraction, file
+// analysis", as distinct from the AVAudioPlayerNode-scheduling path used
+// for playback), K-weights every sample, and gates BS.1770-style
+// 400ms/75%-overlap blocks to an integrated LUFS reading, alongside a
+// 4x-oversampled true peak over the raw signal.
+func measureLoudness(ctx context.Context, path string) (LoudnessResult, error) {
+	f, err := aveng.OpenAudioFile(path)
+	if err != nil {
+		return LoudnessResult{}, fmt.Errorf("loudness: opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	sampleRate := f.SampleRate()
+	channels := f.ChannelCount()
+	if channels <= 0 || sampleRate <= 0 {
+		return LoudnessResult{}, fmt.Errorf("loudness: %s reports no channels/sample rate", path)
+	}
+	weights := loudnessChannelWeights(channels)
+
+	filters := make([]loudnessKWeightingFilter, channels)
+	for i := range filters {
+		filters[i] = newLoudnessKWeightingFilter(sampleRate)
+	}
+
+	truePeak := math.Inf(-1)
+	truePeakHist := make([][3]float64, channels)
+
+	var frameEnergy []float64
+	buf := make([]float32, loudnessReadFrames*channels)
+	var totalFrames int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return LoudnessResult{}, err
+		}
+		n, readErr := f.Read(buf)
+		for frame := 0; frame < n; frame++ {
+			var z float64
+			for ch := 0; ch < channels; ch++ {
+				sample := float64(buf[frame*channels+ch])
+
+				hist := truePeakHist[ch]
+				for _, phase := range loudnessTruePeakOversampleFIR {
+					interp := phase[0]*hist[0] + phase[1]*hist[1] + phase[2]*hist[2] + phase[3]*sample
+					if abs := math.Abs(interp); abs > truePeak {
+						truePeak = abs
+					}
+				}
+				truePeakHist[ch] = [3]float64{hist[1], hist[2], sample}
+
+				filtered := filters[ch].process(sample)
+				z += weights[ch] * filtered * filtered
+			}
+			frameEnergy = append(frameEnergy, z)
+		}
+		totalFrames += int64(n)
+		if readErr != nil {
+			break
+		}
+	}
+
+	blockSize := int(sampleRate * loudnessBlockSeconds)
+	if blockSize < 1 {
+		blockSize = 1
+	}
+	hop := int(float64(blockSize) * (1 - loudnessBlockOverlap))
+	if hop < 1 {
+		hop = 1
+	}
+
+	var blocks []float64
+	for start := 0; start+blockSize <= len(frameEnergy); start += hop {
+		var sum float64
+		for _, z := range frameEnergy[start : start+blockSize] {
+			sum += z
+		}
+		blocks = append(blocks, sum/float64(blockSize))
+	}
+
+	return LoudnessResult{
+		IntegratedLUFS: loudnessIntegrated(blocks),
+		TruePeak:       loudnessLinearToDB(truePeak),
+		Duration:       time.Duration(float64(totalFrames) / sampleRate * float64(time.Second)),
+	}, nil
+}
+
+// loudnessIntegrated applies BS.1770's two-pass relative gating to blocks
+// (one mean-square energy value per gating block): an absolute gate at
+// -70 LUFS, then a relative gate at the first pass's mean minus 10 LU, and
+// returns the mean of whatever survives both passes.
+func loudnessIntegrated(blocks []float64) float64 {
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+
+	absoluteGated := make([]float64, 0, len(blocks))
+	for _, z := range blocks {
+		if loudnessLUFS(z) > loudnessAbsoluteGateLUFS {
+			absoluteGated = append(absoluteGated, z)
+		}
+	}
+	if len(absoluteGated) == 0 {
+		return math.Inf(-1)
+	}
+
+	var sum float64
+	for _, z := range absoluteGated {
+		sum += z
+	}
+	relativeGate := loudnessLUFS(sum/float64(len(absoluteGated))) + loudnessRelativeGateDeltaLU
+
+	relativeGated := make([]float64, 0, len(absoluteGated))
+	for _, z := range absoluteGated {
+		if loudnessLUFS(z) > relativeGate {
+			relativeGated = append(relativeGated, z)
+		}
+	}
+	if len(relativeGated) == 0 {
+		return math.Inf(-1)
+	}
+
+	sum = 0
+	for _, z := range relativeGated {
+		sum += z
+	}
+	return loudnessLUFS(sum / float64(len(relativeGated)))
+}
+
+// loudnessLUFS converts a mean-square energy sum to LUFS per ITU-R BS.1770.
+func loudnessLUFS(z float64) float64 {
+	if z <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(z)
+}
+
+func loudnessLinearToDB(amplitude float64) float64 {
+	if amplitude <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(amplitude)
+}
+
+// loudnessChannelWeights returns the ITU-R BS.1770 channel weight for each
+// channel of a stream with this many channels: mono/stereo weight every
+// channel at 1.0, and 5.1 (L/R/C/LFE/Ls/Rs) excludes the LFE channel
+// entirely and weights the surrounds at 1.41 (+1.5 dB). Any other layout
+// falls back to 1.0 for every channel.
+func loudnessChannelWeights(channels int) []float64 {
+	w := make([]float64, channels)
+	for i := range w {
+		w[i] = 1.0
+	}
+	if channels == 6 {
+		w[3] = 0
+		w[4] = 1.41
+		w[5] = 1.41
+	}
+	return w
+}
+
+// loudnessTruePeakOversampleFIR holds cubic-Lagrange interpolation
+// coefficients for 4x oversampling, the same short fixed kernel
+// avaudio/tap/loudness.go's truePeakOversampleFIR uses - an adequate
+// approximation of BS.1770 Annex 2's 48-tap true-peak filter for this
+// offline scan, not a certified compliance measurement.
+var loudnessTruePeakOversampleFIR = [4][4]float64{
+	{0, 1, 0, 0},
+	{-0.0546875, 0.8203125, 0.2734375, -0.0390625},
+	{-0.0625, 0.5625, 0.5625, -0.0625},
+	{-0.0390625, 0.2734375, 0.8203125, -0.0546875},
+}
+
+// loudnessKWeightingFilter is BS.1770's two-stage K-weighting filter: a
+// high-shelf around 1681 Hz cascaded with a high-pass around 38 Hz, each a
+// standard RBJ biquad recomputed per sample rate - an independent copy of
+// avaudio/tap/loudness.go's kWeightingFilter, which isn't exported (that
+// package's analyzer is shaped around a live Tap.Subscribe callback, not
+// a whole decoded file).
+type loudnessKWeightingFilter struct {
+	shelf    loudnessBiquad
+	highpass loudnessBiquad
+}
+
+func newLoudnessKWeightingFilter(sampleRate float64) loudnessKWeightingFilter {
+	return loudnessKWeightingFilter{
+		shelf:    newLoudnessHighShelfBiquad(sampleRate, 1681.0, 4.0),
+		highpass: newLoudnessHighPassBiquad(sampleRate, 38.0, 1/math.Sqrt2),
+	}
+}
+
+func (f *loudnessKWeightingFilter) process(x float64) float64 {
+	return f.highpass.process(f.shelf.process(x))
+}
+
+// loudnessBiquad is a direct-form-I, a0-normalized second-order IIR
+// section.
+type loudnessBiquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	x1, x2     float64
+	y1, y2     float64
+}
+
+func (b *loudnessBiquad) process(x float64) float64 {
+	y := b.b0*x + b.b1*b.x1 + b.b2*b.x2 - b.a1*b.y1 - b.a2*b.y2
+	b.x2, b.x1 = b.x1, x
+	b.y2, b.y1 = b.y1, y
+	return y
+}
+
+// newLoudnessHighShelfBiquad builds an RBJ high-shelf biquad (shelf slope
+// S=1) boosting frequencies above freqHz by gainDB.
+func newLoudnessHighShelfBiquad(sampleRate, freqHz, gainDB float64) loudnessBiquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * freqHz / sampleRate
+	cosw0 := math.Cos(w0)
+	sinw0 := math.Sin(w0)
+	alpha := sinw0 / 2 * math.Sqrt(2)
+	sqrtA := math.Sqrt(a)
+
+	b0 := a * ((a + 1) + (a-1)*cosw0 + 2*sqrtA*alpha)
+	b1 := -2 * a * ((a - 1) + (a+1)*cosw0)
+	b2 := a * ((a + 1) + (a-1)*cosw0 - 2*sqrtA*alpha)
+	a0 := (a + 1) - (a-1)*cosw0 + 2*sqrtA*alpha
+	a1 := 2 * ((a - 1) - (a+1)*cosw0)
+	a2 := (a + 1) - (a-1)*cosw0 - 2*sqrtA*alpha
+
+	return loudnessBiquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// newLoudnessHighPassBiquad builds an RBJ high-pass biquad with the given
+// Q.
+func newLoudnessHighPassBiquad(sampleRate, freqHz, q float64) loudnessBiquad {
+	w0 := 2 * math.Pi * freqHz / sampleRate
+	cosw0 := math.Cos(w0)
+	sinw0 := math.Sin(w0)
+	alpha := sinw0 / (2 * q)
+
+	b0 := (1 + cosw0) / 2
+	b1 := -(1 + cosw0)
+	b2 := (1 + cosw0) / 2
+	a0 := 1 + alpha
+	a1 := -2 * cosw0
+	a2 := 1 - alpha
+
+	return loudnessBiquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
@@ -0,0 +1,85 @@
+//go:build darwin
+
+package session
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoudnessIntegratedGatesOutSilenceAndQuietOutliers(t *testing.T) {
+	loud := math.Pow(10, (-14.0+0.691)/10)
+	quiet := math.Pow(10, (-50.0+0.691)/10)
+	silent := math.Pow(10, (-120.0+0.691)/10)
+
+	blocks := []float64{silent, quiet, loud, loud, loud, loud, loud}
+	got := loudnessIntegrated(blocks)
+	if math.Abs(got-(-14.0)) > 0.01 {
+		t.Errorf("loudnessIntegrated(%v) = %v, want ~-14 LUFS", blocks, got)
+	}
+}
+
+func TestLoudnessIntegratedOfEmptyBlocksIsNegativeInfinity(t *testing.T) {
+	if got := loudnessIntegrated(nil); !math.IsInf(got, -1) {
+		t.Errorf("loudnessIntegrated(nil) = %v, want -Inf", got)
+	}
+}
+
+func TestLoudnessLinearToDB(t *testing.T) {
+	if got := loudnessLinearToDB(1.0); got != 0 {
+		t.Errorf("loudnessLinearToDB(1.0) = %v, want 0", got)
+	}
+	if got := loudnessLinearToDB(0); !math.IsInf(got, -1) {
+		t.Errorf("loudnessLinearToDB(0) = %v, want -Inf", got)
+	}
+}
+
+func TestLoudnessChannelWeightsExcludesLFEAndWeightsSurrounds(t *testing.T) {
+	w := loudnessChannelWeights(6)
+	want := []float64{1.0, 1.0, 1.0, 0, 1.41, 1.41}
+	for i := range want {
+		if w[i] != want[i] {
+			t.Errorf("loudnessChannelWeights(6)[%d] = %v, want %v", i, w[i], want[i])
+		}
+	}
+}
+
+func TestLoudnessCacheRoundTripsAndMissesOnMTimeChange(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-loudness-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	path := "/tmp/track.wav"
+	modTime := time.Now()
+	want := LoudnessResult{IntegratedLUFS: -16.2, TruePeak: -1.1, Duration: 3 * time.Second}
+
+	if err := saveLoudnessCache(path, modTime, want); err != nil {
+		t.Fatalf("saveLoudnessCache: %v", err)
+	}
+
+	got, ok, err := loadLoudnessCache(path, modTime)
+	if err != nil {
+		t.Fatalf("loadLoudnessCache: %v", err)
+	}
+	if !ok {
+		t.Fatal("loadLoudnessCache reported no cache entry right after saving one")
+	}
+	if got.IntegratedLUFS != want.IntegratedLUFS || got.TruePeak != want.TruePeak || got.Duration != want.Duration {
+		t.Errorf("loadLoudnessCache = %+v, want %+v", got, want)
+	}
+	if got.Path != path {
+		t.Errorf("Path = %q, want %q", got.Path, path)
+	}
+
+	if _, ok, err := loadLoudnessCache(path, modTime.Add(time.Second)); err != nil {
+		t.Fatalf("loadLoudnessCache with a different mtime: %v", err)
+	} else if ok {
+		t.Error("expected a cache miss once mtime changes")
+	}
+}
@@ -0,0 +1,517 @@
+//go:build darwin
+
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	aveng "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// metadataCacheVersion stamps the on-disk shape of a Metadata file, the same
+// way loudnessCacheVersion/indexVersion/detailsVersion guard their own
+// caches.
+const metadataCacheVersion = "1.0-metadata"
+
+// Metadata is the tag and format information ProbeAudioFile extracts from an
+// audio file - ID3v2 (MP3), MP4 atoms (M4A/AAC) or Vorbis comments (FLAC) for
+// Title/Artist/Album/TrackNumber/cover art, plus format-level SampleRate/
+// Channels/DurationSeconds read from the file header via
+// avaudio/engine.OpenAudioFile. engine.PlaybackOptions.Metadata holds one of
+// these so a UI can render track info from the serialized channel state
+// alone, without re-opening the file.
+type Metadata struct {
+	Version         string  `json:"version"`
+	Path            string  `json:"path"`
+	Title           string  `json:"title,omitempty"`
+	Artist          string  `json:"artist,omitempty"`
+	Album           string  `json:"album,omitempty"`
+	TrackNumber     int     `json:"trackNumber,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	SampleRate      float64 `json:"sampleRate"`
+	Channels        int     `json:"channels"`
+	CoverArt        []byte  `json:"coverArt,omitempty"`
+	CoverArtMIME    string  `json:"coverArtMIME,omitempty"`
+}
+
+func metadataCacheDir() (string, error) {
+	dir, err := getPluginCacheDir()
+	if err != nil {
+		return "", err
+	}
+	metaDir := filepath.Join(dir, "metadata")
+	if err := os.MkdirAll(metaDir, 0o755); err != nil {
+		return "", err
+	}
+	return metaDir, nil
+}
+
+// metadataCacheKey hashes path+size+mtime, so a file replaced in place
+// (same path, different bytes) misses the cache instead of reusing stale
+// tags - mirrors loudnessCacheKey, keyed on an extra field since tags (unlike
+// a loudness measurement) are cheap enough to re-read that a false cache hit
+// isn't worth the risk of a truncated download landing at the same mtime.
+func metadataCacheKey(path string, size int64, modTime int64) string {
+	sum := sha256.Sum256([]byte(path + "|" + strconv.FormatInt(size, 10) + "|" + strconv.FormatInt(modTime, 10)))
+	return hex.EncodeToString(sum[:])
+}
+
+func metadataCachePath(path string, size int64, modTime int64) (string, error) {
+	dir, err := metadataCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, metadataCacheKey(path, size, modTime)+".json"), nil
+}
+
+func loadMetadataCache(path string, size int64, modTime int64) (Metadata, bool, error) {
+	cachePath, err := metadataCachePath(path, size, modTime)
+	if err != nil {
+		return Metadata{}, false, err
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Metadata{}, false, nil
+		}
+		return Metadata{}, false, err
+	}
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Metadata{}, false, err
+	}
+	return m, true, nil
+}
+
+// saveMetadataCache writes m via the same write-to-.tmp-then-rename pattern
+// saveLoudnessCache/saveIndex use, so a crash mid-save can't leave a torn
+// metadata/<key>.json a later ProbeAudioFile would fail to parse.
+func saveMetadataCache(path string, size int64, modTime int64, m Metadata) error {
+	cachePath, err := metadataCachePath(path, size, modTime)
+	if err != nil {
+		return err
+	}
+	m.Version = metadataCacheVersion
+	m.Path = path
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cachePath)
+}
+
+// ProbeAudioFile returns path's tags and format info, reading the cached
+// result from an earlier call if path's (size, mtime) pair hasn't changed
+// since, and extracting a fresh one otherwise (see saveMetadataCache). Tag
+// extraction is best-effort: a file whose tag container isn't recognized (or
+// that carries no tags at all) still returns format info with the tag
+// fields left at their zero value, rather than an error - only a failure to
+// open/stat the file itself, or to read its format header, is an error.
+func ProbeAudioFile(path string) (*Metadata, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	size, modTime := info.Size(), info.ModTime().UnixNano()
+	if cached, ok, err := loadMetadataCache(path, size, modTime); err != nil {
+		return nil, err
+	} else if ok {
+		return &cached, nil
+	}
+
+	f, err := aveng.OpenAudioFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: opening %s: %w", path, err)
+	}
+	sampleRate := f.SampleRate()
+	channels := f.ChannelCount()
+	frames := f.FrameCount()
+	f.Close()
+
+	m := Metadata{
+		SampleRate: sampleRate,
+		Channels:   channels,
+	}
+	if sampleRate > 0 {
+		m.DurationSeconds = float64(frames) / sampleRate
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: reading %s: %w", path, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		parseID3v2Tags(raw, &m)
+	case ".flac":
+		parseFLACTags(raw, &m)
+	case ".m4a", ".aac":
+		parseMP4Tags(raw, &m)
+	}
+
+	if err := saveMetadataCache(path, size, modTime, m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// parseID3v2Tags fills Title/Artist/Album/TrackNumber/CoverArt from an
+// ID3v2.2/2.3/2.4 tag at the start of raw, if one is present. Unsynchronized
+// frames and extended headers aren't handled - this covers the common case
+// most taggers write, not the full spec.
+func parseID3v2Tags(raw []byte, m *Metadata) {
+	if len(raw) < 10 || string(raw[0:3]) != "ID3" {
+		return
+	}
+	majorVersion := raw[3]
+	flags := raw[5]
+	tagSize := syncsafeInt(raw[6:10])
+	if 10+tagSize > len(raw) {
+		tagSize = len(raw) - 10
+	}
+	body := raw[10 : 10+tagSize]
+	if flags&0x40 != 0 && len(body) >= 4 {
+		// Extended header present - skip it.
+		extSize := syncsafeInt(body[0:4])
+		if extSize > 0 && extSize < len(body) {
+			body = body[extSize:]
+		}
+	}
+
+	idSize, frameHeaderSize := 4, 10
+	if majorVersion == 2 {
+		idSize, frameHeaderSize = 3, 6
+	}
+
+	for len(body) >= frameHeaderSize {
+		id := string(body[:idSize])
+		if id == "" || id[0] == 0 {
+			break
+		}
+		var size int
+		if majorVersion == 2 {
+			size = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		} else if majorVersion == 4 {
+			size = syncsafeInt(body[idSize : idSize+4])
+		} else {
+			size = int(binary.BigEndian.Uint32(body[idSize : idSize+4]))
+		}
+		frameStart := frameHeaderSize
+		if frameStart+size > len(body) {
+			break
+		}
+		frame := body[frameStart : frameStart+size]
+
+		switch id {
+		case "TIT2", "TT2":
+			m.Title = decodeID3Text(frame)
+		case "TPE1", "TP1":
+			m.Artist = decodeID3Text(frame)
+		case "TALB", "TAL":
+			m.Album = decodeID3Text(frame)
+		case "TRCK", "TRK":
+			if n, err := strconv.Atoi(strings.SplitN(decodeID3Text(frame), "/", 2)[0]); err == nil {
+				m.TrackNumber = n
+			}
+		case "APIC", "PIC":
+			parseID3CoverFrame(frame, majorVersion, m)
+		}
+
+		body = body[frameStart+size:]
+	}
+}
+
+// syncsafeInt decodes a 4-byte ID3v2 "syncsafe" integer: 7 usable bits per
+// byte, the high bit always 0, so a tag size can never be mistaken for a
+// frame sync.
+func syncsafeInt(b []byte) int {
+	return int(b[0])<<21 | int(b[1])<<14 | int(b[2])<<7 | int(b[3])
+}
+
+// decodeID3Text strips a text frame's leading encoding byte and trailing
+// NUL padding. Frames encoded as UTF-16 (encoding byte 1 or 2) are left
+// as-is rather than transcoded - good enough to recover ASCII-range tags,
+// which covers the overwhelming majority of files this is run against.
+func decodeID3Text(frame []byte) string {
+	if len(frame) == 0 {
+		return ""
+	}
+	encoding, text := frame[0], frame[1:]
+	s := strings.TrimRight(string(text), "\x00")
+	if encoding == 1 || encoding == 2 {
+		s = strings.Map(func(r rune) rune {
+			if r == 0 {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseID3CoverFrame extracts the image bytes and MIME type from an
+// APIC (ID3v2.3/2.4) or PIC (ID3v2.2) frame.
+func parseID3CoverFrame(frame []byte, majorVersion byte, m *Metadata) {
+	if len(frame) < 2 {
+		return
+	}
+	rest := frame[1:]
+	var mime string
+	if majorVersion == 2 {
+		if len(rest) < 3 {
+			return
+		}
+		mime = "image/" + strings.ToLower(string(rest[:3]))
+		rest = rest[3:]
+	} else {
+		nul := strings.IndexByte(string(rest), 0)
+		if nul < 0 {
+			return
+		}
+		mime = string(rest[:nul])
+		rest = rest[nul+1:]
+	}
+	if len(rest) < 2 {
+		return
+	}
+	rest = rest[1:] // picture type byte
+	nul := strings.IndexByte(string(rest), 0)
+	if nul < 0 {
+		return
+	}
+	rest = rest[nul+1:]
+	if len(rest) == 0 {
+		return
+	}
+	m.CoverArt = append([]byte(nil), rest...)
+	m.CoverArtMIME = mime
+}
+
+// parseFLACTags reads a FLAC file's VORBIS_COMMENT and PICTURE metadata
+// blocks, per the format in
+// https://xiph.org/flac/format.html#metadata_block .
+func parseFLACTags(raw []byte, m *Metadata) {
+	if len(raw) < 4 || string(raw[0:4]) != "fLaC" {
+		return
+	}
+	pos := 4
+	for pos+4 <= len(raw) {
+		header := raw[pos]
+		last := header&0x80 != 0
+		blockType := header & 0x7f
+		length := int(raw[pos+1])<<16 | int(raw[pos+2])<<8 | int(raw[pos+3])
+		pos += 4
+		if pos+length > len(raw) {
+			break
+		}
+		block := raw[pos : pos+length]
+
+		switch blockType {
+		case 4: // VORBIS_COMMENT
+			parseVorbisComment(block, m)
+		case 6: // PICTURE
+			parseFLACPicture(block, m)
+		}
+
+		pos += length
+		if last {
+			break
+		}
+	}
+}
+
+// parseVorbisComment parses a Vorbis comment block's vendor string and
+// "KEY=value" comment list (little-endian length-prefixed, per
+// https://xiph.org/vorbis/doc/v-comment.html), recognizing TITLE/ARTIST/
+// ALBUM/TRACKNUMBER case-insensitively.
+func parseVorbisComment(block []byte, m *Metadata) {
+	read32 := func(b []byte, off int) (int, bool) {
+		if off+4 > len(b) {
+			return 0, false
+		}
+		return int(binary.LittleEndian.Uint32(b[off : off+4])), true
+	}
+
+	vendorLen, ok := read32(block, 0)
+	if !ok {
+		return
+	}
+	pos := 4 + vendorLen
+	count, ok := read32(block, pos)
+	if !ok {
+		return
+	}
+	pos += 4
+
+	for i := 0; i < count; i++ {
+		entryLen, ok := read32(block, pos)
+		if !ok {
+			return
+		}
+		pos += 4
+		if pos+entryLen > len(block) {
+			return
+		}
+		entry := string(block[pos : pos+entryLen])
+		pos += entryLen
+
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.ToUpper(kv[0]) {
+		case "TITLE":
+			m.Title = kv[1]
+		case "ARTIST":
+			m.Artist = kv[1]
+		case "ALBUM":
+			m.Album = kv[1]
+		case "TRACKNUMBER":
+			if n, err := strconv.Atoi(strings.SplitN(kv[1], "/", 2)[0]); err == nil {
+				m.TrackNumber = n
+			}
+		}
+	}
+}
+
+// parseFLACPicture extracts the image bytes/MIME from a FLAC PICTURE block,
+// whose layout (all big-endian) is: type(4) mimeLen(4) mime desclen(4) desc
+// width(4) height(4) depth(4) colors(4) dataLen(4) data.
+func parseFLACPicture(block []byte, m *Metadata) {
+	pos := 4
+	if pos+4 > len(block) {
+		return
+	}
+	mimeLen := int(binary.BigEndian.Uint32(block[pos : pos+4]))
+	pos += 4
+	if pos+mimeLen > len(block) {
+		return
+	}
+	mime := string(block[pos : pos+mimeLen])
+	pos += mimeLen
+
+	if pos+4 > len(block) {
+		return
+	}
+	descLen := int(binary.BigEndian.Uint32(block[pos : pos+4]))
+	pos += 4 + descLen
+
+	pos += 4 + 4 + 4 + 4 // width, height, depth, colors
+	if pos+4 > len(block) {
+		return
+	}
+	dataLen := int(binary.BigEndian.Uint32(block[pos : pos+4]))
+	pos += 4
+	if pos+dataLen > len(block) {
+		return
+	}
+	m.CoverArt = append([]byte(nil), block[pos:pos+dataLen]...)
+	m.CoverArtMIME = mime
+}
+
+// parseMP4Tags walks an M4A/AAC container's box tree down to
+// moov/udta/meta/ilst, and reads the common iTunes-style atoms: ©nam, ©ART,
+// ©alb, trkn and covr. Only the subset of box nesting ilst tags live under
+// is walked - anything else in moov (mvhd, trak, ...) is skipped over by
+// size rather than parsed.
+func parseMP4Tags(raw []byte, m *Metadata) {
+	moov := findMP4Box(raw, "moov")
+	if moov == nil {
+		return
+	}
+	udta := findMP4Box(moov, "udta")
+	if udta == nil {
+		return
+	}
+	meta := findMP4Box(udta, "meta")
+	if meta == nil {
+		return
+	}
+	// A "meta" box has a 4-byte version/flags header before its children.
+	if len(meta) < 4 {
+		return
+	}
+	ilst := findMP4Box(meta[4:], "ilst")
+	if ilst == nil {
+		return
+	}
+
+	readDataAtom := func(box []byte) []byte {
+		data := findMP4Box(box, "data")
+		if len(data) < 8 {
+			return nil
+		}
+		return data[8:] // 4-byte type + 4-byte locale
+	}
+
+	pos := 0
+	for pos+8 <= len(ilst) {
+		size := int(binary.BigEndian.Uint32(ilst[pos : pos+4]))
+		name := string(ilst[pos+4 : pos+8])
+		if size < 8 || pos+size > len(ilst) {
+			break
+		}
+		box := ilst[pos+8 : pos+size]
+
+		switch name {
+		case "\xa9nam":
+			m.Title = string(readDataAtom(box))
+		case "\xa9ART":
+			m.Artist = string(readDataAtom(box))
+		case "\xa9alb":
+			m.Album = string(readDataAtom(box))
+		case "trkn":
+			if d := readDataAtom(box); len(d) >= 4 {
+				m.TrackNumber = int(binary.BigEndian.Uint16(d[2:4]))
+			}
+		case "covr":
+			if d := readDataAtom(box); len(d) > 0 {
+				m.CoverArt = append([]byte(nil), d...)
+				m.CoverArtMIME = mp4CoverMIME(d)
+			}
+		}
+
+		pos += size
+	}
+}
+
+// findMP4Box returns the payload (everything after the 8-byte size+fourcc
+// header) of the first top-level box named name within data, or nil if
+// none is found.
+func findMP4Box(data []byte, name string) []byte {
+	pos := 0
+	for pos+8 <= len(data) {
+		size := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		fourcc := string(data[pos+4 : pos+8])
+		if size < 8 || pos+size > len(data) {
+			return nil
+		}
+		if fourcc == name {
+			return data[pos+8 : pos+size]
+		}
+		pos += size
+	}
+	return nil
+}
+
+// mp4CoverMIME guesses a covr atom's image type from its magic bytes -
+// the data atom's own type field (PNG=14/JPEG=13) would be more precise,
+// but sniffing the payload works whether or not a given file sets it.
+func mp4CoverMIME(data []byte) string {
+	if len(data) >= 8 && string(data[1:4]) == "PNG" {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
@@ -2,7 +2,11 @@
 
 package session
 
-import "time"
+import (
+    "time"
+
+    "github.com/shaban/macaudio/devices"
+)
 
 // MetricsHook allows callers to observe key events and durations in the session.
 // Implementers can log, aggregate metrics, or emit traces. All methods are optional.
@@ -24,4 +28,51 @@ type MetricsHook interface {
 
     // Warm progress updates
     OnWarmProgress(total, completed int)
+
+    // OnWarmupProgress fires after StartWarmup starts (done=0) and after
+    // each introspection it runs completes, with the running done/total
+    // count against the pass's pending set, cacheHits accumulated so far
+    // (entries skipped because their cached checksum was already current),
+    // failures accumulated so far, and the duration of the introspection
+    // that just finished (0 for the initial call).
+    OnWarmupProgress(done, total, cacheHits, failures int, lastDuration time.Duration)
+
+    // OnAutoWarmTick fires after each StartAutoWarm pass completes, with the
+    // Added/Changed counts from that pass's RefreshQuick diff (the keys
+    // actually warmed) and the pass's total duration.
+    OnAutoWarmTick(added, changed int, duration time.Duration)
+
+    // Subscribe backpressure signals (see Session.Subscribe): OnSubscriptionLag
+    // fires each time a subscriber's channel is found full, OnSubscriptionEvicted
+    // when it stayed full past the hub's drop deadline and got dropped.
+    OnSubscriptionLag(subscriptionID int, queueLen int)
+    OnSubscriptionEvicted(subscriptionID int)
+
+    // OnLoudnessProgress fires after each path ScanLoudness analyzes (or
+    // finds already cached), with the running completed count against the
+    // pool's total - mirrors OnWarmProgress's semantics for Warm/WarmCtx.
+    OnLoudnessProgress(total, completed int)
+
+    // OnCacheMigration fires when loadIndex/readDetails (or RedisBackend's
+    // LoadIndex/GetDetails) upgrade a stored record through one or more
+    // registered migrations instead of serving it as-is - see
+    // RegisterIndexMigration/RegisterDetailsMigration. kind is "index" or
+    // "details"; steps is how many chain links were applied to get from
+    // fromVersion to toVersion.
+    OnCacheMigration(kind, fromVersion, toVersion string, steps int)
+
+    // OnCacheQuarantine fires when a cached details entry can't be read or
+    // migrated up to detailsVersion and is moved aside rather than silently
+    // dropped, so it can be inspected instead of losing the prior
+    // introspection result outright.
+    OnCacheQuarantine(key, reason string)
+
+    // Device hotplug/invalidation signals, fed by devices.Watch (see
+    // engine.Engine.WatchDevices for the capture-channel consumer of the
+    // same feed). OnDeviceAdded/OnDeviceRemoved fire for any audio device,
+    // identified by UID; OnDefaultDeviceChanged fires when the system
+    // default input or output device changes, naming which role moved.
+    OnDeviceAdded(uid string)
+    OnDeviceRemoved(uid string)
+    OnDefaultDeviceChanged(kind devices.DefaultDeviceKind, uid string)
 }
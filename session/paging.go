@@ -0,0 +1,312 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// reconcilePageSize is the fixed chunk size PageChecksums and RefreshQuick's
+// incremental diff are keyed by. Independent of PageRequest.MaxKeys, which a
+// caller of ScanPluginsPage picks for its own listing UI.
+const reconcilePageSize = 50
+
+// defaultMaxKeys is used by ScanPluginsPage when PageRequest.MaxKeys <= 0.
+const defaultMaxKeys = 100
+
+// PluginFilter narrows ScanPluginsPage to entries matching every non-empty
+// field. The zero value matches everything.
+type PluginFilter struct {
+	Type           string
+	Subtype        string
+	ManufacturerID string
+}
+
+func (f PluginFilter) matches(e indexEntry) bool {
+	if f.Type != "" && f.Type != e.Type {
+		return false
+	}
+	if f.Subtype != "" && f.Subtype != e.Subtype {
+		return false
+	}
+	if f.ManufacturerID != "" && f.ManufacturerID != e.ManufacturerID {
+		return false
+	}
+	return true
+}
+
+// PageRequest drives one ScanPluginsPage call. ContinuationToken is the last
+// key returned by the previous page (the zero value starts from the
+// beginning); MaxKeys caps how many entries come back.
+type PageRequest struct {
+	ContinuationToken string
+	MaxKeys           int
+	Filter            PluginFilter
+}
+
+// PluginIndexEntry is the exported mirror of indexEntry returned by
+// ScanPluginsPage - session keeps indexEntry itself unexported since it's
+// also the on-disk shape for index.json.
+type PluginIndexEntry struct {
+	Key            string
+	Type           string
+	Subtype        string
+	ManufacturerID string
+	Name           string
+	Category       string
+	Checksum       string
+}
+
+// PageResponse is one page of a ScanPluginsPage listing.
+type PageResponse struct {
+	Entries               []PluginIndexEntry
+	NextContinuationToken string
+	Truncated             bool
+	IndexVersion          string
+}
+
+// ScanPluginsPage lists the plugin index in stable lexicographic key order,
+// resumable via ContinuationToken - a scan interrupted by process exit or
+// Session.Close can pick up on next launch from the last token instead of
+// restarting from zero, and UI code can use it to lazily populate long
+// plugin lists a page at a time.
+func (s *Session) ScanPluginsPage(ctx context.Context, req PageRequest) (PageResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return PageResponse{}, err
+	}
+
+	s.idxMu.RLock()
+	idx := s.idxSnap
+	s.idxMu.RUnlock()
+	if idx == nil {
+		var err error
+		idx, err = loadIndex()
+		if err != nil {
+			return PageResponse{}, err
+		}
+	}
+
+	maxKeys := req.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultMaxKeys
+	}
+
+	keys := sortedKeys(idx.Entries)
+	start := 0
+	if req.ContinuationToken != "" {
+		start = sort.SearchStrings(keys, req.ContinuationToken)
+		if start < len(keys) && keys[start] == req.ContinuationToken {
+			start++
+		}
+	}
+
+	resp := PageResponse{IndexVersion: idx.Version}
+	for i := start; i < len(keys) && len(resp.Entries) < maxKeys; i++ {
+		e := idx.Entries[keys[i]]
+		if !req.Filter.matches(e) {
+			continue
+		}
+		resp.Entries = append(resp.Entries, PluginIndexEntry{
+			Key: e.Key, Type: e.Type, Subtype: e.Subtype, ManufacturerID: e.ManufacturerID,
+			Name: e.Name, Category: e.Category, Checksum: e.Checksum,
+		})
+		if len(resp.Entries) == maxKeys {
+			// advance past this key so NextContinuationToken resumes correctly
+			for j := i + 1; j < len(keys); j++ {
+				if req.Filter.matches(idx.Entries[keys[j]]) {
+					resp.Truncated = true
+					break
+				}
+			}
+			resp.NextContinuationToken = keys[i]
+		}
+	}
+	return resp, nil
+}
+
+func sortedKeys(entries map[string]indexEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pageChecksumsFor hashes fixed-size, lexicographically-ordered chunks of
+// entries' keys (key=checksum pairs) into one checksum per chunk. Comparing
+// two indexes' PageChecksums pairwise tells RefreshQuick's reconciliation
+// which chunks to actually re-diff.
+//
+// A chunk's composition shifts if a key before it is added/removed (every
+// subsequent chunk's checksum then differs too), so this can flag more
+// chunks as changed than strictly necessary around an insertion/deletion -
+// it never flags fewer, so it's a safe, conservative approximation rather
+// than an exact minimal diff.
+func pageChecksumsFor(entries map[string]indexEntry) []string {
+	keys := sortedKeys(entries)
+	var sums []string
+	for i := 0; i < len(keys); i += reconcilePageSize {
+		end := i + reconcilePageSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		h := sha256.New()
+		for _, k := range keys[i:end] {
+			fmt.Fprintf(h, "%s=%s;", k, entries[k].Checksum)
+		}
+		sums = append(sums, hex.EncodeToString(h.Sum(nil)))
+	}
+	return sums
+}
+
+// keysInPage returns the slice of sorted keys belonging to chunk index page,
+// given reconcilePageSize-sized chunking - the same windowing pageChecksumsFor
+// used to produce that chunk's checksum.
+func keysInPage(keys []string, page int) []string {
+	start := page * reconcilePageSize
+	if start >= len(keys) {
+		return nil
+	}
+	end := start + reconcilePageSize
+	if end > len(keys) {
+		end = len(keys)
+	}
+	return keys[start:end]
+}
+
+// diffByPage compares old and new entry sets restricted to the chunks whose
+// PageChecksums differ (or, if oldPages is empty - e.g. right after a v1
+// migration - falls back to a full comparison). See pageChecksumsFor for why
+// this is conservative rather than exact.
+func diffByPage(old, new_ map[string]indexEntry, oldPages, newPages []string) QuickDiff {
+	diff := QuickDiff{}
+	if len(oldPages) == 0 {
+		return diffAll(old, new_)
+	}
+
+	oldKeys := sortedKeys(old)
+	newKeys := sortedKeys(new_)
+	pages := len(oldPages)
+	if len(newPages) > pages {
+		pages = len(newPages)
+	}
+
+	changed := map[string]bool{}
+	for p := 0; p < pages; p++ {
+		var oldSum, newSum string
+		if p < len(oldPages) {
+			oldSum = oldPages[p]
+		}
+		if p < len(newPages) {
+			newSum = newPages[p]
+		}
+		if oldSum == newSum {
+			continue
+		}
+		for _, k := range keysInPage(oldKeys, p) {
+			changed[k] = true
+		}
+		for _, k := range keysInPage(newKeys, p) {
+			changed[k] = true
+		}
+	}
+
+	for k := range changed {
+		ov, okOld := old[k]
+		nv, okNew := new_[k]
+		switch {
+		case okOld && !okNew:
+			diff.Removed = append(diff.Removed, k)
+		case !okOld && okNew:
+			diff.Added = append(diff.Added, k)
+		case okOld && okNew && ov.Checksum != nv.Checksum:
+			diff.Changed = append(diff.Changed, k)
+		}
+	}
+	return diff
+}
+
+// sortedStringKeys mirrors sortedKeys for the map[string]string shape
+// findPluginChanges works with (key -> quick-info checksum), since Go has
+// no generic map-keys helper this package otherwise uses.
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pageChecksumsForQuickInfo is pageChecksumsFor's map[string]string
+// counterpart, for findPluginChanges' cachedQuickInfo/currentLookup maps.
+func pageChecksumsForQuickInfo(m map[string]string) []string {
+	keys := sortedStringKeys(m)
+	var sums []string
+	for i := 0; i < len(keys); i += reconcilePageSize {
+		end := i + reconcilePageSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		h := sha256.New()
+		for _, k := range keys[i:end] {
+			fmt.Fprintf(h, "%s=%s;", k, m[k])
+		}
+		sums = append(sums, hex.EncodeToString(h.Sum(nil)))
+	}
+	return sums
+}
+
+// changedReasonsFor classifies why each key in changed has a new checksum,
+// for QuickDiff.ChangedReasons. schemaStale marks every changed key "schema"
+// when this pass also migrated a stale SchemaVersion, since a schema
+// migration drops cached details for everything and callers (e.g. autowarm)
+// may want to treat that batch with more suspicion than an ordinary
+// category edit. Otherwise a key is "category" when Category differs (the
+// only other field checksumQuick currently hashes) or "checksum" as a
+// forward-compatible fallback for when checksumQuick covers more fields
+// than it does today.
+func changedReasonsFor(old, new_ map[string]indexEntry, changed []string, schemaStale bool) map[string]string {
+	if len(changed) == 0 {
+		return nil
+	}
+	reasons := make(map[string]string, len(changed))
+	for _, k := range changed {
+		switch {
+		case schemaStale:
+			reasons[k] = "schema"
+		case old[k].Category != new_[k].Category:
+			reasons[k] = "category"
+		default:
+			reasons[k] = "checksum"
+		}
+	}
+	return reasons
+}
+
+// diffAll is the original full-walk diff, used as a fallback when no prior
+// PageChecksums are available to restrict the comparison to.
+func diffAll(old, new_ map[string]indexEntry) QuickDiff {
+	diff := QuickDiff{}
+	for k, ov := range old {
+		nv, ok := new_[k]
+		if !ok {
+			diff.Removed = append(diff.Removed, k)
+			continue
+		}
+		if ov.Checksum != nv.Checksum {
+			diff.Changed = append(diff.Changed, k)
+		}
+	}
+	for k := range new_ {
+		if _, ok := old[k]; !ok {
+			diff.Added = append(diff.Added, k)
+		}
+	}
+	return diff
+}
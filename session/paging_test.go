@@ -0,0 +1,110 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestPageChecksumsForDetectsChangedPageOnly(t *testing.T) {
+	a := map[string]indexEntry{
+		"k1": {Key: "k1", Checksum: "c1"},
+		"k2": {Key: "k2", Checksum: "c2"},
+	}
+	b := map[string]indexEntry{
+		"k1": {Key: "k1", Checksum: "c1"},
+		"k2": {Key: "k2", Checksum: "c2-changed"},
+	}
+	sumsA := pageChecksumsFor(a)
+	sumsB := pageChecksumsFor(b)
+	if len(sumsA) != 1 || len(sumsB) != 1 {
+		t.Fatalf("expected a single page for %d entries, got %d/%d", len(a), len(sumsA), len(sumsB))
+	}
+	if sumsA[0] == sumsB[0] {
+		t.Error("expected page checksum to differ after a key's checksum changed")
+	}
+}
+
+func TestDiffByPageFallsBackWithoutPriorChecksums(t *testing.T) {
+	old := map[string]indexEntry{"k1": {Key: "k1", Checksum: "c1"}}
+	new_ := map[string]indexEntry{"k2": {Key: "k2", Checksum: "c2"}}
+
+	diff := diffByPage(old, new_, nil, pageChecksumsFor(new_))
+	if len(diff.Added) != 1 || diff.Added[0] != "k2" {
+		t.Errorf("Added = %v, want [k2]", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "k1" {
+		t.Errorf("Removed = %v, want [k1]", diff.Removed)
+	}
+}
+
+func TestDiffByPageSkipsUnchangedPages(t *testing.T) {
+	old := map[string]indexEntry{
+		"a": {Key: "a", Checksum: "1"},
+		"b": {Key: "b", Checksum: "1"},
+	}
+	new_ := map[string]indexEntry{
+		"a": {Key: "a", Checksum: "1"},
+		"b": {Key: "b", Checksum: "2"}, // changed
+	}
+	oldPages := pageChecksumsFor(old)
+	newPages := pageChecksumsFor(new_)
+
+	diff := diffByPage(old, new_, oldPages, newPages)
+	if len(diff.Changed) != 1 || diff.Changed[0] != "b" {
+		t.Errorf("Changed = %v, want [b]", diff.Changed)
+	}
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("expected no Added/Removed, got %+v", diff)
+	}
+}
+
+func TestScanPluginsPageFiltersAndPaginates(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-paging-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	sess.idxMu.Lock()
+	sess.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{
+		"aufx:a:ACME:Alpha": {Key: "aufx:a:ACME:Alpha", Type: "aufx", ManufacturerID: "ACME"},
+		"aufx:b:ACME:Beta":  {Key: "aufx:b:ACME:Beta", Type: "aufx", ManufacturerID: "ACME"},
+		"aufx:c:OTHR:Gamma": {Key: "aufx:c:OTHR:Gamma", Type: "aufx", ManufacturerID: "OTHR"},
+	}}
+	sess.idxMu.Unlock()
+
+	page1, err := sess.ScanPluginsPage(context.Background(), PageRequest{MaxKeys: 1})
+	if err != nil {
+		t.Fatalf("ScanPluginsPage: %v", err)
+	}
+	if len(page1.Entries) != 1 || !page1.Truncated || page1.NextContinuationToken == "" {
+		t.Fatalf("unexpected first page: %+v", page1)
+	}
+
+	page2, err := sess.ScanPluginsPage(context.Background(), PageRequest{MaxKeys: 10, ContinuationToken: page1.NextContinuationToken})
+	if err != nil {
+		t.Fatalf("ScanPluginsPage (continued): %v", err)
+	}
+	if len(page2.Entries) != 2 || page2.Truncated {
+		t.Fatalf("unexpected second page: %+v", page2)
+	}
+
+	filtered, err := sess.ScanPluginsPage(context.Background(), PageRequest{Filter: PluginFilter{ManufacturerID: "OTHR"}})
+	if err != nil {
+		t.Fatalf("ScanPluginsPage (filtered): %v", err)
+	}
+	if len(filtered.Entries) != 1 || filtered.Entries[0].Key != "aufx:c:OTHR:Gamma" {
+		t.Fatalf("unexpected filtered page: %+v", filtered)
+	}
+}
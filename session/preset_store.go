@@ -0,0 +1,364 @@
+//go:build darwin
+
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// presetsVersion stamps the on-disk shape of a single plugin key's preset
+// file, the same way indexVersion/detailsVersion guard the plugin cache.
+const presetsVersion = "1.0-presets"
+
+// lockStaleAfter bounds how long a presets/<key>.json.lock file is honored
+// before it's treated as abandoned (e.g. a session that crashed while
+// holding it) and reclaimed rather than blocking forever.
+const lockStaleAfter = 30 * time.Second
+
+// NodeID identifies a live, parameter-addressable plugin instance for
+// ApplyPreset to target. It's opaque to session - session never constructs
+// or interprets one, so this package has no dependency on engine. Callers
+// that do have a live engine graph (e.g. engine.EnginePlugin) supply
+// whatever NodeID their ParamApplier implementation expects.
+type NodeID string
+
+// ParamApplier is how ApplyPreset reaches a live plugin instance, mirroring
+// MetricsHook's decoupling of session from its caller's runtime. A typical
+// implementation resolves nodeID to an engine.EnginePlugin and calls
+// SetParameterAtTime for each entry in the preset.
+type ParamApplier interface {
+	ApplyParam(nodeID NodeID, paramID string, value float32) error
+}
+
+// SetParamApplier registers the ParamApplier ApplyPreset/ApplyPresetForce
+// use to write parameter values to a live node. Presets can still be
+// listed/saved/deleted without one; only applying requires it.
+func (s *Session) SetParamApplier(p ParamApplier) { s.paramApplier = p }
+
+// Preset is a user-defined parameter snapshot for one AudioUnit, named and
+// stored independently of the engine package's Preset/Bank plist format -
+// this one is keyed by the plugin cache's quad-tuple key and carries the
+// checksum captured at save time so GetPreset/ListPresets can flag it Stale
+// once the installed plugin no longer matches.
+type Preset struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Params      map[string]float32 `json:"params"`
+	Tags        []string           `json:"tags,omitempty"`
+	// Checksum is the indexEntry.Checksum for pluginKey at the time this
+	// preset was saved - see checksumQuick.
+	Checksum string    `json:"checksum"`
+	SavedAt  time.Time `json:"savedAt"`
+	// Stale is computed on load, never persisted: true once Checksum no
+	// longer matches the plugin's current indexEntry.Checksum.
+	Stale bool `json:"-"`
+}
+
+// presetFile is the on-disk structure for presets/<detailFileName(key)>.json -
+// every named preset for one plugin key, in a single file.
+type presetFile struct {
+	Version string   `json:"version"`
+	Presets []Preset `json:"presets"`
+}
+
+// StalePresetError is returned by ApplyPreset when the stored preset's
+// checksum no longer matches the plugin's current cache entry. Callers that
+// want to apply it anyway (accepting the parameter drift) should catch this
+// with errors.As and retry via ApplyPresetForce.
+type StalePresetError struct {
+	PluginKey string
+	Name      string
+}
+
+func (e *StalePresetError) Error() string {
+	return fmt.Sprintf("preset %q for %q is stale: plugin has changed since it was saved", e.Name, e.PluginKey)
+}
+
+// ErrPresetNotFound is returned by GetPreset/DeletePreset when no preset
+// with the given name exists for pluginKey.
+var ErrPresetNotFound = errors.New("session: preset not found")
+
+func presetsDir() (string, error) {
+	dir, err := getPluginCacheDir()
+	if err != nil {
+		return "", err
+	}
+	presetsDir := filepath.Join(dir, "presets")
+	if err := os.MkdirAll(presetsDir, 0o755); err != nil {
+		return "", err
+	}
+	return presetsDir, nil
+}
+
+func orphanedPresetsDir() (string, error) {
+	base, err := presetsDir()
+	if err != nil {
+		return "", err
+	}
+	orphaned := filepath.Join(base, ".orphaned")
+	if err := os.MkdirAll(orphaned, 0o755); err != nil {
+		return "", err
+	}
+	return orphaned, nil
+}
+
+func presetFilePath(pluginKey string) (string, error) {
+	dir, err := presetsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, detailFileName(pluginKey)), nil
+}
+
+// acquireFileLock takes an advisory lock on path+".lock" via exclusive
+// file creation, retrying with backoff until timeout - the portable,
+// stdlib-only equivalent of flock(2) this package otherwise avoids, so
+// presets/<key>.json stays safe across concurrent sessions/processes. A
+// lock file older than lockStaleAfter is assumed abandoned and reclaimed.
+func acquireFileLock(path string, timeout time.Duration) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for preset lock %s", lockPath)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func loadPresetFile(pluginKey string) (presetFile, error) {
+	path, err := presetFilePath(pluginKey)
+	if err != nil {
+		return presetFile{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return presetFile{Version: presetsVersion}, nil
+		}
+		return presetFile{}, err
+	}
+	var pf presetFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return presetFile{}, err
+	}
+	return pf, nil
+}
+
+// savePresetFile atomically writes pf for pluginKey, fsyncing before the
+// rename so a crash can't leave a torn or missing file - stricter than
+// saveIndex/writeDetails, which don't fsync, because a lost preset can't be
+// regenerated the way a cache entry can.
+func savePresetFile(pluginKey string, pf presetFile) error {
+	path, err := presetFilePath(pluginKey)
+	if err != nil {
+		return err
+	}
+	unlock, err := acquireFileLock(path, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pf.Version = presetsVersion
+	b, err := json.Marshal(pf)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// currentChecksum looks up pluginKey's checksum in the live plugin index, so
+// ListPresets/GetPreset can flag a preset Stale once the installed plugin no
+// longer matches it. Returns "" (never matching a saved checksum) if the key
+// isn't in the index at all.
+func (s *Session) currentChecksum(pluginKey string) string {
+	s.idxMu.RLock()
+	idx := s.idxSnap
+	s.idxMu.RUnlock()
+	if idx == nil {
+		var err error
+		idx, err = loadIndex()
+		if err != nil {
+			return ""
+		}
+	}
+	return idx.Entries[pluginKey].Checksum
+}
+
+// ListPresets returns every saved preset for pluginKey, each flagged Stale
+// against the plugin's current cache checksum.
+func (s *Session) ListPresets(pluginKey string) ([]Preset, error) {
+	pf, err := loadPresetFile(pluginKey)
+	if err != nil {
+		return nil, err
+	}
+	current := s.currentChecksum(pluginKey)
+	presets := make([]Preset, len(pf.Presets))
+	for i, p := range pf.Presets {
+		p.Stale = current == "" || p.Checksum != current
+		presets[i] = p
+	}
+	return presets, nil
+}
+
+// GetPreset returns one named preset for pluginKey, flagged Stale the same
+// way ListPresets does.
+func (s *Session) GetPreset(pluginKey, name string) (Preset, error) {
+	presets, err := s.ListPresets(pluginKey)
+	if err != nil {
+		return Preset{}, err
+	}
+	for _, p := range presets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("%w: %q for %q", ErrPresetNotFound, name, pluginKey)
+}
+
+// SavePreset writes p under pluginKey, stamping Checksum from the plugin's
+// current cache entry and SavedAt to now (overwriting any caller-supplied
+// values, since those must reflect the moment of the save, not the
+// caller's). An existing preset with the same Name is replaced.
+func (s *Session) SavePreset(pluginKey string, p Preset) error {
+	if p.Name == "" {
+		return errors.New("session: preset name must not be empty")
+	}
+
+	p.Checksum = s.currentChecksum(pluginKey)
+	p.SavedAt = time.Now()
+
+	pf, err := loadPresetFile(pluginKey)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range pf.Presets {
+		if pf.Presets[i].Name == p.Name {
+			pf.Presets[i] = p
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		pf.Presets = append(pf.Presets, p)
+	}
+	return savePresetFile(pluginKey, pf)
+}
+
+// DeletePreset removes one named preset from pluginKey's file.
+func (s *Session) DeletePreset(pluginKey, name string) error {
+	pf, err := loadPresetFile(pluginKey)
+	if err != nil {
+		return err
+	}
+	kept := pf.Presets[:0]
+	found := false
+	for _, p := range pf.Presets {
+		if p.Name == name {
+			found = true
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if !found {
+		return fmt.Errorf("%w: %q for %q", ErrPresetNotFound, name, pluginKey)
+	}
+	pf.Presets = kept
+	return savePresetFile(pluginKey, pf)
+}
+
+// ApplyPreset looks up the named preset and writes its parameters to nodeID
+// via the registered ParamApplier. A stale preset (see Preset.Stale) is
+// rejected with *StalePresetError instead of being applied silently; callers
+// that want to proceed anyway should catch that with errors.As and retry via
+// ApplyPresetForce.
+func (s *Session) ApplyPreset(nodeID NodeID, pluginKey, name string) error {
+	p, err := s.GetPreset(pluginKey, name)
+	if err != nil {
+		return err
+	}
+	if p.Stale {
+		return &StalePresetError{PluginKey: pluginKey, Name: name}
+	}
+	return s.applyPresetParams(nodeID, p)
+}
+
+// ApplyPresetForce applies the named preset exactly like ApplyPreset, but
+// skips the staleness check - for callers that have already decided a
+// *StalePresetError from ApplyPreset is acceptable.
+func (s *Session) ApplyPresetForce(nodeID NodeID, pluginKey, name string) error {
+	p, err := s.GetPreset(pluginKey, name)
+	if err != nil {
+		return err
+	}
+	return s.applyPresetParams(nodeID, p)
+}
+
+func (s *Session) applyPresetParams(nodeID NodeID, p Preset) error {
+	if s.paramApplier == nil {
+		return errors.New("session: no ParamApplier registered (call SetParamApplier)")
+	}
+	for paramID, value := range p.Params {
+		if err := s.paramApplier.ApplyParam(nodeID, paramID, value); err != nil {
+			return fmt.Errorf("param %q: %w", paramID, err)
+		}
+	}
+	return nil
+}
+
+// orphanPresets moves pluginKey's preset file (if any) into presets/.orphaned
+// instead of deleting it, so reinstalling the same plugin later can recover
+// it. Called from RefreshQuick when a key disappears from the quick index.
+func orphanPresets(pluginKey string) error {
+	src, err := presetFilePath(pluginKey)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(src); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	orphanDir, err := orphanedPresetsDir()
+	if err != nil {
+		return err
+	}
+	dst := filepath.Join(orphanDir, filepath.Base(src))
+	return os.Rename(src, dst)
+}
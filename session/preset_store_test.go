@@ -0,0 +1,187 @@
+//go:build darwin
+
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPresetCRUDAndStaleness(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-presets-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	pluginKey := "aufx:FAKE:ACME:Nonexistent Plugin"
+	sess.idxMu.Lock()
+	if sess.idxSnap == nil {
+		sess.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}}
+	}
+	sess.idxSnap.Entries[pluginKey] = indexEntry{Key: pluginKey, Checksum: "checksum-v1"}
+	sess.idxMu.Unlock()
+
+	preset := Preset{Name: "Warm Lead", Params: map[string]float32{"cutoff": 0.5}}
+	if err := sess.SavePreset(pluginKey, preset); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+
+	got, err := sess.GetPreset(pluginKey, "Warm Lead")
+	if err != nil {
+		t.Fatalf("GetPreset: %v", err)
+	}
+	if got.Stale {
+		t.Error("expected fresh preset right after save")
+	}
+	if got.Checksum != "checksum-v1" {
+		t.Errorf("Checksum = %q, want %q", got.Checksum, "checksum-v1")
+	}
+
+	// Simulate the plugin changing (checksum drift) without re-saving the preset.
+	sess.idxMu.Lock()
+	entry := sess.idxSnap.Entries[pluginKey]
+	entry.Checksum = "checksum-v2"
+	sess.idxSnap.Entries[pluginKey] = entry
+	sess.idxMu.Unlock()
+
+	stale, err := sess.GetPreset(pluginKey, "Warm Lead")
+	if err != nil {
+		t.Fatalf("GetPreset after drift: %v", err)
+	}
+	if !stale.Stale {
+		t.Error("expected preset to be flagged Stale after checksum drift")
+	}
+
+	if err := sess.ApplyPreset(NodeID("node-1"), pluginKey, "Warm Lead"); err == nil {
+		t.Fatal("expected ApplyPreset to reject a stale preset")
+	} else {
+		var staleErr *StalePresetError
+		if !errors.As(err, &staleErr) {
+			t.Errorf("expected *StalePresetError, got %T: %v", err, err)
+		}
+	}
+
+	if err := sess.DeletePreset(pluginKey, "Warm Lead"); err != nil {
+		t.Fatalf("DeletePreset: %v", err)
+	}
+	if _, err := sess.GetPreset(pluginKey, "Warm Lead"); !errors.Is(err, ErrPresetNotFound) {
+		t.Errorf("expected ErrPresetNotFound after delete, got %v", err)
+	}
+}
+
+func TestApplyPresetUsesRegisteredParamApplier(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-presets-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	pluginKey := "aufx:FAKE:ACME:Nonexistent Plugin"
+	sess.idxMu.Lock()
+	sess.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{
+		pluginKey: {Key: pluginKey, Checksum: "checksum-v1"},
+	}}
+	sess.idxMu.Unlock()
+
+	if err := sess.SavePreset(pluginKey, Preset{Name: "Default", Params: map[string]float32{"mix": 1}}); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+
+	applied := map[string]float32{}
+	sess.SetParamApplier(paramApplierFunc(func(nodeID NodeID, paramID string, value float32) error {
+		if nodeID != "node-1" {
+			t.Errorf("nodeID = %q, want node-1", nodeID)
+		}
+		applied[paramID] = value
+		return nil
+	}))
+
+	if err := sess.ApplyPreset(NodeID("node-1"), pluginKey, "Default"); err != nil {
+		t.Fatalf("ApplyPreset: %v", err)
+	}
+	if applied["mix"] != 1 {
+		t.Errorf("applied[mix] = %v, want 1", applied["mix"])
+	}
+}
+
+func TestRefreshQuickOrphansRemovedPresets(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-presets-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	if _, err := sess.RefreshQuick(); err != nil {
+		t.Fatalf("refresh quick failed: %v", err)
+	}
+
+	fakeKey := "aufx:GONE:ACME:Departed Plugin"
+	sess.idxMu.Lock()
+	if sess.idxSnap == nil {
+		sess.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}}
+	}
+	sess.idxSnap.Entries[fakeKey] = indexEntry{Key: fakeKey, Checksum: "deadbeef"}
+	_ = saveIndex(sess.idxSnap)
+	sess.idxMu.Unlock()
+
+	if err := sess.SavePreset(fakeKey, Preset{Name: "Preset", Params: map[string]float32{}}); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+	path, err := presetFilePath(fakeKey)
+	if err != nil {
+		t.Fatalf("presetFilePath: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected preset file to exist before refresh: %v", err)
+	}
+
+	if _, err := sess.RefreshQuick(); err != nil {
+		t.Fatalf("refresh quick failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected preset file to be moved out of presets/, got err=%v", err)
+	}
+	orphanDir, err := orphanedPresetsDir()
+	if err != nil {
+		t.Fatalf("orphanedPresetsDir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(orphanDir, filepath.Base(path))); err != nil {
+		t.Fatalf("expected orphaned preset file to exist: %v", err)
+	}
+}
+
+type paramApplierFunc func(nodeID NodeID, paramID string, value float32) error
+
+func (f paramApplierFunc) ApplyParam(nodeID NodeID, paramID string, value float32) error {
+	return f(nodeID, paramID, value)
+}
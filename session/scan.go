@@ -0,0 +1,375 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// scanPollInterval is how often the diff loop behind Scan re-enumerates
+// devices. Independent of Session.pollInterval (the legacy count-based
+// path's interval) so the two subsystems can be tuned separately.
+const scanPollInterval = 250 * time.Millisecond
+
+// DeviceKind filters Scan updates by device family.
+type DeviceKind int
+
+const (
+	KindBoth DeviceKind = iota
+	KindAudio
+	KindMIDI
+)
+
+// Direction filters Scan updates by I/O capability; DirectionAny matches
+// both input and output (or input/output-agnostic) devices.
+type Direction int
+
+const (
+	DirectionAny Direction = iota
+	DirectionInput
+	DirectionOutput
+)
+
+// DeviceFilter narrows a Scan subscription. An empty DeviceFilter (the zero
+// value) matches every device. NameGlob is matched with filepath.Match
+// against both the device's Name and, for MIDI devices, its Manufacturer -
+// case-insensitively.
+type DeviceFilter struct {
+	Kind      DeviceKind
+	Direction Direction
+	NameGlob  string
+}
+
+func (f DeviceFilter) matchesDirection(canInput, canOutput bool) bool {
+	switch f.Direction {
+	case DirectionInput:
+		return canInput
+	case DirectionOutput:
+		return canOutput
+	default:
+		return true
+	}
+}
+
+func (f DeviceFilter) matchesName(candidates ...string) bool {
+	if f.NameGlob == "" {
+		return true
+	}
+	pattern := strings.ToLower(f.NameGlob)
+	for _, c := range candidates {
+		if ok, _ := filepath.Match(pattern, strings.ToLower(c)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateKind discriminates a DeviceUpdate's meaning, mirroring the
+// Found/Lost/Changed vocabulary of discovery services like mDNS/Bonjour
+// browsers.
+type UpdateKind int
+
+const (
+	Found UpdateKind = iota
+	Lost
+	Changed
+)
+
+func (k UpdateKind) String() string {
+	switch k {
+	case Found:
+		return "found"
+	case Lost:
+		return "lost"
+	case Changed:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// DeviceUpdate is one Found/Lost/Changed event from Scan. DeviceUUID is
+// stable across disconnect/reconnect of the same physical device (keyed by
+// transport UID, falling back to name when a device reports no UID) -
+// exactly one of Audio/MIDI is set, matching the device's Kind.
+type DeviceUpdate struct {
+	Kind       UpdateKind
+	DeviceUUID string
+	Timestamp  time.Time
+	Audio      *devices.AudioDevice
+	MIDI       *devices.MIDIDevice
+}
+
+// CancelFunc stops a Scan subscription and closes its channel.
+type CancelFunc func()
+
+// deviceSnapshot is what the diff loop keys its keyed map by - enough to
+// detect property changes (sample rate, channel count, default status)
+// without re-deriving them from the full descriptor on every tick.
+type deviceSnapshot struct {
+	uuid string
+	kind DeviceKind
+	// fingerprint is a cheap summary of the mutable fields Changed should
+	// fire on; recomputed each tick and compared by equality.
+	fingerprint string
+	audio       *devices.AudioDevice
+	midi        *devices.MIDIDevice
+}
+
+// scanner is one Scan subscription's channel plus the filter it applies.
+type scanner struct {
+	ch     chan DeviceUpdate
+	filter DeviceFilter
+}
+
+// scanState holds everything Scan's diff loop needs, separate from the rest
+// of Session so the legacy count-based monitorDevices path (see
+// checkForChangesAsync) is untouched by this subsystem.
+type scanState struct {
+	mu        sync.Mutex
+	scanners  map[int64]*scanner
+	nextID    int64
+	snapshots map[string]deviceSnapshot // keyed by the same key deviceKey() computes
+	uuids     map[string]string         // device key -> stable UUID, survives Lost
+	started   bool
+}
+
+func deviceKey(kind DeviceKind, uid, name string) string {
+	if uid != "" {
+		return uid
+	}
+	return name // fallback for devices that report no transport UID
+}
+
+func newDeviceUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Scan subscribes to typed device discovery events, modeled on the
+// Found/Lost/Changed vocabulary of discovery/browse APIs rather than
+// OnDeviceChange's single coarse callback. Each call gets its own channel;
+// multiple concurrent scanners do not interfere with each other. The
+// returned channel is closed, and no further updates are sent, once either
+// ctx is done or the returned CancelFunc is called.
+//
+// This coexists with the legacy OnDeviceChange/DeviceChanges/
+// SimulateDeviceChange path rather than replacing its implementation -
+// both read from the same device enumeration but run independent poll
+// loops, so neither affects the other's timing or test behavior.
+func (s *Session) Scan(ctx context.Context, filter DeviceFilter) (<-chan DeviceUpdate, CancelFunc, error) {
+	s.ensureScanLoop()
+
+	sc := &scanner{ch: make(chan DeviceUpdate, 16), filter: filter}
+
+	s.scan.mu.Lock()
+	id := s.scan.nextID
+	s.scan.nextID++
+	s.scan.scanners[id] = sc
+	s.scan.mu.Unlock()
+
+	var closeOnce sync.Once
+	cancel := func() {
+		closeOnce.Do(func() {
+			s.scan.mu.Lock()
+			delete(s.scan.scanners, id)
+			s.scan.mu.Unlock()
+			close(sc.ch)
+		})
+	}
+
+	if ctx != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				cancel()
+			case <-s.ctx.Done():
+				cancel()
+			}
+		}()
+	}
+
+	return sc.ch, CancelFunc(cancel), nil
+}
+
+// ensureScanLoop lazily starts the diff loop the first time Scan is called,
+// so sessions that never use Scan pay no extra polling cost.
+func (s *Session) ensureScanLoop() {
+	s.scan.mu.Lock()
+	if s.scan.started {
+		s.scan.mu.Unlock()
+		return
+	}
+	if s.scan.scanners == nil {
+		s.scan.scanners = make(map[int64]*scanner)
+	}
+	if s.scan.snapshots == nil {
+		s.scan.snapshots = make(map[string]deviceSnapshot)
+	}
+	if s.scan.uuids == nil {
+		s.scan.uuids = make(map[string]string)
+	}
+	s.scan.started = true
+	s.scan.mu.Unlock()
+
+	go s.runScanLoop()
+}
+
+func (s *Session) runScanLoop() {
+	ticker := time.NewTicker(scanPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.diffDevicesOnce()
+		}
+	}
+}
+
+// diffDevicesOnce enumerates audio and MIDI devices, diffs them against the
+// last keyed snapshot, and fans out Found/Lost/Changed updates to every
+// matching scanner.
+func (s *Session) diffDevicesOnce() {
+	audioDevices, audioErr := devices.GetAudio()
+	midiDevices, midiErr := devices.GetMIDI()
+	if audioErr != nil && midiErr != nil {
+		return // both enumerations failed; skip this tick rather than report a false Lost for everything
+	}
+
+	now := time.Now()
+	current := make(map[string]deviceSnapshot)
+
+	if audioErr == nil {
+		for i := range audioDevices {
+			d := audioDevices[i]
+			key := deviceKey(KindAudio, d.UID, d.Name)
+			current[key] = deviceSnapshot{
+				kind:        KindAudio,
+				fingerprint: audioFingerprint(d),
+				audio:       &d,
+			}
+		}
+	}
+	if midiErr == nil {
+		for i := range midiDevices {
+			d := midiDevices[i]
+			key := deviceKey(KindMIDI, d.UID, d.Name)
+			current[key] = deviceSnapshot{
+				kind:        KindMIDI,
+				fingerprint: midiFingerprint(d),
+				midi:        &d,
+			}
+		}
+	}
+
+	s.scan.mu.Lock()
+	defer s.scan.mu.Unlock()
+
+	var updates []DeviceUpdate
+
+	for key, snap := range current {
+		uuid, seen := s.scan.uuids[key]
+		if !seen {
+			uuid = newDeviceUUID()
+			s.scan.uuids[key] = uuid
+		}
+		snap.uuid = uuid
+
+		prev, existed := s.scan.snapshots[key]
+		switch {
+		case !existed:
+			updates = append(updates, DeviceUpdate{Kind: Found, DeviceUUID: uuid, Timestamp: now, Audio: snap.audio, MIDI: snap.midi})
+		case prev.fingerprint != snap.fingerprint:
+			updates = append(updates, DeviceUpdate{Kind: Changed, DeviceUUID: uuid, Timestamp: now, Audio: snap.audio, MIDI: snap.midi})
+		}
+		s.scan.snapshots[key] = snap
+	}
+
+	for key, prev := range s.scan.snapshots {
+		if _, stillPresent := current[key]; stillPresent {
+			continue
+		}
+		updates = append(updates, DeviceUpdate{Kind: Lost, DeviceUUID: prev.uuid, Timestamp: now, Audio: prev.audio, MIDI: prev.midi})
+		delete(s.scan.snapshots, key)
+		// uuids intentionally retained so a reconnect reuses the same UUID
+	}
+
+	for _, u := range updates {
+		s.emitDeviceAudit(u)
+		for _, sc := range s.scan.scanners {
+			if !scannerWants(sc.filter, u) {
+				continue
+			}
+			select {
+			case sc.ch <- u:
+			default:
+				// Scanner's buffer is full; drop rather than block the diff loop.
+			}
+		}
+	}
+}
+
+// emitDeviceAudit mirrors a Found/Lost/Changed DeviceUpdate onto the audit
+// stream (see audit.go); a no-op when EnableAudit hasn't been called.
+func (s *Session) emitDeviceAudit(u DeviceUpdate) {
+	payload := DeviceEventPayload{DeviceUUID: u.DeviceUUID}
+	switch {
+	case u.Audio != nil:
+		payload.Kind, payload.Name = "audio", u.Audio.Name
+	case u.MIDI != nil:
+		payload.Kind, payload.Name = "midi", u.MIDI.Name
+	}
+	switch u.Kind {
+	case Found:
+		s.emitAudit(EventDeviceFound, payload)
+	case Lost:
+		s.emitAudit(EventDeviceLost, payload)
+	case Changed:
+		s.emitAudit(EventDeviceChanged, payload)
+	}
+}
+
+func scannerWants(f DeviceFilter, u DeviceUpdate) bool {
+	switch f.Kind {
+	case KindAudio:
+		if u.Audio == nil {
+			return false
+		}
+	case KindMIDI:
+		if u.MIDI == nil {
+			return false
+		}
+	}
+
+	if u.Audio != nil {
+		return f.matchesDirection(u.Audio.CanInput(), u.Audio.CanOutput()) && f.matchesName(u.Audio.Name)
+	}
+	if u.MIDI != nil {
+		return f.matchesDirection(u.MIDI.CanInput(), u.MIDI.CanOutput()) && f.matchesName(u.MIDI.Name, u.MIDI.Manufacturer)
+	}
+	return false
+}
+
+// audioFingerprint summarizes the AudioDevice fields Changed should react
+// to: sample rate, channel count, and default-device status.
+func audioFingerprint(d devices.AudioDevice) string {
+	return fmt.Sprintf("%d/%d/%v/%v/%v", d.InputChannelCount, d.OutputChannelCount, d.IsDefaultInput, d.IsDefaultOutput, d.SupportedSampleRates)
+}
+
+// midiFingerprint summarizes the MIDIDevice fields Changed should react to.
+func midiFingerprint(d devices.MIDIDevice) string {
+	return fmt.Sprintf("%d/%d/%v/%v/%v", d.InputEndpointID, d.OutputEndpointID, d.IsInput, d.IsOutput, d.IsOnline)
+}
@@ -0,0 +1,88 @@
+//go:build darwin
+
+package session
+
+import (
+	"testing"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+func TestDeviceKeyFallsBackToName(t *testing.T) {
+	if got := deviceKey(KindAudio, "uid-123", "Built-in Output"); got != "uid-123" {
+		t.Errorf("deviceKey with UID = %q, want %q", got, "uid-123")
+	}
+	if got := deviceKey(KindAudio, "", "Built-in Output"); got != "Built-in Output" {
+		t.Errorf("deviceKey without UID = %q, want name fallback %q", got, "Built-in Output")
+	}
+}
+
+func TestDeviceFilterMatchesDirection(t *testing.T) {
+	tests := []struct {
+		name                string
+		filter              DeviceFilter
+		canInput, canOutput bool
+		want                bool
+	}{
+		{"AnyMatchesInputOnly", DeviceFilter{Direction: DirectionAny}, true, false, true},
+		{"InputWantsInput", DeviceFilter{Direction: DirectionInput}, true, false, true},
+		{"InputRejectsOutputOnly", DeviceFilter{Direction: DirectionInput}, false, true, false},
+		{"OutputWantsOutput", DeviceFilter{Direction: DirectionOutput}, false, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matchesDirection(tt.canInput, tt.canOutput); got != tt.want {
+				t.Errorf("matchesDirection(%v, %v) = %v, want %v", tt.canInput, tt.canOutput, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceFilterMatchesName(t *testing.T) {
+	f := DeviceFilter{NameGlob: "Scarlett*"}
+	if !f.matchesName("Scarlett 2i2") {
+		t.Error("expected glob to match prefix, case-insensitively")
+	}
+	if !f.matchesName("scarlett solo") {
+		t.Error("expected glob match to be case-insensitive")
+	}
+	if f.matchesName("Built-in Output") {
+		t.Error("expected no match for unrelated name")
+	}
+
+	empty := DeviceFilter{}
+	if !empty.matchesName("anything") {
+		t.Error("expected empty NameGlob to match everything")
+	}
+}
+
+func TestScannerWantsFiltersByKind(t *testing.T) {
+	audioUpdate := DeviceUpdate{Kind: Found, Audio: &devices.AudioDevice{
+		Device:             devices.Device{Name: "Built-in Output"},
+		OutputChannelCount: 2,
+	}}
+	midiUpdate := DeviceUpdate{Kind: Found, MIDI: &devices.MIDIDevice{
+		Device:   devices.Device{Name: "IAC Driver"},
+		IsOutput: true,
+	}}
+
+	if !scannerWants(DeviceFilter{Kind: KindBoth}, audioUpdate) {
+		t.Error("KindBoth should match an audio update")
+	}
+	if !scannerWants(DeviceFilter{Kind: KindAudio}, audioUpdate) {
+		t.Error("KindAudio should match an audio update")
+	}
+	if scannerWants(DeviceFilter{Kind: KindMIDI}, audioUpdate) {
+		t.Error("KindMIDI should not match an audio update")
+	}
+	if !scannerWants(DeviceFilter{Kind: KindMIDI}, midiUpdate) {
+		t.Error("KindMIDI should match a MIDI update")
+	}
+}
+
+func TestUpdateKindString(t *testing.T) {
+	if Found.String() != "found" || Lost.String() != "lost" || Changed.String() != "changed" {
+		t.Errorf("unexpected UpdateKind.String() values: %q %q %q", Found, Lost, Changed)
+	}
+}
@@ -6,10 +6,10 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -50,6 +50,15 @@ type Session struct {
 	callbacks     []ChangeCallback
 	callbackMutex sync.RWMutex
 
+	// hardwareEvents is signaled by the CoreAudio property listener
+	// registered in monitorDevices (see devices.WatchHardwareChanges) so
+	// checkForChangesAsync can run immediately instead of waiting for the
+	// next pollInterval tick. propertyListenerActive reports (via Status)
+	// whether that listener is actually installed, since install can fail
+	// or be disabled via Options.
+	hardwareEvents         chan struct{}
+	propertyListenerActive int64 // atomic bool
+
 	// Plugin management
 	cachedPlugins   []*plugins.Plugin  // Full plugin data
 	cachedQuickInfo map[string]string  // Quick lookup for change detection
@@ -60,6 +69,13 @@ type Session struct {
 	// Configuration
 	audioSpec    AudioSpec
 	pollInterval time.Duration
+	// disablePropertyListener forces monitorDevices onto pollInterval-based
+	// polling; see Options.DisablePropertyListenerMonitoring.
+	disablePropertyListener bool
+	// introspectConcurrency is how many workers doFullPluginScan's
+	// plugins.IntrospectParallel call fans out across; see
+	// Options.IntrospectConcurrency.
+	introspectConcurrency int
 
 	// Control
 	ctx        context.Context
@@ -76,6 +92,38 @@ type Session struct {
 
 	// optional metrics hook
 	hook MetricsHook
+
+	// scan backs the typed Found/Lost/Changed subscription API; see
+	// scan.go. Lazily started by the first Scan call.
+	scan scanState
+
+	// paramApplier backs ApplyPreset/ApplyPresetForce; see preset_store.go.
+	paramApplier ParamApplier
+
+	// audit backs the NDJSON event stream; see audit.go. Inert until
+	// EnableAudit is called.
+	audit auditState
+
+	// hub backs Subscribe's fan-out topics; see subscribe.go. Zero-value
+	// usable - subs is allocated lazily on the first Subscribe call - so it
+	// needs no entry in newSession's literal.
+	hub eventHub
+
+	// backend is the IndexBackend QuickPlugins/Plugin/RefreshQuick persist
+	// through; see index_backend.go. Defaults to FileBackend (the embedded
+	// bbolt store), overridable via Options.Backend - e.g. RedisBackend, to
+	// share one warmed cache across processes.
+	backend IndexBackend
+
+	// defaultIntrospectTimeout bounds the non-Ctx forms of QuickPlugins,
+	// Plugin, RefreshQuick, and Warm; see Options.DefaultIntrospectTimeout
+	// and introspectContext. Zero means no deadline (context.Background()).
+	defaultIntrospectTimeout time.Duration
+
+	// autoWarm backs StartAutoWarm/StopAutoWarm; see autowarm.go. Zero-value
+	// usable - trigger/cancel are set by StartAutoWarm - so it needs no
+	// entry in newSession's literal.
+	autoWarm autoWarmState
 }
 
 // LatencyClass is a coarse latency preference that maps to buffer sizes.
@@ -87,6 +135,25 @@ const (
 	LatencyHigh   LatencyClass = "high"   // prioritize stability (larger buffers)
 )
 
+// Direction distinguishes whether an AudioSpec configures a capture (input)
+// or playback (output) path - see ResolveEngineSpec, OpenInputStream, and
+// OpenOutputStream. The zero value is DirectionOutput, matching every
+// existing caller's playback-oriented use of AudioSpec.
+type Direction int
+
+const (
+	DirectionOutput Direction = iota
+	DirectionInput
+)
+
+// String renders a Direction the way a log line wants it.
+func (d Direction) String() string {
+	if d == DirectionInput {
+		return "input"
+	}
+	return "output"
+}
+
 // AudioSpec captures session-level audio preferences.
 // Note:
 //  - PreferredSampleRate is a target; actual device/sample rate may differ.
@@ -106,11 +173,28 @@ type AudioSpec struct {
 
 	// Optional explicit buffer size hint (frames). Overrides LatencyHint if set > 0.
 	BufferSize   int `json:"buffer_size,omitempty"`
+
+	// Direction selects the capture/playback path this spec resolves for;
+	// see ResolveEngineSpec. Most session-level AudioSpec values are shared
+	// between both directions and can leave this at its zero value
+	// (DirectionOutput); OpenInputStream sets it to DirectionInput itself.
+	Direction Direction `json:"direction,omitempty"`
+
+	// ProcessingFrames optionally sets the app-side block size a
+	// BufferManager hands to its consumer via Pull, independent of
+	// BufferSize/LatencyHint - which only ever resolve the HAL-facing size
+	// ResolveEngineSpec negotiates with the device. An effect block written
+	// for 512 frames fed by a 128-frame HAL is the motivating case: the HAL
+	// side stays at whatever buffer size the device actually runs, while
+	// Pull(ProcessingFrames) keeps handing the app a constant size. <=0
+	// means "no decoupling" - see ResolveProcessingFrames.
+	ProcessingFrames int `json:"processing_frames,omitempty"`
 }
 
 // DeviceChange represents a device change event with async scan status
 type DeviceChange struct {
 	Type          ChangeType            `json:"type"`
+	Reason        ChangeReason          `json:"reason"`
 	Timestamp     time.Time             `json:"timestamp"`
 	AudioCount    int                   `json:"audio_count"`
 	MIDICount     int                   `json:"midi_count"`
@@ -120,6 +204,47 @@ type DeviceChange struct {
 	MIDIScanning  bool                  `json:"midi_scanning"`
 }
 
+// ChangeReason classifies why checkForChangesAsync fired, orthogonal to
+// ChangeType's audio-vs-MIDI axis - a consumer reacting to a default-device
+// swap needs different handling than one reacting to a plug/unplug even
+// when both come back as, say, AudioDeviceChange.
+type ChangeReason int
+
+const (
+	ReasonUnknown ChangeReason = iota
+	// ReasonDevicesAdded is a pure increase in device count - nothing
+	// present before went away, so nothing needs assuming removed.
+	ReasonDevicesAdded
+	// ReasonDevicesRemoved is a pure decrease in device count.
+	ReasonDevicesRemoved
+	// ReasonDefaultChanged is a same-count change - the default
+	// input/output, or a device's format, changed without any device
+	// appearing or disappearing. checkForChangesAsync can't yet tell this
+	// apart from ReasonFormatChanged without the per-device diff
+	// devices.Subscribe does; both report as ReasonDefaultChanged today.
+	ReasonDefaultChanged
+	// ReasonFormatChanged is reserved for a future per-device diff that
+	// distinguishes a format/sample-rate change from a default swap; unused
+	// until that diff exists.
+	ReasonFormatChanged
+)
+
+// String renders a ChangeReason the way a log line wants it.
+func (r ChangeReason) String() string {
+	switch r {
+	case ReasonDevicesAdded:
+		return "devices_added"
+	case ReasonDevicesRemoved:
+		return "devices_removed"
+	case ReasonDefaultChanged:
+		return "default_changed"
+	case ReasonFormatChanged:
+		return "format_changed"
+	default:
+		return "unknown"
+	}
+}
+
 type ChangeType int
 
 const (
@@ -154,10 +279,47 @@ type PluginCache struct {
 
 type PluginCallback func(PluginResult)
 
+// PluginProgress reports doFullPluginScan's running progress through a
+// PluginRequest.Progress callback, one call per plugin as it finishes.
+type PluginProgress struct {
+	Completed   int    `json:"completed"`
+	Total       int    `json:"total"`
+	CurrentName string `json:"current_name"`
+	// LastError is the error from the plugin that just finished, empty on
+	// success.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// PluginProgressCallback receives PluginProgress updates during a full
+// plugin scan; see PluginRequest.Progress. It may be called concurrently
+// from multiple introspection workers.
+type PluginProgressCallback func(PluginProgress)
+
+// PluginFailure records one plugin a full scan failed to introspect - see
+// PluginResult.Failures. The batch keeps running past these instead of
+// aborting; compare plugins.IntrospectFailure, which this wraps with
+// session-friendly (JSON-able, string-keyed) fields.
+type PluginFailure struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Subtype  string `json:"subtype"`
+	Error    string `json:"error"`
+	TimedOut bool   `json:"timed_out"`
+}
+
 type PluginRequest struct {
 	ID        string         `json:"id"`
 	Callback  PluginCallback `json:"-"`
 	Timestamp time.Time      `json:"timestamp"`
+	// Progress, if set, streams PluginProgress updates during a full scan's
+	// parallel introspection pass; see doFullPluginScan.
+	Progress PluginProgressCallback `json:"-"`
+	// Ctx, if set, lets a caller cancel a long scan in progress: no new
+	// plugins are dispatched to introspection workers once Ctx is done,
+	// though plugins already in flight run to completion (see
+	// plugins.IntrospectOptions.Ctx). Defaults to context.Background() when
+	// nil.
+	Ctx context.Context `json:"-"`
 }
 
 type PluginResult struct {
@@ -165,10 +327,14 @@ type PluginResult struct {
 	Success      bool              `json:"success"`
 	Error        string            `json:"error,omitempty"`
 	Plugins      []*plugins.Plugin `json:"plugins,omitempty"`
-	CacheHit     bool              `json:"cache_hit"`
-	ScanTime     time.Duration     `json:"scan_time"`
-	ChangedCount int               `json:"changed_count"`
-	Timestamp    time.Time         `json:"timestamp"`
+	// Failures lists plugins the full scan behind this result could not
+	// introspect; individual failures don't make Success false - see
+	// doFullPluginScan.
+	Failures     []PluginFailure `json:"failures,omitempty"`
+	CacheHit     bool            `json:"cache_hit"`
+	ScanTime     time.Duration   `json:"scan_time"`
+	ChangedCount int             `json:"changed_count"`
+	Timestamp    time.Time       `json:"timestamp"`
 }
 
 // Default audio configuration
@@ -181,18 +347,39 @@ var DefaultAudioSpec = AudioSpec{
 	BufferSize:   512,
 }
 
+// defaultIntrospectConcurrency is Options.IntrospectConcurrency's default:
+// runtime.NumCPU()/2, leaving headroom for the render/UI threads a scan
+// runs alongside, floored at 1 on single-core machines.
+func defaultIntrospectConcurrency() int {
+	if n := runtime.NumCPU() / 2; n > 0 {
+		return n
+	}
+	return 1
+}
+
 // NewSession creates a new audio session with fast async monitoring
 func NewSession(spec AudioSpec) (*Session, error) {
+	return newSession(spec, false)
+}
+
+// newSession is NewSession's body, factored out so NewSessionWithOptions can
+// pass disablePropertyListener before monitorDevices's goroutine starts
+// instead of racing to toggle it afterward.
+func newSession(spec AudioSpec, disablePropertyListener bool) (*Session, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	session := &Session{
-		deviceChanges:  make(chan DeviceChange, 10),
-		pluginRequests: make(chan PluginRequest, 10), // Plugin request queue
-		audioSpec:      spec,
-		pollInterval:   50 * time.Millisecond, // Fast count-based polling
-		ctx:            ctx,
-		cancel:         cancel,
-		inflight:       make(map[string]*inflightCall),
+		deviceChanges:           make(chan DeviceChange, 10),
+		pluginRequests:          make(chan PluginRequest, 10), // Plugin request queue
+		hardwareEvents:          make(chan struct{}, 1),
+		audioSpec:               spec,
+		pollInterval:            50 * time.Millisecond, // Fast count-based polling, used as a fallback
+		disablePropertyListener: disablePropertyListener,
+		introspectConcurrency:   defaultIntrospectConcurrency(),
+		ctx:                     ctx,
+		cancel:                  cancel,
+		inflight:                make(map[string]*inflightCall),
+		backend:                 FileBackend{},
 	}
 
 	// Initial device enumeration and count setup
@@ -222,8 +409,15 @@ func NewSession(spec AudioSpec) (*Session, error) {
 	return session, nil
 }
 
-// SetMetricsHook sets an optional metrics hook. Passing nil disables metrics callbacks.
-func (s *Session) SetMetricsHook(h MetricsHook) { s.hook = h }
+// SetMetricsHook sets an optional metrics hook. Passing nil disables metrics
+// callbacks. Also mirrors h as the package-level migration hook (see
+// cache_migrate.go) so loadIndex/readDetails, which run as free functions
+// with no *Session in scope, can still emit OnCacheMigration/
+// OnCacheQuarantine through it.
+func (s *Session) SetMetricsHook(h MetricsHook) {
+	s.hook = h
+	setMigrationHook(h)
+}
 
 // Options configure advanced behaviors at session construction time.
 // Use this to tune plugin introspection timeouts or warm specific plugins on startup.
@@ -237,12 +431,64 @@ type Options struct {
 	// Warm predicate and concurrency; applied after quick refresh if set
 	WarmSelector   func(plugins.PluginInfo) bool
 	WarmConcurrency int
+	// DisablePropertyListenerMonitoring forces monitorDevices onto its
+	// count-based polling loop (see pollInterval) instead of registering a
+	// CoreAudio property listener via devices.WatchHardwareChanges. Useful
+	// for tests or environments where installing a process-wide hardware
+	// listener isn't desirable.
+	DisablePropertyListenerMonitoring bool
+	// IntrospectConcurrency bounds how many workers doFullPluginScan fans
+	// its plugins.IntrospectParallel call across. <=0 keeps
+	// defaultIntrospectConcurrency() (runtime.NumCPU()/2).
+	IntrospectConcurrency int
+	// SubscriptionDropDeadline bounds how long Subscribe lets a
+	// subscriber's channel stay full before evicting it (see eventHub in
+	// subscribe.go). <=0 keeps defaultDropDeadline.
+	SubscriptionDropDeadline time.Duration
+	// CachePath overrides where the plugin cache (bbolt store, legacy
+	// index.json/details for migration, journal-era files, presets, and
+	// audit log) is read from and written to, equivalent to setting
+	// MACAUDIO_CACHE_DIR for the process (see getPluginCacheDir) - the
+	// cache directory has always been a process-wide setting rather than
+	// per-Session, and CachePath is just a constructor-time way to set it
+	// instead of requiring the caller to mutate the environment themselves.
+	// Empty keeps whatever MACAUDIO_CACHE_DIR/the Mac-native default already
+	// resolve to. Intended for tests pointing at a temp dir.
+	CachePath string
+	// Backend overrides the IndexBackend QuickPlugins/Plugin/RefreshQuick
+	// persist the plugin index and details through; see index_backend.go.
+	// Nil keeps FileBackend (the embedded bbolt store).
+	Backend IndexBackend
+	// DefaultIntrospectTimeout bounds QuickPlugins, Plugin, RefreshQuick, and
+	// Warm - each wraps its *Ctx twin in a context.WithTimeout using this
+	// value. <=0 means no deadline, matching the pre-context behavior of
+	// blocking until plugins.List()/Introspect() returns.
+	DefaultIntrospectTimeout time.Duration
 }
 
 // NewSessionWithOptions creates a session with advanced options.
 func NewSessionWithOptions(spec AudioSpec, opt Options) (*Session, error) {
-	s, err := NewSession(spec)
+	if opt.CachePath != "" {
+		if err := os.Setenv("MACAUDIO_CACHE_DIR", opt.CachePath); err != nil {
+			return nil, err
+		}
+	}
+	s, err := newSession(spec, opt.DisablePropertyListenerMonitoring)
 	if err != nil { return nil, err }
+	if opt.Backend != nil {
+		s.backend = opt.Backend
+		// newSession already loaded idxSnap from the default FileBackend;
+		// reload it from the real backend now so a RedisBackend's shared
+		// index is what QuickPlugins/Plugin/RefreshQuick actually see.
+		if idx, err := s.backend.LoadIndex(); err == nil {
+			s.idxMu.Lock()
+			s.idxSnap = idx
+			s.idxMu.Unlock()
+		}
+	}
+	if opt.IntrospectConcurrency > 0 { s.introspectConcurrency = opt.IntrospectConcurrency }
+	if opt.DefaultIntrospectTimeout > 0 { s.defaultIntrospectTimeout = opt.DefaultIntrospectTimeout }
+	if opt.SubscriptionDropDeadline > 0 { s.hub.dropDeadline = opt.SubscriptionDropDeadline }
 	// Apply timeouts if provided
 	if opt.PresetLoadingTimeout > 0 { plugins.SetPresetLoadingTimeout(opt.PresetLoadingTimeout) }
 	if opt.ProcessUpdateTimeout > 0 { plugins.SetProcessUpdateTimeout(opt.ProcessUpdateTimeout) }
@@ -299,12 +545,22 @@ func (s *Session) GetDeviceCounts() (audioCount, midiCount int) {
 
 // GetPluginsAsync - consumer requests plugins and gets callback when ready
 func (s *Session) GetPluginsAsync(callback PluginCallback) string {
+	return s.GetPluginsAsyncWithProgress(context.Background(), callback, nil)
+}
+
+// GetPluginsAsyncWithProgress is GetPluginsAsync plus a progress callback
+// streamed during a full scan's parallel introspection pass and a context
+// that lets the caller cancel a long scan in progress; see
+// PluginRequest.Progress and PluginRequest.Ctx.
+func (s *Session) GetPluginsAsyncWithProgress(ctx context.Context, callback PluginCallback, progress PluginProgressCallback) string {
 	requestID := fmt.Sprintf("plugin_%d", time.Now().UnixNano())
 
 	request := PluginRequest{
 		ID:        requestID,
 		Callback:  callback,
 		Timestamp: time.Now(),
+		Progress:  progress,
+		Ctx:       ctx,
 	}
 
 	// Non-blocking request queue
@@ -341,13 +597,14 @@ func (s *Session) Status() SessionStatus {
 
 	audioCount, midiCount := s.GetDeviceCounts()
 	return SessionStatus{
-		Monitoring:   s.IsMonitoring(),
-		AudioSpec:    s.audioSpec,
-		AudioCount:   audioCount,
-		MIDICount:    midiCount,
-		LastUpdate:   s.lastUpdate,
-		CacheAge:     time.Since(s.lastUpdate),
-		PollInterval: s.pollInterval,
+		Monitoring:             s.IsMonitoring(),
+		AudioSpec:              s.audioSpec,
+		AudioCount:             audioCount,
+		MIDICount:              midiCount,
+		LastUpdate:             s.lastUpdate,
+		CacheAge:               time.Since(s.lastUpdate),
+		PollInterval:           s.pollInterval,
+		PropertyListenerActive: atomic.LoadInt64(&s.propertyListenerActive) != 0,
 	}
 }
 
@@ -359,6 +616,11 @@ type SessionStatus struct {
 	LastUpdate   time.Time     `json:"last_update"`
 	CacheAge     time.Duration `json:"cache_age"`
 	PollInterval time.Duration `json:"poll_interval"`
+	// PropertyListenerActive reports whether monitorDevices is running on
+	// the CoreAudio property listener rather than pure pollInterval
+	// polling; false if Options.DisablePropertyListenerMonitoring was set
+	// or devices.WatchHardwareChanges failed to install.
+	PropertyListenerActive bool `json:"property_listener_active"`
 }
 
 // getPluginCacheDir returns the Mac-native cache directory
@@ -385,8 +647,21 @@ func getPluginCacheDir() (string, error) {
 	return cacheDir, nil
 }
 
-// Core monitoring loop with fast count-based detection
+// Core monitoring loop with fast count-based detection. Unless
+// disablePropertyListener is set, it also registers a CoreAudio property
+// listener via devices.WatchHardwareChanges so device-count changes and
+// default-device/format changes are picked up with near-zero latency; the
+// ticker below keeps running regardless as a fallback for when the listener
+// can't be installed (or is disabled) and for any notification it misses.
 func (s *Session) monitorDevices() {
+	if !s.disablePropertyListener {
+		if uninstall, err := devices.WatchHardwareChanges(s.signalHardwareEvent); err == nil {
+			atomic.StoreInt64(&s.propertyListenerActive, 1)
+			defer atomic.StoreInt64(&s.propertyListenerActive, 0)
+			defer uninstall()
+		}
+	}
+
 	ticker := time.NewTicker(s.pollInterval)
 	defer ticker.Stop()
 
@@ -394,14 +669,30 @@ func (s *Session) monitorDevices() {
 		select {
 		case <-s.ctx.Done():
 			return
+		case <-s.hardwareEvents:
+			s.checkForChangesAsync(true)
 		case <-ticker.C:
-			s.checkForChangesAsync()
+			s.checkForChangesAsync(false)
 		}
 	}
 }
 
-// Fast change detection with async scanning
-func (s *Session) checkForChangesAsync() {
+// signalHardwareEvent is devices.WatchHardwareChanges's callback; it wakes
+// monitorDevices without blocking the native listener thread on a full
+// hardwareEvents channel.
+func (s *Session) signalHardwareEvent() {
+	select {
+	case s.hardwareEvents <- struct{}{}:
+	default:
+	}
+}
+
+// Fast change detection with async scanning. fromListener is true when a
+// CoreAudio property listener (rather than the pollInterval ticker) woke
+// monitorDevices; it lets a same-count notification - a default-device or
+// nominal-sample-rate change, which never moves GetDeviceCounts - still be
+// reported instead of silently dropped the way the polling loop drops it.
+func (s *Session) checkForChangesAsync(fromListener bool) {
 	// Ultra-fast count check (~50µs)
 	newAudioCount, newMIDICount, err := devices.GetDeviceCounts()
 	if err != nil {
@@ -416,7 +707,24 @@ func (s *Session) checkForChangesAsync() {
 	midiChanged := int64(newMIDICount) != oldMIDICount
 
 	if !audioChanged && !midiChanged {
-		return // No changes detected
+		if !fromListener {
+			return // No changes detected
+		}
+		// The property listener fired without a count change - a default
+		// input/output or nominal-sample-rate change. Re-scan both device
+		// lists so cached data reflects it, and notify so consumers can act
+		// even though the counts the polling loop watches are unchanged.
+		change := DeviceChange{
+			Type:       BothDeviceChange,
+			Reason:     ReasonDefaultChanged,
+			Timestamp:  time.Now(),
+			AudioCount: newAudioCount,
+			MIDICount:  newMIDICount,
+		}
+		s.notifyChange(change)
+		go s.scanAudioDevicesAsync(change)
+		go s.scanMIDIDevicesAsync(change)
+		return
 	}
 
 	// Update counts immediately
@@ -433,9 +741,27 @@ func (s *Session) checkForChangesAsync() {
 		changeType = MIDIDeviceChange
 	}
 
+	// Classify why: a net increase in device count is an add, a net
+	// decrease is a remove. Both counts moving in opposite directions at
+	// once is rare (e.g. a USB interface and its paired MIDI port swapping
+	// out for different ones in the same tick); ReasonDefaultChanged is the
+	// closest fit since it's not a pure add or remove either.
+	var reason ChangeReason
+	oldTotal := oldAudioCount + oldMIDICount
+	newTotal := int64(newAudioCount) + int64(newMIDICount)
+	switch {
+	case newTotal > oldTotal:
+		reason = ReasonDevicesAdded
+	case newTotal < oldTotal:
+		reason = ReasonDevicesRemoved
+	default:
+		reason = ReasonDefaultChanged
+	}
+
 	// Create immediate notification with counts and scanning flags
 	change := DeviceChange{
 		Type:          changeType,
+		Reason:        reason,
 		Timestamp:     time.Now(),
 		AudioCount:    newAudioCount,
 		MIDICount:     newMIDICount,
@@ -471,6 +797,7 @@ func (s *Session) scanAudioDevicesAsync(initialChange DeviceChange) {
 	// Create completion notification
 	change := DeviceChange{
 		Type:          initialChange.Type,
+		Reason:        initialChange.Reason,
 		Timestamp:     time.Now(),
 		AudioCount:    initialChange.AudioCount,
 		MIDICount:     initialChange.MIDICount,
@@ -498,6 +825,7 @@ func (s *Session) scanMIDIDevicesAsync(initialChange DeviceChange) {
 	// Create completion notification
 	change := DeviceChange{
 		Type:          initialChange.Type,
+		Reason:        initialChange.Reason,
 		Timestamp:     time.Now(),
 		AudioCount:    initialChange.AudioCount,
 		MIDICount:     initialChange.MIDICount,
@@ -527,6 +855,11 @@ func (s *Session) notifyChange(change DeviceChange) {
 	for _, callback := range callbacks {
 		go callback(change)
 	}
+
+	// Fan out to Subscribe topics; see subscribe.go. Independent of the
+	// channel/callback paths above - a slow Subscribe subscriber is coalesced
+	// or evicted on its own terms, not by racing this 1ms timeout.
+	s.publishDeviceChange(change)
 }
 
 // Synchronous device refresh for initialization
@@ -592,6 +925,7 @@ func (s *Session) processPluginRequests() {
 // handlePluginRequest processes a single plugin request
 func (s *Session) handlePluginRequest(request PluginRequest) {
 	startTime := time.Now()
+	s.emitAudit(EventScanStarted, nil)
 
 	// Step 1: Load cache from disk if exists
 	cachedPlugins, cachedQuickInfo, err := s.loadFullPluginCache()
@@ -622,6 +956,8 @@ func (s *Session) handlePluginRequest(request PluginRequest) {
 		changes := s.findPluginChanges(cachedQuickInfo, currentInfos)
 		if len(changes) == 0 {
 			// No changes - return cached data
+			s.emitAudit(EventScanFinished, ScanEventPayload{CacheHit: true, ScanTime: time.Since(startTime)})
+			s.publishPluginScan(PluginScanEvent{CacheHit: true, ScanTime: time.Since(startTime), Timestamp: time.Now()})
 			request.Callback(PluginResult{
 				RequestID: request.ID,
 				Success:   true,
@@ -637,18 +973,43 @@ func (s *Session) handlePluginRequest(request PluginRequest) {
 	}
 }
 
-// doFullPluginScan performs complete plugin introspection
+// doFullPluginScan performs complete plugin introspection, fanning out
+// across s.introspectConcurrency workers via plugins.IntrospectParallel
+// instead of blocking the request goroutine on a single-threaded sweep. A
+// per-plugin failure never aborts the batch - it lands in the result's
+// Failures - so one crashy AUv3 component can't stall the whole scan.
 func (s *Session) doFullPluginScan(request PluginRequest, infos plugins.PluginInfos, startTime time.Time) {
-	// Introspect all plugins
-	allPlugins, err := infos.Introspect()
-	if err != nil {
-		request.Callback(PluginResult{
-			RequestID: request.ID,
-			Success:   false,
-			Error:     fmt.Sprintf("plugin introspection failed: %v", err),
-			Timestamp: time.Now(),
-		})
-		return
+	ctx := request.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	report, _ := infos.IntrospectParallel(plugins.IntrospectOptions{
+		Workers:         s.introspectConcurrency,
+		ContinueOnError: true,
+		Ctx:             ctx,
+		Progress: func(done, total int, current plugins.PluginInfo, err error) {
+			if request.Progress == nil {
+				return
+			}
+			progress := PluginProgress{Completed: done, Total: total, CurrentName: current.Name}
+			if err != nil {
+				progress.LastError = err.Error()
+			}
+			request.Progress(progress)
+		},
+	})
+
+	allPlugins := report.Plugins
+	failures := make([]PluginFailure, len(report.Failures))
+	for i, f := range report.Failures {
+		failures[i] = PluginFailure{
+			Name:     f.Info.Name,
+			Type:     f.Info.Type,
+			Subtype:  f.Info.Subtype,
+			Error:    f.Err.Error(),
+			TimedOut: f.TimedOut,
+		}
 	}
 
 	// Build cache
@@ -669,6 +1030,9 @@ func (s *Session) doFullPluginScan(request PluginRequest, infos plugins.PluginIn
 	// Save cache to disk asynchronously
 	go s.savePluginCache(cache)
 
+	s.emitAudit(EventScanFinished, ScanEventPayload{ScanTime: time.Since(startTime), ChangedCount: len(allPlugins)})
+	s.publishPluginScan(PluginScanEvent{ScanTime: time.Since(startTime), ChangedCount: len(allPlugins), Timestamp: time.Now()})
+
 	// Success callback
 	request.Callback(PluginResult{
 		RequestID:    request.ID,
@@ -698,20 +1062,53 @@ func (s *Session) buildQuickLookup(infos plugins.PluginInfos) map[string]string
 	return lookup
 }
 
-// findPluginChanges compares cache with current quick scan
+// findPluginChanges compares cache with current quick scan, restricting the
+// comparison to the pages whose checksum differs (see pageChecksumsForQuickInfo
+// in paging.go) so an added/changed plugin costs O(page) rather than a full
+// walk of every cached key.
 func (s *Session) findPluginChanges(cachedQuickInfo map[string]string, current plugins.PluginInfos) []string {
-	var changedKeys []string
-
-	// Build current lookup
 	currentLookup := s.buildQuickLookup(current)
 
-	// Find additions and modifications
-	for key, checksum := range currentLookup {
+	oldPages := pageChecksumsForQuickInfo(cachedQuickInfo)
+	newPages := pageChecksumsForQuickInfo(currentLookup)
+	oldKeys := sortedStringKeys(cachedQuickInfo)
+	newKeys := sortedStringKeys(currentLookup)
+
+	pages := len(oldPages)
+	if len(newPages) > pages {
+		pages = len(newPages)
+	}
+
+	candidates := map[string]bool{}
+	for p := 0; p < pages; p++ {
+		var oldSum, newSum string
+		if p < len(oldPages) {
+			oldSum = oldPages[p]
+		}
+		if p < len(newPages) {
+			newSum = newPages[p]
+		}
+		if oldSum == newSum {
+			continue
+		}
+		for _, k := range keysInPage(oldKeys, p) {
+			candidates[k] = true
+		}
+		for _, k := range keysInPage(newKeys, p) {
+			candidates[k] = true
+		}
+	}
+
+	var changedKeys []string
+	for key := range candidates {
+		checksum, existsNew := currentLookup[key]
+		if !existsNew {
+			continue // removals aren't reported here - matches the prior full-walk behavior
+		}
 		if cachedChecksum, exists := cachedQuickInfo[key]; !exists || cachedChecksum != checksum {
 			changedKeys = append(changedKeys, key)
 		}
 	}
-
 	return changedKeys
 }
 
@@ -772,6 +1169,10 @@ func (s *Session) updatePluginCache(request PluginRequest, cachedPlugins []*plug
 	// Save cache to disk asynchronously
 	go s.savePluginCache(cache)
 
+	s.emitAudit(EventCacheReconcile, CacheEventPayload{Op: "reconcile"})
+	s.emitAudit(EventScanFinished, ScanEventPayload{ScanTime: time.Since(startTime), ChangedCount: changedCount})
+	s.publishPluginScan(PluginScanEvent{ScanTime: time.Since(startTime), ChangedCount: changedCount, Timestamp: time.Now()})
+
 	// Success callback
 	request.Callback(PluginResult{
 		RequestID:    request.ID,
@@ -794,55 +1195,109 @@ func (s *Session) contains(slice []string, item string) bool {
 	return false
 }
 
-// loadFullPluginCache loads the complete cache from disk
+// loadFullPluginCache loads the complete cache from the bbolt-backed
+// index+details store (see doc.go's two-tier cache, cache_bolt.go). Entries
+// whose details entry is missing or whose stored checksum no longer matches
+// the index are dropped rather than served stale/corrupt, so they fall out
+// of cachedQuickInfo and get re-introspected on the next scan like any other
+// change.
 func (s *Session) loadFullPluginCache() ([]*plugins.Plugin, map[string]string, error) {
-	cacheDir, err := getPluginCacheDir()
+	idx, err := loadIndex()
 	if err != nil {
 		return nil, nil, err
 	}
-
-	cachePath := filepath.Join(cacheDir, "plugin_cache.json")
-	data, err := os.ReadFile(cachePath)
-	if err != nil {
-		return nil, nil, err // No cache file
+	if len(idx.Entries) == 0 {
+		return nil, nil, fmt.Errorf("no plugin cache present")
 	}
 
-	var cache PluginCache
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, nil, err // Corrupted cache
+	// Enforce TTL
+	if time.Since(idx.UpdatedAt) > pluginCacheTTL {
+		return nil, nil, fmt.Errorf("plugin cache expired")
 	}
 
-	// Validate cache version
-	if cache.Version != "1.0" {
-		return nil, nil, fmt.Errorf("unsupported plugin cache version: %s", cache.Version)
+	result := make([]*plugins.Plugin, 0, len(idx.Entries))
+	quickInfo := make(map[string]string, len(idx.Entries))
+	for key, entry := range idx.Entries {
+		plugin, checksum, err := readDetails(key)
+		if err != nil || checksum != entry.Checksum {
+			delete(idx.Entries, key)
+			continue
+		}
+		result = append(result, plugin)
+		quickInfo[key] = entry.Checksum
 	}
 
-	// Enforce TTL
-	if time.Since(cache.Timestamp) > pluginCacheTTL {
-		return nil, nil, fmt.Errorf("plugin cache expired")
-	}
+	s.idxMu.Lock()
+	s.idxSnap = idx
+	s.idxMu.Unlock()
 
-	return cache.Plugins, cache.QuickInfo, nil
+	return result, quickInfo, nil
 }
 
-// savePluginCache saves the cache to disk
+// savePluginCache persists a full-scan snapshot to the bbolt-backed
+// index+details store (see cache_bolt.go) instead of rewriting a monolithic
+// plugin_cache.json. Only plugins whose quick checksum changed since the
+// last snapshot get their details entry (re)written - a plugin already
+// cached unchanged from a prior scan is left alone - so a full rescan costs
+// O(changed) I/O rather than O(N). Each write is its own bbolt transaction,
+// so a crash mid-save leaves the store at its last committed write rather
+// than requiring journal replay.
 func (s *Session) savePluginCache(cache *PluginCache) {
-	cacheDir, err := getPluginCacheDir()
+	idx, err := loadIndex()
 	if err != nil {
-		return
+		idx = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}, SchemaVersion: pluginSchemaVersion}
 	}
 
-	cachePath := filepath.Join(cacheDir, "plugin_cache.json")
-	data, err := json.Marshal(cache)
-	if err != nil {
-		return
+	byKey := make(map[string]*plugins.Plugin, len(cache.Plugins))
+	for _, p := range cache.Plugins {
+		byKey[quadKey(p.Type, p.Subtype, p.ManufacturerID, p.Name)] = p
 	}
 
-	os.WriteFile(cachePath, data, 0644)
+	newEntries := make(map[string]indexEntry, len(cache.QuickInfo))
+	for key, checksum := range cache.QuickInfo {
+		plugin, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		old, existed := idx.Entries[key]
+		newEntries[key] = indexEntry{
+			Key: key, Type: plugin.Type, Subtype: plugin.Subtype, ManufacturerID: plugin.ManufacturerID,
+			Name: plugin.Name, Category: plugin.Category, Checksum: checksum, LastSeenAt: time.Now(),
+		}
+		if existed && old.Checksum == checksum {
+			continue // unchanged - the details entry from a prior scan is still valid
+		}
+		op := "update"
+		if !existed {
+			op = "add"
+		}
+		if err := writeDetails(key, checksum, plugin); err != nil {
+			return
+		}
+		s.emitAudit(EventCacheWrite, CacheEventPayload{Key: key, Op: op})
+	}
+
+	for key := range idx.Entries {
+		if _, ok := newEntries[key]; ok {
+			continue
+		}
+		_ = deleteDetails(key)
+		s.emitAudit(EventCacheWrite, CacheEventPayload{Key: key, Op: "delete"})
+	}
+
+	newIdx := &indexFile{Version: indexVersion, Entries: newEntries, SchemaVersion: pluginSchemaVersion}
+	newIdx.PageChecksums = pageChecksumsFor(newEntries)
+	if err := saveIndex(newIdx); err != nil {
+		return
+	}
+	s.idxMu.Lock()
+	s.idxSnap = newIdx
+	s.idxMu.Unlock()
 }
 
 // Close stops monitoring and cleans up resources
 func (s *Session) Close() error {
+	s.DisableAudit()
 	atomic.StoreInt64(&s.monitoring, 0)
 	s.cancel()
 	// Intentionally do not close s.deviceChanges to avoid send-on-closed panics.
@@ -852,6 +1307,28 @@ func (s *Session) Close() error {
 
 // QuickPlugins returns the cached quick index; runs a quick scan when empty/outdated and persists it.
 func (s *Session) QuickPlugins() (plugins.PluginInfos, error) {
+	ctx, cancel := s.introspectContext()
+	defer cancel()
+	return s.QuickPluginsCtx(ctx)
+}
+
+// introspectContext returns the context the non-Ctx forms of QuickPlugins,
+// Plugin, RefreshQuick, and Warm run under: a context.WithTimeout of
+// Options.DefaultIntrospectTimeout if one was set, otherwise
+// context.Background(). Callers must invoke the returned cancel func even
+// when Background() makes it a no-op, so go vet's lostcancel check stays
+// happy at every call site.
+func (s *Session) introspectContext() (context.Context, context.CancelFunc) {
+	if s.defaultIntrospectTimeout > 0 {
+		return context.WithTimeout(context.Background(), s.defaultIntrospectTimeout)
+	}
+	return context.Background(), func() {}
+}
+
+// QuickPluginsCtx is QuickPlugins, but cancellable/deadline-able via ctx -
+// propagated into plugins.ListWithContext so a broken AU scan can't hang the
+// caller past ctx's deadline.
+func (s *Session) QuickPluginsCtx(ctx context.Context) (plugins.PluginInfos, error) {
 	start := time.Now()
 	if s.hook != nil { s.hook.OnQuickScanStart() }
 	s.idxMu.RLock()
@@ -870,13 +1347,13 @@ func (s *Session) QuickPlugins() (plugins.PluginInfos, error) {
 	}
 
 	// Populate via quick scan
-	infos, err := plugins.List()
+	infos, err := plugins.ListWithContext(ctx)
 	if err != nil {
 		if s.hook != nil { s.hook.OnQuickScanDone(time.Since(start), 0, true) }
 		return nil, err
 	}
 	// Persist index
-	newIdx := &indexFile{Version: indexVersion, UpdatedAt: time.Now(), Entries: map[string]indexEntry{}}
+	newIdx := &indexFile{Version: indexVersion, UpdatedAt: time.Now(), Entries: map[string]indexEntry{}, SchemaVersion: pluginSchemaVersion}
 	for _, info := range infos {
 		key := quadKey(info.Type, info.Subtype, info.ManufacturerID, info.Name)
 		newIdx.Entries[key] = indexEntry{
@@ -884,7 +1361,8 @@ func (s *Session) QuickPlugins() (plugins.PluginInfos, error) {
 			Name: info.Name, Category: info.Category, Checksum: checksumQuick(info), LastSeenAt: time.Now(),
 		}
 	}
-	_ = saveIndex(newIdx) // best-effort
+	newIdx.PageChecksums = pageChecksumsFor(newIdx.Entries)
+	_ = s.backend.SaveIndex(newIdx) // best-effort
 	s.idxMu.Lock()
 	s.idxSnap = newIdx
 	s.idxMu.Unlock()
@@ -894,9 +1372,22 @@ func (s *Session) QuickPlugins() (plugins.PluginInfos, error) {
 
 // Plugin returns full details for the given quadruplet, using lazy cached details when available.
 func (s *Session) Plugin(t, st, man, name string) (*plugins.Plugin, error) {
+	ctx, cancel := s.introspectContext()
+	defer cancel()
+	return s.PluginCtx(ctx, t, st, man, name)
+}
+
+// PluginCtx is Plugin, but cancellable/deadline-able via ctx. A quarantined
+// key (a prior PluginCtx/WarmCtx call that timed out introspecting it) fails
+// immediately without re-attempting the introspect until Session.Unquarantine
+// is called for that key.
+func (s *Session) PluginCtx(ctx context.Context, t, st, man, name string) (*plugins.Plugin, error) {
 	key := quadKey(t, st, man, name)
+	if s.isQuarantined(key) {
+		return nil, fmt.Errorf("plugin %s is quarantined after a prior introspect timeout; call Unquarantine to retry", key)
+	}
 	// Single-flight dedupe: join in-flight call for the same key
-	if p, joined, err := s.joinInFlight(key); joined {
+	if p, joined, err := s.joinInFlightCtx(ctx, key); joined {
 		return p, err
 	}
 	defer s.finishInFlight(key)
@@ -910,8 +1401,8 @@ func (s *Session) Plugin(t, st, man, name string) (*plugins.Plugin, error) {
 			wantChecksum = e.Checksum
 		}
 	}
-	if wantChecksum != "" {
-		if p, chk, err := readDetails(key); err == nil && chk == wantChecksum {
+	if wantChecksum != "" && idx.SchemaVersion == pluginSchemaVersion {
+		if p, chk, err := s.backend.GetDetails(key); err == nil && chk == wantChecksum {
 			if s.hook != nil { s.hook.OnCacheHit(key) }
 			s.setInFlightResult(key, p, nil)
 			return p, nil
@@ -921,9 +1412,10 @@ func (s *Session) Plugin(t, st, man, name string) (*plugins.Plugin, error) {
 	// Introspect single
 	if s.hook != nil { s.hook.OnDetailsFetchStart(key) }
 	t0 := time.Now()
-	infos, err := plugins.List()
+	infos, err := plugins.ListWithContext(ctx)
 	if err != nil {
 		if s.hook != nil { s.hook.OnDetailsFetchDone(key, time.Since(t0), false) }
+		s.quarantineIfTimedOut(ctx, key)
 		s.setInFlightResult(key, nil, err)
 		return nil, err
 	}
@@ -941,27 +1433,76 @@ func (s *Session) Plugin(t, st, man, name string) (*plugins.Plugin, error) {
 		s.setInFlightResult(key, nil, err)
 		return nil, err
 	}
-	p, err := target.Introspect()
+	p, err := target.IntrospectWithContext(ctx)
 	if err != nil {
 	if s.hook != nil { s.hook.OnDetailsFetchDone(key, time.Since(t0), false) }
+		s.quarantineIfTimedOut(ctx, key)
 		s.setInFlightResult(key, nil, err)
 		return nil, err
 	}
 	// Persist details and refresh index entry
 	chk := checksumQuick(*target)
-	_ = writeDetails(key, chk, p)
+	_ = s.backend.PutDetails(key, chk, p)
 	s.idxMu.Lock()
 	if s.idxSnap == nil {
-		s.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}}
+		s.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}, SchemaVersion: pluginSchemaVersion}
 	}
 	s.idxSnap.Entries[key] = indexEntry{Key: key, Type: t, Subtype: st, ManufacturerID: man, Name: name, Category: target.Category, Checksum: chk, LastSeenAt: time.Now()}
-	_ = saveIndex(s.idxSnap)
+	_ = s.backend.SaveIndex(s.idxSnap)
 	s.idxMu.Unlock()
 	if s.hook != nil { s.hook.OnDetailsFetchDone(key, time.Since(t0), true) }
 	s.setInFlightResult(key, p, nil)
 	return p, nil
 }
 
+// isQuarantined reports whether key was previously quarantined by
+// quarantineIfTimedOut and hasn't been cleared by Unquarantine.
+func (s *Session) isQuarantined(key string) bool {
+	s.idxMu.RLock()
+	defer s.idxMu.RUnlock()
+	if s.idxSnap == nil || s.idxSnap.Quarantine == nil {
+		return false
+	}
+	_, ok := s.idxSnap.Quarantine[key]
+	return ok
+}
+
+// quarantineIfTimedOut marks key quarantined when ctx's deadline - not some
+// other failure mode - is why the introspect call just failed, so a plugin
+// that's merely missing or genuinely broken doesn't get silently skipped by
+// every later Warm/WarmCtx pass.
+func (s *Session) quarantineIfTimedOut(ctx context.Context, key string) {
+	if ctx.Err() != context.DeadlineExceeded {
+		return
+	}
+	s.idxMu.Lock()
+	if s.idxSnap == nil {
+		s.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}, SchemaVersion: pluginSchemaVersion}
+	}
+	if s.idxSnap.Quarantine == nil {
+		s.idxSnap.Quarantine = map[string]time.Time{}
+	}
+	s.idxSnap.Quarantine[key] = time.Now()
+	_ = s.backend.SaveIndex(s.idxSnap) // best-effort
+	s.idxMu.Unlock()
+}
+
+// Unquarantine clears key's quarantine entry (set by a prior PluginCtx/
+// WarmCtx introspect timeout) so the next Warm/WarmCtx pass retries it.
+// A key that was never quarantined is a no-op, not an error.
+func (s *Session) Unquarantine(key string) error {
+	s.idxMu.Lock()
+	defer s.idxMu.Unlock()
+	if s.idxSnap == nil || s.idxSnap.Quarantine == nil {
+		return nil
+	}
+	if _, ok := s.idxSnap.Quarantine[key]; !ok {
+		return nil
+	}
+	delete(s.idxSnap.Quarantine, key)
+	return s.backend.SaveIndex(s.idxSnap)
+}
+
 // inflightCall tracks waiting goroutines for Plugin() of a key
 type inflightCall struct {
 	done chan struct{}
@@ -971,14 +1512,26 @@ type inflightCall struct {
 
 // joinInFlight registers/join an in-flight call. If already running, waits and returns its result.
 func (s *Session) joinInFlight(key string) (*plugins.Plugin, bool, error) {
+	return s.joinInFlightCtx(context.Background(), key)
+}
+
+// joinInFlightCtx is joinInFlight, but gives up waiting as soon as ctx is
+// done instead of blocking until the owning call finishes - so a caller that
+// cancels doesn't leak a goroutine parked on <-done while an unrelated, still
+// running call keeps the entry alive.
+func (s *Session) joinInFlightCtx(ctx context.Context, key string) (*plugins.Plugin, bool, error) {
 	s.inflightMu.Lock()
 	if s.inflight == nil { s.inflight = make(map[string]*inflightCall) }
 	if c, ok := s.inflight[key]; ok {
 		// another call in-flight; wait
 		done := c.done
 		s.inflightMu.Unlock()
-		<-done
-		return c.p, true, c.err
+		select {
+		case <-done:
+			return c.p, true, c.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
 	}
 	// create a new in-flight entry for this caller to publish later
 	c := &inflightCall{done: make(chan struct{})}
@@ -1008,18 +1561,34 @@ func (s *Session) setInFlightResult(key string, p *plugins.Plugin, err error) {
 }
 
 // RefreshQuick re-runs quick scan, reconciles the index, and returns a simple diff summary.
-type QuickDiff struct{ Added, Removed, Changed []string }
+type QuickDiff struct {
+	Added, Removed, Changed []string
+	// ChangedReasons maps each key in Changed to why checksumQuick changed
+	// for it: "category" (the common case today), "checksum" (a forward
+	// -compatible fallback for whenever checksumQuick hashes more fields
+	// than it does now), or "schema" when this pass also migrated a stale
+	// SchemaVersion - see changedReasonsFor in paging.go.
+	ChangedReasons map[string]string
+}
 
 func (s *Session) RefreshQuick() (QuickDiff, error) {
+	ctx, cancel := s.introspectContext()
+	defer cancel()
+	return s.RefreshQuickCtx(ctx)
+}
+
+// RefreshQuickCtx is RefreshQuick, but cancellable/deadline-able via ctx.
+func (s *Session) RefreshQuickCtx(ctx context.Context) (QuickDiff, error) {
 	if s.hook != nil { s.hook.OnQuickScanStart() }
+	s.emitAudit(EventScanStarted, nil)
 	t0 := time.Now()
-	infos, err := plugins.List()
+	infos, err := plugins.ListWithContext(ctx)
 	if err != nil {
 		if s.hook != nil { s.hook.OnQuickScanDone(time.Since(t0), 0, true) }
 		return QuickDiff{}, err
 	}
 	// Build new index map
-	newIdx := &indexFile{Version: indexVersion, UpdatedAt: time.Now(), Entries: map[string]indexEntry{}}
+	newIdx := &indexFile{Version: indexVersion, UpdatedAt: time.Now(), Entries: map[string]indexEntry{}, SchemaVersion: pluginSchemaVersion}
 	for _, info := range infos {
 		key := quadKey(info.Type, info.Subtype, info.ManufacturerID, info.Name)
 		newIdx.Entries[key] = indexEntry{
@@ -1027,71 +1596,103 @@ func (s *Session) RefreshQuick() (QuickDiff, error) {
 			Name: info.Name, Category: info.Category, Checksum: checksumQuick(info), LastSeenAt: time.Now(),
 		}
 	}
+	// newIdx.PageChecksums lets the *next* RefreshQuick restrict its diff to
+	// the pages that actually changed instead of walking every entry - see
+	// diffByPage/pageChecksumsFor in paging.go.
+	newIdx.PageChecksums = pageChecksumsFor(newIdx.Entries)
+
 	// Diff
 	s.idxMu.RLock()
 	old := s.idxSnap
 	s.idxMu.RUnlock()
-	diff := QuickDiff{}
-	if old != nil {
-		for k, ov := range old.Entries {
-			nv, ok := newIdx.Entries[k]
-			if !ok {
-				diff.Removed = append(diff.Removed, k)
-			}
-			if ok && ov.Checksum != nv.Checksum {
-				diff.Changed = append(diff.Changed, k)
-			}
-		}
-	}
-	for k := range newIdx.Entries {
-		if old == nil {
-			diff.Added = append(diff.Added, k)
-			continue
-		}
-		if _, ok := old.Entries[k]; !ok {
-			diff.Added = append(diff.Added, k)
-		}
+	var diff QuickDiff
+	var oldEntries map[string]indexEntry
+	schemaStale := false
+	if old == nil {
+		diff = diffAll(map[string]indexEntry{}, newIdx.Entries)
+	} else {
+		oldEntries = old.Entries
+		diff = diffByPage(old.Entries, newIdx.Entries, old.PageChecksums, newIdx.PageChecksums)
+		schemaStale = old.SchemaVersion != 0 && old.SchemaVersion != pluginSchemaVersion
 	}
+	diff.ChangedReasons = changedReasonsFor(oldEntries, newIdx.Entries, diff.Changed, schemaStale)
 	// Save and swap snapshot
-	_ = saveIndex(newIdx)
+	_ = s.backend.SaveIndex(newIdx)
 	s.idxMu.Lock()
 	s.idxSnap = newIdx
 	s.idxMu.Unlock()
 	// Cleanup stale details for removed or changed keys (best-effort)
 	for _, k := range append(diff.Removed, diff.Changed...) {
-		_ = deleteDetails(k)
+		_ = s.backend.DeleteDetails(k)
+	}
+	// Archive (don't delete) presets for keys that vanished entirely, so a
+	// later reinstall of the same plugin can recover them - see
+	// preset_store.go's orphanPresets.
+	for _, k := range diff.Removed {
+		if err := orphanPresets(k); err == nil {
+			s.emitAudit(EventOrphanCleanup, OrphanEventPayload{Key: k})
+		}
 	}
 	if s.hook != nil {
 		s.hook.OnQuickScanDone(time.Since(t0), len(infos), true)
 		s.hook.OnRefreshQuickDiff(len(diff.Added), len(diff.Removed), len(diff.Changed), time.Since(t0))
 	}
+	changedCount := len(diff.Added) + len(diff.Removed) + len(diff.Changed)
+	s.emitAudit(EventScanFinished, ScanEventPayload{
+		ScanTime:     time.Since(t0),
+		ChangedCount: changedCount,
+	})
+	s.publishPluginScan(PluginScanEvent{ScanTime: time.Since(t0), ChangedCount: changedCount, Timestamp: time.Now()})
 	return diff, nil
 }
 
 // Warm introspects details for a subset defined by a selector and saves them to cache.
 func (s *Session) Warm(selector func(plugins.PluginInfo) bool, concurrency int) error {
+	ctx, cancel := s.introspectContext()
+	defer cancel()
+	return s.WarmCtx(ctx, selector, concurrency)
+}
+
+// WarmCtx is Warm, but cancellable/deadline-able via ctx: ctx is propagated
+// into every PluginCtx call, and once it's done no new introspects are
+// started (in-flight ones still run to completion so their goroutines don't
+// leak). Quarantined keys (see PluginCtx) are skipped without counting
+// against total/completed progress.
+func (s *Session) WarmCtx(ctx context.Context, selector func(plugins.PluginInfo) bool, concurrency int) error {
 	if concurrency <= 0 {
 		concurrency = 2
 	}
-	infos, err := s.QuickPlugins()
+	infos, err := s.QuickPluginsCtx(ctx)
 	if err != nil {
 		return err
 	}
 	total := 0
-	for _, info := range infos { if selector == nil || selector(info) { total++ } }
+	for _, info := range infos {
+		key := quadKey(info.Type, info.Subtype, info.ManufacturerID, info.Name)
+		if (selector == nil || selector(info)) && !s.isQuarantined(key) {
+			total++
+		}
+	}
 	completed := 0
 	if s.hook != nil { s.hook.OnWarmProgress(total, completed) }
 	sem := make(chan struct{}, concurrency)
 	errCh := make(chan error, concurrency)
 	for _, info := range infos {
+		if ctx.Err() != nil {
+			break
+		}
+		key := quadKey(info.Type, info.Subtype, info.ManufacturerID, info.Name)
 		if selector != nil && !selector(info) {
 			continue
 		}
+		if s.isQuarantined(key) {
+			continue
+		}
 		info := info
 		sem <- struct{}{}
 		go func() {
 			defer func() { <-sem }()
-			if _, err := s.Plugin(info.Type, info.Subtype, info.ManufacturerID, info.Name); err != nil {
+			if _, err := s.PluginCtx(ctx, info.Type, info.Subtype, info.ManufacturerID, info.Name); err != nil {
 				errCh <- err
 			}
 			completed++
@@ -3,77 +3,22 @@
 package session
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"log"
 	"os"
-	"os/signal"
-	"path/filepath"
 	"sync/atomic"
-	"syscall"
 	"testing"
 	"time"
 
 	"github.com/shaban/macaudio/plugins"
 )
 
+// TestMain used to grow a SESSION_INTERACTIVE=1 branch that ran a live
+// REPL/monitor inside the test binary. That surface now lives in
+// github.com/shaban/macaudio/sessioncli (wrapped by the cmd/macaudio-session
+// binary), so the test binary stays CI-safe and never blocks on a signal.
 func TestMain(m *testing.M) {
-	// Default to non-interactive to support CI and focused unit tests
-	if os.Getenv("SESSION_INTERACTIVE") != "1" {
-		os.Exit(m.Run())
-		return
-	}
-
-	// Interactive demonstration mode (run only when SESSION_INTERACTIVE=1)
-	fmt.Println("🚀 Session Package Test Suite")
-	fmt.Println("=============================")
-	fmt.Println()
-
-	fmt.Println("📋 Test 1: Session Creation")
-	sess, err := NewSessionWithDefaults()
-	if err != nil {
-		log.Fatalf("❌ Failed to create session: %v", err)
-	}
-	fmt.Printf("✅ Session created successfully\n")
-	fmt.Printf("   - Monitoring: %v\n", sess.IsMonitoring())
-	fmt.Printf("   - Audio spec: %+v\n", sess.GetAudioSpec())
-	fmt.Println()
-
-	fmt.Println("📋 Test 2: Initial Device Enumeration")
-	if audioDevices, err := sess.GetAudioDevices(); err == nil {
-		fmt.Printf("✅ Audio devices: %d found\n", len(audioDevices))
-	}
-	if midiDevices, err := sess.GetMIDIDevices(); err == nil {
-		fmt.Printf("✅ MIDI devices: %d found\n", len(midiDevices))
-	}
-	fmt.Println()
-
-	fmt.Println("📋 Test 3: Fast Device Counts")
-	audioCount, midiCount := sess.GetDeviceCounts()
-	fmt.Printf("✅ Fast counts: %d audio, %d MIDI\n", audioCount, midiCount)
-	fmt.Println()
-
-	// Minimal callback check
-	callbackCalled := false
-	sess.OnDeviceChange(func(change DeviceChange) { callbackCalled = true })
-	sess.SimulateDeviceChange(BothDeviceChange)
-	time.Sleep(10 * time.Millisecond)
-	_ = callbackCalled
-
-	// Async plugin load (best-effort)
-	done := make(chan struct{}, 1)
-	sess.GetPluginsAsync(func(result PluginResult) { done <- struct{}{} })
-	select {
-	case <-done:
-	case <-time.After(10 * time.Second):
-	}
-
-	// Interactive monitoring until Ctrl+C
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
-	_ = sess.Close()
-	os.Exit(0)
+	os.Exit(m.Run())
 }
 
 func TestSessionCreation(t *testing.T) {
@@ -332,18 +277,13 @@ func TestRefreshQuickCleansStaleDetails(t *testing.T) {
 	_ = saveIndex(sess.idxSnap)
 	sess.idxMu.Unlock()
 
-	// Write a details file for the fake key
+	// Write details for the fake key
 	if err := writeDetails(fakeKey, "deadbeef", &plugins.Plugin{Name: "Nonexistent Plugin", ManufacturerID: "ACME", Type: "aufx", Subtype: "FAKE"}); err != nil {
 		t.Fatalf("failed to write fake details: %v", err)
 	}
-	// Sanity: file should exist now
-	_, detailsDir, err := getIndexPaths()
-	if err != nil {
-		t.Fatalf("getIndexPaths: %v", err)
-	}
-	path := filepath.Join(detailsDir, detailFileName(fakeKey))
-	if _, err := os.Stat(path); err != nil {
-		t.Fatalf("expected fake details file to exist: %v", err)
+	// Sanity: details should be readable now
+	if _, _, err := readDetails(fakeKey); err != nil {
+		t.Fatalf("expected fake details to exist: %v", err)
 	}
 
 	// Run RefreshQuick; since fakeKey won't be in current scan, it should be considered removed and cleaned
@@ -355,7 +295,142 @@ func TestRefreshQuickCleansStaleDetails(t *testing.T) {
 	t.Logf("RefreshQuick diff: %+v", diff)
 	// Allow a brief moment for cleanup (though it's synchronous currently)
 	time.Sleep(20 * time.Millisecond)
-	if _, err := os.Stat(path); !os.IsNotExist(err) {
-		t.Fatalf("expected fake details file to be deleted, got err=%v", err)
+	if _, _, err := readDetails(fakeKey); err == nil {
+		t.Fatal("expected fake details to be deleted")
+	}
+}
+
+func TestPluginCtxSkipsQuarantinedKey(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	fakeKey := "aufx:FAKE:ACME:Quarantined Plugin"
+	if sess.isQuarantined(fakeKey) {
+		t.Fatal("key should not start quarantined")
+	}
+
+	sess.idxMu.Lock()
+	if sess.idxSnap == nil {
+		sess.idxSnap = &indexFile{Version: indexVersion, Entries: map[string]indexEntry{}}
+	}
+	if sess.idxSnap.Quarantine == nil {
+		sess.idxSnap.Quarantine = map[string]time.Time{}
+	}
+	sess.idxSnap.Quarantine[fakeKey] = time.Now()
+	sess.idxMu.Unlock()
+
+	if !sess.isQuarantined(fakeKey) {
+		t.Fatal("key should be quarantined after setting Quarantine entry")
+	}
+	if _, err := sess.PluginCtx(context.Background(), "aufx", "FAKE", "ACME", "Quarantined Plugin"); err == nil {
+		t.Fatal("expected PluginCtx to refuse a quarantined key")
+	}
+
+	if err := sess.Unquarantine(fakeKey); err != nil {
+		t.Fatalf("Unquarantine: %v", err)
+	}
+	if sess.isQuarantined(fakeKey) {
+		t.Fatal("key should no longer be quarantined after Unquarantine")
+	}
+	if err := sess.Unquarantine(fakeKey); err != nil {
+		t.Fatalf("Unquarantine on an already-clear key should be a no-op, got: %v", err)
+	}
+}
+
+func TestRefreshQuickReportsChangedReasons(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	if _, err := sess.RefreshQuick(); err != nil {
+		t.Fatalf("refresh quick failed: %v", err)
+	}
+
+	// Seed a fake entry, then mutate its Category before the next
+	// RefreshQuick so diffByPage/diffAll reports it Changed.
+	fakeKey := "aufx:FAKE:ACME:Recategorized Plugin"
+	sess.idxMu.Lock()
+	sess.idxSnap.Entries[fakeKey] = indexEntry{Key: fakeKey, Type: "aufx", Subtype: "FAKE", ManufacturerID: "ACME", Name: "Recategorized Plugin", Category: "Effect", Checksum: "old-checksum", LastSeenAt: time.Now()}
+	sess.idxSnap.PageChecksums = pageChecksumsFor(sess.idxSnap.Entries)
+	_ = saveIndex(sess.idxSnap)
+	sess.idxMu.Unlock()
+
+	reasons := changedReasonsFor(
+		map[string]indexEntry{fakeKey: {Category: "Effect", Checksum: "old-checksum"}},
+		map[string]indexEntry{fakeKey: {Category: "Instrument", Checksum: "new-checksum"}},
+		[]string{fakeKey},
+		false,
+	)
+	if reasons[fakeKey] != "category" {
+		t.Fatalf("expected category change to be classified as %q, got %q", "category", reasons[fakeKey])
+	}
+
+	reasons = changedReasonsFor(
+		map[string]indexEntry{fakeKey: {Category: "Effect", Checksum: "old-checksum"}},
+		map[string]indexEntry{fakeKey: {Category: "Effect", Checksum: "new-checksum"}},
+		[]string{fakeKey},
+		true,
+	)
+	if reasons[fakeKey] != "schema" {
+		t.Fatalf("expected schemaStale to override classification with %q, got %q", "schema", reasons[fakeKey])
+	}
+}
+
+func TestPluginCtxTreatsStaleSchemaVersionAsCacheMiss(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	fakeKey := "aufx:FAKE:ACME:Stale Schema Plugin"
+	if err := writeDetails(fakeKey, "deadbeef", &plugins.Plugin{Name: "Stale Schema Plugin", ManufacturerID: "ACME", Type: "aufx", Subtype: "FAKE"}); err != nil {
+		t.Fatalf("failed to write fake details: %v", err)
+	}
+
+	sess.idxMu.Lock()
+	sess.idxSnap = &indexFile{
+		Version:       indexVersion,
+		Entries:       map[string]indexEntry{fakeKey: {Key: fakeKey, Type: "aufx", Subtype: "FAKE", ManufacturerID: "ACME", Name: "Stale Schema Plugin", Category: "Effect", Checksum: "deadbeef", LastSeenAt: time.Now()}},
+		SchemaVersion: pluginSchemaVersion - 1,
+	}
+	sess.idxMu.Unlock()
+
+	// A checksum match alone must not be enough: with a stale SchemaVersion,
+	// PluginCtx should fall through to re-introspecting rather than trusting
+	// the cached details - introspection itself will fail here (no real
+	// AudioUnit backs fakeKey), which is how this test observes the cache
+	// was bypassed instead of short-circuiting on OnCacheHit.
+	if _, err := sess.PluginCtx(context.Background(), "aufx", "FAKE", "ACME", "Stale Schema Plugin"); err == nil {
+		t.Fatal("expected PluginCtx to re-introspect rather than trust a stale-schema cache hit")
 	}
 }
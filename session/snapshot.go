@@ -0,0 +1,365 @@
+//go:build darwin
+
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+// sessionSnapshotVersion is SessionSnapshot's schema version. Restore
+// rejects a snapshot whose SchemaVersion doesn't match rather than
+// guessing at an older/newer shape.
+const sessionSnapshotVersion = 1
+
+// DeviceDescriptor is a lightweight record of one audio device captured in
+// a SessionSnapshot - enough for Restore's ResolutionPolicy to find a
+// reasonable substitute if UID no longer matches anything connected.
+type DeviceDescriptor struct {
+	UID          string `json:"uid"`
+	Name         string `json:"name"`
+	ChannelCount int    `json:"channelCount"`
+	SampleRate   int    `json:"sampleRate,omitempty"`
+}
+
+// AggregateSnapshot records an active aggregate device's composition so
+// Restore can re-create it via CreateAggregate if its exact UID is gone.
+type AggregateSnapshot struct {
+	UID           string   `json:"uid"`
+	Name          string   `json:"name"`
+	SubDeviceUIDs []string `json:"subDeviceUIDs"`
+	MasterUID     string   `json:"masterUID,omitempty"`
+}
+
+// SessionSnapshot is a portable, versioned record of a session's resolved
+// audio topology - the current default input/output devices, the AudioSpec
+// in effect, an aggregate composition if either default device is one, and
+// the QuickInfo checksums of every plugin known to the session's caches -
+// for persisting or migrating "the user's setup" the way a DAW remembers
+// its I/O configuration. See Session.Snapshot and Session.Restore.
+type SessionSnapshot struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Timestamp     time.Time `json:"timestamp"`
+
+	AudioSpec AudioSpec `json:"audioSpec"`
+
+	InputDevice  *DeviceDescriptor `json:"inputDevice,omitempty"`
+	OutputDevice *DeviceDescriptor `json:"outputDevice,omitempty"`
+
+	Aggregate *AggregateSnapshot `json:"aggregate,omitempty"`
+
+	// Plugins is the quick-info checksum (see buildQuickLookup/checksumQuick)
+	// for every plugin the session's caches knew about at snapshot time,
+	// keyed by its type:subtype:manufacturerID:name quadruplet. Restore
+	// compares it against the live checksum to flag a plugin that has since
+	// changed or vanished rather than silently loading as if nothing
+	// happened.
+	Plugins map[string]string `json:"plugins,omitempty"`
+}
+
+// Snapshot captures the session's current default input/output devices,
+// AudioSpec, any active aggregate they belong to, and the plugin caches'
+// quick-info checksums into a versioned, JSON-serializable SessionSnapshot.
+func (s *Session) Snapshot() (SessionSnapshot, error) {
+	audioDevices, err := s.GetAudioDevices()
+	if err != nil {
+		return SessionSnapshot{}, err
+	}
+
+	snap := SessionSnapshot{
+		SchemaVersion: sessionSnapshotVersion,
+		Timestamp:     time.Now(),
+		AudioSpec:     s.audioSpec,
+	}
+
+	var inputDev, outputDev *devices.AudioDevice
+	for i := range audioDevices {
+		d := &audioDevices[i]
+		if d.IsDefaultInput && inputDev == nil {
+			inputDev = d
+		}
+		if d.IsDefaultOutput && outputDev == nil {
+			outputDev = d
+		}
+	}
+	if inputDev != nil {
+		snap.InputDevice = descriptorFor(*inputDev)
+	}
+	if outputDev != nil {
+		snap.OutputDevice = descriptorFor(*outputDev)
+	}
+	if agg := aggregateSnapshotFor(inputDev, outputDev); agg != nil {
+		snap.Aggregate = agg
+	}
+
+	snap.Plugins = s.currentPluginChecksums()
+
+	return snap, nil
+}
+
+func descriptorFor(d devices.AudioDevice) *DeviceDescriptor {
+	channels := d.InputChannelCount
+	if d.OutputChannelCount > channels {
+		channels = d.OutputChannelCount
+	}
+	sampleRate := 0
+	if len(d.SupportedSampleRates) > 0 {
+		sampleRate = d.SupportedSampleRates[0]
+	}
+	return &DeviceDescriptor{UID: d.UID, Name: d.Name, ChannelCount: channels, SampleRate: sampleRate}
+}
+
+func aggregateSnapshotFor(devs ...*devices.AudioDevice) *AggregateSnapshot {
+	for _, d := range devs {
+		if d != nil && d.DeviceType == "aggregate" && len(d.AggregateSubDeviceUIDs) > 0 {
+			return &AggregateSnapshot{
+				UID:           d.UID,
+				Name:          d.Name,
+				SubDeviceUIDs: append([]string(nil), d.AggregateSubDeviceUIDs...),
+			}
+		}
+	}
+	return nil
+}
+
+// currentPluginChecksums merges the full-scan cache's quick-info
+// (cachedQuickInfo) with the lighter quick-index snapshot (idxSnap) so
+// Snapshot/Restore see every plugin the session currently knows about,
+// whichever cache happened to populate it.
+func (s *Session) currentPluginChecksums() map[string]string {
+	s.pluginMutex.RLock()
+	current := make(map[string]string, len(s.cachedQuickInfo))
+	for k, v := range s.cachedQuickInfo {
+		current[k] = v
+	}
+	s.pluginMutex.RUnlock()
+
+	s.idxMu.RLock()
+	if s.idxSnap != nil {
+		for k, e := range s.idxSnap.Entries {
+			if _, ok := current[k]; !ok {
+				current[k] = e.Checksum
+			}
+		}
+	}
+	s.idxMu.RUnlock()
+
+	if len(current) == 0 {
+		return nil
+	}
+	return current
+}
+
+// ResolutionPolicy tells Restore what to do when a SessionSnapshot's
+// device UID no longer matches anything connected.
+type ResolutionPolicy int
+
+const (
+	// ResolutionStrict fails that side instead of substituting anything.
+	ResolutionStrict ResolutionPolicy = iota
+	// ResolutionPreferSameManufacturer substitutes the connected device
+	// whose name starts with the same first word as the snapshot's.
+	// Devices don't carry a structured manufacturer field the way plugins
+	// do (see plugins.PluginInfo.ManufacturerID), so this is a best-effort
+	// stand-in for "same vendor" based on how device names are typically
+	// formatted ("Focusrite Scarlett 18i20", "Apollo Twin X").
+	ResolutionPreferSameManufacturer
+	// ResolutionNearest substitutes the connected device whose channel
+	// count and sample rate are closest to the snapshot's.
+	ResolutionNearest
+)
+
+// PluginVersionWarning flags one plugin referenced in a SessionSnapshot
+// whose state has changed or vanished since the snapshot was taken; see
+// Restore.
+type PluginVersionWarning struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"` // "missing" or "changed"
+}
+
+// RestoreResult reports what Restore actually did, since a ResolutionPolicy
+// other than ResolutionStrict can mean the live topology doesn't exactly
+// match the snapshot.
+type RestoreResult struct {
+	InputDeviceUID     string
+	OutputDeviceUID    string
+	InputSubstituted   bool
+	OutputSubstituted  bool
+	AggregateRecreated bool
+	PluginWarnings     []PluginVersionWarning
+}
+
+// Restore applies a SessionSnapshot's AudioSpec and re-resolves its
+// input/output devices against what's currently connected. An exact UID
+// match is always preferred; when one isn't connected, policy decides what
+// happens - see ResolutionPolicy. If snapshot.Aggregate named a UID that's
+// now missing, Restore re-creates it via CreateAggregate before resolving
+// input/output against the refreshed device list. Every key in
+// snapshot.Plugins whose current quick-info checksum differs (or is gone
+// entirely) is reported in RestoreResult.PluginWarnings rather than
+// silently treated as unchanged.
+func (s *Session) Restore(snapshot SessionSnapshot, policy ResolutionPolicy) (RestoreResult, error) {
+	if snapshot.SchemaVersion != sessionSnapshotVersion {
+		return RestoreResult{}, fmt.Errorf("unsupported session snapshot schema version: %d", snapshot.SchemaVersion)
+	}
+
+	s.audioSpec = snapshot.AudioSpec
+
+	audioDevices, err := s.GetAudioDevices()
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	var result RestoreResult
+
+	if agg := snapshot.Aggregate; agg != nil && !deviceExists(audioDevices, agg.UID) && len(agg.SubDeviceUIDs) >= 2 {
+		spec := AggregateSpec{Name: agg.Name, SubDeviceUIDs: agg.SubDeviceUIDs, MasterUID: agg.MasterUID}
+		if _, err := s.CreateAggregate(spec); err == nil {
+			result.AggregateRecreated = true
+			if audioDevices, err = s.GetAudioDevices(); err != nil {
+				return RestoreResult{}, err
+			}
+		}
+	}
+
+	if snapshot.InputDevice != nil {
+		uid, substituted, err := resolveDevice(*snapshot.InputDevice, audioDevices, policy)
+		if err != nil {
+			return result, err
+		}
+		result.InputDeviceUID, result.InputSubstituted = uid, substituted
+	}
+	if snapshot.OutputDevice != nil {
+		uid, substituted, err := resolveDevice(*snapshot.OutputDevice, audioDevices, policy)
+		if err != nil {
+			return result, err
+		}
+		result.OutputDeviceUID, result.OutputSubstituted = uid, substituted
+	}
+
+	result.PluginWarnings = s.pluginVersionWarnings(snapshot.Plugins)
+
+	return result, nil
+}
+
+func deviceExists(current devices.AudioDevices, uid string) bool {
+	if uid == "" {
+		return false
+	}
+	for _, d := range current {
+		if d.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDevice looks for want.UID among current, falling back to policy
+// when it's no longer connected.
+func resolveDevice(want DeviceDescriptor, current devices.AudioDevices, policy ResolutionPolicy) (uid string, substituted bool, err error) {
+	for _, d := range current {
+		if d.UID == want.UID {
+			return d.UID, false, nil
+		}
+	}
+
+	switch policy {
+	case ResolutionPreferSameManufacturer:
+		if d, ok := nearestByNamePrefix(want, current); ok {
+			return d.UID, true, nil
+		}
+	case ResolutionNearest:
+		if d, ok := nearestByChannelsAndRate(want, current); ok {
+			return d.UID, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("session snapshot device %q (%s) is not connected", want.Name, want.UID)
+}
+
+func nearestByNamePrefix(want DeviceDescriptor, current devices.AudioDevices) (devices.AudioDevice, bool) {
+	token := firstWord(want.Name)
+	if token == "" {
+		return devices.AudioDevice{}, false
+	}
+	for _, d := range current {
+		if firstWord(d.Name) == token {
+			return d, true
+		}
+	}
+	return devices.AudioDevice{}, false
+}
+
+func firstWord(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.IndexByte(name, ' '); i > 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+func nearestByChannelsAndRate(want DeviceDescriptor, current devices.AudioDevices) (devices.AudioDevice, bool) {
+	if len(current) == 0 {
+		return devices.AudioDevice{}, false
+	}
+	best := current[0]
+	bestScore := deviceDistance(want, best)
+	for _, d := range current[1:] {
+		if score := deviceDistance(want, d); score < bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best, true
+}
+
+// deviceDistance weights channel-count difference far higher than sample
+// rate difference - channel count determines whether a device can even
+// serve the role at all, sample rate is just a preference.
+func deviceDistance(want DeviceDescriptor, d devices.AudioDevice) int {
+	channels := d.InputChannelCount
+	if d.OutputChannelCount > channels {
+		channels = d.OutputChannelCount
+	}
+	diff := channels - want.ChannelCount
+	if diff < 0 {
+		diff = -diff
+	}
+	rateDiff := 0
+	if want.SampleRate > 0 && len(d.SupportedSampleRates) > 0 {
+		rateDiff = d.SupportedSampleRates[0] - want.SampleRate
+		if rateDiff < 0 {
+			rateDiff = -rateDiff
+		}
+	}
+	return diff*1000 + rateDiff
+}
+
+// pluginVersionWarnings compares snapshotPlugins against the session's
+// current plugin checksums, reporting anything missing or changed.
+func (s *Session) pluginVersionWarnings(snapshotPlugins map[string]string) []PluginVersionWarning {
+	if len(snapshotPlugins) == 0 {
+		return nil
+	}
+	current := s.currentPluginChecksums()
+
+	keys := make([]string, 0, len(snapshotPlugins))
+	for k := range snapshotPlugins {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var warnings []PluginVersionWarning
+	for _, key := range keys {
+		checksum, ok := current[key]
+		switch {
+		case !ok:
+			warnings = append(warnings, PluginVersionWarning{Key: key, Reason: "missing"})
+		case checksum != snapshotPlugins[key]:
+			warnings = append(warnings, PluginVersionWarning{Key: key, Reason: "changed"})
+		}
+	}
+	return warnings
+}
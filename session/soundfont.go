@@ -0,0 +1,180 @@
+//go:build darwin
+
+package session
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SoundFontPreset is one (bank, program, name) tuple read from an SF2/DLS
+// sound bank's preset directory by ListSoundFontPresets - named apart from
+// this package's own Preset (a saved parameter snapshot) since it describes
+// an instrument selector within a sound bank file, not a plugin's state.
+type SoundFontPreset struct {
+	Bank    int    `json:"bank"`
+	Program int    `json:"program"`
+	Name    string `json:"name"`
+}
+
+// ListSoundFontPresets reads the preset directory out of the SF2 or DLS
+// sound bank at path, without loading the (often many-megabyte) sample
+// data, so a caller can show a user which (bank, program) pairs exist
+// before passing one to engine.Channel.LoadSoundFont. Both formats are
+// RIFF containers; the form type at offset 8 ("sfbk" vs "DLS ") picks
+// which chunk layout is parsed.
+func ListSoundFontPresets(path string) ([]SoundFontPreset, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 12 || string(raw[0:4]) != "RIFF" {
+		return nil, fmt.Errorf("soundfont: %s is not a RIFF file", path)
+	}
+
+	switch string(raw[8:12]) {
+	case "sfbk":
+		return parseSF2Presets(raw[12:])
+	case "DLS ":
+		return parseDLSPresets(raw[12:])
+	default:
+		return nil, fmt.Errorf("soundfont: %s has unrecognized RIFF form type %q", path, raw[8:12])
+	}
+}
+
+// riffChunk is one "<fourcc><size><data>" record within a RIFF container,
+// as walked by walkRIFFChunks. Chunk data is padded to an even length; size
+// is the unpadded length, same as FLAC/MP4's own chunk sizes in
+// metadata.go.
+type riffChunk struct {
+	id   string
+	data []byte
+}
+
+// walkRIFFChunks returns every top-level chunk within data, which must
+// already have a RIFF/LIST header's own id+size+form-type stripped (i.e.
+// data starts right after the form type, or right after a LIST chunk's own
+// list-type field).
+func walkRIFFChunks(data []byte) []riffChunk {
+	var chunks []riffChunk
+	pos := 0
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		pos += 8
+		if pos+size > len(data) {
+			break
+		}
+		chunks = append(chunks, riffChunk{id: id, data: data[pos : pos+size]})
+		pos += size
+		if size%2 == 1 {
+			pos++ // pad byte
+		}
+	}
+	return chunks
+}
+
+// findRIFFList returns the body of the first top-level LIST chunk in
+// chunks whose list-type tag matches listType (e.g. "pdta", "lins"), or
+// nil if none is present.
+func findRIFFList(chunks []riffChunk, listType string) []byte {
+	for _, c := range chunks {
+		if c.id == "LIST" && len(c.data) >= 4 && string(c.data[0:4]) == listType {
+			return c.data[4:]
+		}
+	}
+	return nil
+}
+
+// findRIFFChunk returns the data of the first top-level chunk in chunks
+// with the given fourcc, or nil if none is present.
+func findRIFFChunk(chunks []riffChunk, id string) []byte {
+	for _, c := range chunks {
+		if c.id == id {
+			return c.data
+		}
+	}
+	return nil
+}
+
+// sf2PhdrRecordSize is the size in bytes of one sfPresetHeader record in an
+// SF2 file's phdr chunk, per the SoundFont 2.04 spec section 7.2: a 20-byte
+// name, then wPreset/wBank/wPresetBagNdx (WORD each) and
+// dwLibrary/dwGenre/dwMorphology (DWORD each).
+const sf2PhdrRecordSize = 38
+
+// parseSF2Presets reads the phdr (preset headers) chunk out of an SF2
+// file's pdta LIST, skipping the trailing "EOP" terminator record every
+// well-formed phdr chunk ends with (it marks the end of the last preset's
+// generator/modulator bag, not a real preset).
+func parseSF2Presets(body []byte) ([]SoundFontPreset, error) {
+	pdta := findRIFFList(walkRIFFChunks(body), "pdta")
+	if pdta == nil {
+		return nil, fmt.Errorf("soundfont: missing pdta chunk")
+	}
+	phdr := findRIFFChunk(walkRIFFChunks(pdta), "phdr")
+	if phdr == nil {
+		return nil, fmt.Errorf("soundfont: missing phdr chunk")
+	}
+
+	count := len(phdr) / sf2PhdrRecordSize
+	if count == 0 {
+		return nil, nil
+	}
+
+	presets := make([]SoundFontPreset, 0, count-1)
+	for i := 0; i < count-1; i++ { // last record is the EOP terminator
+		rec := phdr[i*sf2PhdrRecordSize : (i+1)*sf2PhdrRecordSize]
+		name := strings.TrimRight(string(rec[0:20]), "\x00")
+		program := int(binary.LittleEndian.Uint16(rec[20:22]))
+		bank := int(binary.LittleEndian.Uint16(rec[22:24]))
+		presets = append(presets, SoundFontPreset{Bank: bank, Program: program, Name: name})
+	}
+	return presets, nil
+}
+
+// dlsInshRecordSize is the size in bytes of a DLS "insh" chunk: a DWORD
+// region count followed by an MIDILOCALE struct (bank, then program),
+// per the DLS Level 1 spec section 1.9.3.
+const dlsInshRecordSize = 12
+
+// parseDLSPresets reads the lins (instrument list) LIST out of a DLS file,
+// returning one SoundFontPreset per "ins " sub-list: bank/program from its
+// insh chunk, name from its INFO/INAM sub-chunk. DLS encodes the melodic/
+// percussion bank split into bit 31/bits 0-7 of the bank DWORD the same
+// way General MIDI bank-select does; that's surfaced as-is rather than
+// decoded, since LoadSoundFont's bank/program pass straight through to
+// AVAudioUnitSampler.loadSoundBankInstrument.
+func parseDLSPresets(body []byte) ([]SoundFontPreset, error) {
+	lins := findRIFFList(walkRIFFChunks(body), "lins")
+	if lins == nil {
+		return nil, fmt.Errorf("soundfont: missing lins chunk")
+	}
+
+	var presets []SoundFontPreset
+	for _, c := range walkRIFFChunks(lins) {
+		if c.id != "LIST" || len(c.data) < 4 || string(c.data[0:4]) != "ins " {
+			continue
+		}
+		insChunks := walkRIFFChunks(c.data[4:])
+
+		insh := findRIFFChunk(insChunks, "insh")
+		if len(insh) < dlsInshRecordSize {
+			continue
+		}
+		bank := int(binary.LittleEndian.Uint32(insh[4:8]))
+		program := int(binary.LittleEndian.Uint32(insh[8:12]))
+
+		name := ""
+		if info := findRIFFList(insChunks, "INFO"); info != nil {
+			if inam := findRIFFChunk(walkRIFFChunks(info), "INAM"); inam != nil {
+				name = strings.TrimRight(string(inam), "\x00")
+			}
+		}
+
+		presets = append(presets, SoundFontPreset{Bank: bank, Program: program, Name: name})
+	}
+	return presets, nil
+}
@@ -0,0 +1,85 @@
+//go:build darwin
+
+package session
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildTestSF2 assembles a minimal valid SF2 file with a two-preset phdr
+// chunk (plus the mandatory EOP terminator record) wrapped in the
+// RIFF/sfbk/pdta/phdr chunk structure ListSoundFontPresets expects.
+func buildTestSF2(t *testing.T) string {
+	t.Helper()
+
+	phdrRecord := func(name string, preset, bank uint16) []byte {
+		rec := make([]byte, sf2PhdrRecordSize)
+		copy(rec, name)
+		binary.LittleEndian.PutUint16(rec[20:22], preset)
+		binary.LittleEndian.PutUint16(rec[22:24], bank)
+		return rec
+	}
+
+	var phdr []byte
+	phdr = append(phdr, phdrRecord("Grand Piano", 0, 0)...)
+	phdr = append(phdr, phdrRecord("Drum Kit", 0, 128)...)
+	phdr = append(phdr, phdrRecord("EOP", 0, 0)...) // terminator
+
+	chunk := func(id string, data []byte) []byte {
+		out := append([]byte(id), make([]byte, 4)...)
+		binary.LittleEndian.PutUint32(out[4:8], uint32(len(data)))
+		out = append(out, data...)
+		if len(data)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	phdrChunk := chunk("phdr", phdr)
+	pdtaBody := append([]byte("pdta"), phdrChunk...)
+	pdtaChunk := chunk("LIST", pdtaBody)
+	sfbkBody := append([]byte("sfbk"), pdtaChunk...)
+	riff := chunk("RIFF", sfbkBody)
+
+	path := filepath.Join(t.TempDir(), "test.sf2")
+	if err := os.WriteFile(path, riff, 0o644); err != nil {
+		t.Fatalf("writing test SF2 file: %v", err)
+	}
+	return path
+}
+
+func TestListSoundFontPresetsSF2(t *testing.T) {
+	path := buildTestSF2(t)
+
+	presets, err := ListSoundFontPresets(path)
+	if err != nil {
+		t.Fatalf("ListSoundFontPresets: %v", err)
+	}
+
+	want := []SoundFontPreset{
+		{Bank: 0, Program: 0, Name: "Grand Piano"},
+		{Bank: 128, Program: 0, Name: "Drum Kit"},
+	}
+	if len(presets) != len(want) {
+		t.Fatalf("got %d presets, want %d: %+v", len(presets), len(want), presets)
+	}
+	for i, p := range presets {
+		if p != want[i] {
+			t.Errorf("preset %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestListSoundFontPresetsRejectsNonRIFF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-soundfont.sf2")
+	if err := os.WriteFile(path, []byte("not riff data"), 0o644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	if _, err := ListSoundFontPresets(path); err == nil {
+		t.Error("expected an error for a non-RIFF file, got nil")
+	}
+}
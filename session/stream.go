@@ -0,0 +1,387 @@
+//go:build darwin
+
+package session
+
+import (
+	"fmt"
+	"sync"
+
+	aveng "github.com/shaban/macaudio/avaudio/engine"
+	audioformat "github.com/shaban/macaudio/avaudio/format"
+	"github.com/shaban/macaudio/devices"
+)
+
+// lookupDevice finds uid among the system's audio devices, returning nil
+// (not an error) if uid is "" or the lookup fails - both mean "let the
+// engine pick a device itself", which NegotiateSpec already treats as
+// nothing to negotiate against.
+func lookupDevice(uid string) *devices.AudioDevice {
+	if uid == "" {
+		return nil
+	}
+	all, err := devices.GetAudio()
+	if err != nil {
+		return nil
+	}
+	return all.ByUID(uid)
+}
+
+// InputStream captures audio from a session-selected input device and
+// delivers it block by block to a Go callback - the capture counterpart
+// OutputStream provides for playback. Modeled on cpal's Device/Stream
+// split: a device can expose any number of input or output streams, each
+// opened independently of the other direction's device.
+//
+// Built on avaudio/engine's NodeStream (Engine.BuildInputStream), whose
+// native tap block isn't wired up in this tree yet (see BuildInputStream's
+// doc comment) - InputStream opens and tears down cleanly, but cb is never
+// actually invoked until that native side exists.
+type InputStream struct {
+	mu        sync.Mutex
+	eng       *aveng.Engine
+	stream    *aveng.NodeStream
+	resolved  ResolvedSpec
+	resampler *audioformat.Resampler
+	srcFormat *audioformat.Format
+	dstFormat *audioformat.Format
+
+	// spec, deviceUID, and cb are OpenInputStream's original arguments,
+	// kept around so WatchDevice can reopen against a replacement device
+	// with the caller's original intent rather than whatever the stream
+	// happened to negotiate last time.
+	spec      AudioSpec
+	deviceUID string
+	cb        func(aveng.InputData)
+}
+
+// newStreamResampler builds a format.Resampler converting channelCount
+// channels from srcRate to dstRate, for wrapping a tap/fill callback so it
+// can work in AudioSpec.PreferredSampleRate terms even when the device's
+// negotiated rate (NegotiateSpec) came out different. Returns nil, nil,
+// nil, nil if the rates already match - nothing to wrap.
+func newStreamResampler(srcRate, dstRate float64, channelCount int) (*audioformat.Resampler, *audioformat.Format, *audioformat.Format, error) {
+	if srcRate == dstRate {
+		return nil, nil, nil, nil
+	}
+
+	src, err := audioformat.NewWithChannels(srcRate, channelCount, false)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("resampler source format: %w", err)
+	}
+	dst, err := audioformat.NewWithChannels(dstRate, channelCount, false)
+	if err != nil {
+		src.Destroy()
+		return nil, nil, nil, fmt.Errorf("resampler destination format: %w", err)
+	}
+	r, err := audioformat.New(src, dst)
+	if err != nil {
+		src.Destroy()
+		dst.Destroy()
+		return nil, nil, nil, fmt.Errorf("build resampler: %w", err)
+	}
+	return r, src, dst, nil
+}
+
+// OpenInputStream opens a dedicated engine bound to inputDeviceUID (or the
+// system default input if ""), independent of whatever output device the
+// rest of the session is using, and installs a capture tap on its input
+// node. The tap's format matches the input node's own hardware format -
+// queried via Engine.GetNodeOutputFormat(inputNode, 0), since AVAudioEngine
+// reports a node's native format as its output format regardless of the
+// node's own direction - rather than a format session chooses itself.
+//
+// spec's PreferredSampleRate/BufferSize are negotiated against
+// inputDeviceUID's actual supported configuration (NegotiateSpec) before
+// the engine opens, so an unsupported combination gets clamped to the
+// nearest one CoreAudio will accept rather than failing NewWithDevice.
+//
+// If the negotiated rate ends up different from spec.PreferredSampleRate,
+// OpenInputStream inserts a format.Resampler between the tap and cb so cb
+// still receives blocks at PreferredSampleRate - a caller written against
+// one target rate doesn't need to special-case a device locked to another.
+func OpenInputStream(spec AudioSpec, inputDeviceUID string, cb func(aveng.InputData)) (*InputStream, error) {
+	s := &InputStream{}
+	if err := s.open(spec, inputDeviceUID, cb); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open builds s's engine, tap, and (if needed) resampler against
+// deviceUID, and records spec/deviceUID/cb for WatchDevice to replay later.
+// It's factored out of OpenInputStream so WatchDevice can rebuild s in
+// place after the bound device disappears, rather than handing the caller
+// a brand new *InputStream it would have to swap in itself.
+func (s *InputStream) open(spec AudioSpec, deviceUID string, cb func(aveng.InputData)) error {
+	spec.Direction = DirectionInput
+	resolved := NegotiateSpec(spec, lookupDevice(deviceUID), DirectionInput)
+
+	eng, err := aveng.NewWithDevice(resolved.AudioSpec, "", deviceUID)
+	if err != nil {
+		return fmt.Errorf("open input engine: %w", err)
+	}
+
+	inputNode, err := eng.InputNode()
+	if err != nil {
+		eng.Destroy()
+		return fmt.Errorf("get input node: %w", err)
+	}
+
+	hwFormat, err := eng.GetNodeOutputFormat(inputNode, 0)
+	if err != nil {
+		eng.Destroy()
+		return fmt.Errorf("get hardware input format: %w", err)
+	}
+
+	s.eng = eng
+	s.resolved = resolved
+	s.spec = spec
+	s.deviceUID = deviceUID
+	s.cb = cb
+
+	deliver := cb
+	if spec.PreferredSampleRate > 0 {
+		resampler, src, dst, rerr := newStreamResampler(resolved.SampleRate, spec.PreferredSampleRate, resolved.ChannelCount)
+		if rerr != nil {
+			eng.Destroy()
+			return fmt.Errorf("set up input resampler: %w", rerr)
+		}
+		if resampler != nil {
+			s.resampler, s.srcFormat, s.dstFormat = resampler, src, dst
+			deliver = func(d aveng.InputData) {
+				out, cerr := resampler.Convert(d.Float32(), d.FrameCount())
+				if cerr != nil {
+					return
+				}
+				cb(aveng.NewInputData(out, len(out), d.HostTime(), d.SampleTime()))
+			}
+		}
+	}
+
+	stream, err := eng.BuildInputStream(inputNode, 0, hwFormat, deliver)
+	if err != nil {
+		// s.eng (and, if the resampler branch above ran, s.resampler/
+		// s.srcFormat/s.dstFormat) are already populated at this point, so a
+		// bare eng.Destroy() here would leak the resampler/format natives
+		// and leave s.eng pointing at an already-destroyed engine for the
+		// next closeResources() call to double-destroy. Tear down through
+		// closeResources so partial state from this failed open() can't be
+		// leaked or torn down twice.
+		s.closeResources()
+		return fmt.Errorf("build input stream: %w", err)
+	}
+	s.stream = stream
+
+	return nil
+}
+
+// closeResources releases whatever open built, without touching
+// spec/deviceUID/cb - the bookkeeping rebind needs to try again. Fields are
+// nilled as they're released and guarded against already being nil, since
+// open() can call this on a partially-built InputStream (e.g. BuildInputStream
+// failed before s.stream was ever assigned).
+func (s *InputStream) closeResources() error {
+	var err error
+	if s.stream != nil {
+		err = s.stream.Close()
+		s.stream = nil
+	}
+	if s.eng != nil {
+		s.eng.Destroy()
+		s.eng = nil
+	}
+	if s.resampler != nil {
+		s.resampler.Destroy()
+		s.srcFormat.Destroy()
+		s.dstFormat.Destroy()
+		s.resampler, s.srcFormat, s.dstFormat = nil, nil, nil
+	}
+	return err
+}
+
+// Start begins delivering captured blocks to OpenInputStream's callback.
+func (s *InputStream) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stream.Start()
+}
+
+// Stop halts delivery; the stream can be Start()ed again afterward.
+func (s *InputStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stream.Stop()
+}
+
+// Close stops the stream if running and releases its tap and engine. The
+// InputStream must not be used after Close returns.
+func (s *InputStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeResources()
+}
+
+// Stats returns the stream's running delivery counters.
+func (s *InputStream) Stats() aveng.StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stream.Stats()
+}
+
+// ResolvedSpec reports what NegotiateSpec actually resolved inputDeviceUID
+// to, including whether anything had to be adjusted away from the caller's
+// AudioSpec.
+func (s *InputStream) ResolvedSpec() ResolvedSpec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resolved
+}
+
+// WatchDevice subscribes s to devices.Subscribe and automatically rebinds
+// it to the system default input device if the one it's bound to
+// disappears (DeviceRemoved) or goes offline (DeviceAliveChanged) - the
+// raw-engine equivalent of engine.Engine.WatchDevices' Disconnected ->
+// RebindDevice cycle, adapted to a standalone InputStream rather than a
+// graph Channel since InputStream has no engine.Channel to reuse that
+// machinery through. Rebinding always falls back to "" (system default
+// input) rather than trying to name a specific replacement, same as
+// engine.rebindDisconnectedChannels does for capture channels.
+//
+// Opt-in: a caller that doesn't call WatchDevice gets today's behavior -
+// the stream silently stops delivering once its device is gone. Rebind
+// failures (e.g. the fallback default device rejects s's original spec)
+// are reported on the returned channel instead of panicking the watch
+// goroutine; the stream is left closed in that case. Call stop to
+// unsubscribe, e.g. alongside Close.
+func (s *InputStream) WatchDevice() (onRebindError <-chan error, stop func()) {
+	ch, cancel := devices.Subscribe()
+	errs := make(chan error, 8)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for ev := range ch {
+			var lost bool
+			switch ev.Kind {
+			case devices.DeviceRemoved:
+				lost = ev.Audio != nil && ev.Audio.UID == s.boundDeviceUID()
+			case devices.DeviceAliveChanged:
+				lost = ev.Audio != nil && !ev.Audio.IsOnline && ev.Audio.UID == s.boundDeviceUID()
+			}
+			if !lost {
+				continue
+			}
+			if err := s.rebind(); err != nil {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+		}
+	}()
+
+	return errs, func() {
+		cancel()
+		<-done
+	}
+}
+
+// boundDeviceUID reports the device UID s is currently bound to.
+func (s *InputStream) boundDeviceUID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deviceUID
+}
+
+// rebind tears down s's current engine/tap and reopens against the system
+// default input device, reusing the original AudioSpec and callback from
+// the last successful open (WatchDevice's initial call or a prior rebind).
+func (s *InputStream) rebind() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spec, cb := s.spec, s.cb
+	_ = s.closeResources()
+
+	return s.open(spec, "", cb)
+}
+
+// OutputStream generates audio for a session-selected output device from a
+// Go callback that fills each block ahead of the render thread needing it -
+// see InputStream for the capture direction.
+type OutputStream struct {
+	eng      *aveng.Engine
+	stream   *aveng.NodeStream
+	resolved ResolvedSpec
+}
+
+// OpenOutputStream opens a dedicated engine bound to outputDeviceUID (or
+// the system default output if ""), independent of whatever input device
+// the rest of the session is using, and installs cb as a source feeding the
+// engine's main mixer at bus 0, formatted to match the engine's own spec
+// (Engine.GetEngineFormat).
+//
+// spec's PreferredSampleRate/BufferSize are negotiated against
+// outputDeviceUID's actual supported configuration (NegotiateSpec) first,
+// same as OpenInputStream.
+//
+// Unlike OpenInputStream, a negotiated-rate mismatch here is not resampled
+// automatically: cb fills a fixed-size buffer the render thread is about to
+// consume immediately, so feeding it through a format.Resampler cleanly
+// needs a FIFO in between sized independently of the HAL's own block size -
+// exactly what the ring-buffer buffer manager decouples. Until that lands,
+// a caller whose PreferredSampleRate doesn't match the device should treat
+// ResolvedSpec.Adjusted as a signal to regenerate at the negotiated rate
+// itself.
+func OpenOutputStream(spec AudioSpec, outputDeviceUID string, cb func(aveng.OutputData)) (*OutputStream, error) {
+	spec.Direction = DirectionOutput
+	resolved := NegotiateSpec(spec, lookupDevice(outputDeviceUID), DirectionOutput)
+
+	eng, err := aveng.NewWithDevice(resolved.AudioSpec, outputDeviceUID, "")
+	if err != nil {
+		return nil, fmt.Errorf("open output engine: %w", err)
+	}
+
+	mainMixer, err := eng.MainMixerNode()
+	if err != nil {
+		eng.Destroy()
+		return nil, fmt.Errorf("get main mixer node: %w", err)
+	}
+
+	format, err := eng.GetEngineFormat()
+	if err != nil {
+		eng.Destroy()
+		return nil, fmt.Errorf("get engine format: %w", err)
+	}
+
+	stream, err := eng.BuildOutputStream(mainMixer, 0, format, cb)
+	if err != nil {
+		eng.Destroy()
+		return nil, fmt.Errorf("build output stream: %w", err)
+	}
+
+	return &OutputStream{eng: eng, stream: stream, resolved: resolved}, nil
+}
+
+// Start begins calling OpenOutputStream's callback to fill blocks.
+func (s *OutputStream) Start() error { return s.stream.Start() }
+
+// Stop halts delivery; the stream can be Start()ed again afterward.
+func (s *OutputStream) Stop() error { return s.stream.Stop() }
+
+// Close stops the stream if running and releases its source node and
+// engine. The OutputStream must not be used after Close returns.
+func (s *OutputStream) Close() error {
+	err := s.stream.Close()
+	s.eng.Destroy()
+	return err
+}
+
+// Stats returns the stream's running delivery counters.
+func (s *OutputStream) Stats() aveng.StreamStats { return s.stream.Stats() }
+
+// ResolvedSpec reports what NegotiateSpec actually resolved outputDeviceUID
+// to, including whether anything had to be adjusted away from the caller's
+// AudioSpec - see OpenOutputStream's doc comment on why a rate mismatch
+// isn't resampled automatically the way OpenInputStream's is.
+func (s *OutputStream) ResolvedSpec() ResolvedSpec { return s.resolved }
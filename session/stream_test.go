@@ -0,0 +1,185 @@
+//go:build darwin
+
+package session
+
+import (
+	"testing"
+
+	aveng "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/devices"
+)
+
+func TestMapLatencyToCaptureBufferIsHalfOfPlaybackFlooredAt64(t *testing.T) {
+	cases := []struct {
+		class LatencyClass
+		want  int
+	}{
+		{LatencyLow, 64},
+		{LatencyMedium, 128},
+		{LatencyHigh, 512},
+	}
+
+	for _, c := range cases {
+		if got := MapLatencyToCaptureBuffer(c.class); got != c.want {
+			t.Errorf("MapLatencyToCaptureBuffer(%v) = %d, want %d", c.class, got, c.want)
+		}
+	}
+}
+
+func TestResolveEngineSpecPicksBufferMappingByDirection(t *testing.T) {
+	spec := AudioSpec{LatencyHint: LatencyHigh}
+
+	spec.Direction = DirectionOutput
+	out := ResolveEngineSpec(spec)
+	if out.BufferSize != MapLatencyToBuffer(LatencyHigh) {
+		t.Errorf("output direction: BufferSize = %d, want %d", out.BufferSize, MapLatencyToBuffer(LatencyHigh))
+	}
+
+	spec.Direction = DirectionInput
+	in := ResolveEngineSpec(spec)
+	if in.BufferSize != MapLatencyToCaptureBuffer(LatencyHigh) {
+		t.Errorf("input direction: BufferSize = %d, want %d", in.BufferSize, MapLatencyToCaptureBuffer(LatencyHigh))
+	}
+
+	spec.BufferSize = 777
+	if got := ResolveEngineSpec(spec).BufferSize; got != 777 {
+		t.Errorf("explicit BufferSize should override direction mapping, got %d", got)
+	}
+}
+
+func TestNegotiateSpecClampsToDeviceSupportedConfig(t *testing.T) {
+	dev := &devices.AudioDevice{
+		SupportedSampleRates:     []int{44100, 48000},
+		InputChannelCount:        2,
+		MinBufferFrameSize:       64,
+		MaxBufferFrameSize:       1024,
+		PreferredBufferFrameSize: 256,
+	}
+
+	spec := AudioSpec{PreferredSampleRate: 192000, BufferSize: 2048}
+	resolved := NegotiateSpec(spec, dev, DirectionInput)
+
+	if !resolved.Adjusted {
+		t.Error("expected Adjusted=true for an out-of-range rate and buffer size")
+	}
+	if resolved.SampleRate != 48000 {
+		t.Errorf("expected SampleRate clamped to 48000, got %v", resolved.SampleRate)
+	}
+	if resolved.BufferSize != 1024 {
+		t.Errorf("expected BufferSize clamped to 1024, got %d", resolved.BufferSize)
+	}
+}
+
+func TestNegotiateSpecWithNilDeviceIsUnadjusted(t *testing.T) {
+	resolved := NegotiateSpec(AudioSpec{PreferredSampleRate: 192000}, nil, DirectionOutput)
+	if resolved.Adjusted {
+		t.Error("expected Adjusted=false when there's no device to negotiate against")
+	}
+	if resolved.SampleRate != 192000 {
+		t.Errorf("expected SampleRate to pass through unchanged, got %v", resolved.SampleRate)
+	}
+}
+
+func TestOpenInputStreamOpensAndClosesCleanly(t *testing.T) {
+	stream, err := OpenInputStream(AudioSpec{}, "", func(aveng.InputData) {})
+	if err != nil {
+		t.Fatalf("OpenInputStream: %v", err)
+	}
+
+	if err := stream.Start(); err != nil {
+		t.Errorf("Start: %v", err)
+	}
+	if err := stream.Stop(); err != nil {
+		t.Errorf("Stop: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestNewStreamResamplerNoopWhenRatesMatch(t *testing.T) {
+	r, src, dst, err := newStreamResampler(48000, 48000, 2)
+	if err != nil {
+		t.Fatalf("newStreamResampler: %v", err)
+	}
+	if r != nil || src != nil || dst != nil {
+		t.Error("expected no resampler to be built when src and dst rates already match")
+	}
+}
+
+func TestNewStreamResamplerBuildsOnRateMismatch(t *testing.T) {
+	r, src, dst, err := newStreamResampler(44100, 48000, 2)
+	if err != nil {
+		t.Fatalf("newStreamResampler: %v", err)
+	}
+	if r == nil || src == nil || dst == nil {
+		t.Fatal("expected a resampler and its source/destination formats to be built for mismatched rates")
+	}
+	defer func() {
+		r.Destroy()
+		src.Destroy()
+		dst.Destroy()
+	}()
+}
+
+func TestInputStreamRebindReopensAgainstDefaultDevice(t *testing.T) {
+	stream, err := OpenInputStream(AudioSpec{}, "", func(aveng.InputData) {})
+	if err != nil {
+		t.Fatalf("OpenInputStream: %v", err)
+	}
+	defer stream.Close()
+
+	if got := stream.boundDeviceUID(); got != "" {
+		t.Errorf("expected boundDeviceUID to be \"\" after opening with the default device, got %q", got)
+	}
+
+	if err := stream.rebind(); err != nil {
+		t.Fatalf("rebind: %v", err)
+	}
+	if err := stream.Start(); err != nil {
+		t.Errorf("Start after rebind: %v", err)
+	}
+	if err := stream.Stop(); err != nil {
+		t.Errorf("Stop after rebind: %v", err)
+	}
+}
+
+func TestInputStreamCloseResourcesIsNilSafeOnPartialState(t *testing.T) {
+	// open() can call closeResources on a partially-built InputStream - e.g.
+	// BuildInputStream failing leaves s.stream nil while s.eng (and maybe
+	// s.resampler/s.srcFormat/s.dstFormat) are already set. The zero-value
+	// case here is the most partial state of all: nothing should panic or
+	// error just because a field was never assigned.
+	s := &InputStream{}
+	if err := s.closeResources(); err != nil {
+		t.Errorf("closeResources on a zero-value InputStream: %v", err)
+	}
+}
+
+func TestInputStreamWatchDeviceStopUnsubscribesCleanly(t *testing.T) {
+	stream, err := OpenInputStream(AudioSpec{}, "", func(aveng.InputData) {})
+	if err != nil {
+		t.Fatalf("OpenInputStream: %v", err)
+	}
+	defer stream.Close()
+
+	_, stop := stream.WatchDevice()
+	stop()
+}
+
+func TestOpenOutputStreamOpensAndClosesCleanly(t *testing.T) {
+	stream, err := OpenOutputStream(AudioSpec{}, "", func(aveng.OutputData) {})
+	if err != nil {
+		t.Fatalf("OpenOutputStream: %v", err)
+	}
+
+	if err := stream.Start(); err != nil {
+		t.Errorf("Start: %v", err)
+	}
+	if err := stream.Stop(); err != nil {
+		t.Errorf("Stop: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+//go:build darwin
+
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultDropDeadline is Options.SubscriptionDropDeadline's default: how
+// long Subscribe lets a subscriber's channel sit full before evicting it.
+const defaultDropDeadline = 2 * time.Second
+
+// subscriberBuffer sizes every Subscribe channel.
+const subscriberBuffer = 32
+
+// SubscribeFilter narrows which events a Subscribe call receives and how
+// bursts of them are delivered. The zero value matches every device change,
+// delivers no plugin events, and applies no coalescing.
+type SubscribeFilter struct {
+	// Types restricts delivery to these ChangeTypes; nil/empty matches all.
+	Types []ChangeType
+	// IncludePluginEvents additionally delivers PluginScanEvent values
+	// published after a plugin scan finishes; see doFullPluginScan,
+	// updatePluginCache, handlePluginRequest's cache-hit path, and
+	// RefreshQuick.
+	IncludePluginEvents bool
+	// MinInterval, combined with Coalesce, collapses a burst of same-Type
+	// device change events arriving within MinInterval of each other into a
+	// single delivery of the latest one - e.g. several device-add
+	// notifications in quick succession collapse to one.
+	MinInterval time.Duration
+	// Coalesce enables MinInterval-based collapsing; ignored if
+	// MinInterval <= 0.
+	Coalesce bool
+}
+
+func (f SubscribeFilter) matches(ct ChangeType) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionEvent is implemented by every event Session.Subscribe delivers.
+// Separate typed structs rather than one struct with a Kind enum, matching
+// engine.EngineEvent and channel.BusEvent.
+type SessionEvent interface{ isSessionEvent() }
+
+// DeviceChangeEvent wraps a DeviceChange for delivery through Subscribe,
+// published by the same notifyChange call that feeds DeviceChanges() and
+// OnDeviceChange.
+type DeviceChangeEvent struct{ DeviceChange }
+
+func (DeviceChangeEvent) isSessionEvent() {}
+
+// PluginScanEvent is published after a plugin scan finishes, mirroring the
+// ScanEventPayload audit payload.
+type PluginScanEvent struct {
+	CacheHit     bool
+	ScanTime     time.Duration
+	ChangedCount int
+	Timestamp    time.Time
+}
+
+func (PluginScanEvent) isSessionEvent() {}
+
+// Subscription is returned by Session.Subscribe. Events delivers matching
+// SessionEvents; Unsubscribe stops delivery, releases the subscriber's slot,
+// and closes the channel.
+type Subscription struct {
+	Events <-chan SessionEvent
+	cancel CancelFunc
+}
+
+// Unsubscribe stops delivery to this Subscription and closes its channel.
+// Safe to call more than once.
+func (sub Subscription) Unsubscribe() { sub.cancel() }
+
+// subscriber is one Subscribe registration. lastSent/pending track
+// per-ChangeType coalescing state (see SubscribeFilter.Coalesce);
+// fullSince tracks how long ch has been observed full, for eviction.
+type subscriber struct {
+	mu        sync.Mutex
+	id        int
+	ch        chan SessionEvent
+	filter    SubscribeFilter
+	lastSent  map[ChangeType]time.Time
+	pending   map[ChangeType]*time.Timer
+	fullSince time.Time
+	closeOnce sync.Once
+}
+
+func (sub *subscriber) close() {
+	sub.closeOnce.Do(func() {
+		sub.mu.Lock()
+		for _, t := range sub.pending {
+			t.Stop()
+		}
+		sub.mu.Unlock()
+		close(sub.ch)
+	})
+}
+
+// eventHub holds Session.Subscribe's subscriber registry and implements its
+// coalescing/backpressure policy. It's zero-value usable - subs is
+// allocated lazily on the first Subscribe call - the same pattern
+// engine.engineEventBus uses, so Session doesn't need to initialize it in
+// newSession.
+type eventHub struct {
+	mu           sync.Mutex
+	nextID       int
+	subs         map[int]*subscriber
+	dropDeadline time.Duration
+}
+
+// Subscribe registers for device-change and (optionally) plugin-scan
+// events matching filter. Unlike DeviceChanges()'s single shared channel,
+// every Subscribe call gets its own buffered channel and its own
+// back-pressure handling: a burst within filter.MinInterval is coalesced to
+// its latest value when filter.Coalesce is set, and a subscriber whose
+// channel stays full past the hub's drop deadline (see
+// Options.SubscriptionDropDeadline) is evicted - closed and unregistered -
+// instead of notifyChange's blunt 1ms timeout-and-drop. Eviction and lag
+// are reported through MetricsHook.OnSubscriptionEvicted/OnSubscriptionLag.
+func (s *Session) Subscribe(filter SubscribeFilter) (Subscription, error) {
+	sub := &subscriber{
+		ch:       make(chan SessionEvent, subscriberBuffer),
+		filter:   filter,
+		lastSent: map[ChangeType]time.Time{},
+		pending:  map[ChangeType]*time.Timer{},
+	}
+
+	s.hub.mu.Lock()
+	if s.hub.subs == nil {
+		s.hub.subs = make(map[int]*subscriber)
+	}
+	if s.hub.dropDeadline <= 0 {
+		s.hub.dropDeadline = defaultDropDeadline
+	}
+	sub.id = s.hub.nextID
+	s.hub.nextID++
+	s.hub.subs[sub.id] = sub
+	s.hub.mu.Unlock()
+
+	cancel := func() {
+		s.hub.mu.Lock()
+		if cur, ok := s.hub.subs[sub.id]; ok && cur == sub {
+			delete(s.hub.subs, sub.id)
+		}
+		s.hub.mu.Unlock()
+		sub.close()
+	}
+	return Subscription{Events: sub.ch, cancel: cancel}, nil
+}
+
+// publishDeviceChange fans change out to every Subscribe subscriber whose
+// filter matches change.Type. Called from notifyChange, independent of the
+// DeviceChanges channel and OnDeviceChange callbacks.
+func (s *Session) publishDeviceChange(change DeviceChange) {
+	ev := DeviceChangeEvent{change}
+	subs, deadline := s.matchingSubscribers(func(f SubscribeFilter) bool { return f.matches(change.Type) })
+	for _, sub := range subs {
+		s.deliver(sub, ev, change.Type, deadline)
+	}
+}
+
+// publishPluginScan fans ev out to every Subscribe subscriber that opted
+// into IncludePluginEvents. Plugin scan events aren't coalesced by
+// ChangeType - MinInterval/Coalesce only debounce device-change bursts.
+func (s *Session) publishPluginScan(ev PluginScanEvent) {
+	subs, deadline := s.matchingSubscribers(func(f SubscribeFilter) bool { return f.IncludePluginEvents })
+	for _, sub := range subs {
+		s.send(sub, ev, deadline)
+	}
+}
+
+func (s *Session) matchingSubscribers(match func(SubscribeFilter) bool) ([]*subscriber, time.Duration) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	deadline := s.hub.dropDeadline
+	if deadline <= 0 {
+		deadline = defaultDropDeadline
+	}
+	subs := make([]*subscriber, 0, len(s.hub.subs))
+	for _, sub := range s.hub.subs {
+		if match(sub.filter) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, deadline
+}
+
+// deliver applies MinInterval/Coalesce debouncing for ct before handing ev
+// to send. A coalesced event arriving inside the window replaces any
+// already-pending timer for the same ChangeType rather than queuing both.
+func (s *Session) deliver(sub *subscriber, ev SessionEvent, ct ChangeType, deadline time.Duration) {
+	if sub.filter.Coalesce && sub.filter.MinInterval > 0 {
+		sub.mu.Lock()
+		if last, ok := sub.lastSent[ct]; ok {
+			if wait := sub.filter.MinInterval - time.Since(last); wait > 0 {
+				if t, pending := sub.pending[ct]; pending {
+					t.Stop()
+				}
+				sub.pending[ct] = time.AfterFunc(wait, func() {
+					sub.mu.Lock()
+					delete(sub.pending, ct)
+					sub.lastSent[ct] = time.Now()
+					sub.mu.Unlock()
+					s.send(sub, ev, deadline)
+				})
+				sub.mu.Unlock()
+				return
+			}
+		}
+		sub.lastSent[ct] = time.Now()
+		sub.mu.Unlock()
+	}
+	s.send(sub, ev, deadline)
+}
+
+// send delivers ev non-blocking. A full channel is recorded as lag
+// (reported via MetricsHook.OnSubscriptionLag) and, once it's been full for
+// longer than deadline, the subscriber is evicted rather than left backing
+// up the publisher indefinitely.
+func (s *Session) send(sub *subscriber, ev SessionEvent, deadline time.Duration) {
+	sub.mu.Lock()
+	select {
+	case sub.ch <- ev:
+		sub.fullSince = time.Time{}
+		sub.mu.Unlock()
+		return
+	default:
+	}
+	if sub.fullSince.IsZero() {
+		sub.fullSince = time.Now()
+	}
+	evict := time.Since(sub.fullSince) > deadline
+	queueLen := len(sub.ch)
+	sub.mu.Unlock()
+
+	if s.hook != nil {
+		s.hook.OnSubscriptionLag(sub.id, queueLen)
+	}
+	if evict {
+		s.evictSubscriber(sub.id)
+	}
+}
+
+// evictSubscriber drops a subscriber that stayed full past the hub's drop
+// deadline: unregisters it so future publishes skip it, then closes its
+// channel.
+func (s *Session) evictSubscriber(id int) {
+	s.hub.mu.Lock()
+	sub, ok := s.hub.subs[id]
+	if ok {
+		delete(s.hub.subs, id)
+	}
+	s.hub.mu.Unlock()
+	if !ok {
+		return
+	}
+	sub.close()
+	if s.hook != nil {
+		s.hook.OnSubscriptionEvicted(id)
+	}
+}
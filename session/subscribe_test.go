@@ -0,0 +1,178 @@
+//go:build darwin
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaban/macaudio/devices"
+)
+
+func TestSubscribeFiltersByChangeType(t *testing.T) {
+	sess := &Session{}
+	sub, err := sess.Subscribe(SubscribeFilter{Types: []ChangeType{AudioDeviceChange}})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	sess.publishDeviceChange(DeviceChange{Type: MIDIDeviceChange})
+	sess.publishDeviceChange(DeviceChange{Type: AudioDeviceChange})
+
+	select {
+	case ev := <-sub.Events:
+		dc, ok := ev.(DeviceChangeEvent)
+		if !ok || dc.Type != AudioDeviceChange {
+			t.Fatalf("got %#v, want an AudioDeviceChange event", ev)
+		}
+	default:
+		t.Fatal("expected the matching AudioDeviceChange event to be delivered")
+	}
+
+	select {
+	case ev := <-sub.Events:
+		t.Fatalf("unexpected second event %#v; MIDIDeviceChange should have been filtered out", ev)
+	default:
+	}
+}
+
+func TestSubscribeIncludesPluginEventsOnlyWhenOptedIn(t *testing.T) {
+	sess := &Session{}
+	sub, err := sess.Subscribe(SubscribeFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	sess.publishPluginScan(PluginScanEvent{ChangedCount: 3})
+
+	select {
+	case ev := <-sub.Events:
+		t.Fatalf("unexpected plugin event %#v without IncludePluginEvents", ev)
+	default:
+	}
+
+	pluginSub, err := sess.Subscribe(SubscribeFilter{IncludePluginEvents: true})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer pluginSub.Unsubscribe()
+
+	sess.publishPluginScan(PluginScanEvent{ChangedCount: 5})
+
+	select {
+	case ev := <-pluginSub.Events:
+		ps, ok := ev.(PluginScanEvent)
+		if !ok || ps.ChangedCount != 5 {
+			t.Fatalf("got %#v, want a PluginScanEvent with ChangedCount=5", ev)
+		}
+	default:
+		t.Fatal("expected the plugin scan event to be delivered")
+	}
+}
+
+func TestSubscribeCoalescesBurstsWithinMinInterval(t *testing.T) {
+	sess := &Session{}
+	sub, err := sess.Subscribe(SubscribeFilter{Coalesce: true, MinInterval: 30 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	sess.publishDeviceChange(DeviceChange{Type: AudioDeviceChange, AudioCount: 1})
+	sess.publishDeviceChange(DeviceChange{Type: AudioDeviceChange, AudioCount: 2})
+	sess.publishDeviceChange(DeviceChange{Type: AudioDeviceChange, AudioCount: 3})
+
+	select {
+	case ev := <-sub.Events:
+		dc := ev.(DeviceChangeEvent)
+		if dc.AudioCount != 1 {
+			t.Fatalf("first delivery AudioCount = %d, want 1 (the one outside the coalescing window)", dc.AudioCount)
+		}
+	default:
+		t.Fatal("expected the first event to be delivered immediately")
+	}
+
+	select {
+	case ev := <-sub.Events:
+		t.Fatalf("unexpected immediate second delivery %#v; the burst should still be coalescing", ev)
+	default:
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	select {
+	case ev := <-sub.Events:
+		dc := ev.(DeviceChangeEvent)
+		if dc.AudioCount != 3 {
+			t.Fatalf("coalesced delivery AudioCount = %d, want 3 (the latest of the burst)", dc.AudioCount)
+		}
+	default:
+		t.Fatal("expected the coalesced burst to flush after MinInterval")
+	}
+}
+
+func TestSubscribeEvictsSlowSubscriberPastDropDeadline(t *testing.T) {
+	var evicted []int
+	sess := &Session{}
+	sess.hub.dropDeadline = 10 * time.Millisecond
+	sess.SetMetricsHook(&evictRecorder{evicted: &evicted})
+
+	sub, err := sess.Subscribe(SubscribeFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		sess.publishPluginScan(PluginScanEvent{ChangedCount: i})
+	}
+	time.Sleep(20 * time.Millisecond)
+	sess.publishPluginScan(PluginScanEvent{ChangedCount: 999})
+
+	deadline := time.After(time.Second)
+	closed := false
+	for !closed {
+		select {
+		case _, ok := <-sub.Events:
+			if !ok {
+				closed = true
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for the evicted subscriber's channel to close")
+		}
+	}
+	if len(evicted) == 0 {
+		t.Fatal("expected the slow subscriber to be evicted once its channel stayed full past the drop deadline")
+	}
+}
+
+type evictRecorder struct {
+	nopMetricsHook
+	evicted *[]int
+}
+
+func (r *evictRecorder) OnSubscriptionEvicted(id int) { *r.evicted = append(*r.evicted, id) }
+
+// nopMetricsHook implements MetricsHook with no-ops, embeddable by tests
+// that only care about overriding one or two callbacks.
+type nopMetricsHook struct{}
+
+func (nopMetricsHook) OnQuickScanStart()                              {}
+func (nopMetricsHook) OnQuickScanDone(time.Duration, int, bool)        {}
+func (nopMetricsHook) OnDetailsFetchStart(string)                     {}
+func (nopMetricsHook) OnDetailsFetchDone(string, time.Duration, bool) {}
+func (nopMetricsHook) OnCacheHit(string)                              {}
+func (nopMetricsHook) OnCacheMiss(string)                             {}
+func (nopMetricsHook) OnRefreshQuickDiff(int, int, int, time.Duration) {}
+func (nopMetricsHook) OnWarmProgress(int, int)                        {}
+func (nopMetricsHook) OnWarmupProgress(int, int, int, int, time.Duration) {}
+func (nopMetricsHook) OnAutoWarmTick(int, int, time.Duration)         {}
+func (nopMetricsHook) OnSubscriptionLag(int, int)                     {}
+func (nopMetricsHook) OnSubscriptionEvicted(int)                      {}
+func (nopMetricsHook) OnLoudnessProgress(int, int)                    {}
+func (nopMetricsHook) OnCacheMigration(string, string, string, int)   {}
+func (nopMetricsHook) OnCacheQuarantine(string, string)               {}
+func (nopMetricsHook) OnDeviceAdded(string)                           {}
+func (nopMetricsHook) OnDeviceRemoved(string)                         {}
+func (nopMetricsHook) OnDefaultDeviceChanged(devices.DefaultDeviceKind, string) {}
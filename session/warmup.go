@@ -0,0 +1,161 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WarmupOptions configures StartWarmup.
+type WarmupOptions struct {
+	// Concurrency bounds how many PluginCtx introspections run at once;
+	// <=0 falls back to WarmCtx's own default of 2.
+	Concurrency int
+	// RatePerSecond caps how many introspections StartWarmup *starts* per
+	// second, independent of Concurrency - e.g. Concurrency=8,
+	// RatePerSecond=2 keeps a cold-startup warm-up from pegging every core
+	// even with plenty of worker slots free. <=0 means unlimited.
+	RatePerSecond float64
+	// Priority, if set, orders which indexFile.Entries are introspected
+	// first: entries are sorted by descending Priority(entry) before the
+	// worker pool starts pulling from the pending set. nil processes
+	// entries in whatever order indexFile.Entries - a map - happens to
+	// range over.
+	Priority func(indexEntry) int
+}
+
+// WarmupStats summarizes a StartWarmup pass: Total/Done track the pending
+// set (entries whose cached details were already current don't count
+// against either), CacheHits is how many entries were skipped that way,
+// and Failures is how many introspections in the pending set errored.
+type WarmupStats struct {
+	Total     int
+	Done      int
+	CacheHits int
+	Failures  int
+}
+
+// StartWarmup introspects every indexFile.Entries key whose cached details
+// checksum doesn't already match checksumQuick, via a Concurrency-bounded,
+// optionally RatePerSecond-limited worker pool, ordered by opts.Priority if
+// set. Each introspection goes through PluginCtx, so it shares
+// joinInFlightCtx's single-flight dedupe with any concurrent PluginCtx
+// caller on the same key instead of racing it, and a quarantined key (see
+// PluginCtx) is skipped exactly as WarmCtx skips it. ctx cancellation stops
+// new introspections from starting; ones already running finish before
+// StartWarmup returns. Progress is reported incrementally through
+// MetricsHook.OnWarmupProgress as the pass proceeds.
+func (s *Session) StartWarmup(ctx context.Context, opts WarmupOptions) (WarmupStats, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 2
+	}
+
+	s.idxMu.RLock()
+	idx := s.idxSnap
+	s.idxMu.RUnlock()
+	if idx == nil {
+		return WarmupStats{}, nil
+	}
+
+	entries := make([]indexEntry, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		entries = append(entries, e)
+	}
+	if opts.Priority != nil {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return opts.Priority(entries[i]) > opts.Priority(entries[j])
+		})
+	}
+
+	var stats WarmupStats
+	pending := make([]indexEntry, 0, len(entries))
+	for _, e := range entries {
+		if s.isQuarantined(e.Key) {
+			continue
+		}
+		if _, chk, err := s.backend.GetDetails(e.Key); err == nil && chk == e.Checksum {
+			stats.CacheHits++
+			continue
+		}
+		pending = append(pending, e)
+	}
+	stats.Total = len(pending)
+
+	var limiter *time.Ticker
+	if opts.RatePerSecond > 0 {
+		limiter = time.NewTicker(time.Duration(float64(time.Second) / opts.RatePerSecond))
+		defer limiter.Stop()
+	}
+
+	if s.hook != nil {
+		s.hook.OnWarmupProgress(stats.Done, stats.Total, stats.CacheHits, stats.Failures, 0)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+pendingLoop:
+	for _, e := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		if limiter != nil {
+			select {
+			case <-limiter.C:
+			case <-ctx.Done():
+				break pendingLoop
+			}
+		}
+
+		e := e
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			t0 := time.Now()
+			_, err := s.PluginCtx(ctx, e.Type, e.Subtype, e.ManufacturerID, e.Name)
+			dur := time.Since(t0)
+
+			mu.Lock()
+			stats.Done++
+			if err != nil {
+				stats.Failures++
+			}
+			done, total, hits, failures := stats.Done, stats.Total, stats.CacheHits, stats.Failures
+			mu.Unlock()
+
+			if s.hook != nil {
+				s.hook.OnWarmupProgress(done, total, hits, failures, dur)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return stats, ctx.Err()
+}
+
+// Invalidate evicts key's cached details and removes its entry from the
+// index, under the same idxMu lock StartWarmup/PluginCtx use to read/write
+// idxSnap - so a warmup pass mid-read of the index can't observe a
+// half-removed entry. The next QuickPlugins/RefreshQuick re-adds the entry
+// if the plugin is still installed; the next PluginCtx call re-introspects
+// it from scratch rather than serving a stale cache hit.
+func (s *Session) Invalidate(key string) error {
+	if err := s.backend.DeleteDetails(key); err != nil {
+		return err
+	}
+	s.idxMu.Lock()
+	defer s.idxMu.Unlock()
+	if s.idxSnap != nil {
+		delete(s.idxSnap.Entries, key)
+		_ = s.backend.SaveIndex(s.idxSnap)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+//go:build darwin
+
+package session
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// warmupProgressRecorder is a nopMetricsHook that additionally captures
+// every OnWarmupProgress call, for tests that only care about that one
+// callback.
+type warmupProgressRecorder struct {
+	nopMetricsHook
+	calls chan struct{}
+}
+
+func (r *warmupProgressRecorder) OnWarmupProgress(done, total, cacheHits, failures int, dur time.Duration) {
+	select {
+	case r.calls <- struct{}{}:
+	default:
+	}
+}
+
+func TestStartWarmupSkipsCurrentEntriesAndReportsProgress(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	if _, err := sess.QuickPlugins(); err != nil {
+		t.Fatalf("QuickPlugins: %v", err)
+	}
+
+	recorder := &warmupProgressRecorder{calls: make(chan struct{}, 8)}
+	sess.SetMetricsHook(recorder)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	first, err := sess.StartWarmup(ctx, WarmupOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("StartWarmup: %v", err)
+	}
+
+	select {
+	case <-recorder.calls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected at least one OnWarmupProgress call")
+	}
+
+	// A second pass right after the first should find every entry already
+	// current and skip introspecting all of them.
+	second, err := sess.StartWarmup(ctx, WarmupOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("second StartWarmup: %v", err)
+	}
+	if second.Total != 0 {
+		t.Errorf("expected second pass to have nothing pending, got Total=%d", second.Total)
+	}
+	if second.CacheHits != first.Total+first.CacheHits {
+		t.Errorf("expected second pass CacheHits=%d (everything warmed by the first pass), got %d",
+			first.Total+first.CacheHits, second.CacheHits)
+	}
+}
+
+func TestInvalidateForcesReintrospection(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "macaudio-cache-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv("MACAUDIO_CACHE_DIR", tempDir)
+	defer os.Unsetenv("MACAUDIO_CACHE_DIR")
+
+	sess, err := NewSessionWithDefaults()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer sess.Close()
+
+	infos, err := sess.QuickPlugins()
+	if err != nil {
+		t.Fatalf("QuickPlugins: %v", err)
+	}
+	if len(infos) == 0 {
+		t.Skip("no plugins installed to test against")
+	}
+	key := quadKey(infos[0].Type, infos[0].Subtype, infos[0].ManufacturerID, infos[0].Name)
+
+	if _, err := sess.Plugin(infos[0].Type, infos[0].Subtype, infos[0].ManufacturerID, infos[0].Name); err != nil {
+		t.Fatalf("Plugin: %v", err)
+	}
+	if _, _, err := sess.backend.GetDetails(key); err != nil {
+		t.Fatalf("expected cached details before Invalidate, got error: %v", err)
+	}
+
+	if err := sess.Invalidate(key); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+
+	if _, _, err := sess.backend.GetDetails(key); err == nil {
+		t.Error("expected GetDetails to fail after Invalidate")
+	}
+	sess.idxMu.RLock()
+	_, stillIndexed := sess.idxSnap.Entries[key]
+	sess.idxMu.RUnlock()
+	if stillIndexed {
+		t.Error("expected Invalidate to remove the entry from idxSnap")
+	}
+}
@@ -0,0 +1,230 @@
+//go:build darwin
+
+// Package sessioncli implements the command-line surface cmd/macaudio-session
+// wraps around a live session.Session. It replaces the SESSION_INTERACTIVE
+// REPL/monitor that used to live directly in session's TestMain: a stable,
+// supported place for future interactive commands, built entirely on
+// session's exported subscription/preset/paging/audit APIs rather than
+// reaching into its internals.
+package sessioncli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/shaban/macaudio/session"
+)
+
+// CLIConfig configures one Run call. Args is the subcommand and its
+// arguments (not including a program name) - e.g. []string{"devices", "list"}.
+// Stdin/Stdout/Stderr default to os.Stdin/os.Stdout/os.Stderr when left nil.
+type CLIConfig struct {
+	Args   []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func (cfg *CLIConfig) setDefaults() {
+	if cfg.Stdin == nil {
+		cfg.Stdin = os.Stdin
+	}
+	if cfg.Stdout == nil {
+		cfg.Stdout = os.Stdout
+	}
+	if cfg.Stderr == nil {
+		cfg.Stderr = os.Stderr
+	}
+}
+
+// Run dispatches cfg.Args to one of the devices/plugins/presets/audit
+// subcommands against sess, writing results to cfg.Stdout/cfg.Stderr. It
+// returns once the subcommand completes (devices watch and audit tail run
+// until ctx is cancelled - callers typically derive ctx from
+// signal.NotifyContext for graceful shutdown).
+func Run(ctx context.Context, cfg CLIConfig, sess *session.Session) error {
+	cfg.setDefaults()
+	if len(cfg.Args) == 0 {
+		return fmt.Errorf("sessioncli: missing command (usage: devices|plugins|presets|audit ...)")
+	}
+
+	cmd, rest := cfg.Args[0], cfg.Args[1:]
+	switch cmd {
+	case "devices":
+		return runDevices(ctx, cfg, sess, rest)
+	case "plugins":
+		return runPlugins(ctx, cfg, sess, rest)
+	case "presets":
+		return runPresets(cfg, sess, rest)
+	case "audit":
+		return runAudit(ctx, cfg, sess, rest)
+	default:
+		return fmt.Errorf("sessioncli: unknown command %q", cmd)
+	}
+}
+
+func runDevices(ctx context.Context, cfg CLIConfig, sess *session.Session, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("sessioncli: devices requires a subcommand (list|watch)")
+	}
+	switch args[0] {
+	case "list":
+		audio, err := sess.GetAudioDevices()
+		if err != nil {
+			return fmt.Errorf("sessioncli: list audio devices: %w", err)
+		}
+		midi, err := sess.GetMIDIDevices()
+		if err != nil {
+			return fmt.Errorf("sessioncli: list midi devices: %w", err)
+		}
+		for _, d := range audio {
+			fmt.Fprintf(cfg.Stdout, "audio\t%s\t%s\n", d.UID, d.Name)
+		}
+		for _, d := range midi {
+			fmt.Fprintf(cfg.Stdout, "midi\t%s\t%s\n", d.UID, d.Name)
+		}
+		return nil
+	case "watch":
+		updates, cancel, err := sess.Scan(ctx, session.DeviceFilter{})
+		if err != nil {
+			return fmt.Errorf("sessioncli: watch devices: %w", err)
+		}
+		defer cancel()
+		for u := range updates {
+			name := ""
+			if u.Audio != nil {
+				name = u.Audio.Name
+			} else if u.MIDI != nil {
+				name = u.MIDI.Name
+			}
+			fmt.Fprintf(cfg.Stdout, "%s\t%s\t%s\n", u.Kind, u.DeviceUUID, name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("sessioncli: unknown devices subcommand %q", args[0])
+	}
+}
+
+func runPlugins(ctx context.Context, cfg CLIConfig, sess *session.Session, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("sessioncli: plugins requires a subcommand (scan|list|show)")
+	}
+	switch args[0] {
+	case "scan":
+		diff, err := sess.RefreshQuick()
+		if err != nil {
+			return fmt.Errorf("sessioncli: scan plugins: %w", err)
+		}
+		fmt.Fprintf(cfg.Stdout, "added=%d removed=%d changed=%d\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+		return nil
+	case "list":
+		token := ""
+		for {
+			page, err := sess.ScanPluginsPage(ctx, session.PageRequest{ContinuationToken: token})
+			if err != nil {
+				return fmt.Errorf("sessioncli: list plugins: %w", err)
+			}
+			for _, e := range page.Entries {
+				fmt.Fprintf(cfg.Stdout, "%s\t%s\n", e.Key, e.Name)
+			}
+			if !page.Truncated {
+				return nil
+			}
+			token = page.NextContinuationToken
+		}
+	case "show":
+		if len(args) < 2 {
+			return fmt.Errorf("sessioncli: plugins show requires a <type>:<subtype>:<manufacturer>:<name> key")
+		}
+		parts := strings.SplitN(args[1], ":", 4)
+		if len(parts) != 4 {
+			return fmt.Errorf("sessioncli: malformed plugin key %q, expected type:subtype:manufacturer:name", args[1])
+		}
+		p, err := sess.Plugin(parts[0], parts[1], parts[2], parts[3])
+		if err != nil {
+			return fmt.Errorf("sessioncli: show plugin %q: %w", args[1], err)
+		}
+		enc := json.NewEncoder(cfg.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	default:
+		return fmt.Errorf("sessioncli: unknown plugins subcommand %q", args[0])
+	}
+}
+
+func runPresets(cfg CLIConfig, sess *session.Session, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("sessioncli: presets requires a subcommand (ls|save|apply)")
+	}
+	switch args[0] {
+	case "ls":
+		if len(args) < 2 {
+			return fmt.Errorf("sessioncli: presets ls requires a plugin key")
+		}
+		presets, err := sess.ListPresets(args[1])
+		if err != nil {
+			return fmt.Errorf("sessioncli: list presets for %q: %w", args[1], err)
+		}
+		for _, p := range presets {
+			stale := ""
+			if p.Stale {
+				stale = " (stale)"
+			}
+			fmt.Fprintf(cfg.Stdout, "%s%s\n", p.Name, stale)
+		}
+		return nil
+	case "save":
+		if len(args) < 3 {
+			return fmt.Errorf("sessioncli: presets save requires a plugin key and a preset name")
+		}
+		var p session.Preset
+		if err := json.NewDecoder(cfg.Stdin).Decode(&p); err != nil && err != io.EOF {
+			return fmt.Errorf("sessioncli: decode preset params from stdin: %w", err)
+		}
+		p.Name = args[2]
+		if err := sess.SavePreset(args[1], p); err != nil {
+			return fmt.Errorf("sessioncli: save preset %q for %q: %w", p.Name, args[1], err)
+		}
+		return nil
+	case "apply":
+		if len(args) < 4 {
+			return fmt.Errorf("sessioncli: presets apply requires a node id, plugin key, and preset name")
+		}
+		if err := sess.ApplyPreset(session.NodeID(args[1]), args[2], args[3]); err != nil {
+			return fmt.Errorf("sessioncli: apply preset %q for %q: %w", args[3], args[2], err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("sessioncli: unknown presets subcommand %q", args[0])
+	}
+}
+
+func runAudit(ctx context.Context, cfg CLIConfig, sess *session.Session, args []string) error {
+	if len(args) == 0 || args[0] != "tail" {
+		return fmt.Errorf("sessioncli: audit requires the tail subcommand")
+	}
+	if err := sess.EnableAudit(session.DefaultAuditConfig()); err != nil {
+		return fmt.Errorf("sessioncli: enable audit: %w", err)
+	}
+	events, cancel := sess.SubscribeAudit(64)
+	defer cancel()
+
+	enc := json.NewEncoder(cfg.Stdout)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := enc.Encode(ev); err != nil {
+				return fmt.Errorf("sessioncli: write audit event: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,586 @@
+package macaudio
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// SpatialMode selects how a channel's Position is translated into an
+// audible signal: a stereo image for the two pan-based modes, or a
+// binaural render driven by AVAudioEnvironmentNode for SpatialHRTF.
+type SpatialMode string
+
+const (
+	// SpatialStereoPan is a simple linear pan derived from Position's X
+	// coordinate, the same law BaseChannel.SetPan already applies manually.
+	SpatialStereoPan SpatialMode = "stereo_pan"
+	// SpatialEqualPower is a constant-power (sin/cos law) pan across the
+	// full circle around the listener, so a source directly behind the
+	// listener doesn't collapse to silence the way a linear pan would.
+	SpatialEqualPower SpatialMode = "equal_power"
+	// SpatialHRTF renders through AVAudioEnvironmentNode's HRTF algorithm,
+	// giving front/back and elevation cues a plain pan can't. Requires the
+	// engine to have an environment node (see Engine.SetListener); falls
+	// back to SpatialEqualPower until one exists.
+	SpatialHRTF SpatialMode = "hrtf"
+	// SpatialAmbisonicsB is recorded on the channel but not currently
+	// rendered - there's no ambisonic decoder in this tree. A channel set
+	// to this mode behaves like SpatialEqualPower until one is added.
+	SpatialAmbisonicsB SpatialMode = "ambisonics_b"
+)
+
+// SpatialState is the serializable snapshot of a channel's 3D placement,
+// returned by Spatializer.GetSpatialState and embedded in ChannelState.
+type SpatialState struct {
+	Position    [3]float32
+	Forward     [3]float32
+	Up          [3]float32
+	SpatialMode SpatialMode
+}
+
+// Spatializer is implemented by channel types that support 3D positioning.
+// It's kept separate from the Channel interface (matching how
+// PlaybackChannel's queue methods and AuxChannel's sidechain methods stay
+// off Channel too) since not every channel type makes sense to place in
+// space - MasterChannel and MidiInputChannel don't carry an output mixer
+// bus a listener could hear positioned.
+type Spatializer interface {
+	SetPosition(x, y, z float32) error
+	SetOrientation(forward, up [3]float32) error
+	SetSpatialMode(mode SpatialMode) error
+	GetSpatialState() SpatialState
+}
+
+// spatialConfigurer is implemented by every Spatializer (via BaseChannel)
+// and is how Dispatcher.setSpatialParams reaches Spatialize's
+// dispatcher-goroutine-only implementation without exporting it on
+// Spatializer itself - applySpatialConfig must only ever run on the
+// dispatch goroutine, unlike the plain position/mode setters above.
+type spatialConfigurer interface {
+	applySpatialConfig(config SpatialConfig, duration time.Duration, curve RampCurve) (<-chan struct{}, error)
+}
+
+// DistanceAttenuationModel selects how a spatialized channel's gain rolls
+// off with distance from the listener; an alias of avengine's binding-level
+// enum, the same not-reinventing-a-vocabulary approach as RampCurve.
+type DistanceAttenuationModel = avengine.DistanceAttenuationModel
+
+const (
+	DistanceAttenuationInverse     = avengine.DistanceAttenuationInverse
+	DistanceAttenuationLinear      = avengine.DistanceAttenuationLinear
+	DistanceAttenuationExponential = avengine.DistanceAttenuationExponential
+)
+
+// SpatialConfig bundles the AVAudio3DMixing parameters Spatialize applies
+// to a channel in one dispatcher operation: position, rendering algorithm,
+// distance attenuation, and directivity cone. A plain move or mode change
+// that doesn't need the rest of these should keep using SetPosition/
+// SetSpatialMode directly - Spatialize exists for the AVAudioEnvironmentNode
+// tuning those don't touch.
+type SpatialConfig struct {
+	Position [3]float32
+
+	// RenderingAlgorithm only takes effect once SpatialHRTF is selected
+	// (see SetSpatialMode) - environmentFor is what actually applies it, on
+	// the same node SetNode3DPosition positions. Picking a preset:
+	// avengine.RenderingAlgorithmEqualPower skips binaural filtering
+	// entirely (use for a source that doesn't need front/back or elevation
+	// cues, at the lowest CPU cost); RenderingAlgorithmHRTF is the usual
+	// default for headphone monitoring - full binaural cues at a cost
+	// roughly comparable to a few extra EQ bands per source;
+	// RenderingAlgorithmHRTFHQ trades more CPU for a longer, more accurate
+	// head-related impulse response - reserve it for a handful of
+	// foreground sources (e.g. the worked example in spatial_test.go),
+	// since cost scales with source count and HRTFHQ on every channel in a
+	// large session will show up in GetPerformanceStats.
+	RenderingAlgorithm avengine.RenderingAlgorithm
+
+	// Distance attenuation - see DistanceAttenuationModel.
+	DistanceModel     DistanceAttenuationModel
+	RolloffFactor     float32
+	ReferenceDistance float32
+	MaximumDistance   float32
+
+	// Directivity cone, in degrees (0-360): full gain inside
+	// ConeInnerAngle, ConeOuterGain (0-1) outside ConeOuterAngle, linearly
+	// interpolated between. The zero value is omnidirectional, the right
+	// default for a mono mic source with no preferred facing.
+	ConeInnerAngle float32
+	ConeOuterAngle float32
+	ConeOuterGain  float32
+}
+
+// AudioListener is the engine-wide 3D listener snapshot returned by
+// Engine.GetListener, analogous to Web Audio's AudioListener: every
+// spatialized channel's position is rendered relative to it. There is
+// exactly one per Engine; move it with SetListener (and, for Doppler,
+// SetListenerVelocity).
+type AudioListener struct {
+	Position [3]float32
+	Forward  [3]float32
+	Up       [3]float32
+
+	// Velocity, in meters/second, drives Doppler shift for HRTF-rendered
+	// channels; the zero value disables Doppler, matching
+	// AVAudioEnvironmentNode's own default of a stationary listener.
+	Velocity [3]float32
+}
+
+// defaultForward and defaultUp match AVAudioEnvironmentNode's own defaults
+// (listener facing -Z, up is +Y), so a freshly-created channel already
+// renders sensibly before any orientation is set.
+var (
+	defaultForward = [3]float32{0, 0, -1}
+	defaultUp      = [3]float32{0, 1, 0}
+)
+
+// stereoPan derives a linear stereo pan value (-1 left .. 1 right) from a
+// source position relative to the listener. Only the X axis matters; Y/Z
+// are ignored, which is what makes this mode unable to distinguish front
+// from back or above from below, unlike SpatialEqualPower or SpatialHRTF.
+func stereoPan(sourcePos, listenerPos [3]float32) float32 {
+	dx := sourcePos[0] - listenerPos[0]
+	pan := dx / 10 // 10 units either side maps to full left/right
+	return clampPan(pan)
+}
+
+// equalPowerPan computes a constant-power pan from the angle between the
+// source and the listener's forward vector, projected onto the listener's
+// left/right axis. Sources behind the listener still pan fully rather than
+// going silent, unlike stereoPan.
+func equalPowerPan(sourcePos, listenerPos, listenerForward, listenerUp [3]float32) float32 {
+	dir := sub(sourcePos, listenerPos)
+	if norm(dir) == 0 {
+		return 0
+	}
+	dir = normalize(dir)
+
+	right := normalize(cross(listenerForward, listenerUp))
+	if norm(right) == 0 {
+		right = [3]float32{1, 0, 0}
+	}
+
+	// Signed lateral component of dir along the listener's right axis,
+	// clamped to [-1, 1] so floating point drift can't push asin out of
+	// domain below.
+	lateral := dot(dir, right)
+	if lateral > 1 {
+		lateral = 1
+	} else if lateral < -1 {
+		lateral = -1
+	}
+	return lateral
+}
+
+func clampPan(pan float32) float32 {
+	if pan > 1 {
+		return 1
+	}
+	if pan < -1 {
+		return -1
+	}
+	return pan
+}
+
+func sub(a, b [3]float32) [3]float32 {
+	return [3]float32{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func dot(a, b [3]float32) float32 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross(a, b [3]float32) [3]float32 {
+	return [3]float32{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func norm(v [3]float32) float32 {
+	return float32(math.Sqrt(float64(dot(v, v))))
+}
+
+func normalize(v [3]float32) [3]float32 {
+	n := norm(v)
+	if n == 0 {
+		return v
+	}
+	return [3]float32{v[0] / n, v[1] / n, v[2] / n}
+}
+
+// SetPosition places this channel at (x, y, z) in the engine's listener
+// coordinate space, applying the resulting pan (or 3D render, for
+// SpatialHRTF) immediately.
+func (bc *BaseChannel) SetPosition(x, y, z float32) error {
+	bc.mu.Lock()
+	bc.position = [3]float32{x, y, z}
+	bc.mu.Unlock()
+	return bc.applySpatial()
+}
+
+// SetOrientation sets this channel's own forward/up vectors. These only
+// matter for channel types that are themselves directional sound sources
+// (none currently are); they're stored and round-tripped for forward
+// compatibility but don't yet affect rendering.
+func (bc *BaseChannel) SetOrientation(forward, up [3]float32) error {
+	bc.mu.Lock()
+	bc.forward = forward
+	bc.up = up
+	bc.mu.Unlock()
+	return nil
+}
+
+// SetSpatialMode changes how this channel's Position is rendered, applying
+// the new mode's pan (or 3D render) immediately.
+func (bc *BaseChannel) SetSpatialMode(mode SpatialMode) error {
+	switch mode {
+	case SpatialStereoPan, SpatialEqualPower, SpatialHRTF, SpatialAmbisonicsB:
+	default:
+		return fmt.Errorf("unknown spatial mode %q", mode)
+	}
+
+	bc.mu.Lock()
+	bc.spatialMode = mode
+	bc.mu.Unlock()
+	return bc.applySpatial()
+}
+
+// GetSpatialState returns this channel's current 3D placement.
+func (bc *BaseChannel) GetSpatialState() SpatialState {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return SpatialState{
+		Position:    bc.position,
+		Forward:     bc.forward,
+		Up:          bc.up,
+		SpatialMode: bc.spatialMode,
+	}
+}
+
+// applySpatial recomputes and applies this channel's pan (or 3D render)
+// from its current position, mode, and the engine's listener. It's a no-op
+// until the channel has an output mixer (AudioInputChannel/PlaybackChannel
+// do by construction; MasterChannel/MidiInputChannel never get one, so
+// they never render a position even though they embed BaseChannel).
+func (bc *BaseChannel) applySpatial() error {
+	bc.mu.RLock()
+	mixer := bc.outputMixer
+	pos := bc.position
+	mode := bc.spatialMode
+	bc.mu.RUnlock()
+
+	if bc.engine == nil || mixer == nil {
+		return nil
+	}
+
+	if mode == SpatialHRTF {
+		if env, nodePtr, ok := bc.engine.environmentFor(bc); ok {
+			avEngine := bc.engine.getAVEngine()
+			if err := avEngine.SetNode3DPosition(env, nodePtr, pos[0], pos[1], pos[2]); err != nil {
+				return fmt.Errorf("failed to set 3D position: %w", err)
+			}
+			return nil
+		}
+		// No environment node wired up yet (see Engine.SetListener) - fall
+		// back to the equal-power pan so the channel still moves audibly.
+	}
+
+	listenerPos, listenerForward, listenerUp := bc.engine.listenerVectors()
+
+	var pan float32
+	switch mode {
+	case SpatialEqualPower, SpatialHRTF, SpatialAmbisonicsB:
+		pan = equalPowerPan(pos, listenerPos, listenerForward, listenerUp)
+	default:
+		pan = stereoPan(pos, listenerPos)
+	}
+
+	avEngine := bc.engine.getAVEngine()
+	if err := avEngine.SetMixerPanForBus(mixer, pan, 0); err != nil {
+		return fmt.Errorf("failed to apply spatial pan: %w", err)
+	}
+	return nil
+}
+
+// SetListener places the engine's spatial audio listener, recomputing every
+// spatial-mode channel's pan (or 3D render) to match. position/forward/up
+// are in the same coordinate space as every channel's Position.
+func (e *Engine) SetListener(position, forward, up [3]float32) error {
+	e.mu.Lock()
+	e.listenerPosition = position
+	e.listenerForward = forward
+	e.listenerUp = up
+	e.mu.Unlock()
+
+	env, err := e.ensureEnvironmentNode()
+	if err != nil {
+		return err
+	}
+	avEngine := e.getAVEngine()
+	if err := avEngine.SetListenerPosition(env, position[0], position[1], position[2]); err != nil {
+		return fmt.Errorf("failed to set listener position: %w", err)
+	}
+	if err := avEngine.SetListenerOrientation(env, forward, up); err != nil {
+		return fmt.Errorf("failed to set listener orientation: %w", err)
+	}
+
+	// Copy the channel map, then release e.mu before calling into each
+	// channel - applySpatial takes the channel's own mutex, and the channel
+	// map is keyed by the same engine e's lock, so holding both at once
+	// risks a deadlock against a channel that's concurrently calling back
+	// into the engine (e.g. AddSend).
+	e.mu.RLock()
+	channels := make([]Channel, 0, len(e.channels))
+	for _, ch := range e.channels {
+		channels = append(channels, ch)
+	}
+	e.mu.RUnlock()
+
+	for _, ch := range channels {
+		if sp, ok := ch.(Spatializer); ok {
+			if state := sp.GetSpatialState(); state.SpatialMode != "" {
+				if err := sp.SetPosition(state.Position[0], state.Position[1], state.Position[2]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// listenerVectors returns the engine's current listener position/forward/up
+// for use by BaseChannel.applySpatial's pan math.
+func (e *Engine) listenerVectors() (position, forward, up [3]float32) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.listenerPosition, e.listenerForward, e.listenerUp
+}
+
+// GetListener returns a snapshot of the engine's current 3D listener -
+// position/forward/up as last set by SetListener, plus Velocity as last set
+// by SetListenerVelocity. There is exactly one listener per Engine; this
+// doesn't allocate one, it's always populated (with the zero-velocity,
+// forward/up defaults) even before SetListener has ever been called.
+func (e *Engine) GetListener() AudioListener {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return AudioListener{
+		Position: e.listenerPosition,
+		Forward:  e.listenerForward,
+		Up:       e.listenerUp,
+		Velocity: e.listenerVelocity,
+	}
+}
+
+// SetListenerVelocity records the listener's velocity in meters/second, for
+// Doppler shift on HRTF-rendered channels. Unlike SetListener, this doesn't
+// require an environment node to already exist - Doppler only matters once
+// one does, so the value is simply stored and picked up the next time a
+// channel's position is applied through environmentFor.
+func (e *Engine) SetListenerVelocity(velocity [3]float32) error {
+	e.mu.Lock()
+	e.listenerVelocity = velocity
+	e.mu.Unlock()
+	return nil
+}
+
+// environmentFor returns the engine's shared AVAudioEnvironmentNode and the
+// native node pointer bc should be positioned by within it, lazily
+// connecting bc's output mixer into the environment node and selecting the
+// HRTF rendering algorithm on first use. ok is false if the engine has no
+// environment node yet (SetListener hasn't been called), in which case the
+// caller should fall back to plain stereo panning.
+func (e *Engine) environmentFor(bc *BaseChannel) (env, nodePtr unsafe.Pointer, ok bool) {
+	e.mu.RLock()
+	env = e.environmentNode
+	e.mu.RUnlock()
+	if env == nil {
+		return nil, nil, false
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.outputMixer == nil {
+		return nil, nil, false
+	}
+	if !bc.hrtfConnected {
+		avEngine := e.getAVEngine()
+		if err := avEngine.Connect(bc.outputMixer, env, 0, 0); err != nil {
+			if err := avEngine.ConnectWithFormat(bc.outputMixer, env, 0, 0, nil); err != nil {
+				return nil, nil, false
+			}
+		}
+		if err := avEngine.SetNode3DRenderingAlgorithm(env, bc.outputMixer, avengine.RenderingAlgorithmHRTF); err != nil {
+			return nil, nil, false
+		}
+		bc.hrtfConnected = true
+	}
+	return env, bc.outputMixer, true
+}
+
+// ensureEnvironmentNode lazily creates the engine's shared
+// AVAudioEnvironmentNode and connects it into the main mixer, so existing
+// audio reaching it from positioned channels is still audible. Called from
+// SetListener; e.mu must not be held by the caller.
+func (e *Engine) ensureEnvironmentNode() (unsafe.Pointer, error) {
+	e.mu.Lock()
+	if e.environmentNode != nil {
+		env := e.environmentNode
+		e.mu.Unlock()
+		return env, nil
+	}
+	e.mu.Unlock()
+
+	avEngine := e.getAVEngine()
+	env, err := avEngine.CreateEnvironmentNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create environment node: %w", err)
+	}
+
+	mainMixer, err := avEngine.MainMixerNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get main mixer node: %w", err)
+	}
+	if err := avEngine.Connect(env, mainMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(env, mainMixer, 0, 0, nil); err != nil {
+			return nil, fmt.Errorf("failed to connect environment node to main mixer: %w", err)
+		}
+	}
+
+	e.mu.Lock()
+	e.environmentNode = env
+	e.mu.Unlock()
+	return env, nil
+}
+
+// Spatialize applies config's rendering algorithm, distance attenuation,
+// and directivity cone to this channel, then glides its position to
+// config.Position over duration using curve (duration <= 0 applies the
+// position immediately too, like SetPosition). Routed through the
+// dispatcher as OpSetSpatialParams, like every other topology change - see
+// Dispatcher.SetSpatialParams. The returned channel closes once the
+// position ramp completes or is canceled/superseded (see CancelRamps), nil
+// if duration <= 0.
+func (bc *BaseChannel) Spatialize(config SpatialConfig, duration time.Duration, curve RampCurve) (<-chan struct{}, error) {
+	if bc.engine == nil || bc.engine.dispatcher == nil {
+		return nil, fmt.Errorf("channel not connected to a running dispatcher")
+	}
+	return bc.engine.dispatcher.SetSpatialParams(bc.GetIDString(), config, duration, curve)
+}
+
+// applySpatialConfig is Spatialize's dispatcher-goroutine-only
+// implementation (see spatialConfigurer), called from
+// Dispatcher.setSpatialParams. It applies the AVAudioEnvironmentNode-side
+// parameters - rendering algorithm, distance attenuation, directivity -
+// immediately, since those are static per-node settings rather than
+// something a listener perceives as a continuous glide the way position
+// is; only the position itself ramps.
+func (bc *BaseChannel) applySpatialConfig(config SpatialConfig, duration time.Duration, curve RampCurve) (<-chan struct{}, error) {
+	bc.mu.Lock()
+	bc.spatialConfig = config
+	bc.mu.Unlock()
+
+	if bc.engine != nil && bc.outputMixer != nil {
+		if env, nodePtr, ok := bc.engine.environmentFor(bc); ok {
+			avEngine := bc.engine.getAVEngine()
+			if err := avEngine.SetNode3DRenderingAlgorithm(env, nodePtr, config.RenderingAlgorithm); err != nil {
+				return nil, fmt.Errorf("failed to set rendering algorithm: %w", err)
+			}
+			if err := avEngine.SetNodeDistanceAttenuation(env, nodePtr, config.DistanceModel, config.RolloffFactor, config.ReferenceDistance, config.MaximumDistance); err != nil {
+				return nil, fmt.Errorf("failed to set distance attenuation: %w", err)
+			}
+			if err := avEngine.SetNodeDirectivity(env, nodePtr, config.ConeInnerAngle, config.ConeOuterAngle, config.ConeOuterGain); err != nil {
+				return nil, fmt.Errorf("failed to set directivity: %w", err)
+			}
+		}
+	}
+
+	if duration <= 0 {
+		return nil, bc.SetPosition(config.Position[0], config.Position[1], config.Position[2])
+	}
+	return bc.rampPosition(config.Position, duration, curve), nil
+}
+
+// rampPosition glides the channel's position to target over duration,
+// recomputing pan (or 3D render, for SpatialHRTF) on each step via
+// SetPosition. There's no native node-position ramp the way
+// RampMixerVolume/RampMixerPan are (see avengine.Engine), so this ticks in
+// software instead, the same approach AutomationScheduler uses to drive
+// plugin parameters. A call made while a position ramp is already in
+// flight cancels it atomically and starts fresh, same as SetVolumeRamp/
+// SetPanRamp.
+func (bc *BaseChannel) rampPosition(target [3]float32, duration time.Duration, curve RampCurve) <-chan struct{} {
+	const step = 20 * time.Millisecond
+
+	bc.mu.RLock()
+	start := bc.position
+	bc.mu.RUnlock()
+
+	bc.rampMu.Lock()
+	if bc.positionRampCancel != nil {
+		close(bc.positionRampCancel)
+	}
+	cancel := make(chan struct{})
+	bc.positionRampCancel = cancel
+	bc.rampMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(step)
+		defer ticker.Stop()
+		startedAt := time.Now()
+
+		releaseCancel := func() {
+			bc.rampMu.Lock()
+			if bc.positionRampCancel == cancel {
+				bc.positionRampCancel = nil
+			}
+			bc.rampMu.Unlock()
+		}
+
+		for {
+			select {
+			case <-cancel:
+				return
+			case now := <-ticker.C:
+				frac := float32(now.Sub(startedAt).Seconds() / duration.Seconds())
+				if frac >= 1 {
+					bc.SetPosition(target[0], target[1], target[2])
+					releaseCancel()
+					return
+				}
+				shaped := shapeRampFraction(frac, curve)
+				bc.SetPosition(
+					start[0]+shaped*(target[0]-start[0]),
+					start[1]+shaped*(target[1]-start[1]),
+					start[2]+shaped*(target[2]-start[2]),
+				)
+			}
+		}
+	}()
+
+	return done
+}
+
+// shapeRampFraction reshapes frac (0..1, linear elapsed-time progress)
+// according to curve, matching the shapes RampMixerVolume/RampMixerPan use
+// natively so a position ramp feels consistent with a volume or pan ramp
+// run alongside it.
+func shapeRampFraction(frac float32, curve RampCurve) float32 {
+	switch curve {
+	case RampEqualPower:
+		return float32(math.Sin(float64(frac) * math.Pi / 2))
+	case RampExponential:
+		return frac * frac
+	default: // RampLinear
+		return frac
+	}
+}
@@ -0,0 +1,181 @@
+package macaudio
+
+import (
+	"testing"
+	"time"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+	"github.com/shaban/macaudio/devices"
+)
+
+// TestSpatialPositionRoundTrip mirrors TestChannelCreation: it creates a
+// running engine, positions a playback channel in space, and checks the
+// position survives a GetState/SetState round trip and a full
+// SaveToJSON/LoadFromJSON round trip through the serializer.
+func TestSpatialPositionRoundTrip(t *testing.T) {
+	config := createTestConfig(t, 48000, 256)
+
+	engine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer engine.Stop()
+
+	playbackConfig := PlaybackConfig{
+		FilePath:    "/nonexistent/file.wav",
+		LoopEnabled: false,
+		AutoStart:   false,
+	}
+	channel, err := engine.CreatePlaybackChannel("test_spatial", playbackConfig)
+	if err != nil {
+		t.Fatalf("Failed to create playback channel: %v", err)
+	}
+
+	var spatializer Spatializer = channel
+	if err := spatializer.SetPosition(1.5, -2.5, 3.0); err != nil {
+		t.Fatalf("SetPosition failed: %v", err)
+	}
+	if err := spatializer.SetOrientation([3]float32{1, 0, 0}, [3]float32{0, 0, 1}); err != nil {
+		t.Fatalf("SetOrientation failed: %v", err)
+	}
+	if err := spatializer.SetSpatialMode(SpatialEqualPower); err != nil {
+		t.Fatalf("SetSpatialMode failed: %v", err)
+	}
+
+	want := spatializer.GetSpatialState()
+	if want.Position != [3]float32{1.5, -2.5, 3.0} {
+		t.Errorf("Position = %v, want {1.5, -2.5, 3.0}", want.Position)
+	}
+	if want.SpatialMode != SpatialEqualPower {
+		t.Errorf("SpatialMode = %v, want %v", want.SpatialMode, SpatialEqualPower)
+	}
+
+	// GetState/SetState round trip.
+	state := channel.GetState()
+	if state.Position != want.Position {
+		t.Errorf("ChannelState.Position = %v, want %v", state.Position, want.Position)
+	}
+	if state.SpatialMode != want.SpatialMode {
+		t.Errorf("ChannelState.SpatialMode = %v, want %v", state.SpatialMode, want.SpatialMode)
+	}
+
+	restored, err := NewPlaybackChannel("restored", playbackConfig, engine)
+	if err != nil {
+		t.Fatalf("Failed to create channel for restore: %v", err)
+	}
+	if err := restored.SetState(state); err != nil {
+		t.Fatalf("SetState failed: %v", err)
+	}
+	if got := restored.GetSpatialState(); got.Position != want.Position || got.SpatialMode != want.SpatialMode {
+		t.Errorf("restored spatial state = %+v, want %+v", got, want)
+	}
+
+	// Serializer path: GetSerializer().SaveToJSON() should carry the
+	// position through to the JSON it produces.
+	jsonData, err := engine.GetSerializer().SaveToJSON()
+	if err != nil {
+		t.Fatalf("SaveToJSON failed: %v", err)
+	}
+	if !containsString(jsonData, `"spatialMode": "equal_power"`) {
+		t.Error("serialized state doesn't contain the channel's spatial mode")
+	}
+}
+
+// TestSpatializeBinauralMultiMicMonitoring is a worked example of the
+// headphone-monitoring use case Spatialize exists for: two overhead mics on
+// either side of a source, rendered through AVAudioEnvironmentNode's HRTF
+// algorithm (see SpatialConfig.RenderingAlgorithm's preset guidance) so an
+// engineer monitoring on headphones hears them positioned in space rather
+// than just panned left/right.
+func TestSpatializeBinauralMultiMicMonitoring(t *testing.T) {
+	audioDevices, err := devices.GetAudio()
+	if err != nil {
+		t.Fatalf("Failed to enumerate audio devices: %v", err)
+	}
+	inputs := audioDevices.Online().Inputs()
+	if len(inputs) == 0 {
+		t.Skip("No online input devices available for testing")
+	}
+
+	config := createTestConfig(t, 48000, 256)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer eng.Stop()
+
+	// The monitoring listener sits at the origin facing AVAudioEnvironmentNode's default orientation.
+	if err := eng.SetListener([3]float32{0, 0, 0}, defaultForward, defaultUp); err != nil {
+		t.Fatalf("SetListener failed: %v", err)
+	}
+
+	micConfig := AudioInputConfig{DeviceUID: inputs[0].UID, InputBus: 0, MonitoringLevel: 0.5}
+	overheadLeft, err := eng.CreateAudioInputChannel("overhead-left", micConfig)
+	if err != nil {
+		t.Fatalf("Failed to create overhead-left input channel: %v", err)
+	}
+	overheadRight, err := eng.CreateAudioInputChannel("overhead-right", micConfig)
+	if err != nil {
+		t.Fatalf("Failed to create overhead-right input channel: %v", err)
+	}
+
+	if err := overheadLeft.SetSpatialMode(SpatialHRTF); err != nil {
+		t.Fatalf("SetSpatialMode(overhead-left) failed: %v", err)
+	}
+	if err := overheadRight.SetSpatialMode(SpatialHRTF); err != nil {
+		t.Fatalf("SetSpatialMode(overhead-right) failed: %v", err)
+	}
+
+	hrtfConfig := SpatialConfig{
+		RenderingAlgorithm: avengine.RenderingAlgorithmHRTF,
+		DistanceModel:      DistanceAttenuationInverse,
+		RolloffFactor:      1,
+		ReferenceDistance:  1,
+		MaximumDistance:    20,
+		// Omnidirectional - a mic capsule has no preferred facing to model.
+		ConeInnerAngle: 360,
+		ConeOuterAngle: 360,
+		ConeOuterGain:  1,
+	}
+
+	// Overhead-left glides into place over 200ms, the way a monitoring
+	// engineer dragging a position knob would expect to hear it move
+	// rather than jump.
+	leftConfig := hrtfConfig
+	leftConfig.Position = [3]float32{-1.5, 1, -2}
+	done, err := overheadLeft.Spatialize(leftConfig, 200*time.Millisecond, RampEqualPower)
+	if err != nil {
+		t.Fatalf("Spatialize(overhead-left) failed: %v", err)
+	}
+	if done != nil {
+		<-done
+	}
+
+	// Overhead-right applies instantly (duration <= 0), the common case
+	// for an initial placement at session load.
+	rightConfig := hrtfConfig
+	rightConfig.Position = [3]float32{1.5, 1, -2}
+	done, err = overheadRight.Spatialize(rightConfig, 0, RampLinear)
+	if err != nil {
+		t.Fatalf("Spatialize(overhead-right) failed: %v", err)
+	}
+	if done != nil {
+		t.Error("Spatialize with duration <= 0 should not return a ramp channel")
+	}
+
+	left := overheadLeft.GetSpatialState()
+	if left.Position != leftConfig.Position {
+		t.Errorf("overhead-left Position = %v, want %v", left.Position, leftConfig.Position)
+	}
+	right := overheadRight.GetSpatialState()
+	if right.Position != rightConfig.Position {
+		t.Errorf("overhead-right Position = %v, want %v", right.Position, rightConfig.Position)
+	}
+}
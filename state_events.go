@@ -0,0 +1,188 @@
+package macaudio
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCoalesceWindow is the coalescing interval Subscribe uses: patch
+// ops for the same JSON Pointer path arriving within this window collapse
+// down to the latest one, so a fader sweep doesn't flood the bus.
+const DefaultCoalesceWindow = 50 * time.Millisecond
+
+// PatchOp is a single RFC 6902 JSON Patch operation.
+type PatchOp struct {
+	Op    string      `json:"op"` // "add", "remove", or "replace"
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// StateEvent is a batch of PatchOps describing how the engine state
+// changed, as delivered by Serializer.Subscribe.
+type StateEvent struct {
+	Patch     []PatchOp `json:"patch"`
+	Timestamp int64     `json:"timestamp"`
+}
+
+type eventSubscriber struct {
+	raw  chan []PatchOp
+	out  chan StateEvent
+	done chan struct{}
+}
+
+func (sub *eventSubscriber) run(window time.Duration) {
+	defer close(sub.out)
+
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+
+	pending := make(map[string]PatchOp)
+	for {
+		select {
+		case ops, ok := <-sub.raw:
+			if !ok {
+				if len(pending) > 0 {
+					sub.emit(pending)
+				}
+				return
+			}
+			for _, op := range ops {
+				pending[op.Path] = op
+			}
+		case <-ticker.C:
+			if len(pending) > 0 {
+				sub.emit(pending)
+				pending = make(map[string]PatchOp)
+			}
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+func (sub *eventSubscriber) emit(pending map[string]PatchOp) {
+	ops := make([]PatchOp, 0, len(pending))
+	for _, op := range pending {
+		ops = append(ops, op)
+	}
+	select {
+	case sub.out <- StateEvent{Patch: ops, Timestamp: time.Now().UnixNano()}:
+	default:
+		// Consumer isn't keeping up; drop this batch rather than block
+		// the SetState call that triggered it.
+	}
+}
+
+// Subscribe returns a channel that receives a StateEvent every time
+// SetState successfully mutates the engine, carrying a JSON Patch delta
+// against the previous state (see patchStates) rather than the full
+// EngineState, so a UI or logging daemon doesn't need to poll GetState
+// and diff it itself. Patch ops for the same path are coalesced down to
+// the latest within DefaultCoalesceWindow; use SubscribeCoalesced for a
+// different window. Call Unsubscribe when done to release the channel.
+func (s *Serializer) Subscribe() <-chan StateEvent {
+	return s.SubscribeCoalesced(DefaultCoalesceWindow)
+}
+
+// SubscribeCoalesced is Subscribe with an explicit coalescing window.
+func (s *Serializer) SubscribeCoalesced(window time.Duration) <-chan StateEvent {
+	sub := &eventSubscriber{
+		raw:  make(chan []PatchOp, 64),
+		out:  make(chan StateEvent, 16),
+		done: make(chan struct{}),
+	}
+
+	s.eventMu.Lock()
+	s.eventSubs = append(s.eventSubs, sub)
+	s.eventMu.Unlock()
+
+	go sub.run(window)
+	return sub.out
+}
+
+// Unsubscribe stops delivery to a channel previously returned by
+// Subscribe/SubscribeCoalesced and closes it.
+func (s *Serializer) Unsubscribe(ch <-chan StateEvent) {
+	s.eventMu.Lock()
+	defer s.eventMu.Unlock()
+
+	for i, sub := range s.eventSubs {
+		if sub.out == ch {
+			close(sub.done)
+			s.eventSubs = append(s.eventSubs[:i], s.eventSubs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Serializer) publishEvent(ops []PatchOp) {
+	if len(ops) == 0 {
+		return
+	}
+
+	s.eventMu.Lock()
+	subs := make([]*eventSubscriber, len(s.eventSubs))
+	copy(subs, s.eventSubs)
+	s.eventMu.Unlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.raw <- ops:
+		default:
+			// Subscriber's raw buffer is full; drop rather than block.
+		}
+	}
+}
+
+func jsonPointerEscape(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return token
+}
+
+// patchStates computes the RFC 6902 JSON Patch ops that turn `from` into
+// `to`, at channel granularity for adds/removes and field granularity
+// (volume/pan/muted/connections/config) for channels present in both.
+func patchStates(from, to EngineState) []PatchOp {
+	var ops []PatchOp
+
+	for id, toCh := range to.Channels {
+		fromCh, existed := from.Channels[id]
+		path := "/channels/" + jsonPointerEscape(id)
+
+		if !existed {
+			ops = append(ops, PatchOp{Op: "add", Path: path, Value: toCh})
+			continue
+		}
+
+		if fromCh.Volume != toCh.Volume {
+			ops = append(ops, PatchOp{Op: "replace", Path: path + "/volume", Value: toCh.Volume})
+		}
+		if fromCh.Pan != toCh.Pan {
+			ops = append(ops, PatchOp{Op: "replace", Path: path + "/pan", Value: toCh.Pan})
+		}
+		if fromCh.Muted != toCh.Muted {
+			ops = append(ops, PatchOp{Op: "replace", Path: path + "/muted", Value: toCh.Muted})
+		}
+		if !connectionsEqual(fromCh.Connections, toCh.Connections) {
+			ops = append(ops, PatchOp{Op: "replace", Path: path + "/connections", Value: toCh.Connections})
+		}
+		if !reflect.DeepEqual(fromCh.Config, toCh.Config) {
+			ops = append(ops, PatchOp{Op: "replace", Path: path + "/config", Value: toCh.Config})
+		}
+	}
+
+	for id := range from.Channels {
+		if _, exists := to.Channels[id]; !exists {
+			ops = append(ops, PatchOp{Op: "remove", Path: "/channels/" + jsonPointerEscape(id)})
+		}
+	}
+
+	if !connectionsEqual(from.Connections, to.Connections) {
+		ops = append(ops, PatchOp{Op: "replace", Path: "/connections", Value: to.Connections})
+	}
+
+	return ops
+}
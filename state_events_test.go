@@ -0,0 +1,73 @@
+package macaudio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPatchStatesReportsAddRemoveReplace(t *testing.T) {
+	from := EngineState{
+		Channels: map[string]ChannelState{
+			"a": {ID: "a", Volume: 1.0, Muted: false},
+			"b": {ID: "b", Volume: 0.5},
+		},
+	}
+	to := EngineState{
+		Channels: map[string]ChannelState{
+			"a": {ID: "a", Volume: 0.7, Muted: true},
+			"c": {ID: "c", Volume: 1.0},
+		},
+	}
+
+	ops := patchStates(from, to)
+
+	var sawAdd, sawRemove, sawVolume, sawMute bool
+	for _, op := range ops {
+		switch {
+		case op.Op == "add" && op.Path == "/channels/c":
+			sawAdd = true
+		case op.Op == "remove" && op.Path == "/channels/b":
+			sawRemove = true
+		case op.Op == "replace" && op.Path == "/channels/a/volume":
+			sawVolume = true
+		case op.Op == "replace" && op.Path == "/channels/a/muted":
+			sawMute = true
+		}
+	}
+	if !sawAdd || !sawRemove || !sawVolume || !sawMute {
+		t.Fatalf("missing expected ops, got %+v", ops)
+	}
+}
+
+func TestSerializerSubscribeReceivesCoalescedPatch(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+	events := serializer.SubscribeCoalesced(10 * time.Millisecond)
+	defer serializer.Unsubscribe(events)
+
+	masterID := eng.GetMasterChannel().GetIDString()
+	for _, v := range []float32{0.2, 0.4, 0.6} {
+		state := serializer.GetState()
+		master := state.Channels[masterID]
+		master.Volume = v
+		state.Channels[masterID] = master
+		if err := serializer.SetState(state); err != nil {
+			t.Fatalf("SetState failed: %v", err)
+		}
+	}
+
+	select {
+	case event := <-events:
+		if len(event.Patch) == 0 {
+			t.Fatal("expected a non-empty patch")
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timed out waiting for a state event")
+	}
+}
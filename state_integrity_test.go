@@ -0,0 +1,99 @@
+package macaudio
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestSaveSignedAndLoadVerifiedRoundTrips(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := serializer.SaveSignedToWriter(&buf, priv); err != nil {
+		t.Fatalf("SaveSignedToWriter failed: %v", err)
+	}
+
+	if err := serializer.LoadVerifiedFromReader(bytes.NewReader(buf.Bytes()), pub); err != nil {
+		t.Fatalf("LoadVerifiedFromReader failed: %v", err)
+	}
+}
+
+func TestLoadVerifiedFromReaderRejectsTamperedContent(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := serializer.SaveSignedToWriter(&buf, priv); err != nil {
+		t.Fatalf("SaveSignedToWriter failed: %v", err)
+	}
+
+	tampered := bytes.Replace(buf.Bytes(), []byte(`"version"`), []byte(`"version_"`), 1)
+	if err := serializer.LoadVerifiedFromReader(bytes.NewReader(tampered), pub); err == nil {
+		t.Fatal("expected LoadVerifiedFromReader to reject a tampered state")
+	}
+}
+
+func TestLoadVerifiedFromReaderRejectsUnsignedState(t *testing.T) {
+	config := createTestConfig(t, 48000, 512)
+	eng, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Stop()
+
+	serializer := eng.GetSerializer()
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := serializer.SaveToWriter(&buf); err != nil {
+		t.Fatalf("SaveToWriter failed: %v", err)
+	}
+
+	if err := serializer.LoadVerifiedFromReader(bytes.NewReader(buf.Bytes()), pub); err == nil {
+		t.Fatal("expected LoadVerifiedFromReader to reject an unsigned state")
+	}
+}
+
+func TestValidateContentHashDetectsMismatch(t *testing.T) {
+	state := EngineState{Version: "1.1.0", Channels: map[string]ChannelState{}}
+	hash, err := computeContentHash(state)
+	if err != nil {
+		t.Fatalf("computeContentHash failed: %v", err)
+	}
+	state.ContentHash = hash
+
+	if err := ValidateContentHash(state); err != nil {
+		t.Fatalf("expected matching hash to validate, got: %v", err)
+	}
+
+	state.Timestamp = state.Timestamp + 1
+	if err := ValidateContentHash(state); err == nil {
+		t.Fatal("expected a mutated state to fail hash validation")
+	}
+}
@@ -0,0 +1,201 @@
+// Package stream gives channel constructors a device/format abstraction to
+// build on instead of juggling raw device UID strings, modeled on the
+// Device/Stream split other audio hosts (e.g. cpal) use to replace a flat
+// Endpoint/Voice API: a Device enumerates the formats it actually supports,
+// and OpenInputStream/OpenOutputStream hand back a validated stream handle
+// instead of silently falling back to whatever the hardware defaults to.
+package stream
+
+import (
+	"fmt"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// Format describes one sample rate/channel-count combination a Device can be
+// opened with. BitDepth is 0 when the device doesn't report supported bit
+// depths explicitly (CoreAudio commonly negotiates this internally).
+type Format struct {
+	SampleRate   int
+	ChannelCount int
+	BitDepth     int
+}
+
+// Device is a capability-queried audio device: everything a caller needs to
+// pick a format and open a stream, without reaching into avaudio/engine or
+// the devices package directly.
+type Device struct {
+	UID             string
+	Name            string
+	MaxInputChans   int
+	MaxOutputChans  int
+	IsDefaultInput  bool
+	IsDefaultOutput bool
+
+	sampleRates []int
+}
+
+// SupportedFormats lists the formats OpenInputStream/OpenOutputStream will
+// accept for this device: every supported sample rate, at the device's
+// input or output channel count (whichever is non-zero; devices here are
+// either capture or playback capable, not validated for aggregate devices
+// that are both).
+func (d Device) SupportedFormats() []Format {
+	channels := d.MaxOutputChans
+	if channels == 0 {
+		channels = d.MaxInputChans
+	}
+
+	formats := make([]Format, len(d.sampleRates))
+	for i, rate := range d.sampleRates {
+		formats[i] = Format{SampleRate: rate, ChannelCount: channels}
+	}
+	return formats
+}
+
+// supports reports whether cfg matches one of d's supported formats. A zero
+// SampleRate or ChannelCount in cfg is treated as "accept the device's
+// default for this field" rather than as a literal 0Hz/0-channel request.
+func (d Device) supports(cfg Format) bool {
+	for _, f := range d.SupportedFormats() {
+		if cfg.SampleRate != 0 && cfg.SampleRate != f.SampleRate {
+			continue
+		}
+		if cfg.ChannelCount != 0 && cfg.ChannelCount != f.ChannelCount {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func deviceFromInfo(info avengine.DeviceInfo) Device {
+	return Device{
+		UID:             info.UID,
+		Name:            info.Name,
+		MaxInputChans:   info.MaxInputChannels,
+		MaxOutputChans:  info.MaxOutputChannels,
+		IsDefaultInput:  info.IsDefaultInput,
+		IsDefaultOutput: info.IsDefaultOutput,
+		sampleRates:     info.SampleRates,
+	}
+}
+
+// Devices lists every available audio device, input- and output-capable
+// alike, via avaudio/engine.Devices.
+func Devices() ([]Device, error) {
+	infos, err := avengine.Devices()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Device, len(infos))
+	for i, info := range infos {
+		out[i] = deviceFromInfo(info)
+	}
+	return out, nil
+}
+
+// deviceByUID looks up a single device by UID via Devices.
+func deviceByUID(uid string) (Device, error) {
+	all, err := Devices()
+	if err != nil {
+		return Device{}, err
+	}
+	for _, d := range all {
+		if d.UID == uid {
+			return d, nil
+		}
+	}
+	return Device{}, fmt.Errorf("no device with UID %q", uid)
+}
+
+// DefaultInputDevice returns the system's current default input device.
+func DefaultInputDevice() (Device, error) {
+	all, err := Devices()
+	if err != nil {
+		return Device{}, err
+	}
+	for _, d := range all {
+		if d.IsDefaultInput {
+			return d, nil
+		}
+	}
+	return Device{}, fmt.Errorf("no default input device")
+}
+
+// DefaultOutputDevice returns the system's current default output device.
+func DefaultOutputDevice() (Device, error) {
+	all, err := Devices()
+	if err != nil {
+		return Device{}, err
+	}
+	for _, d := range all {
+		if d.IsDefaultOutput {
+			return d, nil
+		}
+	}
+	return Device{}, fmt.Errorf("no default output device")
+}
+
+// InputStream is a validated handle to an input device/format pair, returned
+// by OpenInputStream. It carries no native resources itself - the shared
+// AVAudioInputNode is still created lazily by Engine.getOrCreateInputNode -
+// it exists so channel constructors can consume a typed, already-validated
+// value instead of a raw UID plus hoped-for format.
+type InputStream struct {
+	Device Device
+	Format Format
+}
+
+// OutputStream is the output-side counterpart to InputStream.
+type OutputStream struct {
+	Device Device
+	Format Format
+}
+
+// OpenInputStream validates cfg against deviceUID's SupportedFormats and
+// returns a handle describing the negotiated stream. Pass "" for deviceUID
+// to use DefaultInputDevice. Returns an error (rather than silently falling
+// back to a different format) if the device can't provide cfg.
+func OpenInputStream(deviceUID string, cfg Format) (*InputStream, error) {
+	device, err := resolveDevice(deviceUID, true)
+	if err != nil {
+		return nil, err
+	}
+	if device.MaxInputChans == 0 {
+		return nil, fmt.Errorf("device %q has no input channels", device.UID)
+	}
+	if !device.supports(cfg) {
+		return nil, fmt.Errorf("device %q does not support requested format %+v (supported: %+v)",
+			device.UID, cfg, device.SupportedFormats())
+	}
+	return &InputStream{Device: device, Format: cfg}, nil
+}
+
+// OpenOutputStream is the output-side counterpart to OpenInputStream. Pass
+// "" for deviceUID to use DefaultOutputDevice.
+func OpenOutputStream(deviceUID string, cfg Format) (*OutputStream, error) {
+	device, err := resolveDevice(deviceUID, false)
+	if err != nil {
+		return nil, err
+	}
+	if device.MaxOutputChans == 0 {
+		return nil, fmt.Errorf("device %q has no output channels", device.UID)
+	}
+	if !device.supports(cfg) {
+		return nil, fmt.Errorf("device %q does not support requested format %+v (supported: %+v)",
+			device.UID, cfg, device.SupportedFormats())
+	}
+	return &OutputStream{Device: device, Format: cfg}, nil
+}
+
+func resolveDevice(deviceUID string, forInput bool) (Device, error) {
+	if deviceUID == "" {
+		if forInput {
+			return DefaultInputDevice()
+		}
+		return DefaultOutputDevice()
+	}
+	return deviceByUID(deviceUID)
+}
@@ -0,0 +1,173 @@
+package macaudio
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// SynthKind selects which signal generator a SynthChannel's render node
+// produces.
+type SynthKind string
+
+const (
+	SynthSine    SynthKind = "sine"
+	SynthNoise   SynthKind = "noise"
+	SynthSweep   SynthKind = "sweep"
+	SynthSilence SynthKind = "silence"
+)
+
+// NoiseKind selects the spectral character of a SynthNoise channel.
+type NoiseKind string
+
+const (
+	NoiseWhite NoiseKind = "white"
+	NoisePink  NoiseKind = "pink"
+)
+
+// SynthConfig holds configuration for a synth channel.
+type SynthConfig struct {
+	Kind SynthKind
+
+	// Frequency and Amplitude apply to SynthSine; Amplitude also applies to
+	// SynthNoise and SynthSweep.
+	Frequency float64
+	Amplitude float64
+
+	// Noise applies to SynthNoise.
+	Noise NoiseKind
+
+	// StartFrequency, EndFrequency and Duration apply to SynthSweep.
+	StartFrequency float64
+	EndFrequency   float64
+	Duration       time.Duration
+}
+
+// SynthChannel is a channel backed by a native AUAudioUnit signal generator
+// (see avengine.CreateSineSynth/CreateNoiseSynth/CreateSweepSynth/
+// CreateSilenceSynth), used as an acoustically well-defined, macOS-path-free
+// source for isolation and capacity tests that previously depended on
+// /System/Library/Sounds/Ping.aiff.
+//
+// Unlike ProcessingChannel's render unit, a SynthChannel's generator runs
+// entirely in native code - only its parameters (frequency, amplitude) cross
+// into Go - so it needs no render-notify-tap binding to produce sound.
+type SynthChannel struct {
+	*BaseChannel
+
+	config SynthConfig
+
+	renderNode unsafe.Pointer
+}
+
+// NewSynthChannel creates a new synth channel backed by the generator node
+// matching config.Kind.
+func NewSynthChannel(name string, config SynthConfig, engine *Engine) (*SynthChannel, error) {
+	avEngine := engine.getAVEngine()
+
+	var renderNode unsafe.Pointer
+	var err error
+	switch config.Kind {
+	case SynthSine:
+		renderNode, err = avEngine.CreateSineSynth(config.Frequency, config.Amplitude)
+	case SynthNoise:
+		renderNode, err = avEngine.CreateNoiseSynth(noiseKindToAVEngine(config.Noise))
+	case SynthSweep:
+		renderNode, err = avEngine.CreateSweepSynth(config.StartFrequency, config.EndFrequency, config.Duration)
+	case SynthSilence:
+		renderNode, err = avEngine.CreateSilenceSynth()
+	default:
+		return nil, fmt.Errorf("unknown synth kind %q", config.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create synth node: %w", err)
+	}
+
+	baseChannel := NewBaseChannel(name, ChannelTypeSynth, engine)
+
+	outputMixer, err := avEngine.CreateMixerNode() // Create dedicated mixer for this channel
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel mixer: %w", err)
+	}
+	baseChannel.outputMixer = outputMixer
+
+	return &SynthChannel{
+		BaseChannel: baseChannel,
+		config:      config,
+		renderNode:  renderNode,
+	}, nil
+}
+
+func noiseKindToAVEngine(kind NoiseKind) avengine.SynthNoiseKind {
+	if kind == NoisePink {
+		return avengine.SynthNoisePink
+	}
+	return avengine.SynthNoiseWhite
+}
+
+// SetFrequency updates the live frequency of a SynthSine or SynthSweep
+// channel. It is an error to call this on a SynthNoise or SynthSilence
+// channel.
+func (sc *SynthChannel) SetFrequency(frequencyHz float64) error {
+	if sc.config.Kind != SynthSine && sc.config.Kind != SynthSweep {
+		return fmt.Errorf("SetFrequency does not apply to a %s synth channel", sc.config.Kind)
+	}
+	avEngine := sc.engine.getAVEngine()
+	if err := avEngine.SetSynthFrequency(sc.renderNode, frequencyHz); err != nil {
+		return err
+	}
+	sc.config.Frequency = frequencyHz
+	return nil
+}
+
+// SetAmplitude updates the live output amplitude of the synth channel.
+func (sc *SynthChannel) SetAmplitude(amplitude float64) error {
+	avEngine := sc.engine.getAVEngine()
+	if err := avEngine.SetSynthAmplitude(sc.renderNode, amplitude); err != nil {
+		return err
+	}
+	sc.config.Amplitude = amplitude
+	return nil
+}
+
+// Start starts the synth channel, connecting its render node into the
+// channel mixer and the channel mixer into the main mixer, same
+// connect-with-fallback pattern as ProcessingChannel.Start.
+func (sc *SynthChannel) Start() error {
+	if err := sc.BaseChannel.Start(); err != nil {
+		return err
+	}
+
+	avEngine := sc.engine.getAVEngine()
+
+	if err := avEngine.Connect(sc.renderNode, sc.outputMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(sc.renderNode, sc.outputMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect synth node to channel mixer: %w", err)
+		}
+	}
+
+	mainMixer, err := avEngine.MainMixerNode()
+	if err != nil {
+		return fmt.Errorf("failed to get main mixer: %w", err)
+	}
+	if err := avEngine.Connect(sc.outputMixer, mainMixer, 0, 0); err != nil {
+		if err := avEngine.ConnectWithFormat(sc.outputMixer, mainMixer, 0, 0, nil); err != nil {
+			return fmt.Errorf("failed to connect channel mixer to main mixer: %w", err)
+		}
+	}
+
+	return sc.engine.startAVEngineIfReady()
+}
+
+// Stop stops the synth channel and disconnects its render node.
+func (sc *SynthChannel) Stop() error {
+	avEngine := sc.engine.getAVEngine()
+
+	if sc.outputMixer != nil {
+		avEngine.DisconnectNodeInput(sc.outputMixer, 0)
+	}
+
+	return sc.BaseChannel.Stop()
+}
@@ -0,0 +1,204 @@
+// Package waveform extracts a downsampled min/max peak envelope from an
+// audio file, suitable for rendering a waveform in a DAW/player UI without
+// loading the whole file into memory or shipping raw samples to the
+// frontend.
+package waveform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	avengine "github.com/shaban/macaudio/avaudio/engine"
+)
+
+// Options configures a ComputePeaks call.
+type Options struct {
+	StartFrame int64 // first frame to analyze
+	EndFrame   int64 // frame to stop before; 0 means "to end of file"
+	Channels   int   // output channel count: must be the file's channel count, or 1 to downmix; 0 defaults to the file's channel count
+	NumBins    int   // number of peak bins to produce
+}
+
+// cacheKey identifies a previously computed peak envelope. fileHash is
+// derived from the file's size and modification time rather than its
+// content, since hashing the content would mean reading the whole file up
+// front - exactly what streaming is meant to avoid. A file edited in place
+// without its mtime changing will produce a stale cache hit; this matches
+// the tradeoff LoadFileStreaming already makes by trusting file extension
+// over content sniffing.
+type cacheKey struct {
+	fileHash string
+	channels int
+	numBins  int
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[cacheKey][]int16{}
+)
+
+func fileHash(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d:%d", path, info.Size(), info.ModTime().UnixNano()), nil
+}
+
+const readChunkFrames = 4096
+
+// ComputePeaks streams path in chunks and returns a peak envelope of length
+// opts.NumBins * channels * 2: for each bin, the minimum and maximum sample
+// observed in that bin's frame range, per channel, interleaved
+// (bin0ch0min, bin0ch0max, bin0ch1min, bin0ch1max, bin1ch0min, ...).
+//
+// onProgress, if non-nil, is called with a 0..1 fraction as frames are
+// consumed. Cancel ctx to abort a long scan early; a cancelled scan returns
+// ctx.Err().
+//
+// Repeated calls for the same file/NumBins/channel count return the cached
+// result in O(1) rather than rescanning.
+func ComputePeaks(ctx context.Context, path string, opts Options, onProgress func(float64)) ([]int16, error) {
+	if opts.NumBins <= 0 {
+		return nil, fmt.Errorf("numBins must be positive, got %d", opts.NumBins)
+	}
+
+	hash, err := fileHash(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat %q: %w", path, err)
+	}
+
+	f, err := avengine.OpenAudioFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+
+	channels := opts.Channels
+	if channels == 0 {
+		channels = f.ChannelCount()
+	}
+	if channels != 1 && channels != f.ChannelCount() {
+		return nil, fmt.Errorf("requested %d channels but file has %d (only a mono downmix is supported otherwise)", channels, f.ChannelCount())
+	}
+
+	key := cacheKey{fileHash: hash, channels: channels, numBins: opts.NumBins}
+	cacheMu.Lock()
+	if cached, ok := cache[key]; ok {
+		cacheMu.Unlock()
+		out := make([]int16, len(cached))
+		copy(out, cached)
+		return out, nil
+	}
+	cacheMu.Unlock()
+
+	start := opts.StartFrame
+	end := opts.EndFrame
+	if end == 0 || end > f.FrameCount() {
+		end = f.FrameCount()
+	}
+	if start < 0 || start >= end {
+		return nil, fmt.Errorf("invalid frame range [%d, %d)", start, end)
+	}
+
+	peaks, err := scan(ctx, f, start, end, channels, opts.NumBins, onProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	cache[key] = peaks
+	cacheMu.Unlock()
+
+	out := make([]int16, len(peaks))
+	copy(out, peaks)
+	return out, nil
+}
+
+func scan(ctx context.Context, f *avengine.AudioFile, start, end int64, channels, numBins int, onProgress func(float64)) ([]int16, error) {
+	totalFrames := end - start
+	framesPerBin := float64(totalFrames) / float64(numBins)
+
+	fileChannels := f.ChannelCount()
+	mins := make([]float32, numBins*channels)
+	maxs := make([]float32, numBins*channels)
+	for i := range mins {
+		mins[i] = 1
+		maxs[i] = -1
+	}
+
+	if err := f.Seek(start); err != nil {
+		return nil, fmt.Errorf("seek to frame %d: %w", start, err)
+	}
+
+	buf := make([]float32, readChunkFrames*fileChannels)
+	var consumed int64
+
+	for consumed < totalFrames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		want := readChunkFrames
+		if remaining := totalFrames - consumed; remaining < int64(want) {
+			want = int(remaining)
+		}
+
+		framesRead, readErr := f.Read(buf[:want*fileChannels])
+		for frame := 0; frame < framesRead; frame++ {
+			absFrame := consumed + int64(frame)
+			bin := int(float64(absFrame) / framesPerBin)
+			if bin >= numBins {
+				bin = numBins - 1
+			}
+
+			for ch := 0; ch < channels; ch++ {
+				var sample float32
+				if channels == 1 && fileChannels > 1 {
+					for fc := 0; fc < fileChannels; fc++ {
+						sample += buf[frame*fileChannels+fc]
+					}
+					sample /= float32(fileChannels)
+				} else {
+					sample = buf[frame*fileChannels+ch]
+				}
+
+				idx := bin*channels + ch
+				if sample < mins[idx] {
+					mins[idx] = sample
+				}
+				if sample > maxs[idx] {
+					maxs[idx] = sample
+				}
+			}
+		}
+
+		consumed += int64(framesRead)
+		if onProgress != nil {
+			onProgress(float64(consumed) / float64(totalFrames))
+		}
+
+		if readErr != nil && framesRead == 0 {
+			break
+		}
+	}
+
+	out := make([]int16, numBins*channels*2)
+	for i := 0; i < numBins*channels; i++ {
+		out[i*2] = toInt16(mins[i])
+		out[i*2+1] = toInt16(maxs[i])
+	}
+	return out, nil
+}
+
+func toInt16(sample float32) int16 {
+	if sample > 1 {
+		sample = 1
+	}
+	if sample < -1 {
+		sample = -1
+	}
+	return int16(sample * 32767)
+}